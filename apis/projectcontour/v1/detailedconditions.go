@@ -134,10 +134,49 @@ type DetailedCondition struct {
 	Warnings []SubCondition `json:"warnings,omitempty"`
 }
 
+// ConditionTransition records a single change in the status or reason of a
+// condition, so that the history of an object flapping between states can be
+// inspected after the fact.
+type ConditionTransition struct {
+	// Type of the condition that transitioned.
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MaxLength=316
+	Type string `json:"type"`
+	// Status of the condition after the transition, one of True, False, Unknown.
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=True;False;Unknown
+	Status ConditionStatus `json:"status"`
+	// Reason for the condition after the transition.
+	// +optional
+	// +kubebuilder:validation:MaxLength=1024
+	Reason string `json:"reason,omitempty"`
+	// Message is a human readable message indicating details about the transition.
+	// +optional
+	// +kubebuilder:validation:MaxLength=32768
+	Message string `json:"message,omitempty"`
+	// ObservedGeneration is the .metadata.generation of the object that
+	// the transition was observed for.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// TransitionTime is the time at which the transition was observed.
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Format=date-time
+	TransitionTime metav1.Time `json:"transitionTime"`
+}
+
 const (
 	// ValidConditionType describes an valid condition.
 	ValidConditionType = "Valid"
 
+	// OrphanedConditionType describes an HTTPProxy that is not
+	// part of a delegation chain from a root HTTPProxy.
+	OrphanedConditionType = "Orphaned"
+
 	// ConditionTypeAuthError describes an error condition related to Auth.
 	ConditionTypeAuthError = "AuthError"
 
@@ -40,6 +40,36 @@ type HTTPProxySpec struct {
 	// is given precedence over this field.
 	// +optional
 	IngressClassName string `json:"ingressClassName,omitempty"`
+	// Parameters declares the parameters that the includes of this HTTPProxy
+	// supply, so that it can be included as a template by many HTTPProxies.
+	// A parameter is referenced as "${name}" in the names of the services
+	// and the header values of the routes, in the prefix conditions of the
+	// routes and includes, and in the names, namespaces and parameter values
+	// of the includes.
+	// Parameters can't be declared by root HTTPProxies.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Parameters []HTTPProxyParameter `json:"parameters,omitempty"`
+}
+
+// HTTPProxyParameter declares a parameter of an HTTPProxy.
+type HTTPProxyParameter struct {
+	// Name of the parameter.
+	// +kubebuilder:validation:Pattern=`^[A-Za-z_][A-Za-z0-9_]*$`
+	Name string `json:"name"`
+	// Default is the value of the parameter when an include doesn't supply it.
+	// A parameter without a default must be supplied by every include.
+	// +optional
+	Default *string `json:"default,omitempty"`
+}
+
+// IncludeParameter supplies the value of a parameter of an included HTTPProxy.
+type IncludeParameter struct {
+	// Name of the parameter, which the included HTTPProxy must declare.
+	Name string `json:"name"`
+	// Value of the parameter.
+	Value string `json:"value"`
 }
 
 // Include describes a set of policies that can be applied to an HTTPProxy in a namespace.
@@ -59,10 +89,21 @@ type Include struct {
 	// on includes.
 	// +optional
 	Conditions []MatchCondition `json:"conditions,omitempty"`
+	// Priority replaces the priority of all the routes of the included
+	// HTTPProxy, including those of the HTTPProxies that it includes,
+	// so that the including HTTPProxy controls their order.
+	// +optional
+	Priority *int32 `json:"priority,omitempty"`
+	// Parameters supplies the values of the parameters that the
+	// included HTTPProxy declares.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Parameters []IncludeParameter `json:"parameters,omitempty"`
 }
 
 // MatchCondition are a general holder for matching rules for HTTPProxies.
-// One of Prefix, Exact, Regex, Header or QueryParameter must be provided.
+// One of Prefix, Exact, Regex, Header, QueryParameter, Methods, Not or Or must be provided.
 type MatchCondition struct {
 	// Prefix defines a prefix match for a request.
 	// +optional
@@ -85,6 +126,84 @@ type MatchCondition struct {
 	// QueryParameter specifies the query parameter condition to match.
 	// +optional
 	QueryParameter *QueryParameterMatchCondition `json:"queryParameter,omitempty"`
+
+	// Methods specifies the request methods to match. A request
+	// matches if its method is any of the given methods.
+	// +optional
+	// +kubebuilder:validation:MinItems=1
+	Methods []HTTPMethod `json:"methods,omitempty"`
+
+	// Not specifies a condition that must not match.
+	// This field is not allowed in include match conditions.
+	// +optional
+	Not *NegatedMatchCondition `json:"not,omitempty"`
+
+	// Or specifies conditions of which at least one must match.
+	// A route with Or conditions is compiled into a route for each
+	// combination of them, so Or can't be combined with other
+	// conditions in the same MatchCondition.
+	// This field is not allowed in include match conditions.
+	// +optional
+	Or []OrMatchCondition `json:"or,omitempty"`
+}
+
+// HTTPMethod is an HTTP request method.
+// +kubebuilder:validation:Enum=GET;HEAD;POST;PUT;DELETE;CONNECT;OPTIONS;TRACE;PATCH
+type HTTPMethod string
+
+// NegatedMatchCondition specifies a condition that must not match.
+// One of Prefix, Exact, Regex, Header or QueryParameter must be provided.
+// Negated Prefix, Exact and Regex conditions match the full request path,
+// regardless of the conditions of the includes of the route.
+type NegatedMatchCondition struct {
+	// Prefix defines a prefix the request path must not have.
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// Exact defines a request path that must not match exactly.
+	// +optional
+	Exact string `json:"exact,omitempty"`
+
+	// Regex defines a regex the request path must not match.
+	// +optional
+	Regex string `json:"regex,omitempty"`
+
+	// Header specifies a header condition that must not match.
+	// +optional
+	Header *HeaderMatchCondition `json:"header,omitempty"`
+
+	// QueryParameter specifies a query parameter condition that must not match.
+	// +optional
+	QueryParameter *QueryParameterMatchCondition `json:"queryParameter,omitempty"`
+}
+
+// OrMatchCondition specifies one of the alternative conditions of an Or
+// condition. One of Prefix, Exact, Regex, Header, QueryParameter or Not
+// must be provided.
+type OrMatchCondition struct {
+	// Prefix defines a prefix match for a request.
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// Exact defines a exact match for a request.
+	// +optional
+	Exact string `json:"exact,omitempty"`
+
+	// Regex defines a regex match for a request.
+	// +optional
+	Regex string `json:"regex,omitempty"`
+
+	// Header specifies the header condition to match.
+	// +optional
+	Header *HeaderMatchCondition `json:"header,omitempty"`
+
+	// QueryParameter specifies the query parameter condition to match.
+	// +optional
+	QueryParameter *QueryParameterMatchCondition `json:"queryParameter,omitempty"`
+
+	// Not specifies a condition that must not match.
+	// +optional
+	Not *NegatedMatchCondition `json:"not,omitempty"`
 }
 
 // HeaderMatchCondition specifies how to conditionally match against HTTP
@@ -285,6 +404,15 @@ type VirtualHost struct {
 	// +kubebuilder:validation:Pattern="^(\\*\\.)?[a-z0-9]([-a-z0-9]*[a-z0-9])?(\\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$"
 	Fqdn string `json:"fqdn"`
 
+	// Aliases are additional fully qualified domain names that the
+	// virtual host is served on. Each alias can be exact or a wildcard,
+	// and shares the routes and TLS configuration of the fqdn, so the
+	// TLS certificate must also contain a name that matches each alias
+	// that doesn't have its own certificate in tls.aliasSecrets.
+	//
+	// +optional
+	Aliases []VirtualHostAlias `json:"aliases,omitempty"`
+
 	// If present the fields describes TLS properties of the virtual
 	// host. The SNI names that will be matched on are described in fqdn,
 	// the tls.secretName secret must contain a certificate that itself
@@ -293,6 +421,15 @@ type VirtualHost struct {
 	// +optional
 	TLS *TLS `json:"tls,omitempty"`
 
+	// Insecure declares that the virtual host is only served over
+	// plain HTTP, without TLS or a redirect to HTTPS. It can't be
+	// combined with TLS. Depending on Contour's insecure virtual
+	// host policy, it's required for virtual hosts without TLS, or
+	// rejected.
+	//
+	// +optional
+	Insecure bool `json:"insecure,omitempty"`
+
 	// This field configures an extension service to perform
 	// authorization for this virtual host. Authorization can
 	// only be configured on virtual hosts that have TLS enabled.
@@ -323,6 +460,106 @@ type VirtualHost struct {
 	// Only one of IPAllowFilterPolicy and IPDenyFilterPolicy can be defined.
 	// The rules defined here may be overridden in a Route.
 	IPDenyFilterPolicy []IPFilterPolicy `json:"ipDenyPolicy,omitempty"`
+
+	// HTTPSRedirectPolicy customizes how HTTP requests to routes that
+	// require TLS are redirected to HTTPS. If set, it replaces the
+	// Contour-wide HTTPS redirect policy for this virtual host.
+	// +optional
+	HTTPSRedirectPolicy *HTTPSRedirectPolicy `json:"httpsRedirectPolicy,omitempty"`
+
+	// HTTP2MaxConcurrentStreams is the maximum number of concurrent
+	// streams that each downstream HTTP/2 connection to this virtual
+	// host can open. If set, it replaces the listener's HTTP/2 maximum
+	// concurrent streams for this virtual host. It can only be defined
+	// on virtual hosts that terminate TLS.
+	//
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=2147483647
+	// +optional
+	HTTP2MaxConcurrentStreams *uint32 `json:"http2MaxConcurrentStreams,omitempty"`
+
+	// MaintenanceMode switches the routes of this virtual host, including
+	// the routes of included HTTPProxies, to a static response, e.g. while
+	// the application is taken down for maintenance.
+	// +optional
+	MaintenanceMode *MaintenanceMode `json:"maintenanceMode,omitempty"`
+
+	// AccessLogPolicy overrides the Contour-wide access log configuration
+	// for the requests to this virtual host. Settings that aren't set
+	// keep their Contour-wide value.
+	// +optional
+	AccessLogPolicy *AccessLogPolicy `json:"accessLogPolicy,omitempty"`
+}
+
+// AccessLogPolicy defines how the requests to a virtual host are access logged.
+type AccessLogPolicy struct {
+	// Disabled turns off access logging for the virtual host.
+	// It can't be combined with the other settings.
+	// +optional
+	Disabled bool `json:"disabled,omitempty"`
+
+	// Format sets the access log format of the virtual host.
+	// Defaults to the Contour-wide access log format.
+	// +kubebuilder:validation:Enum=envoy;json
+	// +optional
+	Format string `json:"format,omitempty"`
+
+	// FormatString sets the access log format string of the virtual
+	// host when the access log format is `envoy`. Defaults to the
+	// Contour-wide access log format string.
+	// +optional
+	FormatString string `json:"formatString,omitempty"`
+
+	// JSONFields sets the fields that are logged for the virtual host
+	// when the access log format is `json`, in the same syntax as the
+	// Contour-wide JSON fields. Defaults to the Contour-wide JSON fields.
+	// +optional
+	JSONFields []string `json:"jsonFields,omitempty"`
+}
+
+// MaintenanceMode defines the static response that the routes of
+// a virtual host send while the virtual host is in maintenance.
+type MaintenanceMode struct {
+	// Enabled puts the virtual host in maintenance.
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// StatusCode is the HTTP status code of the maintenance response.
+	// Defaults to 503.
+	// +kubebuilder:validation:Minimum=200
+	// +kubebuilder:validation:Maximum=599
+	// +optional
+	StatusCode int `json:"statusCode,omitempty"`
+
+	// Body is the body of the maintenance response.
+	// If not set, the response has no body.
+	// +optional
+	Body string `json:"body,omitempty"`
+
+	// HealthCheckPathPrefixes are path prefixes that keep being routed
+	// to their services while the virtual host is in maintenance, for
+	// example the paths that load balancers use for health checks.
+	// +optional
+	HealthCheckPathPrefixes []string `json:"healthCheckPathPrefixes,omitempty"`
+}
+
+// HTTPSRedirectPolicy defines how HTTP requests are redirected to HTTPS.
+type HTTPSRedirectPolicy struct {
+	// StatusCode is the HTTP status code of the redirect response.
+	// Defaults to 301.
+	// +kubebuilder:validation:Enum=301;308
+	// +optional
+	StatusCode int `json:"statusCode,omitempty"`
+
+	// StripQuery removes the query string from the redirect location.
+	// +optional
+	StripQuery bool `json:"stripQuery,omitempty"`
+
+	// ExcludedPathPrefixes are path prefixes that are served over
+	// HTTP instead of being redirected, for example
+	// /.well-known/acme-challenge.
+	// +optional
+	ExcludedPathPrefixes []string `json:"excludedPathPrefixes,omitempty"`
 }
 
 // JWTProvider defines how to verify JWTs on requests.
@@ -413,6 +650,13 @@ type TLS struct {
 	// The name can be optionally prefixed with namespace "namespace/name".
 	// When cross-namespace reference is used, TLSCertificateDelegation resource must exist in the namespace to grant access to the secret.
 	SecretName string `json:"secretName,omitempty"`
+	// CertificateName is the name of a cert-manager Certificate in the
+	// namespace of this HTTPProxy. It is an alternative to SecretName:
+	// Envoy serves the Secret the Certificate is issued to, and the
+	// HTTPProxy's status reports when the Certificate is not ready.
+	// SecretName and CertificateName can't both be specified.
+	// +optional
+	CertificateName string `json:"certificateName,omitempty"`
 	// MinimumProtocolVersion is the minimum TLS version this vhost should
 	// negotiate. Valid options are `1.2` (default) and `1.3`. Any other value
 	// defaults to TLS 1.2.
@@ -443,8 +687,31 @@ type TLS struct {
 	// EnableFallbackCertificate defines if the vhost should allow a default certificate to
 	// be applied which handles all requests which don't match the SNI defined in this vhost.
 	EnableFallbackCertificate bool `json:"enableFallbackCertificate,omitempty"`
+
+	// AliasSecrets are the Secrets of the certificates of aliases of the
+	// virtual host, which are served for the SNI names of the aliases
+	// instead of the certificate of SecretName or CertificateName.
+	// +optional
+	AliasSecrets []AliasSecret `json:"aliasSecrets,omitempty"`
+}
+
+// AliasSecret is the Secret of the certificate of an alias of a virtual host.
+type AliasSecret struct {
+	// Alias is an alias of the virtual host.
+	Alias VirtualHostAlias `json:"alias"`
+
+	// SecretName is the name of a TLS secret containing a certificate
+	// for the alias. The name can be optionally prefixed with namespace
+	// "namespace/name". When cross-namespace reference is used,
+	// TLSCertificateDelegation resource must exist in the namespace to
+	// grant access to the secret.
+	SecretName string `json:"secretName"`
 }
 
+// VirtualHostAlias is an additional fully qualified domain name of a virtual host.
+// +kubebuilder:validation:Pattern="^(\\*\\.)?[a-z0-9]([-a-z0-9]*[a-z0-9])?(\\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$"
+type VirtualHostAlias string
+
 // CORSHeaderValue specifies the value of the string headers returned by a cross-domain request.
 // +kubebuilder:validation:Pattern="^[a-zA-Z0-9!#$%&'*+.^_`|~-]+$"
 type CORSHeaderValue string
@@ -501,6 +768,15 @@ type Route struct {
 	// Services are the services to proxy traffic.
 	// +optional
 	Services []Service `json:"services,omitempty"`
+	// AggregateServices are the services to proxy traffic to through an
+	// Envoy aggregate cluster, in order of preference. Traffic is only
+	// sent to a Service when the endpoints of the Services before it are
+	// unhealthy. Unlike failover Services, each aggregate Service keeps
+	// its own protocol and upstream validation, and can be an ExternalName
+	// Service.
+	// +optional
+	// +kubebuilder:validation:MaxItems=8
+	AggregateServices []AggregateService `json:"aggregateServices,omitempty"`
 	// Enables websocket support for the route.
 	// +optional
 	EnableWebsockets bool `json:"enableWebsockets,omitempty"`
@@ -519,6 +795,9 @@ type Route struct {
 	// The retry policy for this route.
 	// +optional
 	RetryPolicy *RetryPolicy `json:"retryPolicy,omitempty"`
+	// The hedge policy for this route.
+	// +optional
+	HedgePolicy *HedgePolicy `json:"hedgePolicy,omitempty"`
 	// The health check policy for this route.
 	// +optional
 	HealthCheckPolicy *HTTPHealthCheckPolicy `json:"healthCheckPolicy,omitempty"`
@@ -542,6 +821,13 @@ type Route struct {
 	// policies are specified in does not matter.
 	// +optional
 	CookieRewritePolicies []CookieRewritePolicy `json:"cookieRewritePolicies,omitempty"`
+	// ABTestPolicy splits the traffic between the services of the
+	// route deterministically, according to their weights, by hashing
+	// a request header or cookie, so that a given user consistently
+	// reaches the same service. The services must all have the
+	// same cookie rewrite policies.
+	// +optional
+	ABTestPolicy *ABTestPolicy `json:"abTestPolicy,omitempty"`
 	// The policy for rate limiting on the route.
 	// +optional
 	RateLimitPolicy *RateLimitPolicy `json:"rateLimitPolicy,omitempty"`
@@ -573,6 +859,141 @@ type Route struct {
 	// Only one of IPAllowFilterPolicy and IPDenyFilterPolicy can be defined.
 	// The rules defined here override any rules set on the root HTTPProxy.
 	IPDenyFilterPolicy []IPFilterPolicy `json:"ipDenyPolicy,omitempty"`
+
+	// Metadata is a set of key/value pairs, such as the owning team,
+	// that are added to the Envoy route metadata in the
+	// "projectcontour.io" namespace. The values can be included in
+	// access logs with the %METADATA(ROUTE:projectcontour.io:<key>)%
+	// command operator.
+	// +optional
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// EnableCapture enables capturing the requests and responses
+	// that match the route to the sink configured in the Contour
+	// configuration's capture settings. Requests are sampled and
+	// bodies are size-limited according to those settings.
+	// Capture has no effect if no sink is configured.
+	// +optional
+	EnableCapture bool `json:"enableCapture,omitempty"`
+
+	// StatPrefix enables the Envoy per-route statistics of the route,
+	// such as request counts by response code class and request
+	// durations. They are emitted under the prefix
+	// vhost.<fqdn>.route.<namespace>_<name>_<statPrefix>, where
+	// namespace and name are those of the HTTPProxy that defines the
+	// route. Per-route statistics take about 1KiB of Envoy memory
+	// per route, so they should only be enabled for critical routes.
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9_-]+$`
+	// +kubebuilder:validation:MaxLength=63
+	// +optional
+	StatPrefix string `json:"statPrefix,omitempty"`
+
+	// Schedule restricts when the route is active. While the route
+	// is inactive, it is left out of the virtual host, as if it
+	// wasn't defined. Contour re-evaluates schedules when they
+	// change, so routes are added and removed at the scheduled times.
+	// +optional
+	Schedule *RouteSchedule `json:"schedule,omitempty"`
+
+	// RuntimeFraction restricts the route to a percentage of the
+	// requests that match its conditions, which is read from an Envoy
+	// runtime flag. Requests that don't match fall through to the
+	// next route, so the percentage can be changed to gradually
+	// enable the route without recreating it.
+	// +optional
+	RuntimeFraction *RuntimeFraction `json:"runtimeFraction,omitempty"`
+
+	// Priority orders the route before all routes with a lower
+	// priority, regardless of their conditions. Routes with the same
+	// priority are ordered by their conditions, most specific first.
+	// Routes with the same non-zero priority are ambiguous, and make
+	// the HTTPProxy invalid, if their path conditions differ and at
+	// least one of them is a regex, since they may both match.
+	// Defaults to 0.
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+}
+
+// RuntimeFraction defines the runtime flag that
+// gives the percentage of requests a route matches.
+type RuntimeFraction struct {
+	// Flag is the name of the runtime flag. Flags are shared by all
+	// routes that use them, in all namespaces. The percentage of a
+	// flag can be set in the Contour configuration, which serves it
+	// to Envoy as the "contour.flags.<flag>" runtime key.
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9_-]+(\.[a-zA-Z0-9_-]+)*$`
+	Flag string `json:"flag"`
+
+	// DefaultPercent is the percentage of requests
+	// that match the route while the flag isn't set.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	DefaultPercent uint32 `json:"defaultPercent,omitempty"`
+}
+
+// RouteSchedule defines when a route is active.
+type RouteSchedule struct {
+	// TimeZone is the IANA time zone, e.g. Europe/Berlin,
+	// that the windows are defined in. Defaults to UTC.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+
+	// NotBefore is the time, in RFC 3339 format, before which
+	// the route is inactive, e.g. for launch embargoes.
+	// +optional
+	NotBefore string `json:"notBefore,omitempty"`
+
+	// NotAfter is the time, in RFC 3339 format,
+	// from which the route is inactive.
+	// +optional
+	NotAfter string `json:"notAfter,omitempty"`
+
+	// Windows are the weekly recurring windows in which the route
+	// is active. If not set, the route is active all the time
+	// between NotBefore and NotAfter.
+	// +optional
+	Windows []ScheduleWindow `json:"windows,omitempty"`
+}
+
+// ScheduleWindow defines a weekly recurring window of time,
+// like the day of week and time fields of a cron schedule.
+type ScheduleWindow struct {
+	// Days are the days of the week on which the window starts.
+	// Defaults to every day.
+	// +optional
+	Days []ScheduleDay `json:"days,omitempty"`
+
+	// Start is the time of day, in HH:MM format, at which the window starts.
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	Start string `json:"start"`
+
+	// End is the time of day, in HH:MM format, at which the window
+	// ends. If it isn't after Start, the window ends on the next day.
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	End string `json:"end"`
+}
+
+// ScheduleDay is a day of the week.
+// +kubebuilder:validation:Enum=Mon;Tue;Wed;Thu;Fri;Sat;Sun
+type ScheduleDay string
+
+// ABTestPolicy defines the request attribute that is hashed to pick
+// the service of a route. Exactly one of HeaderName and CookieName
+// must be set. Requests without the attribute are split randomly.
+type ABTestPolicy struct {
+	// HeaderName is the name of the request header that is hashed.
+	// +optional
+	HeaderName string `json:"headerName,omitempty"`
+
+	// CookieName is the name of the request cookie that is hashed.
+	// +optional
+	CookieName string `json:"cookieName,omitempty"`
+
+	// Salt is hashed together with the header or cookie value, so
+	// that tests with different salts assign users independently.
+	// +optional
+	Salt string `json:"salt,omitempty"`
 }
 
 type JWTVerificationPolicy struct {
@@ -630,6 +1051,41 @@ type HTTPDirectResponsePolicy struct {
 	//
 	// +optional
 	Body string `json:"body,omitempty"`
+
+	// BodyFrom sets the content of the response body to the
+	// value of a key of a ConfigMap or an Opaque Secret in the
+	// HTTPProxy's namespace, for content that is managed separately
+	// from the HTTPProxy.
+	// The value must not be larger than 4096 bytes.
+	// Only one of Body and BodyFrom can be set.
+	//
+	// +optional
+	BodyFrom *DirectResponseBodySource `json:"bodyFrom,omitempty"`
+}
+
+// DirectResponseBodySource references the content of
+// a direct response body.
+// Exactly one of ConfigMapName and SecretName must be set.
+type DirectResponseBodySource struct {
+	// ConfigMapName is the name of the ConfigMap in the
+	// HTTPProxy's namespace.
+	//
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	ConfigMapName string `json:"configMapName,omitempty"`
+
+	// SecretName is the name of the Secret in the
+	// HTTPProxy's namespace.
+	//
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	SecretName string `json:"secretName,omitempty"`
+
+	// Key is the key of the ConfigMap's or Secret's data
+	// that holds the content of the body.
+	//
+	// +kubebuilder:validation:MinLength=1
+	Key string `json:"key"`
 }
 
 // HTTPRequestRedirectPolicy defines configuration for redirecting a request.
@@ -973,8 +1429,10 @@ type Service struct {
 	// +optional
 	HealthPort int `json:"healthPort,omitempty"`
 	// Protocol may be used to specify (or override) the protocol used to reach this Service.
-	// Values may be tls, h2, h2c. If omitted, protocol-selection falls back on Service annotations.
-	// +kubebuilder:validation:Enum=h2;h2c;tls
+	// Values may be h1, tls, h2, h2c, where h1 is cleartext HTTP/1.1, which overrides any
+	// protocol set by Service annotations. If omitted, protocol-selection falls back on
+	// Service annotations.
+	// +kubebuilder:validation:Enum=h1;h2;h2c;tls
 	// +optional
 	Protocol *string `json:"protocol,omitempty"`
 	// Weight defines percentage of traffic to balance traffic
@@ -1004,6 +1462,86 @@ type Service struct {
 	// Slow start will gradually increase amount of traffic to a newly added endpoint.
 	// +optional
 	SlowStartPolicy *SlowStartPolicy `json:"slowStartPolicy,omitempty"`
+	// DNSLookupFamily defines how the external name of an ExternalName
+	// Service is looked up. When configured as V4, the DNS resolver will
+	// only perform a lookup for addresses in the IPv4 family. If V6 is
+	// configured, the DNS resolver will only perform a lookup for addresses
+	// in the IPv6 family. If AUTO is configured, the DNS resolver will first
+	// perform a lookup for addresses in the IPv6 family and fallback to a
+	// lookup for addresses in the IPv4 family. If ALL is specified, the DNS
+	// resolver will perform a lookup for both IPv4 and IPv6 families, and
+	// return all resolved addresses. If not specified, the Contour-wide
+	// setting defined in the config file or ContourConfiguration applies
+	// (defaults to "auto").
+	//
+	// See https://www.envoyproxy.io/docs/envoy/latest/api-v3/config/cluster/v3/cluster.proto.html#envoy-v3-api-enum-config-cluster-v3-cluster-dnslookupfamily
+	// for more information.
+	// +optional
+	// +kubebuilder:validation:Enum=auto;v4;v6;all
+	DNSLookupFamily string `json:"dnsLookupFamily,omitempty"`
+	// Failover is an ordered list of Services that only receive the
+	// traffic for this Service when its endpoints are unhealthy. Each
+	// Service in the list is a lower priority level than the ones
+	// before it, and receives traffic when the endpoints of all the
+	// higher priority levels are unhealthy. Failover Services must be
+	// in the same namespace as the HTTPProxy, are reached with the same
+	// protocol and policies as this Service, and can't be ExternalName
+	// Services. Failover is only supported for route Services.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	Failover []FailoverService `json:"failover,omitempty"`
+	// Subset restricts the traffic for this Service to the subset of
+	// its pods that have all of these labels, for example version=v2.
+	// Subsets are selected by Envoy subset load balancing, so traffic
+	// is not sent to any other pods of the Service, even when there are
+	// no pods in the subset. Subset can't be used with ExternalName
+	// Services, and is only supported for route Services that are not
+	// mirrors.
+	// +optional
+	// +kubebuilder:validation:MaxProperties=8
+	Subset map[string]string `json:"subset,omitempty"`
+	// HTTP2KeepalivePolicy sends HTTP/2 PING frames on the connections
+	// to this Service, so that half-open connections, e.g. through NATs
+	// or firewalls that dropped them, are detected and closed. It
+	// overrides the HTTP/2 keepalive in the Contour configuration, and
+	// only applies when the Service is reached with the h2 or h2c protocol.
+	// +optional
+	HTTP2KeepalivePolicy *HTTP2KeepalivePolicy `json:"http2KeepalivePolicy,omitempty"`
+}
+
+// AggregateService is a Service that is part of an aggregate cluster.
+type AggregateService struct {
+	// Name is the name of Kubernetes service to proxy traffic.
+	Name string `json:"name"`
+	// Port (defined as Integer) to proxy traffic to since a service can have multiple defined.
+	//
+	// +required
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int `json:"port"`
+	// Protocol may be used to specify (or override) the protocol used to reach this Service.
+	// Values may be h1, tls, h2, h2c, where h1 is cleartext HTTP/1.1, which overrides any
+	// protocol set by Service annotations. If omitted, protocol-selection falls back on
+	// Service annotations.
+	// +kubebuilder:validation:Enum=h1;h2;h2c;tls
+	// +optional
+	Protocol *string `json:"protocol,omitempty"`
+	// UpstreamValidation defines how to verify the backend service's certificate
+	// +optional
+	UpstreamValidation *UpstreamValidation `json:"validation,omitempty"`
+}
+
+// FailoverService is a Service that receives traffic when the
+// endpoints of the Services before it are unhealthy.
+type FailoverService struct {
+	// Name is the name of Kubernetes service to fail over to.
+	Name string `json:"name"`
+	// Port (defined as Integer) to proxy traffic to since a service can have multiple defined.
+	//
+	// +required
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int `json:"port"`
 }
 
 // HTTPHealthCheckPolicy defines health checks on the upstream service.
@@ -1091,12 +1629,56 @@ type TimeoutPolicy struct {
 	// +optional
 	// +kubebuilder:validation:Pattern=`^(((\d*(\.\d*)?h)|(\d*(\.\d*)?m)|(\d*(\.\d*)?s)|(\d*(\.\d*)?ms)|(\d*(\.\d*)?us)|(\d*(\.\d*)?µs)|(\d*(\.\d*)?ns))+|infinity|infinite)$`
 	IdleConnection string `json:"idleConnection,omitempty"`
+
+	// Timeout for establishing a connection to the upstream service.
+	// If not supplied, the connect timeout in the Contour configuration applies,
+	// or Envoy's default value of 2s if that isn't set either.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^((\d*(\.\d*)?h)|(\d*(\.\d*)?m)|(\d*(\.\d*)?s)|(\d*(\.\d*)?ms)|(\d*(\.\d*)?us)|(\d*(\.\d*)?µs)|(\d*(\.\d*)?ns))+$`
+	Connect string `json:"connect,omitempty"`
+
+	// ResponseReply customizes the reply that Envoy sends when the response
+	// timeout expires, which is a 504 with a plain text body by default.
+	// If not supplied, the response timeout reply in the Contour configuration applies.
+	// +optional
+	ResponseReply *TimeoutReply `json:"responseReply,omitempty"`
+
+	// IdleReply customizes the reply that Envoy sends when the idle timeout
+	// expires, which is a 504 with a plain text body by default, or a 408
+	// if the request hasn't been fully received.
+	// If not supplied, the idle timeout reply in the Contour configuration applies.
+	// +optional
+	IdleReply *TimeoutReply `json:"idleReply,omitempty"`
+}
+
+// TimeoutReply customizes the reply that Envoy sends when a timeout expires.
+type TimeoutReply struct {
+	// StatusCode is the HTTP status code of the reply.
+	// If not supplied, the default status code of the timeout is kept.
+	// +optional
+	// +kubebuilder:validation:Minimum=200
+	// +kubebuilder:validation:Maximum=599
+	StatusCode int `json:"statusCode,omitempty"`
+
+	// Body is the body of the reply, e.g. a JSON error payload.
+	// If not supplied, the default body of the timeout is kept.
+	// +optional
+	Body string `json:"body,omitempty"`
+
+	// ContentType is the content type of the body.
+	// If not supplied, text/plain is used.
+	// +optional
+	ContentType string `json:"contentType,omitempty"`
 }
 
 // RetryOn is a string type alias with validation to ensure that the value is valid.
 // +kubebuilder:validation:Enum="5xx";gateway-error;reset;connect-failure;retriable-4xx;refused-stream;retriable-status-codes;retriable-headers;cancelled;deadline-exceeded;internal;resource-exhausted;unavailable
 type RetryOn string
 
+// RetryHostPredicate is a string type alias with validation to ensure that the value is valid.
+// +kubebuilder:validation:Enum=previous-hosts
+type RetryHostPredicate string
+
 // RetryPolicy defines the attributes associated with retrying policy.
 type RetryPolicy struct {
 	// NumRetries is maximum allowed number of retries.
@@ -1139,6 +1721,70 @@ type RetryPolicy struct {
 	// This field is only respected when you include `retriable-status-codes` in the `RetryOn` field.
 	// +optional
 	RetriableStatusCodes []uint32 `json:"retriableStatusCodes,omitempty"`
+	// RetriableHeaders specifies the response headers that should be retried.
+	// A response is retried if it matches any of the conditions.
+	//
+	// This field is only respected when you include `retriable-headers` in the `RetryOn` field.
+	// +optional
+	RetriableHeaders []HeaderMatchCondition `json:"retriableHeaders,omitempty"`
+	// RetriableRequestHeaders restricts retries to the requests that match
+	// any of the conditions. If not set, all requests can be retried.
+	// +optional
+	RetriableRequestHeaders []HeaderMatchCondition `json:"retriableRequestHeaders,omitempty"`
+	// PerTryIdleTimeout specifies the idle timeout per retry attempt,
+	// which is reset each time data is received from the upstream.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^(((\d*(\.\d*)?h)|(\d*(\.\d*)?m)|(\d*(\.\d*)?s)|(\d*(\.\d*)?ms)|(\d*(\.\d*)?us)|(\d*(\.\d*)?µs)|(\d*(\.\d*)?ns))+|infinity|infinite)$`
+	PerTryIdleTimeout string `json:"perTryIdleTimeout,omitempty"`
+	// RetryHostPredicates specifies the hosts that are avoided when
+	// selecting the host for a retry.
+	//
+	// Supported predicates:
+	//
+	// - `previous-hosts`: hosts that previous attempts were sent to
+	// +optional
+	RetryHostPredicates []RetryHostPredicate `json:"retryHostPredicates,omitempty"`
+	// HostSelectionMaxAttempts is the maximum number of times a host is
+	// selected for a retry until one is not avoided by the
+	// RetryHostPredicates. If all of them are avoided, the last selected
+	// host is used. If not supplied, the Envoy default of 1 is used.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	HostSelectionMaxAttempts int64 `json:"hostSelectionMaxAttempts,omitempty"`
+	// RetryBackOff specifies the back-off between retries. Envoy
+	// waits for a random interval up to an upper bound that grows
+	// exponentially with each retry, so that the retries of
+	// concurrent requests are spread out. If not supplied, the
+	// Envoy default base interval of 25ms is used.
+	// +optional
+	RetryBackOff *RetryBackOff `json:"retryBackOff,omitempty"`
+}
+
+// RetryBackOff defines the exponential back-off between retries.
+type RetryBackOff struct {
+	// BaseInterval is the interval that the upper bound of the
+	// back-off starts from. It must be greater than 1ms.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^(((\d*(\.\d*)?h)|(\d*(\.\d*)?m)|(\d*(\.\d*)?s)|(\d*(\.\d*)?ms)|(\d*(\.\d*)?us)|(\d*(\.\d*)?µs)|(\d*(\.\d*)?ns))+)$`
+	BaseInterval string `json:"baseInterval"`
+	// MaxInterval is the maximum back-off between retries.
+	// It must not be less than BaseInterval. If not supplied,
+	// ten times BaseInterval is used.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^(((\d*(\.\d*)?h)|(\d*(\.\d*)?m)|(\d*(\.\d*)?s)|(\d*(\.\d*)?ms)|(\d*(\.\d*)?us)|(\d*(\.\d*)?µs)|(\d*(\.\d*)?ns))+)$`
+	MaxInterval string `json:"maxInterval,omitempty"`
+}
+
+// HedgePolicy defines when requests are hedged, by sending
+// speculative parallel requests to the upstream.
+type HedgePolicy struct {
+	// HedgeOnPerTryTimeout sends a retry when the per-try timeout
+	// of the retry policy is hit, without cancelling the attempts
+	// that are in flight. The first successful response is returned.
+	// Requires a retry policy with a perTryTimeout, and should only be
+	// used for idempotent requests.
+	// +optional
+	HedgeOnPerTryTimeout bool `json:"hedgeOnPerTryTimeout,omitempty"`
 }
 
 // ReplacePrefix describes a path prefix replacement.
@@ -1234,17 +1880,35 @@ type LoadBalancerPolicy struct {
 	// Strategy specifies the policy used to balance requests
 	// across the pool of backend pods. Valid policy names are
 	// `Random`, `RoundRobin`, `WeightedLeastRequest`, `Cookie`,
-	// and `RequestHash`. If an unknown strategy name is specified
-	// or no policy is supplied, the default `RoundRobin` policy
-	// is used.
+	// `RequestHash` and `Maglev`. If an unknown strategy name is
+	// specified or no policy is supplied, the default `RoundRobin`
+	// policy is used.
 	Strategy string `json:"strategy,omitempty"`
 
 	// RequestHashPolicies contains a list of hash policies to apply when the
-	// `RequestHash` load balancing strategy is chosen. If an element of the
-	// supplied list of hash policies is invalid, it will be ignored. If the
-	// list of hash policies is empty after validation, the load balancing
-	// strategy will fall back to the default `RoundRobin`.
+	// `RequestHash` or `Maglev` load balancing strategy is chosen. If an
+	// element of the supplied list of hash policies is invalid, it will be
+	// ignored. If the list of hash policies is empty after validation, the
+	// load balancing strategy will fall back to the default `RoundRobin`.
 	RequestHashPolicies []RequestHashPolicy `json:"requestHashPolicies,omitempty"`
+
+	// MaglevTableSize sets the size of the lookup table of the `Maglev`
+	// strategy, which must be a prime number. Larger tables spread
+	// requests more evenly across backend pods, at the cost of memory.
+	// If not supplied, the Envoy default of 65537 is used.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=5000011
+	MaglevTableSize uint64 `json:"maglevTableSize,omitempty"`
+
+	// ActiveRequestBias sets how strongly the `WeightedLeastRequest`
+	// strategy favors backend pods with fewer active requests when they
+	// have different weights. Higher values favor them more strongly,
+	// and `0.0` ignores active requests. If not supplied, the Envoy
+	// default of `1.0` is used.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^([0-9]+([.][0-9]+)?|[.][0-9]+)$`
+	ActiveRequestBias string `json:"activeRequestBias,omitempty"`
 }
 
 // HeadersPolicy defines how headers are managed during forwarding.
@@ -1384,6 +2048,21 @@ type HTTPProxyStatus struct {
 	// +listType=map
 	// +listMapKey=type
 	Conditions []DetailedCondition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+	// +optional
+	// ConditionHistory contains the most recent transitions of the
+	// conditions in Conditions, newest first. A transition is recorded
+	// whenever the status or reason of a condition changes. The history
+	// is bounded, older transitions are discarded.
+	// +kubebuilder:validation:MaxItems=10
+	ConditionHistory []ConditionTransition `json:"conditionHistory,omitempty"`
+	// ProgrammedGeneration is the most recent .metadata.generation of
+	// the HTTPProxy that has been programmed into Envoy, that is, whose
+	// configuration at least one Envoy has acknowledged. It lags behind
+	// the observedGeneration of the conditions until then, and does not
+	// advance for generations that are not valid.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	ProgrammedGeneration int64 `json:"programmedGeneration,omitempty"`
 }
 
 // +genclient
@@ -1417,6 +2096,25 @@ type HTTPProxyList struct {
 	Items           []HTTPProxy `json:"items"`
 }
 
+// HTTP2KeepalivePolicy defines how HTTP/2 PING frames keep the
+// connections to a Service alive.
+type HTTP2KeepalivePolicy struct {
+	// Interval is how often a PING frame is sent on each connection.
+	// Duration is expressed in the Go [Duration format](https://godoc.org/time#ParseDuration).
+	// Valid time units are "ns", "us" (or "µs"), "ms", "s", "m", "h".
+	// +required
+	// +kubebuilder:validation:Pattern=`^(((\d*(\.\d*)?h)|(\d*(\.\d*)?m)|(\d*(\.\d*)?s)|(\d*(\.\d*)?ms)|(\d*(\.\d*)?us)|(\d*(\.\d*)?µs)|(\d*(\.\d*)?ns))+)$`
+	Interval string `json:"interval"`
+
+	// Timeout is how long to wait for the response to a PING frame
+	// before the connection is closed. It must be at least 1ms.
+	// Duration is expressed in the Go [Duration format](https://godoc.org/time#ParseDuration).
+	// Valid time units are "ns", "us" (or "µs"), "ms", "s", "m", "h".
+	// +required
+	// +kubebuilder:validation:Pattern=`^(((\d*(\.\d*)?h)|(\d*(\.\d*)?m)|(\d*(\.\d*)?s)|(\d*(\.\d*)?ms)|(\d*(\.\d*)?us)|(\d*(\.\d*)?µs)|(\d*(\.\d*)?ns))+)$`
+	Timeout string `json:"timeout"`
+}
+
 // SlowStartPolicy will gradually increase amount of traffic to a newly added endpoint.
 // It can be used only with RoundRobin and WeightedLeastRequest load balancing strategies.
 type SlowStartPolicy struct {
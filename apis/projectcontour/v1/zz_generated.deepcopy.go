@@ -25,6 +25,81 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ABTestPolicy) DeepCopyInto(out *ABTestPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ABTestPolicy.
+func (in *ABTestPolicy) DeepCopy() *ABTestPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ABTestPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccessLogPolicy) DeepCopyInto(out *AccessLogPolicy) {
+	*out = *in
+	if in.JSONFields != nil {
+		in, out := &in.JSONFields, &out.JSONFields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessLogPolicy.
+func (in *AccessLogPolicy) DeepCopy() *AccessLogPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(AccessLogPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AggregateService) DeepCopyInto(out *AggregateService) {
+	*out = *in
+	if in.Protocol != nil {
+		in, out := &in.Protocol, &out.Protocol
+		*out = new(string)
+		**out = **in
+	}
+	if in.UpstreamValidation != nil {
+		in, out := &in.UpstreamValidation, &out.UpstreamValidation
+		*out = new(UpstreamValidation)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AggregateService.
+func (in *AggregateService) DeepCopy() *AggregateService {
+	if in == nil {
+		return nil
+	}
+	out := new(AggregateService)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AliasSecret) DeepCopyInto(out *AliasSecret) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AliasSecret.
+func (in *AliasSecret) DeepCopy() *AliasSecret {
+	if in == nil {
+		return nil
+	}
+	out := new(AliasSecret)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AuthorizationPolicy) DeepCopyInto(out *AuthorizationPolicy) {
 	*out = *in
@@ -158,6 +233,22 @@ func (in *ClientCertificateDetails) DeepCopy() *ClientCertificateDetails {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConditionTransition) DeepCopyInto(out *ConditionTransition) {
+	*out = *in
+	in.TransitionTime.DeepCopyInto(&out.TransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConditionTransition.
+func (in *ConditionTransition) DeepCopy() *ConditionTransition {
+	if in == nil {
+		return nil
+	}
+	out := new(ConditionTransition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CookieDomainRewrite) DeepCopyInto(out *CookieDomainRewrite) {
 	*out = *in
@@ -249,6 +340,21 @@ func (in *DetailedCondition) DeepCopy() *DetailedCondition {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DirectResponseBodySource) DeepCopyInto(out *DirectResponseBodySource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DirectResponseBodySource.
+func (in *DirectResponseBodySource) DeepCopy() *DirectResponseBodySource {
+	if in == nil {
+		return nil
+	}
+	out := new(DirectResponseBodySource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DownstreamValidation) DeepCopyInto(out *DownstreamValidation) {
 	*out = *in
@@ -284,6 +390,21 @@ func (in *ExtensionServiceReference) DeepCopy() *ExtensionServiceReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FailoverService) DeepCopyInto(out *FailoverService) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FailoverService.
+func (in *FailoverService) DeepCopy() *FailoverService {
+	if in == nil {
+		return nil
+	}
+	out := new(FailoverService)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GenericKeyDescriptor) DeepCopyInto(out *GenericKeyDescriptor) {
 	*out = *in
@@ -321,9 +442,29 @@ func (in *GlobalRateLimitPolicy) DeepCopy() *GlobalRateLimitPolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTP2KeepalivePolicy) DeepCopyInto(out *HTTP2KeepalivePolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTP2KeepalivePolicy.
+func (in *HTTP2KeepalivePolicy) DeepCopy() *HTTP2KeepalivePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTP2KeepalivePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HTTPDirectResponsePolicy) DeepCopyInto(out *HTTPDirectResponsePolicy) {
 	*out = *in
+	if in.BodyFrom != nil {
+		in, out := &in.BodyFrom, &out.BodyFrom
+		*out = new(DirectResponseBodySource)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPDirectResponsePolicy.
@@ -435,6 +576,26 @@ func (in *HTTPProxyList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPProxyParameter) DeepCopyInto(out *HTTPProxyParameter) {
+	*out = *in
+	if in.Default != nil {
+		in, out := &in.Default, &out.Default
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPProxyParameter.
+func (in *HTTPProxyParameter) DeepCopy() *HTTPProxyParameter {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPProxyParameter)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HTTPProxySpec) DeepCopyInto(out *HTTPProxySpec) {
 	*out = *in
@@ -462,6 +623,13 @@ func (in *HTTPProxySpec) DeepCopyInto(out *HTTPProxySpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make([]HTTPProxyParameter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPProxySpec.
@@ -485,6 +653,13 @@ func (in *HTTPProxyStatus) DeepCopyInto(out *HTTPProxyStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ConditionHistory != nil {
+		in, out := &in.ConditionHistory, &out.ConditionHistory
+		*out = make([]ConditionTransition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPProxyStatus.
@@ -542,6 +717,26 @@ func (in *HTTPRequestRedirectPolicy) DeepCopy() *HTTPRequestRedirectPolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPSRedirectPolicy) DeepCopyInto(out *HTTPSRedirectPolicy) {
+	*out = *in
+	if in.ExcludedPathPrefixes != nil {
+		in, out := &in.ExcludedPathPrefixes, &out.ExcludedPathPrefixes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPSRedirectPolicy.
+func (in *HTTPSRedirectPolicy) DeepCopy() *HTTPSRedirectPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPSRedirectPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HTTPStatusRange) DeepCopyInto(out *HTTPStatusRange) {
 	*out = *in
@@ -627,6 +822,21 @@ func (in *HeadersPolicy) DeepCopy() *HeadersPolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HedgePolicy) DeepCopyInto(out *HedgePolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HedgePolicy.
+func (in *HedgePolicy) DeepCopy() *HedgePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(HedgePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IPFilterPolicy) DeepCopyInto(out *IPFilterPolicy) {
 	*out = *in
@@ -652,6 +862,16 @@ func (in *Include) DeepCopyInto(out *Include) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Priority != nil {
+		in, out := &in.Priority, &out.Priority
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make([]IncludeParameter, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Include.
@@ -664,6 +884,21 @@ func (in *Include) DeepCopy() *Include {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IncludeParameter) DeepCopyInto(out *IncludeParameter) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IncludeParameter.
+func (in *IncludeParameter) DeepCopy() *IncludeParameter {
+	if in == nil {
+		return nil
+	}
+	out := new(IncludeParameter)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *JWTProvider) DeepCopyInto(out *JWTProvider) {
 	*out = *in
@@ -742,6 +977,26 @@ func (in *LocalRateLimitPolicy) DeepCopy() *LocalRateLimitPolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceMode) DeepCopyInto(out *MaintenanceMode) {
+	*out = *in
+	if in.HealthCheckPathPrefixes != nil {
+		in, out := &in.HealthCheckPathPrefixes, &out.HealthCheckPathPrefixes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceMode.
+func (in *MaintenanceMode) DeepCopy() *MaintenanceMode {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceMode)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MatchCondition) DeepCopyInto(out *MatchCondition) {
 	*out = *in
@@ -755,6 +1010,23 @@ func (in *MatchCondition) DeepCopyInto(out *MatchCondition) {
 		*out = new(QueryParameterMatchCondition)
 		**out = **in
 	}
+	if in.Methods != nil {
+		in, out := &in.Methods, &out.Methods
+		*out = make([]HTTPMethod, len(*in))
+		copy(*out, *in)
+	}
+	if in.Not != nil {
+		in, out := &in.Not, &out.Not
+		*out = new(NegatedMatchCondition)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Or != nil {
+		in, out := &in.Or, &out.Or
+		*out = make([]OrMatchCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatchCondition.
@@ -767,6 +1039,61 @@ func (in *MatchCondition) DeepCopy() *MatchCondition {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NegatedMatchCondition) DeepCopyInto(out *NegatedMatchCondition) {
+	*out = *in
+	if in.Header != nil {
+		in, out := &in.Header, &out.Header
+		*out = new(HeaderMatchCondition)
+		**out = **in
+	}
+	if in.QueryParameter != nil {
+		in, out := &in.QueryParameter, &out.QueryParameter
+		*out = new(QueryParameterMatchCondition)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NegatedMatchCondition.
+func (in *NegatedMatchCondition) DeepCopy() *NegatedMatchCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(NegatedMatchCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OrMatchCondition) DeepCopyInto(out *OrMatchCondition) {
+	*out = *in
+	if in.Header != nil {
+		in, out := &in.Header, &out.Header
+		*out = new(HeaderMatchCondition)
+		**out = **in
+	}
+	if in.QueryParameter != nil {
+		in, out := &in.QueryParameter, &out.QueryParameter
+		*out = new(QueryParameterMatchCondition)
+		**out = **in
+	}
+	if in.Not != nil {
+		in, out := &in.Not, &out.Not
+		*out = new(NegatedMatchCondition)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OrMatchCondition.
+func (in *OrMatchCondition) DeepCopy() *OrMatchCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(OrMatchCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PathRewritePolicy) DeepCopyInto(out *PathRewritePolicy) {
 	*out = *in
@@ -1009,6 +1336,21 @@ func (in *RequestHeaderValueMatchDescriptor) DeepCopy() *RequestHeaderValueMatch
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetryBackOff) DeepCopyInto(out *RetryBackOff) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetryBackOff.
+func (in *RetryBackOff) DeepCopy() *RetryBackOff {
+	if in == nil {
+		return nil
+	}
+	out := new(RetryBackOff)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RetryPolicy) DeepCopyInto(out *RetryPolicy) {
 	*out = *in
@@ -1022,6 +1364,26 @@ func (in *RetryPolicy) DeepCopyInto(out *RetryPolicy) {
 		*out = make([]uint32, len(*in))
 		copy(*out, *in)
 	}
+	if in.RetriableHeaders != nil {
+		in, out := &in.RetriableHeaders, &out.RetriableHeaders
+		*out = make([]HeaderMatchCondition, len(*in))
+		copy(*out, *in)
+	}
+	if in.RetriableRequestHeaders != nil {
+		in, out := &in.RetriableRequestHeaders, &out.RetriableRequestHeaders
+		*out = make([]HeaderMatchCondition, len(*in))
+		copy(*out, *in)
+	}
+	if in.RetryHostPredicates != nil {
+		in, out := &in.RetryHostPredicates, &out.RetryHostPredicates
+		*out = make([]RetryHostPredicate, len(*in))
+		copy(*out, *in)
+	}
+	if in.RetryBackOff != nil {
+		in, out := &in.RetryBackOff, &out.RetryBackOff
+		*out = new(RetryBackOff)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetryPolicy.
@@ -1051,6 +1413,13 @@ func (in *Route) DeepCopyInto(out *Route) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.AggregateServices != nil {
+		in, out := &in.AggregateServices, &out.AggregateServices
+		*out = make([]AggregateService, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.AuthPolicy != nil {
 		in, out := &in.AuthPolicy, &out.AuthPolicy
 		*out = new(AuthorizationPolicy)
@@ -1059,13 +1428,18 @@ func (in *Route) DeepCopyInto(out *Route) {
 	if in.TimeoutPolicy != nil {
 		in, out := &in.TimeoutPolicy, &out.TimeoutPolicy
 		*out = new(TimeoutPolicy)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.RetryPolicy != nil {
 		in, out := &in.RetryPolicy, &out.RetryPolicy
 		*out = new(RetryPolicy)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.HedgePolicy != nil {
+		in, out := &in.HedgePolicy, &out.HedgePolicy
+		*out = new(HedgePolicy)
+		**out = **in
+	}
 	if in.HealthCheckPolicy != nil {
 		in, out := &in.HealthCheckPolicy, &out.HealthCheckPolicy
 		*out = new(HTTPHealthCheckPolicy)
@@ -1098,6 +1472,11 @@ func (in *Route) DeepCopyInto(out *Route) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ABTestPolicy != nil {
+		in, out := &in.ABTestPolicy, &out.ABTestPolicy
+		*out = new(ABTestPolicy)
+		**out = **in
+	}
 	if in.RateLimitPolicy != nil {
 		in, out := &in.RateLimitPolicy, &out.RateLimitPolicy
 		*out = new(RateLimitPolicy)
@@ -1111,7 +1490,7 @@ func (in *Route) DeepCopyInto(out *Route) {
 	if in.DirectResponsePolicy != nil {
 		in, out := &in.DirectResponsePolicy, &out.DirectResponsePolicy
 		*out = new(HTTPDirectResponsePolicy)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.InternalRedirectPolicy != nil {
 		in, out := &in.InternalRedirectPolicy, &out.InternalRedirectPolicy
@@ -1133,6 +1512,23 @@ func (in *Route) DeepCopyInto(out *Route) {
 		*out = make([]IPFilterPolicy, len(*in))
 		copy(*out, *in)
 	}
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = new(RouteSchedule)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RuntimeFraction != nil {
+		in, out := &in.RuntimeFraction, &out.RuntimeFraction
+		*out = new(RuntimeFraction)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Route.
@@ -1145,6 +1541,63 @@ func (in *Route) DeepCopy() *Route {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RouteSchedule) DeepCopyInto(out *RouteSchedule) {
+	*out = *in
+	if in.Windows != nil {
+		in, out := &in.Windows, &out.Windows
+		*out = make([]ScheduleWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouteSchedule.
+func (in *RouteSchedule) DeepCopy() *RouteSchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(RouteSchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RuntimeFraction) DeepCopyInto(out *RuntimeFraction) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RuntimeFraction.
+func (in *RuntimeFraction) DeepCopy() *RuntimeFraction {
+	if in == nil {
+		return nil
+	}
+	out := new(RuntimeFraction)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduleWindow) DeepCopyInto(out *ScheduleWindow) {
+	*out = *in
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]ScheduleDay, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleWindow.
+func (in *ScheduleWindow) DeepCopy() *ScheduleWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Service) DeepCopyInto(out *Service) {
 	*out = *in
@@ -1180,6 +1633,23 @@ func (in *Service) DeepCopyInto(out *Service) {
 		*out = new(SlowStartPolicy)
 		**out = **in
 	}
+	if in.Failover != nil {
+		in, out := &in.Failover, &out.Failover
+		*out = make([]FailoverService, len(*in))
+		copy(*out, *in)
+	}
+	if in.Subset != nil {
+		in, out := &in.Subset, &out.Subset
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.HTTP2KeepalivePolicy != nil {
+		in, out := &in.HTTP2KeepalivePolicy, &out.HTTP2KeepalivePolicy
+		*out = new(HTTP2KeepalivePolicy)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Service.
@@ -1302,6 +1772,11 @@ func (in *TLS) DeepCopyInto(out *TLS) {
 		*out = new(DownstreamValidation)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.AliasSecrets != nil {
+		in, out := &in.AliasSecrets, &out.AliasSecrets
+		*out = make([]AliasSecret, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLS.
@@ -1420,6 +1895,16 @@ func (in *TLSCertificateDelegationStatus) DeepCopy() *TLSCertificateDelegationSt
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TimeoutPolicy) DeepCopyInto(out *TimeoutPolicy) {
 	*out = *in
+	if in.ResponseReply != nil {
+		in, out := &in.ResponseReply, &out.ResponseReply
+		*out = new(TimeoutReply)
+		**out = **in
+	}
+	if in.IdleReply != nil {
+		in, out := &in.IdleReply, &out.IdleReply
+		*out = new(TimeoutReply)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TimeoutPolicy.
@@ -1432,6 +1917,21 @@ func (in *TimeoutPolicy) DeepCopy() *TimeoutPolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TimeoutReply) DeepCopyInto(out *TimeoutReply) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TimeoutReply.
+func (in *TimeoutReply) DeepCopy() *TimeoutReply {
+	if in == nil {
+		return nil
+	}
+	out := new(TimeoutReply)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UpstreamValidation) DeepCopyInto(out *UpstreamValidation) {
 	*out = *in
@@ -1450,6 +1950,11 @@ func (in *UpstreamValidation) DeepCopy() *UpstreamValidation {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VirtualHost) DeepCopyInto(out *VirtualHost) {
 	*out = *in
+	if in.Aliases != nil {
+		in, out := &in.Aliases, &out.Aliases
+		*out = make([]VirtualHostAlias, len(*in))
+		copy(*out, *in)
+	}
 	if in.TLS != nil {
 		in, out := &in.TLS, &out.TLS
 		*out = new(TLS)
@@ -1487,6 +1992,26 @@ func (in *VirtualHost) DeepCopyInto(out *VirtualHost) {
 		*out = make([]IPFilterPolicy, len(*in))
 		copy(*out, *in)
 	}
+	if in.HTTPSRedirectPolicy != nil {
+		in, out := &in.HTTPSRedirectPolicy, &out.HTTPSRedirectPolicy
+		*out = new(HTTPSRedirectPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HTTP2MaxConcurrentStreams != nil {
+		in, out := &in.HTTP2MaxConcurrentStreams, &out.HTTP2MaxConcurrentStreams
+		*out = new(uint32)
+		**out = **in
+	}
+	if in.MaintenanceMode != nil {
+		in, out := &in.MaintenanceMode, &out.MaintenanceMode
+		*out = new(MaintenanceMode)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AccessLogPolicy != nil {
+		in, out := &in.AccessLogPolicy, &out.AccessLogPolicy
+		*out = new(AccessLogPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualHost.
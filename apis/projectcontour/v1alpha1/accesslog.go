@@ -15,7 +15,9 @@ package v1alpha1
 
 import (
 	"fmt"
+	"math"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -59,6 +61,9 @@ var jsonFields = map[string]string{
 	"path":                  "%REQ(X-ENVOY-ORIGINAL-PATH?:PATH)%",
 	"request_id":            "%REQ(X-REQUEST-ID)%",
 	"uber_trace_id":         "%REQ(UBER-TRACE-ID)%",
+	"upstream_namespace":    "%UPSTREAM_METADATA(projectcontour.io:namespace)%",
+	"upstream_pod":          "%UPSTREAM_METADATA(projectcontour.io:pod)%",
+	"upstream_service":      "%UPSTREAM_METADATA(projectcontour.io:service)%",
 	"upstream_service_time": "%RESP(X-ENVOY-UPSTREAM-SERVICE-TIME)%",
 	"user_agent":            "%REQ(USER-AGENT)%",
 	"x_forwarded_for":       "%REQ(X-FORWARDED-FOR)%",
@@ -161,7 +166,8 @@ var envoyComplexOperators = map[string]struct {
 		argsOptional:       true,
 		truncateDisallowed: true,
 	},
-	"TRAILER": {},
+	"TRAILER":           {},
+	"UPSTREAM_METADATA": {},
 }
 
 // AccessLogType is the name of a supported access logging mechanism.
@@ -185,15 +191,115 @@ const (
 	JSONAccessLog AccessLogType = "json"
 )
 
+// AccessLogFormatPreset is the name of a predefined access log format.
+type AccessLogFormatPreset string
+
+func (p AccessLogFormatPreset) Validate() error {
+	if p == "" {
+		return nil
+	}
+	if _, ok := accessLogFormatPresets[p]; !ok {
+		return fmt.Errorf("invalid access log format preset %q", p)
+	}
+	return nil
+}
+
+const (
+	// Log in the Apache HTTP Server combined log format.
+	ApacheCombinedAccessLogPreset AccessLogFormatPreset = "apache-combined"
+	// Log in the W3C extended log format, with the fields
+	// date, time, c-ip, cs-method, cs-uri, sc-status, sc-bytes,
+	// cs-bytes, time-taken, cs-host, cs(User-Agent) and cs(Referer).
+	W3CAccessLogPreset AccessLogFormatPreset = "w3c"
+	// Log JSON objects with the fields named as in the Elastic Common Schema.
+	JSONECSAccessLogPreset AccessLogFormatPreset = "json-ecs"
+)
+
+// accessLogFormat is the access log configuration that a preset expands to.
+type accessLogFormat struct {
+	format       AccessLogType
+	formatString string
+	jsonFields   AccessLogJSONFields
+}
+
+// accessLogFormatPresets are the access log configurations of each preset.
+var accessLogFormatPresets = map[AccessLogFormatPreset]accessLogFormat{
+	ApacheCombinedAccessLogPreset: {
+		format:       EnvoyAccessLog,
+		formatString: `%DOWNSTREAM_REMOTE_ADDRESS_WITHOUT_PORT% - - [%START_TIME(%d/%b/%Y:%H:%M:%S %z)%] "%REQ(:METHOD)% %REQ(X-ENVOY-ORIGINAL-PATH?:PATH)% %PROTOCOL%" %RESPONSE_CODE% %BYTES_SENT% "%REQ(REFERER)%" "%REQ(USER-AGENT)%"` + "\n",
+	},
+	W3CAccessLogPreset: {
+		format:       EnvoyAccessLog,
+		formatString: `%START_TIME(%Y-%m-%d %H:%M:%S)% %DOWNSTREAM_REMOTE_ADDRESS_WITHOUT_PORT% %REQ(:METHOD)% %REQ(X-ENVOY-ORIGINAL-PATH?:PATH)% %RESPONSE_CODE% %BYTES_SENT% %BYTES_RECEIVED% %DURATION% %REQ(:AUTHORITY)% "%REQ(USER-AGENT)%" "%REQ(REFERER)%"` + "\n",
+	},
+	JSONECSAccessLogPreset: {
+		format: JSONAccessLog,
+		// Envoy only reports durations in milliseconds while ECS
+		// expects nanoseconds, so they, and other values that ECS
+		// has no field for, are logged in the envoy namespace.
+		jsonFields: AccessLogJSONFields{
+			"@timestamp",
+			"ecs.version=8.11.0",
+			"client.ip=%DOWNSTREAM_REMOTE_ADDRESS_WITHOUT_PORT%",
+			"client.port=%DOWNSTREAM_REMOTE_PORT%",
+			"http.request.bytes=%BYTES_RECEIVED%",
+			"http.request.id=%REQ(X-REQUEST-ID)%",
+			"http.request.method=%REQ(:METHOD)%",
+			"http.request.referrer=%REQ(REFERER)%",
+			"http.response.bytes=%BYTES_SENT%",
+			"http.response.status_code=%RESPONSE_CODE%",
+			"tls.client.server_name=%REQUESTED_SERVER_NAME%",
+			"url.domain=%REQ(:AUTHORITY)%",
+			"url.original=%REQ(X-ENVOY-ORIGINAL-PATH?:PATH)%",
+			"user_agent.original=%REQ(USER-AGENT)%",
+			"envoy.duration_ms=%DURATION%",
+			"envoy.protocol=%PROTOCOL%",
+			"envoy.response_flags=%RESPONSE_FLAGS%",
+			"envoy.upstream.cluster=%UPSTREAM_CLUSTER%",
+			"envoy.upstream.host=%UPSTREAM_HOST%",
+		},
+	},
+}
+
+// AccessLogJSONFields are the fields that JSON logging outputs. A field
+// is either the name of a known field or a "name=value" pair. Dots in a
+// name nest the field in JSON objects, e.g. "request.method", and a
+// ":number" or ":bool" suffix of a name types the field, e.g.
+// "duration:number" or "sampled:bool=true".
 type AccessLogJSONFields []string
 
+// AccessLogJSONFieldType is the JSON type that a field is logged as.
+type AccessLogJSONFieldType string
+
+const (
+	// Log the field as a JSON number.
+	AccessLogJSONNumber AccessLogJSONFieldType = "number"
+	// Log the field as a JSON boolean.
+	AccessLogJSONBool AccessLogJSONFieldType = "bool"
+)
+
 func (a AccessLogJSONFields) Validate() error {
+	fieldMap := a.AsFieldMap()
 
-	for key, val := range a.AsFieldMap() {
+	for key, val := range fieldMap {
 		if val == "" {
 			return fmt.Errorf("invalid JSON log field name %s", key)
 		}
 
+		path := strings.Split(key, ".")
+		for i, name := range path {
+			if name == "" {
+				return fmt.Errorf("invalid JSON log field name %s: empty object name", key)
+			}
+			if i == 0 {
+				continue
+			}
+			parent := strings.Join(path[:i], ".")
+			if _, ok := fieldMap[parent]; ok {
+				return fmt.Errorf("invalid JSON log field name %s: field %s is not an object", key, parent)
+			}
+		}
+
 		if jsonFields[key] == val {
 			continue
 		}
@@ -204,39 +310,85 @@ func (a AccessLogJSONFields) Validate() error {
 		}
 	}
 
+	for key, fieldType := range a.FieldTypes() {
+		val := fieldMap[key]
+
+		switch fieldType {
+		case AccessLogJSONNumber:
+			if f, err := strconv.ParseFloat(val, 64); err == nil && !math.IsNaN(f) && !math.IsInf(f, 0) {
+				continue
+			}
+		case AccessLogJSONBool:
+			if _, err := strconv.ParseBool(val); err == nil {
+				continue
+			}
+		default:
+			return fmt.Errorf("invalid JSON log field type %q of field %s", fieldType, key)
+		}
+
+		// Envoy logs the value of a single command operator with the
+		// type of the operator, but any other text makes it a string.
+		if loc := commandOperatorRegexp.FindStringIndex(val); loc == nil || loc[0] != 0 || loc[1] != len(val) {
+			return fmt.Errorf("invalid JSON log field %s: a %s must be a literal or a single Envoy operator", key, fieldType)
+		}
+	}
+
 	return nil
 }
 
 func (a AccessLogJSONFields) AsFieldMap() map[string]string {
 	fieldMap := map[string]string{}
 
-	for _, val := range a {
-		parts := strings.SplitN(val, "=", 2)
+	for _, field := range a {
+		key, _, val, hasValue := splitJSONField(field)
 
-		if len(parts) == 1 {
-			operator, foundInFieldMapping := jsonFields[val]
-			_, isSimpleOperator := envoySimpleOperators[strings.ToUpper(val)]
+		if !hasValue {
+			operator, foundInFieldMapping := jsonFields[key]
+			_, isSimpleOperator := envoySimpleOperators[strings.ToUpper(key)]
 
 			switch {
 			case isSimpleOperator && !foundInFieldMapping:
 				// Operator name is known to be simple, upcase and wrap it in percents.
-				fieldMap[val] = fmt.Sprintf("%%%s%%", strings.ToUpper(val))
+				fieldMap[key] = fmt.Sprintf("%%%s%%", strings.ToUpper(key))
 			case foundInFieldMapping:
 				// Operator name has a known mapping, store the result of the mapping.
-				fieldMap[val] = operator
+				fieldMap[key] = operator
 			default:
 				// Operator name not found, save as emptystring and let validation catch it later.
-				fieldMap[val] = ""
+				fieldMap[key] = ""
 			}
 		} else {
 			// Value is a full key:value pair, store it as is.
-			fieldMap[parts[0]] = parts[1]
+			fieldMap[key] = val
 		}
 	}
 
 	return fieldMap
 }
 
+// FieldTypes returns the types of the typed fields, keyed by field name.
+func (a AccessLogJSONFields) FieldTypes() map[string]AccessLogJSONFieldType {
+	fieldTypes := map[string]AccessLogJSONFieldType{}
+
+	for _, field := range a {
+		if key, fieldType, _, _ := splitJSONField(field); fieldType != "" {
+			fieldTypes[key] = fieldType
+		}
+	}
+
+	return fieldTypes
+}
+
+// splitJSONField splits a JSON field into its name, its
+// optional type and its optional value.
+func splitJSONField(field string) (key string, fieldType AccessLogJSONFieldType, val string, hasValue bool) {
+	key, val, hasValue = strings.Cut(field, "=")
+	if i := strings.LastIndex(key, ":"); i >= 0 {
+		key, fieldType = key[:i], AccessLogJSONFieldType(key[i+1:])
+	}
+	return key, fieldType, val, hasValue
+}
+
 type AccessLogLevel string
 
 func (a AccessLogLevel) Validate() error {
@@ -28,6 +28,15 @@ func TestValidateAccessLogType(t *testing.T) {
 	assert.NoError(t, v1alpha1.JSONAccessLog.Validate())
 }
 
+func TestValidateAccessLogFormatPreset(t *testing.T) {
+	assert.Error(t, v1alpha1.AccessLogFormatPreset("foo").Validate())
+
+	assert.NoError(t, v1alpha1.AccessLogFormatPreset("").Validate())
+	assert.NoError(t, v1alpha1.ApacheCombinedAccessLogPreset.Validate())
+	assert.NoError(t, v1alpha1.W3CAccessLogPreset.Validate())
+	assert.NoError(t, v1alpha1.JSONECSAccessLogPreset.Validate())
+}
+
 func TestValidateAccessLogLevel(t *testing.T) {
 	assert.Error(t, v1alpha1.AccessLogLevel("").Validate())
 	assert.Error(t, v1alpha1.AccessLogLevel("foo").Validate())
@@ -56,6 +65,14 @@ func TestValidateAccessLogJSONFields(t *testing.T) {
 		{"invalid=%REQ_WITHOUT_QUERY%"},
 		{"invalid=%ENVIRONMENT%"},
 		{"@timestamp", "invalid=%START_TIME(%s.%6f):10%"},
+		{"request=%REQ(:PATH)%", "request.method=%REQ(:METHOD)%"},
+		{"request..method=%REQ(:METHOD)%"},
+		{"request.=%REQ(:METHOD)%"},
+		{"duration:integer"},
+		{"duration:number=%DURATION%ms"},
+		{"duration:number=%DURATION%%RESPONSE_DURATION%"},
+		{"rate:number=NaN"},
+		{"sampled:bool=maybe"},
 	}
 
 	for _, c := range errorCases {
@@ -79,6 +96,10 @@ func TestValidateAccessLogJSONFields(t *testing.T) {
 		{"dog=pug", "cat=black"},
 		{"grpc_status"},
 		{"grpc_status_number"},
+		{"request.method=%REQ(:METHOD)%", "request.path=%REQ(:PATH)%", "response.code=%RESPONSE_CODE%"},
+		{"duration:number", "response_code:number=%RESPONSE_CODE%"},
+		{"sample.rate:number=0.5", "sampled:bool=true"},
+		{"upstream_pod", "upstream_namespace", "upstream_service"},
 	}
 
 	for _, c := range successCases {
@@ -86,6 +107,32 @@ func TestValidateAccessLogJSONFields(t *testing.T) {
 	}
 }
 
+func TestAccessLogJSONFieldTypes(t *testing.T) {
+	fields := v1alpha1.AccessLogJSONFields{
+		"@timestamp",
+		"duration:number",
+		"request.method=%REQ(:METHOD)%",
+		"sample.rate:number=0.5",
+		"sampled:bool=true",
+		"url=http://example.com:8080/",
+	}
+
+	assert.Equal(t, map[string]string{
+		"@timestamp":     "%START_TIME%",
+		"duration":       "%DURATION%",
+		"request.method": "%REQ(:METHOD)%",
+		"sample.rate":    "0.5",
+		"sampled":        "true",
+		"url":            "http://example.com:8080/",
+	}, fields.AsFieldMap())
+
+	assert.Equal(t, map[string]v1alpha1.AccessLogJSONFieldType{
+		"duration":    v1alpha1.AccessLogJSONNumber,
+		"sample.rate": v1alpha1.AccessLogJSONNumber,
+		"sampled":     v1alpha1.AccessLogJSONBool,
+	}, fields.FieldTypes())
+}
+
 func TestAccessLogFormatString(t *testing.T) {
 	errorCases := []string{
 		"%REQ=dog%\n",
@@ -100,6 +147,7 @@ func TestAccessLogFormatString(t *testing.T) {
 		"%START_TIME(%s.%6f):10%\n",
 		"no newline at the end",
 		"%METADATA%\n",
+		"%UPSTREAM_METADATA%\n",
 	}
 
 	for _, c := range errorCases {
@@ -122,6 +170,7 @@ func TestAccessLogFormatString(t *testing.T) {
 		"%GRPC_STATUS%\n",
 		"%GRPC_STATUS_NUMBER%\n",
 		"%METADATA(ROUTE:com.test.my_filter:test_key):20%\n",
+		"%UPSTREAM_METADATA(projectcontour.io:pod)%\n",
 		"%UPSTREAM_PROTOCOL%\n",
 		"%UPSTREAM_PEER_SUBJECT%\n",
 		"%UPSTREAM_PEER_ISSUER%\n",
@@ -82,8 +82,57 @@ type ContourConfigurationSpec struct {
 	// +optional
 	Metrics *MetricsConfig `json:"metrics,omitempty"`
 
+	// HTTPProxyMetrics enables the metrics of the requests to the
+	// upstreams of each HTTPProxy, which Contour aggregates from the
+	// Envoy cluster stats and serves on its metrics endpoint.
+	// If not specified, they are disabled.
+	// +optional
+	HTTPProxyMetrics *HTTPProxyMetricsConfig `json:"httpProxyMetrics,omitempty"`
+
 	// Tracing defines properties for exporting trace data to OpenTelemetry.
 	Tracing *TracingConfig `json:"tracing,omitempty"`
+
+	// Capture defines where requests and responses are captured to
+	// for HTTPProxy routes that enable capture.
+	// +optional
+	Capture *CaptureConfig `json:"capture,omitempty"`
+
+	// SecretBackend defines where Envoy gets the certificates and keys
+	// that it serves for TLS virtual hosts and Gateway listeners from.
+	// If not specified, they are read from Kubernetes Secrets.
+	// +optional
+	SecretBackend *SecretBackendConfig `json:"secretBackend,omitempty"`
+
+	// SecretEncryption enables the encryption of the private keys of
+	// the Kubernetes Secrets that Contour holds in memory, to reduce
+	// their exposure in heap dumps of the Contour process.
+	// If not specified, private keys are held in plaintext.
+	// +optional
+	SecretEncryption *SecretEncryptionConfig `json:"secretEncryption,omitempty"`
+
+	// HTTPSRedirect customizes how HTTP requests to routes that require
+	// TLS are redirected to HTTPS. HTTPProxies can replace it for their
+	// virtual host.
+	// +optional
+	HTTPSRedirect *HTTPSRedirectConfig `json:"httpsRedirect,omitempty"`
+
+	// RuntimeFlags are the percentages of the runtime flags of
+	// HTTPProxy routes, keyed by flag name. They are served to
+	// Envoy over RTDS and override the default percentages of
+	// the routes.
+	// +optional
+	RuntimeFlags map[string]uint32 `json:"runtimeFlags,omitempty"`
+
+	// FeatureGates enables or disables the feature gates of the
+	// experimental subsystems of Contour, keyed by gate name.
+	// Gates that are not specified keep their default state.
+	// +optional
+	FeatureGates map[string]bool `json:"featureGates,omitempty"`
+
+	// LeaderElection defines how the replicas of Contour elect
+	// the leader that writes the status of resources.
+	// +optional
+	LeaderElection *LeaderElectionConfig `json:"leaderElection,omitempty"`
 }
 
 // XDSServerType is the type of xDS server implementation.
@@ -124,6 +173,52 @@ type XDSServerConfig struct {
 	// Contour's default is { caFile: "/certs/ca.crt", certFile: "/certs/tls.cert", keyFile: "/certs/tls.key", insecure: false }.
 	// +optional
 	TLS *TLS `json:"tls,omitempty"`
+
+	// Authorization configures how Envoy nodes connecting to the xDS
+	// server are authorized. Nodes that are not authorized are refused
+	// the xDS configuration.
+	//
+	// Contour's default is to serve any node that can connect.
+	// +optional
+	Authorization *XDSServerAuthorization `json:"authorization,omitempty"`
+}
+
+// XDSServerAuthorization configures how Envoy nodes connecting to the
+// xDS server are authorized. A node is authorized if it satisfies any
+// of the configured methods.
+type XDSServerAuthorization struct {
+	// AllowedSubjectAltNames is the list of Subject Alternative Names
+	// (DNS names, URIs, IP addresses or email addresses) that authorize
+	// a node when present in its client certificate. Requires the xDS
+	// server to be served over TLS.
+	// +optional
+	AllowedSubjectAltNames []string `json:"allowedSubjectAltNames,omitempty"`
+
+	// JWT authorizes nodes that present a valid JWT in the
+	// "contour-xds-token" field of their node metadata, as set
+	// by `contour bootstrap --xds-token-file`. Envoy presents
+	// the same token on every reconnection for the lifetime of
+	// its pod, so the token must be long-lived.
+	// +optional
+	JWT *XDSServerJWTAuthorization `json:"jwt,omitempty"`
+}
+
+// XDSServerJWTAuthorization configures how the JWTs presented
+// by Envoy nodes are verified.
+type XDSServerJWTAuthorization struct {
+	// KeyFile is the name of a PEM file holding the public keys or
+	// certificates used to verify the JWT signatures. The file is
+	// re-read when nodes connect, so keys can be rotated in place.
+	// +kubebuilder:validation:MinLength=1
+	KeyFile string `json:"keyFile"`
+
+	// Issuer is the required "iss" claim of the JWTs.
+	// +optional
+	Issuer string `json:"issuer,omitempty"`
+
+	// Audiences is the list of accepted "aud" claims of the JWTs.
+	// +optional
+	Audiences []string `json:"audiences,omitempty"`
 }
 
 // GatewayConfig holds the config for Gateway API controllers.
@@ -186,6 +281,38 @@ type HealthConfig struct {
 	Port int `json:"port,omitempty"`
 }
 
+// EnvoyHealthListener customizes the endpoints of the health listener of Envoy.
+type EnvoyHealthListener struct {
+	// ReadinessPath is the path of the readiness endpoint, which
+	// reports whether Envoy is initialized and serving traffic.
+	//
+	// Contour's default is "/ready".
+	// +kubebuilder:validation:Pattern=`^/[^?#\s]*$`
+	// +optional
+	ReadinessPath *string `json:"readinessPath,omitempty"`
+
+	// Liveness adds a liveness endpoint to the health listener.
+	// If not specified, there is no liveness endpoint.
+	// +optional
+	Liveness *EnvoyLivenessEndpoint `json:"liveness,omitempty"`
+}
+
+// EnvoyLivenessEndpoint defines the liveness endpoint of Envoy, which
+// fails while Envoy is draining, or while one of the given clusters
+// has too few healthy endpoints.
+type EnvoyLivenessEndpoint struct {
+	// Path is the path of the liveness endpoint.
+	// It must differ from the readiness path.
+	// +kubebuilder:validation:Pattern=`^/[^?#\s]*$`
+	Path string `json:"path"`
+
+	// ClusterMinHealthyPercentages are the minimum percentages of
+	// healthy endpoints of Envoy clusters, keyed by cluster name,
+	// below which the liveness endpoint fails.
+	// +optional
+	ClusterMinHealthyPercentages map[string]uint32 `json:"clusterMinHealthyPercentages,omitempty"`
+}
+
 // MetricsConfig defines the metrics endpoint.
 type MetricsConfig struct {
 	// Defines the metrics address interface.
@@ -202,6 +329,26 @@ type MetricsConfig struct {
 	// Metrics and health endpoints cannot have same port number when metrics is served over HTTPS.
 	// +optional
 	TLS *MetricsTLS `json:"tls,omitempty"`
+
+	// BearerTokenFile is the file holding the bearer token that
+	// scrapers must present in the Authorization header. The file
+	// is read by Contour, also for the metrics of Envoy, whose
+	// configuration then holds the token.
+	// +optional
+	BearerTokenFile string `json:"bearerTokenFile,omitempty"`
+}
+
+// HTTPProxyMetricsConfig defines how Contour aggregates the
+// metrics of HTTPProxies from the stats of the Envoys.
+type HTTPProxyMetricsConfig struct {
+	// ScrapeInterval is how often Contour scrapes the stats of
+	// the Envoys. The Envoy metrics endpoint must be served
+	// over HTTP.
+	//
+	// Contour's default is 30s.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^(((\d*(\.\d*)?h)|(\d*(\.\d*)?m)|(\d*(\.\d*)?s)|(\d*(\.\d*)?ms)|(\d*(\.\d*)?us)|(\d*(\.\d*)?µs)|(\d*(\.\d*)?ns))+)$`
+	ScrapeInterval *string `json:"scrapeInterval,omitempty"`
 }
 
 // TLS holds TLS file config details.
@@ -217,6 +364,12 @@ type MetricsTLS struct {
 	// Client key filename.
 	// +optional
 	KeyFile string `json:"keyFile,omitempty"`
+
+	// AllowedSubjectAltNames is the list of Subject Alternative Names
+	// (DNS names, URIs, IP addresses or email addresses) of which the
+	// client certificate must present one. Requires CAFile.
+	// +optional
+	AllowedSubjectAltNames []string `json:"allowedSubjectAltNames,omitempty"`
 }
 
 // HTTPVersionType is the name of a supported HTTP version.
@@ -260,6 +413,11 @@ type EnvoyConfig struct {
 	// +optional
 	Health *HealthConfig `json:"health,omitempty"`
 
+	// HealthListener customizes the endpoints that the health
+	// listener of Envoy serves.
+	// +optional
+	HealthListener *EnvoyHealthListener `json:"healthListener,omitempty"`
+
 	// Metrics defines the endpoint Envoy uses to serve metrics.
 	//
 	// Contour's default is { address: "0.0.0.0", port: 8002 }.
@@ -301,8 +459,95 @@ type EnvoyConfig struct {
 	// Network holds various configurable Envoy network values.
 	// +optional
 	Network *NetworkParameters `json:"network,omitempty"`
+
+	// Compression holds the settings of the response compression
+	// of the HTTP connection managers.
+	// +optional
+	Compression *EnvoyCompression `json:"compression,omitempty"`
+
+	// ResponseMap customizes the replies that Envoy generates itself,
+	// e.g. when no route matches or no upstream is healthy, by their
+	// status code. Responses of upstreams are not changed.
+	// +optional
+	ResponseMap []ResponseMapping `json:"responseMap,omitempty"`
+}
+
+// ResponseMapping customizes the replies that Envoy
+// generates with a given status code.
+type ResponseMapping struct {
+	// StatusCode is the status code of the replies that are customized.
+	// Each status code can only be mapped once.
+	//
+	// +kubebuilder:validation:Minimum=200
+	// +kubebuilder:validation:Maximum=599
+	StatusCode int `json:"statusCode"`
+
+	// RewriteStatusCode replaces the status code of the replies.
+	// If not specified, the status code is kept.
+	//
+	// +kubebuilder:validation:Minimum=200
+	// +kubebuilder:validation:Maximum=599
+	// +optional
+	RewriteStatusCode int `json:"rewriteStatusCode,omitempty"`
+
+	// Body replaces the body of the replies. It's available
+	// as %LOCAL_REPLY_BODY% in the JSON format.
+	// If not specified, the body is kept.
+	// +optional
+	Body string `json:"body,omitempty"`
+
+	// ContentType is the content type of the replies.
+	// If not specified, text/plain is used for bodies,
+	// and application/json for the JSON format.
+	// +optional
+	ContentType string `json:"contentType,omitempty"`
+
+	// JSONFormat renders the replies as JSON objects, whose values are
+	// Envoy command operators, e.g. %RESPONSE_CODE% or %LOCAL_REPLY_BODY%.
+	// See https://www.envoyproxy.io/docs/envoy/latest/configuration/observability/access_log/usage#command-operators
+	// for more information.
+	// +optional
+	JSONFormat map[string]string `json:"jsonFormat,omitempty"`
 }
 
+// EnvoyCompression holds the settings of the response compression.
+type EnvoyCompression struct {
+	// Algorithm selects the compression algorithm of responses.
+	//
+	// Values: `gzip` (default), `brotli`, `zstd`, `disabled`.
+	//
+	// Other values will produce an error.
+	// +optional
+	Algorithm CompressionAlgorithm `json:"algorithm,omitempty"`
+
+	// MinContentLength is the minimum length of the responses that
+	// are compressed, in bytes. If not specified, Envoy's default of
+	// 30 bytes is used.
+	// +optional
+	MinContentLength *uint32 `json:"minContentLength,omitempty"`
+
+	// ContentTypes are the content types of the responses that are
+	// compressed. If not specified, text, JavaScript, JSON, XML, SVG
+	// and gRPC-Web responses are compressed.
+	// +optional
+	ContentTypes []string `json:"contentTypes,omitempty"`
+}
+
+// CompressionAlgorithm is the compression algorithm of responses.
+type CompressionAlgorithm string
+
+const (
+	// Compress responses with gzip.
+	// This is the default value.
+	GzipCompression CompressionAlgorithm = "gzip"
+	// Compress responses with brotli.
+	BrotliCompression CompressionAlgorithm = "brotli"
+	// Compress responses with zstd.
+	ZstdCompression CompressionAlgorithm = "zstd"
+	// Do not compress responses.
+	DisabledCompression CompressionAlgorithm = "disabled"
+)
+
 // DebugConfig contains Contour specific troubleshooting options.
 type DebugConfig struct {
 	// Defines the Contour debug address interface.
@@ -348,8 +593,9 @@ type EnvoyListenerConfig struct {
 	// When configured as overwrite, overwrites any Server header with "envoy".
 	// When configured as append_if_absent, if a Server header is present, pass it through, otherwise set it to "envoy".
 	// When configured as pass_through, pass through the value of the Server header, and do not append a header if none is present.
+	// When configured as remove, remove any Server header and do not append one.
 	//
-	// Values: `overwrite` (default), `append_if_absent`, `pass_through`
+	// Values: `overwrite` (default), `append_if_absent`, `pass_through`, `remove`
 	//
 	// Other values will produce an error.
 	// Contour's default is overwrite.
@@ -366,6 +612,33 @@ type EnvoyListenerConfig struct {
 	// +optional
 	ConnectionBalancer string `json:"connectionBalancer,omitempty"`
 
+	// DrainType defines when Envoy drains the connections of a listener.
+	// When configured as default, connections are drained when the listener
+	// is modified or removed, and when Envoy is shutting down, i.e. on hot
+	// restart or after its health check is failed by the shutdown manager.
+	// When configured as modify-only, connections are only drained when the
+	// listener is modified or removed.
+	// See https://www.envoyproxy.io/docs/envoy/latest/api-v3/config/listener/v3/listener.proto#envoy-v3-api-enum-config-listener-v3-listener-draintype
+	// for more information.
+	//
+	// Values: `default` (default), `modify-only`.
+	//
+	// Other values will produce an error.
+	// Contour's default is default.
+	// +optional
+	DrainType ListenerDrainType `json:"drainType,omitempty"`
+
+	// RemovalDelay defines how long a listener that is no longer needed,
+	// e.g. because the port of a Gateway listener changed, keeps serving
+	// after it's replaced. This gives Envoy time to warm the replacement
+	// listener and start serving on it before the old listener is drained.
+	// Listeners that are updated in place, e.g. when their filter chains
+	// change, are always warmed by Envoy before replacing the old ones.
+	// Must be a valid Go duration string. If not specified, listeners are
+	// removed immediately.
+	// +optional
+	RemovalDelay *string `json:"removalDelay,omitempty"`
+
 	// Defines the maximum requests for downstream connections. If not specified, there is no limit.
 	// see https://www.envoyproxy.io/docs/envoy/latest/api-v3/config/core/v3/protocol.proto#envoy-v3-api-msg-config-core-v3-httpprotocoloptions
 	// for more information.
@@ -383,11 +656,237 @@ type EnvoyListenerConfig struct {
 	// +optional
 	PerConnectionBufferLimitBytes *uint32 `json:"per-connection-buffer-limit-bytes,omitempty"`
 
+	// HTTP2 holds the settings of downstream HTTP/2 connections, which
+	// protect Envoy against misbehaving HTTP/2 clients.
+	// +optional
+	HTTP2 *EnvoyHTTP2 `json:"http2,omitempty"`
+
+	// SocketOptions holds the socket options of the listeners, which
+	// apply to their downstream connections.
+	// +optional
+	SocketOptions *EnvoySocketOptions `json:"socketOptions,omitempty"`
+
+	// HTTPFilters are additional HTTP filters that are added to the
+	// filter chains of the HTTP connection managers, to enable Envoy
+	// filters that Contour does not configure. The filters are added
+	// in order at their positions.
+	// +optional
+	HTTPFilters []HTTPFilter `json:"httpFilters,omitempty"`
+
+	// HTTPConnectionManager holds the settings of the HTTP
+	// connection managers of the listeners.
+	// +optional
+	HTTPConnectionManager *EnvoyHTTPConnectionManager `json:"httpConnectionManager,omitempty"`
+
 	// TLS holds various configurable Envoy TLS listener values.
 	// +optional
 	TLS *EnvoyTLS `json:"tls,omitempty"`
 }
 
+// EnvoyHTTPConnectionManager holds the settings of the HTTP connection
+// managers. The Server header transformation is set with the
+// ServerHeaderTransformation of the listener configuration.
+type EnvoyHTTPConnectionManager struct {
+	// ServerName is the value of the Server header that Envoy sets
+	// on responses, as selected by the Server header transformation.
+	//
+	// Contour's default is "envoy".
+	// +kubebuilder:validation:MinLength=1
+	// +optional
+	ServerName *string `json:"serverName,omitempty"`
+
+	// PreserveExternalRequestID sets whether the x-request-id header
+	// of requests from external clients is passed through instead of
+	// being replaced with a new request ID.
+	//
+	// Contour's default is true.
+	// +optional
+	PreserveExternalRequestID *bool `json:"preserveExternalRequestID,omitempty"`
+
+	// NormalizePath sets whether the paths of requests are normalized
+	// according to RFC 3986, e.g. by resolving "." and ".." segments,
+	// before they are matched against routes.
+	//
+	// Contour's default is true.
+	// +optional
+	NormalizePath *bool `json:"normalizePath,omitempty"`
+
+	// Via is the value that Envoy appends to the Via header of
+	// requests and responses. If not specified, the Via header
+	// is passed through unchanged.
+	//
+	// +kubebuilder:validation:MinLength=1
+	// +optional
+	Via *string `json:"via,omitempty"`
+}
+
+// HTTPFilter is an additional HTTP filter of the HTTP connection managers.
+type HTTPFilter struct {
+	// Name is the name of the filter, which must be unique
+	// among the additional filters.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Position defines where the filter is added to the filter chain.
+	// When configured as before-router, the filter is added right before
+	// the router filter, i.e. after the filters that Contour configures.
+	// When configured as after-cors, the filter is added right after the
+	// CORS filter.
+	//
+	// Values: `before-router` (default), `after-cors`.
+	//
+	// Other values will produce an error.
+	// +optional
+	Position HTTPFilterPosition `json:"position,omitempty"`
+
+	// TypedConfig is the YAML or JSON representation of the typed
+	// configuration of the filter, including its `@type`, e.g.
+	// `type.googleapis.com/envoy.extensions.filters.http.buffer.v3.Buffer`.
+	// It is passed through to Envoy as is, and must be a valid
+	// configuration of an HTTP filter that Contour knows the type of.
+	// +kubebuilder:validation:MinLength=1
+	TypedConfig string `json:"typedConfig"`
+}
+
+// HTTPFilterPosition defines where an additional HTTP filter
+// is added to the filter chain.
+type HTTPFilterPosition string
+
+const (
+	// Add the filter right before the router filter.
+	// This is the default value.
+	BeforeRouterHTTPFilterPosition HTTPFilterPosition = "before-router"
+	// Add the filter right after the CORS filter.
+	AfterCORSHTTPFilterPosition HTTPFilterPosition = "after-cors"
+)
+
+// EnvoySocketOptions holds the socket options of the listeners.
+type EnvoySocketOptions struct {
+	// TCPKeepalive holds the settings of the TCP keepalive probes.
+	// +optional
+	TCPKeepalive *TCPKeepaliveConfig `json:"tcpKeepalive,omitempty"`
+
+	// TOS is the value of the IPv4 Type of Service field, including
+	// the 6 bit DSCP field, of the IPv4 packets sent by the listeners.
+	//
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=255
+	// +optional
+	TOS *int32 `json:"tos,omitempty"`
+
+	// TrafficClass is the value of the IPv6 Traffic Class field,
+	// including the 6 bit DSCP field, of the packets sent by IPv6
+	// listeners.
+	//
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=255
+	// +optional
+	TrafficClass *int32 `json:"trafficClass,omitempty"`
+
+	// Freebind allows the listeners to bind to addresses that are
+	// not configured on Envoy's host yet.
+	//
+	// Contour's default is false.
+	// +optional
+	Freebind *bool `json:"freebind,omitempty"`
+}
+
+// TCPKeepaliveConfig holds the settings of the TCP keepalive probes.
+// Settings that are not specified use Contour's defaults: probes are
+// sent after 45s of inactivity, every 5s, and the connection is closed
+// after 9 unanswered probes.
+type TCPKeepaliveConfig struct {
+	// Idle is how long a connection must be idle before the first
+	// probe is sent. Must be a valid Go duration string of whole
+	// seconds.
+	// +kubebuilder:validation:Pattern=`^((\d*(\.\d*)?h)|(\d*(\.\d*)?m)|(\d*(\.\d*)?s)|(\d*(\.\d*)?ms)|(\d*(\.\d*)?us)|(\d*(\.\d*)?µs)|(\d*(\.\d*)?ns))+$`
+	// +optional
+	Idle *string `json:"idle,omitempty"`
+
+	// Interval is the time between probes. Must be a valid Go
+	// duration string of whole seconds.
+	// +kubebuilder:validation:Pattern=`^((\d*(\.\d*)?h)|(\d*(\.\d*)?m)|(\d*(\.\d*)?s)|(\d*(\.\d*)?ms)|(\d*(\.\d*)?us)|(\d*(\.\d*)?µs)|(\d*(\.\d*)?ns))+$`
+	// +optional
+	Interval *string `json:"interval,omitempty"`
+
+	// Probes is the number of unanswered probes after which the
+	// connection is closed.
+	//
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Probes *uint32 `json:"probes,omitempty"`
+}
+
+// EnvoyHTTP2 holds the settings of downstream HTTP/2 connections.
+// Settings that are not specified use Envoy's defaults.
+// See https://www.envoyproxy.io/docs/envoy/latest/api-v3/config/core/v3/protocol.proto#envoy-v3-api-msg-config-core-v3-http2protocoloptions
+// for more information.
+type EnvoyHTTP2 struct {
+	// MaxConcurrentStreams is the maximum number of concurrent streams
+	// that each downstream HTTP/2 connection can open. HTTPProxies that
+	// terminate TLS can override it for their virtual host.
+	//
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=2147483647
+	// +optional
+	MaxConcurrentStreams *uint32 `json:"maxConcurrentStreams,omitempty"`
+
+	// InitialStreamWindowSize is the initial flow control window
+	// size of each stream, in bytes.
+	//
+	// +kubebuilder:validation:Minimum=65535
+	// +kubebuilder:validation:Maximum=2147483647
+	// +optional
+	InitialStreamWindowSize *uint32 `json:"initialStreamWindowSize,omitempty"`
+
+	// InitialConnectionWindowSize is the initial flow control window
+	// size of each connection, in bytes.
+	//
+	// +kubebuilder:validation:Minimum=65535
+	// +kubebuilder:validation:Maximum=2147483647
+	// +optional
+	InitialConnectionWindowSize *uint32 `json:"initialConnectionWindowSize,omitempty"`
+
+	// MaxOutboundFrames is the maximum number of frames that can be
+	// queued for writing on each connection. Connections that exceed
+	// it are closed.
+	//
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxOutboundFrames *uint32 `json:"maxOutboundFrames,omitempty"`
+
+	// MaxOutboundControlFrames is the maximum number of PING, SETTINGS
+	// and RST_STREAM frames that can be queued for writing on each
+	// connection. Connections that exceed it are closed.
+	//
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxOutboundControlFrames *uint32 `json:"maxOutboundControlFrames,omitempty"`
+
+	// MaxConsecutiveInboundFramesWithEmptyPayload is the maximum number
+	// of consecutive frames with an empty payload and no end stream flag
+	// that each connection can receive. Connections that exceed it are
+	// closed.
+	//
+	// +optional
+	MaxConsecutiveInboundFramesWithEmptyPayload *uint32 `json:"maxConsecutiveInboundFramesWithEmptyPayload,omitempty"`
+
+	// MaxInboundPriorityFramesPerStream is the maximum number of PRIORITY
+	// frames that each connection can receive, per stream. Connections
+	// that exceed it are closed.
+	//
+	// +optional
+	MaxInboundPriorityFramesPerStream *uint32 `json:"maxInboundPriorityFramesPerStream,omitempty"`
+
+	// MaxInboundWindowUpdateFramesPerDataFrameSent is the maximum number
+	// of WINDOW_UPDATE frames that each connection can receive for each
+	// DATA frame sent. Connections that exceed it are closed.
+	//
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxInboundWindowUpdateFramesPerDataFrameSent *uint32 `json:"maxInboundWindowUpdateFramesPerDataFrameSent,omitempty"`
+}
+
 // EnvoyTLS describes tls parameters for Envoy listneners.
 type EnvoyTLS struct {
 	// MinimumProtocolVersion is the minimum TLS version this vhost should
@@ -464,6 +963,16 @@ type EnvoyLogging struct {
 	// +optional
 	AccessLogFormat AccessLogType `json:"accessLogFormat,omitempty"`
 
+	// AccessLogFormatPreset selects a predefined access log format,
+	// which replaces the AccessLogFormat, AccessLogFormatString and
+	// AccessLogJSONFields settings.
+	//
+	// Values: `apache-combined`, `w3c`, `json-ecs`.
+	//
+	// Other values will produce an error.
+	// +optional
+	AccessLogFormatPreset AccessLogFormatPreset `json:"accessLogFormatPreset,omitempty"`
+
 	// AccessLogFormatString sets the access log format when format is set to `envoy`.
 	// When empty, Envoy's default format is used.
 	// +optional
@@ -481,6 +990,79 @@ type EnvoyLogging struct {
 	// Other values will produce an error.
 	// +optional
 	AccessLogLevel AccessLogLevel `json:"accessLogLevel,omitempty"`
+
+	// AccessLogGRPC configures a gRPC access log service (ALS) that
+	// Envoy streams the HTTP and TCP access logs to, in addition to
+	// the file based access logs.
+	// +optional
+	AccessLogGRPC *AccessLogGRPCConfig `json:"accessLogGRPC,omitempty"`
+
+	// AccessLogFilter selects the requests that are access logged,
+	// in addition to AccessLogLevel. When not set, all the requests
+	// of the access log level are logged.
+	// +optional
+	AccessLogFilter *AccessLogFilter `json:"accessLogFilter,omitempty"`
+}
+
+// AccessLogFilter selects the requests that are access logged.
+// A request is logged if it matches any of the conditions that
+// are set, or if it is sampled.
+type AccessLogFilter struct {
+	// MinStatusCode logs the requests whose response status code
+	// is at least the given code.
+	// +optional
+	// +kubebuilder:validation:Minimum=100
+	// +kubebuilder:validation:Maximum=599
+	MinStatusCode *uint32 `json:"minStatusCode,omitempty"`
+
+	// MinDuration logs the requests that take at least the given
+	// duration, from the start of the request to the end of the
+	// response.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^((\d*(\.\d*)?h)|(\d*(\.\d*)?m)|(\d*(\.\d*)?s)|(\d*(\.\d*)?ms)|(\d*(\.\d*)?us)|(\d*(\.\d*)?µs)|(\d*(\.\d*)?ns))+$`
+	MinDuration *string `json:"minDuration,omitempty"`
+
+	// RequestHeaders logs the requests that have any of the
+	// given request headers.
+	// +optional
+	RequestHeaders []string `json:"requestHeaders,omitempty"`
+
+	// SamplePercent logs the given percentage of the requests,
+	// chosen at random.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	SamplePercent *uint32 `json:"samplePercent,omitempty"`
+}
+
+// AccessLogGRPCConfig configures the gRPC access log service.
+type AccessLogGRPCConfig struct {
+	// ExtensionService identifies the extension service defining
+	// the gRPC access log service. The address and TLS settings of
+	// the extension service are used to connect to it.
+	ExtensionService *NamespacedName `json:"extensionService"`
+
+	// LogName is the name that the access log service uses
+	// to tell the access logs of Envoy apart.
+	//
+	// Contour's default is contour.
+	// +optional
+	LogName *string `json:"logName,omitempty"`
+
+	// BufferSizeBytes is the size of the buffer of access log entries
+	// that Envoy flushes to the access log service when full.
+	//
+	// Envoy's default is 16384.
+	// +optional
+	BufferSizeBytes *uint32 `json:"bufferSizeBytes,omitempty"`
+
+	// BufferFlushInterval is the interval that Envoy flushes the
+	// buffered access log entries to the access log service at.
+	//
+	// Envoy's default is 1s.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^((\d*(\.\d*)?h)|(\d*(\.\d*)?m)|(\d*(\.\d*)?s)|(\d*(\.\d*)?ms)|(\d*(\.\d*)?us)|(\d*(\.\d*)?µs)|(\d*(\.\d*)?ns))+$`
+	BufferFlushInterval *string `json:"bufferFlushInterval,omitempty"`
 }
 
 // TimeoutParameters holds various configurable proxy timeout values.
@@ -552,6 +1134,18 @@ type TimeoutParameters struct {
 	// for more information.
 	// +optional
 	ConnectTimeout *string `json:"connectTimeout,omitempty"`
+
+	// ResponseTimeoutReply customizes the reply that the proxy sends when the
+	// response timeout of a route expires. HTTPProxy routes can override it
+	// in their timeout policy.
+	// +optional
+	ResponseTimeoutReply *contour_api_v1.TimeoutReply `json:"responseTimeoutReply,omitempty"`
+
+	// IdleTimeoutReply customizes the reply that the proxy sends when the
+	// stream idle timeout or the idle timeout of a route expires. HTTPProxy
+	// routes can override it in their timeout policy.
+	// +optional
+	IdleTimeoutReply *contour_api_v1.TimeoutReply `json:"idleTimeoutReply,omitempty"`
 }
 
 // ClusterDNSFamilyType is the Ip family to use for resolving DNS
@@ -569,6 +1163,21 @@ const (
 	AllClusterDNSFamily ClusterDNSFamilyType = "all"
 )
 
+// ClusterStatNameFormat is the format of the names
+// that Envoy uses for the stats of clusters.
+type ClusterStatNameFormat string
+
+const (
+	// Name the stats of clusters for Services by the Service's
+	// namespace, name and port, e.g. `default_kuard_8080`.
+	// This is the default.
+	ServiceClusterStatNameFormat ClusterStatNameFormat = "service"
+	// Name the stats of clusters by the Envoy cluster name, which
+	// includes a hash of the cluster's settings, so that clusters
+	// for the same Service with different settings have separate stats.
+	ClusterNameClusterStatNameFormat ClusterStatNameFormat = "cluster"
+)
+
 // ServerHeaderTransformation defines the action to be applied to the Server header on the response path
 type ServerHeaderTransformationType string
 
@@ -582,6 +1191,20 @@ const (
 	// Pass through the value of the Server header, and do not append a header
 	// if none is present.
 	PassThroughServerHeader ServerHeaderTransformationType = "pass_through"
+	// Remove any Server header, and do not append a header.
+	RemoveServerHeader ServerHeaderTransformationType = "remove"
+)
+
+// ListenerDrainType defines when Envoy drains the connections of a listener.
+type ListenerDrainType string
+
+const (
+	// Drain connections when the listener is modified or removed,
+	// and when Envoy is shutting down.
+	// This is the default value.
+	DefaultListenerDrainType ListenerDrainType = "default"
+	// Only drain connections when the listener is modified or removed.
+	ModifyOnlyListenerDrainType ListenerDrainType = "modify-only"
 )
 
 // ClusterParameters holds various configurable cluster values.
@@ -623,6 +1246,172 @@ type ClusterParameters struct {
 	// +kubebuilder:validation:Minimum=1
 	// +optional
 	PerConnectionBufferLimitBytes *uint32 `json:"per-connection-buffer-limit-bytes,omitempty"`
+
+	// StatNameFormat sets the format of the names that Envoy uses for
+	// the stats of clusters. The `service` format is stable when the
+	// settings of a cluster, or the Secrets that it references, change.
+	//
+	// Values: `service` (default), `cluster`.
+	//
+	// Other values will produce an error.
+	// +optional
+	StatNameFormat ClusterStatNameFormat `json:"statNameFormat,omitempty"`
+
+	// MaxStatNameLength caps the length of the names that Envoy uses for
+	// the stats of clusters. Longer names are truncated and end in a hash
+	// of the full name, so they stay unique and stable.
+	// If not specified, there is no limit.
+	//
+	// +kubebuilder:validation:Minimum=8
+	// +optional
+	MaxStatNameLength *uint32 `json:"maxStatNameLength,omitempty"`
+
+	// EndpointDeregistrationDelay defines how long endpoints that are
+	// removed from a Service's Endpoints, e.g. because their Pod is
+	// terminating, are kept in Envoy's load balancing set. This gives
+	// backends with long shutdown phases time to finish serving.
+	// Must be a valid Go duration string. If not specified, endpoints
+	// are removed immediately.
+	// +optional
+	EndpointDeregistrationDelay *string `json:"endpointDeregistrationDelay,omitempty"`
+
+	// HTTP2Keepalive sends HTTP/2 PING frames on the connections to
+	// upstreams that are reached with HTTP/2, so that half-open
+	// connections, e.g. through NATs or firewalls that dropped them,
+	// are detected and closed. HTTPProxy services can override it.
+	// If not specified, no PING frames are sent.
+	// +optional
+	HTTP2Keepalive *HTTP2KeepaliveConfig `json:"http2Keepalive,omitempty"`
+
+	// HTTP2 holds the settings of the connections to upstreams that are
+	// reached with HTTP/2, which control how many requests Envoy
+	// multiplexes on each connection and how much data it buffers.
+	// +optional
+	HTTP2 *UpstreamHTTP2 `json:"http2,omitempty"`
+
+	// DNSResolvers are the addresses of the DNS resolvers that
+	// externalName clusters use, formatted as <ip> or <ip>:<port>.
+	// The port defaults to 53. This lets externalName clusters
+	// resolve names with dedicated resolvers rather than those
+	// of Envoy's host.
+	// If not specified, the resolvers of Envoy's host are used.
+	// +optional
+	DNSResolvers []string `json:"dnsResolvers,omitempty"`
+
+	// DNSRefreshRate defines how often externalName clusters resolve
+	// their names again. Must be a valid Go duration string greater
+	// than 1ms. If not specified, Envoy's default of 5s applies.
+	// +kubebuilder:validation:Pattern=`^((\d*(\.\d*)?h)|(\d*(\.\d*)?m)|(\d*(\.\d*)?s)|(\d*(\.\d*)?ms)|(\d*(\.\d*)?us)|(\d*(\.\d*)?µs)|(\d*(\.\d*)?ns))+$`
+	// +optional
+	DNSRefreshRate *string `json:"dnsRefreshRate,omitempty"`
+
+	// CircuitBreakers holds the default circuit breaker thresholds of
+	// the clusters. Services can override each threshold with their
+	// projectcontour.io/max-* annotations.
+	// If not specified, Envoy's defaults apply.
+	// +optional
+	CircuitBreakers *CircuitBreakers `json:"circuitBreakers,omitempty"`
+}
+
+// CircuitBreakers holds the circuit breaker thresholds of clusters.
+// Thresholds that are not specified use Envoy's defaults.
+// See https://www.envoyproxy.io/docs/envoy/latest/api-v3/config/cluster/v3/circuit_breaker.proto
+// for more information.
+type CircuitBreakers struct {
+	// MaxConnections is the maximum number of connections that
+	// Envoy opens to the endpoints of each cluster.
+	//
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxConnections *uint32 `json:"maxConnections,omitempty"`
+
+	// MaxPendingRequests is the maximum number of requests that
+	// are queued while waiting for a connection.
+	//
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxPendingRequests *uint32 `json:"maxPendingRequests,omitempty"`
+
+	// MaxRequests is the maximum number of parallel requests
+	// to the endpoints of each cluster.
+	//
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxRequests *uint32 `json:"maxRequests,omitempty"`
+
+	// MaxRetries is the maximum number of parallel retries
+	// to the endpoints of each cluster. Ignored if a retry
+	// budget is set.
+	//
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxRetries *uint32 `json:"maxRetries,omitempty"`
+
+	// RetryBudgetPercent limits the parallel retries to the endpoints
+	// of each cluster to a percentage of the active requests, so that
+	// retries scale with the traffic but can't amplify an overload.
+	// If only RetryBudgetMinRetryConcurrency is set, Envoy's default
+	// of 20% applies.
+	//
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	RetryBudgetPercent *uint32 `json:"retryBudgetPercent,omitempty"`
+
+	// RetryBudgetMinRetryConcurrency is the number of parallel retries
+	// to the endpoints of each cluster that are always allowed by the
+	// retry budget. If only RetryBudgetPercent is set, Envoy's default
+	// of 3 applies.
+	//
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	RetryBudgetMinRetryConcurrency *uint32 `json:"retryBudgetMinRetryConcurrency,omitempty"`
+}
+
+// UpstreamHTTP2 holds the settings of upstream HTTP/2 connections.
+// Settings that are not specified use Envoy's defaults.
+// See https://www.envoyproxy.io/docs/envoy/latest/api-v3/config/core/v3/protocol.proto#envoy-v3-api-msg-config-core-v3-http2protocoloptions
+// for more information.
+type UpstreamHTTP2 struct {
+	// MaxConcurrentStreams is the maximum number of concurrent streams
+	// that Envoy opens on each upstream HTTP/2 connection. Envoy opens
+	// more connections when it's reached.
+	//
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=2147483647
+	// +optional
+	MaxConcurrentStreams *uint32 `json:"maxConcurrentStreams,omitempty"`
+
+	// InitialStreamWindowSize is the initial flow control window
+	// size of each stream, in bytes.
+	//
+	// +kubebuilder:validation:Minimum=65535
+	// +kubebuilder:validation:Maximum=2147483647
+	// +optional
+	InitialStreamWindowSize *uint32 `json:"initialStreamWindowSize,omitempty"`
+
+	// InitialConnectionWindowSize is the initial flow control window
+	// size of each connection, in bytes.
+	//
+	// +kubebuilder:validation:Minimum=65535
+	// +kubebuilder:validation:Maximum=2147483647
+	// +optional
+	InitialConnectionWindowSize *uint32 `json:"initialConnectionWindowSize,omitempty"`
+}
+
+// HTTP2KeepaliveConfig defines how HTTP/2 PING frames
+// keep upstream connections alive.
+type HTTP2KeepaliveConfig struct {
+	// Interval is how often a PING frame is sent on each connection.
+	// Must be a valid Go duration string.
+	// +kubebuilder:validation:Pattern=`^((\d*(\.\d*)?h)|(\d*(\.\d*)?m)|(\d*(\.\d*)?s)|(\d*(\.\d*)?ms)|(\d*(\.\d*)?us)|(\d*(\.\d*)?µs)|(\d*(\.\d*)?ns))+$`
+	Interval string `json:"interval"`
+
+	// Timeout is how long to wait for the response to a PING frame
+	// before the connection is closed. Must be a valid Go duration
+	// string of at least 1ms.
+	// +kubebuilder:validation:Pattern=`^((\d*(\.\d*)?h)|(\d*(\.\d*)?m)|(\d*(\.\d*)?s)|(\d*(\.\d*)?ms)|(\d*(\.\d*)?us)|(\d*(\.\d*)?µs)|(\d*(\.\d*)?ns))+$`
+	Timeout string `json:"timeout"`
 }
 
 // HTTPProxyConfig defines parameters on HTTPProxy.
@@ -642,8 +1431,40 @@ type HTTPProxyConfig struct {
 	// use as fallback when a non-SNI request is received.
 	// +optional
 	FallbackCertificate *NamespacedName `json:"fallbackCertificate,omitempty"`
+
+	// EnableNamespaceReports enables maintaining a NamespaceReport
+	// resource in each namespace that contains HTTPProxies, summarizing
+	// the state of the HTTPProxies in that namespace.
+	//
+	// Contour's default is false.
+	// +optional
+	EnableNamespaceReports *bool `json:"enableNamespaceReports,omitempty"`
+
+	// InsecureVirtualHosts sets whether root HTTPProxies can define
+	// virtual hosts that are only served over plain HTTP.
+	//
+	// Values: `allowed` (default), `explicit`, `disallowed`.
+	//
+	// Other values will produce an error.
+	// +optional
+	InsecureVirtualHosts InsecureVirtualHostPolicy `json:"insecureVirtualHosts,omitempty"`
 }
 
+// InsecureVirtualHostPolicy sets whether root HTTPProxies can
+// define virtual hosts that are only served over plain HTTP.
+type InsecureVirtualHostPolicy string
+
+const (
+	// Serve root HTTPProxies without TLS over plain HTTP,
+	// whether or not they set insecure. This is the default.
+	AllowedInsecureVirtualHosts InsecureVirtualHostPolicy = "allowed"
+	// Only serve root HTTPProxies without TLS if they
+	// explicitly set insecure.
+	ExplicitInsecureVirtualHosts InsecureVirtualHostPolicy = "explicit"
+	// Require root HTTPProxies to specify TLS.
+	DisallowedInsecureVirtualHosts InsecureVirtualHostPolicy = "disallowed"
+)
+
 // NetworkParameters hold various configurable network values.
 type NetworkParameters struct {
 	// XffNumTrustedHops defines the number of additional ingress proxy hops from the
@@ -732,6 +1553,237 @@ type TracingConfig struct {
 
 	// ExtensionService identifies the extension service defining the otel-collector.
 	ExtensionService *NamespacedName `json:"extensionService"`
+
+	// Provider defines the tracer that Envoy exports trace data with.
+	//
+	// Values: `opentelemetry` (default), `zipkin`.
+	//
+	// +kubebuilder:validation:Enum=opentelemetry;zipkin
+	// +optional
+	Provider TracingProvider `json:"provider,omitempty"`
+
+	// Zipkin configures the zipkin tracer.
+	// +optional
+	Zipkin *ZipkinTracingConfig `json:"zipkin,omitempty"`
+
+	// ForceTrace allows clients to force the tracing of a request with
+	// the x-envoy-force-trace header, for example to debug a single
+	// request in production. Forced requests are also access logged
+	// regardless of the access log level and filter.
+	// +optional
+	ForceTrace *ForceTraceConfig `json:"forceTrace,omitempty"`
+}
+
+// ForceTraceConfig defines the clients that can force the tracing
+// of their requests.
+type ForceTraceConfig struct {
+	// AllowedClientCIDRs are the address ranges of the clients that
+	// can force tracing, e.g. 10.0.0.0/8. Envoy treats the requests
+	// of these clients, and only these, as internal requests.
+	// +kubebuilder:validation:MinItems=1
+	AllowedClientCIDRs []string `json:"allowedClientCIDRs"`
+}
+
+// TracingProvider is the tracer that Envoy exports trace data with.
+type TracingProvider string
+
+const (
+	// Export trace data to an OpenTelemetry collector with OTLP over gRPC.
+	OpenTelemetryTracingProvider TracingProvider = "opentelemetry"
+	// Export trace data to a Zipkin collector with the Zipkin API over HTTP/2.
+	ZipkinTracingProvider TracingProvider = "zipkin"
+)
+
+// ZipkinSpanEncoding is the encoding of the spans sent to a Zipkin collector.
+type ZipkinSpanEncoding string
+
+const (
+	JSONZipkinSpanEncoding  ZipkinSpanEncoding = "json"
+	ProtoZipkinSpanEncoding ZipkinSpanEncoding = "proto"
+)
+
+// ZipkinTracingConfig defines how trace data is exported to a Zipkin
+// collector.
+type ZipkinTracingConfig struct {
+	// CollectorEndpoint is the path of the API of the collector
+	// that spans are sent to.
+	// contour's default is /api/v2/spans.
+	// +optional
+	CollectorEndpoint string `json:"collectorEndpoint,omitempty"`
+
+	// Encoding defines the encoding of the spans.
+	//
+	// Values: `json` (default), `proto`.
+	//
+	// +kubebuilder:validation:Enum=json;proto
+	// +optional
+	Encoding ZipkinSpanEncoding `json:"encoding,omitempty"`
+}
+
+// CaptureConfig defines where and how much of the requests and
+// responses on routes with capture enabled are captured.
+type CaptureConfig struct {
+	// FilePathPrefix is the path prefix of the files Envoy writes
+	// each captured request and response to, as JSON.
+	// +kubebuilder:validation:MinLength=1
+	FilePathPrefix string `json:"filePathPrefix"`
+
+	// SamplePercent is the percentage of requests to capture.
+	// contour's default is 100.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	SamplePercent *uint32 `json:"samplePercent,omitempty"`
+
+	// MaxBufferedBytes limits how many bytes of each request and
+	// response body are captured. If unset, Envoy's default of
+	// 1KiB is used.
+	// +optional
+	MaxBufferedBytes *uint32 `json:"maxBufferedBytes,omitempty"`
+}
+
+// SecretBackendType is the source of the TLS certificates served by Envoy.
+type SecretBackendType string
+
+const (
+	// Serve certificates and keys from Kubernetes Secrets.
+	// This is the default value.
+	KubernetesSecretBackend SecretBackendType = "kubernetes"
+	// Serve certificates and keys from files on Envoy's filesystem.
+	FileSecretBackend SecretBackendType = "file"
+	// Have Envoy fetch certificates and keys from an external SDS server.
+	SDSSecretBackend SecretBackendType = "sds"
+)
+
+// SecretBackendConfig defines where Envoy gets the certificates and
+// keys that it serves from.
+type SecretBackendConfig struct {
+	// Type is the secret backend to use.
+	// When configured as kubernetes, the certificates and keys are read
+	// from the referenced Kubernetes Secrets, and sent to Envoy by Contour.
+	// When configured as file, Envoy reads the certificate and key of a
+	// referenced Secret from the tls.crt and tls.key files in the
+	// <directory>/<namespace>/<name> directory, and reloads them when they
+	// change.
+	// When configured as sds, Envoy fetches the certificate and key of a
+	// referenced Secret from the SDS server defined by the extension
+	// service, using <namespace>/<name> as the name of the SDS resource.
+	// Referenced Secrets don't need to exist in Kubernetes when using the
+	// file or sds backends.
+	//
+	// Values: `kubernetes` (default), `file`, `sds`.
+	//
+	// Other values will produce an error.
+	// +optional
+	Type SecretBackendType `json:"type,omitempty"`
+
+	// Directory is the directory on Envoy's filesystem that contains the
+	// certificates and keys, used by the file backend.
+	// +optional
+	Directory string `json:"directory,omitempty"`
+
+	// ExtensionService identifies the extension service defining the
+	// SDS server, used by the sds backend.
+	// +optional
+	ExtensionService *NamespacedName `json:"extensionService,omitempty"`
+}
+
+// SecretEncryptionConfig defines how the private keys that Contour
+// holds in memory are encrypted.
+type SecretEncryptionConfig struct {
+	// DataKeyFile is the name of the file holding the AES-256 data key
+	// that encrypts the private keys, as 32 raw or base64 encoded bytes.
+	// If not specified, a random data key is generated when Contour
+	// starts.
+	// +optional
+	DataKeyFile string `json:"dataKeyFile,omitempty"`
+
+	// UnsealCommand is the command that unseals a data key that was
+	// sealed by a KMS. The content of the data key file is written to
+	// the standard input of the command, which must write the data key
+	// to its standard output.
+	// Requires DataKeyFile.
+	// +optional
+	UnsealCommand []string `json:"unsealCommand,omitempty"`
+}
+
+// HTTPSRedirectConfig defines how HTTP requests are redirected to HTTPS.
+type HTTPSRedirectConfig struct {
+	// StatusCode is the HTTP status code of the redirect response.
+	// Defaults to 301.
+	// +kubebuilder:validation:Enum=301;308
+	// +optional
+	StatusCode int `json:"statusCode,omitempty"`
+
+	// StripQuery removes the query string from the redirect location.
+	// +optional
+	StripQuery *bool `json:"stripQuery,omitempty"`
+
+	// ExcludedPathPrefixes are path prefixes that are served over
+	// HTTP instead of being redirected, for example
+	// /.well-known/acme-challenge.
+	// +optional
+	ExcludedPathPrefixes []string `json:"excludedPathPrefixes,omitempty"`
+}
+
+// LeaderElectionResourceLock is the type of resource
+// that leader election locks.
+type LeaderElectionResourceLock string
+
+// LeasesResourceLock locks a Lease.
+const LeasesResourceLock LeaderElectionResourceLock = "leases"
+
+// LeaderElectionConfig defines how the replicas of Contour
+// elect the leader that writes the status of resources.
+type LeaderElectionConfig struct {
+	// Disable disables leader election, so that every replica of
+	// Contour writes the status of resources. It should only be
+	// disabled for installations that run a single replica.
+	//
+	// Contour's default is false.
+	// +optional
+	Disable *bool `json:"disable,omitempty"`
+
+	// LeaseName is the name of the resource that leader election locks.
+	//
+	// Contour's default is leader-elect.
+	// +optional
+	LeaseName string `json:"leaseName,omitempty"`
+
+	// LeaseNamespace is the namespace of the resource that leader
+	// election locks.
+	//
+	// Contour's default is the namespace that it runs in.
+	// +optional
+	LeaseNamespace string `json:"leaseNamespace,omitempty"`
+
+	// ResourceLock is the type of resource that leader election locks.
+	// Values: `leases` (default).
+	// +kubebuilder:validation:Enum=leases
+	// +optional
+	ResourceLock LeaderElectionResourceLock `json:"resourceLock,omitempty"`
+
+	// LeaseDuration is how long the replicas that aren't the leader
+	// wait before trying to acquire the leadership.
+	//
+	// Contour's default is 15s.
+	// +optional
+	LeaseDuration *string `json:"leaseDuration,omitempty"`
+
+	// RenewDeadline is how long the leader retries to renew the
+	// leadership before giving it up. Must be shorter than
+	// LeaseDuration.
+	//
+	// Contour's default is 10s.
+	// +optional
+	RenewDeadline *string `json:"renewDeadline,omitempty"`
+
+	// RetryPeriod is how long the replicas wait between
+	// attempts to acquire or renew the leadership.
+	//
+	// Contour's default is 2s.
+	// +optional
+	RetryPeriod *string `json:"retryPeriod,omitempty"`
 }
 
 // CustomTag defines custom tags with unique tag name
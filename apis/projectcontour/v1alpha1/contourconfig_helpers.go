@@ -15,9 +15,16 @@ package v1alpha1
 
 import (
 	"fmt"
+	"net"
+	"net/http"
+	"regexp"
 	"strconv"
+	"strings"
+	"time"
 
+	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 // Validate configuration that is not already covered by CRD validation.
@@ -26,12 +33,15 @@ func (c *ContourConfigurationSpec) Validate() error {
 	if err := endpointsInConfict(c.Health, c.Metrics); err != nil {
 		return fmt.Errorf("invalid contour configuration: %v", err)
 	}
+	if err := c.Metrics.Validate(); err != nil {
+		return fmt.Errorf("invalid contour configuration: %v", err)
+	}
 
 	// Validation of nested configuration structs.
 	var validateFuncs []func() error
 
 	if c.XDSServer != nil {
-		validateFuncs = append(validateFuncs, c.XDSServer.Type.Validate)
+		validateFuncs = append(validateFuncs, c.XDSServer.Validate)
 	}
 	if c.Envoy != nil {
 		validateFuncs = append(validateFuncs, c.Envoy.Validate)
@@ -39,9 +49,33 @@ func (c *ContourConfigurationSpec) Validate() error {
 	if c.Gateway != nil {
 		validateFuncs = append(validateFuncs, c.Gateway.Validate)
 	}
+	if c.HTTPProxy != nil {
+		validateFuncs = append(validateFuncs, c.HTTPProxy.InsecureVirtualHosts.Validate)
+	}
 	if c.Tracing != nil {
 		validateFuncs = append(validateFuncs, c.Tracing.Validate)
 	}
+	if c.Capture != nil {
+		validateFuncs = append(validateFuncs, c.Capture.Validate)
+	}
+	if c.SecretBackend != nil {
+		validateFuncs = append(validateFuncs, c.SecretBackend.Validate)
+	}
+	if c.SecretEncryption != nil {
+		validateFuncs = append(validateFuncs, c.SecretEncryption.Validate)
+	}
+	if c.HTTPSRedirect != nil {
+		validateFuncs = append(validateFuncs, c.HTTPSRedirect.Validate)
+	}
+	if c.RuntimeFlags != nil {
+		validateFuncs = append(validateFuncs, c.validateRuntimeFlags)
+	}
+	if c.LeaderElection != nil {
+		validateFuncs = append(validateFuncs, c.LeaderElection.Validate)
+	}
+	if c.HTTPProxyMetrics != nil {
+		validateFuncs = append(validateFuncs, c.validateHTTPProxyMetrics)
+	}
 
 	for _, validate := range validateFuncs {
 		if err := validate(); err != nil {
@@ -52,6 +86,155 @@ func (c *ContourConfigurationSpec) Validate() error {
 	return nil
 }
 
+func (c *CaptureConfig) Validate() error {
+	if c.FilePathPrefix == "" {
+		return fmt.Errorf("capture.filePathPrefix must be defined")
+	}
+
+	if c.SamplePercent != nil && (*c.SamplePercent < 1 || *c.SamplePercent > 100) {
+		return fmt.Errorf("invalid capture sample percent %d, must be between 1 and 100", *c.SamplePercent)
+	}
+
+	return nil
+}
+
+func (s *SecretBackendConfig) Validate() error {
+	switch s.Type {
+	case "", KubernetesSecretBackend:
+		if s.Directory != "" || s.ExtensionService != nil {
+			return fmt.Errorf("secretBackend.directory and secretBackend.extensionService can't be set with the kubernetes secret backend")
+		}
+	case FileSecretBackend:
+		if s.Directory == "" {
+			return fmt.Errorf("secretBackend.directory must be defined with the file secret backend")
+		}
+		if s.ExtensionService != nil {
+			return fmt.Errorf("secretBackend.extensionService can't be set with the file secret backend")
+		}
+	case SDSSecretBackend:
+		if s.ExtensionService == nil {
+			return fmt.Errorf("secretBackend.extensionService must be defined with the sds secret backend")
+		}
+		if s.Directory != "" {
+			return fmt.Errorf("secretBackend.directory can't be set with the sds secret backend")
+		}
+	default:
+		return fmt.Errorf("invalid secret backend type %q", s.Type)
+	}
+
+	return nil
+}
+
+// Validate ensures that an unseal command is only set with a data key file.
+func (s *SecretEncryptionConfig) Validate() error {
+	if len(s.UnsealCommand) > 0 && s.DataKeyFile == "" {
+		return fmt.Errorf("secretEncryption.dataKeyFile must be defined with secretEncryption.unsealCommand")
+	}
+
+	return nil
+}
+
+func (h *HTTPSRedirectConfig) Validate() error {
+	switch h.StatusCode {
+	case 0, http.StatusMovedPermanently, http.StatusPermanentRedirect:
+	default:
+		return fmt.Errorf("invalid httpsRedirect.statusCode %d, must be 301 or 308", h.StatusCode)
+	}
+
+	for _, prefix := range h.ExcludedPathPrefixes {
+		if !strings.HasPrefix(prefix, "/") {
+			return fmt.Errorf("invalid httpsRedirect.excludedPathPrefixes %q, must start with /", prefix)
+		}
+	}
+
+	return nil
+}
+
+// Validate ensures that the durations of leader election can
+// be satisfied: the leader must be able to retry renewing the
+// leadership before its renew deadline, which must expire before
+// the other replicas try to acquire the leadership.
+func (l *LeaderElectionConfig) Validate() error {
+	switch l.ResourceLock {
+	case "", LeasesResourceLock:
+	default:
+		return fmt.Errorf("invalid leaderElection.resourceLock %q, must be %s",
+			l.ResourceLock, LeasesResourceLock)
+	}
+
+	duration := func(name string, s *string) (time.Duration, error) {
+		if s == nil {
+			return 0, nil
+		}
+		d, err := time.ParseDuration(*s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid leaderElection.%s %q: %w", name, *s, err)
+		}
+		if d <= 0 {
+			return 0, fmt.Errorf("invalid leaderElection.%s %q, must be greater than zero", name, *s)
+		}
+		return d, nil
+	}
+
+	leaseDuration, err := duration("leaseDuration", l.LeaseDuration)
+	if err != nil {
+		return err
+	}
+	renewDeadline, err := duration("renewDeadline", l.RenewDeadline)
+	if err != nil {
+		return err
+	}
+	retryPeriod, err := duration("retryPeriod", l.RetryPeriod)
+	if err != nil {
+		return err
+	}
+
+	if leaseDuration > 0 && renewDeadline > 0 && leaseDuration <= renewDeadline {
+		return fmt.Errorf("invalid leaderElection.leaseDuration %q, must be longer than renewDeadline %q", *l.LeaseDuration, *l.RenewDeadline)
+	}
+	// Retries are jittered by up to 20%.
+	if renewDeadline > 0 && retryPeriod > 0 && renewDeadline <= retryPeriod*6/5 {
+		return fmt.Errorf("invalid leaderElection.renewDeadline %q, must be longer than 1.2 times retryPeriod %q", *l.RenewDeadline, *l.RetryPeriod)
+	}
+
+	return nil
+}
+
+// runtimeFlagRegex matches valid runtime flag names.
+var runtimeFlagRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]+(\.[a-zA-Z0-9_-]+)*$`)
+
+func (c *ContourConfigurationSpec) validateRuntimeFlags() error {
+	for flag, percent := range c.RuntimeFlags {
+		if !runtimeFlagRegex.MatchString(flag) {
+			return fmt.Errorf("invalid runtimeFlags flag %q", flag)
+		}
+		if percent > 100 {
+			return fmt.Errorf("invalid runtimeFlags percent %d for flag %q, must be at most 100", percent, flag)
+		}
+	}
+
+	return nil
+}
+
+func (c *ContourConfigurationSpec) validateHTTPProxyMetrics() error {
+	if s := c.HTTPProxyMetrics.ScrapeInterval; s != nil {
+		d, err := time.ParseDuration(*s)
+		if err != nil {
+			return fmt.Errorf("invalid httpProxyMetrics.scrapeInterval %q: %w", *s, err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("invalid httpProxyMetrics.scrapeInterval %q, must be greater than zero", *s)
+		}
+	}
+
+	// Contour scrapes the Envoy metrics endpoint over HTTP.
+	if c.Envoy != nil && c.Envoy.Metrics != nil && c.Envoy.Metrics.TLS != nil {
+		return fmt.Errorf("httpProxyMetrics cannot be enabled when the Envoy metrics endpoint is served over HTTPS")
+	}
+
+	return nil
+}
+
 func (t *TracingConfig) Validate() error {
 	if t.ExtensionService == nil {
 		return fmt.Errorf("tracing.extensionService must be defined")
@@ -90,6 +273,97 @@ func (t *TracingConfig) Validate() error {
 		}
 		customTagNames = append(customTagNames, customTag.TagName)
 	}
+
+	if err := t.ForceTrace.Validate(); err != nil {
+		return err
+	}
+
+	switch t.Provider {
+	case "", OpenTelemetryTracingProvider:
+		if t.Zipkin != nil {
+			return fmt.Errorf("tracing.zipkin requires the %q tracing provider", ZipkinTracingProvider)
+		}
+	case ZipkinTracingProvider:
+		return t.Zipkin.Validate()
+	default:
+		return fmt.Errorf("invalid tracing provider %q", t.Provider)
+	}
+
+	return nil
+}
+
+// Validate ensures that the force trace configuration
+// allows at least one valid address range.
+func (f *ForceTraceConfig) Validate() error {
+	if f == nil {
+		return nil
+	}
+
+	if len(f.AllowedClientCIDRs) == 0 {
+		return fmt.Errorf("tracing.forceTrace.allowedClientCIDRs must be defined")
+	}
+	for _, cidr := range f.AllowedClientCIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("invalid tracing.forceTrace.allowedClientCIDRs: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// Validate ensures that the Zipkin tracing configuration is valid.
+func (z *ZipkinTracingConfig) Validate() error {
+	if z == nil {
+		return nil
+	}
+
+	if z.CollectorEndpoint != "" && !strings.HasPrefix(z.CollectorEndpoint, "/") {
+		return fmt.Errorf("invalid tracing.zipkin.collectorEndpoint %q: must start with a slash", z.CollectorEndpoint)
+	}
+
+	switch z.Encoding {
+	case "", JSONZipkinSpanEncoding, ProtoZipkinSpanEncoding:
+		return nil
+	default:
+		return fmt.Errorf("invalid tracing.zipkin.encoding %q", z.Encoding)
+	}
+}
+
+// Validate ensures that the xDS server configuration is valid.
+func (x *XDSServerConfig) Validate() error {
+	if err := x.Type.Validate(); err != nil {
+		return err
+	}
+
+	return x.Authorization.Validate(x.TLS != nil && x.TLS.Insecure != nil && *x.TLS.Insecure)
+}
+
+// Validate ensures that the xDS server authorization configuration is
+// valid. Client certificate SANs can only be authorized if the xDS server
+// is not insecure.
+func (a *XDSServerAuthorization) Validate(insecure bool) error {
+	if a == nil {
+		return nil
+	}
+
+	if len(a.AllowedSubjectAltNames) == 0 && a.JWT == nil {
+		return fmt.Errorf("invalid xDS server authorization: at least one of allowed subject alt names or JWT must be specified")
+	}
+
+	if len(a.AllowedSubjectAltNames) > 0 && insecure {
+		return fmt.Errorf("invalid xDS server authorization: allowed subject alt names require a TLS xDS server")
+	}
+
+	for _, san := range a.AllowedSubjectAltNames {
+		if len(strings.TrimSpace(san)) == 0 {
+			return fmt.Errorf("invalid xDS server authorization: allowed subject alt names must not be blank")
+		}
+	}
+
+	if a.JWT != nil && len(strings.TrimSpace(a.JWT.KeyFile)) == 0 {
+		return fmt.Errorf("invalid xDS server authorization: JWT key file must be specified")
+	}
+
 	return nil
 }
 
@@ -111,11 +385,164 @@ func (d ClusterDNSFamilyType) Validate() error {
 	}
 }
 
+func (f ClusterStatNameFormat) Validate() error {
+	switch f {
+	case "", ServiceClusterStatNameFormat, ClusterNameClusterStatNameFormat:
+		return nil
+	default:
+		return fmt.Errorf("invalid cluster stat name format %q", f)
+	}
+}
+
+func (p InsecureVirtualHostPolicy) Validate() error {
+	switch p {
+	case "", AllowedInsecureVirtualHosts, ExplicitInsecureVirtualHosts, DisallowedInsecureVirtualHosts:
+		return nil
+	default:
+		return fmt.Errorf("invalid insecure virtual host policy %q", p)
+	}
+}
+
+func (d ListenerDrainType) Validate() error {
+	switch d {
+	case "", DefaultListenerDrainType, ModifyOnlyListenerDrainType:
+		return nil
+	default:
+		return fmt.Errorf("invalid listener drain type %q", d)
+	}
+}
+
+func (a CompressionAlgorithm) Validate() error {
+	switch a {
+	case "", GzipCompression, BrotliCompression, ZstdCompression, DisabledCompression:
+		return nil
+	default:
+		return fmt.Errorf("invalid compression algorithm %q", a)
+	}
+}
+
+// Validate ensures the compression configuration is valid.
+func (c *EnvoyCompression) Validate() error {
+	if c == nil {
+		return nil
+	}
+
+	if err := c.Algorithm.Validate(); err != nil {
+		return err
+	}
+	for _, contentType := range c.ContentTypes {
+		if strings.TrimSpace(contentType) == "" {
+			return fmt.Errorf("invalid compression content type %q", contentType)
+		}
+	}
+	return nil
+}
+
+// ValidateResponseMap checks that the status codes of the response
+// mappings are valid and unique, and that each mapping changes the reply.
+func ValidateResponseMap(mappings []ResponseMapping) error {
+	statusCodes := map[int]bool{}
+	for _, m := range mappings {
+		if m.StatusCode < 200 || m.StatusCode > 599 {
+			return fmt.Errorf("invalid response mapping status code %d: must be between 200 and 599", m.StatusCode)
+		}
+		if statusCodes[m.StatusCode] {
+			return fmt.Errorf("invalid response mapping: status code %d is mapped more than once", m.StatusCode)
+		}
+		statusCodes[m.StatusCode] = true
+
+		if m.RewriteStatusCode != 0 && (m.RewriteStatusCode < 200 || m.RewriteStatusCode > 599) {
+			return fmt.Errorf("invalid response mapping rewrite status code %d: must be between 200 and 599", m.RewriteStatusCode)
+		}
+		if m.RewriteStatusCode == 0 && m.Body == "" && len(m.JSONFormat) == 0 {
+			return fmt.Errorf("invalid response mapping for status code %d: one of rewrite status code, body or JSON format must be set", m.StatusCode)
+		}
+	}
+	return nil
+}
+
+// Validate ensures the HTTP connection manager configuration is valid.
+func (m *EnvoyHTTPConnectionManager) Validate() error {
+	if m == nil {
+		return nil
+	}
+
+	if m.ServerName != nil {
+		if err := ValidateServerName(*m.ServerName); err != nil {
+			return err
+		}
+	}
+
+	if m.Via != nil {
+		if err := ValidateVia(*m.Via); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ValidateServerName checks that name is a valid value of the Server header.
+func ValidateServerName(name string) error {
+	return validateHeaderValue("server name", name)
+}
+
+// ValidateVia checks that via is a valid value of the Via header.
+func ValidateVia(via string) error {
+	return validateHeaderValue("via", via)
+}
+
+func validateHeaderValue(field, value string) error {
+	if value == "" || strings.TrimSpace(value) != value || strings.ContainsAny(value, "\r\n\x00") {
+		return fmt.Errorf("invalid %s %q: must be a non-empty header value without surrounding whitespace", field, value)
+	}
+	return nil
+}
+
+func (p HTTPFilterPosition) Validate() error {
+	switch p {
+	case "", BeforeRouterHTTPFilterPosition, AfterCORSHTTPFilterPosition:
+		return nil
+	default:
+		return fmt.Errorf("invalid HTTP filter position %q", p)
+	}
+}
+
+// ValidateHTTPFilters checks that the additional HTTP filters
+// have unique names, typed configurations and valid positions.
+func ValidateHTTPFilters(filters []HTTPFilter) error {
+	names := map[string]bool{}
+	for _, f := range filters {
+		if f.Name == "" {
+			return fmt.Errorf("invalid HTTP filter: name must be set")
+		}
+		if names[f.Name] {
+			return fmt.Errorf("invalid HTTP filter %q: name must be unique", f.Name)
+		}
+		names[f.Name] = true
+
+		if strings.TrimSpace(f.TypedConfig) == "" {
+			return fmt.Errorf("invalid HTTP filter %q: typed config must be set", f.Name)
+		}
+		if err := f.Position.Validate(); err != nil {
+			return fmt.Errorf("invalid HTTP filter %q: %w", f.Name, err)
+		}
+	}
+	return nil
+}
+
 // Validate configuration that cannot be handled with CRD validation.
 func (e *EnvoyConfig) Validate() error {
 	if err := endpointsInConfict(e.Health, e.Metrics); err != nil {
 		return fmt.Errorf("invalid envoy configuration: %v", err)
 	}
+	if err := e.Metrics.Validate(); err != nil {
+		return fmt.Errorf("invalid envoy configuration: %v", err)
+	}
+
+	if err := e.HealthListener.Validate(); err != nil {
+		return err
+	}
 
 	if err := e.Logging.Validate(); err != nil {
 		return err
@@ -140,6 +567,65 @@ func (e *EnvoyConfig) Validate() error {
 		if err := e.Cluster.DNSLookupFamily.Validate(); err != nil {
 			return err
 		}
+		if err := e.Cluster.StatNameFormat.Validate(); err != nil {
+			return err
+		}
+		if e.Cluster.EndpointDeregistrationDelay != nil {
+			if _, err := time.ParseDuration(*e.Cluster.EndpointDeregistrationDelay); err != nil {
+				return fmt.Errorf("invalid endpoint deregistration delay %q: %w", *e.Cluster.EndpointDeregistrationDelay, err)
+			}
+		}
+		if e.Cluster.HTTP2Keepalive != nil {
+			if err := e.Cluster.HTTP2Keepalive.Validate(); err != nil {
+				return err
+			}
+		}
+		for _, resolver := range e.Cluster.DNSResolvers {
+			if _, _, err := ParseDNSResolver(resolver); err != nil {
+				return err
+			}
+		}
+		if e.Cluster.DNSRefreshRate != nil {
+			if err := validateDNSRefreshRate(*e.Cluster.DNSRefreshRate); err != nil {
+				return err
+			}
+		}
+		if e.Cluster.CircuitBreakers != nil {
+			if err := e.Cluster.CircuitBreakers.Validate(); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Listener.DrainType
+	if e.Listener != nil {
+		if err := e.Listener.DrainType.Validate(); err != nil {
+			return err
+		}
+		if e.Listener.RemovalDelay != nil {
+			if _, err := time.ParseDuration(*e.Listener.RemovalDelay); err != nil {
+				return fmt.Errorf("invalid listener removal delay %q: %w", *e.Listener.RemovalDelay, err)
+			}
+		}
+		if e.Listener.SocketOptions != nil && e.Listener.SocketOptions.TCPKeepalive != nil {
+			if err := e.Listener.SocketOptions.TCPKeepalive.Validate(); err != nil {
+				return err
+			}
+		}
+		if err := ValidateHTTPFilters(e.Listener.HTTPFilters); err != nil {
+			return err
+		}
+		if err := e.Listener.HTTPConnectionManager.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if err := e.Compression.Validate(); err != nil {
+		return err
+	}
+
+	if err := ValidateResponseMap(e.ResponseMap); err != nil {
+		return err
 	}
 
 	// Envoy TLS configuration
@@ -212,12 +698,102 @@ func (e *EnvoyLogging) Validate() error {
 	if err := e.AccessLogFormat.Validate(); err != nil {
 		return err
 	}
+	if err := e.AccessLogFormatPreset.Validate(); err != nil {
+		return err
+	}
 	if err := e.AccessLogJSONFields.Validate(); err != nil {
 		return err
 	}
+	if err := e.AccessLogGRPC.Validate(); err != nil {
+		return err
+	}
+	if err := e.AccessLogFilter.Validate(); err != nil {
+		return err
+	}
 	return AccessLogFormatString(e.AccessLogFormatString).Validate()
 }
 
+// Validate ensures that the gRPC access log service
+// configuration is valid.
+func (a *AccessLogGRPCConfig) Validate() error {
+	if a == nil {
+		return nil
+	}
+
+	if a.ExtensionService == nil {
+		return fmt.Errorf("accessLogGRPC.extensionService must be defined")
+	}
+
+	if a.LogName != nil && *a.LogName == "" {
+		return fmt.Errorf("accessLogGRPC.logName must not be empty")
+	}
+
+	if a.BufferFlushInterval != nil {
+		interval, err := time.ParseDuration(*a.BufferFlushInterval)
+		if err != nil {
+			return fmt.Errorf("invalid access log buffer flush interval %q: %v", *a.BufferFlushInterval, err)
+		}
+		if interval <= 0 {
+			return fmt.Errorf("invalid access log buffer flush interval %q: must be positive", *a.BufferFlushInterval)
+		}
+	}
+
+	return nil
+}
+
+// Validate ensures that the access log filter is valid.
+func (a *AccessLogFilter) Validate() error {
+	if a == nil {
+		return nil
+	}
+
+	if a.MinStatusCode != nil && (*a.MinStatusCode < 100 || *a.MinStatusCode > 599) {
+		return fmt.Errorf("invalid access log filter minimum status code %d: must be between 100 and 599", *a.MinStatusCode)
+	}
+
+	if a.MinDuration != nil {
+		duration, err := time.ParseDuration(*a.MinDuration)
+		if err != nil {
+			return fmt.Errorf("invalid access log filter minimum duration %q: %v", *a.MinDuration, err)
+		}
+		if duration <= 0 {
+			return fmt.Errorf("invalid access log filter minimum duration %q: must be positive", *a.MinDuration)
+		}
+	}
+
+	for _, header := range a.RequestHeaders {
+		if msgs := validation.IsHTTPHeaderName(header); len(msgs) != 0 {
+			return fmt.Errorf("invalid access log filter request header %q: %v", header, msgs)
+		}
+	}
+
+	if a.SamplePercent != nil && (*a.SamplePercent < 1 || *a.SamplePercent > 100) {
+		return fmt.Errorf("invalid access log filter sample percent %d: must be between 1 and 100", *a.SamplePercent)
+	}
+
+	return nil
+}
+
+// WithAccessLogFormatPreset returns the logging configuration with the
+// access log format, format string and JSON fields replaced by those
+// of AccessLogFormatPreset, or e unchanged if no preset is set.
+func (e *EnvoyLogging) WithAccessLogFormatPreset() *EnvoyLogging {
+	if e == nil {
+		return nil
+	}
+
+	preset, ok := accessLogFormatPresets[e.AccessLogFormatPreset]
+	if !ok {
+		return e
+	}
+
+	out := e.DeepCopy()
+	out.AccessLogFormat = preset.format
+	out.AccessLogFormatString = preset.formatString
+	out.AccessLogJSONFields = preset.jsonFields
+	return out
+}
+
 // AccessLogFormatterExtensions returns a list of formatter extension names required by the access log format.
 //
 // Note: When adding support for new formatter, update the list of extensions here and
@@ -257,6 +833,74 @@ func (e *EnvoyLogging) AccessLogFormatterExtensions() []string {
 	return extensions
 }
 
+// Validate ensures that the paths of the health listener endpoints are
+// valid and distinct, and that the minimum healthy percentages are at
+// most 100.
+func (h *EnvoyHealthListener) Validate() error {
+	if h == nil {
+		return nil
+	}
+
+	readinessPath := "/ready"
+	if h.ReadinessPath != nil {
+		readinessPath = *h.ReadinessPath
+		if err := validateHealthPath(readinessPath); err != nil {
+			return fmt.Errorf("invalid readiness path: %w", err)
+		}
+	}
+
+	if h.Liveness == nil {
+		return nil
+	}
+
+	if err := validateHealthPath(h.Liveness.Path); err != nil {
+		return fmt.Errorf("invalid liveness path: %w", err)
+	}
+	if h.Liveness.Path == readinessPath {
+		return fmt.Errorf("invalid liveness path %q: must differ from the readiness path", h.Liveness.Path)
+	}
+	for cluster, percentage := range h.Liveness.ClusterMinHealthyPercentages {
+		if cluster == "" {
+			return fmt.Errorf("invalid liveness min healthy percentage: cluster name must be set")
+		}
+		if percentage > 100 {
+			return fmt.Errorf("invalid liveness min healthy percentage %d of cluster %q: must be at most 100", percentage, cluster)
+		}
+	}
+
+	return nil
+}
+
+// validateHealthPath ensures that path is an absolute
+// path without a query, a fragment or whitespace.
+func validateHealthPath(path string) error {
+	if !strings.HasPrefix(path, "/") || strings.ContainsAny(path, "?# \t\r\n") {
+		return fmt.Errorf("%q must be an absolute path without a query, a fragment or whitespace", path)
+	}
+	return nil
+}
+
+// Validate ensures that the allowed subject alt names of the
+// metrics endpoint are not blank, and that client certificates
+// are verified when they are set.
+func (m *MetricsConfig) Validate() error {
+	if m == nil || m.TLS == nil || len(m.TLS.AllowedSubjectAltNames) == 0 {
+		return nil
+	}
+
+	if m.TLS.CAFile == "" {
+		return fmt.Errorf("metrics allowed subject alt names require a CA file")
+	}
+
+	for _, san := range m.TLS.AllowedSubjectAltNames {
+		if len(strings.TrimSpace(san)) == 0 {
+			return fmt.Errorf("metrics allowed subject alt names must not be blank")
+		}
+	}
+
+	return nil
+}
+
 // endpointsInConfict returns error if different protocol are configured to use single port.
 func endpointsInConfict(health *HealthConfig, metrics *MetricsConfig) error {
 	if health != nil && metrics != nil && metrics.TLS != nil && health.Address == metrics.Address && health.Port == metrics.Port {
@@ -264,3 +908,112 @@ func endpointsInConfict(health *HealthConfig, metrics *MetricsConfig) error {
 	}
 	return nil
 }
+
+// Validate ensures that the HTTP/2 keepalive interval and timeout are
+// valid durations, and that the timeout is at least 1ms, as Envoy requires.
+func (k *HTTP2KeepaliveConfig) Validate() error {
+	interval, err := time.ParseDuration(k.Interval)
+	if err != nil {
+		return fmt.Errorf("invalid HTTP/2 keepalive interval %q: %w", k.Interval, err)
+	}
+	if interval <= 0 {
+		return fmt.Errorf("invalid HTTP/2 keepalive interval %q: must be greater than zero", k.Interval)
+	}
+
+	timeout, err := time.ParseDuration(k.Timeout)
+	if err != nil {
+		return fmt.Errorf("invalid HTTP/2 keepalive timeout %q: %w", k.Timeout, err)
+	}
+	if timeout < time.Millisecond {
+		return fmt.Errorf("invalid HTTP/2 keepalive timeout %q: must be at least 1ms", k.Timeout)
+	}
+
+	return nil
+}
+
+// Validate ensures that the TCP keepalive durations are whole seconds.
+func (k *TCPKeepaliveConfig) Validate() error {
+	for _, value := range []*string{k.Idle, k.Interval} {
+		if value == nil {
+			continue
+		}
+
+		d, err := time.ParseDuration(*value)
+		if err != nil {
+			return fmt.Errorf("invalid TCP keepalive duration %q: %w", *value, err)
+		}
+		if d < time.Second || d%time.Second != 0 {
+			return fmt.Errorf("invalid TCP keepalive duration %q: must be a whole number of seconds", *value)
+		}
+	}
+
+	return nil
+}
+
+// ParseDNSResolver parses the address of a DNS resolver, formatted as
+// <ip> or <ip>:<port>, into its IP and port. The port defaults to 53.
+func ParseDNSResolver(resolver string) (string, uint32, error) {
+	if net.ParseIP(resolver) != nil {
+		return resolver, 53, nil
+	}
+
+	host, port, err := net.SplitHostPort(resolver)
+	if err != nil || net.ParseIP(host) == nil {
+		return "", 0, fmt.Errorf("invalid DNS resolver %q, must be <ip> or <ip>:<port>", resolver)
+	}
+	n, err := strconv.ParseUint(port, 10, 16)
+	if err != nil || n == 0 {
+		return "", 0, fmt.Errorf("invalid DNS resolver %q, invalid port %q", resolver, port)
+	}
+	return host, uint32(n), nil
+}
+
+// validateDNSRefreshRate ensures that the DNS refresh rate
+// is greater than the 1ms minimum of Envoy.
+func validateDNSRefreshRate(rate string) error {
+	d, err := time.ParseDuration(rate)
+	if err != nil {
+		return fmt.Errorf("invalid DNS refresh rate %q: %w", rate, err)
+	}
+	if d <= time.Millisecond {
+		return fmt.Errorf("invalid DNS refresh rate %q, must be greater than 1ms", rate)
+	}
+	return nil
+}
+
+// Validate ensures that the circuit breaker thresholds that are
+// specified are positive, since zero would reject all traffic.
+func (c *CircuitBreakers) Validate() error {
+	thresholds := []struct {
+		name  string
+		value *uint32
+	}{
+		{"max connections", c.MaxConnections},
+		{"max pending requests", c.MaxPendingRequests},
+		{"max requests", c.MaxRequests},
+		{"max retries", c.MaxRetries},
+		{"retry budget percent", c.RetryBudgetPercent},
+		{"retry budget min retry concurrency", c.RetryBudgetMinRetryConcurrency},
+	}
+	for _, threshold := range thresholds {
+		if threshold.value != nil && *threshold.value == 0 {
+			return fmt.Errorf("invalid circuit breaker %s 0, must be at least 1", threshold.name)
+		}
+	}
+	if c.RetryBudgetPercent != nil && *c.RetryBudgetPercent > 100 {
+		return fmt.Errorf("invalid circuit breaker retry budget percent %d, must be at most 100", *c.RetryBudgetPercent)
+	}
+	return nil
+}
+
+// GetConditionFor returns the a pointer to the condition for a given type,
+// or nil if there are none currently present.
+func (status *ContourConfigurationStatus) GetConditionFor(condType string) *contour_api_v1.DetailedCondition {
+	for i, cond := range status.Conditions {
+		if cond.Type == condType {
+			return &status.Conditions[i]
+		}
+	}
+
+	return nil
+}
@@ -38,6 +38,75 @@ func TestContourConfigurationSpecValidate(t *testing.T) {
 		require.Error(t, c.Validate())
 	})
 
+	t.Run("xds server authorization validation", func(t *testing.T) {
+		c := v1alpha1.ContourConfigurationSpec{
+			XDSServer: &v1alpha1.XDSServerConfig{
+				Type: v1alpha1.ContourServerType,
+				TLS: &v1alpha1.TLS{
+					Insecure: ref.To(false),
+				},
+				Authorization: &v1alpha1.XDSServerAuthorization{},
+			},
+		}
+		require.Error(t, c.Validate())
+
+		c.XDSServer.Authorization.AllowedSubjectAltNames = []string{"envoy"}
+		require.NoError(t, c.Validate())
+
+		c.XDSServer.Authorization.AllowedSubjectAltNames = []string{" "}
+		require.Error(t, c.Validate())
+
+		c.XDSServer.Authorization.AllowedSubjectAltNames = nil
+		c.XDSServer.Authorization.JWT = &v1alpha1.XDSServerJWTAuthorization{}
+		require.Error(t, c.Validate())
+
+		c.XDSServer.Authorization.JWT.KeyFile = "/keys/jwt.pem"
+		require.NoError(t, c.Validate())
+
+		c.XDSServer.TLS.Insecure = ref.To(true)
+		require.NoError(t, c.Validate())
+
+		c.XDSServer.Authorization.AllowedSubjectAltNames = []string{"envoy"}
+		require.Error(t, c.Validate())
+	})
+
+	t.Run("metrics authorization validation", func(t *testing.T) {
+		c := v1alpha1.ContourConfigurationSpec{
+			Metrics: &v1alpha1.MetricsConfig{
+				Address: "0.0.0.0",
+				Port:    8000,
+				TLS: &v1alpha1.MetricsTLS{
+					CertFile:               "/certs/tls.crt",
+					KeyFile:                "/certs/tls.key",
+					AllowedSubjectAltNames: []string{"prometheus"},
+				},
+				BearerTokenFile: "/tokens/metrics",
+			},
+			Envoy: &v1alpha1.EnvoyConfig{
+				Metrics: &v1alpha1.MetricsConfig{
+					Address: "0.0.0.0",
+					Port:    8002,
+				},
+			},
+		}
+		require.Error(t, c.Validate())
+
+		c.Metrics.TLS.CAFile = "/certs/ca.crt"
+		require.NoError(t, c.Validate())
+
+		c.Metrics.TLS.AllowedSubjectAltNames = []string{" "}
+		require.Error(t, c.Validate())
+
+		c.Metrics.TLS.AllowedSubjectAltNames = nil
+		c.Envoy.Metrics.TLS = &v1alpha1.MetricsTLS{
+			AllowedSubjectAltNames: []string{"prometheus"},
+		}
+		require.Error(t, c.Validate())
+
+		c.Envoy.Metrics.TLS.CAFile = "/certs/ca.crt"
+		require.NoError(t, c.Validate())
+	})
+
 	t.Run("envoy validation", func(t *testing.T) {
 		c := v1alpha1.ContourConfigurationSpec{
 			Envoy: &v1alpha1.EnvoyConfig{
@@ -98,8 +167,73 @@ func TestContourConfigurationSpecValidate(t *testing.T) {
 		require.NoError(t, c.Validate())
 
 		c.Envoy.Cluster.DNSLookupFamily = v1alpha1.AllClusterDNSFamily
+		c.Envoy.Cluster.StatNameFormat = v1alpha1.ServiceClusterStatNameFormat
 		require.NoError(t, c.Validate())
 
+		c.Envoy.Cluster.StatNameFormat = v1alpha1.ClusterNameClusterStatNameFormat
+		require.NoError(t, c.Validate())
+
+		c.Envoy.Cluster.StatNameFormat = "foo"
+		require.Error(t, c.Validate())
+
+		c.Envoy.Cluster.StatNameFormat = ""
+		require.NoError(t, c.Validate())
+
+		c.Envoy.Cluster.EndpointDeregistrationDelay = ref.To("10s")
+		require.NoError(t, c.Validate())
+
+		c.Envoy.Cluster.EndpointDeregistrationDelay = ref.To("foo")
+		require.Error(t, c.Validate())
+
+		c.Envoy.Cluster.EndpointDeregistrationDelay = nil
+
+		c.Envoy.Cluster.HTTP2Keepalive = &v1alpha1.HTTP2KeepaliveConfig{Interval: "30s", Timeout: "5s"}
+		require.NoError(t, c.Validate())
+
+		c.Envoy.Cluster.HTTP2Keepalive = &v1alpha1.HTTP2KeepaliveConfig{Interval: "foo", Timeout: "5s"}
+		require.Error(t, c.Validate())
+
+		c.Envoy.Cluster.HTTP2Keepalive = &v1alpha1.HTTP2KeepaliveConfig{Interval: "30s", Timeout: "500us"}
+		require.Error(t, c.Validate())
+
+		c.Envoy.Cluster.HTTP2Keepalive = nil
+
+		c.Envoy.Cluster.DNSResolvers = []string{"10.0.0.10", "[fd00::10]:5353"}
+		c.Envoy.Cluster.DNSRefreshRate = ref.To("30s")
+		require.NoError(t, c.Validate())
+
+		c.Envoy.Cluster.DNSResolvers = []string{"dns.example.com:53"}
+		require.Error(t, c.Validate())
+
+		c.Envoy.Cluster.DNSResolvers = nil
+		c.Envoy.Cluster.DNSRefreshRate = ref.To("1ms")
+		require.Error(t, c.Validate())
+
+		c.Envoy.Cluster.DNSRefreshRate = nil
+
+		c.Envoy.Cluster.CircuitBreakers = &v1alpha1.CircuitBreakers{
+			MaxConnections: ref.To(uint32(10000)),
+			MaxRequests:    ref.To(uint32(10000)),
+		}
+		require.NoError(t, c.Validate())
+
+		c.Envoy.Cluster.CircuitBreakers.MaxRetries = ref.To(uint32(0))
+		require.Error(t, c.Validate())
+
+		c.Envoy.Cluster.CircuitBreakers.MaxRetries = nil
+		c.Envoy.Cluster.CircuitBreakers.RetryBudgetPercent = ref.To(uint32(20))
+		c.Envoy.Cluster.CircuitBreakers.RetryBudgetMinRetryConcurrency = ref.To(uint32(3))
+		require.NoError(t, c.Validate())
+
+		c.Envoy.Cluster.CircuitBreakers.RetryBudgetPercent = ref.To(uint32(101))
+		require.Error(t, c.Validate())
+
+		c.Envoy.Cluster.CircuitBreakers.RetryBudgetPercent = ref.To(uint32(20))
+		c.Envoy.Cluster.CircuitBreakers.RetryBudgetMinRetryConcurrency = ref.To(uint32(0))
+		require.Error(t, c.Validate())
+
+		c.Envoy.Cluster.CircuitBreakers = nil
+
 		c.Envoy.Cluster.DNSLookupFamily = "foo"
 		require.Error(t, c.Validate())
 
@@ -112,6 +246,157 @@ func TestContourConfigurationSpecValidate(t *testing.T) {
 		}
 		require.NoError(t, c.Validate())
 
+		c.Envoy.Listener.DrainType = v1alpha1.ModifyOnlyListenerDrainType
+		require.NoError(t, c.Validate())
+
+		c.Envoy.Listener.DrainType = "drain-all"
+		require.Error(t, c.Validate())
+
+		c.Envoy.Listener.DrainType = v1alpha1.DefaultListenerDrainType
+		require.NoError(t, c.Validate())
+
+		c.Envoy.Listener.RemovalDelay = ref.To("foo")
+		require.Error(t, c.Validate())
+
+		c.Envoy.Listener.RemovalDelay = ref.To("30s")
+		require.NoError(t, c.Validate())
+
+		c.Envoy.Listener.SocketOptions = &v1alpha1.EnvoySocketOptions{
+			TCPKeepalive: &v1alpha1.TCPKeepaliveConfig{
+				Idle:     ref.To("60s"),
+				Interval: ref.To("10s"),
+			},
+		}
+		require.NoError(t, c.Validate())
+
+		c.Envoy.Listener.SocketOptions.TCPKeepalive.Idle = ref.To("foo")
+		require.Error(t, c.Validate())
+
+		c.Envoy.Listener.SocketOptions.TCPKeepalive.Idle = ref.To("1500ms")
+		require.Error(t, c.Validate())
+
+		c.Envoy.Listener.SocketOptions.TCPKeepalive.Idle = ref.To("60s")
+		c.Envoy.Listener.SocketOptions.TCPKeepalive.Interval = ref.To("0s")
+		require.Error(t, c.Validate())
+
+		c.Envoy.Listener.SocketOptions.TCPKeepalive.Interval = ref.To("10s")
+		require.NoError(t, c.Validate())
+
+		c.Envoy.Listener.HTTPFilters = []v1alpha1.HTTPFilter{{
+			Name:        "buffer",
+			TypedConfig: `{"@type": "type.googleapis.com/envoy.extensions.filters.http.buffer.v3.Buffer"}`,
+		}}
+		require.NoError(t, c.Validate())
+
+		c.Envoy.Listener.HTTPFilters[0].Position = "after-router"
+		require.Error(t, c.Validate())
+
+		c.Envoy.Listener.HTTPFilters[0].Position = v1alpha1.AfterCORSHTTPFilterPosition
+		require.NoError(t, c.Validate())
+
+		c.Envoy.Listener.HTTPFilters = append(c.Envoy.Listener.HTTPFilters, v1alpha1.HTTPFilter{Name: "buffer"})
+		require.Error(t, c.Validate())
+
+		c.Envoy.Listener.HTTPFilters[1].Name = "other"
+		require.Error(t, c.Validate())
+
+		c.Envoy.Listener.HTTPFilters[1].TypedConfig = c.Envoy.Listener.HTTPFilters[0].TypedConfig
+		require.NoError(t, c.Validate())
+
+		c.Envoy.Listener.HTTPConnectionManager = &v1alpha1.EnvoyHTTPConnectionManager{
+			ServerName:                ref.To("example"),
+			PreserveExternalRequestID: ref.To(false),
+			NormalizePath:             ref.To(false),
+		}
+		require.NoError(t, c.Validate())
+
+		c.Envoy.Listener.HTTPConnectionManager.ServerName = ref.To("example\r\nX-Injected: true")
+		require.Error(t, c.Validate())
+
+		c.Envoy.Listener.HTTPConnectionManager.ServerName = ref.To(" example")
+		require.Error(t, c.Validate())
+
+		c.Envoy.Listener.HTTPConnectionManager.ServerName = ref.To("example/1.0")
+		require.NoError(t, c.Validate())
+
+		c.Envoy.Listener.HTTPConnectionManager.Via = ref.To("1.1 example")
+		require.NoError(t, c.Validate())
+
+		c.Envoy.Listener.HTTPConnectionManager.Via = ref.To("1.1 example\n")
+		require.Error(t, c.Validate())
+
+		c.Envoy.Listener.HTTPConnectionManager.Via = nil
+
+		c.Envoy.Compression = &v1alpha1.EnvoyCompression{
+			Algorithm:        v1alpha1.BrotliCompression,
+			MinContentLength: ref.To(uint32(1024)),
+			ContentTypes:     []string{"text/html"},
+		}
+		require.NoError(t, c.Validate())
+
+		c.Envoy.Compression.Algorithm = "deflate"
+		require.Error(t, c.Validate())
+
+		c.Envoy.Compression.Algorithm = v1alpha1.DisabledCompression
+		c.Envoy.Compression.ContentTypes = []string{""}
+		require.Error(t, c.Validate())
+
+		c.Envoy.Compression.ContentTypes = nil
+		require.NoError(t, c.Validate())
+
+		c.Envoy.ResponseMap = []v1alpha1.ResponseMapping{{
+			StatusCode: 503,
+			JSONFormat: map[string]string{"code": "%RESPONSE_CODE%"},
+		}, {
+			StatusCode:        404,
+			RewriteStatusCode: 410,
+			Body:              "gone",
+		}}
+		require.NoError(t, c.Validate())
+
+		c.Envoy.ResponseMap[1].StatusCode = 503
+		require.Error(t, c.Validate())
+
+		c.Envoy.ResponseMap[1].StatusCode = 99
+		require.Error(t, c.Validate())
+
+		c.Envoy.ResponseMap[1].StatusCode = 404
+		c.Envoy.ResponseMap[1].RewriteStatusCode = 600
+		require.Error(t, c.Validate())
+
+		c.Envoy.ResponseMap[1] = v1alpha1.ResponseMapping{StatusCode: 404}
+		require.Error(t, c.Validate())
+
+		c.Envoy.ResponseMap = nil
+
+		c.Envoy.HealthListener = &v1alpha1.EnvoyHealthListener{
+			ReadinessPath: ref.To("/healthz/ready"),
+			Liveness: &v1alpha1.EnvoyLivenessEndpoint{
+				Path:                         "/healthz/live",
+				ClusterMinHealthyPercentages: map[string]uint32{"default/backend/80/da39a3ee5e": 50},
+			},
+		}
+		require.NoError(t, c.Validate())
+
+		c.Envoy.HealthListener.ReadinessPath = ref.To("healthz")
+		require.Error(t, c.Validate())
+
+		c.Envoy.HealthListener.ReadinessPath = ref.To("/healthz?ready")
+		require.Error(t, c.Validate())
+
+		c.Envoy.HealthListener.ReadinessPath = nil
+		c.Envoy.HealthListener.Liveness.Path = "/ready"
+		require.Error(t, c.Validate())
+
+		c.Envoy.HealthListener.Liveness.Path = "/healthz/live"
+		c.Envoy.HealthListener.Liveness.ClusterMinHealthyPercentages["default/backend/80/da39a3ee5e"] = 101
+		require.Error(t, c.Validate())
+
+		c.Envoy.HealthListener.Liveness.ClusterMinHealthyPercentages = map[string]uint32{"": 50}
+		require.Error(t, c.Validate())
+
+		c.Envoy.HealthListener = nil
+
 		c.Envoy.Listener.TLS.MinimumProtocolVersion = "invalid"
 		require.Error(t, c.Validate())
 
@@ -141,6 +426,58 @@ func TestContourConfigurationSpecValidate(t *testing.T) {
 			"AES128-GCM-SHA256",
 		}
 		require.Error(t, c.Validate())
+
+		c.Envoy.Listener.TLS.CipherSuites = nil
+		c.Envoy.Logging = &v1alpha1.EnvoyLogging{
+			AccessLogFormat: v1alpha1.EnvoyAccessLog,
+			AccessLogGRPC: &v1alpha1.AccessLogGRPCConfig{
+				ExtensionService: &v1alpha1.NamespacedName{
+					Namespace: "projectcontour",
+					Name:      "als",
+				},
+				LogName:             ref.To("contour"),
+				BufferFlushInterval: ref.To("2s"),
+			},
+		}
+		require.NoError(t, c.Validate())
+
+		c.Envoy.Logging.AccessLogGRPC.BufferFlushInterval = ref.To("0s")
+		require.Error(t, c.Validate())
+
+		c.Envoy.Logging.AccessLogGRPC.BufferFlushInterval = ref.To("invalid")
+		require.Error(t, c.Validate())
+
+		c.Envoy.Logging.AccessLogGRPC.BufferFlushInterval = nil
+		c.Envoy.Logging.AccessLogGRPC.LogName = ref.To("")
+		require.Error(t, c.Validate())
+
+		c.Envoy.Logging.AccessLogGRPC.LogName = nil
+		c.Envoy.Logging.AccessLogGRPC.ExtensionService = nil
+		require.Error(t, c.Validate())
+
+		c.Envoy.Logging.AccessLogGRPC = nil
+		c.Envoy.Logging.AccessLogFilter = &v1alpha1.AccessLogFilter{
+			MinStatusCode:  ref.To(uint32(400)),
+			MinDuration:    ref.To("500ms"),
+			RequestHeaders: []string{"X-Debug"},
+			SamplePercent:  ref.To(uint32(10)),
+		}
+		require.NoError(t, c.Validate())
+
+		c.Envoy.Logging.AccessLogFilter.MinStatusCode = ref.To(uint32(600))
+		require.Error(t, c.Validate())
+
+		c.Envoy.Logging.AccessLogFilter.MinStatusCode = nil
+		c.Envoy.Logging.AccessLogFilter.MinDuration = ref.To("-1s")
+		require.Error(t, c.Validate())
+
+		c.Envoy.Logging.AccessLogFilter.MinDuration = nil
+		c.Envoy.Logging.AccessLogFilter.RequestHeaders = []string{"X Debug"}
+		require.Error(t, c.Validate())
+
+		c.Envoy.Logging.AccessLogFilter.RequestHeaders = nil
+		c.Envoy.Logging.AccessLogFilter.SamplePercent = ref.To(uint32(0))
+		require.Error(t, c.Validate())
 	})
 
 	t.Run("gateway validation", func(t *testing.T) {
@@ -212,9 +549,229 @@ func TestContourConfigurationSpecValidate(t *testing.T) {
 		c.Tracing.CustomTags = customTags
 		require.Error(t, c.Validate())
 
+		c.Tracing.CustomTags = nil
+		c.Tracing.ForceTrace = &v1alpha1.ForceTraceConfig{}
+		require.Error(t, c.Validate())
+
+		c.Tracing.ForceTrace.AllowedClientCIDRs = []string{"10.0.0.0/8", "not-a-cidr"}
+		require.Error(t, c.Validate())
+
+		c.Tracing.ForceTrace.AllowedClientCIDRs = []string{"10.0.0.0/8", "fd00::/8"}
+		require.NoError(t, c.Validate())
+
+		c.Tracing.ForceTrace = nil
+		c.Tracing.Zipkin = &v1alpha1.ZipkinTracingConfig{}
+		require.Error(t, c.Validate())
+
+		c.Tracing.Provider = v1alpha1.ZipkinTracingProvider
+		require.NoError(t, c.Validate())
+
+		c.Tracing.Zipkin.CollectorEndpoint = "api/v2/spans"
+		require.Error(t, c.Validate())
+
+		c.Tracing.Zipkin.CollectorEndpoint = "/api/v2/spans"
+		c.Tracing.Zipkin.Encoding = "thrift"
+		require.Error(t, c.Validate())
+
+		c.Tracing.Zipkin.Encoding = v1alpha1.ProtoZipkinSpanEncoding
+		require.NoError(t, c.Validate())
+
+		c.Tracing.Provider = "jaeger"
+		require.Error(t, c.Validate())
+	})
+
+	t.Run("capture validation", func(t *testing.T) {
+		c := v1alpha1.ContourConfigurationSpec{
+			Capture: &v1alpha1.CaptureConfig{},
+		}
+		require.Error(t, c.Validate())
+
+		c.Capture.FilePathPrefix = "/var/log/envoy/capture"
+		require.NoError(t, c.Validate())
+
+		c.Capture.SamplePercent = ref.To(uint32(0))
+		require.Error(t, c.Validate())
+
+		c.Capture.SamplePercent = ref.To(uint32(101))
+		require.Error(t, c.Validate())
+
+		c.Capture.SamplePercent = ref.To(uint32(10))
+		require.NoError(t, c.Validate())
+	})
+
+	t.Run("secret backend validation", func(t *testing.T) {
+		c := v1alpha1.ContourConfigurationSpec{
+			SecretBackend: &v1alpha1.SecretBackendConfig{},
+		}
+		require.NoError(t, c.Validate())
+
+		c.SecretBackend.Directory = "/etc/envoy/certs"
+		require.Error(t, c.Validate())
+
+		c.SecretBackend.Type = v1alpha1.FileSecretBackend
+		require.NoError(t, c.Validate())
+
+		c.SecretBackend.Directory = ""
+		require.Error(t, c.Validate())
+
+		c.SecretBackend.Type = v1alpha1.SDSSecretBackend
+		require.Error(t, c.Validate())
+
+		c.SecretBackend.ExtensionService = &v1alpha1.NamespacedName{Namespace: "projectcontour", Name: "sds"}
+		require.NoError(t, c.Validate())
+
+		c.SecretBackend.Directory = "/etc/envoy/certs"
+		require.Error(t, c.Validate())
+
+		c.SecretBackend = &v1alpha1.SecretBackendConfig{Type: "vault"}
+		require.Error(t, c.Validate())
+	})
+
+	t.Run("secret encryption validation", func(t *testing.T) {
+		c := v1alpha1.ContourConfigurationSpec{
+			SecretEncryption: &v1alpha1.SecretEncryptionConfig{},
+		}
+		require.NoError(t, c.Validate())
+
+		c.SecretEncryption.UnsealCommand = []string{"aws", "kms", "decrypt"}
+		require.Error(t, c.Validate())
+
+		c.SecretEncryption.DataKeyFile = "/keys/data-key.enc"
+		require.NoError(t, c.Validate())
+	})
+
+	t.Run("insecure virtual hosts validation", func(t *testing.T) {
+		c := v1alpha1.ContourConfigurationSpec{
+			HTTPProxy: &v1alpha1.HTTPProxyConfig{},
+		}
+		require.NoError(t, c.Validate())
+
+		c.HTTPProxy.InsecureVirtualHosts = v1alpha1.ExplicitInsecureVirtualHosts
+		require.NoError(t, c.Validate())
+
+		c.HTTPProxy.InsecureVirtualHosts = v1alpha1.DisallowedInsecureVirtualHosts
+		require.NoError(t, c.Validate())
+
+		c.HTTPProxy.InsecureVirtualHosts = "foo"
+		require.Error(t, c.Validate())
+	})
+
+	t.Run("https redirect validation", func(t *testing.T) {
+		c := v1alpha1.ContourConfigurationSpec{
+			HTTPSRedirect: &v1alpha1.HTTPSRedirectConfig{},
+		}
+		require.NoError(t, c.Validate())
+
+		c.HTTPSRedirect.StatusCode = 308
+		require.NoError(t, c.Validate())
+
+		c.HTTPSRedirect.StatusCode = 302
+		require.Error(t, c.Validate())
+
+		c.HTTPSRedirect.StatusCode = 301
+		c.HTTPSRedirect.ExcludedPathPrefixes = []string{"/.well-known/"}
+		require.NoError(t, c.Validate())
+
+		c.HTTPSRedirect.ExcludedPathPrefixes = []string{".well-known"}
+		require.Error(t, c.Validate())
+	})
+
+	t.Run("runtime flags validation", func(t *testing.T) {
+		c := v1alpha1.ContourConfigurationSpec{
+			RuntimeFlags: map[string]uint32{"new-checkout": 25, "search.v2": 100},
+		}
+		require.NoError(t, c.Validate())
+
+		c.RuntimeFlags = map[string]uint32{"new-checkout": 101}
+		require.Error(t, c.Validate())
+
+		c.RuntimeFlags = map[string]uint32{"new checkout": 25}
+		require.Error(t, c.Validate())
+	})
+
+	t.Run("leader election validation", func(t *testing.T) {
+		c := v1alpha1.ContourConfigurationSpec{
+			LeaderElection: &v1alpha1.LeaderElectionConfig{
+				ResourceLock:  v1alpha1.LeasesResourceLock,
+				LeaseDuration: ref.To("15s"),
+				RenewDeadline: ref.To("10s"),
+				RetryPeriod:   ref.To("2s"),
+			},
+		}
+		require.NoError(t, c.Validate())
+
+		c.LeaderElection.ResourceLock = "configmapsleases"
+		require.EqualError(t, c.Validate(), `invalid leaderElection.resourceLock "configmapsleases", must be leases`)
+
+		c.LeaderElection.ResourceLock = v1alpha1.LeasesResourceLock
+		c.LeaderElection.RetryPeriod = ref.To("0s")
+		require.EqualError(t, c.Validate(), `invalid leaderElection.retryPeriod "0s", must be greater than zero`)
+
+		c.LeaderElection.RetryPeriod = ref.To("2s")
+		c.LeaderElection.LeaseDuration = ref.To("10s")
+		require.EqualError(t, c.Validate(), `invalid leaderElection.leaseDuration "10s", must be longer than renewDeadline "10s"`)
+
+		c.LeaderElection.LeaseDuration = ref.To("15s")
+		c.LeaderElection.RetryPeriod = ref.To("9s")
+		require.EqualError(t, c.Validate(), `invalid leaderElection.renewDeadline "10s", must be longer than 1.2 times retryPeriod "9s"`)
+	})
+
+	t.Run("httpproxy metrics validation", func(t *testing.T) {
+		c := v1alpha1.ContourConfigurationSpec{
+			HTTPProxyMetrics: &v1alpha1.HTTPProxyMetricsConfig{},
+		}
+		require.NoError(t, c.Validate())
+
+		c.HTTPProxyMetrics.ScrapeInterval = ref.To("15s")
+		require.NoError(t, c.Validate())
+
+		c.HTTPProxyMetrics.ScrapeInterval = ref.To("0s")
+		require.EqualError(t, c.Validate(), `invalid httpProxyMetrics.scrapeInterval "0s", must be greater than zero`)
+
+		c.HTTPProxyMetrics.ScrapeInterval = ref.To("15s")
+		c.Envoy = &v1alpha1.EnvoyConfig{
+			Metrics: &v1alpha1.MetricsConfig{
+				TLS: &v1alpha1.MetricsTLS{
+					CertFile: "cert.pem",
+					KeyFile:  "key.pem",
+				},
+			},
+		}
+		require.EqualError(t, c.Validate(), "httpProxyMetrics cannot be enabled when the Envoy metrics endpoint is served over HTTPS")
 	})
 }
 
+func TestParseDNSResolver(t *testing.T) {
+	tests := map[string]struct {
+		resolver string
+		wantIP   string
+		wantPort uint32
+		wantErr  bool
+	}{
+		"ipv4":              {resolver: "10.0.0.10", wantIP: "10.0.0.10", wantPort: 53},
+		"ipv4 with port":    {resolver: "10.0.0.10:5353", wantIP: "10.0.0.10", wantPort: 5353},
+		"ipv6":              {resolver: "fd00::10", wantIP: "fd00::10", wantPort: 53},
+		"ipv6 with port":    {resolver: "[fd00::10]:5353", wantIP: "fd00::10", wantPort: 5353},
+		"hostname":          {resolver: "dns.example.com", wantErr: true},
+		"invalid port":      {resolver: "10.0.0.10:dns", wantErr: true},
+		"zero port":         {resolver: "10.0.0.10:0", wantErr: true},
+		"port out of range": {resolver: "10.0.0.10:65536", wantErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			ip, port, err := v1alpha1.ParseDNSResolver(tc.resolver)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantIP, ip)
+			assert.Equal(t, tc.wantPort, port)
+		})
+	}
+}
+
 func TestSanitizeCipherSuites(t *testing.T) {
 	testCases := map[string]struct {
 		ciphers []string
@@ -276,3 +833,50 @@ func TestAccessLogFormatExtensions(t *testing.T) {
 	}
 	assert.Empty(t, e3.AccessLogFormatterExtensions())
 }
+
+func TestWithAccessLogFormatPreset(t *testing.T) {
+	// Without a preset, the configuration is unchanged.
+	e := &v1alpha1.EnvoyLogging{
+		AccessLogFormat:       v1alpha1.EnvoyAccessLog,
+		AccessLogFormatString: "%START_TIME%\n",
+		AccessLogLevel:        v1alpha1.LogLevelError,
+	}
+	assert.Equal(t, e, e.WithAccessLogFormatPreset())
+
+	tests := map[v1alpha1.AccessLogFormatPreset]v1alpha1.AccessLogType{
+		v1alpha1.ApacheCombinedAccessLogPreset: v1alpha1.EnvoyAccessLog,
+		v1alpha1.W3CAccessLogPreset:            v1alpha1.EnvoyAccessLog,
+		v1alpha1.JSONECSAccessLogPreset:        v1alpha1.JSONAccessLog,
+	}
+	for preset, format := range tests {
+		t.Run(string(preset), func(t *testing.T) {
+			e := &v1alpha1.EnvoyLogging{
+				AccessLogFormat:       v1alpha1.JSONAccessLog,
+				AccessLogFormatPreset: preset,
+				AccessLogFormatString: "%START_TIME%\n",
+				AccessLogJSONFields:   v1alpha1.AccessLogJSONFields{"method"},
+				AccessLogLevel:        v1alpha1.LogLevelError,
+			}
+
+			got := e.WithAccessLogFormatPreset()
+			require.NoError(t, got.Validate())
+			assert.Equal(t, format, got.AccessLogFormat)
+			assert.Equal(t, v1alpha1.LogLevelError, got.AccessLogLevel)
+			assert.Empty(t, got.AccessLogFormatterExtensions())
+
+			// The preset replaces the format string and
+			// JSON fields, whichever format it uses.
+			switch format {
+			case v1alpha1.EnvoyAccessLog:
+				assert.NotEqual(t, "%START_TIME%\n", got.AccessLogFormatString)
+				assert.Empty(t, got.AccessLogJSONFields)
+			case v1alpha1.JSONAccessLog:
+				assert.Empty(t, got.AccessLogFormatString)
+				assert.NotContains(t, got.AccessLogJSONFields, "method")
+			}
+
+			// The original configuration is not modified.
+			assert.Equal(t, "%START_TIME%\n", e.AccessLogFormatString)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NamespaceReportName is the name of the NamespaceReport that
+// Contour maintains in each namespace containing HTTPProxies.
+const NamespaceReportName = "contour"
+
+// NamespaceReportProblemLimit is the maximum number of problems
+// recorded in a NamespaceReport's status.
+const NamespaceReportProblemLimit = 50
+
+// NamespaceReportProblem describes a single HTTPProxy in the namespace
+// that is not valid.
+type NamespaceReportProblem struct {
+	// Kind of the resource with the problem, e.g. HTTPProxy.
+	Kind string `json:"kind"`
+
+	// Name of the resource with the problem.
+	Name string `json:"name"`
+
+	// Reason is the type of the first error reported for the resource,
+	// e.g. `Orphaned` or `TLSError`.
+	Reason string `json:"reason"`
+
+	// Message is a human readable description of the problem.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// NamespaceReportStatus summarizes the state of the Contour
+// configuration in a single namespace.
+type NamespaceReportStatus struct {
+	// ValidProxies is the number of valid HTTPProxies in the namespace.
+	ValidProxies int `json:"validProxies"`
+
+	// InvalidProxies is the number of HTTPProxies in the namespace
+	// that have errors, not counting orphaned HTTPProxies.
+	InvalidProxies int `json:"invalidProxies"`
+
+	// OrphanedProxies is the number of HTTPProxies in the namespace
+	// that are not part of a delegation chain from a root HTTPProxy.
+	OrphanedProxies int `json:"orphanedProxies"`
+
+	// CertificateErrors is the number of HTTPProxies in the namespace
+	// that have TLS certificate errors.
+	CertificateErrors int `json:"certificateErrors"`
+
+	// Problems lists the HTTPProxies in the namespace that are not valid,
+	// sorted by name. At most 50 problems are recorded.
+	//
+	// +optional
+	// +kubebuilder:validation:MaxItems=50
+	Problems []NamespaceReportProblem `json:"problems,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Namespaced,shortName=nsreport;nsreports
+// +kubebuilder:printcolumn:name="Valid",type="integer",JSONPath=".status.validProxies",description="Number of valid HTTPProxies"
+// +kubebuilder:printcolumn:name="Invalid",type="integer",JSONPath=".status.invalidProxies",description="Number of invalid HTTPProxies"
+// +kubebuilder:printcolumn:name="Orphaned",type="integer",JSONPath=".status.orphanedProxies",description="Number of orphaned HTTPProxies"
+// +kubebuilder:printcolumn:name="Certificate Errors",type="integer",JSONPath=".status.certificateErrors",description="Number of HTTPProxies with certificate errors"
+
+// NamespaceReport is a read-only summary of the health of the Contour
+// configuration in a namespace. It is maintained by the Contour leader
+// so that namespace owners can audit their HTTPProxies without needing
+// cluster-wide permissions.
+type NamespaceReport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status NamespaceReportStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// NamespaceReportList contains a list of NamespaceReport resources.
+type NamespaceReportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NamespaceReport `json:"items"`
+}
@@ -29,6 +29,76 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccessLogFilter) DeepCopyInto(out *AccessLogFilter) {
+	*out = *in
+	if in.MinStatusCode != nil {
+		in, out := &in.MinStatusCode, &out.MinStatusCode
+		*out = new(uint32)
+		**out = **in
+	}
+	if in.MinDuration != nil {
+		in, out := &in.MinDuration, &out.MinDuration
+		*out = new(string)
+		**out = **in
+	}
+	if in.RequestHeaders != nil {
+		in, out := &in.RequestHeaders, &out.RequestHeaders
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SamplePercent != nil {
+		in, out := &in.SamplePercent, &out.SamplePercent
+		*out = new(uint32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessLogFilter.
+func (in *AccessLogFilter) DeepCopy() *AccessLogFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(AccessLogFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccessLogGRPCConfig) DeepCopyInto(out *AccessLogGRPCConfig) {
+	*out = *in
+	if in.ExtensionService != nil {
+		in, out := &in.ExtensionService, &out.ExtensionService
+		*out = new(NamespacedName)
+		**out = **in
+	}
+	if in.LogName != nil {
+		in, out := &in.LogName, &out.LogName
+		*out = new(string)
+		**out = **in
+	}
+	if in.BufferSizeBytes != nil {
+		in, out := &in.BufferSizeBytes, &out.BufferSizeBytes
+		*out = new(uint32)
+		**out = **in
+	}
+	if in.BufferFlushInterval != nil {
+		in, out := &in.BufferFlushInterval, &out.BufferFlushInterval
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessLogGRPCConfig.
+func (in *AccessLogGRPCConfig) DeepCopy() *AccessLogGRPCConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(AccessLogGRPCConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in AccessLogJSONFields) DeepCopyInto(out *AccessLogJSONFields) {
 	{
@@ -48,6 +118,76 @@ func (in AccessLogJSONFields) DeepCopy() AccessLogJSONFields {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CaptureConfig) DeepCopyInto(out *CaptureConfig) {
+	*out = *in
+	if in.SamplePercent != nil {
+		in, out := &in.SamplePercent, &out.SamplePercent
+		*out = new(uint32)
+		**out = **in
+	}
+	if in.MaxBufferedBytes != nil {
+		in, out := &in.MaxBufferedBytes, &out.MaxBufferedBytes
+		*out = new(uint32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CaptureConfig.
+func (in *CaptureConfig) DeepCopy() *CaptureConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CaptureConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CircuitBreakers) DeepCopyInto(out *CircuitBreakers) {
+	*out = *in
+	if in.MaxConnections != nil {
+		in, out := &in.MaxConnections, &out.MaxConnections
+		*out = new(uint32)
+		**out = **in
+	}
+	if in.MaxPendingRequests != nil {
+		in, out := &in.MaxPendingRequests, &out.MaxPendingRequests
+		*out = new(uint32)
+		**out = **in
+	}
+	if in.MaxRequests != nil {
+		in, out := &in.MaxRequests, &out.MaxRequests
+		*out = new(uint32)
+		**out = **in
+	}
+	if in.MaxRetries != nil {
+		in, out := &in.MaxRetries, &out.MaxRetries
+		*out = new(uint32)
+		**out = **in
+	}
+	if in.RetryBudgetPercent != nil {
+		in, out := &in.RetryBudgetPercent, &out.RetryBudgetPercent
+		*out = new(uint32)
+		**out = **in
+	}
+	if in.RetryBudgetMinRetryConcurrency != nil {
+		in, out := &in.RetryBudgetMinRetryConcurrency, &out.RetryBudgetMinRetryConcurrency
+		*out = new(uint32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CircuitBreakers.
+func (in *CircuitBreakers) DeepCopy() *CircuitBreakers {
+	if in == nil {
+		return nil
+	}
+	out := new(CircuitBreakers)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterParameters) DeepCopyInto(out *ClusterParameters) {
 	*out = *in
@@ -61,6 +201,41 @@ func (in *ClusterParameters) DeepCopyInto(out *ClusterParameters) {
 		*out = new(uint32)
 		**out = **in
 	}
+	if in.MaxStatNameLength != nil {
+		in, out := &in.MaxStatNameLength, &out.MaxStatNameLength
+		*out = new(uint32)
+		**out = **in
+	}
+	if in.EndpointDeregistrationDelay != nil {
+		in, out := &in.EndpointDeregistrationDelay, &out.EndpointDeregistrationDelay
+		*out = new(string)
+		**out = **in
+	}
+	if in.HTTP2Keepalive != nil {
+		in, out := &in.HTTP2Keepalive, &out.HTTP2Keepalive
+		*out = new(HTTP2KeepaliveConfig)
+		**out = **in
+	}
+	if in.HTTP2 != nil {
+		in, out := &in.HTTP2, &out.HTTP2
+		*out = new(UpstreamHTTP2)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DNSResolvers != nil {
+		in, out := &in.DNSResolvers, &out.DNSResolvers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DNSRefreshRate != nil {
+		in, out := &in.DNSRefreshRate, &out.DNSRefreshRate
+		*out = new(string)
+		**out = **in
+	}
+	if in.CircuitBreakers != nil {
+		in, out := &in.CircuitBreakers, &out.CircuitBreakers
+		*out = new(CircuitBreakers)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterParameters.
@@ -195,11 +370,55 @@ func (in *ContourConfigurationSpec) DeepCopyInto(out *ContourConfigurationSpec)
 		*out = new(MetricsConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.HTTPProxyMetrics != nil {
+		in, out := &in.HTTPProxyMetrics, &out.HTTPProxyMetrics
+		*out = new(HTTPProxyMetricsConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Tracing != nil {
 		in, out := &in.Tracing, &out.Tracing
 		*out = new(TracingConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Capture != nil {
+		in, out := &in.Capture, &out.Capture
+		*out = new(CaptureConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretBackend != nil {
+		in, out := &in.SecretBackend, &out.SecretBackend
+		*out = new(SecretBackendConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretEncryption != nil {
+		in, out := &in.SecretEncryption, &out.SecretEncryption
+		*out = new(SecretEncryptionConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HTTPSRedirect != nil {
+		in, out := &in.HTTPSRedirect, &out.HTTPSRedirect
+		*out = new(HTTPSRedirectConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RuntimeFlags != nil {
+		in, out := &in.RuntimeFlags, &out.RuntimeFlags
+		*out = make(map[string]uint32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.FeatureGates != nil {
+		in, out := &in.FeatureGates, &out.FeatureGates
+		*out = make(map[string]bool, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.LeaderElection != nil {
+		in, out := &in.LeaderElection, &out.LeaderElection
+		*out = new(LeaderElectionConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContourConfigurationSpec.
@@ -455,6 +674,31 @@ func (in *DeploymentSettings) DeepCopy() *DeploymentSettings {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnvoyCompression) DeepCopyInto(out *EnvoyCompression) {
+	*out = *in
+	if in.MinContentLength != nil {
+		in, out := &in.MinContentLength, &out.MinContentLength
+		*out = new(uint32)
+		**out = **in
+	}
+	if in.ContentTypes != nil {
+		in, out := &in.ContentTypes, &out.ContentTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvoyCompression.
+func (in *EnvoyCompression) DeepCopy() *EnvoyCompression {
+	if in == nil {
+		return nil
+	}
+	out := new(EnvoyCompression)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EnvoyConfig) DeepCopyInto(out *EnvoyConfig) {
 	*out = *in
@@ -483,6 +727,11 @@ func (in *EnvoyConfig) DeepCopyInto(out *EnvoyConfig) {
 		*out = new(HealthConfig)
 		**out = **in
 	}
+	if in.HealthListener != nil {
+		in, out := &in.HealthListener, &out.HealthListener
+		*out = new(EnvoyHealthListener)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Metrics != nil {
 		in, out := &in.Metrics, &out.Metrics
 		*out = new(MetricsConfig)
@@ -518,6 +767,18 @@ func (in *EnvoyConfig) DeepCopyInto(out *EnvoyConfig) {
 		*out = new(NetworkParameters)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Compression != nil {
+		in, out := &in.Compression, &out.Compression
+		*out = new(EnvoyCompression)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ResponseMap != nil {
+		in, out := &in.ResponseMap, &out.ResponseMap
+		*out = make([]ResponseMapping, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvoyConfig.
@@ -530,6 +791,121 @@ func (in *EnvoyConfig) DeepCopy() *EnvoyConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnvoyHTTP2) DeepCopyInto(out *EnvoyHTTP2) {
+	*out = *in
+	if in.MaxConcurrentStreams != nil {
+		in, out := &in.MaxConcurrentStreams, &out.MaxConcurrentStreams
+		*out = new(uint32)
+		**out = **in
+	}
+	if in.InitialStreamWindowSize != nil {
+		in, out := &in.InitialStreamWindowSize, &out.InitialStreamWindowSize
+		*out = new(uint32)
+		**out = **in
+	}
+	if in.InitialConnectionWindowSize != nil {
+		in, out := &in.InitialConnectionWindowSize, &out.InitialConnectionWindowSize
+		*out = new(uint32)
+		**out = **in
+	}
+	if in.MaxOutboundFrames != nil {
+		in, out := &in.MaxOutboundFrames, &out.MaxOutboundFrames
+		*out = new(uint32)
+		**out = **in
+	}
+	if in.MaxOutboundControlFrames != nil {
+		in, out := &in.MaxOutboundControlFrames, &out.MaxOutboundControlFrames
+		*out = new(uint32)
+		**out = **in
+	}
+	if in.MaxConsecutiveInboundFramesWithEmptyPayload != nil {
+		in, out := &in.MaxConsecutiveInboundFramesWithEmptyPayload, &out.MaxConsecutiveInboundFramesWithEmptyPayload
+		*out = new(uint32)
+		**out = **in
+	}
+	if in.MaxInboundPriorityFramesPerStream != nil {
+		in, out := &in.MaxInboundPriorityFramesPerStream, &out.MaxInboundPriorityFramesPerStream
+		*out = new(uint32)
+		**out = **in
+	}
+	if in.MaxInboundWindowUpdateFramesPerDataFrameSent != nil {
+		in, out := &in.MaxInboundWindowUpdateFramesPerDataFrameSent, &out.MaxInboundWindowUpdateFramesPerDataFrameSent
+		*out = new(uint32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvoyHTTP2.
+func (in *EnvoyHTTP2) DeepCopy() *EnvoyHTTP2 {
+	if in == nil {
+		return nil
+	}
+	out := new(EnvoyHTTP2)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnvoyHTTPConnectionManager) DeepCopyInto(out *EnvoyHTTPConnectionManager) {
+	*out = *in
+	if in.ServerName != nil {
+		in, out := &in.ServerName, &out.ServerName
+		*out = new(string)
+		**out = **in
+	}
+	if in.PreserveExternalRequestID != nil {
+		in, out := &in.PreserveExternalRequestID, &out.PreserveExternalRequestID
+		*out = new(bool)
+		**out = **in
+	}
+	if in.NormalizePath != nil {
+		in, out := &in.NormalizePath, &out.NormalizePath
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Via != nil {
+		in, out := &in.Via, &out.Via
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvoyHTTPConnectionManager.
+func (in *EnvoyHTTPConnectionManager) DeepCopy() *EnvoyHTTPConnectionManager {
+	if in == nil {
+		return nil
+	}
+	out := new(EnvoyHTTPConnectionManager)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnvoyHealthListener) DeepCopyInto(out *EnvoyHealthListener) {
+	*out = *in
+	if in.ReadinessPath != nil {
+		in, out := &in.ReadinessPath, &out.ReadinessPath
+		*out = new(string)
+		**out = **in
+	}
+	if in.Liveness != nil {
+		in, out := &in.Liveness, &out.Liveness
+		*out = new(EnvoyLivenessEndpoint)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvoyHealthListener.
+func (in *EnvoyHealthListener) DeepCopy() *EnvoyHealthListener {
+	if in == nil {
+		return nil
+	}
+	out := new(EnvoyHealthListener)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EnvoyListener) DeepCopyInto(out *EnvoyListener) {
 	*out = *in
@@ -563,6 +939,11 @@ func (in *EnvoyListenerConfig) DeepCopyInto(out *EnvoyListenerConfig) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.RemovalDelay != nil {
+		in, out := &in.RemovalDelay, &out.RemovalDelay
+		*out = new(string)
+		**out = **in
+	}
 	if in.MaxRequestsPerConnection != nil {
 		in, out := &in.MaxRequestsPerConnection, &out.MaxRequestsPerConnection
 		*out = new(uint32)
@@ -573,6 +954,26 @@ func (in *EnvoyListenerConfig) DeepCopyInto(out *EnvoyListenerConfig) {
 		*out = new(uint32)
 		**out = **in
 	}
+	if in.HTTP2 != nil {
+		in, out := &in.HTTP2, &out.HTTP2
+		*out = new(EnvoyHTTP2)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SocketOptions != nil {
+		in, out := &in.SocketOptions, &out.SocketOptions
+		*out = new(EnvoySocketOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HTTPFilters != nil {
+		in, out := &in.HTTPFilters, &out.HTTPFilters
+		*out = make([]HTTPFilter, len(*in))
+		copy(*out, *in)
+	}
+	if in.HTTPConnectionManager != nil {
+		in, out := &in.HTTPConnectionManager, &out.HTTPConnectionManager
+		*out = new(EnvoyHTTPConnectionManager)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.TLS != nil {
 		in, out := &in.TLS, &out.TLS
 		*out = new(EnvoyTLS)
@@ -591,29 +992,61 @@ func (in *EnvoyListenerConfig) DeepCopy() *EnvoyListenerConfig {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EnvoyLogging) DeepCopyInto(out *EnvoyLogging) {
+func (in *EnvoyLivenessEndpoint) DeepCopyInto(out *EnvoyLivenessEndpoint) {
 	*out = *in
-	if in.AccessLogJSONFields != nil {
-		in, out := &in.AccessLogJSONFields, &out.AccessLogJSONFields
-		*out = make(AccessLogJSONFields, len(*in))
-		copy(*out, *in)
+	if in.ClusterMinHealthyPercentages != nil {
+		in, out := &in.ClusterMinHealthyPercentages, &out.ClusterMinHealthyPercentages
+		*out = make(map[string]uint32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvoyLogging.
-func (in *EnvoyLogging) DeepCopy() *EnvoyLogging {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvoyLivenessEndpoint.
+func (in *EnvoyLivenessEndpoint) DeepCopy() *EnvoyLivenessEndpoint {
 	if in == nil {
 		return nil
 	}
-	out := new(EnvoyLogging)
+	out := new(EnvoyLivenessEndpoint)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EnvoySettings) DeepCopyInto(out *EnvoySettings) {
+func (in *EnvoyLogging) DeepCopyInto(out *EnvoyLogging) {
 	*out = *in
-	if in.NetworkPublishing != nil {
+	if in.AccessLogJSONFields != nil {
+		in, out := &in.AccessLogJSONFields, &out.AccessLogJSONFields
+		*out = make(AccessLogJSONFields, len(*in))
+		copy(*out, *in)
+	}
+	if in.AccessLogGRPC != nil {
+		in, out := &in.AccessLogGRPC, &out.AccessLogGRPC
+		*out = new(AccessLogGRPCConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AccessLogFilter != nil {
+		in, out := &in.AccessLogFilter, &out.AccessLogFilter
+		*out = new(AccessLogFilter)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvoyLogging.
+func (in *EnvoyLogging) DeepCopy() *EnvoyLogging {
+	if in == nil {
+		return nil
+	}
+	out := new(EnvoyLogging)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnvoySettings) DeepCopyInto(out *EnvoySettings) {
+	*out = *in
+	if in.NetworkPublishing != nil {
 		in, out := &in.NetworkPublishing, &out.NetworkPublishing
 		*out = new(NetworkPublishing)
 		(*in).DeepCopyInto(*out)
@@ -667,6 +1100,41 @@ func (in *EnvoySettings) DeepCopy() *EnvoySettings {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnvoySocketOptions) DeepCopyInto(out *EnvoySocketOptions) {
+	*out = *in
+	if in.TCPKeepalive != nil {
+		in, out := &in.TCPKeepalive, &out.TCPKeepalive
+		*out = new(TCPKeepaliveConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TOS != nil {
+		in, out := &in.TOS, &out.TOS
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TrafficClass != nil {
+		in, out := &in.TrafficClass, &out.TrafficClass
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Freebind != nil {
+		in, out := &in.Freebind, &out.Freebind
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvoySocketOptions.
+func (in *EnvoySocketOptions) DeepCopy() *EnvoySocketOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(EnvoySocketOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EnvoyTLS) DeepCopyInto(out *EnvoyTLS) {
 	*out = *in
@@ -772,7 +1240,7 @@ func (in *ExtensionServiceSpec) DeepCopyInto(out *ExtensionServiceSpec) {
 	if in.TimeoutPolicy != nil {
 		in, out := &in.TimeoutPolicy, &out.TimeoutPolicy
 		*out = new(v1.TimeoutPolicy)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -823,6 +1291,26 @@ func (in *ExtensionServiceTarget) DeepCopy() *ExtensionServiceTarget {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ForceTraceConfig) DeepCopyInto(out *ForceTraceConfig) {
+	*out = *in
+	if in.AllowedClientCIDRs != nil {
+		in, out := &in.AllowedClientCIDRs, &out.AllowedClientCIDRs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ForceTraceConfig.
+func (in *ForceTraceConfig) DeepCopy() *ForceTraceConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ForceTraceConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GatewayConfig) DeepCopyInto(out *GatewayConfig) {
 	*out = *in
@@ -843,6 +1331,36 @@ func (in *GatewayConfig) DeepCopy() *GatewayConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTP2KeepaliveConfig) DeepCopyInto(out *HTTP2KeepaliveConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTP2KeepaliveConfig.
+func (in *HTTP2KeepaliveConfig) DeepCopy() *HTTP2KeepaliveConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTP2KeepaliveConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPFilter) DeepCopyInto(out *HTTPFilter) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPFilter.
+func (in *HTTPFilter) DeepCopy() *HTTPFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HTTPProxyConfig) DeepCopyInto(out *HTTPProxyConfig) {
 	*out = *in
@@ -861,6 +1379,11 @@ func (in *HTTPProxyConfig) DeepCopyInto(out *HTTPProxyConfig) {
 		*out = new(NamespacedName)
 		**out = **in
 	}
+	if in.EnableNamespaceReports != nil {
+		in, out := &in.EnableNamespaceReports, &out.EnableNamespaceReports
+		*out = new(bool)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPProxyConfig.
@@ -873,6 +1396,51 @@ func (in *HTTPProxyConfig) DeepCopy() *HTTPProxyConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPProxyMetricsConfig) DeepCopyInto(out *HTTPProxyMetricsConfig) {
+	*out = *in
+	if in.ScrapeInterval != nil {
+		in, out := &in.ScrapeInterval, &out.ScrapeInterval
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPProxyMetricsConfig.
+func (in *HTTPProxyMetricsConfig) DeepCopy() *HTTPProxyMetricsConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPProxyMetricsConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPSRedirectConfig) DeepCopyInto(out *HTTPSRedirectConfig) {
+	*out = *in
+	if in.StripQuery != nil {
+		in, out := &in.StripQuery, &out.StripQuery
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ExcludedPathPrefixes != nil {
+		in, out := &in.ExcludedPathPrefixes, &out.ExcludedPathPrefixes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPSRedirectConfig.
+func (in *HTTPSRedirectConfig) DeepCopy() *HTTPSRedirectConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPSRedirectConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HeadersPolicy) DeepCopyInto(out *HeadersPolicy) {
 	*out = *in
@@ -935,13 +1503,48 @@ func (in *IngressConfig) DeepCopy() *IngressConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LeaderElectionConfig) DeepCopyInto(out *LeaderElectionConfig) {
+	*out = *in
+	if in.Disable != nil {
+		in, out := &in.Disable, &out.Disable
+		*out = new(bool)
+		**out = **in
+	}
+	if in.LeaseDuration != nil {
+		in, out := &in.LeaseDuration, &out.LeaseDuration
+		*out = new(string)
+		**out = **in
+	}
+	if in.RenewDeadline != nil {
+		in, out := &in.RenewDeadline, &out.RenewDeadline
+		*out = new(string)
+		**out = **in
+	}
+	if in.RetryPeriod != nil {
+		in, out := &in.RetryPeriod, &out.RetryPeriod
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LeaderElectionConfig.
+func (in *LeaderElectionConfig) DeepCopy() *LeaderElectionConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(LeaderElectionConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MetricsConfig) DeepCopyInto(out *MetricsConfig) {
 	*out = *in
 	if in.TLS != nil {
 		in, out := &in.TLS, &out.TLS
 		*out = new(MetricsTLS)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -958,6 +1561,11 @@ func (in *MetricsConfig) DeepCopy() *MetricsConfig {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MetricsTLS) DeepCopyInto(out *MetricsTLS) {
 	*out = *in
+	if in.AllowedSubjectAltNames != nil {
+		in, out := &in.AllowedSubjectAltNames, &out.AllowedSubjectAltNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricsTLS.
@@ -970,6 +1578,99 @@ func (in *MetricsTLS) DeepCopy() *MetricsTLS {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceReport) DeepCopyInto(out *NamespaceReport) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceReport.
+func (in *NamespaceReport) DeepCopy() *NamespaceReport {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceReport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NamespaceReport) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceReportList) DeepCopyInto(out *NamespaceReportList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NamespaceReport, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceReportList.
+func (in *NamespaceReportList) DeepCopy() *NamespaceReportList {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceReportList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NamespaceReportList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceReportProblem) DeepCopyInto(out *NamespaceReportProblem) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceReportProblem.
+func (in *NamespaceReportProblem) DeepCopy() *NamespaceReportProblem {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceReportProblem)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceReportStatus) DeepCopyInto(out *NamespaceReportStatus) {
+	*out = *in
+	if in.Problems != nil {
+		in, out := &in.Problems, &out.Problems
+		*out = make([]NamespaceReportProblem, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceReportStatus.
+func (in *NamespaceReportStatus) DeepCopy() *NamespaceReportStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceReportStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NamespacedName) DeepCopyInto(out *NamespacedName) {
 	*out = *in
@@ -1127,6 +1828,98 @@ func (in *RateLimitServiceConfig) DeepCopy() *RateLimitServiceConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResponseMapping) DeepCopyInto(out *ResponseMapping) {
+	*out = *in
+	if in.JSONFormat != nil {
+		in, out := &in.JSONFormat, &out.JSONFormat
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResponseMapping.
+func (in *ResponseMapping) DeepCopy() *ResponseMapping {
+	if in == nil {
+		return nil
+	}
+	out := new(ResponseMapping)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretBackendConfig) DeepCopyInto(out *SecretBackendConfig) {
+	*out = *in
+	if in.ExtensionService != nil {
+		in, out := &in.ExtensionService, &out.ExtensionService
+		*out = new(NamespacedName)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretBackendConfig.
+func (in *SecretBackendConfig) DeepCopy() *SecretBackendConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretBackendConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretEncryptionConfig) DeepCopyInto(out *SecretEncryptionConfig) {
+	*out = *in
+	if in.UnsealCommand != nil {
+		in, out := &in.UnsealCommand, &out.UnsealCommand
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretEncryptionConfig.
+func (in *SecretEncryptionConfig) DeepCopy() *SecretEncryptionConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretEncryptionConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TCPKeepaliveConfig) DeepCopyInto(out *TCPKeepaliveConfig) {
+	*out = *in
+	if in.Idle != nil {
+		in, out := &in.Idle, &out.Idle
+		*out = new(string)
+		**out = **in
+	}
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(string)
+		**out = **in
+	}
+	if in.Probes != nil {
+		in, out := &in.Probes, &out.Probes
+		*out = new(uint32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TCPKeepaliveConfig.
+func (in *TCPKeepaliveConfig) DeepCopy() *TCPKeepaliveConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(TCPKeepaliveConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TLS) DeepCopyInto(out *TLS) {
 	*out = *in
@@ -1185,6 +1978,16 @@ func (in *TimeoutParameters) DeepCopyInto(out *TimeoutParameters) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.ResponseTimeoutReply != nil {
+		in, out := &in.ResponseTimeoutReply, &out.ResponseTimeoutReply
+		*out = new(v1.TimeoutReply)
+		**out = **in
+	}
+	if in.IdleTimeoutReply != nil {
+		in, out := &in.IdleTimeoutReply, &out.IdleTimeoutReply
+		*out = new(v1.TimeoutReply)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TimeoutParameters.
@@ -1236,6 +2039,16 @@ func (in *TracingConfig) DeepCopyInto(out *TracingConfig) {
 		*out = new(NamespacedName)
 		**out = **in
 	}
+	if in.Zipkin != nil {
+		in, out := &in.Zipkin, &out.Zipkin
+		*out = new(ZipkinTracingConfig)
+		**out = **in
+	}
+	if in.ForceTrace != nil {
+		in, out := &in.ForceTrace, &out.ForceTrace
+		*out = new(ForceTraceConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TracingConfig.
@@ -1248,6 +2061,61 @@ func (in *TracingConfig) DeepCopy() *TracingConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpstreamHTTP2) DeepCopyInto(out *UpstreamHTTP2) {
+	*out = *in
+	if in.MaxConcurrentStreams != nil {
+		in, out := &in.MaxConcurrentStreams, &out.MaxConcurrentStreams
+		*out = new(uint32)
+		**out = **in
+	}
+	if in.InitialStreamWindowSize != nil {
+		in, out := &in.InitialStreamWindowSize, &out.InitialStreamWindowSize
+		*out = new(uint32)
+		**out = **in
+	}
+	if in.InitialConnectionWindowSize != nil {
+		in, out := &in.InitialConnectionWindowSize, &out.InitialConnectionWindowSize
+		*out = new(uint32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpstreamHTTP2.
+func (in *UpstreamHTTP2) DeepCopy() *UpstreamHTTP2 {
+	if in == nil {
+		return nil
+	}
+	out := new(UpstreamHTTP2)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *XDSServerAuthorization) DeepCopyInto(out *XDSServerAuthorization) {
+	*out = *in
+	if in.AllowedSubjectAltNames != nil {
+		in, out := &in.AllowedSubjectAltNames, &out.AllowedSubjectAltNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.JWT != nil {
+		in, out := &in.JWT, &out.JWT
+		*out = new(XDSServerJWTAuthorization)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new XDSServerAuthorization.
+func (in *XDSServerAuthorization) DeepCopy() *XDSServerAuthorization {
+	if in == nil {
+		return nil
+	}
+	out := new(XDSServerAuthorization)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *XDSServerConfig) DeepCopyInto(out *XDSServerConfig) {
 	*out = *in
@@ -1256,6 +2124,11 @@ func (in *XDSServerConfig) DeepCopyInto(out *XDSServerConfig) {
 		*out = new(TLS)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Authorization != nil {
+		in, out := &in.Authorization, &out.Authorization
+		*out = new(XDSServerAuthorization)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new XDSServerConfig.
@@ -1267,3 +2140,38 @@ func (in *XDSServerConfig) DeepCopy() *XDSServerConfig {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *XDSServerJWTAuthorization) DeepCopyInto(out *XDSServerJWTAuthorization) {
+	*out = *in
+	if in.Audiences != nil {
+		in, out := &in.Audiences, &out.Audiences
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new XDSServerJWTAuthorization.
+func (in *XDSServerJWTAuthorization) DeepCopy() *XDSServerJWTAuthorization {
+	if in == nil {
+		return nil
+	}
+	out := new(XDSServerJWTAuthorization)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZipkinTracingConfig) DeepCopyInto(out *ZipkinTracingConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZipkinTracingConfig.
+func (in *ZipkinTracingConfig) DeepCopy() *ZipkinTracingConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ZipkinTracingConfig)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -14,6 +14,10 @@
 package main
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+
 	"github.com/alecthomas/kingpin/v2"
 	"github.com/projectcontour/contour/internal/envoy"
 )
@@ -34,10 +38,58 @@ func registerBootstrap(app *kingpin.Application) (*kingpin.CmdClause, *envoy.Boo
 	bootstrap.Flag("envoy-key-file", "Client key filename for Envoy secure xDS gRPC communication.").Envar("ENVOY_KEY_FILE").StringVar(&config.GrpcClientKey)
 	bootstrap.Flag("namespace", "The namespace the Envoy container will run in.").Envar("CONTOUR_NAMESPACE").Default("projectcontour").StringVar(&config.Namespace)
 	bootstrap.Flag("overload-max-heap", "Defines the maximum heap size in bytes until overload manager stops accepting new connections.").Uint64Var(&config.MaximumHeapSizeBytes)
+	bootstrap.Flag("overload-shrink-heap-threshold", "Fraction of the maximum heap size at which overload manager shrinks the heap. Defaults to 0.95.").Float64Var(&config.OverloadShrinkHeapThreshold)
+	bootstrap.Flag("overload-stop-accepting-requests-threshold", "Fraction of the maximum heap size at which overload manager stops accepting requests. Defaults to 0.98.").Float64Var(&config.OverloadStopAcceptingRequestsThreshold)
 	bootstrap.Flag("resources-dir", "Directory where configuration files will be written to.").StringVar(&config.ResourcesDir)
+	bootstrap.Flag("static-resources-file", "YAML filename with static clusters, listeners and secrets to add to the bootstrap configuration.").PlaceHolder("/path/to/file").StringVar(&config.StaticResourcesFile)
+	bootstrap.Flag("stats-histogram-buckets", "Histogram bucket boundaries of the stats with a name prefix, or '*' for all stats. Flag can be given multiple times.").PlaceHolder("<prefix>=<bucket>,...").SetValue(histogramBucketsValue{buckets: &config.HistogramBuckets})
 	bootstrap.Flag("xds-address", "xDS gRPC API address.").StringVar(&config.XDSAddress)
 	bootstrap.Flag("xds-port", "xDS gRPC API port.").IntVar(&config.XDSGRPCPort)
 	bootstrap.Flag("xds-resource-version", "The versions of the xDS resources to request from Contour.").Default("v3").StringVar((*string)(&config.XDSResourceVersion))
+	bootstrap.Flag("xds-token-file", "JWT filename for Envoy to present to the xDS server for authorization.").PlaceHolder("/path/to/file").Envar("ENVOY_XDS_TOKEN_FILE").StringVar(&config.XDSTokenFile)
 
 	return bootstrap, &config
 }
+
+// histogramBucketsValue is a kingpin.Value that adds the histogram
+// bucket boundaries of a stat prefix from <prefix>=<bucket>,... .
+type histogramBucketsValue struct {
+	buckets *[]envoy.HistogramBuckets
+}
+
+func (v histogramBucketsValue) Set(s string) error {
+	prefix, list, ok := strings.Cut(s, "=")
+	if !ok {
+		return fmt.Errorf("histogram buckets %q must be formatted as <prefix>=<bucket>,...", s)
+	}
+
+	h := envoy.HistogramBuckets{Prefix: strings.TrimSpace(prefix)}
+	for _, b := range strings.Split(list, ",") {
+		bucket, err := strconv.ParseFloat(strings.TrimSpace(b), 64)
+		if err != nil {
+			return fmt.Errorf("invalid histogram bucket of stat prefix %q: %w", h.Prefix, err)
+		}
+		h.Buckets = append(h.Buckets, bucket)
+	}
+
+	*v.buckets = append(*v.buckets, h)
+	return nil
+}
+
+func (v histogramBucketsValue) String() string {
+	var settings []string
+	for _, h := range *v.buckets {
+		var buckets []string
+		for _, b := range h.Buckets {
+			buckets = append(buckets, strconv.FormatFloat(b, 'g', -1, 64))
+		}
+		settings = append(settings, h.Prefix+"="+strings.Join(buckets, ","))
+	}
+
+	return strings.Join(settings, " ")
+}
+
+// IsCumulative allows the flag to be given multiple times.
+func (v histogramBucketsValue) IsCumulative() bool {
+	return true
+}
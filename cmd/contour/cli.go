@@ -17,7 +17,6 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
-	"fmt"
 	"os"
 
 	"github.com/alecthomas/kingpin/v2"
@@ -27,13 +26,14 @@ import (
 	envoy_service_endpoint_v3 "github.com/envoyproxy/go-control-plane/envoy/service/endpoint/v3"
 	envoy_service_listener_v3 "github.com/envoyproxy/go-control-plane/envoy/service/listener/v3"
 	envoy_service_route_v3 "github.com/envoyproxy/go-control-plane/envoy/service/route/v3"
+	resource_v3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
 	"github.com/sirupsen/logrus"
 	grpc_code "google.golang.org/genproto/googleapis/rpc/code"
 	"google.golang.org/genproto/googleapis/rpc/status"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
-	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 )
 
 // registerCli registers the cli subcommand and flags
@@ -49,10 +49,21 @@ func registerCli(app *kingpin.Application, log *logrus.Logger) (*kingpin.CmdClau
 	cli.Flag("key-file", "Client key file for connecting to a TLS-secured Contour.").Envar("CLI_KEY_FILE").StringVar(&client.ClientKey)
 	cli.Flag("nack", "NACK all responses (for testing).").BoolVar(&client.Nack)
 	cli.Flag("node-id", "Node ID for the CLI client to use.").Envar("CLI_NODE_ID").Default("ContourCLI").StringVar(&client.NodeID)
+	cli.Flag("output", "Output format for xDS responses.").Short('o').Default(textOutput).EnumVar(&client.Output, cliOutputFormats...)
 
 	return cli, &client
 }
 
+// xdsTypeURLs maps the names of the xDS APIs accepted by
+// the cli subcommands to the type URLs of their resources.
+var xdsTypeURLs = map[string]string{
+	"cds": resource_v3.ClusterType,
+	"eds": resource_v3.EndpointType,
+	"lds": resource_v3.ListenerType,
+	"rds": resource_v3.RouteType,
+	"sds": resource_v3.SecretType,
+}
+
 // Client holds the details for the cli client to connect to.
 // TODO(youngnick): Move NACK handling to a sentinel, either file or keystroke.
 type Client struct {
@@ -63,6 +74,7 @@ type Client struct {
 	Nack        bool
 	Delta       bool
 	NodeID      string
+	Output      string
 	Log         *logrus.Logger
 }
 
@@ -153,16 +165,51 @@ func (c *Client) RouteStream() envoy_service_route_v3.RouteDiscoveryService_Stre
 	return stream
 }
 
+// watch streams the resources of the given type from Contour,
+// passing each request and response to the printer.
+func (c *Client) watch(typeURL string, resources []string, p printer) {
+	if c.Delta {
+		var st deltaStream
+		switch typeURL {
+		case resource_v3.ClusterType:
+			st = c.DeltaClusterStream()
+		case resource_v3.EndpointType:
+			st = c.DeltaEndpointStream()
+		case resource_v3.ListenerType:
+			st = c.DeltaListenerStream()
+		default:
+			// Contour serves routes and secrets over the same stream.
+			st = c.DeltaRouteStream()
+		}
+		watchDeltaStream(c.Log, st, typeURL, resources, c.Nack, c.NodeID, p)
+		return
+	}
+
+	var st stream
+	switch typeURL {
+	case resource_v3.ClusterType:
+		st = c.ClusterStream()
+	case resource_v3.EndpointType:
+		st = c.EndpointStream()
+	case resource_v3.ListenerType:
+		st = c.ListenerStream()
+	default:
+		// Contour serves routes and secrets over the same stream.
+		st = c.RouteStream()
+	}
+	watchstream(c.Log, st, typeURL, resources, c.Nack, c.NodeID, p)
+}
+
 type stream interface {
 	Send(*envoy_discovery_v3.DiscoveryRequest) error
 	Recv() (*envoy_discovery_v3.DiscoveryResponse, error)
 }
 
-func watchstream(log *logrus.Logger, st stream, typeURL string, resources []string, nack bool, nodeID string) {
-	m := protojson.MarshalOptions{
-		Multiline:     true,
-		Indent:        "  ",
-		UseProtoNames: true,
+func watchstream(log *logrus.Logger, st stream, typeURL string, resources []string, nack bool, nodeID string, p printer) {
+	printRequest := func(req proto.Message) {
+		if err := p.printRequest(req); err != nil {
+			log.WithError(err).Fatal("failed to print Discover Request")
+		}
 	}
 
 	currentVersion := "0"
@@ -177,7 +224,7 @@ func watchstream(log *logrus.Logger, st stream, typeURL string, resources []stri
 		},
 	}
 	log.WithField("currentVersion", currentVersion).Info("Sending discover request")
-	fmt.Println(m.Format(req))
+	printRequest(req)
 	err := st.Send(req)
 	if err != nil {
 		log.WithError(err).Fatal("failed to send Discover Request")
@@ -195,9 +242,8 @@ func watchstream(log *logrus.Logger, st stream, typeURL string, resources []stri
 			WithField("nonce", resp.Nonce).
 			Info("Received Discovery Response")
 
-		fmt.Println(m.Format(resp))
-		if err != nil {
-			log.WithError(err).Fatal("failed to marshal Discovery Response")
+		if err := p.printResponse(resp); err != nil {
+			log.WithError(err).Fatal("failed to print Discovery Response")
 		}
 
 		currentVersion = resp.VersionInfo
@@ -226,7 +272,7 @@ func watchstream(log *logrus.Logger, st stream, typeURL string, resources []stri
 				WithField("currentVersion", currentVersion).
 				Info("Sending NACK discover request")
 
-			fmt.Println(m.Format(nackReq))
+			printRequest(nackReq)
 			err := st.Send(nackReq)
 			if err != nil {
 				log.WithError(err).Fatal("failed to send NACK Discover Request")
@@ -249,7 +295,7 @@ func watchstream(log *logrus.Logger, st stream, typeURL string, resources []stri
 				WithField("version_info", resp.VersionInfo).
 				WithField("currentVersion", currentVersion).
 				Info("Sending ACK discover request")
-			fmt.Println(m.Format(ackReq))
+			printRequest(ackReq)
 			err := st.Send(ackReq)
 			if err != nil {
 				log.WithError(err).Fatal("failed to send ACK Discover Request")
@@ -300,11 +346,11 @@ type deltaStream interface {
 	Recv() (*envoy_discovery_v3.DeltaDiscoveryResponse, error)
 }
 
-func watchDeltaStream(log *logrus.Logger, st deltaStream, typeURL string, resources []string, nack bool, nodeID string) {
-	m := protojson.MarshalOptions{
-		Multiline:     true,
-		Indent:        "  ",
-		UseProtoNames: true,
+func watchDeltaStream(log *logrus.Logger, st deltaStream, typeURL string, resources []string, nack bool, nodeID string, p printer) {
+	printRequest := func(req proto.Message) {
+		if err := p.printRequest(req); err != nil {
+			log.WithError(err).Fatal("failed to print Discover Request")
+		}
 	}
 
 	currentVersion := "0"
@@ -318,7 +364,7 @@ func watchDeltaStream(log *logrus.Logger, st deltaStream, typeURL string, resour
 		},
 	}
 	log.WithField("currentVersion", currentVersion).Info("Sending incremental discover request")
-	fmt.Println(m.Format(req))
+	printRequest(req)
 	err := st.Send(req)
 	if err != nil {
 		log.WithError(err).Fatal("failed to send incremental Discover Request")
@@ -336,9 +382,8 @@ func watchDeltaStream(log *logrus.Logger, st deltaStream, typeURL string, resour
 			WithField("nonce", resp.Nonce).
 			Info("Received Discovery Response")
 
-		fmt.Println(m.Format(resp))
-		if err != nil {
-			log.WithError(err).Fatal("failed to marshal incremental Discovery Response")
+		if err := p.printResponse(resp); err != nil {
+			log.WithError(err).Fatal("failed to print incremental Discovery Response")
 		}
 
 		currentVersion = resp.SystemVersionInfo
@@ -364,7 +409,7 @@ func watchDeltaStream(log *logrus.Logger, st deltaStream, typeURL string, resour
 				WithField("currentVersion", currentVersion).
 				Info("Sending incremental NACK discover request")
 
-			fmt.Println(m.Format(nackReq))
+			printRequest(nackReq)
 			err := st.Send(nackReq)
 			if err != nil {
 				log.WithError(err).Fatal("failed to send NACK Discover Request")
@@ -384,7 +429,7 @@ func watchDeltaStream(log *logrus.Logger, st deltaStream, typeURL string, resour
 				WithField("version_info", resp.SystemVersionInfo).
 				WithField("currentVersion", currentVersion).
 				Info("Sending incremental ACK discover request")
-			fmt.Println(m.Format(ackReq))
+			printRequest(ackReq)
 			err := st.Send(ackReq)
 			if err != nil {
 				log.WithError(err).Fatal("failed to send ACK incremental Discover Request")
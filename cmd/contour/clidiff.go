@@ -0,0 +1,206 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"gopkg.in/yaml.v3"
+)
+
+// xdsSnapshot holds the resources of each type from a file of xDS
+// responses written by the cli in the json or yaml output format.
+type xdsSnapshot map[string]*xdsSnapshotType
+
+// xdsSnapshotType holds the latest version of each resource of one type.
+type xdsSnapshotType struct {
+	version   string
+	resources map[string]proto.Message
+}
+
+// readXDSSnapshotFile reads an xdsSnapshot from the named file.
+func readXDSSnapshotFile(name string) (xdsSnapshot, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	snapshot, err := readXDSSnapshot(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot %q: %w", name, err)
+	}
+	return snapshot, nil
+}
+
+// readXDSSnapshot reads a stream of xDS responses in the json or yaml
+// output format and applies them in order, so later responses for a
+// type replace or update the resources from earlier ones.
+func readXDSSnapshot(r io.Reader) (xdsSnapshot, error) {
+	br := bufio.NewReader(r)
+
+	// JSON output is a stream of objects, one per line, which
+	// isn't valid YAML, so it needs to be decoded separately.
+	var decode func(any) error
+	if first, err := peekNonSpace(br); err == nil && first == '{' {
+		decode = json.NewDecoder(br).Decode
+	} else {
+		decode = yaml.NewDecoder(br).Decode
+	}
+
+	snapshot := xdsSnapshot{}
+	for {
+		var resp xdsResponse
+		err := decode(&resp)
+		if errors.Is(err, io.EOF) {
+			return snapshot, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := snapshot.apply(&resp); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// peekNonSpace returns the first non-whitespace byte in br without consuming it.
+func peekNonSpace(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.Peek(1)
+		if err != nil {
+			return 0, err
+		}
+		if !bytes.ContainsAny(b, " \t\r\n") {
+			return b[0], nil
+		}
+		if _, err := br.Discard(1); err != nil {
+			return 0, err
+		}
+	}
+}
+
+func (s xdsSnapshot) apply(resp *xdsResponse) error {
+	if resp.TypeURL == "" {
+		return errors.New("response has no typeUrl")
+	}
+
+	mt, err := protoregistry.GlobalTypes.FindMessageByURL(resp.TypeURL)
+	if err != nil {
+		return fmt.Errorf("unknown resource type %q: %w", resp.TypeURL, err)
+	}
+
+	// Incremental responses only hold the resources that changed,
+	// while state of the world responses hold all resources of a type.
+	t, ok := s[resp.TypeURL]
+	if !ok || !resp.Incremental {
+		t = &xdsSnapshotType{resources: map[string]proto.Message{}}
+		s[resp.TypeURL] = t
+	}
+	t.version = resp.VersionInfo
+
+	for _, r := range resp.Resources {
+		buf, err := json.Marshal(r.Resource)
+		if err != nil {
+			return err
+		}
+
+		msg := mt.New().Interface()
+		if err := protojson.Unmarshal(buf, msg); err != nil {
+			return fmt.Errorf("failed to unmarshal resource %q of type %q: %w", r.Name, resp.TypeURL, err)
+		}
+		t.resources[r.Name] = msg
+	}
+	for _, name := range resp.RemovedResources {
+		delete(t.resources, name)
+	}
+
+	return nil
+}
+
+// diffXDSSnapshots returns the resources that were added, modified or
+// removed in b compared to a, sorted by type URL and resource name.
+func diffXDSSnapshots(a, b xdsSnapshot) []resourceChange {
+	typeURLs := map[string]bool{}
+	for typeURL := range a {
+		typeURLs[typeURL] = true
+	}
+	for typeURL := range b {
+		typeURLs[typeURL] = true
+	}
+
+	empty := &xdsSnapshotType{}
+
+	var changes []resourceChange
+	for typeURL := range typeURLs {
+		before, after := a[typeURL], b[typeURL]
+		if before == nil {
+			before = empty
+		}
+		if after == nil {
+			after = empty
+		}
+
+		for name, msg := range after.resources {
+			previous, ok := before.resources[name]
+			switch {
+			case !ok:
+				changes = append(changes, resourceChange{change: resourceAdded, typeURL: typeURL, version: after.version, name: name, current: msg})
+			case !proto.Equal(previous, msg):
+				changes = append(changes, resourceChange{change: resourceModified, typeURL: typeURL, version: after.version, name: name, previous: previous, current: msg})
+			}
+		}
+		for name, msg := range before.resources {
+			if _, ok := after.resources[name]; !ok {
+				changes = append(changes, resourceChange{change: resourceRemoved, typeURL: typeURL, version: after.version, name: name, previous: msg})
+			}
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].typeURL != changes[j].typeURL {
+			return changes[i].typeURL < changes[j].typeURL
+		}
+		return changes[i].name < changes[j].name
+	})
+
+	return changes
+}
+
+// diffSnapshotFiles writes the differences between the snapshot files
+// a and b to w in the given output format, and returns whether there
+// were any differences.
+func diffSnapshotFiles(w io.Writer, format, a, b string) (bool, error) {
+	before, err := readXDSSnapshotFile(a)
+	if err != nil {
+		return false, err
+	}
+	after, err := readXDSSnapshotFile(b)
+	if err != nil {
+		return false, err
+	}
+
+	changes := diffXDSSnapshots(before, after)
+	return len(changes) > 0, writeResourceChanges(w, format, changes)
+}
@@ -0,0 +1,139 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	envoy_cluster_v3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	envoy_listener_v3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	envoy_discovery_v3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	resource_v3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	"github.com/projectcontour/contour/internal/protobuf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// writeSnapshot writes the responses to a file in
+// the given output format and returns its name.
+func writeSnapshot(t *testing.T, format string, responses ...proto.Message) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	p := newPrinter(&buf, format)
+	for _, resp := range responses {
+		require.NoError(t, p.printResponse(resp))
+	}
+
+	name := filepath.Join(t.TempDir(), "snapshot."+format)
+	require.NoError(t, os.WriteFile(name, buf.Bytes(), 0600))
+	return name
+}
+
+func clusters(version string, clusters ...*envoy_cluster_v3.Cluster) *envoy_discovery_v3.DiscoveryResponse {
+	resp := &envoy_discovery_v3.DiscoveryResponse{
+		VersionInfo: version,
+		TypeUrl:     resource_v3.ClusterType,
+	}
+	for _, c := range clusters {
+		resp.Resources = append(resp.Resources, protobuf.MustMarshalAny(c))
+	}
+	return resp
+}
+
+func listeners(version string, names ...string) *envoy_discovery_v3.DiscoveryResponse {
+	resp := &envoy_discovery_v3.DiscoveryResponse{
+		VersionInfo: version,
+		TypeUrl:     resource_v3.ListenerType,
+	}
+	for _, name := range names {
+		resp.Resources = append(resp.Resources, protobuf.MustMarshalAny(&envoy_listener_v3.Listener{Name: name}))
+	}
+	return resp
+}
+
+func TestDiffSnapshotFiles(t *testing.T) {
+	// Snapshots may be in different formats, and hold
+	// several responses for each type.
+	a := writeSnapshot(t, jsonOutput,
+		clusters("1", cluster("a", time.Second)),
+		clusters("2", cluster("a", time.Second), cluster("b", time.Second), cluster("c", time.Second)),
+		listeners("1", "ingress_http"),
+	)
+	b := writeSnapshot(t, yamlOutput,
+		clusters("5", cluster("a", time.Second), cluster("b", 2*time.Second), cluster("d", time.Second)),
+		listeners("3", "ingress_http", "ingress_https"),
+	)
+
+	var buf bytes.Buffer
+	changed, err := diffSnapshotFiles(&buf, tableOutput, a, b)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, `VERSION  TYPE                                                   NAME           CHANGE
+5        type.googleapis.com/envoy.config.cluster.v3.Cluster    b              modified
+5        type.googleapis.com/envoy.config.cluster.v3.Cluster    c              removed
+5        type.googleapis.com/envoy.config.cluster.v3.Cluster    d              added
+3        type.googleapis.com/envoy.config.listener.v3.Listener  ingress_https  added
+`, buf.String())
+
+	// A snapshot doesn't differ from itself.
+	buf.Reset()
+	changed, err = diffSnapshotFiles(&buf, tableOutput, a, a)
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Empty(t, buf.String())
+}
+
+func TestReadXDSSnapshotIncremental(t *testing.T) {
+	name := writeSnapshot(t, yamlOutput,
+		&envoy_discovery_v3.DeltaDiscoveryResponse{
+			SystemVersionInfo: "1",
+			TypeUrl:           resource_v3.ClusterType,
+			Resources: []*envoy_discovery_v3.Resource{
+				{Name: "a", Resource: protobuf.MustMarshalAny(cluster("a", time.Second))},
+				{Name: "b", Resource: protobuf.MustMarshalAny(cluster("b", time.Second))},
+			},
+		},
+		// Resources not in an incremental response are unchanged.
+		&envoy_discovery_v3.DeltaDiscoveryResponse{
+			SystemVersionInfo: "2",
+			TypeUrl:           resource_v3.ClusterType,
+			Resources: []*envoy_discovery_v3.Resource{
+				{Name: "c", Resource: protobuf.MustMarshalAny(cluster("c", time.Second))},
+			},
+			RemovedResources: []string{"a"},
+		},
+	)
+
+	snapshot, err := readXDSSnapshotFile(name)
+	require.NoError(t, err)
+	require.Contains(t, snapshot, resource_v3.ClusterType)
+	assert.Equal(t, "2", snapshot[resource_v3.ClusterType].version)
+	assert.Len(t, snapshot[resource_v3.ClusterType].resources, 2)
+	assert.Contains(t, snapshot[resource_v3.ClusterType].resources, "b")
+	assert.Contains(t, snapshot[resource_v3.ClusterType].resources, "c")
+}
+
+func TestReadXDSSnapshotErrors(t *testing.T) {
+	_, err := readXDSSnapshot(bytes.NewBufferString(`{"typeUrl":"type.googleapis.com/unknown.Type","resources":[]}`))
+	assert.ErrorContains(t, err, `unknown resource type "type.googleapis.com/unknown.Type"`)
+
+	_, err = readXDSSnapshot(bytes.NewBufferString("resources: []\n"))
+	assert.ErrorContains(t, err, "response has no typeUrl")
+}
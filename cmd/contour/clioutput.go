@@ -0,0 +1,240 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	envoy_discovery_v3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	"github.com/envoyproxy/go-control-plane/pkg/cache/v3"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats supported by the cli subcommands.
+const (
+	// textOutput prints every xDS request and response as
+	// multi-line protobuf JSON.
+	textOutput = "text"
+
+	// jsonOutput prints each xDS response as a single line of JSON.
+	jsonOutput = "json"
+
+	// yamlOutput prints each xDS response as a YAML document.
+	yamlOutput = "yaml"
+
+	// tableOutput prints a table of the resources in each xDS response.
+	tableOutput = "table"
+)
+
+// cliOutputFormats are the valid values of the cli --output flag.
+var cliOutputFormats = []string{textOutput, jsonOutput, yamlOutput, tableOutput}
+
+// textMarshalOptions are used to print protobuf messages in the text output format.
+var textMarshalOptions = protojson.MarshalOptions{
+	Multiline:     true,
+	Indent:        "  ",
+	UseProtoNames: true,
+}
+
+// printer prints the xDS requests sent and responses received by the cli.
+type printer interface {
+	printRequest(req proto.Message) error
+	printResponse(resp proto.Message) error
+}
+
+// newPrinter returns a printer that writes to w in the given output format.
+func newPrinter(w io.Writer, format string) printer {
+	if format == textOutput {
+		return &textPrinter{w: w}
+	}
+	return &formatPrinter{w: w, format: format}
+}
+
+// textPrinter prints every request and response as multi-line protobuf JSON.
+type textPrinter struct {
+	w io.Writer
+}
+
+func (p *textPrinter) printRequest(req proto.Message) error {
+	_, err := fmt.Fprintln(p.w, textMarshalOptions.Format(req))
+	return err
+}
+
+func (p *textPrinter) printResponse(resp proto.Message) error {
+	_, err := fmt.Fprintln(p.w, textMarshalOptions.Format(resp))
+	return err
+}
+
+// formatPrinter prints only responses, in one of the json, yaml
+// or table output formats.
+type formatPrinter struct {
+	w      io.Writer
+	format string
+}
+
+func (p *formatPrinter) printRequest(proto.Message) error {
+	return nil
+}
+
+func (p *formatPrinter) printResponse(resp proto.Message) error {
+	out, err := toXDSResponse(resp)
+	if err != nil {
+		return err
+	}
+	return writeXDSResponse(p.w, p.format, out)
+}
+
+// xdsResponse is the stable schema used to print xDS responses
+// in the json, yaml and table output formats. It is shared by
+// the state of the world and incremental protocols.
+type xdsResponse struct {
+	TypeURL          string        `json:"typeUrl" yaml:"typeUrl"`
+	VersionInfo      string        `json:"versionInfo" yaml:"versionInfo"`
+	Nonce            string        `json:"nonce" yaml:"nonce"`
+	Incremental      bool          `json:"incremental,omitempty" yaml:"incremental,omitempty"`
+	Resources        []xdsResource `json:"resources" yaml:"resources"`
+	RemovedResources []string      `json:"removedResources,omitempty" yaml:"removedResources,omitempty"`
+}
+
+// xdsResource is a single resource in an xdsResponse.
+type xdsResource struct {
+	Name     string `json:"name" yaml:"name"`
+	Version  string `json:"version,omitempty" yaml:"version,omitempty"`
+	Resource any    `json:"resource" yaml:"resource"`
+}
+
+// newXDSResponse converts a state of the world DiscoveryResponse to an xdsResponse.
+func newXDSResponse(resp *envoy_discovery_v3.DiscoveryResponse) (*xdsResponse, error) {
+	out := &xdsResponse{
+		TypeURL:     resp.TypeUrl,
+		VersionInfo: resp.VersionInfo,
+		Nonce:       resp.Nonce,
+		Resources:   []xdsResource{},
+	}
+
+	for _, r := range resp.Resources {
+		res, err := newXDSResource(r)
+		if err != nil {
+			return nil, err
+		}
+		out.Resources = append(out.Resources, *res)
+	}
+
+	return out, nil
+}
+
+// newDeltaXDSResponse converts an incremental DeltaDiscoveryResponse to an xdsResponse.
+func newDeltaXDSResponse(resp *envoy_discovery_v3.DeltaDiscoveryResponse) (*xdsResponse, error) {
+	out := &xdsResponse{
+		TypeURL:          resp.TypeUrl,
+		VersionInfo:      resp.SystemVersionInfo,
+		Nonce:            resp.Nonce,
+		Incremental:      true,
+		Resources:        []xdsResource{},
+		RemovedResources: resp.RemovedResources,
+	}
+
+	for _, r := range resp.Resources {
+		res, err := newXDSResource(r.Resource)
+		if err != nil {
+			return nil, err
+		}
+		res.Name = r.Name
+		res.Version = r.Version
+		out.Resources = append(out.Resources, *res)
+	}
+
+	return out, nil
+}
+
+// toXDSResponse converts either kind of discovery response to an xdsResponse.
+func toXDSResponse(resp proto.Message) (*xdsResponse, error) {
+	switch resp := resp.(type) {
+	case *envoy_discovery_v3.DiscoveryResponse:
+		return newXDSResponse(resp)
+	case *envoy_discovery_v3.DeltaDiscoveryResponse:
+		return newDeltaXDSResponse(resp)
+	default:
+		return nil, fmt.Errorf("unsupported response type %T", resp)
+	}
+}
+
+func newXDSResource(r *anypb.Any) (*xdsResource, error) {
+	msg, err := r.UnmarshalNew()
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal resource of type %q: %w", r.TypeUrl, err)
+	}
+
+	resource, err := resourceValue(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &xdsResource{
+		Name:     cache.GetResourceName(msg),
+		Resource: resource,
+	}, nil
+}
+
+// resourceValue converts msg to a generic value so that it is
+// rendered as a nested object in both JSON and YAML.
+func resourceValue(msg proto.Message) (any, error) {
+	buf, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal resource of type %q: %w", msg.ProtoReflect().Descriptor().FullName(), err)
+	}
+
+	var resource any
+	if err := json.Unmarshal(buf, &resource); err != nil {
+		return nil, err
+	}
+
+	return resource, nil
+}
+
+// writeXDSResponse writes resp to w in the given output format.
+func writeXDSResponse(w io.Writer, format string, resp *xdsResponse) error {
+	switch format {
+	case jsonOutput:
+		return json.NewEncoder(w).Encode(resp)
+	case yamlOutput:
+		if _, err := fmt.Fprintln(w, "---"); err != nil {
+			return err
+		}
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(resp); err != nil {
+			return err
+		}
+		return enc.Close()
+	case tableOutput:
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "VERSION\tNONCE\tTYPE\tNAME\tSTATUS")
+		for _, r := range resp.Resources {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", resp.VersionInfo, resp.Nonce, resp.TypeURL, r.Name, "present")
+		}
+		for _, name := range resp.RemovedResources {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", resp.VersionInfo, resp.Nonce, resp.TypeURL, name, "removed")
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unsupported output format %q", format)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	envoy_cluster_v3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	envoy_discovery_v3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	resource_v3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	"github.com/projectcontour/contour/internal/protobuf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func TestWriteXDSResponse(t *testing.T) {
+	cluster := &envoy_cluster_v3.Cluster{
+		Name: "default/kuard/80",
+	}
+
+	resp, err := newXDSResponse(&envoy_discovery_v3.DiscoveryResponse{
+		VersionInfo: "2",
+		Nonce:       "3",
+		TypeUrl:     resource_v3.ClusterType,
+		Resources:   []*anypb.Any{protobuf.MustMarshalAny(cluster)},
+	})
+	require.NoError(t, err)
+
+	deltaResp, err := newDeltaXDSResponse(&envoy_discovery_v3.DeltaDiscoveryResponse{
+		SystemVersionInfo: "2",
+		Nonce:             "3",
+		TypeUrl:           resource_v3.ClusterType,
+		Resources: []*envoy_discovery_v3.Resource{{
+			Name:     "default/kuard/80",
+			Version:  "1",
+			Resource: protobuf.MustMarshalAny(cluster),
+		}},
+		RemovedResources: []string{"default/old/80"},
+	})
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		format string
+		resp   *xdsResponse
+		want   string
+	}{
+		"json": {
+			format: jsonOutput,
+			resp:   resp,
+			want: `{"typeUrl":"type.googleapis.com/envoy.config.cluster.v3.Cluster","versionInfo":"2","nonce":"3","resources":[{"name":"default/kuard/80","resource":{"name":"default/kuard/80"}}]}
+`,
+		},
+		"yaml": {
+			format: yamlOutput,
+			resp:   resp,
+			want: `---
+typeUrl: type.googleapis.com/envoy.config.cluster.v3.Cluster
+versionInfo: "2"
+nonce: "3"
+resources:
+  - name: default/kuard/80
+    resource:
+      name: default/kuard/80
+`,
+		},
+		"table": {
+			format: tableOutput,
+			resp:   resp,
+			want: `VERSION  NONCE  TYPE                                                 NAME              STATUS
+2        3      type.googleapis.com/envoy.config.cluster.v3.Cluster  default/kuard/80  present
+`,
+		},
+		"incremental json": {
+			format: jsonOutput,
+			resp:   deltaResp,
+			want: `{"typeUrl":"type.googleapis.com/envoy.config.cluster.v3.Cluster","versionInfo":"2","nonce":"3","incremental":true,"resources":[{"name":"default/kuard/80","version":"1","resource":{"name":"default/kuard/80"}}],"removedResources":["default/old/80"]}
+`,
+		},
+		"incremental table": {
+			format: tableOutput,
+			resp:   deltaResp,
+			want: `VERSION  NONCE  TYPE                                                 NAME              STATUS
+2        3      type.googleapis.com/envoy.config.cluster.v3.Cluster  default/kuard/80  present
+2        3      type.googleapis.com/envoy.config.cluster.v3.Cluster  default/old/80    removed
+`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, writeXDSResponse(&buf, tc.format, tc.resp))
+			assert.Equal(t, tc.want, buf.String())
+		})
+	}
+}
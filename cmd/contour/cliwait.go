@@ -0,0 +1,132 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/projectcontour/contour/internal/contour"
+	"gopkg.in/yaml.v3"
+)
+
+// waitRequest holds the arguments of the cli wait subcommand.
+type waitRequest struct {
+	DebugAddr  string
+	Proxy      string
+	Generation int64
+	Timeout    time.Duration
+}
+
+// waitForProxy asks the debug service of Contour to wait until the
+// generation of the HTTPProxy is ready, writes its readiness to w in
+// the given output format, and returns whether it is ready.
+func waitForProxy(w io.Writer, format string, req waitRequest) (bool, error) {
+	query := url.Values{}
+	query.Set("proxy", req.Proxy)
+	query.Set("timeout", req.Timeout.String())
+	if req.Generation > 0 {
+		query.Set("generation", strconv.FormatInt(req.Generation, 10))
+	}
+
+	u := url.URL{
+		Scheme:   "http",
+		Host:     req.DebugAddr,
+		Path:     "/debug/wait",
+		RawQuery: query.Encode(),
+	}
+
+	// Leave Contour time to respond once the wait times out.
+	client := http.Client{Timeout: req.Timeout + 10*time.Second}
+	resp, err := client.Get(u.String())
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return false, err
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusServiceUnavailable {
+		return false, fmt.Errorf("unexpected response %q: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var readiness contour.ProxyReadiness
+	if err := json.Unmarshal(body, &readiness); err != nil {
+		return false, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if err := writeProxyReadiness(w, format, body, &readiness); err != nil {
+		return false, err
+	}
+	return readiness.Ready, nil
+}
+
+// writeProxyReadiness writes the readiness, whose JSON encoding is
+// body, to w in the given output format.
+func writeProxyReadiness(w io.Writer, format string, body []byte, readiness *contour.ProxyReadiness) error {
+	switch format {
+	case jsonOutput:
+		return json.NewEncoder(w).Encode(readiness)
+	case yamlOutput:
+		// Decode the JSON generically so that
+		// YAML uses the same field names.
+		var out any
+		if err := json.Unmarshal(body, &out); err != nil {
+			return err
+		}
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(out); err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+
+	var state string
+	switch {
+	case readiness.Ready:
+		state = fmt.Sprintf("ready at revision %d", readiness.Revision)
+	case !readiness.Translated:
+		state = "not translated"
+	case !readiness.Valid:
+		state = "invalid"
+	default:
+		state = fmt.Sprintf("waiting for revision %d", readiness.Revision)
+	}
+	if _, err := fmt.Fprintf(w, "%s generation %d: %s\n", readiness.Proxy, readiness.Generation, state); err != nil {
+		return err
+	}
+
+	if len(readiness.Nodes) == 0 {
+		_, err := fmt.Fprintln(w, "no connected Envoys")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NODE\tREVISION\tREADY")
+	for _, node := range readiness.Nodes {
+		fmt.Fprintf(tw, "%s\t%d\t%t\n", node.Node, node.Revision, node.Ready)
+	}
+	return tw.Flush()
+}
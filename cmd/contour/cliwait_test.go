@@ -0,0 +1,102 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/projectcontour/contour/internal/contour"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitForProxy(t *testing.T) {
+	var query url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+
+		if query.Get("proxy") == "invalid" {
+			http.Error(w, `invalid proxy "invalid"`, http.StatusBadRequest)
+			return
+		}
+
+		readiness := contour.ProxyReadiness{
+			Proxy:      query.Get("proxy"),
+			Generation: 2,
+			Translated: true,
+			Valid:      true,
+			Revision:   7,
+			Ready:      query.Get("proxy") == "default/ready",
+			Nodes: []contour.NodeReadiness{
+				{Node: "envoy-a", Revision: 7, Ready: true},
+			},
+		}
+		if !readiness.Ready {
+			readiness.Nodes = append(readiness.Nodes, contour.NodeReadiness{Node: "envoy-b", Revision: 6})
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(readiness))
+	}))
+	defer srv.Close()
+
+	req := waitRequest{
+		DebugAddr: srv.Listener.Addr().String(),
+		Proxy:     "default/ready",
+		Timeout:   time.Minute,
+	}
+
+	var buf bytes.Buffer
+	ready, err := waitForProxy(&buf, textOutput, req)
+	require.NoError(t, err)
+	assert.True(t, ready)
+	assert.Equal(t, "1m0s", query.Get("timeout"))
+	assert.Empty(t, query.Get("generation"))
+	assert.Equal(t, `default/ready generation 2: ready at revision 7
+NODE     REVISION  READY
+envoy-a  7         true
+`, buf.String())
+
+	buf.Reset()
+	req.Proxy = "default/pending"
+	req.Generation = 2
+	ready, err = waitForProxy(&buf, yamlOutput, req)
+	require.NoError(t, err)
+	assert.False(t, ready)
+	assert.Equal(t, "2", query.Get("generation"))
+	assert.Equal(t, `generation: 2
+nodes:
+  - node: envoy-a
+    ready: true
+    revision: 7
+  - node: envoy-b
+    ready: false
+    revision: 6
+proxy: default/pending
+ready: false
+revision: 7
+translated: true
+valid: true
+`, buf.String())
+
+	req.Proxy = "invalid"
+	_, err = waitForProxy(&buf, textOutput, req)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid proxy "invalid"`)
+}
@@ -0,0 +1,246 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	envoy_discovery_v3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	"github.com/envoyproxy/go-control-plane/pkg/cache/v3"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+	"gopkg.in/yaml.v3"
+)
+
+// The kinds of change to a resource reported by cli watch.
+const (
+	resourceAdded    = "added"
+	resourceModified = "modified"
+	resourceRemoved  = "removed"
+)
+
+// xdsChange is the stable schema used by cli watch to print a change
+// to a single xDS resource in the json, yaml and table output formats.
+type xdsChange struct {
+	Change      string `json:"change" yaml:"change"`
+	TypeURL     string `json:"typeUrl" yaml:"typeUrl"`
+	VersionInfo string `json:"versionInfo" yaml:"versionInfo"`
+	Name        string `json:"name" yaml:"name"`
+	Resource    any    `json:"resource,omitempty" yaml:"resource,omitempty"`
+}
+
+// resourceChange records a change to a single resource between
+// two responses on an xDS stream.
+type resourceChange struct {
+	change   string
+	typeURL  string
+	version  string
+	name     string
+	previous proto.Message
+	current  proto.Message
+}
+
+// watchPrinter is a printer that tracks the resources received on an
+// xDS stream and prints only the resources that were added, modified
+// or removed by each response.
+type watchPrinter struct {
+	w      io.Writer
+	format string
+
+	// resources holds the last seen version of each resource, by name.
+	resources map[string]proto.Message
+}
+
+// newWatchPrinter returns a watchPrinter that writes to w in the given output format.
+func newWatchPrinter(w io.Writer, format string) *watchPrinter {
+	return &watchPrinter{
+		w:         w,
+		format:    format,
+		resources: map[string]proto.Message{},
+	}
+}
+
+func (p *watchPrinter) printRequest(proto.Message) error {
+	return nil
+}
+
+func (p *watchPrinter) printResponse(resp proto.Message) error {
+	changes, err := p.update(resp)
+	if err != nil {
+		return err
+	}
+
+	return writeResourceChanges(p.w, p.format, changes)
+}
+
+// update records the resources in resp and returns the changes
+// from the previous response, sorted by resource name.
+func (p *watchPrinter) update(resp proto.Message) ([]resourceChange, error) {
+	var changes []resourceChange
+
+	record := func(typeURL, version, name string, msg proto.Message) {
+		previous, ok := p.resources[name]
+		switch {
+		case !ok:
+			changes = append(changes, resourceChange{change: resourceAdded, typeURL: typeURL, version: version, name: name, current: msg})
+		case !proto.Equal(previous, msg):
+			changes = append(changes, resourceChange{change: resourceModified, typeURL: typeURL, version: version, name: name, previous: previous, current: msg})
+		}
+		p.resources[name] = msg
+	}
+
+	remove := func(typeURL, version, name string) {
+		if previous, ok := p.resources[name]; ok {
+			changes = append(changes, resourceChange{change: resourceRemoved, typeURL: typeURL, version: version, name: name, previous: previous})
+			delete(p.resources, name)
+		}
+	}
+
+	switch resp := resp.(type) {
+	case *envoy_discovery_v3.DiscoveryResponse:
+		// A state of the world response holds every resource, so
+		// anything not in it has been removed.
+		seen := map[string]bool{}
+		for _, r := range resp.Resources {
+			msg, err := r.UnmarshalNew()
+			if err != nil {
+				return nil, fmt.Errorf("failed to unmarshal resource of type %q: %w", r.TypeUrl, err)
+			}
+			name := cache.GetResourceName(msg)
+			seen[name] = true
+			record(resp.TypeUrl, resp.VersionInfo, name, msg)
+		}
+		for name := range p.resources {
+			if !seen[name] {
+				remove(resp.TypeUrl, resp.VersionInfo, name)
+			}
+		}
+	case *envoy_discovery_v3.DeltaDiscoveryResponse:
+		for _, r := range resp.Resources {
+			msg, err := r.Resource.UnmarshalNew()
+			if err != nil {
+				return nil, fmt.Errorf("failed to unmarshal resource of type %q: %w", r.Resource.TypeUrl, err)
+			}
+			record(resp.TypeUrl, resp.SystemVersionInfo, r.Name, msg)
+		}
+		for _, name := range resp.RemovedResources {
+			remove(resp.TypeUrl, resp.SystemVersionInfo, name)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported response type %T", resp)
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].name < changes[j].name
+	})
+
+	return changes, nil
+}
+
+// writeResourceChanges writes changes to w in the given output format.
+func writeResourceChanges(w io.Writer, format string, changes []resourceChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	switch format {
+	case textOutput:
+		for _, c := range changes {
+			if err := writeResourceChangeText(w, c); err != nil {
+				return err
+			}
+		}
+		return nil
+	case jsonOutput, yamlOutput:
+		for _, c := range changes {
+			out := xdsChange{
+				Change:      c.change,
+				TypeURL:     c.typeURL,
+				VersionInfo: c.version,
+				Name:        c.name,
+			}
+			if c.current != nil {
+				resource, err := resourceValue(c.current)
+				if err != nil {
+					return err
+				}
+				out.Resource = resource
+			}
+
+			if format == jsonOutput {
+				if err := json.NewEncoder(w).Encode(out); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if _, err := fmt.Fprintln(w, "---"); err != nil {
+				return err
+			}
+			enc := yaml.NewEncoder(w)
+			enc.SetIndent(2)
+			if err := enc.Encode(out); err != nil {
+				return err
+			}
+			if err := enc.Close(); err != nil {
+				return err
+			}
+		}
+		return nil
+	case tableOutput:
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "VERSION\tTYPE\tNAME\tCHANGE")
+		for _, c := range changes {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", c.version, c.typeURL, c.name, c.change)
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unsupported output format %q", format)
+	}
+}
+
+// writeResourceChangeText writes a summary line for c, followed by the
+// added resource or a diff of the modified resource.
+func writeResourceChangeText(w io.Writer, c resourceChange) error {
+	msg := c.current
+	if msg == nil {
+		msg = c.previous
+	}
+
+	if _, err := fmt.Fprintf(w, "%s %s %q (version %s)\n",
+		c.change, msg.ProtoReflect().Descriptor().Name(), c.name, c.version); err != nil {
+		return err
+	}
+
+	var body string
+	switch c.change {
+	case resourceAdded:
+		body = textMarshalOptions.Format(c.current)
+	case resourceModified:
+		body = cmp.Diff(c.previous, c.current, protocmp.Transform())
+	}
+
+	if body == "" {
+		return nil
+	}
+
+	_, err := fmt.Fprintln(w, strings.TrimRight(body, "\n"))
+	return err
+}
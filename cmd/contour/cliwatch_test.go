@@ -0,0 +1,122 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	envoy_cluster_v3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	envoy_discovery_v3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	resource_v3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	"github.com/projectcontour/contour/internal/protobuf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func cluster(name string, timeout time.Duration) *envoy_cluster_v3.Cluster {
+	return &envoy_cluster_v3.Cluster{
+		Name:           name,
+		ConnectTimeout: durationpb.New(timeout),
+	}
+}
+
+func TestWatchPrinterStateOfTheWorld(t *testing.T) {
+	response := func(version string, clusters ...*envoy_cluster_v3.Cluster) *envoy_discovery_v3.DiscoveryResponse {
+		resp := &envoy_discovery_v3.DiscoveryResponse{
+			VersionInfo: version,
+			TypeUrl:     resource_v3.ClusterType,
+		}
+		for _, c := range clusters {
+			resp.Resources = append(resp.Resources, protobuf.MustMarshalAny(c))
+		}
+		return resp
+	}
+
+	var buf bytes.Buffer
+	p := newWatchPrinter(&buf, tableOutput)
+
+	require.NoError(t, p.printResponse(response("1", cluster("a", time.Second), cluster("b", time.Second))))
+	assert.Equal(t, `VERSION  TYPE                                                 NAME  CHANGE
+1        type.googleapis.com/envoy.config.cluster.v3.Cluster  a     added
+1        type.googleapis.com/envoy.config.cluster.v3.Cluster  b     added
+`, buf.String())
+
+	// Unchanged resources are not printed.
+	buf.Reset()
+	require.NoError(t, p.printResponse(response("2", cluster("a", time.Second), cluster("b", time.Second))))
+	assert.Empty(t, buf.String())
+
+	// Resources missing from the response have been removed.
+	buf.Reset()
+	require.NoError(t, p.printResponse(response("3", cluster("a", 2*time.Second), cluster("c", time.Second))))
+	assert.Equal(t, `VERSION  TYPE                                                 NAME  CHANGE
+3        type.googleapis.com/envoy.config.cluster.v3.Cluster  a     modified
+3        type.googleapis.com/envoy.config.cluster.v3.Cluster  b     removed
+3        type.googleapis.com/envoy.config.cluster.v3.Cluster  c     added
+`, buf.String())
+}
+
+func TestWatchPrinterIncremental(t *testing.T) {
+	response := func(version string, removed []string, clusters ...*envoy_cluster_v3.Cluster) *envoy_discovery_v3.DeltaDiscoveryResponse {
+		resp := &envoy_discovery_v3.DeltaDiscoveryResponse{
+			SystemVersionInfo: version,
+			TypeUrl:           resource_v3.ClusterType,
+			RemovedResources:  removed,
+		}
+		for _, c := range clusters {
+			resp.Resources = append(resp.Resources, &envoy_discovery_v3.Resource{
+				Name:     c.Name,
+				Resource: protobuf.MustMarshalAny(c),
+			})
+		}
+		return resp
+	}
+
+	var buf bytes.Buffer
+	p := newWatchPrinter(&buf, jsonOutput)
+
+	require.NoError(t, p.printResponse(response("1", nil, cluster("a", time.Second), cluster("b", time.Second))))
+
+	// Resources missing from an incremental response are unchanged.
+	buf.Reset()
+	require.NoError(t, p.printResponse(response("2", []string{"b", "unknown"}, cluster("a", 2*time.Second))))
+	assert.Equal(t, `{"change":"modified","typeUrl":"type.googleapis.com/envoy.config.cluster.v3.Cluster","versionInfo":"2","name":"a","resource":{"connect_timeout":"2s","name":"a"}}
+{"change":"removed","typeUrl":"type.googleapis.com/envoy.config.cluster.v3.Cluster","versionInfo":"2","name":"b"}
+`, buf.String())
+}
+
+func TestWatchPrinterText(t *testing.T) {
+	var buf bytes.Buffer
+	p := newWatchPrinter(&buf, textOutput)
+
+	require.NoError(t, p.printResponse(&envoy_discovery_v3.DiscoveryResponse{
+		VersionInfo: "1",
+		TypeUrl:     resource_v3.ClusterType,
+		Resources:   []*anypb.Any{protobuf.MustMarshalAny(cluster("a", time.Second))},
+	}))
+	assert.Contains(t, buf.String(), `added Cluster "a" (version 1)`)
+	// protojson randomizes whitespace, so don't match it exactly.
+	assert.Regexp(t, `"connect_timeout":\s+"1s"`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, p.printResponse(&envoy_discovery_v3.DiscoveryResponse{
+		VersionInfo: "2",
+		TypeUrl:     resource_v3.ClusterType,
+	}))
+	assert.Equal(t, "removed Cluster \"a\" (version 2)\n", buf.String())
+}
@@ -0,0 +1,116 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/projectcontour/contour/internal/dag"
+	"github.com/projectcontour/contour/internal/debug"
+	"gopkg.in/yaml.v3"
+)
+
+// whoUsesRequest holds the arguments of the cli who-uses subcommand.
+type whoUsesRequest struct {
+	DebugAddr string
+	Object    string
+}
+
+// whoUses asks the debug service of Contour for the HTTPProxies that
+// refer to the object, and writes them to w in the given output format.
+func whoUses(w io.Writer, format string, req whoUsesRequest) error {
+	object, err := dag.ParseDependency(req.Object)
+	if err != nil {
+		return err
+	}
+
+	u := url.URL{
+		Scheme:   "http",
+		Host:     req.DebugAddr,
+		Path:     "/debug/dependents",
+		RawQuery: url.Values{"object": []string{object.String()}}.Encode(),
+	}
+
+	client := http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(u.String())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response %q: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var all []debug.Dependents
+	if err := json.Unmarshal(body, &all); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	dependents := debug.Dependents{
+		Object:      object.String(),
+		HTTPProxies: []string{},
+	}
+	for _, d := range all {
+		if d.Object == dependents.Object {
+			dependents.HTTPProxies = append(dependents.HTTPProxies, d.HTTPProxies...)
+		}
+	}
+
+	return writeDependents(w, format, &dependents)
+}
+
+// writeDependents writes the dependents to w in the given output format.
+func writeDependents(w io.Writer, format string, dependents *debug.Dependents) error {
+	switch format {
+	case jsonOutput:
+		return json.NewEncoder(w).Encode(dependents)
+	case yamlOutput:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(map[string]any{
+			"object":      dependents.Object,
+			"httpproxies": dependents.HTTPProxies,
+		}); err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+
+	if len(dependents.HTTPProxies) == 0 {
+		_, err := fmt.Fprintf(w, "no HTTPProxies use %s\n", dependents.Object)
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, "HTTPProxies that use %s:\n", dependents.Object); err != nil {
+		return err
+	}
+	for _, proxy := range dependents.HTTPProxies {
+		if _, err := fmt.Fprintln(w, proxy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/projectcontour/contour/internal/debug"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWhoUses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/debug/dependents", r.URL.Path)
+
+		dependents := []debug.Dependents{}
+		if r.URL.Query().Get("object") == "secret/default/tls" {
+			dependents = append(dependents, debug.Dependents{
+				Object:      "secret/default/tls",
+				HTTPProxies: []string{"default/root", "teama/app"},
+			})
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(dependents))
+	}))
+	defer srv.Close()
+
+	req := whoUsesRequest{
+		DebugAddr: srv.Listener.Addr().String(),
+		Object:    "Secret/default/tls",
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, whoUses(&buf, textOutput, req))
+	assert.Equal(t, `HTTPProxies that use secret/default/tls:
+default/root
+teama/app
+`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, whoUses(&buf, yamlOutput, req))
+	assert.Equal(t, `httpproxies:
+  - default/root
+  - teama/app
+object: secret/default/tls
+`, buf.String())
+
+	buf.Reset()
+	req.Object = "service/default/kuard"
+	require.NoError(t, whoUses(&buf, textOutput, req))
+	assert.Equal(t, "no HTTPProxies use service/default/kuard\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, whoUses(&buf, jsonOutput, req))
+	assert.JSONEq(t, `{"object": "service/default/kuard", "httpproxies": []}`, buf.String())
+
+	req.Object = "configmap/default/kuard"
+	err := whoUses(&buf, textOutput, req)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "kind must be secret or service")
+}
@@ -68,6 +68,26 @@ func main() {
 	sds := cli.Command("sds", "Watch secrets.")
 	sds.Arg("resources", "SDS resource filter").StringsVar(&resources)
 
+	watch := cli.Command("watch", "Watch changes to xDS resources as they happen.")
+	watchAPI := watch.Arg("api", "xDS API to watch.").Required().Enum("cds", "eds", "lds", "rds", "sds")
+	watch.Arg("resources", "Resource filter").StringsVar(&resources)
+
+	diff := cli.Command("diff", "Show the differences between two xDS snapshots written with --output=json or --output=yaml.")
+	diffA := diff.Arg("snapshot-a", "Snapshot to compare from.").Required().ExistingFile()
+	diffB := diff.Arg("snapshot-b", "Snapshot to compare to.").Required().ExistingFile()
+
+	var waitReq waitRequest
+	wait := cli.Command("wait", "Wait until a generation of an HTTPProxy is ACKed by every Envoy connected to Contour.")
+	wait.Arg("proxy", "HTTPProxy to wait for, as <namespace>/<name>.").Required().StringVar(&waitReq.Proxy)
+	wait.Flag("debug-address", "Contour debug service host:port.").Default("127.0.0.1:6060").StringVar(&waitReq.DebugAddr)
+	wait.Flag("generation", "HTTPProxy generation to wait for. Defaults to the generation Contour last processed.").Int64Var(&waitReq.Generation)
+	wait.Flag("timeout", "How long to wait, at most 4m.").Default("60s").DurationVar(&waitReq.Timeout)
+
+	var whoUsesReq whoUsesRequest
+	whoUsesCmd := cli.Command("who-uses", "Show the HTTPProxies that use a Secret or Service.")
+	whoUsesCmd.Arg("object", "Object to look up, as secret/<namespace>/<name> or service/<namespace>/<name>.").Required().StringVar(&whoUsesReq.Object)
+	whoUsesCmd.Flag("debug-address", "Contour debug service host:port.").Default("127.0.0.1:6060").StringVar(&whoUsesReq.DebugAddr)
+
 	envoyCmd := app.Command("envoy", "Sub-command for envoy actions.")
 
 	// Add a "shutdown" command which initiates an Envoy shutdown sequence.
@@ -78,6 +98,9 @@ func main() {
 	gatewayProvisioner, gatewayProvisionerConfig := registerGatewayProvisioner(app)
 
 	serve, serveCtx := registerServe(app)
+
+	validateConfig, validateConfigCtx := registerValidateConfig(app)
+
 	version := app.Command("version", "Build information for Contour.")
 
 	args := os.Args[1:]
@@ -104,50 +127,49 @@ func main() {
 		if err := envoy.ValidAdminAddress(bootstrapCtx.AdminAddress); err != nil {
 			log.WithField("flag", "--admin-address").WithError(err).Fatal("failed to parse bootstrap args")
 		}
+		if err := bootstrapCtx.ValidOverloadManager(); err != nil {
+			log.WithError(err).Fatal("failed to parse bootstrap args")
+		}
+		if err := bootstrapCtx.ValidHistogramBuckets(); err != nil {
+			log.WithField("flag", "--stats-histogram-buckets").WithError(err).Fatal("failed to parse bootstrap args")
+		}
 		if err := envoy_v3.WriteBootstrap(bootstrapCtx); err != nil {
 			log.WithError(err).Fatal("failed to write bootstrap configuration")
 		}
 	case certgenApp.FullCommand():
 		doCertgen(certgenConfig, log)
 	case cds.FullCommand():
-		if client.Delta {
-			stream := client.DeltaClusterStream()
-			watchDeltaStream(log, stream, resource_v3.ClusterType, resources, client.Nack, client.NodeID)
-		} else {
-			stream := client.ClusterStream()
-			watchstream(log, stream, resource_v3.ClusterType, resources, client.Nack, client.NodeID)
-		}
+		client.watch(resource_v3.ClusterType, resources, newPrinter(os.Stdout, client.Output))
 	case eds.FullCommand():
-		if client.Delta {
-			stream := client.DeltaEndpointStream()
-			watchDeltaStream(log, stream, resource_v3.EndpointType, resources, client.Nack, client.NodeID)
-		} else {
-			stream := client.EndpointStream()
-			watchstream(log, stream, resource_v3.EndpointType, resources, client.Nack, client.NodeID)
-		}
+		client.watch(resource_v3.EndpointType, resources, newPrinter(os.Stdout, client.Output))
 	case lds.FullCommand():
-		if client.Delta {
-			stream := client.DeltaListenerStream()
-			watchDeltaStream(log, stream, resource_v3.ListenerType, resources, client.Nack, client.NodeID)
-		} else {
-			stream := client.ListenerStream()
-			watchstream(log, stream, resource_v3.ListenerType, resources, client.Nack, client.NodeID)
-		}
+		client.watch(resource_v3.ListenerType, resources, newPrinter(os.Stdout, client.Output))
 	case rds.FullCommand():
-		if client.Delta {
-			stream := client.DeltaRouteStream()
-			watchDeltaStream(log, stream, resource_v3.RouteType, resources, client.Nack, client.NodeID)
-		} else {
-			stream := client.RouteStream()
-			watchstream(log, stream, resource_v3.RouteType, resources, client.Nack, client.NodeID)
-		}
+		client.watch(resource_v3.RouteType, resources, newPrinter(os.Stdout, client.Output))
 	case sds.FullCommand():
-		if client.Delta {
-			stream := client.DeltaRouteStream()
-			watchDeltaStream(log, stream, resource_v3.SecretType, resources, client.Nack, client.NodeID)
-		} else {
-			stream := client.RouteStream()
-			watchstream(log, stream, resource_v3.SecretType, resources, client.Nack, client.NodeID)
+		client.watch(resource_v3.SecretType, resources, newPrinter(os.Stdout, client.Output))
+	case watch.FullCommand():
+		client.watch(xdsTypeURLs[*watchAPI], resources, newWatchPrinter(os.Stdout, client.Output))
+	case diff.FullCommand():
+		changed, err := diffSnapshotFiles(os.Stdout, client.Output, *diffA, *diffB)
+		if err != nil {
+			log.WithError(err).Fatal("failed to diff snapshots")
+		}
+		// Like diff(1), exit with status 1 if there were differences.
+		if changed {
+			os.Exit(1)
+		}
+	case wait.FullCommand():
+		ready, err := waitForProxy(os.Stdout, client.Output, waitReq)
+		if err != nil {
+			log.WithError(err).Fatal("failed to wait for HTTPProxy")
+		}
+		if !ready {
+			os.Exit(1)
+		}
+	case whoUsesCmd.FullCommand():
+		if err := whoUses(os.Stdout, client.Output, whoUsesReq); err != nil {
+			log.WithError(err).Fatal("failed to look up dependents")
 		}
 	case serve.FullCommand():
 		// Parse args a second time so cli flags are applied
@@ -181,6 +203,10 @@ func main() {
 		if err := serve.doServe(); err != nil {
 			log.WithError(err).Fatal("Contour server failed")
 		}
+	case validateConfig.FullCommand():
+		if !doValidateConfig(validateConfigCtx, os.Stdout) {
+			os.Exit(1)
+		}
 	case version.FullCommand():
 		println(build.PrintBuildInfo())
 	default:
@@ -57,4 +57,7 @@ func TestOptionFlagsAreSorted(t *testing.T) {
 
 	serve, _ := registerServe(app)
 	assertOptionFlagsAreSorted(t, serve)
+
+	validateConfig, _ := registerValidateConfig(app)
+	assertOptionFlagsAreSorted(t, validateConfig)
 }
@@ -0,0 +1,93 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirupsen/logrus"
+)
+
+// metricsTokenWatcher watches the file holding the bearer token of
+// the Envoy metrics listeners, and sets the token of the listeners
+// when it changes.
+//
+// The directory of the file is watched rather than the file itself,
+// so that the atomic symlink swaps done by the kubelet when updating
+// a Secret volume are seen.
+type metricsTokenWatcher struct {
+	log   logrus.FieldLogger
+	path  string
+	token string
+
+	// setToken sets the token of the Envoy metrics listeners.
+	setToken func(token string)
+}
+
+// NeedLeaderElection returns false, as every Contour
+// serves the stats listeners to its Envoys.
+func (w *metricsTokenWatcher) NeedLeaderElection() bool {
+	return false
+}
+
+func (w *metricsTokenWatcher) Start(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	if err := watcher.Add(filepath.Dir(w.path)); err != nil {
+		return fmt.Errorf("failed to watch Envoy metrics bearer token file %q: %w", w.path, err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-watcher.Errors:
+			w.log.WithError(err).Error("failed to watch Envoy metrics bearer token file")
+		case <-watcher.Events:
+			w.reload()
+		}
+	}
+}
+
+// reload reads the token file, and sets the token of
+// the Envoy metrics listeners if it changed.
+func (w *metricsTokenWatcher) reload() {
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		// The file is missing while a Secret volume
+		// is updated, so wait for the next event.
+		if !os.IsNotExist(err) {
+			w.log.WithError(err).Error("failed to read Envoy metrics bearer token, keeping the current token")
+		}
+		return
+	}
+
+	token := strings.TrimSpace(string(data))
+	if token == "" || token == w.token {
+		return
+	}
+	w.token = token
+
+	w.setToken(token)
+	w.log.Info("reloaded Envoy metrics bearer token")
+}
@@ -0,0 +1,56 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/projectcontour/contour/internal/fixture"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsTokenWatcherReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(path, []byte("secret\n"), 0o600))
+
+	var set []string
+	w := &metricsTokenWatcher{
+		log:   fixture.NewTestLogger(t),
+		path:  path,
+		token: "secret",
+		setToken: func(token string) {
+			set = append(set, token)
+		},
+	}
+
+	// The token didn't change.
+	w.reload()
+	assert.Empty(t, set)
+
+	require.NoError(t, os.WriteFile(path, []byte("rotated\n"), 0o600))
+	w.reload()
+	assert.Equal(t, []string{"rotated"}, set)
+
+	// The file is missing or empty while the Secret
+	// volume is updated, so the token is kept.
+	require.NoError(t, os.Remove(path))
+	w.reload()
+	require.NoError(t, os.WriteFile(path, nil, 0o600))
+	w.reload()
+	assert.Equal(t, []string{"rotated"}, set)
+	assert.Equal(t, "rotated", w.token)
+}
@@ -0,0 +1,212 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"strings"
+
+	contour_api_v1alpha1 "github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
+	"github.com/projectcontour/contour/internal/contour"
+	"github.com/projectcontour/contour/internal/dag"
+	envoy_v3 "github.com/projectcontour/contour/internal/envoy/v3"
+	xdscache_v3 "github.com/projectcontour/contour/internal/xdscache/v3"
+	"github.com/projectcontour/contour/pkg/config"
+	"github.com/sirupsen/logrus"
+)
+
+// restartRequiredParameters are the paths of the parameters of
+// the configuration file, or their prefixes, that are only applied
+// when Contour starts, as they configure its clients, servers and
+// informers rather than the resources it sends to Envoy.
+var restartRequiredParameters = []string{
+	"debug",
+	"incluster",
+	"kubeconfig",
+	"kubernetesClientQPS",
+	"kubernetesClientBurst",
+	"kubernetesClientPriorityLevel",
+	"server",
+	"gateway",
+	"ingress-status-address",
+	"tls.fallback-certificate",
+	"tls.envoy-client-certificate",
+	"enableNamespaceReports",
+	"envoy-service-namespace",
+	"envoy-service-name",
+	"cluster.stat-name-format",
+	"cluster.max-stat-name-length",
+	"cluster.endpoint-deregistration-delay",
+	"network.admin-port",
+	"metrics",
+	"health",
+	"secret-backend",
+	"secret-encryption",
+	"feature-gates",
+	"leader-election",
+}
+
+// configReloader applies the changes of the configuration file.
+// Changes of the parameters of the resources sent to Envoy are
+// applied in place, while changes of the parameters that are only
+// applied when Contour starts are logged.
+type configReloader struct {
+	log     logrus.FieldLogger
+	watcher *config.Watcher
+
+	// params are the parameters Contour runs with: those of
+	// the configuration file, overridden by the flags.
+	params config.Parameters
+
+	// restartRequired are the paths of the parameters,
+	// or their prefixes, that are not reloaded.
+	restartRequired []string
+
+	// reload applies the reloadable parameters of params.
+	reload func(params *config.Parameters) error
+}
+
+// NeedLeaderElection returns false, as every Contour
+// must apply the changes of its configuration file.
+func (r *configReloader) NeedLeaderElection() bool {
+	return false
+}
+
+func (r *configReloader) Start(ctx context.Context) error {
+	errs := make(chan error, 1)
+	go func() {
+		errs <- r.watcher.Start(ctx)
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-errs:
+			return err
+		case update := <-r.watcher.Updates():
+			r.apply(update)
+		}
+	}
+}
+
+// apply applies the changed parameters of update that can be
+// reloaded, and logs those that require Contour to restart.
+func (r *configReloader) apply(update config.Update) {
+	if update.Err != nil {
+		r.log.WithError(update.Err).Error("invalid configuration file, keeping the current configuration")
+		return
+	}
+
+	params := r.params
+	var reloaded []string
+	for _, path := range update.Changed {
+		if r.requiresRestart(path) {
+			r.log.WithField("parameter", path).Warn("configuration file parameter changed, restart Contour to apply it")
+			continue
+		}
+		if err := config.CopyParameter(&params, update.Parameters, path); err != nil {
+			r.log.WithError(err).Error("invalid configuration file, keeping the current configuration")
+			return
+		}
+		reloaded = append(reloaded, path)
+	}
+
+	if len(reloaded) == 0 {
+		return
+	}
+
+	if err := r.reload(&params); err != nil {
+		r.log.WithError(err).Error("invalid configuration file, keeping the current configuration")
+		return
+	}
+	r.params = params
+
+	for _, path := range reloaded {
+		r.log.WithField("parameter", path).Info("reloaded configuration file parameter")
+	}
+}
+
+// requiresRestart returns true if the parameter at
+// path is only applied when Contour starts.
+func (r *configReloader) requiresRestart(path string) bool {
+	for _, prefix := range r.restartRequired {
+		if path == prefix || strings.HasPrefix(path, prefix+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// reloadTargets are the caches and the DAG builder
+// that reloaded parameters are applied to.
+type reloadTargets struct {
+	eventHandler  *contour.EventHandler
+	builder       *dag.Builder
+	listenerCache *xdscache_v3.ListenerCache
+	routeCache    *xdscache_v3.RouteCache
+	clusterCache  *xdscache_v3.ClusterCache
+	runtimeCache  *xdscache_v3.RuntimeCache
+
+	// secretBackend is set up once, so it is
+	// kept by the reloaded listener configuration.
+	secretBackend envoy_v3.SecretBackend
+}
+
+// reloadConfig applies the reloadable parameters of params to
+// targets, once the event handler has finished building the
+// current DAG, and rebuilds the DAG.
+func (s *Server) reloadConfig(params *config.Parameters, targets reloadTargets) error {
+	ctx := *s.ctx
+	ctx.Config = *params
+
+	contourConfiguration, err := overlayAndValidate(ctx.convertToContourConfigurationSpec())
+	if err != nil {
+		return err
+	}
+
+	listenerConfig, err := s.getListenerConfig(contourConfiguration)
+	if err != nil {
+		return err
+	}
+	listenerConfig.SecretBackend = targets.secretBackend
+
+	clusterCache, err := newClusterCache(contourConfiguration)
+	if err != nil {
+		return err
+	}
+
+	dbc, err := s.getDAGBuilderConfig(contourConfiguration, listenerConfig, targets.builder.Metrics)
+	if err != nil {
+		return err
+	}
+	dbc.externalServingSecrets = targets.secretBackend != nil
+	processors := s.getDAGProcessors(dbc)
+
+	targets.eventHandler.Reconfigure(func() {
+		targets.listenerCache.SetConfig(listenerConfig)
+
+		targets.routeCache.CaptureEnabled = listenerConfig.CaptureConfig != nil
+		targets.routeCache.RemoveServerHeader = listenerConfig.ServerHeaderTransformation == contour_api_v1alpha1.RemoveServerHeader
+
+		targets.clusterCache.DNSResolverSettings = clusterCache.DNSResolverSettings
+		targets.clusterCache.CircuitBreakerDefaults = clusterCache.CircuitBreakerDefaults
+
+		targets.builder.Processors = processors
+	})
+
+	targets.runtimeCache.SetFlags(contourConfiguration.RuntimeFlags)
+
+	return nil
+}
@@ -0,0 +1,106 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/projectcontour/contour/internal/fixture"
+	"github.com/projectcontour/contour/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigReloaderApply(t *testing.T) {
+	tests := map[string]struct {
+		update   config.Update
+		reload   error
+		reloaded *config.Parameters
+	}{
+		"reloadable parameters changed": {
+			update: config.Update{
+				Parameters: &config.Parameters{
+					RuntimeFlags: config.RuntimeFlags{"new-checkout": 25},
+					Timeouts:     config.TimeoutParameters{RequestTimeout: "30s"},
+				},
+				Changed: []string{"timeouts.request-timeout", "runtime-flags"},
+			},
+			reloaded: &config.Parameters{
+				RuntimeFlags: config.RuntimeFlags{"new-checkout": 25},
+				Timeouts:     config.TimeoutParameters{RequestTimeout: "30s", ConnectTimeout: "5s"},
+				Debug:        true,
+			},
+		},
+		"restart required parameter changed": {
+			update: config.Update{
+				Parameters: &config.Parameters{
+					RuntimeFlags: config.RuntimeFlags{"new-checkout": 25},
+					Server:       config.ServerParameters{XDSServerType: config.EnvoyServerType},
+				},
+				Changed: []string{"server.xds-server-type", "runtime-flags"},
+			},
+			reloaded: &config.Parameters{
+				RuntimeFlags: config.RuntimeFlags{"new-checkout": 25},
+				Timeouts:     config.TimeoutParameters{ConnectTimeout: "5s"},
+				Debug:        true,
+			},
+		},
+		"only restart required parameters changed": {
+			update: config.Update{
+				Parameters: &config.Parameters{Debug: false},
+				Changed:    []string{"debug"},
+			},
+		},
+		"reload failed": {
+			update: config.Update{
+				Parameters: &config.Parameters{AccessLogLevel: config.LogLevelError},
+				Changed:    []string{"accesslog-level"},
+			},
+			reload: errors.New("invalid"),
+		},
+		"invalid configuration file": {
+			update: config.Update{Err: errors.New("invalid")},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			initial := config.Parameters{
+				Timeouts: config.TimeoutParameters{ConnectTimeout: "5s"},
+				Debug:    true,
+			}
+
+			var reloaded *config.Parameters
+			r := &configReloader{
+				log:             fixture.NewTestLogger(t),
+				params:          initial,
+				restartRequired: restartRequiredParameters,
+				reload: func(params *config.Parameters) error {
+					if tc.reload == nil {
+						reloaded = params
+					}
+					return tc.reload
+				},
+			}
+
+			r.apply(tc.update)
+			assert.Equal(t, tc.reloaded, reloaded)
+			if tc.reloaded != nil {
+				assert.Equal(t, *tc.reloaded, r.params)
+			} else {
+				assert.Equal(t, initial, r.params)
+			}
+		})
+	}
+}
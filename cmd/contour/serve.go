@@ -21,9 +21,11 @@ import (
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/alecthomas/kingpin/v2"
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	envoy_server_v3 "github.com/envoyproxy/go-control-plane/pkg/server/v3"
 	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
 	contour_api_v1alpha1 "github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
@@ -34,12 +36,16 @@ import (
 	"github.com/projectcontour/contour/internal/dag"
 	"github.com/projectcontour/contour/internal/debug"
 	envoy_v3 "github.com/projectcontour/contour/internal/envoy/v3"
+	"github.com/projectcontour/contour/internal/featuregate"
 	"github.com/projectcontour/contour/internal/health"
 	"github.com/projectcontour/contour/internal/httpsvc"
 	"github.com/projectcontour/contour/internal/k8s"
 	"github.com/projectcontour/contour/internal/leadership"
 	"github.com/projectcontour/contour/internal/metrics"
+	"github.com/projectcontour/contour/internal/proxymetrics"
 	"github.com/projectcontour/contour/internal/ref"
+	"github.com/projectcontour/contour/internal/sealing"
+	"github.com/projectcontour/contour/internal/status"
 	"github.com/projectcontour/contour/internal/timeout"
 	"github.com/projectcontour/contour/internal/xds"
 	contour_xds_v3 "github.com/projectcontour/contour/internal/xds/v3"
@@ -50,6 +56,8 @@ import (
 	"github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
 	networking_v1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/kubernetes"
@@ -57,6 +65,7 @@ import (
 	"k8s.io/client-go/tools/cache"
 	ctrl_cache "sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
 	controller_runtime_metrics "sigs.k8s.io/controller-runtime/pkg/metrics"
@@ -77,30 +86,24 @@ func registerServe(app *kingpin.Application) (*kingpin.CmdClause, *serveContext)
 	// parse our action will return early, resulting in the precedence order
 	// we want.
 	var (
-		configFile string
-		parsed     bool
+		configFiles []string
+		parsed      bool
 	)
 	ctx := newServeContext()
 
 	parseConfig := func(_ *kingpin.ParseContext) error {
 
-		if ctx.contourConfigurationName != "" && configFile != "" {
+		if ctx.contourConfigurationName != "" && len(configFiles) > 0 {
 			return fmt.Errorf("cannot specify both %s and %s", "--contour-config", "-c/--config-path")
 		}
 
-		if parsed || configFile == "" {
+		if parsed || len(configFiles) == 0 {
 			// if there is no config file supplied, or we've
 			// already parsed it, return immediately.
 			return nil
 		}
 
-		f, err := os.Open(configFile)
-		if err != nil {
-			return err
-		}
-		defer f.Close()
-
-		params, err := config.Parse(f)
+		params, conversions, err := config.ParseFiles(configFiles...)
 		if err != nil {
 			return err
 		}
@@ -112,12 +115,14 @@ func registerServe(app *kingpin.Application) (*kingpin.CmdClause, *serveContext)
 		parsed = true
 
 		ctx.Config = *params
+		ctx.configPaths = configFiles
+		ctx.configConversions = conversions
 
 		return nil
 	}
 	serve.Flag("accesslog-format", "Format for Envoy access logs.").PlaceHolder("<envoy|json>").StringVar((*string)(&ctx.Config.AccessLogFormat))
 
-	serve.Flag("config-path", "Path to base configuration.").Short('c').PlaceHolder("/path/to/file").Action(parseConfig).ExistingFileVar(&configFile)
+	serve.Flag("config-path", "Path to base configuration, or to a directory of configuration files. Repeat to layer configurations, later ones overriding earlier ones.").Short('c').PlaceHolder("/path/to/file").Action(parseConfig).ExistingFilesOrDirsVar(&configFiles)
 	serve.Flag("contour-cafile", "CA bundle file name for serving gRPC with TLS.").Envar("CONTOUR_CAFILE").StringVar(&ctx.caFile)
 	serve.Flag("contour-cert-file", "Contour certificate file name for serving gRPC over TLS.").PlaceHolder("/path/to/file").Envar("CONTOUR_CERT_FILE").StringVar(&ctx.contourCert)
 	serve.Flag("contour-config-name", "Name of ContourConfiguration CRD.").PlaceHolder("contour").Action(parseConfig).StringVar(&ctx.contourConfigurationName)
@@ -126,8 +131,8 @@ func registerServe(app *kingpin.Application) (*kingpin.CmdClause, *serveContext)
 	serve.Flag("debug", "Enable debug logging.").Short('d').BoolVar(&ctx.Config.Debug)
 	serve.Flag("debug-http-address", "Address the debug http endpoint will bind to.").PlaceHolder("<ipaddr>").StringVar(&ctx.debugAddr)
 	serve.Flag("debug-http-port", "Port the debug http endpoint will bind to.").PlaceHolder("<port>").IntVar(&ctx.debugPort)
-	serve.Flag("disable-feature", "Do not start an informer for the specified resources.").PlaceHolder("<extensionservices,tlsroutes,grpcroutes,tcproutes>").EnumsVar(&ctx.disabledFeatures, "extensionservices", "tlsroutes", "grpcroutes", "tcproutes")
-	serve.Flag("disable-leader-election", "Disable leader election mechanism.").BoolVar(&ctx.LeaderElection.Disable)
+	serve.Flag("disable-feature", "Do not start an informer for the specified resources.").PlaceHolder("<extensionservices,tlsroutes,grpcroutes,tcproutes,certificates>").EnumsVar(&ctx.disabledFeatures, "extensionservices", "tlsroutes", "grpcroutes", "tcproutes", "certificates")
+	serve.Flag("disable-leader-election", "Disable leader election mechanism.").BoolVar(&ctx.Config.LeaderElection.Disable)
 
 	serve.Flag("envoy-http-access-log", "Envoy HTTP access log.").PlaceHolder("/path/to/file").StringVar(&ctx.httpAccessLog)
 	serve.Flag("envoy-https-access-log", "Envoy HTTPS access log.").PlaceHolder("/path/to/file").StringVar(&ctx.httpsAccessLog)
@@ -138,6 +143,8 @@ func registerServe(app *kingpin.Application) (*kingpin.CmdClause, *serveContext)
 	serve.Flag("envoy-service-name", "Name of the Envoy service to inspect for Ingress status details.").PlaceHolder("<name>").StringVar(&ctx.Config.EnvoyServiceName)
 	serve.Flag("envoy-service-namespace", "Envoy Service Namespace.").PlaceHolder("<namespace>").StringVar(&ctx.Config.EnvoyServiceNamespace)
 
+	serve.Flag("feature-gates", "Feature gates to enable or disable, overriding the configuration.").PlaceHolder("<name>=<true|false>,...").SetValue(featureGatesValue{gates: &ctx.Config.FeatureGates})
+
 	serve.Flag("health-address", "Address the health HTTP endpoint will bind to.").PlaceHolder("<ipaddr>").StringVar(&ctx.healthAddr)
 	serve.Flag("health-port", "Port the health HTTP endpoint will bind to.").PlaceHolder("<port>").IntVar(&ctx.healthPort)
 	serve.Flag("http-address", "Address the metrics HTTP endpoint will bind to.").PlaceHolder("<ipaddr>").StringVar(&ctx.metricsAddr)
@@ -150,14 +157,16 @@ func registerServe(app *kingpin.Application) (*kingpin.CmdClause, *serveContext)
 
 	serve.Flag("kubeconfig", "Path to kubeconfig (if not in running inside a cluster).").PlaceHolder("/path/to/file").StringVar(&ctx.Config.Kubeconfig)
 	serve.Flag("kubernetes-client-burst", "Burst allowed for the Kubernetes client.").IntVar(&ctx.Config.KubeClientBurst)
+	serve.Flag("kubernetes-client-priority-level", "API Priority and Fairness priority level of the Kubernetes client.").PlaceHolder("<name>").StringVar(&ctx.Config.KubeClientPriorityLevel)
 	serve.Flag("kubernetes-client-qps", "QPS allowed for the Kubernetes client.").Float32Var(&ctx.Config.KubeClientQPS)
 	serve.Flag("kubernetes-debug", "Enable Kubernetes client debug logging with log level.").PlaceHolder("<log level>").UintVar(&ctx.KubernetesDebug)
 
-	serve.Flag("leader-election-lease-duration", "The duration of the leadership lease.").Default("15s").DurationVar(&ctx.LeaderElection.LeaseDuration)
-	serve.Flag("leader-election-renew-deadline", "The duration leader will retry refreshing leadership before giving up.").Default("10s").DurationVar(&ctx.LeaderElection.RenewDeadline)
-	serve.Flag("leader-election-resource-name", "The name of the resource (Lease) leader election will lease.").Default("leader-elect").StringVar(&ctx.LeaderElection.Name)
-	serve.Flag("leader-election-resource-namespace", "The namespace of the resource (Lease) leader election will lease.").Default(config.GetenvOr("CONTOUR_NAMESPACE", "projectcontour")).StringVar(&ctx.LeaderElection.Namespace)
-	serve.Flag("leader-election-retry-period", "The interval which Contour will attempt to acquire leadership lease.").Default("2s").DurationVar(&ctx.LeaderElection.RetryPeriod)
+	serve.Flag("leader-election-lease-duration", "The duration of the leadership lease.").PlaceHolder("15s").StringVar(&ctx.Config.LeaderElection.LeaseDuration)
+	serve.Flag("leader-election-renew-deadline", "The duration leader will retry refreshing leadership before giving up.").PlaceHolder("10s").StringVar(&ctx.Config.LeaderElection.RenewDeadline)
+	serve.Flag("leader-election-resource-lock", "The type of resource leader election will lock.").PlaceHolder("<leases>").StringVar((*string)(&ctx.Config.LeaderElection.ResourceLock))
+	serve.Flag("leader-election-resource-name", "The name of the resource (Lease) leader election will lease.").PlaceHolder("leader-elect").StringVar(&ctx.Config.LeaderElection.LeaseName)
+	serve.Flag("leader-election-resource-namespace", "The namespace of the resource (Lease) leader election will lease.").PlaceHolder("<namespace>").StringVar(&ctx.Config.LeaderElection.LeaseNamespace)
+	serve.Flag("leader-election-retry-period", "The interval which Contour will attempt to acquire leadership lease.").PlaceHolder("2s").StringVar(&ctx.Config.LeaderElection.RetryPeriod)
 
 	serve.Flag("root-namespaces", "Restrict contour to searching these namespaces for root ingress routes.").PlaceHolder("<ns,ns>").StringVar(&ctx.rootNamespaces)
 
@@ -180,11 +189,31 @@ type Server struct {
 	coreClient *kubernetes.Clientset
 	mgr        manager.Manager
 	registry   *prometheus.Registry
+
+	// secretSealer encrypts the private keys of Secrets as
+	// they are cached by the informer, if enabled.
+	secretSealer *sealing.Sealer
+
+	// contourConfiguration is the ContourConfiguration that
+	// Contour was started with, if any.
+	contourConfiguration *contour_api_v1alpha1.ContourConfiguration
+
+	// userConfig is the configuration specified by the user, either
+	// the spec of the ContourConfiguration or the serve context
+	// converted to one.
+	userConfig contour_api_v1alpha1.ContourConfigurationSpec
+
+	// featureGates govern the experimental subsystems.
+	featureGates *featuregate.Gates
 }
 
 // NewServer returns a Server object which contains the initial configuration
 // objects required to start an instance of Contour.
 func NewServer(log logrus.FieldLogger, ctx *serveContext) (*Server, error) {
+	s := &Server{
+		log: log,
+		ctx: ctx,
+	}
 
 	var restConfigOpts []func(*rest.Config)
 
@@ -208,6 +237,18 @@ func NewServer(log logrus.FieldLogger, ctx *serveContext) (*Server, error) {
 		return nil, fmt.Errorf("failed to create Kubernetes clients: %w", err)
 	}
 
+	if priorityLevel := ctx.Config.KubeClientPriorityLevel; priorityLevel != "" {
+		// The FlowSchema matches the service accounts of the namespace that Contour runs in.
+		contourNamespace := config.GetenvOr("CONTOUR_NAMESPACE", "projectcontour")
+
+		log.WithField("priority-level", priorityLevel).
+			WithField("flow-schema", k8s.FlowSchemaName(contourNamespace)).
+			Info("assigning Kubernetes client requests to priority level")
+		if err := k8s.EnsureFlowSchema(context.Background(), coreClient, contourNamespace, priorityLevel); err != nil {
+			return nil, fmt.Errorf("failed to assign Kubernetes client requests to priority level: %w", err)
+		}
+	}
+
 	scheme, err := k8s.NewContourScheme()
 	if err != nil {
 		return nil, fmt.Errorf("unable to create scheme: %w", err)
@@ -230,9 +271,11 @@ func NewServer(log logrus.FieldLogger, ctx *serveContext) (*Server, error) {
 							return obj, nil
 						}
 
-						// Do not touch Secrets that might be needed.
+						// Do not touch Secrets that might be needed, other than
+						// to encrypt their private key.
 						if secret.Type == corev1.SecretTypeTLS || secret.Type == corev1.SecretTypeOpaque {
-							return obj, nil
+							dag.SealPrivateKey(secret, s.secretSealer)
+							return secret, nil
 						}
 
 						// Other types of Secrets will never be referred to, so we can remove all data.
@@ -244,6 +287,25 @@ func NewServer(log logrus.FieldLogger, ctx *serveContext) (*Server, error) {
 
 						return secret, nil
 					}},
+				&corev1.Pod{}: {
+					Transform: func(obj any) (any, error) {
+						pod, ok := obj.(*corev1.Pod)
+						// TransformFunc should handle the tombstone of type cache.DeletedFinalStateUnknown
+						if !ok {
+							return obj, nil
+						}
+
+						// Only the labels of Pods are used, to select the endpoints of Service subsets.
+						return &corev1.Pod{
+							ObjectMeta: metav1.ObjectMeta{
+								Name:            pod.Name,
+								Namespace:       pod.Namespace,
+								UID:             pod.UID,
+								ResourceVersion: pod.ResourceVersion,
+								Labels:          pod.Labels,
+							},
+						}, nil
+					}},
 			},
 			// DefaultTransform is called for objects that do not have a TransformByObject function.
 			DefaultTransform: func(obj any) (any, error) {
@@ -264,19 +326,15 @@ func NewServer(log logrus.FieldLogger, ctx *serveContext) (*Server, error) {
 		options.Cache.Namespaces = watchedNamespaces
 	}
 
-	if ctx.LeaderElection.Disable {
-		log.Info("Leader election disabled")
-		options.LeaderElection = false
-	} else {
-		options.LeaderElection = true
-		options.LeaderElectionResourceLock = "leases"
-		options.LeaderElectionNamespace = ctx.LeaderElection.Namespace
-		options.LeaderElectionID = ctx.LeaderElection.Name
-		options.LeaseDuration = &ctx.LeaderElection.LeaseDuration
-		options.RenewDeadline = &ctx.LeaderElection.RenewDeadline
-		options.RetryPeriod = &ctx.LeaderElection.RetryPeriod
-		options.LeaderElectionReleaseOnCancel = true
+	// The manager is configured with the leader election configuration,
+	// so the user's configuration is read before the manager is created.
+	if err := s.readUserConfig(restConfig, scheme); err != nil {
+		return nil, err
+	}
+	if err := setLeaderElectionOptions(log, &options, s.userConfig.LeaderElection); err != nil {
+		return nil, err
 	}
+
 	mgr, err := manager.New(restConfig, options)
 	if err != nil {
 		return nil, fmt.Errorf("unable to set up controller manager: %w", err)
@@ -287,47 +345,107 @@ func NewServer(log logrus.FieldLogger, ctx *serveContext) (*Server, error) {
 	// statistics).
 	registry := controller_runtime_metrics.Registry.(*prometheus.Registry)
 
-	return &Server{
-		log:        log,
-		ctx:        ctx,
-		coreClient: coreClient,
-		mgr:        mgr,
-		registry:   registry,
-	}, nil
+	s.coreClient = coreClient
+	s.mgr = mgr
+	s.registry = registry
+
+	return s, nil
 }
 
-func (s *Server) getConfig() (contour_api_v1alpha1.ContourConfigurationSpec, error) {
-	var userConfig contour_api_v1alpha1.ContourConfigurationSpec
+// readUserConfig reads the configuration specified by the user.
+func (s *Server) readUserConfig(restConfig *rest.Config, scheme *runtime.Scheme) error {
+	if len(s.ctx.contourConfigurationName) == 0 {
+		// No contour configuration passed, so convert the ServeContext into a ContourConfigurationSpec.
+		s.userConfig = s.ctx.convertToContourConfigurationSpec()
+		return nil
+	}
 
-	// Get the ContourConfiguration CRD if specified
-	if len(s.ctx.contourConfigurationName) > 0 {
-		// Determine the name/namespace of the configuration resource utilizing the environment
-		// variable "CONTOUR_NAMESPACE" which should exist on the Contour deployment.
-		//
-		// If the env variable is not present, it will default to "projectcontour".
-		contourNamespace, found := os.LookupEnv("CONTOUR_NAMESPACE")
-		if !found {
-			contourNamespace = "projectcontour"
-		}
+	// Determine the name/namespace of the configuration resource utilizing the environment
+	// variable "CONTOUR_NAMESPACE" which should exist on the Contour deployment.
+	//
+	// If the env variable is not present, it will default to "projectcontour".
+	contourNamespace, found := os.LookupEnv("CONTOUR_NAMESPACE")
+	if !found {
+		contourNamespace = "projectcontour"
+	}
 
-		contourConfig := &contour_api_v1alpha1.ContourConfiguration{}
-		key := client.ObjectKey{Namespace: contourNamespace, Name: s.ctx.contourConfigurationName}
+	// Using a client that reads from the API server since
+	// the manager, and its caches, aren't created yet.
+	reader, err := client.New(restConfig, client.Options{Scheme: scheme})
+	if err != nil {
+		return fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
 
-		// Using GetAPIReader() here because the manager's caches won't be started yet,
-		// so reads from the manager's client (which uses the caches for reads) will fail.
-		if err := s.mgr.GetAPIReader().Get(context.Background(), key, contourConfig); err != nil {
-			return contour_api_v1alpha1.ContourConfigurationSpec{}, fmt.Errorf("error getting contour configuration %s: %v", key, err)
-		}
+	contourConfig := &contour_api_v1alpha1.ContourConfiguration{}
+	key := client.ObjectKey{Namespace: contourNamespace, Name: s.ctx.contourConfigurationName}
+	if err := reader.Get(context.Background(), key, contourConfig); err != nil {
+		return fmt.Errorf("error getting contour configuration %s: %v", key, err)
+	}
 
-		// Copy the Spec from the parsed Configuration
-		userConfig = contourConfig.Spec
-	} else {
-		// No contour configuration passed, so convert the ServeContext into a ContourConfigurationSpec.
-		userConfig = s.ctx.convertToContourConfigurationSpec()
+	// Copy the Spec from the parsed Configuration
+	s.userConfig = contourConfig.Spec
+	s.contourConfiguration = contourConfig
+
+	return nil
+}
+
+// setLeaderElectionOptions configures the leader election of the
+// manager according to the user specified leaderElection, overlaid
+// on the defaults.
+func setLeaderElectionOptions(log logrus.FieldLogger, options *manager.Options, leaderElection *contour_api_v1alpha1.LeaderElectionConfig) error {
+	spec, err := contourconfig.OverlayOnDefaults(contour_api_v1alpha1.ContourConfigurationSpec{LeaderElection: leaderElection})
+	if err != nil {
+		return err
+	}
+	le := spec.LeaderElection
+
+	if err := le.Validate(); err != nil {
+		return err
+	}
+
+	if *le.Disable {
+		log.Info("Leader election disabled")
+		options.LeaderElection = false
+		return nil
+	}
+
+	// The durations are validated above.
+	leaseDuration, _ := time.ParseDuration(*le.LeaseDuration)
+	renewDeadline, _ := time.ParseDuration(*le.RenewDeadline)
+	retryPeriod, _ := time.ParseDuration(*le.RetryPeriod)
+
+	leaseNamespace := le.LeaseNamespace
+	if leaseNamespace == "" {
+		leaseNamespace = config.GetenvOr("CONTOUR_NAMESPACE", "projectcontour")
+	}
+
+	options.LeaderElection = true
+	options.LeaderElectionResourceLock = string(le.ResourceLock)
+	options.LeaderElectionNamespace = leaseNamespace
+	options.LeaderElectionID = le.LeaseName
+	options.LeaseDuration = &leaseDuration
+	options.RenewDeadline = &renewDeadline
+	options.RetryPeriod = &retryPeriod
+	options.LeaderElectionReleaseOnCancel = true
+
+	return nil
+}
+
+func (s *Server) getConfig() (contour_api_v1alpha1.ContourConfigurationSpec, error) {
+	contourConfiguration, err := overlayAndValidate(s.userConfig)
+	if err != nil {
+		if s.contourConfiguration != nil {
+			s.setContourConfigurationInvalid(err)
+		}
+		return contour_api_v1alpha1.ContourConfigurationSpec{}, err
 	}
 
-	// Overlay the user-specified config onto the default config to come up
-	// with the final set of config to use.
+	return contourConfiguration, nil
+}
+
+// overlayAndValidate overlays the user-specified config onto the default
+// config to come up with the final set of config to use, and validates it.
+func overlayAndValidate(userConfig contour_api_v1alpha1.ContourConfigurationSpec) (contour_api_v1alpha1.ContourConfigurationSpec, error) {
 	contourConfiguration, err := contourconfig.OverlayOnDefaults(userConfig)
 	if err != nil {
 		return contour_api_v1alpha1.ContourConfigurationSpec{}, err
@@ -337,9 +455,24 @@ func (s *Server) getConfig() (contour_api_v1alpha1.ContourConfigurationSpec, err
 		return contour_api_v1alpha1.ContourConfigurationSpec{}, err
 	}
 
+	if _, err := featuregate.New(contourConfiguration.FeatureGates); err != nil {
+		return contour_api_v1alpha1.ContourConfigurationSpec{}, err
+	}
+
 	return contourConfiguration, nil
 }
 
+// setContourConfigurationInvalid reports err in the status of the
+// ContourConfiguration that Contour failed to start with. The status
+// is written directly since the status update handler isn't running.
+func (s *Server) setContourConfigurationInvalid(err error) {
+	update := status.ContourConfigurationUpdate(k8s.NamespacedNameOf(s.contourConfiguration), s.contourConfiguration.Generation, err)
+
+	if err := s.mgr.GetClient().Status().Update(context.Background(), update.Mutator.Mutate(s.contourConfiguration)); err != nil {
+		s.log.WithError(err).Error("unable to update contourconfiguration status")
+	}
+}
+
 // doServe runs the contour serve subcommand.
 func (s *Server) doServe() error {
 	// Get config. Any user-specified settings are "overlaid" onto default settings
@@ -353,6 +486,28 @@ func (s *Server) doServe() error {
 		return err
 	}
 
+	// Report the deprecated parameters of the configuration file,
+	// so that they can be migrated before they are removed.
+	for _, conversion := range s.ctx.configConversions {
+		s.log.WithField("context", "deprecations").
+			WithField("parameter", conversion.From).
+			WithField("replacement", conversion.To).
+			Warnf("configuration file parameter %s", conversion)
+	}
+
+	if s.featureGates, err = featuregate.New(contourConfiguration.FeatureGates); err != nil {
+		return err
+	}
+	for _, gate := range s.featureGates.States() {
+		log := s.log.WithField("context", "feature-gates").WithField("stage", gate.Stage)
+		switch {
+		case gate.Enabled && !gate.Default:
+			log.Infof("enabled feature gate %s", gate.Name)
+		case !gate.Enabled && gate.Default:
+			log.Infof("disabled feature gate %s", gate.Name)
+		}
+	}
+
 	// Check that all the necessary namespaces are being watched
 	watchedNamespaces := sets.New(s.ctx.watchedNamespaces()...)
 	rootNamespaces := contourConfiguration.HTTPProxy.RootNamespaces
@@ -397,66 +552,95 @@ func (s *Server) doServe() error {
 		}
 	}
 
-	timeouts, err := contourconfig.ParseTimeoutPolicy(contourConfiguration.Envoy.Timeouts)
+	listenerConfig, err := s.getListenerConfig(contourConfiguration)
 	if err != nil {
 		return err
 	}
 
-	listenerConfig := xdscache_v3.ListenerConfig{
-		UseProxyProto:                 *contourConfiguration.Envoy.Listener.UseProxyProto,
-		HTTPAccessLog:                 contourConfiguration.Envoy.HTTPListener.AccessLog,
-		HTTPSAccessLog:                contourConfiguration.Envoy.HTTPSListener.AccessLog,
-		AccessLogType:                 contourConfiguration.Envoy.Logging.AccessLogFormat,
-		AccessLogJSONFields:           contourConfiguration.Envoy.Logging.AccessLogJSONFields,
-		AccessLogLevel:                contourConfiguration.Envoy.Logging.AccessLogLevel,
-		AccessLogFormatString:         contourConfiguration.Envoy.Logging.AccessLogFormatString,
-		AccessLogFormatterExtensions:  contourConfiguration.Envoy.Logging.AccessLogFormatterExtensions(),
-		MinimumTLSVersion:             annotation.MinTLSVersion(contourConfiguration.Envoy.Listener.TLS.MinimumProtocolVersion, "1.2"),
-		CipherSuites:                  contourConfiguration.Envoy.Listener.TLS.SanitizedCipherSuites(),
-		Timeouts:                      timeouts,
-		DefaultHTTPVersions:           parseDefaultHTTPVersions(contourConfiguration.Envoy.DefaultHTTPVersions),
-		AllowChunkedLength:            !*contourConfiguration.Envoy.Listener.DisableAllowChunkedLength,
-		MergeSlashes:                  !*contourConfiguration.Envoy.Listener.DisableMergeSlashes,
-		ServerHeaderTransformation:    contourConfiguration.Envoy.Listener.ServerHeaderTransformation,
-		XffNumTrustedHops:             *contourConfiguration.Envoy.Network.XffNumTrustedHops,
-		ConnectionBalancer:            contourConfiguration.Envoy.Listener.ConnectionBalancer,
-		MaxRequestsPerConnection:      contourConfiguration.Envoy.Listener.MaxRequestsPerConnection,
-		PerConnectionBufferLimitBytes: contourConfiguration.Envoy.Listener.PerConnectionBufferLimitBytes,
-	}
-
-	if listenerConfig.TracingConfig, err = s.setupTracingService(contourConfiguration.Tracing); err != nil {
-		return err
-	}
-
-	if listenerConfig.RateLimitConfig, err = s.setupRateLimitService(contourConfiguration); err != nil {
+	if listenerConfig.SecretBackend, err = s.setupSecretBackend(contourConfiguration); err != nil {
 		return err
 	}
 
-	if listenerConfig.GlobalExternalAuthConfig, err = s.setupGlobalExternalAuthentication(contourConfiguration); err != nil {
+	if s.secretSealer, err = s.setupSecretEncryption(contourConfiguration); err != nil {
 		return err
 	}
 
 	contourMetrics := metrics.NewMetrics(s.registry)
 
+	var deprecatedParameters []string
+	for _, conversion := range s.ctx.configConversions {
+		deprecatedParameters = append(deprecatedParameters, conversion.From)
+	}
+	contourMetrics.SetDeprecatedParameterMetric(deprecatedParameters)
+
 	// Endpoints updates are handled directly by the EndpointsTranslator
 	// due to their high update rate and their orthogonal nature.
 	endpointHandler := xdscache_v3.NewEndpointsTranslator(s.log.WithField("context", "endpointstranslator"))
+	if delay := contourConfiguration.Envoy.Cluster.EndpointDeregistrationDelay; delay != nil {
+		if endpointHandler.DeregistrationDelay, err = time.ParseDuration(*delay); err != nil {
+			return fmt.Errorf("failed to parse endpoint deregistration delay: %w", err)
+		}
+	}
+
+	secretsCache := xdscache_v3.NewSecretsCache(envoy_v3.StatsSecrets(contourConfiguration.Envoy.Metrics.TLS))
+	secretsCache.Backend = listenerConfig.SecretBackend
+	secretsCache.Sealer = s.secretSealer
+	secretsCache.Log = s.log.WithField("context", "secretCache")
+	secretsCache.Metrics = contourMetrics
+
+	listenerCache := xdscache_v3.NewListenerCache(listenerConfig, *contourConfiguration.Envoy.Metrics, *contourConfiguration.Envoy.Health, *contourConfiguration.Envoy.Network.EnvoyAdminPort)
+
+	// Set the bearer token of the Envoy metrics listeners
+	// again when its file is rotated.
+	if tokenFile := contourConfiguration.Envoy.Metrics.BearerTokenFile; tokenFile != "" {
+		if err := s.mgr.Add(&metricsTokenWatcher{
+			log:      s.log.WithField("context", "metrics-token-watcher"),
+			path:     tokenFile,
+			token:    listenerConfig.MetricsBearerToken,
+			setToken: listenerCache.SetMetricsBearerToken,
+		}); err != nil {
+			return err
+		}
+	}
+
+	runtimeCache := &xdscache_v3.RuntimeCache{
+		Flags: contourConfiguration.RuntimeFlags,
+	}
+
+	clusterCache, err := newClusterCache(contourConfiguration)
+	if err != nil {
+		return err
+	}
+
+	routeCache := &xdscache_v3.RouteCache{
+		CaptureEnabled:     listenerConfig.CaptureConfig != nil,
+		RemoveServerHeader: listenerConfig.ServerHeaderTransformation == contour_api_v1alpha1.RemoveServerHeader,
+	}
 
 	resources := []xdscache.ResourceCache{
-		xdscache_v3.NewListenerCache(listenerConfig, *contourConfiguration.Envoy.Metrics, *contourConfiguration.Envoy.Health, *contourConfiguration.Envoy.Network.EnvoyAdminPort),
-		xdscache_v3.NewSecretsCache(envoy_v3.StatsSecrets(contourConfiguration.Envoy.Metrics.TLS)),
-		&xdscache_v3.RouteCache{},
-		&xdscache_v3.ClusterCache{},
+		listenerCache,
+		secretsCache,
+		routeCache,
+		clusterCache,
 		endpointHandler,
-		&xdscache_v3.RuntimeCache{},
+		runtimeCache,
 	}
 
+	// acks tracks the revisions of the xDS resources that Envoy has ACKed.
+	acks := xds.NewACKTracker()
+
 	// snapshotHandler is used to produce new snapshots when the internal state changes for any xDS resource.
-	snapshotHandler := xdscache.NewSnapshotHandler(resources, s.log.WithField("context", "snapshotHandler"))
+	snapshotHandler := xdscache.NewSnapshotHandler(resources, acks, s.log.WithField("context", "snapshotHandler"))
 
 	// register observer for endpoints updates.
 	endpointHandler.Observer = contour.ComposeObservers(snapshotHandler)
 
+	// register observer for listener removals.
+	listenerCache.Observer = contour.ComposeObservers(snapshotHandler)
+
+	// register observer for runtime flag changes.
+	runtimeCache.Observer = contour.ComposeObservers(snapshotHandler)
+
 	// Log that we're using the fallback certificate if configured.
 	if contourConfiguration.HTTPProxy.FallbackCertificate != nil {
 		s.log.WithField("context", "fallback-certificate").Infof("enabled fallback certificate with secret: %q", contourConfiguration.HTTPProxy.FallbackCertificate)
@@ -465,77 +649,128 @@ func (s *Server) doServe() error {
 		s.log.WithField("context", "envoy-client-certificate").Infof("enabled client certificate with secret: %q", contourConfiguration.Envoy.ClientCertificate)
 	}
 
-	var ingressClassNames []string
-	if contourConfiguration.Ingress != nil {
-		ingressClassNames = contourConfiguration.Ingress.ClassNames
+	sh := k8s.NewStatusUpdateHandler(s.log.WithField("context", "StatusUpdateHandler"), s.mgr.GetClient(), contourMetrics)
+	if err := s.mgr.Add(sh); err != nil {
+		return err
 	}
 
-	var clientCert *types.NamespacedName
-	var fallbackCert *types.NamespacedName
-	if contourConfiguration.Envoy.ClientCertificate != nil {
-		clientCert = &types.NamespacedName{Name: contourConfiguration.Envoy.ClientCertificate.Name, Namespace: contourConfiguration.Envoy.ClientCertificate.Namespace}
-	}
-	if contourConfiguration.HTTPProxy.FallbackCertificate != nil {
-		fallbackCert = &types.NamespacedName{Name: contourConfiguration.HTTPProxy.FallbackCertificate.Name, Namespace: contourConfiguration.HTTPProxy.FallbackCertificate.Namespace}
+	dbc, err := s.getDAGBuilderConfig(contourConfiguration, listenerConfig, contourMetrics)
+	if err != nil {
+		return err
 	}
-
-	sh := k8s.NewStatusUpdateHandler(s.log.WithField("context", "StatusUpdateHandler"), s.mgr.GetClient(), contourMetrics)
-	if err := s.mgr.Add(sh); err != nil {
+	builder := s.getDAGBuilder(dbc)
+
+	// Report the generation of the HTTPProxies programmed into Envoy, once
+	// Envoy ACKs the revision of the xDS resources built from them.
+	programmedGenerationReporter := contour.NewProgrammedGenerationReporter(
+		s.log.WithField("context", "programmedGenerationReporter"),
+		acks,
+		sh.Writer(),
+		dag.ComposeObservers(
+			// The resource caches convert the DAG independently, so
+			// convert it concurrently before taking the snapshot.
+			dag.ComposeObserversParallel(0, xdscache.ObserversOf(resources)...),
+			snapshotHandler,
+		),
+	)
+	if err := s.mgr.Add(programmedGenerationReporter); err != nil {
 		return err
 	}
 
-	var gatewayControllerName string
-	var gatewayRef *types.NamespacedName
+	dagObservers := []dag.Observer{programmedGenerationReporter}
+	needsNotification := []leadership.NeedLeaderElectionNotification{programmedGenerationReporter}
+
+	// Report the status of the ContourConfiguration and restart
+	// when its spec changes, if Contour was started with one.
+	if s.contourConfiguration != nil {
+		contourConfigurationController, err := controller.RegisterContourConfigurationController(
+			s.log.WithField("context", "contourconfiguration-controller"),
+			s.mgr,
+			sh.Writer(),
+			k8s.NamespacedNameOf(s.contourConfiguration),
+			s.contourConfiguration.Generation,
+			func(spec contour_api_v1alpha1.ContourConfigurationSpec) error {
+				_, err := overlayAndValidate(spec)
+				return err
+			},
+		)
+		if err != nil {
+			return err
+		}
+		needsNotification = append(needsNotification, contourConfigurationController)
+	}
 
-	if contourConfiguration.Gateway != nil {
-		gatewayControllerName = contourConfiguration.Gateway.ControllerName
+	// Report the objects that use deprecated features.
+	deprecationReporter := contour.NewDeprecationReporter(
+		s.log.WithField("context", "deprecations"),
+		s.mgr.GetEventRecorderFor("contour"),
+	)
+	dagObservers = append(dagObservers, deprecationReporter)
+	needsNotification = append(needsNotification, deprecationReporter)
 
-		if contourConfiguration.Gateway.GatewayRef != nil {
-			gatewayRef = &types.NamespacedName{
-				Namespace: contourConfiguration.Gateway.GatewayRef.Namespace,
-				Name:      contourConfiguration.Gateway.GatewayRef.Name,
-			}
+	// Maintain a NamespaceReport in each namespace containing HTTPProxies, if enabled.
+	if *contourConfiguration.HTTPProxy.EnableNamespaceReports {
+		namespaceReportWriter := contour.NewNamespaceReportWriter(s.log.WithField("context", "namespaceReportWriter"), s.mgr.GetClient())
+		if err := s.mgr.Add(namespaceReportWriter); err != nil {
+			return err
 		}
+		dagObservers = append(dagObservers, namespaceReportWriter)
+		needsNotification = append(needsNotification, namespaceReportWriter)
 	}
 
-	builder := s.getDAGBuilder(dagBuilderConfig{
-		ingressClassNames:                  ingressClassNames,
-		rootNamespaces:                     contourConfiguration.HTTPProxy.RootNamespaces,
-		gatewayControllerName:              gatewayControllerName,
-		gatewayRef:                         gatewayRef,
-		disablePermitInsecure:              *contourConfiguration.HTTPProxy.DisablePermitInsecure,
-		enableExternalNameService:          *contourConfiguration.EnableExternalNameService,
-		dnsLookupFamily:                    contourConfiguration.Envoy.Cluster.DNSLookupFamily,
-		headersPolicy:                      contourConfiguration.Policy,
-		clientCert:                         clientCert,
-		fallbackCert:                       fallbackCert,
-		connectTimeout:                     timeouts.ConnectTimeout,
-		client:                             s.mgr.GetClient(),
-		metrics:                            contourMetrics,
-		httpAddress:                        contourConfiguration.Envoy.HTTPListener.Address,
-		httpPort:                           contourConfiguration.Envoy.HTTPListener.Port,
-		httpsAddress:                       contourConfiguration.Envoy.HTTPSListener.Address,
-		httpsPort:                          contourConfiguration.Envoy.HTTPSListener.Port,
-		globalExternalAuthorizationService: contourConfiguration.GlobalExternalAuthorization,
-		globalRateLimitService:             contourConfiguration.RateLimitService,
-		maxRequestsPerConnection:           contourConfiguration.Envoy.Cluster.MaxRequestsPerConnection,
-		perConnectionBufferLimitBytes:      contourConfiguration.Envoy.Cluster.PerConnectionBufferLimitBytes,
-	})
+	// Aggregate the Envoy cluster stats into metrics of each HTTPProxy, if enabled.
+	if contourConfiguration.HTTPProxyMetrics != nil {
+		aggregator, err := s.setupHTTPProxyMetrics(contourConfiguration, clusterCache)
+		if err != nil {
+			return err
+		}
+		dagObservers = append(dagObservers, aggregator)
+	}
 
 	// Build the core Kubernetes event handler.
 	observer := contour.NewRebuildMetricsObserver(
 		contourMetrics,
-		dag.ComposeObservers(append(xdscache.ObserversOf(resources), snapshotHandler)...),
+		dag.ComposeObservers(dagObservers...),
 	)
 	contourHandler := contour.NewEventHandler(contour.EventHandlerConfig{
-		Logger:          s.log.WithField("context", "contourEventHandler"),
-		HoldoffDelay:    100 * time.Millisecond,
-		HoldoffMaxDelay: 500 * time.Millisecond,
-		Observer:        observer,
-		StatusUpdater:   sh.Writer(),
-		Builder:         builder,
+		Logger:           s.log.WithField("context", "contourEventHandler"),
+		HoldoffDelay:     100 * time.Millisecond,
+		HoldoffMaxDelay:  500 * time.Millisecond,
+		Observer:         observer,
+		StatusUpdater:    sh.Writer(),
+		Builder:          builder,
+		EndpointsHandler: endpointHandler,
 	})
 
+	// Apply changes of the configuration file, if Contour was started with one.
+	if len(s.ctx.configPaths) > 0 {
+		watcher, err := config.NewWatcher(s.ctx.configPaths...)
+		if err != nil {
+			return err
+		}
+
+		targets := reloadTargets{
+			eventHandler:  contourHandler,
+			builder:       builder,
+			listenerCache: listenerCache,
+			routeCache:    routeCache,
+			clusterCache:  clusterCache,
+			runtimeCache:  runtimeCache,
+			secretBackend: listenerConfig.SecretBackend,
+		}
+		if err := s.mgr.Add(&configReloader{
+			log:             s.log.WithField("context", "config-reloader"),
+			watcher:         watcher,
+			params:          s.ctx.Config,
+			restartRequired: restartRequiredParameters,
+			reload: func(params *config.Parameters) error {
+				return s.reloadConfig(params, targets)
+			},
+		}); err != nil {
+			return err
+		}
+	}
+
 	// Wrap contourHandler in an EventRecorder which tracks API server events.
 	eventHandler := &contour.EventRecorder{
 		Next:    contourHandler,
@@ -548,7 +783,10 @@ func (s *Server) doServe() error {
 		"tlscertificatedelegations": &contour_api_v1.TLSCertificateDelegation{},
 		"extensionservices":         &contour_api_v1alpha1.ExtensionService{},
 		"services":                  &corev1.Service{},
+		"configmaps":                &corev1.ConfigMap{},
 		"ingresses":                 &networking_v1.Ingress{},
+		"certificates":              &certmanagerv1.Certificate{},
+		"namespaces":                &corev1.Namespace{},
 	}
 
 	// Some of the resources are optional and can be disabled, do not create informers for those.
@@ -556,6 +794,12 @@ func (s *Server) doServe() error {
 		delete(informerResources, feat)
 	}
 
+	// cert-manager may not be installed, only inform on its Certificates if it is.
+	if r, ok := informerResources["certificates"]; ok && !s.resourceInstalled(r) {
+		s.log.Info("cert-manager Certificates are not installed, HTTPProxy tls.certificateName is not supported")
+		delete(informerResources, "certificates")
+	}
+
 	// Inform on the remaining resources.
 	for name, r := range informerResources {
 		if err := informOnResource(r, eventHandler, s.mgr.GetCache()); err != nil {
@@ -564,7 +808,7 @@ func (s *Server) doServe() error {
 	}
 
 	// Inform on Gateway API resources.
-	needsNotification := s.setupGatewayAPI(contourConfiguration, s.mgr, eventHandler, sh)
+	needsNotification = append(needsNotification, s.setupGatewayAPI(contourConfiguration, s.mgr, eventHandler, sh)...)
 
 	// Inform on secrets, filtering by root namespaces.
 	var handler cache.ResourceEventHandler = eventHandler
@@ -578,14 +822,17 @@ func (s *Server) doServe() error {
 		s.log.WithError(err).WithField("resource", "secrets").Fatal("failed to create informer")
 	}
 
-	// Inform on endpoints.
-	if err := informOnResource(&corev1.Endpoints{}, &contour.EventRecorder{
-		Next:    endpointHandler,
-		Counter: contourMetrics.EventHandlerOperations,
-	}, s.mgr.GetCache()); err != nil {
+	// Inform on endpoints. The event handler sends them straight
+	// to the endpoints translator, without a DAG rebuild.
+	if err := informOnResource(&corev1.Endpoints{}, eventHandler, s.mgr.GetCache()); err != nil {
 		s.log.WithError(err).WithField("resource", "endpoints").Fatal("failed to create informer")
 	}
 
+	// Inform on pods, whose labels select the endpoints of Service subsets.
+	if err := informOnResource(&corev1.Pod{}, eventHandler, s.mgr.GetCache()); err != nil {
+		s.log.WithError(err).WithField("resource", "pods").Fatal("failed to create informer")
+	}
+
 	// Register our event handler with the manager.
 	if err := s.mgr.Add(contourHandler); err != nil {
 		return err
@@ -602,7 +849,7 @@ func (s *Server) doServe() error {
 	}
 
 	// Create debug service and register with mgr.
-	if err := s.setupDebugService(*contourConfiguration.Debug, builder); err != nil {
+	if err := s.setupDebugService(*contourConfiguration.Debug, builder, programmedGenerationReporter); err != nil {
 		return err
 	}
 
@@ -611,9 +858,9 @@ func (s *Server) doServe() error {
 		log:                   s.log.WithField("context", "loadBalancerStatusWriter"),
 		cache:                 s.mgr.GetCache(),
 		lbStatus:              make(chan corev1.LoadBalancerStatus, 1),
-		ingressClassNames:     ingressClassNames,
-		gatewayControllerName: gatewayControllerName,
-		gatewayRef:            gatewayRef,
+		ingressClassNames:     dbc.ingressClassNames,
+		gatewayControllerName: dbc.gatewayControllerName,
+		gatewayRef:            dbc.gatewayRef,
 		statusUpdater:         sh.Writer(),
 	}
 	if err := s.mgr.Add(lbsw); err != nil {
@@ -652,6 +899,8 @@ func (s *Server) doServe() error {
 		config:          *contourConfiguration.XDSServer,
 		snapshotHandler: snapshotHandler,
 		resources:       resources,
+		acks:            acks,
+		featureGates:    s.featureGates,
 	}
 	if err := s.mgr.Add(xdsServer); err != nil {
 		return err
@@ -661,6 +910,7 @@ func (s *Server) doServe() error {
 		ToNotify: append([]leadership.NeedLeaderElectionNotification{
 			contourHandler,
 			observer,
+			contourMetrics,
 		}, needsNotification...),
 	}
 	if err := s.mgr.Add(notifier); err != nil {
@@ -671,6 +921,307 @@ func (s *Server) doServe() error {
 	return s.mgr.Start(signals.SetupSignalHandler())
 }
 
+// getListenerConfig returns the configuration of the Envoy
+// listeners, except for their secret backend, which is only
+// set up once.
+func (s *Server) getListenerConfig(contourConfiguration contour_api_v1alpha1.ContourConfigurationSpec) (xdscache_v3.ListenerConfig, error) {
+	timeouts, err := contourconfig.ParseTimeoutPolicy(contourConfiguration.Envoy.Timeouts)
+	if err != nil {
+		return xdscache_v3.ListenerConfig{}, err
+	}
+
+	accessLogging := contourConfiguration.Envoy.Logging.WithAccessLogFormatPreset()
+
+	listenerConfig := xdscache_v3.ListenerConfig{
+		UseProxyProto:                 *contourConfiguration.Envoy.Listener.UseProxyProto,
+		HTTPAccessLog:                 contourConfiguration.Envoy.HTTPListener.AccessLog,
+		HTTPSAccessLog:                contourConfiguration.Envoy.HTTPSListener.AccessLog,
+		AccessLogType:                 accessLogging.AccessLogFormat,
+		AccessLogJSONFields:           accessLogging.AccessLogJSONFields,
+		AccessLogLevel:                accessLogging.AccessLogLevel,
+		AccessLogFormatString:         accessLogging.AccessLogFormatString,
+		AccessLogFormatterExtensions:  accessLogging.AccessLogFormatterExtensions(),
+		MinimumTLSVersion:             annotation.MinTLSVersion(contourConfiguration.Envoy.Listener.TLS.MinimumProtocolVersion, "1.2"),
+		CipherSuites:                  contourConfiguration.Envoy.Listener.TLS.SanitizedCipherSuites(),
+		Timeouts:                      timeouts,
+		DefaultHTTPVersions:           parseDefaultHTTPVersions(contourConfiguration.Envoy.DefaultHTTPVersions),
+		AllowChunkedLength:            !*contourConfiguration.Envoy.Listener.DisableAllowChunkedLength,
+		MergeSlashes:                  !*contourConfiguration.Envoy.Listener.DisableMergeSlashes,
+		ServerHeaderTransformation:    contourConfiguration.Envoy.Listener.ServerHeaderTransformation,
+		XffNumTrustedHops:             *contourConfiguration.Envoy.Network.XffNumTrustedHops,
+		ConnectionBalancer:            contourConfiguration.Envoy.Listener.ConnectionBalancer,
+		DrainType:                     contourConfiguration.Envoy.Listener.DrainType,
+		MaxRequestsPerConnection:      contourConfiguration.Envoy.Listener.MaxRequestsPerConnection,
+		PerConnectionBufferLimitBytes: contourConfiguration.Envoy.Listener.PerConnectionBufferLimitBytes,
+	}
+
+	if delay := contourConfiguration.Envoy.Listener.RemovalDelay; delay != nil {
+		if listenerConfig.RemovalDelay, err = time.ParseDuration(*delay); err != nil {
+			return xdscache_v3.ListenerConfig{}, fmt.Errorf("failed to parse listener removal delay: %w", err)
+		}
+	}
+
+	if http2 := contourConfiguration.Envoy.Listener.HTTP2; http2 != nil {
+		listenerConfig.HTTP2Settings = envoy_v3.HTTP2Settings{
+			MaxConcurrentStreams:                         http2.MaxConcurrentStreams,
+			InitialStreamWindowSize:                      http2.InitialStreamWindowSize,
+			InitialConnectionWindowSize:                  http2.InitialConnectionWindowSize,
+			MaxOutboundFrames:                            http2.MaxOutboundFrames,
+			MaxOutboundControlFrames:                     http2.MaxOutboundControlFrames,
+			MaxConsecutiveInboundFramesWithEmptyPayload:  http2.MaxConsecutiveInboundFramesWithEmptyPayload,
+			MaxInboundPriorityFramesPerStream:            http2.MaxInboundPriorityFramesPerStream,
+			MaxInboundWindowUpdateFramesPerDataFrameSent: http2.MaxInboundWindowUpdateFramesPerDataFrameSent,
+		}
+	}
+
+	if opts := contourConfiguration.Envoy.Listener.SocketOptions; opts != nil {
+		listenerConfig.SocketOptions = envoy_v3.SocketOptions{
+			TOS:          opts.TOS,
+			TrafficClass: opts.TrafficClass,
+			Freebind:     ref.Val(opts.Freebind, false),
+		}
+
+		if keepalive := opts.TCPKeepalive; keepalive != nil {
+			listenerConfig.SocketOptions.TCPKeepaliveProbes = keepalive.Probes
+			if keepalive.Idle != nil {
+				if listenerConfig.SocketOptions.TCPKeepaliveIdle, err = time.ParseDuration(*keepalive.Idle); err != nil {
+					return xdscache_v3.ListenerConfig{}, fmt.Errorf("failed to parse TCP keepalive idle time: %w", err)
+				}
+			}
+			if keepalive.Interval != nil {
+				if listenerConfig.SocketOptions.TCPKeepaliveInterval, err = time.ParseDuration(*keepalive.Interval); err != nil {
+					return xdscache_v3.ListenerConfig{}, fmt.Errorf("failed to parse TCP keepalive interval: %w", err)
+				}
+			}
+		}
+	}
+
+	if compression := contourConfiguration.Envoy.Compression; compression != nil {
+		listenerConfig.Compression = envoy_v3.CompressionSettings{
+			Algorithm:        compression.Algorithm,
+			MinContentLength: compression.MinContentLength,
+			ContentTypes:     compression.ContentTypes,
+		}
+	}
+
+	if hcm := contourConfiguration.Envoy.Listener.HTTPConnectionManager; hcm != nil {
+		listenerConfig.HTTPConnectionManagerSettings = envoy_v3.HTTPConnectionManagerSettings{
+			ServerName:                ref.Val(hcm.ServerName, ""),
+			PreserveExternalRequestID: hcm.PreserveExternalRequestID,
+			NormalizePath:             hcm.NormalizePath,
+			Via:                       ref.Val(hcm.Via, ""),
+		}
+	}
+
+	for _, f := range contourConfiguration.Envoy.Listener.HTTPFilters {
+		filter, err := envoy_v3.ParseHTTPFilter(f.Name, f.TypedConfig)
+		if err != nil {
+			return xdscache_v3.ListenerConfig{}, err
+		}
+		listenerConfig.HTTPFilters = append(listenerConfig.HTTPFilters, envoy_v3.AdditionalHTTPFilter{
+			Position: f.Position,
+			Filter:   filter,
+		})
+	}
+
+	if timeoutParams := contourConfiguration.Envoy.Timeouts; timeoutParams != nil {
+		listenerConfig.TimeoutReplies = envoy_v3.TimeoutReplies{
+			Response: parseTimeoutReply(timeoutParams.ResponseTimeoutReply),
+			Idle:     parseTimeoutReply(timeoutParams.IdleTimeoutReply),
+		}
+	}
+
+	for _, m := range contourConfiguration.Envoy.ResponseMap {
+		listenerConfig.ResponseMap = append(listenerConfig.ResponseMap, envoy_v3.ResponseMapping{
+			StatusCode:        uint32(m.StatusCode),
+			RewriteStatusCode: uint32(m.RewriteStatusCode),
+			Body:              m.Body,
+			ContentType:       m.ContentType,
+			JSONFormat:        m.JSONFormat,
+		})
+	}
+
+	if hl := contourConfiguration.Envoy.HealthListener; hl != nil {
+		listenerConfig.HealthSettings.ReadinessPath = ref.Val(hl.ReadinessPath, "")
+		if hl.Liveness != nil {
+			listenerConfig.HealthSettings.LivenessPath = hl.Liveness.Path
+			listenerConfig.HealthSettings.LivenessClusterMinHealthyPercentages = hl.Liveness.ClusterMinHealthyPercentages
+		}
+	}
+
+	// Envoy cannot read the bearer token itself, so it is read
+	// here and placed in the static stats listeners, which are
+	// updated by a metricsTokenWatcher when the file changes.
+	if tokenFile := contourConfiguration.Envoy.Metrics.BearerTokenFile; tokenFile != "" {
+		token, err := os.ReadFile(tokenFile)
+		if err != nil {
+			return xdscache_v3.ListenerConfig{}, fmt.Errorf("error reading Envoy metrics bearer token: %w", err)
+		}
+		listenerConfig.MetricsBearerToken = strings.TrimSpace(string(token))
+	}
+
+	if listenerConfig.TracingConfig, err = s.setupTracingService(contourConfiguration.Tracing); err != nil {
+		return xdscache_v3.ListenerConfig{}, err
+	}
+
+	if listenerConfig.GRPCAccessLogConfig, err = s.setupGRPCAccessLogService(accessLogging.AccessLogGRPC); err != nil {
+		return xdscache_v3.ListenerConfig{}, err
+	}
+
+	if filter := accessLogging.AccessLogFilter; filter != nil {
+		listenerConfig.AccessLogFilter = &envoy_v3.AccessLogFilterConfig{
+			MinStatusCode:  ref.Val(filter.MinStatusCode, 0),
+			RequestHeaders: filter.RequestHeaders,
+			SamplePercent:  ref.Val(filter.SamplePercent, 0),
+		}
+		if filter.MinDuration != nil {
+			if listenerConfig.AccessLogFilter.MinDuration, err = time.ParseDuration(*filter.MinDuration); err != nil {
+				return xdscache_v3.ListenerConfig{}, fmt.Errorf("failed to parse access log filter minimum duration: %w", err)
+			}
+		}
+	}
+
+	if capture := contourConfiguration.Capture; capture != nil {
+		s.log.WithField("context", "capture").Infof("capturing requests on routes with capture enabled to %q", capture.FilePathPrefix)
+		listenerConfig.CaptureConfig = &envoy_v3.CaptureConfig{
+			FilePathPrefix:   capture.FilePathPrefix,
+			SamplePercent:    ref.Val(capture.SamplePercent, 100),
+			MaxBufferedBytes: ref.Val(capture.MaxBufferedBytes, 0),
+		}
+	}
+
+	if listenerConfig.RateLimitConfig, err = s.setupRateLimitService(contourConfiguration); err != nil {
+		return xdscache_v3.ListenerConfig{}, err
+	}
+
+	if listenerConfig.GlobalExternalAuthConfig, err = s.setupGlobalExternalAuthentication(contourConfiguration); err != nil {
+		return xdscache_v3.ListenerConfig{}, err
+	}
+
+	return listenerConfig, nil
+}
+
+// newClusterCache returns a ClusterCache configured with
+// the cluster settings of contourConfiguration.
+func newClusterCache(contourConfiguration contour_api_v1alpha1.ContourConfigurationSpec) (*xdscache_v3.ClusterCache, error) {
+	var dnsResolverSettings envoy_v3.DNSResolverSettings
+	for _, resolver := range contourConfiguration.Envoy.Cluster.DNSResolvers {
+		ip, port, err := contour_api_v1alpha1.ParseDNSResolver(resolver)
+		if err != nil {
+			return nil, err
+		}
+		dnsResolverSettings.Resolvers = append(dnsResolverSettings.Resolvers, envoy_v3.SocketAddress(ip, int(port)))
+	}
+	if rate := contourConfiguration.Envoy.Cluster.DNSRefreshRate; rate != nil {
+		var err error
+		if dnsResolverSettings.RefreshRate, err = time.ParseDuration(*rate); err != nil {
+			return nil, fmt.Errorf("failed to parse DNS refresh rate: %w", err)
+		}
+	}
+
+	var circuitBreakerDefaults envoy_v3.CircuitBreakerSettings
+	if cb := contourConfiguration.Envoy.Cluster.CircuitBreakers; cb != nil {
+		circuitBreakerDefaults = envoy_v3.CircuitBreakerSettings{
+			MaxConnections:     ref.Val(cb.MaxConnections, 0),
+			MaxPendingRequests: ref.Val(cb.MaxPendingRequests, 0),
+			MaxRequests:        ref.Val(cb.MaxRequests, 0),
+			MaxRetries:         ref.Val(cb.MaxRetries, 0),
+
+			RetryBudgetPercent:             ref.Val(cb.RetryBudgetPercent, 0),
+			RetryBudgetMinRetryConcurrency: ref.Val(cb.RetryBudgetMinRetryConcurrency, 0),
+		}
+	}
+
+	return &xdscache_v3.ClusterCache{
+		StatNameFormat:         contourConfiguration.Envoy.Cluster.StatNameFormat,
+		MaxStatNameLength:      int(ref.Val(contourConfiguration.Envoy.Cluster.MaxStatNameLength, 0)),
+		DNSResolverSettings:    dnsResolverSettings,
+		CircuitBreakerDefaults: circuitBreakerDefaults,
+	}, nil
+}
+
+// getDAGBuilderConfig returns the configuration of the DAG builder.
+func (s *Server) getDAGBuilderConfig(contourConfiguration contour_api_v1alpha1.ContourConfigurationSpec,
+	listenerConfig xdscache_v3.ListenerConfig, contourMetrics *metrics.Metrics) (dagBuilderConfig, error) {
+	var http2Keepalive *dag.HTTP2KeepaliveConfig
+	if keepalive := contourConfiguration.Envoy.Cluster.HTTP2Keepalive; keepalive != nil {
+		var err error
+		http2Keepalive = &dag.HTTP2KeepaliveConfig{}
+		if http2Keepalive.Interval, err = time.ParseDuration(keepalive.Interval); err != nil {
+			return dagBuilderConfig{}, fmt.Errorf("failed to parse HTTP/2 keepalive interval: %w", err)
+		}
+		if http2Keepalive.Timeout, err = time.ParseDuration(keepalive.Timeout); err != nil {
+			return dagBuilderConfig{}, fmt.Errorf("failed to parse HTTP/2 keepalive timeout: %w", err)
+		}
+	}
+
+	var http2Settings *dag.HTTP2Settings
+	if http2 := contourConfiguration.Envoy.Cluster.HTTP2; http2 != nil {
+		http2Settings = &dag.HTTP2Settings{
+			MaxConcurrentStreams:        http2.MaxConcurrentStreams,
+			InitialStreamWindowSize:     http2.InitialStreamWindowSize,
+			InitialConnectionWindowSize: http2.InitialConnectionWindowSize,
+		}
+	}
+
+	var ingressClassNames []string
+	if contourConfiguration.Ingress != nil {
+		ingressClassNames = contourConfiguration.Ingress.ClassNames
+	}
+
+	var clientCert *types.NamespacedName
+	var fallbackCert *types.NamespacedName
+	if contourConfiguration.Envoy.ClientCertificate != nil {
+		clientCert = &types.NamespacedName{Name: contourConfiguration.Envoy.ClientCertificate.Name, Namespace: contourConfiguration.Envoy.ClientCertificate.Namespace}
+	}
+	if contourConfiguration.HTTPProxy.FallbackCertificate != nil {
+		fallbackCert = &types.NamespacedName{Name: contourConfiguration.HTTPProxy.FallbackCertificate.Name, Namespace: contourConfiguration.HTTPProxy.FallbackCertificate.Namespace}
+	}
+
+	var gatewayControllerName string
+	var gatewayRef *types.NamespacedName
+
+	if contourConfiguration.Gateway != nil {
+		gatewayControllerName = contourConfiguration.Gateway.ControllerName
+
+		if contourConfiguration.Gateway.GatewayRef != nil {
+			gatewayRef = &types.NamespacedName{
+				Namespace: contourConfiguration.Gateway.GatewayRef.Namespace,
+				Name:      contourConfiguration.Gateway.GatewayRef.Name,
+			}
+		}
+	}
+
+	return dagBuilderConfig{
+		ingressClassNames:                  ingressClassNames,
+		rootNamespaces:                     contourConfiguration.HTTPProxy.RootNamespaces,
+		gatewayControllerName:              gatewayControllerName,
+		gatewayRef:                         gatewayRef,
+		disablePermitInsecure:              *contourConfiguration.HTTPProxy.DisablePermitInsecure,
+		insecureVirtualHosts:               contourConfiguration.HTTPProxy.InsecureVirtualHosts,
+		enableExternalNameService:          *contourConfiguration.EnableExternalNameService,
+		dnsLookupFamily:                    contourConfiguration.Envoy.Cluster.DNSLookupFamily,
+		headersPolicy:                      contourConfiguration.Policy,
+		clientCert:                         clientCert,
+		fallbackCert:                       fallbackCert,
+		connectTimeout:                     listenerConfig.Timeouts.ConnectTimeout,
+		client:                             s.mgr.GetClient(),
+		metrics:                            contourMetrics,
+		httpAddress:                        contourConfiguration.Envoy.HTTPListener.Address,
+		httpPort:                           contourConfiguration.Envoy.HTTPListener.Port,
+		httpsAddress:                       contourConfiguration.Envoy.HTTPSListener.Address,
+		httpsPort:                          contourConfiguration.Envoy.HTTPSListener.Port,
+		globalExternalAuthorizationService: contourConfiguration.GlobalExternalAuthorization,
+		globalRateLimitService:             contourConfiguration.RateLimitService,
+		maxRequestsPerConnection:           contourConfiguration.Envoy.Cluster.MaxRequestsPerConnection,
+		perConnectionBufferLimitBytes:      contourConfiguration.Envoy.Cluster.PerConnectionBufferLimitBytes,
+		http2Keepalive:                     http2Keepalive,
+		http2Settings:                      http2Settings,
+		externalServingSecrets:             listenerConfig.SecretBackend != nil,
+		httpsRedirect:                      contourConfiguration.HTTPSRedirect,
+	}, nil
+}
+
 func (s *Server) getExtensionSvcConfig(name string, namespace string) (xdscache_v3.ExtensionServiceConfig, error) {
 	extensionSvc := &contour_api_v1alpha1.ExtensionService{}
 	key := client.ObjectKey{
@@ -744,16 +1295,94 @@ func (s *Server) setupTracingService(tracingConfig *contour_api_v1alpha1.Tracing
 		overallSampling = 100.0
 	}
 
+	var zipkin *envoy_v3.ZipkinTracingConfig
+	if tracingConfig.Provider == contour_api_v1alpha1.ZipkinTracingProvider {
+		zipkin = &envoy_v3.ZipkinTracingConfig{
+			CollectorEndpoint: "/api/v2/spans",
+		}
+		if tracingConfig.Zipkin != nil {
+			if tracingConfig.Zipkin.CollectorEndpoint != "" {
+				zipkin.CollectorEndpoint = tracingConfig.Zipkin.CollectorEndpoint
+			}
+			zipkin.ProtoEncoding = tracingConfig.Zipkin.Encoding == contour_api_v1alpha1.ProtoZipkinSpanEncoding
+		}
+	}
+
+	var forceTraceClientCIDRs []string
+	if tracingConfig.ForceTrace != nil {
+		forceTraceClientCIDRs = tracingConfig.ForceTrace.AllowedClientCIDRs
+	}
+
 	return &xdscache_v3.TracingConfig{
 		ServiceName:            ref.Val(tracingConfig.ServiceName, "contour"),
 		ExtensionServiceConfig: extensionSvcConfig,
 		OverallSampling:        overallSampling,
 		MaxPathTagLength:       ref.Val(tracingConfig.MaxPathTagLength, 256),
 		CustomTags:             customTags,
+		Zipkin:                 zipkin,
+		ForceTraceClientCIDRs:  forceTraceClientCIDRs,
 	}, nil
 
 }
 
+func (s *Server) setupGRPCAccessLogService(accessLogGRPC *contour_api_v1alpha1.AccessLogGRPCConfig) (*xdscache_v3.GRPCAccessLogConfig, error) {
+	if accessLogGRPC == nil {
+		return nil, nil
+	}
+
+	// ensure the specified ExtensionService exists
+	extensionSvcConfig, err := s.getExtensionSvcConfig(accessLogGRPC.ExtensionService.Name, accessLogGRPC.ExtensionService.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var bufferFlushInterval time.Duration
+	if accessLogGRPC.BufferFlushInterval != nil {
+		if bufferFlushInterval, err = time.ParseDuration(*accessLogGRPC.BufferFlushInterval); err != nil {
+			return nil, fmt.Errorf("failed to parse access log buffer flush interval: %w", err)
+		}
+	}
+
+	return &xdscache_v3.GRPCAccessLogConfig{
+		ExtensionServiceConfig: extensionSvcConfig,
+		LogName:                ref.Val(accessLogGRPC.LogName, "contour"),
+		BufferSizeBytes:        ref.Val(accessLogGRPC.BufferSizeBytes, 0),
+		BufferFlushInterval:    bufferFlushInterval,
+	}, nil
+}
+
+func (s *Server) setupHTTPProxyMetrics(contourConfiguration contour_api_v1alpha1.ContourConfigurationSpec,
+	clusterCache *xdscache_v3.ClusterCache) (*proxymetrics.Aggregator, error) {
+
+	interval := 30 * time.Second
+	if scrapeInterval := contourConfiguration.HTTPProxyMetrics.ScrapeInterval; scrapeInterval != nil {
+		var err error
+		if interval, err = time.ParseDuration(*scrapeInterval); err != nil {
+			return nil, fmt.Errorf("failed to parse HTTPProxy metrics scrape interval: %w", err)
+		}
+	}
+
+	envoyService := types.NamespacedName{
+		Namespace: contourConfiguration.Envoy.Service.Namespace,
+		Name:      contourConfiguration.Envoy.Service.Name,
+	}
+
+	aggregator := proxymetrics.NewAggregator(s.log.WithField("context", "httpproxy-metrics"), proxymetrics.Config{
+		Targets:         proxymetrics.EndpointsTargets(s.mgr.GetClient(), envoyService, contourConfiguration.Envoy.Metrics.Port),
+		StatName:        clusterCache.StatName,
+		Interval:        interval,
+		BearerTokenFile: contourConfiguration.Envoy.Metrics.BearerTokenFile,
+	})
+	if err := s.registry.Register(aggregator); err != nil {
+		return nil, err
+	}
+	if err := s.mgr.Add(aggregator); err != nil {
+		return nil, err
+	}
+
+	return aggregator, nil
+}
+
 func (s *Server) setupRateLimitService(contourConfiguration contour_api_v1alpha1.ContourConfigurationSpec) (*xdscache_v3.RateLimitConfig, error) {
 	if contourConfiguration.RateLimitService == nil {
 		return nil, nil
@@ -774,6 +1403,60 @@ func (s *Server) setupRateLimitService(contourConfiguration contour_api_v1alpha1
 	}, nil
 }
 
+// setupSecretEncryption returns the sealer that encrypts the private
+// keys of cached Secrets, or nil if secret encryption is not enabled.
+func (s *Server) setupSecretEncryption(contourConfiguration contour_api_v1alpha1.ContourConfigurationSpec) (*sealing.Sealer, error) {
+	encryption := contourConfiguration.SecretEncryption
+	if encryption == nil {
+		return nil, nil
+	}
+
+	var dataKey []byte
+	var err error
+
+	if encryption.DataKeyFile != "" {
+		s.log.WithField("context", "secret-encryption").Infof("encrypting private keys with the data key in %q", encryption.DataKeyFile)
+		dataKey, err = sealing.LoadDataKey(encryption.DataKeyFile, encryption.UnsealCommand)
+	} else {
+		s.log.WithField("context", "secret-encryption").Info("encrypting private keys with a random data key")
+		dataKey, err = sealing.RandomDataKey()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load secret encryption data key: %w", err)
+	}
+
+	return sealing.NewSealer(dataKey)
+}
+
+// setupSecretBackend returns the secret backend that provides the
+// certificates and keys served by Envoy, or nil if they're served
+// from Kubernetes Secrets.
+func (s *Server) setupSecretBackend(contourConfiguration contour_api_v1alpha1.ContourConfigurationSpec) (envoy_v3.SecretBackend, error) {
+	backend := contourConfiguration.SecretBackend
+	if backend == nil {
+		return nil, nil
+	}
+
+	log := s.log.WithField("context", "secret-backend")
+
+	switch backend.Type {
+	case contour_api_v1alpha1.FileSecretBackend:
+		log.Infof("serving certificates and keys from files in %q", backend.Directory)
+		return envoy_v3.FileSecretBackend{Directory: backend.Directory}, nil
+	case contour_api_v1alpha1.SDSSecretBackend:
+		// ensure the specified ExtensionService exists
+		extensionSvcConfig, err := s.getExtensionSvcConfig(backend.ExtensionService.Name, backend.ExtensionService.Namespace)
+		if err != nil {
+			return nil, err
+		}
+
+		log.Infof("serving certificates and keys from SDS server %q", extensionSvcConfig.ExtensionService)
+		return envoy_v3.SDSSecretBackend{ClusterName: dag.ExtensionClusterName(extensionSvcConfig.ExtensionService)}, nil
+	default:
+		return nil, nil
+	}
+}
+
 func (s *Server) setupGlobalExternalAuthentication(contourConfiguration contour_api_v1alpha1.ContourConfigurationSpec) (*xdscache_v3.GlobalExternalAuthConfig, error) {
 	if contourConfiguration.GlobalExternalAuthorization == nil {
 		return nil, nil
@@ -806,14 +1489,16 @@ func (s *Server) setupGlobalExternalAuthentication(contourConfiguration contour_
 	return globalExternalAuthConfig, nil
 }
 
-func (s *Server) setupDebugService(debugConfig contour_api_v1alpha1.DebugConfig, builder *dag.Builder) error {
+func (s *Server) setupDebugService(debugConfig contour_api_v1alpha1.DebugConfig, builder *dag.Builder, readiness debug.ReadinessWaiter) error {
 	debugsvc := &debug.Service{
 		Service: httpsvc.Service{
 			Addr:        debugConfig.Address,
 			Port:        debugConfig.Port,
 			FieldLogger: s.log.WithField("context", "debugsvc"),
 		},
-		Builder: builder,
+		Builder:      builder,
+		FeatureGates: s.featureGates,
+		Readiness:    readiness,
 	}
 	return s.mgr.Add(debugsvc)
 }
@@ -825,6 +1510,8 @@ type xdsServer struct {
 	config          contour_api_v1alpha1.XDSServerConfig
 	snapshotHandler *xdscache.SnapshotHandler
 	resources       []xdscache.ResourceCache
+	acks            *xds.ACKTracker
+	featureGates    *featuregate.Gates
 }
 
 func (x *xdsServer) NeedLeaderElection() bool {
@@ -840,15 +1527,20 @@ func (x *xdsServer) Start(ctx context.Context) error {
 	}
 	log.Printf("informer caches synced")
 
-	grpcServer := xds.NewServer(x.registry, grpcOptions(log, x.config.TLS)...)
+	opts := append(grpcOptions(log, x.config.TLS), authorizationOptions(log, x.config.Authorization)...)
+	grpcServer := xds.NewServer(x.registry, opts...)
 
 	switch x.config.Type {
 	case contour_api_v1alpha1.EnvoyServerType:
 		v3cache := contour_xds_v3.NewSnapshotCache(false, log)
 		x.snapshotHandler.AddSnapshotter(v3cache)
-		contour_xds_v3.RegisterServer(envoy_server_v3.NewServer(ctx, v3cache, contour_xds_v3.NewRequestLoggingCallbacks(log)), grpcServer)
+		var srv contour_xds_v3.Server = envoy_server_v3.NewServer(ctx, v3cache, contour_xds_v3.NewACKTrackingCallbacks(log, x.acks))
+		if !x.featureGates.Enabled(featuregate.DeltaXDS) {
+			srv = contour_xds_v3.WithoutDelta(srv)
+		}
+		contour_xds_v3.RegisterServer(srv, grpcServer)
 	case contour_api_v1alpha1.ContourServerType:
-		contour_xds_v3.RegisterServer(contour_xds_v3.NewContourServer(log, xdscache.ResourcesOf(x.resources)...), grpcServer)
+		contour_xds_v3.RegisterServer(contour_xds_v3.NewContourServer(log, x.acks, xdscache.ResourcesOf(x.resources)...), grpcServer)
 	default:
 		// This can't happen due to config validation.
 		log.Fatalf("invalid xDS server type %q", x.config.Type)
@@ -864,6 +1556,9 @@ func (x *xdsServer) Start(ctx context.Context) error {
 	if *x.config.TLS.Insecure {
 		log = log.WithField("insecure", true)
 	}
+	if x.config.Authorization != nil {
+		log = log.WithField("authorization", true)
+	}
 
 	log.Infof("started xDS server type: %q", x.config.Type)
 	defer log.Info("stopped xDS server")
@@ -893,12 +1588,17 @@ func (s *Server) setupMetrics(metricsConfig contour_api_v1alpha1.MetricsConfig,
 		ServeMux:    http.ServeMux{},
 	}
 
-	metricsvc.ServeMux.Handle("/metrics", metrics.Handler(registry))
+	metricsHandler := metrics.Handler(registry)
+	if metricsConfig.BearerTokenFile != "" {
+		metricsHandler = httpsvc.RequireBearerToken(metricsConfig.BearerTokenFile, metricsHandler)
+	}
+	metricsvc.ServeMux.Handle("/metrics", metricsHandler)
 
 	if metricsConfig.TLS != nil {
 		metricsvc.Cert = metricsConfig.TLS.CertFile
 		metricsvc.Key = metricsConfig.TLS.KeyFile
 		metricsvc.CABundle = metricsConfig.TLS.CAFile
+		metricsvc.AllowedSANs = metricsConfig.TLS.AllowedSubjectAltNames
 	}
 
 	if healthConfig.Address == metricsConfig.Address && healthConfig.Port == metricsConfig.Port {
@@ -991,6 +1691,11 @@ func (s *Server) setupGatewayAPI(contourConfiguration contour_api_v1alpha1.Conto
 		for _, f := range s.ctx.disabledFeatures {
 			delete(features, f)
 		}
+		if !s.featureGates.Enabled(featuregate.GatewayAPIExperimentalRoutes) {
+			delete(features, "tlsroutes")
+			delete(features, "grpcroutes")
+			delete(features, "tcproutes")
+		}
 
 		// Create and register the HTTPRoute controller with the manager.
 		if err := controller.RegisterHTTPRouteController(s.log.WithField("context", "httproute-controller"), mgr, eventHandler); err != nil {
@@ -1022,11 +1727,6 @@ func (s *Server) setupGatewayAPI(contourConfiguration contour_api_v1alpha1.Conto
 		if err := informOnResource(&gatewayapi_v1beta1.ReferenceGrant{}, eventHandler, mgr.GetCache()); err != nil {
 			s.log.WithError(err).WithField("resource", "referencegrants").Fatal("failed to create informer")
 		}
-
-		// Inform on Namespaces.
-		if err := informOnResource(&corev1.Namespace{}, eventHandler, mgr.GetCache()); err != nil {
-			s.log.WithError(err).WithField("resource", "namespaces").Fatal("failed to create informer")
-		}
 	}
 	return needLeadershipNotification
 }
@@ -1037,6 +1737,7 @@ type dagBuilderConfig struct {
 	gatewayControllerName              string
 	gatewayRef                         *types.NamespacedName
 	disablePermitInsecure              bool
+	insecureVirtualHosts               contour_api_v1alpha1.InsecureVirtualHostPolicy
 	enableExternalNameService          bool
 	dnsLookupFamily                    contour_api_v1alpha1.ClusterDNSFamilyType
 	headersPolicy                      *contour_api_v1alpha1.PolicyConfig
@@ -1052,11 +1753,15 @@ type dagBuilderConfig struct {
 	globalExternalAuthorizationService *contour_api_v1.AuthorizationServer
 	maxRequestsPerConnection           *uint32
 	perConnectionBufferLimitBytes      *uint32
+	http2Keepalive                     *dag.HTTP2KeepaliveConfig
+	http2Settings                      *dag.HTTP2Settings
 	globalRateLimitService             *contour_api_v1alpha1.RateLimitServiceConfig
+	externalServingSecrets             bool
+	httpsRedirect                      *contour_api_v1alpha1.HTTPSRedirectConfig
 }
 
-func (s *Server) getDAGBuilder(dbc dagBuilderConfig) *dag.Builder {
-
+// getDAGProcessors returns the processors of the DAG builder.
+func (s *Server) getDAGProcessors(dbc dagBuilderConfig) []dag.Processor {
 	var (
 		requestHeadersPolicy       dag.HeadersPolicy
 		responseHeadersPolicy      dag.HeadersPolicy
@@ -1101,6 +1806,15 @@ func (s *Server) getDAGBuilder(dbc dagBuilderConfig) *dag.Builder {
 		responseHeadersPolicyIngress = responseHeadersPolicy
 	}
 
+	var httpsRedirectPolicy *dag.HTTPSRedirectPolicy
+	if dbc.httpsRedirect != nil {
+		httpsRedirectPolicy = &dag.HTTPSRedirectPolicy{
+			StatusCode:           dbc.httpsRedirect.StatusCode,
+			StripQuery:           ref.Val(dbc.httpsRedirect.StripQuery, false),
+			ExcludedPathPrefixes: dbc.httpsRedirect.ExcludedPathPrefixes,
+		}
+	}
+
 	s.log.Debugf("EnableExternalNameService is set to %t", dbc.enableExternalNameService)
 
 	// Get the appropriate DAG processors.
@@ -1122,6 +1836,9 @@ func (s *Server) getDAGBuilder(dbc dagBuilderConfig) *dag.Builder {
 			ConnectTimeout:                dbc.connectTimeout,
 			MaxRequestsPerConnection:      dbc.maxRequestsPerConnection,
 			PerConnectionBufferLimitBytes: dbc.perConnectionBufferLimitBytes,
+			HTTP2Keepalive:                dbc.http2Keepalive,
+			HTTP2Settings:                 dbc.http2Settings,
+			HTTPSRedirectPolicy:           httpsRedirectPolicy,
 		},
 		&dag.ExtensionServiceProcessor{
 			// Note that ExtensionService does not support ExternalName, if it does get added,
@@ -1129,10 +1846,13 @@ func (s *Server) getDAGBuilder(dbc dagBuilderConfig) *dag.Builder {
 			FieldLogger:       s.log.WithField("context", "ExtensionServiceProcessor"),
 			ClientCertificate: dbc.clientCert,
 			ConnectTimeout:    dbc.connectTimeout,
+			HTTP2Keepalive:    dbc.http2Keepalive,
+			HTTP2Settings:     dbc.http2Settings,
 		},
 		&dag.HTTPProxyProcessor{
 			EnableExternalNameService:     dbc.enableExternalNameService,
 			DisablePermitInsecure:         dbc.disablePermitInsecure,
+			InsecureVirtualHosts:          dbc.insecureVirtualHosts,
 			FallbackCertificate:           dbc.fallbackCert,
 			DNSLookupFamily:               dbc.dnsLookupFamily,
 			ClientCertificate:             dbc.clientCert,
@@ -1143,6 +1863,9 @@ func (s *Server) getDAGBuilder(dbc dagBuilderConfig) *dag.Builder {
 			MaxRequestsPerConnection:      dbc.maxRequestsPerConnection,
 			GlobalRateLimitService:        dbc.globalRateLimitService,
 			PerConnectionBufferLimitBytes: dbc.perConnectionBufferLimitBytes,
+			HTTP2Keepalive:                dbc.http2Keepalive,
+			HTTP2Settings:                 dbc.http2Settings,
+			HTTPSRedirectPolicy:           httpsRedirectPolicy,
 		},
 	}
 
@@ -1153,9 +1876,15 @@ func (s *Server) getDAGBuilder(dbc dagBuilderConfig) *dag.Builder {
 			ConnectTimeout:                dbc.connectTimeout,
 			MaxRequestsPerConnection:      dbc.maxRequestsPerConnection,
 			PerConnectionBufferLimitBytes: dbc.perConnectionBufferLimitBytes,
+			HTTP2Keepalive:                dbc.http2Keepalive,
+			HTTP2Settings:                 dbc.http2Settings,
 		})
 	}
 
+	return dagProcessors
+}
+
+func (s *Server) getDAGBuilder(dbc dagBuilderConfig) *dag.Builder {
 	var configuredSecretRefs []*types.NamespacedName
 	if dbc.fallbackCert != nil {
 		configuredSecretRefs = append(configuredSecretRefs, dbc.fallbackCert)
@@ -1170,11 +1899,12 @@ func (s *Server) getDAGBuilder(dbc dagBuilderConfig) *dag.Builder {
 			IngressClassNames:        dbc.ingressClassNames,
 			ConfiguredGatewayToCache: dbc.gatewayRef,
 			ConfiguredSecretRefs:     configuredSecretRefs,
+			ExternalServingSecrets:   dbc.externalServingSecrets,
 			FieldLogger:              s.log.WithField("context", "KubernetesCache"),
 			Client:                   dbc.client,
 			Metrics:                  dbc.metrics,
 		},
-		Processors: dagProcessors,
+		Processors: s.getDAGProcessors(dbc),
 		Metrics:    dbc.metrics,
 	}
 
@@ -1184,6 +1914,17 @@ func (s *Server) getDAGBuilder(dbc dagBuilderConfig) *dag.Builder {
 	return builder
 }
 
+// resourceInstalled returns true if the API server serves the kind of obj.
+func (s *Server) resourceInstalled(obj client.Object) bool {
+	gvk, err := apiutil.GVKForObject(obj, s.mgr.GetScheme())
+	if err != nil {
+		return false
+	}
+
+	_, err = s.mgr.GetRESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+	return err == nil
+}
+
 func informOnResource(obj client.Object, handler cache.ResourceEventHandler, cache ctrl_cache.Cache) error {
 	inf, err := cache.GetInformer(context.Background(), obj)
 	if err != nil {
@@ -15,6 +15,7 @@ package main
 
 import (
 	"testing"
+	"time"
 
 	contour_api_v1alpha1 "github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
 	"github.com/projectcontour/contour/internal/dag"
@@ -23,6 +24,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
 
 func TestGetDAGBuilder(t *testing.T) {
@@ -185,9 +187,86 @@ func TestGetDAGBuilder(t *testing.T) {
 		assert.EqualValues(t, ingressClassNames, got.Source.IngressClassNames)
 	})
 
+	t.Run("https redirect policy specified", func(t *testing.T) {
+		serve := &Server{
+			log: logrus.StandardLogger(),
+		}
+		got := serve.getDAGBuilder(dagBuilderConfig{
+			rootNamespaces:  []string{},
+			dnsLookupFamily: contour_api_v1alpha1.AutoClusterDNSFamily,
+			httpsRedirect: &contour_api_v1alpha1.HTTPSRedirectConfig{
+				StatusCode:           308,
+				StripQuery:           ref.To(true),
+				ExcludedPathPrefixes: []string{"/.well-known/"},
+			},
+		})
+		commonAssertions(t, got)
+
+		want := &dag.HTTPSRedirectPolicy{
+			StatusCode:           308,
+			StripQuery:           true,
+			ExcludedPathPrefixes: []string{"/.well-known/"},
+		}
+		assert.Equal(t, want, mustGetHTTPProxyProcessor(t, got).HTTPSRedirectPolicy)
+		assert.Equal(t, want, mustGetIngressProcessor(t, got).HTTPSRedirectPolicy)
+	})
+
 	// TODO(3453): test additional properties of the DAG builder (processor fields, cache fields, Gateway tests (requires a client fake))
 }
 
+func TestSetLeaderElectionOptions(t *testing.T) {
+	log := logrus.StandardLogger()
+
+	t.Run("defaults", func(t *testing.T) {
+		t.Setenv("CONTOUR_NAMESPACE", "contour-system")
+
+		var options manager.Options
+		require.NoError(t, setLeaderElectionOptions(log, &options, nil))
+
+		assert.True(t, options.LeaderElection)
+		assert.Equal(t, "leases", options.LeaderElectionResourceLock)
+		assert.Equal(t, "leader-elect", options.LeaderElectionID)
+		assert.Equal(t, "contour-system", options.LeaderElectionNamespace)
+		assert.Equal(t, 15*time.Second, *options.LeaseDuration)
+		assert.Equal(t, 10*time.Second, *options.RenewDeadline)
+		assert.Equal(t, 2*time.Second, *options.RetryPeriod)
+		assert.True(t, options.LeaderElectionReleaseOnCancel)
+	})
+
+	t.Run("user specified", func(t *testing.T) {
+		var options manager.Options
+		require.NoError(t, setLeaderElectionOptions(log, &options, &contour_api_v1alpha1.LeaderElectionConfig{
+			LeaseName:      "contour",
+			LeaseNamespace: "leases",
+			ResourceLock:   contour_api_v1alpha1.LeasesResourceLock,
+			LeaseDuration:  ref.To("1m"),
+		}))
+
+		assert.True(t, options.LeaderElection)
+		assert.Equal(t, "leases", options.LeaderElectionResourceLock)
+		assert.Equal(t, "contour", options.LeaderElectionID)
+		assert.Equal(t, "leases", options.LeaderElectionNamespace)
+		assert.Equal(t, time.Minute, *options.LeaseDuration)
+		assert.Equal(t, 10*time.Second, *options.RenewDeadline)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		var options manager.Options
+		require.NoError(t, setLeaderElectionOptions(log, &options, &contour_api_v1alpha1.LeaderElectionConfig{
+			Disable: ref.To(true),
+		}))
+
+		assert.False(t, options.LeaderElection)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		var options manager.Options
+		require.Error(t, setLeaderElectionOptions(log, &options, &contour_api_v1alpha1.LeaderElectionConfig{
+			RenewDeadline: ref.To("1m"),
+		}))
+	})
+}
+
 func mustGetHTTPProxyProcessor(t *testing.T, builder *dag.Builder) *dag.HTTPProxyProcessor {
 	t.Helper()
 	for i := range builder.Processors {
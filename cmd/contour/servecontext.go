@@ -20,14 +20,18 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
 	contour_api_v1alpha1 "github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
+	"github.com/projectcontour/contour/internal/dag"
 	envoy_v3 "github.com/projectcontour/contour/internal/envoy/v3"
 	"github.com/projectcontour/contour/internal/k8s"
 	"github.com/projectcontour/contour/internal/ref"
+	"github.com/projectcontour/contour/internal/xds"
 	xdscache_v3 "github.com/projectcontour/contour/internal/xdscache/v3"
 	"github.com/projectcontour/contour/pkg/config"
 	"github.com/sirupsen/logrus"
@@ -40,6 +44,14 @@ type serveContext struct {
 	// Name of the ContourConfiguration CRD to use for configuration.
 	contourConfigurationName string
 
+	// Paths of the configuration files, or directories of them,
+	// that Config was layered from.
+	configPaths []string
+
+	// Deprecated parameters of the configuration file that
+	// were converted when it was parsed.
+	configConversions []config.Conversion
+
 	Config config.Parameters
 
 	ServerConfig
@@ -88,9 +100,6 @@ type serveContext struct {
 	// PermitInsecureGRPC disables TLS on Contour's gRPC listener.
 	PermitInsecureGRPC bool
 
-	// Leader election configuration.
-	LeaderElection LeaderElection
-
 	// Features disabled by the user.
 	disabledFeatures []string
 }
@@ -102,15 +111,6 @@ type ServerConfig struct {
 	caFile, contourCert, contourKey string
 }
 
-type LeaderElection struct {
-	Disable       bool
-	LeaseDuration time.Duration
-	RenewDeadline time.Duration
-	RetryPeriod   time.Duration
-	Namespace     string
-	Name          string
-}
-
 // newServeContext returns a serveContext initialized to defaults.
 func newServeContext() *serveContext {
 	// Set defaults for parameters which are then overridden via flags, ENV, or ConfigFile
@@ -174,6 +174,29 @@ func grpcOptions(log logrus.FieldLogger, contourXDSConfig *contour_api_v1alpha1.
 	return opts
 }
 
+// authorizationOptions returns the grpc.ServerOptions that authorize the
+// Envoy nodes connecting to the xDS server, if authorization is configured.
+func authorizationOptions(log logrus.FieldLogger, authz *contour_api_v1alpha1.XDSServerAuthorization) []grpc.ServerOption {
+	if authz == nil {
+		return nil
+	}
+
+	authorizer := &xds.NodeAuthorizer{
+		AllowedSANs: authz.AllowedSubjectAltNames,
+		Log:         log,
+	}
+	if authz.JWT != nil {
+		authorizer.JWTKeyFile = authz.JWT.KeyFile
+		authorizer.JWTIssuer = authz.JWT.Issuer
+		authorizer.JWTAudiences = authz.JWT.Audiences
+	}
+
+	return []grpc.ServerOption{
+		grpc.ChainStreamInterceptor(authorizer.StreamServerInterceptor()),
+		grpc.ChainUnaryInterceptor(authorizer.UnaryServerInterceptor()),
+	}
+}
+
 // tlsconfig returns a new *tls.Config. If the TLS parameters passed are not properly configured
 // for tls communication, tlsconfig returns nil.
 func tlsconfig(log logrus.FieldLogger, contourXDSTLS *contour_api_v1alpha1.TLS) *tls.Config {
@@ -285,6 +308,20 @@ func parseDefaultHTTPVersions(versions []contour_api_v1alpha1.HTTPVersionType) [
 	return parsed
 }
 
+// parseTimeoutReply returns the DAG timeout reply
+// for the given reply, or nil if it is nil.
+func parseTimeoutReply(reply *contour_api_v1.TimeoutReply) *dag.TimeoutReply {
+	if reply == nil {
+		return nil
+	}
+
+	return &dag.TimeoutReply{
+		StatusCode:  uint32(reply.StatusCode),
+		Body:        reply.Body,
+		ContentType: reply.ContentType,
+	}
+}
+
 func (ctx *serveContext) convertToContourConfigurationSpec() contour_api_v1alpha1.ContourConfigurationSpec {
 	ingress := &contour_api_v1alpha1.IngressConfig{}
 	if len(ctx.ingressClassName) > 0 {
@@ -336,6 +373,26 @@ func (ctx *serveContext) convertToContourConfigurationSpec() contour_api_v1alpha
 		accessLogLevel = contour_api_v1alpha1.LogLevelDisabled
 	}
 
+	var accessLogGRPC *contour_api_v1alpha1.AccessLogGRPCConfig
+	if als := ctx.Config.AccessLogGRPC; als != nil {
+		nsedName := k8s.NamespacedNameFrom(als.ExtensionService)
+		accessLogGRPC = &contour_api_v1alpha1.AccessLogGRPCConfig{
+			ExtensionService: &contour_api_v1alpha1.NamespacedName{
+				Name:      nsedName.Name,
+				Namespace: nsedName.Namespace,
+			},
+		}
+		if als.LogName != "" {
+			accessLogGRPC.LogName = ref.To(als.LogName)
+		}
+		if als.BufferSizeBytes > 0 {
+			accessLogGRPC.BufferSizeBytes = ref.To(als.BufferSizeBytes)
+		}
+		if als.BufferFlushInterval != "" {
+			accessLogGRPC.BufferFlushInterval = ref.To(als.BufferFlushInterval)
+		}
+	}
+
 	var defaultHTTPVersions []contour_api_v1alpha1.HTTPVersionType
 	for _, version := range ctx.Config.DefaultHTTPVersions {
 		switch version {
@@ -368,6 +425,143 @@ func (ctx *serveContext) convertToContourConfigurationSpec() contour_api_v1alpha
 	if len(ctx.Config.Timeouts.ConnectTimeout) > 0 {
 		timeoutParams.ConnectTimeout = ref.To(ctx.Config.Timeouts.ConnectTimeout)
 	}
+	if reply := ctx.Config.Timeouts.ResponseTimeoutReply; reply != nil {
+		timeoutParams.ResponseTimeoutReply = &contour_api_v1.TimeoutReply{
+			StatusCode:  reply.StatusCode,
+			Body:        reply.Body,
+			ContentType: reply.ContentType,
+		}
+	}
+	if reply := ctx.Config.Timeouts.IdleTimeoutReply; reply != nil {
+		timeoutParams.IdleTimeoutReply = &contour_api_v1.TimeoutReply{
+			StatusCode:  reply.StatusCode,
+			Body:        reply.Body,
+			ContentType: reply.ContentType,
+		}
+	}
+
+	var endpointDeregistrationDelay *string
+	if len(ctx.Config.Cluster.EndpointDeregistrationDelay) > 0 {
+		endpointDeregistrationDelay = ref.To(ctx.Config.Cluster.EndpointDeregistrationDelay)
+	}
+
+	var dnsRefreshRate *string
+	if len(ctx.Config.Cluster.DNSRefreshRate) > 0 {
+		dnsRefreshRate = ref.To(ctx.Config.Cluster.DNSRefreshRate)
+	}
+
+	var http2Keepalive *contour_api_v1alpha1.HTTP2KeepaliveConfig
+	if keepalive := ctx.Config.Cluster.HTTP2Keepalive; keepalive != (config.HTTP2KeepaliveParameters{}) {
+		http2Keepalive = &contour_api_v1alpha1.HTTP2KeepaliveConfig{
+			Interval: keepalive.Interval,
+			Timeout:  keepalive.Timeout,
+		}
+	}
+
+	var clusterHTTP2 *contour_api_v1alpha1.UpstreamHTTP2
+	if http2 := ctx.Config.Cluster.HTTP2; http2 != (config.UpstreamHTTP2Parameters{}) {
+		clusterHTTP2 = &contour_api_v1alpha1.UpstreamHTTP2{
+			MaxConcurrentStreams:        http2.MaxConcurrentStreams,
+			InitialStreamWindowSize:     http2.InitialStreamWindowSize,
+			InitialConnectionWindowSize: http2.InitialConnectionWindowSize,
+		}
+	}
+
+	var circuitBreakers *contour_api_v1alpha1.CircuitBreakers
+	if cb := ctx.Config.Cluster.CircuitBreakers; cb != (config.CircuitBreakerParameters{}) {
+		circuitBreakers = &contour_api_v1alpha1.CircuitBreakers{
+			MaxConnections:     cb.MaxConnections,
+			MaxPendingRequests: cb.MaxPendingRequests,
+			MaxRequests:        cb.MaxRequests,
+			MaxRetries:         cb.MaxRetries,
+
+			RetryBudgetPercent:             cb.RetryBudgetPercent,
+			RetryBudgetMinRetryConcurrency: cb.RetryBudgetMinRetryConcurrency,
+		}
+	}
+
+	var listenerRemovalDelay *string
+	if len(ctx.Config.Listener.RemovalDelay) > 0 {
+		listenerRemovalDelay = ref.To(ctx.Config.Listener.RemovalDelay)
+	}
+
+	var listenerHTTP2 *contour_api_v1alpha1.EnvoyHTTP2
+	if http2 := ctx.Config.Listener.HTTP2; http2 != (config.HTTP2Parameters{}) {
+		listenerHTTP2 = &contour_api_v1alpha1.EnvoyHTTP2{
+			MaxConcurrentStreams:                         http2.MaxConcurrentStreams,
+			InitialStreamWindowSize:                      http2.InitialStreamWindowSize,
+			InitialConnectionWindowSize:                  http2.InitialConnectionWindowSize,
+			MaxOutboundFrames:                            http2.MaxOutboundFrames,
+			MaxOutboundControlFrames:                     http2.MaxOutboundControlFrames,
+			MaxConsecutiveInboundFramesWithEmptyPayload:  http2.MaxConsecutiveInboundFramesWithEmptyPayload,
+			MaxInboundPriorityFramesPerStream:            http2.MaxInboundPriorityFramesPerStream,
+			MaxInboundWindowUpdateFramesPerDataFrameSent: http2.MaxInboundWindowUpdateFramesPerDataFrameSent,
+		}
+	}
+
+	var listenerSocketOptions *contour_api_v1alpha1.EnvoySocketOptions
+	if opts := ctx.Config.Listener.SocketOptions; opts != (config.SocketOptions{}) {
+		listenerSocketOptions = &contour_api_v1alpha1.EnvoySocketOptions{
+			TOS:          opts.TOS,
+			TrafficClass: opts.TrafficClass,
+			Freebind:     ref.To(opts.Freebind),
+		}
+
+		if keepalive := opts.TCPKeepalive; keepalive != (config.TCPKeepaliveParameters{}) {
+			listenerSocketOptions.TCPKeepalive = &contour_api_v1alpha1.TCPKeepaliveConfig{
+				Probes: keepalive.Probes,
+			}
+			if len(keepalive.Idle) > 0 {
+				listenerSocketOptions.TCPKeepalive.Idle = ref.To(keepalive.Idle)
+			}
+			if len(keepalive.Interval) > 0 {
+				listenerSocketOptions.TCPKeepalive.Interval = ref.To(keepalive.Interval)
+			}
+		}
+	}
+
+	var listenerHTTPConnectionManager *contour_api_v1alpha1.EnvoyHTTPConnectionManager
+	if hcm := ctx.Config.Listener.HTTPConnectionManager; hcm.ServerName != "" || hcm.PreserveExternalRequestID != nil || hcm.NormalizePath != nil || hcm.Via != "" {
+		listenerHTTPConnectionManager = &contour_api_v1alpha1.EnvoyHTTPConnectionManager{
+			PreserveExternalRequestID: hcm.PreserveExternalRequestID,
+			NormalizePath:             hcm.NormalizePath,
+		}
+		if hcm.ServerName != "" {
+			listenerHTTPConnectionManager.ServerName = ref.To(hcm.ServerName)
+		}
+		if hcm.Via != "" {
+			listenerHTTPConnectionManager.Via = ref.To(hcm.Via)
+		}
+	}
+
+	var listenerHTTPFilters []contour_api_v1alpha1.HTTPFilter
+	for _, f := range ctx.Config.Listener.HTTPFilters {
+		listenerHTTPFilters = append(listenerHTTPFilters, contour_api_v1alpha1.HTTPFilter{
+			Name:        f.Name,
+			Position:    contour_api_v1alpha1.HTTPFilterPosition(f.Position),
+			TypedConfig: f.TypedConfig,
+		})
+	}
+
+	var compression *contour_api_v1alpha1.EnvoyCompression
+	if c := ctx.Config.Compression; c.Algorithm != "" || c.MinContentLength != nil || len(c.ContentTypes) > 0 {
+		compression = &contour_api_v1alpha1.EnvoyCompression{
+			Algorithm:        contour_api_v1alpha1.CompressionAlgorithm(c.Algorithm),
+			MinContentLength: c.MinContentLength,
+			ContentTypes:     c.ContentTypes,
+		}
+	}
+
+	var responseMap []contour_api_v1alpha1.ResponseMapping
+	for _, m := range ctx.Config.ResponseMap {
+		responseMap = append(responseMap, contour_api_v1alpha1.ResponseMapping{
+			StatusCode:        m.StatusCode,
+			RewriteStatusCode: m.RewriteStatusCode,
+			Body:              m.Body,
+			ContentType:       m.ContentType,
+			JSONFormat:        m.JSONFormat,
+		})
+	}
 
 	var dnsLookupFamily contour_api_v1alpha1.ClusterDNSFamilyType
 	switch ctx.Config.Cluster.DNSLookupFamily {
@@ -402,6 +596,59 @@ func (ctx *serveContext) convertToContourConfigurationSpec() contour_api_v1alpha
 				Name:      namespacedName.Name,
 				Namespace: namespacedName.Namespace,
 			},
+			Provider: contour_api_v1alpha1.TracingProvider(ctx.Config.Tracing.Provider),
+		}
+		if zipkin := ctx.Config.Tracing.Zipkin; zipkin != nil {
+			tracingConfig.Zipkin = &contour_api_v1alpha1.ZipkinTracingConfig{
+				CollectorEndpoint: zipkin.CollectorEndpoint,
+				Encoding:          contour_api_v1alpha1.ZipkinSpanEncoding(zipkin.Encoding),
+			}
+		}
+		if forceTrace := ctx.Config.Tracing.ForceTrace; forceTrace != nil {
+			tracingConfig.ForceTrace = &contour_api_v1alpha1.ForceTraceConfig{
+				AllowedClientCIDRs: forceTrace.AllowedClientCIDRs,
+			}
+		}
+	}
+
+	var secretBackend *contour_api_v1alpha1.SecretBackendConfig
+	if ctx.Config.SecretBackend != nil {
+		secretBackend = &contour_api_v1alpha1.SecretBackendConfig{
+			Type:      contour_api_v1alpha1.SecretBackendType(ctx.Config.SecretBackend.Type),
+			Directory: ctx.Config.SecretBackend.Directory,
+		}
+		if ctx.Config.SecretBackend.ExtensionService != "" {
+			nsedName := k8s.NamespacedNameFrom(ctx.Config.SecretBackend.ExtensionService)
+			secretBackend.ExtensionService = &contour_api_v1alpha1.NamespacedName{
+				Name:      nsedName.Name,
+				Namespace: nsedName.Namespace,
+			}
+		}
+	}
+
+	var secretEncryption *contour_api_v1alpha1.SecretEncryptionConfig
+	if ctx.Config.SecretEncryption != nil {
+		secretEncryption = &contour_api_v1alpha1.SecretEncryptionConfig{
+			DataKeyFile:   ctx.Config.SecretEncryption.DataKeyFile,
+			UnsealCommand: ctx.Config.SecretEncryption.UnsealCommand,
+		}
+	}
+
+	var httpsRedirect *contour_api_v1alpha1.HTTPSRedirectConfig
+	if ctx.Config.HTTPSRedirect != nil {
+		httpsRedirect = &contour_api_v1alpha1.HTTPSRedirectConfig{
+			StatusCode:           ctx.Config.HTTPSRedirect.StatusCode,
+			StripQuery:           &ctx.Config.HTTPSRedirect.StripQuery,
+			ExcludedPathPrefixes: ctx.Config.HTTPSRedirect.ExcludedPathPrefixes,
+		}
+	}
+
+	var captureConfig *contour_api_v1alpha1.CaptureConfig
+	if ctx.Config.Capture != nil {
+		captureConfig = &contour_api_v1alpha1.CaptureConfig{
+			FilePathPrefix:   ctx.Config.Capture.FilePathPrefix,
+			SamplePercent:    ctx.Config.Capture.SamplePercent,
+			MaxBufferedBytes: ctx.Config.Capture.MaxBufferedBytes,
 		}
 	}
 
@@ -422,14 +669,23 @@ func (ctx *serveContext) convertToContourConfigurationSpec() contour_api_v1alpha
 		}
 	}
 
+	// The Server header transformation of the HTTP connection
+	// manager settings takes precedence over the top-level one.
+	serverHeaderTransformationConfig := ctx.Config.ServerHeaderTransformation
+	if t := ctx.Config.Listener.HTTPConnectionManager.ServerHeaderTransformation; t != "" {
+		serverHeaderTransformationConfig = t
+	}
+
 	var serverHeaderTransformation contour_api_v1alpha1.ServerHeaderTransformationType
-	switch ctx.Config.ServerHeaderTransformation {
+	switch serverHeaderTransformationConfig {
 	case config.OverwriteServerHeader:
 		serverHeaderTransformation = contour_api_v1alpha1.OverwriteServerHeader
 	case config.AppendIfAbsentServerHeader:
 		serverHeaderTransformation = contour_api_v1alpha1.AppendIfAbsentServerHeader
 	case config.PassThroughServerHeader:
 		serverHeaderTransformation = contour_api_v1alpha1.PassThroughServerHeader
+	case config.RemoveServerHeader:
+		serverHeaderTransformation = contour_api_v1alpha1.RemoveServerHeader
 	}
 
 	var globalExtAuth *contour_api_v1.AuthorizationServer
@@ -508,6 +764,34 @@ func (ctx *serveContext) convertToContourConfigurationSpec() contour_api_v1alpha
 	setMetricsFromConfig(ctx.Config.Metrics.Contour, &contourMetrics)
 	setMetricsFromConfig(ctx.Config.Metrics.Envoy, &envoyMetrics)
 
+	var httpProxyMetrics *contour_api_v1alpha1.HTTPProxyMetricsConfig
+	if ctx.Config.Metrics.HTTPProxy != nil {
+		httpProxyMetrics = &contour_api_v1alpha1.HTTPProxyMetricsConfig{}
+		if ctx.Config.Metrics.HTTPProxy.ScrapeInterval != "" {
+			httpProxyMetrics.ScrapeInterval = &ctx.Config.Metrics.HTTPProxy.ScrapeInterval
+		}
+	}
+
+	httpAccessLog, httpsAccessLog := ctx.httpAccessLog, ctx.httpsAccessLog
+	if ctx.Config.AccessLogPath != "" {
+		httpAccessLog = ctx.Config.AccessLogPath
+		httpsAccessLog = ctx.Config.AccessLogPath
+	}
+
+	envoyHealth := contour_api_v1alpha1.HealthConfig{
+		Address: ctx.statsAddr,
+		Port:    ctx.statsPort,
+	}
+
+	// As with metrics, the health listener configuration from
+	// the config file takes precedence over command line.
+	if len(ctx.Config.Health.Envoy.Address) > 0 {
+		envoyHealth.Address = ctx.Config.Health.Envoy.Address
+	}
+	if ctx.Config.Health.Envoy.Port > 0 {
+		envoyHealth.Port = ctx.Config.Health.Envoy.Port
+	}
+
 	// Convert serveContext to a ContourConfiguration
 	contourConfiguration := contour_api_v1alpha1.ContourConfigurationSpec{
 		Ingress: ingress,
@@ -526,8 +810,14 @@ func (ctx *serveContext) convertToContourConfigurationSpec() contour_api_v1alpha
 				DisableMergeSlashes:           &ctx.Config.DisableMergeSlashes,
 				ServerHeaderTransformation:    serverHeaderTransformation,
 				ConnectionBalancer:            ctx.Config.Listener.ConnectionBalancer,
+				DrainType:                     contour_api_v1alpha1.ListenerDrainType(ctx.Config.Listener.DrainType),
+				RemovalDelay:                  listenerRemovalDelay,
 				PerConnectionBufferLimitBytes: ctx.Config.Listener.PerConnectionBufferLimitBytes,
 				MaxRequestsPerConnection:      ctx.Config.Listener.MaxRequestsPerConnection,
+				HTTP2:                         listenerHTTP2,
+				SocketOptions:                 listenerSocketOptions,
+				HTTPFilters:                   listenerHTTPFilters,
+				HTTPConnectionManager:         listenerHTTPConnectionManager,
 				TLS: &contour_api_v1alpha1.EnvoyTLS{
 					MinimumProtocolVersion: ctx.Config.TLS.MinimumProtocolVersion,
 					CipherSuites:           cipherSuites,
@@ -540,29 +830,38 @@ func (ctx *serveContext) convertToContourConfigurationSpec() contour_api_v1alpha
 			HTTPListener: &contour_api_v1alpha1.EnvoyListener{
 				Address:   ctx.httpAddr,
 				Port:      ctx.httpPort,
-				AccessLog: ctx.httpAccessLog,
+				AccessLog: httpAccessLog,
 			},
 			HTTPSListener: &contour_api_v1alpha1.EnvoyListener{
 				Address:   ctx.httpsAddr,
 				Port:      ctx.httpsPort,
-				AccessLog: ctx.httpsAccessLog,
-			},
-			Metrics: &envoyMetrics,
-			Health: &contour_api_v1alpha1.HealthConfig{
-				Address: ctx.statsAddr,
-				Port:    ctx.statsPort,
+				AccessLog: httpsAccessLog,
 			},
+			Metrics:           &envoyMetrics,
+			Health:            &envoyHealth,
+			HealthListener:    ctx.Config.Health.Envoy.Listener(),
 			ClientCertificate: clientCertificate,
 			Logging: &contour_api_v1alpha1.EnvoyLogging{
 				AccessLogFormat:       accessLogFormat,
+				AccessLogFormatPreset: contour_api_v1alpha1.AccessLogFormatPreset(ctx.Config.AccessLogFormatPreset),
 				AccessLogFormatString: ctx.Config.AccessLogFormatString,
 				AccessLogJSONFields:   accessLogFields,
 				AccessLogLevel:        accessLogLevel,
+				AccessLogGRPC:         accessLogGRPC,
+				AccessLogFilter:       ctx.Config.AccessLogFilter.Filter(),
 			},
 			DefaultHTTPVersions: defaultHTTPVersions,
 			Timeouts:            timeoutParams,
 			Cluster: &contour_api_v1alpha1.ClusterParameters{
 				DNSLookupFamily:               dnsLookupFamily,
+				StatNameFormat:                contour_api_v1alpha1.ClusterStatNameFormat(ctx.Config.Cluster.StatNameFormat),
+				MaxStatNameLength:             ctx.Config.Cluster.MaxStatNameLength,
+				EndpointDeregistrationDelay:   endpointDeregistrationDelay,
+				HTTP2Keepalive:                http2Keepalive,
+				HTTP2:                         clusterHTTP2,
+				DNSResolvers:                  ctx.Config.Cluster.DNSResolvers,
+				DNSRefreshRate:                dnsRefreshRate,
+				CircuitBreakers:               circuitBreakers,
 				MaxRequestsPerConnection:      ctx.Config.Cluster.MaxRequestsPerConnection,
 				PerConnectionBufferLimitBytes: ctx.Config.Cluster.PerConnectionBufferLimitBytes,
 			},
@@ -570,19 +869,39 @@ func (ctx *serveContext) convertToContourConfigurationSpec() contour_api_v1alpha
 				XffNumTrustedHops: &ctx.Config.Network.XffNumTrustedHops,
 				EnvoyAdminPort:    &ctx.Config.Network.EnvoyAdminPort,
 			},
+			Compression: compression,
+			ResponseMap: responseMap,
 		},
 		Gateway: gatewayConfig,
 		HTTPProxy: &contour_api_v1alpha1.HTTPProxyConfig{
-			DisablePermitInsecure: &ctx.Config.DisablePermitInsecure,
-			RootNamespaces:        ctx.proxyRootNamespaces(),
-			FallbackCertificate:   fallbackCertificate,
+			DisablePermitInsecure:  &ctx.Config.DisablePermitInsecure,
+			RootNamespaces:         ctx.proxyRootNamespaces(),
+			FallbackCertificate:    fallbackCertificate,
+			EnableNamespaceReports: &ctx.Config.EnableNamespaceReports,
+			InsecureVirtualHosts:   contour_api_v1alpha1.InsecureVirtualHostPolicy(ctx.Config.InsecureVirtualHosts),
 		},
 		EnableExternalNameService:   &ctx.Config.EnableExternalNameService,
 		GlobalExternalAuthorization: globalExtAuth,
 		RateLimitService:            rateLimitService,
 		Policy:                      policy,
 		Metrics:                     &contourMetrics,
+		HTTPProxyMetrics:            httpProxyMetrics,
 		Tracing:                     tracingConfig,
+		Capture:                     captureConfig,
+		SecretBackend:               secretBackend,
+		SecretEncryption:            secretEncryption,
+		HTTPSRedirect:               httpsRedirect,
+		RuntimeFlags:                ctx.Config.RuntimeFlags,
+		FeatureGates:                ctx.Config.FeatureGates,
+		LeaderElection: &contour_api_v1alpha1.LeaderElectionConfig{
+			Disable:        ref.To(ctx.Config.LeaderElection.Disable),
+			LeaseName:      ctx.Config.LeaderElection.LeaseName,
+			LeaseNamespace: ctx.Config.LeaderElection.LeaseNamespace,
+			ResourceLock:   contour_api_v1alpha1.LeaderElectionResourceLock(ctx.Config.LeaderElection.ResourceLock),
+			LeaseDuration:  ref.To(ctx.Config.LeaderElection.LeaseDuration),
+			RenewDeadline:  ref.To(ctx.Config.LeaderElection.RenewDeadline),
+			RetryPeriod:    ref.To(ctx.Config.LeaderElection.RetryPeriod),
+		},
 	}
 
 	xdsServerType := contour_api_v1alpha1.ContourServerType
@@ -602,6 +921,20 @@ func (ctx *serveContext) convertToContourConfigurationSpec() contour_api_v1alpha
 		},
 	}
 
+	if authz := ctx.Config.Server.Authorization; authz != nil {
+		contourConfiguration.XDSServer.Authorization = &contour_api_v1alpha1.XDSServerAuthorization{
+			AllowedSubjectAltNames: authz.AllowedSANs,
+		}
+
+		if authz.JWT != nil {
+			contourConfiguration.XDSServer.Authorization.JWT = &contour_api_v1alpha1.XDSServerJWTAuthorization{
+				KeyFile:   authz.JWT.KeyFile,
+				Issuer:    authz.JWT.Issuer,
+				Audiences: authz.JWT.Audiences,
+			}
+		}
+	}
+
 	return contourConfiguration
 }
 
@@ -616,9 +949,56 @@ func setMetricsFromConfig(src config.MetricsServerParameters, dst *contour_api_v
 
 	if src.HasTLS() {
 		dst.TLS = &contour_api_v1alpha1.MetricsTLS{
-			CertFile: src.ServerCert,
-			KeyFile:  src.ServerKey,
-			CAFile:   src.CABundle,
+			CertFile:               src.ServerCert,
+			KeyFile:                src.ServerKey,
+			CAFile:                 src.CABundle,
+			AllowedSubjectAltNames: src.AllowedSANs,
+		}
+	}
+
+	if len(src.BearerTokenPath) > 0 {
+		dst.BearerTokenFile = src.BearerTokenPath
+	}
+}
+
+// featureGatesValue is a kingpin.Value that sets feature gates
+// from a comma separated list of <name>=<true|false> pairs.
+type featureGatesValue struct {
+	gates *map[string]bool
+}
+
+func (v featureGatesValue) Set(s string) error {
+	for _, pair := range strings.Split(s, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return fmt.Errorf("feature gate %q must be formatted as <name>=<true|false>", pair)
+		}
+
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid value of feature gate %q: %w", name, err)
+		}
+
+		if *v.gates == nil {
+			*v.gates = map[string]bool{}
 		}
+		(*v.gates)[name] = enabled
 	}
+
+	return nil
+}
+
+func (v featureGatesValue) String() string {
+	var pairs []string
+	for name, enabled := range *v.gates {
+		pairs = append(pairs, fmt.Sprintf("%s=%t", name, enabled))
+	}
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, ",")
+}
+
+// IsCumulative allows the flag to be given multiple times.
+func (v featureGatesValue) IsCumulative() bool {
+	return true
 }
@@ -24,6 +24,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/alecthomas/kingpin/v2"
 	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
 	contour_api_v1alpha1 "github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
 	envoy_v3 "github.com/projectcontour/contour/internal/envoy/v3"
@@ -31,6 +32,7 @@ import (
 	"github.com/projectcontour/contour/internal/ref"
 	"github.com/projectcontour/contour/pkg/config"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/tsaarni/certyaml"
 	"google.golang.org/grpc"
 )
@@ -477,8 +479,9 @@ func TestConvertServeContext(t *testing.T) {
 			},
 			Gateway: nil,
 			HTTPProxy: &contour_api_v1alpha1.HTTPProxyConfig{
-				DisablePermitInsecure: ref.To(false),
-				FallbackCertificate:   nil,
+				DisablePermitInsecure:  ref.To(false),
+				FallbackCertificate:    nil,
+				EnableNamespaceReports: ref.To(false),
 			},
 			EnableExternalNameService:   ref.To(false),
 			RateLimitService:            nil,
@@ -492,6 +495,15 @@ func TestConvertServeContext(t *testing.T) {
 				Address: "0.0.0.0",
 				Port:    8000,
 			},
+			LeaderElection: &contour_api_v1alpha1.LeaderElectionConfig{
+				Disable:        ref.To(false),
+				LeaseName:      "leader-elect",
+				LeaseNamespace: "projectcontour",
+				ResourceLock:   contour_api_v1alpha1.LeasesResourceLock,
+				LeaseDuration:  ref.To("15s"),
+				RenewDeadline:  ref.To("10s"),
+				RetryPeriod:    ref.To("2s"),
+			},
 		}
 	}
 
@@ -551,6 +563,30 @@ func TestConvertServeContext(t *testing.T) {
 				return cfg
 			},
 		},
+		"xds server authorization": {
+			getServeContext: func(ctx *serveContext) *serveContext {
+				ctx.Config.Server.Authorization = &config.XDSAuthorizationParameters{
+					AllowedSANs: []string{"spiffe://cluster.local/ns/projectcontour/sa/envoy"},
+					JWT: &config.XDSJWTParameters{
+						KeyFile:   "/keys/jwt.pem",
+						Issuer:    "https://kubernetes.default.svc",
+						Audiences: []string{"contour"},
+					},
+				}
+				return ctx
+			},
+			getContourConfiguration: func(cfg contour_api_v1alpha1.ContourConfigurationSpec) contour_api_v1alpha1.ContourConfigurationSpec {
+				cfg.XDSServer.Authorization = &contour_api_v1alpha1.XDSServerAuthorization{
+					AllowedSubjectAltNames: []string{"spiffe://cluster.local/ns/projectcontour/sa/envoy"},
+					JWT: &contour_api_v1alpha1.XDSServerJWTAuthorization{
+						KeyFile:   "/keys/jwt.pem",
+						Issuer:    "https://kubernetes.default.svc",
+						Audiences: []string{"contour"},
+					},
+				}
+				return cfg
+			},
+		},
 		"gatewayapi - controller": {
 			getServeContext: func(ctx *serveContext) *serveContext {
 				ctx.Config.GatewayConfig = &config.GatewayParameters{
@@ -604,6 +640,8 @@ func TestConvertServeContext(t *testing.T) {
 		"httpproxy": {
 			getServeContext: func(ctx *serveContext) *serveContext {
 				ctx.Config.DisablePermitInsecure = true
+				ctx.Config.EnableNamespaceReports = true
+				ctx.Config.InsecureVirtualHosts = config.ExplicitInsecureVirtualHosts
 				ctx.Config.TLS.FallbackCertificate = config.NamespacedName{
 					Name:      "fallbackname",
 					Namespace: "fallbacknamespace",
@@ -617,6 +655,8 @@ func TestConvertServeContext(t *testing.T) {
 						Name:      "fallbackname",
 						Namespace: "fallbacknamespace",
 					},
+					EnableNamespaceReports: ref.To(true),
+					InsecureVirtualHosts:   contour_api_v1alpha1.ExplicitInsecureVirtualHosts,
 				}
 				return cfg
 			},
@@ -747,6 +787,491 @@ func TestConvertServeContext(t *testing.T) {
 				return cfg
 			},
 		},
+		"access log -- preset": {
+			getServeContext: func(ctx *serveContext) *serveContext {
+				ctx.Config.AccessLogFormatPreset = config.JSONECSAccessLogPreset
+				return ctx
+			},
+			getContourConfiguration: func(cfg contour_api_v1alpha1.ContourConfigurationSpec) contour_api_v1alpha1.ContourConfigurationSpec {
+				cfg.Envoy.Logging.AccessLogFormatPreset = contour_api_v1alpha1.JSONECSAccessLogPreset
+				return cfg
+			},
+		},
+		"cluster stat names": {
+			getServeContext: func(ctx *serveContext) *serveContext {
+				ctx.Config.Cluster.StatNameFormat = config.ClusterNameClusterStatNameFormat
+				ctx.Config.Cluster.MaxStatNameLength = ref.To(uint32(60))
+				return ctx
+			},
+			getContourConfiguration: func(cfg contour_api_v1alpha1.ContourConfigurationSpec) contour_api_v1alpha1.ContourConfigurationSpec {
+				cfg.Envoy.Cluster.StatNameFormat = contour_api_v1alpha1.ClusterNameClusterStatNameFormat
+				cfg.Envoy.Cluster.MaxStatNameLength = ref.To(uint32(60))
+				return cfg
+			},
+		},
+		"endpoint deregistration delay": {
+			getServeContext: func(ctx *serveContext) *serveContext {
+				ctx.Config.Cluster.EndpointDeregistrationDelay = "10s"
+				return ctx
+			},
+			getContourConfiguration: func(cfg contour_api_v1alpha1.ContourConfigurationSpec) contour_api_v1alpha1.ContourConfigurationSpec {
+				cfg.Envoy.Cluster.EndpointDeregistrationDelay = ref.To("10s")
+				return cfg
+			},
+		},
+		"http2 keepalive": {
+			getServeContext: func(ctx *serveContext) *serveContext {
+				ctx.Config.Cluster.HTTP2Keepalive = config.HTTP2KeepaliveParameters{
+					Interval: "30s",
+					Timeout:  "5s",
+				}
+				return ctx
+			},
+			getContourConfiguration: func(cfg contour_api_v1alpha1.ContourConfigurationSpec) contour_api_v1alpha1.ContourConfigurationSpec {
+				cfg.Envoy.Cluster.HTTP2Keepalive = &contour_api_v1alpha1.HTTP2KeepaliveConfig{
+					Interval: "30s",
+					Timeout:  "5s",
+				}
+				return cfg
+			},
+		},
+		"cluster http2": {
+			getServeContext: func(ctx *serveContext) *serveContext {
+				ctx.Config.Cluster.HTTP2 = config.UpstreamHTTP2Parameters{
+					MaxConcurrentStreams:        ref.To(uint32(100)),
+					InitialStreamWindowSize:     ref.To(uint32(65535)),
+					InitialConnectionWindowSize: ref.To(uint32(1048576)),
+				}
+				return ctx
+			},
+			getContourConfiguration: func(cfg contour_api_v1alpha1.ContourConfigurationSpec) contour_api_v1alpha1.ContourConfigurationSpec {
+				cfg.Envoy.Cluster.HTTP2 = &contour_api_v1alpha1.UpstreamHTTP2{
+					MaxConcurrentStreams:        ref.To(uint32(100)),
+					InitialStreamWindowSize:     ref.To(uint32(65535)),
+					InitialConnectionWindowSize: ref.To(uint32(1048576)),
+				}
+				return cfg
+			},
+		},
+		"cluster dns resolvers": {
+			getServeContext: func(ctx *serveContext) *serveContext {
+				ctx.Config.Cluster.DNSResolvers = []string{"10.0.0.10", "10.0.0.11:5353"}
+				ctx.Config.Cluster.DNSRefreshRate = "30s"
+				return ctx
+			},
+			getContourConfiguration: func(cfg contour_api_v1alpha1.ContourConfigurationSpec) contour_api_v1alpha1.ContourConfigurationSpec {
+				cfg.Envoy.Cluster.DNSResolvers = []string{"10.0.0.10", "10.0.0.11:5353"}
+				cfg.Envoy.Cluster.DNSRefreshRate = ref.To("30s")
+				return cfg
+			},
+		},
+		"cluster circuit breakers": {
+			getServeContext: func(ctx *serveContext) *serveContext {
+				ctx.Config.Cluster.CircuitBreakers = config.CircuitBreakerParameters{
+					MaxConnections:     ref.To(uint32(10000)),
+					MaxRequests:        ref.To(uint32(20000)),
+					RetryBudgetPercent: ref.To(uint32(20)),
+				}
+				return ctx
+			},
+			getContourConfiguration: func(cfg contour_api_v1alpha1.ContourConfigurationSpec) contour_api_v1alpha1.ContourConfigurationSpec {
+				cfg.Envoy.Cluster.CircuitBreakers = &contour_api_v1alpha1.CircuitBreakers{
+					MaxConnections:     ref.To(uint32(10000)),
+					MaxRequests:        ref.To(uint32(20000)),
+					RetryBudgetPercent: ref.To(uint32(20)),
+				}
+				return cfg
+			},
+		},
+		"capture": {
+			getServeContext: func(ctx *serveContext) *serveContext {
+				ctx.Config.Capture = &config.Capture{
+					FilePathPrefix: "/var/log/envoy/capture",
+					SamplePercent:  ref.To(uint32(10)),
+				}
+				return ctx
+			},
+			getContourConfiguration: func(cfg contour_api_v1alpha1.ContourConfigurationSpec) contour_api_v1alpha1.ContourConfigurationSpec {
+				cfg.Capture = &contour_api_v1alpha1.CaptureConfig{
+					FilePathPrefix: "/var/log/envoy/capture",
+					SamplePercent:  ref.To(uint32(10)),
+				}
+				return cfg
+			},
+		},
+		"secret backend": {
+			getServeContext: func(ctx *serveContext) *serveContext {
+				ctx.Config.SecretBackend = &config.SecretBackend{
+					Type:             config.SDSSecretBackend,
+					ExtensionService: "projectcontour/sds",
+				}
+				return ctx
+			},
+			getContourConfiguration: func(cfg contour_api_v1alpha1.ContourConfigurationSpec) contour_api_v1alpha1.ContourConfigurationSpec {
+				cfg.SecretBackend = &contour_api_v1alpha1.SecretBackendConfig{
+					Type: contour_api_v1alpha1.SDSSecretBackend,
+					ExtensionService: &contour_api_v1alpha1.NamespacedName{
+						Namespace: "projectcontour",
+						Name:      "sds",
+					},
+				}
+				return cfg
+			},
+		},
+		"secret encryption": {
+			getServeContext: func(ctx *serveContext) *serveContext {
+				ctx.Config.SecretEncryption = &config.SecretEncryption{
+					DataKeyFile:   "/keys/data-key.enc",
+					UnsealCommand: []string{"aws", "kms", "decrypt"},
+				}
+				return ctx
+			},
+			getContourConfiguration: func(cfg contour_api_v1alpha1.ContourConfigurationSpec) contour_api_v1alpha1.ContourConfigurationSpec {
+				cfg.SecretEncryption = &contour_api_v1alpha1.SecretEncryptionConfig{
+					DataKeyFile:   "/keys/data-key.enc",
+					UnsealCommand: []string{"aws", "kms", "decrypt"},
+				}
+				return cfg
+			},
+		},
+		"https redirect": {
+			getServeContext: func(ctx *serveContext) *serveContext {
+				ctx.Config.HTTPSRedirect = &config.HTTPSRedirect{
+					StatusCode:           308,
+					StripQuery:           true,
+					ExcludedPathPrefixes: []string{"/.well-known/acme-challenge/"},
+				}
+				return ctx
+			},
+			getContourConfiguration: func(cfg contour_api_v1alpha1.ContourConfigurationSpec) contour_api_v1alpha1.ContourConfigurationSpec {
+				cfg.HTTPSRedirect = &contour_api_v1alpha1.HTTPSRedirectConfig{
+					StatusCode:           308,
+					StripQuery:           ref.To(true),
+					ExcludedPathPrefixes: []string{"/.well-known/acme-challenge/"},
+				}
+				return cfg
+			},
+		},
+		"runtime flags": {
+			getServeContext: func(ctx *serveContext) *serveContext {
+				ctx.Config.RuntimeFlags = config.RuntimeFlags{
+					"new-checkout": 25,
+				}
+				return ctx
+			},
+			getContourConfiguration: func(cfg contour_api_v1alpha1.ContourConfigurationSpec) contour_api_v1alpha1.ContourConfigurationSpec {
+				cfg.RuntimeFlags = map[string]uint32{
+					"new-checkout": 25,
+				}
+				return cfg
+			},
+		},
+		"feature gates": {
+			getServeContext: func(ctx *serveContext) *serveContext {
+				ctx.Config.FeatureGates = map[string]bool{
+					"DeltaXDS": true,
+				}
+				return ctx
+			},
+			getContourConfiguration: func(cfg contour_api_v1alpha1.ContourConfigurationSpec) contour_api_v1alpha1.ContourConfigurationSpec {
+				cfg.FeatureGates = map[string]bool{
+					"DeltaXDS": true,
+				}
+				return cfg
+			},
+		},
+		"listener drain type": {
+			getServeContext: func(ctx *serveContext) *serveContext {
+				ctx.Config.Listener.DrainType = config.ModifyOnlyListenerDrainType
+				return ctx
+			},
+			getContourConfiguration: func(cfg contour_api_v1alpha1.ContourConfigurationSpec) contour_api_v1alpha1.ContourConfigurationSpec {
+				cfg.Envoy.Listener.DrainType = contour_api_v1alpha1.ModifyOnlyListenerDrainType
+				return cfg
+			},
+		},
+		"listener removal delay": {
+			getServeContext: func(ctx *serveContext) *serveContext {
+				ctx.Config.Listener.RemovalDelay = "30s"
+				return ctx
+			},
+			getContourConfiguration: func(cfg contour_api_v1alpha1.ContourConfigurationSpec) contour_api_v1alpha1.ContourConfigurationSpec {
+				cfg.Envoy.Listener.RemovalDelay = ref.To("30s")
+				return cfg
+			},
+		},
+		"listener http2": {
+			getServeContext: func(ctx *serveContext) *serveContext {
+				ctx.Config.Listener.HTTP2 = config.HTTP2Parameters{
+					MaxConcurrentStreams:     ref.To(uint32(100)),
+					MaxOutboundControlFrames: ref.To(uint32(1000)),
+				}
+				return ctx
+			},
+			getContourConfiguration: func(cfg contour_api_v1alpha1.ContourConfigurationSpec) contour_api_v1alpha1.ContourConfigurationSpec {
+				cfg.Envoy.Listener.HTTP2 = &contour_api_v1alpha1.EnvoyHTTP2{
+					MaxConcurrentStreams:     ref.To(uint32(100)),
+					MaxOutboundControlFrames: ref.To(uint32(1000)),
+				}
+				return cfg
+			},
+		},
+		"listener socket options": {
+			getServeContext: func(ctx *serveContext) *serveContext {
+				ctx.Config.Listener.SocketOptions = config.SocketOptions{
+					TCPKeepalive: config.TCPKeepaliveParameters{
+						Idle:   "60s",
+						Probes: ref.To(uint32(3)),
+					},
+					TOS:      ref.To(int32(64)),
+					Freebind: true,
+				}
+				return ctx
+			},
+			getContourConfiguration: func(cfg contour_api_v1alpha1.ContourConfigurationSpec) contour_api_v1alpha1.ContourConfigurationSpec {
+				cfg.Envoy.Listener.SocketOptions = &contour_api_v1alpha1.EnvoySocketOptions{
+					TCPKeepalive: &contour_api_v1alpha1.TCPKeepaliveConfig{
+						Idle:   ref.To("60s"),
+						Probes: ref.To(uint32(3)),
+					},
+					TOS:      ref.To(int32(64)),
+					Freebind: ref.To(true),
+				}
+				return cfg
+			},
+		},
+		"listener HTTP filters": {
+			getServeContext: func(ctx *serveContext) *serveContext {
+				ctx.Config.Listener.HTTPFilters = []config.HTTPFilter{{
+					Name:        "buffer",
+					Position:    config.AfterCORSHTTPFilterPosition,
+					TypedConfig: `{"@type": "type.googleapis.com/envoy.extensions.filters.http.buffer.v3.Buffer"}`,
+				}}
+				return ctx
+			},
+			getContourConfiguration: func(cfg contour_api_v1alpha1.ContourConfigurationSpec) contour_api_v1alpha1.ContourConfigurationSpec {
+				cfg.Envoy.Listener.HTTPFilters = []contour_api_v1alpha1.HTTPFilter{{
+					Name:        "buffer",
+					Position:    contour_api_v1alpha1.AfterCORSHTTPFilterPosition,
+					TypedConfig: `{"@type": "type.googleapis.com/envoy.extensions.filters.http.buffer.v3.Buffer"}`,
+				}}
+				return cfg
+			},
+		},
+		"compression": {
+			getServeContext: func(ctx *serveContext) *serveContext {
+				ctx.Config.Compression = config.CompressionParameters{
+					Algorithm:        config.BrotliCompression,
+					MinContentLength: ref.To(uint32(1024)),
+				}
+				return ctx
+			},
+			getContourConfiguration: func(cfg contour_api_v1alpha1.ContourConfigurationSpec) contour_api_v1alpha1.ContourConfigurationSpec {
+				cfg.Envoy.Compression = &contour_api_v1alpha1.EnvoyCompression{
+					Algorithm:        contour_api_v1alpha1.BrotliCompression,
+					MinContentLength: ref.To(uint32(1024)),
+				}
+				return cfg
+			},
+		},
+		"response map": {
+			getServeContext: func(ctx *serveContext) *serveContext {
+				ctx.Config.ResponseMap = config.ResponseMap{{
+					StatusCode:        404,
+					RewriteStatusCode: 410,
+					Body:              "gone",
+				}, {
+					StatusCode: 503,
+					JSONFormat: map[string]string{"code": "%RESPONSE_CODE%"},
+				}}
+				return ctx
+			},
+			getContourConfiguration: func(cfg contour_api_v1alpha1.ContourConfigurationSpec) contour_api_v1alpha1.ContourConfigurationSpec {
+				cfg.Envoy.ResponseMap = []contour_api_v1alpha1.ResponseMapping{{
+					StatusCode:        404,
+					RewriteStatusCode: 410,
+					Body:              "gone",
+				}, {
+					StatusCode: 503,
+					JSONFormat: map[string]string{"code": "%RESPONSE_CODE%"},
+				}}
+				return cfg
+			},
+		},
+		"metrics authorization": {
+			getServeContext: func(ctx *serveContext) *serveContext {
+				ctx.Config.Metrics.Contour = config.MetricsServerParameters{
+					ServerCert:      "/certs/tls.crt",
+					ServerKey:       "/certs/tls.key",
+					CABundle:        "/certs/ca.crt",
+					AllowedSANs:     []string{"prometheus"},
+					BearerTokenPath: "/tokens/metrics",
+				}
+				ctx.Config.Metrics.Envoy.BearerTokenPath = "/tokens/metrics"
+				return ctx
+			},
+			getContourConfiguration: func(cfg contour_api_v1alpha1.ContourConfigurationSpec) contour_api_v1alpha1.ContourConfigurationSpec {
+				cfg.Metrics.TLS = &contour_api_v1alpha1.MetricsTLS{
+					CertFile:               "/certs/tls.crt",
+					KeyFile:                "/certs/tls.key",
+					CAFile:                 "/certs/ca.crt",
+					AllowedSubjectAltNames: []string{"prometheus"},
+				}
+				cfg.Metrics.BearerTokenFile = "/tokens/metrics"
+				cfg.Envoy.Metrics.BearerTokenFile = "/tokens/metrics"
+				return cfg
+			},
+		},
+		"access log path": {
+			getServeContext: func(ctx *serveContext) *serveContext {
+				ctx.Config.AccessLogPath = "/var/log/envoy/access.log"
+				return ctx
+			},
+			getContourConfiguration: func(cfg contour_api_v1alpha1.ContourConfigurationSpec) contour_api_v1alpha1.ContourConfigurationSpec {
+				cfg.Envoy.HTTPListener.AccessLog = "/var/log/envoy/access.log"
+				cfg.Envoy.HTTPSListener.AccessLog = "/var/log/envoy/access.log"
+				return cfg
+			},
+		},
+		"access log grpc": {
+			getServeContext: func(ctx *serveContext) *serveContext {
+				ctx.Config.AccessLogGRPC = &config.AccessLogGRPCParameters{
+					ExtensionService:    "projectcontour/als",
+					LogName:             "envoy",
+					BufferSizeBytes:     32768,
+					BufferFlushInterval: "2s",
+				}
+				return ctx
+			},
+			getContourConfiguration: func(cfg contour_api_v1alpha1.ContourConfigurationSpec) contour_api_v1alpha1.ContourConfigurationSpec {
+				cfg.Envoy.Logging.AccessLogGRPC = &contour_api_v1alpha1.AccessLogGRPCConfig{
+					ExtensionService: &contour_api_v1alpha1.NamespacedName{
+						Namespace: "projectcontour",
+						Name:      "als",
+					},
+					LogName:             ref.To("envoy"),
+					BufferSizeBytes:     ref.To(uint32(32768)),
+					BufferFlushInterval: ref.To("2s"),
+				}
+				return cfg
+			},
+		},
+		"access log filter": {
+			getServeContext: func(ctx *serveContext) *serveContext {
+				ctx.Config.AccessLogFilter = &config.AccessLogFilterParameters{
+					MinStatusCode: 400,
+					MinDuration:   "1s",
+					SamplePercent: 5,
+				}
+				return ctx
+			},
+			getContourConfiguration: func(cfg contour_api_v1alpha1.ContourConfigurationSpec) contour_api_v1alpha1.ContourConfigurationSpec {
+				cfg.Envoy.Logging.AccessLogFilter = &contour_api_v1alpha1.AccessLogFilter{
+					MinStatusCode: ref.To(uint32(400)),
+					MinDuration:   ref.To("1s"),
+					SamplePercent: ref.To(uint32(5)),
+				}
+				return cfg
+			},
+		},
+		"httpproxy metrics": {
+			getServeContext: func(ctx *serveContext) *serveContext {
+				ctx.Config.Metrics.HTTPProxy = &config.HTTPProxyMetricsParameters{
+					ScrapeInterval: "15s",
+				}
+				return ctx
+			},
+			getContourConfiguration: func(cfg contour_api_v1alpha1.ContourConfigurationSpec) contour_api_v1alpha1.ContourConfigurationSpec {
+				cfg.HTTPProxyMetrics = &contour_api_v1alpha1.HTTPProxyMetricsConfig{
+					ScrapeInterval: ref.To("15s"),
+				}
+				return cfg
+			},
+		},
+		"envoy health listener": {
+			getServeContext: func(ctx *serveContext) *serveContext {
+				ctx.Config.Health.Envoy = config.EnvoyHealthParameters{
+					Port:          8090,
+					ReadinessPath: "/healthz/ready",
+					LivenessPath:  "/healthz/live",
+					LivenessClusterMinHealthyPercentages: map[string]uint32{
+						"default/backend/80/da39a3ee5e": 50,
+					},
+				}
+				return ctx
+			},
+			getContourConfiguration: func(cfg contour_api_v1alpha1.ContourConfigurationSpec) contour_api_v1alpha1.ContourConfigurationSpec {
+				cfg.Envoy.Health.Port = 8090
+				cfg.Envoy.HealthListener = &contour_api_v1alpha1.EnvoyHealthListener{
+					ReadinessPath: ref.To("/healthz/ready"),
+					Liveness: &contour_api_v1alpha1.EnvoyLivenessEndpoint{
+						Path: "/healthz/live",
+						ClusterMinHealthyPercentages: map[string]uint32{
+							"default/backend/80/da39a3ee5e": 50,
+						},
+					},
+				}
+				return cfg
+			},
+		},
+		"listener HTTP connection manager": {
+			getServeContext: func(ctx *serveContext) *serveContext {
+				ctx.Config.Listener.HTTPConnectionManager = config.HTTPConnectionManagerParameters{
+					ServerName:                 "example",
+					ServerHeaderTransformation: config.AppendIfAbsentServerHeader,
+					NormalizePath:              ref.To(false),
+				}
+				return ctx
+			},
+			getContourConfiguration: func(cfg contour_api_v1alpha1.ContourConfigurationSpec) contour_api_v1alpha1.ContourConfigurationSpec {
+				cfg.Envoy.Listener.ServerHeaderTransformation = contour_api_v1alpha1.AppendIfAbsentServerHeader
+				cfg.Envoy.Listener.HTTPConnectionManager = &contour_api_v1alpha1.EnvoyHTTPConnectionManager{
+					ServerName:    ref.To("example"),
+					NormalizePath: ref.To(false),
+				}
+				return cfg
+			},
+		},
+		"listener HTTP connection manager, remove server header": {
+			getServeContext: func(ctx *serveContext) *serveContext {
+				ctx.Config.ServerHeaderTransformation = config.RemoveServerHeader
+				ctx.Config.Listener.HTTPConnectionManager = config.HTTPConnectionManagerParameters{
+					Via: "1.1 example",
+				}
+				return ctx
+			},
+			getContourConfiguration: func(cfg contour_api_v1alpha1.ContourConfigurationSpec) contour_api_v1alpha1.ContourConfigurationSpec {
+				cfg.Envoy.Listener.ServerHeaderTransformation = contour_api_v1alpha1.RemoveServerHeader
+				cfg.Envoy.Listener.HTTPConnectionManager = &contour_api_v1alpha1.EnvoyHTTPConnectionManager{
+					Via: ref.To("1.1 example"),
+				}
+				return cfg
+			},
+		},
+		"timeout replies": {
+			getServeContext: func(ctx *serveContext) *serveContext {
+				ctx.Config.Timeouts.ResponseTimeoutReply = &config.TimeoutReply{
+					StatusCode:  503,
+					Body:        `{"error":"timeout"}`,
+					ContentType: "application/json",
+				}
+				ctx.Config.Timeouts.IdleTimeoutReply = &config.TimeoutReply{
+					StatusCode: 408,
+				}
+				return ctx
+			},
+			getContourConfiguration: func(cfg contour_api_v1alpha1.ContourConfigurationSpec) contour_api_v1alpha1.ContourConfigurationSpec {
+				cfg.Envoy.Timeouts.ResponseTimeoutReply = &contour_api_v1.TimeoutReply{
+					StatusCode:  503,
+					Body:        `{"error":"timeout"}`,
+					ContentType: "application/json",
+				}
+				cfg.Envoy.Timeouts.IdleTimeoutReply = &contour_api_v1.TimeoutReply{
+					StatusCode: 408,
+				}
+				return cfg
+			},
+		},
 		"disable merge slashes": {
 			getServeContext: func(ctx *serveContext) *serveContext {
 				ctx.Config.DisableMergeSlashes = true
@@ -852,6 +1377,82 @@ func TestConvertServeContext(t *testing.T) {
 				return cfg
 			},
 		},
+		"leader election": {
+			getServeContext: func(ctx *serveContext) *serveContext {
+				ctx.Config.LeaderElection = config.LeaderElectionParameters{
+					Disable:        true,
+					LeaseName:      "contour",
+					LeaseNamespace: "contour-system",
+					ResourceLock:   config.LeasesResourceLock,
+					LeaseDuration:  "30s",
+					RenewDeadline:  "20s",
+					RetryPeriod:    "4s",
+				}
+				return ctx
+			},
+			getContourConfiguration: func(cfg contour_api_v1alpha1.ContourConfigurationSpec) contour_api_v1alpha1.ContourConfigurationSpec {
+				cfg.LeaderElection = &contour_api_v1alpha1.LeaderElectionConfig{
+					Disable:        ref.To(true),
+					LeaseName:      "contour",
+					LeaseNamespace: "contour-system",
+					ResourceLock:   contour_api_v1alpha1.LeasesResourceLock,
+					LeaseDuration:  ref.To("30s"),
+					RenewDeadline:  ref.To("20s"),
+					RetryPeriod:    ref.To("4s"),
+				}
+				return cfg
+			},
+		},
+		"tracing config zipkin": {
+			getServeContext: func(ctx *serveContext) *serveContext {
+				ctx.Config.Tracing = &config.Tracing{
+					ExtensionService: "zipkin/zipkin-collector",
+					Provider:         config.ZipkinTracingProvider,
+					Zipkin: &config.ZipkinTracing{
+						CollectorEndpoint: "/api/v2/spans",
+						Encoding:          "proto",
+					},
+				}
+				return ctx
+			},
+			getContourConfiguration: func(cfg contour_api_v1alpha1.ContourConfigurationSpec) contour_api_v1alpha1.ContourConfigurationSpec {
+				cfg.Tracing = &contour_api_v1alpha1.TracingConfig{
+					ExtensionService: &contour_api_v1alpha1.NamespacedName{
+						Name:      "zipkin-collector",
+						Namespace: "zipkin",
+					},
+					Provider: contour_api_v1alpha1.ZipkinTracingProvider,
+					Zipkin: &contour_api_v1alpha1.ZipkinTracingConfig{
+						CollectorEndpoint: "/api/v2/spans",
+						Encoding:          contour_api_v1alpha1.ProtoZipkinSpanEncoding,
+					},
+				}
+				return cfg
+			},
+		},
+		"tracing config force trace": {
+			getServeContext: func(ctx *serveContext) *serveContext {
+				ctx.Config.Tracing = &config.Tracing{
+					ExtensionService: "otel/otel-collector",
+					ForceTrace: &config.ForceTrace{
+						AllowedClientCIDRs: []string{"10.0.0.0/8"},
+					},
+				}
+				return ctx
+			},
+			getContourConfiguration: func(cfg contour_api_v1alpha1.ContourConfigurationSpec) contour_api_v1alpha1.ContourConfigurationSpec {
+				cfg.Tracing = &contour_api_v1alpha1.TracingConfig{
+					ExtensionService: &contour_api_v1alpha1.NamespacedName{
+						Name:      "otel-collector",
+						Namespace: "otel",
+					},
+					ForceTrace: &contour_api_v1alpha1.ForceTraceConfig{
+						AllowedClientCIDRs: []string{"10.0.0.0/8"},
+					},
+				}
+				return cfg
+			},
+		},
 		"tracing config only extensionService": {
 			getServeContext: func(ctx *serveContext) *serveContext {
 				ctx.Config.Tracing = &config.Tracing{
@@ -880,3 +1481,74 @@ func TestConvertServeContext(t *testing.T) {
 		})
 	}
 }
+
+func TestFeatureGatesFlag(t *testing.T) {
+	tests := map[string]struct {
+		args    []string
+		want    map[string]bool
+		wantErr bool
+	}{
+		"single gate": {
+			args: []string{"--feature-gates=DeltaXDS=true"},
+			want: map[string]bool{"DeltaXDS": true},
+		},
+		"comma separated gates": {
+			args: []string{"--feature-gates=DeltaXDS=true, GatewayAPIExperimentalRoutes=false"},
+			want: map[string]bool{"DeltaXDS": true, "GatewayAPIExperimentalRoutes": false},
+		},
+		"repeated flag": {
+			args: []string{"--feature-gates=DeltaXDS=true", "--feature-gates=DeltaXDS=false"},
+			want: map[string]bool{"DeltaXDS": false},
+		},
+		"missing value": {
+			args:    []string{"--feature-gates=DeltaXDS"},
+			wantErr: true,
+		},
+		"invalid value": {
+			args:    []string{"--feature-gates=DeltaXDS=maybe"},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			app := kingpin.New("contour", "")
+			serve, ctx := registerServe(app)
+
+			_, err := app.Parse(append([]string{serve.FullCommand()}, tc.args...))
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, ctx.Config.FeatureGates)
+		})
+	}
+}
+
+func TestConfigPathFlag(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.yaml")
+	require.NoError(t, os.WriteFile(base, []byte("accesslog-level: error\nkubernetesClientQPS: 10\n"), 0600))
+
+	overrides := filepath.Join(dir, "overrides")
+	require.NoError(t, os.Mkdir(overrides, 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(overrides, "cluster.yaml"), []byte("accesslog-level: info\n"), 0600))
+
+	app := kingpin.New("contour", "")
+	serve, ctx := registerServe(app)
+
+	args := []string{serve.FullCommand(), "--config-path", base, "-c", overrides, "--kubernetes-client-qps=20"}
+
+	// Parse twice, as main does, so that the flags
+	// override the configuration files.
+	_, err := app.Parse(args)
+	require.NoError(t, err)
+	_, err = app.Parse(args)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{base, overrides}, ctx.configPaths)
+	assert.Equal(t, config.LogLevelInfo, ctx.Config.AccessLogLevel)
+	assert.Equal(t, float32(20), ctx.Config.KubeClientQPS)
+}
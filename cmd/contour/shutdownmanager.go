@@ -33,6 +33,7 @@ import (
 const (
 	prometheusURL      = "http://unix/stats/prometheus"
 	healthcheckFailURL = "http://unix/healthcheck/fail"
+	reopenLogsURL      = "http://unix/reopen_logs"
 	prometheusStat     = "envoy_http_downstream_cx_active"
 )
 
@@ -50,6 +51,22 @@ type shutdownmanagerContext struct {
 	// shutdownReadyCheckInterval is the polling interval for the file used in the /shutdown endpoint
 	shutdownReadyCheckInterval time.Duration
 
+	// adminAddress is the address of the Envoy admin webpage, used to reopen rotated access logs
+	adminAddress string
+
+	// accessLogPaths are the Envoy access log files that are rotated
+	accessLogPaths []string
+
+	// accessLogMaxBytes is the size at which an access log file is rotated.
+	// If zero, access log files are not rotated.
+	accessLogMaxBytes int64
+
+	// accessLogMaxFiles is the number of rotated files kept for each access log file
+	accessLogMaxFiles int
+
+	// accessLogCheckInterval is the polling interval for the sizes of the access log files
+	accessLogCheckInterval time.Duration
+
 	logrus.FieldLogger
 }
 
@@ -85,6 +102,8 @@ func newShutdownManagerContext() *shutdownmanagerContext {
 		httpServePort:              8090,
 		shutdownReadyFile:          shutdownReadyFile,
 		shutdownReadyCheckInterval: shutdownReadyCheckInterval,
+		accessLogMaxFiles:          5,
+		accessLogCheckInterval:     10 * time.Second,
 	}
 }
 
@@ -137,6 +156,77 @@ func (s *shutdownmanagerContext) shutdownReadyHandler(w http.ResponseWriter, r *
 	}
 }
 
+// rotateAccessLogs polls the sizes of the access log files until ctx is done, and
+// rotates the files that have grown to the maximum size. Envoy keeps writing to the
+// open handle of a rotated file until it is told to reopen its logs, so no lines
+// are lost.
+func (s *shutdownmanagerContext) rotateAccessLogs(ctx context.Context) {
+	l := s.WithField("context", "rotateAccessLogs")
+	ticker := time.NewTicker(s.accessLogCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		rotated := false
+		for _, path := range s.accessLogPaths {
+			ok, err := rotateAccessLog(path, s.accessLogMaxBytes, s.accessLogMaxFiles)
+			if err != nil {
+				l.WithField("path", path).Errorf("error rotating access log: %v", err)
+				continue
+			}
+			if ok {
+				l.WithField("path", path).Info("rotated access log")
+				rotated = true
+			}
+		}
+
+		if rotated {
+			if err := reopenEnvoyLogs(s.adminAddress); err != nil {
+				l.Error(err)
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// rotateAccessLog renames the access log file at path to path.1, after shifting
+// the previously rotated files up by one and dropping the oldest one, if the file
+// is a regular file of at least maxBytes bytes. It returns whether the file was
+// rotated.
+func rotateAccessLog(path string, maxBytes int64, maxFiles int) (bool, error) {
+	info, err := os.Stat(path)
+	switch {
+	case os.IsNotExist(err):
+		return false, nil
+	case err != nil:
+		return false, err
+	}
+
+	// Files such as /dev/stdout cannot be rotated.
+	if !info.Mode().IsRegular() || info.Size() < maxBytes {
+		return false, nil
+	}
+
+	if maxFiles < 1 {
+		maxFiles = 1
+	}
+	for i := maxFiles - 1; i > 0; i-- {
+		err := os.Rename(fmt.Sprintf("%s.%d", path, i), fmt.Sprintf("%s.%d", path, i+1))
+		if err != nil && !os.IsNotExist(err) {
+			return false, err
+		}
+	}
+
+	if err := os.Rename(path, path+".1"); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 // shutdownHandler is called from a pod preStop hook, where it will block pod shutdown
 // until envoy is able to drain connections to below the min-open threshold.
 func (s *shutdownContext) shutdownHandler() {
@@ -219,6 +309,29 @@ func shutdownEnvoy(adminAddress string) error {
 	return nil
 }
 
+// reopenEnvoyLogs sends a POST request to /reopen_logs to tell Envoy to reopen its access log files
+func reopenEnvoyLogs(adminAddress string) error {
+
+	httpClient := http.Client{
+		Transport: &http.Transport{
+			DialContext: func(_ context.Context, _, _ string) (net.Conn, error) {
+				return net.Dial("unix", adminAddress)
+			},
+		},
+	}
+	/* #nosec */
+	resp, err := httpClient.Post(reopenLogsURL, "", nil)
+	if err != nil {
+		return fmt.Errorf("creating reopen logs POST request failed: %s", err)
+	}
+
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("POST for %q returned HTTP status %s", reopenLogsURL, resp.Status)
+	}
+	return nil
+}
+
 // getOpenConnections parses a http request to a prometheus endpoint returning the sum of values found
 func getOpenConnections(adminAddress string) (int, error) {
 
@@ -286,6 +399,11 @@ func doShutdownManager(config *shutdownmanagerContext) {
 	http.HandleFunc("/healthz", config.healthzHandler)
 	http.HandleFunc("/shutdown", config.shutdownReadyHandler)
 
+	if len(config.accessLogPaths) > 0 && config.accessLogMaxBytes > 0 {
+		config.Infof("rotating access logs %v at %d bytes", config.accessLogPaths, config.accessLogMaxBytes)
+		go config.rotateAccessLogs(context.Background())
+	}
+
 	// Fails gosec G114: Use of net/http serve function that has no support for setting timeouts
 	// nolint:gosec
 	if err := http.ListenAndServe(fmt.Sprintf(":%d", config.httpServePort), nil); err != http.ErrServerClosed {
@@ -300,6 +418,11 @@ func registerShutdownManager(cmd *kingpin.CmdClause, log logrus.FieldLogger) (*k
 	ctx.FieldLogger = log.WithField("context", "shutdown-manager")
 
 	shutdownmgr := cmd.Command("shutdown-manager", "Start envoy shutdown-manager.")
+	shutdownmgr.Flag("access-log", "Envoy access log file to rotate. May be repeated.").PlaceHolder("/path/to/file").StringsVar(&ctx.accessLogPaths)
+	shutdownmgr.Flag("access-log-check-interval", "Time to poll the sizes of the access log files.").DurationVar(&ctx.accessLogCheckInterval)
+	shutdownmgr.Flag("access-log-max-bytes", "Size at which an access log file is rotated. Zero disables rotation.").Int64Var(&ctx.accessLogMaxBytes)
+	shutdownmgr.Flag("access-log-max-files", "Number of rotated files to keep for each access log file.").IntVar(&ctx.accessLogMaxFiles)
+	shutdownmgr.Flag("admin-address", "Envoy admin interface address.").Default("/admin/admin.sock").StringVar(&ctx.adminAddress)
 	shutdownmgr.Flag("ready-file", "File to poll while waiting shutdown to be completed.").Default(shutdownReadyFile).StringVar(&ctx.shutdownReadyFile)
 	shutdownmgr.Flag("serve-port", "Port to serve the http server on.").IntVar(&ctx.httpServePort)
 
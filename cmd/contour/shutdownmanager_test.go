@@ -134,6 +134,57 @@ func TestShutdownManager_ShutdownReadyHandler_ClientCancel(t *testing.T) {
 	handler.ServeHTTP(rr, req)
 }
 
+func TestRotateAccessLog(t *testing.T) {
+	dir := t.TempDir()
+	accessLog := path.Join(dir, "access.log")
+
+	readFile := func(name string) string {
+		t.Helper()
+		data, err := os.ReadFile(name)
+		assert.NoError(t, err)
+		return string(data)
+	}
+
+	// A missing file is not rotated.
+	rotated, err := rotateAccessLog(accessLog, 4, 2)
+	assert.NoError(t, err)
+	assert.False(t, rotated)
+
+	// A file below the maximum size is not rotated.
+	assert.NoError(t, os.WriteFile(accessLog, []byte("one"), 0600))
+	rotated, err = rotateAccessLog(accessLog, 4, 2)
+	assert.NoError(t, err)
+	assert.False(t, rotated)
+
+	assert.NoError(t, os.WriteFile(accessLog, []byte("first"), 0600))
+	rotated, err = rotateAccessLog(accessLog, 4, 2)
+	assert.NoError(t, err)
+	assert.True(t, rotated)
+	assert.NoFileExists(t, accessLog)
+	assert.Equal(t, "first", readFile(accessLog+".1"))
+
+	assert.NoError(t, os.WriteFile(accessLog, []byte("second"), 0600))
+	rotated, err = rotateAccessLog(accessLog, 4, 2)
+	assert.NoError(t, err)
+	assert.True(t, rotated)
+	assert.Equal(t, "second", readFile(accessLog+".1"))
+	assert.Equal(t, "first", readFile(accessLog+".2"))
+
+	// The oldest file is dropped.
+	assert.NoError(t, os.WriteFile(accessLog, []byte("third"), 0600))
+	rotated, err = rotateAccessLog(accessLog, 4, 2)
+	assert.NoError(t, err)
+	assert.True(t, rotated)
+	assert.Equal(t, "third", readFile(accessLog+".1"))
+	assert.Equal(t, "second", readFile(accessLog+".2"))
+	assert.NoFileExists(t, accessLog+".3")
+
+	// Devices are not rotated.
+	rotated, err = rotateAccessLog(os.DevNull, 0, 2)
+	assert.NoError(t, err)
+	assert.False(t, rotated)
+}
+
 func TestParseOpenConnections(t *testing.T) {
 	type testcase struct {
 		stats           io.Reader
@@ -0,0 +1,240 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/kingpin/v2"
+	contour_api_v1alpha1 "github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
+	"github.com/projectcontour/contour/internal/featuregate"
+	"github.com/projectcontour/contour/pkg/config"
+	"gopkg.in/yaml.v3"
+	k8s_yaml "sigs.k8s.io/yaml"
+)
+
+type validateConfigContext struct {
+	// Path of the configuration file or ContourConfiguration to validate.
+	path string
+
+	// Output format of the problems found, text or json.
+	output string
+}
+
+func registerValidateConfig(app *kingpin.Application) (*kingpin.CmdClause, *validateConfigContext) {
+	ctx := &validateConfigContext{}
+
+	validate := app.Command("validate-config", "Validate a configuration file or a ContourConfiguration, exiting with status 1 if it is invalid.")
+	validate.Arg("path", "Path of the configuration file, or of a YAML ContourConfiguration.").Required().ExistingFileVar(&ctx.path)
+	validate.Flag("output", "Output format of the problems found. Either text or json.").Short('o').Default("text").EnumVar(&ctx.output, "text", "json")
+
+	return validate, ctx
+}
+
+// Severities of the problems found in a configuration.
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+)
+
+// configProblem is a problem found in a configuration.
+type configProblem struct {
+	Severity string `json:"severity"`
+	Line     int    `json:"line,omitempty"`
+	Field    string `json:"field,omitempty"`
+	Message  string `json:"message"`
+}
+
+// configValidation is the result of validating a configuration.
+type configValidation struct {
+	Path     string          `json:"path"`
+	Valid    bool            `json:"valid"`
+	Problems []configProblem `json:"problems"`
+}
+
+// doValidateConfig validates the configuration at ctx.path, writes
+// the problems found to out and returns true if it is valid.
+func doValidateConfig(ctx *validateConfigContext, out io.Writer) bool {
+	result := configValidation{
+		Path:     ctx.path,
+		Problems: validateConfig(ctx.path),
+		Valid:    true,
+	}
+	for _, p := range result.Problems {
+		if p.Severity == severityError {
+			result.Valid = false
+		}
+	}
+
+	if ctx.output == "json" {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(result)
+		return result.Valid
+	}
+
+	for _, p := range result.Problems {
+		location := result.Path
+		if p.Line > 0 {
+			location += ":" + strconv.Itoa(p.Line)
+		}
+		message := p.Message
+		if p.Field != "" {
+			message = p.Field + ": " + message
+		}
+		fmt.Fprintf(out, "%s: %s: %s\n", location, p.Severity, message)
+	}
+	if result.Valid {
+		fmt.Fprintf(out, "%s: valid\n", result.Path)
+	}
+
+	return result.Valid
+}
+
+// validateConfig returns the problems found in the configuration
+// file or ContourConfiguration at path.
+func validateConfig(path string) []configProblem {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return []configProblem{{Severity: severityError, Message: err.Error()}}
+	}
+
+	if isContourConfiguration(path, data) {
+		return validateContourConfiguration(data)
+	}
+
+	return validateConfigFile(path, data)
+}
+
+// isContourConfiguration returns true if data is a YAML
+// or JSON document of a ContourConfiguration.
+func isContourConfiguration(path string, data []byte) bool {
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		return false
+	}
+
+	var typeMeta struct {
+		Kind string `yaml:"kind"`
+	}
+	if err := yaml.Unmarshal(data, &typeMeta); err != nil {
+		return false
+	}
+
+	return typeMeta.Kind == "ContourConfiguration"
+}
+
+func validateContourConfiguration(data []byte) []configProblem {
+	var cfg contour_api_v1alpha1.ContourConfiguration
+	if err := k8s_yaml.UnmarshalStrict(data, &cfg); err != nil {
+		return []configProblem{{Severity: severityError, Message: err.Error()}}
+	}
+
+	if _, err := overlayAndValidate(cfg.Spec); err != nil {
+		return []configProblem{{Severity: severityError, Message: err.Error()}}
+	}
+
+	return nil
+}
+
+func validateConfigFile(path string, data []byte) []configProblem {
+	params, conversions, err := config.ParseFileWithConversions(path)
+	if err != nil {
+		return parseProblems(err)
+	}
+
+	var problems []configProblem
+	for _, c := range conversions {
+		problems = append(problems, configProblem{
+			Severity: severityWarning,
+			Line:     config.FieldLine(data, c.From),
+			Field:    c.From,
+			Message:  c.String(),
+		})
+	}
+
+	fieldErrors := params.FieldErrors()
+	for _, fe := range fieldErrors {
+		problems = append(problems, configProblem{
+			Severity: severityError,
+			Line:     config.FieldLine(data, fe.Field),
+			Field:    fe.Field,
+			Message:  fe.Err.Error(),
+		})
+	}
+
+	if _, err := featuregate.New(params.FeatureGates); err != nil {
+		problems = append(problems, configProblem{
+			Severity: severityError,
+			Line:     config.FieldLine(data, "feature-gates"),
+			Field:    "feature-gates",
+			Message:  err.Error(),
+		})
+		return problems
+	}
+
+	// Contour converts the parameters to a ContourConfiguration,
+	// whose validation covers the combinations of parameters.
+	if len(fieldErrors) == 0 {
+		ctx := newServeContext()
+		ctx.Config = *params
+		if _, err := overlayAndValidate(ctx.convertToContourConfigurationSpec()); err != nil {
+			problems = append(problems, configProblem{Severity: severityError, Message: err.Error()})
+		}
+	}
+
+	return problems
+}
+
+// yamlLine matches the line number in the errors of the YAML decoder.
+var yamlLine = regexp.MustCompile(`line (\d+): `)
+
+// yamlUnknownField matches the errors of the YAML decoder for unknown fields.
+var yamlUnknownField = regexp.MustCompile(`field (\S+) not found in type`)
+
+// parseProblems returns the problems of err, an error
+// returned by config.ParseFileWithConversions.
+func parseProblems(err error) []configProblem {
+	messages := []string{err.Error()}
+
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		messages = typeErr.Errors
+	}
+
+	problems := make([]configProblem, 0, len(messages))
+	for _, message := range messages {
+		p := configProblem{Severity: severityError, Message: message}
+
+		if m := yamlLine.FindStringSubmatchIndex(message); m != nil {
+			p.Line, _ = strconv.Atoi(message[m[2]:m[3]])
+			// Drop the line number, which is reported separately.
+			p.Message = message[:m[0]] + message[m[1]:]
+		}
+		if m := yamlUnknownField.FindStringSubmatch(message); m != nil {
+			p.Field = m[1]
+		}
+
+		problems = append(problems, p)
+	}
+
+	return problems
+}
@@ -0,0 +1,210 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateConfig(t *testing.T) {
+	tests := map[string]struct {
+		filename string
+		content  string
+		want     []configProblem
+	}{
+		"valid configuration file": {
+			filename: "contour.yaml",
+			content: `
+timeouts:
+  request-timeout: 30s
+`,
+		},
+		"unknown fields": {
+			filename: "contour.yaml",
+			content: `
+timeouts:
+  request-timeout: 30s
+foo: bar
+policy:
+  bar: baz
+`,
+			want: []configProblem{{
+				Severity: severityError,
+				Line:     4,
+				Field:    "foo",
+				Message:  "field foo not found in type config.Parameters",
+			}, {
+				Severity: severityError,
+				Line:     6,
+				Field:    "bar",
+				Message:  "field bar not found in type config.PolicyParameters",
+			}},
+		},
+		"syntax error": {
+			filename: "contour.yaml",
+			content: `
+timeouts:
+  request-timeout: 30s
+  connect-timeout: 2s: 5s
+`,
+			want: []configProblem{{
+				Severity: severityError,
+				Line:     4,
+				Message:  "failed to parse configuration: yaml: mapping values are not allowed in this context",
+			}},
+		},
+		"invalid parameters": {
+			filename: "contour.yaml",
+			content: `
+accesslog-format: xml
+cluster:
+  dns-lookup-family: ipv5
+`,
+			want: []configProblem{{
+				Severity: severityError,
+				Line:     4,
+				Field:    "cluster.dns-lookup-family",
+				Message:  `invalid cluster DNS lookup family "ipv5"`,
+			}, {
+				Severity: severityError,
+				Line:     2,
+				Field:    "accesslog-format",
+				Message:  `invalid access log format "xml"`,
+			}},
+		},
+		"deprecated parameter": {
+			filename: "contour.yaml",
+			content: `
+request-timeout: 30s
+`,
+			want: []configProblem{{
+				Severity: severityWarning,
+				Line:     2,
+				Field:    "request-timeout",
+				Message:  "request-timeout is deprecated and was converted to timeouts.request-timeout",
+			}},
+		},
+		"unknown feature gate": {
+			filename: "contour.yaml",
+			content: `
+feature-gates:
+  Teleportation: true
+`,
+			want: []configProblem{{
+				Severity: severityError,
+				Line:     2,
+				Field:    "feature-gates",
+				Message:  "unknown feature gates: Teleportation",
+			}},
+		},
+		"invalid TOML configuration file": {
+			filename: "contour.toml",
+			content: `
+accesslog-format = "xml"
+`,
+			want: []configProblem{{
+				Severity: severityError,
+				Field:    "accesslog-format",
+				Message:  `invalid access log format "xml"`,
+			}},
+		},
+		"valid ContourConfiguration": {
+			filename: "contourconfig.yaml",
+			content: `
+apiVersion: projectcontour.io/v1alpha1
+kind: ContourConfiguration
+metadata:
+  name: contour
+spec:
+  envoy:
+    cluster:
+      dnsLookupFamily: v4
+`,
+		},
+		"invalid ContourConfiguration": {
+			filename: "contourconfig.yaml",
+			content: `
+apiVersion: projectcontour.io/v1alpha1
+kind: ContourConfiguration
+metadata:
+  name: contour
+spec:
+  envoy:
+    cluster:
+      dnsLookupFamily: v5
+`,
+			want: []configProblem{{
+				Severity: severityError,
+				Message:  `invalid cluster dns family type "v5"`,
+			}},
+		},
+		"ContourConfiguration unknown field": {
+			filename: "contourconfig.yaml",
+			content: `
+apiVersion: projectcontour.io/v1alpha1
+kind: ContourConfiguration
+metadata:
+  name: contour
+spec:
+  foo: bar
+`,
+			want: []configProblem{{
+				Severity: severityError,
+				Message:  `error unmarshaling JSON: while decoding JSON: json: unknown field "foo"`,
+			}},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tc.filename)
+			require.NoError(t, os.WriteFile(path, []byte(tc.content), 0600))
+
+			assert.Equal(t, tc.want, validateConfig(path))
+		})
+	}
+}
+
+func TestDoValidateConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "contour.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("request-timeout: 30s\naccesslog-format: xml\n"), 0600))
+
+	var out bytes.Buffer
+	assert.False(t, doValidateConfig(&validateConfigContext{path: path, output: "text"}, &out))
+	assert.Equal(t,
+		path+`:1: warning: request-timeout: request-timeout is deprecated and was converted to timeouts.request-timeout
+`+path+`:2: error: accesslog-format: invalid access log format "xml"
+`, out.String())
+
+	out.Reset()
+	assert.False(t, doValidateConfig(&validateConfigContext{path: path, output: "json"}, &out))
+
+	var result configValidation
+	require.NoError(t, json.Unmarshal(out.Bytes(), &result))
+	assert.False(t, result.Valid)
+	assert.Len(t, result.Problems, 2)
+
+	require.NoError(t, os.WriteFile(path, []byte("timeouts:\n  request-timeout: 30s\n"), 0600))
+
+	out.Reset()
+	assert.True(t, doValidateConfig(&validateConfigContext{path: path, output: "text"}, &out))
+	assert.Equal(t, path+": valid\n", out.String())
+}
@@ -0,0 +1,42 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build none
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/projectcontour/contour/internal/dag"
+)
+
+// Writes the Gateway API conformance features that Contour supports
+// to the file named by the first argument, one per line.
+func main() {
+	if len(os.Args) != 2 {
+		log.Fatalf("usage: %s <output file>", os.Args[0])
+	}
+
+	f, err := os.Create(os.Args[1])
+	if err != nil {
+		log.Fatalf("%s", err)
+	}
+	defer f.Close()
+
+	for _, feature := range dag.SupportedGatewayAPIFeatures() {
+		fmt.Fprintln(f, feature)
+	}
+}
@@ -75,6 +75,9 @@ var annotationsByKind = map[string]map[string]struct{}{
 	"Secret": {
 		"projectcontour.io/generated-by-version": {},
 	},
+	"Namespace": {
+		"projectcontour.io/default-ingress-class": {},
+	},
 }
 
 // ValidForKind checks if a particular annotation is valid for a given Kind.
@@ -209,6 +212,17 @@ func IngressClass(o metav1.Object) string {
 	return ""
 }
 
+// DefaultIngressClass returns the default ingress class of the HTTPProxies
+// of the supplied Namespace, which is the value of its
+// projectcontour.io/default-ingress-class annotation or, if it isn't
+// annotated, label.
+func DefaultIngressClass(ns metav1.Object) string {
+	if class, ok := ns.GetAnnotations()["projectcontour.io/default-ingress-class"]; ok {
+		return class
+	}
+	return ns.GetLabels()["projectcontour.io/default-ingress-class"]
+}
+
 // MinTLSVersion returns the TLS protocol version specified by an ingress annotation
 // or default if non present.
 func MinTLSVersion(version string, defaultVal string) string {
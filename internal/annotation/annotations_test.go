@@ -389,6 +389,49 @@ func TestHttpAllowed(t *testing.T) {
 	}
 }
 
+func TestDefaultIngressClass(t *testing.T) {
+	tests := map[string]struct {
+		ns   *v1.Namespace
+		want string
+	}{
+		"no default": {
+			ns:   &v1.Namespace{},
+			want: "",
+		},
+		"annotation": {
+			ns: &v1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{"projectcontour.io/default-ingress-class": "internal"},
+				},
+			},
+			want: "internal",
+		},
+		"label": {
+			ns: &v1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"projectcontour.io/default-ingress-class": "internal"},
+				},
+			},
+			want: "internal",
+		},
+		"annotation takes precedence over label": {
+			ns: &v1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{"projectcontour.io/default-ingress-class": "internal"},
+					Labels:      map[string]string{"projectcontour.io/default-ingress-class": "external"},
+				},
+			},
+			want: "internal",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, DefaultIngressClass(tc.ns))
+		})
+	}
+}
+
 func TestAnnotationCompat(t *testing.T) {
 	tests := map[string]struct {
 		svc   *v1.Service
@@ -465,9 +508,12 @@ func TestAnnotationKindValidation(t *testing.T) {
 				"projectcontour.io/ingress.class": {
 					known: true, valid: false,
 				},
-				// Unknown, so potentially valid.
+				"projectcontour.io/default-ingress-class": {
+					known: true, valid: true,
+				},
+				// Unknown, so not validated.
 				"foo.io/secret-sauce": {
-					known: false, valid: true,
+					known: false, valid: false,
 				},
 			},
 		},
@@ -479,6 +525,7 @@ func TestAnnotationKindValidation(t *testing.T) {
 		kindOf(&v1.Service{}),
 		kindOf(&networking_v1.Ingress{}),
 		kindOf(&contour_api_v1.HTTPProxy{}),
+		kindOf(&v1.Namespace{}),
 	} {
 		for key := range annotationsByKind[kind] {
 			t.Run(fmt.Sprintf("%s is known and valid for %s", key, kind),
@@ -539,6 +586,8 @@ func kindOf(obj any) string {
 		return "HTTPProxy"
 	case *contour_api_v1.TLSCertificateDelegation:
 		return "TLSCertificateDelegation"
+	case *v1.Namespace:
+		return "Namespace"
 	default:
 		return ""
 	}
@@ -0,0 +1,52 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package certsan checks the subject alternative names of the
+// client certificates presented to Contour's TLS servers.
+package certsan
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+)
+
+// Verify checks that the verified client certificate of the
+// connection has one of the allowed DNS name, email address,
+// IP address or URI SANs.
+func Verify(cs tls.ConnectionState, allowed []string) error {
+	if len(cs.VerifiedChains) == 0 || len(cs.VerifiedChains[0]) == 0 {
+		return errors.New("no verified client certificate")
+	}
+
+	leaf := cs.VerifiedChains[0][0]
+
+	sans := append([]string{}, leaf.DNSNames...)
+	sans = append(sans, leaf.EmailAddresses...)
+	for _, ip := range leaf.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	for _, uri := range leaf.URIs {
+		sans = append(sans, uri.String())
+	}
+
+	for _, san := range sans {
+		for _, a := range allowed {
+			if san == a {
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("client certificate SANs %q are not allowed", sans)
+}
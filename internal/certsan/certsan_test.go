@@ -0,0 +1,81 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package certsan
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerify(t *testing.T) {
+	spiffe, err := url.Parse("spiffe://cluster.local/ns/projectcontour/sa/envoy")
+	require.NoError(t, err)
+
+	leaf := &x509.Certificate{
+		DNSNames:       []string{"envoy"},
+		EmailAddresses: []string{"envoy@projectcontour.io"},
+		IPAddresses:    []net.IP{net.ParseIP("10.0.0.1")},
+		URIs:           []*url.URL{spiffe},
+	}
+	verified := tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{leaf}}}
+
+	tests := map[string]struct {
+		cs      tls.ConnectionState
+		allowed []string
+		wantErr string
+	}{
+		"DNS name": {
+			cs:      verified,
+			allowed: []string{"envoy"},
+		},
+		"email address": {
+			cs:      verified,
+			allowed: []string{"envoy@projectcontour.io"},
+		},
+		"IP address": {
+			cs:      verified,
+			allowed: []string{"10.0.0.1"},
+		},
+		"URI": {
+			cs:      verified,
+			allowed: []string{"prometheus", spiffe.String()},
+		},
+		"no allowed SAN": {
+			cs:      verified,
+			allowed: []string{"prometheus"},
+			wantErr: `client certificate SANs ["envoy" "envoy@projectcontour.io" "10.0.0.1" "spiffe://cluster.local/ns/projectcontour/sa/envoy"] are not allowed`,
+		},
+		"no verified client certificate": {
+			allowed: []string{"envoy"},
+			wantErr: "no verified client certificate",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := Verify(tc.cs, tc.allowed)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contour
+
+import (
+	"github.com/projectcontour/contour/internal/dag"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+)
+
+// DeprecatedFeatureReason is the reason of the events recorded
+// on the objects that use a deprecated feature.
+const DeprecatedFeatureReason = "DeprecatedFeature"
+
+// DeprecationReporter is a dag.Observer that reports the objects
+// that start using a deprecated feature, by logging a warning and
+// recording a Warning event on them, once this Contour is elected
+// leader.
+type DeprecationReporter struct {
+	log      logrus.FieldLogger
+	recorder record.EventRecorder
+
+	// leader will become ready to read when this reporter becomes the leader.
+	leader chan struct{}
+
+	// reported holds the objects reported to use each deprecated
+	// feature, by UID, as of the last DAG rebuild.
+	reported map[deprecatedUse]struct{}
+}
+
+type deprecatedUse struct {
+	feature string
+	uid     types.UID
+}
+
+// NewDeprecationReporter returns a DeprecationReporter that
+// records events with the supplied recorder.
+func NewDeprecationReporter(log logrus.FieldLogger, recorder record.EventRecorder) *DeprecationReporter {
+	return &DeprecationReporter{
+		log:      log,
+		recorder: recorder,
+		leader:   make(chan struct{}),
+		reported: map[deprecatedUse]struct{}{},
+	}
+}
+
+func (r *DeprecationReporter) OnElectedLeader() {
+	close(r.leader)
+}
+
+// OnChange reports the objects of the DAG that use a deprecated
+// feature that they didn't use as of the previous DAG rebuild.
+func (r *DeprecationReporter) OnChange(d *dag.DAG) {
+	select {
+	case <-r.leader:
+	default:
+		return
+	}
+
+	reported := map[deprecatedUse]struct{}{}
+	for feature, objects := range d.DeprecatedFeatures {
+		for _, obj := range objects {
+			use := deprecatedUse{feature: feature, uid: obj.GetUID()}
+			reported[use] = struct{}{}
+			if _, ok := r.reported[use]; ok {
+				continue
+			}
+
+			replacement := dag.DeprecatedFeatureReplacements[feature]
+
+			r.log.WithField("feature", feature).
+				WithField("replacement", replacement).
+				WithField("namespace", obj.GetNamespace()).
+				WithField("name", obj.GetName()).
+				Warn("object uses a deprecated feature")
+
+			r.recorder.Eventf(obj, corev1.EventTypeWarning, DeprecatedFeatureReason,
+				"%s is deprecated and will be removed in a future release, use %s instead", feature, replacement)
+		}
+	}
+
+	r.reported = reported
+}
@@ -0,0 +1,83 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contour
+
+import (
+	"testing"
+
+	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
+	"github.com/projectcontour/contour/internal/dag"
+	"github.com/projectcontour/contour/internal/fixture"
+	"github.com/stretchr/testify/assert"
+	networking_v1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestDeprecationReporter(t *testing.T) {
+	proxy := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "proxy", UID: "proxy-uid"},
+	}
+	ingress := &networking_v1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "ingress", UID: "ingress-uid"},
+	}
+
+	dagWith := func(objects map[string][]client.Object) *dag.DAG {
+		return &dag.DAG{DeprecatedFeatures: objects}
+	}
+
+	recorder := record.NewFakeRecorder(10)
+	r := NewDeprecationReporter(fixture.NewTestLogger(t), recorder)
+
+	events := func() []string {
+		var got []string
+		for {
+			select {
+			case e := <-recorder.Events:
+				got = append(got, e)
+			default:
+				return got
+			}
+		}
+	}
+
+	// Nothing is reported until elected leader.
+	r.OnChange(dagWith(map[string][]client.Object{dag.DeprecatedTCPProxyIncludes: {proxy}}))
+	assert.Empty(t, events())
+
+	r.OnElectedLeader()
+
+	r.OnChange(dagWith(map[string][]client.Object{dag.DeprecatedTCPProxyIncludes: {proxy}}))
+	assert.Equal(t, []string{
+		"Warning DeprecatedFeature httpproxy:spec.tcpproxy.includes is deprecated and will be removed in a future release, use spec.tcpproxy.include instead",
+	}, events())
+
+	// Objects are reported once while they use the feature.
+	r.OnChange(dagWith(map[string][]client.Object{
+		dag.DeprecatedTCPProxyIncludes:       {proxy},
+		dag.DeprecatedIngressClassAnnotation: {ingress},
+	}))
+	assert.Equal(t, []string{
+		"Warning DeprecatedFeature ingress:kubernetes.io/ingress.class is deprecated and will be removed in a future release, use spec.ingressClassName instead",
+	}, events())
+
+	// Objects that stop and start using the feature again
+	// are reported again.
+	r.OnChange(dagWith(nil))
+	assert.Empty(t, events())
+
+	r.OnChange(dagWith(map[string][]client.Object{dag.DeprecatedTCPProxyIncludes: {proxy}}))
+	assert.Len(t, events(), 1)
+}
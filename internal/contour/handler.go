@@ -24,6 +24,7 @@ import (
 	"github.com/projectcontour/contour/internal/dag"
 	"github.com/projectcontour/contour/internal/k8s"
 	"github.com/sirupsen/logrus"
+	"k8s.io/client-go/tools/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -33,22 +34,32 @@ type EventHandlerConfig struct {
 	Observer                      dag.Observer
 	HoldoffDelay, HoldoffMaxDelay time.Duration
 	StatusUpdater                 k8s.StatusUpdater
+
+	// EndpointsHandler, if set, receives the events for
+	// Endpoints and Pods directly, without a DAG rebuild.
+	EndpointsHandler cache.ResourceEventHandler
 }
 
 // EventHandler implements cache.ResourceEventHandler, filters k8s events towards
 // a dag.Builder and calls through to the Observer to notify it that a new DAG
-// is available.
+// is available. Endpoints events are sent straight to the endpoints handler.
+// Other events are queued, and a change to routes or certificates is rebuilt
+// within the holdoff delay, however many other events follow it.
 type EventHandler struct {
 	builder  *dag.Builder
 	observer dag.Observer
 
+	endpoints cache.ResourceEventHandler
+
 	holdoffDelay, holdoffMaxDelay time.Duration
 
 	statusUpdater k8s.StatusUpdater
 
 	logrus.FieldLogger
 
-	update chan any
+	// queue holds the events received but not yet
+	// applied to the builder's cache.
+	queue *eventQueue
 
 	sequence chan int
 
@@ -65,7 +76,8 @@ func NewEventHandler(config EventHandlerConfig) *EventHandler {
 		holdoffDelay:    config.HoldoffDelay,
 		holdoffMaxDelay: config.HoldoffMaxDelay,
 		statusUpdater:   config.StatusUpdater,
-		update:          make(chan any),
+		endpoints:       config.EndpointsHandler,
+		queue:           newEventQueue(maxQueuedEvents),
 		sequence:        make(chan int, 1),
 	}
 }
@@ -82,16 +94,50 @@ type opDelete struct {
 	obj any
 }
 
+type opReconfigure struct {
+	apply func()
+}
+
 func (e *EventHandler) OnAdd(obj any, isInInitialList bool) {
-	e.update <- opAdd{obj: obj}
+	e.enqueue(opAdd{obj: obj})
 }
 
 func (e *EventHandler) OnUpdate(oldObj, newObj any) {
-	e.update <- opUpdate{oldObj: oldObj, newObj: newObj}
+	e.enqueue(opUpdate{oldObj: oldObj, newObj: newObj})
 }
 
 func (e *EventHandler) OnDelete(obj any) {
-	e.update <- opDelete{obj: obj}
+	e.enqueue(opDelete{obj: obj})
+}
+
+// Reconfigure queues apply to be called by the event loop, between
+// DAG rebuilds, followed by a DAG rebuild. apply may change the
+// configuration of the builder and of the DAG observers.
+func (e *EventHandler) Reconfigure(apply func()) {
+	e.enqueue(opReconfigure{apply: apply})
+}
+
+// enqueue queues op to be processed by the event loop,
+// or sends it to the endpoints handler if it only changes
+// endpoints.
+func (e *EventHandler) enqueue(op any) {
+	priority := priorityOf(op)
+	if priority != priorityEndpoints {
+		e.queue.push(priority, op)
+		return
+	}
+	if e.endpoints == nil {
+		return
+	}
+
+	switch op := op.(type) {
+	case opAdd:
+		e.endpoints.OnAdd(op.obj, false)
+	case opUpdate:
+		e.endpoints.OnUpdate(op.oldObj, op.newObj)
+	case opDelete:
+		e.endpoints.OnDelete(op.obj)
+	}
 }
 
 func (e *EventHandler) NeedLeaderElection() bool {
@@ -102,7 +148,7 @@ func (e *EventHandler) NeedLeaderElection() bool {
 func (e *EventHandler) OnElectedLeader() {
 	// Trigger an update when we are elected leader to ensure resource
 	// statuses are not stale.
-	e.update <- true
+	e.enqueue(true)
 }
 
 func (e *EventHandler) Start(ctx context.Context) error {
@@ -115,18 +161,61 @@ func (e *EventHandler) Start(ctx context.Context) error {
 		outstanding int
 
 		// timer holds the timer which will expire after e.HoldoffDelay
+		// with no further events.
 		timer *time.Timer
 
 		// pending is a reference to the current timer's channel.
 		pending <-chan time.Time
 
+		// deadlineTimer holds the timer which will expire after
+		// e.HoldoffDelay from the first change to routes or
+		// certificates since the last DAG rebuild. Unlike timer,
+		// it is not reset by further events.
+		deadlineTimer *time.Timer
+
+		// deadline is a reference to the current deadline timer's channel.
+		deadline <-chan time.Time
+
 		// lastDAGRebuild holds the last time rebuildDAG was called.
 		// lastDAGRebuild is seeded to the current time on entry to
 		// run to allow the holdoff timer to batch the updates from
 		// the API informers.
 		lastDAGRebuild = time.Now()
+
+		// scheduleTimer holds the timer which will expire when a
+		// route schedule of the last DAG changes.
+		scheduleTimer *time.Timer
+
+		// scheduled is a reference to the current schedule timer's channel.
+		scheduled <-chan time.Time
 	)
 
+	// rebuild builds a new DAG, and arms the schedule timer
+	// to rebuild it again when a route schedule changes.
+	rebuild := func() {
+		if timer != nil {
+			timer.Stop()
+			timer, pending = nil, nil
+		}
+		if deadlineTimer != nil {
+			deadlineTimer.Stop()
+			deadlineTimer, deadline = nil, nil
+		}
+
+		next := e.rebuildDAG()
+		e.incSequence()
+		lastDAGRebuild = time.Now()
+
+		if scheduleTimer != nil {
+			scheduleTimer.Stop()
+			scheduleTimer, scheduled = nil, nil
+		}
+		if !next.IsZero() {
+			scheduleTimer = time.NewTimer(time.Until(next))
+			scheduled = scheduleTimer.C
+		}
+	}
+
 	reset := func() (v int) {
 		v, outstanding = outstanding, 0
 		return
@@ -134,41 +223,64 @@ func (e *EventHandler) Start(ctx context.Context) error {
 
 	for {
 		// In the main loop one of four things can happen.
-		// 1. We're waiting for an event on op, stop, or pending, noting that
-		//    pending may be nil if there are no pending events.
-		// 2. We're processing an event.
-		// 3. The holdoff timer from a previous event has fired and we're
-		//    building a new DAG and sending to the Observer.
+		// 1. We're waiting for events on the queue, stop, pending, deadline
+		//    or scheduled, noting that the timers may be nil if there are
+		//    no pending events or route schedule changes.
+		// 2. We're processing the queued events, highest priority first.
+		// 3. The holdoff or deadline timer from previous events, or the
+		//    schedule timer, has fired and we're building a new DAG and
+		//    sending to the Observer.
 		// 4. We're stopping.
 		//
 		// Only one of these things can happen at a time.
 		select {
-		case op := <-e.update:
-			if e.onUpdate(op) {
-				outstanding++
-				// If there is already a timer running, stop it.
-				if timer != nil {
-					timer.Stop()
+		case <-e.queue.ready:
+			var changed [numPriorities]bool
+			for priority, ops := range e.queue.drain() {
+				for _, op := range ops {
+					if e.onUpdate(op) {
+						outstanding++
+						changed[priority] = true
+					} else {
+						// notify any watchers that we received the event but chose
+						// not to process it.
+						e.incSequence()
+					}
 				}
+			}
+			if !changed[priorityHigh] && !changed[priorityNormal] {
+				continue
+			}
 
-				delay := e.holdoffDelay
-				if time.Since(lastDAGRebuild) > e.holdoffMaxDelay {
-					// the maximum holdoff delay has been exceeded so schedule the update
-					// immediately by delaying for 0ns.
-					delay = 0
-				}
-				timer = time.NewTimer(delay)
-				pending = timer.C
-			} else {
-				// notify any watchers that we received the event but chose
-				// not to process it.
-				e.incSequence()
+			delay := e.holdoffDelay
+			if time.Since(lastDAGRebuild) > e.holdoffMaxDelay {
+				// the maximum holdoff delay has been exceeded so schedule the update
+				// immediately by delaying for 0ns.
+				delay = 0
 			}
+
+			// A change to routes or certificates is rebuilt within the
+			// holdoff delay, however many other events follow it.
+			if changed[priorityHigh] && deadlineTimer == nil {
+				deadlineTimer = time.NewTimer(delay)
+				deadline = deadlineTimer.C
+			}
+
+			// If there is already a timer running, stop it.
+			if timer != nil {
+				timer.Stop()
+			}
+			timer = time.NewTimer(delay)
+			pending = timer.C
 		case <-pending:
 			e.WithField("last_update", time.Since(lastDAGRebuild)).WithField("outstanding", reset()).Info("performing delayed update")
-			e.rebuildDAG()
-			e.incSequence()
-			lastDAGRebuild = time.Now()
+			rebuild()
+		case <-deadline:
+			e.WithField("last_update", time.Since(lastDAGRebuild)).WithField("outstanding", reset()).Info("performing prioritized update")
+			rebuild()
+		case <-scheduled:
+			e.WithField("last_update", time.Since(lastDAGRebuild)).Info("performing scheduled update")
+			rebuild()
 		case <-ctx.Done():
 			// shutdown
 			return nil
@@ -211,6 +323,9 @@ func (e *EventHandler) onUpdate(op any) bool {
 		return false
 	case opDelete:
 		return e.builder.Source.Remove(op.obj)
+	case opReconfigure:
+		op.apply()
+		return true
 	case bool:
 		return op
 	default:
@@ -240,11 +355,15 @@ func (e *EventHandler) incSequence() {
 
 // rebuildDAG builds a new DAG and sends it to the Observer,
 // the updates the status on objects, and updates the metrics.
-func (e *EventHandler) rebuildDAG() {
+// It returns the next time at which a route schedule of the
+// new DAG changes, or the zero time if none does.
+func (e *EventHandler) rebuildDAG() time.Time {
 	latestDAG := e.builder.Build()
 	e.observer.OnChange(latestDAG)
 
 	for _, upd := range latestDAG.StatusCache.GetStatusUpdates() {
 		e.statusUpdater.Send(upd)
 	}
+
+	return latestDAG.NextScheduleChange
 }
@@ -25,7 +25,9 @@ import (
 	"github.com/projectcontour/contour/internal/metrics"
 	"github.com/projectcontour/contour/internal/status"
 	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // EventRecorder records the count and kind of events forwarded
@@ -92,6 +94,9 @@ func (m *RebuildMetricsObserver) OnChange(d *dag.DAG) {
 	m.nextObserver.OnChange(d)
 	timer.ObserveDuration()
 
+	m.metrics.SetDelegatedSecretMetric(calculateDelegatedSecretMetric(d.DelegatedSecrets))
+	m.metrics.SetDeprecatedFeatureMetric(calculateDeprecatedFeatureMetric(d.DeprecatedFeatures))
+
 	select {
 	case <-m.httpProxyMetricsEnabled:
 		m.metrics.SetHTTPProxyMetric(calculateRouteMetric(d.StatusCache.GetProxyUpdates()))
@@ -105,12 +110,16 @@ func calculateRouteMetric(updates []*status.ProxyUpdate) metrics.RouteMetric {
 	proxyMetricInvalid := make(map[metrics.Meta]int)
 	proxyMetricOrphaned := make(map[metrics.Meta]int)
 	proxyMetricRoots := make(map[metrics.Meta]int)
+	proxyMetricOrphanedChildren := make(map[metrics.Meta]int)
 
 	for _, u := range updates {
 		calcMetrics(u, proxyMetricValid, proxyMetricInvalid, proxyMetricOrphaned, proxyMetricTotal)
 		if u.Vhost != "" {
 			proxyMetricRoots[metrics.Meta{VHost: u.Vhost, Namespace: u.Fullname.Namespace}]++
 		}
+		if _, ok := u.Conditions[status.OrphanedCondition]; ok {
+			proxyMetricOrphanedChildren[metrics.Meta{Namespace: u.Fullname.Namespace, Name: u.Fullname.Name}] = 1
+		}
 	}
 
 	return metrics.RouteMetric{
@@ -119,7 +128,29 @@ func calculateRouteMetric(updates []*status.ProxyUpdate) metrics.RouteMetric {
 		Orphaned: proxyMetricOrphaned,
 		Total:    proxyMetricTotal,
 		Root:     proxyMetricRoots,
+
+		OrphanedChildren: proxyMetricOrphanedChildren,
+	}
+}
+
+func calculateDelegatedSecretMetric(secrets map[types.NamespacedName][]types.NamespacedName) map[metrics.SecretUsageMeta]int {
+	usage := make(map[metrics.SecretUsageMeta]int)
+	for secret, proxies := range secrets {
+		for _, proxy := range proxies {
+			usage[metrics.SecretUsageMeta{SecretNamespace: secret.Namespace, SecretName: secret.Name, Namespace: proxy.Namespace}]++
+		}
+	}
+	return usage
+}
+
+func calculateDeprecatedFeatureMetric(features map[string][]client.Object) map[metrics.DeprecatedFeatureMeta]int {
+	usage := make(map[metrics.DeprecatedFeatureMeta]int)
+	for feature, objects := range features {
+		for _, obj := range objects {
+			usage[metrics.DeprecatedFeatureMeta{Feature: feature, Namespace: obj.GetNamespace()}]++
+		}
 	}
+	return usage
 }
 
 func calcMetrics(u *status.ProxyUpdate, metricValid map[metrics.Meta]int, metricInvalid map[metrics.Meta]int, metricOrphaned map[metrics.Meta]int, metricTotal map[metrics.Meta]int) {
@@ -22,6 +22,7 @@ import (
 	"github.com/projectcontour/contour/internal/metrics"
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
+	networking_v1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -339,6 +340,7 @@ func TestHTTPProxyMetrics(t *testing.T) {
 			Total: map[metrics.Meta]int{
 				{Namespace: "roots"}: 1,
 			},
+			OrphanedChildren: map[metrics.Meta]int{},
 		},
 	})
 
@@ -357,6 +359,7 @@ func TestHTTPProxyMetrics(t *testing.T) {
 			Total: map[metrics.Meta]int{
 				{Namespace: "roots"}: 1,
 			},
+			OrphanedChildren: map[metrics.Meta]int{},
 		},
 	})
 
@@ -375,6 +378,7 @@ func TestHTTPProxyMetrics(t *testing.T) {
 			Total: map[metrics.Meta]int{
 				{Namespace: "finance"}: 1,
 			},
+			OrphanedChildren: map[metrics.Meta]int{},
 		},
 		rootNamespaces: []string{"foo"},
 	})
@@ -392,6 +396,7 @@ func TestHTTPProxyMetrics(t *testing.T) {
 			Total: map[metrics.Meta]int{
 				{Namespace: "roots"}: 1,
 			},
+			OrphanedChildren: map[metrics.Meta]int{},
 		},
 	})
 
@@ -410,6 +415,7 @@ func TestHTTPProxyMetrics(t *testing.T) {
 			Total: map[metrics.Meta]int{
 				{Namespace: "roots"}: 1,
 			},
+			OrphanedChildren: map[metrics.Meta]int{},
 		},
 	})
 
@@ -430,6 +436,7 @@ func TestHTTPProxyMetrics(t *testing.T) {
 			Total: map[metrics.Meta]int{
 				{Namespace: "roots"}: 2,
 			},
+			OrphanedChildren: map[metrics.Meta]int{},
 		},
 	})
 
@@ -446,6 +453,9 @@ func TestHTTPProxyMetrics(t *testing.T) {
 			Total: map[metrics.Meta]int{
 				{Namespace: "roots"}: 1,
 			},
+			OrphanedChildren: map[metrics.Meta]int{
+				{Namespace: "roots", Name: "child"}: 1,
+			},
 		},
 	})
 
@@ -467,6 +477,7 @@ func TestHTTPProxyMetrics(t *testing.T) {
 			Total: map[metrics.Meta]int{
 				{Namespace: "roots"}: 3,
 			},
+			OrphanedChildren: map[metrics.Meta]int{},
 		},
 	})
 
@@ -485,6 +496,9 @@ func TestHTTPProxyMetrics(t *testing.T) {
 			Total: map[metrics.Meta]int{
 				{Namespace: "roots"}: 2,
 			},
+			OrphanedChildren: map[metrics.Meta]int{
+				{Namespace: "roots", Name: "validChild"}: 1,
+			},
 		},
 	})
 
@@ -506,6 +520,135 @@ func TestHTTPProxyMetrics(t *testing.T) {
 			Total: map[metrics.Meta]int{
 				{Namespace: "roots"}: 3,
 			},
+			OrphanedChildren: map[metrics.Meta]int{},
 		},
 	})
 }
+
+func TestDelegatedSecretMetrics(t *testing.T) {
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "wildcard",
+			Namespace: "certs",
+		},
+		Type: v1.SecretTypeTLS,
+		Data: map[string][]byte{
+			v1.TLSCertKey:       []byte(fixture.CERTIFICATE),
+			v1.TLSPrivateKeyKey: []byte(fixture.RSA_PRIVATE_KEY),
+		},
+	}
+
+	delegation := &contour_api_v1.TLSCertificateDelegation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "wildcard",
+			Namespace: secret.Namespace,
+		},
+		Spec: contour_api_v1.TLSCertificateDelegationSpec{
+			Delegations: []contour_api_v1.CertificateDelegation{{
+				SecretName:       secret.Name,
+				TargetNamespaces: []string{"*"},
+			}},
+		},
+	}
+
+	proxy := func(namespace, name string) *contour_api_v1.HTTPProxy {
+		return &contour_api_v1.HTTPProxy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Spec: contour_api_v1.HTTPProxySpec{
+				VirtualHost: &contour_api_v1.VirtualHost{
+					Fqdn: name + "." + namespace + ".example.com",
+					TLS: &contour_api_v1.TLS{
+						SecretName: secret.Namespace + "/" + secret.Name,
+					},
+				},
+				Routes: []contour_api_v1.Route{{
+					Services: []contour_api_v1.Service{{
+						Name: "home",
+						Port: 8080,
+					}},
+				}},
+			},
+		}
+	}
+
+	builder := dag.Builder{
+		Source: dag.KubernetesCache{
+			FieldLogger: fixture.NewTestLogger(t),
+		},
+		Processors: []dag.Processor{
+			&dag.ListenerProcessor{},
+			&dag.HTTPProxyProcessor{},
+		},
+	}
+	for _, o := range []any{
+		secret,
+		delegation,
+		proxy("marketing", "blog"),
+		proxy("marketing", "shop"),
+		proxy("teama", "app"),
+	} {
+		builder.Source.Insert(o)
+	}
+
+	assert.Equal(t, map[metrics.SecretUsageMeta]int{
+		{SecretNamespace: "certs", SecretName: "wildcard", Namespace: "marketing"}: 2,
+		{SecretNamespace: "certs", SecretName: "wildcard", Namespace: "teama"}:     1,
+	}, calculateDelegatedSecretMetric(builder.Build().DelegatedSecrets))
+}
+
+func TestCalculateDeprecatedFeatureMetric(t *testing.T) {
+	proxy := func(namespace, name string, includes *contour_api_v1.TCPProxyInclude) *contour_api_v1.HTTPProxy {
+		return &contour_api_v1.HTTPProxy{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec: contour_api_v1.HTTPProxySpec{
+				VirtualHost: &contour_api_v1.VirtualHost{
+					Fqdn: name + ".example.com",
+					TLS: &contour_api_v1.TLS{
+						Passthrough: true,
+					},
+				},
+				TCPProxy: &contour_api_v1.TCPProxy{
+					IncludesDeprecated: includes,
+					Include:            nil,
+				},
+			},
+		}
+	}
+
+	builder := dag.Builder{
+		Source: dag.KubernetesCache{
+			FieldLogger: fixture.NewTestLogger(t),
+		},
+		Processors: []dag.Processor{
+			&dag.ListenerProcessor{},
+			&dag.IngressProcessor{
+				FieldLogger: fixture.NewTestLogger(t),
+			},
+			&dag.HTTPProxyProcessor{},
+		},
+	}
+	for _, o := range []any{
+		&networking_v1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "teama",
+				Name:      "ingress",
+				Annotations: map[string]string{
+					"kubernetes.io/ingress.class": "contour",
+				},
+			},
+		},
+		proxy("marketing", "blog", &contour_api_v1.TCPProxyInclude{Name: "backend"}),
+		proxy("marketing", "shop", &contour_api_v1.TCPProxyInclude{Name: "backend"}),
+		proxy("teama", "app", nil),
+	} {
+		builder.Source.Insert(o)
+	}
+
+	assert.Equal(t, map[metrics.DeprecatedFeatureMeta]int{
+		{Feature: dag.DeprecatedTCPProxyIncludes, Namespace: "marketing"}:   2,
+		{Feature: dag.DeprecatedIngressClassAnnotation, Namespace: "teama"}: 1,
+	}, calculateDeprecatedFeatureMetric(builder.Build().DeprecatedFeatures))
+}
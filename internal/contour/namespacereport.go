@@ -0,0 +1,269 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contour
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
+	contour_api_v1alpha1 "github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
+	"github.com/projectcontour/contour/internal/dag"
+	"github.com/projectcontour/contour/internal/status"
+	"github.com/sirupsen/logrus"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// NamespaceReportWriter is a dag.Observer that maintains a NamespaceReport
+// in each namespace containing HTTPProxies. Reports are computed on every
+// DAG rebuild and written back to the API server by a separate goroutine
+// once this Contour is elected leader.
+type NamespaceReportWriter struct {
+	log    logrus.FieldLogger
+	client client.Client
+
+	// leader will become ready to read when this writer becomes the leader.
+	leader chan struct{}
+
+	// mu serializes queueing reports and guards latest.
+	mu sync.Mutex
+
+	// latest holds the reports calculated from the last DAG, so that
+	// they can be written as soon as this writer becomes the leader.
+	latest map[string]*contour_api_v1alpha1.NamespaceReportStatus
+
+	// reports holds the most recently calculated set of reports that
+	// has not yet been written.
+	reports chan map[string]*contour_api_v1alpha1.NamespaceReportStatus
+
+	// written holds the reports last written to each namespace.
+	written map[string]*contour_api_v1alpha1.NamespaceReportStatus
+}
+
+// NewNamespaceReportWriter returns a NamespaceReportWriter that writes
+// NamespaceReports using the supplied client.
+func NewNamespaceReportWriter(log logrus.FieldLogger, client client.Client) *NamespaceReportWriter {
+	return &NamespaceReportWriter{
+		log:     log,
+		client:  client,
+		leader:  make(chan struct{}),
+		reports: make(chan map[string]*contour_api_v1alpha1.NamespaceReportStatus, 1),
+		written: map[string]*contour_api_v1alpha1.NamespaceReportStatus{},
+	}
+}
+
+// OnElectedLeader queues the reports of the last DAG, so that they are
+// written without waiting for the DAG to change.
+func (w *NamespaceReportWriter) OnElectedLeader() {
+	close(w.leader)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.latest != nil {
+		w.queue(w.latest)
+	}
+}
+
+// OnChange calculates the NamespaceReports for the DAG and, once this
+// writer is the leader, queues them for writing.
+func (w *NamespaceReportWriter) OnChange(d *dag.DAG) {
+	reports := calculateNamespaceReports(d.StatusCache.GetProxyUpdates())
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.latest = reports
+
+	select {
+	case <-w.leader:
+		w.queue(reports)
+	default:
+	}
+}
+
+// queue queues reports for writing, replacing any reports that have not
+// been written yet. It must be called with w.mu held.
+func (w *NamespaceReportWriter) queue(reports map[string]*contour_api_v1alpha1.NamespaceReportStatus) {
+	// Drop any pending reports since they are superseded by these ones.
+	select {
+	case <-w.reports:
+	default:
+	}
+	w.reports <- reports
+}
+
+// NeedLeaderElection is true since only the leader writes NamespaceReports.
+func (w *NamespaceReportWriter) NeedLeaderElection() bool {
+	return true
+}
+
+// Start writes queued NamespaceReports until the context is done.
+func (w *NamespaceReportWriter) Start(ctx context.Context) error {
+	w.log.Info("started namespace report writer")
+	defer w.log.Info("stopped namespace report writer")
+
+	if err := w.resync(ctx); err != nil {
+		w.log.WithError(err).Error("unable to list namespace reports")
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case reports := <-w.reports:
+			w.write(ctx, reports)
+		}
+	}
+}
+
+// resync replaces the reports last written with the reports in the API
+// server, which may have been written by a previous leader, so that the
+// reports of namespaces that no longer contain HTTPProxies are reset.
+func (w *NamespaceReportWriter) resync(ctx context.Context) error {
+	var list contour_api_v1alpha1.NamespaceReportList
+	if err := w.client.List(ctx, &list); err != nil {
+		return err
+	}
+
+	w.written = map[string]*contour_api_v1alpha1.NamespaceReportStatus{}
+	for i := range list.Items {
+		report := &list.Items[i]
+		if report.Name != contour_api_v1alpha1.NamespaceReportName {
+			continue
+		}
+		if !apiequality.Semantic.DeepEqual(&report.Status, &contour_api_v1alpha1.NamespaceReportStatus{}) {
+			w.written[report.Namespace] = report.Status.DeepCopy()
+		}
+	}
+	return nil
+}
+
+func (w *NamespaceReportWriter) write(ctx context.Context, reports map[string]*contour_api_v1alpha1.NamespaceReportStatus) {
+	// Namespaces that no longer contain any HTTPProxies have their
+	// report reset rather than left stale.
+	pending := make(map[string]*contour_api_v1alpha1.NamespaceReportStatus, len(reports))
+	for namespace, report := range reports {
+		pending[namespace] = report
+	}
+	for namespace := range w.written {
+		if _, ok := pending[namespace]; !ok {
+			pending[namespace] = &contour_api_v1alpha1.NamespaceReportStatus{}
+		}
+	}
+
+	for namespace, report := range pending {
+		if apiequality.Semantic.DeepEqual(w.written[namespace], report) {
+			continue
+		}
+
+		if err := w.apply(ctx, namespace, report); err != nil {
+			w.log.WithError(err).WithField("namespace", namespace).Error("unable to write namespace report")
+			continue
+		}
+
+		if apiequality.Semantic.DeepEqual(report, &contour_api_v1alpha1.NamespaceReportStatus{}) {
+			delete(w.written, namespace)
+		} else {
+			w.written[namespace] = report
+		}
+	}
+}
+
+func (w *NamespaceReportWriter) apply(ctx context.Context, namespace string, report *contour_api_v1alpha1.NamespaceReportStatus) error {
+	key := types.NamespacedName{Namespace: namespace, Name: contour_api_v1alpha1.NamespaceReportName}
+
+	existing := &contour_api_v1alpha1.NamespaceReport{}
+	if err := w.client.Get(ctx, key, existing); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+
+		existing = &contour_api_v1alpha1.NamespaceReport{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: key.Namespace,
+				Name:      key.Name,
+			},
+		}
+		if err := w.client.Create(ctx, existing); err != nil {
+			return err
+		}
+	}
+
+	if apiequality.Semantic.DeepEqual(&existing.Status, report) {
+		return nil
+	}
+
+	updated := existing.DeepCopy()
+	updated.Status = *report
+	return w.client.Status().Update(ctx, updated)
+}
+
+// calculateNamespaceReports summarizes the supplied HTTPProxy status updates
+// by namespace.
+func calculateNamespaceReports(updates []*status.ProxyUpdate) map[string]*contour_api_v1alpha1.NamespaceReportStatus {
+	reports := map[string]*contour_api_v1alpha1.NamespaceReportStatus{}
+
+	for _, u := range updates {
+		report, ok := reports[u.Fullname.Namespace]
+		if !ok {
+			report = &contour_api_v1alpha1.NamespaceReportStatus{}
+			reports[u.Fullname.Namespace] = report
+		}
+
+		validCond := u.ConditionFor(status.ValidCondition)
+		if validCond.Status == contour_api_v1.ConditionTrue {
+			report.ValidProxies++
+			continue
+		}
+
+		if _, ok := validCond.GetError(contour_api_v1.ConditionTypeOrphanedError); ok {
+			report.OrphanedProxies++
+		} else {
+			report.InvalidProxies++
+		}
+
+		if _, ok := validCond.GetError(contour_api_v1.ConditionTypeTLSError); ok {
+			report.CertificateErrors++
+		}
+
+		problem := contour_api_v1alpha1.NamespaceReportProblem{
+			Kind:    "HTTPProxy",
+			Name:    u.Fullname.Name,
+			Reason:  validCond.Reason,
+			Message: validCond.Message,
+		}
+		if len(validCond.Errors) > 0 {
+			problem.Reason = validCond.Errors[0].Type
+			problem.Message = validCond.Errors[0].Message
+		}
+		report.Problems = append(report.Problems, problem)
+	}
+
+	for _, report := range reports {
+		sort.Slice(report.Problems, func(i, j int) bool {
+			return report.Problems[i].Name < report.Problems[j].Name
+		})
+		if len(report.Problems) > contour_api_v1alpha1.NamespaceReportProblemLimit {
+			report.Problems = report.Problems[:contour_api_v1alpha1.NamespaceReportProblemLimit]
+		}
+	}
+
+	return reports
+}
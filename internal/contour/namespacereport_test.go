@@ -0,0 +1,175 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contour
+
+import (
+	"context"
+	"testing"
+
+	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
+	contour_api_v1alpha1 "github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
+	"github.com/projectcontour/contour/internal/dag"
+	"github.com/projectcontour/contour/internal/fixture"
+	"github.com/projectcontour/contour/internal/k8s"
+	"github.com/projectcontour/contour/internal/status"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func proxyUpdate(namespace, name string, errs ...string) *status.ProxyUpdate {
+	pu := &status.ProxyUpdate{
+		Fullname:   types.NamespacedName{Namespace: namespace, Name: name},
+		Conditions: map[status.ConditionType]*contour_api_v1.DetailedCondition{},
+	}
+
+	cond := pu.ConditionFor(status.ValidCondition)
+	for _, e := range errs {
+		cond.AddError(e, "SomeReason", e+" for "+name)
+	}
+
+	return pu
+}
+
+func TestCalculateNamespaceReports(t *testing.T) {
+	updates := []*status.ProxyUpdate{
+		proxyUpdate("roots", "valid"),
+		proxyUpdate("roots", "tls", contour_api_v1.ConditionTypeTLSError),
+		proxyUpdate("roots", "include", contour_api_v1.ConditionTypeIncludeError),
+		proxyUpdate("team-a", "orphan", contour_api_v1.ConditionTypeOrphanedError),
+		proxyUpdate("team-a", "valid"),
+	}
+
+	want := map[string]*contour_api_v1alpha1.NamespaceReportStatus{
+		"roots": {
+			ValidProxies:      1,
+			InvalidProxies:    2,
+			CertificateErrors: 1,
+			Problems: []contour_api_v1alpha1.NamespaceReportProblem{
+				{Kind: "HTTPProxy", Name: "include", Reason: "IncludeError", Message: "IncludeError for include"},
+				{Kind: "HTTPProxy", Name: "tls", Reason: "TLSError", Message: "TLSError for tls"},
+			},
+		},
+		"team-a": {
+			ValidProxies:    1,
+			OrphanedProxies: 1,
+			Problems: []contour_api_v1alpha1.NamespaceReportProblem{
+				{Kind: "HTTPProxy", Name: "orphan", Reason: "Orphaned", Message: "Orphaned for orphan"},
+			},
+		},
+	}
+
+	assert.Equal(t, want, calculateNamespaceReports(updates))
+}
+
+func TestNamespaceReportWriter(t *testing.T) {
+	scheme, err := k8s.NewContourScheme()
+	require.NoError(t, err)
+
+	client := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&contour_api_v1alpha1.NamespaceReport{}).
+		Build()
+
+	w := NewNamespaceReportWriter(fixture.NewTestLogger(t), client)
+
+	getReport := func(namespace string) contour_api_v1alpha1.NamespaceReportStatus {
+		t.Helper()
+
+		report := &contour_api_v1alpha1.NamespaceReport{}
+		require.NoError(t, client.Get(context.Background(), types.NamespacedName{
+			Namespace: namespace,
+			Name:      contour_api_v1alpha1.NamespaceReportName,
+		}, report))
+
+		return report.Status
+	}
+
+	// Reports are created in namespaces containing HTTPProxies.
+	w.write(context.Background(), calculateNamespaceReports([]*status.ProxyUpdate{
+		proxyUpdate("team-a", "valid"),
+		proxyUpdate("team-b", "orphan", contour_api_v1.ConditionTypeOrphanedError),
+	}))
+
+	assert.Equal(t, contour_api_v1alpha1.NamespaceReportStatus{ValidProxies: 1}, getReport("team-a"))
+	assert.Equal(t, 1, getReport("team-b").OrphanedProxies)
+
+	// Reports are reset once a namespace no longer contains HTTPProxies.
+	w.write(context.Background(), calculateNamespaceReports([]*status.ProxyUpdate{
+		proxyUpdate("team-a", "valid"),
+		proxyUpdate("team-a", "other"),
+	}))
+
+	assert.Equal(t, contour_api_v1alpha1.NamespaceReportStatus{ValidProxies: 2}, getReport("team-a"))
+	assert.Equal(t, contour_api_v1alpha1.NamespaceReportStatus{}, getReport("team-b"))
+	assert.NotContains(t, w.written, "team-b")
+}
+
+func TestNamespaceReportWriterElectedLeader(t *testing.T) {
+	scheme, err := k8s.NewContourScheme()
+	require.NoError(t, err)
+
+	// A report written by a previous leader.
+	stale := &contour_api_v1alpha1.NamespaceReport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "team-old",
+			Name:      contour_api_v1alpha1.NamespaceReportName,
+		},
+		Status: contour_api_v1alpha1.NamespaceReportStatus{ValidProxies: 3},
+	}
+
+	client := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&contour_api_v1alpha1.NamespaceReport{}).
+		WithObjects(stale).
+		Build()
+
+	w := NewNamespaceReportWriter(fixture.NewTestLogger(t), client)
+
+	// Reports are not queued before this writer is the leader.
+	cache := status.NewCache(types.NamespacedName{}, "")
+	proxy := fixture.NewProxy("team-a/valid").WithSpec(contour_api_v1.HTTPProxySpec{})
+	pu, commit := cache.ProxyAccessor(proxy)
+	pu.ConditionFor(status.ValidCondition)
+	commit()
+	w.OnChange(&dag.DAG{StatusCache: cache})
+	assert.Empty(t, w.reports)
+
+	// Once elected, the reports of the last DAG are queued without
+	// waiting for the DAG to change.
+	w.OnElectedLeader()
+	require.Len(t, w.reports, 1)
+
+	require.NoError(t, w.resync(context.Background()))
+	w.write(context.Background(), <-w.reports)
+
+	getReport := func(namespace string) contour_api_v1alpha1.NamespaceReportStatus {
+		t.Helper()
+
+		report := &contour_api_v1alpha1.NamespaceReport{}
+		require.NoError(t, client.Get(context.Background(), types.NamespacedName{
+			Namespace: namespace,
+			Name:      contour_api_v1alpha1.NamespaceReportName,
+		}, report))
+
+		return report.Status
+	}
+
+	assert.Equal(t, contour_api_v1alpha1.NamespaceReportStatus{ValidProxies: 1}, getReport("team-a"))
+
+	// The report of the previous leader is reset.
+	assert.Equal(t, contour_api_v1alpha1.NamespaceReportStatus{}, getReport("team-old"))
+}
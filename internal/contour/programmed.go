@@ -0,0 +1,218 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contour
+
+import (
+	"context"
+	"sync"
+
+	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
+	"github.com/projectcontour/contour/internal/dag"
+	"github.com/projectcontour/contour/internal/k8s"
+	"github.com/projectcontour/contour/internal/status"
+	"github.com/projectcontour/contour/internal/xds"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// maxPendingRevisions is the number of revisions, that are not
+// programmed yet, whose HTTPProxy generations are retained.
+const maxPendingRevisions = 16
+
+// ProgrammedGenerationReporter is a dag.Observer that updates the
+// xDS caches, through the next observer, and reports the generation of
+// the valid HTTPProxies in their status.programmedGeneration once the
+// revision of the xDS resources built from them is programmed into
+// Envoy. Reports are written by a separate goroutine once this Contour
+// is elected leader.
+//
+// Every Contour also records the revision that translated each
+// HTTPProxy generation, so that WaitReady can wait for it.
+type ProgrammedGenerationReporter struct {
+	log           logrus.FieldLogger
+	acks          *xds.ACKTracker
+	statusUpdater k8s.StatusUpdater
+	next          dag.Observer
+
+	// leader will become ready to read when this reporter becomes the leader.
+	leader chan struct{}
+
+	// changed is ready to read when a revision is recorded.
+	changed chan struct{}
+
+	mu sync.Mutex
+	// revisions holds the generations of the valid HTTPProxies
+	// of the revisions that are not reported yet, oldest first.
+	revisions []revisionGenerations
+
+	// reported holds the generations last reported.
+	reported map[types.NamespacedName]int64
+
+	// translations holds the translation of each
+	// HTTPProxy in the latest DAG.
+	translations map[types.NamespacedName]translation
+
+	// translated is closed, and replaced, whenever
+	// the translations are updated.
+	translated chan struct{}
+}
+
+type revisionGenerations struct {
+	revision    int64
+	generations map[types.NamespacedName]int64
+}
+
+// translation is the generation of an HTTPProxy in the DAG, and the
+// first revision of the xDS resources that was built from it.
+type translation struct {
+	generation int64
+	revision   int64
+	valid      bool
+}
+
+// NewProgrammedGenerationReporter returns a ProgrammedGenerationReporter
+// that updates the xDS caches with next and writes the status updates
+// to statusUpdater.
+func NewProgrammedGenerationReporter(log logrus.FieldLogger, acks *xds.ACKTracker, statusUpdater k8s.StatusUpdater, next dag.Observer) *ProgrammedGenerationReporter {
+	return &ProgrammedGenerationReporter{
+		log:           log,
+		acks:          acks,
+		statusUpdater: statusUpdater,
+		next:          next,
+		leader:        make(chan struct{}),
+		changed:       make(chan struct{}, 1),
+		reported:      map[types.NamespacedName]int64{},
+		translations:  map[types.NamespacedName]translation{},
+		translated:    make(chan struct{}),
+	}
+}
+
+func (r *ProgrammedGenerationReporter) OnElectedLeader() {
+	close(r.leader)
+}
+
+// OnChange updates the xDS caches with the DAG and records the
+// generations of its valid HTTPProxies for the resulting revision.
+func (r *ProgrammedGenerationReporter) OnChange(d *dag.DAG) {
+	revision := r.acks.Update(func() {
+		r.next.OnChange(d)
+	})
+
+	r.recordTranslations(d, revision)
+
+	select {
+	case <-r.leader:
+	default:
+		return
+	}
+
+	generations := map[types.NamespacedName]int64{}
+	for _, pu := range d.StatusCache.GetProxyUpdates() {
+		if pu.ConditionFor(status.ValidCondition).Status == contour_api_v1.ConditionTrue {
+			generations[pu.Fullname] = pu.Generation
+		}
+	}
+
+	r.mu.Lock()
+	r.revisions = append(r.revisions, revisionGenerations{revision: revision, generations: generations})
+	if len(r.revisions) > maxPendingRevisions {
+		r.revisions = r.revisions[len(r.revisions)-maxPendingRevisions:]
+	}
+	r.mu.Unlock()
+
+	// The revision may have been programmed before it was recorded.
+	select {
+	case r.changed <- struct{}{}:
+	default:
+	}
+}
+
+// recordTranslations records the translations of the HTTPProxies of
+// the DAG. A translation keeps its revision until the generation or
+// validity of its HTTPProxy changes.
+func (r *ProgrammedGenerationReporter) recordTranslations(d *dag.DAG, revision int64) {
+	translations := map[types.NamespacedName]translation{}
+	for _, pu := range d.StatusCache.GetProxyUpdates() {
+		t := translation{
+			generation: pu.Generation,
+			revision:   revision,
+			valid:      pu.ConditionFor(status.ValidCondition).Status == contour_api_v1.ConditionTrue,
+		}
+		if prev, ok := r.translations[pu.Fullname]; ok && prev.generation == t.generation && prev.valid == t.valid {
+			t.revision = prev.revision
+		}
+		translations[pu.Fullname] = t
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.translations = translations
+	close(r.translated)
+	r.translated = make(chan struct{})
+}
+
+// NeedLeaderElection is true since only the leader writes status.
+func (r *ProgrammedGenerationReporter) NeedLeaderElection() bool {
+	return true
+}
+
+// Start reports the programmed generations until the context is done.
+func (r *ProgrammedGenerationReporter) Start(ctx context.Context) error {
+	r.log.Info("started programmed generation reporter")
+	defer r.log.Info("stopped programmed generation reporter")
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-r.acks.Notify():
+		case <-r.changed:
+		}
+
+		r.report(r.acks.Programmed())
+	}
+}
+
+// report sends a status update for each HTTPProxy whose generation
+// in the latest recorded revision, that is no later than programmed,
+// was not reported yet.
+func (r *ProgrammedGenerationReporter) report(programmed int64) {
+	r.mu.Lock()
+	var generations map[types.NamespacedName]int64
+	for len(r.revisions) > 0 && r.revisions[0].revision <= programmed {
+		generations = r.revisions[0].generations
+		r.revisions = r.revisions[1:]
+	}
+	r.mu.Unlock()
+
+	if generations == nil {
+		return
+	}
+
+	for name, generation := range generations {
+		if r.reported[name] == generation {
+			continue
+		}
+
+		r.statusUpdater.Send(k8s.NewStatusUpdate(
+			name.Name,
+			name.Namespace,
+			&contour_api_v1.HTTPProxy{},
+			status.ProgrammedGenerationMutator(generation),
+		))
+	}
+
+	r.reported = generations
+}
@@ -0,0 +1,111 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contour
+
+import (
+	"testing"
+
+	envoy_resource_v3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
+	"github.com/projectcontour/contour/internal/dag"
+	"github.com/projectcontour/contour/internal/fixture"
+	"github.com/projectcontour/contour/internal/k8s"
+	"github.com/projectcontour/contour/internal/status"
+	"github.com/projectcontour/contour/internal/xds"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestProgrammedGenerationReporter(t *testing.T) {
+	proxy := func(name string, generation int64) *contour_api_v1.HTTPProxy {
+		return &contour_api_v1.HTTPProxy{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:  "default",
+				Name:       name,
+				Generation: generation,
+			},
+		}
+	}
+
+	buildDAG := func(proxies ...*contour_api_v1.HTTPProxy) *dag.DAG {
+		d := &dag.DAG{StatusCache: status.NewCache(types.NamespacedName{}, "")}
+		for _, p := range proxies {
+			pu, commit := d.StatusCache.ProxyAccessor(p)
+			cond := pu.ConditionFor(status.ValidCondition)
+			if p.Name == "invalid" {
+				cond.AddError(contour_api_v1.ConditionTypeRouteError, "SomeReason", "invalid route")
+			}
+			commit()
+		}
+		return d
+	}
+
+	// ack makes an Envoy ACK the current revision on its cluster and
+	// listener streams.
+	ack := func(acks *xds.ACKTracker, nonce string) {
+		acks.Read(func(revision int64) {
+			for streamID, typeURL := range []string{envoy_resource_v3.ClusterType, envoy_resource_v3.ListenerType} {
+				acks.Request(int64(streamID), "envoy", typeURL, "", false)
+				acks.Response(int64(streamID), typeURL, nonce, revision)
+				acks.Request(int64(streamID), "", typeURL, nonce, false)
+			}
+		})
+	}
+
+	statusUpdater := &k8s.StatusUpdateCacher{}
+	statusUpdater.OnAdd(proxy("valid", 1))
+	statusUpdater.OnAdd(proxy("invalid", 1))
+
+	updated := 0
+	acks := xds.NewACKTracker()
+	r := NewProgrammedGenerationReporter(fixture.NewTestLogger(t), acks, statusUpdater, dag.ObserverFunc(func(*dag.DAG) {
+		updated++
+	}))
+
+	programmedGeneration := func(name string) int64 {
+		t.Helper()
+
+		s, err := statusUpdater.GetStatus(proxy(name, 0))
+		require.NoError(t, err)
+		return s.ProgrammedGeneration
+	}
+
+	// The xDS caches are updated before this Contour is elected leader,
+	// but nothing is reported.
+	r.OnChange(buildDAG(proxy("valid", 1), proxy("invalid", 1)))
+	assert.Equal(t, 1, updated)
+	ack(acks, "a")
+	r.report(acks.Programmed())
+	assert.Equal(t, int64(0), programmedGeneration("valid"))
+
+	r.OnElectedLeader()
+
+	// Generations are reported once their revision is programmed.
+	r.OnChange(buildDAG(proxy("valid", 2), proxy("invalid", 2)))
+	assert.Equal(t, 2, updated)
+	r.report(acks.Programmed())
+	assert.Equal(t, int64(0), programmedGeneration("valid"))
+
+	ack(acks, "b")
+	r.report(acks.Programmed())
+	assert.Equal(t, int64(2), programmedGeneration("valid"))
+	assert.Equal(t, int64(0), programmedGeneration("invalid"))
+
+	// Later revisions that are not programmed are not reported.
+	r.OnChange(buildDAG(proxy("valid", 3)))
+	r.report(acks.Programmed())
+	assert.Equal(t, int64(2), programmedGeneration("valid"))
+}
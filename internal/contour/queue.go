@@ -0,0 +1,148 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contour
+
+import (
+	"sync"
+
+	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
+	v1 "k8s.io/api/core/v1"
+	networking_v1 "k8s.io/api/networking/v1"
+	"k8s.io/client-go/tools/cache"
+	gatewayapi_v1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gatewayapi_v1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+// eventPriority is the order in which queued events are
+// applied to the DAG builder's cache.
+type eventPriority int
+
+const (
+	// priorityHigh is for events that change the routes or
+	// certificates served by Envoy.
+	priorityHigh eventPriority = iota
+
+	// priorityNormal is for all other events.
+	priorityNormal
+
+	numPriorities
+
+	// priorityEndpoints is for events that only change the
+	// endpoints of Services. They are not queued, but sent
+	// straight to the endpoints cache as EDS-only updates.
+	priorityEndpoints eventPriority = -1
+)
+
+// maxQueuedEvents is the number of events the queue holds
+// before pushing to it blocks the informers.
+const maxQueuedEvents = 1024
+
+// priorityOf returns the priority of the given operation.
+func priorityOf(op any) eventPriority {
+	var obj any
+	switch op := op.(type) {
+	case opAdd:
+		obj = op.obj
+	case opUpdate:
+		obj = op.newObj
+	case opDelete:
+		obj = op.obj
+	case bool:
+		// Leader election must refresh statuses promptly.
+		return priorityHigh
+	case opReconfigure:
+		return priorityHigh
+	}
+
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+
+	switch obj.(type) {
+	case *v1.Endpoints, *v1.Pod:
+		return priorityEndpoints
+	case *v1.Secret,
+		*contour_api_v1.HTTPProxy,
+		*networking_v1.Ingress,
+		*gatewayapi_v1beta1.Gateway,
+		*gatewayapi_v1beta1.HTTPRoute,
+		*gatewayapi_v1alpha2.TLSRoute,
+		*gatewayapi_v1alpha2.GRPCRoute,
+		*gatewayapi_v1alpha2.TCPRoute:
+		return priorityHigh
+	default:
+		return priorityNormal
+	}
+}
+
+// eventQueue is a bounded queue of events ordered by priority.
+// Pushing to the queue doesn't block the informers while a DAG
+// is being rebuilt, unless the queue is full.
+type eventQueue struct {
+	mu     sync.Mutex
+	events [numPriorities][]any
+
+	// size is the number of events in the queue, and
+	// capacity the number it holds before push blocks.
+	size, capacity int
+
+	// notFull is signalled when events are drained
+	// from the queue.
+	notFull *sync.Cond
+
+	// ready receives a value when events are pushed
+	// to the queue.
+	ready chan struct{}
+}
+
+func newEventQueue(capacity int) *eventQueue {
+	q := &eventQueue{
+		capacity: capacity,
+		ready:    make(chan struct{}, 1),
+	}
+	q.notFull = sync.NewCond(&q.mu)
+	return q
+}
+
+// push adds op to the queue at the given priority,
+// waiting for the queue to be drained if it is full.
+func (q *eventQueue) push(priority eventPriority, op any) {
+	q.mu.Lock()
+	for q.size >= q.capacity {
+		q.notFull.Wait()
+	}
+	q.events[priority] = append(q.events[priority], op)
+	q.size++
+	q.mu.Unlock()
+
+	select {
+	case q.ready <- struct{}{}:
+	default:
+		// The consumer has already been signalled.
+	}
+}
+
+// drain removes all events from the queue and returns them
+// by priority. Events of the same priority are returned in
+// the order in which they were pushed.
+func (q *eventQueue) drain() [numPriorities][]any {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	events := q.events
+	q.events = [numPriorities][]any{}
+	q.size = 0
+	q.notFull.Broadcast()
+	return events
+}
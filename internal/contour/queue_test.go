@@ -0,0 +1,148 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contour
+
+import (
+	"testing"
+	"time"
+
+	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
+	contour_api_v1alpha1 "github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+	gatewayapi_v1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+func TestPriorityOf(t *testing.T) {
+	tests := map[string]struct {
+		op   any
+		want eventPriority
+	}{
+		"secret added": {
+			op:   opAdd{obj: &v1.Secret{}},
+			want: priorityHigh,
+		},
+		"httpproxy updated": {
+			op:   opUpdate{oldObj: &contour_api_v1.HTTPProxy{}, newObj: &contour_api_v1.HTTPProxy{}},
+			want: priorityHigh,
+		},
+		"httproute deleted": {
+			op:   opDelete{obj: &gatewayapi_v1beta1.HTTPRoute{}},
+			want: priorityHigh,
+		},
+		"secret tombstone deleted": {
+			op:   opDelete{obj: cache.DeletedFinalStateUnknown{Obj: &v1.Secret{}}},
+			want: priorityHigh,
+		},
+		"elected leader": {
+			op:   true,
+			want: priorityHigh,
+		},
+		"reconfigured": {
+			op:   opReconfigure{apply: func() {}},
+			want: priorityHigh,
+		},
+		"service added": {
+			op:   opAdd{obj: &v1.Service{}},
+			want: priorityNormal,
+		},
+		"namespace deleted": {
+			op:   opDelete{obj: &v1.Namespace{}},
+			want: priorityNormal,
+		},
+		"endpoints updated": {
+			op:   opUpdate{oldObj: &v1.Endpoints{}, newObj: &v1.Endpoints{}},
+			want: priorityEndpoints,
+		},
+		"pod tombstone deleted": {
+			op:   opDelete{obj: cache.DeletedFinalStateUnknown{Obj: &v1.Pod{}}},
+			want: priorityEndpoints,
+		},
+		"extension service updated": {
+			op:   opUpdate{oldObj: &contour_api_v1alpha1.ExtensionService{}, newObj: &contour_api_v1alpha1.ExtensionService{}},
+			want: priorityNormal,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, priorityOf(tc.op))
+		})
+	}
+}
+
+func TestEventQueue(t *testing.T) {
+	q := newEventQueue(maxQueuedEvents)
+	assert.Equal(t, [numPriorities][]any{}, q.drain())
+
+	q.push(priorityNormal, "normal-1")
+	q.push(priorityHigh, "high-1")
+	q.push(priorityNormal, "normal-2")
+	q.push(priorityHigh, "high-2")
+
+	// Pushing more than once leaves a single signal pending.
+	assert.Len(t, q.ready, 1)
+	<-q.ready
+
+	assert.Equal(t, [numPriorities][]any{
+		priorityHigh:   {"high-1", "high-2"},
+		priorityNormal: {"normal-1", "normal-2"},
+	}, q.drain())
+	assert.Equal(t, [numPriorities][]any{}, q.drain())
+}
+
+func TestEventQueueFull(t *testing.T) {
+	q := newEventQueue(2)
+	q.push(priorityNormal, "normal-1")
+	q.push(priorityNormal, "normal-2")
+
+	pushed := make(chan struct{})
+	go func() {
+		q.push(priorityHigh, "high-1")
+		close(pushed)
+	}()
+
+	// Pushing to a full queue blocks until it is drained.
+	select {
+	case <-pushed:
+		t.Fatal("push to a full queue did not block")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	assert.Equal(t, []any{"normal-1", "normal-2"}, q.drain()[priorityNormal])
+	<-pushed
+	assert.Equal(t, []any{"high-1"}, q.drain()[priorityHigh])
+}
+
+func TestEventHandlerSendsEndpointsToEndpointsHandler(t *testing.T) {
+	var got []any
+	e := NewEventHandler(EventHandlerConfig{
+		EndpointsHandler: cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(obj any) { got = append(got, obj) },
+			UpdateFunc: func(_, newObj any) { got = append(got, newObj) },
+			DeleteFunc: func(obj any) { got = append(got, obj) },
+		},
+	})
+
+	endpoints := &v1.Endpoints{}
+	pod := &v1.Pod{}
+	e.OnAdd(endpoints, false)
+	e.OnUpdate(pod, pod)
+	e.OnDelete(endpoints)
+	e.OnAdd(&v1.Service{}, false)
+
+	assert.Equal(t, []any{endpoints, pod, endpoints}, got)
+	assert.Equal(t, []any{opAdd{obj: &v1.Service{}}}, e.queue.drain()[priorityNormal])
+}
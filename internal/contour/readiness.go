@@ -0,0 +1,130 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contour
+
+import (
+	"context"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ProxyReadiness is the readiness of a generation of an HTTPProxy.
+type ProxyReadiness struct {
+	// Proxy is the namespace and name of the HTTPProxy.
+	Proxy string `json:"proxy"`
+
+	// Generation is the generation waited for. If no generation
+	// was requested, it is the generation in the DAG.
+	Generation int64 `json:"generation"`
+
+	// Translated is true once the DAG holds the generation,
+	// or a later one.
+	Translated bool `json:"translated"`
+
+	// Valid is true if the translated generation is valid.
+	Valid bool `json:"valid"`
+
+	// Revision is the revision of the xDS resources that
+	// were first built from the translated generation.
+	Revision int64 `json:"revision,omitempty"`
+
+	// Ready is true once every Envoy connected to this Contour
+	// has ACKed the revision, and at least one is connected.
+	Ready bool `json:"ready"`
+
+	// Nodes holds the readiness of each connected Envoy.
+	Nodes []NodeReadiness `json:"nodes"`
+}
+
+// NodeReadiness is the readiness of an Envoy.
+type NodeReadiness struct {
+	// Node is the node ID of the Envoy.
+	Node string `json:"node"`
+
+	// Revision is the revision that the Envoy has ACKed.
+	Revision int64 `json:"revision"`
+
+	// Ready is true if the Envoy has ACKed the revision
+	// of the HTTPProxy, or a later one.
+	Ready bool `json:"ready"`
+}
+
+// WaitReady waits until the supplied generation of the HTTPProxy, or a
+// later one, is translated and ACKed by every Envoy connected to this
+// Contour, or until the generation turns out to be invalid, or the
+// context is done. If generation is 0, it waits for the generation in
+// the DAG. It returns the last readiness observed.
+func (r *ProgrammedGenerationReporter) WaitReady(ctx context.Context, proxy types.NamespacedName, generation int64) ProxyReadiness {
+	for {
+		// Take the channels before reading the
+		// readiness, so that no change is missed.
+		acked := r.acks.Changed()
+		r.mu.Lock()
+		translated := r.translated
+		r.mu.Unlock()
+
+		readiness := r.readiness(proxy, generation)
+		if readiness.Ready || (readiness.Translated && !readiness.Valid) {
+			return readiness
+		}
+
+		select {
+		case <-ctx.Done():
+			return readiness
+		case <-acked:
+		case <-translated:
+		}
+	}
+}
+
+func (r *ProgrammedGenerationReporter) readiness(proxy types.NamespacedName, generation int64) ProxyReadiness {
+	readiness := ProxyReadiness{
+		Proxy:      proxy.String(),
+		Generation: generation,
+		Nodes:      []NodeReadiness{},
+	}
+
+	r.mu.Lock()
+	t, ok := r.translations[proxy]
+	r.mu.Unlock()
+
+	if !ok || t.generation < generation {
+		return readiness
+	}
+
+	if generation == 0 {
+		readiness.Generation = t.generation
+	}
+	readiness.Translated = true
+	readiness.Valid = t.valid
+	readiness.Revision = t.revision
+
+	ready := true
+	for node, revision := range r.acks.Nodes() {
+		nr := NodeReadiness{
+			Node:     node,
+			Revision: revision,
+			Ready:    revision >= t.revision,
+		}
+		ready = ready && nr.Ready
+		readiness.Nodes = append(readiness.Nodes, nr)
+	}
+	sort.Slice(readiness.Nodes, func(i, j int) bool {
+		return readiness.Nodes[i].Node < readiness.Nodes[j].Node
+	})
+
+	readiness.Ready = t.valid && ready && len(readiness.Nodes) > 0
+	return readiness
+}
@@ -0,0 +1,116 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contour
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	envoy_resource_v3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
+	"github.com/projectcontour/contour/internal/dag"
+	"github.com/projectcontour/contour/internal/fixture"
+	"github.com/projectcontour/contour/internal/k8s"
+	"github.com/projectcontour/contour/internal/status"
+	"github.com/projectcontour/contour/internal/xds"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestWaitReady(t *testing.T) {
+	buildDAG := func(generation int64, valid bool) *dag.DAG {
+		d := &dag.DAG{StatusCache: status.NewCache(types.NamespacedName{}, "")}
+		pu, commit := d.StatusCache.ProxyAccessor(&contour_api_v1.HTTPProxy{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:  "default",
+				Name:       "proxy",
+				Generation: generation,
+			},
+		})
+		cond := pu.ConditionFor(status.ValidCondition)
+		if !valid {
+			cond.AddError(contour_api_v1.ConditionTypeRouteError, "SomeReason", "invalid route")
+		}
+		commit()
+		return d
+	}
+
+	// ack makes an Envoy ACK the current revision on its
+	// cluster and listener streams.
+	ack := func(acks *xds.ACKTracker, node string, nonce string) {
+		acks.Read(func(revision int64) {
+			for i, typeURL := range []string{envoy_resource_v3.ClusterType, envoy_resource_v3.ListenerType} {
+				streamID := int64(len(node)*10 + i)
+				acks.Request(streamID, node, typeURL, "", false)
+				acks.Response(streamID, typeURL, nonce, revision)
+				acks.Request(streamID, "", typeURL, nonce, false)
+			}
+		})
+	}
+
+	proxy := types.NamespacedName{Namespace: "default", Name: "proxy"}
+
+	acks := xds.NewACKTracker()
+	r := NewProgrammedGenerationReporter(fixture.NewTestLogger(t), acks, &k8s.StatusUpdateCacher{}, dag.ObserverFunc(func(*dag.DAG) {}))
+
+	// Nothing is translated yet.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, ProxyReadiness{
+		Proxy:      "default/proxy",
+		Generation: 1,
+		Nodes:      []NodeReadiness{},
+	}, r.WaitReady(ctx, proxy, 1))
+
+	r.OnChange(buildDAG(1, true))
+	ack(acks, "envoy-a", "a")
+	r.OnChange(buildDAG(2, true))
+	ack(acks, "envoy-bb", "b")
+
+	// The generation is ACKed by one of the Envoys.
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, ProxyReadiness{
+		Proxy:      "default/proxy",
+		Generation: 2,
+		Translated: true,
+		Valid:      true,
+		Revision:   2,
+		Nodes: []NodeReadiness{
+			{Node: "envoy-a", Revision: 1},
+			{Node: "envoy-bb", Revision: 2, Ready: true},
+		},
+	}, r.WaitReady(ctx, proxy, 0))
+
+	// Earlier generations are ready once the
+	// generation that replaced them is.
+	done := make(chan ProxyReadiness)
+	go func() {
+		done <- r.WaitReady(context.Background(), proxy, 1)
+	}()
+	ack(acks, "envoy-a", "c")
+	readiness := <-done
+	assert.True(t, readiness.Ready)
+	assert.Equal(t, int64(2), readiness.Revision)
+
+	// Invalid generations return right away.
+	r.OnChange(buildDAG(3, false))
+	readiness = r.WaitReady(context.Background(), proxy, 3)
+	assert.True(t, readiness.Translated)
+	assert.False(t, readiness.Valid)
+	assert.False(t, readiness.Ready)
+	assert.Equal(t, int64(3), readiness.Revision)
+}
@@ -69,6 +69,7 @@ func Defaults() contour_api_v1alpha1.ContourConfigurationSpec {
 				DisableMergeSlashes:        ref.To(false),
 				ServerHeaderTransformation: contour_api_v1alpha1.OverwriteServerHeader,
 				ConnectionBalancer:         "",
+				DrainType:                  contour_api_v1alpha1.DefaultListenerDrainType,
 				TLS: &contour_api_v1alpha1.EnvoyTLS{
 					MinimumProtocolVersion: "1.2",
 					CipherSuites:           contour_api_v1alpha1.DefaultTLSCiphers,
@@ -100,6 +101,7 @@ func Defaults() contour_api_v1alpha1.ContourConfigurationSpec {
 			ClientCertificate: nil,
 			Logging: &contour_api_v1alpha1.EnvoyLogging{
 				AccessLogFormat:       contour_api_v1alpha1.EnvoyAccessLog,
+				AccessLogFormatPreset: "",
 				AccessLogFormatString: "",
 				AccessLogJSONFields:   nil,
 				AccessLogLevel:        contour_api_v1alpha1.LogLevelInfo,
@@ -119,6 +121,7 @@ func Defaults() contour_api_v1alpha1.ContourConfigurationSpec {
 			},
 			Cluster: &contour_api_v1alpha1.ClusterParameters{
 				DNSLookupFamily: contour_api_v1alpha1.AutoClusterDNSFamily,
+				StatNameFormat:  contour_api_v1alpha1.ServiceClusterStatNameFormat,
 			},
 			Network: &contour_api_v1alpha1.NetworkParameters{
 				XffNumTrustedHops: ref.To(uint32(0)),
@@ -127,9 +130,10 @@ func Defaults() contour_api_v1alpha1.ContourConfigurationSpec {
 		},
 		Gateway: nil,
 		HTTPProxy: &contour_api_v1alpha1.HTTPProxyConfig{
-			DisablePermitInsecure: ref.To(false),
-			RootNamespaces:        nil,
-			FallbackCertificate:   nil,
+			DisablePermitInsecure:  ref.To(false),
+			RootNamespaces:         nil,
+			FallbackCertificate:    nil,
+			EnableNamespaceReports: ref.To(false),
 		},
 		EnableExternalNameService: ref.To(false),
 		RateLimitService:          nil,
@@ -143,6 +147,16 @@ func Defaults() contour_api_v1alpha1.ContourConfigurationSpec {
 			Port:    8000,
 			TLS:     nil,
 		},
+		LeaderElection: &contour_api_v1alpha1.LeaderElectionConfig{
+			Disable:   ref.To(false),
+			LeaseName: "leader-elect",
+			// The namespace that Contour runs in.
+			LeaseNamespace: "",
+			ResourceLock:   contour_api_v1alpha1.LeasesResourceLock,
+			LeaseDuration:  ref.To("15s"),
+			RenewDeadline:  ref.To("10s"),
+			RetryPeriod:    ref.To("2s"),
+		},
 	}
 }
 
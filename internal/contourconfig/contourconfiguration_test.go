@@ -59,6 +59,19 @@ func TestOverlayOnDefaults(t *testing.T) {
 				MaxRequestsPerConnection:   ref.To(uint32(1)),
 				ServerHeaderTransformation: contour_api_v1alpha1.PassThroughServerHeader,
 				ConnectionBalancer:         "yesplease",
+				DrainType:                  contour_api_v1alpha1.ModifyOnlyListenerDrainType,
+				RemovalDelay:               ref.To("30s"),
+				SocketOptions: &contour_api_v1alpha1.EnvoySocketOptions{
+					TrafficClass: ref.To(int32(32)),
+				},
+				HTTPConnectionManager: &contour_api_v1alpha1.EnvoyHTTPConnectionManager{
+					ServerName: ref.To("example"),
+					Via:        ref.To("1.1 example"),
+				},
+				HTTPFilters: []contour_api_v1alpha1.HTTPFilter{{
+					Name:        "buffer",
+					TypedConfig: `{"@type": "type.googleapis.com/envoy.extensions.filters.http.buffer.v3.Buffer"}`,
+				}},
 				TLS: &contour_api_v1alpha1.EnvoyTLS{
 					MinimumProtocolVersion: "1.7",
 					CipherSuites: []string{
@@ -85,14 +98,22 @@ func TestOverlayOnDefaults(t *testing.T) {
 				Address: "1.1.1.1",
 				Port:    8222,
 			},
+			HealthListener: &contour_api_v1alpha1.EnvoyHealthListener{
+				ReadinessPath: ref.To("/healthz/ready"),
+				Liveness: &contour_api_v1alpha1.EnvoyLivenessEndpoint{
+					Path: "/healthz/live",
+				},
+			},
 			Metrics: &contour_api_v1alpha1.MetricsConfig{
 				Address: "1.2.12.1212",
 				Port:    8882,
 				TLS: &contour_api_v1alpha1.MetricsTLS{
-					CAFile:   "cafile",
-					CertFile: "certfile",
-					KeyFile:  "keyfile",
+					CAFile:                 "cafile",
+					CertFile:               "certfile",
+					KeyFile:                "keyfile",
+					AllowedSubjectAltNames: []string{"prometheus"},
 				},
+				BearerTokenFile: "tokenfile",
 			},
 			ClientCertificate: &contour_api_v1alpha1.NamespacedName{
 				Namespace: "clientcertnamespace",
@@ -103,6 +124,21 @@ func TestOverlayOnDefaults(t *testing.T) {
 				AccessLogFormatString: "foo",
 				AccessLogJSONFields:   []string{"field-1", "field-2"},
 				AccessLogLevel:        contour_api_v1alpha1.LogLevelCritical,
+				AccessLogGRPC: &contour_api_v1alpha1.AccessLogGRPCConfig{
+					ExtensionService: &contour_api_v1alpha1.NamespacedName{
+						Namespace: "alsnamespace",
+						Name:      "alsname",
+					},
+					LogName:             ref.To("envoy"),
+					BufferSizeBytes:     ref.To(uint32(32768)),
+					BufferFlushInterval: ref.To("2s"),
+				},
+				AccessLogFilter: &contour_api_v1alpha1.AccessLogFilter{
+					MinStatusCode:  ref.To(uint32(400)),
+					MinDuration:    ref.To("1s"),
+					RequestHeaders: []string{"x-debug"},
+					SamplePercent:  ref.To(uint32(5)),
+				},
 			},
 			DefaultHTTPVersions: []contour_api_v1alpha1.HTTPVersionType{
 				"HTTP/2.2",
@@ -118,12 +154,30 @@ func TestOverlayOnDefaults(t *testing.T) {
 				ConnectTimeout:                ref.To("7s"),
 			},
 			Cluster: &contour_api_v1alpha1.ClusterParameters{
-				DNSLookupFamily: contour_api_v1alpha1.IPv4ClusterDNSFamily,
+				DNSLookupFamily:             contour_api_v1alpha1.IPv4ClusterDNSFamily,
+				StatNameFormat:              contour_api_v1alpha1.ClusterNameClusterStatNameFormat,
+				MaxStatNameLength:           ref.To(uint32(60)),
+				EndpointDeregistrationDelay: ref.To("10s"),
+				HTTP2: &contour_api_v1alpha1.UpstreamHTTP2{
+					MaxConcurrentStreams: ref.To(uint32(100)),
+				},
+				DNSResolvers:   []string{"10.0.0.10"},
+				DNSRefreshRate: ref.To("30s"),
+				CircuitBreakers: &contour_api_v1alpha1.CircuitBreakers{
+					MaxRetries: ref.To(uint32(10)),
+				},
 			},
 			Network: &contour_api_v1alpha1.NetworkParameters{
 				XffNumTrustedHops: ref.To(uint32(77)),
 				EnvoyAdminPort:    ref.To(9997),
 			},
+			Compression: &contour_api_v1alpha1.EnvoyCompression{
+				Algorithm: contour_api_v1alpha1.ZstdCompression,
+			},
+			ResponseMap: []contour_api_v1alpha1.ResponseMapping{{
+				StatusCode: 503,
+				Body:       "unavailable",
+			}},
 		},
 		Gateway: &contour_api_v1alpha1.GatewayConfig{
 			ControllerName: "gatewaycontroller",
@@ -139,6 +193,7 @@ func TestOverlayOnDefaults(t *testing.T) {
 				Namespace: "fallbackcertificatenamespace",
 				Name:      "fallbackcertificatename",
 			},
+			EnableNamespaceReports: ref.To(true),
 		},
 		EnableExternalNameService: ref.To(true),
 		RateLimitService: &contour_api_v1alpha1.RateLimitServiceConfig{
@@ -184,6 +239,18 @@ func TestOverlayOnDefaults(t *testing.T) {
 				KeyFile:  "keyfile.keyfile",
 			},
 		},
+		HTTPProxyMetrics: &contour_api_v1alpha1.HTTPProxyMetricsConfig{
+			ScrapeInterval: ref.To("15s"),
+		},
+		LeaderElection: &contour_api_v1alpha1.LeaderElectionConfig{
+			Disable:        ref.To(true),
+			LeaseName:      "contour-leader",
+			LeaseNamespace: "contour-system",
+			ResourceLock:   contour_api_v1alpha1.LeasesResourceLock,
+			LeaseDuration:  ref.To("30s"),
+			RenewDeadline:  ref.To("20s"),
+			RetryPeriod:    ref.To("4s"),
+		},
 	}
 
 	tests := map[string]struct {
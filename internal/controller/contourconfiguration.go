@@ -0,0 +1,170 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	contour_api_v1alpha1 "github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
+	"github.com/projectcontour/contour/internal/k8s"
+	"github.com/projectcontour/contour/internal/leadership"
+	"github.com/projectcontour/contour/internal/status"
+	"github.com/sirupsen/logrus"
+	api_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+type contourConfigurationReconciler struct {
+	client        client.Client
+	statusUpdater k8s.StatusUpdater
+	log           logrus.FieldLogger
+	name          types.NamespacedName
+	generation    int64
+	validate      func(contour_api_v1alpha1.ContourConfigurationSpec) error
+	eventSource   chan event.GenericEvent
+
+	changedOnce sync.Once
+	changed     chan struct{}
+}
+
+// RegisterContourConfigurationController creates the contourconfiguration
+// controller. The controller watches the ContourConfiguration name, which
+// Contour was started with at the given generation, and reports whether
+// its spec is valid in its Valid condition. Once a valid change is made
+// to its spec, the controller stops the manager so that Contour restarts
+// with the new configuration. Invalid changes are ignored.
+func RegisterContourConfigurationController(
+	log logrus.FieldLogger,
+	mgr manager.Manager,
+	statusUpdater k8s.StatusUpdater,
+	name types.NamespacedName,
+	generation int64,
+	validate func(contour_api_v1alpha1.ContourConfigurationSpec) error,
+) (leadership.NeedLeaderElectionNotification, error) {
+	r := &contourConfigurationReconciler{
+		client:        mgr.GetClient(),
+		statusUpdater: statusUpdater,
+		log:           log,
+		name:          name,
+		generation:    generation,
+		validate:      validate,
+		// Set up a source.Channel that will trigger a reconcile
+		// of the ContourConfiguration when this Contour process
+		// is elected leader, to ensure that its status is up to
+		// date.
+		eventSource: make(chan event.GenericEvent),
+		changed:     make(chan struct{}),
+	}
+
+	c, err := controller.NewUnmanaged("contourconfiguration-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return nil, err
+	}
+	if err := mgr.Add(&noLeaderElectionController{c}); err != nil {
+		return nil, err
+	}
+	if err := mgr.Add(r); err != nil {
+		return nil, err
+	}
+
+	if err := c.Watch(
+		source.Kind(mgr.GetCache(), &contour_api_v1alpha1.ContourConfiguration{}),
+		&handler.EnqueueRequestForObject{},
+		predicate.NewPredicateFuncs(r.isWatched),
+	); err != nil {
+		return nil, err
+	}
+
+	if err := c.Watch(
+		&source.Channel{Source: r.eventSource},
+		&handler.EnqueueRequestForObject{},
+	); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *contourConfigurationReconciler) OnElectedLeader() {
+	r.log.Info("elected leader, triggering reconcile for contourconfiguration")
+
+	r.eventSource <- event.GenericEvent{Object: &contour_api_v1alpha1.ContourConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: r.name.Namespace,
+			Name:      r.name.Name,
+		},
+	}}
+}
+
+// isWatched returns true if obj is the ContourConfiguration
+// that Contour was started with.
+func (r *contourConfigurationReconciler) isWatched(obj client.Object) bool {
+	return k8s.NamespacedNameOf(obj) == r.name
+}
+
+func (r *contourConfigurationReconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	log := r.log.WithField("name", request.Name).WithField("namespace", request.Namespace)
+
+	cfg := &contour_api_v1alpha1.ContourConfiguration{}
+	if err := r.client.Get(ctx, request.NamespacedName, cfg); err != nil {
+		if api_errors.IsNotFound(err) {
+			log.Error("contourconfiguration was deleted, keeping the current configuration")
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, fmt.Errorf("error getting contourconfiguration: %w", err)
+	}
+
+	validationErr := r.validate(cfg.Spec)
+	r.statusUpdater.Send(status.ContourConfigurationUpdate(r.name, cfg.Generation, validationErr))
+
+	switch {
+	case cfg.Generation == r.generation:
+	case validationErr != nil:
+		log.WithError(validationErr).WithField("generation", cfg.Generation).
+			Error("contourconfiguration is invalid, keeping the current configuration")
+	default:
+		log.WithField("generation", cfg.Generation).Info("contourconfiguration changed, restarting to apply it")
+		r.changedOnce.Do(func() { close(r.changed) })
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// NeedLeaderElection returns false, as every Contour must
+// restart to apply a new configuration.
+func (r *contourConfigurationReconciler) NeedLeaderElection() bool {
+	return false
+}
+
+// Start returns an error, which stops the manager, once a valid
+// change is made to the ContourConfiguration.
+func (r *contourConfigurationReconciler) Start(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return nil
+	case <-r.changed:
+		return fmt.Errorf("contourconfiguration %s changed", r.name)
+	}
+}
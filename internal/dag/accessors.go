@@ -19,7 +19,6 @@ import (
 	"strings"
 
 	"github.com/projectcontour/contour/internal/annotation"
-	"github.com/projectcontour/contour/internal/xds"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -202,6 +201,10 @@ func (d *DAG) GetClusters() []*Cluster {
 			for _, route := range vhost.Routes {
 				res = append(res, route.Clusters...)
 
+				if route.AggregateCluster != nil {
+					res = append(res, route.AggregateCluster.Clusters...)
+				}
+
 				if route.MirrorPolicy != nil && route.MirrorPolicy.Cluster != nil {
 					res = append(res, route.MirrorPolicy.Cluster)
 				}
@@ -212,6 +215,10 @@ func (d *DAG) GetClusters() []*Cluster {
 			for _, route := range vhost.Routes {
 				res = append(res, route.Clusters...)
 
+				if route.AggregateCluster != nil {
+					res = append(res, route.AggregateCluster.Clusters...)
+				}
+
 				if route.MirrorPolicy != nil && route.MirrorPolicy.Cluster != nil {
 					res = append(res, route.MirrorPolicy.Cluster)
 				}
@@ -241,6 +248,31 @@ func (d *DAG) GetDNSNameClusters() []*DNSNameCluster {
 	return res
 }
 
+// GetAggregateClusters returns all aggregate clusters in the DAG.
+func (d *DAG) GetAggregateClusters() []*AggregateCluster {
+	var res []*AggregateCluster
+
+	for _, listener := range d.Listeners {
+		for _, vhost := range listener.VirtualHosts {
+			for _, route := range vhost.Routes {
+				if route.AggregateCluster != nil {
+					res = append(res, route.AggregateCluster)
+				}
+			}
+		}
+
+		for _, vhost := range listener.SecureVirtualHosts {
+			for _, route := range vhost.Routes {
+				if route.AggregateCluster != nil {
+					res = append(res, route.AggregateCluster)
+				}
+			}
+		}
+	}
+
+	return res
+}
+
 func (d *DAG) GetServiceClusters() []*ServiceCluster {
 	var res []*ServiceCluster
 
@@ -255,17 +287,19 @@ func (d *DAG) GetServiceClusters() []*ServiceCluster {
 
 		// A Service has only one WeightedService entry. Fake up a
 		// ServiceCluster so that the visitor can pretend to not
-		// know this.
+		// know this. Failover Services are added at lower priority
+		// levels.
 		c := &ServiceCluster{
-			ClusterName: xds.ClusterLoadAssignmentName(
-				types.NamespacedName{
-					Name:      cluster.Upstream.Weighted.ServiceName,
-					Namespace: cluster.Upstream.Weighted.ServiceNamespace,
-				},
-				cluster.Upstream.Weighted.ServicePort.Name),
+			ClusterName: cluster.ClusterLoadAssignmentName(),
 			Services: []WeightedService{
 				cluster.Upstream.Weighted,
 			},
+			SubsetKeys: cluster.SubsetKeys(),
+		}
+		for i, failover := range cluster.Failover {
+			w := failover.Weighted
+			w.Priority = uint32(i + 1)
+			c.Services = append(c.Services, w)
 		}
 
 		res = append(res, c)
@@ -290,7 +324,9 @@ func (d *DAG) GetExtensionClusters() map[string]*ExtensionCluster {
 	return res
 }
 
-func (d *DAG) GetSecrets() []*Secret {
+// GetServerSecrets returns the secrets served by TLS virtual hosts,
+// including fallback certificates.
+func (d *DAG) GetServerSecrets() []*Secret {
 	var res []*Secret
 	for _, l := range d.Listeners {
 		for _, svh := range l.SecureVirtualHosts {
@@ -303,12 +339,6 @@ func (d *DAG) GetSecrets() []*Secret {
 		}
 	}
 
-	for _, c := range d.GetClusters() {
-		if c.ClientCertificate != nil {
-			res = append(res, c.ClientCertificate)
-		}
-	}
-
 	return res
 }
 
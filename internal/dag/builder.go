@@ -123,5 +123,7 @@ func (b *Builder) Build() *DAG {
 
 	dag.Listeners = listeners
 
+	b.Source.setDependents(dag.Dependents)
+
 	return dag
 }
@@ -19,6 +19,7 @@ import (
 	"testing"
 	"time"
 
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
 	"github.com/projectcontour/contour/internal/fixture"
 	"github.com/projectcontour/contour/internal/gatewayapi"
@@ -6741,6 +6742,69 @@ func TestDAGInsert(t *testing.T) {
 		},
 	}
 
+	certificate1 := &certmanagerv1.Certificate{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo-com",
+			Namespace: "default",
+		},
+		Spec: certmanagerv1.CertificateSpec{
+			SecretName: sec1.Name,
+		},
+	}
+
+	proxyCertificate := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "example-com",
+			Namespace: "default",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "foo.com",
+				TLS: &contour_api_v1.TLS{
+					CertificateName: certificate1.Name,
+				},
+			},
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: "kuard",
+					Port: 8080,
+				}},
+			}},
+		},
+	}
+
+	proxyHTTPSRedirectPolicy := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "example-com",
+			Namespace: "default",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "foo.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName: sec1.Name,
+				},
+				HTTPSRedirectPolicy: &contour_api_v1.HTTPSRedirectPolicy{
+					StatusCode:           308,
+					StripQuery:           true,
+					ExcludedPathPrefixes: []string{"/.well-known/acme-challenge/"},
+				},
+			},
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: "kuard",
+					Port: 8080,
+				}},
+			}},
+		},
+	}
+
 	proxyMinTLS13 := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "example-com",
@@ -7549,9 +7613,8 @@ func TestDAGInsert(t *testing.T) {
 		},
 	}
 
-	// proxy2d is a proxy with two routes that have the same prefix and a Contains header
-	// condition on the same header, differing only in the value of the condition.
-	proxy2d := &contour_api_v1.HTTPProxy{
+	// proxyAggregate is a proxy with a route to an aggregate of two services.
+	proxyAggregate := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "example-com",
 			Namespace: "default",
@@ -7560,48 +7623,24 @@ func TestDAGInsert(t *testing.T) {
 			VirtualHost: &contour_api_v1.VirtualHost{
 				Fqdn: "example.com",
 			},
-			Routes: []contour_api_v1.Route{
-				{
-					Conditions: []contour_api_v1.MatchCondition{
-						{
-							Header: &contour_api_v1.HeaderMatchCondition{
-								Name:     "e-tag",
-								Contains: "abc",
-							},
-						},
-						{
-							Prefix: "/",
-						},
-					},
-					Services: []contour_api_v1.Service{{
-						Name: "kuard",
-						Port: 8080,
-					}},
-				},
-				{
-					Conditions: []contour_api_v1.MatchCondition{
-						{
-							Header: &contour_api_v1.HeaderMatchCondition{
-								Name:     "e-tag",
-								Contains: "def",
-							},
-						},
-						{
-							Prefix: "/",
-						},
-					},
-					Services: []contour_api_v1.Service{{
-						Name: "kuard",
-						Port: 8080,
-					}},
-				},
-			},
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/",
+				}},
+				AggregateServices: []contour_api_v1.AggregateService{{
+					Name: "kuard",
+					Port: 8080,
+				}, {
+					Name:     "kuarder",
+					Port:     8080,
+					Protocol: ref.To("h2c"),
+				}},
+			}},
 		},
 	}
 
-	// proxy2e is a proxy with two routes that both have a condition on the same
-	// header, one using Contains and one using NotContains.
-	proxy2e := &contour_api_v1.HTTPProxy{
+	// proxyFailover is a proxy with a service that fails over to two other services.
+	proxyFailover := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "example-com",
 			Namespace: "default",
@@ -7610,58 +7649,6 @@ func TestDAGInsert(t *testing.T) {
 			VirtualHost: &contour_api_v1.VirtualHost{
 				Fqdn: "example.com",
 			},
-			Routes: []contour_api_v1.Route{
-				{
-					Conditions: []contour_api_v1.MatchCondition{
-						{
-							Header: &contour_api_v1.HeaderMatchCondition{
-								Name:     "e-tag",
-								Contains: "abc",
-							},
-						},
-						{
-							Prefix: "/",
-						},
-					},
-					Services: []contour_api_v1.Service{{
-						Name: "kuard",
-						Port: 8080,
-					}},
-				},
-				{
-					Conditions: []contour_api_v1.MatchCondition{
-						{
-							Header: &contour_api_v1.HeaderMatchCondition{
-								Name:        "e-tag",
-								NotContains: "abc",
-							},
-						},
-						{
-							Prefix: "/",
-						},
-					},
-					Services: []contour_api_v1.Service{{
-						Name: "kuard",
-						Port: 8080,
-					}},
-				},
-			},
-		},
-	}
-
-	// proxy6 has TLS and does not specify min tls version
-	proxy6 := &contour_api_v1.HTTPProxy{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "example-com",
-			Namespace: "default",
-		},
-		Spec: contour_api_v1.HTTPProxySpec{
-			VirtualHost: &contour_api_v1.VirtualHost{
-				Fqdn: "foo.com",
-				TLS: &contour_api_v1.TLS{
-					SecretName: sec1.Name,
-				},
-			},
 			Routes: []contour_api_v1.Route{{
 				Conditions: []contour_api_v1.MatchCondition{{
 					Prefix: "/",
@@ -7669,12 +7656,20 @@ func TestDAGInsert(t *testing.T) {
 				Services: []contour_api_v1.Service{{
 					Name: "kuard",
 					Port: 8080,
+					Failover: []contour_api_v1.FailoverService{{
+						Name: "kuarder",
+						Port: 8080,
+					}, {
+						Name: "kuardest",
+						Port: 8080,
+					}},
 				}},
 			}},
 		},
 	}
 
-	proxy17 := &contour_api_v1.HTTPProxy{
+	// proxySubset is a proxy with a service that only sends traffic to a subset of its pods.
+	proxySubset := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "example-com",
 			Namespace: "default",
@@ -7688,18 +7683,17 @@ func TestDAGInsert(t *testing.T) {
 					Prefix: "/",
 				}},
 				Services: []contour_api_v1.Service{{
-					Name: "kuard",
-					Port: 8080,
-					UpstreamValidation: &contour_api_v1.UpstreamValidation{
-						CACertificate: cert1.Name,
-						SubjectName:   "example.com",
-					},
+					Name:   "kuard",
+					Port:   8080,
+					Subset: map[string]string{"version": "v2"},
 				}},
 			}},
 		},
 	}
-	protocolh2 := "h2"
-	proxy17h2 := &contour_api_v1.HTTPProxy{
+
+	// proxyH1 is a proxy that overrides the h2c protocol of its service
+	// and the global connect timeout.
+	proxyH1 := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "example-com",
 			Namespace: "default",
@@ -7712,19 +7706,21 @@ func TestDAGInsert(t *testing.T) {
 				Conditions: []contour_api_v1.MatchCondition{{
 					Prefix: "/",
 				}},
+				TimeoutPolicy: &contour_api_v1.TimeoutPolicy{
+					Connect: "500ms",
+				},
 				Services: []contour_api_v1.Service{{
 					Name:     "kuard",
-					Port:     8080,
-					Protocol: &protocolh2,
-					UpstreamValidation: &contour_api_v1.UpstreamValidation{
-						CACertificate: cert1.Name,
-						SubjectName:   "example.com",
-					},
+					Port:     80,
+					Protocol: ref.To("h1"),
 				}},
 			}},
 		},
 	}
-	proxy17UpstreamCACertDelegation := &contour_api_v1.HTTPProxy{
+
+	// proxyKeepalive is a proxy that sends HTTP/2 PING frames
+	// on the connections to its h2c service.
+	proxyKeepalive := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "example-com",
 			Namespace: "default",
@@ -7739,18 +7735,19 @@ func TestDAGInsert(t *testing.T) {
 				}},
 				Services: []contour_api_v1.Service{{
 					Name: "kuard",
-					Port: 8080,
-					UpstreamValidation: &contour_api_v1.UpstreamValidation{
-						CACertificate: fmt.Sprintf("%s/%s", cert2.Namespace, cert2.Name),
-						SubjectName:   "example.com",
+					Port: 80,
+					HTTP2KeepalivePolicy: &contour_api_v1.HTTP2KeepalivePolicy{
+						Interval: "30s",
+						Timeout:  "5s",
 					},
 				}},
 			}},
 		},
 	}
 
-	// proxy18 is downstream validation, HTTP route
-	proxy18 := &contour_api_v1.HTTPProxy{
+	// proxyMaintenance is a proxy in maintenance that keeps
+	// routing its health check path to its service.
+	proxyMaintenance := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "example-com",
 			Namespace: "default",
@@ -7758,11 +7755,10 @@ func TestDAGInsert(t *testing.T) {
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
 				Fqdn: "example.com",
-				TLS: &contour_api_v1.TLS{
-					SecretName: sec1.Name,
-					ClientValidation: &contour_api_v1.DownstreamValidation{
-						CACertificate: cert1.Name,
-					},
+				MaintenanceMode: &contour_api_v1.MaintenanceMode{
+					Enabled:                 true,
+					Body:                    "down for maintenance",
+					HealthCheckPathPrefixes: []string{"/healthz"},
 				},
 			},
 			Routes: []contour_api_v1.Route{{
@@ -7770,15 +7766,24 @@ func TestDAGInsert(t *testing.T) {
 					Prefix: "/",
 				}},
 				Services: []contour_api_v1.Service{{
-					Name: s1.Name,
+					Name: "kuard",
+					Port: 8080,
+				}},
+			}, {
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/healthz/ready",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: "kuard",
 					Port: 8080,
 				}},
 			}},
 		},
 	}
 
-	// proxy19 is downstream validation, TCP proxying
-	proxy19 := &contour_api_v1.HTTPProxy{
+	// proxyABTest is a proxy with a route that splits
+	// users between two services by their session cookie.
+	proxyABTest := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "example-com",
 			Namespace: "default",
@@ -7786,24 +7791,29 @@ func TestDAGInsert(t *testing.T) {
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
 				Fqdn: "example.com",
-				TLS: &contour_api_v1.TLS{
-					SecretName: sec1.Name,
-					ClientValidation: &contour_api_v1.DownstreamValidation{
-						CACertificate: cert1.Name,
-					},
-				},
 			},
-			TCPProxy: &contour_api_v1.TCPProxy{
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/",
+				}},
 				Services: []contour_api_v1.Service{{
-					Name: s1.Name,
+					Name: "kuard",
+					Port: 8080,
+				}, {
+					Name: "kuarder",
 					Port: 8080,
 				}},
-			},
+				ABTestPolicy: &contour_api_v1.ABTestPolicy{
+					CookieName: "session",
+					Salt:       "checkout",
+				},
+			}},
 		},
 	}
 
-	// proxy10 has a websocket route
-	proxy10 := &contour_api_v1.HTTPProxy{
+	// proxyRuntimeFraction is a proxy with a route that gets
+	// a percentage of the requests, and one for the rest.
+	proxyRuntimeFraction := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "example-com",
 			Namespace: "default",
@@ -7817,14 +7827,17 @@ func TestDAGInsert(t *testing.T) {
 					Prefix: "/",
 				}},
 				Services: []contour_api_v1.Service{{
-					Name: "kuard",
+					Name: "kuarder",
 					Port: 8080,
 				}},
+				RuntimeFraction: &contour_api_v1.RuntimeFraction{
+					Flag:           "new-kuard",
+					DefaultPercent: 10,
+				},
 			}, {
 				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/websocket",
+					Prefix: "/",
 				}},
-				EnableWebsockets: true,
 				Services: []contour_api_v1.Service{{
 					Name: "kuard",
 					Port: 8080,
@@ -7833,8 +7846,9 @@ func TestDAGInsert(t *testing.T) {
 		},
 	}
 
-	// proxy10b has a websocket route w/multiple upstreams
-	proxy10b := &contour_api_v1.HTTPProxy{
+	// proxyPriority is a proxy with a regex route that has to be
+	// matched before a prefix route, and an include with a priority.
+	proxyPriority := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "example-com",
 			Namespace: "default",
@@ -7843,19 +7857,24 @@ func TestDAGInsert(t *testing.T) {
 			VirtualHost: &contour_api_v1.VirtualHost{
 				Fqdn: "example.com",
 			},
+			Includes: []contour_api_v1.Include{{
+				Name:       "child",
+				Conditions: []contour_api_v1.MatchCondition{{Prefix: "/child"}},
+				Priority:   ref.To(int32(5)),
+			}},
 			Routes: []contour_api_v1.Route{{
 				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/",
+					Regex: "/api/.*/legacy",
 				}},
 				Services: []contour_api_v1.Service{{
-					Name: "kuard",
+					Name: "kuarder",
 					Port: 8080,
 				}},
+				Priority: 10,
 			}, {
 				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/websocket",
+					Prefix: "/api/v1",
 				}},
-				EnableWebsockets: true,
 				Services: []contour_api_v1.Service{{
 					Name: "kuard",
 					Port: 8080,
@@ -7864,95 +7883,83 @@ func TestDAGInsert(t *testing.T) {
 		},
 	}
 
-	// proxy12 tests mirroring
-	proxy12 := &contour_api_v1.HTTPProxy{
+	// proxyPriorityChild is included by proxyPriority, which
+	// replaces the priority of its route.
+	proxyPriorityChild := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "example-com",
-			Namespace: s1.Namespace,
+			Name:      "child",
+			Namespace: "default",
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
-			VirtualHost: &contour_api_v1.VirtualHost{
-				Fqdn: "example.com",
-			},
 			Routes: []contour_api_v1.Route{{
-				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/",
-				}},
 				Services: []contour_api_v1.Service{{
-					Name: s1.Name,
+					Name: "kuard",
 					Port: 8080,
-				}, {
-					Name:   s2.Name,
-					Port:   8080,
-					Mirror: true,
 				}},
+				Priority: 100,
 			}},
 		},
 	}
 
-	// proxy13 has two mirrors, invalid.
-	proxy13 := &contour_api_v1.HTTPProxy{
+	// proxyParameters includes proxyTemplate twice,
+	// with different values for its parameters.
+	proxyParameters := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "example-com",
-			Namespace: s1.Namespace,
+			Namespace: "default",
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
 				Fqdn: "example.com",
 			},
-			Routes: []contour_api_v1.Route{{
-				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/",
+			Includes: []contour_api_v1.Include{{
+				Name:       "template",
+				Conditions: []contour_api_v1.MatchCondition{{Prefix: "/a"}},
+				Parameters: []contour_api_v1.IncludeParameter{{
+					Name:  "service",
+					Value: "kuard",
 				}},
-				Services: []contour_api_v1.Service{{
-					Name: s1.Name,
-					Port: 8080,
-				}, {
-					Name:   s2.Name,
-					Port:   8080,
-					Mirror: true,
+			}, {
+				Name:       "template",
+				Conditions: []contour_api_v1.MatchCondition{{Prefix: "/b"}},
+				Parameters: []contour_api_v1.IncludeParameter{{
+					Name:  "service",
+					Value: "kuarder",
 				}, {
-					// it is legal to mention a service more that
-					// once, however it is not legal for more than one
-					// service to be marked as mirror.
-					Name:   s2.Name,
-					Port:   8080,
-					Mirror: true,
+					Name:  "version",
+					Value: "v2",
 				}},
 			}},
 		},
 	}
 
-	// proxy20 is downstream validation, skip cert validation
-	proxy20 := &contour_api_v1.HTTPProxy{
+	proxyTemplate := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "example-com",
+			Name:      "template",
 			Namespace: "default",
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
-			VirtualHost: &contour_api_v1.VirtualHost{
-				Fqdn: "example.com",
-				TLS: &contour_api_v1.TLS{
-					SecretName: sec1.Name,
-					ClientValidation: &contour_api_v1.DownstreamValidation{
-						SkipClientCertValidation: true,
-					},
-				},
-			},
+			Parameters: []contour_api_v1.HTTPProxyParameter{{
+				Name: "service",
+			}, {
+				Name:    "version",
+				Default: ref.To("v1"),
+			}},
 			Routes: []contour_api_v1.Route{{
 				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/",
+					Prefix: "/${version}",
 				}},
 				Services: []contour_api_v1.Service{{
-					Name: s1.Name,
+					Name: "${service}",
 					Port: 8080,
 				}},
 			}},
 		},
 	}
 
-	// proxy21 is downstream validation, skip cert validation, with a CA
-	proxy21 := &contour_api_v1.HTTPProxy{
+	// proxyOrNot is a proxy with a route with an Or
+	// condition and a Not condition.
+	proxyOrNot := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "example-com",
 			Namespace: "default",
@@ -7960,87 +7967,94 @@ func TestDAGInsert(t *testing.T) {
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
 				Fqdn: "example.com",
-				TLS: &contour_api_v1.TLS{
-					SecretName: sec1.Name,
-					ClientValidation: &contour_api_v1.DownstreamValidation{
-						SkipClientCertValidation: true,
-						CACertificate:            cert1.Name,
-					},
-				},
 			},
 			Routes: []contour_api_v1.Route{{
 				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/",
+					Or: []contour_api_v1.OrMatchCondition{{
+						Prefix: "/v1",
+					}, {
+						Prefix: "/v2",
+					}},
+				}, {
+					Not: &contour_api_v1.NegatedMatchCondition{
+						Header: &contour_api_v1.HeaderMatchCondition{
+							Name:     "user-agent",
+							Contains: "bot",
+						},
+					},
 				}},
 				Services: []contour_api_v1.Service{{
-					Name: s1.Name,
+					Name: "kuard",
 					Port: 8080,
 				}},
 			}},
 		},
 	}
 
-	// proxy22 is downstream validation with CRL check.
-	proxy22 := &contour_api_v1.HTTPProxy{
+	// proxyAliases is a proxy with an exact and a wildcard
+	// alias that share its routes and TLS configuration.
+	proxyAliases := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "example-com",
 			Namespace: "default",
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
-				Fqdn: "example.com",
+				Fqdn:    "example.com",
+				Aliases: []contour_api_v1.VirtualHostAlias{"www.example.com", "*.example.org"},
 				TLS: &contour_api_v1.TLS{
 					SecretName: sec1.Name,
-					ClientValidation: &contour_api_v1.DownstreamValidation{
-						CACertificate:             cert1.Name,
-						CertificateRevocationList: crl.Name,
-					},
 				},
 			},
 			Routes: []contour_api_v1.Route{{
-				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/",
-				}},
 				Services: []contour_api_v1.Service{{
-					Name: s1.Name,
+					Name: "kuard",
 					Port: 8080,
 				}},
 			}},
 		},
 	}
 
-	// proxy22 is downstream validation with CRL check but only for leaf-certificate.
-	proxy23 := &contour_api_v1.HTTPProxy{
+	secAlias := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "alias-secret",
+			Namespace: "default",
+		},
+		Type: v1.SecretTypeTLS,
+		Data: secretdata(fixture.CERTIFICATE, fixture.RSA_PRIVATE_KEY),
+	}
+
+	// proxyAliasSecrets is a proxy with an alias
+	// that has its own certificate.
+	proxyAliasSecrets := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "example-com",
 			Namespace: "default",
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
-				Fqdn: "example.com",
+				Fqdn:    "example.com",
+				Aliases: []contour_api_v1.VirtualHostAlias{"www.example.com", "example.org"},
 				TLS: &contour_api_v1.TLS{
 					SecretName: sec1.Name,
-					ClientValidation: &contour_api_v1.DownstreamValidation{
-						CACertificate:             cert1.Name,
-						CertificateRevocationList: crl.Name,
-						OnlyVerifyLeafCertCrl:     true,
-					},
+					AliasSecrets: []contour_api_v1.AliasSecret{{
+						Alias:      "example.org",
+						SecretName: secAlias.Name,
+					}},
 				},
 			},
 			Routes: []contour_api_v1.Route{{
-				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/",
-				}},
 				Services: []contour_api_v1.Service{{
-					Name: s1.Name,
+					Name: "kuard",
 					Port: 8080,
 				}},
 			}},
 		},
 	}
 
-	// proxy24 is downstream validation, optional cert validation
-	proxy24 := &contour_api_v1.HTTPProxy{
+	// proxyEmbargo is a proxy with a route
+	// that is embargoed until its launch.
+	proxyEmbargo := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "example-com",
 			Namespace: "default",
@@ -8048,28 +8062,33 @@ func TestDAGInsert(t *testing.T) {
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
 				Fqdn: "example.com",
-				TLS: &contour_api_v1.TLS{
-					SecretName: sec1.Name,
-					ClientValidation: &contour_api_v1.DownstreamValidation{
-						CACertificate:             cert1.Name,
-						OptionalClientCertificate: true,
-					},
-				},
 			},
 			Routes: []contour_api_v1.Route{{
 				Conditions: []contour_api_v1.MatchCondition{{
 					Prefix: "/",
 				}},
 				Services: []contour_api_v1.Service{{
-					Name: s1.Name,
+					Name: "kuard",
+					Port: 8080,
+				}},
+			}, {
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/launch",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: "kuard",
 					Port: 8080,
 				}},
+				Schedule: &contour_api_v1.RouteSchedule{
+					NotBefore: "2099-01-01T00:00:00Z",
+				},
 			}},
 		},
 	}
 
-	// proxy25 is downstream validation, fwd client cert details
-	proxy25 := &contour_api_v1.HTTPProxy{
+	// proxy2d is a proxy with two routes that have the same prefix and a Contains header
+	// condition on the same header, differing only in the value of the condition.
+	proxy2d := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "example-com",
 			Namespace: "default",
@@ -8077,229 +8096,212 @@ func TestDAGInsert(t *testing.T) {
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
 				Fqdn: "example.com",
-				TLS: &contour_api_v1.TLS{
-					SecretName: sec1.Name,
-					ClientValidation: &contour_api_v1.DownstreamValidation{
-						CACertificate: cert1.Name,
-						ForwardClientCertificate: &contour_api_v1.ClientCertificateDetails{
-							Subject: true,
-							Cert:    true,
-							Chain:   true,
-							DNS:     true,
-							URI:     true,
+			},
+			Routes: []contour_api_v1.Route{
+				{
+					Conditions: []contour_api_v1.MatchCondition{
+						{
+							Header: &contour_api_v1.HeaderMatchCondition{
+								Name:     "e-tag",
+								Contains: "abc",
+							},
+						},
+						{
+							Prefix: "/",
+						},
+					},
+					Services: []contour_api_v1.Service{{
+						Name: "kuard",
+						Port: 8080,
+					}},
+				},
+				{
+					Conditions: []contour_api_v1.MatchCondition{
+						{
+							Header: &contour_api_v1.HeaderMatchCondition{
+								Name:     "e-tag",
+								Contains: "def",
+							},
+						},
+						{
+							Prefix: "/",
 						},
 					},
+					Services: []contour_api_v1.Service{{
+						Name: "kuard",
+						Port: 8080,
+					}},
 				},
 			},
-			Routes: []contour_api_v1.Route{{
-				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/",
-				}},
-				Services: []contour_api_v1.Service{{
-					Name: s1.Name,
-					Port: 8080,
-				}},
-			}},
 		},
 	}
 
-	// invalid because tcpproxy both includes another and
-	// has a list of services.
-	proxy37 := &contour_api_v1.HTTPProxy{
+	// proxy2e is a proxy with two routes that both have a condition on the same
+	// header, one using Contains and one using NotContains.
+	proxy2e := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "simple",
-			Namespace: "roots",
+			Name:      "example-com",
+			Namespace: "default",
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
-				Fqdn: "passthrough.example.com",
-				TLS: &contour_api_v1.TLS{
-					Passthrough: true,
-				},
+				Fqdn: "example.com",
 			},
-			TCPProxy: &contour_api_v1.TCPProxy{
-				Include: &contour_api_v1.TCPProxyInclude{
-					Name:      "foo",
-					Namespace: "roots",
+			Routes: []contour_api_v1.Route{
+				{
+					Conditions: []contour_api_v1.MatchCondition{
+						{
+							Header: &contour_api_v1.HeaderMatchCondition{
+								Name:     "e-tag",
+								Contains: "abc",
+							},
+						},
+						{
+							Prefix: "/",
+						},
+					},
+					Services: []contour_api_v1.Service{{
+						Name: "kuard",
+						Port: 8080,
+					}},
+				},
+				{
+					Conditions: []contour_api_v1.MatchCondition{
+						{
+							Header: &contour_api_v1.HeaderMatchCondition{
+								Name:        "e-tag",
+								NotContains: "abc",
+							},
+						},
+						{
+							Prefix: "/",
+						},
+					},
+					Services: []contour_api_v1.Service{{
+						Name: "kuard",
+						Port: 8080,
+					}},
 				},
-				Services: []contour_api_v1.Service{{
-					Name: s1.Name,
-					Port: 8080,
-				}},
 			},
 		},
 	}
 
-	// Invalid because tcpproxy neither includes another httpproxy
-	// nor has a list of services.
-	proxy37a := &contour_api_v1.HTTPProxy{
+	// proxy6 has TLS and does not specify min tls version
+	proxy6 := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "simple",
-			Namespace: "roots",
+			Name:      "example-com",
+			Namespace: "default",
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
-				Fqdn: "passthrough.example.com",
+				Fqdn: "foo.com",
 				TLS: &contour_api_v1.TLS{
-					Passthrough: true,
+					SecretName: sec1.Name,
 				},
 			},
-			TCPProxy: &contour_api_v1.TCPProxy{},
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: "kuard",
+					Port: 8080,
+				}},
+			}},
 		},
 	}
 
-	// proxy38 is invalid when combined with proxy39
-	// as the latter is a root.
-	proxy38 := &contour_api_v1.HTTPProxy{
+	proxy17 := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "simple",
-			Namespace: "roots",
+			Name:      "example-com",
+			Namespace: "default",
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
-				Fqdn: "passthrough.example.com",
-				TLS: &contour_api_v1.TLS{
-					Passthrough: true,
-				},
-			},
-			TCPProxy: &contour_api_v1.TCPProxy{
-				Include: &contour_api_v1.TCPProxyInclude{
-					Name:      "foo",
-					Namespace: s1.Namespace,
-				},
-			},
-		},
-	}
-
-	proxy39 := &contour_api_v1.HTTPProxy{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "foo",
-			Namespace: s1.Namespace,
-		},
-		Spec: contour_api_v1.HTTPProxySpec{
-			VirtualHost: &contour_api_v1.VirtualHost{
-				Fqdn: "www.example.com",
-				TLS: &contour_api_v1.TLS{
-					Passthrough: true,
-				},
+				Fqdn: "example.com",
 			},
-			TCPProxy: &contour_api_v1.TCPProxy{
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/",
+				}},
 				Services: []contour_api_v1.Service{{
-					Name: s1.Name,
+					Name: "kuard",
 					Port: 8080,
+					UpstreamValidation: &contour_api_v1.UpstreamValidation{
+						CACertificate: cert1.Name,
+						SubjectName:   "example.com",
+					},
 				}},
-			},
-		},
-	}
-
-	// proxy39broot is a valid TCPProxy which includes to another TCPProxy
-	proxy39broot := &contour_api_v1.HTTPProxy{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "root",
-			Namespace: s1.Namespace,
-		},
-		Spec: contour_api_v1.HTTPProxySpec{
-			VirtualHost: &contour_api_v1.VirtualHost{
-				Fqdn: "www.example.com",
-				TLS: &contour_api_v1.TLS{
-					Passthrough: true,
-				},
-			},
-			TCPProxy: &contour_api_v1.TCPProxy{
-				Include: &contour_api_v1.TCPProxyInclude{
-					Name:      "foo",
-					Namespace: s1.Namespace,
-				},
-			},
+			}},
 		},
 	}
-
-	proxy39brootplural := &contour_api_v1.HTTPProxy{
+	protocolh2 := "h2"
+	proxy17h2 := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "root",
-			Namespace: s1.Namespace,
+			Name:      "example-com",
+			Namespace: "default",
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
-				Fqdn: "www.example.com",
-				TLS: &contour_api_v1.TLS{
-					Passthrough: true,
-				},
-			},
-			TCPProxy: &contour_api_v1.TCPProxy{
-				IncludesDeprecated: &contour_api_v1.TCPProxyInclude{
-					Name:      "foo",
-					Namespace: s1.Namespace,
-				},
+				Fqdn: "example.com",
 			},
-		},
-	}
-
-	proxy39bchild := &contour_api_v1.HTTPProxy{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "foo",
-			Namespace: s1.Namespace,
-		},
-		Spec: contour_api_v1.HTTPProxySpec{
-			TCPProxy: &contour_api_v1.TCPProxy{
-				Services: []contour_api_v1.Service{{
-					Name: s1.Name,
-					Port: 8080,
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/",
 				}},
-			},
-		},
-	}
-
-	proxy40 := &contour_api_v1.HTTPProxy{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "foo",
-			Namespace: s1.Namespace,
-		},
-		Spec: contour_api_v1.HTTPProxySpec{
-			TCPProxy: &contour_api_v1.TCPProxy{
 				Services: []contour_api_v1.Service{{
-					Name: s1.Name,
-					Port: 8080,
+					Name:     "kuard",
+					Port:     8080,
+					Protocol: &protocolh2,
+					UpstreamValidation: &contour_api_v1.UpstreamValidation{
+						CACertificate: cert1.Name,
+						SubjectName:   "example.com",
+					},
 				}},
-			},
+			}},
 		},
 	}
-
-	// issue 2309, each route must have at least one service
-	proxy41 := &contour_api_v1.HTTPProxy{
+	proxy17UpstreamCACertDelegation := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "missing-service",
-			Namespace: s1.Namespace,
+			Name:      "example-com",
+			Namespace: "default",
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
-				Fqdn: "missing-service.example.com",
+				Fqdn: "example.com",
 			},
 			Routes: []contour_api_v1.Route{{
 				Conditions: []contour_api_v1.MatchCondition{{
 					Prefix: "/",
 				}},
-				Services: nil, // missing
+				Services: []contour_api_v1.Service{{
+					Name: "kuard",
+					Port: 8080,
+					UpstreamValidation: &contour_api_v1.UpstreamValidation{
+						CACertificate: fmt.Sprintf("%s/%s", cert2.Namespace, cert2.Name),
+						SubjectName:   "example.com",
+					},
+				}},
 			}},
 		},
 	}
 
-	proxy100 := &contour_api_v1.HTTPProxy{
+	// proxy18 is downstream validation, HTTP route
+	proxy18 := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "example-com",
-			Namespace: s1.Namespace,
+			Namespace: "default",
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
 				Fqdn: "example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName: sec1.Name,
+					ClientValidation: &contour_api_v1.DownstreamValidation{
+						CACertificate: cert1.Name,
+					},
+				},
 			},
-			Includes: []contour_api_v1.Include{{
-				Name:      "marketingwww",
-				Namespace: "marketing",
-				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/blog",
-				}},
-			}},
 			Routes: []contour_api_v1.Route{{
 				Conditions: []contour_api_v1.MatchCondition{{
 					Prefix: "/",
@@ -8312,89 +8314,121 @@ func TestDAGInsert(t *testing.T) {
 		},
 	}
 
-	proxy100a := &contour_api_v1.HTTPProxy{
+	// proxy19 is downstream validation, TCP proxying
+	proxy19 := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "marketingwww",
-			Namespace: "marketing",
+			Name:      "example-com",
+			Namespace: "default",
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
-			Routes: []contour_api_v1.Route{{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName: sec1.Name,
+					ClientValidation: &contour_api_v1.DownstreamValidation{
+						CACertificate: cert1.Name,
+					},
+				},
+			},
+			TCPProxy: &contour_api_v1.TCPProxy{
 				Services: []contour_api_v1.Service{{
-					Name: "blog",
+					Name: s1.Name,
 					Port: 8080,
 				}},
-			}},
+			},
 		},
 	}
 
-	proxy100b := &contour_api_v1.HTTPProxy{
+	// proxy10 has a websocket route
+	proxy10 := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "marketingwww",
-			Namespace: "marketing",
+			Name:      "example-com",
+			Namespace: "default",
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
 			Routes: []contour_api_v1.Route{{
 				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/infotech",
+					Prefix: "/",
 				}},
 				Services: []contour_api_v1.Service{{
-					Name: "blog",
+					Name: "kuard",
+					Port: 8080,
+				}},
+			}, {
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/websocket",
+				}},
+				EnableWebsockets: true,
+				Services: []contour_api_v1.Service{{
+					Name: "kuard",
 					Port: 8080,
 				}},
 			}},
 		},
 	}
 
-	proxy100c := &contour_api_v1.HTTPProxy{
+	// proxy10b has a websocket route w/multiple upstreams
+	proxy10b := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "marketingwww",
-			Namespace: "marketing",
+			Name:      "example-com",
+			Namespace: "default",
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
-			Includes: []contour_api_v1.Include{{
-				Name:      "marketingit",
-				Namespace: "it",
-				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/it",
-				}},
-			}},
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
 			Routes: []contour_api_v1.Route{{
 				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/infotech",
+					Prefix: "/",
 				}},
 				Services: []contour_api_v1.Service{{
-					Name: "blog",
+					Name: "kuard",
 					Port: 8080,
 				}},
 			}, {
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/websocket",
+				}},
+				EnableWebsockets: true,
 				Services: []contour_api_v1.Service{{
-					Name: "blog",
+					Name: "kuard",
 					Port: 8080,
 				}},
 			}},
 		},
 	}
 
-	proxy100d := &contour_api_v1.HTTPProxy{
+	// proxy12 tests mirroring
+	proxy12 := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "marketingit",
-			Namespace: "it",
+			Name:      "example-com",
+			Namespace: s1.Namespace,
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
 			Routes: []contour_api_v1.Route{{
 				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/foo",
+					Prefix: "/",
 				}},
 				Services: []contour_api_v1.Service{{
-					Name: "blog",
+					Name: s1.Name,
 					Port: 8080,
+				}, {
+					Name:   s2.Name,
+					Port:   8080,
+					Mirror: true,
 				}},
 			}},
 		},
 	}
 
-	// proxy101 and proxy101a test inclusion without a specified namespace.
-	proxy101 := &contour_api_v1.HTTPProxy{
+	// proxy13 has two mirrors, invalid.
+	proxy13 := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "example-com",
 			Namespace: s1.Namespace,
@@ -8403,12 +8437,6 @@ func TestDAGInsert(t *testing.T) {
 			VirtualHost: &contour_api_v1.VirtualHost{
 				Fqdn: "example.com",
 			},
-			Includes: []contour_api_v1.Include{{
-				Name: "kuarder",
-				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/kuarder",
-				}},
-			}},
 			Routes: []contour_api_v1.Route{{
 				Conditions: []contour_api_v1.MatchCondition{{
 					Prefix: "/",
@@ -8416,41 +8444,41 @@ func TestDAGInsert(t *testing.T) {
 				Services: []contour_api_v1.Service{{
 					Name: s1.Name,
 					Port: 8080,
+				}, {
+					Name:   s2.Name,
+					Port:   8080,
+					Mirror: true,
+				}, {
+					// it is legal to mention a service more that
+					// once, however it is not legal for more than one
+					// service to be marked as mirror.
+					Name:   s2.Name,
+					Port:   8080,
+					Mirror: true,
 				}},
 			}},
 		},
 	}
 
-	proxy101a := &contour_api_v1.HTTPProxy{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "kuarder",
-			Namespace: proxy101.Namespace,
-		},
-		Spec: contour_api_v1.HTTPProxySpec{
-			Routes: []contour_api_v1.Route{{
-				Services: []contour_api_v1.Service{{
-					Name: s2.Name,
-					Port: 8080,
-				}},
-			}},
-		},
-	}
-
-	// invalid because two prefix conditions on route.
-	proxy102 := &contour_api_v1.HTTPProxy{
+	// proxy20 is downstream validation, skip cert validation
+	proxy20 := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "example-com",
-			Namespace: s1.Namespace,
+			Namespace: "default",
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
 				Fqdn: "example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName: sec1.Name,
+					ClientValidation: &contour_api_v1.DownstreamValidation{
+						SkipClientCertValidation: true,
+					},
+				},
 			},
 			Routes: []contour_api_v1.Route{{
 				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/v1",
-				}, {
-					Prefix: "/api",
+					Prefix: "/",
 				}},
 				Services: []contour_api_v1.Service{{
 					Name: s1.Name,
@@ -8460,26 +8488,27 @@ func TestDAGInsert(t *testing.T) {
 		},
 	}
 
-	// invalid because two prefix conditions on include.
-	proxy103 := &contour_api_v1.HTTPProxy{
+	// proxy21 is downstream validation, skip cert validation, with a CA
+	proxy21 := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "example-com",
-			Namespace: s1.Namespace,
+			Namespace: "default",
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
 				Fqdn: "example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName: sec1.Name,
+					ClientValidation: &contour_api_v1.DownstreamValidation{
+						SkipClientCertValidation: true,
+						CACertificate:            cert1.Name,
+					},
+				},
 			},
-			Includes: []contour_api_v1.Include{{
-				Name:      "www",
-				Namespace: "teama",
+			Routes: []contour_api_v1.Route{{
 				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/v1",
-				}, {
-					Prefix: "/api",
+					Prefix: "/",
 				}},
-			}},
-			Routes: []contour_api_v1.Route{{
 				Services: []contour_api_v1.Service{{
 					Name: s1.Name,
 					Port: 8080,
@@ -8488,36 +8517,23 @@ func TestDAGInsert(t *testing.T) {
 		},
 	}
 
-	proxy103a := &contour_api_v1.HTTPProxy{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "www",
-			Namespace: "teama",
-		},
-		Spec: contour_api_v1.HTTPProxySpec{
-			Routes: []contour_api_v1.Route{{
-				Services: []contour_api_v1.Service{{
-					Name: s12.Name,
-					Port: 8080,
-				}},
-			}},
-		},
-	}
-
-	proxy104 := &contour_api_v1.HTTPProxy{
+	// proxy22 is downstream validation with CRL check.
+	proxy22 := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "example-com",
-			Namespace: s1.Namespace,
+			Namespace: "default",
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
 				Fqdn: "example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName: sec1.Name,
+					ClientValidation: &contour_api_v1.DownstreamValidation{
+						CACertificate:             cert1.Name,
+						CertificateRevocationList: crl.Name,
+					},
+				},
 			},
-			Includes: []contour_api_v1.Include{{
-				Name: "kuarder",
-				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/kuarder",
-				}},
-			}},
 			Routes: []contour_api_v1.Route{{
 				Conditions: []contour_api_v1.MatchCondition{{
 					Prefix: "/",
@@ -8530,36 +8546,24 @@ func TestDAGInsert(t *testing.T) {
 		},
 	}
 
-	proxy104a := &contour_api_v1.HTTPProxy{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "kuarder",
-			Namespace: proxy104.Namespace,
-		},
-		Spec: contour_api_v1.HTTPProxySpec{
-			Routes: []contour_api_v1.Route{{
-				Services: []contour_api_v1.Service{{
-					Name: s2.Name,
-					Port: 8080,
-				}},
-			}},
-		},
-	}
-
-	proxy105 := &contour_api_v1.HTTPProxy{
+	// proxy22 is downstream validation with CRL check but only for leaf-certificate.
+	proxy23 := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "example-com",
-			Namespace: s1.Namespace,
+			Namespace: "default",
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
 				Fqdn: "example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName: sec1.Name,
+					ClientValidation: &contour_api_v1.DownstreamValidation{
+						CACertificate:             cert1.Name,
+						CertificateRevocationList: crl.Name,
+						OnlyVerifyLeafCertCrl:     true,
+					},
+				},
 			},
-			Includes: []contour_api_v1.Include{{
-				Name: "kuarder",
-				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/kuarder",
-				}},
-			}},
 			Routes: []contour_api_v1.Route{{
 				Conditions: []contour_api_v1.MatchCondition{{
 					Prefix: "/",
@@ -8572,39 +8576,23 @@ func TestDAGInsert(t *testing.T) {
 		},
 	}
 
-	proxy105a := &contour_api_v1.HTTPProxy{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "kuarder",
-			Namespace: proxy105.Namespace,
-		},
-		Spec: contour_api_v1.HTTPProxySpec{
-			Routes: []contour_api_v1.Route{{
-				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/",
-				}},
-				Services: []contour_api_v1.Service{{
-					Name: s2.Name,
-					Port: 8080,
-				}},
-			}},
-		},
-	}
-
-	proxy106 := &contour_api_v1.HTTPProxy{
+	// proxy24 is downstream validation, optional cert validation
+	proxy24 := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "example-com",
-			Namespace: s1.Namespace,
+			Namespace: "default",
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
 				Fqdn: "example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName: sec1.Name,
+					ClientValidation: &contour_api_v1.DownstreamValidation{
+						CACertificate:             cert1.Name,
+						OptionalClientCertificate: true,
+					},
+				},
 			},
-			Includes: []contour_api_v1.Include{{
-				Name: "kuarder",
-				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/kuarder/",
-				}},
-			}},
 			Routes: []contour_api_v1.Route{{
 				Conditions: []contour_api_v1.MatchCondition{{
 					Prefix: "/",
@@ -8617,406 +8605,379 @@ func TestDAGInsert(t *testing.T) {
 		},
 	}
 
-	proxy106a := &contour_api_v1.HTTPProxy{
+	// proxy25 is downstream validation, fwd client cert details
+	proxy25 := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "kuarder",
-			Namespace: proxy105.Namespace,
+			Name:      "example-com",
+			Namespace: "default",
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName: sec1.Name,
+					ClientValidation: &contour_api_v1.DownstreamValidation{
+						CACertificate: cert1.Name,
+						ForwardClientCertificate: &contour_api_v1.ClientCertificateDetails{
+							Subject: true,
+							Cert:    true,
+							Chain:   true,
+							DNS:     true,
+							URI:     true,
+						},
+					},
+				},
+			},
 			Routes: []contour_api_v1.Route{{
 				Conditions: []contour_api_v1.MatchCondition{{
 					Prefix: "/",
 				}},
 				Services: []contour_api_v1.Service{{
-					Name: s2.Name,
+					Name: s1.Name,
 					Port: 8080,
 				}},
 			}},
 		},
 	}
 
-	proxy107 := &contour_api_v1.HTTPProxy{
+	// invalid because tcpproxy both includes another and
+	// has a list of services.
+	proxy37 := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "example-com",
-			Namespace: s1.Namespace,
+			Name:      "simple",
+			Namespace: "roots",
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
-				Fqdn: "example.com",
+				Fqdn: "passthrough.example.com",
+				TLS: &contour_api_v1.TLS{
+					Passthrough: true,
+				},
 			},
-			Includes: []contour_api_v1.Include{{
-				Name: "kuarder",
-				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/kuarder",
-				}},
-			}},
-			Routes: []contour_api_v1.Route{{
-				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/",
-				}},
+			TCPProxy: &contour_api_v1.TCPProxy{
+				Include: &contour_api_v1.TCPProxyInclude{
+					Name:      "foo",
+					Namespace: "roots",
+				},
 				Services: []contour_api_v1.Service{{
 					Name: s1.Name,
 					Port: 8080,
 				}},
-			}},
+			},
 		},
 	}
 
-	proxy107a := &contour_api_v1.HTTPProxy{
+	// Invalid because tcpproxy neither includes another httpproxy
+	// nor has a list of services.
+	proxy37a := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "kuarder",
-			Namespace: proxy105.Namespace,
+			Name:      "simple",
+			Namespace: "roots",
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
-			Routes: []contour_api_v1.Route{{
-				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/withavengeance",
-				}},
-				Services: []contour_api_v1.Service{{
-					Name: s2.Name,
-					Port: 8080,
-				}},
-			}},
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "passthrough.example.com",
+				TLS: &contour_api_v1.TLS{
+					Passthrough: true,
+				},
+			},
+			TCPProxy: &contour_api_v1.TCPProxy{},
 		},
 	}
 
-	// proxy108 and proxy108a test duplicate conditions on include
-	proxy108 := &contour_api_v1.HTTPProxy{
+	// proxy38 is invalid when combined with proxy39
+	// as the latter is a root.
+	proxy38 := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "root",
-			Namespace: s1.Namespace,
+			Name:      "simple",
+			Namespace: "roots",
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
-				Fqdn: "example.com",
-			},
-			Includes: []contour_api_v1.Include{{
-				Name:      "blogteama",
-				Namespace: "teama",
-				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/blog",
-					Header: &contour_api_v1.HeaderMatchCondition{
-						Name:     "x-header",
-						Contains: "abc",
-					},
-				}},
-			}, {
-				Name:      "blogteama",
-				Namespace: "teamb",
-				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/blog",
-					Header: &contour_api_v1.HeaderMatchCondition{
-						Name:     "x-header",
-						Contains: "abc",
-					},
-				}},
-			}},
-			Routes: []contour_api_v1.Route{{
-				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/",
-				}},
+				Fqdn: "passthrough.example.com",
+				TLS: &contour_api_v1.TLS{
+					Passthrough: true,
+				},
+			},
+			TCPProxy: &contour_api_v1.TCPProxy{
+				Include: &contour_api_v1.TCPProxyInclude{
+					Name:      "foo",
+					Namespace: s1.Namespace,
+				},
+			},
+		},
+	}
+
+	proxy39 := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: s1.Namespace,
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "www.example.com",
+				TLS: &contour_api_v1.TLS{
+					Passthrough: true,
+				},
+			},
+			TCPProxy: &contour_api_v1.TCPProxy{
 				Services: []contour_api_v1.Service{{
 					Name: s1.Name,
 					Port: 8080,
 				}},
-			}},
+			},
 		},
 	}
 
-	proxy108a := &contour_api_v1.HTTPProxy{
+	// proxy39broot is a valid TCPProxy which includes to another TCPProxy
+	proxy39broot := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "blogteama",
-			Namespace: "teama",
+			Name:      "root",
+			Namespace: s1.Namespace,
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
-			Routes: []contour_api_v1.Route{{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "www.example.com",
+				TLS: &contour_api_v1.TLS{
+					Passthrough: true,
+				},
+			},
+			TCPProxy: &contour_api_v1.TCPProxy{
+				Include: &contour_api_v1.TCPProxyInclude{
+					Name:      "foo",
+					Namespace: s1.Namespace,
+				},
+			},
+		},
+	}
+
+	proxy39brootplural := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "root",
+			Namespace: s1.Namespace,
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "www.example.com",
+				TLS: &contour_api_v1.TLS{
+					Passthrough: true,
+				},
+			},
+			TCPProxy: &contour_api_v1.TCPProxy{
+				IncludesDeprecated: &contour_api_v1.TCPProxyInclude{
+					Name:      "foo",
+					Namespace: s1.Namespace,
+				},
+			},
+		},
+	}
+
+	proxy39bchild := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: s1.Namespace,
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			TCPProxy: &contour_api_v1.TCPProxy{
 				Services: []contour_api_v1.Service{{
-					Name: s12.Name,
+					Name: s1.Name,
 					Port: 8080,
 				}},
-			}},
+			},
 		},
 	}
 
-	proxy108b := &contour_api_v1.HTTPProxy{
+	proxy40 := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "blogteamb",
-			Namespace: "teamb",
+			Name:      "foo",
+			Namespace: s1.Namespace,
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
-			Routes: []contour_api_v1.Route{{
+			TCPProxy: &contour_api_v1.TCPProxy{
 				Services: []contour_api_v1.Service{{
-					Name: s13.Name,
+					Name: s1.Name,
 					Port: 8080,
 				}},
-			}},
+			},
 		},
 	}
 
-	proxyReplaceHostHeaderRoute := &contour_api_v1.HTTPProxy{
+	// issue 2309, each route must have at least one service
+	proxy41 := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "example-com",
-			Namespace: "default",
+			Name:      "missing-service",
+			Namespace: s1.Namespace,
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
-				Fqdn: "example.com",
+				Fqdn: "missing-service.example.com",
 			},
 			Routes: []contour_api_v1.Route{{
 				Conditions: []contour_api_v1.MatchCondition{{
 					Prefix: "/",
 				}},
-				Services: []contour_api_v1.Service{{
-					Name: "nginx",
-					Port: 80,
-				}},
-				RequestHeadersPolicy: &contour_api_v1.HeadersPolicy{
-					Set: []contour_api_v1.HeaderValue{{
-						Name:  "Host",
-						Value: "bar.com",
-					}},
-				},
+				Services: nil, // missing
 			}},
 		},
 	}
 
-	proxyReplaceHostHeaderService := &contour_api_v1.HTTPProxy{
+	proxy100 := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "example-com",
-			Namespace: "default",
+			Namespace: s1.Namespace,
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
 				Fqdn: "example.com",
 			},
+			Includes: []contour_api_v1.Include{{
+				Name:      "marketingwww",
+				Namespace: "marketing",
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/blog",
+				}},
+			}},
 			Routes: []contour_api_v1.Route{{
 				Conditions: []contour_api_v1.MatchCondition{{
 					Prefix: "/",
 				}},
 				Services: []contour_api_v1.Service{{
-					Name: "nginx",
-					Port: 80,
-					RequestHeadersPolicy: &contour_api_v1.HeadersPolicy{
-						Set: []contour_api_v1.HeaderValue{{
-							Name:  "Host",
-							Value: "bar.com",
-						}},
-					},
+					Name: s1.Name,
+					Port: 8080,
 				}},
 			}},
 		},
 	}
 
-	proxyReplaceHostHeaderMultiple := &contour_api_v1.HTTPProxy{
+	proxy100a := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "example-com",
-			Namespace: "default",
+			Name:      "marketingwww",
+			Namespace: "marketing",
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
-			VirtualHost: &contour_api_v1.VirtualHost{
-				Fqdn: "example.com",
-			},
 			Routes: []contour_api_v1.Route{{
-				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/",
-				}},
 				Services: []contour_api_v1.Service{{
-					Name: "nginx",
-					Port: 80,
+					Name: "blog",
+					Port: 8080,
 				}},
-				RequestHeadersPolicy: &contour_api_v1.HeadersPolicy{
-					Set: []contour_api_v1.HeaderValue{{
-						Name:  "Host",
-						Value: "bar.com",
-					}, {
-						Name:  "x-header",
-						Value: "bar.com",
-					}, {
-						Name:  "y-header",
-						Value: "zed.com",
-					}},
-				},
 			}},
 		},
 	}
 
-	proxyReplaceNonHostHeader := &contour_api_v1.HTTPProxy{
+	proxy100b := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "example-com",
-			Namespace: "default",
+			Name:      "marketingwww",
+			Namespace: "marketing",
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
-			VirtualHost: &contour_api_v1.VirtualHost{
-				Fqdn: "example.com",
-			},
 			Routes: []contour_api_v1.Route{{
 				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/",
+					Prefix: "/infotech",
 				}},
 				Services: []contour_api_v1.Service{{
-					Name: "nginx",
-					Port: 80,
+					Name: "blog",
+					Port: 8080,
 				}},
-				RequestHeadersPolicy: &contour_api_v1.HeadersPolicy{
-					Set: []contour_api_v1.HeaderValue{{
-						Name:  "x-header",
-						Value: "bar.com",
-					}},
-				},
 			}},
 		},
 	}
 
-	proxyReplaceHeaderEmptyValue := &contour_api_v1.HTTPProxy{
+	proxy100c := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "example-com",
-			Namespace: "default",
+			Name:      "marketingwww",
+			Namespace: "marketing",
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
-			VirtualHost: &contour_api_v1.VirtualHost{
-				Fqdn: "example.com",
-			},
+			Includes: []contour_api_v1.Include{{
+				Name:      "marketingit",
+				Namespace: "it",
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/it",
+				}},
+			}},
 			Routes: []contour_api_v1.Route{{
 				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/",
+					Prefix: "/infotech",
 				}},
 				Services: []contour_api_v1.Service{{
-					Name: "nginx",
-					Port: 80,
+					Name: "blog",
+					Port: 8080,
+				}},
+			}, {
+				Services: []contour_api_v1.Service{{
+					Name: "blog",
+					Port: 8080,
 				}},
-				RequestHeadersPolicy: &contour_api_v1.HeadersPolicy{
-					Set: []contour_api_v1.HeaderValue{{
-						Name: "x-header",
-					}},
-				},
 			}},
 		},
 	}
 
-	proxyCookieLoadBalancer := &contour_api_v1.HTTPProxy{
+	proxy100d := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "example-com",
-			Namespace: "default",
+			Name:      "marketingit",
+			Namespace: "it",
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
-			VirtualHost: &contour_api_v1.VirtualHost{
-				Fqdn: "example.com",
-			},
 			Routes: []contour_api_v1.Route{{
 				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/",
+					Prefix: "/foo",
 				}},
 				Services: []contour_api_v1.Service{{
-					Name: "nginx",
-					Port: 80,
+					Name: "blog",
+					Port: 8080,
 				}},
-				LoadBalancerPolicy: &contour_api_v1.LoadBalancerPolicy{
-					Strategy: "Cookie",
-				},
 			}},
 		},
 	}
 
-	proxyLoadBalancerHashPolicyHeader := &contour_api_v1.HTTPProxy{
+	// proxy101 and proxy101a test inclusion without a specified namespace.
+	proxy101 := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "example-com",
-			Namespace: "default",
+			Namespace: s1.Namespace,
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
 				Fqdn: "example.com",
 			},
+			Includes: []contour_api_v1.Include{{
+				Name: "kuarder",
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/kuarder",
+				}},
+			}},
 			Routes: []contour_api_v1.Route{{
 				Conditions: []contour_api_v1.MatchCondition{{
 					Prefix: "/",
 				}},
 				Services: []contour_api_v1.Service{{
-					Name: "nginx",
-					Port: 80,
+					Name: s1.Name,
+					Port: 8080,
 				}},
-				LoadBalancerPolicy: &contour_api_v1.LoadBalancerPolicy{
-					Strategy: "RequestHash",
-					RequestHashPolicies: []contour_api_v1.RequestHashPolicy{
-						{
-							Terminal: true,
-							HeaderHashOptions: &contour_api_v1.HeaderHashOptions{
-								HeaderName: "X-Some-Header",
-							},
-						},
-						{
-							// Lower case but duplicated, should be ignored.
-							HeaderHashOptions: &contour_api_v1.HeaderHashOptions{
-								HeaderName: "x-some-header",
-							},
-						},
-						{
-							HeaderHashOptions: nil,
-						},
-						{
-							HeaderHashOptions: &contour_api_v1.HeaderHashOptions{
-								HeaderName: "X-Some-Other-Header",
-							},
-						},
-						{
-							HeaderHashOptions: &contour_api_v1.HeaderHashOptions{
-								HeaderName: "",
-							},
-						},
-					},
-				},
 			}},
 		},
 	}
 
-	proxyLoadBalancerHashPolicyQueryParameter := &contour_api_v1.HTTPProxy{
+	proxy101a := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "example-com",
-			Namespace: "default",
+			Name:      "kuarder",
+			Namespace: proxy101.Namespace,
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
-			VirtualHost: &contour_api_v1.VirtualHost{
-				Fqdn: "example.com",
-			},
 			Routes: []contour_api_v1.Route{{
-				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/",
-				}},
 				Services: []contour_api_v1.Service{{
-					Name: "nginx",
-					Port: 80,
+					Name: s2.Name,
+					Port: 8080,
 				}},
-				LoadBalancerPolicy: &contour_api_v1.LoadBalancerPolicy{
-					Strategy: "RequestHash",
-					RequestHashPolicies: []contour_api_v1.RequestHashPolicy{
-						{
-							Terminal: true,
-							QueryParameterHashOptions: &contour_api_v1.QueryParameterHashOptions{
-								ParameterName: "something",
-							},
-						},
-						{
-							QueryParameterHashOptions: nil,
-						},
-						{
-							QueryParameterHashOptions: &contour_api_v1.QueryParameterHashOptions{
-								ParameterName: "other",
-							},
-						},
-						{
-							QueryParameterHashOptions: &contour_api_v1.QueryParameterHashOptions{
-								ParameterName: "",
-							},
-						},
-					},
-				},
 			}},
 		},
 	}
 
-	proxyLoadBalancerHashPolicySourceIP := &contour_api_v1.HTTPProxy{
+	// invalid because two prefix conditions on route.
+	proxy102 := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "example-com",
-			Namespace: "default",
+			Namespace: s1.Namespace,
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
@@ -9024,254 +8985,166 @@ func TestDAGInsert(t *testing.T) {
 			},
 			Routes: []contour_api_v1.Route{{
 				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/",
+					Prefix: "/v1",
+				}, {
+					Prefix: "/api",
 				}},
 				Services: []contour_api_v1.Service{{
-					Name: "nginx",
-					Port: 80,
+					Name: s1.Name,
+					Port: 8080,
 				}},
-				LoadBalancerPolicy: &contour_api_v1.LoadBalancerPolicy{
-					Strategy: "RequestHash",
-					RequestHashPolicies: []contour_api_v1.RequestHashPolicy{
-						{
-							// Ensure header hash policies and source IP hashing
-							// can coexist.
-							HeaderHashOptions: &contour_api_v1.HeaderHashOptions{
-								HeaderName: "X-Some-Header",
-							},
-						},
-						{
-							Terminal:     true,
-							HashSourceIP: true,
-						},
-						{
-							// Duplicate should be ignored.
-							HashSourceIP: true,
-						},
-					},
-				},
 			}},
 		},
 	}
 
-	proxyLoadBalancerHashPolicyAllInvalid := &contour_api_v1.HTTPProxy{
+	// invalid because two prefix conditions on include.
+	proxy103 := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "example-com",
-			Namespace: "default",
+			Namespace: s1.Namespace,
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
 				Fqdn: "example.com",
 			},
-			Routes: []contour_api_v1.Route{{
+			Includes: []contour_api_v1.Include{{
+				Name:      "www",
+				Namespace: "teama",
 				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/",
+					Prefix: "/v1",
+				}, {
+					Prefix: "/api",
 				}},
+			}},
+			Routes: []contour_api_v1.Route{{
 				Services: []contour_api_v1.Service{{
-					Name: "nginx",
-					Port: 80,
+					Name: s1.Name,
+					Port: 8080,
 				}},
-				LoadBalancerPolicy: &contour_api_v1.LoadBalancerPolicy{
-					Strategy: "RequestHash",
-					RequestHashPolicies: []contour_api_v1.RequestHashPolicy{
-						{
-							HeaderHashOptions: nil,
-						},
-						{
-							HeaderHashOptions: &contour_api_v1.HeaderHashOptions{
-								HeaderName: "",
-							},
-						},
-						{
-							HeaderHashOptions: &contour_api_v1.HeaderHashOptions{
-								HeaderName: "X-Foo",
-							},
-							HashSourceIP: true,
-						},
-						{
-							HeaderHashOptions: &contour_api_v1.HeaderHashOptions{
-								HeaderName: "X-Foo",
-							},
-							QueryParameterHashOptions: &contour_api_v1.QueryParameterHashOptions{
-								ParameterName: "something",
-							},
-						},
-						{
-							HeaderHashOptions: &contour_api_v1.HeaderHashOptions{
-								HeaderName: "X-Foo",
-							},
-							QueryParameterHashOptions: &contour_api_v1.QueryParameterHashOptions{
-								ParameterName: "something",
-							},
-							HashSourceIP: true,
-						},
-						{
-							QueryParameterHashOptions: &contour_api_v1.QueryParameterHashOptions{
-								ParameterName: "something",
-							},
-							HashSourceIP: true,
-						},
-						{
-							QueryParameterHashOptions: nil,
-						},
-						{
-							QueryParameterHashOptions: &contour_api_v1.QueryParameterHashOptions{
-								ParameterName: "",
-							},
-						},
-					},
-				},
 			}},
 		},
 	}
 
-	// proxy109 has a route that rewrites headers.
-	proxy109 := &contour_api_v1.HTTPProxy{
+	proxy103a := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "example-com",
-			Namespace: "default",
+			Name:      "www",
+			Namespace: "teama",
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
-			VirtualHost: &contour_api_v1.VirtualHost{
-				Fqdn: "example.com",
-			},
 			Routes: []contour_api_v1.Route{{
-				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/",
-				}},
 				Services: []contour_api_v1.Service{{
-					Name: "kuard",
+					Name: s12.Name,
 					Port: 8080,
 				}},
-				RequestHeadersPolicy: &contour_api_v1.HeadersPolicy{
-					Set: []contour_api_v1.HeaderValue{{
-						Name:  "In-Foo",
-						Value: "bar",
-					}},
-					Remove: []string{
-						"In-Baz",
-					},
-				},
-				ResponseHeadersPolicy: &contour_api_v1.HeadersPolicy{
-					Set: []contour_api_v1.HeaderValue{{
-						Name:  "Out-Foo",
-						Value: "bar",
-					}},
-					Remove: []string{
-						"Out-Baz",
-					},
-				},
 			}},
 		},
 	}
-	// proxy111 has a route that rewrites headers.
-	proxy111 := &contour_api_v1.HTTPProxy{
+
+	proxy104 := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "example-com",
-			Namespace: "default",
+			Namespace: s1.Namespace,
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
 				Fqdn: "example.com",
 			},
+			Includes: []contour_api_v1.Include{{
+				Name: "kuarder",
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/kuarder",
+				}},
+			}},
 			Routes: []contour_api_v1.Route{{
 				Conditions: []contour_api_v1.MatchCondition{{
 					Prefix: "/",
 				}},
 				Services: []contour_api_v1.Service{{
-					Name: "nginx",
-					Port: 80,
+					Name: s1.Name,
+					Port: 8080,
 				}},
-				ResponseHeadersPolicy: &contour_api_v1.HeadersPolicy{
-					Set: []contour_api_v1.HeaderValue{{
-						Name:  "Host",
-						Value: "bar.baz",
-					}},
-				},
 			}},
 		},
 	}
-	// proxy112 has a route that rewrites headers.
-	proxy112 := &contour_api_v1.HTTPProxy{
+
+	proxy104a := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "example-com",
-			Namespace: "default",
+			Name:      "kuarder",
+			Namespace: proxy104.Namespace,
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
-			VirtualHost: &contour_api_v1.VirtualHost{
-				Fqdn: "example.com",
-			},
 			Routes: []contour_api_v1.Route{{
-				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/",
-				}},
 				Services: []contour_api_v1.Service{{
-					Name: "nginx",
-					Port: 80,
-					ResponseHeadersPolicy: &contour_api_v1.HeadersPolicy{
-						Set: []contour_api_v1.HeaderValue{{
-							Name:  "Host",
-							Value: "bar.baz",
-						}},
-					},
+					Name: s2.Name,
+					Port: 8080,
 				}},
 			}},
 		},
 	}
 
-	// Invalid because has exact in include match conditions
-	proxy113 := &contour_api_v1.HTTPProxy{
+	proxy105 := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "example113-com",
+			Name:      "example-com",
 			Namespace: s1.Namespace,
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
-				Fqdn: "example113.com",
+				Fqdn: "example.com",
 			},
 			Includes: []contour_api_v1.Include{{
 				Name: "kuarder",
 				Conditions: []contour_api_v1.MatchCondition{{
-					Exact: "/kuarder",
+					Prefix: "/kuarder",
+				}},
+			}},
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: s1.Name,
+					Port: 8080,
 				}},
 			}},
 		},
 	}
 
-	proxy114 := &contour_api_v1.HTTPProxy{
+	proxy105a := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "example114-com",
-			Namespace: s1.Namespace,
+			Name:      "kuarder",
+			Namespace: proxy105.Namespace,
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
-			VirtualHost: &contour_api_v1.VirtualHost{
-				Fqdn: "example114.com",
-			},
-			Includes: []contour_api_v1.Include{{
-				Name:      "proxy114a",
-				Namespace: s1.Namespace,
+			Routes: []contour_api_v1.Route{{
 				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/foo",
+					Prefix: "/",
 				}},
-			}, {
-				Name:      "proxy114b",
-				Namespace: s2.Namespace,
-				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/bar",
+				Services: []contour_api_v1.Service{{
+					Name: s2.Name,
+					Port: 8080,
 				}},
 			}},
 		},
 	}
 
-	proxy114a := &contour_api_v1.HTTPProxy{
+	proxy106 := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "proxy114a",
+			Name:      "example-com",
 			Namespace: s1.Namespace,
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Includes: []contour_api_v1.Include{{
+				Name: "kuarder",
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/kuarder/",
+				}},
+			}},
 			Routes: []contour_api_v1.Route{{
 				Conditions: []contour_api_v1.MatchCondition{{
-					Exact: "/exact",
+					Prefix: "/",
 				}},
 				Services: []contour_api_v1.Service{{
 					Name: s1.Name,
@@ -9281,15 +9154,15 @@ func TestDAGInsert(t *testing.T) {
 		},
 	}
 
-	proxy114b := &contour_api_v1.HTTPProxy{
+	proxy106a := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "proxy114b",
-			Namespace: s2.Namespace,
+			Name:      "kuarder",
+			Namespace: proxy105.Namespace,
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			Routes: []contour_api_v1.Route{{
 				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/prefix",
+					Prefix: "/",
 				}},
 				Services: []contour_api_v1.Service{{
 					Name: s2.Name,
@@ -9299,146 +9172,125 @@ func TestDAGInsert(t *testing.T) {
 		},
 	}
 
-	cookieRewritePoliciesRoute := &contour_api_v1.HTTPProxy{
+	proxy107 := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "example-com",
-			Namespace: "default",
+			Namespace: s1.Namespace,
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
 				Fqdn: "example.com",
 			},
+			Includes: []contour_api_v1.Include{{
+				Name: "kuarder",
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/kuarder",
+				}},
+			}},
 			Routes: []contour_api_v1.Route{{
 				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/foo",
+					Prefix: "/",
 				}},
-				CookieRewritePolicies: []contour_api_v1.CookieRewritePolicy{
-					{
-						Name: "some-cookie",
-						PathRewrite: &contour_api_v1.CookiePathRewrite{
-							Value: "/foo",
-						},
-						DomainRewrite: &contour_api_v1.CookieDomainRewrite{
-							Value: "example.com",
-						},
-						Secure:   ref.To(true),
-						SameSite: ref.To("Strict"),
-					},
-					{
-						Name:     "some-other-cookie",
-						SameSite: ref.To("Lax"),
-						Secure:   ref.To(false),
-					},
-				},
 				Services: []contour_api_v1.Service{{
-					Name: "nginx",
-					Port: 80,
+					Name: s1.Name,
+					Port: 8080,
 				}},
 			}},
 		},
 	}
 
-	cookieRewritePoliciesService := &contour_api_v1.HTTPProxy{
+	proxy107a := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "example-com",
-			Namespace: "default",
+			Name:      "kuarder",
+			Namespace: proxy105.Namespace,
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
-			VirtualHost: &contour_api_v1.VirtualHost{
-				Fqdn: "example.com",
-			},
 			Routes: []contour_api_v1.Route{{
 				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/foo",
+					Prefix: "/withavengeance",
 				}},
 				Services: []contour_api_v1.Service{{
-					Name: "nginx",
-					Port: 80,
-					CookieRewritePolicies: []contour_api_v1.CookieRewritePolicy{
-						{
-							Name: "some-cookie",
-							PathRewrite: &contour_api_v1.CookiePathRewrite{
-								Value: "/foo",
-							},
-							DomainRewrite: &contour_api_v1.CookieDomainRewrite{
-								Value: "example.com",
-							},
-							Secure:   ref.To(true),
-							SameSite: ref.To("Strict"),
-						},
-						{
-							Name:     "some-other-cookie",
-							SameSite: ref.To("Lax"),
-						},
-					},
+					Name: s2.Name,
+					Port: 8080,
 				}},
 			}},
 		},
 	}
 
-	duplicateCookieRewritePoliciesRoute := &contour_api_v1.HTTPProxy{
+	// proxy108 and proxy108a test duplicate conditions on include
+	proxy108 := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "example-com",
-			Namespace: "default",
+			Name:      "root",
+			Namespace: s1.Namespace,
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
 				Fqdn: "example.com",
 			},
+			Includes: []contour_api_v1.Include{{
+				Name:      "blogteama",
+				Namespace: "teama",
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/blog",
+					Header: &contour_api_v1.HeaderMatchCondition{
+						Name:     "x-header",
+						Contains: "abc",
+					},
+				}},
+			}, {
+				Name:      "blogteama",
+				Namespace: "teamb",
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/blog",
+					Header: &contour_api_v1.HeaderMatchCondition{
+						Name:     "x-header",
+						Contains: "abc",
+					},
+				}},
+			}},
 			Routes: []contour_api_v1.Route{{
 				Conditions: []contour_api_v1.MatchCondition{{
 					Prefix: "/",
 				}},
-				CookieRewritePolicies: []contour_api_v1.CookieRewritePolicy{
-					{
-						Name:   "some-cookie",
-						Secure: ref.To(true),
-					},
-					{
-						Name:     "some-cookie",
-						SameSite: ref.To("Lax"),
-					},
-				},
 				Services: []contour_api_v1.Service{{
-					Name: "nginx",
-					Port: 80,
+					Name: s1.Name,
+					Port: 8080,
 				}},
 			}},
 		},
 	}
 
-	duplicateCookieRewritePoliciesService := &contour_api_v1.HTTPProxy{
+	proxy108a := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "example-com",
-			Namespace: "default",
+			Name:      "blogteama",
+			Namespace: "teama",
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
-			VirtualHost: &contour_api_v1.VirtualHost{
-				Fqdn: "example.com",
-			},
 			Routes: []contour_api_v1.Route{{
-				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/",
+				Services: []contour_api_v1.Service{{
+					Name: s12.Name,
+					Port: 8080,
 				}},
+			}},
+		},
+	}
+
+	proxy108b := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "blogteamb",
+			Namespace: "teamb",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
 				Services: []contour_api_v1.Service{{
-					Name: "nginx",
-					Port: 80,
-					CookieRewritePolicies: []contour_api_v1.CookieRewritePolicy{
-						{
-							Name:   "some-cookie",
-							Secure: ref.To(true),
-						},
-						{
-							Name:     "some-cookie",
-							SameSite: ref.To("Lax"),
-						},
-					},
+					Name: s13.Name,
+					Port: 8080,
 				}},
 			}},
 		},
 	}
 
-	emptyCookieRewritePolicyRoute := &contour_api_v1.HTTPProxy{
+	proxyReplaceHostHeaderRoute := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "example-com",
 			Namespace: "default",
@@ -9451,20 +9303,21 @@ func TestDAGInsert(t *testing.T) {
 				Conditions: []contour_api_v1.MatchCondition{{
 					Prefix: "/",
 				}},
-				CookieRewritePolicies: []contour_api_v1.CookieRewritePolicy{
-					{
-						Name: "some-cookie",
-					},
-				},
 				Services: []contour_api_v1.Service{{
 					Name: "nginx",
 					Port: 80,
 				}},
+				RequestHeadersPolicy: &contour_api_v1.HeadersPolicy{
+					Set: []contour_api_v1.HeaderValue{{
+						Name:  "Host",
+						Value: "bar.com",
+					}},
+				},
 			}},
 		},
 	}
 
-	emptyCookieRewritePolicyService := &contour_api_v1.HTTPProxy{
+	proxyReplaceHostHeaderService := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "example-com",
 			Namespace: "default",
@@ -9480,18 +9333,18 @@ func TestDAGInsert(t *testing.T) {
 				Services: []contour_api_v1.Service{{
 					Name: "nginx",
 					Port: 80,
-					CookieRewritePolicies: []contour_api_v1.CookieRewritePolicy{
-						{
-							Name: "some-cookie",
-						},
+					RequestHeadersPolicy: &contour_api_v1.HeadersPolicy{
+						Set: []contour_api_v1.HeaderValue{{
+							Name:  "Host",
+							Value: "bar.com",
+						}},
 					},
 				}},
 			}},
 		},
 	}
 
-	protocol := "h2c"
-	proxy110 := &contour_api_v1.HTTPProxy{
+	proxyReplaceHostHeaderMultiple := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "example-com",
 			Namespace: "default",
@@ -9505,34 +9358,53 @@ func TestDAGInsert(t *testing.T) {
 					Prefix: "/",
 				}},
 				Services: []contour_api_v1.Service{{
-					Name:     "kuard",
-					Port:     8080,
-					Protocol: &protocol,
+					Name: "nginx",
+					Port: 80,
 				}},
+				RequestHeadersPolicy: &contour_api_v1.HeadersPolicy{
+					Set: []contour_api_v1.HeaderValue{{
+						Name:  "Host",
+						Value: "bar.com",
+					}, {
+						Name:  "x-header",
+						Value: "bar.com",
+					}, {
+						Name:  "y-header",
+						Value: "zed.com",
+					}},
+				},
 			}},
 		},
 	}
 
-	ingressExternalNameService := &networking_v1.Ingress{
+	proxyReplaceNonHostHeader := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "externalname",
+			Name:      "example-com",
 			Namespace: "default",
 		},
-		Spec: networking_v1.IngressSpec{
-			Rules: []networking_v1.IngressRule{{
-				Host: "example.com",
-				IngressRuleValue: networking_v1.IngressRuleValue{
-					HTTP: &networking_v1.HTTPIngressRuleValue{
-						Paths: []networking_v1.HTTPIngressPath{{
-							Backend: *backendv1(s14.GetName(), intstr.FromInt(80)),
-						}},
-					},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: "nginx",
+					Port: 80,
+				}},
+				RequestHeadersPolicy: &contour_api_v1.HeadersPolicy{
+					Set: []contour_api_v1.HeaderValue{{
+						Name:  "x-header",
+						Value: "bar.com",
+					}},
 				},
 			}},
 		},
 	}
 
-	proxyExternalNameService := &contour_api_v1.HTTPProxy{
+	proxyReplaceHeaderEmptyValue := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "example-com",
 			Namespace: "default",
@@ -9546,14 +9418,19 @@ func TestDAGInsert(t *testing.T) {
 					Prefix: "/",
 				}},
 				Services: []contour_api_v1.Service{{
-					Name: s14.GetName(),
+					Name: "nginx",
 					Port: 80,
 				}},
+				RequestHeadersPolicy: &contour_api_v1.HeadersPolicy{
+					Set: []contour_api_v1.HeaderValue{{
+						Name: "x-header",
+					}},
+				},
 			}},
 		},
 	}
 
-	tcpProxyExternalNameService := &contour_api_v1.HTTPProxy{
+	proxyCookieLoadBalancer := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "example-com",
 			Namespace: "default",
@@ -9561,473 +9438,1525 @@ func TestDAGInsert(t *testing.T) {
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
 				Fqdn: "example.com",
-				TLS: &contour_api_v1.TLS{
-					SecretName: sec1.Name,
-				},
 			},
-			TCPProxy: &contour_api_v1.TCPProxy{
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/",
+				}},
 				Services: []contour_api_v1.Service{{
-					Name:     s14.GetName(),
-					Port:     80,
-					Protocol: ref.To("tls"),
+					Name: "nginx",
+					Port: 80,
 				}},
-			},
+				LoadBalancerPolicy: &contour_api_v1.LoadBalancerPolicy{
+					Strategy: "Cookie",
+				},
+			}},
 		},
 	}
 
-	// Invalid proxy because the regex match is in the includes block.
-	proxyInvalidRegexPath := &contour_api_v1.HTTPProxy{
+	proxyLoadBalancerHashPolicyHeader := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "example-invalid-regexpath-com",
-			Namespace: s1.Namespace,
+			Name:      "example-com",
+			Namespace: "default",
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
-				Fqdn: "example.invalid.regexpath.com",
+				Fqdn: "example.com",
 			},
-			Includes: []contour_api_v1.Include{{
-				Name: "path",
+			Routes: []contour_api_v1.Route{{
 				Conditions: []contour_api_v1.MatchCondition{{
-					Regex: "/.*/path",
+					Prefix: "/",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: "nginx",
+					Port: 80,
 				}},
+				LoadBalancerPolicy: &contour_api_v1.LoadBalancerPolicy{
+					Strategy: "RequestHash",
+					RequestHashPolicies: []contour_api_v1.RequestHashPolicy{
+						{
+							Terminal: true,
+							HeaderHashOptions: &contour_api_v1.HeaderHashOptions{
+								HeaderName: "X-Some-Header",
+							},
+						},
+						{
+							// Lower case but duplicated, should be ignored.
+							HeaderHashOptions: &contour_api_v1.HeaderHashOptions{
+								HeaderName: "x-some-header",
+							},
+						},
+						{
+							HeaderHashOptions: nil,
+						},
+						{
+							HeaderHashOptions: &contour_api_v1.HeaderHashOptions{
+								HeaderName: "X-Some-Other-Header",
+							},
+						},
+						{
+							HeaderHashOptions: &contour_api_v1.HeaderHashOptions{
+								HeaderName: "",
+							},
+						},
+					},
+				},
 			}},
 		},
 	}
 
-	proxyRegexPath := &contour_api_v1.HTTPProxy{
+	proxyLoadBalancerHashPolicyQueryParameter := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "example-valid-regexpath-com",
-			Namespace: s1.Namespace,
+			Name:      "example-com",
+			Namespace: "default",
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
-				Fqdn: "example.valid.regexpath.com",
+				Fqdn: "example.com",
 			},
-			Includes: []contour_api_v1.Include{{
-				Name:      "child1",
-				Namespace: s1.Namespace,
+			Routes: []contour_api_v1.Route{{
 				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/foo",
+					Prefix: "/",
 				}},
-			}, {
-				Name:      "child2",
-				Namespace: s2.Namespace,
-				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/bar",
+				Services: []contour_api_v1.Service{{
+					Name: "nginx",
+					Port: 80,
 				}},
+				LoadBalancerPolicy: &contour_api_v1.LoadBalancerPolicy{
+					Strategy: "RequestHash",
+					RequestHashPolicies: []contour_api_v1.RequestHashPolicy{
+						{
+							Terminal: true,
+							QueryParameterHashOptions: &contour_api_v1.QueryParameterHashOptions{
+								ParameterName: "something",
+							},
+						},
+						{
+							QueryParameterHashOptions: nil,
+						},
+						{
+							QueryParameterHashOptions: &contour_api_v1.QueryParameterHashOptions{
+								ParameterName: "other",
+							},
+						},
+						{
+							QueryParameterHashOptions: &contour_api_v1.QueryParameterHashOptions{
+								ParameterName: "",
+							},
+						},
+					},
+				},
 			}},
 		},
 	}
 
-	child1 := &contour_api_v1.HTTPProxy{
+	proxyLoadBalancerHashPolicySourceIP := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "child1",
-			Namespace: s1.Namespace,
+			Name:      "example-com",
+			Namespace: "default",
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
 			Routes: []contour_api_v1.Route{{
 				Conditions: []contour_api_v1.MatchCondition{{
-					Regex: "/regex/.*",
+					Prefix: "/",
 				}},
 				Services: []contour_api_v1.Service{{
-					Name: s1.Name,
-					Port: 8080,
+					Name: "nginx",
+					Port: 80,
 				}},
+				LoadBalancerPolicy: &contour_api_v1.LoadBalancerPolicy{
+					Strategy: "RequestHash",
+					RequestHashPolicies: []contour_api_v1.RequestHashPolicy{
+						{
+							// Ensure header hash policies and source IP hashing
+							// can coexist.
+							HeaderHashOptions: &contour_api_v1.HeaderHashOptions{
+								HeaderName: "X-Some-Header",
+							},
+						},
+						{
+							Terminal:     true,
+							HashSourceIP: true,
+						},
+						{
+							// Duplicate should be ignored.
+							HashSourceIP: true,
+						},
+					},
+				},
 			}},
 		},
 	}
 
-	child2 := &contour_api_v1.HTTPProxy{
+	proxyLoadBalancerHashPolicyAllInvalid := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "child2",
-			Namespace: s2.Namespace,
+			Name:      "example-com",
+			Namespace: "default",
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
 			Routes: []contour_api_v1.Route{{
 				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/prefix",
+					Prefix: "/",
 				}},
 				Services: []contour_api_v1.Service{{
-					Name: s2.Name,
-					Port: 8080,
+					Name: "nginx",
+					Port: 80,
 				}},
-			}},
-		},
-	}
-
-	tests := map[string]struct {
-		objs                         []any
-		disablePermitInsecure        bool
-		enableExternalNameSvc        bool
-		fallbackCertificateName      string
-		fallbackCertificateNamespace string
-		want                         []*Listener
-	}{
-		"ingressv1: insert ingress w/ default backend w/o matching service": {
-			objs: []any{
-				i1V1,
-			},
-			want: listeners(),
-		},
-		"ingressv1: insert ingress w/ default backend": {
-			objs: []any{
-				i1V1,
-				s1,
-			},
-			want: listeners(
-				&Listener{
-					Name: HTTP_LISTENER_NAME,
-					Port: 8080,
-					VirtualHosts: virtualhosts(
-						virtualhost("*", prefixroute("/", service(s1))),
+				LoadBalancerPolicy: &contour_api_v1.LoadBalancerPolicy{
+					Strategy: "RequestHash",
+					RequestHashPolicies: []contour_api_v1.RequestHashPolicy{
+						{
+							HeaderHashOptions: nil,
+						},
+						{
+							HeaderHashOptions: &contour_api_v1.HeaderHashOptions{
+								HeaderName: "",
+							},
+						},
+						{
+							HeaderHashOptions: &contour_api_v1.HeaderHashOptions{
+								HeaderName: "X-Foo",
+							},
+							HashSourceIP: true,
+						},
+						{
+							HeaderHashOptions: &contour_api_v1.HeaderHashOptions{
+								HeaderName: "X-Foo",
+							},
+							QueryParameterHashOptions: &contour_api_v1.QueryParameterHashOptions{
+								ParameterName: "something",
+							},
+						},
+						{
+							HeaderHashOptions: &contour_api_v1.HeaderHashOptions{
+								HeaderName: "X-Foo",
+							},
+							QueryParameterHashOptions: &contour_api_v1.QueryParameterHashOptions{
+								ParameterName: "something",
+							},
+							HashSourceIP: true,
+						},
+						{
+							QueryParameterHashOptions: &contour_api_v1.QueryParameterHashOptions{
+								ParameterName: "something",
+							},
+							HashSourceIP: true,
+						},
+						{
+							QueryParameterHashOptions: nil,
+						},
+						{
+							QueryParameterHashOptions: &contour_api_v1.QueryParameterHashOptions{
+								ParameterName: "",
+							},
+						},
+					},
+				},
+			}},
+		},
+	}
+
+	// proxy109 has a route that rewrites headers.
+	proxy109 := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "example-com",
+			Namespace: "default",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: "kuard",
+					Port: 8080,
+				}},
+				RequestHeadersPolicy: &contour_api_v1.HeadersPolicy{
+					Set: []contour_api_v1.HeaderValue{{
+						Name:  "In-Foo",
+						Value: "bar",
+					}},
+					Remove: []string{
+						"In-Baz",
+					},
+				},
+				ResponseHeadersPolicy: &contour_api_v1.HeadersPolicy{
+					Set: []contour_api_v1.HeaderValue{{
+						Name:  "Out-Foo",
+						Value: "bar",
+					}},
+					Remove: []string{
+						"Out-Baz",
+					},
+				},
+			}},
+		},
+	}
+	// proxy111 has a route that rewrites headers.
+	proxy111 := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "example-com",
+			Namespace: "default",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: "nginx",
+					Port: 80,
+				}},
+				ResponseHeadersPolicy: &contour_api_v1.HeadersPolicy{
+					Set: []contour_api_v1.HeaderValue{{
+						Name:  "Host",
+						Value: "bar.baz",
+					}},
+				},
+			}},
+		},
+	}
+	// proxy112 has a route that rewrites headers.
+	proxy112 := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "example-com",
+			Namespace: "default",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: "nginx",
+					Port: 80,
+					ResponseHeadersPolicy: &contour_api_v1.HeadersPolicy{
+						Set: []contour_api_v1.HeaderValue{{
+							Name:  "Host",
+							Value: "bar.baz",
+						}},
+					},
+				}},
+			}},
+		},
+	}
+
+	// Invalid because has exact in include match conditions
+	proxy113 := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "example113-com",
+			Namespace: s1.Namespace,
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example113.com",
+			},
+			Includes: []contour_api_v1.Include{{
+				Name: "kuarder",
+				Conditions: []contour_api_v1.MatchCondition{{
+					Exact: "/kuarder",
+				}},
+			}},
+		},
+	}
+
+	proxy114 := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "example114-com",
+			Namespace: s1.Namespace,
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example114.com",
+			},
+			Includes: []contour_api_v1.Include{{
+				Name:      "proxy114a",
+				Namespace: s1.Namespace,
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/foo",
+				}},
+			}, {
+				Name:      "proxy114b",
+				Namespace: s2.Namespace,
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/bar",
+				}},
+			}},
+		},
+	}
+
+	proxy114a := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "proxy114a",
+			Namespace: s1.Namespace,
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Exact: "/exact",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: s1.Name,
+					Port: 8080,
+				}},
+			}},
+		},
+	}
+
+	proxy114b := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "proxy114b",
+			Namespace: s2.Namespace,
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/prefix",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: s2.Name,
+					Port: 8080,
+				}},
+			}},
+		},
+	}
+
+	cookieRewritePoliciesRoute := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "example-com",
+			Namespace: "default",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/foo",
+				}},
+				CookieRewritePolicies: []contour_api_v1.CookieRewritePolicy{
+					{
+						Name: "some-cookie",
+						PathRewrite: &contour_api_v1.CookiePathRewrite{
+							Value: "/foo",
+						},
+						DomainRewrite: &contour_api_v1.CookieDomainRewrite{
+							Value: "example.com",
+						},
+						Secure:   ref.To(true),
+						SameSite: ref.To("Strict"),
+					},
+					{
+						Name:     "some-other-cookie",
+						SameSite: ref.To("Lax"),
+						Secure:   ref.To(false),
+					},
+				},
+				Services: []contour_api_v1.Service{{
+					Name: "nginx",
+					Port: 80,
+				}},
+			}},
+		},
+	}
+
+	cookieRewritePoliciesService := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "example-com",
+			Namespace: "default",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/foo",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: "nginx",
+					Port: 80,
+					CookieRewritePolicies: []contour_api_v1.CookieRewritePolicy{
+						{
+							Name: "some-cookie",
+							PathRewrite: &contour_api_v1.CookiePathRewrite{
+								Value: "/foo",
+							},
+							DomainRewrite: &contour_api_v1.CookieDomainRewrite{
+								Value: "example.com",
+							},
+							Secure:   ref.To(true),
+							SameSite: ref.To("Strict"),
+						},
+						{
+							Name:     "some-other-cookie",
+							SameSite: ref.To("Lax"),
+						},
+					},
+				}},
+			}},
+		},
+	}
+
+	duplicateCookieRewritePoliciesRoute := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "example-com",
+			Namespace: "default",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/",
+				}},
+				CookieRewritePolicies: []contour_api_v1.CookieRewritePolicy{
+					{
+						Name:   "some-cookie",
+						Secure: ref.To(true),
+					},
+					{
+						Name:     "some-cookie",
+						SameSite: ref.To("Lax"),
+					},
+				},
+				Services: []contour_api_v1.Service{{
+					Name: "nginx",
+					Port: 80,
+				}},
+			}},
+		},
+	}
+
+	duplicateCookieRewritePoliciesService := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "example-com",
+			Namespace: "default",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: "nginx",
+					Port: 80,
+					CookieRewritePolicies: []contour_api_v1.CookieRewritePolicy{
+						{
+							Name:   "some-cookie",
+							Secure: ref.To(true),
+						},
+						{
+							Name:     "some-cookie",
+							SameSite: ref.To("Lax"),
+						},
+					},
+				}},
+			}},
+		},
+	}
+
+	emptyCookieRewritePolicyRoute := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "example-com",
+			Namespace: "default",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/",
+				}},
+				CookieRewritePolicies: []contour_api_v1.CookieRewritePolicy{
+					{
+						Name: "some-cookie",
+					},
+				},
+				Services: []contour_api_v1.Service{{
+					Name: "nginx",
+					Port: 80,
+				}},
+			}},
+		},
+	}
+
+	emptyCookieRewritePolicyService := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "example-com",
+			Namespace: "default",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: "nginx",
+					Port: 80,
+					CookieRewritePolicies: []contour_api_v1.CookieRewritePolicy{
+						{
+							Name: "some-cookie",
+						},
+					},
+				}},
+			}},
+		},
+	}
+
+	protocol := "h2c"
+	proxy110 := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "example-com",
+			Namespace: "default",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name:     "kuard",
+					Port:     8080,
+					Protocol: &protocol,
+				}},
+			}},
+		},
+	}
+
+	ingressExternalNameService := &networking_v1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "externalname",
+			Namespace: "default",
+		},
+		Spec: networking_v1.IngressSpec{
+			Rules: []networking_v1.IngressRule{{
+				Host: "example.com",
+				IngressRuleValue: networking_v1.IngressRuleValue{
+					HTTP: &networking_v1.HTTPIngressRuleValue{
+						Paths: []networking_v1.HTTPIngressPath{{
+							Backend: *backendv1(s14.GetName(), intstr.FromInt(80)),
+						}},
+					},
+				},
+			}},
+		},
+	}
+
+	proxyExternalNameService := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "example-com",
+			Namespace: "default",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: s14.GetName(),
+					Port: 80,
+				}},
+			}},
+		},
+	}
+
+	proxyExternalNameServiceDNSLookupFamily := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "example-com",
+			Namespace: "default",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name:            s14.GetName(),
+					Port:            80,
+					DNSLookupFamily: "v6",
+				}},
+			}},
+		},
+	}
+
+	tcpProxyExternalNameService := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "example-com",
+			Namespace: "default",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName: sec1.Name,
+				},
+			},
+			TCPProxy: &contour_api_v1.TCPProxy{
+				Services: []contour_api_v1.Service{{
+					Name:     s14.GetName(),
+					Port:     80,
+					Protocol: ref.To("tls"),
+				}},
+			},
+		},
+	}
+
+	// Invalid proxy because the regex match is in the includes block.
+	proxyInvalidRegexPath := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "example-invalid-regexpath-com",
+			Namespace: s1.Namespace,
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.invalid.regexpath.com",
+			},
+			Includes: []contour_api_v1.Include{{
+				Name: "path",
+				Conditions: []contour_api_v1.MatchCondition{{
+					Regex: "/.*/path",
+				}},
+			}},
+		},
+	}
+
+	proxyRegexPath := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "example-valid-regexpath-com",
+			Namespace: s1.Namespace,
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.valid.regexpath.com",
+			},
+			Includes: []contour_api_v1.Include{{
+				Name:      "child1",
+				Namespace: s1.Namespace,
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/foo",
+				}},
+			}, {
+				Name:      "child2",
+				Namespace: s2.Namespace,
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/bar",
+				}},
+			}},
+		},
+	}
+
+	child1 := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "child1",
+			Namespace: s1.Namespace,
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Regex: "/regex/.*",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: s1.Name,
+					Port: 8080,
+				}},
+			}},
+		},
+	}
+
+	child2 := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "child2",
+			Namespace: s2.Namespace,
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/prefix",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: s2.Name,
+					Port: 8080,
+				}},
+			}},
+		},
+	}
+
+	tests := map[string]struct {
+		objs                         []any
+		disablePermitInsecure        bool
+		enableExternalNameSvc        bool
+		fallbackCertificateName      string
+		fallbackCertificateNamespace string
+		want                         []*Listener
+	}{
+		"ingressv1: insert ingress w/ default backend w/o matching service": {
+			objs: []any{
+				i1V1,
+			},
+			want: listeners(),
+		},
+		"ingressv1: insert ingress w/ default backend": {
+			objs: []any{
+				i1V1,
+				s1,
+			},
+			want: listeners(
+				&Listener{
+					Name: HTTP_LISTENER_NAME,
+					Port: 8080,
+					VirtualHosts: virtualhosts(
+						virtualhost("*", prefixroute("/", service(s1))),
+					),
+				},
+			),
+		},
+		"ingressv1: insert ingress w/ single unnamed backend w/o matching service": {
+			objs: []any{
+				i2V1,
+			},
+			want: listeners(),
+		},
+		"ingressv1: insert ingress w/ single unnamed backend": {
+			objs: []any{
+				i2V1,
+				s1,
+			},
+			want: listeners(
+				&Listener{
+					Name: HTTP_LISTENER_NAME,
+					Port: 8080,
+					VirtualHosts: virtualhosts(
+						virtualhost("*", prefixroute("/", service(s1))),
+					),
+				},
+			),
+		},
+		"ingressv1: insert ingress with missing spec.rule.http key": {
+			objs: []any{
+				i2aV1,
+			},
+			want: listeners(),
+		},
+		"ingressv1: insert ingress w/ host name and single backend w/o matching service": {
+			objs: []any{
+				i3V1,
+			},
+			want: listeners(),
+		},
+		"ingressv1: insert ingress w/ host name and single backend": {
+			objs: []any{
+				i3V1,
+				s1,
+			},
+			want: listeners(
+				&Listener{
+					Name: HTTP_LISTENER_NAME,
+					Port: 8080,
+					VirtualHosts: virtualhosts(
+						virtualhost("kuard.example.com", prefixroute("/", service(s1))),
+					),
+				},
+			),
+		},
+		"ingressv1: insert non matching service then ingress w/ default backend": {
+			objs: []any{
+				s2,
+				i1V1,
+			},
+			want: listeners(),
+		},
+		"ingressv1: insert ingress w/ default backend then matching service with wrong port": {
+			objs: []any{
+				i1V1,
+				s3,
+			},
+			want: listeners(),
+		},
+		"ingressv1: insert unnamed ingress w/ single backend then matching service with wrong port": {
+			objs: []any{
+				i2V1,
+				s3,
+			},
+			want: listeners(),
+		},
+		"ingressv1: insert ingress w/ default backend then matching service w/ named port": {
+			objs: []any{
+				i4V1,
+				s1,
+			},
+			want: listeners(
+				&Listener{
+					Name: HTTP_LISTENER_NAME,
+					Port: 8080,
+					VirtualHosts: virtualhosts(
+						virtualhost("*", prefixroute("/", service(s1))),
+					),
+				},
+			),
+		},
+		"ingressv1: insert service w/ named port then ingress w/ default backend": {
+			objs: []any{
+				s1,
+				i4V1,
+			},
+			want: listeners(
+				&Listener{
+					Name: HTTP_LISTENER_NAME,
+					Port: 8080,
+					VirtualHosts: virtualhosts(
+						virtualhost("*", prefixroute("/", service(s1))),
+					),
+				},
+			),
+		},
+		"ingressv1: insert ingress w/ single unnamed backend w/ named service port then service": {
+			objs: []any{
+				i5V1,
+				s1,
+			},
+			want: listeners(
+				&Listener{
+					Name: HTTP_LISTENER_NAME,
+					Port: 8080,
+					VirtualHosts: virtualhosts(
+						virtualhost("*", prefixroute("/", service(s1))),
+					),
+				},
+			),
+		},
+		"ingressv1: insert service then ingress w/ single unnamed backend w/ named service port": {
+			objs: []any{
+				s1,
+				i5V1,
+			},
+			want: listeners(
+				&Listener{
+					Name: HTTP_LISTENER_NAME,
+					Port: 8080,
+					VirtualHosts: virtualhosts(
+						virtualhost("*", prefixroute("/", service(s1))),
+					),
+				},
+			),
+		},
+		"insert secret": {
+			objs: []any{
+				sec1,
+			},
+			want: listeners(),
+		},
+		"ingressv1: insert secret then ingress w/o tls": {
+			objs: []any{
+				sec1,
+				i1V1,
+			},
+			want: listeners(),
+		},
+		"ingressv1: insert service, secret then ingress w/o tls": {
+			objs: []any{
+				s1,
+				sec1,
+				i1V1,
+			},
+			want: listeners(
+				&Listener{
+					Name: HTTP_LISTENER_NAME,
+					Port: 8080,
+					VirtualHosts: virtualhosts(
+						virtualhost("*", prefixroute("/", service(s1))),
+					),
+				},
+			),
+		},
+		"ingressv1: insert secret then ingress w/ tls": {
+			objs: []any{
+				sec1,
+				i3V1,
+			},
+			want: listeners(),
+		},
+		"ingressv1: insert service, secret then ingress w/ tls": {
+			objs: []any{
+				s1,
+				sec1,
+				i3V1,
+			},
+			want: listeners(
+				&Listener{
+					Name: HTTP_LISTENER_NAME,
+					Port: 8080,
+					VirtualHosts: virtualhosts(
+						virtualhost("kuard.example.com", prefixroute("/", service(s1))),
+					),
+				},
+				&Listener{
+					Name: HTTPS_LISTENER_NAME,
+					Port: 8443,
+					SecureVirtualHosts: securevirtualhosts(
+						securevirtualhost("kuard.example.com", sec1, prefixroute("/", service(s1))),
+					),
+				},
+			),
+		},
+		"ingressv1: insert service w/ secret with w/ blank ca.crt": {
+			objs: []any{
+				s1,
+				sec3, // issue 1644
+				i3V1,
+			},
+			want: listeners(
+				&Listener{
+					Name: HTTP_LISTENER_NAME,
+					Port: 8080,
+					VirtualHosts: virtualhosts(
+						virtualhost("kuard.example.com", prefixroute("/", service(s1))),
+					),
+				},
+				&Listener{
+					Name: HTTPS_LISTENER_NAME,
+					Port: 8443,
+					SecureVirtualHosts: securevirtualhosts(
+						securevirtualhost("kuard.example.com", sec3, prefixroute("/", service(s1))),
+					),
+				},
+			),
+		},
+		"ingressv1: insert invalid secret then ingress w/o tls": {
+			objs: []any{
+				secInvalid,
+				i1V1,
+			},
+			want: listeners(),
+		},
+		"ingressv1: insert service, invalid secret then ingress w/o tls": {
+			objs: []any{
+				s1,
+				secInvalid,
+				i1V1,
+			},
+			want: listeners(
+				&Listener{
+					Name: HTTP_LISTENER_NAME,
+					Port: 8080,
+					VirtualHosts: virtualhosts(
+						virtualhost("*", prefixroute("/", service(s1))),
+					),
+				},
+			),
+		},
+		"ingressv1: insert invalid secret then ingress w/ tls": {
+			objs: []any{
+				secInvalid,
+				i3V1,
+			},
+			want: listeners(),
+		},
+		"ingressv1: insert service, invalid secret then ingress w/ tls": {
+			objs: []any{
+				s1,
+				secInvalid,
+				i3V1,
+			},
+			want: listeners(
+				&Listener{
+					Name: HTTP_LISTENER_NAME,
+					Port: 8080,
+					VirtualHosts: virtualhosts(
+						virtualhost("kuard.example.com", prefixroute("/", service(s1))),
+					),
+				},
+			),
+		},
+		"ingressv1: insert ingress w/ two vhosts": {
+			objs: []any{
+				i6V1,
+			},
+			want: nil, // no matching service
+		},
+		"ingressv1: insert ingress w/ two vhosts then matching service": {
+			objs: []any{
+				i6V1,
+				s1,
+			},
+			want: listeners(
+				&Listener{
+					Name: HTTP_LISTENER_NAME,
+					Port: 8080,
+					VirtualHosts: virtualhosts(
+						virtualhost("a.example.com", prefixroute("/", service(s1))),
+						virtualhost("b.example.com", prefixroute("/", service(s1))),
+					),
+				},
+			),
+		},
+		"ingressv1: insert service then ingress w/ two vhosts": {
+			objs: []any{
+				s1,
+				i6V1,
+			},
+			want: listeners(
+				&Listener{
+					Name: HTTP_LISTENER_NAME,
+					Port: 8080,
+					VirtualHosts: virtualhosts(
+						virtualhost("a.example.com", prefixroute("/", service(s1))),
+						virtualhost("b.example.com", prefixroute("/", service(s1))),
+					),
+				},
+			),
+		},
+		"ingressv1: insert ingress w/ two vhosts then service then secret": {
+			objs: []any{
+				i6V1,
+				s1,
+				sec1,
+			},
+			want: listeners(
+				&Listener{
+					Name: HTTP_LISTENER_NAME,
+					Port: 8080,
+					VirtualHosts: virtualhosts(
+						virtualhost("a.example.com", prefixroute("/", service(s1))),
+						virtualhost("b.example.com", prefixroute("/", service(s1))),
+					),
+				}, &Listener{
+					Name: HTTPS_LISTENER_NAME,
+					Port: 8443,
+					SecureVirtualHosts: securevirtualhosts(
+						securevirtualhost("b.example.com", sec1, prefixroute("/", service(s1))),
+					),
+				},
+			),
+		},
+		"ingressv1: insert service then secret then ingress w/ two vhosts": {
+			objs: []any{
+				s1,
+				sec1,
+				i6V1,
+			},
+			want: listeners(
+				&Listener{
+					Name: HTTP_LISTENER_NAME,
+					Port: 8080,
+					VirtualHosts: virtualhosts(
+						virtualhost("a.example.com", prefixroute("/", service(s1))),
+						virtualhost("b.example.com", prefixroute("/", service(s1))),
+					),
+				}, &Listener{
+					Name: HTTPS_LISTENER_NAME,
+					Port: 8443,
+					SecureVirtualHosts: securevirtualhosts(
+						securevirtualhost("b.example.com", sec1, prefixroute("/", service(s1))),
+					),
+				},
+			),
+		},
+		"ingressv1: insert ingress w/ two paths then one service": {
+			objs: []any{
+				i7V1,
+				s1,
+			},
+			want: listeners(
+				&Listener{
+					Name: HTTP_LISTENER_NAME,
+					Port: 8080,
+					VirtualHosts: virtualhosts(
+						virtualhost("b.example.com",
+							prefixroute("/", service(s1)),
+						),
+					),
+				},
+			),
+		},
+		"ingressv1: insert ingress w/ two paths then services": {
+			objs: []any{
+				i7V1,
+				s2,
+				s1,
+			},
+			want: listeners(
+				&Listener{
+					Name: HTTP_LISTENER_NAME,
+					Port: 8080,
+					VirtualHosts: virtualhosts(
+						virtualhost("b.example.com",
+							prefixroute("/", service(s1)),
+							prefixroute("/kuarder", service(s2)),
+						),
 					),
 				},
 			),
 		},
-		"ingressv1: insert ingress w/ single unnamed backend w/o matching service": {
-			objs: []any{
-				i2V1,
-			},
-			want: listeners(),
-		},
-		"ingressv1: insert ingress w/ single unnamed backend": {
+		"ingressv1: insert two services then ingress w/ two ingress rules": {
 			objs: []any{
-				i2V1,
-				s1,
+				s1, s2, i8V1,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("*", prefixroute("/", service(s1))),
+						virtualhost("b.example.com",
+							prefixroute("/", service(s1)),
+							prefixroute("/kuarder", service(s2)),
+						),
 					),
 				},
 			),
 		},
-		"ingressv1: insert ingress with missing spec.rule.http key": {
-			objs: []any{
-				i2aV1,
-			},
-			want: listeners(),
-		},
-		"ingressv1: insert ingress w/ host name and single backend w/o matching service": {
+		"ingressv1: insert ingress w/ two paths httpAllowed: false": {
 			objs: []any{
-				i3V1,
+				i9V1,
 			},
 			want: listeners(),
 		},
-		"ingressv1: insert ingress w/ host name and single backend": {
+		"ingressv1: insert ingress w/ two paths httpAllowed: false then tls and service": {
 			objs: []any{
-				i3V1,
-				s1,
+				i9V1,
+				sec1,
+				s1, s2,
 			},
 			want: listeners(
 				&Listener{
-					Name: HTTP_LISTENER_NAME,
-					Port: 8080,
-					VirtualHosts: virtualhosts(
-						virtualhost("kuard.example.com", prefixroute("/", service(s1))),
+					Name: HTTPS_LISTENER_NAME,
+					Port: 8443,
+					SecureVirtualHosts: securevirtualhosts(
+						securevirtualhost("b.example.com", sec1,
+							prefixroute("/", service(s1)),
+							prefixroute("/kuarder", service(s2)),
+						),
 					),
 				},
 			),
 		},
-		"ingressv1: insert non matching service then ingress w/ default backend": {
+		"ingressv1: insert default ingress httpAllowed: false": {
 			objs: []any{
-				s2,
-				i1V1,
+				i1aV1,
 			},
 			want: listeners(),
 		},
-		"ingressv1: insert ingress w/ default backend then matching service with wrong port": {
+		"ingressv1: insert default ingress httpAllowed: false then tls and service": {
 			objs: []any{
-				i1V1,
-				s3,
+				i1aV1, sec1, s1,
 			},
-			want: listeners(),
+			want: listeners(), // default ingress cannot be tls
 		},
-		"ingressv1: insert unnamed ingress w/ single backend then matching service with wrong port": {
+		"ingressv1: insert ingress w/ two vhosts httpAllowed: false": {
 			objs: []any{
-				i2V1,
-				s3,
+				i6aV1,
 			},
 			want: listeners(),
 		},
-		"ingressv1: insert ingress w/ default backend then matching service w/ named port": {
+		"ingressv1: insert ingress w/ two vhosts httpAllowed: false then tls and service": {
 			objs: []any{
-				i4V1,
-				s1,
+				i6aV1, sec1, s1,
 			},
 			want: listeners(
 				&Listener{
-					Name: HTTP_LISTENER_NAME,
-					Port: 8080,
-					VirtualHosts: virtualhosts(
-						virtualhost("*", prefixroute("/", service(s1))),
+					Name: HTTPS_LISTENER_NAME,
+					Port: 8443,
+					SecureVirtualHosts: securevirtualhosts(
+						securevirtualhost("b.example.com", sec1, prefixroute("/", service(s1))),
 					),
 				},
 			),
 		},
-		"ingressv1: insert service w/ named port then ingress w/ default backend": {
+		"ingressv1: insert ingress w/ force-ssl-redirect: true": {
 			objs: []any{
-				s1,
-				i4V1,
+				i6bV1, sec1, s1,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("*", prefixroute("/", service(s1))),
+						virtualhost("b.example.com", routeUpgrade("/", service(s1))),
 					),
-				},
-			),
-		},
-		"ingressv1: insert ingress w/ single unnamed backend w/ named service port then service": {
-			objs: []any{
-				i5V1,
-				s1,
-			},
-			want: listeners(
-				&Listener{
-					Name: HTTP_LISTENER_NAME,
-					Port: 8080,
-					VirtualHosts: virtualhosts(
-						virtualhost("*", prefixroute("/", service(s1))),
+				}, &Listener{
+					Name: HTTPS_LISTENER_NAME,
+					Port: 8443,
+					SecureVirtualHosts: securevirtualhosts(
+						securevirtualhost("b.example.com", sec1, routeUpgrade("/", service(s1))),
 					),
 				},
 			),
 		},
-		"ingressv1: insert service then ingress w/ single unnamed backend w/ named service port": {
+
+		"ingressv1: insert ingress w/ force-ssl-redirect: true and allow-http: false": {
 			objs: []any{
-				s1,
-				i5V1,
+				i6cV1, sec1, s1,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("*", prefixroute("/", service(s1))),
+						virtualhost("b.example.com", routeUpgrade("/", service(s1))),
+					),
+				}, &Listener{
+					Name: HTTPS_LISTENER_NAME,
+					Port: 8443,
+					SecureVirtualHosts: securevirtualhosts(
+						securevirtualhost("b.example.com", sec1, routeUpgrade("/", service(s1))),
 					),
 				},
 			),
 		},
-		"insert secret": {
-			objs: []any{
-				sec1,
-			},
-			want: listeners(),
-		},
-		"ingressv1: insert secret then ingress w/o tls": {
-			objs: []any{
-				sec1,
-				i1V1,
-			},
-			want: listeners(),
-		},
-		"ingressv1: insert service, secret then ingress w/o tls": {
+		"insert httpproxy with tls version 1.2": {
 			objs: []any{
-				s1,
-				sec1,
-				i1V1,
+				proxyMinTLS12, s1, sec1,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("*", prefixroute("/", service(s1))),
+						virtualhost("foo.com", routeUpgrade("/", service(s1))),
+					),
+				}, &Listener{
+					Name: HTTPS_LISTENER_NAME,
+					Port: 8443,
+					SecureVirtualHosts: securevirtualhosts(
+						&SecureVirtualHost{
+							VirtualHost: VirtualHost{
+								Name: "foo.com",
+								Routes: routes(
+									routeUpgrade("/", service(s1)),
+								),
+							},
+							MinTLSVersion: "1.2",
+							Secret:        secret(sec1),
+						},
 					),
 				},
 			),
 		},
-		"ingressv1: insert secret then ingress w/ tls": {
-			objs: []any{
-				sec1,
-				i3V1,
-			},
-			want: listeners(),
-		},
-		"ingressv1: insert service, secret then ingress w/ tls": {
+		"insert httpproxy with cert-manager certificate": {
 			objs: []any{
-				s1,
-				sec1,
-				i3V1,
+				proxyCertificate, certificate1, s1, sec1,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("kuard.example.com", prefixroute("/", service(s1))),
+						virtualhost("foo.com", routeUpgrade("/", service(s1))),
 					),
-				},
-				&Listener{
+				}, &Listener{
 					Name: HTTPS_LISTENER_NAME,
 					Port: 8443,
 					SecureVirtualHosts: securevirtualhosts(
-						securevirtualhost("kuard.example.com", sec1, prefixroute("/", service(s1))),
+						securevirtualhost("foo.com", sec1, routeUpgrade("/", service(s1))),
 					),
 				},
 			),
 		},
-		"ingressv1: insert service w/ secret with w/ blank ca.crt": {
+		"insert httpproxy with https redirect policy": {
 			objs: []any{
-				s1,
-				sec3, // issue 1644
-				i3V1,
+				proxyHTTPSRedirectPolicy, s1, sec1,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("kuard.example.com", prefixroute("/", service(s1))),
+						virtualhost("foo.com",
+							&Route{
+								PathMatchCondition: prefixString("/"),
+								Clusters:           clusters(service(s1)),
+								HTTPSUpgrade:       true,
+								HTTPSRedirectPolicy: &HTTPSRedirectPolicy{
+									StatusCode:           308,
+									StripQuery:           true,
+									ExcludedPathPrefixes: []string{"/.well-known/acme-challenge/"},
+								},
+							},
+							prefixroute("/.well-known/acme-challenge/", service(s1)),
+						),
 					),
-				},
-				&Listener{
+				}, &Listener{
 					Name: HTTPS_LISTENER_NAME,
 					Port: 8443,
 					SecureVirtualHosts: securevirtualhosts(
-						securevirtualhost("kuard.example.com", sec3, prefixroute("/", service(s1))),
+						securevirtualhost("foo.com", sec1, &Route{
+							PathMatchCondition: prefixString("/"),
+							Clusters:           clusters(service(s1)),
+							HTTPSUpgrade:       true,
+							HTTPSRedirectPolicy: &HTTPSRedirectPolicy{
+								StatusCode:           308,
+								StripQuery:           true,
+								ExcludedPathPrefixes: []string{"/.well-known/acme-challenge/"},
+							},
+						}),
 					),
 				},
 			),
 		},
-		"ingressv1: insert invalid secret then ingress w/o tls": {
-			objs: []any{
-				secInvalid,
-				i1V1,
-			},
-			want: listeners(),
-		},
-		"ingressv1: insert service, invalid secret then ingress w/o tls": {
+		"insert httpproxy with tls version 1.3": {
 			objs: []any{
-				s1,
-				secInvalid,
-				i1V1,
+				proxyMinTLS13, s1, sec1,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("*", prefixroute("/", service(s1))),
+						virtualhost("foo.com", routeUpgrade("/", service(s1))),
+					),
+				}, &Listener{
+					Name: HTTPS_LISTENER_NAME,
+					Port: 8443,
+					SecureVirtualHosts: securevirtualhosts(
+						&SecureVirtualHost{
+							VirtualHost: VirtualHost{
+								Name: "foo.com",
+								Routes: routes(
+									routeUpgrade("/", service(s1)),
+								),
+							},
+							MinTLSVersion: "1.3",
+							Secret:        secret(sec1),
+						},
 					),
 				},
 			),
 		},
-		"ingressv1: insert invalid secret then ingress w/ tls": {
-			objs: []any{
-				secInvalid,
-				i3V1,
-			},
-			want: listeners(),
-		},
-		"ingressv1: insert service, invalid secret then ingress w/ tls": {
+		"insert httpproxy with invalid tls version": {
 			objs: []any{
-				s1,
-				secInvalid,
-				i3V1,
+				proxyMinTLSInvalid, s1, sec1,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("kuard.example.com", prefixroute("/", service(s1))),
+						virtualhost("foo.com", routeUpgrade("/", service(s1))),
+					),
+				}, &Listener{
+					Name: HTTPS_LISTENER_NAME,
+					Port: 8443,
+					SecureVirtualHosts: securevirtualhosts(
+						securevirtualhost("foo.com", sec1, routeUpgrade("/", service(s1))),
 					),
 				},
 			),
 		},
-		"ingressv1: insert ingress w/ two vhosts": {
-			objs: []any{
-				i6V1,
-			},
-			want: nil, // no matching service
-		},
-		"ingressv1: insert ingress w/ two vhosts then matching service": {
+		"insert httpproxy referencing two backends, one missing": {
 			objs: []any{
-				i6V1,
-				s1,
+				proxyMultipleBackends, s2,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("a.example.com", prefixroute("/", service(s1))),
-						virtualhost("b.example.com", prefixroute("/", service(s1))),
+						virtualhost("example.com", prefixroute("/", service(s2))),
 					),
 				},
 			),
 		},
-		"ingressv1: insert service then ingress w/ two vhosts": {
-			objs: []any{
-				s1,
-				i6V1,
+		"insert httpproxy with a wildcard fqdn": {
+			objs: []any{
+				proxyWildcardFQDN, s1,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("a.example.com", prefixroute("/", service(s1))),
-						virtualhost("b.example.com", prefixroute("/", service(s1))),
+						virtualhost("*.projectcontour.io",
+							&Route{
+								PathMatchCondition: prefixString("/"),
+								HeaderMatchConditions: []HeaderMatchCondition{
+									{Name: ":authority", Value: "^[a-z0-9]([-a-z0-9]*[a-z0-9])?\\.projectcontour\\.io(:[0-9]+)?", MatchType: "regex", Invert: false},
+								},
+								Clusters: clusters(service(s1)),
+							}),
 					),
 				},
 			),
 		},
-		"ingressv1: insert ingress w/ two vhosts then service then secret": {
+		"insert httpproxy referencing two backends": {
 			objs: []any{
-				i6V1,
-				s1,
-				sec1,
+				proxyMultipleBackends, s1, s2,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("a.example.com", prefixroute("/", service(s1))),
-						virtualhost("b.example.com", prefixroute("/", service(s1))),
-					),
-				}, &Listener{
-					Name: HTTPS_LISTENER_NAME,
-					Port: 8443,
-					SecureVirtualHosts: securevirtualhosts(
-						securevirtualhost("b.example.com", sec1, prefixroute("/", service(s1))),
+						virtualhost("example.com", prefixroute("/", service(s1), service(s2))),
 					),
 				},
 			),
 		},
-		"ingressv1: insert service then secret then ingress w/ two vhosts": {
+		"ingressv1: insert ingress w/ tls min proto annotation": {
 			objs: []any{
-				s1,
+				i10aV1,
 				sec1,
-				i6V1,
+				s1,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("a.example.com", prefixroute("/", service(s1))),
 						virtualhost("b.example.com", prefixroute("/", service(s1))),
 					),
 				}, &Listener{
 					Name: HTTPS_LISTENER_NAME,
 					Port: 8443,
 					SecureVirtualHosts: securevirtualhosts(
-						securevirtualhost("b.example.com", sec1, prefixroute("/", service(s1))),
+						&SecureVirtualHost{
+							VirtualHost: VirtualHost{
+								Name: "b.example.com",
+								Routes: routes(
+									prefixroute("/", service(s1)),
+								),
+							},
+							MinTLSVersion: "1.3",
+							Secret:        secret(sec1),
+						},
 					),
 				},
 			),
 		},
-		"ingressv1: insert ingress w/ two paths then one service": {
+		"ingressv1: insert ingress w/ websocket route annotation": {
 			objs: []any{
-				i7V1,
+				i11V1,
 				s1,
 			},
 			want: listeners(
@@ -10035,17 +10964,17 @@ func TestDAGInsert(t *testing.T) {
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("b.example.com",
+						virtualhost("*",
 							prefixroute("/", service(s1)),
+							routeWebsocket("/ws1", service(s1)),
 						),
 					),
 				},
 			),
 		},
-		"ingressv1: insert ingress w/ two paths then services": {
+		"ingressv1: insert ingress w/ invalid legacy timeout annotation": {
 			objs: []any{
-				i7V1,
-				s2,
+				i12aV1,
 				s1,
 			},
 			want: listeners(
@@ -10053,260 +10982,262 @@ func TestDAGInsert(t *testing.T) {
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("b.example.com",
-							prefixroute("/", service(s1)),
-							prefixroute("/kuarder", service(s2)),
-						),
+						virtualhost("*", &Route{
+							PathMatchCondition: prefixString("/"),
+							Clusters:           clustermap(s1),
+						}),
 					),
 				},
 			),
 		},
-		"ingressv1: insert two services then ingress w/ two ingress rules": {
+		"ingressv1: insert ingress w/ invalid timeout annotation": {
 			objs: []any{
-				s1, s2, i8V1,
+				i12dV1,
+				s1,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("b.example.com",
-							prefixroute("/", service(s1)),
-							prefixroute("/kuarder", service(s2)),
-						),
+						virtualhost("*", &Route{
+							PathMatchCondition: prefixString("/"),
+							Clusters:           clustermap(s1),
+						}),
 					),
 				},
 			),
 		},
-		"ingressv1: insert ingress w/ two paths httpAllowed: false": {
+		"insert httpproxy w/ invalid timeoutpolicy": {
 			objs: []any{
-				i9V1,
+				proxyTimeoutPolicyInvalidResponse,
+				s1,
 			},
 			want: listeners(),
 		},
-		"ingressv1: insert ingress w/ two paths httpAllowed: false then tls and service": {
+		"ingressv1: insert ingress w/ valid legacy timeout annotation": {
 			objs: []any{
-				i9V1,
-				sec1,
-				s1, s2,
+				i12bV1,
+				s1,
 			},
 			want: listeners(
 				&Listener{
-					Name: HTTPS_LISTENER_NAME,
-					Port: 8443,
-					SecureVirtualHosts: securevirtualhosts(
-						securevirtualhost("b.example.com", sec1,
-							prefixroute("/", service(s1)),
-							prefixroute("/kuarder", service(s2)),
-						),
+					Name: HTTP_LISTENER_NAME,
+					Port: 8080,
+					VirtualHosts: virtualhosts(
+						virtualhost("*", &Route{
+							PathMatchCondition: prefixString("/"),
+							Clusters:           clustermap(s1),
+							TimeoutPolicy: RouteTimeoutPolicy{
+								ResponseTimeout: timeout.DurationSetting(90 * time.Second),
+							},
+						}),
 					),
 				},
 			),
 		},
-		"ingressv1: insert default ingress httpAllowed: false": {
-			objs: []any{
-				i1aV1,
-			},
-			want: listeners(),
-		},
-		"ingressv1: insert default ingress httpAllowed: false then tls and service": {
-			objs: []any{
-				i1aV1, sec1, s1,
-			},
-			want: listeners(), // default ingress cannot be tls
-		},
-		"ingressv1: insert ingress w/ two vhosts httpAllowed: false": {
-			objs: []any{
-				i6aV1,
-			},
-			want: listeners(),
-		},
-		"ingressv1: insert ingress w/ two vhosts httpAllowed: false then tls and service": {
+		"ingressv1: insert ingress w/ valid timeout annotation": {
 			objs: []any{
-				i6aV1, sec1, s1,
+				i12eV1,
+				s1,
 			},
 			want: listeners(
 				&Listener{
-					Name: HTTPS_LISTENER_NAME,
-					Port: 8443,
-					SecureVirtualHosts: securevirtualhosts(
-						securevirtualhost("b.example.com", sec1, prefixroute("/", service(s1))),
+					Name: HTTP_LISTENER_NAME,
+					Port: 8080,
+					VirtualHosts: virtualhosts(
+						virtualhost("*", &Route{
+							PathMatchCondition: prefixString("/"),
+							Clusters:           clustermap(s1),
+							TimeoutPolicy: RouteTimeoutPolicy{
+								ResponseTimeout: timeout.DurationSetting(90 * time.Second),
+							},
+						}),
 					),
 				},
 			),
 		},
-		"ingressv1: insert ingress w/ force-ssl-redirect: true": {
+		"insert httpproxy w/ valid timeoutpolicy": {
 			objs: []any{
-				i6bV1, sec1, s1,
+				proxyTimeoutPolicyValidResponse,
+				s1,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("b.example.com", routeUpgrade("/", service(s1))),
-					),
-				}, &Listener{
-					Name: HTTPS_LISTENER_NAME,
-					Port: 8443,
-					SecureVirtualHosts: securevirtualhosts(
-						securevirtualhost("b.example.com", sec1, routeUpgrade("/", service(s1))),
+						virtualhost("bar.com", &Route{
+							PathMatchCondition: prefixString("/"),
+							Clusters:           clustermap(s1),
+							TimeoutPolicy:      RouteTimeoutPolicy{ResponseTimeout: timeout.DurationSetting(90 * time.Second)},
+						}),
 					),
 				},
 			),
 		},
-
-		"ingressv1: insert ingress w/ force-ssl-redirect: true and allow-http: false": {
+		"ingressv1: insert ingress w/ legacy infinite timeout annotation": {
 			objs: []any{
-				i6cV1, sec1, s1,
+				i12cV1,
+				s1,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("b.example.com", routeUpgrade("/", service(s1))),
-					),
-				}, &Listener{
-					Name: HTTPS_LISTENER_NAME,
-					Port: 8443,
-					SecureVirtualHosts: securevirtualhosts(
-						securevirtualhost("b.example.com", sec1, routeUpgrade("/", service(s1))),
+						virtualhost("*", &Route{
+							PathMatchCondition: prefixString("/"),
+							Clusters:           clustermap(s1),
+							TimeoutPolicy: RouteTimeoutPolicy{
+								ResponseTimeout: timeout.DisabledSetting(),
+							},
+						}),
 					),
 				},
 			),
 		},
-		"insert httpproxy with tls version 1.2": {
+		"ingressv1: insert ingress w/ infinite timeout annotation": {
 			objs: []any{
-				proxyMinTLS12, s1, sec1,
+				i12fV1,
+				s1,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("foo.com", routeUpgrade("/", service(s1))),
-					),
-				}, &Listener{
-					Name: HTTPS_LISTENER_NAME,
-					Port: 8443,
-					SecureVirtualHosts: securevirtualhosts(
-						&SecureVirtualHost{
-							VirtualHost: VirtualHost{
-								Name: "foo.com",
-								Routes: routes(
-									routeUpgrade("/", service(s1)),
-								),
+						virtualhost("*", &Route{
+							PathMatchCondition: prefixString("/"),
+							Clusters:           clustermap(s1),
+							TimeoutPolicy: RouteTimeoutPolicy{
+								ResponseTimeout: timeout.DisabledSetting(),
 							},
-							MinTLSVersion: "1.2",
-							Secret:        secret(sec1),
-						},
+						}),
 					),
 				},
 			),
 		},
-		"insert httpproxy with tls version 1.3": {
+		"insert httpproxy w/ infinite timeoutpolicy": {
 			objs: []any{
-				proxyMinTLS13, s1, sec1,
+				proxyTimeoutPolicyInfiniteResponse,
+				s1,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("foo.com", routeUpgrade("/", service(s1))),
-					),
-				}, &Listener{
-					Name: HTTPS_LISTENER_NAME,
-					Port: 8443,
-					SecureVirtualHosts: securevirtualhosts(
-						&SecureVirtualHost{
-							VirtualHost: VirtualHost{
-								Name: "foo.com",
-								Routes: routes(
-									routeUpgrade("/", service(s1)),
-								),
-							},
-							MinTLSVersion: "1.3",
-							Secret:        secret(sec1),
-						},
+						virtualhost("bar.com", &Route{
+							PathMatchCondition: prefixString("/"),
+							Clusters:           clustermap(s1),
+							TimeoutPolicy:      RouteTimeoutPolicy{ResponseTimeout: timeout.DisabledSetting()},
+						}),
 					),
 				},
 			),
 		},
-		"insert httpproxy with invalid tls version": {
+		"insert httpproxy with missing tls delegation should not present port 80": {
 			objs: []any{
-				proxyMinTLSInvalid, s1, sec1,
+				s10, proxyDelegatedTLSSecret,
+			},
+			want: listeners(), // no listeners, ir19 is invalid
+		},
+		"insert httpproxy with retry annotations": {
+			objs: []any{
+				proxyRetryPolicyValidTimeout,
+				s1,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("foo.com", routeUpgrade("/", service(s1))),
-					),
-				}, &Listener{
-					Name: HTTPS_LISTENER_NAME,
-					Port: 8443,
-					SecureVirtualHosts: securevirtualhosts(
-						securevirtualhost("foo.com", sec1, routeUpgrade("/", service(s1))),
+						virtualhost("bar.com", &Route{
+							PathMatchCondition: prefixString("/"),
+							Clusters:           clustermap(s1),
+							RetryPolicy: &RetryPolicy{
+								RetryOn:       "5xx",
+								NumRetries:    6,
+								PerTryTimeout: timeout.DurationSetting(10 * time.Second),
+							},
+						}),
 					),
 				},
 			),
 		},
-		"insert httpproxy referencing two backends, one missing": {
+		"insert httpproxy with invalid PerTryTimeout": {
 			objs: []any{
-				proxyMultipleBackends, s2,
+				proxyRetryPolicyInvalidTimeout,
+				s1,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("example.com", prefixroute("/", service(s2))),
+						virtualhost("bar.com", &Route{
+							PathMatchCondition: prefixString("/"),
+							Clusters:           clustermap(s1),
+							RetryPolicy: &RetryPolicy{
+								RetryOn:       "5xx",
+								NumRetries:    6,
+								PerTryTimeout: timeout.DefaultSetting(),
+							},
+						}),
 					),
 				},
 			),
 		},
-		"insert httpproxy with a wildcard fqdn": {
+		"insert httpproxy with zero retry count": {
 			objs: []any{
-				proxyWildcardFQDN, s1,
+				proxyRetryPolicyZeroRetries,
+				s1,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("*.projectcontour.io",
-							&Route{
-								PathMatchCondition: prefixString("/"),
-								HeaderMatchConditions: []HeaderMatchCondition{
-									{Name: ":authority", Value: "^[a-z0-9]([-a-z0-9]*[a-z0-9])?\\.projectcontour\\.io(:[0-9]+)?", MatchType: "regex", Invert: false},
-								},
-								Clusters: clusters(service(s1)),
-							}),
+						virtualhost("bar.com", &Route{
+							PathMatchCondition: prefixString("/"),
+							Clusters:           clustermap(s1),
+							RetryPolicy: &RetryPolicy{
+								RetryOn:       "5xx",
+								NumRetries:    1,
+								PerTryTimeout: timeout.DurationSetting(10 * time.Second),
+							},
+						}),
 					),
 				},
 			),
 		},
-		"insert httpproxy referencing two backends": {
+		"ingressv1: insert ingress with timeout policy": {
 			objs: []any{
-				proxyMultipleBackends, s1, s2,
+				i14V1,
+				s1,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("example.com", prefixroute("/", service(s1), service(s2))),
+						virtualhost("*", &Route{
+							PathMatchCondition: prefixString("/"),
+							Clusters:           clustermap(s1),
+							RetryPolicy: &RetryPolicy{
+								RetryOn:       "gateway-error",
+								NumRetries:    6,
+								PerTryTimeout: timeout.DurationSetting(10 * time.Second),
+							},
+						}),
 					),
 				},
 			),
 		},
-		"ingressv1: insert ingress w/ tls min proto annotation": {
+		"ingressv1: insert ingress with regex route": {
 			objs: []any{
-				i10aV1,
-				sec1,
+				i15V1,
 				s1,
 			},
 			want: listeners(
@@ -10314,29 +11245,24 @@ func TestDAGInsert(t *testing.T) {
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("b.example.com", prefixroute("/", service(s1))),
-					),
-				}, &Listener{
-					Name: HTTPS_LISTENER_NAME,
-					Port: 8443,
-					SecureVirtualHosts: securevirtualhosts(
-						&SecureVirtualHost{
-							VirtualHost: VirtualHost{
-								Name: "b.example.com",
-								Routes: routes(
-									prefixroute("/", service(s1)),
-								),
-							},
-							MinTLSVersion: "1.3",
-							Secret:        secret(sec1),
-						},
+						virtualhost("*", &Route{
+							PathMatchCondition: regex("/[^/]+/invoices(/.*|/?)"),
+							Clusters:           clustermap(s1),
+						}),
 					),
 				},
 			),
 		},
-		"ingressv1: insert ingress w/ websocket route annotation": {
+		"ingressv1: insert ingress with invalid regex route": {
 			objs: []any{
-				i11V1,
+				i15InvalidRegexV1,
+				s1,
+			},
+			want: listeners(),
+		},
+		"ingressv1: insert ingress with various path match types": {
+			objs: []any{
+				iPathMatchTypesV1,
 				s1,
 			},
 			want: listeners(
@@ -10345,365 +11271,455 @@ func TestDAGInsert(t *testing.T) {
 					Port: 8080,
 					VirtualHosts: virtualhosts(
 						virtualhost("*",
-							prefixroute("/", service(s1)),
-							routeWebsocket("/ws1", service(s1)),
+							&Route{
+								PathMatchCondition: exact("/exact"),
+								Clusters:           clustermap(s1),
+							},
+							&Route{
+								PathMatchCondition: exact("/exact_with_regex/.*"),
+								Clusters:           clustermap(s1),
+							},
+							&Route{
+								PathMatchCondition: prefixSegment("/prefix"),
+								Clusters:           clustermap(s1),
+							},
+							&Route{
+								// Trailing slash is stripped.
+								PathMatchCondition: prefixSegment("/prefix_trailing_slash"),
+								Clusters:           clustermap(s1),
+							},
+							&Route{
+								PathMatchCondition: prefixSegment("/prefix_with_regex/.*"),
+								Clusters:           clustermap(s1),
+							},
+							&Route{
+								PathMatchCondition: prefixString("/implementation_specific"),
+								Clusters:           clustermap(s1),
+							},
+							&Route{
+								PathMatchCondition: regex("/implementation_specific_with_regex/.*"),
+								Clusters:           clustermap(s1),
+							},
 						),
 					),
 				},
 			),
 		},
-		"ingressv1: insert ingress w/ invalid legacy timeout annotation": {
+		"ingressv1: insert ingress with wildcard hostnames": {
 			objs: []any{
-				i12aV1,
 				s1,
+				i16V1,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("*", &Route{
-							PathMatchCondition: prefixString("/"),
-							Clusters:           clustermap(s1),
+						virtualhost("*", prefixroute("/", service(s1))),
+						virtualhost("*.example.com", &Route{
+							PathMatchCondition: &PrefixMatchCondition{Prefix: "/"},
+							HeaderMatchConditions: []HeaderMatchCondition{
+								{
+									Name:      ":authority",
+									MatchType: HeaderMatchTypeRegex,
+									Value:     "^[a-z0-9]([-a-z0-9]*[a-z0-9])?\\.example\\.com(:[0-9]+)?",
+								},
+							},
+							Clusters: clusters(service(s1)),
 						}),
 					),
 				},
 			),
 		},
-		"ingressv1: insert ingress w/ invalid timeout annotation": {
+		"ingressv1: insert ingress overlay": {
 			objs: []any{
-				i12dV1,
-				s1,
+				i13aV1, i13bV1, sec13, s13a, s13b,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("*", &Route{
-							PathMatchCondition: prefixString("/"),
-							Clusters:           clustermap(s1),
-						}),
+						virtualhost("example.com",
+							routeUpgrade("/", service(s13a)),
+							prefixroute("/.well-known/acme-challenge/gVJl5NWL2owUqZekjHkt_bo3OHYC2XNDURRRgLI5JTk", service(s13b)),
+						),
+					),
+				}, &Listener{
+					Name: HTTPS_LISTENER_NAME,
+					Port: 8443,
+					SecureVirtualHosts: securevirtualhosts(
+						securevirtualhost("example.com", sec13,
+							routeUpgrade("/", service(s13a)),
+							prefixroute("/.well-known/acme-challenge/gVJl5NWL2owUqZekjHkt_bo3OHYC2XNDURRRgLI5JTk", service(s13b)),
+						),
 					),
 				},
 			),
 		},
-		"insert httpproxy w/ invalid timeoutpolicy": {
-			objs: []any{
-				proxyTimeoutPolicyInvalidResponse,
-				s1,
-			},
-			want: listeners(),
-		},
-		"ingressv1: insert ingress w/ valid legacy timeout annotation": {
+		"ingressv1: h2c service annotation": {
 			objs: []any{
-				i12bV1,
-				s1,
+				i3aV1, s3a,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("*", &Route{
-							PathMatchCondition: prefixString("/"),
-							Clusters:           clustermap(s1),
-							TimeoutPolicy: RouteTimeoutPolicy{
-								ResponseTimeout: timeout.DurationSetting(90 * time.Second),
-							},
-						}),
+						virtualhost("*",
+							prefixroute("/", &Service{
+								Protocol: "h2c",
+								Weighted: WeightedService{
+									Weight:           1,
+									ServiceName:      s3a.Name,
+									ServiceNamespace: s3a.Namespace,
+									ServicePort:      s3a.Spec.Ports[0],
+									HealthPort:       s3a.Spec.Ports[0],
+								},
+							}),
+						),
 					),
 				},
 			),
 		},
-		"ingressv1: insert ingress w/ valid timeout annotation": {
+		"ingressv1: h2 service annotation": {
 			objs: []any{
-				i12eV1,
-				s1,
+				i3aV1, s3b,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("*", &Route{
-							PathMatchCondition: prefixString("/"),
-							Clusters:           clustermap(s1),
-							TimeoutPolicy: RouteTimeoutPolicy{
-								ResponseTimeout: timeout.DurationSetting(90 * time.Second),
-							},
-						}),
+						virtualhost("*",
+							prefixroute("/", &Service{
+								Protocol: "h2",
+								Weighted: WeightedService{
+									Weight:           1,
+									ServiceName:      s3b.Name,
+									ServiceNamespace: s3b.Namespace,
+									ServicePort:      s3b.Spec.Ports[0],
+									HealthPort:       s3b.Spec.Ports[0],
+								},
+							}),
+						),
 					),
 				},
 			),
 		},
-		"insert httpproxy w/ valid timeoutpolicy": {
+		"ingressv1: tls service annotation": {
 			objs: []any{
-				proxyTimeoutPolicyValidResponse,
-				s1,
+				i3aV1, s3c,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("bar.com", &Route{
-							PathMatchCondition: prefixString("/"),
-							Clusters:           clustermap(s1),
-							TimeoutPolicy:      RouteTimeoutPolicy{ResponseTimeout: timeout.DurationSetting(90 * time.Second)},
-						}),
+						virtualhost("*",
+							prefixroute("/", &Service{
+								Protocol: "tls",
+								Weighted: WeightedService{
+									Weight:           1,
+									ServiceName:      s3c.Name,
+									ServiceNamespace: s3c.Namespace,
+									ServicePort:      s3c.Spec.Ports[0],
+									HealthPort:       s3c.Spec.Ports[0],
+								},
+							}),
+						),
 					),
 				},
 			),
 		},
-		"ingressv1: insert ingress w/ legacy infinite timeout annotation": {
+		"ingressv1: insert ingress then service w/ upstream annotations": {
 			objs: []any{
-				i12cV1,
-				s1,
+				i1V1,
+				s1b,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("*", &Route{
-							PathMatchCondition: prefixString("/"),
-							Clusters:           clustermap(s1),
-							TimeoutPolicy: RouteTimeoutPolicy{
-								ResponseTimeout: timeout.DisabledSetting(),
-							},
-						}),
+						virtualhost("*",
+							prefixroute("/", &Service{
+								Weighted: WeightedService{
+									Weight:           1,
+									ServiceName:      s1b.Name,
+									ServiceNamespace: s1b.Namespace,
+									ServicePort:      s1b.Spec.Ports[0],
+									HealthPort:       s1b.Spec.Ports[0],
+								},
+								MaxConnections:     9000,
+								MaxPendingRequests: 4096,
+								MaxRequests:        404,
+								MaxRetries:         7,
+							}),
+						),
 					),
 				},
 			),
 		},
-		"ingressv1: insert ingress w/ infinite timeout annotation": {
+		"insert httpproxy with two routes to the same service": {
 			objs: []any{
-				i12fV1,
-				s1,
+				proxyWeightsTwoRoutesDiffWeights, s1,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("*", &Route{
-							PathMatchCondition: prefixString("/"),
-							Clusters:           clustermap(s1),
-							TimeoutPolicy: RouteTimeoutPolicy{
-								ResponseTimeout: timeout.DisabledSetting(),
-							},
-						}),
+						virtualhost("example.com",
+							routeCluster("/a", &Cluster{
+								Upstream: &Service{
+									Weighted: WeightedService{
+										Weight:           1,
+										ServiceName:      s1.Name,
+										ServiceNamespace: s1.Namespace,
+										ServicePort:      s1.Spec.Ports[0],
+										HealthPort:       s1.Spec.Ports[0],
+									},
+								},
+								Weight: 90,
+							}),
+							routeCluster("/b", &Cluster{
+								Upstream: &Service{
+									Weighted: WeightedService{
+										Weight:           1,
+										ServiceName:      s1.Name,
+										ServiceNamespace: s1.Namespace,
+										ServicePort:      s1.Spec.Ports[0],
+										HealthPort:       s1.Spec.Ports[0],
+									},
+								},
+								Weight: 60,
+							}),
+						),
 					),
 				},
 			),
 		},
-		"insert httpproxy w/ infinite timeoutpolicy": {
+		"insert httpproxy with one routes to the same service with two different weights": {
 			objs: []any{
-				proxyTimeoutPolicyInfiniteResponse,
-				s1,
+				proxyWeightsOneRouteDiffWeights, s1,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("bar.com", &Route{
-							PathMatchCondition: prefixString("/"),
-							Clusters:           clustermap(s1),
-							TimeoutPolicy:      RouteTimeoutPolicy{ResponseTimeout: timeout.DisabledSetting()},
-						}),
+						virtualhost("example.com",
+							routeCluster("/a",
+								&Cluster{
+									Upstream: &Service{
+										Weighted: WeightedService{
+											Weight:           1,
+											ServiceName:      s1.Name,
+											ServiceNamespace: s1.Namespace,
+											ServicePort:      s1.Spec.Ports[0],
+											HealthPort:       s1.Spec.Ports[0],
+										},
+									},
+									Weight: 90,
+								}, &Cluster{
+									Upstream: &Service{
+										Weighted: WeightedService{
+											Weight:           1,
+											ServiceName:      s1.Name,
+											ServiceNamespace: s1.Namespace,
+											ServicePort:      s1.Spec.Ports[0],
+											HealthPort:       s1.Spec.Ports[0],
+										},
+									},
+									Weight: 60,
+								},
+							),
+						),
 					),
 				},
 			),
 		},
-		"insert httpproxy with missing tls delegation should not present port 80": {
-			objs: []any{
-				s10, proxyDelegatedTLSSecret,
-			},
-			want: listeners(), // no listeners, ir19 is invalid
-		},
-		"insert httpproxy with retry annotations": {
+		"insert httproxy": {
 			objs: []any{
-				proxyRetryPolicyValidTimeout,
-				s1,
+				proxy1, s1,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("bar.com", &Route{
-							PathMatchCondition: prefixString("/"),
-							Clusters:           clustermap(s1),
-							RetryPolicy: &RetryPolicy{
-								RetryOn:       "5xx",
-								NumRetries:    6,
-								PerTryTimeout: timeout.DurationSetting(10 * time.Second),
-							},
-						}),
+						virtualhost("example.com", prefixroute("/", service(s1))),
 					),
 				},
 			),
 		},
-		"insert httpproxy with invalid PerTryTimeout": {
+		"insert httproxy w/o condition": {
 			objs: []any{
-				proxyRetryPolicyInvalidTimeout,
-				s1,
+				proxy1b, s1,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("bar.com", &Route{
-							PathMatchCondition: prefixString("/"),
-							Clusters:           clustermap(s1),
-							RetryPolicy: &RetryPolicy{
-								RetryOn:       "5xx",
-								NumRetries:    6,
-								PerTryTimeout: timeout.DefaultSetting(),
-							},
-						}),
+						virtualhost("example.com", prefixroute("/", service(s1))),
 					),
 				},
 			),
 		},
-		"insert httpproxy with zero retry count": {
+		"insert httproxy with invalid include": {
 			objs: []any{
-				proxyRetryPolicyZeroRetries,
-				s1,
+				&contour_api_v1.HTTPProxy{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "default",
+						Name:      "example-com",
+					},
+					Spec: contour_api_v1.HTTPProxySpec{
+						VirtualHost: &contour_api_v1.VirtualHost{
+							Fqdn: "example.com",
+						},
+						Includes: []contour_api_v1.Include{{
+							Conditions: []contour_api_v1.MatchCondition{{
+								Prefix: "/finance",
+							}},
+							Name:      "non-existent",
+							Namespace: "non-existent",
+						}},
+					},
+				},
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("bar.com", &Route{
-							PathMatchCondition: prefixString("/"),
-							Clusters:           clustermap(s1),
-							RetryPolicy: &RetryPolicy{
-								RetryOn:       "5xx",
-								NumRetries:    1,
-								PerTryTimeout: timeout.DurationSetting(10 * time.Second),
+						virtualhost("example.com", &Route{
+							PathMatchCondition: prefixString("/finance"),
+							DirectResponse: &DirectResponse{
+								StatusCode: http.StatusBadGateway,
 							},
 						}),
 					),
 				},
 			),
 		},
-		"ingressv1: insert ingress with timeout policy": {
+		"insert httproxy with include references another root": {
 			objs: []any{
-				i14V1,
-				s1,
+				&contour_api_v1.HTTPProxy{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "default",
+						Name:      "example-com",
+					},
+					Spec: contour_api_v1.HTTPProxySpec{
+						VirtualHost: &contour_api_v1.VirtualHost{
+							Fqdn: "example.com",
+						},
+						Includes: []contour_api_v1.Include{{
+							Conditions: []contour_api_v1.MatchCondition{{
+								Prefix: "/finance",
+							}},
+							Name:      "other-root",
+							Namespace: "default",
+						}},
+					},
+				},
+				&contour_api_v1.HTTPProxy{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "other-root",
+						Namespace: "default",
+					},
+					Spec: contour_api_v1.HTTPProxySpec{
+						VirtualHost: &contour_api_v1.VirtualHost{
+							Fqdn: "example2.com",
+						},
+					},
+				},
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("*", &Route{
-							PathMatchCondition: prefixString("/"),
-							Clusters:           clustermap(s1),
-							RetryPolicy: &RetryPolicy{
-								RetryOn:       "gateway-error",
-								NumRetries:    6,
-								PerTryTimeout: timeout.DurationSetting(10 * time.Second),
+						virtualhost("example.com", &Route{
+							PathMatchCondition: prefixString("/finance"),
+							DirectResponse: &DirectResponse{
+								StatusCode: http.StatusBadGateway,
 							},
 						}),
 					),
 				},
 			),
 		},
-		"ingressv1: insert ingress with regex route": {
+		"insert httproxy w/ conditions": {
 			objs: []any{
-				i15V1,
-				s1,
+				proxy1c, s1,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("*", &Route{
-							PathMatchCondition: regex("/[^/]+/invoices(/.*|/?)"),
-							Clusters:           clustermap(s1),
+						virtualhost("example.com", &Route{
+							PathMatchCondition: prefixString("/kuard"),
+							HeaderMatchConditions: []HeaderMatchCondition{
+								{Name: "x-request-id", MatchType: "present"},
+								{Name: "e-tag", Value: "abcdef", MatchType: "contains"},
+								{Name: "x-timeout", Value: "infinity", MatchType: "contains", Invert: true},
+								{Name: "digest-auth", Value: "scott", MatchType: "exact"},
+								{Name: "digest-password", Value: "tiger", MatchType: "exact", Invert: true},
+							},
+							Clusters: clusters(service(s1)),
 						}),
 					),
 				},
 			),
 		},
-		"ingressv1: insert ingress with invalid regex route": {
-			objs: []any{
-				i15InvalidRegexV1,
-				s1,
-			},
-			want: listeners(),
-		},
-		"ingressv1: insert ingress with various path match types": {
+		"insert httproxy w/ multiple routes with a Contains condition on the same header": {
 			objs: []any{
-				iPathMatchTypesV1,
-				s1,
+				proxy2d, s1,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("*",
-							&Route{
-								PathMatchCondition: exact("/exact"),
-								Clusters:           clustermap(s1),
-							},
-							&Route{
-								PathMatchCondition: exact("/exact_with_regex/.*"),
-								Clusters:           clustermap(s1),
-							},
-							&Route{
-								PathMatchCondition: prefixSegment("/prefix"),
-								Clusters:           clustermap(s1),
-							},
-							&Route{
-								// Trailing slash is stripped.
-								PathMatchCondition: prefixSegment("/prefix_trailing_slash"),
-								Clusters:           clustermap(s1),
-							},
-							&Route{
-								PathMatchCondition: prefixSegment("/prefix_with_regex/.*"),
-								Clusters:           clustermap(s1),
-							},
-							&Route{
-								PathMatchCondition: prefixString("/implementation_specific"),
-								Clusters:           clustermap(s1),
+						virtualhost("example.com", &Route{
+							PathMatchCondition: prefixString("/"),
+							HeaderMatchConditions: []HeaderMatchCondition{
+								{Name: "e-tag", Value: "abc", MatchType: "contains"},
 							},
-							&Route{
-								PathMatchCondition: regex("/implementation_specific_with_regex/.*"),
-								Clusters:           clustermap(s1),
+							Clusters: clusters(service(s1)),
+						}, &Route{
+							PathMatchCondition: prefixString("/"),
+							HeaderMatchConditions: []HeaderMatchCondition{
+								{Name: "e-tag", Value: "def", MatchType: "contains"},
 							},
-						),
+							Clusters: clusters(service(s1)),
+						}),
 					),
 				},
 			),
 		},
-		"ingressv1: insert ingress with wildcard hostnames": {
+		"insert httproxy w/ multiple routes with condition on the same header, one Contains and one NotContains": {
 			objs: []any{
-				s1,
-				i16V1,
+				proxy2e, s1,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("*", prefixroute("/", service(s1))),
-						virtualhost("*.example.com", &Route{
-							PathMatchCondition: &PrefixMatchCondition{Prefix: "/"},
+						virtualhost("example.com", &Route{
+							PathMatchCondition: prefixString("/"),
 							HeaderMatchConditions: []HeaderMatchCondition{
-								{
-									Name:      ":authority",
-									MatchType: HeaderMatchTypeRegex,
-									Value:     "^[a-z0-9]([-a-z0-9]*[a-z0-9])?\\.example\\.com(:[0-9]+)?",
-								},
+								{Name: "e-tag", Value: "abc", MatchType: "contains"},
+							},
+							Clusters: clusters(service(s1)),
+						}, &Route{
+							PathMatchCondition: prefixString("/"),
+							HeaderMatchConditions: []HeaderMatchCondition{
+								{Name: "e-tag", Value: "abc", MatchType: "contains", Invert: true},
 							},
 							Clusters: clusters(service(s1)),
 						}),
@@ -10711,50 +11727,54 @@ func TestDAGInsert(t *testing.T) {
 				},
 			),
 		},
-		"ingressv1: insert ingress overlay": {
+		"insert httproxy w/ included conditions": {
 			objs: []any{
-				i13aV1, i13bV1, sec13, s13a, s13b,
+				proxy2a, proxy2b, s1,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("example.com",
-							routeUpgrade("/", service(s13a)),
-							prefixroute("/.well-known/acme-challenge/gVJl5NWL2owUqZekjHkt_bo3OHYC2XNDURRRgLI5JTk", service(s13b)),
-						),
-					),
-				}, &Listener{
-					Name: HTTPS_LISTENER_NAME,
-					Port: 8443,
-					SecureVirtualHosts: securevirtualhosts(
-						securevirtualhost("example.com", sec13,
-							routeUpgrade("/", service(s13a)),
-							prefixroute("/.well-known/acme-challenge/gVJl5NWL2owUqZekjHkt_bo3OHYC2XNDURRRgLI5JTk", service(s13b)),
-						),
+						virtualhost("example.com", &Route{
+							PathMatchCondition: prefixString("/kuard"),
+							HeaderMatchConditions: []HeaderMatchCondition{
+								{Name: "x-request-id", MatchType: "present"},
+								{Name: "x-timeout", Value: "infinity", MatchType: "contains", Invert: true},
+								{Name: "digest-auth", Value: "scott", MatchType: "exact"},
+								{Name: "e-tag", Value: "abcdef", MatchType: "contains"},
+								{Name: "digest-password", Value: "tiger", MatchType: "exact", Invert: true},
+							},
+							Clusters: clusters(service(s1)),
+						}, &Route{
+							PathMatchCondition: prefixString("/kuard"),
+							HeaderMatchConditions: []HeaderMatchCondition{
+								{Name: "x-request-id", MatchType: "present"},
+								{Name: "x-timeout", MatchType: "present", Invert: true},
+								{Name: "digest-auth", Value: "scott", MatchType: "exact"},
+								{Name: "e-tag", Value: "abcdef", MatchType: "contains"},
+								{Name: "digest-password", Value: "tiger", MatchType: "exact", Invert: true},
+							},
+							Clusters: clusters(service(s1)),
+						}),
 					),
 				},
 			),
 		},
-		"ingressv1: h2c service annotation": {
+		"insert httpproxy w/ healthcheck": {
 			objs: []any{
-				i3aV1, s3a,
+				proxy2c, s1,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("*",
-							prefixroute("/", &Service{
-								Protocol: "h2c",
-								Weighted: WeightedService{
-									Weight:           1,
-									ServiceName:      s3a.Name,
-									ServiceNamespace: s3a.Namespace,
-									ServicePort:      s3a.Spec.Ports[0],
-									HealthPort:       s3a.Spec.Ports[0],
+						virtualhost("example.com",
+							routeCluster("/", &Cluster{
+								Upstream: service(s1),
+								HTTPHealthCheckPolicy: &HTTPHealthCheckPolicy{
+									Path: "/healthz",
 								},
 							}),
 						),
@@ -10762,88 +11782,85 @@ func TestDAGInsert(t *testing.T) {
 				},
 			),
 		},
-		"ingressv1: h2 service annotation": {
+		"insert httpproxy w/ aggregate services": {
 			objs: []any{
-				i3aV1, s3b,
+				proxyAggregate, s1, s2,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("*",
-							prefixroute("/", &Service{
-								Protocol: "h2",
-								Weighted: WeightedService{
-									Weight:           1,
-									ServiceName:      s3b.Name,
-									ServiceNamespace: s3b.Namespace,
-									ServicePort:      s3b.Spec.Ports[0],
-									HealthPort:       s3b.Spec.Ports[0],
-								},
-							}),
-						),
+						virtualhost("example.com", &Route{
+							PathMatchCondition: prefixString("/"),
+							AggregateCluster: &AggregateCluster{
+								Clusters: []*Cluster{{
+									Upstream: service(s1),
+								}, {
+									Upstream: service(s2),
+									Protocol: "h2c",
+								}},
+							},
+						}),
 					),
 				},
 			),
 		},
-		"ingressv1: tls service annotation": {
+		"insert httpproxy w/ failover services": {
 			objs: []any{
-				i3aV1, s3c,
+				proxyFailover, s1, s2, s2a,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("*",
-							prefixroute("/", &Service{
-								Protocol: "tls",
-								Weighted: WeightedService{
-									Weight:           1,
-									ServiceName:      s3c.Name,
-									ServiceNamespace: s3c.Namespace,
-									ServicePort:      s3c.Spec.Ports[0],
-									HealthPort:       s3c.Spec.Ports[0],
-								},
+						virtualhost("example.com",
+							routeCluster("/", &Cluster{
+								Upstream: service(s1),
+								Failover: []*Service{service(s2), service(s2a)},
 							}),
 						),
 					),
 				},
 			),
 		},
-		"ingressv1: insert ingress then service w/ upstream annotations": {
+		"insert httpproxy w/ missing failover service": {
 			objs: []any{
-				i1V1,
-				s1b,
+				proxyFailover, s1, s2,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("*",
-							prefixroute("/", &Service{
-								Weighted: WeightedService{
-									Weight:           1,
-									ServiceName:      s1b.Name,
-									ServiceNamespace: s1b.Namespace,
-									ServicePort:      s1b.Spec.Ports[0],
-									HealthPort:       s1b.Spec.Ports[0],
-								},
-								MaxConnections:     9000,
-								MaxPendingRequests: 4096,
-								MaxRequests:        404,
-								MaxRetries:         7,
+						virtualhost("example.com", directResponseRoute("/", http.StatusServiceUnavailable)),
+					),
+				},
+			),
+		},
+		"insert httpproxy w/ subset": {
+			objs: []any{
+				proxySubset, s1,
+			},
+			want: listeners(
+				&Listener{
+					Name: HTTP_LISTENER_NAME,
+					Port: 8080,
+					VirtualHosts: virtualhosts(
+						virtualhost("example.com",
+							routeCluster("/", &Cluster{
+								Upstream: service(s1),
+								Subset:   map[string]string{"version": "v2"},
 							}),
 						),
 					),
 				},
 			),
 		},
-		"insert httpproxy with two routes to the same service": {
+		"insert httpproxy w/ h1 protocol and connect timeout": {
 			objs: []any{
-				proxyWeightsTwoRoutesDiffWeights, s1,
+				proxyH1, s3a,
 			},
 			want: listeners(
 				&Listener{
@@ -10851,38 +11868,20 @@ func TestDAGInsert(t *testing.T) {
 					Port: 8080,
 					VirtualHosts: virtualhosts(
 						virtualhost("example.com",
-							routeCluster("/a", &Cluster{
-								Upstream: &Service{
-									Weighted: WeightedService{
-										Weight:           1,
-										ServiceName:      s1.Name,
-										ServiceNamespace: s1.Namespace,
-										ServicePort:      s1.Spec.Ports[0],
-										HealthPort:       s1.Spec.Ports[0],
-									},
-								},
-								Weight: 90,
-							}),
-							routeCluster("/b", &Cluster{
-								Upstream: &Service{
-									Weighted: WeightedService{
-										Weight:           1,
-										ServiceName:      s1.Name,
-										ServiceNamespace: s1.Namespace,
-										ServicePort:      s1.Spec.Ports[0],
-										HealthPort:       s1.Spec.Ports[0],
-									},
+							routeCluster("/", &Cluster{
+								Upstream: grpcService(s3a, "h2c"),
+								TimeoutPolicy: ClusterTimeoutPolicy{
+									ConnectTimeout: 500 * time.Millisecond,
 								},
-								Weight: 60,
 							}),
 						),
 					),
 				},
 			),
 		},
-		"insert httpproxy with one routes to the same service with two different weights": {
+		"insert httpproxy w/ http2 keepalive policy": {
 			objs: []any{
-				proxyWeightsOneRouteDiffWeights, s1,
+				proxyKeepalive, s3a,
 			},
 			want: listeners(
 				&Listener{
@@ -10890,274 +11889,239 @@ func TestDAGInsert(t *testing.T) {
 					Port: 8080,
 					VirtualHosts: virtualhosts(
 						virtualhost("example.com",
-							routeCluster("/a",
-								&Cluster{
-									Upstream: &Service{
-										Weighted: WeightedService{
-											Weight:           1,
-											ServiceName:      s1.Name,
-											ServiceNamespace: s1.Namespace,
-											ServicePort:      s1.Spec.Ports[0],
-											HealthPort:       s1.Spec.Ports[0],
-										},
-									},
-									Weight: 90,
-								}, &Cluster{
-									Upstream: &Service{
-										Weighted: WeightedService{
-											Weight:           1,
-											ServiceName:      s1.Name,
-											ServiceNamespace: s1.Namespace,
-											ServicePort:      s1.Spec.Ports[0],
-											HealthPort:       s1.Spec.Ports[0],
-										},
-									},
-									Weight: 60,
+							routeCluster("/", &Cluster{
+								Upstream: grpcService(s3a, "h2c"),
+								Protocol: "h2c",
+								HTTP2Keepalive: &HTTP2KeepaliveConfig{
+									Interval: 30 * time.Second,
+									Timeout:  5 * time.Second,
 								},
-							),
+							}),
 						),
 					),
 				},
 			),
 		},
-		"insert httproxy": {
+		"insert httpproxy in maintenance": {
 			objs: []any{
-				proxy1, s1,
+				proxyMaintenance, s1,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("example.com", prefixroute("/", service(s1))),
+						virtualhost("example.com",
+							&Route{
+								PathMatchCondition: prefixString("/"),
+								Clusters:           clusters(service(s1)),
+								DirectResponse: &DirectResponse{
+									StatusCode: http.StatusServiceUnavailable,
+									Body:       "down for maintenance",
+								},
+							},
+							prefixroute("/healthz", service(s1)),
+							prefixroute("/healthz/ready", service(s1)),
+						),
 					),
 				},
 			),
 		},
-		"insert httproxy w/o condition": {
+		"insert httpproxy w/ a/b test policy": {
 			objs: []any{
-				proxy1b, s1,
+				proxyABTest, s1, s2,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("example.com", prefixroute("/", service(s1))),
+						virtualhost("example.com", &Route{
+							PathMatchCondition: prefixString("/"),
+							Clusters:           clusters(service(s1), service(s2)),
+							ABTestPolicy: &ABTestPolicy{
+								CookieName: "session",
+								Salt:       "checkout",
+							},
+						}),
 					),
 				},
 			),
 		},
-		"insert httproxy with invalid include": {
+		"insert httpproxy w/ runtime fraction": {
 			objs: []any{
-				&contour_api_v1.HTTPProxy{
-					ObjectMeta: metav1.ObjectMeta{
-						Namespace: "default",
-						Name:      "example-com",
-					},
-					Spec: contour_api_v1.HTTPProxySpec{
-						VirtualHost: &contour_api_v1.VirtualHost{
-							Fqdn: "example.com",
-						},
-						Includes: []contour_api_v1.Include{{
-							Conditions: []contour_api_v1.MatchCondition{{
-								Prefix: "/finance",
-							}},
-							Name:      "non-existent",
-							Namespace: "non-existent",
-						}},
-					},
-				},
+				proxyRuntimeFraction, s1, s2,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("example.com", &Route{
-							PathMatchCondition: prefixString("/finance"),
-							DirectResponse: &DirectResponse{
-								StatusCode: http.StatusBadGateway,
+						virtualhost("example.com",
+							&Route{
+								PathMatchCondition: prefixString("/"),
+								Clusters:           clusters(service(s2)),
+								RuntimeFraction: &RuntimeFraction{
+									Flag:           "new-kuard",
+									DefaultPercent: 10,
+								},
 							},
-						}),
+							prefixroute("/", service(s1)),
+						),
 					),
 				},
 			),
 		},
-		"insert httproxy with include references another root": {
+		"insert httpproxy w/ route priorities": {
 			objs: []any{
-				&contour_api_v1.HTTPProxy{
-					ObjectMeta: metav1.ObjectMeta{
-						Namespace: "default",
-						Name:      "example-com",
-					},
-					Spec: contour_api_v1.HTTPProxySpec{
-						VirtualHost: &contour_api_v1.VirtualHost{
-							Fqdn: "example.com",
-						},
-						Includes: []contour_api_v1.Include{{
-							Conditions: []contour_api_v1.MatchCondition{{
-								Prefix: "/finance",
-							}},
-							Name:      "other-root",
-							Namespace: "default",
-						}},
-					},
-				},
-				&contour_api_v1.HTTPProxy{
-					ObjectMeta: metav1.ObjectMeta{
-						Name:      "other-root",
-						Namespace: "default",
-					},
-					Spec: contour_api_v1.HTTPProxySpec{
-						VirtualHost: &contour_api_v1.VirtualHost{
-							Fqdn: "example2.com",
-						},
-					},
-				},
+				proxyPriority, proxyPriorityChild, s1, s2,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("example.com", &Route{
-							PathMatchCondition: prefixString("/finance"),
-							DirectResponse: &DirectResponse{
-								StatusCode: http.StatusBadGateway,
+						virtualhost("example.com",
+							&Route{
+								PathMatchCondition: &RegexMatchCondition{Regex: "/api/.*/legacy"},
+								Clusters:           clusters(service(s2)),
+								Precedence:         10,
 							},
-						}),
+							prefixroute("/api/v1", service(s1)),
+							&Route{
+								PathMatchCondition: prefixString("/child"),
+								Clusters:           clusters(service(s1)),
+								Precedence:         5,
+							},
+						),
 					),
 				},
 			),
 		},
-		"insert httproxy w/ conditions": {
+		"insert httpproxy w/ parameterized includes": {
 			objs: []any{
-				proxy1c, s1,
+				proxyParameters, proxyTemplate, s1, s2,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("example.com", &Route{
-							PathMatchCondition: prefixString("/kuard"),
-							HeaderMatchConditions: []HeaderMatchCondition{
-								{Name: "x-request-id", MatchType: "present"},
-								{Name: "e-tag", Value: "abcdef", MatchType: "contains"},
-								{Name: "x-timeout", Value: "infinity", MatchType: "contains", Invert: true},
-								{Name: "digest-auth", Value: "scott", MatchType: "exact"},
-								{Name: "digest-password", Value: "tiger", MatchType: "exact", Invert: true},
-							},
-							Clusters: clusters(service(s1)),
-						}),
+						virtualhost("example.com",
+							prefixroute("/a/v1", service(s1)),
+							prefixroute("/b/v2", service(s2)),
+						),
 					),
 				},
 			),
 		},
-		"insert httproxy w/ multiple routes with a Contains condition on the same header": {
+		"insert httpproxy w/ or and not conditions": {
 			objs: []any{
-				proxy2d, s1,
+				proxyOrNot, s1,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("example.com", &Route{
-							PathMatchCondition: prefixString("/"),
-							HeaderMatchConditions: []HeaderMatchCondition{
-								{Name: "e-tag", Value: "abc", MatchType: "contains"},
+						virtualhost("example.com",
+							&Route{
+								PathMatchCondition: prefixString("/v1"),
+								HeaderMatchConditions: []HeaderMatchCondition{{
+									Name:      "user-agent",
+									Value:     "bot",
+									MatchType: "contains",
+									Invert:    true,
+								}},
+								Clusters: clusters(service(s1)),
 							},
-							Clusters: clusters(service(s1)),
-						}, &Route{
-							PathMatchCondition: prefixString("/"),
-							HeaderMatchConditions: []HeaderMatchCondition{
-								{Name: "e-tag", Value: "def", MatchType: "contains"},
+							&Route{
+								PathMatchCondition: prefixString("/v2"),
+								HeaderMatchConditions: []HeaderMatchCondition{{
+									Name:      "user-agent",
+									Value:     "bot",
+									MatchType: "contains",
+									Invert:    true,
+								}},
+								Clusters: clusters(service(s1)),
 							},
-							Clusters: clusters(service(s1)),
-						}),
+						),
 					),
 				},
 			),
 		},
-		"insert httproxy w/ multiple routes with condition on the same header, one Contains and one NotContains": {
+		"insert httpproxy w/ aliases": {
 			objs: []any{
-				proxy2e, s1,
+				proxyAliases, sec1, s1,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("example.com", &Route{
+						virtualhost("*.example.org", &Route{
 							PathMatchCondition: prefixString("/"),
 							HeaderMatchConditions: []HeaderMatchCondition{
-								{Name: "e-tag", Value: "abc", MatchType: "contains"},
+								{Name: ":authority", Value: "^[a-z0-9]([-a-z0-9]*[a-z0-9])?\\.example\\.org(:[0-9]+)?", MatchType: "regex", Invert: false},
 							},
-							Clusters: clusters(service(s1)),
-						}, &Route{
+							Clusters:     clusters(service(s1)),
+							HTTPSUpgrade: true,
+						}),
+						virtualhost("example.com", routeUpgrade("/", service(s1))),
+						virtualhost("www.example.com", routeUpgrade("/", service(s1))),
+					),
+				}, &Listener{
+					Name: HTTPS_LISTENER_NAME,
+					Port: 8443,
+					SecureVirtualHosts: securevirtualhosts(
+						securevirtualhost("*.example.org", sec1, &Route{
 							PathMatchCondition: prefixString("/"),
 							HeaderMatchConditions: []HeaderMatchCondition{
-								{Name: "e-tag", Value: "abc", MatchType: "contains", Invert: true},
+								{Name: ":authority", Value: "^[a-z0-9]([-a-z0-9]*[a-z0-9])?\\.example\\.org(:[0-9]+)?", MatchType: "regex", Invert: false},
 							},
-							Clusters: clusters(service(s1)),
+							Clusters:     clusters(service(s1)),
+							HTTPSUpgrade: true,
 						}),
+						securevirtualhost("example.com", sec1, routeUpgrade("/", service(s1))),
+						securevirtualhost("www.example.com", sec1, routeUpgrade("/", service(s1))),
 					),
 				},
 			),
 		},
-		"insert httproxy w/ included conditions": {
+		"insert httpproxy w/ alias secrets": {
 			objs: []any{
-				proxy2a, proxy2b, s1,
+				proxyAliasSecrets, sec1, secAlias, s1,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("example.com", &Route{
-							PathMatchCondition: prefixString("/kuard"),
-							HeaderMatchConditions: []HeaderMatchCondition{
-								{Name: "x-request-id", MatchType: "present"},
-								{Name: "x-timeout", Value: "infinity", MatchType: "contains", Invert: true},
-								{Name: "digest-auth", Value: "scott", MatchType: "exact"},
-								{Name: "e-tag", Value: "abcdef", MatchType: "contains"},
-								{Name: "digest-password", Value: "tiger", MatchType: "exact", Invert: true},
-							},
-							Clusters: clusters(service(s1)),
-						}, &Route{
-							PathMatchCondition: prefixString("/kuard"),
-							HeaderMatchConditions: []HeaderMatchCondition{
-								{Name: "x-request-id", MatchType: "present"},
-								{Name: "x-timeout", MatchType: "present", Invert: true},
-								{Name: "digest-auth", Value: "scott", MatchType: "exact"},
-								{Name: "e-tag", Value: "abcdef", MatchType: "contains"},
-								{Name: "digest-password", Value: "tiger", MatchType: "exact", Invert: true},
-							},
-							Clusters: clusters(service(s1)),
-						}),
+						virtualhost("example.com", routeUpgrade("/", service(s1))),
+						virtualhost("example.org", routeUpgrade("/", service(s1))),
+						virtualhost("www.example.com", routeUpgrade("/", service(s1))),
+					),
+				}, &Listener{
+					Name: HTTPS_LISTENER_NAME,
+					Port: 8443,
+					SecureVirtualHosts: securevirtualhosts(
+						securevirtualhost("example.com", sec1, routeUpgrade("/", service(s1))),
+						securevirtualhost("example.org", secAlias, routeUpgrade("/", service(s1))),
+						securevirtualhost("www.example.com", sec1, routeUpgrade("/", service(s1))),
 					),
 				},
 			),
 		},
-		"insert httpproxy w/ healthcheck": {
+		"insert httpproxy w/ embargoed route": {
 			objs: []any{
-				proxy2c, s1,
+				proxyEmbargo, s1,
 			},
 			want: listeners(
 				&Listener{
 					Name: HTTP_LISTENER_NAME,
 					Port: 8080,
 					VirtualHosts: virtualhosts(
-						virtualhost("example.com",
-							routeCluster("/", &Cluster{
-								Upstream: service(s1),
-								HTTPHealthCheckPolicy: &HTTPHealthCheckPolicy{
-									Path: "/healthz",
-								},
-							}),
-						),
+						virtualhost("example.com", prefixroute("/", service(s1))),
 					),
 				},
 			),
@@ -12968,6 +13932,38 @@ func TestDAGInsert(t *testing.T) {
 				},
 			),
 		},
+		"insert proxy with externalName service and dns lookup family": {
+			objs: []any{
+				proxyExternalNameServiceDNSLookupFamily,
+				s14,
+			},
+			enableExternalNameSvc: true,
+			want: listeners(
+				&Listener{
+					Name: HTTP_LISTENER_NAME,
+					Port: 8080,
+					VirtualHosts: virtualhosts(
+						virtualhost("example.com", &Route{
+							PathMatchCondition: prefixString("/"),
+							Clusters: []*Cluster{{
+								Upstream: &Service{
+									ExternalName: "externalservice.io",
+									Weighted: WeightedService{
+										Weight:           1,
+										ServiceName:      s14.Name,
+										ServiceNamespace: s14.Namespace,
+										ServicePort:      s14.Spec.Ports[0],
+										HealthPort:       s14.Spec.Ports[0],
+									},
+								},
+								SNI:             "externalservice.io",
+								DNSLookupFamily: "v6",
+							}},
+						}),
+					),
+				},
+			),
+		},
 		"insert tcp proxy with externalName service": {
 			objs: []any{
 				tcpProxyExternalNameService,
@@ -16235,3 +17231,148 @@ func withMirror(r *Route, mirror *Service, weight int64) *Route {
 	}
 	return r
 }
+
+func TestHTTPProxyClusters(t *testing.T) {
+	builder := Builder{
+		Source: KubernetesCache{
+			FieldLogger: fixture.NewTestLogger(t),
+		},
+		Processors: []Processor{
+			&ListenerProcessor{},
+			&HTTPProxyProcessor{},
+		},
+	}
+
+	builder.Source.Insert(fixture.NewService("default/kuard").
+		WithPorts(v1.ServicePort{Port: 8080, TargetPort: intstr.FromInt(8080)}))
+	builder.Source.Insert(fixture.NewService("teama/backend").
+		WithPorts(v1.ServicePort{Port: 80, TargetPort: intstr.FromInt(8080)}))
+	builder.Source.Insert(&contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "root",
+			Namespace: "default",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Includes: []contour_api_v1.Include{{
+				Name:      "child",
+				Namespace: "teama",
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/teama",
+				}},
+			}},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: "kuard",
+					Port: 8080,
+				}},
+			}},
+		},
+	})
+	builder.Source.Insert(&contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "child",
+			Namespace: "teama",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: "backend",
+					Port: 80,
+				}},
+			}},
+		},
+	})
+
+	got := map[types.NamespacedName][]string{}
+	for proxy, clusters := range builder.Build().HTTPProxyClusters {
+		for _, c := range clusters {
+			got[proxy] = append(got[proxy], c.Upstream.Weighted.ServiceNamespace+"/"+c.Upstream.Weighted.ServiceName)
+		}
+	}
+
+	assert.Equal(t, map[types.NamespacedName][]string{
+		{Namespace: "default", Name: "root"}: {"default/kuard"},
+		{Namespace: "teama", Name: "child"}:  {"teama/backend"},
+	}, got)
+}
+
+func TestHTTPProxyDependents(t *testing.T) {
+	builder := Builder{
+		Source: KubernetesCache{
+			FieldLogger: fixture.NewTestLogger(t),
+		},
+		Processors: []Processor{
+			&ListenerProcessor{},
+			&HTTPProxyProcessor{},
+		},
+	}
+
+	builder.Source.Insert(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "tls",
+			Namespace: "default",
+		},
+		Type: v1.SecretTypeTLS,
+		Data: secretdata(fixture.CERTIFICATE, fixture.RSA_PRIVATE_KEY),
+	})
+	builder.Source.Insert(fixture.NewService("default/kuard").
+		WithPorts(v1.ServicePort{Port: 8080, TargetPort: intstr.FromInt(8080)}))
+	builder.Source.Insert(&contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "root",
+			Namespace: "default",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName: "tls",
+				},
+			},
+			Includes: []contour_api_v1.Include{{
+				Name:      "child",
+				Namespace: "teama",
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/teama",
+				}},
+			}},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: "kuard",
+					Port: 8080,
+				}},
+			}},
+		},
+	})
+	// The child refers to a Service that doesn't exist,
+	// which is a dependency all the same.
+	builder.Source.Insert(&contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "child",
+			Namespace: "teama",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: "backend",
+					Port: 80,
+				}},
+			}},
+		},
+	})
+
+	assert.Equal(t, map[Dependency][]types.NamespacedName{
+		{Kind: DependencyKindSecret, NamespacedName: types.NamespacedName{Namespace: "default", Name: "tls"}}: {
+			{Namespace: "default", Name: "root"},
+		},
+		{Kind: DependencyKindService, NamespacedName: types.NamespacedName{Namespace: "default", Name: "kuard"}}: {
+			{Namespace: "default", Name: "root"},
+		},
+		{Kind: DependencyKindService, NamespacedName: types.NamespacedName{Namespace: "teama", Name: "backend"}}: {
+			{Namespace: "teama", Name: "child"},
+		},
+	}, builder.Build().Dependents)
+}
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"sync"
 
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
 	contour_api_v1alpha1 "github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
 	"github.com/projectcontour/contour/internal/annotation"
@@ -60,9 +61,15 @@ type KubernetesCache struct {
 	// Secrets that are referred from the configuration file.
 	ConfiguredSecretRefs []*types.NamespacedName
 
+	// ExternalServingSecrets is true if Envoy gets the certificates
+	// and keys that it serves from a secret backend other than
+	// Kubernetes. The referenced Secrets then don't need to exist.
+	ExternalServingSecrets bool
+
 	ingresses                 map[types.NamespacedName]*networking_v1.Ingress
 	httpproxies               map[types.NamespacedName]*contour_api_v1.HTTPProxy
 	secrets                   map[types.NamespacedName]*Secret
+	configmaps                map[types.NamespacedName]*v1.ConfigMap
 	tlscertificatedelegations map[types.NamespacedName]*contour_api_v1.TLSCertificateDelegation
 	services                  map[types.NamespacedName]*v1.Service
 	namespaces                map[string]*v1.Namespace
@@ -74,6 +81,19 @@ type KubernetesCache struct {
 	tcproutes                 map[types.NamespacedName]*gatewayapi_v1alpha2.TCPRoute
 	referencegrants           map[types.NamespacedName]*gatewayapi_v1beta1.ReferenceGrant
 	extensions                map[types.NamespacedName]*contour_api_v1alpha1.ExtensionService
+	certificates              map[types.NamespacedName]*certmanagerv1.Certificate
+
+	// unmatchedHTTPProxies holds the HTTPProxies whose ingress class
+	// doesn't match, which changes to the default ingress class of
+	// their namespace can make match.
+	unmatchedHTTPProxies map[types.NamespacedName]*contour_api_v1.HTTPProxy
+
+	// dependents holds the HTTPProxies that refer to each ConfigMap,
+	// Secret and Service in the last DAG built from the cache, or nil if
+	// no DAG has been built yet. DAGs are also built for the debug
+	// service, so it's guarded by dependentsMu.
+	dependents   map[Dependency][]types.NamespacedName
+	dependentsMu sync.Mutex
 
 	// Metrics contains Prometheus metrics.
 	Metrics *metrics.Metrics
@@ -99,6 +119,7 @@ func (kc *KubernetesCache) init() {
 	kc.ingresses = make(map[types.NamespacedName]*networking_v1.Ingress)
 	kc.httpproxies = make(map[types.NamespacedName]*contour_api_v1.HTTPProxy)
 	kc.secrets = make(map[types.NamespacedName]*Secret)
+	kc.configmaps = make(map[types.NamespacedName]*v1.ConfigMap)
 	kc.tlscertificatedelegations = make(map[types.NamespacedName]*contour_api_v1.TLSCertificateDelegation)
 	kc.services = make(map[types.NamespacedName]*v1.Service)
 	kc.namespaces = make(map[string]*v1.Namespace)
@@ -108,6 +129,8 @@ func (kc *KubernetesCache) init() {
 	kc.grpcroutes = make(map[types.NamespacedName]*gatewayapi_v1alpha2.GRPCRoute)
 	kc.tcproutes = make(map[types.NamespacedName]*gatewayapi_v1alpha2.TCPRoute)
 	kc.extensions = make(map[types.NamespacedName]*contour_api_v1alpha1.ExtensionService)
+	kc.certificates = make(map[types.NamespacedName]*certmanagerv1.Certificate)
+	kc.unmatchedHTTPProxies = make(map[types.NamespacedName]*contour_api_v1.HTTPProxy)
 }
 
 // Insert inserts obj into the KubernetesCache.
@@ -125,12 +148,17 @@ func (kc *KubernetesCache) Insert(obj any) bool {
 			kc.secrets[k8s.NamespacedNameOf(obj)] = &Secret{Object: obj}
 			return kc.secretTriggersRebuild(obj), len(kc.secrets)
 
+		case *v1.ConfigMap:
+			kc.configmaps[k8s.NamespacedNameOf(obj)] = obj
+			return kc.configMapTriggersRebuild(obj), len(kc.configmaps)
+
 		case *v1.Service:
 			kc.services[k8s.NamespacedNameOf(obj)] = obj
 			return kc.serviceTriggersRebuild(obj), len(kc.services)
 
 		case *v1.Namespace:
 			kc.namespaces[obj.Name] = obj
+			kc.matchHTTPProxies(obj.Name)
 			return true, len(kc.namespaces)
 
 		case *networking_v1.Ingress:
@@ -149,19 +177,22 @@ func (kc *KubernetesCache) Insert(obj any) bool {
 			return true, len(kc.ingresses)
 
 		case *contour_api_v1.HTTPProxy:
-			if !ingressclass.MatchesHTTPProxy(obj, kc.IngressClassNames) {
+			if !ingressclass.MatchesHTTPProxy(obj, kc.IngressClassNames, kc.defaultIngressClass(obj.Namespace)) {
 				// We didn't get a match so report this object is being ignored.
 				kc.WithField("name", obj.GetName()).
 					WithField("namespace", obj.GetNamespace()).
 					WithField("kind", k8s.KindOf(obj)).
 					WithField("ingress-class-annotation", annotation.IngressClass(obj)).
 					WithField("ingress-class-name", obj.Spec.IngressClassName).
+					WithField("namespace-default-ingress-class", kc.defaultIngressClass(obj.Namespace)).
 					WithField("target-ingress-classes", kc.IngressClassNames).
 					Debug("ignoring HTTPProxy with unmatched ingress class")
+				kc.unmatchedHTTPProxies[k8s.NamespacedNameOf(obj)] = obj
 				return false, len(kc.httpproxies)
 			}
 
 			kc.httpproxies[k8s.NamespacedNameOf(obj)] = obj
+			delete(kc.unmatchedHTTPProxies, k8s.NamespacedNameOf(obj))
 			return true, len(kc.httpproxies)
 
 		case *contour_api_v1.TLSCertificateDelegation:
@@ -240,6 +271,10 @@ func (kc *KubernetesCache) Insert(obj any) bool {
 			kc.extensions[k8s.NamespacedNameOf(obj)] = obj
 			return true, len(kc.extensions)
 
+		case *certmanagerv1.Certificate:
+			kc.certificates[k8s.NamespacedNameOf(obj)] = obj
+			return kc.certificateTriggersRebuild(obj), len(kc.certificates)
+
 		default:
 			// not an interesting object
 			kc.WithField("object", obj).Error("insert unknown object")
@@ -303,6 +338,11 @@ func (kc *KubernetesCache) remove(obj any) (bool, int) {
 		delete(kc.secrets, m)
 		return kc.secretTriggersRebuild(obj), len(kc.secrets)
 
+	case *v1.ConfigMap:
+		m := k8s.NamespacedNameOf(obj)
+		delete(kc.configmaps, m)
+		return kc.configMapTriggersRebuild(obj), len(kc.configmaps)
+
 	case *v1.Service:
 		m := k8s.NamespacedNameOf(obj)
 		delete(kc.services, m)
@@ -311,6 +351,7 @@ func (kc *KubernetesCache) remove(obj any) (bool, int) {
 	case *v1.Namespace:
 		_, ok := kc.namespaces[obj.Name]
 		delete(kc.namespaces, obj.Name)
+		kc.matchHTTPProxies(obj.Name)
 		return ok, len(kc.namespaces)
 
 	case *networking_v1.Ingress:
@@ -323,6 +364,7 @@ func (kc *KubernetesCache) remove(obj any) (bool, int) {
 		m := k8s.NamespacedNameOf(obj)
 		_, ok := kc.httpproxies[m]
 		delete(kc.httpproxies, m)
+		delete(kc.unmatchedHTTPProxies, m)
 		return ok, len(kc.httpproxies)
 
 	case *contour_api_v1.TLSCertificateDelegation:
@@ -395,6 +437,11 @@ func (kc *KubernetesCache) remove(obj any) (bool, int) {
 		delete(kc.extensions, m)
 		return ok, len(kc.extensions)
 
+	case *certmanagerv1.Certificate:
+		m := k8s.NamespacedNameOf(obj)
+		delete(kc.certificates, m)
+		return kc.certificateTriggersRebuild(obj), len(kc.certificates)
+
 	default:
 		// not interesting
 		kc.WithField("object", obj).Error("remove unknown object")
@@ -405,6 +452,13 @@ func (kc *KubernetesCache) remove(obj any) (bool, int) {
 // serviceTriggersRebuild returns true if this service is referenced
 // by an Ingress or HTTPProxy in this cache.
 func (kc *KubernetesCache) serviceTriggersRebuild(service *v1.Service) bool {
+	// The services of an HTTPProxy that declares parameters are only
+	// known once the DAG has expanded them, so look the service up in
+	// the dependents of the last DAG first.
+	if used, _ := kc.hasDependents(Dependency{Kind: DependencyKindService, NamespacedName: k8s.NamespacedNameOf(service)}); used {
+		return true
+	}
+
 	for _, ingress := range kc.ingresses {
 		if ingress.Namespace != service.Namespace {
 			continue
@@ -437,6 +491,16 @@ func (kc *KubernetesCache) serviceTriggersRebuild(service *v1.Service) bool {
 				if s.Name == service.Name {
 					return true
 				}
+				for _, f := range s.Failover {
+					if f.Name == service.Name {
+						return true
+					}
+				}
+			}
+			for _, s := range route.AggregateServices {
+				if s.Name == service.Name {
+					return true
+				}
 			}
 		}
 		if tcpproxy := proxy.Spec.TCPProxy; tcpproxy != nil {
@@ -498,24 +562,70 @@ func isRefToService(ref gatewayapi_v1beta1.BackendObjectReference, service *v1.S
 		string(ref.Name) == service.Name
 }
 
+// defaultIngressClass returns the default ingress class of the
+// HTTPProxies of the namespace, if any.
+func (kc *KubernetesCache) defaultIngressClass(namespace string) string {
+	if ns, ok := kc.namespaces[namespace]; ok {
+		return annotation.DefaultIngressClass(ns)
+	}
+	return ""
+}
+
+// matchHTTPProxies matches the ingress class of the HTTPProxies of the
+// namespace again, after its default ingress class may have changed.
+func (kc *KubernetesCache) matchHTTPProxies(namespace string) {
+	defaultClass := kc.defaultIngressClass(namespace)
+
+	for name, proxy := range kc.httpproxies {
+		if name.Namespace == namespace && !ingressclass.MatchesHTTPProxy(proxy, kc.IngressClassNames, defaultClass) {
+			delete(kc.httpproxies, name)
+			kc.unmatchedHTTPProxies[name] = proxy
+		}
+	}
+	for name, proxy := range kc.unmatchedHTTPProxies {
+		if name.Namespace == namespace && ingressclass.MatchesHTTPProxy(proxy, kc.IngressClassNames, defaultClass) {
+			delete(kc.unmatchedHTTPProxies, name)
+			kc.httpproxies[name] = proxy
+		}
+	}
+
+	kc.Metrics.SetDAGCacheObjectMetric(k8s.KindOf(&contour_api_v1.HTTPProxy{}), len(kc.httpproxies))
+}
+
 // secretTriggersRebuild returns true if this secret is referenced by an Ingress
 // or HTTPProxy object, or by the configuration file.
 // If the secret is not in the same namespace the function ignores TLSCertificateDelegation.
 // As a result, it may trigger rebuild even if the reference is invalid, which should be rare and not worth the added complexity.
 // Permission is checked when the secret is actually accessed.
 func (kc *KubernetesCache) secretTriggersRebuild(secretObj *v1.Secret) bool {
+	secret := types.NamespacedName{
+		Namespace: secretObj.Namespace,
+		Name:      secretObj.Name,
+	}
+
 	if _, isCA := secretObj.Data[CACertificateKey]; isCA {
 		// locating a secret validation usage involves traversing each
 		// proxy object, determining if there is a valid delegation,
 		// and if the reference the secret as a certificate. The DAG already
-		// does this so don't reproduce the logic and just assume for the moment
-		// that any change to a CA secret will trigger a rebuild.
-		return true
+		// does this, so look the secret up in the dependents of the last
+		// DAG instead, and assume that any change to a CA secret will
+		// trigger a rebuild until the first DAG is built.
+		if used, ok := kc.hasDependents(Dependency{Kind: DependencyKindSecret, NamespacedName: secret}); used || !ok {
+			return true
+		}
+
+		for _, ext := range kc.extensions {
+			if v := ext.Spec.UpstreamValidation; v != nil && secret == k8s.NamespacedNameFrom(v.CACertificate, k8s.DefaultNamespace(ext.Namespace)) {
+				return true
+			}
+		}
 	}
 
-	secret := types.NamespacedName{
-		Namespace: secretObj.Namespace,
-		Name:      secretObj.Name,
+	// Secrets that hold direct response bodies can be referenced by
+	// the routes of any HTTPProxy, so look them up in the dependents
+	// of the last DAG too.
+	if used, _ := kc.hasDependents(Dependency{Kind: DependencyKindSecret, NamespacedName: secret}); used {
+		return true
 	}
 
 	for _, ingress := range kc.ingresses {
@@ -542,6 +652,19 @@ func (kc *KubernetesCache) secretTriggersRebuild(secretObj *v1.Secret) bool {
 			return true
 		}
 
+		if len(tls.CertificateName) > 0 {
+			certificate, ok := kc.certificates[types.NamespacedName{Namespace: proxy.Namespace, Name: tls.CertificateName}]
+			if ok && secret == (types.NamespacedName{Namespace: certificate.Namespace, Name: certificate.Spec.SecretName}) {
+				return true
+			}
+		}
+
+		for _, aliasSecret := range tls.AliasSecrets {
+			if secret == k8s.NamespacedNameFrom(aliasSecret.SecretName, k8s.DefaultNamespace(proxy.Namespace)) {
+				return true
+			}
+		}
+
 		cv := tls.ClientValidation
 		if cv != nil && secret == k8s.NamespacedNameFrom(cv.CertificateRevocationList, k8s.DefaultNamespace(proxy.Namespace)) {
 			return true
@@ -572,6 +695,56 @@ func (kc *KubernetesCache) secretTriggersRebuild(secretObj *v1.Secret) bool {
 	return false
 }
 
+// configMapTriggersRebuild returns true if this ConfigMap is
+// referenced by an HTTPProxy in the last DAG built from the cache.
+// ConfigMaps are only referenced by direct response bodies, which the
+// DAG resolves, so they don't trigger a rebuild until the first DAG
+// is built.
+func (kc *KubernetesCache) configMapTriggersRebuild(configMap *v1.ConfigMap) bool {
+	used, _ := kc.hasDependents(Dependency{Kind: DependencyKindConfigMap, NamespacedName: k8s.NamespacedNameOf(configMap)})
+	return used
+}
+
+// setDependents records the dependents of the last DAG
+// built from the cache.
+func (kc *KubernetesCache) setDependents(dependents map[Dependency][]types.NamespacedName) {
+	kc.dependentsMu.Lock()
+	defer kc.dependentsMu.Unlock()
+
+	if dependents == nil {
+		dependents = map[Dependency][]types.NamespacedName{}
+	}
+	kc.dependents = dependents
+}
+
+// hasDependents returns whether any HTTPProxy refers to dep in
+// the last DAG built from the cache, and false for ok if no DAG
+// has been built yet.
+func (kc *KubernetesCache) hasDependents(dep Dependency) (used bool, ok bool) {
+	kc.dependentsMu.Lock()
+	defer kc.dependentsMu.Unlock()
+
+	if kc.dependents == nil {
+		return false, false
+	}
+	return len(kc.dependents[dep]) > 0, true
+}
+
+// certificateTriggersRebuild returns true if this cert-manager
+// Certificate is referenced by an HTTPProxy in this cache.
+func (kc *KubernetesCache) certificateTriggersRebuild(certificate *certmanagerv1.Certificate) bool {
+	for _, proxy := range kc.httpproxies {
+		if proxy.Namespace != certificate.Namespace {
+			continue
+		}
+		if vh := proxy.Spec.VirtualHost; vh != nil && vh.TLS != nil && vh.TLS.CertificateName == certificate.Name {
+			return true
+		}
+	}
+
+	return false
+}
+
 func isRefToSecret(ref gatewayapi_v1beta1.SecretObjectReference, secret *v1.Secret, gatewayNamespace string) bool {
 	return ref.Group != nil && *ref.Group == "" &&
 		ref.Kind != nil && *ref.Kind == "Secret" &&
@@ -601,7 +774,33 @@ func (kc *KubernetesCache) LookupTLSSecret(name types.NamespacedName, targetName
 	if !kc.delegationPermitted(name, targetNamespace) {
 		return nil, NewDelegationNotPermittedError(fmt.Errorf("Certificate delegation not permitted"))
 	}
-	return kc.LookupTLSSecretInsecure(name)
+	return kc.LookupServingSecretInsecure(name)
+}
+
+// LookupServingSecretInsecure returns Secret with the TLS certificate
+// and private key that Envoy serves. If Envoy gets them from a secret
+// backend other than Kubernetes, the returned Secret only holds the name
+// of the referenced Secret. No delegation check is performed.
+func (kc *KubernetesCache) LookupServingSecretInsecure(name types.NamespacedName) (*Secret, error) {
+	if !kc.ExternalServingSecrets {
+		return kc.LookupTLSSecretInsecure(name)
+	}
+
+	return &Secret{
+		Object: &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name.Name,
+				Namespace: name.Namespace,
+			},
+			Type: v1.SecretTypeTLS,
+		},
+	}, nil
+}
+
+// LookupCertificate returns the cert-manager Certificate with the given name.
+func (kc *KubernetesCache) LookupCertificate(name types.NamespacedName) (*certmanagerv1.Certificate, bool) {
+	certificate, ok := kc.certificates[name]
+	return certificate, ok
 }
 
 // LookupCASecret returns Secret with CA certificate from cache.
@@ -658,6 +857,38 @@ func (kc *KubernetesCache) LookupCRLSecret(name types.NamespacedName, targetName
 	return sec, nil
 }
 
+// LookupSecretKey returns the value of the given key of the Secret
+// with the given name from the cache.
+func (kc *KubernetesCache) LookupSecretKey(name types.NamespacedName, key string) ([]byte, error) {
+	sec, ok := kc.secrets[name]
+	if !ok {
+		return nil, fmt.Errorf("Secret not found")
+	}
+
+	value, ok := sec.Object.Data[key]
+	if !ok {
+		return nil, fmt.Errorf("Secret has no key %q", key)
+	}
+	return value, nil
+}
+
+// LookupConfigMapKey returns the value of the given key of the
+// ConfigMap with the given name from the cache.
+func (kc *KubernetesCache) LookupConfigMapKey(name types.NamespacedName, key string) ([]byte, error) {
+	cm, ok := kc.configmaps[name]
+	if !ok {
+		return nil, fmt.Errorf("ConfigMap not found")
+	}
+
+	if value, ok := cm.Data[key]; ok {
+		return []byte(value), nil
+	}
+	if value, ok := cm.BinaryData[key]; ok {
+		return value, nil
+	}
+	return nil, fmt.Errorf("ConfigMap has no key %q", key)
+}
+
 // LookupUpstreamValidation constructs PeerValidationContext with CA certificate from the cache.
 // If name (referred Secret) is in different namespace than targetNamespace (the referring object),
 // then delegation check is performed.
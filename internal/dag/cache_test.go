@@ -18,6 +18,7 @@ import (
 	"errors"
 	"testing"
 
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
 	contour_api_v1alpha1 "github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
 	"github.com/projectcontour/contour/internal/fixture"
@@ -40,6 +41,7 @@ func TestKubernetesCacheInsert(t *testing.T) {
 	tests := map[string]struct {
 		cacheGateway *types.NamespacedName
 		pre          []any
+		build        bool // build a DAG from pre before inserting obj
 		obj          any
 		want         bool
 	}{
@@ -225,6 +227,37 @@ func TestKubernetesCacheInsert(t *testing.T) {
 			},
 			want: true,
 		},
+		"insert alias secret referenced by httpproxy": {
+			pre: []any{
+				&contour_api_v1.HTTPProxy{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "simple",
+						Namespace: "default",
+					},
+					Spec: contour_api_v1.HTTPProxySpec{
+						VirtualHost: &contour_api_v1.VirtualHost{
+							Aliases: []contour_api_v1.VirtualHostAlias{"www.example.com"},
+							TLS: &contour_api_v1.TLS{
+								SecretName: "secret",
+								AliasSecrets: []contour_api_v1.AliasSecret{{
+									Alias:      "www.example.com",
+									SecretName: "www-secret",
+								}},
+							},
+						},
+					},
+				},
+			},
+			obj: &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "www-secret",
+					Namespace: "default",
+				},
+				Type: v1.SecretTypeTLS,
+				Data: secretdata(fixture.CERTIFICATE, fixture.RSA_PRIVATE_KEY),
+			},
+			want: true,
+		},
 		"insert secret referenced by httpproxy via tls delegation": {
 			pre: []any{
 				&contour_api_v1.HTTPProxy{
@@ -657,6 +690,47 @@ func TestKubernetesCacheInsert(t *testing.T) {
 			obj:  "not an object",
 			want: false,
 		},
+		"insert configmap not referenced": {
+			obj: &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "bodies",
+					Namespace: "default",
+				},
+			},
+			want: false,
+		},
+		"insert configmap referenced by direct response body": {
+			pre: []any{
+				&contour_api_v1.HTTPProxy{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "kuard",
+						Namespace: "default",
+					},
+					Spec: contour_api_v1.HTTPProxySpec{
+						VirtualHost: &contour_api_v1.VirtualHost{
+							Fqdn: "example.com",
+						},
+						Routes: []contour_api_v1.Route{{
+							DirectResponsePolicy: &contour_api_v1.HTTPDirectResponsePolicy{
+								StatusCode: 503,
+								BodyFrom: &contour_api_v1.DirectResponseBodySource{
+									ConfigMapName: "bodies",
+									Key:           "maintenance.html",
+								},
+							},
+						}},
+					},
+				},
+			},
+			build: true,
+			obj: &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "bodies",
+					Namespace: "default",
+				},
+			},
+			want: true,
+		},
 		"insert service": {
 			obj: &v1.Service{
 				ObjectMeta: metav1.ObjectMeta{
@@ -822,6 +896,53 @@ func TestKubernetesCacheInsert(t *testing.T) {
 			},
 			want: true,
 		},
+		"insert service referenced by templated httpproxy": {
+			pre: []any{
+				&contour_api_v1.HTTPProxy{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "root",
+						Namespace: "default",
+					},
+					Spec: contour_api_v1.HTTPProxySpec{
+						VirtualHost: &contour_api_v1.VirtualHost{
+							Fqdn: "example.com",
+						},
+						Includes: []contour_api_v1.Include{{
+							Name: "kuard",
+							Parameters: []contour_api_v1.IncludeParameter{{
+								Name:  "service",
+								Value: "service",
+							}},
+						}},
+					},
+				},
+				&contour_api_v1.HTTPProxy{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "kuard",
+						Namespace: "default",
+					},
+					Spec: contour_api_v1.HTTPProxySpec{
+						Parameters: []contour_api_v1.HTTPProxyParameter{{
+							Name: "service",
+						}},
+						Routes: []contour_api_v1.Route{{
+							Services: []contour_api_v1.Service{{
+								Name: "${service}",
+								Port: 8080,
+							}},
+						}},
+					},
+				},
+			},
+			build: true,
+			obj: &v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "service",
+					Namespace: "default",
+				},
+			},
+			want: true,
+		},
 		"insert service referenced by httpproxy tcpproxy": {
 			pre: []any{
 				&contour_api_v1.HTTPProxy{
@@ -1122,22 +1243,86 @@ func TestKubernetesCacheInsert(t *testing.T) {
 
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
-			cache := KubernetesCache{
-				ConfiguredGatewayToCache: tc.cacheGateway,
-				ConfiguredSecretRefs: []*types.NamespacedName{
-					{Name: "secretReferredByConfigFile", Namespace: "default"}},
-				FieldLogger: fixture.NewTestLogger(t),
-				Client:      new(fakeReader),
+			builder := Builder{
+				Source: KubernetesCache{
+					ConfiguredGatewayToCache: tc.cacheGateway,
+					ConfiguredSecretRefs: []*types.NamespacedName{
+						{Name: "secretReferredByConfigFile", Namespace: "default"}},
+					FieldLogger: fixture.NewTestLogger(t),
+					Client:      new(fakeReader),
+				},
+				Processors: []Processor{
+					&HTTPProxyProcessor{},
+				},
 			}
+			cache := &builder.Source
 			for _, p := range tc.pre {
 				cache.Insert(p)
 			}
+			if tc.build {
+				builder.Build()
+			}
 			got := cache.Insert(tc.obj)
 			assert.Equalf(t, tc.want, got, "Insert failed for object %v ", tc.obj)
 		})
 	}
 }
 
+func TestKubernetesCacheNamespaceDefaultIngressClass(t *testing.T) {
+	cache := KubernetesCache{
+		IngressClassNames: []string{"internal"},
+		FieldLogger:       fixture.NewTestLogger(t),
+	}
+
+	proxy := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "proxy",
+			Namespace: "tenant",
+		},
+	}
+	namespace := func(defaultClass string) *v1.Namespace {
+		return &v1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "tenant",
+				Annotations: map[string]string{
+					"projectcontour.io/default-ingress-class": defaultClass,
+				},
+			},
+		}
+	}
+	cached := func() bool {
+		_, ok := cache.httpproxies[types.NamespacedName{Namespace: "tenant", Name: "proxy"}]
+		return ok
+	}
+
+	// The HTTPProxy doesn't set an ingress class, nor does its namespace.
+	assert.False(t, cache.Insert(proxy))
+	assert.False(t, cached())
+
+	// Its namespace now defaults to the ingress class of Contour.
+	assert.True(t, cache.Insert(namespace("internal")))
+	assert.True(t, cached())
+
+	// Its namespace now defaults to another ingress class.
+	assert.True(t, cache.Insert(namespace("external")))
+	assert.False(t, cached())
+
+	// Its namespace defaults to the ingress class of Contour again,
+	// before the HTTPProxy is updated.
+	assert.True(t, cache.Insert(namespace("internal")))
+	assert.True(t, cache.Remove(proxy))
+	assert.True(t, cache.Insert(proxy))
+	assert.True(t, cached())
+
+	// Its namespace is deleted.
+	assert.True(t, cache.Remove(namespace("internal")))
+	assert.False(t, cached())
+
+	// The HTTPProxy is deleted.
+	assert.False(t, cache.Remove(proxy))
+	assert.Empty(t, cache.unmatchedHTTPProxies)
+}
+
 // Simple fake for use with specific Gateway test cases,
 // just returns an error on Get. This could be improved
 // or replaced with a mock but would also require
@@ -1853,6 +2038,50 @@ func TestLookupService(t *testing.T) {
 	}
 }
 
+func TestLookupServingSecret(t *testing.T) {
+	meta := types.NamespacedName{Namespace: "default", Name: "secret"}
+
+	tests := map[string]struct {
+		external bool
+		want     *Secret
+		wantErr  bool
+	}{
+		"kubernetes secret does not exist": {
+			external: false,
+			wantErr:  true,
+		},
+		"external secret does not need to exist": {
+			external: true,
+			want: &Secret{
+				Object: &v1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "secret",
+						Namespace: "default",
+					},
+					Type: v1.SecretTypeTLS,
+				},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			cache := KubernetesCache{
+				FieldLogger:            fixture.NewTestLogger(t),
+				ExternalServingSecrets: tc.external,
+			}
+
+			got, err := cache.LookupServingSecretInsecure(meta)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
 func TestServiceTriggersRebuild(t *testing.T) {
 
 	cache := func(objs ...any) *KubernetesCache {
@@ -1936,6 +2165,47 @@ func TestServiceTriggersRebuild(t *testing.T) {
 		}
 	}
 
+	httpProxyFailover := func(namespace, name string) *contour_api_v1.HTTPProxy {
+		return &contour_api_v1.HTTPProxy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Spec: contour_api_v1.HTTPProxySpec{
+				Routes: []contour_api_v1.Route{{
+					Services: []contour_api_v1.Service{{
+						Name: "primary",
+						Port: 80,
+						Failover: []contour_api_v1.FailoverService{{
+							Name: name,
+							Port: 80,
+						}},
+					}},
+				}},
+			},
+		}
+	}
+
+	httpProxyAggregate := func(namespace, name string) *contour_api_v1.HTTPProxy {
+		return &contour_api_v1.HTTPProxy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Spec: contour_api_v1.HTTPProxySpec{
+				Routes: []contour_api_v1.Route{{
+					AggregateServices: []contour_api_v1.AggregateService{{
+						Name: "primary",
+						Port: 80,
+					}, {
+						Name: name,
+						Port: 80,
+					}},
+				}},
+			},
+		}
+	}
+
 	tcpProxy := func(namespace, name string) *contour_api_v1.HTTPProxy {
 		return &contour_api_v1.HTTPProxy{
 			ObjectMeta: metav1.ObjectMeta{
@@ -2078,6 +2348,22 @@ func TestServiceTriggersRebuild(t *testing.T) {
 			svc:  service("default", "service-1"),
 			want: false,
 		},
+		"httpproxy failover exists in same namespace as service": {
+			cache: cache(
+				service("default", "service-1"),
+				httpProxyFailover("default", "service-1"),
+			),
+			svc:  service("default", "service-1"),
+			want: true,
+		},
+		"httpproxy aggregate service exists in same namespace as service": {
+			cache: cache(
+				service("default", "service-1"),
+				httpProxyAggregate("default", "service-1"),
+			),
+			svc:  service("default", "service-1"),
+			want: true,
+		},
 		"tcproxy exists in same namespace as service": {
 			cache: cache(
 				service("default", "service-1"),
@@ -2277,6 +2563,12 @@ func TestSecretTriggersRebuild(t *testing.T) {
 		}
 	}
 
+	// built records dependents as those of the last DAG built from kc.
+	built := func(kc *KubernetesCache, dependents map[Dependency][]types.NamespacedName) *KubernetesCache {
+		kc.setDependents(dependents)
+		return kc
+	}
+
 	httpProxyWithClientValidation := func(namespace, name, crlSecretName string) *contour_api_v1.HTTPProxy {
 		return &contour_api_v1.HTTPProxy{
 			ObjectMeta: metav1.ObjectMeta{
@@ -2313,6 +2605,51 @@ func TestSecretTriggersRebuild(t *testing.T) {
 			secret: caSecret,
 			want:   true,
 		},
+		"CA secret used in the last DAG triggers rebuild": {
+			cache: built(cache(), map[Dependency][]types.NamespacedName{
+				{Kind: DependencyKindSecret, NamespacedName: types.NamespacedName{Namespace: "default", Name: "ca"}}: {
+					{Namespace: "default", Name: "proxy"},
+				},
+			}),
+			secret: caSecret,
+			want:   true,
+		},
+		"CA secret not used in the last DAG does not trigger rebuild": {
+			cache: built(cache(), map[Dependency][]types.NamespacedName{
+				{Kind: DependencyKindService, NamespacedName: types.NamespacedName{Namespace: "default", Name: "ca"}}: {
+					{Namespace: "default", Name: "proxy"},
+				},
+			}),
+			secret: caSecret,
+			want:   false,
+		},
+		"CA secret used by an extension service triggers rebuild": {
+			cache: built(cache(
+				&contour_api_v1alpha1.ExtensionService{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "ext",
+						Namespace: "default",
+					},
+					Spec: contour_api_v1alpha1.ExtensionServiceSpec{
+						UpstreamValidation: &contour_api_v1.UpstreamValidation{
+							CACertificate: "ca",
+							SubjectName:   "ext.example.com",
+						},
+					},
+				},
+			), nil),
+			secret: caSecret,
+			want:   true,
+		},
+		"secret used in the last DAG triggers rebuild": {
+			cache: built(cache(), map[Dependency][]types.NamespacedName{
+				{Kind: DependencyKindSecret, NamespacedName: types.NamespacedName{Namespace: "default", Name: "secret"}}: {
+					{Namespace: "default", Name: "proxy"},
+				},
+			}),
+			secret: secret("default", "secret"),
+			want:   true,
+		},
 		"ingress secret triggers rebuild": {
 			cache: cache(
 				ingress("default", "secret", "secret", ""),
@@ -2482,6 +2819,22 @@ func TestSecretTriggersRebuild(t *testing.T) {
 			secret: secret("default", "crl"),
 			want:   true,
 		},
+		"httpproxy references certificate issued to secret": {
+			cache: cache(
+				certificate("default", "cert", "tlscert"),
+				httpProxyWithCertificate("default", "proxy", "cert"),
+			),
+			secret: secret("default", "tlscert"),
+			want:   true,
+		},
+		"httpproxy references certificate issued to another secret": {
+			cache: cache(
+				certificate("default", "cert", "othercert"),
+				httpProxyWithCertificate("default", "proxy", "cert"),
+			),
+			secret: secret("default", "tlscert"),
+			want:   false,
+		},
 	}
 
 	for name, tc := range tests {
@@ -2491,6 +2844,78 @@ func TestSecretTriggersRebuild(t *testing.T) {
 	}
 }
 
+func TestCertificateTriggersRebuild(t *testing.T) {
+	cache := func(objs ...any) *KubernetesCache {
+		cache := KubernetesCache{
+			FieldLogger: fixture.NewTestLogger(t),
+		}
+		for _, o := range objs {
+			cache.Insert(o)
+		}
+		return &cache
+	}
+
+	tests := map[string]struct {
+		cache       *KubernetesCache
+		certificate *certmanagerv1.Certificate
+		want        bool
+	}{
+		"no httpproxies": {
+			cache:       cache(),
+			certificate: certificate("default", "cert", "tlscert"),
+			want:        false,
+		},
+		"httpproxy references certificate": {
+			cache: cache(
+				httpProxyWithCertificate("default", "proxy", "cert"),
+			),
+			certificate: certificate("default", "cert", "tlscert"),
+			want:        true,
+		},
+		"httpproxy in another namespace references certificate name": {
+			cache: cache(
+				httpProxyWithCertificate("user", "proxy", "cert"),
+			),
+			certificate: certificate("default", "cert", "tlscert"),
+			want:        false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.cache.certificateTriggersRebuild(tc.certificate))
+		})
+	}
+}
+
+func certificate(namespace, name, secretName string) *certmanagerv1.Certificate {
+	return &certmanagerv1.Certificate{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: certmanagerv1.CertificateSpec{
+			SecretName: secretName,
+		},
+	}
+}
+
+func httpProxyWithCertificate(namespace, name, certificateName string) *contour_api_v1.HTTPProxy {
+	return &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				TLS: &contour_api_v1.TLS{
+					CertificateName: certificateName,
+				},
+			},
+		},
+	}
+}
+
 func TestRouteTriggersRebuild(t *testing.T) {
 
 	cache := func(objs ...any) *KubernetesCache {
@@ -129,20 +129,292 @@ func includeMatchConditionsValid(conds []contour_api_v1.MatchCondition) error {
 		if cond.Regex != "" {
 			return fmt.Errorf("regex conditions are not allowed in includes block")
 		}
+		if cond.Not != nil {
+			return fmt.Errorf("not conditions are not allowed in includes block")
+		}
+		if len(cond.Or) > 0 {
+			return fmt.Errorf("or conditions are not allowed in includes block")
+		}
+	}
+
+	return nil
+}
+
+// maxOrCombinations is the maximum number of routes
+// that a route with Or conditions is compiled into.
+const maxOrCombinations = 32
+
+// expandOrConditions returns a copy of route for each combination of
+// the alternatives of its Or conditions, in which each Or condition is
+// replaced by one of its alternatives. Routes without Or conditions are
+// returned as they are.
+func expandOrConditions(route contour_api_v1.Route) ([]contour_api_v1.Route, error) {
+	expanded := [][]contour_api_v1.MatchCondition{nil}
+
+	for _, cond := range route.Conditions {
+		if len(cond.Or) == 0 {
+			for i := range expanded {
+				expanded[i] = append(expanded[i], cond)
+			}
+			continue
+		}
+
+		if cond.Prefix != "" || cond.Exact != "" || cond.Regex != "" || cond.Header != nil || cond.QueryParameter != nil || len(cond.Methods) > 0 || cond.Not != nil {
+			return nil, errors.New("or conditions can't be combined with other conditions in the same condition")
+		}
+
+		if len(expanded)*len(cond.Or) > maxOrCombinations {
+			return nil, fmt.Errorf("or conditions can't have more than %d combinations", maxOrCombinations)
+		}
+
+		var next [][]contour_api_v1.MatchCondition
+		for _, conds := range expanded {
+			for _, alt := range cond.Or {
+				// Copy the conditions, since they are shared
+				// by the combinations of the alternatives.
+				combination := append(append([]contour_api_v1.MatchCondition{}, conds...), contour_api_v1.MatchCondition{
+					Prefix:         alt.Prefix,
+					Exact:          alt.Exact,
+					Regex:          alt.Regex,
+					Header:         alt.Header,
+					QueryParameter: alt.QueryParameter,
+					Not:            alt.Not,
+				})
+				next = append(next, combination)
+			}
+		}
+		expanded = next
+	}
+
+	if len(expanded) == 1 {
+		return []contour_api_v1.Route{route}, nil
+	}
+
+	routes := make([]contour_api_v1.Route, 0, len(expanded))
+	for _, conds := range expanded {
+		r := route
+		r.Conditions = conds
+		routes = append(routes, r)
+	}
+	return routes, nil
+}
+
+// negatedMatchConditionsValid validates the Not conditions
+// within a slice of MatchConditions.
+func negatedMatchConditionsValid(conds []contour_api_v1.MatchCondition) error {
+	for _, cond := range conds {
+		not := cond.Not
+		if not == nil {
+			continue
+		}
+
+		set := 0
+		for _, isSet := range []bool{not.Prefix != "", not.Exact != "", not.Regex != "", not.Header != nil, not.QueryParameter != nil} {
+			if isSet {
+				set++
+			}
+		}
+		if set != 1 {
+			return errors.New("not conditions must specify exactly one condition")
+		}
+
+		negated := []contour_api_v1.MatchCondition{{
+			Prefix:         not.Prefix,
+			Exact:          not.Exact,
+			Regex:          not.Regex,
+			Header:         not.Header,
+			QueryParameter: not.QueryParameter,
+		}}
+		if err := pathMatchConditionsValid(negated); err != nil {
+			return fmt.Errorf("not: %w", err)
+		}
+		if err := headerMatchConditionsValid(negated); err != nil {
+			return fmt.Errorf("not: %w", err)
+		}
+		if err := queryParameterMatchConditionsValid(negated); err != nil {
+			return fmt.Errorf("not: %w", err)
+		}
+		if not.Header != nil && len(headerMatchConditions([]contour_api_v1.HeaderMatchCondition{*not.Header})) == 0 {
+			return errors.New("not: must specify a header condition")
+		}
 	}
 
 	return nil
 }
 
+// negatedMatchCondition returns the HeaderMatchCondition that matches
+// requests that don't match the given Not condition. Path and query
+// parameter conditions are negated by matching the :path header.
+func negatedMatchCondition(not *contour_api_v1.NegatedMatchCondition) HeaderMatchCondition {
+	pathCondition := func(regex string) HeaderMatchCondition {
+		return HeaderMatchCondition{
+			Name:      ":path",
+			Value:     regex,
+			MatchType: HeaderMatchTypeRegex,
+			Invert:    true,
+		}
+	}
+
+	switch {
+	case not.Prefix != "":
+		return pathCondition(regexp.QuoteMeta(not.Prefix) + `.*`)
+	case not.Exact != "":
+		return pathCondition(regexp.QuoteMeta(not.Exact) + `(\?.*)?`)
+	case not.Regex != "":
+		return pathCondition(`(?:` + not.Regex + `)(\?.*)?`)
+	case not.QueryParameter != nil:
+		return pathCondition(queryParameterPathRegex(*not.QueryParameter))
+	default:
+		hc := headerMatchConditions([]contour_api_v1.HeaderMatchCondition{*not.Header})[0]
+		hc.Invert = !hc.Invert
+		return hc
+	}
+}
+
+// queryParameterPathRegex returns a regex that matches the
+// request paths with a query parameter that matches cond.
+func queryParameterPathRegex(cond contour_api_v1.QueryParameterMatchCondition) string {
+	value := func(v string) string {
+		v = regexp.QuoteMeta(v)
+		if cond.IgnoreCase {
+			return `(?i:` + v + `)`
+		}
+		return v
+	}
+
+	var match string
+	switch {
+	case cond.Exact != "":
+		match = `=` + value(cond.Exact)
+	case cond.Prefix != "":
+		match = `=` + value(cond.Prefix) + `[^&]*`
+	case cond.Suffix != "":
+		match = `=[^&]*` + value(cond.Suffix)
+	case cond.Contains != "":
+		match = `=[^&]*` + value(cond.Contains) + `[^&]*`
+	case cond.Regex != "":
+		match = `=(?:` + cond.Regex + `)`
+	case cond.Present:
+		match = `(=[^&]*)?`
+	}
+
+	return `[^?]*\?(.*&)?` + regexp.QuoteMeta(cond.Name) + match + `(&.*)?`
+}
+
 func mergeHeaderMatchConditions(conds []contour_api_v1.MatchCondition) []HeaderMatchCondition {
 	var headerConditions []contour_api_v1.HeaderMatchCondition
+	var negated []HeaderMatchCondition
 	for _, cond := range conds {
 		if cond.Header != nil {
 			headerConditions = append(headerConditions, *cond.Header)
 		}
+		if cond.Not != nil {
+			negated = append(negated, negatedMatchCondition(cond.Not))
+		}
+	}
+
+	hc := append(headerMatchConditions(headerConditions), negated...)
+	if methods, ok := mergeMethodMatchConditions(conds); ok {
+		hc = append(hc, methodMatchCondition(methods))
+	}
+	return hc
+}
+
+// knownMethods are the request methods that method conditions can match.
+var knownMethods = map[contour_api_v1.HTTPMethod]bool{
+	"GET":     true,
+	"HEAD":    true,
+	"POST":    true,
+	"PUT":     true,
+	"DELETE":  true,
+	"CONNECT": true,
+	"OPTIONS": true,
+	"TRACE":   true,
+	"PATCH":   true,
+}
+
+// mergeMethodMatchConditions returns the methods that satisfy every
+// method condition of conds, in the order of the first condition,
+// and whether there are any method conditions.
+func mergeMethodMatchConditions(conds []contour_api_v1.MatchCondition) ([]contour_api_v1.HTTPMethod, bool) {
+	var methods []contour_api_v1.HTTPMethod
+	found := false
+
+	for _, cond := range conds {
+		if len(cond.Methods) == 0 {
+			continue
+		}
+		if !found {
+			found = true
+			for _, m := range cond.Methods {
+				if !containsMethod(methods, m) {
+					methods = append(methods, m)
+				}
+			}
+			continue
+		}
+
+		var common []contour_api_v1.HTTPMethod
+		for _, m := range methods {
+			if containsMethod(cond.Methods, m) {
+				common = append(common, m)
+			}
+		}
+		methods = common
+	}
+
+	return methods, found
+}
+
+func containsMethod(methods []contour_api_v1.HTTPMethod, method contour_api_v1.HTTPMethod) bool {
+	for _, m := range methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// methodMatchCondition returns the HeaderMatchCondition that matches
+// requests with any of the given methods. Envoy uses the HTTP/2
+// ":method" header internally for both HTTP/1 and HTTP/2 requests.
+func methodMatchCondition(methods []contour_api_v1.HTTPMethod) HeaderMatchCondition {
+	if len(methods) == 1 {
+		return HeaderMatchCondition{
+			Name:      ":method",
+			Value:     string(methods[0]),
+			MatchType: HeaderMatchTypeExact,
+		}
 	}
 
-	return headerMatchConditions(headerConditions)
+	values := make([]string, 0, len(methods))
+	for _, m := range methods {
+		values = append(values, regexp.QuoteMeta(string(m)))
+	}
+	return HeaderMatchCondition{
+		Name:      ":method",
+		Value:     strings.Join(values, "|"),
+		MatchType: HeaderMatchTypeRegex,
+	}
+}
+
+// methodMatchConditionsValid validates the method conditions within a
+// slice of MatchConditions. It returns an error if a method is unknown,
+// or if no method satisfies every method condition.
+func methodMatchConditionsValid(conds []contour_api_v1.MatchCondition) error {
+	for _, cond := range conds {
+		for _, m := range cond.Methods {
+			if !knownMethods[m] {
+				return fmt.Errorf("unknown method %q", m)
+			}
+		}
+	}
+
+	if methods, ok := mergeMethodMatchConditions(conds); ok && len(methods) == 0 {
+		return errors.New("method conditions don't have a method in common")
+	}
+
+	return nil
 }
 
 func mergeQueryParamMatchConditions(conds []contour_api_v1.MatchCondition) []QueryParamMatchCondition {
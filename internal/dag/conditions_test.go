@@ -14,6 +14,7 @@
 package dag
 
 import (
+	"regexp"
 	"testing"
 
 	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
@@ -350,6 +351,146 @@ func TestHeaderMatchConditions(t *testing.T) {
 				Value:     "abcdef",
 			}},
 		},
+		"not header regex": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Not: &contour_api_v1.NegatedMatchCondition{
+					Header: &contour_api_v1.HeaderMatchCondition{
+						Name:  "user-agent",
+						Regex: ".*bot.*",
+					},
+				},
+			}},
+			want: []HeaderMatchCondition{{
+				Name:      "user-agent",
+				MatchType: "regex",
+				Value:     ".*bot.*",
+				Invert:    true,
+			}},
+		},
+		"not header not present": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Not: &contour_api_v1.NegatedMatchCondition{
+					Header: &contour_api_v1.HeaderMatchCondition{
+						Name:       "x-request-id",
+						NotPresent: true,
+					},
+				},
+			}},
+			want: []HeaderMatchCondition{{
+				Name:      "x-request-id",
+				MatchType: "present",
+			}},
+		},
+		"not prefix": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Not: &contour_api_v1.NegatedMatchCondition{
+					Prefix: "/admin.v1",
+				},
+			}},
+			want: []HeaderMatchCondition{{
+				Name:      ":path",
+				MatchType: "regex",
+				Value:     `/admin\.v1.*`,
+				Invert:    true,
+			}},
+		},
+		"not exact": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Not: &contour_api_v1.NegatedMatchCondition{
+					Exact: "/healthz",
+				},
+			}},
+			want: []HeaderMatchCondition{{
+				Name:      ":path",
+				MatchType: "regex",
+				Value:     `/healthz(\?.*)?`,
+				Invert:    true,
+			}},
+		},
+		"not regex": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Not: &contour_api_v1.NegatedMatchCondition{
+					Regex: "/v[0-9]+/.*",
+				},
+			}},
+			want: []HeaderMatchCondition{{
+				Name:      ":path",
+				MatchType: "regex",
+				Value:     `(?:/v[0-9]+/.*)(\?.*)?`,
+				Invert:    true,
+			}},
+		},
+		"not query parameter prefix, ignoring case": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Not: &contour_api_v1.NegatedMatchCondition{
+					QueryParameter: &contour_api_v1.QueryParameterMatchCondition{
+						Name:       "debug",
+						Prefix:     "true",
+						IgnoreCase: true,
+					},
+				},
+			}},
+			want: []HeaderMatchCondition{{
+				Name:      ":path",
+				MatchType: "regex",
+				Value:     `[^?]*\?(.*&)?debug=(?i:true)[^&]*(&.*)?`,
+				Invert:    true,
+			}},
+		},
+		"header and not header": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Not: &contour_api_v1.NegatedMatchCondition{
+					Header: &contour_api_v1.HeaderMatchCondition{
+						Name:  "x-tenant",
+						Exact: "internal",
+					},
+				},
+			}, {
+				Header: &contour_api_v1.HeaderMatchCondition{
+					Name:    "x-tenant",
+					Present: true,
+				},
+			}},
+			want: []HeaderMatchCondition{{
+				Name:      "x-tenant",
+				MatchType: "present",
+			}, {
+				Name:      "x-tenant",
+				MatchType: "exact",
+				Value:     "internal",
+				Invert:    true,
+			}},
+		},
+		"single method": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Methods: []contour_api_v1.HTTPMethod{"POST"},
+			}},
+			want: []HeaderMatchCondition{{
+				Name:      ":method",
+				MatchType: "exact",
+				Value:     "POST",
+			}},
+		},
+		"methods merged with headers": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Methods: []contour_api_v1.HTTPMethod{"GET", "HEAD", "GET"},
+			}, {
+				Header: &contour_api_v1.HeaderMatchCondition{
+					Name:    "x-request-id",
+					Present: true,
+				},
+			}, {
+				Methods: []contour_api_v1.HTTPMethod{"POST", "HEAD", "GET"},
+			}},
+			want: []HeaderMatchCondition{{
+				Name:      "x-request-id",
+				MatchType: "present",
+			}, {
+				Name:      ":method",
+				MatchType: "regex",
+				Value:     "GET|HEAD",
+			}},
+		},
 	}
 
 	for name, tc := range tests {
@@ -929,3 +1070,213 @@ func TestRegexMatchConditionsValid(t *testing.T) {
 		})
 	}
 }
+
+func TestQueryParameterPathRegex(t *testing.T) {
+	tests := map[string]struct {
+		cond    contour_api_v1.QueryParameterMatchCondition
+		matches []string
+		misses  []string
+	}{
+		"exact": {
+			cond:    contour_api_v1.QueryParameterMatchCondition{Name: "debug", Exact: "true"},
+			matches: []string{"/?debug=true", "/api?a=b&debug=true", "/api?debug=true&a=b"},
+			misses:  []string{"/", "/?debug=truer", "/?xdebug=true", "/debug=true", "/?debug=TRUE"},
+		},
+		"exact, ignoring case": {
+			cond:    contour_api_v1.QueryParameterMatchCondition{Name: "debug", Exact: "true", IgnoreCase: true},
+			matches: []string{"/?debug=TRUE"},
+			misses:  []string{"/?DEBUG=true"},
+		},
+		"prefix": {
+			cond:    contour_api_v1.QueryParameterMatchCondition{Name: "v", Prefix: "1."},
+			matches: []string{"/?v=1.2", "/?a=b&v=1."},
+			misses:  []string{"/?v=12", "/?v=2&a=1.2"},
+		},
+		"suffix": {
+			cond:    contour_api_v1.QueryParameterMatchCondition{Name: "file", Suffix: ".pdf"},
+			matches: []string{"/?file=a.pdf", "/?file=a.pdf&x=y"},
+			misses:  []string{"/?file=a.pdfx", "/?file=apdf"},
+		},
+		"contains": {
+			cond:    contour_api_v1.QueryParameterMatchCondition{Name: "q", Contains: "cat"},
+			matches: []string{"/?q=concatenate", "/?q=cat"},
+			misses:  []string{"/?q=dog&x=cat"},
+		},
+		"regex": {
+			cond:    contour_api_v1.QueryParameterMatchCondition{Name: "id", Regex: "[0-9]+"},
+			matches: []string{"/?id=42", "/?a=1&id=42&b=2"},
+			misses:  []string{"/?id=abc", "/?id="},
+		},
+		"present": {
+			cond:    contour_api_v1.QueryParameterMatchCondition{Name: "debug", Present: true},
+			matches: []string{"/?debug", "/?debug=", "/?a=1&debug=1"},
+			misses:  []string{"/", "/?debugger=1", "/debug"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			re := regexp.MustCompile(`^(?:` + queryParameterPathRegex(tc.cond) + `)$`)
+			for _, path := range tc.matches {
+				assert.True(t, re.MatchString(path), "expected %q to match", path)
+			}
+			for _, path := range tc.misses {
+				assert.False(t, re.MatchString(path), "expected %q not to match", path)
+			}
+		})
+	}
+}
+
+func TestExpandOrConditions(t *testing.T) {
+	header := func(name, value string) *contour_api_v1.HeaderMatchCondition {
+		return &contour_api_v1.HeaderMatchCondition{Name: name, Exact: value}
+	}
+
+	tests := map[string]struct {
+		conditions []contour_api_v1.MatchCondition
+		want       [][]contour_api_v1.MatchCondition
+		wantErr    bool
+	}{
+		"no or conditions": {
+			conditions: []contour_api_v1.MatchCondition{{Prefix: "/api"}},
+			want:       [][]contour_api_v1.MatchCondition{{{Prefix: "/api"}}},
+		},
+		"one or condition": {
+			conditions: []contour_api_v1.MatchCondition{{
+				Header: header("x-tenant", "a"),
+			}, {
+				Or: []contour_api_v1.OrMatchCondition{{Prefix: "/v1"}, {Prefix: "/v2"}},
+			}},
+			want: [][]contour_api_v1.MatchCondition{
+				{{Header: header("x-tenant", "a")}, {Prefix: "/v1"}},
+				{{Header: header("x-tenant", "a")}, {Prefix: "/v2"}},
+			},
+		},
+		"two or conditions": {
+			conditions: []contour_api_v1.MatchCondition{{
+				Or: []contour_api_v1.OrMatchCondition{{Prefix: "/v1"}, {Prefix: "/v2"}},
+			}, {
+				Or: []contour_api_v1.OrMatchCondition{{Header: header("x-tenant", "a")}, {Header: header("x-tenant", "b")}},
+			}},
+			want: [][]contour_api_v1.MatchCondition{
+				{{Prefix: "/v1"}, {Header: header("x-tenant", "a")}},
+				{{Prefix: "/v1"}, {Header: header("x-tenant", "b")}},
+				{{Prefix: "/v2"}, {Header: header("x-tenant", "a")}},
+				{{Prefix: "/v2"}, {Header: header("x-tenant", "b")}},
+			},
+		},
+		"or combined with another condition": {
+			conditions: []contour_api_v1.MatchCondition{{
+				Prefix: "/api",
+				Or:     []contour_api_v1.OrMatchCondition{{Header: header("x-tenant", "a")}},
+			}},
+			wantErr: true,
+		},
+		"too many combinations": {
+			conditions: []contour_api_v1.MatchCondition{
+				{Or: make([]contour_api_v1.OrMatchCondition, 8)},
+				{Or: make([]contour_api_v1.OrMatchCondition, 8)},
+			},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := expandOrConditions(contour_api_v1.Route{Conditions: tc.conditions})
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			var conditions [][]contour_api_v1.MatchCondition
+			for _, route := range got {
+				conditions = append(conditions, route.Conditions)
+			}
+			assert.Equal(t, tc.want, conditions)
+		})
+	}
+}
+
+func TestMethodMatchConditionsValid(t *testing.T) {
+	tests := map[string]struct {
+		conds []contour_api_v1.MatchCondition
+		want  bool
+	}{
+		"no methods": {
+			conds: []contour_api_v1.MatchCondition{{Prefix: "/"}},
+			want:  true,
+		},
+		"methods in common": {
+			conds: []contour_api_v1.MatchCondition{
+				{Methods: []contour_api_v1.HTTPMethod{"GET", "POST"}},
+				{Methods: []contour_api_v1.HTTPMethod{"POST", "PUT"}},
+			},
+			want: true,
+		},
+		"no method in common": {
+			conds: []contour_api_v1.MatchCondition{
+				{Methods: []contour_api_v1.HTTPMethod{"GET"}},
+				{Methods: []contour_api_v1.HTTPMethod{"POST"}},
+			},
+			want: false,
+		},
+		"unknown method": {
+			conds: []contour_api_v1.MatchCondition{
+				{Methods: []contour_api_v1.HTTPMethod{"get"}},
+			},
+			want: false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := methodMatchConditionsValid(tc.conds)
+			assert.Equal(t, tc.want, err == nil, "got error %v", err)
+		})
+	}
+}
+
+func TestNegatedMatchConditionsValid(t *testing.T) {
+	tests := map[string]struct {
+		not  *contour_api_v1.NegatedMatchCondition
+		want bool
+	}{
+		"prefix": {
+			not:  &contour_api_v1.NegatedMatchCondition{Prefix: "/admin"},
+			want: true,
+		},
+		"header": {
+			not:  &contour_api_v1.NegatedMatchCondition{Header: &contour_api_v1.HeaderMatchCondition{Name: "x-a", Present: true}},
+			want: true,
+		},
+		"nothing set": {
+			not:  &contour_api_v1.NegatedMatchCondition{},
+			want: false,
+		},
+		"more than one condition set": {
+			not:  &contour_api_v1.NegatedMatchCondition{Prefix: "/admin", Exact: "/admin"},
+			want: false,
+		},
+		"prefix without slash": {
+			not:  &contour_api_v1.NegatedMatchCondition{Prefix: "admin"},
+			want: false,
+		},
+		"header without a condition": {
+			not:  &contour_api_v1.NegatedMatchCondition{Header: &contour_api_v1.HeaderMatchCondition{Name: "x-a"}},
+			want: false,
+		},
+		"invalid query parameter regex": {
+			not:  &contour_api_v1.NegatedMatchCondition{QueryParameter: &contour_api_v1.QueryParameterMatchCondition{Name: "id", Regex: "[0-9"}},
+			want: false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := negatedMatchConditionsValid([]contour_api_v1.MatchCondition{{Not: tc.not}})
+			assert.Equal(t, tc.want, err == nil, "got error %v", err)
+		})
+	}
+}
@@ -20,14 +20,20 @@ import (
 	"fmt"
 	"net"
 	"regexp"
+	"runtime"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	contour_api_v1alpha1 "github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
 	"github.com/projectcontour/contour/internal/status"
 	"github.com/projectcontour/contour/internal/timeout"
+	"github.com/projectcontour/contour/internal/xds"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // Observer is an interface for receiving notification of DAG updates.
@@ -56,6 +62,42 @@ func ComposeObservers(observers ...Observer) Observer {
 	})
 }
 
+// ComposeObserversParallel returns a new Observer that calls its arguments
+// concurrently on a pool of at most workers goroutines, and returns once all
+// of them have returned. If workers is less than 1, runtime.GOMAXPROCS(0) is
+// used. The observers must not modify the DAG.
+func ComposeObserversParallel(workers int, observers ...Observer) Observer {
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(observers) {
+		workers = len(observers)
+	}
+	if workers <= 1 {
+		return ComposeObservers(observers...)
+	}
+
+	return ObserverFunc(func(d *DAG) {
+		pending := make(chan Observer, len(observers))
+		for _, o := range observers {
+			pending <- o
+		}
+		close(pending)
+
+		var wg sync.WaitGroup
+		wg.Add(workers)
+		for i := 0; i < workers; i++ {
+			go func() {
+				defer wg.Done()
+				for o := range pending {
+					o.OnChange(d)
+				}
+			}()
+		}
+		wg.Wait()
+	})
+}
+
 type DAG struct {
 	// StatusCache holds a cache of status updates to send.
 	StatusCache status.Cache
@@ -67,6 +109,159 @@ type DAG struct {
 	// and Listeners are derived from the Gateway's Listeners, or
 	// false otherwise.
 	HasDynamicListeners bool
+
+	// NextScheduleChange is the next time at which a route schedule
+	// changes whether its route is active, so that the DAG has to
+	// be rebuilt then. It is the zero time if no schedule changes.
+	NextScheduleChange time.Time
+
+	// DelegatedSecrets holds the HTTPProxies that use each TLS
+	// Secret that is delegated to them from another namespace.
+	DelegatedSecrets map[types.NamespacedName][]types.NamespacedName
+
+	// HTTPProxyClusters holds the clusters that the routes of
+	// each HTTPProxy send requests to, keyed by the namespace
+	// and name of the HTTPProxy that defines the routes.
+	HTTPProxyClusters map[types.NamespacedName][]*Cluster
+
+	// DeprecatedFeatures holds the objects that use each
+	// deprecated feature, keyed by the name of the feature.
+	DeprecatedFeatures map[string][]client.Object
+
+	// Dependents holds the HTTPProxies that refer to each
+	// ConfigMap, Secret and Service, whether or not the object exists
+	// and the reference is valid.
+	Dependents map[Dependency][]types.NamespacedName
+}
+
+// Kinds of the objects that HTTPProxies depend on.
+const (
+	DependencyKindConfigMap = "ConfigMap"
+	DependencyKindSecret    = "Secret"
+	DependencyKindService   = "Service"
+)
+
+// Dependency is an object that HTTPProxies refer to.
+type Dependency struct {
+	Kind string
+	types.NamespacedName
+}
+
+// String returns the dependency as <kind>/<namespace>/<name>,
+// with the kind in lower case.
+func (d Dependency) String() string {
+	return strings.ToLower(d.Kind) + "/" + d.Namespace + "/" + d.Name
+}
+
+// ParseDependency parses a dependency written as
+// <kind>/<namespace>/<name>, with the kind in any case.
+func ParseDependency(s string) (Dependency, error) {
+	parts := strings.Split(s, "/")
+	if len(parts) != 3 || parts[1] == "" || parts[2] == "" {
+		return Dependency{}, fmt.Errorf("invalid object %q: must be <kind>/<namespace>/<name>", s)
+	}
+
+	for _, kind := range []string{DependencyKindSecret, DependencyKindService} {
+		if strings.EqualFold(parts[0], kind) {
+			return Dependency{
+				Kind:           kind,
+				NamespacedName: types.NamespacedName{Namespace: parts[1], Name: parts[2]},
+			}, nil
+		}
+	}
+
+	return Dependency{}, fmt.Errorf("invalid object %q: kind must be secret or service", s)
+}
+
+// Deprecated features of the resources that are processed
+// into the DAG.
+const (
+	// DeprecatedTCPProxyIncludes is the "includes" field of
+	// an HTTPProxy TCPProxy.
+	DeprecatedTCPProxyIncludes = "httpproxy:spec.tcpproxy.includes"
+
+	// DeprecatedIngressClassAnnotation is the
+	// "kubernetes.io/ingress.class" annotation of an Ingress.
+	DeprecatedIngressClassAnnotation = "ingress:kubernetes.io/ingress.class"
+)
+
+// DeprecatedFeatureReplacements holds what replaces
+// each deprecated feature.
+var DeprecatedFeatureReplacements = map[string]string{
+	DeprecatedTCPProxyIncludes:       "spec.tcpproxy.include",
+	DeprecatedIngressClassAnnotation: "spec.ingressClassName",
+}
+
+// scheduleChange records that a route schedule changes at t.
+func (d *DAG) scheduleChange(t time.Time) {
+	if t.IsZero() {
+		return
+	}
+	if d.NextScheduleChange.IsZero() || t.Before(d.NextScheduleChange) {
+		d.NextScheduleChange = t
+	}
+}
+
+// useDelegatedSecret records that proxy uses secret, if
+// secret is delegated to it from another namespace.
+func (d *DAG) useDelegatedSecret(secret types.NamespacedName, proxy types.NamespacedName) {
+	if secret.Namespace == proxy.Namespace {
+		return
+	}
+	if d.DelegatedSecrets == nil {
+		d.DelegatedSecrets = make(map[types.NamespacedName][]types.NamespacedName)
+	}
+	for _, p := range d.DelegatedSecrets[secret] {
+		if p == proxy {
+			return
+		}
+	}
+	d.DelegatedSecrets[secret] = append(d.DelegatedSecrets[secret], proxy)
+}
+
+// useDependency records that proxy refers to the object
+// of the given kind and name.
+func (d *DAG) useDependency(kind string, name types.NamespacedName, proxy types.NamespacedName) {
+	dep := Dependency{Kind: kind, NamespacedName: name}
+	if d.Dependents == nil {
+		d.Dependents = make(map[Dependency][]types.NamespacedName)
+	}
+	for _, p := range d.Dependents[dep] {
+		if p == proxy {
+			return
+		}
+	}
+	d.Dependents[dep] = append(d.Dependents[dep], proxy)
+}
+
+// useHTTPProxyClusters records that a route of proxy
+// sends requests to the clusters of r.
+func (d *DAG) useHTTPProxyClusters(proxy types.NamespacedName, r *Route) {
+	clusters := r.Clusters
+	if r.AggregateCluster != nil {
+		clusters = r.AggregateCluster.Clusters
+	}
+	if len(clusters) == 0 {
+		return
+	}
+	if d.HTTPProxyClusters == nil {
+		d.HTTPProxyClusters = make(map[types.NamespacedName][]*Cluster)
+	}
+	d.HTTPProxyClusters[proxy] = append(d.HTTPProxyClusters[proxy], clusters...)
+}
+
+// useDeprecatedFeature records that obj uses
+// the deprecated feature.
+func (d *DAG) useDeprecatedFeature(feature string, obj client.Object) {
+	if d.DeprecatedFeatures == nil {
+		d.DeprecatedFeatures = make(map[string][]client.Object)
+	}
+	for _, o := range d.DeprecatedFeatures[feature] {
+		if o == obj {
+			return
+		}
+	}
+	d.DeprecatedFeatures[feature] = append(d.DeprecatedFeatures[feature], obj)
 }
 
 type MatchCondition interface {
@@ -96,6 +291,15 @@ type PrefixMatchCondition struct {
 	PrefixMatchType PrefixMatchType
 }
 
+// covers returns true if every path that starts with
+// prefix is also matched by the condition.
+func (pc *PrefixMatchCondition) covers(prefix string) bool {
+	if pc.PrefixMatchType == PrefixMatchSegment {
+		return prefix == pc.Prefix || strings.HasPrefix(prefix, strings.TrimSuffix(pc.Prefix, "/")+"/")
+	}
+	return strings.HasPrefix(prefix, pc.Prefix)
+}
+
 func (ec *ExactMatchCondition) String() string {
 	return "exact: " + ec.Path
 }
@@ -233,6 +437,22 @@ type Redirect struct {
 	PathRewritePolicy *PathRewritePolicy
 }
 
+// HTTPSRedirectPolicy defines how HTTP requests to routes
+// that require TLS are redirected to HTTPS.
+type HTTPSRedirectPolicy struct {
+	// StatusCode is the HTTP response code to
+	// use. Valid options are 301 or 308.
+	StatusCode int
+
+	// StripQuery removes the query string from
+	// the redirect location.
+	StripQuery bool
+
+	// ExcludedPathPrefixes are path prefixes that are
+	// served over HTTP instead of being redirected.
+	ExcludedPathPrefixes []string
+}
+
 const (
 	// InternalRedirectCrossSchemeNever deny following a redirect if the schemes are different.
 	InternalRedirectCrossSchemeNever = "never"
@@ -289,12 +509,24 @@ type Route struct {
 	// Route has a higher priority.
 	Priority uint8
 
+	// Precedence orders the Route before all Routes with a lower
+	// precedence, regardless of their match conditions.
+	Precedence int32
+
 	Clusters []*Cluster
 
+	// AggregateCluster is the aggregate cluster traffic is sent
+	// to, instead of Clusters.
+	AggregateCluster *AggregateCluster
+
 	// Should this route generate a 301 upgrade if accessed
 	// over HTTP?
 	HTTPSUpgrade bool
 
+	// HTTPSRedirectPolicy customizes the upgrade redirect
+	// generated if HTTPSUpgrade is set.
+	HTTPSRedirectPolicy *HTTPSRedirectPolicy
+
 	// AuthDisabled is set if authorization should be disabled
 	// for this route. If authorization is disabled, the AuthContext
 	// field has no effect.
@@ -313,6 +545,9 @@ type Route struct {
 	// RetryPolicy defines the retry / number / timeout options for a route
 	RetryPolicy *RetryPolicy
 
+	// HedgePolicy defines when requests to the route are hedged.
+	HedgePolicy *HedgePolicy
+
 	// Indicates that during forwarding, the matched prefix (or path) should be swapped with this value
 	PathRewritePolicy *PathRewritePolicy
 
@@ -329,6 +564,14 @@ type Route struct {
 	// headers should be rewritten for responses on this route.
 	CookieRewritePolicies []CookieRewritePolicy
 
+	// ABTestPolicy defines the request attribute that is hashed
+	// to pick the cluster of the route, if not nil.
+	ABTestPolicy *ABTestPolicy
+
+	// RuntimeFraction restricts the route to a percentage
+	// of the requests that match its conditions, if not nil.
+	RuntimeFraction *RuntimeFraction
+
 	// RateLimitPolicy defines if/how requests for the route are rate limited.
 	RateLimitPolicy *RateLimitPolicy
 
@@ -362,6 +605,17 @@ type Route struct {
 	// requests should be filtered. The behavior of the filters is governed
 	// by IPFilterAllow.
 	IPFilterRules []IPFilterRule
+
+	// Metadata is added to the Envoy route metadata.
+	Metadata map[string]string
+
+	// Capture is set if requests and responses matching
+	// this route should be captured by the HTTP tap filter.
+	Capture bool
+
+	// StatPrefix is the prefix of the Envoy per-route statistics
+	// of this route. If empty, no per-route statistics are emitted.
+	StatPrefix string
 }
 
 // HasPathPrefix returns whether this route has a PrefixPathCondition.
@@ -391,6 +645,49 @@ type RouteTimeoutPolicy struct {
 	// IdleStreamTimeout is the timeout applied to idle connection during single request-response.
 	// Stream is HTTP/2 and HTTP/3 concept, for HTTP/1 it refers to single request-response within connection.
 	IdleStreamTimeout timeout.Setting
+
+	// ResponseTimeoutReply customizes the reply sent when
+	// the response timeout expires, if not nil.
+	ResponseTimeoutReply *TimeoutReply
+
+	// IdleStreamTimeoutReply customizes the reply sent when
+	// the idle stream timeout expires, if not nil.
+	IdleStreamTimeoutReply *TimeoutReply
+}
+
+// ABTestPolicy defines the request attribute that is hashed to pick the
+// cluster of a route. Exactly one of HeaderName and CookieName is set.
+type ABTestPolicy struct {
+	HeaderName string
+	CookieName string
+	Salt       string
+}
+
+// RuntimeFraction defines the runtime flag that
+// gives the percentage of requests a route matches.
+type RuntimeFraction struct {
+	// Flag is the name of the runtime flag.
+	Flag string
+
+	// DefaultPercent is the percentage of requests
+	// that match the route while the flag isn't set.
+	DefaultPercent uint32
+}
+
+func (rf *RuntimeFraction) String() string {
+	return fmt.Sprintf("runtime: flag: %s default: %d", rf.Flag, rf.DefaultPercent)
+}
+
+// TimeoutReply customizes the local reply that Envoy sends when a timeout expires.
+type TimeoutReply struct {
+	// StatusCode replaces the status code of the reply, if not zero.
+	StatusCode uint32
+
+	// Body replaces the body of the reply, if not empty.
+	Body string
+
+	// ContentType is the content type of Body.
+	ContentType string
 }
 
 // ClusterTimeoutPolicy defines the timeout policy for a cluster.
@@ -418,6 +715,37 @@ type RetryPolicy struct {
 	// PerTryTimeout specifies the timeout per retry attempt.
 	// Ignored if RetryOn is blank.
 	PerTryTimeout timeout.Setting
+
+	// RetriableHeaders specifies the response headers under which retry takes place.
+	RetriableHeaders []HeaderMatchCondition
+
+	// RetriableRequestHeaders restricts retries to the
+	// requests that match any of the conditions.
+	RetriableRequestHeaders []HeaderMatchCondition
+
+	// PerTryIdleTimeout specifies the idle timeout per retry attempt.
+	// Ignored if RetryOn is blank.
+	PerTryIdleTimeout timeout.Setting
+
+	// AvoidPreviousHosts avoids the hosts of previous attempts
+	// when selecting the host for a retry.
+	AvoidPreviousHosts bool
+
+	// HostSelectionMaxAttempts specifies the maximum number of times
+	// the host for a retry is selected. Zero means the Envoy default.
+	HostSelectionMaxAttempts int64
+
+	// BackOffBaseInterval and BackOffMaxInterval specify the
+	// exponential back-off between retries. Zero means the
+	// Envoy default.
+	BackOffBaseInterval, BackOffMaxInterval time.Duration
+}
+
+// HedgePolicy defines when requests are hedged.
+type HedgePolicy struct {
+	// HedgeOnPerTryTimeout sends a retry when the per-try
+	// timeout is hit without cancelling the attempts in flight.
+	HedgeOnPerTryTimeout bool
 }
 
 // PathRewritePolicy defines a policy for rewriting the path of
@@ -435,6 +763,20 @@ type PathRewritePolicy struct {
 	PrefixRegexRemove string
 }
 
+// AggregateCluster sends traffic to the first of its Clusters
+// that has healthy endpoints.
+type AggregateCluster struct {
+	// Clusters are the Clusters of the aggregate cluster,
+	// in order of preference.
+	Clusters []*Cluster
+
+	// RequestHeadersPolicy defines how headers are managed during forwarding
+	RequestHeadersPolicy *HeadersPolicy
+
+	// ResponseHeadersPolicy defines how headers are managed during forwarding
+	ResponseHeadersPolicy *HeadersPolicy
+}
+
 // MirrorPolicy defines the mirroring policy for a route.
 type MirrorPolicy struct {
 	Cluster *Cluster
@@ -706,6 +1048,10 @@ type VirtualHost struct {
 	// by IPFilterAllow.
 	IPFilterRules []IPFilterRule
 
+	// AccessLogPolicy overrides the access log configuration
+	// for the requests to the virtual host, if not nil.
+	AccessLogPolicy *AccessLogPolicy
+
 	Routes map[string]*Route
 }
 
@@ -716,6 +1062,37 @@ func (v *VirtualHost) AddRoute(route *Route) {
 	v.Routes[conditionsToString(route)] = route
 }
 
+// addHTTPSRedirectExclusions adds routes for the path prefixes that
+// the HTTPS redirect policy of route excludes from being redirected.
+// The added routes are copies of route that serve the request instead
+// of redirecting it. Routes that already exist are kept.
+func (v *VirtualHost) addHTTPSRedirectExclusions(route *Route) {
+	if !route.HTTPSUpgrade || route.HTTPSRedirectPolicy == nil {
+		return
+	}
+
+	prefix, ok := route.PathMatchCondition.(*PrefixMatchCondition)
+	if !ok {
+		return
+	}
+
+	for _, excluded := range route.HTTPSRedirectPolicy.ExcludedPathPrefixes {
+		if !prefix.covers(excluded) {
+			continue
+		}
+
+		exclusion := *route
+		exclusion.PathMatchCondition = &PrefixMatchCondition{Prefix: excluded, PrefixMatchType: PrefixMatchString}
+		exclusion.HTTPSUpgrade = false
+		exclusion.HTTPSRedirectPolicy = nil
+
+		if _, ok := v.Routes[conditionsToString(&exclusion)]; ok {
+			continue
+		}
+		v.AddRoute(&exclusion)
+	}
+}
+
 func conditionsToString(r *Route) string {
 	s := []string{r.PathMatchCondition.String()}
 	for _, cond := range r.HeaderMatchConditions {
@@ -724,6 +1101,9 @@ func conditionsToString(r *Route) string {
 	for _, cond := range r.QueryParamMatchConditions {
 		s = append(s, cond.String())
 	}
+	if r.RuntimeFraction != nil {
+		s = append(s, r.RuntimeFraction.String())
+	}
 	return strings.Join(s, ",")
 }
 
@@ -757,6 +1137,10 @@ type SecureVirtualHost struct {
 
 	// JWTProviders specify how to verify JWTs.
 	JWTProviders []JWTProvider
+
+	// HTTP2MaxConcurrentStreams overrides the listener's maximum
+	// concurrent streams of downstream HTTP/2 connections.
+	HTTP2MaxConcurrentStreams *uint32
 }
 
 type JWTProvider struct {
@@ -790,6 +1174,23 @@ type JWTRule struct {
 	ProviderName          string
 }
 
+// AccessLogPolicy defines how the requests to a virtual host are
+// access logged. Settings with a zero value keep the access log
+// configuration of the listener.
+type AccessLogPolicy struct {
+	// Disabled turns off access logging.
+	Disabled bool
+
+	// Format is the access log format.
+	Format contour_api_v1alpha1.AccessLogType
+
+	// FormatString is the format string of the envoy access log format.
+	FormatString string
+
+	// JSONFields are the fields of the json access log format.
+	JSONFields contour_api_v1alpha1.AccessLogJSONFields
+}
+
 type IPFilterRule struct {
 	// Remote determines what ip to filter on.
 	// If true, filters on the remote address. If false, filters on the
@@ -941,6 +1342,15 @@ type Cluster struct {
 	// at this Cluster will be forwarded to.
 	Upstream *Service
 
+	// Failover are the backend Kubernetes services that traffic is
+	// forwarded to when the endpoints of the Upstream are unhealthy,
+	// in priority order.
+	Failover []*Service
+
+	// Subset holds the pod labels that select the endpoints of the
+	// Upstream that receive traffic, using Envoy subset load balancing.
+	Subset map[string]string
+
 	// The relative weight of this Cluster compared to its siblings.
 	Weight uint32
 
@@ -999,11 +1409,73 @@ type Cluster struct {
 
 	SlowStartConfig *SlowStartConfig
 
+	// MaglevTableSize sets the size of the lookup table of
+	// the Maglev load balancer. Zero means the Envoy default.
+	MaglevTableSize uint64
+
+	// ActiveRequestBias sets the active request bias of the
+	// least request load balancer. Nil means the Envoy default.
+	ActiveRequestBias *float64
+
 	// MaxRequestsPerConnection defines the maximum number of requests per connection to the upstream before it is closed.
 	MaxRequestsPerConnection *uint32
 
 	// PerConnectionBufferLimitBytes defines the soft limit on size of the cluster’s new connection read and write buffers.
 	PerConnectionBufferLimitBytes *uint32
+
+	// HTTP2Keepalive defines the PING frames sent on HTTP/2
+	// connections to the upstream. It is only used when the
+	// cluster's protocol is h2 or h2c.
+	HTTP2Keepalive *HTTP2KeepaliveConfig
+
+	// HTTP2Settings defines the settings of HTTP/2 connections
+	// to the upstream. It is only used when the cluster's
+	// protocol is h2 or h2c.
+	HTTP2Settings *HTTP2Settings
+}
+
+// ClusterLoadAssignmentName returns the name of the EDS
+// ClusterLoadAssignment that holds the endpoints of this Cluster.
+// The endpoints of Clusters with failover Services are merged, so
+// their name is made of the names of each Service, in priority order.
+//
+// The endpoints of Clusters with a subset have metadata for the
+// subset labels, so their name also includes the label keys.
+func (c *Cluster) ClusterLoadAssignmentName() string {
+	names := []string{serviceLoadAssignmentName(c.Upstream)}
+	for _, s := range c.Failover {
+		names = append(names, serviceLoadAssignmentName(s))
+	}
+
+	name := strings.Join(names, ";")
+	if keys := c.SubsetKeys(); len(keys) > 0 {
+		name += "|" + strings.Join(keys, ",")
+	}
+
+	return name
+}
+
+// SubsetKeys returns the sorted label keys of the subset of this
+// Cluster, or nil if it doesn't have one.
+func (c *Cluster) SubsetKeys() []string {
+	if len(c.Subset) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(c.Subset))
+	for k := range c.Subset {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func serviceLoadAssignmentName(s *Service) string {
+	return xds.ClusterLoadAssignmentName(
+		types.NamespacedName{Name: s.Weighted.ServiceName, Namespace: s.Weighted.ServiceNamespace},
+		s.Weighted.ServicePort.Name,
+	)
 }
 
 // WeightedService represents the load balancing weight of a
@@ -1019,6 +1491,9 @@ type WeightedService struct {
 	ServicePort v1.ServicePort
 	// HealthPort is the port for healthcheck.
 	HealthPort v1.ServicePort
+	// Priority is the priority level of the endpoints of this
+	// service, where 0 is the highest.
+	Priority uint32
 }
 
 // ServiceCluster capture the set of Kubernetes Services that will
@@ -1031,6 +1506,9 @@ type ServiceCluster struct {
 	ClusterName string
 	// Services are the load balancing targets. This slice must not be empty.
 	Services []WeightedService
+	// SubsetKeys are the pod labels that are added to the metadata
+	// of the endpoints, for subset load balancing.
+	SubsetKeys []string
 }
 
 // DeepCopy performs a deep copy of ServiceClusters
@@ -1041,6 +1519,10 @@ func (s *ServiceCluster) DeepCopy() *ServiceCluster {
 		Services:    make([]WeightedService, len(s.Services)),
 	}
 
+	if s.SubsetKeys != nil {
+		s2.SubsetKeys = append([]string{}, s.SubsetKeys...)
+	}
+
 	for i, w := range s.Services {
 		s2.Services[i] = w
 		w.ServicePort.DeepCopyInto(&s2.Services[i].ServicePort)
@@ -1193,6 +1675,14 @@ type ExtensionCluster struct {
 	// ClientCertificate is the optional identifier of the TLS secret containing client certificate and
 	// private key to be used when establishing TLS connection to upstream cluster.
 	ClientCertificate *Secret
+
+	// HTTP2Keepalive defines the PING frames sent on
+	// HTTP/2 connections to the extension.
+	HTTP2Keepalive *HTTP2KeepaliveConfig
+
+	// HTTP2Settings defines the settings of
+	// HTTP/2 connections to the extension.
+	HTTP2Settings *HTTP2Settings
 }
 
 const singleDNSLabelWildcardRegex = "^[a-z0-9]([-a-z0-9]*[a-z0-9])?"
@@ -1214,6 +1704,25 @@ func wildcardDomainHeaderMatch(fqdn string) HeaderMatchCondition {
 	}
 }
 
+// HTTP2KeepaliveConfig holds the settings of the HTTP/2 PING
+// frames that keep upstream connections alive.
+type HTTP2KeepaliveConfig struct {
+	Interval time.Duration
+	Timeout  time.Duration
+}
+
+func (k *HTTP2KeepaliveConfig) String() string {
+	return k.Interval.String() + "/" + k.Timeout.String()
+}
+
+// HTTP2Settings holds the settings of HTTP/2 connections to
+// upstreams. Settings that are nil use Envoy's defaults.
+type HTTP2Settings struct {
+	MaxConcurrentStreams        *uint32
+	InitialStreamWindowSize     *uint32
+	InitialConnectionWindowSize *uint32
+}
+
 // SlowStartConfig holds configuration for gradually increasing amount of traffic to a newly added endpoint.
 type SlowStartConfig struct {
 	Window           time.Duration
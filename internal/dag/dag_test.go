@@ -14,9 +14,13 @@
 package dag
 
 import (
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 )
@@ -107,6 +111,40 @@ func TestObserverFunc(t *testing.T) {
 	assert.Equal(t, true, result)
 }
 
+func TestComposeObserversParallel(t *testing.T) {
+	for _, workers := range []int{0, 1, 2, 10} {
+		var called, running, maxRunning atomic.Int32
+		root := &DAG{}
+
+		observers := make([]Observer, 5)
+		for i := range observers {
+			observers[i] = ObserverFunc(func(d *DAG) {
+				assert.Same(t, root, d)
+
+				n := running.Add(1)
+				for {
+					max := maxRunning.Load()
+					if n <= max || maxRunning.CompareAndSwap(max, n) {
+						break
+					}
+				}
+				time.Sleep(10 * time.Millisecond)
+				running.Add(-1)
+				called.Add(1)
+			})
+		}
+
+		ComposeObserversParallel(workers, observers...).OnChange(root)
+
+		// All the observers have returned.
+		assert.Equal(t, int32(len(observers)), called.Load())
+		assert.Equal(t, int32(0), running.Load())
+		if workers > 0 {
+			assert.LessOrEqual(t, maxRunning.Load(), int32(workers))
+		}
+	}
+}
+
 func TestServiceClusterValid(t *testing.T) {
 	invalid := []ServiceCluster{
 		{},
@@ -243,3 +281,45 @@ func TestServiceClusterRebalance(t *testing.T) {
 	}
 
 }
+
+func TestParseDependency(t *testing.T) {
+	tests := map[string]struct {
+		in      string
+		want    Dependency
+		wantErr bool
+	}{
+		"secret": {
+			in:   "secret/default/tls",
+			want: Dependency{Kind: DependencyKindSecret, NamespacedName: types.NamespacedName{Namespace: "default", Name: "tls"}},
+		},
+		"service in any case": {
+			in:   "Service/default/kuard",
+			want: Dependency{Kind: DependencyKindService, NamespacedName: types.NamespacedName{Namespace: "default", Name: "kuard"}},
+		},
+		"unknown kind": {
+			in:      "configmap/default/kuard",
+			wantErr: true,
+		},
+		"missing namespace": {
+			in:      "secret/tls",
+			wantErr: true,
+		},
+		"empty name": {
+			in:      "secret/default/",
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseDependency(tc.in)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, strings.ToLower(tc.in), got.String())
+		})
+	}
+}
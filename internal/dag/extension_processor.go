@@ -39,6 +39,14 @@ type ExtensionServiceProcessor struct {
 
 	// ConnectTimeout defines how long the proxy should wait when establishing connection to upstream service.
 	ConnectTimeout time.Duration
+
+	// HTTP2Keepalive defines the PING frames sent on
+	// HTTP/2 connections to upstreams.
+	HTTP2Keepalive *HTTP2KeepaliveConfig
+
+	// HTTP2Settings defines the settings of
+	// HTTP/2 connections to upstreams.
+	HTTP2Settings *HTTP2Settings
 }
 
 var _ Processor = &ExtensionServiceProcessor{}
@@ -114,11 +122,13 @@ func (p *ExtensionServiceProcessor) buildExtensionService(
 		ClusterTimeoutPolicy: ctp,
 		SNI:                  "",
 		ClientCertificate:    clientCertSecret,
+		HTTP2Keepalive:       p.HTTP2Keepalive,
+		HTTP2Settings:        p.HTTP2Settings,
 	}
 
 	lbPolicy := loadBalancerPolicy(ext.Spec.LoadBalancerPolicy)
 	switch lbPolicy {
-	case LoadBalancerPolicyCookie, LoadBalancerPolicyRequestHash:
+	case LoadBalancerPolicyCookie, LoadBalancerPolicyRequestHash, LoadBalancerPolicyMaglev:
 		validCondition.AddWarningf(contour_api_v1.ConditionTypeSpecError, "IgnoredField",
 			"ignoring field %q; %s load balancer policy is not supported for ExtensionClusters",
 			".Spec.LoadBalancerPolicy", lbPolicy)
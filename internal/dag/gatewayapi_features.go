@@ -0,0 +1,58 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dag
+
+import (
+	"sort"
+)
+
+// gatewayAPIFeatures are the Gateway API features implemented by the
+// GatewayAPIProcessor, named as in the upstream conformance suite.
+// This must be updated when support for a feature is added, since
+// the Gateway API conformance tests are run with exactly this set.
+var gatewayAPIFeatures = []string{
+	// Core features.
+	KindGateway,
+	KindHTTPRoute,
+	"ReferenceGrant",
+
+	// Status is updated with the GatewayClass's generation.
+	"GatewayClassObservedGenerationBump",
+
+	// Routes may select a Listener by port via the parentRef port.
+	"RouteDestinationPortMatching",
+
+	// HTTPRoute matches.
+	"HTTPRouteMethodMatching",
+	"HTTPRouteQueryParamMatching",
+
+	// HTTPRoute filters.
+	"HTTPResponseHeaderModification",
+	"HTTPRouteHostRewrite",
+	"HTTPRoutePathRedirect",
+	"HTTPRoutePathRewrite",
+	"HTTPRoutePortRedirect",
+	"HTTPRouteRequestMirror",
+	"HTTPRouteSchemeRedirect",
+
+	KindTLSRoute,
+}
+
+// SupportedGatewayAPIFeatures returns the sorted names of the Gateway
+// API conformance features that Contour supports.
+func SupportedGatewayAPIFeatures() []string {
+	features := append([]string(nil), gatewayAPIFeatures...)
+	sort.Strings(features)
+	return features
+}
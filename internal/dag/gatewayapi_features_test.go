@@ -0,0 +1,33 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dag
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestSupportedGatewayAPIFeatures(t *testing.T) {
+	features := SupportedGatewayAPIFeatures()
+
+	assert.True(t, sort.StringsAreSorted(features))
+	assert.Equal(t, len(features), sets.New(features...).Len(), "features must be unique")
+
+	// The returned slice is a copy.
+	features[0] = "modified"
+	assert.NotEqual(t, "modified", SupportedGatewayAPIFeatures()[0])
+}
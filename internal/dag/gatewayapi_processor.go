@@ -64,6 +64,14 @@ type GatewayAPIProcessor struct {
 
 	// PerConnectionBufferLimitBytes defines the soft limit on size of the cluster’s new connection read and write buffers.
 	PerConnectionBufferLimitBytes *uint32
+
+	// HTTP2Keepalive defines the PING frames sent on
+	// HTTP/2 connections to upstreams.
+	HTTP2Keepalive *HTTP2KeepaliveConfig
+
+	// HTTP2Settings defines the settings of
+	// HTTP/2 connections to upstreams.
+	HTTP2Settings *HTTP2Settings
 }
 
 // matchConditions holds match rules.
@@ -704,9 +712,9 @@ func (p *GatewayAPIProcessor) resolveListenerSecret(certificateRefs []gatewayapi
 		meta = types.NamespacedName{Name: string(certificateRef.Name), Namespace: p.source.gateway.Namespace}
 	}
 
-	// Use LookupTLSSecretInsecure instead of LookupTLSSecret since Gateway API uses its own mechanism (ReferenceGrant, not TLSCertificateDelegation)
+	// Use LookupServingSecretInsecure instead of LookupTLSSecret since Gateway API uses its own mechanism (ReferenceGrant, not TLSCertificateDelegation)
 	// to control access to secrets across namespaces.
-	listenerSecret, err := p.source.LookupTLSSecretInsecure(meta)
+	listenerSecret, err := p.source.LookupServingSecretInsecure(meta)
 	if err != nil {
 		gwAccessor.AddListenerCondition(
 			listenerName,
@@ -976,6 +984,8 @@ func (p *GatewayAPIProcessor) computeTLSRouteForListener(route *gatewayapi_v1alp
 				TimeoutPolicy:                 ClusterTimeoutPolicy{ConnectTimeout: p.ConnectTimeout},
 				MaxRequestsPerConnection:      p.MaxRequestsPerConnection,
 				PerConnectionBufferLimitBytes: p.PerConnectionBufferLimitBytes,
+				HTTP2Keepalive:                p.HTTP2Keepalive,
+				HTTP2Settings:                 p.HTTP2Settings,
 			})
 		}
 
@@ -1594,6 +1604,8 @@ func (p *GatewayAPIProcessor) computeTCPRouteForListener(route *gatewayapi_v1alp
 			TimeoutPolicy:                 ClusterTimeoutPolicy{ConnectTimeout: p.ConnectTimeout},
 			MaxRequestsPerConnection:      p.MaxRequestsPerConnection,
 			PerConnectionBufferLimitBytes: p.PerConnectionBufferLimitBytes,
+			HTTP2Keepalive:                p.HTTP2Keepalive,
+			HTTP2Settings:                 p.HTTP2Settings,
 		})
 	}
 
@@ -1900,6 +1912,8 @@ func (p *GatewayAPIProcessor) httpClusters(routeNamespace string, backendRefs []
 			TimeoutPolicy:                 ClusterTimeoutPolicy{ConnectTimeout: p.ConnectTimeout},
 			MaxRequestsPerConnection:      p.MaxRequestsPerConnection,
 			PerConnectionBufferLimitBytes: p.PerConnectionBufferLimitBytes,
+			HTTP2Keepalive:                p.HTTP2Keepalive,
+			HTTP2Settings:                 p.HTTP2Settings,
 		})
 	}
 	return clusters, totalWeight, true
@@ -1986,6 +2000,8 @@ func (p *GatewayAPIProcessor) grpcClusters(routeNamespace string, backendRefs []
 			TimeoutPolicy:                 ClusterTimeoutPolicy{ConnectTimeout: p.ConnectTimeout},
 			MaxRequestsPerConnection:      p.MaxRequestsPerConnection,
 			PerConnectionBufferLimitBytes: p.PerConnectionBufferLimitBytes,
+			HTTP2Keepalive:                p.HTTP2Keepalive,
+			HTTP2Settings:                 p.HTTP2Settings,
 		})
 	}
 	return clusters, totalWeight, true
@@ -25,6 +25,8 @@ import (
 	"strings"
 	"time"
 
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	certmanagermetav1 "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
 	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
 	contour_api_v1alpha1 "github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
 	"github.com/projectcontour/contour/internal/annotation"
@@ -33,6 +35,7 @@ import (
 	"github.com/projectcontour/contour/internal/timeout"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 // defaultMaxRequestBytes specifies default value maxRequestBytes for AuthorizationServer
@@ -54,11 +57,20 @@ type HTTPProxyProcessor struct {
 	dag      *DAG
 	source   *KubernetesCache
 	orphaned map[types.NamespacedName]bool
+	now      time.Time
+
+	// Clock returns the current time, which route schedules
+	// are evaluated at. Defaults to time.Now.
+	Clock func() time.Time
 
 	// DisablePermitInsecure disables the use of the
 	// permitInsecure field in HTTPProxy.
 	DisablePermitInsecure bool
 
+	// InsecureVirtualHosts sets whether root HTTPProxies can
+	// define virtual hosts that are only served over plain HTTP.
+	InsecureVirtualHosts contour_api_v1alpha1.InsecureVirtualHostPolicy
+
 	// FallbackCertificate is the optional identifier of the
 	// TLS secret to use by default when SNI is not set on a
 	// request.
@@ -104,8 +116,21 @@ type HTTPProxyProcessor struct {
 	// PerConnectionBufferLimitBytes defines the soft limit on size of the listener’s new connection read and write buffers.
 	PerConnectionBufferLimitBytes *uint32
 
+	// HTTP2Keepalive defines the PING frames sent on
+	// HTTP/2 connections to upstreams.
+	HTTP2Keepalive *HTTP2KeepaliveConfig
+
+	// HTTP2Settings defines the settings of
+	// HTTP/2 connections to upstreams.
+	HTTP2Settings *HTTP2Settings
+
 	// GlobalRateLimitService defines Envoy's Global RateLimit Service configuration.
 	GlobalRateLimitService *contour_api_v1alpha1.RateLimitServiceConfig
+
+	// HTTPSRedirectPolicy customizes the redirect of HTTP
+	// requests to HTTPS for virtual hosts that don't set
+	// their own policy (optional).
+	HTTPSRedirectPolicy *HTTPSRedirectPolicy
 }
 
 // Run translates HTTPProxies into DAG objects and
@@ -114,6 +139,10 @@ func (p *HTTPProxyProcessor) Run(dag *DAG, source *KubernetesCache) {
 	p.dag = dag
 	p.source = source
 	p.orphaned = make(map[types.NamespacedName]bool, len(p.orphaned))
+	p.now = time.Now()
+	if p.Clock != nil {
+		p.now = p.Clock()
+	}
 
 	// reset the processor when we're done
 	defer func() {
@@ -133,11 +162,76 @@ func (p *HTTPProxyProcessor) Run(dag *DAG, source *KubernetesCache) {
 			pa.ConditionFor(status.ValidCondition).AddError(contour_api_v1.ConditionTypeOrphanedError,
 				"Orphaned",
 				"this HTTPProxy is not part of a delegation chain from a root HTTPProxy")
+			reason, message := p.orphanedDetails(proxy)
+			pa.ConditionFor(status.OrphanedCondition).AddError(contour_api_v1.ConditionTypeOrphanedError,
+				reason,
+				message)
 			commit()
 		}
 	}
 }
 
+// maxOrphanedSuggestions is the maximum number of root HTTPProxies
+// suggested as possible parents of an orphaned HTTPProxy.
+const maxOrphanedSuggestions = 5
+
+// orphanedDetails returns the reason and message explaining why the
+// given HTTPProxy is orphaned. If other HTTPProxies reference it, they
+// are listed since their status explains why the include failed.
+// Otherwise the root HTTPProxies that live in, or already include
+// HTTPProxies from, the orphan's namespace are suggested as parents.
+func (p *HTTPProxyProcessor) orphanedDetails(orphan *contour_api_v1.HTTPProxy) (string, string) {
+	var referencing, candidates []string
+
+	for _, proxy := range p.source.httpproxies {
+		includesNamespace := false
+		referencesOrphan := false
+
+		for _, include := range proxy.Spec.Includes {
+			namespace := include.Namespace
+			if namespace == "" {
+				namespace = proxy.Namespace
+			}
+
+			if namespace == orphan.Namespace {
+				includesNamespace = true
+				if include.Name == orphan.Name {
+					referencesOrphan = true
+				}
+			}
+		}
+
+		switch {
+		case referencesOrphan:
+			referencing = append(referencing, proxy.Namespace+"/"+proxy.Name)
+		case proxy.Spec.VirtualHost == nil || isBlank(proxy.Spec.VirtualHost.Fqdn) || !p.rootAllowed(proxy.Namespace):
+			continue
+		case proxy.Namespace == orphan.Namespace || includesNamespace:
+			candidates = append(candidates, fmt.Sprintf("%s (%s/%s)", strings.ToLower(proxy.Spec.VirtualHost.Fqdn), proxy.Namespace, proxy.Name))
+		}
+	}
+
+	// sort for test stability
+	sort.Strings(referencing)
+	sort.Strings(candidates)
+
+	if len(referencing) > 0 {
+		return "IncludeFailed", fmt.Sprintf("this HTTPProxy is referenced by %s but is not included by it, check the referencing HTTPProxy's status for details",
+			strings.Join(referencing, ", "))
+	}
+
+	if len(candidates) == 0 {
+		return "NotIncluded", "this HTTPProxy is not included by any root HTTPProxy"
+	}
+
+	suggestions := strings.Join(candidates, ", ")
+	if len(candidates) > maxOrphanedSuggestions {
+		suggestions = fmt.Sprintf("%s and %d more", strings.Join(candidates[:maxOrphanedSuggestions], ", "), len(candidates)-maxOrphanedSuggestions)
+	}
+
+	return "NotIncluded", fmt.Sprintf("this HTTPProxy is not included by any root HTTPProxy, it could be included by %s", suggestions)
+}
+
 func (p *HTTPProxyProcessor) computeHTTPProxy(proxy *contour_api_v1.HTTPProxy) {
 	pa, commit := p.dag.StatusCache.ProxyAccessor(proxy)
 	validCond := pa.ConditionFor(status.ValidCondition)
@@ -169,26 +263,80 @@ func (p *HTTPProxyProcessor) computeHTTPProxy(proxy *contour_api_v1.HTTPProxy) {
 		return
 	}
 
+	if err := virtualHostAliasesValid(proxy.Spec.VirtualHost); err != nil {
+		validCond.AddErrorf(contour_api_v1.ConditionTypeVirtualHostError, "AliasNotValid",
+			"Spec.VirtualHost.Aliases is invalid: %s", err)
+		return
+	}
+
+	accessLogPolicy, err := toAccessLogPolicy(proxy.Spec.VirtualHost.AccessLogPolicy)
+	if err != nil {
+		validCond.AddErrorf(contour_api_v1.ConditionTypeVirtualHostError, "AccessLogPolicyNotValid",
+			"Spec.VirtualHost.AccessLogPolicy is invalid: %s", err)
+		return
+	}
+
 	if len(proxy.Spec.Routes) == 0 && len(proxy.Spec.Includes) == 0 && proxy.Spec.TCPProxy == nil {
 		validCond.AddError(contour_api_v1.ConditionTypeSpecError, "NothingDefined",
 			"HTTPProxy.Spec must have at least one Route, Include, or a TCPProxy")
 		return
 	}
 
+	if len(proxy.Spec.Parameters) > 0 {
+		validCond.AddError(contour_api_v1.ConditionTypeSpecError, "ParametersNotPermitted",
+			"Spec.Parameters can only be defined for HTTPProxies that are included")
+		return
+	}
+
 	if len(proxy.Spec.VirtualHost.JWTProviders) > 0 {
-		if proxy.Spec.VirtualHost.TLS == nil || len(proxy.Spec.VirtualHost.TLS.SecretName) == 0 {
+		if tls := proxy.Spec.VirtualHost.TLS; tls == nil || (len(tls.SecretName) == 0 && len(tls.CertificateName) == 0) {
 			validCond.AddError(contour_api_v1.ConditionTypeJWTVerificationError, "JWTVerificationNotPermitted",
 				"Spec.VirtualHost.JWTProviders can only be defined for root HTTPProxies that terminate TLS")
 			return
 		}
 	}
 
+	if proxy.Spec.VirtualHost.HTTP2MaxConcurrentStreams != nil {
+		if tls := proxy.Spec.VirtualHost.TLS; tls == nil || (len(tls.SecretName) == 0 && len(tls.CertificateName) == 0) {
+			validCond.AddError(contour_api_v1.ConditionTypeVirtualHostError, "HTTP2MaxConcurrentStreamsNotPermitted",
+				"Spec.VirtualHost.HTTP2MaxConcurrentStreams can only be defined for root HTTPProxies that terminate TLS")
+			return
+		}
+	}
+
 	if proxy.Spec.VirtualHost.TLS == nil && proxy.Spec.VirtualHost.Authorization != nil && len(proxy.Spec.VirtualHost.Authorization.ExtensionServiceRef.Name) > 0 {
 		validCond.AddError(contour_api_v1.ConditionTypeAuthError, "AuthNotPermitted",
 			"Spec.VirtualHost.Authorization.ExtensionServiceRef can only be defined for root HTTPProxies that terminate TLS")
 		return
 	}
 
+	if proxy.Spec.VirtualHost.TLS == nil {
+		switch {
+		case p.InsecureVirtualHosts == contour_api_v1alpha1.DisallowedInsecureVirtualHosts:
+			validCond.AddError(contour_api_v1.ConditionTypeVirtualHostError, "InsecureVirtualHostNotPermitted",
+				"Spec.VirtualHost.TLS must be specified, insecure virtual hosts are not permitted")
+			return
+		case p.InsecureVirtualHosts == contour_api_v1alpha1.ExplicitInsecureVirtualHosts && !proxy.Spec.VirtualHost.Insecure:
+			validCond.AddError(contour_api_v1.ConditionTypeVirtualHostError, "InsecureVirtualHostNotDeclared",
+				"Spec.VirtualHost.Insecure must be set for virtual hosts that don't specify TLS")
+			return
+		}
+	} else if proxy.Spec.VirtualHost.Insecure {
+		validCond.AddError(contour_api_v1.ConditionTypeTLSError, "TLSConfigNotValid",
+			"Spec.VirtualHost: both Insecure and TLS were specified")
+		return
+	}
+
+	if policy := proxy.Spec.VirtualHost.HTTPSRedirectPolicy; policy != nil {
+		for _, prefix := range policy.ExcludedPathPrefixes {
+			if !strings.HasPrefix(prefix, "/") {
+				validCond.AddErrorf(contour_api_v1.ConditionTypeSpecError, "HTTPSRedirectPolicyNotValid",
+					"Spec.VirtualHost.HTTPSRedirectPolicy: excluded path prefix %q must start with a slash", prefix)
+				return
+			}
+		}
+	}
+
 	if len(proxy.Spec.VirtualHost.IPAllowFilterPolicy) > 0 && len(proxy.Spec.VirtualHost.IPDenyFilterPolicy) > 0 {
 		validCond.AddError(contour_api_v1.ConditionTypeIPFilterError, "IncompatibleIPAddressFilters",
 			"Spec.VirtualHost.IPAllowFilterPolicy and Spec.VirtualHost.IPDepnyFilterPolicy cannot both be defined.")
@@ -196,6 +344,7 @@ func (p *HTTPProxyProcessor) computeHTTPProxy(proxy *contour_api_v1.HTTPProxy) {
 	}
 
 	var tlsEnabled bool
+	var aliasSecrets map[string]*Secret
 	if tls := proxy.Spec.VirtualHost.TLS; tls != nil {
 		if tls.Passthrough && tls.EnableFallbackCertificate {
 			validCond.AddError(contour_api_v1.ConditionTypeTLSError, "TLSIncompatibleFeatures",
@@ -207,9 +356,27 @@ func (p *HTTPProxyProcessor) computeHTTPProxy(proxy *contour_api_v1.HTTPProxy) {
 			return
 		}
 
-		if isBlank(tls.SecretName) && !tls.Passthrough {
+		if !isBlank(tls.CertificateName) && tls.Passthrough {
+			validCond.AddError(contour_api_v1.ConditionTypeTLSError, "TLSConfigNotValid",
+				"Spec.VirtualHost.TLS: both Passthrough and CertificateName were specified")
+			return
+		}
+
+		if len(tls.AliasSecrets) > 0 && tls.Passthrough {
+			validCond.AddError(contour_api_v1.ConditionTypeTLSError, "TLSConfigNotValid",
+				"Spec.VirtualHost.TLS: both Passthrough and AliasSecrets were specified")
+			return
+		}
+
+		if !isBlank(tls.SecretName) && !isBlank(tls.CertificateName) {
+			validCond.AddError(contour_api_v1.ConditionTypeTLSError, "TLSConfigNotValid",
+				"Spec.VirtualHost.TLS: both SecretName and CertificateName were specified")
+			return
+		}
+
+		if isBlank(tls.SecretName) && isBlank(tls.CertificateName) && !tls.Passthrough {
 			validCond.AddError(contour_api_v1.ConditionTypeTLSError, "TLSConfigNotValid",
-				"Spec.VirtualHost.TLS: neither Passthrough nor SecretName were specified")
+				"Spec.VirtualHost.TLS: none of Passthrough, SecretName or CertificateName were specified")
 			return
 		}
 
@@ -224,12 +391,36 @@ func (p *HTTPProxyProcessor) computeHTTPProxy(proxy *contour_api_v1.HTTPProxy) {
 		// Attach secrets to TLS enabled vhosts.
 		if !tls.Passthrough {
 			secretName := k8s.NamespacedNameFrom(tls.SecretName, k8s.DefaultNamespace(proxy.Namespace))
+			var certificate *certmanagerv1.Certificate
+			if !isBlank(tls.CertificateName) {
+				var ok bool
+				certificate, ok = p.source.LookupCertificate(types.NamespacedName{Namespace: proxy.Namespace, Name: tls.CertificateName})
+				if !ok {
+					validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "CertificateNotFound",
+						"Spec.VirtualHost.TLS Certificate %q not found", tls.CertificateName)
+					return
+				}
+				secretName = types.NamespacedName{Namespace: certificate.Namespace, Name: certificate.Spec.SecretName}
+			}
+
+			p.dag.useDependency(DependencyKindSecret, secretName, k8s.NamespacedNameOf(proxy))
 			sec, err := p.source.LookupTLSSecret(secretName, proxy.Namespace)
 			if err != nil {
-				if _, ok := err.(DelegationNotPermittedError); ok {
+				switch _, ok := err.(DelegationNotPermittedError); {
+				case ok:
 					validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "DelegationNotPermitted",
 						"Spec.VirtualHost.TLS Secret %q certificate delegation not permitted", tls.SecretName)
-				} else {
+				case certificate != nil:
+					// The Secret doesn't exist or isn't valid yet, most
+					// likely because the Certificate hasn't been issued.
+					if notReady, ok := certificateNotReady(certificate); ok {
+						validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "CertificateNotReady",
+							"Spec.VirtualHost.TLS Certificate %q is not ready: %s", tls.CertificateName, notReady)
+					} else {
+						validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "SecretNotValid",
+							"Spec.VirtualHost.TLS Certificate %q Secret %q is invalid: %s", tls.CertificateName, certificate.Spec.SecretName, err)
+					}
+				default:
 					validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "SecretNotValid",
 						"Spec.VirtualHost.TLS Secret %q is invalid: %s", tls.SecretName, err)
 				}
@@ -244,8 +435,15 @@ func (p *HTTPProxyProcessor) computeHTTPProxy(proxy *contour_api_v1.HTTPProxy) {
 
 			svhost := p.dag.EnsureSecureVirtualHost(listener.Name, host)
 			svhost.Secret = sec
+			p.dag.useDelegatedSecret(secretName, k8s.NamespacedNameOf(proxy))
 			// default to a minimum TLS version of 1.2 if it's not specified
 			svhost.MinTLSVersion = annotation.MinTLSVersion(tls.MinimumProtocolVersion, "1.2")
+			svhost.HTTP2MaxConcurrentStreams = proxy.Spec.VirtualHost.HTTP2MaxConcurrentStreams
+
+			var ok bool
+			if aliasSecrets, ok = p.computeAliasSecrets(validCond, proxy); !ok {
+				return
+			}
 
 			// Check if FallbackCertificate && ClientValidation are both enabled in the same vhost
 			if tls.EnableFallbackCertificate && tls.ClientValidation != nil {
@@ -273,6 +471,7 @@ func (p *HTTPProxyProcessor) computeHTTPProxy(proxy *contour_api_v1.HTTPProxy) {
 					return
 				}
 
+				p.dag.useDependency(DependencyKindSecret, *p.FallbackCertificate, k8s.NamespacedNameOf(proxy))
 				sec, err = p.source.LookupTLSSecret(*p.FallbackCertificate, proxy.Namespace)
 				if err != nil {
 					if _, ok := err.(DelegationNotPermittedError); ok {
@@ -305,6 +504,7 @@ func (p *HTTPProxyProcessor) computeHTTPProxy(proxy *contour_api_v1.HTTPProxy) {
 				}
 				if tls.ClientValidation.CACertificate != "" {
 					secretName := k8s.NamespacedNameFrom(tls.ClientValidation.CACertificate, k8s.DefaultNamespace(proxy.Namespace))
+					p.dag.useDependency(DependencyKindSecret, secretName, k8s.NamespacedNameOf(proxy))
 					cacert, err := p.source.LookupCASecret(secretName, proxy.Namespace)
 					if err != nil {
 						if _, ok := err.(DelegationNotPermittedError); ok {
@@ -324,6 +524,7 @@ func (p *HTTPProxyProcessor) computeHTTPProxy(proxy *contour_api_v1.HTTPProxy) {
 				}
 				if tls.ClientValidation.CertificateRevocationList != "" {
 					secretName := k8s.NamespacedNameFrom(tls.ClientValidation.CertificateRevocationList, k8s.DefaultNamespace(proxy.Namespace))
+					p.dag.useDependency(DependencyKindSecret, secretName, k8s.NamespacedNameOf(proxy))
 					crl, err := p.source.LookupCRLSecret(secretName, proxy.Namespace)
 					if err != nil {
 						if _, ok := err.(DelegationNotPermittedError); ok {
@@ -387,6 +588,7 @@ func (p *HTTPProxyProcessor) computeHTTPProxy(proxy *contour_api_v1.HTTPProxy) {
 					}
 
 					caCertNamespacedName := k8s.NamespacedNameFrom(jwtProvider.RemoteJWKS.UpstreamValidation.CACertificate, k8s.DefaultNamespace(proxy.Namespace))
+					p.dag.useDependency(DependencyKindSecret, caCertNamespacedName, k8s.NamespacedNameOf(proxy))
 					uv, err = p.source.LookupUpstreamValidation(jwtProvider.RemoteJWKS.UpstreamValidation, caCertNamespacedName, proxy.Namespace)
 					if err != nil {
 						if _, ok := err.(DelegationNotPermittedError); ok {
@@ -494,6 +696,16 @@ func (p *HTTPProxyProcessor) computeHTTPProxy(proxy *contour_api_v1.HTTPProxy) {
 
 	routes := p.computeRoutes(validCond, proxy, proxy, nil, nil, tlsEnabled, defaultJWTProvider)
 
+	if err := routePrioritiesValid(routes); err != nil {
+		validCond.AddErrorf(contour_api_v1.ConditionTypeRouteError, "AmbiguousRoutePriority",
+			"%s", err)
+		return
+	}
+
+	if maintenance := proxy.Spec.VirtualHost.MaintenanceMode; maintenance != nil && maintenance.Enabled {
+		routes = maintenanceRoutes(routes, maintenance)
+	}
+
 	listener, err := p.dag.GetSingleListener("http")
 	if err != nil {
 		validCond.AddError(contour_api_v1.ConditionTypeListenerError, "ErrorIdentifyingListener", err.Error())
@@ -509,6 +721,7 @@ func (p *HTTPProxyProcessor) computeHTTPProxy(proxy *contour_api_v1.HTTPProxy) {
 		return
 	}
 	insecure.CORSPolicy = cp
+	insecure.AccessLogPolicy = accessLogPolicy
 
 	var isValidRLP bool
 	insecure.RateLimitPolicy, isValidRLP = computeVirtualHostRateLimitPolicy(proxy, p.GlobalRateLimitService, validCond)
@@ -528,6 +741,9 @@ func (p *HTTPProxyProcessor) computeHTTPProxy(proxy *contour_api_v1.HTTPProxy) {
 	}
 
 	addRoutes(insecure, routes)
+	for _, route := range routes {
+		insecure.addHTTPSRedirectExclusions(route)
+	}
 
 	// if TLS is enabled for this virtual host and there is no tcp proxy defined,
 	// then add routes to the secure virtualhost definition.
@@ -540,6 +756,7 @@ func (p *HTTPProxyProcessor) computeHTTPProxy(proxy *contour_api_v1.HTTPProxy) {
 
 		secure := p.dag.EnsureSecureVirtualHost(listener.Name, host)
 		secure.CORSPolicy = cp
+		secure.AccessLogPolicy = accessLogPolicy
 
 		secure.RateLimitPolicy, isValidRLP = computeVirtualHostRateLimitPolicy(proxy, p.GlobalRateLimitService, validCond)
 		if !isValidRLP {
@@ -588,6 +805,187 @@ func (p *HTTPProxyProcessor) computeHTTPProxy(proxy *contour_api_v1.HTTPProxy) {
 			}
 		}
 	}
+
+	p.computeVirtualHostAliases(proxy, aliasSecrets)
+}
+
+// computeAliasSecrets returns the TLS secrets of the aliases of proxy
+// that have their own certificate, by lower case alias. It returns
+// false if an alias secret is invalid.
+func (p *HTTPProxyProcessor) computeAliasSecrets(validCond *contour_api_v1.DetailedCondition, proxy *contour_api_v1.HTTPProxy) (map[string]*Secret, bool) {
+	aliases := sets.NewString()
+	for _, alias := range proxy.Spec.VirtualHost.Aliases {
+		aliases.Insert(strings.ToLower(string(alias)))
+	}
+
+	var secrets map[string]*Secret
+	for _, aliasSecret := range proxy.Spec.VirtualHost.TLS.AliasSecrets {
+		alias := strings.ToLower(string(aliasSecret.Alias))
+		if !aliases.Has(alias) {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "AliasSecretNotValid",
+				"Spec.VirtualHost.TLS.AliasSecrets: %q is not an alias of the virtual host", aliasSecret.Alias)
+			return nil, false
+		}
+		if _, ok := secrets[alias]; ok {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "AliasSecretNotValid",
+				"Spec.VirtualHost.TLS.AliasSecrets: alias %q has more than one Secret", aliasSecret.Alias)
+			return nil, false
+		}
+
+		secretName := k8s.NamespacedNameFrom(aliasSecret.SecretName, k8s.DefaultNamespace(proxy.Namespace))
+		p.dag.useDependency(DependencyKindSecret, secretName, k8s.NamespacedNameOf(proxy))
+		sec, err := p.source.LookupTLSSecret(secretName, proxy.Namespace)
+		if err != nil {
+			if _, ok := err.(DelegationNotPermittedError); ok {
+				validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "DelegationNotPermitted",
+					"Spec.VirtualHost.TLS.AliasSecrets Secret %q certificate delegation not permitted", aliasSecret.SecretName)
+			} else {
+				validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "SecretNotValid",
+					"Spec.VirtualHost.TLS.AliasSecrets Secret %q is invalid: %s", aliasSecret.SecretName, err)
+			}
+			return nil, false
+		}
+
+		if secrets == nil {
+			secrets = make(map[string]*Secret)
+		}
+		secrets[alias] = sec
+		p.dag.useDelegatedSecret(secretName, k8s.NamespacedNameOf(proxy))
+	}
+
+	return secrets, true
+}
+
+// computeVirtualHostAliases adds the virtual hosts of the aliases of
+// proxy, which are copies of the virtual hosts of its fqdn, so that
+// they share its routes and TLS configuration. Aliases with a secret
+// in aliasSecrets serve it instead of the secret of the fqdn.
+func (p *HTTPProxyProcessor) computeVirtualHostAliases(proxy *contour_api_v1.HTTPProxy, aliasSecrets map[string]*Secret) {
+	fqdn := proxy.Spec.VirtualHost.Fqdn
+
+	for _, alias := range proxy.Spec.VirtualHost.Aliases {
+		host := string(alias)
+
+		if listener, err := p.dag.GetSingleListener("http"); err == nil {
+			if vhost := p.dag.GetVirtualHost(listener.Name, fqdn); vhost != nil {
+				copyVirtualHost(p.dag.EnsureVirtualHost(listener.Name, host), vhost, fqdn)
+			}
+		}
+
+		if listener, err := p.dag.GetSingleListener("https"); err == nil {
+			if svhost := p.dag.GetSecureVirtualHost(listener.Name, fqdn); svhost != nil {
+				secure := p.dag.EnsureSecureVirtualHost(listener.Name, host)
+				vhost := secure.VirtualHost
+				*secure = *svhost
+				secure.VirtualHost = vhost
+				copyVirtualHost(&secure.VirtualHost, &svhost.VirtualHost, fqdn)
+				if sec, ok := aliasSecrets[strings.ToLower(host)]; ok {
+					secure.Secret = sec
+				}
+			}
+		}
+	}
+}
+
+// copyVirtualHost copies the virtual host src of fqdn to
+// dst, keeping the name and existing routes of dst.
+func copyVirtualHost(dst, src *VirtualHost, fqdn string) {
+	name, routes := dst.Name, dst.Routes
+	*dst = *src
+	dst.Name, dst.Routes = name, routes
+	for _, route := range src.Routes {
+		dst.AddRoute(aliasRoute(route, fqdn, name))
+	}
+}
+
+// aliasRoute returns the route of the virtual host fqdn for its alias,
+// replacing the wildcard domain header match of fqdn, if any, with the
+// one of alias.
+func aliasRoute(route *Route, fqdn, alias string) *Route {
+	if !strings.HasPrefix(fqdn, "*.") && !strings.HasPrefix(alias, "*.") {
+		return route
+	}
+
+	r := *route
+	r.HeaderMatchConditions = nil
+	for _, cond := range route.HeaderMatchConditions {
+		if strings.HasPrefix(fqdn, "*.") && cond == wildcardDomainHeaderMatch(fqdn) {
+			continue
+		}
+		r.HeaderMatchConditions = append(r.HeaderMatchConditions, cond)
+	}
+	if strings.HasPrefix(alias, "*.") {
+		r.HeaderMatchConditions = append(r.HeaderMatchConditions, wildcardDomainHeaderMatch(alias))
+	}
+	return &r
+}
+
+// toAccessLogPolicy converts the access log policy of a virtual
+// host into a DAG access log policy, or returns an error if it
+// is invalid.
+func toAccessLogPolicy(policy *contour_api_v1.AccessLogPolicy) (*AccessLogPolicy, error) {
+	if policy == nil {
+		return nil, nil
+	}
+
+	if policy.Disabled {
+		if policy.Format != "" || policy.FormatString != "" || len(policy.JSONFields) > 0 {
+			return nil, errors.New("disabled can't be combined with other settings")
+		}
+		return &AccessLogPolicy{Disabled: true}, nil
+	}
+
+	format := contour_api_v1alpha1.AccessLogType(policy.Format)
+	if format != "" {
+		if err := format.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	if err := contour_api_v1alpha1.AccessLogFormatString(policy.FormatString).Validate(); err != nil {
+		return nil, err
+	}
+	jsonFields := contour_api_v1alpha1.AccessLogJSONFields(policy.JSONFields)
+	if err := jsonFields.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &AccessLogPolicy{
+		Format:       format,
+		FormatString: policy.FormatString,
+		JSONFields:   jsonFields,
+	}, nil
+}
+
+// virtualHostAliasesValid returns an error if an alias of vhost
+// is blank, or is its fqdn or another alias.
+func virtualHostAliasesValid(vhost *contour_api_v1.VirtualHost) error {
+	names := sets.NewString(strings.ToLower(vhost.Fqdn))
+	for _, alias := range vhost.Aliases {
+		name := strings.ToLower(string(alias))
+		if isBlank(name) {
+			return errors.New("alias must not be blank")
+		}
+		if names.Has(name) {
+			return fmt.Errorf("alias %q is duplicated", alias)
+		}
+		names.Insert(name)
+	}
+	return nil
+}
+
+// virtualHostNames returns the lower case fqdn
+// and aliases of vhost, without duplicates.
+func virtualHostNames(vhost *contour_api_v1.VirtualHost) []string {
+	names := []string{strings.ToLower(vhost.Fqdn)}
+	seen := sets.NewString(names...)
+	for _, alias := range vhost.Aliases {
+		name := strings.ToLower(string(alias))
+		if !seen.Has(name) {
+			seen.Insert(name)
+			names = append(names, name)
+		}
+	}
+	return names
 }
 
 type vhost interface {
@@ -601,6 +999,99 @@ func addRoutes(vhost vhost, routes []*Route) {
 	}
 }
 
+// routePrioritiesValid returns an error if routes with the same
+// non-zero priority are ambiguous, i.e. their path match conditions
+// differ and at least one of them is a regex, so that they may both
+// match a request and their order isn't obvious.
+func routePrioritiesValid(routes []*Route) error {
+	for i, a := range routes {
+		if a.Precedence == 0 {
+			continue
+		}
+		for _, b := range routes[i+1:] {
+			if b.Precedence != a.Precedence || a.PathMatchCondition.String() == b.PathMatchCondition.String() {
+				continue
+			}
+
+			_, aRegex := a.PathMatchCondition.(*RegexMatchCondition)
+			_, bRegex := b.PathMatchCondition.(*RegexMatchCondition)
+			if aRegex || bRegex {
+				return fmt.Errorf("routes with %q and %q have the same priority %d and may both match",
+					a.PathMatchCondition, b.PathMatchCondition, a.Precedence)
+			}
+		}
+	}
+
+	return nil
+}
+
+// maintenanceRoutes returns the routes of a virtual host in maintenance.
+// Routes send the maintenance response instead of routing to their
+// services, except for the paths under the health check path prefixes,
+// which are served by copies of the routes that match them.
+func maintenanceRoutes(routes []*Route, maintenance *contour_api_v1.MaintenanceMode) []*Route {
+	statusCode := uint32(http.StatusServiceUnavailable)
+	if maintenance.StatusCode != 0 {
+		statusCode = uint32(maintenance.StatusCode)
+	}
+
+	existing := map[string]bool{}
+	for _, route := range routes {
+		existing[conditionsToString(route)] = true
+	}
+
+	var result []*Route
+	for _, route := range routes {
+		if maintenanceHealthCheckRoute(route, maintenance.HealthCheckPathPrefixes) {
+			result = append(result, route)
+			continue
+		}
+
+		// Prefix routes that match health check paths are copied
+		// for the health check path prefix, so that they keep
+		// routing those paths to their services.
+		if prefix, ok := route.PathMatchCondition.(*PrefixMatchCondition); ok {
+			for _, healthCheck := range maintenance.HealthCheckPathPrefixes {
+				if !prefix.covers(healthCheck) {
+					continue
+				}
+
+				live := *route
+				live.PathMatchCondition = &PrefixMatchCondition{Prefix: healthCheck, PrefixMatchType: PrefixMatchString}
+				if existing[conditionsToString(&live)] {
+					continue
+				}
+				existing[conditionsToString(&live)] = true
+				result = append(result, &live)
+			}
+		}
+
+		down := *route
+		down.DirectResponse = directResponse(statusCode, maintenance.Body)
+		result = append(result, &down)
+	}
+
+	return result
+}
+
+// maintenanceHealthCheckRoute returns true if all the paths that
+// route matches are under one of the health check path prefixes.
+func maintenanceHealthCheckRoute(route *Route, healthCheckPrefixes []string) bool {
+	for _, healthCheck := range healthCheckPrefixes {
+		switch cond := route.PathMatchCondition.(type) {
+		case *ExactMatchCondition:
+			if strings.HasPrefix(cond.Path, healthCheck) {
+				return true
+			}
+		case *PrefixMatchCondition:
+			if (&PrefixMatchCondition{Prefix: healthCheck}).covers(cond.Prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func addStatusBadGatewayRoute(routes []*Route, conds []contour_api_v1.MatchCondition) []*Route {
 	if len(conds) > 0 {
 		routes = append(routes, &Route{
@@ -671,6 +1162,12 @@ func (p *HTTPProxyProcessor) computeRoutes(
 			continue
 		}
 
+		if err := methodMatchConditionsValid(include.Conditions); err != nil {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeIncludeError, "MethodMatchConditionsNotValid",
+				"include: %s", err)
+			continue
+		}
+
 		// Check to see if we have any duplicate include conditions.
 		if includeMatchConditionsIdentical(include.Conditions, seenConds) {
 			validCond.AddError(contour_api_v1.ConditionTypeIncludeError, "DuplicateMatchConditions",
@@ -698,9 +1195,29 @@ func (p *HTTPProxyProcessor) computeRoutes(
 
 		inc, incCommit := p.dag.StatusCache.ProxyAccessor(includedProxy)
 		incValidCond := inc.ConditionFor(status.ValidCondition)
-		routes = append(routes, p.computeRoutes(incValidCond, rootProxy, includedProxy, append(conditions, include.Conditions...), visited, enforceTLS, defaultJWTProvider)...)
+
+		parameterizedProxy, err := parameterizedHTTPProxy(includedProxy, include.Parameters)
+		if err != nil {
+			incValidCond.AddErrorf(contour_api_v1.ConditionTypeIncludeError, "IncludeParametersNotValid",
+				"include from %s/%s: %s", proxy.Namespace, proxy.Name, err)
+			incCommit()
+			validCond.AddErrorf(contour_api_v1.ConditionTypeIncludeError, "IncludeParametersNotValid",
+				"include %s/%s: %s", namespace, include.Name, err)
+			// Set 502 response when the parameters of the include are not valid.
+			routes = addStatusBadGatewayRoute(routes, include.Conditions)
+			delete(p.orphaned, types.NamespacedName{Name: includedProxy.Name, Namespace: includedProxy.Namespace})
+			continue
+		}
+		includedRoutes := p.computeRoutes(incValidCond, rootProxy, parameterizedProxy, append(conditions, include.Conditions...), visited, enforceTLS, defaultJWTProvider)
 		incCommit()
 
+		if include.Priority != nil {
+			for _, route := range includedRoutes {
+				route.Precedence = *include.Priority
+			}
+		}
+		routes = append(routes, includedRoutes...)
+
 		// dest is not an orphaned httpproxy, as there is an httpproxy that points to it
 		delete(p.orphaned, types.NamespacedName{Name: includedProxy.Name, Namespace: includedProxy.Namespace})
 	}
@@ -709,7 +1226,20 @@ func (p *HTTPProxyProcessor) computeRoutes(
 		"CONTOUR_NAMESPACE": proxy.Namespace,
 	}
 
+	// Routes with Or conditions are compiled
+	// into a route for each of their alternatives.
+	var proxyRoutes []contour_api_v1.Route
 	for _, route := range proxy.Spec.Routes {
+		expanded, err := expandOrConditions(route)
+		if err != nil {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeRouteError, "OrConditionsNotValid",
+				"route: %s", err)
+			return nil
+		}
+		proxyRoutes = append(proxyRoutes, expanded...)
+	}
+
+	for _, route := range proxyRoutes {
 		if err := routeActionCountValid(route); err != nil {
 			validCond.AddError(contour_api_v1.ConditionTypeRouteError, "RouteActionCountNotValid", err.Error())
 			return nil
@@ -731,6 +1261,12 @@ func (p *HTTPProxyProcessor) computeRoutes(
 			return nil
 		}
 
+		if err := negatedMatchConditionsValid(routeConditions); err != nil {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeRouteError, "NotConditionsNotValid",
+				"route: %s", err)
+			return nil
+		}
+
 		// Look for invalid query parameter conditions on this route
 		if err := queryParameterMatchConditionsValid(routeConditions); err != nil {
 			validCond.AddError(contour_api_v1.ConditionTypeRouteError, "QueryParameterMatchConditionsNotValid",
@@ -738,6 +1274,12 @@ func (p *HTTPProxyProcessor) computeRoutes(
 			return nil
 		}
 
+		if err := methodMatchConditionsValid(routeConditions); err != nil {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeRouteError, "MethodMatchConditionsNotValid",
+				"route: %s", err)
+			return nil
+		}
+
 		reqHP, err := headersPolicyRoute(route.RequestHeadersPolicy, true /* allow Host */, dynamicHeaders)
 		if err != nil {
 			validCond.AddErrorf(contour_api_v1.ConditionTypeRouteError, "RequestHeadersPolicyInvalid",
@@ -759,6 +1301,20 @@ func (p *HTTPProxyProcessor) computeRoutes(
 			return nil
 		}
 
+		abPolicy, err := abTestPolicy(route.ABTestPolicy)
+		if err != nil {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeRouteError, "ABTestPolicyNotValid",
+				"route.abTestPolicy is invalid: %s", err)
+			return nil
+		}
+
+		fraction, err := runtimeFraction(route.RuntimeFraction)
+		if err != nil {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeRouteError, "RuntimeFractionNotValid",
+				"route.runtimeFraction is invalid: %s", err)
+			return nil
+		}
+
 		rtp, ctp, err := timeoutPolicy(route.TimeoutPolicy, p.ConnectTimeout)
 		if err != nil {
 			validCond.AddErrorf(contour_api_v1.ConditionTypeRouteError, "TimeoutPolicyNotValid",
@@ -773,8 +1329,36 @@ func (p *HTTPProxyProcessor) computeRoutes(
 			return nil
 		}
 
+		rp, err := retryPolicy(route.RetryPolicy)
+		if err != nil {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeRouteError, "RetryPolicyNotValid",
+				"route.retryPolicy is invalid: %s", err)
+			return nil
+		}
+
+		hp, err := hedgePolicy(route.HedgePolicy, rp)
+		if err != nil {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeRouteError, "HedgePolicyNotValid",
+				"route.hedgePolicy is invalid: %s", err)
+			return nil
+		}
+
+		schedule, err := toRouteSchedule(route.Schedule)
+		if err != nil {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeRouteError, "ScheduleNotValid",
+				"route.schedule is invalid: %s", err)
+			return nil
+		}
+
 		requestHashPolicies, lbPolicy := loadBalancerRequestHashPolicies(route.LoadBalancerPolicy, validCond)
 
+		maglevTableSize, activeRequestBias, err := loadBalancerTuning(route.LoadBalancerPolicy, lbPolicy, validCond)
+		if err != nil {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeRouteError, "LoadBalancerPolicyNotValid",
+				"route.loadBalancerPolicy is invalid: %s", err)
+			return nil
+		}
+
 		redirectPolicy, err := redirectRoutePolicy(route.RequestRedirectPolicy)
 		if err != nil {
 			validCond.AddErrorf(contour_api_v1.ConditionTypeRouteError, "RequestRedirectPolicy",
@@ -784,24 +1368,40 @@ func (p *HTTPProxyProcessor) computeRoutes(
 
 		internalRedirectPolicy := internalRedirectPolicy(route.InternalRedirectPolicy)
 
-		directPolicy := directResponsePolicy(route.DirectResponsePolicy)
+		directPolicy, err := p.directResponsePolicy(route.DirectResponsePolicy, proxy)
+		if err != nil {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeRouteError, "DirectResponsePolicyNotValid",
+				"route.directResponsePolicy is invalid: %s", err)
+			return nil
+		}
 
 		r := &Route{
 			PathMatchCondition:        mergePathMatchConditions(routeConditions),
 			HeaderMatchConditions:     mergeHeaderMatchConditions(routeConditions),
 			QueryParamMatchConditions: mergeQueryParamMatchConditions(routeConditions),
+			Precedence:                route.Priority,
+			RuntimeFraction:           fraction,
 			Websocket:                 route.EnableWebsockets,
 			HTTPSUpgrade:              routeEnforceTLS(enforceTLS, route.PermitInsecure && !p.DisablePermitInsecure),
 			TimeoutPolicy:             rtp,
-			RetryPolicy:               retryPolicy(route.RetryPolicy),
+			RetryPolicy:               rp,
+			HedgePolicy:               hp,
 			RequestHeadersPolicy:      reqHP,
 			ResponseHeadersPolicy:     respHP,
 			CookieRewritePolicies:     cookieRP,
+			ABTestPolicy:              abPolicy,
 			RateLimitPolicy:           rlp,
 			RequestHashPolicies:       requestHashPolicies,
 			Redirect:                  redirectPolicy,
 			DirectResponse:            directPolicy,
 			InternalRedirectPolicy:    internalRedirectPolicy,
+			Metadata:                  route.Metadata,
+			Capture:                   route.EnableCapture,
+			StatPrefix:                routeStatPrefix(proxy, route.StatPrefix),
+		}
+
+		if r.HTTPSUpgrade {
+			r.HTTPSRedirectPolicy = p.httpsRedirectPolicy(rootProxy)
 		}
 
 		// If the enclosing root proxy enabled authorization,
@@ -878,7 +1478,35 @@ func (p *HTTPProxyProcessor) computeRoutes(
 			return nil
 		}
 
-		for _, service := range route.Services {
+		services := route.Services
+		var aggregate *AggregateCluster
+		if len(route.AggregateServices) > 0 {
+			if err := aggregateServicesValid(route.AggregateServices); err != nil {
+				validCond.AddErrorf(contour_api_v1.ConditionTypeRouteError, "AggregateServicesNotValid",
+					"route.aggregateServices is invalid: %s", err)
+				return nil
+			}
+			services = aggregateServices(route.AggregateServices)
+
+			// The Contour-wide headers policies can't be applied to
+			// each Service of an aggregate cluster, so they are applied
+			// to the aggregate cluster, without the Service headers.
+			delete(dynamicHeaders, "CONTOUR_SERVICE_NAME")
+			delete(dynamicHeaders, "CONTOUR_SERVICE_PORT")
+			aggregate = &AggregateCluster{}
+			if aggregate.RequestHeadersPolicy, err = headersPolicyService(p.RequestHeadersPolicy, nil, true, dynamicHeaders); err != nil {
+				validCond.AddErrorf(contour_api_v1.ConditionTypeServiceError, "RequestHeadersPolicyInvalid",
+					"%s on request headers", err)
+				return nil
+			}
+			if aggregate.ResponseHeadersPolicy, err = headersPolicyService(p.ResponseHeadersPolicy, nil, false, dynamicHeaders); err != nil {
+				validCond.AddErrorf(contour_api_v1.ConditionTypeServiceError, "ResponseHeadersPolicyInvalid",
+					"%s on response headers", err)
+				return nil
+			}
+		}
+
+		for _, service := range services {
 			if service.Port < 1 || service.Port > 65535 {
 				validCond.AddErrorf(contour_api_v1.ConditionTypeServiceError, "ServicePortInvalid",
 					"service %q: port must be in the range 1-65535", service.Name)
@@ -893,6 +1521,7 @@ func (p *HTTPProxyProcessor) computeRoutes(
 			}
 
 			m := types.NamespacedName{Name: service.Name, Namespace: proxy.Namespace}
+			p.dag.useDependency(DependencyKindService, m, k8s.NamespacedNameOf(proxy))
 			s, err := p.dag.EnsureService(m, service.Port, healthPort, p.source, p.EnableExternalNameService)
 			if err != nil {
 				validCond.AddErrorf(contour_api_v1.ConditionTypeServiceError, "ServiceUnresolvedReference",
@@ -900,6 +1529,20 @@ func (p *HTTPProxyProcessor) computeRoutes(
 				continue
 			}
 
+			failover, err := p.failoverServices(k8s.NamespacedNameOf(proxy), service, s)
+			if err != nil {
+				validCond.AddErrorf(contour_api_v1.ConditionTypeServiceError, "FailoverServiceNotValid",
+					"Service [%s:%d] failover is invalid: %s", service.Name, service.Port, err)
+				continue
+			}
+
+			subset, err := subsetSelector(service, s)
+			if err != nil {
+				validCond.AddErrorf(contour_api_v1.ConditionTypeServiceError, "SubsetNotValid",
+					"Service [%s:%d] subset is invalid: %s", service.Name, service.Port, err)
+				continue
+			}
+
 			// Determine the protocol to use to speak to this Cluster.
 			protocol, err := getProtocol(service, s)
 			if err != nil {
@@ -910,6 +1553,7 @@ func (p *HTTPProxyProcessor) computeRoutes(
 			var uv *PeerValidationContext
 			if (protocol == "tls" || protocol == "h2") && service.UpstreamValidation != nil {
 				caCertNamespacedName := k8s.NamespacedNameFrom(service.UpstreamValidation.CACertificate, k8s.DefaultNamespace(proxy.Namespace))
+				p.dag.useDependency(DependencyKindSecret, caCertNamespacedName, k8s.NamespacedNameOf(proxy))
 				// we can only validate TLS connections to services that talk TLS
 				uv, err = p.source.LookupUpstreamValidation(service.UpstreamValidation, caCertNamespacedName, proxy.Namespace)
 				if err != nil {
@@ -975,8 +1619,26 @@ func (p *HTTPProxyProcessor) computeRoutes(
 				}
 			}
 
+			http2Keepalive := p.HTTP2Keepalive
+			if service.HTTP2KeepalivePolicy != nil {
+				http2Keepalive, err = http2KeepaliveConfig(service.HTTP2KeepalivePolicy)
+				if err != nil {
+					validCond.AddErrorf(contour_api_v1.ConditionTypeServiceError, "HTTP2KeepaliveInvalid",
+						"%s on HTTP/2 keepalive policy", err)
+					return nil
+				}
+			}
+
+			dnsLookupFamily, err := p.serviceDNSLookupFamily(service)
+			if err != nil {
+				validCond.AddError(contour_api_v1.ConditionTypeServiceError, "DNSLookupFamilyInvalid", err.Error())
+				return nil
+			}
+
 			c := &Cluster{
 				Upstream:                      s,
+				Failover:                      failover,
+				Subset:                        subset,
 				LoadBalancerPolicy:            lbPolicy,
 				Weight:                        uint32(service.Weight),
 				HTTPHealthCheckPolicy:         healthPolicy,
@@ -986,12 +1648,16 @@ func (p *HTTPProxyProcessor) computeRoutes(
 				CookieRewritePolicies:         cookieRP,
 				Protocol:                      protocol,
 				SNI:                           determineSNI(r.RequestHeadersPolicy, reqHP, s),
-				DNSLookupFamily:               string(p.DNSLookupFamily),
+				DNSLookupFamily:               dnsLookupFamily,
 				ClientCertificate:             clientCertSecret,
 				TimeoutPolicy:                 ctp,
 				SlowStartConfig:               slowStart,
+				MaglevTableSize:               maglevTableSize,
+				ActiveRequestBias:             activeRequestBias,
 				MaxRequestsPerConnection:      p.MaxRequestsPerConnection,
 				PerConnectionBufferLimitBytes: p.PerConnectionBufferLimitBytes,
+				HTTP2Keepalive:                http2Keepalive,
+				HTTP2Settings:                 p.HTTP2Settings,
 			}
 			if service.Mirror && r.MirrorPolicy != nil {
 				validCond.AddError(contour_api_v1.ConditionTypeServiceError, "OnlyOneMirror",
@@ -1022,6 +1688,21 @@ func (p *HTTPProxyProcessor) computeRoutes(
 			r.DirectResponse = directResponse(http.StatusServiceUnavailable, "")
 		}
 
+		// The cookie rewrite code runs on the weighted cluster that
+		// Envoy picks before the A/B test hash is set, which may not be
+		// the one that serves the request, so it must be the same on all.
+		if r.ABTestPolicy != nil && !sameCookieRewritePolicies(r.Clusters) {
+			validCond.AddError(contour_api_v1.ConditionTypeRouteError, "ABTestPolicyNotValid",
+				"route.abTestPolicy is invalid: the services of the route must have the same cookieRewritePolicies")
+			return nil
+		}
+
+		if aggregate != nil && len(r.Clusters) > 0 {
+			aggregate.Clusters = r.Clusters
+			r.AggregateCluster = aggregate
+			r.Clusters = nil
+		}
+
 		// If we have a wildcard match, add a header match regex rule to match the
 		// hostname so we can be sure to only match one DNS label. This is required
 		// as Envoy's virtualhost hostname wildcard matching can match multiple
@@ -1049,6 +1730,17 @@ func (p *HTTPProxyProcessor) computeRoutes(
 			return nil
 		}
 
+		// Leave out routes that are inactive, and rebuild
+		// the DAG when their schedule next changes.
+		if schedule != nil {
+			active, next := schedule.evaluate(p.now)
+			p.dag.scheduleChange(next)
+			if !active {
+				continue
+			}
+		}
+
+		p.dag.useHTTPProxyClusters(k8s.NamespacedNameOf(proxy), r)
 		routes = append(routes, r)
 	}
 
@@ -1126,6 +1818,9 @@ func (p *HTTPProxyProcessor) processHTTPProxyTCPProxy(validCond *contour_api_v1.
 	if tcpproxy.Include == nil {
 		tcpProxyInclude = tcpproxy.IncludesDeprecated
 	}
+	if tcpproxy.IncludesDeprecated != nil {
+		p.dag.useDeprecatedFeature(DeprecatedTCPProxyIncludes, httpproxy)
+	}
 
 	if len(tcpproxy.Services) > 0 && tcpProxyInclude != nil {
 		validCond.AddError(contour_api_v1.ConditionTypeTCPProxyError, "NoServicesAndInclude",
@@ -1135,7 +1830,7 @@ func (p *HTTPProxyProcessor) processHTTPProxyTCPProxy(validCond *contour_api_v1.
 
 	lbPolicy := loadBalancerPolicy(tcpproxy.LoadBalancerPolicy)
 	switch lbPolicy {
-	case LoadBalancerPolicyCookie, LoadBalancerPolicyRequestHash:
+	case LoadBalancerPolicyCookie, LoadBalancerPolicyRequestHash, LoadBalancerPolicyMaglev:
 		validCond.AddWarningf(contour_api_v1.ConditionTypeTCPProxyError, "IgnoredField",
 			"ignoring field %q; %s load balancer policy is not supported for TCPProxies",
 			"Spec.TCPProxy.LoadBalancerPolicy", lbPolicy)
@@ -1155,6 +1850,7 @@ func (p *HTTPProxyProcessor) processHTTPProxyTCPProxy(validCond *contour_api_v1.
 			}
 
 			m := types.NamespacedName{Name: service.Name, Namespace: httpproxy.Namespace}
+			p.dag.useDependency(DependencyKindService, m, k8s.NamespacedNameOf(httpproxy))
 			s, err := p.dag.EnsureService(m, service.Port, healthPort, p.source, p.EnableExternalNameService)
 			if err != nil {
 				validCond.AddErrorf(contour_api_v1.ConditionTypeTCPProxyError, "ServiceUnresolvedReference",
@@ -1169,6 +1865,25 @@ func (p *HTTPProxyProcessor) processHTTPProxyTCPProxy(validCond *contour_api_v1.
 				return false
 			}
 
+			dnsLookupFamily, err := p.serviceDNSLookupFamily(service)
+			if err != nil {
+				validCond.AddError(contour_api_v1.ConditionTypeServiceError, "DNSLookupFamilyInvalid", err.Error())
+				return false
+			}
+
+			if len(service.Failover) > 0 {
+				validCond.AddWarningf(contour_api_v1.ConditionTypeTCPProxyError, "IgnoredField",
+					"ignoring field %q; failover is not supported for TCPProxies", "Spec.TCPProxy.Services.Failover")
+			}
+			if len(service.Subset) > 0 {
+				validCond.AddWarningf(contour_api_v1.ConditionTypeTCPProxyError, "IgnoredField",
+					"ignoring field %q; subset is not supported for TCPProxies", "Spec.TCPProxy.Services.Subset")
+			}
+			if service.HTTP2KeepalivePolicy != nil {
+				validCond.AddWarningf(contour_api_v1.ConditionTypeTCPProxyError, "IgnoredField",
+					"ignoring field %q; HTTP/2 keepalive is not supported for TCPProxies", "Spec.TCPProxy.Services.HTTP2KeepalivePolicy")
+			}
+
 			proxy.Clusters = append(proxy.Clusters, &Cluster{
 				Upstream:             s,
 				Weight:               uint32(service.Weight),
@@ -1176,6 +1891,7 @@ func (p *HTTPProxyProcessor) processHTTPProxyTCPProxy(validCond *contour_api_v1.
 				LoadBalancerPolicy:   lbPolicy,
 				TCPHealthCheckPolicy: healthPolicy,
 				SNI:                  s.ExternalName,
+				DNSLookupFamily:      dnsLookupFamily,
 				TimeoutPolicy:        ClusterTimeoutPolicy{ConnectTimeout: p.ConnectTimeout},
 			})
 		}
@@ -1249,7 +1965,7 @@ func (p *HTTPProxyProcessor) processHTTPProxyTCPProxy(validCond *contour_api_v1.
 // invalid HTTPProxy objects are excluded from the slice and their status
 // updated accordingly.
 func (p *HTTPProxyProcessor) validHTTPProxies() []*contour_api_v1.HTTPProxy {
-	// ensure that a given fqdn is only referenced in a single HTTPProxy resource
+	// ensure that a given fqdn, or alias, is only referenced in a single HTTPProxy resource
 	var valid []*contour_api_v1.HTTPProxy
 	fqdnHTTPProxies := make(map[string][]*contour_api_v1.HTTPProxy)
 	for _, proxy := range p.source.httpproxies {
@@ -1257,35 +1973,117 @@ func (p *HTTPProxyProcessor) validHTTPProxies() []*contour_api_v1.HTTPProxy {
 			valid = append(valid, proxy)
 			continue
 		}
-		fqdn := strings.ToLower(proxy.Spec.VirtualHost.Fqdn)
-		fqdnHTTPProxies[fqdn] = append(fqdnHTTPProxies[fqdn], proxy)
+		for _, fqdn := range virtualHostNames(proxy.Spec.VirtualHost) {
+			fqdnHTTPProxies[fqdn] = append(fqdnHTTPProxies[fqdn], proxy)
+		}
 	}
 
+	duplicate := make(map[*contour_api_v1.HTTPProxy]bool)
 	for fqdn, proxies := range fqdnHTTPProxies {
-		switch len(proxies) {
-		case 1:
-			valid = append(valid, proxies[0])
-		default:
-			// multiple proxies use the same fqdn. mark them as invalid.
-			var conflicting []string
-			for _, proxy := range proxies {
-				conflicting = append(conflicting, proxy.Namespace+"/"+proxy.Name)
-			}
-			sort.Strings(conflicting) // sort for test stability
-			msg := fmt.Sprintf("fqdn %q is used in multiple HTTPProxies: %s", fqdn, strings.Join(conflicting, ", "))
-			for _, proxy := range proxies {
-				pa, commit := p.dag.StatusCache.ProxyAccessor(proxy)
-				pa.Vhost = fqdn
-				pa.ConditionFor(status.ValidCondition).AddError(contour_api_v1.ConditionTypeVirtualHostError,
-					"DuplicateVhost",
-					msg)
-				commit()
-			}
+		if len(proxies) == 1 {
+			continue
+		}
+
+		// multiple proxies use the same fqdn. mark them as invalid.
+		var conflicting []string
+		for _, proxy := range proxies {
+			conflicting = append(conflicting, proxy.Namespace+"/"+proxy.Name)
+		}
+		sort.Strings(conflicting) // sort for test stability
+		msg := fmt.Sprintf("fqdn %q is used in multiple HTTPProxies: %s", fqdn, strings.Join(conflicting, ", "))
+		for _, proxy := range proxies {
+			pa, commit := p.dag.StatusCache.ProxyAccessor(proxy)
+			pa.Vhost = strings.ToLower(proxy.Spec.VirtualHost.Fqdn)
+			pa.ConditionFor(status.ValidCondition).AddError(contour_api_v1.ConditionTypeVirtualHostError,
+				"DuplicateVhost",
+				msg)
+			commit()
+			duplicate[proxy] = true
+		}
+	}
+
+	for _, proxy := range p.source.httpproxies {
+		if proxy.Spec.VirtualHost != nil && !duplicate[proxy] {
+			valid = append(valid, proxy)
 		}
 	}
 	return valid
 }
 
+// serviceDNSLookupFamily returns the DNS lookup family of service
+// if specified, otherwise the Contour-wide setting.
+func (p *HTTPProxyProcessor) serviceDNSLookupFamily(service contour_api_v1.Service) (string, error) {
+	switch service.DNSLookupFamily {
+	case "auto", "v4", "v6", "all":
+		return service.DNSLookupFamily, nil
+	case "":
+		return string(p.DNSLookupFamily), nil
+	default:
+		return "", fmt.Errorf("Service [%s:%d] DNSLookupFamily has an invalid value %q, must be auto, all, v4 or v6", service.Name, service.Port, service.DNSLookupFamily)
+	}
+}
+
+// failoverServices returns the failover Services of service, whose
+// own Service is upstream, in priority order. service belongs to
+// the given HTTPProxy.
+func (p *HTTPProxyProcessor) failoverServices(proxy types.NamespacedName, service contour_api_v1.Service, upstream *Service) ([]*Service, error) {
+	if len(service.Failover) == 0 {
+		return nil, nil
+	}
+
+	// Failover Services are merged into the EDS endpoints of the
+	// cluster, which ExternalName Services don't use.
+	if len(upstream.ExternalName) > 0 {
+		return nil, errors.New("failover is not supported for ExternalName Services")
+	}
+
+	var failover []*Service
+	for _, f := range service.Failover {
+		m := types.NamespacedName{Name: f.Name, Namespace: proxy.Namespace}
+		p.dag.useDependency(DependencyKindService, m, proxy)
+		s, err := p.dag.EnsureService(m, f.Port, f.Port, p.source, p.EnableExternalNameService)
+		if err != nil {
+			return nil, fmt.Errorf("unresolved service reference: %w", err)
+		}
+		if len(s.ExternalName) > 0 {
+			return nil, fmt.Errorf("Service [%s:%d] is an ExternalName Service", f.Name, f.Port)
+		}
+		failover = append(failover, s)
+	}
+
+	return failover, nil
+}
+
+// subsetSelector returns the pod labels that select the subset of
+// service, whose own Service is upstream, or nil if it has no subset.
+func subsetSelector(service contour_api_v1.Service, upstream *Service) (map[string]string, error) {
+	if len(service.Subset) == 0 {
+		return nil, nil
+	}
+
+	// Subsets are selected by the metadata of the EDS
+	// endpoints, which ExternalName Services don't use.
+	if len(upstream.ExternalName) > 0 {
+		return nil, errors.New("subset is not supported for ExternalName Services")
+	}
+	if service.Mirror {
+		return nil, errors.New("subset is not supported for mirror Services")
+	}
+
+	subset := make(map[string]string, len(service.Subset))
+	for k, v := range service.Subset {
+		if errs := validation.IsQualifiedName(k); len(errs) > 0 {
+			return nil, fmt.Errorf("label key %q is invalid: %s", k, strings.Join(errs, "; "))
+		}
+		if errs := validation.IsValidLabelValue(v); len(errs) > 0 {
+			return nil, fmt.Errorf("label %q value %q is invalid: %s", k, v, strings.Join(errs, "; "))
+		}
+		subset[k] = v
+	}
+
+	return subset, nil
+}
+
 // rootAllowed returns true if the HTTPProxy lives in a permitted root namespace.
 func (p *HTTPProxyProcessor) rootAllowed(namespace string) bool {
 	if len(p.source.RootNamespaces) == 0 {
@@ -1545,6 +2343,10 @@ func getProtocol(service contour_api_v1.Service, s *Service) (string, error) {
 		protocol = *service.Protocol
 		switch protocol {
 		case "h2c", "h2", "tls":
+		case "h1":
+			// Cleartext HTTP/1.1 is the default protocol, which
+			// overrides any protocol set by Service annotations.
+			protocol = ""
 		default:
 			return "", fmt.Errorf("unsupported protocol: %v", protocol)
 		}
@@ -1790,6 +2592,56 @@ func isBlank(s string) bool {
 	return len(strings.TrimSpace(s)) == 0
 }
 
+// httpsRedirectPolicy returns the HTTPS redirect policy of the virtual
+// host of the root proxy, or the global policy if it doesn't have one.
+func (p *HTTPProxyProcessor) httpsRedirectPolicy(rootProxy *contour_api_v1.HTTPProxy) *HTTPSRedirectPolicy {
+	policy := rootProxy.Spec.VirtualHost.HTTPSRedirectPolicy
+	if policy == nil {
+		return p.HTTPSRedirectPolicy
+	}
+
+	return &HTTPSRedirectPolicy{
+		StatusCode:           policy.StatusCode,
+		StripQuery:           policy.StripQuery,
+		ExcludedPathPrefixes: policy.ExcludedPathPrefixes,
+	}
+}
+
+// certificateNotReady returns why the cert-manager Certificate is not
+// ready, and true, or false if the Certificate is ready.
+func certificateNotReady(certificate *certmanagerv1.Certificate) (string, bool) {
+	for _, cond := range certificate.Status.Conditions {
+		if cond.Type != certmanagerv1.CertificateConditionReady {
+			continue
+		}
+		if cond.Status == certmanagermetav1.ConditionTrue {
+			return "", false
+		}
+		if len(cond.Message) > 0 {
+			return cond.Message, true
+		}
+		if len(cond.Reason) > 0 {
+			return cond.Reason, true
+		}
+	}
+
+	return "issuance is pending", true
+}
+
+// routeStatPrefix returns the Envoy stat prefix of a route of the
+// HTTPProxy, which is qualified by the namespace and name of the
+// HTTPProxy, or "" if the route does not enable per-route stats.
+func routeStatPrefix(proxy *contour_api_v1.HTTPProxy, statPrefix string) string {
+	if statPrefix == "" {
+		return ""
+	}
+
+	// Dots separate the elements of Envoy stat names,
+	// so the dots of the HTTPProxy name are replaced.
+	name := strings.ReplaceAll(proxy.Name, ".", "_")
+	return proxy.Namespace + "_" + name + "_" + statPrefix
+}
+
 // routeEnforceTLS determines if the route should redirect the user to a secure TLS listener
 func routeEnforceTLS(enforceTLS, permitInsecure bool) bool {
 	return enforceTLS && !permitInsecure
@@ -1809,6 +2661,10 @@ func routeActionCountValid(route contour_api_v1.Route) error {
 		routeActionCount++
 	}
 
+	if len(route.AggregateServices) > 0 {
+		routeActionCount++
+	}
+
 	if route.RequestRedirectPolicy != nil {
 		routeActionCount++
 	}
@@ -1818,11 +2674,40 @@ func routeActionCountValid(route contour_api_v1.Route) error {
 	}
 
 	if routeActionCount != 1 {
-		return errors.New("must set exactly one of route.services or route.requestRedirectPolicy or route.directResponsePolicy")
+		return errors.New("must set exactly one of route.services or route.aggregateServices or route.requestRedirectPolicy or route.directResponsePolicy")
+	}
+	return nil
+}
+
+// aggregateServicesValid returns an error if a Service
+// port is in the aggregate services more than once.
+func aggregateServicesValid(services []contour_api_v1.AggregateService) error {
+	seen := map[string]bool{}
+	for _, service := range services {
+		key := fmt.Sprintf("%s:%d", service.Name, service.Port)
+		if seen[key] {
+			return fmt.Errorf("Service [%s] is listed more than once", key)
+		}
+		seen[key] = true
 	}
 	return nil
 }
 
+// aggregateServices returns the aggregate services as route
+// services, so that their clusters are built the same way.
+func aggregateServices(services []contour_api_v1.AggregateService) []contour_api_v1.Service {
+	var res []contour_api_v1.Service
+	for _, service := range services {
+		res = append(res, contour_api_v1.Service{
+			Name:               service.Name,
+			Port:               service.Port,
+			Protocol:           service.Protocol,
+			UpstreamValidation: service.UpstreamValidation,
+		})
+	}
+	return res
+}
+
 // redirectRoutePolicy builds a *dag.Redirect for the supplied redirect policy.
 func redirectRoutePolicy(redirect *contour_api_v1.HTTPRequestRedirectPolicy) (*Redirect, error) {
 	if redirect == nil {
@@ -1876,12 +2761,52 @@ func redirectRoutePolicy(redirect *contour_api_v1.HTTPRequestRedirectPolicy) (*R
 	}, nil
 }
 
-func directResponsePolicy(direct *contour_api_v1.HTTPDirectResponsePolicy) *DirectResponse {
+// maxDirectResponseBodySize is the maximum size of a direct
+// response body that Envoy accepts by default.
+const maxDirectResponseBodySize = 4096
+
+func (p *HTTPProxyProcessor) directResponsePolicy(direct *contour_api_v1.HTTPDirectResponsePolicy, proxy *contour_api_v1.HTTPProxy) (*DirectResponse, error) {
 	if direct == nil {
-		return nil
+		return nil, nil
+	}
+
+	if direct.BodyFrom == nil {
+		return directResponse(uint32(direct.StatusCode), direct.Body), nil
+	}
+
+	if len(direct.Body) > 0 {
+		return nil, errors.New("cannot specify both body and bodyFrom")
+	}
+
+	var (
+		kind string
+		name types.NamespacedName
+		body []byte
+		err  error
+	)
+	switch from := direct.BodyFrom; {
+	case len(from.ConfigMapName) > 0 && len(from.SecretName) > 0:
+		return nil, errors.New("bodyFrom cannot specify both configMapName and secretName")
+	case len(from.ConfigMapName) > 0:
+		kind, name = DependencyKindConfigMap, types.NamespacedName{Namespace: proxy.Namespace, Name: from.ConfigMapName}
+		p.dag.useDependency(kind, name, k8s.NamespacedNameOf(proxy))
+		body, err = p.source.LookupConfigMapKey(name, from.Key)
+	case len(from.SecretName) > 0:
+		kind, name = DependencyKindSecret, types.NamespacedName{Namespace: proxy.Namespace, Name: from.SecretName}
+		p.dag.useDependency(kind, name, k8s.NamespacedNameOf(proxy))
+		body, err = p.source.LookupSecretKey(name, from.Key)
+	default:
+		return nil, errors.New("bodyFrom must specify configMapName or secretName")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("bodyFrom %s %q is invalid: %s", kind, name, err)
+	}
+	if len(body) > maxDirectResponseBodySize {
+		return nil, fmt.Errorf("bodyFrom %s %q key %q is %d bytes, must not be larger than %d bytes",
+			kind, name, direct.BodyFrom.Key, len(body), maxDirectResponseBodySize)
 	}
 
-	return directResponse(uint32(direct.StatusCode), direct.Body)
+	return directResponse(uint32(direct.StatusCode), string(body)), nil
 }
 
 func internalRedirectPolicy(internal *contour_api_v1.HTTPInternalRedirectPolicy) *InternalRedirectPolicy {
@@ -1911,6 +2836,29 @@ func internalRedirectPolicy(internal *contour_api_v1.HTTPInternalRedirectPolicy)
 	return policy
 }
 
+func http2KeepaliveConfig(keepalive *contour_api_v1.HTTP2KeepalivePolicy) (*HTTP2KeepaliveConfig, error) {
+	interval, err := time.ParseDuration(keepalive.Interval)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing interval: %s", err)
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("interval %q must be greater than zero", keepalive.Interval)
+	}
+
+	timeout, err := time.ParseDuration(keepalive.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing timeout: %s", err)
+	}
+	if timeout < time.Millisecond {
+		return nil, fmt.Errorf("timeout %q must be at least 1ms", keepalive.Timeout)
+	}
+
+	return &HTTP2KeepaliveConfig{
+		Interval: interval,
+		Timeout:  timeout,
+	}, nil
+}
+
 func slowStartConfig(slowStart *contour_api_v1.SlowStartPolicy) (*SlowStartConfig, error) {
 	window, err := time.ParseDuration(slowStart.Window)
 	if err != nil {
@@ -1931,3 +2879,106 @@ func slowStartConfig(slowStart *contour_api_v1.SlowStartPolicy) (*SlowStartConfi
 		MinWeightPercent: slowStart.MinimumWeightPercent,
 	}, nil
 }
+
+// parameterReference matches the references to the
+// parameters of an HTTPProxy, "${name}".
+var parameterReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// parameterizedHTTPProxy returns a copy of proxy whose references to
+// its parameters are replaced with the values supplied by an include,
+// or else with their defaults. proxy itself is returned when it
+// declares no parameters and the include supplies none.
+func parameterizedHTTPProxy(proxy *contour_api_v1.HTTPProxy, supplied []contour_api_v1.IncludeParameter) (*contour_api_v1.HTTPProxy, error) {
+	if len(proxy.Spec.Parameters) == 0 && len(supplied) == 0 {
+		return proxy, nil
+	}
+
+	values := map[string]string{}
+	for _, param := range supplied {
+		if _, ok := values[param.Name]; ok {
+			return nil, fmt.Errorf("parameter %q is supplied more than once", param.Name)
+		}
+		values[param.Name] = param.Value
+	}
+
+	declared := map[string]bool{}
+	for _, param := range proxy.Spec.Parameters {
+		if declared[param.Name] {
+			return nil, fmt.Errorf("parameter %q is declared more than once", param.Name)
+		}
+		declared[param.Name] = true
+
+		if _, ok := values[param.Name]; ok {
+			continue
+		}
+		if param.Default == nil {
+			return nil, fmt.Errorf("parameter %q is not supplied", param.Name)
+		}
+		values[param.Name] = *param.Default
+	}
+
+	for _, param := range supplied {
+		if !declared[param.Name] {
+			return nil, fmt.Errorf("parameter %q is not declared", param.Name)
+		}
+	}
+
+	var err error
+	expand := func(s string) string {
+		return parameterReference.ReplaceAllStringFunc(s, func(ref string) string {
+			name := ref[2 : len(ref)-1]
+			value, ok := values[name]
+			if !ok && err == nil {
+				err = fmt.Errorf("reference to undeclared parameter %q", name)
+			}
+			return value
+		})
+	}
+	expandConditions := func(conds []contour_api_v1.MatchCondition) {
+		for i := range conds {
+			conds[i].Prefix = expand(conds[i].Prefix)
+		}
+	}
+	expandHeadersPolicy := func(policy *contour_api_v1.HeadersPolicy) {
+		if policy == nil {
+			return
+		}
+		for i := range policy.Set {
+			policy.Set[i].Value = expand(policy.Set[i].Value)
+		}
+	}
+
+	proxy = proxy.DeepCopy()
+	for i := range proxy.Spec.Routes {
+		route := &proxy.Spec.Routes[i]
+		expandConditions(route.Conditions)
+		expandHeadersPolicy(route.RequestHeadersPolicy)
+		expandHeadersPolicy(route.ResponseHeadersPolicy)
+		for j := range route.Services {
+			service := &route.Services[j]
+			service.Name = expand(service.Name)
+			for k := range service.Failover {
+				service.Failover[k].Name = expand(service.Failover[k].Name)
+			}
+			expandHeadersPolicy(service.RequestHeadersPolicy)
+			expandHeadersPolicy(service.ResponseHeadersPolicy)
+		}
+		for j := range route.AggregateServices {
+			route.AggregateServices[j].Name = expand(route.AggregateServices[j].Name)
+		}
+	}
+	for i := range proxy.Spec.Includes {
+		include := &proxy.Spec.Includes[i]
+		include.Name = expand(include.Name)
+		include.Namespace = expand(include.Namespace)
+		expandConditions(include.Conditions)
+		for j := range include.Parameters {
+			include.Parameters[j].Value = expand(include.Parameters[j].Value)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return proxy, nil
+}
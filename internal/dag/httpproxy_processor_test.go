@@ -1386,3 +1386,178 @@ func TestValidateVirtualHostRateLimitPolicy(t *testing.T) {
 		})
 	}
 }
+
+func TestParameterizedHTTPProxy(t *testing.T) {
+	template := &contour_api_v1.HTTPProxy{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "template",
+			Namespace: "default",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			Parameters: []contour_api_v1.HTTPProxyParameter{{
+				Name: "service",
+			}, {
+				Name:    "team",
+				Default: ref.To("platform"),
+			}},
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/${team}",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: "${service}",
+					Port: 8080,
+					Failover: []contour_api_v1.FailoverService{{
+						Name: "${service}-standby",
+						Port: 8080,
+					}},
+					RequestHeadersPolicy: &contour_api_v1.HeadersPolicy{
+						Set: []contour_api_v1.HeaderValue{{Name: "x-service", Value: "${service}"}},
+					},
+				}},
+				ResponseHeadersPolicy: &contour_api_v1.HeadersPolicy{
+					Set: []contour_api_v1.HeaderValue{{Name: "x-team", Value: "${team}"}},
+				},
+			}},
+			Includes: []contour_api_v1.Include{{
+				Name:      "${service}-child",
+				Namespace: "${team}",
+				Parameters: []contour_api_v1.IncludeParameter{{
+					Name:  "owner",
+					Value: "${team}",
+				}},
+			}},
+		},
+	}
+
+	tests := map[string]struct {
+		proxy    *contour_api_v1.HTTPProxy
+		supplied []contour_api_v1.IncludeParameter
+		want     func(*contour_api_v1.HTTPProxy)
+		wantErr  string
+	}{
+		"no parameters": {
+			proxy: &contour_api_v1.HTTPProxy{
+				Spec: contour_api_v1.HTTPProxySpec{
+					Routes: []contour_api_v1.Route{{
+						Services: []contour_api_v1.Service{{Name: "${service}", Port: 8080}},
+					}},
+				},
+			},
+			want: func(*contour_api_v1.HTTPProxy) {},
+		},
+		"supplied and default values": {
+			proxy: template,
+			supplied: []contour_api_v1.IncludeParameter{{
+				Name:  "service",
+				Value: "kuard",
+			}},
+			want: func(proxy *contour_api_v1.HTTPProxy) {
+				route := &proxy.Spec.Routes[0]
+				route.Conditions[0].Prefix = "/platform"
+				route.Services[0].Name = "kuard"
+				route.Services[0].Failover[0].Name = "kuard-standby"
+				route.Services[0].RequestHeadersPolicy.Set[0].Value = "kuard"
+				route.ResponseHeadersPolicy.Set[0].Value = "platform"
+				include := &proxy.Spec.Includes[0]
+				include.Name = "kuard-child"
+				include.Namespace = "platform"
+				include.Parameters[0].Value = "platform"
+			},
+		},
+		"supplied value overrides default": {
+			proxy: template,
+			supplied: []contour_api_v1.IncludeParameter{{
+				Name:  "service",
+				Value: "kuard",
+			}, {
+				Name:  "team",
+				Value: "marketing",
+			}},
+			want: func(proxy *contour_api_v1.HTTPProxy) {
+				route := &proxy.Spec.Routes[0]
+				route.Conditions[0].Prefix = "/marketing"
+				route.Services[0].Name = "kuard"
+				route.Services[0].Failover[0].Name = "kuard-standby"
+				route.Services[0].RequestHeadersPolicy.Set[0].Value = "kuard"
+				route.ResponseHeadersPolicy.Set[0].Value = "marketing"
+				include := &proxy.Spec.Includes[0]
+				include.Name = "kuard-child"
+				include.Namespace = "marketing"
+				include.Parameters[0].Value = "marketing"
+			},
+		},
+		"parameter without default not supplied": {
+			proxy:   template,
+			wantErr: `parameter "service" is not supplied`,
+		},
+		"parameter supplied more than once": {
+			proxy: template,
+			supplied: []contour_api_v1.IncludeParameter{{
+				Name:  "service",
+				Value: "kuard",
+			}, {
+				Name:  "service",
+				Value: "kuarder",
+			}},
+			wantErr: `parameter "service" is supplied more than once`,
+		},
+		"undeclared parameter supplied": {
+			proxy: template,
+			supplied: []contour_api_v1.IncludeParameter{{
+				Name:  "service",
+				Value: "kuard",
+			}, {
+				Name:  "port",
+				Value: "8080",
+			}},
+			wantErr: `parameter "port" is not declared`,
+		},
+		"reference to undeclared parameter": {
+			proxy: &contour_api_v1.HTTPProxy{
+				Spec: contour_api_v1.HTTPProxySpec{
+					Parameters: []contour_api_v1.HTTPProxyParameter{{
+						Name:    "service",
+						Default: ref.To("kuard"),
+					}},
+					Routes: []contour_api_v1.Route{{
+						Services: []contour_api_v1.Service{{Name: "${svc}", Port: 8080}},
+					}},
+				},
+			},
+			wantErr: `reference to undeclared parameter "svc"`,
+		},
+		"parameter declared more than once": {
+			proxy: &contour_api_v1.HTTPProxy{
+				Spec: contour_api_v1.HTTPProxySpec{
+					Parameters: []contour_api_v1.HTTPProxyParameter{{
+						Name:    "service",
+						Default: ref.To("kuard"),
+					}, {
+						Name:    "service",
+						Default: ref.To("kuarder"),
+					}},
+				},
+			},
+			wantErr: `parameter "service" is declared more than once`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := parameterizedHTTPProxy(tc.proxy, tc.supplied)
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			want := tc.proxy.DeepCopy()
+			tc.want(want)
+			assert.Equal(t, want, got)
+		})
+	}
+
+	// The template itself is left untouched.
+	assert.Equal(t, "${service}", template.Spec.Routes[0].Services[0].Name)
+}
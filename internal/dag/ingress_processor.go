@@ -59,6 +59,18 @@ type IngressProcessor struct {
 
 	// PerConnectionBufferLimitBytes defines the soft limit on size of the cluster’s new connection read and write buffers.
 	PerConnectionBufferLimitBytes *uint32
+
+	// HTTP2Keepalive defines the PING frames sent on
+	// HTTP/2 connections to upstreams.
+	HTTP2Keepalive *HTTP2KeepaliveConfig
+
+	// HTTP2Settings defines the settings of
+	// HTTP/2 connections to upstreams.
+	HTTP2Settings *HTTP2Settings
+
+	// HTTPSRedirectPolicy customizes the redirect of HTTP
+	// requests to HTTPS (optional).
+	HTTPSRedirectPolicy *HTTPSRedirectPolicy
 }
 
 // Run translates Ingresses into DAG objects and
@@ -129,6 +141,9 @@ func (p *IngressProcessor) computeSecureVirtualhosts() {
 func (p *IngressProcessor) computeIngresses() {
 	// deconstruct each ingress into routes and virtualhost entries
 	for _, ing := range p.source.ingresses {
+		if _, ok := ing.Annotations["kubernetes.io/ingress.class"]; ok {
+			p.dag.useDeprecatedFeature(DeprecatedIngressClassAnnotation, ing)
+		}
 
 		// rewrite the default ingress to a stock ingress rule.
 		rules := rulesFromSpec(ing.Spec)
@@ -215,6 +230,7 @@ func (p *IngressProcessor) computeIngressRule(ing *networking_v1.Ingress, rule n
 
 			vhost := p.dag.EnsureVirtualHost(listener.Name, host)
 			vhost.AddRoute(r)
+			vhost.addHTTPSRedirectExclusions(r)
 		}
 
 		listener, err := p.dag.GetSingleListener("https")
@@ -272,9 +288,15 @@ func (p *IngressProcessor) route(ingress *networking_v1.Ingress, host string, pa
 			TimeoutPolicy:                 ClusterTimeoutPolicy{ConnectTimeout: p.ConnectTimeout},
 			MaxRequestsPerConnection:      p.MaxRequestsPerConnection,
 			PerConnectionBufferLimitBytes: p.PerConnectionBufferLimitBytes,
+			HTTP2Keepalive:                p.HTTP2Keepalive,
+			HTTP2Settings:                 p.HTTP2Settings,
 		}},
 	}
 
+	if r.HTTPSUpgrade {
+		r.HTTPSRedirectPolicy = p.HTTPSRedirectPolicy
+	}
+
 	switch pathType {
 	case networking_v1.PathTypePrefix:
 		prefixMatchType := PrefixMatchSegment
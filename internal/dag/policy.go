@@ -16,8 +16,11 @@ package dag
 import (
 	"errors"
 	"fmt"
+	"math/big"
 	"net/http"
+	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -55,6 +58,11 @@ const (
 	// LoadBalancerPolicyRequestHash denotes request attribute hashing is used
 	// to make load balancing decisions.
 	LoadBalancerPolicyRequestHash = "RequestHash"
+
+	// LoadBalancerPolicyMaglev denotes request attribute hashing is used
+	// to make load balancing decisions, with a Maglev lookup table
+	// instead of a hash ring.
+	LoadBalancerPolicyMaglev = "Maglev"
 )
 
 // retryOn transforms a slice of retry on values to a comma-separated string.
@@ -71,9 +79,21 @@ func retryOn(ron []contour_api_v1.RetryOn) string {
 	return strings.Join(ss, ",")
 }
 
-func retryPolicy(rp *contour_api_v1.RetryPolicy) *RetryPolicy {
+func retryPolicy(rp *contour_api_v1.RetryPolicy) (*RetryPolicy, error) {
 	if rp == nil {
-		return nil
+		return nil, nil
+	}
+
+	if err := retriableHeadersValid(rp.RetriableHeaders); err != nil {
+		return nil, fmt.Errorf("retriableHeaders: %w", err)
+	}
+	if err := retriableHeadersValid(rp.RetriableRequestHeaders); err != nil {
+		return nil, fmt.Errorf("retriableRequestHeaders: %w", err)
+	}
+
+	perTryIdleTimeout, err := timeout.Parse(rp.PerTryIdleTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("perTryIdleTimeout: %w", err)
 	}
 
 	// If PerTryTimeout is not a valid duration string, use the Envoy default
@@ -97,12 +117,95 @@ func retryPolicy(rp *contour_api_v1.RetryPolicy) *RetryPolicy {
 		numRetries = 1
 	}
 
+	var avoidPreviousHosts bool
+	for _, predicate := range rp.RetryHostPredicates {
+		switch predicate {
+		case "previous-hosts":
+			avoidPreviousHosts = true
+		default:
+			return nil, fmt.Errorf("retryHostPredicates: unsupported predicate %q", predicate)
+		}
+	}
+
+	var backOffBase, backOffMax time.Duration
+	if rp.RetryBackOff != nil {
+		backOffBase, backOffMax, err = retryBackOff(rp.RetryBackOff)
+		if err != nil {
+			return nil, fmt.Errorf("retryBackOff: %w", err)
+		}
+	}
+
 	return &RetryPolicy{
-		RetryOn:              retryOn(rp.RetryOn),
-		RetriableStatusCodes: rp.RetriableStatusCodes,
-		NumRetries:           uint32(numRetries),
-		PerTryTimeout:        perTryTimeout,
+		RetryOn:                  retryOn(rp.RetryOn),
+		RetriableStatusCodes:     rp.RetriableStatusCodes,
+		NumRetries:               uint32(numRetries),
+		PerTryTimeout:            perTryTimeout,
+		RetriableHeaders:         headerMatchConditions(rp.RetriableHeaders),
+		RetriableRequestHeaders:  headerMatchConditions(rp.RetriableRequestHeaders),
+		PerTryIdleTimeout:        perTryIdleTimeout,
+		AvoidPreviousHosts:       avoidPreviousHosts,
+		HostSelectionMaxAttempts: rp.HostSelectionMaxAttempts,
+		BackOffBaseInterval:      backOffBase,
+		BackOffMaxInterval:       backOffMax,
+	}, nil
+}
+
+// retryBackOff parses the base and max intervals of a retry back-off.
+func retryBackOff(rb *contour_api_v1.RetryBackOff) (base, max time.Duration, err error) {
+	base, err = time.ParseDuration(rb.BaseInterval)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid base interval %q: %w", rb.BaseInterval, err)
+	}
+	if base <= time.Millisecond {
+		return 0, 0, fmt.Errorf("base interval %q must be greater than 1ms", rb.BaseInterval)
+	}
+
+	if rb.MaxInterval == "" {
+		return base, 0, nil
 	}
+
+	max, err = time.ParseDuration(rb.MaxInterval)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid max interval %q: %w", rb.MaxInterval, err)
+	}
+	if max < base {
+		return 0, 0, fmt.Errorf("max interval %q must not be less than base interval %q", rb.MaxInterval, rb.BaseInterval)
+	}
+	return base, max, nil
+}
+
+// hedgePolicy builds a HedgePolicy for a route with the given retry
+// policy. Hedging on the per-try timeout requires retries with a
+// per-try timeout.
+func hedgePolicy(hp *contour_api_v1.HedgePolicy, rp *RetryPolicy) (*HedgePolicy, error) {
+	if hp == nil {
+		return nil, nil
+	}
+
+	if hp.HedgeOnPerTryTimeout {
+		if rp == nil || rp.NumRetries == 0 || rp.PerTryTimeout.UseDefault() || rp.PerTryTimeout.IsDisabled() {
+			return nil, errors.New("hedgeOnPerTryTimeout requires a retryPolicy with retries and a perTryTimeout")
+		}
+	}
+
+	return &HedgePolicy{
+		HedgeOnPerTryTimeout: hp.HedgeOnPerTryTimeout,
+	}, nil
+}
+
+// retriableHeadersValid validates the header conditions of a retry
+// policy. Unlike the header conditions of a route, any of them can
+// match, so they can't contradict each other.
+func retriableHeadersValid(headers []contour_api_v1.HeaderMatchCondition) error {
+	for _, h := range headers {
+		if h.Regex == "" {
+			continue
+		}
+		if err := ValidateRegex(h.Regex); err != nil {
+			return fmt.Errorf("invalid regular expression specified for header %q", h.Name)
+		}
+	}
+	return nil
 }
 
 func headersPolicyService(defaultPolicy *HeadersPolicy, policy *contour_api_v1.HeadersPolicy, allowHostRewrite bool, dynamicHeaders map[string]string) (*HeadersPolicy, error) {
@@ -318,6 +421,67 @@ func escapeHeaderValue(value string, dynamicHeaders map[string]string) string {
 	return escapedValue
 }
 
+// abTestPolicy returns the ABTestPolicy for the given
+// policy, or nil if it is nil.
+func abTestPolicy(policy *contour_api_v1.ABTestPolicy) (*ABTestPolicy, error) {
+	if policy == nil {
+		return nil, nil
+	}
+
+	if (policy.HeaderName == "") == (policy.CookieName == "") {
+		return nil, errors.New("exactly one of headerName and cookieName must be set")
+	}
+
+	return &ABTestPolicy{
+		HeaderName: policy.HeaderName,
+		CookieName: policy.CookieName,
+		Salt:       policy.Salt,
+	}, nil
+}
+
+// sameCookieRewritePolicies returns whether the clusters all have
+// the same cookie rewrite policies, in any order.
+func sameCookieRewritePolicies(clusters []*Cluster) bool {
+	policies := func(c *Cluster) map[string]CookieRewritePolicy {
+		m := map[string]CookieRewritePolicy{}
+		for _, p := range c.CookieRewritePolicies {
+			m[p.Name] = p
+		}
+		return m
+	}
+
+	for i := 1; i < len(clusters); i++ {
+		if !reflect.DeepEqual(policies(clusters[0]), policies(clusters[i])) {
+			return false
+		}
+	}
+	return true
+}
+
+// runtimeFlagRegex matches valid runtime flag names.
+var runtimeFlagRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]+(\.[a-zA-Z0-9_-]+)*$`)
+
+// runtimeFraction returns the RuntimeFraction for the given
+// runtime fraction, or nil if it is nil.
+func runtimeFraction(fraction *contour_api_v1.RuntimeFraction) (*RuntimeFraction, error) {
+	if fraction == nil {
+		return nil, nil
+	}
+
+	if !runtimeFlagRegex.MatchString(fraction.Flag) {
+		return nil, fmt.Errorf("invalid flag %q", fraction.Flag)
+	}
+
+	if fraction.DefaultPercent > 100 {
+		return nil, fmt.Errorf("defaultPercent %d must be at most 100", fraction.DefaultPercent)
+	}
+
+	return &RuntimeFraction{
+		Flag:           fraction.Flag,
+		DefaultPercent: fraction.DefaultPercent,
+	}, nil
+}
+
 func cookieRewritePolicies(policies []contour_api_v1.CookieRewritePolicy) ([]CookieRewritePolicy, error) {
 	validPolicies := make([]CookieRewritePolicy, 0, len(policies))
 	cookieNames := map[string]struct{}{}
@@ -448,15 +612,43 @@ func timeoutPolicy(tp *contour_api_v1.TimeoutPolicy, connectTimeout time.Duratio
 		return RouteTimeoutPolicy{}, ClusterTimeoutPolicy{}, fmt.Errorf("error parsing idle connection timeout: %w", err)
 	}
 
+	// The connect timeout overrides the global one, and
+	// can't be infinite, so it is parsed as a plain duration.
+	if tp.Connect != "" {
+		connectTimeout, err = time.ParseDuration(tp.Connect)
+		if err != nil {
+			return RouteTimeoutPolicy{}, ClusterTimeoutPolicy{}, fmt.Errorf("error parsing connect timeout: %w", err)
+		}
+		if connectTimeout <= 0 {
+			return RouteTimeoutPolicy{}, ClusterTimeoutPolicy{}, fmt.Errorf("connect timeout %q must be greater than zero", tp.Connect)
+		}
+	}
+
 	return RouteTimeoutPolicy{
-			ResponseTimeout:   responseTimeout,
-			IdleStreamTimeout: idleStreamTimeout,
+			ResponseTimeout:        responseTimeout,
+			IdleStreamTimeout:      idleStreamTimeout,
+			ResponseTimeoutReply:   timeoutReply(tp.ResponseReply),
+			IdleStreamTimeoutReply: timeoutReply(tp.IdleReply),
 		}, ClusterTimeoutPolicy{
 			IdleConnectionTimeout: idleConnectionTimeout,
 			ConnectTimeout:        connectTimeout,
 		}, nil
 }
 
+// timeoutReply returns the TimeoutReply for the given reply,
+// or nil if it is nil.
+func timeoutReply(reply *contour_api_v1.TimeoutReply) *TimeoutReply {
+	if reply == nil {
+		return nil
+	}
+
+	return &TimeoutReply{
+		StatusCode:  uint32(reply.StatusCode),
+		Body:        reply.Body,
+		ContentType: reply.ContentType,
+	}
+}
+
 func httpHealthCheckPolicy(hc *contour_api_v1.HTTPHealthCheckPolicy) (*HTTPHealthCheckPolicy, error) {
 	if hc == nil {
 		return nil, nil
@@ -512,7 +704,7 @@ func loadBalancerPolicy(lbp *contour_api_v1.LoadBalancerPolicy) string {
 		return ""
 	}
 	switch lbp.Strategy {
-	case LoadBalancerPolicyWeightedLeastRequest, LoadBalancerPolicyRandom, LoadBalancerPolicyCookie, LoadBalancerPolicyRequestHash:
+	case LoadBalancerPolicyWeightedLeastRequest, LoadBalancerPolicyRandom, LoadBalancerPolicyCookie, LoadBalancerPolicyRequestHash, LoadBalancerPolicyMaglev:
 		return lbp.Strategy
 	default:
 		return ""
@@ -674,6 +866,44 @@ func globalRateLimitPolicy(in *contour_api_v1.GlobalRateLimitPolicy) (*GlobalRat
 	return res, nil
 }
 
+// loadBalancerTuning returns the Maglev table size and the least
+// request active request bias of the load balancer policy. Settings
+// that don't apply to the actual strategy are ignored with a warning.
+func loadBalancerTuning(lbp *contour_api_v1.LoadBalancerPolicy, strategy string, validCond *contour_api_v1.DetailedCondition) (uint64, *float64, error) {
+	if lbp == nil {
+		return 0, nil, nil
+	}
+
+	var tableSize uint64
+	if lbp.MaglevTableSize > 0 {
+		switch {
+		case strategy != LoadBalancerPolicyMaglev:
+			validCond.AddWarningf(contour_api_v1.ConditionTypeSpecError, "IgnoredField",
+				"ignoring field %q; it only applies to the %s load balancer strategy", "loadBalancerPolicy.maglevTableSize", LoadBalancerPolicyMaglev)
+		case !big.NewInt(0).SetUint64(lbp.MaglevTableSize).ProbablyPrime(0):
+			return 0, nil, fmt.Errorf("maglevTableSize %d is not a prime number", lbp.MaglevTableSize)
+		default:
+			tableSize = lbp.MaglevTableSize
+		}
+	}
+
+	var bias *float64
+	if lbp.ActiveRequestBias != "" {
+		if strategy != LoadBalancerPolicyWeightedLeastRequest {
+			validCond.AddWarningf(contour_api_v1.ConditionTypeSpecError, "IgnoredField",
+				"ignoring field %q; it only applies to the %s load balancer strategy", "loadBalancerPolicy.activeRequestBias", LoadBalancerPolicyWeightedLeastRequest)
+		} else {
+			value, err := strconv.ParseFloat(lbp.ActiveRequestBias, 64)
+			if err != nil || value < 0 {
+				return 0, nil, fmt.Errorf("activeRequestBias %q is not a non-negative decimal number", lbp.ActiveRequestBias)
+			}
+			bias = &value
+		}
+	}
+
+	return tableSize, bias, nil
+}
+
 // Validates and returns list of hash policies along with lb actual strategy to
 // be used. Will return default strategy and empty list of hash policies if
 // validation fails.
@@ -691,7 +921,7 @@ func loadBalancerRequestHashPolicies(lbp *contour_api_v1.LoadBalancerPolicy, val
 				Path:       "/",
 			}},
 		}, LoadBalancerPolicyCookie
-	case LoadBalancerPolicyRequestHash:
+	case LoadBalancerPolicyRequestHash, LoadBalancerPolicyMaglev:
 		rhps := []RequestHashPolicy{}
 		actualStrategy := strategy
 		hashSourceIPSet := false
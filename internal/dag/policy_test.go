@@ -20,6 +20,7 @@ import (
 	"time"
 
 	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
+	"github.com/projectcontour/contour/internal/ref"
 	"github.com/projectcontour/contour/internal/timeout"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
@@ -117,10 +118,71 @@ func TestRetryPolicyIngress(t *testing.T) {
 	}
 }
 
+func TestHedgePolicy(t *testing.T) {
+	retries := &RetryPolicy{
+		RetryOn:       "5xx",
+		NumRetries:    2,
+		PerTryTimeout: timeout.DurationSetting(100 * time.Millisecond),
+	}
+
+	tests := map[string]struct {
+		hp      *contour_api_v1.HedgePolicy
+		rp      *RetryPolicy
+		want    *HedgePolicy
+		wantErr bool
+	}{
+		"nil hedge policy": {
+			hp:   nil,
+			rp:   retries,
+			want: nil,
+		},
+		"hedge on per try timeout": {
+			hp:   &contour_api_v1.HedgePolicy{HedgeOnPerTryTimeout: true},
+			rp:   retries,
+			want: &HedgePolicy{HedgeOnPerTryTimeout: true},
+		},
+		"hedge on per try timeout without retry policy": {
+			hp:      &contour_api_v1.HedgePolicy{HedgeOnPerTryTimeout: true},
+			rp:      nil,
+			wantErr: true,
+		},
+		"hedge on per try timeout without per try timeout": {
+			hp: &contour_api_v1.HedgePolicy{HedgeOnPerTryTimeout: true},
+			rp: &RetryPolicy{
+				RetryOn:    "5xx",
+				NumRetries: 2,
+			},
+			wantErr: true,
+		},
+		"hedge on per try timeout with retries disabled": {
+			hp: &contour_api_v1.HedgePolicy{HedgeOnPerTryTimeout: true},
+			rp: &RetryPolicy{
+				RetryOn:       "5xx",
+				NumRetries:    0,
+				PerTryTimeout: timeout.DurationSetting(100 * time.Millisecond),
+			},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := hedgePolicy(tc.hp, tc.rp)
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.want, got)
+			}
+		})
+	}
+}
+
 func TestRetryPolicy(t *testing.T) {
 	tests := map[string]struct {
-		rp   *contour_api_v1.RetryPolicy
-		want *RetryPolicy
+		rp      *contour_api_v1.RetryPolicy
+		want    *RetryPolicy
+		wantErr bool
 	}{
 		"nil retry policy": {
 			rp:   nil,
@@ -181,12 +243,129 @@ func TestRetryPolicy(t *testing.T) {
 				NumRetries:           1,
 			},
 		},
+		"retriable headers": {
+			rp: &contour_api_v1.RetryPolicy{
+				RetryOn: []contour_api_v1.RetryOn{"retriable-headers"},
+				RetriableHeaders: []contour_api_v1.HeaderMatchCondition{{
+					Name:  "x-upstream-retry",
+					Exact: "true",
+				}},
+				RetriableRequestHeaders: []contour_api_v1.HeaderMatchCondition{{
+					Name:    "x-idempotent",
+					Present: true,
+				}},
+			},
+			want: &RetryPolicy{
+				RetryOn:    "retriable-headers",
+				NumRetries: 1,
+				RetriableHeaders: []HeaderMatchCondition{{
+					Name:      "x-upstream-retry",
+					Value:     "true",
+					MatchType: HeaderMatchTypeExact,
+				}},
+				RetriableRequestHeaders: []HeaderMatchCondition{{
+					Name:      "x-idempotent",
+					MatchType: HeaderMatchTypePresent,
+				}},
+			},
+		},
+		"invalid retriable request header regex": {
+			rp: &contour_api_v1.RetryPolicy{
+				RetriableRequestHeaders: []contour_api_v1.HeaderMatchCondition{{
+					Name:  "x-idempotent",
+					Regex: "^(true",
+				}},
+			},
+			wantErr: true,
+		},
+		"per try idle timeout": {
+			rp: &contour_api_v1.RetryPolicy{
+				PerTryIdleTimeout: "5s",
+			},
+			want: &RetryPolicy{
+				RetryOn:           "5xx",
+				NumRetries:        1,
+				PerTryIdleTimeout: timeout.DurationSetting(5 * time.Second),
+			},
+		},
+		"retry host predicates": {
+			rp: &contour_api_v1.RetryPolicy{
+				RetryHostPredicates:      []contour_api_v1.RetryHostPredicate{"previous-hosts"},
+				HostSelectionMaxAttempts: 3,
+			},
+			want: &RetryPolicy{
+				RetryOn:                  "5xx",
+				NumRetries:               1,
+				AvoidPreviousHosts:       true,
+				HostSelectionMaxAttempts: 3,
+			},
+		},
+		"unsupported retry host predicate": {
+			rp: &contour_api_v1.RetryPolicy{
+				RetryHostPredicates: []contour_api_v1.RetryHostPredicate{"canary-hosts"},
+			},
+			wantErr: true,
+		},
+		"invalid per try idle timeout": {
+			rp: &contour_api_v1.RetryPolicy{
+				PerTryIdleTimeout: "five seconds",
+			},
+			wantErr: true,
+		},
+		"retry back off": {
+			rp: &contour_api_v1.RetryPolicy{
+				RetryBackOff: &contour_api_v1.RetryBackOff{
+					BaseInterval: "100ms",
+					MaxInterval:  "2s",
+				},
+			},
+			want: &RetryPolicy{
+				RetryOn:             "5xx",
+				NumRetries:          1,
+				BackOffBaseInterval: 100 * time.Millisecond,
+				BackOffMaxInterval:  2 * time.Second,
+			},
+		},
+		"retry back off without max interval": {
+			rp: &contour_api_v1.RetryPolicy{
+				RetryBackOff: &contour_api_v1.RetryBackOff{
+					BaseInterval: "50ms",
+				},
+			},
+			want: &RetryPolicy{
+				RetryOn:             "5xx",
+				NumRetries:          1,
+				BackOffBaseInterval: 50 * time.Millisecond,
+			},
+		},
+		"retry back off base interval too short": {
+			rp: &contour_api_v1.RetryPolicy{
+				RetryBackOff: &contour_api_v1.RetryBackOff{
+					BaseInterval: "1ms",
+				},
+			},
+			wantErr: true,
+		},
+		"retry back off max interval less than base interval": {
+			rp: &contour_api_v1.RetryPolicy{
+				RetryBackOff: &contour_api_v1.RetryBackOff{
+					BaseInterval: "1s",
+					MaxInterval:  "500ms",
+				},
+			},
+			wantErr: true,
+		},
 	}
 
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
-			got := retryPolicy(tc.rp)
-			assert.Equal(t, tc.want, got)
+			got, err := retryPolicy(tc.rp)
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.want, got)
+			}
 		})
 	}
 }
@@ -267,6 +446,51 @@ func TestTimeoutPolicy(t *testing.T) {
 				ConnectTimeout: 5 * time.Second,
 			},
 		},
+		"connect timeout overrides global connection timeout": {
+			tp: &contour_api_v1.TimeoutPolicy{
+				Connect: "250ms",
+			},
+			clusterConnectTimeout: 5 * time.Second,
+			wantClusterTimeoutPolicy: ClusterTimeoutPolicy{
+				ConnectTimeout: 250 * time.Millisecond,
+			},
+		},
+		"invalid connect timeout": {
+			tp: &contour_api_v1.TimeoutPolicy{
+				Connect: "infinite",
+			},
+			wantErr: true,
+		},
+		"zero connect timeout": {
+			tp: &contour_api_v1.TimeoutPolicy{
+				Connect: "0s",
+			},
+			wantErr: true,
+		},
+		"timeout replies": {
+			tp: &contour_api_v1.TimeoutPolicy{
+				Response: "10s",
+				ResponseReply: &contour_api_v1.TimeoutReply{
+					StatusCode:  503,
+					Body:        `{"error":"timeout"}`,
+					ContentType: "application/json",
+				},
+				IdleReply: &contour_api_v1.TimeoutReply{
+					StatusCode: 408,
+				},
+			},
+			wantRouteTimeoutPolicy: RouteTimeoutPolicy{
+				ResponseTimeout: timeout.DurationSetting(10 * time.Second),
+				ResponseTimeoutReply: &TimeoutReply{
+					StatusCode:  503,
+					Body:        `{"error":"timeout"}`,
+					ContentType: "application/json",
+				},
+				IdleStreamTimeoutReply: &TimeoutReply{
+					StatusCode: 408,
+				},
+			},
+		},
 	}
 
 	for name, tc := range tests {
@@ -321,6 +545,12 @@ func TestLoadBalancerPolicy(t *testing.T) {
 			},
 			want: "RequestHash",
 		},
+		"Maglev": {
+			lbp: &contour_api_v1.LoadBalancerPolicy{
+				Strategy: "Maglev",
+			},
+			want: "Maglev",
+		},
 		"unknown": {
 			lbp: &contour_api_v1.LoadBalancerPolicy{
 				Strategy: "please",
@@ -337,6 +567,84 @@ func TestLoadBalancerPolicy(t *testing.T) {
 	}
 }
 
+func TestLoadBalancerTuning(t *testing.T) {
+	tests := map[string]struct {
+		lbp           *contour_api_v1.LoadBalancerPolicy
+		strategy      string
+		wantTableSize uint64
+		wantBias      *float64
+		wantWarnings  int
+		wantErr       bool
+	}{
+		"nil": {
+			lbp: nil,
+		},
+		"maglev table size": {
+			lbp: &contour_api_v1.LoadBalancerPolicy{
+				Strategy:        "Maglev",
+				MaglevTableSize: 131,
+			},
+			strategy:      LoadBalancerPolicyMaglev,
+			wantTableSize: 131,
+		},
+		"maglev table size not prime": {
+			lbp: &contour_api_v1.LoadBalancerPolicy{
+				Strategy:        "Maglev",
+				MaglevTableSize: 100,
+			},
+			strategy: LoadBalancerPolicyMaglev,
+			wantErr:  true,
+		},
+		"maglev table size ignored": {
+			lbp: &contour_api_v1.LoadBalancerPolicy{
+				Strategy:        "Random",
+				MaglevTableSize: 131,
+			},
+			strategy:     LoadBalancerPolicyRandom,
+			wantWarnings: 1,
+		},
+		"active request bias": {
+			lbp: &contour_api_v1.LoadBalancerPolicy{
+				Strategy:          "WeightedLeastRequest",
+				ActiveRequestBias: "0.5",
+			},
+			strategy: LoadBalancerPolicyWeightedLeastRequest,
+			wantBias: ref.To(0.5),
+		},
+		"active request bias not a number": {
+			lbp: &contour_api_v1.LoadBalancerPolicy{
+				Strategy:          "WeightedLeastRequest",
+				ActiveRequestBias: "lots",
+			},
+			strategy: LoadBalancerPolicyWeightedLeastRequest,
+			wantErr:  true,
+		},
+		"active request bias ignored": {
+			lbp: &contour_api_v1.LoadBalancerPolicy{
+				Strategy:          "Maglev",
+				ActiveRequestBias: "0.5",
+			},
+			strategy:     LoadBalancerPolicyMaglev,
+			wantWarnings: 1,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			validCond := &contour_api_v1.DetailedCondition{}
+			gotTableSize, gotBias, err := loadBalancerTuning(tc.lbp, tc.strategy, validCond)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantTableSize, gotTableSize)
+			assert.Equal(t, tc.wantBias, gotBias)
+			assert.Len(t, validCond.Warnings, tc.wantWarnings)
+		})
+	}
+}
+
 func TestHeadersPolicy(t *testing.T) {
 	tests := map[string]struct {
 		hp      *contour_api_v1.HeadersPolicy
@@ -1259,3 +1567,111 @@ func TestValidateHeaderAlteration(t *testing.T) {
 		})
 	}
 }
+
+func TestABTestPolicy(t *testing.T) {
+	tests := map[string]struct {
+		in      *contour_api_v1.ABTestPolicy
+		want    *ABTestPolicy
+		wantErr bool
+	}{
+		"nil": {
+			in:   nil,
+			want: nil,
+		},
+		"header": {
+			in: &contour_api_v1.ABTestPolicy{
+				HeaderName: "x-user-id",
+				Salt:       "checkout",
+			},
+			want: &ABTestPolicy{
+				HeaderName: "x-user-id",
+				Salt:       "checkout",
+			},
+		},
+		"cookie": {
+			in: &contour_api_v1.ABTestPolicy{
+				CookieName: "session",
+			},
+			want: &ABTestPolicy{
+				CookieName: "session",
+			},
+		},
+		"neither header nor cookie": {
+			in: &contour_api_v1.ABTestPolicy{
+				Salt: "checkout",
+			},
+			wantErr: true,
+		},
+		"both header and cookie": {
+			in: &contour_api_v1.ABTestPolicy{
+				HeaderName: "x-user-id",
+				CookieName: "session",
+			},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := abTestPolicy(tc.in)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestRuntimeFraction(t *testing.T) {
+	tests := map[string]struct {
+		in      *contour_api_v1.RuntimeFraction
+		want    *RuntimeFraction
+		wantErr bool
+	}{
+		"nil": {
+			in:   nil,
+			want: nil,
+		},
+		"valid": {
+			in: &contour_api_v1.RuntimeFraction{
+				Flag:           "checkout.new-ui",
+				DefaultPercent: 25,
+			},
+			want: &RuntimeFraction{
+				Flag:           "checkout.new-ui",
+				DefaultPercent: 25,
+			},
+		},
+		"no flag": {
+			in:      &contour_api_v1.RuntimeFraction{},
+			wantErr: true,
+		},
+		"invalid flag": {
+			in: &contour_api_v1.RuntimeFraction{
+				Flag: "checkout..new-ui",
+			},
+			wantErr: true,
+		},
+		"percent over 100": {
+			in: &contour_api_v1.RuntimeFraction{
+				Flag:           "new-ui",
+				DefaultPercent: 101,
+			},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := runtimeFraction(tc.in)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dag
+
+import (
+	"fmt"
+	"time"
+
+	// Embed the time zone database, since the
+	// Contour image doesn't necessarily have one.
+	_ "time/tzdata"
+
+	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
+)
+
+var scheduleDays = map[contour_api_v1.ScheduleDay]time.Weekday{
+	"Sun": time.Sunday,
+	"Mon": time.Monday,
+	"Tue": time.Tuesday,
+	"Wed": time.Wednesday,
+	"Thu": time.Thursday,
+	"Fri": time.Friday,
+	"Sat": time.Saturday,
+}
+
+// routeSchedule defines when a route is active.
+type routeSchedule struct {
+	location  *time.Location
+	notBefore time.Time
+	notAfter  time.Time
+	windows   []scheduleWindow
+}
+
+// scheduleWindow is a weekly recurring window of time.
+type scheduleWindow struct {
+	// days are the days on which the window starts,
+	// or nil if it starts every day.
+	days map[time.Weekday]bool
+
+	// start and end are the minutes of the day
+	// at which the window starts and ends.
+	start, end int
+}
+
+// toRouteSchedule returns the routeSchedule for the given
+// schedule, or nil if the route has no schedule.
+func toRouteSchedule(schedule *contour_api_v1.RouteSchedule) (*routeSchedule, error) {
+	if schedule == nil {
+		return nil, nil
+	}
+
+	rs := &routeSchedule{
+		location: time.UTC,
+	}
+
+	if schedule.TimeZone != "" {
+		location, err := time.LoadLocation(schedule.TimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time zone %q: %w", schedule.TimeZone, err)
+		}
+		rs.location = location
+	}
+
+	var err error
+	if schedule.NotBefore != "" {
+		if rs.notBefore, err = time.Parse(time.RFC3339, schedule.NotBefore); err != nil {
+			return nil, fmt.Errorf("invalid notBefore %q: %w", schedule.NotBefore, err)
+		}
+	}
+	if schedule.NotAfter != "" {
+		if rs.notAfter, err = time.Parse(time.RFC3339, schedule.NotAfter); err != nil {
+			return nil, fmt.Errorf("invalid notAfter %q: %w", schedule.NotAfter, err)
+		}
+	}
+	if !rs.notBefore.IsZero() && !rs.notAfter.IsZero() && !rs.notAfter.After(rs.notBefore) {
+		return nil, fmt.Errorf("notAfter %q must be after notBefore %q", schedule.NotAfter, schedule.NotBefore)
+	}
+
+	for _, window := range schedule.Windows {
+		sw := scheduleWindow{}
+
+		if sw.start, err = minuteOfDay(window.Start); err != nil {
+			return nil, fmt.Errorf("invalid window start %q: %w", window.Start, err)
+		}
+		if sw.end, err = minuteOfDay(window.End); err != nil {
+			return nil, fmt.Errorf("invalid window end %q: %w", window.End, err)
+		}
+		if sw.end <= sw.start {
+			sw.end += 24 * 60
+		}
+
+		for _, day := range window.Days {
+			weekday, ok := scheduleDays[day]
+			if !ok {
+				return nil, fmt.Errorf("invalid window day %q", day)
+			}
+			if sw.days == nil {
+				sw.days = map[time.Weekday]bool{}
+			}
+			sw.days[weekday] = true
+		}
+
+		rs.windows = append(rs.windows, sw)
+	}
+
+	return rs, nil
+}
+
+// minuteOfDay returns the minute of the day of a time of day in HH:MM format.
+func minuteOfDay(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// evaluate returns whether the route is active at now, and the next
+// time after now at which that changes, or the zero time if it doesn't.
+func (rs *routeSchedule) evaluate(now time.Time) (bool, time.Time) {
+	if !rs.notAfter.IsZero() && !now.Before(rs.notAfter) {
+		return false, time.Time{}
+	}
+
+	var next time.Time
+	candidate := func(t time.Time) {
+		if t.After(now) && (next.IsZero() || t.Before(next)) {
+			next = t
+		}
+	}
+
+	active := true
+	if !rs.notBefore.IsZero() {
+		active = !now.Before(rs.notBefore)
+		candidate(rs.notBefore)
+	}
+	if !rs.notAfter.IsZero() {
+		candidate(rs.notAfter)
+	}
+
+	if len(rs.windows) > 0 {
+		var open bool
+
+		// Windows that started on the previous day may still be
+		// open, and the next window starts within a week.
+		year, month, day := now.In(rs.location).Date()
+		for offset := -1; offset <= 7; offset++ {
+			weekday := time.Date(year, month, day+offset, 0, 0, 0, 0, rs.location).Weekday()
+			for _, window := range rs.windows {
+				if window.days != nil && !window.days[weekday] {
+					continue
+				}
+
+				start := time.Date(year, month, day+offset, 0, window.start, 0, 0, rs.location)
+				end := time.Date(year, month, day+offset, 0, window.end, 0, 0, rs.location)
+				if !now.Before(start) && now.Before(end) {
+					open = true
+				}
+				candidate(start)
+				candidate(end)
+			}
+		}
+
+		active = active && open
+	}
+
+	return active, next
+}
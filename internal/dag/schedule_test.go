@@ -0,0 +1,187 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dag
+
+import (
+	"testing"
+	"time"
+
+	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToRouteSchedule(t *testing.T) {
+	tests := map[string]struct {
+		schedule *contour_api_v1.RouteSchedule
+		wantErr  bool
+	}{
+		"nil schedule": {
+			schedule: nil,
+		},
+		"valid schedule": {
+			schedule: &contour_api_v1.RouteSchedule{
+				TimeZone:  "Europe/Berlin",
+				NotBefore: "2026-01-01T00:00:00Z",
+				NotAfter:  "2026-12-31T00:00:00Z",
+				Windows: []contour_api_v1.ScheduleWindow{{
+					Days:  []contour_api_v1.ScheduleDay{"Mon", "Fri"},
+					Start: "09:00",
+					End:   "17:30",
+				}},
+			},
+		},
+		"invalid time zone": {
+			schedule: &contour_api_v1.RouteSchedule{TimeZone: "Mars/Olympus"},
+			wantErr:  true,
+		},
+		"invalid notBefore": {
+			schedule: &contour_api_v1.RouteSchedule{NotBefore: "tomorrow"},
+			wantErr:  true,
+		},
+		"notAfter before notBefore": {
+			schedule: &contour_api_v1.RouteSchedule{
+				NotBefore: "2026-12-31T00:00:00Z",
+				NotAfter:  "2026-01-01T00:00:00Z",
+			},
+			wantErr: true,
+		},
+		"invalid window start": {
+			schedule: &contour_api_v1.RouteSchedule{
+				Windows: []contour_api_v1.ScheduleWindow{{Start: "9am", End: "17:00"}},
+			},
+			wantErr: true,
+		},
+		"invalid window day": {
+			schedule: &contour_api_v1.RouteSchedule{
+				Windows: []contour_api_v1.ScheduleWindow{{Days: []contour_api_v1.ScheduleDay{"Someday"}, Start: "09:00", End: "17:00"}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := toRouteSchedule(tc.schedule)
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRouteScheduleEvaluate(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	// 2026-10-16 is a Friday.
+	friday := func(hour, min int) time.Time {
+		return time.Date(2026, 10, 16, hour, min, 0, 0, berlin)
+	}
+
+	tests := map[string]struct {
+		schedule   *contour_api_v1.RouteSchedule
+		now        time.Time
+		wantActive bool
+		wantNext   time.Time
+	}{
+		"before notBefore": {
+			schedule:   &contour_api_v1.RouteSchedule{NotBefore: "2026-10-20T10:00:00Z"},
+			now:        friday(12, 0),
+			wantActive: false,
+			wantNext:   time.Date(2026, 10, 20, 10, 0, 0, 0, time.UTC),
+		},
+		"between notBefore and notAfter": {
+			schedule: &contour_api_v1.RouteSchedule{
+				NotBefore: "2026-10-01T00:00:00Z",
+				NotAfter:  "2026-10-20T00:00:00Z",
+			},
+			now:        friday(12, 0),
+			wantActive: true,
+			wantNext:   time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC),
+		},
+		"after notAfter": {
+			schedule:   &contour_api_v1.RouteSchedule{NotAfter: "2026-10-01T00:00:00Z"},
+			now:        friday(12, 0),
+			wantActive: false,
+		},
+		"in window": {
+			schedule: &contour_api_v1.RouteSchedule{
+				TimeZone: "Europe/Berlin",
+				Windows: []contour_api_v1.ScheduleWindow{{
+					Days:  []contour_api_v1.ScheduleDay{"Fri"},
+					Start: "09:00",
+					End:   "17:00",
+				}},
+			},
+			now:        friday(12, 0),
+			wantActive: true,
+			wantNext:   friday(17, 0),
+		},
+		"after window": {
+			schedule: &contour_api_v1.RouteSchedule{
+				TimeZone: "Europe/Berlin",
+				Windows: []contour_api_v1.ScheduleWindow{{
+					Days:  []contour_api_v1.ScheduleDay{"Mon", "Fri"},
+					Start: "09:00",
+					End:   "17:00",
+				}},
+			},
+			now:        friday(18, 0),
+			wantActive: false,
+			wantNext:   time.Date(2026, 10, 19, 9, 0, 0, 0, berlin),
+		},
+		"in window that started the previous day": {
+			schedule: &contour_api_v1.RouteSchedule{
+				TimeZone: "Europe/Berlin",
+				Windows: []contour_api_v1.ScheduleWindow{{
+					Days:  []contour_api_v1.ScheduleDay{"Thu"},
+					Start: "22:00",
+					End:   "02:00",
+				}},
+			},
+			now:        friday(1, 0),
+			wantActive: true,
+			wantNext:   friday(2, 0),
+		},
+		"window across a daylight saving time change": {
+			schedule: &contour_api_v1.RouteSchedule{
+				TimeZone: "Europe/Berlin",
+				Windows: []contour_api_v1.ScheduleWindow{{
+					Days:  []contour_api_v1.ScheduleDay{"Sun"},
+					Start: "09:00",
+					End:   "17:00",
+				}},
+			},
+			now:        time.Date(2026, 10, 23, 12, 0, 0, 0, berlin),
+			wantActive: false,
+			// Clocks go back on 2026-10-25, so the window
+			// starts at 08:00 UTC instead of 07:00 UTC.
+			wantNext: time.Date(2026, 10, 25, 8, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			rs, err := toRouteSchedule(tc.schedule)
+			require.NoError(t, err)
+
+			active, next := rs.evaluate(tc.now)
+			assert.Equal(t, tc.wantActive, active)
+			assert.True(t, tc.wantNext.Equal(next), "want next %s, got %s", tc.wantNext, next)
+		})
+	}
+}
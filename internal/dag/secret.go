@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/projectcontour/contour/internal/sealing"
 	v1 "k8s.io/api/core/v1"
 )
 
@@ -54,6 +55,11 @@ func validTLSSecret(secret *v1.Secret) error {
 		return errors.New("missing TLS private key")
 	}
 
+	// Only valid private keys are sealed, see SealPrivateKey.
+	if sealing.IsSealed(data) {
+		return nil
+	}
+
 	if err := validatePrivateKey(data); err != nil {
 		return fmt.Errorf("invalid TLS private key: %v", err)
 	}
@@ -61,6 +67,28 @@ func validTLSSecret(secret *v1.Secret) error {
 	return nil
 }
 
+// SealPrivateKey replaces the private key in the tls.key key of the
+// Secret with its sealed form, and zeroes the plaintext. Invalid private
+// keys are left as they are, so that they're reported by validTLSSecret.
+// The last-applied-configuration annotation, which may hold a copy of
+// the private key, is removed. SealPrivateKey does nothing if sealer
+// is nil.
+func SealPrivateKey(secret *v1.Secret, sealer *sealing.Sealer) {
+	if sealer == nil {
+		return
+	}
+
+	data, ok := secret.Data[v1.TLSPrivateKeyKey]
+	if !ok || sealing.IsSealed(data) || validatePrivateKey(data) != nil {
+		return
+	}
+
+	secret.Data[v1.TLSPrivateKeyKey] = sealer.Seal(data)
+	sealing.Zero(data)
+
+	delete(secret.Annotations, v1.LastAppliedConfigAnnotation)
+}
+
 // validCASecret returns an error if the Secret is not of type TLS or Opaque or
 // if it doesn't contain a valid CA bundle in the ca.crt key.
 func validCASecret(secret *v1.Secret) error {
@@ -19,8 +19,11 @@ import (
 	"testing"
 
 	"github.com/projectcontour/contour/internal/fixture"
+	"github.com/projectcontour/contour/internal/sealing"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestValidSecrets(t *testing.T) {
@@ -370,6 +373,58 @@ func TestValidSecrets(t *testing.T) {
 	}
 }
 
+func TestSealPrivateKey(t *testing.T) {
+	key, err := sealing.RandomDataKey()
+	require.NoError(t, err)
+	sealer, err := sealing.NewSealer(key)
+	require.NoError(t, err)
+
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				v1.LastAppliedConfigAnnotation: "{}",
+				"other":                        "annotation",
+			},
+		},
+		Type: v1.SecretTypeTLS,
+		Data: secretdata(fixture.CERTIFICATE, fixture.RSA_PRIVATE_KEY),
+	}
+	plaintext := secret.Data[v1.TLSPrivateKeyKey]
+
+	SealPrivateKey(secret, sealer)
+
+	sealed := secret.Data[v1.TLSPrivateKeyKey]
+	assert.True(t, sealing.IsSealed(sealed))
+	assert.Equal(t, make([]byte, len(plaintext)), plaintext)
+	assert.Equal(t, map[string]string{"other": "annotation"}, secret.Annotations)
+	assert.NoError(t, validTLSSecret(secret))
+
+	opened, err := sealer.Open(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, []byte(fixture.RSA_PRIVATE_KEY), opened)
+
+	// Sealing is idempotent.
+	SealPrivateKey(secret, sealer)
+	assert.Equal(t, sealed, secret.Data[v1.TLSPrivateKeyKey])
+
+	// Invalid keys are left to be reported.
+	invalid := &v1.Secret{
+		Type: v1.SecretTypeTLS,
+		Data: secretdata(fixture.CERTIFICATE, "not a key"),
+	}
+	SealPrivateKey(invalid, sealer)
+	assert.Equal(t, []byte("not a key"), invalid.Data[v1.TLSPrivateKeyKey])
+	assert.Error(t, validTLSSecret(invalid))
+
+	// Nothing is sealed without a sealer.
+	unsealed := &v1.Secret{
+		Type: v1.SecretTypeTLS,
+		Data: secretdata(fixture.CERTIFICATE, fixture.RSA_PRIVATE_KEY),
+	}
+	SealPrivateKey(unsealed, nil)
+	assert.Equal(t, []byte(fixture.RSA_PRIVATE_KEY), unsealed.Data[v1.TLSPrivateKeyKey])
+}
+
 func secretdata(cert, key string) map[string][]byte {
 	return map[string][]byte{
 		v1.TLSCertKey:       []byte(cert),
@@ -14,17 +14,22 @@
 package dag
 
 import (
+	"strings"
 	"testing"
 
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	certmanagermetav1 "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
+	contour_api_v1alpha1 "github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
 	"github.com/projectcontour/contour/internal/fixture"
 	"github.com/projectcontour/contour/internal/gatewayapi"
 	"github.com/projectcontour/contour/internal/k8s"
 	"github.com/projectcontour/contour/internal/ref"
 	"github.com/projectcontour/contour/internal/status"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	v1 "k8s.io/api/core/v1"
 	networking_v1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -37,9 +42,10 @@ import (
 func TestDAGStatus(t *testing.T) {
 
 	type testcase struct {
-		objs                []any
-		fallbackCertificate *types.NamespacedName
-		want                map[types.NamespacedName]contour_api_v1.DetailedCondition
+		objs                 []any
+		fallbackCertificate  *types.NamespacedName
+		insecureVirtualHosts contour_api_v1alpha1.InsecureVirtualHostPolicy
+		want                 map[types.NamespacedName]contour_api_v1.DetailedCondition
 	}
 
 	run := func(t *testing.T, desc string, tc testcase) {
@@ -57,7 +63,8 @@ func TestDAGStatus(t *testing.T) {
 						FieldLogger: fixture.NewTestLogger(t),
 					},
 					&HTTPProxyProcessor{
-						FallbackCertificate: tc.fallbackCertificate,
+						FallbackCertificate:  tc.fallbackCertificate,
+						InsecureVirtualHosts: tc.insecureVirtualHosts,
 					},
 					&GatewayAPIProcessor{
 						FieldLogger: fixture.NewTestLogger(t),
@@ -884,6 +891,107 @@ func TestDAGStatus(t *testing.T) {
 		},
 	})
 
+	// proxyInvalidAggregateServicesDuplicate is invalid because it lists an aggregate service twice
+	proxyInvalidAggregateServicesDuplicate := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "roots",
+			Name:      "duplicateaggregate",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/foo",
+				}},
+				AggregateServices: []contour_api_v1.AggregateService{{
+					Name: "home",
+					Port: 8080,
+				}, {
+					Name: "home",
+					Port: 8080,
+				}},
+			}},
+		},
+	}
+
+	run(t, "proxy with duplicate aggregate services is invalid", testcase{
+		objs: []any{proxyInvalidAggregateServicesDuplicate, fixture.ServiceRootsHome},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyInvalidAggregateServicesDuplicate.Name, Namespace: proxyInvalidAggregateServicesDuplicate.Namespace}: fixture.NewValidCondition().
+				WithGeneration(proxyInvalidAggregateServicesDuplicate.Generation).
+				WithError(contour_api_v1.ConditionTypeRouteError, "AggregateServicesNotValid", `route.aggregateServices is invalid: Service [home:8080] is listed more than once`),
+		},
+	})
+
+	// proxyInvalidFailoverServiceInvalid is invalid because it fails over to an invalid service
+	proxyInvalidFailoverServiceInvalid := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "roots",
+			Name:      "invalidfailover",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/foo",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: "home",
+					Port: 8080,
+					Failover: []contour_api_v1.FailoverService{{
+						Name: "invalid",
+						Port: 8080,
+					}},
+				}},
+			}},
+		},
+	}
+
+	run(t, "proxy with missing failover service is invalid", testcase{
+		objs: []any{proxyInvalidFailoverServiceInvalid, fixture.ServiceRootsHome},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyInvalidFailoverServiceInvalid.Name, Namespace: proxyInvalidFailoverServiceInvalid.Namespace}: fixture.NewValidCondition().
+				WithGeneration(proxyInvalidFailoverServiceInvalid.Generation).
+				WithError(contour_api_v1.ConditionTypeServiceError, "FailoverServiceNotValid", `Service [home:8080] failover is invalid: unresolved service reference: service "roots/invalid" not found`),
+		},
+	})
+
+	// proxyInvalidSubset is invalid because its subset has an invalid label key
+	proxyInvalidSubset := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "roots",
+			Name:      "invalidsubset",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/foo",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name:   "home",
+					Port:   8080,
+					Subset: map[string]string{"-version": "v2"},
+				}},
+			}},
+		},
+	}
+
+	run(t, "proxy with invalid subset is invalid", testcase{
+		objs: []any{proxyInvalidSubset, fixture.ServiceRootsHome},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyInvalidSubset.Name, Namespace: proxyInvalidSubset.Namespace}: fixture.NewValidCondition().
+				WithGeneration(proxyInvalidSubset.Generation).
+				WithError(contour_api_v1.ConditionTypeServiceError, "SubsetNotValid", `Service [home:8080] subset is invalid: label key "-version" is invalid: name part must consist of alphanumeric characters, '-', '_' or '.', and must start and end with an alphanumeric character (e.g. 'MyName',  or 'my.name',  or '123-abc', regex used for validation is '([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]')`),
+		},
+	})
+
 	proxyValidExampleCom := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "example-com",
@@ -965,6 +1073,115 @@ func TestDAGStatus(t *testing.T) {
 		},
 	})
 
+	proxyAliasReuseExampleCom := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "alias-example",
+			Namespace: "roots",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn:    "example.org",
+				Aliases: []contour_api_v1.VirtualHostAlias{"www.example.org", "Example.com"},
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: "kuard",
+					Port: 8080,
+				}},
+			}},
+		},
+	}
+
+	run(t, "conflicting proxies due to fqdn reuse as an alias", testcase{
+		objs: []any{proxyValidExampleCom, proxyAliasReuseExampleCom},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyValidExampleCom.Name, Namespace: proxyValidExampleCom.Namespace}: fixture.NewValidCondition().
+				WithGeneration(proxyValidExampleCom.Generation).
+				WithError(contour_api_v1.ConditionTypeVirtualHostError, "DuplicateVhost", `fqdn "example.com" is used in multiple HTTPProxies: roots/alias-example, roots/example-com`),
+			{Name: proxyAliasReuseExampleCom.Name, Namespace: proxyAliasReuseExampleCom.Namespace}: fixture.NewValidCondition().
+				WithGeneration(proxyAliasReuseExampleCom.Generation).
+				WithError(contour_api_v1.ConditionTypeVirtualHostError, "DuplicateVhost", `fqdn "example.com" is used in multiple HTTPProxies: roots/alias-example, roots/example-com`),
+		},
+	})
+
+	proxyDuplicateAlias := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "duplicate-alias",
+			Namespace: "roots",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn:    "example.org",
+				Aliases: []contour_api_v1.VirtualHostAlias{"www.example.org", "WWW.example.org"},
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: "kuard",
+					Port: 8080,
+				}},
+			}},
+		},
+	}
+
+	run(t, "duplicate virtual host alias", testcase{
+		objs: []any{proxyDuplicateAlias},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyDuplicateAlias.Name, Namespace: proxyDuplicateAlias.Namespace}: fixture.NewValidCondition().
+				WithGeneration(proxyDuplicateAlias.Generation).
+				WithError(contour_api_v1.ConditionTypeVirtualHostError, "AliasNotValid", `Spec.VirtualHost.Aliases is invalid: alias "WWW.example.org" is duplicated`),
+		},
+	})
+
+	proxyAliasSecretUnknownAlias := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "alias-secret",
+			Namespace: fixture.SecretRootsCert.Namespace,
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn:    "example.org",
+				Aliases: []contour_api_v1.VirtualHostAlias{"www.example.org"},
+				TLS: &contour_api_v1.TLS{
+					SecretName: fixture.SecretRootsCert.Name,
+					AliasSecrets: []contour_api_v1.AliasSecret{{
+						Alias:      "example.com",
+						SecretName: fixture.SecretRootsCert.Name,
+					}},
+				},
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: "home",
+					Port: 8080,
+				}},
+			}},
+		},
+	}
+
+	run(t, "alias secret of an unknown alias", testcase{
+		objs: []any{fixture.SecretRootsCert, proxyAliasSecretUnknownAlias},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyAliasSecretUnknownAlias.Name, Namespace: proxyAliasSecretUnknownAlias.Namespace}: fixture.NewValidCondition().
+				WithGeneration(proxyAliasSecretUnknownAlias.Generation).
+				WithError(contour_api_v1.ConditionTypeTLSError, "AliasSecretNotValid", `Spec.VirtualHost.TLS.AliasSecrets: "example.com" is not an alias of the virtual host`),
+		},
+	})
+
+	proxyAliasSecretNotFound := proxyAliasSecretUnknownAlias.DeepCopy()
+	proxyAliasSecretNotFound.Spec.VirtualHost.TLS.AliasSecrets = []contour_api_v1.AliasSecret{{
+		Alias:      "www.example.org",
+		SecretName: "non-existing",
+	}}
+
+	run(t, "alias secret not found", testcase{
+		objs: []any{fixture.SecretRootsCert, proxyAliasSecretNotFound},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyAliasSecretNotFound.Name, Namespace: proxyAliasSecretNotFound.Namespace}: fixture.NewValidCondition().
+				WithGeneration(proxyAliasSecretNotFound.Generation).
+				WithError(contour_api_v1.ConditionTypeTLSError, "SecretNotValid", `Spec.VirtualHost.TLS.AliasSecrets Secret "non-existing" is invalid: Secret not found`),
+		},
+	})
+
 	proxyRootIncludesRoot := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "root-blog",
@@ -1264,6 +1481,38 @@ func TestDAGStatus(t *testing.T) {
 		},
 	})
 
+	proxyInvalidMethodMatchConditions := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "roots",
+			Name:      "example",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Methods: []contour_api_v1.HTTPMethod{"GET", "HEAD"},
+				}, {
+					Methods: []contour_api_v1.HTTPMethod{"POST"},
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: "home",
+					Port: 8080,
+				}},
+			}},
+		},
+	}
+
+	run(t, "route method conditions without a common method", testcase{
+		objs: []any{proxyInvalidMethodMatchConditions, fixture.ServiceRootsHome},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyInvalidMethodMatchConditions.Name, Namespace: proxyInvalidMethodMatchConditions.Namespace}: fixture.NewValidCondition().
+				WithGeneration(proxyInvalidMethodMatchConditions.Generation).
+				WithError(contour_api_v1.ConditionTypeRouteError, "MethodMatchConditionsNotValid", "route: method conditions don't have a method in common"),
+		},
+	})
+
 	proxyValidDelegatedRoots := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: "roots",
@@ -2387,6 +2636,78 @@ func TestDAGStatus(t *testing.T) {
 		},
 	})
 
+	proxyIncludeTemplate := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "roots",
+			Name:      "example",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Includes: []contour_api_v1.Include{{
+				Name: "template",
+			}},
+		},
+	}
+
+	proxyTemplate := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "roots",
+			Name:      "template",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			Parameters: []contour_api_v1.HTTPProxyParameter{{
+				Name: "service",
+			}},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: "${service}",
+					Port: 8080,
+				}},
+			}},
+		},
+	}
+
+	run(t, "httpproxy w/ include missing a parameter", testcase{
+		objs: []any{proxyIncludeTemplate, proxyTemplate, fixture.ServiceRootsKuard},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyIncludeTemplate.Name, Namespace: proxyIncludeTemplate.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeIncludeError, "IncludeParametersNotValid", `include roots/template: parameter "service" is not supplied`),
+			{Name: proxyTemplate.Name, Namespace: proxyTemplate.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeIncludeError, "IncludeParametersNotValid", `include from roots/example: parameter "service" is not supplied`),
+		},
+	})
+
+	proxyRootParameters := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "roots",
+			Name:      "example",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Parameters: []contour_api_v1.HTTPProxyParameter{{
+				Name: "service",
+			}},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: "${service}",
+					Port: 8080,
+				}},
+			}},
+		},
+	}
+
+	run(t, "root httpproxy w/ parameters", testcase{
+		objs: []any{proxyRootParameters, fixture.ServiceRootsKuard},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyRootParameters.Name, Namespace: proxyRootParameters.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeSpecError, "ParametersNotPermitted", "Spec.Parameters can only be defined for HTTPProxies that are included"),
+		},
+	})
+
 	proxyTCPInvalidMissingService := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "missing-tcp-proxy-service",
@@ -2639,54 +2960,252 @@ func TestDAGStatus(t *testing.T) {
 		objs: []any{proxyInvalidNoServices, fixture.ServiceRootsKuard},
 		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
 			{Name: proxyInvalidNoServices.Name, Namespace: proxyInvalidNoServices.Namespace}: fixture.NewValidCondition().
-				WithError(contour_api_v1.ConditionTypeRouteError, "RouteActionCountNotValid", "must set exactly one of route.services or route.requestRedirectPolicy or route.directResponsePolicy"),
+				WithError(contour_api_v1.ConditionTypeRouteError, "RouteActionCountNotValid", "must set exactly one of route.services or route.aggregateServices or route.requestRedirectPolicy or route.directResponsePolicy"),
 		},
 	})
 
-	fallbackCertificate := &contour_api_v1.HTTPProxy{
-		ObjectMeta: metav1.ObjectMeta{
-			Namespace: "roots",
-			Name:      "example",
-		},
-		Spec: contour_api_v1.HTTPProxySpec{
-			VirtualHost: &contour_api_v1.VirtualHost{
-				Fqdn: "example.com",
-				TLS: &contour_api_v1.TLS{
-					SecretName:                "ssl-cert",
-					EnableFallbackCertificate: true,
-				},
+	directResponseBodyFrom := func(name string, policy *contour_api_v1.HTTPDirectResponsePolicy) *contour_api_v1.HTTPProxy {
+		return &contour_api_v1.HTTPProxy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "roots",
 			},
-			Routes: []contour_api_v1.Route{{
-				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/foo",
-				}},
-				Services: []contour_api_v1.Service{{
-					Name: "home",
-					Port: 8080,
+			Spec: contour_api_v1.HTTPProxySpec{
+				VirtualHost: &contour_api_v1.VirtualHost{
+					Fqdn: name + ".example.com",
+				},
+				Routes: []contour_api_v1.Route{{
+					DirectResponsePolicy: policy,
 				}},
-			}},
-		},
+			},
+		}
 	}
 
-	fallbackCertDelegation := &contour_api_v1.TLSCertificateDelegation{
+	bodySecret := &v1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			Namespace: "non-existing",
-			Name:      "fallback-cert-delegation",
+			Name:      "bodies",
+			Namespace: "roots",
 		},
-		Spec: contour_api_v1.TLSCertificateDelegationSpec{
-			Delegations: []contour_api_v1.CertificateDelegation{
-				{
-					SecretName:       "non-existing",
-					TargetNamespaces: []string{"roots"},
-				},
-			},
+		Type: v1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			"maintenance.html": []byte("<h1>Down for maintenance</h1>"),
+			"large":            []byte(strings.Repeat("x", 4097)),
 		},
 	}
 
-	run(t, "non-existing fallback certificate passed to contour", testcase{
-		fallbackCertificate: &types.NamespacedName{
-			Name:      "non-existing",
-			Namespace: "non-existing",
+	proxyBodyFromSecret := directResponseBodyFrom("body-from-secret", &contour_api_v1.HTTPDirectResponsePolicy{
+		StatusCode: 503,
+		BodyFrom: &contour_api_v1.DirectResponseBodySource{
+			SecretName: "bodies",
+			Key:        "maintenance.html",
+		},
+	})
+
+	run(t, "direct response body from a Secret", testcase{
+		objs: []any{proxyBodyFromSecret, bodySecret},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyBodyFromSecret.Name, Namespace: proxyBodyFromSecret.Namespace}: fixture.NewValidCondition().Valid(),
+		},
+	})
+
+	proxyBodyFromMissingSecret := directResponseBodyFrom("body-from-missing-secret", &contour_api_v1.HTTPDirectResponsePolicy{
+		StatusCode: 503,
+		BodyFrom: &contour_api_v1.DirectResponseBodySource{
+			SecretName: "missing",
+			Key:        "maintenance.html",
+		},
+	})
+
+	run(t, "direct response body from a missing Secret", testcase{
+		objs: []any{proxyBodyFromMissingSecret, bodySecret},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyBodyFromMissingSecret.Name, Namespace: proxyBodyFromMissingSecret.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeRouteError, "DirectResponsePolicyNotValid",
+					`route.directResponsePolicy is invalid: bodyFrom Secret "roots/missing" is invalid: Secret not found`),
+		},
+	})
+
+	proxyBodyFromMissingKey := directResponseBodyFrom("body-from-missing-key", &contour_api_v1.HTTPDirectResponsePolicy{
+		StatusCode: 503,
+		BodyFrom: &contour_api_v1.DirectResponseBodySource{
+			SecretName: "bodies",
+			Key:        "index.html",
+		},
+	})
+
+	run(t, "direct response body from a missing Secret key", testcase{
+		objs: []any{proxyBodyFromMissingKey, bodySecret},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyBodyFromMissingKey.Name, Namespace: proxyBodyFromMissingKey.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeRouteError, "DirectResponsePolicyNotValid",
+					`route.directResponsePolicy is invalid: bodyFrom Secret "roots/bodies" is invalid: Secret has no key "index.html"`),
+		},
+	})
+
+	proxyBodyFromLargeKey := directResponseBodyFrom("body-from-large-key", &contour_api_v1.HTTPDirectResponsePolicy{
+		StatusCode: 503,
+		BodyFrom: &contour_api_v1.DirectResponseBodySource{
+			SecretName: "bodies",
+			Key:        "large",
+		},
+	})
+
+	run(t, "direct response body from a Secret key that is too large", testcase{
+		objs: []any{proxyBodyFromLargeKey, bodySecret},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyBodyFromLargeKey.Name, Namespace: proxyBodyFromLargeKey.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeRouteError, "DirectResponsePolicyNotValid",
+					`route.directResponsePolicy is invalid: bodyFrom Secret "roots/bodies" key "large" is 4097 bytes, must not be larger than 4096 bytes`),
+		},
+	})
+
+	bodyConfigMap := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "bodies",
+			Namespace: "roots",
+		},
+		Data: map[string]string{
+			"maintenance.html": "<h1>Down for maintenance</h1>",
+		},
+	}
+
+	proxyBodyFromConfigMap := directResponseBodyFrom("body-from-configmap", &contour_api_v1.HTTPDirectResponsePolicy{
+		StatusCode: 503,
+		BodyFrom: &contour_api_v1.DirectResponseBodySource{
+			ConfigMapName: "bodies",
+			Key:           "maintenance.html",
+		},
+	})
+
+	run(t, "direct response body from a ConfigMap", testcase{
+		objs: []any{proxyBodyFromConfigMap, bodyConfigMap},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyBodyFromConfigMap.Name, Namespace: proxyBodyFromConfigMap.Namespace}: fixture.NewValidCondition().Valid(),
+		},
+	})
+
+	run(t, "direct response body from a missing ConfigMap", testcase{
+		objs: []any{proxyBodyFromConfigMap},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyBodyFromConfigMap.Name, Namespace: proxyBodyFromConfigMap.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeRouteError, "DirectResponsePolicyNotValid",
+					`route.directResponsePolicy is invalid: bodyFrom ConfigMap "roots/bodies" is invalid: ConfigMap not found`),
+		},
+	})
+
+	proxyBodyFromMissingConfigMapKey := directResponseBodyFrom("body-from-missing-configmap-key", &contour_api_v1.HTTPDirectResponsePolicy{
+		StatusCode: 503,
+		BodyFrom: &contour_api_v1.DirectResponseBodySource{
+			ConfigMapName: "bodies",
+			Key:           "index.html",
+		},
+	})
+
+	run(t, "direct response body from a missing ConfigMap key", testcase{
+		objs: []any{proxyBodyFromMissingConfigMapKey, bodyConfigMap},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyBodyFromMissingConfigMapKey.Name, Namespace: proxyBodyFromMissingConfigMapKey.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeRouteError, "DirectResponsePolicyNotValid",
+					`route.directResponsePolicy is invalid: bodyFrom ConfigMap "roots/bodies" is invalid: ConfigMap has no key "index.html"`),
+		},
+	})
+
+	proxyBodyFromConfigMapAndSecret := directResponseBodyFrom("body-from-configmap-and-secret", &contour_api_v1.HTTPDirectResponsePolicy{
+		StatusCode: 503,
+		BodyFrom: &contour_api_v1.DirectResponseBodySource{
+			ConfigMapName: "bodies",
+			SecretName:    "bodies",
+			Key:           "maintenance.html",
+		},
+	})
+
+	run(t, "direct response body from both a ConfigMap and a Secret", testcase{
+		objs: []any{proxyBodyFromConfigMapAndSecret, bodyConfigMap, bodySecret},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyBodyFromConfigMapAndSecret.Name, Namespace: proxyBodyFromConfigMapAndSecret.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeRouteError, "DirectResponsePolicyNotValid",
+					"route.directResponsePolicy is invalid: bodyFrom cannot specify both configMapName and secretName"),
+		},
+	})
+
+	proxyBodyFromNothing := directResponseBodyFrom("body-from-nothing", &contour_api_v1.HTTPDirectResponsePolicy{
+		StatusCode: 503,
+		BodyFrom: &contour_api_v1.DirectResponseBodySource{
+			Key: "maintenance.html",
+		},
+	})
+
+	run(t, "direct response body from neither a ConfigMap nor a Secret", testcase{
+		objs: []any{proxyBodyFromNothing},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyBodyFromNothing.Name, Namespace: proxyBodyFromNothing.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeRouteError, "DirectResponsePolicyNotValid",
+					"route.directResponsePolicy is invalid: bodyFrom must specify configMapName or secretName"),
+		},
+	})
+
+	proxyBodyAndBodyFrom := directResponseBodyFrom("body-and-body-from", &contour_api_v1.HTTPDirectResponsePolicy{
+		StatusCode: 503,
+		Body:       "down for maintenance",
+		BodyFrom: &contour_api_v1.DirectResponseBodySource{
+			SecretName: "bodies",
+			Key:        "maintenance.html",
+		},
+	})
+
+	run(t, "direct response with both body and bodyFrom", testcase{
+		objs: []any{proxyBodyAndBodyFrom, bodySecret},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyBodyAndBodyFrom.Name, Namespace: proxyBodyAndBodyFrom.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeRouteError, "DirectResponsePolicyNotValid",
+					"route.directResponsePolicy is invalid: cannot specify both body and bodyFrom"),
+		},
+	})
+
+	fallbackCertificate := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "roots",
+			Name:      "example",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName:                "ssl-cert",
+					EnableFallbackCertificate: true,
+				},
+			},
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/foo",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: "home",
+					Port: 8080,
+				}},
+			}},
+		},
+	}
+
+	fallbackCertDelegation := &contour_api_v1.TLSCertificateDelegation{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "non-existing",
+			Name:      "fallback-cert-delegation",
+		},
+		Spec: contour_api_v1.TLSCertificateDelegationSpec{
+			Delegations: []contour_api_v1.CertificateDelegation{
+				{
+					SecretName:       "non-existing",
+					TargetNamespaces: []string{"roots"},
+				},
+			},
+		},
+	}
+
+	run(t, "non-existing fallback certificate passed to contour", testcase{
+		fallbackCertificate: &types.NamespacedName{
+			Name:      "non-existing",
+			Namespace: "non-existing",
 		},
 		objs: []any{fallbackCertificate, fallbackCertDelegation, fixture.SecretRootsFallback, fixture.SecretRootsCert, fixture.ServiceRootsHome},
 		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
@@ -2861,14 +3380,225 @@ func TestDAGStatus(t *testing.T) {
 		},
 	}
 
-	run(t, "httpproxy w/ tcpproxy with neither TLS passthrough nor secret name specified", testcase{
+	run(t, "httpproxy w/ tcpproxy with none of TLS passthrough, secret name or certificate name specified", testcase{
 		objs: []any{
 			fixture.SecretRootsCert,
 			tlsNoPassthroughOrSecretName,
 		},
 		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
 			{Name: "invalid", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().
-				WithError(contour_api_v1.ConditionTypeTLSError, "TLSConfigNotValid", "Spec.VirtualHost.TLS: neither Passthrough nor SecretName were specified"),
+				WithError(contour_api_v1.ConditionTypeTLSError, "TLSConfigNotValid", "Spec.VirtualHost.TLS: none of Passthrough, SecretName or CertificateName were specified"),
+		},
+	})
+
+	proxyCertificate := func(secretName, certificateName string) *contour_api_v1.HTTPProxy {
+		return &contour_api_v1.HTTPProxy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "certificate",
+				Namespace: fixture.ServiceRootsKuard.Namespace,
+			},
+			Spec: contour_api_v1.HTTPProxySpec{
+				VirtualHost: &contour_api_v1.VirtualHost{
+					Fqdn: "www.example.com",
+					TLS: &contour_api_v1.TLS{
+						SecretName:      secretName,
+						CertificateName: certificateName,
+					},
+				},
+				Routes: []contour_api_v1.Route{{
+					Services: []contour_api_v1.Service{{
+						Name: fixture.ServiceRootsKuard.Name,
+						Port: 8080,
+					}},
+				}},
+			},
+		}
+	}
+
+	certificate := func(conditions ...certmanagerv1.CertificateCondition) *certmanagerv1.Certificate {
+		return &certmanagerv1.Certificate{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "www",
+				Namespace: fixture.ServiceRootsKuard.Namespace,
+			},
+			Spec: certmanagerv1.CertificateSpec{
+				SecretName: fixture.SecretRootsCert.Name,
+			},
+			Status: certmanagerv1.CertificateStatus{
+				Conditions: conditions,
+			},
+		}
+	}
+
+	run(t, "httpproxy with ready certificate", testcase{
+		objs: []any{
+			fixture.SecretRootsCert,
+			fixture.ServiceRootsKuard,
+			certificate(certmanagerv1.CertificateCondition{
+				Type:   certmanagerv1.CertificateConditionReady,
+				Status: certmanagermetav1.ConditionTrue,
+			}),
+			proxyCertificate("", "www"),
+		},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: "certificate", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().Valid(),
+		},
+	})
+
+	run(t, "httpproxy with secret name and certificate name both specified", testcase{
+		objs: []any{
+			fixture.SecretRootsCert,
+			fixture.ServiceRootsKuard,
+			certificate(),
+			proxyCertificate(fixture.SecretRootsCert.Name, "www"),
+		},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: "certificate", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeTLSError, "TLSConfigNotValid", "Spec.VirtualHost.TLS: both SecretName and CertificateName were specified"),
+		},
+	})
+
+	run(t, "httpproxy with missing certificate", testcase{
+		objs: []any{
+			fixture.SecretRootsCert,
+			fixture.ServiceRootsKuard,
+			proxyCertificate("", "www"),
+		},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: "certificate", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeTLSError, "CertificateNotFound", `Spec.VirtualHost.TLS Certificate "www" not found`),
+		},
+	})
+
+	run(t, "httpproxy with certificate pending issuance", testcase{
+		objs: []any{
+			fixture.ServiceRootsKuard,
+			certificate(),
+			proxyCertificate("", "www"),
+		},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: "certificate", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeTLSError, "CertificateNotReady", `Spec.VirtualHost.TLS Certificate "www" is not ready: issuance is pending`),
+		},
+	})
+
+	run(t, "httpproxy with certificate not ready", testcase{
+		objs: []any{
+			fixture.ServiceRootsKuard,
+			certificate(certmanagerv1.CertificateCondition{
+				Type:    certmanagerv1.CertificateConditionReady,
+				Status:  certmanagermetav1.ConditionFalse,
+				Reason:  "DoesNotExist",
+				Message: "Issuing certificate as Secret does not exist",
+			}),
+			proxyCertificate("", "www"),
+		},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: "certificate", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeTLSError, "CertificateNotReady", `Spec.VirtualHost.TLS Certificate "www" is not ready: Issuing certificate as Secret does not exist`),
+		},
+	})
+
+	run(t, "httpproxy with invalid https redirect excluded path prefix", testcase{
+		objs: []any{
+			fixture.SecretRootsCert,
+			fixture.ServiceRootsKuard,
+			&contour_api_v1.HTTPProxy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "redirect",
+					Namespace: fixture.ServiceRootsKuard.Namespace,
+				},
+				Spec: contour_api_v1.HTTPProxySpec{
+					VirtualHost: &contour_api_v1.VirtualHost{
+						Fqdn: "example.com",
+						TLS: &contour_api_v1.TLS{
+							SecretName: fixture.SecretRootsCert.Name,
+						},
+						HTTPSRedirectPolicy: &contour_api_v1.HTTPSRedirectPolicy{
+							ExcludedPathPrefixes: []string{".well-known"},
+						},
+					},
+					Routes: []contour_api_v1.Route{{
+						Services: []contour_api_v1.Service{{
+							Name: fixture.ServiceRootsKuard.Name,
+							Port: 8080,
+						}},
+					}},
+				},
+			},
+		},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: "redirect", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeSpecError, "HTTPSRedirectPolicyNotValid", `Spec.VirtualHost.HTTPSRedirectPolicy: excluded path prefix ".well-known" must start with a slash`),
+		},
+	})
+
+	proxyInsecure := func(insecure bool, tls *contour_api_v1.TLS) *contour_api_v1.HTTPProxy {
+		return &contour_api_v1.HTTPProxy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "insecure",
+				Namespace: fixture.ServiceRootsKuard.Namespace,
+			},
+			Spec: contour_api_v1.HTTPProxySpec{
+				VirtualHost: &contour_api_v1.VirtualHost{
+					Fqdn:     "example.com",
+					Insecure: insecure,
+					TLS:      tls,
+				},
+				Routes: []contour_api_v1.Route{{
+					Services: []contour_api_v1.Service{{
+						Name: fixture.ServiceRootsKuard.Name,
+						Port: 8080,
+					}},
+				}},
+			},
+		}
+	}
+
+	run(t, "httpproxy with insecure and tls both specified", testcase{
+		objs: []any{
+			fixture.SecretRootsCert,
+			fixture.ServiceRootsKuard,
+			proxyInsecure(true, &contour_api_v1.TLS{SecretName: fixture.SecretRootsCert.Name}),
+		},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: "insecure", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeTLSError, "TLSConfigNotValid", "Spec.VirtualHost: both Insecure and TLS were specified"),
+		},
+	})
+
+	run(t, "httpproxy without tls when insecure virtual hosts must be explicit", testcase{
+		objs: []any{
+			fixture.ServiceRootsKuard,
+			proxyInsecure(false, nil),
+		},
+		insecureVirtualHosts: contour_api_v1alpha1.ExplicitInsecureVirtualHosts,
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: "insecure", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeVirtualHostError, "InsecureVirtualHostNotDeclared", "Spec.VirtualHost.Insecure must be set for virtual hosts that don't specify TLS"),
+		},
+	})
+
+	run(t, "insecure httpproxy when insecure virtual hosts must be explicit", testcase{
+		objs: []any{
+			fixture.ServiceRootsKuard,
+			proxyInsecure(true, nil),
+		},
+		insecureVirtualHosts: contour_api_v1alpha1.ExplicitInsecureVirtualHosts,
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: "insecure", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().Valid(),
+		},
+	})
+
+	run(t, "insecure httpproxy when insecure virtual hosts are disallowed", testcase{
+		objs: []any{
+			fixture.ServiceRootsKuard,
+			proxyInsecure(true, nil),
+		},
+		insecureVirtualHosts: contour_api_v1alpha1.DisallowedInsecureVirtualHosts,
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: "insecure", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeVirtualHostError, "InsecureVirtualHostNotPermitted", "Spec.VirtualHost.TLS must be specified, insecure virtual hosts are not permitted"),
 		},
 	})
 
@@ -3224,6 +3954,78 @@ func TestDAGStatus(t *testing.T) {
 		},
 	})
 
+	invalidRetryPolicy := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: fixture.ServiceRootsKuard.Namespace,
+			Name:      "invalid-retry-policy",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Routes: []contour_api_v1.Route{
+				{
+					Services: []contour_api_v1.Service{
+						{
+							Name: fixture.ServiceRootsKuard.Name,
+						},
+					},
+					RetryPolicy: &contour_api_v1.RetryPolicy{
+						RetriableRequestHeaders: []contour_api_v1.HeaderMatchCondition{
+							{Name: "x-idempotent", Regex: "^(true"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	run(t, "proxy with invalid retriable request header regex is invalid", testcase{
+		objs: []any{invalidRetryPolicy, fixture.ServiceRootsKuard},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{
+				Name:      invalidRetryPolicy.Name,
+				Namespace: invalidRetryPolicy.Namespace,
+			}: fixture.NewValidCondition().WithError(contour_api_v1.ConditionTypeRouteError, "RetryPolicyNotValid",
+				`route.retryPolicy is invalid: retriableRequestHeaders: invalid regular expression specified for header "x-idempotent"`),
+		},
+	})
+
+	invalidHedgePolicy := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: fixture.ServiceRootsKuard.Namespace,
+			Name:      "invalid-hedge-policy",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Routes: []contour_api_v1.Route{
+				{
+					Services: []contour_api_v1.Service{
+						{
+							Name: fixture.ServiceRootsKuard.Name,
+						},
+					},
+					HedgePolicy: &contour_api_v1.HedgePolicy{
+						HedgeOnPerTryTimeout: true,
+					},
+				},
+			},
+		},
+	}
+
+	run(t, "proxy with hedge policy but no retry policy is invalid", testcase{
+		objs: []any{invalidHedgePolicy, fixture.ServiceRootsKuard},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{
+				Name:      invalidHedgePolicy.Name,
+				Namespace: invalidHedgePolicy.Namespace,
+			}: fixture.NewValidCondition().WithError(contour_api_v1.ConditionTypeRouteError, "HedgePolicyNotValid",
+				`route.hedgePolicy is invalid: hedgeOnPerTryTimeout requires a retryPolicy with retries and a perTryTimeout`),
+		},
+	})
+
 	// issue 3197: Fallback and passthrough HTTPProxy directive should emit a config error
 	tlsPassthroughAndFallback := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
@@ -3325,7 +4127,7 @@ func TestDAGStatus(t *testing.T) {
 		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
 			{Name: multipleRouteAction.Name, Namespace: multipleRouteAction.Namespace}: fixture.NewValidCondition().
 				WithError(contour_api_v1.ConditionTypeRouteError, "RouteActionCountNotValid",
-					"must set exactly one of route.services or route.requestRedirectPolicy or route.directResponsePolicy"),
+					"must set exactly one of route.services or route.aggregateServices or route.requestRedirectPolicy or route.directResponsePolicy"),
 		},
 	})
 
@@ -3783,6 +4585,42 @@ func TestDAGStatus(t *testing.T) {
 		},
 	})
 
+	serviceInvalidDNSLookupFamily := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "roots",
+			Name:      "service-invalid-dns-lookup-family",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Routes: []contour_api_v1.Route{
+				{
+					Services: []contour_api_v1.Service{{
+						Name:            "home",
+						Port:            8080,
+						DNSLookupFamily: "v7",
+					}},
+				},
+			},
+		},
+	}
+
+	run(t, "service invalid DNS lookup family", testcase{
+		objs: []any{
+			serviceInvalidDNSLookupFamily,
+			fixture.ServiceRootsHome,
+		},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			k8s.NamespacedNameOf(serviceInvalidDNSLookupFamily): fixture.NewValidCondition().
+				WithError(
+					contour_api_v1.ConditionTypeServiceError,
+					"DNSLookupFamilyInvalid",
+					"Service [home:8080] DNSLookupFamily has an invalid value \"v7\", must be auto, all, v4 or v6",
+				),
+		},
+	})
+
 	jwtVerificationNoProvidersRouteHasRef := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: "roots",
@@ -4009,17 +4847,126 @@ func TestDAGStatus(t *testing.T) {
 		},
 	}
 
-	run(t, "JWT verification invalid TLS fallback configured", testcase{
+	run(t, "JWT verification invalid TLS fallback configured", testcase{
+		objs: []any{
+			jwtVerificationInvalidTLSFallbackConfigured,
+			fixture.ServiceRootsHome,
+		},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			k8s.NamespacedNameOf(jwtVerificationInvalidTLSFallbackConfigured): fixture.NewValidCondition().
+				WithError(
+					contour_api_v1.ConditionTypeJWTVerificationError,
+					"JWTVerificationNotPermitted",
+					"Spec.VirtualHost.JWTProviders can only be defined for root HTTPProxies that terminate TLS",
+				),
+		},
+	})
+
+	http2MaxConcurrentStreamsTLSNotConfigured := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "roots",
+			Name:      "http2-max-concurrent-streams-tls-not-configured",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn:                      "example.com",
+				HTTP2MaxConcurrentStreams: ref.To(uint32(100)),
+			},
+			Routes: []contour_api_v1.Route{
+				{
+					Services: []contour_api_v1.Service{{
+						Name: "home",
+						Port: 8080,
+					}},
+				},
+			},
+		},
+	}
+
+	run(t, "HTTP/2 max concurrent streams invalid TLS not configured", testcase{
+		objs: []any{
+			http2MaxConcurrentStreamsTLSNotConfigured,
+			fixture.ServiceRootsHome,
+		},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			k8s.NamespacedNameOf(http2MaxConcurrentStreamsTLSNotConfigured): fixture.NewValidCondition().
+				WithError(
+					contour_api_v1.ConditionTypeVirtualHostError,
+					"HTTP2MaxConcurrentStreamsNotPermitted",
+					"Spec.VirtualHost.HTTP2MaxConcurrentStreams can only be defined for root HTTPProxies that terminate TLS",
+				),
+		},
+	})
+
+	accessLogPolicyDisabledWithFormat := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "roots",
+			Name:      "access-log-policy-disabled-with-format",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+				AccessLogPolicy: &contour_api_v1.AccessLogPolicy{
+					Disabled: true,
+					Format:   "json",
+				},
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: "home",
+					Port: 8080,
+				}},
+			}},
+		},
+	}
+
+	run(t, "access log policy disabled with format", testcase{
+		objs: []any{
+			accessLogPolicyDisabledWithFormat,
+			fixture.ServiceRootsHome,
+		},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			k8s.NamespacedNameOf(accessLogPolicyDisabledWithFormat): fixture.NewValidCondition().
+				WithError(
+					contour_api_v1.ConditionTypeVirtualHostError,
+					"AccessLogPolicyNotValid",
+					"Spec.VirtualHost.AccessLogPolicy is invalid: disabled can't be combined with other settings",
+				),
+		},
+	})
+
+	accessLogPolicyInvalidJSONFields := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "roots",
+			Name:      "access-log-policy-invalid-json-fields",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+				AccessLogPolicy: &contour_api_v1.AccessLogPolicy{
+					JSONFields: []string{"unknown-field"},
+				},
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: "home",
+					Port: 8080,
+				}},
+			}},
+		},
+	}
+
+	run(t, "access log policy with invalid JSON fields", testcase{
 		objs: []any{
-			jwtVerificationInvalidTLSFallbackConfigured,
+			accessLogPolicyInvalidJSONFields,
 			fixture.ServiceRootsHome,
 		},
 		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
-			k8s.NamespacedNameOf(jwtVerificationInvalidTLSFallbackConfigured): fixture.NewValidCondition().
+			k8s.NamespacedNameOf(accessLogPolicyInvalidJSONFields): fixture.NewValidCondition().
 				WithError(
-					contour_api_v1.ConditionTypeJWTVerificationError,
-					"JWTVerificationNotPermitted",
-					"Spec.VirtualHost.JWTProviders can only be defined for root HTTPProxies that terminate TLS",
+					contour_api_v1.ConditionTypeVirtualHostError,
+					"AccessLogPolicyNotValid",
+					"Spec.VirtualHost.AccessLogPolicy is invalid: invalid JSON log field name unknown-field",
 				),
 		},
 	})
@@ -4585,6 +5532,323 @@ func TestDAGStatus(t *testing.T) {
 		},
 	})
 
+	// proxyWithInvalidHTTP2KeepaliveTimeout is invalid because Envoy
+	// requires the keepalive timeout to be at least 1ms.
+	proxyWithInvalidHTTP2KeepaliveTimeout := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "roots",
+			Name:      "http2-keepalive-invalid-timeout",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "www.example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: "home",
+					Port: 8080,
+					HTTP2KeepalivePolicy: &contour_api_v1.HTTP2KeepalivePolicy{
+						Interval: "30s",
+						Timeout:  "100us",
+					},
+				}},
+			}},
+		},
+	}
+
+	run(t, "HTTP/2 keepalive with a timeout below 1ms", testcase{
+		objs: []any{
+			proxyWithInvalidHTTP2KeepaliveTimeout,
+			fixture.ServiceRootsHome,
+		},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			k8s.NamespacedNameOf(proxyWithInvalidHTTP2KeepaliveTimeout): fixture.NewValidCondition().
+				WithError(
+					contour_api_v1.ConditionTypeServiceError,
+					"HTTP2KeepaliveInvalid",
+					"timeout \"100us\" must be at least 1ms on HTTP/2 keepalive policy",
+				),
+		},
+	})
+
+	// proxyWithInvalidScheduleTimeZone is invalid because
+	// its route schedule has an unknown time zone.
+	proxyWithInvalidScheduleTimeZone := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "roots",
+			Name:      "schedule-invalid-time-zone",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "www.example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: "home",
+					Port: 8080,
+				}},
+				Schedule: &contour_api_v1.RouteSchedule{
+					TimeZone: "Mars/Olympus",
+				},
+			}},
+		},
+	}
+
+	run(t, "route schedule with an unknown time zone", testcase{
+		objs: []any{
+			proxyWithInvalidScheduleTimeZone,
+			fixture.ServiceRootsHome,
+		},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			k8s.NamespacedNameOf(proxyWithInvalidScheduleTimeZone): fixture.NewValidCondition().
+				WithError(
+					contour_api_v1.ConditionTypeRouteError,
+					"ScheduleNotValid",
+					"route.schedule is invalid: invalid time zone \"Mars/Olympus\": unknown time zone Mars/Olympus",
+				),
+		},
+	})
+
+	// proxyWithInvalidABTestPolicy is invalid because its
+	// a/b test policy has neither a header nor a cookie.
+	proxyWithInvalidABTestPolicy := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "roots",
+			Name:      "abtest-invalid",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "www.example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: "home",
+					Port: 8080,
+				}},
+				ABTestPolicy: &contour_api_v1.ABTestPolicy{
+					Salt: "checkout",
+				},
+			}},
+		},
+	}
+
+	run(t, "a/b test policy without a header or cookie", testcase{
+		objs: []any{
+			proxyWithInvalidABTestPolicy,
+			fixture.ServiceRootsHome,
+		},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			k8s.NamespacedNameOf(proxyWithInvalidABTestPolicy): fixture.NewValidCondition().
+				WithError(
+					contour_api_v1.ConditionTypeRouteError,
+					"ABTestPolicyNotValid",
+					"route.abTestPolicy is invalid: exactly one of headerName and cookieName must be set",
+				),
+		},
+	})
+
+	abTestServices := func(name string, foo1Cookies, foo2Cookies []contour_api_v1.CookieRewritePolicy) *contour_api_v1.HTTPProxy {
+		return &contour_api_v1.HTTPProxy{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "roots",
+				Name:      name,
+			},
+			Spec: contour_api_v1.HTTPProxySpec{
+				VirtualHost: &contour_api_v1.VirtualHost{
+					Fqdn: name + ".example.com",
+				},
+				Routes: []contour_api_v1.Route{{
+					Services: []contour_api_v1.Service{{
+						Name:                  "foo1",
+						Port:                  8080,
+						Weight:                50,
+						CookieRewritePolicies: foo1Cookies,
+					}, {
+						Name:                  "foo2",
+						Port:                  8080,
+						Weight:                50,
+						CookieRewritePolicies: foo2Cookies,
+					}},
+					ABTestPolicy: &contour_api_v1.ABTestPolicy{
+						CookieName: "session",
+					},
+				}},
+			},
+		}
+	}
+
+	sessionCookie := contour_api_v1.CookieRewritePolicy{
+		Name:        "session",
+		PathRewrite: &contour_api_v1.CookiePathRewrite{Value: "/"},
+	}
+	themeCookie := contour_api_v1.CookieRewritePolicy{
+		Name:   "theme",
+		Secure: ref.To(true),
+	}
+
+	proxyABTestSameCookieRewrites := abTestServices("abtest-same-cookie-rewrites",
+		[]contour_api_v1.CookieRewritePolicy{sessionCookie, themeCookie},
+		[]contour_api_v1.CookieRewritePolicy{themeCookie, sessionCookie})
+
+	run(t, "a/b test policy with the same service cookie rewrite policies", testcase{
+		objs: []any{
+			proxyABTestSameCookieRewrites,
+			fixture.ServiceRootsFoo1,
+			fixture.ServiceRootsFoo2,
+		},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			k8s.NamespacedNameOf(proxyABTestSameCookieRewrites): fixture.NewValidCondition().Valid(),
+		},
+	})
+
+	proxyABTestConflictingCookieRewrites := abTestServices("abtest-conflicting-cookie-rewrites",
+		[]contour_api_v1.CookieRewritePolicy{sessionCookie},
+		[]contour_api_v1.CookieRewritePolicy{themeCookie})
+
+	run(t, "a/b test policy with conflicting service cookie rewrite policies", testcase{
+		objs: []any{
+			proxyABTestConflictingCookieRewrites,
+			fixture.ServiceRootsFoo1,
+			fixture.ServiceRootsFoo2,
+		},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			k8s.NamespacedNameOf(proxyABTestConflictingCookieRewrites): fixture.NewValidCondition().
+				WithError(
+					contour_api_v1.ConditionTypeRouteError,
+					"ABTestPolicyNotValid",
+					"route.abTestPolicy is invalid: the services of the route must have the same cookieRewritePolicies",
+				),
+		},
+	})
+
+	// proxyWithInvalidRuntimeFraction is invalid because
+	// its runtime fraction has a percentage over 100.
+	proxyWithInvalidRuntimeFraction := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "roots",
+			Name:      "runtime-fraction-invalid",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "www.example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: "home",
+					Port: 8080,
+				}},
+				RuntimeFraction: &contour_api_v1.RuntimeFraction{
+					Flag:           "new-home",
+					DefaultPercent: 110,
+				},
+			}},
+		},
+	}
+
+	run(t, "runtime fraction with a percentage over 100", testcase{
+		objs: []any{
+			proxyWithInvalidRuntimeFraction,
+			fixture.ServiceRootsHome,
+		},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			k8s.NamespacedNameOf(proxyWithInvalidRuntimeFraction): fixture.NewValidCondition().
+				WithError(
+					contour_api_v1.ConditionTypeRouteError,
+					"RuntimeFractionNotValid",
+					"route.runtimeFraction is invalid: defaultPercent 110 must be at most 100",
+				),
+		},
+	})
+
+	// proxyWithAmbiguousPriorities is invalid because a regex
+	// route and a prefix route have the same priority.
+	proxyWithAmbiguousPriorities := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "roots",
+			Name:      "ambiguous-priorities",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "www.example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Regex: "/api/.*",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: "home",
+					Port: 8080,
+				}},
+				Priority: 10,
+			}, {
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/api/v1",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: "home",
+					Port: 8080,
+				}},
+				Priority: 10,
+			}},
+		},
+	}
+
+	run(t, "routes with ambiguous priorities", testcase{
+		objs: []any{
+			proxyWithAmbiguousPriorities,
+			fixture.ServiceRootsHome,
+		},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			k8s.NamespacedNameOf(proxyWithAmbiguousPriorities): fixture.NewValidCondition().
+				WithError(
+					contour_api_v1.ConditionTypeRouteError,
+					"AmbiguousRoutePriority",
+					`routes with "regex: /api/.*" and "prefix: /api/v1 type: string" have the same priority 10 and may both match`,
+				),
+		},
+	})
+
+	// proxyWithInvalidOrCondition is invalid because its
+	// Or condition is combined with a prefix condition.
+	proxyWithInvalidOrCondition := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "roots",
+			Name:      "or-condition-invalid",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "www.example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/api",
+					Or: []contour_api_v1.OrMatchCondition{{
+						Prefix: "/v1",
+					}},
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: "home",
+					Port: 8080,
+				}},
+			}},
+		},
+	}
+
+	run(t, "or condition combined with a prefix condition", testcase{
+		objs: []any{
+			proxyWithInvalidOrCondition,
+			fixture.ServiceRootsHome,
+		},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			k8s.NamespacedNameOf(proxyWithInvalidOrCondition): fixture.NewValidCondition().
+				WithError(
+					contour_api_v1.ConditionTypeRouteError,
+					"OrConditionsNotValid",
+					"route: or conditions can't be combined with other conditions in the same condition",
+				),
+		},
+	})
+
 	// Invalid, Regex is in include match condition block
 	proxyRegexIncludeInvalid := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
@@ -11118,3 +12382,112 @@ func routeAcceptedTCPRouteCondition() metav1.Condition {
 		Message: "Accepted TCPRoute",
 	}
 }
+
+func TestDAGStatusOrphanedCondition(t *testing.T) {
+	child := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "teama",
+			Name:      "child",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: "foo",
+					Port: 8080,
+				}},
+			}},
+		},
+	}
+
+	sibling := child.DeepCopy()
+	sibling.Name = "sibling"
+
+	root := func(namespace, name, fqdn string, includes ...contour_api_v1.Include) *contour_api_v1.HTTPProxy {
+		return &contour_api_v1.HTTPProxy{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      name,
+			},
+			Spec: contour_api_v1.HTTPProxySpec{
+				VirtualHost: &contour_api_v1.VirtualHost{
+					Fqdn: fqdn,
+				},
+				Includes: includes,
+			},
+		}
+	}
+
+	tests := map[string]struct {
+		objs        []any
+		wantReason  string
+		wantMessage string
+	}{
+		"no candidate roots": {
+			objs: []any{
+				child,
+				root("roots", "unrelated", "unrelated.example.com"),
+			},
+			wantReason:  "NotIncluded",
+			wantMessage: "this HTTPProxy is not included by any root HTTPProxy",
+		},
+		"roots delegating to the namespace are suggested": {
+			objs: []any{
+				child,
+				sibling,
+				root("roots", "unrelated", "unrelated.example.com"),
+				root("roots", "delegating", "Delegating.example.com", contour_api_v1.Include{
+					Name:      "sibling",
+					Namespace: "teama",
+				}),
+				root("teama", "local", "local.example.com"),
+			},
+			wantReason:  "NotIncluded",
+			wantMessage: "this HTTPProxy is not included by any root HTTPProxy, it could be included by delegating.example.com (roots/delegating), local.example.com (teama/local)",
+		},
+		"referencing HTTPProxies are reported": {
+			objs: []any{
+				child,
+				root("roots", "invalid", "", contour_api_v1.Include{
+					Name:      "child",
+					Namespace: "teama",
+				}),
+			},
+			wantReason:  "IncludeFailed",
+			wantMessage: "this HTTPProxy is referenced by roots/invalid but is not included by it, check the referencing HTTPProxy's status for details",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			builder := Builder{
+				Source: KubernetesCache{
+					RootNamespaces: []string{"roots", "teama"},
+					FieldLogger:    fixture.NewTestLogger(t),
+				},
+				Processors: []Processor{
+					&ListenerProcessor{},
+					&HTTPProxyProcessor{},
+				},
+			}
+			for _, o := range tc.objs {
+				builder.Source.Insert(o)
+			}
+			dag := builder.Build()
+
+			var orphanedCond *contour_api_v1.DetailedCondition
+			for _, pu := range dag.StatusCache.GetProxyUpdates() {
+				if pu.Fullname == k8s.NamespacedNameOf(child) {
+					orphanedCond = pu.Conditions[status.OrphanedCondition]
+				}
+			}
+
+			require.NotNil(t, orphanedCond)
+			assert.Equal(t, contour_api_v1.ConditionTrue, orphanedCond.Status)
+
+			orphanedErr, ok := orphanedCond.GetError(contour_api_v1.ConditionTypeOrphanedError)
+			require.True(t, ok)
+			assert.Equal(t, tc.wantReason, orphanedErr.Reason)
+			assert.Equal(t, tc.wantMessage, orphanedErr.Message)
+		})
+	}
+}
@@ -17,10 +17,12 @@ package debug
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/pprof"
 
 	"github.com/projectcontour/contour/internal/dag"
+	"github.com/projectcontour/contour/internal/featuregate"
 	"github.com/projectcontour/contour/internal/httpsvc"
 )
 
@@ -29,6 +31,11 @@ type Service struct {
 	httpsvc.Service
 
 	Builder *dag.Builder
+
+	FeatureGates *featuregate.Gates
+
+	// Readiness, if set, serves /debug/wait.
+	Readiness ReadinessWaiter
 }
 
 func (svc *Service) NeedLeaderElection() bool {
@@ -40,6 +47,12 @@ func (svc *Service) NeedLeaderElection() bool {
 func (svc *Service) Start(ctx context.Context) error {
 	registerProfile(&svc.ServeMux)
 	registerDotWriter(&svc.ServeMux, svc.Builder)
+	registerDelegatedSecretsWriter(&svc.ServeMux, svc.Builder)
+	registerDependentsWriter(&svc.ServeMux, svc.Builder)
+	registerFeatureGatesWriter(&svc.ServeMux, svc.FeatureGates)
+	if svc.Readiness != nil {
+		registerWaitHandler(&svc.ServeMux, svc.Readiness)
+	}
 	return svc.Service.Start(ctx)
 }
 
@@ -63,3 +76,27 @@ func registerDotWriter(mux *http.ServeMux, builder *dag.Builder) {
 		dw.writeDot(w)
 	})
 }
+
+func registerDelegatedSecretsWriter(mux *http.ServeMux, builder *dag.Builder) {
+	mux.HandleFunc("/debug/delegated-secrets", func(w http.ResponseWriter, r *http.Request) {
+		sw := &delegatedSecretsWriter{
+			Builder: builder,
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := sw.writeDelegatedSecrets(w); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+func registerFeatureGatesWriter(mux *http.ServeMux, gates *featuregate.Gates) {
+	mux.HandleFunc("/debug/feature-gates", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(gates.States()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
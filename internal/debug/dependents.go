@@ -0,0 +1,88 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debug
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+
+	"github.com/projectcontour/contour/internal/dag"
+)
+
+// Dependents is a Secret or Service, written as
+// <kind>/<namespace>/<name>, and the HTTPProxies
+// that refer to it.
+type Dependents struct {
+	Object      string   `json:"object"`
+	HTTPProxies []string `json:"httpproxies"`
+}
+
+type dependentsWriter struct {
+	Builder DagBuilder
+}
+
+// writeDependents writes the dependents of the objects of the DAG,
+// sorted by object, as JSON. If object is not nil, only its dependents
+// are written.
+func (dw *dependentsWriter) writeDependents(w io.Writer, object *dag.Dependency) error {
+	all := []Dependents{}
+	for dep, proxies := range dw.Builder.Build().Dependents {
+		if object != nil && dep != *object {
+			continue
+		}
+		d := Dependents{
+			Object: dep.String(),
+		}
+		for _, proxy := range proxies {
+			d.HTTPProxies = append(d.HTTPProxies, proxy.String())
+		}
+		sort.Strings(d.HTTPProxies)
+		all = append(all, d)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Object < all[j].Object
+	})
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(all)
+}
+
+// registerDependentsWriter registers /debug/dependents, which responds
+// with the HTTPProxies that refer to each Secret and Service, or only
+// to the object given as <kind>/<namespace>/<name> by the "object"
+// query parameter.
+func registerDependentsWriter(mux *http.ServeMux, builder *dag.Builder) {
+	mux.HandleFunc("/debug/dependents", func(w http.ResponseWriter, r *http.Request) {
+		var object *dag.Dependency
+		if o := r.URL.Query().Get("object"); o != "" {
+			dep, err := dag.ParseDependency(o)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			object = &dep
+		}
+
+		dw := &dependentsWriter{
+			Builder: builder,
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := dw.writeDependents(w, object); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
@@ -0,0 +1,65 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debug
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/projectcontour/contour/internal/dag"
+	"github.com/projectcontour/contour/internal/debug/mocks"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestWriteDependents(t *testing.T) {
+	tls := dag.Dependency{
+		Kind:           dag.DependencyKindSecret,
+		NamespacedName: types.NamespacedName{Namespace: "default", Name: "tls"},
+	}
+	d := dag.DAG{
+		Dependents: map[dag.Dependency][]types.NamespacedName{
+			tls: {
+				{Namespace: "teamb", Name: "app"},
+				{Namespace: "teama", Name: "app"},
+			},
+			{Kind: dag.DependencyKindService, NamespacedName: types.NamespacedName{Namespace: "default", Name: "kuard"}}: {
+				{Namespace: "default", Name: "root"},
+			},
+		},
+	}
+	b := mocks.DagBuilder{}
+	b.On("Build").Return(&d)
+
+	dw := &dependentsWriter{
+		Builder: &b,
+	}
+	buf := bytes.Buffer{}
+	require.NoError(t, dw.writeDependents(&buf, nil))
+
+	require.JSONEq(t, `[
+		{"object": "secret/default/tls", "httpproxies": ["teama/app", "teamb/app"]},
+		{"object": "service/default/kuard", "httpproxies": ["default/root"]}
+	]`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, dw.writeDependents(&buf, &tls))
+	require.JSONEq(t, `[
+		{"object": "secret/default/tls", "httpproxies": ["teama/app", "teamb/app"]}
+	]`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, dw.writeDependents(&buf, &dag.Dependency{Kind: dag.DependencyKindSecret, NamespacedName: types.NamespacedName{Namespace: "default", Name: "other"}}))
+	require.JSONEq(t, `[]`, buf.String())
+}
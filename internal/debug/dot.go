@@ -69,6 +69,9 @@ func collectDag(b DagBuilder) (nodeCollection, edgeCollection) {
 				nodes[route] = true
 
 				clusters := route.Clusters
+				if route.AggregateCluster != nil {
+					clusters = append(clusters, route.AggregateCluster.Clusters...)
+				}
 				if route.MirrorPolicy != nil && route.MirrorPolicy.Cluster != nil {
 					clusters = append(clusters, route.MirrorPolicy.Cluster)
 				}
@@ -93,6 +96,9 @@ func collectDag(b DagBuilder) (nodeCollection, edgeCollection) {
 				nodes[route] = true
 
 				clusters := route.Clusters
+				if route.AggregateCluster != nil {
+					clusters = append(clusters, route.AggregateCluster.Clusters...)
+				}
 				if route.MirrorPolicy != nil && route.MirrorPolicy.Cluster != nil {
 					clusters = append(clusters, route.MirrorPolicy.Cluster)
 				}
@@ -0,0 +1,55 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debug
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+)
+
+// delegatedSecret is a TLS Secret that is delegated to
+// HTTPProxies in other namespaces, and the HTTPProxies
+// that use it.
+type delegatedSecret struct {
+	Secret      string   `json:"secret"`
+	HTTPProxies []string `json:"httpproxies"`
+}
+
+type delegatedSecretsWriter struct {
+	Builder DagBuilder
+}
+
+// writeDelegatedSecrets writes the delegated Secrets
+// of the DAG, sorted by name, as JSON.
+func (sw *delegatedSecretsWriter) writeDelegatedSecrets(w io.Writer) error {
+	secrets := []delegatedSecret{}
+	for secret, proxies := range sw.Builder.Build().DelegatedSecrets {
+		ds := delegatedSecret{
+			Secret: secret.String(),
+		}
+		for _, proxy := range proxies {
+			ds.HTTPProxies = append(ds.HTTPProxies, proxy.String())
+		}
+		sort.Strings(ds.HTTPProxies)
+		secrets = append(secrets, ds)
+	}
+	sort.Slice(secrets, func(i, j int) bool {
+		return secrets[i].Secret < secrets[j].Secret
+	})
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(secrets)
+}
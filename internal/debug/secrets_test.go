@@ -0,0 +1,51 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debug
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/projectcontour/contour/internal/dag"
+	"github.com/projectcontour/contour/internal/debug/mocks"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestWriteDelegatedSecrets(t *testing.T) {
+	d := dag.DAG{
+		DelegatedSecrets: map[types.NamespacedName][]types.NamespacedName{
+			{Namespace: "certs", Name: "wildcard"}: {
+				{Namespace: "teamb", Name: "app"},
+				{Namespace: "teama", Name: "app"},
+			},
+			{Namespace: "certs", Name: "apex"}: {
+				{Namespace: "teama", Name: "root"},
+			},
+		},
+	}
+	b := mocks.DagBuilder{}
+	b.On("Build").Return(&d)
+
+	sw := &delegatedSecretsWriter{
+		Builder: &b,
+	}
+	buf := bytes.Buffer{}
+	require.NoError(t, sw.writeDelegatedSecrets(&buf))
+
+	require.JSONEq(t, `[
+		{"secret": "certs/apex", "httpproxies": ["teama/root"]},
+		{"secret": "certs/wildcard", "httpproxies": ["teama/app", "teamb/app"]}
+	]`, buf.String())
+}
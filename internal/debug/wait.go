@@ -0,0 +1,115 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debug
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/projectcontour/contour/internal/contour"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// defaultWaitTimeout is how long /debug/wait waits
+	// if the request has no timeout.
+	defaultWaitTimeout = 30 * time.Second
+
+	// maxWaitTimeout keeps waits within the
+	// write timeout of the debug service.
+	maxWaitTimeout = 4 * time.Minute
+)
+
+// ReadinessWaiter waits for HTTPProxy generations to be programmed
+// into the Envoys.
+type ReadinessWaiter interface {
+	WaitReady(ctx context.Context, proxy types.NamespacedName, generation int64) contour.ProxyReadiness
+}
+
+// waitRequest holds the query parameters of /debug/wait.
+type waitRequest struct {
+	proxy      types.NamespacedName
+	generation int64
+	timeout    time.Duration
+}
+
+func parseWaitRequest(r *http.Request) (*waitRequest, error) {
+	q := r.URL.Query()
+
+	namespace, name, ok := strings.Cut(q.Get("proxy"), "/")
+	if !ok || namespace == "" || name == "" {
+		return nil, fmt.Errorf("invalid proxy %q: must be <namespace>/<name>", q.Get("proxy"))
+	}
+
+	req := &waitRequest{
+		proxy:   types.NamespacedName{Namespace: namespace, Name: name},
+		timeout: defaultWaitTimeout,
+	}
+
+	if g := q.Get("generation"); g != "" {
+		generation, err := strconv.ParseInt(g, 10, 64)
+		if err != nil || generation < 0 {
+			return nil, fmt.Errorf("invalid generation %q", g)
+		}
+		req.generation = generation
+	}
+
+	if t := q.Get("timeout"); t != "" {
+		timeout, err := time.ParseDuration(t)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout %q: %w", t, err)
+		}
+		if timeout <= 0 || timeout > maxWaitTimeout {
+			return nil, fmt.Errorf("invalid timeout %q: must be greater than 0s and at most %s", t, maxWaitTimeout)
+		}
+		req.timeout = timeout
+	}
+
+	return req, nil
+}
+
+// registerWaitHandler registers /debug/wait, which waits until a
+// generation of an HTTPProxy is ready, and responds with its readiness.
+// The status code is 200 if the generation is ready, and 503 if it is
+// invalid or the timeout expired.
+func registerWaitHandler(mux *http.ServeMux, waiter ReadinessWaiter) {
+	mux.HandleFunc("/debug/wait", func(w http.ResponseWriter, r *http.Request) {
+		req, err := parseWaitRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), req.timeout)
+		defer cancel()
+
+		readiness := waiter.WaitReady(ctx, req.proxy, req.generation)
+
+		w.Header().Set("Content-Type", "application/json")
+		if !readiness.Ready {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(readiness); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
@@ -0,0 +1,100 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debug
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/projectcontour/contour/internal/contour"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+type readinessWaiterFunc func(ctx context.Context, proxy types.NamespacedName, generation int64) contour.ProxyReadiness
+
+func (f readinessWaiterFunc) WaitReady(ctx context.Context, proxy types.NamespacedName, generation int64) contour.ProxyReadiness {
+	return f(ctx, proxy, generation)
+}
+
+func TestWaitHandler(t *testing.T) {
+	var gotProxy types.NamespacedName
+	var gotGeneration int64
+	var gotTimeout time.Duration
+
+	mux := http.NewServeMux()
+	registerWaitHandler(mux, readinessWaiterFunc(func(ctx context.Context, proxy types.NamespacedName, generation int64) contour.ProxyReadiness {
+		gotProxy, gotGeneration = proxy, generation
+		if deadline, ok := ctx.Deadline(); ok {
+			gotTimeout = time.Until(deadline).Round(time.Second)
+		}
+		return contour.ProxyReadiness{
+			Proxy:      proxy.String(),
+			Generation: generation,
+			Ready:      proxy.Name == "ready",
+			Nodes:      []contour.NodeReadiness{},
+		}
+	}))
+
+	tests := map[string]struct {
+		query          string
+		wantCode       int
+		wantProxy      types.NamespacedName
+		wantGeneration int64
+		wantTimeout    time.Duration
+	}{
+		"ready": {
+			query:          "proxy=default/ready&generation=3&timeout=1m",
+			wantCode:       http.StatusOK,
+			wantProxy:      types.NamespacedName{Namespace: "default", Name: "ready"},
+			wantGeneration: 3,
+			wantTimeout:    time.Minute,
+		},
+		"not ready": {
+			query:       "proxy=default/pending",
+			wantCode:    http.StatusServiceUnavailable,
+			wantProxy:   types.NamespacedName{Namespace: "default", Name: "pending"},
+			wantTimeout: defaultWaitTimeout,
+		},
+		"missing namespace": {
+			query:    "proxy=ready",
+			wantCode: http.StatusBadRequest,
+		},
+		"invalid generation": {
+			query:    "proxy=default/ready&generation=-1",
+			wantCode: http.StatusBadRequest,
+		},
+		"timeout too long": {
+			query:    "proxy=default/ready&timeout=1h",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			gotProxy, gotGeneration, gotTimeout = types.NamespacedName{}, 0, 0
+
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/wait?"+tc.query, nil))
+
+			assert.Equal(t, tc.wantCode, rec.Code)
+			assert.Equal(t, tc.wantProxy, gotProxy)
+			assert.Equal(t, tc.wantGeneration, gotGeneration)
+			assert.Equal(t, tc.wantTimeout, gotTimeout)
+		})
+	}
+}
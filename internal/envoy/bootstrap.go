@@ -58,6 +58,10 @@ type BootstrapConfig struct {
 	// Defaults to 8001.
 	XDSGRPCPort int
 
+	// XDSTokenFile is the filename that contains a JWT that Envoy presents
+	// to the xDS server in its node metadata to be authorized.
+	XDSTokenFile string
+
 	// XDSResourceVersion defines the XDS Server Version to use.
 	// Defaults to "v3"
 	XDSResourceVersion config.ResourceVersion
@@ -82,6 +86,11 @@ type BootstrapConfig struct {
 	// ResourcesDir is the directory where out of line Envoy resources can be placed.
 	ResourcesDir string
 
+	// StaticResourcesFile is the filename of a YAML document with
+	// clusters, listeners and secrets that are added to the static
+	// resources of the bootstrap configuration.
+	StaticResourcesFile string
+
 	// SkipFilePathCheck specifies whether to skip checking whether files
 	// referenced in the configuration actually exist. This option is for
 	// testing only.
@@ -94,6 +103,27 @@ type BootstrapConfig struct {
 	// MaximumHeapSizeBytes specifies the number of bytes that overload manager allows heap to grow to.
 	// When reaching the set threshold, new connections are denied.
 	MaximumHeapSizeBytes uint64
+
+	// OverloadShrinkHeapThreshold is the fraction of MaximumHeapSizeBytes
+	// at which overload manager shrinks the heap. Defaults to 0.95.
+	OverloadShrinkHeapThreshold float64
+
+	// OverloadStopAcceptingRequestsThreshold is the fraction of
+	// MaximumHeapSizeBytes at which overload manager stops accepting
+	// requests. Defaults to 0.98.
+	OverloadStopAcceptingRequestsThreshold float64
+
+	// HistogramBuckets overrides the default bucket boundaries of the
+	// histograms of the stats with the given name prefixes. The first
+	// matching entry applies.
+	HistogramBuckets []HistogramBuckets
+}
+
+// HistogramBuckets are the bucket boundaries of the histograms of the
+// stats whose names start with Prefix. The prefix "*" matches all stats.
+type HistogramBuckets struct {
+	Prefix  string
+	Buckets []float64
 }
 
 // GetXdsAddress returns the address configured or defaults to "127.0.0.1"
@@ -118,6 +148,66 @@ func (c *BootstrapConfig) GetDNSLookupFamily() string {
 	return stringOrDefault(c.DNSLookupFamily, "auto")
 }
 
+// GetOverloadShrinkHeapThreshold returns the configured shrink heap threshold or defaults to 0.95
+func (c *BootstrapConfig) GetOverloadShrinkHeapThreshold() float64 {
+	return floatOrDefault(c.OverloadShrinkHeapThreshold, 0.95)
+}
+
+// GetOverloadStopAcceptingRequestsThreshold returns the configured stop accepting requests threshold or defaults to 0.98
+func (c *BootstrapConfig) GetOverloadStopAcceptingRequestsThreshold() float64 {
+	return floatOrDefault(c.OverloadStopAcceptingRequestsThreshold, 0.98)
+}
+
+// ValidOverloadManager checks that the overload action thresholds
+// are fractions of the maximum heap size, that the heap is shrunk
+// before requests are denied, and that the thresholds are only set
+// along with the maximum heap size.
+func (c *BootstrapConfig) ValidOverloadManager() error {
+	if c.MaximumHeapSizeBytes == 0 {
+		if c.OverloadShrinkHeapThreshold != 0 || c.OverloadStopAcceptingRequestsThreshold != 0 {
+			return fmt.Errorf("overload action thresholds require the maximum heap size to be set")
+		}
+		return nil
+	}
+
+	shrinkHeap := c.GetOverloadShrinkHeapThreshold()
+	if shrinkHeap < 0 || shrinkHeap > 1 {
+		return fmt.Errorf("invalid shrink heap threshold %v, must be between 0 and 1", shrinkHeap)
+	}
+	stopAcceptingRequests := c.GetOverloadStopAcceptingRequestsThreshold()
+	if stopAcceptingRequests < 0 || stopAcceptingRequests > 1 {
+		return fmt.Errorf("invalid stop accepting requests threshold %v, must be between 0 and 1", stopAcceptingRequests)
+	}
+	if shrinkHeap > stopAcceptingRequests {
+		return fmt.Errorf("shrink heap threshold %v must not be greater than stop accepting requests threshold %v", shrinkHeap, stopAcceptingRequests)
+	}
+
+	return nil
+}
+
+// ValidHistogramBuckets checks that every histogram bucket
+// override has a prefix, and increasing positive boundaries.
+func (c *BootstrapConfig) ValidHistogramBuckets() error {
+	for _, h := range c.HistogramBuckets {
+		if h.Prefix == "" {
+			return fmt.Errorf("histogram buckets require a stat prefix")
+		}
+		if len(h.Buckets) == 0 {
+			return fmt.Errorf("histogram buckets of stat prefix %q must not be empty", h.Prefix)
+		}
+		for i, b := range h.Buckets {
+			if b <= 0 {
+				return fmt.Errorf("invalid histogram bucket %v of stat prefix %q, must be positive", b, h.Prefix)
+			}
+			if i > 0 && b <= h.Buckets[i-1] {
+				return fmt.Errorf("histogram buckets of stat prefix %q must be increasing", h.Prefix)
+			}
+		}
+	}
+
+	return nil
+}
+
 // ValidAdminAddress checks if the address supplied is
 // "localhost" or an IP address. Only a Unix Socket
 // is supported for this address to mitigate security.
@@ -143,6 +233,13 @@ func intOrDefault(i, def int) int {
 	return i
 }
 
+func floatOrDefault(f, def float64) float64 {
+	if f == 0 {
+		return def
+	}
+	return f
+}
+
 func WriteConfig(filename string, config proto.Message) (err error) {
 	var out *os.File
 
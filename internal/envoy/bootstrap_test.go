@@ -40,3 +40,94 @@ func TestValidAdminAddress(t *testing.T) {
 		})
 	}
 }
+
+func TestValidHistogramBuckets(t *testing.T) {
+	tests := map[string]struct {
+		buckets []HistogramBuckets
+		wantErr bool
+	}{
+		"none": {},
+		"valid": {
+			buckets: []HistogramBuckets{
+				{Prefix: "cluster.", Buckets: []float64{50, 100, 250}},
+				{Prefix: "*", Buckets: []float64{0.5, 1}},
+			},
+		},
+		"no prefix": {
+			buckets: []HistogramBuckets{{Buckets: []float64{50}}},
+			wantErr: true,
+		},
+		"no buckets": {
+			buckets: []HistogramBuckets{{Prefix: "cluster."}},
+			wantErr: true,
+		},
+		"zero bucket": {
+			buckets: []HistogramBuckets{{Prefix: "cluster.", Buckets: []float64{0, 50}}},
+			wantErr: true,
+		},
+		"decreasing buckets": {
+			buckets: []HistogramBuckets{{Prefix: "cluster.", Buckets: []float64{100, 50}}},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := BootstrapConfig{HistogramBuckets: tc.buckets}
+			assert.Equal(t, tc.wantErr, c.ValidHistogramBuckets() != nil)
+		})
+	}
+}
+
+func TestValidOverloadManager(t *testing.T) {
+	tests := map[string]struct {
+		config  BootstrapConfig
+		wantErr bool
+	}{
+		"disabled": {
+			config: BootstrapConfig{},
+		},
+		"default thresholds": {
+			config: BootstrapConfig{MaximumHeapSizeBytes: 2147483648},
+		},
+		"custom thresholds": {
+			config: BootstrapConfig{
+				MaximumHeapSizeBytes:                   2147483648,
+				OverloadShrinkHeapThreshold:            0.8,
+				OverloadStopAcceptingRequestsThreshold: 0.9,
+			},
+		},
+		"thresholds without maximum heap size": {
+			config:  BootstrapConfig{OverloadShrinkHeapThreshold: 0.8},
+			wantErr: true,
+		},
+		"shrink heap threshold out of range": {
+			config: BootstrapConfig{
+				MaximumHeapSizeBytes:        2147483648,
+				OverloadShrinkHeapThreshold: -0.5,
+			},
+			wantErr: true,
+		},
+		"stop accepting requests threshold out of range": {
+			config: BootstrapConfig{
+				MaximumHeapSizeBytes:                   2147483648,
+				OverloadStopAcceptingRequestsThreshold: 95,
+			},
+			wantErr: true,
+		},
+		"shrink heap threshold greater than stop accepting requests threshold": {
+			config: BootstrapConfig{
+				MaximumHeapSizeBytes:                   2147483648,
+				OverloadStopAcceptingRequestsThreshold: 0.9,
+			},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.config.ValidOverloadManager()
+			assert.Equal(t, tc.wantErr, err != nil, err)
+		})
+	}
+}
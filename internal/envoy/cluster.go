@@ -54,9 +54,35 @@ func Clustername(cluster *dag.Cluster) string {
 	if !cluster.TimeoutPolicy.IdleConnectionTimeout.UseDefault() {
 		buf += cluster.TimeoutPolicy.IdleConnectionTimeout.Duration().String()
 	}
+	if cluster.TimeoutPolicy.ConnectTimeout > 0 {
+		buf += "connect:" + cluster.TimeoutPolicy.ConnectTimeout.String()
+	}
 	if cluster.SlowStartConfig != nil {
 		buf += cluster.SlowStartConfig.String()
 	}
+	if ka := cluster.HTTP2Keepalive; ka != nil && (cluster.Protocol == "h2" || cluster.Protocol == "h2c") {
+		buf += "keepalive:" + ka.String()
+	}
+	if cluster.MaglevTableSize > 0 {
+		buf += strconv.FormatUint(cluster.MaglevTableSize, 10)
+	}
+	if cluster.ActiveRequestBias != nil {
+		buf += strconv.FormatFloat(*cluster.ActiveRequestBias, 'f', -1, 64)
+	}
+	for _, failover := range cluster.Failover {
+		buf += "/" + failover.Weighted.ServiceName + ":" + strconv.Itoa(int(failover.Weighted.ServicePort.Port))
+	}
+	// Routes to different subsets with the same label keys
+	// share a cluster, and select the subset by metadata.
+	if keys := cluster.SubsetKeys(); len(keys) > 0 {
+		buf += "subset:" + strings.Join(keys, ",")
+	}
+	// The DNS lookup family only applies to ExternalName services,
+	// which can be looked up with a different one on each route.
+	// Leave out auto, which is the default, so that names don't change.
+	if len(service.ExternalName) > 0 && cluster.DNSLookupFamily != "auto" {
+		buf += cluster.DNSLookupFamily
+	}
 
 	// This isn't a crypto hash, we just want a unique name.
 	hash := sha1.Sum([]byte(buf)) // nolint:gosec
@@ -66,6 +92,21 @@ func Clustername(cluster *dag.Cluster) string {
 	return Hashname(60, ns, name, strconv.Itoa(int(service.Weighted.ServicePort.Port)), fmt.Sprintf("%x", hash[:5]))
 }
 
+// AggregateClusterName returns the name of the CDS aggregate cluster,
+// which is derived from the names of its clusters.
+func AggregateClusterName(cluster *dag.AggregateCluster) string {
+	var names []string
+	for _, c := range cluster.Clusters {
+		names = append(names, Clustername(c))
+	}
+
+	// This isn't a crypto hash, we just want a unique name.
+	hash := sha1.Sum([]byte(strings.Join(names, ";"))) // nolint:gosec
+
+	first := cluster.Clusters[0].Upstream.Weighted
+	return Hashname(60, "aggregate", first.ServiceNamespace, first.ServiceName, fmt.Sprintf("%x", hash[:5]))
+}
+
 // AltStatName generates an alternative stat name for the service
 // using format ns_name_port
 func AltStatName(service *dag.Service) string {
@@ -100,6 +141,17 @@ func Hashname(l int, s ...string) string {
 	return hash[:min(len(hash), l)]
 }
 
+// TruncateStatName returns name if its length does not exceed l.
+// Otherwise, the end of name is replaced with a hash derived from
+// name, so that the result is stable and unlikely to collide.
+func TruncateStatName(l int, name string) string {
+	if l >= len(name) {
+		return name
+	}
+	hash := fmt.Sprintf("%x", sha256.Sum256([]byte(name)))
+	return truncate(l, name, hash[:6])
+}
+
 // truncate truncates s to l length by replacing the
 // end of s with -suffix.
 func truncate(l int, s, suffix string) string {
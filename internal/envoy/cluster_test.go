@@ -45,6 +45,21 @@ func TestTruncate(t *testing.T) {
 	}
 }
 
+func TestTruncateStatName(t *testing.T) {
+	assert.Equal(t, "default_kuard_8080", TruncateStatName(60, "default_kuard_8080"))
+	assert.Equal(t, "default_kuard_8080", TruncateStatName(len("default_kuard_8080"), "default_kuard_8080"))
+
+	// Truncated names end in a hash of the whole name, so names
+	// with the same prefix remain distinct.
+	a := TruncateStatName(16, "default_kuard_8080")
+	b := TruncateStatName(16, "default_kuard_8081")
+	assert.Len(t, a, 16)
+	assert.Len(t, b, 16)
+	assert.Equal(t, "default_k-", a[:10])
+	assert.NotEqual(t, a, b)
+	assert.Equal(t, a, TruncateStatName(16, "default_kuard_8080"))
+}
+
 func TestAnyPositive(t *testing.T) {
 	assert.Equal(t, false, AnyPositive(0))
 	assert.Equal(t, true, AnyPositive(1))
@@ -61,6 +61,11 @@ func SingleSimpleCluster(route *dag.Route) bool {
 	}
 
 	cluster := route.Clusters[0]
+	// If the target cluster selects a subset of its endpoints, then
+	// we need to match their metadata on a WeightedCluster.
+	if len(cluster.Subset) > 0 {
+		return false
+	}
 	// If the target cluster performs any kind of header manipulation,
 	// then we should use a WeightedCluster to encode the additional
 	// configuration.
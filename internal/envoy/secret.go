@@ -28,3 +28,24 @@ func Secretname(s *dag.Secret) string {
 	name := s.Name()
 	return Hashname(60, ns, name, fmt.Sprintf("%x", hash[:5]))
 }
+
+// ValidationContextSecretname returns the name of the SDS secret for
+// the downstream validation context pvc. The name depends on the Secrets
+// that pvc refers to, but not on their contents, so that a rotated CA or
+// CRL updates the SDS secret without changing the listeners that use it.
+func ValidationContextSecretname(pvc *dag.PeerValidationContext) string {
+	var ca, crl string
+	if pvc.CACertificate != nil {
+		ca = pvc.CACertificate.Namespace() + "/" + pvc.CACertificate.Name()
+	}
+	if pvc.CRL != nil {
+		crl = pvc.CRL.Namespace() + "/" + pvc.CRL.Name()
+	}
+
+	// This isn't a crypto hash, we just want a unique name.
+	hash := sha1.Sum([]byte(fmt.Sprintf("%s,%s,%t,%t", ca, crl, pvc.SkipClientCertValidation, pvc.OnlyVerifyLeafCertCrl))) // nolint:gosec
+	if pvc.CACertificate == nil {
+		return Hashname(60, "validation", fmt.Sprintf("%x", hash[:5]))
+	}
+	return Hashname(60, pvc.CACertificate.Namespace(), pvc.CACertificate.Name(), "validation", fmt.Sprintf("%x", hash[:5]))
+}
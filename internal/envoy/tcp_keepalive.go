@@ -32,3 +32,13 @@ const (
 	// is defined here for consistency.
 	IPPROTO_TCP = syscall.IPPROTO_TCP
 )
+
+// Linux IP socket options used to mark the packets
+// sent by the listeners.
+// nolint:revive
+const (
+	IPPROTO_IP   = 0x0
+	IP_TOS       = 0x1
+	IPPROTO_IPV6 = 0x29
+	IPV6_TCLASS  = 0x43
+)
@@ -0,0 +1,105 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/projectcontour/contour/internal/dag"
+)
+
+// ABTestHashHeader is the request header that the Lua filter sets to the
+// hash of the A/B test attribute of a request, and that picks the weighted
+// cluster of routes with an A/B test policy instead of a random value.
+const ABTestHashHeader = "x-contour-ab-hash"
+
+var abTestTemplate = template.Must(template.New("abtest").Funcs(template.FuncMap{
+	"lua": luaString,
+}).Parse(`
+function envoy_on_request(request_handle)
+	local headers = request_handle:headers()
+	headers:remove("{{.Header}}")
+
+	local value = nil
+	{{if .Policy.HeaderName}}
+	value = headers:get({{lua .Policy.HeaderName}})
+	{{else}}
+	local cookies = headers:get("cookie")
+	if cookies ~= nil then
+		for name, v in string.gmatch(cookies, "([^=;%s]+)=([^;]*)") do
+			if name == {{lua .Policy.CookieName}} then
+				value = v
+				break
+			end
+		end
+	end
+	{{end}}
+
+	if value ~= nil then
+		-- 32-bit FNV-1a, kept in the range of a Lua number.
+		local h = 2166136261
+		local s = {{lua .Policy.Salt}} .. value
+		for i = 1, string.len(s) do
+			h = bit.bxor(h, string.byte(s, i)) % 4294967296
+			h = (bit.lshift(h, 24) % 4294967296 + h * 403) % 4294967296
+		end
+		headers:add("{{.Header}}", string.format("%.0f", h))
+	end
+
+	-- The route, and with it the weighted cluster, was picked
+	-- before the hash was set, so have Envoy pick it again.
+	request_handle:clearRouteCache()
+end
+`))
+
+// abTestCode returns the Lua code that sets the ABTestHashHeader
+// of requests to the hash of the attribute of the given policy.
+func abTestCode(policy *dag.ABTestPolicy) string {
+	t := new(bytes.Buffer)
+	err := abTestTemplate.Execute(t, struct {
+		Header string
+		Policy *dag.ABTestPolicy
+	}{
+		Header: ABTestHashHeader,
+		Policy: policy,
+	})
+	if err != nil {
+		// If template execution fails, return no code.
+		return ""
+	}
+
+	return t.String()
+}
+
+// luaString returns s as a quoted Lua string literal.
+func luaString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' || c == '"':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 0x20 || c >= 0x7f:
+			fmt.Fprintf(&b, "\\%03d", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,51 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"testing"
+
+	"github.com/projectcontour/contour/internal/dag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestABTestCode(t *testing.T) {
+	header := abTestCode(&dag.ABTestPolicy{HeaderName: "x-user-id", Salt: "checkout"})
+	assert.Contains(t, header, `value = headers:get("x-user-id")`)
+	assert.Contains(t, header, `local s = "checkout" .. value`)
+	assert.Contains(t, header, `headers:add("x-contour-ab-hash", string.format("%.0f", h))`)
+	assert.NotContains(t, header, `headers:get("cookie")`)
+
+	cookie := abTestCode(&dag.ABTestPolicy{CookieName: "session"})
+	assert.Contains(t, cookie, `headers:get("cookie")`)
+	assert.Contains(t, cookie, `if name == "session" then`)
+	assert.Contains(t, cookie, `local s = "" .. value`)
+}
+
+func TestLuaString(t *testing.T) {
+	tests := map[string]string{
+		"":             `""`,
+		"salt":         `"salt"`,
+		`say "hi"`:     `"say \"hi\""`,
+		`back\slash`:   `"back\\slash"`,
+		"new\nline":    `"new\010line"`,
+		"end]]--\x7fx": `"end]]--\127x"`,
+		"caf\xc3\xa9":  `"caf\195\169"`,
+		"\") os.exit(": `"\") os.exit("`,
+	}
+
+	for in, want := range tests {
+		assert.Equal(t, want, luaString(in), "luaString(%q)", in)
+	}
+}
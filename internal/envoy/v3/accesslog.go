@@ -14,28 +14,326 @@
 package v3
 
 import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
 	envoy_accesslog_v3 "github.com/envoyproxy/go-control-plane/envoy/config/accesslog/v3"
 	envoy_config_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
 	envoy_file_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/access_loggers/file/v3"
+	envoy_grpc_als_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/access_loggers/grpc/v3"
 	envoy_req_without_query_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/formatter/req_without_query/v3"
+	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+	envoy_type_v3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
 	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
 	contour_api_v1alpha1 "github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
+	"github.com/projectcontour/contour/internal/dag"
 	"github.com/projectcontour/contour/internal/protobuf"
+	"github.com/projectcontour/contour/internal/timeout"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/structpb"
+	"k8s.io/apimachinery/pkg/types"
 )
 
+// tcpGRPCAccessLog is the name of the access logger that streams
+// the access logs of TCP proxies to a gRPC access log service.
+const tcpGRPCAccessLog = "envoy.access_loggers.tcp_grpc"
+
+// GRPCAccessLogConfig configures the gRPC access log service (ALS)
+// that access logs are streamed to.
+type GRPCAccessLogConfig struct {
+	ExtensionService types.NamespacedName
+	SNI              string
+	Timeout          timeout.Setting
+
+	// LogName is the name that the access log service
+	// uses to tell the access logs of Envoy apart.
+	LogName string
+
+	// BufferSizeBytes is the size of the buffer of access log
+	// entries that Envoy flushes to the access log service
+	// when full. Envoy's default is used if zero.
+	BufferSizeBytes uint32
+
+	// BufferFlushInterval is the interval that Envoy flushes
+	// the buffered access log entries at. Envoy's default is
+	// used if zero.
+	BufferFlushInterval time.Duration
+}
+
+// AccessLogFilterConfig selects the requests that are access logged.
+// A request is logged if it matches any of the conditions that are
+// set, or if it is sampled.
+type AccessLogFilterConfig struct {
+	// MinStatusCode logs the requests whose response status
+	// code is at least the given code, if not zero.
+	MinStatusCode uint32
+
+	// MinDuration logs the requests that take at least
+	// the given duration, if not zero.
+	MinDuration time.Duration
+
+	// RequestHeaders logs the requests that have
+	// any of the given request headers.
+	RequestHeaders []string
+
+	// SamplePercent logs the given percentage of the
+	// requests, chosen at random, if not zero.
+	SamplePercent uint32
+}
+
+// FilterAccessLogs adds the access log filter of config to each of
+// the access logs, in addition to the filter that they already have,
+// and returns them.
+func FilterAccessLogs(logs []*envoy_accesslog_v3.AccessLog, config *AccessLogFilterConfig) []*envoy_accesslog_v3.AccessLog {
+	for _, log := range logs {
+		filter := accessLogFilter(config)
+		if filter == nil {
+			break
+		}
+		addAccessLogFilter(log, filter)
+	}
+
+	return logs
+}
+
+// LogForcedTraces adds a filter to each of the access logs that logs
+// the requests whose client forced their tracing with the
+// x-envoy-force-trace header, in addition to the requests that the
+// access log already logs, and returns them.
+func LogForcedTraces(logs []*envoy_accesslog_v3.AccessLog) []*envoy_accesslog_v3.AccessLog {
+	for _, log := range logs {
+		// An access log without a filter logs all the requests.
+		if log.Filter == nil {
+			continue
+		}
+
+		forced := andFilter([]*envoy_accesslog_v3.AccessLogFilter{{
+			FilterSpecifier: &envoy_accesslog_v3.AccessLogFilter_TraceableFilter{
+				TraceableFilter: &envoy_accesslog_v3.TraceableFilter{},
+			},
+		}, {
+			FilterSpecifier: &envoy_accesslog_v3.AccessLogFilter_HeaderFilter{
+				HeaderFilter: &envoy_accesslog_v3.HeaderFilter{
+					Header: &envoy_route_v3.HeaderMatcher{
+						Name: "x-envoy-force-trace",
+						HeaderMatchSpecifier: &envoy_route_v3.HeaderMatcher_PresentMatch{
+							PresentMatch: true,
+						},
+					},
+				},
+			},
+		}})
+		log.Filter = orFilter([]*envoy_accesslog_v3.AccessLogFilter{log.Filter, forced})
+	}
+
+	return logs
+}
+
+// AuthorityMatch selects the requests whose authority, ignoring the
+// port, is Host but none of Excluded. Host may be a wildcard domain,
+// and Excluded are the domains that it covers but that are served
+// by other virtual hosts.
+type AuthorityMatch struct {
+	Host     string
+	Excluded []string
+}
+
+// FilterAccessLogsByAuthority adds a filter to each of the access
+// logs that logs the requests selected by match, and returns them.
+func FilterAccessLogsByAuthority(logs []*envoy_accesslog_v3.AccessLog, match AuthorityMatch) []*envoy_accesslog_v3.AccessLog {
+	for _, log := range logs {
+		filters := []*envoy_accesslog_v3.AccessLogFilter{authorityFilter(match.Host, false)}
+		for _, host := range match.Excluded {
+			filters = append(filters, authorityFilter(host, true))
+		}
+		addAccessLogFilter(log, andFilter(filters))
+	}
+
+	return logs
+}
+
+// ExcludeAccessLogsByAuthority adds a filter to each of the access
+// logs that logs the requests that none of matches select, and
+// returns them.
+func ExcludeAccessLogsByAuthority(logs []*envoy_accesslog_v3.AccessLog, matches []AuthorityMatch) []*envoy_accesslog_v3.AccessLog {
+	if len(matches) == 0 {
+		return logs
+	}
+
+	for _, log := range logs {
+		var filters []*envoy_accesslog_v3.AccessLogFilter
+		for _, match := range matches {
+			// A request is not selected by match if its authority
+			// isn't the host of match or is one of the excluded.
+			excluded := []*envoy_accesslog_v3.AccessLogFilter{authorityFilter(match.Host, true)}
+			for _, host := range match.Excluded {
+				excluded = append(excluded, authorityFilter(host, false))
+			}
+			filters = append(filters, orFilter(excluded))
+		}
+		addAccessLogFilter(log, andFilter(filters))
+	}
+
+	return logs
+}
+
+// authorityFilter returns the access log filter that selects the
+// requests to host, with any port, or the other requests if invert
+// is true.
+func authorityFilter(host string, invert bool) *envoy_accesslog_v3.AccessLogFilter {
+	regex := regexp.QuoteMeta(host)
+	if strings.HasPrefix(host, "*.") {
+		regex = "[a-z0-9]([-a-z0-9]*[a-z0-9])?" + regexp.QuoteMeta(host[1:])
+	}
+
+	return &envoy_accesslog_v3.AccessLogFilter{
+		FilterSpecifier: &envoy_accesslog_v3.AccessLogFilter_HeaderFilter{
+			HeaderFilter: &envoy_accesslog_v3.HeaderFilter{
+				Header: &envoy_route_v3.HeaderMatcher{
+					// Envoy uses the HTTP/2 ":authority" header
+					// in place of the HTTP/1 "host" header.
+					Name: ":authority",
+					HeaderMatchSpecifier: &envoy_route_v3.HeaderMatcher_StringMatch{
+						StringMatch: &matcher.StringMatcher{
+							MatchPattern: &matcher.StringMatcher_SafeRegex{
+								SafeRegex: SafeRegexMatch("(?i)" + regex + "(:[0-9]+)?"),
+							},
+						},
+					},
+					InvertMatch: invert,
+				},
+			},
+		},
+	}
+}
+
+// addAccessLogFilter adds filter to the filter that log already has.
+func addAccessLogFilter(log *envoy_accesslog_v3.AccessLog, filter *envoy_accesslog_v3.AccessLogFilter) {
+	if log.Filter != nil {
+		filter = andFilter([]*envoy_accesslog_v3.AccessLogFilter{log.Filter, filter})
+	}
+	log.Filter = filter
+}
+
+// andFilter returns the access log filter that
+// selects the requests that all filters select.
+func andFilter(filters []*envoy_accesslog_v3.AccessLogFilter) *envoy_accesslog_v3.AccessLogFilter {
+	if len(filters) == 1 {
+		return filters[0]
+	}
+
+	return &envoy_accesslog_v3.AccessLogFilter{
+		FilterSpecifier: &envoy_accesslog_v3.AccessLogFilter_AndFilter{
+			AndFilter: &envoy_accesslog_v3.AndFilter{
+				Filters: filters,
+			},
+		},
+	}
+}
+
+// orFilter returns the access log filter that
+// selects the requests that any of filters select.
+func orFilter(filters []*envoy_accesslog_v3.AccessLogFilter) *envoy_accesslog_v3.AccessLogFilter {
+	if len(filters) == 1 {
+		return filters[0]
+	}
+
+	return &envoy_accesslog_v3.AccessLogFilter{
+		FilterSpecifier: &envoy_accesslog_v3.AccessLogFilter_OrFilter{
+			OrFilter: &envoy_accesslog_v3.OrFilter{
+				Filters: filters,
+			},
+		},
+	}
+}
+
+// accessLogFilter returns the access log filter that logs the requests
+// selected by config, or nil if config does not select any.
+func accessLogFilter(config *AccessLogFilterConfig) *envoy_accesslog_v3.AccessLogFilter {
+	if config == nil {
+		return nil
+	}
+
+	var filters []*envoy_accesslog_v3.AccessLogFilter
+
+	if config.MinStatusCode > 0 {
+		filters = append(filters, &envoy_accesslog_v3.AccessLogFilter{
+			FilterSpecifier: &envoy_accesslog_v3.AccessLogFilter_StatusCodeFilter{
+				StatusCodeFilter: &envoy_accesslog_v3.StatusCodeFilter{
+					Comparison: &envoy_accesslog_v3.ComparisonFilter{
+						Op: envoy_accesslog_v3.ComparisonFilter_GE,
+						Value: &envoy_config_core_v3.RuntimeUInt32{
+							DefaultValue: config.MinStatusCode,
+							RuntimeKey:   "contour.accesslog.filter.min_status_code",
+						},
+					},
+				},
+			},
+		})
+	}
+
+	if config.MinDuration > 0 {
+		filters = append(filters, &envoy_accesslog_v3.AccessLogFilter{
+			FilterSpecifier: &envoy_accesslog_v3.AccessLogFilter_DurationFilter{
+				DurationFilter: &envoy_accesslog_v3.DurationFilter{
+					Comparison: &envoy_accesslog_v3.ComparisonFilter{
+						Op: envoy_accesslog_v3.ComparisonFilter_GE,
+						Value: &envoy_config_core_v3.RuntimeUInt32{
+							DefaultValue: uint32(config.MinDuration.Milliseconds()),
+							RuntimeKey:   "contour.accesslog.filter.min_duration",
+						},
+					},
+				},
+			},
+		})
+	}
+
+	for _, header := range config.RequestHeaders {
+		filters = append(filters, &envoy_accesslog_v3.AccessLogFilter{
+			FilterSpecifier: &envoy_accesslog_v3.AccessLogFilter_HeaderFilter{
+				HeaderFilter: &envoy_accesslog_v3.HeaderFilter{
+					Header: &envoy_route_v3.HeaderMatcher{
+						Name: header,
+						HeaderMatchSpecifier: &envoy_route_v3.HeaderMatcher_PresentMatch{
+							PresentMatch: true,
+						},
+					},
+				},
+			},
+		})
+	}
+
+	if config.SamplePercent > 0 {
+		filters = append(filters, &envoy_accesslog_v3.AccessLogFilter{
+			FilterSpecifier: &envoy_accesslog_v3.AccessLogFilter_RuntimeFilter{
+				RuntimeFilter: &envoy_accesslog_v3.RuntimeFilter{
+					RuntimeKey: "contour.accesslog.filter.sample_percent",
+					PercentSampled: &envoy_type_v3.FractionalPercent{
+						Numerator:   config.SamplePercent,
+						Denominator: envoy_type_v3.FractionalPercent_HUNDRED,
+					},
+				},
+			},
+		})
+	}
+
+	if len(filters) == 0 {
+		return nil
+	}
+	return orFilter(filters)
+}
+
 // FileAccessLogEnvoy returns a new file based access log filter
 func FileAccessLogEnvoy(path string, format string, extensions []string, level contour_api_v1alpha1.AccessLogLevel) []*envoy_accesslog_v3.AccessLog {
 	if level == contour_api_v1alpha1.LogLevelDisabled {
 		return nil
 	}
 
-	var filter *envoy_accesslog_v3.AccessLogFilter
-	if level == contour_api_v1alpha1.LogLevelError {
-		filter = filterOnlyErrors(300) // We want to log resp status >= 300
-	} else if level == contour_api_v1alpha1.LogLevelCritical {
-		filter = filterOnlyErrors(500) // We want to log resp status >= 500
-	}
+	filter := accessLogLevelFilter(level)
+
 	// Nil by default to defer to Envoy's default log format.
 	var logFormat *envoy_file_v3.FileAccessLog_LogFormat
 
@@ -73,19 +371,15 @@ func FileAccessLogJSON(path string, fields contour_api_v1alpha1.AccessLogJSONFie
 		return nil
 	}
 
-	var filter *envoy_accesslog_v3.AccessLogFilter
-	if level == contour_api_v1alpha1.LogLevelError {
-		filter = filterOnlyErrors(300) // We want to log resp status >= 300
-	} else if level == contour_api_v1alpha1.LogLevelCritical {
-		filter = filterOnlyErrors(500) // We want to log resp status >= 500
-	}
+	filter := accessLogLevelFilter(level)
 
 	jsonformat := &structpb.Struct{
 		Fields: make(map[string]*structpb.Value),
 	}
 
+	fieldTypes := fields.FieldTypes()
 	for k, v := range fields.AsFieldMap() {
-		jsonformat.Fields[k] = sv(v)
+		setJSONField(jsonformat, strings.Split(k, "."), jsonValue(v, fieldTypes[k]))
 	}
 
 	return []*envoy_accesslog_v3.AccessLog{{
@@ -107,6 +401,99 @@ func FileAccessLogJSON(path string, fields contour_api_v1alpha1.AccessLogJSONFie
 	}}
 }
 
+// HTTPGRPCAccessLog returns a new access log filter that streams the
+// access logs of HTTP connection managers to the gRPC access log service.
+func HTTPGRPCAccessLog(config *GRPCAccessLogConfig, level contour_api_v1alpha1.AccessLogLevel) []*envoy_accesslog_v3.AccessLog {
+	if config == nil || level == contour_api_v1alpha1.LogLevelDisabled {
+		return nil
+	}
+
+	return []*envoy_accesslog_v3.AccessLog{{
+		Name: wellknown.HTTPGRPCAccessLog,
+		ConfigType: &envoy_accesslog_v3.AccessLog_TypedConfig{
+			TypedConfig: protobuf.MustMarshalAny(&envoy_grpc_als_v3.HttpGrpcAccessLogConfig{
+				CommonConfig: grpcAccessLogCommonConfig(config),
+			}),
+		},
+		Filter: accessLogLevelFilter(level),
+	}}
+}
+
+// TCPGRPCAccessLog returns a new access log filter that streams the
+// access logs of TCP proxies to the gRPC access log service.
+func TCPGRPCAccessLog(config *GRPCAccessLogConfig, level contour_api_v1alpha1.AccessLogLevel) []*envoy_accesslog_v3.AccessLog {
+	if config == nil || level == contour_api_v1alpha1.LogLevelDisabled {
+		return nil
+	}
+
+	return []*envoy_accesslog_v3.AccessLog{{
+		Name: tcpGRPCAccessLog,
+		ConfigType: &envoy_accesslog_v3.AccessLog_TypedConfig{
+			TypedConfig: protobuf.MustMarshalAny(&envoy_grpc_als_v3.TcpGrpcAccessLogConfig{
+				CommonConfig: grpcAccessLogCommonConfig(config),
+			}),
+		},
+		Filter: accessLogLevelFilter(level),
+	}}
+}
+
+func grpcAccessLogCommonConfig(config *GRPCAccessLogConfig) *envoy_grpc_als_v3.CommonGrpcAccessLogConfig {
+	common := &envoy_grpc_als_v3.CommonGrpcAccessLogConfig{
+		LogName:             config.LogName,
+		GrpcService:         GrpcService(dag.ExtensionClusterName(config.ExtensionService), config.SNI, config.Timeout),
+		TransportApiVersion: envoy_config_core_v3.ApiVersion_V3,
+		BufferSizeBytes:     protobuf.UInt32OrNil(config.BufferSizeBytes),
+	}
+	if config.BufferFlushInterval > 0 {
+		common.BufferFlushInterval = durationpb.New(config.BufferFlushInterval)
+	}
+	return common
+}
+
+// accessLogLevelFilter returns the access log filter that
+// logs only the requests of the given access log level.
+func accessLogLevelFilter(level contour_api_v1alpha1.AccessLogLevel) *envoy_accesslog_v3.AccessLogFilter {
+	switch level {
+	case contour_api_v1alpha1.LogLevelError:
+		return filterOnlyErrors(300) // We want to log resp status >= 300
+	case contour_api_v1alpha1.LogLevelCritical:
+		return filterOnlyErrors(500) // We want to log resp status >= 500
+	default:
+		return nil
+	}
+}
+
+// setJSONField sets the field at the given path of nested
+// JSON objects, creating the objects that do not exist yet.
+func setJSONField(s *structpb.Struct, path []string, value *structpb.Value) {
+	for _, name := range path[:len(path)-1] {
+		nested := s.Fields[name].GetStructValue()
+		if nested == nil {
+			nested = &structpb.Struct{Fields: make(map[string]*structpb.Value)}
+			s.Fields[name] = structpb.NewStructValue(nested)
+		}
+		s = nested
+	}
+	s.Fields[path[len(path)-1]] = value
+}
+
+// jsonValue returns the value of a JSON access log field. Typed literals
+// are logged as their type, while Envoy logs the value of a single
+// command operator with the type of the operator.
+func jsonValue(value string, fieldType contour_api_v1alpha1.AccessLogJSONFieldType) *structpb.Value {
+	switch fieldType {
+	case contour_api_v1alpha1.AccessLogJSONNumber:
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return structpb.NewNumberValue(f)
+		}
+	case contour_api_v1alpha1.AccessLogJSONBool:
+		if b, err := strconv.ParseBool(value); err == nil {
+			return structpb.NewBoolValue(b)
+		}
+	}
+	return sv(value)
+}
+
 func sv(s string) *structpb.Value {
 	return &structpb.Value{
 		Kind: &structpb.Value_StringValue{
@@ -15,16 +15,25 @@ package v3
 
 import (
 	"testing"
+	"time"
 
 	envoy_accesslog_v3 "github.com/envoyproxy/go-control-plane/envoy/config/accesslog/v3"
 	envoy_config_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
 	envoy_file_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/access_loggers/file/v3"
+	envoy_grpc_als_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/access_loggers/grpc/v3"
 	envoy_req_without_query_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/formatter/req_without_query/v3"
+	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+	envoy_type_v3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
 	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
 	contour_api_v1alpha1 "github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
 	"github.com/projectcontour/contour/internal/protobuf"
+	"github.com/projectcontour/contour/internal/timeout"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 func TestFileAccessLog(t *testing.T) {
@@ -169,6 +178,54 @@ func TestJSONFileAccessLog(t *testing.T) {
 			},
 			},
 		},
+		"nested and typed fields": {
+			path: "/dev/stdout",
+			headers: contour_api_v1alpha1.AccessLogJSONFields([]string{
+				"@timestamp",
+				"duration:number",
+				"request.method=%REQ(:METHOD)%",
+				"request.headers.user_agent=%REQ(USER-AGENT)%",
+				"sample.rate:number=0.5",
+				"sampled:bool=true",
+			}),
+			want: []*envoy_accesslog_v3.AccessLog{{
+				Name: wellknown.FileAccessLog,
+				ConfigType: &envoy_accesslog_v3.AccessLog_TypedConfig{
+					TypedConfig: protobuf.MustMarshalAny(&envoy_file_v3.FileAccessLog{
+						Path: "/dev/stdout",
+						AccessLogFormat: &envoy_file_v3.FileAccessLog_LogFormat{
+							LogFormat: &envoy_config_core_v3.SubstitutionFormatString{
+								Format: &envoy_config_core_v3.SubstitutionFormatString_JsonFormat{
+									JsonFormat: &structpb.Struct{
+										Fields: map[string]*structpb.Value{
+											"@timestamp": sv("%START_TIME%"),
+											"duration":   sv("%DURATION%"),
+											"request": structpb.NewStructValue(&structpb.Struct{
+												Fields: map[string]*structpb.Value{
+													"method": sv("%REQ(:METHOD)%"),
+													"headers": structpb.NewStructValue(&structpb.Struct{
+														Fields: map[string]*structpb.Value{
+															"user_agent": sv("%REQ(USER-AGENT)%"),
+														},
+													}),
+												},
+											}),
+											"sample": structpb.NewStructValue(&structpb.Struct{
+												Fields: map[string]*structpb.Value{
+													"rate": structpb.NewNumberValue(0.5),
+												},
+											}),
+											"sampled": structpb.NewBoolValue(true),
+										},
+									},
+								},
+							},
+						},
+					}),
+				},
+			},
+			},
+		},
 	}
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -283,3 +340,315 @@ func TestAccessLogLevel(t *testing.T) {
 	// Log level disabled should return nil.
 	assert.Nil(t, FileAccessLogJSON("/dev/stdout", nil, nil, contour_api_v1alpha1.LogLevelDisabled))
 }
+
+func TestGRPCAccessLog(t *testing.T) {
+	config := &GRPCAccessLogConfig{
+		ExtensionService:    types.NamespacedName{Namespace: "projectcontour", Name: "als"},
+		Timeout:             timeout.DurationSetting(5 * time.Second),
+		LogName:             "contour",
+		BufferSizeBytes:     32768,
+		BufferFlushInterval: 2 * time.Second,
+	}
+
+	common := &envoy_grpc_als_v3.CommonGrpcAccessLogConfig{
+		LogName: "contour",
+		GrpcService: &envoy_config_core_v3.GrpcService{
+			TargetSpecifier: &envoy_config_core_v3.GrpcService_EnvoyGrpc_{
+				EnvoyGrpc: &envoy_config_core_v3.GrpcService_EnvoyGrpc{
+					ClusterName: "extension/projectcontour/als",
+					Authority:   "extension.projectcontour.als",
+				},
+			},
+			Timeout: durationpb.New(5 * time.Second),
+		},
+		TransportApiVersion: envoy_config_core_v3.ApiVersion_V3,
+		BufferSizeBytes:     wrapperspb.UInt32(32768),
+		BufferFlushInterval: durationpb.New(2 * time.Second),
+	}
+
+	protobuf.ExpectEqual(t, []*envoy_accesslog_v3.AccessLog{{
+		Name: wellknown.HTTPGRPCAccessLog,
+		ConfigType: &envoy_accesslog_v3.AccessLog_TypedConfig{
+			TypedConfig: protobuf.MustMarshalAny(&envoy_grpc_als_v3.HttpGrpcAccessLogConfig{
+				CommonConfig: common,
+			}),
+		},
+	}}, HTTPGRPCAccessLog(config, contour_api_v1alpha1.LogLevelInfo))
+
+	protobuf.ExpectEqual(t, []*envoy_accesslog_v3.AccessLog{{
+		Name: "envoy.access_loggers.tcp_grpc",
+		ConfigType: &envoy_accesslog_v3.AccessLog_TypedConfig{
+			TypedConfig: protobuf.MustMarshalAny(&envoy_grpc_als_v3.TcpGrpcAccessLogConfig{
+				CommonConfig: common,
+			}),
+		},
+		Filter: filterOnlyErrors(500),
+	}}, TCPGRPCAccessLog(config, contour_api_v1alpha1.LogLevelCritical))
+
+	// No access log service or log level disabled should return nil.
+	assert.Nil(t, HTTPGRPCAccessLog(nil, contour_api_v1alpha1.LogLevelInfo))
+	assert.Nil(t, TCPGRPCAccessLog(config, contour_api_v1alpha1.LogLevelDisabled))
+}
+
+func TestFilterAccessLogs(t *testing.T) {
+	statusCodeFilter := &envoy_accesslog_v3.AccessLogFilter{
+		FilterSpecifier: &envoy_accesslog_v3.AccessLogFilter_StatusCodeFilter{
+			StatusCodeFilter: &envoy_accesslog_v3.StatusCodeFilter{
+				Comparison: &envoy_accesslog_v3.ComparisonFilter{
+					Op: envoy_accesslog_v3.ComparisonFilter_GE,
+					Value: &envoy_config_core_v3.RuntimeUInt32{
+						DefaultValue: 400,
+						RuntimeKey:   "contour.accesslog.filter.min_status_code",
+					},
+				},
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		level  contour_api_v1alpha1.AccessLogLevel
+		config *AccessLogFilterConfig
+		want   *envoy_accesslog_v3.AccessLogFilter
+	}{
+		"no filter": {
+			level: contour_api_v1alpha1.LogLevelInfo,
+			want:  nil,
+		},
+		"empty filter": {
+			level:  contour_api_v1alpha1.LogLevelInfo,
+			config: &AccessLogFilterConfig{},
+			want:   nil,
+		},
+		"single condition": {
+			level: contour_api_v1alpha1.LogLevelInfo,
+			config: &AccessLogFilterConfig{
+				MinStatusCode: 400,
+			},
+			want: statusCodeFilter,
+		},
+		"all conditions and access log level": {
+			level: contour_api_v1alpha1.LogLevelCritical,
+			config: &AccessLogFilterConfig{
+				MinStatusCode:  400,
+				MinDuration:    1500 * time.Millisecond,
+				RequestHeaders: []string{"x-debug"},
+				SamplePercent:  10,
+			},
+			want: &envoy_accesslog_v3.AccessLogFilter{
+				FilterSpecifier: &envoy_accesslog_v3.AccessLogFilter_AndFilter{
+					AndFilter: &envoy_accesslog_v3.AndFilter{
+						Filters: []*envoy_accesslog_v3.AccessLogFilter{
+							filterOnlyErrors(500),
+							{
+								FilterSpecifier: &envoy_accesslog_v3.AccessLogFilter_OrFilter{
+									OrFilter: &envoy_accesslog_v3.OrFilter{
+										Filters: []*envoy_accesslog_v3.AccessLogFilter{
+											statusCodeFilter,
+											{
+												FilterSpecifier: &envoy_accesslog_v3.AccessLogFilter_DurationFilter{
+													DurationFilter: &envoy_accesslog_v3.DurationFilter{
+														Comparison: &envoy_accesslog_v3.ComparisonFilter{
+															Op: envoy_accesslog_v3.ComparisonFilter_GE,
+															Value: &envoy_config_core_v3.RuntimeUInt32{
+																DefaultValue: 1500,
+																RuntimeKey:   "contour.accesslog.filter.min_duration",
+															},
+														},
+													},
+												},
+											},
+											{
+												FilterSpecifier: &envoy_accesslog_v3.AccessLogFilter_HeaderFilter{
+													HeaderFilter: &envoy_accesslog_v3.HeaderFilter{
+														Header: &envoy_route_v3.HeaderMatcher{
+															Name: "x-debug",
+															HeaderMatchSpecifier: &envoy_route_v3.HeaderMatcher_PresentMatch{
+																PresentMatch: true,
+															},
+														},
+													},
+												},
+											},
+											{
+												FilterSpecifier: &envoy_accesslog_v3.AccessLogFilter_RuntimeFilter{
+													RuntimeFilter: &envoy_accesslog_v3.RuntimeFilter{
+														RuntimeKey: "contour.accesslog.filter.sample_percent",
+														PercentSampled: &envoy_type_v3.FractionalPercent{
+															Numerator:   10,
+															Denominator: envoy_type_v3.FractionalPercent_HUNDRED,
+														},
+													},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := FilterAccessLogs(FileAccessLogEnvoy("/dev/stdout", "", nil, tc.level), tc.config)
+			want := []*envoy_accesslog_v3.AccessLog{{
+				Name: wellknown.FileAccessLog,
+				ConfigType: &envoy_accesslog_v3.AccessLog_TypedConfig{
+					TypedConfig: protobuf.MustMarshalAny(&envoy_file_v3.FileAccessLog{
+						Path: "/dev/stdout",
+					}),
+				},
+				Filter: tc.want,
+			}}
+			protobuf.ExpectEqual(t, want, got)
+		})
+	}
+}
+
+func TestFilterAccessLogsByAuthority(t *testing.T) {
+	authority := func(regex string, invert bool) *envoy_accesslog_v3.AccessLogFilter {
+		return &envoy_accesslog_v3.AccessLogFilter{
+			FilterSpecifier: &envoy_accesslog_v3.AccessLogFilter_HeaderFilter{
+				HeaderFilter: &envoy_accesslog_v3.HeaderFilter{
+					Header: &envoy_route_v3.HeaderMatcher{
+						Name: ":authority",
+						HeaderMatchSpecifier: &envoy_route_v3.HeaderMatcher_StringMatch{
+							StringMatch: &matcher.StringMatcher{
+								MatchPattern: &matcher.StringMatcher_SafeRegex{
+									SafeRegex: SafeRegexMatch(regex),
+								},
+							},
+						},
+						InvertMatch: invert,
+					},
+				},
+			},
+		}
+	}
+	and := func(filters ...*envoy_accesslog_v3.AccessLogFilter) *envoy_accesslog_v3.AccessLogFilter {
+		return &envoy_accesslog_v3.AccessLogFilter{
+			FilterSpecifier: &envoy_accesslog_v3.AccessLogFilter_AndFilter{
+				AndFilter: &envoy_accesslog_v3.AndFilter{Filters: filters},
+			},
+		}
+	}
+	or := func(filters ...*envoy_accesslog_v3.AccessLogFilter) *envoy_accesslog_v3.AccessLogFilter {
+		return &envoy_accesslog_v3.AccessLogFilter{
+			FilterSpecifier: &envoy_accesslog_v3.AccessLogFilter_OrFilter{
+				OrFilter: &envoy_accesslog_v3.OrFilter{Filters: filters},
+			},
+		}
+	}
+	accessLog := func(filter *envoy_accesslog_v3.AccessLogFilter) []*envoy_accesslog_v3.AccessLog {
+		return []*envoy_accesslog_v3.AccessLog{{
+			Name: wellknown.FileAccessLog,
+			ConfigType: &envoy_accesslog_v3.AccessLog_TypedConfig{
+				TypedConfig: protobuf.MustMarshalAny(&envoy_file_v3.FileAccessLog{
+					Path: "/dev/stdout",
+				}),
+			},
+			Filter: filter,
+		}}
+	}
+
+	protobuf.ExpectEqual(t,
+		accessLog(authority(`(?i)www\.example\.com(:[0-9]+)?`, false)),
+		FilterAccessLogsByAuthority(
+			FileAccessLogEnvoy("/dev/stdout", "", nil, contour_api_v1alpha1.LogLevelInfo),
+			AuthorityMatch{Host: "www.example.com"},
+		),
+	)
+
+	protobuf.ExpectEqual(t,
+		accessLog(and(
+			filterOnlyErrors(300),
+			and(
+				authority(`(?i)[a-z0-9]([-a-z0-9]*[a-z0-9])?\.example\.com(:[0-9]+)?`, false),
+				authority(`(?i)www\.example\.com(:[0-9]+)?`, true),
+			),
+		)),
+		FilterAccessLogsByAuthority(
+			FileAccessLogEnvoy("/dev/stdout", "", nil, contour_api_v1alpha1.LogLevelError),
+			AuthorityMatch{Host: "*.example.com", Excluded: []string{"www.example.com"}},
+		),
+	)
+
+	protobuf.ExpectEqual(t,
+		accessLog(nil),
+		ExcludeAccessLogsByAuthority(
+			FileAccessLogEnvoy("/dev/stdout", "", nil, contour_api_v1alpha1.LogLevelInfo),
+			nil,
+		),
+	)
+
+	protobuf.ExpectEqual(t,
+		accessLog(and(
+			authority(`(?i)foo\.com(:[0-9]+)?`, true),
+			or(
+				authority(`(?i)[a-z0-9]([-a-z0-9]*[a-z0-9])?\.example\.com(:[0-9]+)?`, true),
+				authority(`(?i)www\.example\.com(:[0-9]+)?`, false),
+			),
+		)),
+		ExcludeAccessLogsByAuthority(
+			FileAccessLogEnvoy("/dev/stdout", "", nil, contour_api_v1alpha1.LogLevelInfo),
+			[]AuthorityMatch{
+				{Host: "foo.com"},
+				{Host: "*.example.com", Excluded: []string{"www.example.com"}},
+			},
+		),
+	)
+}
+
+func TestLogForcedTraces(t *testing.T) {
+	protobuf.ExpectEqual(t,
+		FileAccessLogEnvoy("/dev/stdout", "", nil, contour_api_v1alpha1.LogLevelInfo),
+		LogForcedTraces(FileAccessLogEnvoy("/dev/stdout", "", nil, contour_api_v1alpha1.LogLevelInfo)),
+	)
+
+	want := []*envoy_accesslog_v3.AccessLog{{
+		Name: wellknown.FileAccessLog,
+		ConfigType: &envoy_accesslog_v3.AccessLog_TypedConfig{
+			TypedConfig: protobuf.MustMarshalAny(&envoy_file_v3.FileAccessLog{
+				Path: "/dev/stdout",
+			}),
+		},
+		Filter: &envoy_accesslog_v3.AccessLogFilter{
+			FilterSpecifier: &envoy_accesslog_v3.AccessLogFilter_OrFilter{
+				OrFilter: &envoy_accesslog_v3.OrFilter{
+					Filters: []*envoy_accesslog_v3.AccessLogFilter{
+						filterOnlyErrors(500),
+						{
+							FilterSpecifier: &envoy_accesslog_v3.AccessLogFilter_AndFilter{
+								AndFilter: &envoy_accesslog_v3.AndFilter{
+									Filters: []*envoy_accesslog_v3.AccessLogFilter{
+										{
+											FilterSpecifier: &envoy_accesslog_v3.AccessLogFilter_TraceableFilter{
+												TraceableFilter: &envoy_accesslog_v3.TraceableFilter{},
+											},
+										},
+										{
+											FilterSpecifier: &envoy_accesslog_v3.AccessLogFilter_HeaderFilter{
+												HeaderFilter: &envoy_accesslog_v3.HeaderFilter{
+													Header: &envoy_route_v3.HeaderMatcher{
+														Name: "x-envoy-force-trace",
+														HeaderMatchSpecifier: &envoy_route_v3.HeaderMatcher_PresentMatch{
+															PresentMatch: true,
+														},
+													},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}}
+	protobuf.ExpectEqual(t, want, LogForcedTraces(FileAccessLogEnvoy("/dev/stdout", "", nil, contour_api_v1alpha1.LogLevelCritical)))
+}
@@ -59,7 +59,6 @@ func UpstreamTLSContext(peerValidationContext *dag.PeerValidationContext, sni st
 	return context
 }
 
-// TODO: update this for SDS (CommonTlsContext_ValidationContextSdsSecretConfig) instead of inlining it.
 func validationContext(ca []byte, subjectName string, skipVerifyPeerCert bool, crl []byte, onlyVerifyLeafCertCrl bool) *envoy_v3_tls.CommonTlsContext_ValidationContext {
 	vc := &envoy_v3_tls.CommonTlsContext_ValidationContext{
 		ValidationContext: &envoy_v3_tls.CertificateValidationContext{
@@ -104,8 +103,25 @@ func validationContext(ca []byte, subjectName string, skipVerifyPeerCert bool, c
 	return vc
 }
 
-// DownstreamTLSContext creates a new DownstreamTlsContext.
-func DownstreamTLSContext(serverSecret *dag.Secret, tlsMinProtoVersion envoy_v3_tls.TlsParameters_TlsProtocol, cipherSuites []string, peerValidationContext *dag.PeerValidationContext, alpnProtos ...string) *envoy_v3_tls.DownstreamTlsContext {
+// ValidationContextSecret creates the SDS secret of the downstream
+// validation context pvc, so that rotating its CA or CRL only updates
+// the secret instead of the listeners that use it.
+func ValidationContextSecret(pvc *dag.PeerValidationContext) *envoy_v3_tls.Secret {
+	vc := validationContext(pvc.GetCACertificate(), "", pvc.SkipClientCertValidation, pvc.GetCRL(), pvc.OnlyVerifyLeafCertCrl)
+
+	return &envoy_v3_tls.Secret{
+		Name: envoy.ValidationContextSecretname(pvc),
+		Type: &envoy_v3_tls.Secret_ValidationContext{
+			ValidationContext: vc.ValidationContext,
+		},
+	}
+}
+
+// DownstreamTLSContext creates a new DownstreamTlsContext that
+// fetches its certificate with serverSecret, and its validation
+// context, if any, from the ValidationContextSecret of
+// peerValidationContext.
+func DownstreamTLSContext(serverSecret *envoy_v3_tls.SdsSecretConfig, tlsMinProtoVersion envoy_v3_tls.TlsParameters_TlsProtocol, cipherSuites []string, peerValidationContext *dag.PeerValidationContext, alpnProtos ...string) *envoy_v3_tls.DownstreamTlsContext {
 	context := &envoy_v3_tls.DownstreamTlsContext{
 		CommonTlsContext: &envoy_v3_tls.CommonTlsContext{
 			TlsParams: &envoy_v3_tls.TlsParameters{
@@ -113,20 +129,18 @@ func DownstreamTLSContext(serverSecret *dag.Secret, tlsMinProtoVersion envoy_v3_
 				TlsMaximumProtocolVersion: envoy_v3_tls.TlsParameters_TLSv1_3,
 				CipherSuites:              cipherSuites,
 			},
-			TlsCertificateSdsSecretConfigs: []*envoy_v3_tls.SdsSecretConfig{{
-				Name:      envoy.Secretname(serverSecret),
-				SdsConfig: ConfigSource("contour"),
-			}},
-			AlpnProtocols: alpnProtos,
+			TlsCertificateSdsSecretConfigs: []*envoy_v3_tls.SdsSecretConfig{serverSecret},
+			AlpnProtocols:                  alpnProtos,
 		},
 	}
 	if peerValidationContext != nil {
-		vc := validationContext(peerValidationContext.GetCACertificate(), "", peerValidationContext.SkipClientCertValidation,
-			peerValidationContext.GetCRL(), peerValidationContext.OnlyVerifyLeafCertCrl)
-		if vc != nil {
-			context.CommonTlsContext.ValidationContextType = vc
-			context.RequireClientCertificate = wrapperspb.Bool(!peerValidationContext.OptionalClientCertificate)
+		context.CommonTlsContext.ValidationContextType = &envoy_v3_tls.CommonTlsContext_ValidationContextSdsSecretConfig{
+			ValidationContextSdsSecretConfig: &envoy_v3_tls.SdsSecretConfig{
+				Name:      envoy.ValidationContextSecretname(peerValidationContext),
+				SdsConfig: ConfigSource("contour"),
+			},
 		}
+		context.RequireClientCertificate = wrapperspb.Bool(!peerValidationContext.OptionalClientCertificate)
 	}
 
 	return context
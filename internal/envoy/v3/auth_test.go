@@ -20,6 +20,7 @@ import (
 	envoy_v3_tls "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
 	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
 	"github.com/projectcontour/contour/internal/dag"
+	"github.com/projectcontour/contour/internal/envoy"
 	"github.com/projectcontour/contour/internal/protobuf"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -117,3 +118,85 @@ func TestUpstreamTLSContext(t *testing.T) {
 		})
 	}
 }
+
+func TestValidationContextSecret(t *testing.T) {
+	ca := &dag.Secret{
+		Object: &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "ca",
+				Namespace: "default",
+			},
+			Data: map[string][]byte{
+				dag.CACertificateKey: []byte("ca"),
+			},
+		},
+	}
+	crl := &dag.Secret{
+		Object: &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "crl",
+				Namespace: "default",
+			},
+			Data: map[string][]byte{
+				dag.CRLKey: []byte("crl"),
+			},
+		},
+	}
+	trustedCA := &envoy_api_v3_core.DataSource{
+		Specifier: &envoy_api_v3_core.DataSource_InlineBytes{
+			InlineBytes: []byte("ca"),
+		},
+	}
+
+	tests := map[string]struct {
+		validation *dag.PeerValidationContext
+		want       *envoy_v3_tls.CertificateValidationContext
+	}{
+		"ca": {
+			validation: &dag.PeerValidationContext{
+				CACertificate: ca,
+				// Downstream validation doesn't support subject names.
+				SubjectName: "client",
+			},
+			want: &envoy_v3_tls.CertificateValidationContext{
+				TrustedCa: trustedCA,
+			},
+		},
+		"skip client cert validation": {
+			validation: &dag.PeerValidationContext{
+				SkipClientCertValidation: true,
+			},
+			want: &envoy_v3_tls.CertificateValidationContext{
+				TrustChainVerification: envoy_v3_tls.CertificateValidationContext_ACCEPT_UNTRUSTED,
+			},
+		},
+		"ca and crl for the leaf certificate": {
+			validation: &dag.PeerValidationContext{
+				CACertificate:         ca,
+				CRL:                   crl,
+				OnlyVerifyLeafCertCrl: true,
+			},
+			want: &envoy_v3_tls.CertificateValidationContext{
+				TrustedCa: trustedCA,
+				Crl: &envoy_api_v3_core.DataSource{
+					Specifier: &envoy_api_v3_core.DataSource_InlineBytes{
+						InlineBytes: []byte("crl"),
+					},
+				},
+				OnlyVerifyLeafCertCrl: true,
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := ValidationContextSecret(tc.validation)
+			protobuf.ExpectEqual(t, &envoy_v3_tls.Secret{
+				Name: envoy.ValidationContextSecretname(tc.validation),
+				Type: &envoy_v3_tls.Secret_ValidationContext{
+					ValidationContext: tc.want,
+				},
+			}, got)
+		})
+	}
+}
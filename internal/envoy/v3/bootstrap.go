@@ -29,6 +29,7 @@ import (
 	envoy_cluster_v3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
 	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoy_endpoint_v3 "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
+	envoy_config_metrics_v3 "github.com/envoyproxy/go-control-plane/envoy/config/metrics/v3"
 	envoy_config_overload_v3 "github.com/envoyproxy/go-control-plane/envoy/config/overload/v3"
 	envoy_file_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/access_loggers/file/v3"
 	envoy_regex_engines_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/regex_engines/v3"
@@ -39,10 +40,14 @@ import (
 	"github.com/projectcontour/contour/internal/envoy"
 	"github.com/projectcontour/contour/internal/protobuf"
 	"github.com/projectcontour/contour/internal/timeout"
+	"github.com/projectcontour/contour/internal/xds"
+	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
 	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
 	"google.golang.org/protobuf/types/known/wrapperspb"
+	"sigs.k8s.io/yaml"
 )
 
 // WriteBootstrap writes bootstrap configuration to files.
@@ -79,10 +84,20 @@ type bootstrapf func(*envoy.BootstrapConfig) (string, proto.Message)
 func bootstrap(c *envoy.BootstrapConfig) ([]bootstrapf, error) {
 	var steps []bootstrapf
 
+	xdsToken, err := readXDSToken(c)
+	if err != nil {
+		return nil, err
+	}
+
+	staticResources, err := readStaticResources(c)
+	if err != nil {
+		return nil, err
+	}
+
 	if c.GrpcClientCert == "" && c.GrpcClientKey == "" && c.GrpcCABundle == "" {
 		steps = append(steps,
 			func(*envoy.BootstrapConfig) (string, proto.Message) {
-				return c.Path, bootstrapConfig(c)
+				return c.Path, bootstrapConfig(c, xdsToken, staticResources)
 			})
 
 		return steps, nil
@@ -121,7 +136,7 @@ func bootstrap(c *envoy.BootstrapConfig) ([]bootstrapf, error) {
 
 		steps = append(steps,
 			func(*envoy.BootstrapConfig) (string, proto.Message) {
-				b := bootstrapConfig(c)
+				b := bootstrapConfig(c, xdsToken, staticResources)
 				b.StaticResources.Clusters[0].TransportSocket = UpstreamTLSTransportSocket(
 					upstreamFileTLSContext(c))
 				return c.Path, b
@@ -149,7 +164,7 @@ func bootstrap(c *envoy.BootstrapConfig) ([]bootstrapf, error) {
 			return sdsValidationContextPath, validationContextSdsSecretConfig(c)
 		},
 		func(*envoy.BootstrapConfig) (string, proto.Message) {
-			b := bootstrapConfig(c)
+			b := bootstrapConfig(c, xdsToken, staticResources)
 			b.StaticResources.Clusters[0].TransportSocket = UpstreamTLSTransportSocket(
 				upstreamSdsTLSContext(sdsTLSCertificatePath, sdsValidationContextPath))
 			return c.Path, b
@@ -159,7 +174,63 @@ func bootstrap(c *envoy.BootstrapConfig) ([]bootstrapf, error) {
 	return steps, nil
 }
 
-func bootstrapConfig(c *envoy.BootstrapConfig) *envoy_bootstrap_v3.Bootstrap {
+// readXDSToken returns the token that Envoy presents to the
+// xDS server, or an empty string if none is configured.
+func readXDSToken(c *envoy.BootstrapConfig) (string, error) {
+	if c.XDSTokenFile == "" {
+		return "", nil
+	}
+
+	data, err := os.ReadFile(c.XDSTokenFile)
+	if err != nil {
+		return "", err
+	}
+
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("%q is empty", c.XDSTokenFile)
+	}
+
+	return token, nil
+}
+
+// readStaticResources returns the static resources that are merged
+// into the bootstrap configuration, or nil if none are configured.
+func readStaticResources(c *envoy.BootstrapConfig) (*envoy_bootstrap_v3.Bootstrap_StaticResources, error) {
+	if c.StaticResourcesFile == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(c.StaticResourcesFile)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err = yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %q: %w", c.StaticResourcesFile, err)
+	}
+
+	resources := &envoy_bootstrap_v3.Bootstrap_StaticResources{}
+	if err := protojson.Unmarshal(data, resources); err != nil {
+		return nil, fmt.Errorf("failed to parse %q: %w", c.StaticResourcesFile, err)
+	}
+	if err := resources.ValidateAll(); err != nil {
+		return nil, fmt.Errorf("invalid static resources in %q: %w", c.StaticResourcesFile, err)
+	}
+
+	// The bootstrap configuration already has
+	// clusters with these names.
+	for _, cluster := range resources.Clusters {
+		if cluster.Name == "contour" || cluster.Name == "envoy-admin" {
+			return nil, fmt.Errorf("invalid static resources in %q: cluster name %q is reserved", c.StaticResourcesFile, cluster.Name)
+		}
+	}
+
+	return resources, nil
+}
+
+func bootstrapConfig(c *envoy.BootstrapConfig, xdsToken string, staticResources *envoy_bootstrap_v3.Bootstrap_StaticResources) *envoy_bootstrap_v3.Bootstrap {
 	bootstrap := &envoy_bootstrap_v3.Bootstrap{
 		LayeredRuntime: &envoy_bootstrap_v3.LayeredRuntime{
 			Layers: []*envoy_bootstrap_v3.RuntimeLayer{
@@ -216,7 +287,7 @@ func bootstrapConfig(c *envoy.BootstrapConfig) *envoy_bootstrap_v3.Bootstrap {
 						KeepaliveInterval: wrapperspb.UInt32(5),
 					},
 				},
-				TypedExtensionProtocolOptions: protocolOptions(HTTPVersion2, timeout.DefaultSetting(), nil),
+				TypedExtensionProtocolOptions: protocolOptions(HTTPVersion2, timeout.DefaultSetting(), nil, nil, nil),
 				CircuitBreakers: &envoy_cluster_v3.CircuitBreakers{
 					Thresholds: []*envoy_cluster_v3.CircuitBreakers_Thresholds{{
 						Priority:           envoy_core_v3.RoutingPriority_HIGH,
@@ -255,6 +326,27 @@ func bootstrapConfig(c *envoy.BootstrapConfig) *envoy_bootstrap_v3.Bootstrap {
 			Address:   UnixSocketAddress(c.GetAdminAddress(), c.GetAdminPort()),
 		},
 	}
+	if staticResources != nil {
+		bootstrap.StaticResources.Clusters = append(bootstrap.StaticResources.Clusters, staticResources.Clusters...)
+		bootstrap.StaticResources.Listeners = staticResources.Listeners
+		bootstrap.StaticResources.Secrets = staticResources.Secrets
+	}
+	if xdsToken != "" {
+		// The xDS server reads the token from the node metadata,
+		// which Envoy sends on every xDS stream it opens.
+		bootstrap.Node = &envoy_core_v3.Node{
+			Metadata: &structpb.Struct{
+				Fields: map[string]*structpb.Value{
+					xds.NodeTokenMetadataKey: structpb.NewStringValue(xdsToken),
+				},
+			},
+		}
+	}
+	if len(c.HistogramBuckets) > 0 {
+		bootstrap.StatsConfig = &envoy_config_metrics_v3.StatsConfig{
+			HistogramBucketSettings: histogramBucketSettings(c.HistogramBuckets),
+		}
+	}
 	if c.MaximumHeapSizeBytes > 0 {
 		bootstrap.OverloadManager = &envoy_config_overload_v3.OverloadManager{
 			RefreshInterval: durationpb.New(250 * time.Millisecond),
@@ -277,7 +369,7 @@ func bootstrapConfig(c *envoy.BootstrapConfig) *envoy_bootstrap_v3.Bootstrap {
 							Name: "envoy.resource_monitors.fixed_heap",
 							TriggerOneof: &envoy_config_overload_v3.Trigger_Threshold{
 								Threshold: &envoy_config_overload_v3.ThresholdTrigger{
-									Value: 0.95,
+									Value: c.GetOverloadShrinkHeapThreshold(),
 								},
 							},
 						},
@@ -290,7 +382,7 @@ func bootstrapConfig(c *envoy.BootstrapConfig) *envoy_bootstrap_v3.Bootstrap {
 							Name: "envoy.resource_monitors.fixed_heap",
 							TriggerOneof: &envoy_config_overload_v3.Trigger_Threshold{
 								Threshold: &envoy_config_overload_v3.ThresholdTrigger{
-									Value: 0.98,
+									Value: c.GetOverloadStopAcceptingRequestsThreshold(),
 								},
 							},
 						},
@@ -302,6 +394,26 @@ func bootstrapConfig(c *envoy.BootstrapConfig) *envoy_bootstrap_v3.Bootstrap {
 	return bootstrap
 }
 
+// histogramBucketSettings returns the histogram bucket settings of
+// the given stat prefixes, where "*" matches all stats.
+func histogramBucketSettings(buckets []envoy.HistogramBuckets) []*envoy_config_metrics_v3.HistogramBucketSettings {
+	var settings []*envoy_config_metrics_v3.HistogramBucketSettings
+	for _, h := range buckets {
+		match := &matcher.StringMatcher{
+			MatchPattern: &matcher.StringMatcher_Prefix{Prefix: h.Prefix},
+		}
+		if h.Prefix == "*" {
+			match.MatchPattern = &matcher.StringMatcher_SafeRegex{SafeRegex: SafeRegexMatch(".*")}
+		}
+
+		settings = append(settings, &envoy_config_metrics_v3.HistogramBucketSettings{
+			Match:   match,
+			Buckets: h.Buckets,
+		})
+	}
+	return settings
+}
+
 func adminAccessLog(logPath string) []*envoy_config_accesslog_v3.AccessLog {
 	return []*envoy_config_accesslog_v3.AccessLog{
 		{
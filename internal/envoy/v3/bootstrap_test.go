@@ -14,7 +14,9 @@
 package v3
 
 import (
+	"os"
 	"path"
+	"path/filepath"
 	"testing"
 
 	envoy_bootstrap_v3 "github.com/envoyproxy/go-control-plane/envoy/config/bootstrap/v3"
@@ -22,11 +24,59 @@ import (
 	"github.com/projectcontour/contour/internal/envoy"
 	"github.com/projectcontour/contour/internal/protobuf"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
 )
 
 func TestBootstrap(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("header.claims.signature\n"), 0600))
+
+	staticResourcesFile := filepath.Join(t.TempDir(), "resources.yaml")
+	require.NoError(t, os.WriteFile(staticResourcesFile, []byte(`
+clusters:
+- name: stats-sidecar
+  type: STATIC
+  connect_timeout: 1s
+  load_assignment:
+    cluster_name: stats-sidecar
+    endpoints:
+    - lb_endpoints:
+      - endpoint:
+          address:
+            socket_address:
+              address: 127.0.0.1
+              port_value: 9102
+listeners:
+- name: stats-sidecar
+  address:
+    socket_address:
+      address: 0.0.0.0
+      port_value: 9103
+  filter_chains:
+  - filters:
+    - name: envoy.filters.network.tcp_proxy
+      typed_config:
+        "@type": type.googleapis.com/envoy.extensions.filters.network.tcp_proxy.v3.TcpProxy
+        stat_prefix: stats-sidecar
+        cluster: stats-sidecar
+`), 0600))
+
+	invalidStaticResourcesFile := filepath.Join(t.TempDir(), "invalid.yaml")
+	require.NoError(t, os.WriteFile(invalidStaticResourcesFile, []byte(`
+clusters:
+- name: ""
+  connect_timeout: 1s
+`), 0600))
+
+	reservedStaticResourcesFile := filepath.Join(t.TempDir(), "reserved.yaml")
+	require.NoError(t, os.WriteFile(reservedStaticResourcesFile, []byte(`
+clusters:
+- name: contour
+  connect_timeout: 1s
+`), 0600))
+
 	tests := map[string]struct {
 		config                        envoy.BootstrapConfig
 		wantedBootstrapConfig         string
@@ -1831,122 +1881,172 @@ func TestBootstrap(t *testing.T) {
 			},
 			wantedError: true,
 		},
-		"Enable overload manager by specifying --overload-max-heap=2147483648": {
+		"--xds-token-file=token": {
 			config: envoy.BootstrapConfig{
-				Path:                 "envoy.json",
-				Namespace:            "projectcontour",
-				MaximumHeapSizeBytes: 2147483648, // 2 GiB
-			},
+				Path:         "envoy.json",
+				Namespace:    "testing-ns",
+				XDSTokenFile: tokenFile},
 			wantedBootstrapConfig: `{
-        "static_resources": {
-          "clusters": [
+  "node": {
+    "metadata": {
+      "contour-xds-token": "header.claims.signature"
+    }
+  },
+  "static_resources": {
+    "clusters": [
+      {
+        "name": "contour",
+        "alt_stat_name": "testing-ns_contour_8001",
+        "type": "STATIC",
+        "connect_timeout": "5s",
+        "load_assignment": {
+          "cluster_name": "contour",
+          "endpoints": [
             {
-              "name": "contour",
-              "alt_stat_name": "projectcontour_contour_8001",
-              "type": "STATIC",
-              "connect_timeout": "5s",
-              "load_assignment": {
-                "cluster_name": "contour",
-                "endpoints": [
-                  {
-                    "lb_endpoints": [
-                      {
-                        "endpoint": {
-                          "address": {
-                            "socket_address": {
-                              "address": "127.0.0.1",
-                              "port_value": 8001
-                            }
-                          }
-                        }
+              "lb_endpoints": [
+                {
+                  "endpoint": {
+                    "address": {
+                      "socket_address": {
+                        "address": "127.0.0.1",
+                        "port_value": 8001
                       }
-                    ]
-                  }
-                ]
-              },
-              "circuit_breakers": {
-                "thresholds": [
-                  {
-                    "priority": "HIGH",
-                    "max_connections": 100000,
-                    "max_pending_requests": 100000,
-                    "max_requests": 60000000,
-                    "max_retries": 50
-                  },
-                  {
-                    "max_connections": 100000,
-                    "max_pending_requests": 100000,
-                    "max_requests": 60000000,
-                    "max_retries": 50
-                  }
-                ]
-              },
-              "typed_extension_protocol_options": {
-                "envoy.extensions.upstreams.http.v3.HttpProtocolOptions": {
-                  "@type": "type.googleapis.com/envoy.extensions.upstreams.http.v3.HttpProtocolOptions",
-                  "explicit_http_config": {
-                    "http2_protocol_options": {}
+                    }
                   }
                 }
-              },
-              "upstream_connection_options": {
-                "tcp_keepalive": {
-                  "keepalive_probes": 3,
-                  "keepalive_time": 30,
-                  "keepalive_interval": 5
-                }
-              }
+              ]
+            }
+          ]
+        },
+        "circuit_breakers": {
+          "thresholds": [
+            {
+              "priority": "HIGH",
+              "max_connections": 100000,
+              "max_pending_requests": 100000,
+              "max_requests": 60000000,
+              "max_retries": 50
             },
             {
-              "name": "envoy-admin",
-              "alt_stat_name": "projectcontour_envoy-admin_9001",
-              "type": "STATIC",
-              "connect_timeout": "0.250s",
-              "load_assignment": {
-                "cluster_name": "envoy-admin",
-                "endpoints": [
-                  {
-                    "lb_endpoints": [
-                      {
-                        "endpoint": {
-                          "address": {
-                            "pipe": {
-                              "path": "/admin/admin.sock",
-                              "mode": 420
-                            }
-                          }
-                        }
-                      }
-                    ]
-                  }
-                ]
-              }
+              "max_connections": 100000,
+              "max_pending_requests": 100000,
+              "max_requests": 60000000,
+              "max_retries": 50
             }
           ]
         },
-        "default_regex_engine": {
-          "name": "envoy.regex_engines.google_re2",
-          "typed_config": {
-            "@type": "type.googleapis.com/envoy.extensions.regex_engines.v3.GoogleRE2"
+        "typed_extension_protocol_options": {
+          "envoy.extensions.upstreams.http.v3.HttpProtocolOptions": {
+            "@type": "type.googleapis.com/envoy.extensions.upstreams.http.v3.HttpProtocolOptions",
+            "explicit_http_config": {
+              "http2_protocol_options": {}
+            }
           }
         },
-        "dynamic_resources": {
-          "lds_config": {
-            "api_config_source": {
-              "api_type": "GRPC",
-              "transport_api_version": "V3",
-              "grpc_services": [
+        "upstream_connection_options": {
+          "tcp_keepalive": {
+            "keepalive_probes": 3,
+            "keepalive_time": 30,
+            "keepalive_interval": 5
+          }
+        }
+      },
+      {
+        "name": "envoy-admin",
+        "alt_stat_name": "testing-ns_envoy-admin_9001",
+        "type": "STATIC",
+        "connect_timeout": "0.250s",
+        "load_assignment": {
+          "cluster_name": "envoy-admin",
+          "endpoints": [
+            {
+              "lb_endpoints": [
                 {
-                  "envoy_grpc": {
-                    "cluster_name": "contour",
-                    "authority": "contour"
+                  "endpoint": {
+                    "address": {
+                      "pipe": {
+                        "path": "/admin/admin.sock",
+                        "mode": "420"
+                      }
+                    }
                   }
                 }
               ]
-            },
-            "resource_api_version": "V3"
-          },
-          "cds_config": {
+            }
+          ]
+        }
+      }
+    ]
+  },
+  "dynamic_resources": {
+    "lds_config": {
+      "api_config_source": {
+        "api_type": "GRPC",
+        "transport_api_version": "V3",
+        "grpc_services": [
+          {
+            "envoy_grpc": {
+              "cluster_name": "contour",
+              "authority": "contour"
+            }
+          }
+        ]
+      },
+	  "resource_api_version": "V3"
+    },
+    "cds_config": {
+      "api_config_source": {
+        "api_type": "GRPC",
+        "transport_api_version": "V3",
+        "grpc_services": [
+          {
+            "envoy_grpc": {
+              "cluster_name": "contour",
+              "authority": "contour"
+            }
+          }
+        ]
+      },
+ 	  "resource_api_version": "V3"
+    }
+  },
+  "default_regex_engine": {
+    "name": "envoy.regex_engines.google_re2",
+    "typed_config": {
+      "@type": "type.googleapis.com/envoy.extensions.regex_engines.v3.GoogleRE2"
+    }
+  },
+  "admin": {
+    "access_log": [
+      {
+        "name": "envoy.access_loggers.file",
+        "typed_config": {
+          "@type": "type.googleapis.com/envoy.extensions.access_loggers.file.v3.FileAccessLog",
+          "path": "/dev/null"
+        }
+      }
+    ],
+    "address": {
+   	 "pipe": {
+        "path": "/admin/admin.sock",
+        "mode": "420"
+      }
+    }
+  },
+  "layered_runtime": {
+    "layers": [
+      {
+        "name": "base",
+        "static_layer": {
+          "re2.max_program_size.error_level": 1048576,
+          "re2.max_program_size.warn_level": 1000
+        }
+      },
+      {
+        "name": "dynamic",
+        "rtds_layer": {
+          "name": "dynamic",
+          "rtds_config": {
             "api_config_source": {
               "api_type": "GRPC",
               "transport_api_version": "V3",
@@ -1961,15 +2061,637 @@ func TestBootstrap(t *testing.T) {
             },
             "resource_api_version": "V3"
           }
-        },
-        "layered_runtime": {
-          "layers": [
-            {
-              "name": "base",
-              "static_layer": {
-                "re2.max_program_size.error_level": 1048576,
-                "re2.max_program_size.warn_level": 1000
-              }
+        }
+      },
+      {
+        "name": "admin",
+        "admin_layer": {}
+      }
+    ]
+  }
+}`,
+		},
+		"return error when the xDS token file does not exist": {
+			config: envoy.BootstrapConfig{
+				Path:         "envoy.json",
+				Namespace:    "testing-ns",
+				XDSTokenFile: filepath.Join(t.TempDir(), "missing"),
+			},
+			wantedError: true,
+		},
+		"--static-resources-file=resources.yaml": {
+			config: envoy.BootstrapConfig{
+				Path:                "envoy.json",
+				Namespace:           "testing-ns",
+				StaticResourcesFile: staticResourcesFile,
+			},
+			wantedBootstrapConfig: `{
+  "static_resources": {
+    "clusters": [
+      {
+        "name": "contour",
+        "alt_stat_name": "testing-ns_contour_8001",
+        "type": "STATIC",
+        "connect_timeout": "5s",
+        "load_assignment": {
+          "cluster_name": "contour",
+          "endpoints": [
+            {
+              "lb_endpoints": [
+                {
+                  "endpoint": {
+                    "address": {
+                      "socket_address": {
+                        "address": "127.0.0.1",
+                        "port_value": 8001
+                      }
+                    }
+                  }
+                }
+              ]
+            }
+          ]
+        },
+        "circuit_breakers": {
+          "thresholds": [
+            {
+              "priority": "HIGH",
+              "max_connections": 100000,
+              "max_pending_requests": 100000,
+              "max_requests": 60000000,
+              "max_retries": 50
+            },
+            {
+              "max_connections": 100000,
+              "max_pending_requests": 100000,
+              "max_requests": 60000000,
+              "max_retries": 50
+            }
+          ]
+        },
+        "typed_extension_protocol_options": {
+          "envoy.extensions.upstreams.http.v3.HttpProtocolOptions": {
+            "@type": "type.googleapis.com/envoy.extensions.upstreams.http.v3.HttpProtocolOptions",
+            "explicit_http_config": {
+              "http2_protocol_options": {}
+            }
+          }
+        },
+        "upstream_connection_options": {
+          "tcp_keepalive": {
+            "keepalive_probes": 3,
+            "keepalive_time": 30,
+            "keepalive_interval": 5
+          }
+        }
+      },
+      {
+        "name": "envoy-admin",
+        "alt_stat_name": "testing-ns_envoy-admin_9001",
+        "type": "STATIC",
+        "connect_timeout": "0.250s",
+        "load_assignment": {
+          "cluster_name": "envoy-admin",
+          "endpoints": [
+            {
+              "lb_endpoints": [
+                {
+                  "endpoint": {
+                    "address": {
+                      "pipe": {
+                        "path": "/admin/admin.sock",
+                        "mode": "420"
+                      }
+                    }
+                  }
+                }
+              ]
+            }
+          ]
+        }
+      },
+      {
+        "name": "stats-sidecar",
+        "type": "STATIC",
+        "connect_timeout": "1s",
+        "load_assignment": {
+          "cluster_name": "stats-sidecar",
+          "endpoints": [
+            {
+              "lb_endpoints": [
+                {
+                  "endpoint": {
+                    "address": {
+                      "socket_address": {
+                        "address": "127.0.0.1",
+                        "port_value": 9102
+                      }
+                    }
+                  }
+                }
+              ]
+            }
+          ]
+        }
+      }
+    ],
+    "listeners": [
+      {
+        "name": "stats-sidecar",
+        "address": {
+          "socket_address": {
+            "address": "0.0.0.0",
+            "port_value": 9103
+          }
+        },
+        "filter_chains": [
+          {
+            "filters": [
+              {
+                "name": "envoy.filters.network.tcp_proxy",
+                "typed_config": {
+                  "@type": "type.googleapis.com/envoy.extensions.filters.network.tcp_proxy.v3.TcpProxy",
+                  "stat_prefix": "stats-sidecar",
+                  "cluster": "stats-sidecar"
+                }
+              }
+            ]
+          }
+        ]
+      }
+    ]
+  },
+  "dynamic_resources": {
+    "lds_config": {
+      "api_config_source": {
+        "api_type": "GRPC",
+        "transport_api_version": "V3",
+        "grpc_services": [
+          {
+            "envoy_grpc": {
+              "cluster_name": "contour",
+              "authority": "contour"
+            }
+          }
+        ]
+      },
+	  "resource_api_version": "V3"
+    },
+    "cds_config": {
+      "api_config_source": {
+        "api_type": "GRPC",
+        "transport_api_version": "V3",
+        "grpc_services": [
+          {
+            "envoy_grpc": {
+              "cluster_name": "contour",
+              "authority": "contour"
+            }
+          }
+        ]
+      },
+ 	  "resource_api_version": "V3"
+    }
+  },
+  "default_regex_engine": {
+    "name": "envoy.regex_engines.google_re2",
+    "typed_config": {
+      "@type": "type.googleapis.com/envoy.extensions.regex_engines.v3.GoogleRE2"
+    }
+  },
+  "admin": {
+    "access_log": [
+      {
+        "name": "envoy.access_loggers.file",
+        "typed_config": {
+          "@type": "type.googleapis.com/envoy.extensions.access_loggers.file.v3.FileAccessLog",
+          "path": "/dev/null"
+        }
+      }
+    ],
+    "address": {
+   	 "pipe": {
+        "path": "/admin/admin.sock",
+        "mode": "420"
+      }
+    }
+  },
+  "layered_runtime": {
+    "layers": [
+      {
+        "name": "base",
+        "static_layer": {
+          "re2.max_program_size.error_level": 1048576,
+          "re2.max_program_size.warn_level": 1000
+        }
+      },
+      {
+        "name": "dynamic",
+        "rtds_layer": {
+          "name": "dynamic",
+          "rtds_config": {
+            "api_config_source": {
+              "api_type": "GRPC",
+              "transport_api_version": "V3",
+              "grpc_services": [
+                {
+                  "envoy_grpc": {
+                    "cluster_name": "contour",
+                    "authority": "contour"
+                  }
+                }
+              ]
+            },
+            "resource_api_version": "V3"
+          }
+        }
+      },
+      {
+        "name": "admin",
+        "admin_layer": {}
+      }
+    ]
+  }
+}`,
+		},
+		"return error when the static resources are invalid": {
+			config: envoy.BootstrapConfig{
+				Path:                "envoy.json",
+				Namespace:           "testing-ns",
+				StaticResourcesFile: invalidStaticResourcesFile,
+			},
+			wantedError: true,
+		},
+		"return error when a static cluster is named contour": {
+			config: envoy.BootstrapConfig{
+				Path:                "envoy.json",
+				Namespace:           "testing-ns",
+				StaticResourcesFile: reservedStaticResourcesFile,
+			},
+			wantedError: true,
+		},
+		"Enable overload manager by specifying --overload-max-heap=2147483648": {
+			config: envoy.BootstrapConfig{
+				Path:                 "envoy.json",
+				Namespace:            "projectcontour",
+				MaximumHeapSizeBytes: 2147483648, // 2 GiB
+			},
+			wantedBootstrapConfig: `{
+        "static_resources": {
+          "clusters": [
+            {
+              "name": "contour",
+              "alt_stat_name": "projectcontour_contour_8001",
+              "type": "STATIC",
+              "connect_timeout": "5s",
+              "load_assignment": {
+                "cluster_name": "contour",
+                "endpoints": [
+                  {
+                    "lb_endpoints": [
+                      {
+                        "endpoint": {
+                          "address": {
+                            "socket_address": {
+                              "address": "127.0.0.1",
+                              "port_value": 8001
+                            }
+                          }
+                        }
+                      }
+                    ]
+                  }
+                ]
+              },
+              "circuit_breakers": {
+                "thresholds": [
+                  {
+                    "priority": "HIGH",
+                    "max_connections": 100000,
+                    "max_pending_requests": 100000,
+                    "max_requests": 60000000,
+                    "max_retries": 50
+                  },
+                  {
+                    "max_connections": 100000,
+                    "max_pending_requests": 100000,
+                    "max_requests": 60000000,
+                    "max_retries": 50
+                  }
+                ]
+              },
+              "typed_extension_protocol_options": {
+                "envoy.extensions.upstreams.http.v3.HttpProtocolOptions": {
+                  "@type": "type.googleapis.com/envoy.extensions.upstreams.http.v3.HttpProtocolOptions",
+                  "explicit_http_config": {
+                    "http2_protocol_options": {}
+                  }
+                }
+              },
+              "upstream_connection_options": {
+                "tcp_keepalive": {
+                  "keepalive_probes": 3,
+                  "keepalive_time": 30,
+                  "keepalive_interval": 5
+                }
+              }
+            },
+            {
+              "name": "envoy-admin",
+              "alt_stat_name": "projectcontour_envoy-admin_9001",
+              "type": "STATIC",
+              "connect_timeout": "0.250s",
+              "load_assignment": {
+                "cluster_name": "envoy-admin",
+                "endpoints": [
+                  {
+                    "lb_endpoints": [
+                      {
+                        "endpoint": {
+                          "address": {
+                            "pipe": {
+                              "path": "/admin/admin.sock",
+                              "mode": 420
+                            }
+                          }
+                        }
+                      }
+                    ]
+                  }
+                ]
+              }
+            }
+          ]
+        },
+        "default_regex_engine": {
+          "name": "envoy.regex_engines.google_re2",
+          "typed_config": {
+            "@type": "type.googleapis.com/envoy.extensions.regex_engines.v3.GoogleRE2"
+          }
+        },
+        "dynamic_resources": {
+          "lds_config": {
+            "api_config_source": {
+              "api_type": "GRPC",
+              "transport_api_version": "V3",
+              "grpc_services": [
+                {
+                  "envoy_grpc": {
+                    "cluster_name": "contour",
+                    "authority": "contour"
+                  }
+                }
+              ]
+            },
+            "resource_api_version": "V3"
+          },
+          "cds_config": {
+            "api_config_source": {
+              "api_type": "GRPC",
+              "transport_api_version": "V3",
+              "grpc_services": [
+                {
+                  "envoy_grpc": {
+                    "cluster_name": "contour",
+                    "authority": "contour"
+                  }
+                }
+              ]
+            },
+            "resource_api_version": "V3"
+          }
+        },
+        "layered_runtime": {
+          "layers": [
+            {
+              "name": "base",
+              "static_layer": {
+                "re2.max_program_size.error_level": 1048576,
+                "re2.max_program_size.warn_level": 1000
+              }
+            },
+            {
+              "name": "dynamic",
+              "rtds_layer": {
+                "name": "dynamic",
+                "rtds_config": {
+                  "api_config_source": {
+                    "api_type": "GRPC",
+                    "transport_api_version": "V3",
+                    "grpc_services": [
+                      {
+                        "envoy_grpc": {
+                          "cluster_name": "contour",
+                          "authority": "contour"
+                        }
+                      }
+                    ]
+                  },
+                  "resource_api_version": "V3"
+                }
+              }
+            },
+            {
+              "name": "admin",
+              "admin_layer": {}
+            }
+          ]
+        },
+        "admin": {
+          "access_log": [
+            {
+              "name": "envoy.access_loggers.file",
+              "typed_config": {
+                "@type": "type.googleapis.com/envoy.extensions.access_loggers.file.v3.FileAccessLog",
+                "path": "/dev/null"
+              }
+            }
+          ],
+          "address": {
+            "pipe": {
+              "path": "/admin/admin.sock",
+              "mode": 420
+            }
+          }
+        },
+        "overload_manager": {
+          "refresh_interval": "0.250s",
+          "resource_monitors": [
+            {
+              "name": "envoy.resource_monitors.fixed_heap",
+              "typed_config": {
+                "@type": "type.googleapis.com/envoy.extensions.resource_monitors.fixed_heap.v3.FixedHeapConfig",
+                "max_heap_size_bytes": "2147483648"
+              }
+            }
+          ],
+          "actions": [
+            {
+              "name": "envoy.overload_actions.shrink_heap",
+              "triggers": [
+                {
+                  "name": "envoy.resource_monitors.fixed_heap",
+                  "threshold": {
+                    "value": 0.95
+                  }
+                }
+              ]
+            },
+            {
+              "name": "envoy.overload_actions.stop_accepting_requests",
+              "triggers": [
+                {
+                  "name": "envoy.resource_monitors.fixed_heap",
+                  "threshold": {
+                    "value": 0.98
+                  }
+                }
+              ]
+            }
+          ]
+        }
+      }`},
+		"Configure overload action thresholds": {
+			config: envoy.BootstrapConfig{
+				Path:                                   "envoy.json",
+				Namespace:                              "projectcontour",
+				MaximumHeapSizeBytes:                   2147483648, // 2 GiB
+				OverloadShrinkHeapThreshold:            0.8,
+				OverloadStopAcceptingRequestsThreshold: 0.9,
+			},
+			wantedBootstrapConfig: `{
+        "static_resources": {
+          "clusters": [
+            {
+              "name": "contour",
+              "alt_stat_name": "projectcontour_contour_8001",
+              "type": "STATIC",
+              "connect_timeout": "5s",
+              "load_assignment": {
+                "cluster_name": "contour",
+                "endpoints": [
+                  {
+                    "lb_endpoints": [
+                      {
+                        "endpoint": {
+                          "address": {
+                            "socket_address": {
+                              "address": "127.0.0.1",
+                              "port_value": 8001
+                            }
+                          }
+                        }
+                      }
+                    ]
+                  }
+                ]
+              },
+              "circuit_breakers": {
+                "thresholds": [
+                  {
+                    "priority": "HIGH",
+                    "max_connections": 100000,
+                    "max_pending_requests": 100000,
+                    "max_requests": 60000000,
+                    "max_retries": 50
+                  },
+                  {
+                    "max_connections": 100000,
+                    "max_pending_requests": 100000,
+                    "max_requests": 60000000,
+                    "max_retries": 50
+                  }
+                ]
+              },
+              "typed_extension_protocol_options": {
+                "envoy.extensions.upstreams.http.v3.HttpProtocolOptions": {
+                  "@type": "type.googleapis.com/envoy.extensions.upstreams.http.v3.HttpProtocolOptions",
+                  "explicit_http_config": {
+                    "http2_protocol_options": {}
+                  }
+                }
+              },
+              "upstream_connection_options": {
+                "tcp_keepalive": {
+                  "keepalive_probes": 3,
+                  "keepalive_time": 30,
+                  "keepalive_interval": 5
+                }
+              }
+            },
+            {
+              "name": "envoy-admin",
+              "alt_stat_name": "projectcontour_envoy-admin_9001",
+              "type": "STATIC",
+              "connect_timeout": "0.250s",
+              "load_assignment": {
+                "cluster_name": "envoy-admin",
+                "endpoints": [
+                  {
+                    "lb_endpoints": [
+                      {
+                        "endpoint": {
+                          "address": {
+                            "pipe": {
+                              "path": "/admin/admin.sock",
+                              "mode": 420
+                            }
+                          }
+                        }
+                      }
+                    ]
+                  }
+                ]
+              }
+            }
+          ]
+        },
+        "default_regex_engine": {
+          "name": "envoy.regex_engines.google_re2",
+          "typed_config": {
+            "@type": "type.googleapis.com/envoy.extensions.regex_engines.v3.GoogleRE2"
+          }
+        },
+        "dynamic_resources": {
+          "lds_config": {
+            "api_config_source": {
+              "api_type": "GRPC",
+              "transport_api_version": "V3",
+              "grpc_services": [
+                {
+                  "envoy_grpc": {
+                    "cluster_name": "contour",
+                    "authority": "contour"
+                  }
+                }
+              ]
+            },
+            "resource_api_version": "V3"
+          },
+          "cds_config": {
+            "api_config_source": {
+              "api_type": "GRPC",
+              "transport_api_version": "V3",
+              "grpc_services": [
+                {
+                  "envoy_grpc": {
+                    "cluster_name": "contour",
+                    "authority": "contour"
+                  }
+                }
+              ]
+            },
+            "resource_api_version": "V3"
+          }
+        },
+        "layered_runtime": {
+          "layers": [
+            {
+              "name": "base",
+              "static_layer": {
+                "re2.max_program_size.error_level": 1048576,
+                "re2.max_program_size.warn_level": 1000
+              }
             },
             {
               "name": "dynamic",
@@ -2033,7 +2755,7 @@ func TestBootstrap(t *testing.T) {
                 {
                   "name": "envoy.resource_monitors.fixed_heap",
                   "threshold": {
-                    "value": 0.95
+                    "value": 0.8
                   }
                 }
               ]
@@ -2044,10 +2766,216 @@ func TestBootstrap(t *testing.T) {
                 {
                   "name": "envoy.resource_monitors.fixed_heap",
                   "threshold": {
-                    "value": 0.98
+                    "value": 0.9
+                  }
+                }
+              ]
+            }
+          ]
+        }
+      }`},
+		"Configure histogram buckets": {
+			config: envoy.BootstrapConfig{
+				Path:      "envoy.json",
+				Namespace: "projectcontour",
+				HistogramBuckets: []envoy.HistogramBuckets{
+					{Prefix: "cluster.", Buckets: []float64{50, 100, 250}},
+					{Prefix: "*", Buckets: []float64{0.5, 1, 5}},
+				},
+			},
+			wantedBootstrapConfig: `{
+        "static_resources": {
+          "clusters": [
+            {
+              "name": "contour",
+              "alt_stat_name": "projectcontour_contour_8001",
+              "type": "STATIC",
+              "connect_timeout": "5s",
+              "load_assignment": {
+                "cluster_name": "contour",
+                "endpoints": [
+                  {
+                    "lb_endpoints": [
+                      {
+                        "endpoint": {
+                          "address": {
+                            "socket_address": {
+                              "address": "127.0.0.1",
+                              "port_value": 8001
+                            }
+                          }
+                        }
+                      }
+                    ]
+                  }
+                ]
+              },
+              "circuit_breakers": {
+                "thresholds": [
+                  {
+                    "priority": "HIGH",
+                    "max_connections": 100000,
+                    "max_pending_requests": 100000,
+                    "max_requests": 60000000,
+                    "max_retries": 50
+                  },
+                  {
+                    "max_connections": 100000,
+                    "max_pending_requests": 100000,
+                    "max_requests": 60000000,
+                    "max_retries": 50
+                  }
+                ]
+              },
+              "typed_extension_protocol_options": {
+                "envoy.extensions.upstreams.http.v3.HttpProtocolOptions": {
+                  "@type": "type.googleapis.com/envoy.extensions.upstreams.http.v3.HttpProtocolOptions",
+                  "explicit_http_config": {
+                    "http2_protocol_options": {}
+                  }
+                }
+              },
+              "upstream_connection_options": {
+                "tcp_keepalive": {
+                  "keepalive_probes": 3,
+                  "keepalive_time": 30,
+                  "keepalive_interval": 5
+                }
+              }
+            },
+            {
+              "name": "envoy-admin",
+              "alt_stat_name": "projectcontour_envoy-admin_9001",
+              "type": "STATIC",
+              "connect_timeout": "0.250s",
+              "load_assignment": {
+                "cluster_name": "envoy-admin",
+                "endpoints": [
+                  {
+                    "lb_endpoints": [
+                      {
+                        "endpoint": {
+                          "address": {
+                            "pipe": {
+                              "path": "/admin/admin.sock",
+                              "mode": 420
+                            }
+                          }
+                        }
+                      }
+                    ]
+                  }
+                ]
+              }
+            }
+          ]
+        },
+        "default_regex_engine": {
+          "name": "envoy.regex_engines.google_re2",
+          "typed_config": {
+            "@type": "type.googleapis.com/envoy.extensions.regex_engines.v3.GoogleRE2"
+          }
+        },
+        "dynamic_resources": {
+          "lds_config": {
+            "api_config_source": {
+              "api_type": "GRPC",
+              "transport_api_version": "V3",
+              "grpc_services": [
+                {
+                  "envoy_grpc": {
+                    "cluster_name": "contour",
+                    "authority": "contour"
+                  }
+                }
+              ]
+            },
+            "resource_api_version": "V3"
+          },
+          "cds_config": {
+            "api_config_source": {
+              "api_type": "GRPC",
+              "transport_api_version": "V3",
+              "grpc_services": [
+                {
+                  "envoy_grpc": {
+                    "cluster_name": "contour",
+                    "authority": "contour"
                   }
                 }
               ]
+            },
+            "resource_api_version": "V3"
+          }
+        },
+        "layered_runtime": {
+          "layers": [
+            {
+              "name": "base",
+              "static_layer": {
+                "re2.max_program_size.error_level": 1048576,
+                "re2.max_program_size.warn_level": 1000
+              }
+            },
+            {
+              "name": "dynamic",
+              "rtds_layer": {
+                "name": "dynamic",
+                "rtds_config": {
+                  "api_config_source": {
+                    "api_type": "GRPC",
+                    "transport_api_version": "V3",
+                    "grpc_services": [
+                      {
+                        "envoy_grpc": {
+                          "cluster_name": "contour",
+                          "authority": "contour"
+                        }
+                      }
+                    ]
+                  },
+                  "resource_api_version": "V3"
+                }
+              }
+            },
+            {
+              "name": "admin",
+              "admin_layer": {}
+            }
+          ]
+        },
+        "admin": {
+          "access_log": [
+            {
+              "name": "envoy.access_loggers.file",
+              "typed_config": {
+                "@type": "type.googleapis.com/envoy.extensions.access_loggers.file.v3.FileAccessLog",
+                "path": "/dev/null"
+              }
+            }
+          ],
+          "address": {
+            "pipe": {
+              "path": "/admin/admin.sock",
+              "mode": 420
+            }
+          }
+        },
+        "stats_config": {
+          "histogram_bucket_settings": [
+            {
+              "match": {
+                "prefix": "cluster."
+              },
+              "buckets": [50, 100, 250]
+            },
+            {
+              "match": {
+                "safe_regex": {
+                  "regex": ".*"
+                }
+              },
+              "buckets": [0.5, 1, 5]
             }
           ]
         }
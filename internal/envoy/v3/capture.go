@@ -0,0 +1,136 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	envoy_matcher_v3 "github.com/envoyproxy/go-control-plane/envoy/config/common/matcher/v3"
+	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	envoy_config_tap_v3 "github.com/envoyproxy/go-control-plane/envoy/config/tap/v3"
+	envoy_common_tap_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/common/tap/v3"
+	envoy_config_filter_http_tap_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/tap/v3"
+	http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	envoy_type_v3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/projectcontour/contour/internal/dag"
+	"github.com/projectcontour/contour/internal/protobuf"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// CaptureFilterName is the name of the HTTP tap filter
+// used to capture requests and responses.
+const CaptureFilterName = "envoy.filters.http.tap"
+
+// CaptureConfig holds the settings for capturing
+// requests and responses with the HTTP tap filter.
+type CaptureConfig struct {
+	// FilePathPrefix is the path prefix of the files
+	// that each captured request and response is written to.
+	FilePathPrefix string
+
+	// SamplePercent is the percentage of requests
+	// to capture on routes with capture enabled.
+	SamplePercent uint32
+
+	// MaxBufferedBytes limits the size of each captured request
+	// and response body. Zero means Envoy's default limit.
+	MaxBufferedBytes uint32
+}
+
+// CaptureFilter returns a configured HTTP tap filter,
+// or nil if config is nil.
+func CaptureFilter(config *CaptureConfig) *http.HttpFilter {
+	if config == nil {
+		return nil
+	}
+
+	output := &envoy_config_tap_v3.OutputConfig{
+		Sinks: []*envoy_config_tap_v3.OutputSink{{
+			Format: envoy_config_tap_v3.OutputSink_JSON_BODY_AS_STRING,
+			OutputSinkType: &envoy_config_tap_v3.OutputSink_FilePerTap{
+				FilePerTap: &envoy_config_tap_v3.FilePerTapSink{
+					PathPrefix: config.FilePathPrefix,
+				},
+			},
+		}},
+	}
+	if config.MaxBufferedBytes > 0 {
+		output.MaxBufferedRxBytes = wrapperspb.UInt32(config.MaxBufferedBytes)
+		output.MaxBufferedTxBytes = wrapperspb.UInt32(config.MaxBufferedBytes)
+	}
+
+	return &http.HttpFilter{
+		Name: CaptureFilterName,
+		ConfigType: &http.HttpFilter_TypedConfig{
+			TypedConfig: protobuf.MustMarshalAny(&envoy_config_filter_http_tap_v3.Tap{
+				CommonConfig: &envoy_common_tap_v3.CommonExtensionConfig{
+					ConfigType: &envoy_common_tap_v3.CommonExtensionConfig_StaticConfig{
+						StaticConfig: &envoy_config_tap_v3.TapConfig{
+							Match: &envoy_matcher_v3.MatchPredicate{
+								Rule: &envoy_matcher_v3.MatchPredicate_AnyMatch{
+									AnyMatch: true,
+								},
+							},
+							OutputConfig: output,
+							TapEnabled: &envoy_core_v3.RuntimeFractionalPercent{
+								DefaultValue: &envoy_type_v3.FractionalPercent{
+									Numerator:   config.SamplePercent,
+									Denominator: envoy_type_v3.FractionalPercent_HUNDRED,
+								},
+							},
+						},
+					},
+				},
+			}),
+		},
+	}
+}
+
+// DisableCapture disables the HTTP tap filter on the routes of the
+// given virtual host that don't have capture enabled. The Envoy routes
+// must have been built from dagRoutes, in the same order. The tap
+// filter has no per-route config, so the filter is disabled on the
+// whole virtual host when none of its routes have capture enabled,
+// and otherwise on each route that doesn't.
+func DisableCapture(evh *envoy_route_v3.VirtualHost, dagRoutes []*dag.Route) {
+	var capture bool
+	for _, route := range dagRoutes {
+		capture = capture || route.Capture
+	}
+
+	if !capture {
+		if evh.TypedPerFilterConfig == nil {
+			evh.TypedPerFilterConfig = map[string]*anypb.Any{}
+		}
+		evh.TypedPerFilterConfig[CaptureFilterName] = captureDisabled()
+		return
+	}
+
+	for i, route := range dagRoutes {
+		if route.Capture {
+			continue
+		}
+		if evh.Routes[i].TypedPerFilterConfig == nil {
+			evh.Routes[i].TypedPerFilterConfig = map[string]*anypb.Any{}
+		}
+		evh.Routes[i].TypedPerFilterConfig[CaptureFilterName] = captureDisabled()
+	}
+}
+
+// captureDisabled returns a per-route config to disable the HTTP tap filter.
+func captureDisabled() *anypb.Any {
+	return protobuf.MustMarshalAny(&envoy_route_v3.FilterConfig{
+		Disabled: true,
+	})
+}
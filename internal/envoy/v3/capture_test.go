@@ -0,0 +1,148 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"testing"
+
+	envoy_matcher_v3 "github.com/envoyproxy/go-control-plane/envoy/config/common/matcher/v3"
+	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	envoy_config_tap_v3 "github.com/envoyproxy/go-control-plane/envoy/config/tap/v3"
+	envoy_common_tap_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/common/tap/v3"
+	envoy_config_filter_http_tap_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/tap/v3"
+	http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	envoy_type_v3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/projectcontour/contour/internal/dag"
+	"github.com/projectcontour/contour/internal/protobuf"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestCaptureFilter(t *testing.T) {
+	tapFilter := func(percent uint32, output *envoy_config_tap_v3.OutputConfig) *http.HttpFilter {
+		return &http.HttpFilter{
+			Name: "envoy.filters.http.tap",
+			ConfigType: &http.HttpFilter_TypedConfig{
+				TypedConfig: protobuf.MustMarshalAny(&envoy_config_filter_http_tap_v3.Tap{
+					CommonConfig: &envoy_common_tap_v3.CommonExtensionConfig{
+						ConfigType: &envoy_common_tap_v3.CommonExtensionConfig_StaticConfig{
+							StaticConfig: &envoy_config_tap_v3.TapConfig{
+								Match: &envoy_matcher_v3.MatchPredicate{
+									Rule: &envoy_matcher_v3.MatchPredicate_AnyMatch{AnyMatch: true},
+								},
+								OutputConfig: output,
+								TapEnabled: &envoy_core_v3.RuntimeFractionalPercent{
+									DefaultValue: &envoy_type_v3.FractionalPercent{
+										Numerator:   percent,
+										Denominator: envoy_type_v3.FractionalPercent_HUNDRED,
+									},
+								},
+							},
+						},
+					},
+				}),
+			},
+		}
+	}
+
+	sinks := []*envoy_config_tap_v3.OutputSink{{
+		Format: envoy_config_tap_v3.OutputSink_JSON_BODY_AS_STRING,
+		OutputSinkType: &envoy_config_tap_v3.OutputSink_FilePerTap{
+			FilePerTap: &envoy_config_tap_v3.FilePerTapSink{
+				PathPrefix: "/var/log/envoy/capture",
+			},
+		},
+	}}
+
+	tests := map[string]struct {
+		cfg  *CaptureConfig
+		want *http.HttpFilter
+	}{
+		"nil config produces nil filter": {
+			cfg:  nil,
+			want: nil,
+		},
+		"default buffer limits": {
+			cfg: &CaptureConfig{
+				FilePathPrefix: "/var/log/envoy/capture",
+				SamplePercent:  100,
+			},
+			want: tapFilter(100, &envoy_config_tap_v3.OutputConfig{
+				Sinks: sinks,
+			}),
+		},
+		"sampled with buffer limits": {
+			cfg: &CaptureConfig{
+				FilePathPrefix:   "/var/log/envoy/capture",
+				SamplePercent:    5,
+				MaxBufferedBytes: 4096,
+			},
+			want: tapFilter(5, &envoy_config_tap_v3.OutputConfig{
+				Sinks:              sinks,
+				MaxBufferedRxBytes: wrapperspb.UInt32(4096),
+				MaxBufferedTxBytes: wrapperspb.UInt32(4096),
+			}),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			protobuf.ExpectEqual(t, tc.want, CaptureFilter(tc.cfg))
+		})
+	}
+}
+
+func TestDisableCapture(t *testing.T) {
+	disabled := protobuf.MustMarshalAny(&envoy_route_v3.FilterConfig{Disabled: true})
+
+	tests := map[string]struct {
+		capture    []bool
+		wantVhost  map[string]*anypb.Any
+		wantRoutes []map[string]*anypb.Any
+	}{
+		"no routes capture": {
+			capture:    []bool{false, false},
+			wantVhost:  map[string]*anypb.Any{"envoy.filters.http.tap": disabled},
+			wantRoutes: []map[string]*anypb.Any{nil, nil},
+		},
+		"some routes capture": {
+			capture:   []bool{true, false, true},
+			wantVhost: nil,
+			wantRoutes: []map[string]*anypb.Any{
+				nil,
+				{"envoy.filters.http.tap": disabled},
+				nil,
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var dagRoutes []*dag.Route
+			evh := &envoy_route_v3.VirtualHost{Name: "www.example.com"}
+			for _, capture := range tc.capture {
+				dagRoutes = append(dagRoutes, &dag.Route{Capture: capture})
+				evh.Routes = append(evh.Routes, &envoy_route_v3.Route{})
+			}
+
+			DisableCapture(evh, dagRoutes)
+
+			protobuf.ExpectEqual(t, tc.wantVhost, evh.TypedPerFilterConfig)
+			for i, want := range tc.wantRoutes {
+				protobuf.ExpectEqual(t, want, evh.Routes[i].TypedPerFilterConfig)
+			}
+		})
+	}
+}
@@ -20,17 +20,17 @@ import (
 
 	envoy_cluster_v3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
 	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	envoy_aggregate_cluster_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/clusters/aggregate/v3"
+	envoy_cares_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/network/dns_resolver/cares/v3"
 	envoy_extensions_upstream_http_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/upstreams/http/v3"
 	envoy_type "github.com/envoyproxy/go-control-plane/envoy/type/v3"
 	"github.com/projectcontour/contour/internal/dag"
 	"github.com/projectcontour/contour/internal/envoy"
 	"github.com/projectcontour/contour/internal/protobuf"
 	"github.com/projectcontour/contour/internal/timeout"
-	"github.com/projectcontour/contour/internal/xds"
 	"google.golang.org/protobuf/types/known/anypb"
 	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/wrapperspb"
-	"k8s.io/apimachinery/pkg/types"
 )
 
 func clusterDefaults() *envoy_cluster_v3.Cluster {
@@ -60,7 +60,7 @@ func Cluster(c *dag.Cluster) *envoy_cluster_v3.Cluster {
 	case 0:
 		// external name not set, cluster will be discovered via EDS
 		cluster.ClusterDiscoveryType = ClusterDiscoveryType(envoy_cluster_v3.Cluster_EDS)
-		cluster.EdsClusterConfig = edsconfig("contour", service)
+		cluster.EdsClusterConfig = edsconfig("contour", c)
 	default:
 		// external name set, use hard coded DNS name
 		// external name set to LOGICAL_DNS when user selects the ALL loookup family
@@ -89,6 +89,17 @@ func Cluster(c *dag.Cluster) *envoy_cluster_v3.Cluster {
 		}
 	}
 
+	if keys := c.SubsetKeys(); len(keys) > 0 {
+		// Routes select the endpoints of the subset by their
+		// metadata, and get no endpoints when none match.
+		cluster.LbSubsetConfig = &envoy_cluster_v3.Cluster_LbSubsetConfig{
+			FallbackPolicy: envoy_cluster_v3.Cluster_LbSubsetConfig_NO_FALLBACK,
+			SubsetSelectors: []*envoy_cluster_v3.Cluster_LbSubsetConfig_LbSubsetSelector{{
+				Keys: keys,
+			}},
+		}
+	}
+
 	httpVersion := HTTPVersionAuto
 	switch c.Protocol {
 	case "tls":
@@ -117,25 +128,67 @@ func Cluster(c *dag.Cluster) *envoy_cluster_v3.Cluster {
 		cluster.ConnectTimeout = durationpb.New(c.TimeoutPolicy.ConnectTimeout)
 	}
 
-	cluster.TypedExtensionProtocolOptions = protocolOptions(httpVersion, c.TimeoutPolicy.IdleConnectionTimeout, c.MaxRequestsPerConnection)
+	cluster.TypedExtensionProtocolOptions = protocolOptions(httpVersion, c.TimeoutPolicy.IdleConnectionTimeout, c.MaxRequestsPerConnection, c.HTTP2Keepalive, c.HTTP2Settings)
 
-	if c.SlowStartConfig != nil {
-		switch cluster.LbPolicy {
-		case envoy_cluster_v3.Cluster_LEAST_REQUEST:
+	switch cluster.LbPolicy {
+	case envoy_cluster_v3.Cluster_LEAST_REQUEST:
+		if c.SlowStartConfig != nil || c.ActiveRequestBias != nil {
+			config := &envoy_cluster_v3.Cluster_LeastRequestLbConfig{}
+			if c.SlowStartConfig != nil {
+				config.SlowStartConfig = slowStartConfig(c.SlowStartConfig)
+			}
+			if c.ActiveRequestBias != nil {
+				config.ActiveRequestBias = &envoy_core_v3.RuntimeDouble{
+					DefaultValue: *c.ActiveRequestBias,
+					RuntimeKey:   "contour.leastrequest.activerequestbias",
+				}
+			}
 			cluster.LbConfig = &envoy_cluster_v3.Cluster_LeastRequestLbConfig_{
-				LeastRequestLbConfig: &envoy_cluster_v3.Cluster_LeastRequestLbConfig{
-					SlowStartConfig: slowStartConfig(c.SlowStartConfig),
-				},
+				LeastRequestLbConfig: config,
 			}
-		case envoy_cluster_v3.Cluster_ROUND_ROBIN:
+		}
+	case envoy_cluster_v3.Cluster_ROUND_ROBIN:
+		if c.SlowStartConfig != nil {
 			cluster.LbConfig = &envoy_cluster_v3.Cluster_RoundRobinLbConfig_{
 				RoundRobinLbConfig: &envoy_cluster_v3.Cluster_RoundRobinLbConfig{
 					SlowStartConfig: slowStartConfig(c.SlowStartConfig),
 				},
 			}
-		default:
-			// Slow start is only supported for round robin and weighted least request.
 		}
+	case envoy_cluster_v3.Cluster_MAGLEV:
+		if c.MaglevTableSize > 0 {
+			cluster.LbConfig = &envoy_cluster_v3.Cluster_MaglevLbConfig_{
+				MaglevLbConfig: &envoy_cluster_v3.Cluster_MaglevLbConfig{
+					TableSize: wrapperspb.UInt64(c.MaglevTableSize),
+				},
+			}
+		}
+	default:
+		// Slow start is only supported for round robin and weighted least request.
+	}
+
+	return cluster
+}
+
+// AggregateCluster builds an envoy_cluster_v3.Cluster for the given
+// *dag.AggregateCluster, which sends traffic to the first of its
+// clusters that has healthy endpoints.
+func AggregateCluster(c *dag.AggregateCluster) *envoy_cluster_v3.Cluster {
+	var clusters []string
+	for _, cluster := range c.Clusters {
+		clusters = append(clusters, envoy.Clustername(cluster))
+	}
+
+	cluster := clusterDefaults()
+	cluster.Name = envoy.AggregateClusterName(c)
+	cluster.LbPolicy = envoy_cluster_v3.Cluster_CLUSTER_PROVIDED
+	cluster.ClusterDiscoveryType = &envoy_cluster_v3.Cluster_ClusterType{
+		ClusterType: &envoy_cluster_v3.Cluster_CustomClusterType{
+			Name: "envoy.clusters.aggregate",
+			TypedConfig: protobuf.MustMarshalAny(&envoy_aggregate_cluster_v3.ClusterConfig{
+				Clusters: clusters,
+			}),
+		},
 	}
 
 	return cluster
@@ -187,7 +240,7 @@ func ExtensionCluster(ext *dag.ExtensionCluster) *envoy_cluster_v3.Cluster {
 	if ext.ClusterTimeoutPolicy.ConnectTimeout > time.Duration(0) {
 		cluster.ConnectTimeout = durationpb.New(ext.ClusterTimeoutPolicy.ConnectTimeout)
 	}
-	cluster.TypedExtensionProtocolOptions = protocolOptions(http2Version, ext.ClusterTimeoutPolicy.IdleConnectionTimeout, nil)
+	cluster.TypedExtensionProtocolOptions = protocolOptions(http2Version, ext.ClusterTimeoutPolicy.IdleConnectionTimeout, nil, ext.HTTP2Keepalive, ext.HTTP2Settings)
 
 	return cluster
 }
@@ -216,13 +269,10 @@ func DNSNameCluster(c *dag.DNSNameCluster) *envoy_cluster_v3.Cluster {
 	return cluster
 }
 
-func edsconfig(cluster string, service *dag.Service) *envoy_cluster_v3.Cluster_EdsClusterConfig {
+func edsconfig(cluster string, c *dag.Cluster) *envoy_cluster_v3.Cluster_EdsClusterConfig {
 	return &envoy_cluster_v3.Cluster_EdsClusterConfig{
-		EdsConfig: ConfigSource(cluster),
-		ServiceName: xds.ClusterLoadAssignmentName(
-			types.NamespacedName{Name: service.Weighted.ServiceName, Namespace: service.Weighted.ServiceNamespace},
-			service.Weighted.ServicePort.Name,
-		),
+		EdsConfig:   ConfigSource(cluster),
+		ServiceName: c.ClusterLoadAssignmentName(),
 	}
 }
 
@@ -234,6 +284,8 @@ func lbPolicy(strategy string) envoy_cluster_v3.Cluster_LbPolicy {
 		return envoy_cluster_v3.Cluster_RANDOM
 	case dag.LoadBalancerPolicyCookie, dag.LoadBalancerPolicyRequestHash:
 		return envoy_cluster_v3.Cluster_RING_HASH
+	case dag.LoadBalancerPolicyMaglev:
+		return envoy_cluster_v3.Cluster_MAGLEV
 	default:
 		return envoy_cluster_v3.Cluster_ROUND_ROBIN
 	}
@@ -296,6 +348,98 @@ func ClusterDiscoveryTypeForAddress(address string, t envoy_cluster_v3.Cluster_D
 	return &envoy_cluster_v3.Cluster_Type{Type: clusterType}
 }
 
+// DNSResolverSettings holds the DNS settings of the clusters that
+// resolve their endpoints with DNS. Settings that are not set use
+// Envoy's defaults.
+type DNSResolverSettings struct {
+	// Resolvers are the addresses of the DNS resolvers. If empty,
+	// the resolvers of Envoy's host are used.
+	Resolvers []*envoy_core_v3.Address
+
+	// RefreshRate is how often names are resolved again.
+	RefreshRate time.Duration
+}
+
+// ApplyDNSResolverSettings sets the DNS settings of the cluster, if it
+// resolves its endpoints with DNS.
+func ApplyDNSResolverSettings(cluster *envoy_cluster_v3.Cluster, settings DNSResolverSettings) {
+	switch cluster.GetType() {
+	case envoy_cluster_v3.Cluster_STRICT_DNS, envoy_cluster_v3.Cluster_LOGICAL_DNS:
+	default:
+		return
+	}
+
+	if len(settings.Resolvers) > 0 {
+		cluster.TypedDnsResolverConfig = &envoy_core_v3.TypedExtensionConfig{
+			Name: "envoy.network.dns_resolver.cares",
+			TypedConfig: protobuf.MustMarshalAny(&envoy_cares_v3.CaresDnsResolverConfig{
+				Resolvers: settings.Resolvers,
+			}),
+		}
+	}
+
+	if settings.RefreshRate > 0 {
+		cluster.DnsRefreshRate = durationpb.New(settings.RefreshRate)
+	}
+}
+
+// CircuitBreakerSettings holds the default circuit breaker
+// thresholds of clusters. Zero means Envoy's default.
+type CircuitBreakerSettings struct {
+	MaxConnections     uint32
+	MaxPendingRequests uint32
+	MaxRequests        uint32
+	MaxRetries         uint32
+
+	RetryBudgetPercent             uint32
+	RetryBudgetMinRetryConcurrency uint32
+}
+
+// ApplyCircuitBreakerDefaults sets the thresholds of the cluster's
+// default priority circuit breaker that the cluster doesn't set.
+func ApplyCircuitBreakerDefaults(cluster *envoy_cluster_v3.Cluster, settings CircuitBreakerSettings) {
+	if settings == (CircuitBreakerSettings{}) {
+		return
+	}
+
+	if cluster.CircuitBreakers == nil {
+		cluster.CircuitBreakers = &envoy_cluster_v3.CircuitBreakers{}
+	}
+
+	var thresholds *envoy_cluster_v3.CircuitBreakers_Thresholds
+	for _, t := range cluster.CircuitBreakers.Thresholds {
+		if t.Priority == envoy_core_v3.RoutingPriority_DEFAULT {
+			thresholds = t
+			break
+		}
+	}
+	if thresholds == nil {
+		thresholds = &envoy_cluster_v3.CircuitBreakers_Thresholds{}
+		cluster.CircuitBreakers.Thresholds = append(cluster.CircuitBreakers.Thresholds, thresholds)
+	}
+
+	if thresholds.MaxConnections == nil {
+		thresholds.MaxConnections = protobuf.UInt32OrNil(settings.MaxConnections)
+	}
+	if thresholds.MaxPendingRequests == nil {
+		thresholds.MaxPendingRequests = protobuf.UInt32OrNil(settings.MaxPendingRequests)
+	}
+	if thresholds.MaxRequests == nil {
+		thresholds.MaxRequests = protobuf.UInt32OrNil(settings.MaxRequests)
+	}
+	if thresholds.MaxRetries == nil {
+		thresholds.MaxRetries = protobuf.UInt32OrNil(settings.MaxRetries)
+	}
+	if thresholds.RetryBudget == nil && (settings.RetryBudgetPercent > 0 || settings.RetryBudgetMinRetryConcurrency > 0) {
+		thresholds.RetryBudget = &envoy_cluster_v3.CircuitBreakers_Thresholds_RetryBudget{
+			MinRetryConcurrency: protobuf.UInt32OrNil(settings.RetryBudgetMinRetryConcurrency),
+		}
+		if settings.RetryBudgetPercent > 0 {
+			thresholds.RetryBudget.BudgetPercent = &envoy_type.Percent{Value: float64(settings.RetryBudgetPercent)}
+		}
+	}
+}
+
 // parseDNSLookupFamily parses the dnsLookupFamily string into a envoy_cluster_v3.Cluster_DnsLookupFamily
 func parseDNSLookupFamily(value string) envoy_cluster_v3.Cluster_DnsLookupFamily {
 
@@ -310,7 +454,7 @@ func parseDNSLookupFamily(value string) envoy_cluster_v3.Cluster_DnsLookupFamily
 	return envoy_cluster_v3.Cluster_AUTO
 }
 
-func protocolOptions(explicitHTTPVersion HTTPVersionType, idleConnectionTimeout timeout.Setting, maxRequestsPerConnection *uint32) map[string]*anypb.Any {
+func protocolOptions(explicitHTTPVersion HTTPVersionType, idleConnectionTimeout timeout.Setting, maxRequestsPerConnection *uint32, http2Keepalive *dag.HTTP2KeepaliveConfig, http2Settings *dag.HTTP2Settings) map[string]*anypb.Any {
 	// Keep Envoy defaults by not setting protocol options at all if not necessary.
 	if explicitHTTPVersion == HTTPVersionAuto && idleConnectionTimeout.UseDefault() && maxRequestsPerConnection == nil {
 		return nil
@@ -327,9 +471,32 @@ func protocolOptions(explicitHTTPVersion HTTPVersionType, idleConnectionTimeout
 			},
 		}
 	case HTTPVersion2:
+		var http2ProtocolOptions *envoy_core_v3.Http2ProtocolOptions
+		if http2Keepalive != nil || http2Settings != nil {
+			http2ProtocolOptions = &envoy_core_v3.Http2ProtocolOptions{}
+		}
+		if http2Keepalive != nil {
+			http2ProtocolOptions.ConnectionKeepalive = &envoy_core_v3.KeepaliveSettings{
+				Interval: durationpb.New(http2Keepalive.Interval),
+				Timeout:  durationpb.New(http2Keepalive.Timeout),
+			}
+		}
+		if http2Settings != nil {
+			if v := http2Settings.MaxConcurrentStreams; v != nil {
+				http2ProtocolOptions.MaxConcurrentStreams = wrapperspb.UInt32(*v)
+			}
+			if v := http2Settings.InitialStreamWindowSize; v != nil {
+				http2ProtocolOptions.InitialStreamWindowSize = wrapperspb.UInt32(*v)
+			}
+			if v := http2Settings.InitialConnectionWindowSize; v != nil {
+				http2ProtocolOptions.InitialConnectionWindowSize = wrapperspb.UInt32(*v)
+			}
+		}
 		options.UpstreamProtocolOptions = &envoy_extensions_upstream_http_v3.HttpProtocolOptions_ExplicitHttpConfig_{
 			ExplicitHttpConfig: &envoy_extensions_upstream_http_v3.HttpProtocolOptions_ExplicitHttpConfig{
-				ProtocolConfig: &envoy_extensions_upstream_http_v3.HttpProtocolOptions_ExplicitHttpConfig_Http2ProtocolOptions{},
+				ProtocolConfig: &envoy_extensions_upstream_http_v3.HttpProtocolOptions_ExplicitHttpConfig_Http2ProtocolOptions{
+					Http2ProtocolOptions: http2ProtocolOptions,
+				},
 			},
 		}
 	case HTTPVersion3:
@@ -20,6 +20,8 @@ import (
 	envoy_cluster_v3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
 	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoy_endpoint_v3 "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
+	envoy_aggregate_cluster_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/clusters/aggregate/v3"
+	envoy_cares_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/network/dns_resolver/cares/v3"
 	envoy_extensions_upstream_http_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/upstreams/http/v3"
 	envoy_type "github.com/envoyproxy/go-control-plane/envoy/type/v3"
 	"github.com/projectcontour/contour/internal/dag"
@@ -88,6 +90,21 @@ func TestCluster(t *testing.T) {
 		},
 	}
 
+	svcBackup := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "backup",
+			Namespace: "default",
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{{
+				Name:       "http",
+				Protocol:   "TCP",
+				Port:       443,
+				TargetPort: intstr.FromInt(8080),
+			}},
+		},
+	}
+
 	secret := &dag.Secret{
 		Object: &v1.Secret{
 			ObjectMeta: metav1.ObjectMeta{
@@ -156,6 +173,96 @@ func TestCluster(t *testing.T) {
 				},
 			},
 		},
+		"h2c upstream with http2 keepalive": {
+			cluster: &dag.Cluster{
+				Upstream: service(s1, "h2c"),
+				Protocol: "h2c",
+				HTTP2Keepalive: &dag.HTTP2KeepaliveConfig{
+					Interval: 30 * time.Second,
+					Timeout:  5 * time.Second,
+				},
+			},
+			want: &envoy_cluster_v3.Cluster{
+				Name:                 "default/kuard/443/b0124ba5c2",
+				AltStatName:          "default_kuard_443",
+				ClusterDiscoveryType: ClusterDiscoveryType(envoy_cluster_v3.Cluster_EDS),
+				EdsClusterConfig: &envoy_cluster_v3.Cluster_EdsClusterConfig{
+					EdsConfig:   ConfigSource("contour"),
+					ServiceName: "default/kuard/http",
+				},
+				TypedExtensionProtocolOptions: map[string]*anypb.Any{
+					"envoy.extensions.upstreams.http.v3.HttpProtocolOptions": protobuf.MustMarshalAny(
+						&envoy_extensions_upstream_http_v3.HttpProtocolOptions{
+							UpstreamProtocolOptions: &envoy_extensions_upstream_http_v3.HttpProtocolOptions_ExplicitHttpConfig_{
+								ExplicitHttpConfig: &envoy_extensions_upstream_http_v3.HttpProtocolOptions_ExplicitHttpConfig{
+									ProtocolConfig: &envoy_extensions_upstream_http_v3.HttpProtocolOptions_ExplicitHttpConfig_Http2ProtocolOptions{
+										Http2ProtocolOptions: &envoy_core_v3.Http2ProtocolOptions{
+											ConnectionKeepalive: &envoy_core_v3.KeepaliveSettings{
+												Interval: durationpb.New(30 * time.Second),
+												Timeout:  durationpb.New(5 * time.Second),
+											},
+										},
+									},
+								},
+							},
+						}),
+				},
+			},
+		},
+		"h2c upstream with http2 settings": {
+			cluster: &dag.Cluster{
+				Upstream: service(s1, "h2c"),
+				Protocol: "h2c",
+				HTTP2Settings: &dag.HTTP2Settings{
+					MaxConcurrentStreams:        ref.To(uint32(100)),
+					InitialStreamWindowSize:     ref.To(uint32(65535)),
+					InitialConnectionWindowSize: ref.To(uint32(1048576)),
+				},
+			},
+			want: &envoy_cluster_v3.Cluster{
+				Name:                 "default/kuard/443/f4f94965ec",
+				AltStatName:          "default_kuard_443",
+				ClusterDiscoveryType: ClusterDiscoveryType(envoy_cluster_v3.Cluster_EDS),
+				EdsClusterConfig: &envoy_cluster_v3.Cluster_EdsClusterConfig{
+					EdsConfig:   ConfigSource("contour"),
+					ServiceName: "default/kuard/http",
+				},
+				TypedExtensionProtocolOptions: map[string]*anypb.Any{
+					"envoy.extensions.upstreams.http.v3.HttpProtocolOptions": protobuf.MustMarshalAny(
+						&envoy_extensions_upstream_http_v3.HttpProtocolOptions{
+							UpstreamProtocolOptions: &envoy_extensions_upstream_http_v3.HttpProtocolOptions_ExplicitHttpConfig_{
+								ExplicitHttpConfig: &envoy_extensions_upstream_http_v3.HttpProtocolOptions_ExplicitHttpConfig{
+									ProtocolConfig: &envoy_extensions_upstream_http_v3.HttpProtocolOptions_ExplicitHttpConfig_Http2ProtocolOptions{
+										Http2ProtocolOptions: &envoy_core_v3.Http2ProtocolOptions{
+											MaxConcurrentStreams:        wrapperspb.UInt32(100),
+											InitialStreamWindowSize:     wrapperspb.UInt32(65535),
+											InitialConnectionWindowSize: wrapperspb.UInt32(1048576),
+										},
+									},
+								},
+							},
+						}),
+				},
+			},
+		},
+		"http1 upstream ignores http2 keepalive": {
+			cluster: &dag.Cluster{
+				Upstream: service(s1),
+				HTTP2Keepalive: &dag.HTTP2KeepaliveConfig{
+					Interval: 30 * time.Second,
+					Timeout:  5 * time.Second,
+				},
+			},
+			want: &envoy_cluster_v3.Cluster{
+				Name:                 "default/kuard/443/da39a3ee5e",
+				AltStatName:          "default_kuard_443",
+				ClusterDiscoveryType: ClusterDiscoveryType(envoy_cluster_v3.Cluster_EDS),
+				EdsClusterConfig: &envoy_cluster_v3.Cluster_EdsClusterConfig{
+					EdsConfig:   ConfigSource("contour"),
+					ServiceName: "default/kuard/http",
+				},
+			},
+		},
 		"h2 upstream": {
 			cluster: &dag.Cluster{
 				Upstream: service(s1, "h2"),
@@ -201,7 +308,7 @@ func TestCluster(t *testing.T) {
 				DNSLookupFamily: "v4",
 			},
 			want: &envoy_cluster_v3.Cluster{
-				Name:                 "default/kuard/443/da39a3ee5e",
+				Name:                 "default/kuard/443/8090fd368c",
 				AltStatName:          "default_kuard_443",
 				ClusterDiscoveryType: ClusterDiscoveryType(envoy_cluster_v3.Cluster_STRICT_DNS),
 				LoadAssignment:       ExternalNameClusterLoadAssignment(service(s2)),
@@ -214,7 +321,7 @@ func TestCluster(t *testing.T) {
 				DNSLookupFamily: "v6",
 			},
 			want: &envoy_cluster_v3.Cluster{
-				Name:                 "default/kuard/443/da39a3ee5e",
+				Name:                 "default/kuard/443/39bcc1930b",
 				AltStatName:          "default_kuard_443",
 				ClusterDiscoveryType: ClusterDiscoveryType(envoy_cluster_v3.Cluster_STRICT_DNS),
 				LoadAssignment:       ExternalNameClusterLoadAssignment(service(s2)),
@@ -240,7 +347,7 @@ func TestCluster(t *testing.T) {
 				DNSLookupFamily: "all",
 			},
 			want: &envoy_cluster_v3.Cluster{
-				Name:                 "default/kuard/443/da39a3ee5e",
+				Name:                 "default/kuard/443/d87c448044",
 				AltStatName:          "default_kuard_443",
 				ClusterDiscoveryType: ClusterDiscoveryType(envoy_cluster_v3.Cluster_LOGICAL_DNS),
 				LoadAssignment:       ExternalNameClusterLoadAssignment(service(s2)),
@@ -466,6 +573,89 @@ func TestCluster(t *testing.T) {
 				LbPolicy: envoy_cluster_v3.Cluster_RING_HASH,
 			},
 		},
+		"cluster with failover service": {
+			cluster: &dag.Cluster{
+				Upstream: service(s1),
+				Failover: []*dag.Service{service(svcBackup)},
+			},
+			want: &envoy_cluster_v3.Cluster{
+				Name:                 "default/kuard/443/4965087f46",
+				AltStatName:          "default_kuard_443",
+				ClusterDiscoveryType: ClusterDiscoveryType(envoy_cluster_v3.Cluster_EDS),
+				EdsClusterConfig: &envoy_cluster_v3.Cluster_EdsClusterConfig{
+					EdsConfig:   ConfigSource("contour"),
+					ServiceName: "default/kuard/http;default/backup/http",
+				},
+			},
+		},
+		"cluster with subset": {
+			cluster: &dag.Cluster{
+				Upstream: service(s1),
+				Subset:   map[string]string{"version": "v2", "track": "stable"},
+			},
+			want: &envoy_cluster_v3.Cluster{
+				Name:                 "default/kuard/443/60802bddc0",
+				AltStatName:          "default_kuard_443",
+				ClusterDiscoveryType: ClusterDiscoveryType(envoy_cluster_v3.Cluster_EDS),
+				EdsClusterConfig: &envoy_cluster_v3.Cluster_EdsClusterConfig{
+					EdsConfig:   ConfigSource("contour"),
+					ServiceName: "default/kuard/http|track,version",
+				},
+				LbSubsetConfig: &envoy_cluster_v3.Cluster_LbSubsetConfig{
+					FallbackPolicy: envoy_cluster_v3.Cluster_LbSubsetConfig_NO_FALLBACK,
+					SubsetSelectors: []*envoy_cluster_v3.Cluster_LbSubsetConfig_LbSubsetSelector{{
+						Keys: []string{"track", "version"},
+					}},
+				},
+			},
+		},
+		"cluster with maglev policy": {
+			cluster: &dag.Cluster{
+				Upstream:           service(s1),
+				LoadBalancerPolicy: dag.LoadBalancerPolicyMaglev,
+				MaglevTableSize:    131,
+			},
+			want: &envoy_cluster_v3.Cluster{
+				Name:                 "default/kuard/443/41e3a67428",
+				AltStatName:          "default_kuard_443",
+				ClusterDiscoveryType: ClusterDiscoveryType(envoy_cluster_v3.Cluster_EDS),
+				EdsClusterConfig: &envoy_cluster_v3.Cluster_EdsClusterConfig{
+					EdsConfig:   ConfigSource("contour"),
+					ServiceName: "default/kuard/http",
+				},
+				LbPolicy: envoy_cluster_v3.Cluster_MAGLEV,
+				LbConfig: &envoy_cluster_v3.Cluster_MaglevLbConfig_{
+					MaglevLbConfig: &envoy_cluster_v3.Cluster_MaglevLbConfig{
+						TableSize: wrapperspb.UInt64(131),
+					},
+				},
+			},
+		},
+		"cluster with least request active request bias": {
+			cluster: &dag.Cluster{
+				Upstream:           service(s1),
+				LoadBalancerPolicy: dag.LoadBalancerPolicyWeightedLeastRequest,
+				ActiveRequestBias:  ref.To(1.5),
+			},
+			want: &envoy_cluster_v3.Cluster{
+				Name:                 "default/kuard/443/568d53cb26",
+				AltStatName:          "default_kuard_443",
+				ClusterDiscoveryType: ClusterDiscoveryType(envoy_cluster_v3.Cluster_EDS),
+				EdsClusterConfig: &envoy_cluster_v3.Cluster_EdsClusterConfig{
+					EdsConfig:   ConfigSource("contour"),
+					ServiceName: "default/kuard/http",
+				},
+				LbPolicy: envoy_cluster_v3.Cluster_LEAST_REQUEST,
+				LbConfig: &envoy_cluster_v3.Cluster_LeastRequestLbConfig_{
+					LeastRequestLbConfig: &envoy_cluster_v3.Cluster_LeastRequestLbConfig{
+						ActiveRequestBias: &envoy_core_v3.RuntimeDouble{
+							DefaultValue: 1.5,
+							RuntimeKey:   "contour.leastrequest.activerequestbias",
+						},
+					},
+				},
+			},
+		},
 
 		"tcp service": {
 			cluster: &dag.Cluster{
@@ -536,7 +726,7 @@ func TestCluster(t *testing.T) {
 				TimeoutPolicy: dag.ClusterTimeoutPolicy{ConnectTimeout: 10 * time.Second},
 			},
 			want: &envoy_cluster_v3.Cluster{
-				Name:                 "default/kuard/443/da39a3ee5e",
+				Name:                 "default/kuard/443/cf6df64a38",
 				AltStatName:          "default_kuard_443",
 				ClusterDiscoveryType: ClusterDiscoveryType(envoy_cluster_v3.Cluster_EDS),
 				EdsClusterConfig: &envoy_cluster_v3.Cluster_EdsClusterConfig{
@@ -920,6 +1110,162 @@ func TestDNSNameCluster(t *testing.T) {
 	}
 }
 
+func TestAggregateCluster(t *testing.T) {
+	s1 := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kuard",
+			Namespace: "default",
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{{
+				Name:     "http",
+				Protocol: "TCP",
+				Port:     443,
+			}},
+		},
+	}
+	s2 := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kuard-v2",
+			Namespace: "default",
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{{
+				Name:     "http",
+				Protocol: "TCP",
+				Port:     443,
+			}},
+		},
+	}
+
+	got := AggregateCluster(&dag.AggregateCluster{
+		Clusters: []*dag.Cluster{
+			{Upstream: service(s1)},
+			{Upstream: service(s2), Protocol: "h2c"},
+		},
+	})
+
+	want := clusterDefaults()
+	proto.Merge(want, &envoy_cluster_v3.Cluster{
+		Name:     "aggregate/default/kuard/c7353e8747",
+		LbPolicy: envoy_cluster_v3.Cluster_CLUSTER_PROVIDED,
+		ClusterDiscoveryType: &envoy_cluster_v3.Cluster_ClusterType{
+			ClusterType: &envoy_cluster_v3.Cluster_CustomClusterType{
+				Name: "envoy.clusters.aggregate",
+				TypedConfig: protobuf.MustMarshalAny(&envoy_aggregate_cluster_v3.ClusterConfig{
+					Clusters: []string{
+						"default/kuard/443/da39a3ee5e",
+						"default/kuard-v2/443/f4f94965ec",
+					},
+				}),
+			},
+		},
+	})
+
+	protobuf.ExpectEqual(t, want, got)
+}
+
+func TestApplyDNSResolverSettings(t *testing.T) {
+	settings := DNSResolverSettings{
+		Resolvers:   []*envoy_core_v3.Address{SocketAddress("10.0.0.10", 53)},
+		RefreshRate: 30 * time.Second,
+	}
+
+	strictDNS := &envoy_cluster_v3.Cluster{
+		ClusterDiscoveryType: ClusterDiscoveryType(envoy_cluster_v3.Cluster_STRICT_DNS),
+	}
+	ApplyDNSResolverSettings(strictDNS, settings)
+	protobuf.ExpectEqual(t, &envoy_cluster_v3.Cluster{
+		ClusterDiscoveryType: ClusterDiscoveryType(envoy_cluster_v3.Cluster_STRICT_DNS),
+		TypedDnsResolverConfig: &envoy_core_v3.TypedExtensionConfig{
+			Name: "envoy.network.dns_resolver.cares",
+			TypedConfig: protobuf.MustMarshalAny(&envoy_cares_v3.CaresDnsResolverConfig{
+				Resolvers: []*envoy_core_v3.Address{SocketAddress("10.0.0.10", 53)},
+			}),
+		},
+		DnsRefreshRate: durationpb.New(30 * time.Second),
+	}, strictDNS)
+
+	// The resolvers of Envoy's host are used by default.
+	logicalDNS := &envoy_cluster_v3.Cluster{
+		ClusterDiscoveryType: ClusterDiscoveryType(envoy_cluster_v3.Cluster_LOGICAL_DNS),
+	}
+	ApplyDNSResolverSettings(logicalDNS, DNSResolverSettings{RefreshRate: 30 * time.Second})
+	protobuf.ExpectEqual(t, &envoy_cluster_v3.Cluster{
+		ClusterDiscoveryType: ClusterDiscoveryType(envoy_cluster_v3.Cluster_LOGICAL_DNS),
+		DnsRefreshRate:       durationpb.New(30 * time.Second),
+	}, logicalDNS)
+
+	// Clusters that don't resolve their endpoints with DNS are left alone.
+	eds := &envoy_cluster_v3.Cluster{
+		ClusterDiscoveryType: ClusterDiscoveryType(envoy_cluster_v3.Cluster_EDS),
+	}
+	ApplyDNSResolverSettings(eds, settings)
+	protobuf.ExpectEqual(t, &envoy_cluster_v3.Cluster{
+		ClusterDiscoveryType: ClusterDiscoveryType(envoy_cluster_v3.Cluster_EDS),
+	}, eds)
+}
+
+func TestApplyCircuitBreakerDefaults(t *testing.T) {
+	settings := CircuitBreakerSettings{
+		MaxConnections: 10000,
+		MaxRequests:    20000,
+	}
+
+	cluster := &envoy_cluster_v3.Cluster{}
+	ApplyCircuitBreakerDefaults(cluster, settings)
+	protobuf.ExpectEqual(t, &envoy_cluster_v3.Cluster{
+		CircuitBreakers: &envoy_cluster_v3.CircuitBreakers{
+			Thresholds: []*envoy_cluster_v3.CircuitBreakers_Thresholds{{
+				MaxConnections: wrapperspb.UInt32(10000),
+				MaxRequests:    wrapperspb.UInt32(20000),
+			}},
+		},
+	}, cluster)
+
+	// The thresholds of the service take precedence.
+	cluster = &envoy_cluster_v3.Cluster{
+		CircuitBreakers: &envoy_cluster_v3.CircuitBreakers{
+			Thresholds: []*envoy_cluster_v3.CircuitBreakers_Thresholds{{
+				MaxConnections: wrapperspb.UInt32(100),
+				MaxRetries:     wrapperspb.UInt32(5),
+			}},
+		},
+	}
+	ApplyCircuitBreakerDefaults(cluster, settings)
+	protobuf.ExpectEqual(t, &envoy_cluster_v3.Cluster{
+		CircuitBreakers: &envoy_cluster_v3.CircuitBreakers{
+			Thresholds: []*envoy_cluster_v3.CircuitBreakers_Thresholds{{
+				MaxConnections: wrapperspb.UInt32(100),
+				MaxRequests:    wrapperspb.UInt32(20000),
+				MaxRetries:     wrapperspb.UInt32(5),
+			}},
+		},
+	}, cluster)
+
+	// A retry budget limits the retries to a share of the requests.
+	cluster = &envoy_cluster_v3.Cluster{}
+	ApplyCircuitBreakerDefaults(cluster, CircuitBreakerSettings{
+		RetryBudgetPercent:             25,
+		RetryBudgetMinRetryConcurrency: 5,
+	})
+	protobuf.ExpectEqual(t, &envoy_cluster_v3.Cluster{
+		CircuitBreakers: &envoy_cluster_v3.CircuitBreakers{
+			Thresholds: []*envoy_cluster_v3.CircuitBreakers_Thresholds{{
+				RetryBudget: &envoy_cluster_v3.CircuitBreakers_Thresholds_RetryBudget{
+					BudgetPercent:       &envoy_type.Percent{Value: 25},
+					MinRetryConcurrency: wrapperspb.UInt32(5),
+				},
+			}},
+		},
+	}, cluster)
+
+	// Clusters are left alone when there are no defaults.
+	cluster = &envoy_cluster_v3.Cluster{}
+	ApplyCircuitBreakerDefaults(cluster, CircuitBreakerSettings{})
+	protobuf.ExpectEqual(t, &envoy_cluster_v3.Cluster{}, cluster)
+}
+
 func TestClusterLoadAssignmentName(t *testing.T) {
 	assert.Equal(t, xds.ClusterLoadAssignmentName(types.NamespacedName{Namespace: "ns", Name: "svc"}, "port"), "ns/svc/port")
 	assert.Equal(t, xds.ClusterLoadAssignmentName(types.NamespacedName{Namespace: "ns", Name: "svc"}, ""), "ns/svc")
@@ -1065,11 +1411,11 @@ func TestLBPolicy(t *testing.T) {
 		"unknown":              envoy_cluster_v3.Cluster_ROUND_ROBIN,
 		"Cookie":               envoy_cluster_v3.Cluster_RING_HASH,
 		"RequestHash":          envoy_cluster_v3.Cluster_RING_HASH,
+		"Maglev":               envoy_cluster_v3.Cluster_MAGLEV,
 
-		// RingHash and Maglev were removed as options in 0.13.
+		// RingHash was removed as an option in 0.13.
 		// See #1150
 		"RingHash": envoy_cluster_v3.Cluster_ROUND_ROBIN,
-		"Maglev":   envoy_cluster_v3.Cluster_ROUND_ROBIN,
 	}
 
 	for policy, want := range tests {
@@ -33,6 +33,31 @@ func LBEndpoint(addr *envoy_core_v3.Address) *envoy_endpoint_v3.LbEndpoint {
 	}
 }
 
+// SubsetMetadataNamespace is the Envoy metadata namespace
+// that subset load balancing selects endpoints by.
+const SubsetMetadataNamespace = "envoy.lb"
+
+// SubsetMetadata returns the subset load balancing metadata
+// for the given pod labels, or nil if there are none.
+func SubsetMetadata(labels map[string]string) *envoy_core_v3.Metadata {
+	return stringMetadata(SubsetMetadataNamespace, labels)
+}
+
+// EndpointMetadataNamespace is the Envoy metadata namespace that
+// carries the Kubernetes metadata of an endpoint, for access logs
+// to refer to with %UPSTREAM_METADATA%.
+const EndpointMetadataNamespace = "projectcontour.io"
+
+// EndpointMetadata returns the Kubernetes metadata of an endpoint
+// that is backed by the given pod and selected by the given service.
+func EndpointMetadata(namespace, pod, service string) *envoy_core_v3.Metadata {
+	return stringMetadata(EndpointMetadataNamespace, map[string]string{
+		"namespace": namespace,
+		"pod":       pod,
+		"service":   service,
+	})
+}
+
 // HealthCheckConfig returns an *envoy_endpoint_v3.Endpoint_HealthCheckConfig with a single
 func HealthCheckConfig(healthCheckPort int32) *envoy_endpoint_v3.Endpoint_HealthCheckConfig {
 	if healthCheckPort == 0 {
@@ -0,0 +1,90 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"fmt"
+
+	envoy_router_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/router/v3"
+	http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	contour_api_v1alpha1 "github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/anypb"
+	"sigs.k8s.io/yaml"
+
+	// Register the types of the HTTP filters that Contour does not
+	// configure itself, so that they can be added as additional filters.
+	_ "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/adaptive_concurrency/v3"
+	_ "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/admission_control/v3"
+	_ "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/aws_lambda/v3"
+	_ "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/bandwidth_limit/v3"
+	_ "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/buffer/v3"
+	_ "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/csrf/v3"
+	_ "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/custom_response/v3"
+	_ "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/decompressor/v3"
+	_ "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_proc/v3"
+	_ "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/fault/v3"
+	_ "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/grpc_http1_bridge/v3"
+	_ "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/grpc_http1_reverse_bridge/v3"
+	_ "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/grpc_json_transcoder/v3"
+	_ "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/header_mutation/v3"
+	_ "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/header_to_metadata/v3"
+	_ "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/kill_request/v3"
+	_ "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/oauth2/v3"
+	_ "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/set_metadata/v3"
+	_ "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/wasm/v3"
+)
+
+// AdditionalHTTPFilter is an HTTP filter that is added to the
+// filter chains of the HTTP connection managers at Position.
+type AdditionalHTTPFilter struct {
+	Position contour_api_v1alpha1.HTTPFilterPosition
+	Filter   *http.HttpFilter
+}
+
+// ParseHTTPFilter returns the HTTP filter with the given name and the
+// typed configuration in typedConfig, which is the YAML or JSON
+// representation of a google.protobuf.Any. The type of the
+// configuration must be known, and the configuration must be valid.
+func ParseHTTPFilter(name string, typedConfig string) (*http.HttpFilter, error) {
+	data, err := yaml.YAMLToJSON([]byte(typedConfig))
+	if err != nil {
+		return nil, fmt.Errorf("invalid HTTP filter %q: %w", name, err)
+	}
+
+	config := &anypb.Any{}
+	if err := protojson.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("invalid HTTP filter %q: %w", name, err)
+	}
+
+	msg, err := config.UnmarshalNew()
+	if err != nil {
+		return nil, fmt.Errorf("invalid HTTP filter %q: %w", name, err)
+	}
+	if _, ok := msg.(*envoy_router_v3.Router); ok {
+		return nil, fmt.Errorf("invalid HTTP filter %q: the router filter is always configured", name)
+	}
+	if v, ok := msg.(interface{ ValidateAll() error }); ok {
+		if err := v.ValidateAll(); err != nil {
+			return nil, fmt.Errorf("invalid HTTP filter %q: %w", name, err)
+		}
+	}
+
+	return &http.HttpFilter{
+		Name: name,
+		ConfigType: &http.HttpFilter_TypedConfig{
+			TypedConfig: config,
+		},
+	}, nil
+}
@@ -0,0 +1,88 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"testing"
+
+	envoy_buffer_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/buffer/v3"
+	http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	"github.com/projectcontour/contour/internal/protobuf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestParseHTTPFilter(t *testing.T) {
+	tests := map[string]struct {
+		typedConfig string
+		want        *http.HttpFilter
+		wantErr     string
+	}{
+		"yaml": {
+			typedConfig: `
+"@type": type.googleapis.com/envoy.extensions.filters.http.buffer.v3.Buffer
+max_request_bytes: 1024
+`,
+			want: &http.HttpFilter{
+				Name: "buffer",
+				ConfigType: &http.HttpFilter_TypedConfig{
+					TypedConfig: protobuf.MustMarshalAny(&envoy_buffer_v3.Buffer{
+						MaxRequestBytes: wrapperspb.UInt32(1024),
+					}),
+				},
+			},
+		},
+		"json": {
+			typedConfig: `{"@type": "type.googleapis.com/envoy.extensions.filters.http.buffer.v3.Buffer", "maxRequestBytes": 1024}`,
+			want: &http.HttpFilter{
+				Name: "buffer",
+				ConfigType: &http.HttpFilter_TypedConfig{
+					TypedConfig: protobuf.MustMarshalAny(&envoy_buffer_v3.Buffer{
+						MaxRequestBytes: wrapperspb.UInt32(1024),
+					}),
+				},
+			},
+		},
+		"missing type": {
+			typedConfig: `max_request_bytes: 1024`,
+			wantErr:     `invalid HTTP filter "buffer"`,
+		},
+		"unknown type": {
+			typedConfig: `"@type": type.googleapis.com/envoy.extensions.filters.http.unknown.v3.Unknown`,
+			wantErr:     `invalid HTTP filter "buffer"`,
+		},
+		"invalid config": {
+			typedConfig: `"@type": type.googleapis.com/envoy.extensions.filters.http.buffer.v3.Buffer`,
+			wantErr:     "MaxRequestBytes",
+		},
+		"router": {
+			typedConfig: `"@type": type.googleapis.com/envoy.extensions.filters.http.router.v3.Router`,
+			wantErr:     "the router filter is always configured",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseHTTPFilter("buffer", tc.typedConfig)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			protobuf.ExpectEqual(t, tc.want, got)
+		})
+	}
+}
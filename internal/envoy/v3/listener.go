@@ -16,6 +16,7 @@ package v3
 import (
 	"errors"
 	"fmt"
+	"net"
 	"sort"
 	"strings"
 	"time"
@@ -23,7 +24,9 @@ import (
 	accesslog "github.com/envoyproxy/go-control-plane/envoy/config/accesslog/v3"
 	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoy_listener_v3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	envoy_brotli_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/compression/brotli/compressor/v3"
 	envoy_gzip_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/compression/gzip/compressor/v3"
+	envoy_zstd_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/compression/zstd/compressor/v3"
 	envoy_compressor_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/compressor/v3"
 	envoy_cors_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/cors/v3"
 	envoy_config_filter_http_ext_authz_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_authz/v3"
@@ -45,6 +48,7 @@ import (
 	"github.com/projectcontour/contour/internal/dag"
 	"github.com/projectcontour/contour/internal/envoy"
 	"github.com/projectcontour/contour/internal/protobuf"
+	"github.com/projectcontour/contour/internal/ref"
 	"github.com/projectcontour/contour/internal/sorter"
 	"github.com/projectcontour/contour/internal/timeout"
 	"google.golang.org/protobuf/types/known/durationpb"
@@ -164,17 +168,125 @@ type httpConnectionManagerBuilder struct {
 	maxConnectionDuration         timeout.Setting
 	connectionShutdownGracePeriod timeout.Setting
 	filters                       []*http.HttpFilter
+	additionalFilters             []AdditionalHTTPFilter
+	compression                   CompressionSettings
+	settings                      HTTPConnectionManagerSettings
 	codec                         HTTPVersionType // Note the zero value is AUTO, which is the default we want.
 	allowChunkedLength            bool
 	mergeSlashes                  bool
 	serverHeaderTransformation    http.HttpConnectionManager_ServerHeaderTransformation
 	forwardClientCertificate      *dag.ClientCertificateDetails
 	numTrustedHops                uint32
+	internalAddresses             []string
 	tracingConfig                 *http.HttpConnectionManager_Tracing
 	maxRequestsPerConnection      *uint32
+	http2Settings                 HTTP2Settings
+	localReplyConfig              *http.LocalReplyConfig
 	enableWebsockets              bool
 }
 
+// HTTPConnectionManagerSettings holds settings of the HTTP connection
+// managers. Settings that are not set use Contour's defaults.
+type HTTPConnectionManagerSettings struct {
+	// ServerName is the value of the Server header.
+	// If empty, Envoy's default of "envoy" is used.
+	ServerName string
+
+	// PreserveExternalRequestID defaults to true.
+	PreserveExternalRequestID *bool
+
+	// NormalizePath defaults to true.
+	NormalizePath *bool
+
+	// Via is appended to the Via header of requests
+	// and responses. If empty, Via is left alone.
+	Via string
+}
+
+// CompressionSettings holds the settings of the compressor filter.
+// The zero value compresses the default content types with gzip.
+type CompressionSettings struct {
+	Algorithm        contour_api_v1alpha1.CompressionAlgorithm
+	MinContentLength *uint32
+	ContentTypes     []string
+}
+
+// defaultCompressionContentTypes are the content types
+// that are compressed if none are configured.
+var defaultCompressionContentTypes = []string{
+	// Default content-types https://github.com/envoyproxy/envoy/blob/e74999dbdb12aa4d6b7a5d62d51731ea86bf72be/source/extensions/filters/http/compressor/compressor_filter.cc#L35-L38
+	"text/html", "text/plain", "text/css", "application/javascript", "application/x-javascript",
+	"text/javascript", "text/x-javascript", "text/ecmascript", "text/js", "text/jscript",
+	"text/x-js", "application/ecmascript", "application/x-json", "application/xml",
+	"application/json", "image/svg+xml", "text/xml", "application/xhtml+xml",
+	// Additional content-types for grpc-web https://github.com/grpc/grpc/blob/master/doc/PROTOCOL-WEB.md#protocol-differences-vs-grpc-over-http2
+	"application/grpc-web", "application/grpc-web+proto", "application/grpc-web+json", "application/grpc-web+thrift",
+	"application/grpc-web-text", "application/grpc-web-text+proto", "application/grpc-web-text+thrift",
+}
+
+// compressorFilter returns the compressor filter for the settings,
+// or nil if compression is disabled.
+func compressorFilter(settings CompressionSettings) *http.HttpFilter {
+	var library *envoy_core_v3.TypedExtensionConfig
+	switch settings.Algorithm {
+	case contour_api_v1alpha1.DisabledCompression:
+		return nil
+	case contour_api_v1alpha1.BrotliCompression:
+		library = &envoy_core_v3.TypedExtensionConfig{
+			Name:        "brotli",
+			TypedConfig: protobuf.MustMarshalAny(&envoy_brotli_v3.Brotli{}),
+		}
+	case contour_api_v1alpha1.ZstdCompression:
+		library = &envoy_core_v3.TypedExtensionConfig{
+			Name:        "zstd",
+			TypedConfig: protobuf.MustMarshalAny(&envoy_zstd_v3.Zstd{}),
+		}
+	default:
+		library = &envoy_core_v3.TypedExtensionConfig{
+			Name:        "gzip",
+			TypedConfig: protobuf.MustMarshalAny(&envoy_gzip_v3.Gzip{}),
+		}
+	}
+
+	contentTypes := settings.ContentTypes
+	if len(contentTypes) == 0 {
+		contentTypes = defaultCompressionContentTypes
+	}
+
+	var minContentLength *wrapperspb.UInt32Value
+	if settings.MinContentLength != nil {
+		minContentLength = wrapperspb.UInt32(*settings.MinContentLength)
+	}
+
+	return &http.HttpFilter{
+		Name: "compressor",
+		ConfigType: &http.HttpFilter_TypedConfig{
+			TypedConfig: protobuf.MustMarshalAny(&envoy_compressor_v3.Compressor{
+				CompressorLibrary: library,
+				ResponseDirectionConfig: &envoy_compressor_v3.Compressor_ResponseDirectionConfig{
+					CommonConfig: &envoy_compressor_v3.Compressor_CommonDirectionConfig{
+						MinContentLength: minContentLength,
+						ContentType:      contentTypes,
+					},
+				},
+			}),
+		},
+	}
+}
+
+// HTTP2Settings holds the settings of downstream HTTP/2 connections.
+// Settings that are nil use Envoy's defaults.
+type HTTP2Settings struct {
+	MaxConcurrentStreams                         *uint32
+	InitialStreamWindowSize                      *uint32
+	InitialConnectionWindowSize                  *uint32
+	MaxOutboundFrames                            *uint32
+	MaxOutboundControlFrames                     *uint32
+	MaxConsecutiveInboundFramesWithEmptyPayload  *uint32
+	MaxInboundPriorityFramesPerStream            *uint32
+	MaxInboundWindowUpdateFramesPerDataFrameSent *uint32
+}
+
 func (b *httpConnectionManagerBuilder) EnableWebsockets(enable bool) *httpConnectionManagerBuilder {
 	b.enableWebsockets = enable
 	return b
@@ -262,7 +374,9 @@ func (b *httpConnectionManagerBuilder) ServerHeaderTransformation(value contour_
 		b.serverHeaderTransformation = http.HttpConnectionManager_OVERWRITE
 	case contour_api_v1alpha1.AppendIfAbsentServerHeader:
 		b.serverHeaderTransformation = http.HttpConnectionManager_APPEND_IF_ABSENT
-	case contour_api_v1alpha1.PassThroughServerHeader:
+	case contour_api_v1alpha1.PassThroughServerHeader,
+		// The Server header is removed by the route configurations.
+		contour_api_v1alpha1.RemoveServerHeader:
 		b.serverHeaderTransformation = http.HttpConnectionManager_PASS_THROUGH
 	}
 	return b
@@ -278,45 +392,40 @@ func (b *httpConnectionManagerBuilder) NumTrustedHops(num uint32) *httpConnectio
 	return b
 }
 
+// InternalAddresses sets the address ranges of the clients whose requests
+// are internal, instead of Envoy's default of the RFC 1918 and RFC 4193
+// ranges. Invalid address ranges are ignored.
+func (b *httpConnectionManagerBuilder) InternalAddresses(cidrs []string) *httpConnectionManagerBuilder {
+	b.internalAddresses = cidrs
+	return b
+}
+
 // MaxRequestsPerConnection sets max requests per connection for the downstream.
 func (b *httpConnectionManagerBuilder) MaxRequestsPerConnection(maxRequestsPerConnection *uint32) *httpConnectionManagerBuilder {
 	b.maxRequestsPerConnection = maxRequestsPerConnection
 	return b
 }
 
+// HTTP2Settings sets the settings of downstream HTTP/2 connections.
+func (b *httpConnectionManagerBuilder) HTTP2Settings(settings HTTP2Settings) *httpConnectionManagerBuilder {
+	b.http2Settings = settings
+	return b
+}
+
+// LocalReplyConfig sets the config used to customize
+// the local replies that the connection manager sends.
+func (b *httpConnectionManagerBuilder) LocalReplyConfig(config *http.LocalReplyConfig) *httpConnectionManagerBuilder {
+	b.localReplyConfig = config
+	return b
+}
+
 func (b *httpConnectionManagerBuilder) DefaultFilters() *httpConnectionManagerBuilder {
 
 	// Add a default set of ordered http filters.
 	// The names are not required to match anything and are
 	// identified by the TypeURL of each filter.
 	b.filters = append(b.filters,
-		&http.HttpFilter{
-			Name: "compressor",
-			ConfigType: &http.HttpFilter_TypedConfig{
-				TypedConfig: protobuf.MustMarshalAny(&envoy_compressor_v3.Compressor{
-					CompressorLibrary: &envoy_core_v3.TypedExtensionConfig{
-						Name: "gzip",
-						TypedConfig: protobuf.MustMarshalAny(
-							&envoy_gzip_v3.Gzip{},
-						),
-					},
-					ResponseDirectionConfig: &envoy_compressor_v3.Compressor_ResponseDirectionConfig{
-						CommonConfig: &envoy_compressor_v3.Compressor_CommonDirectionConfig{
-							ContentType: []string{
-								// Default content-types https://github.com/envoyproxy/envoy/blob/e74999dbdb12aa4d6b7a5d62d51731ea86bf72be/source/extensions/filters/http/compressor/compressor_filter.cc#L35-L38
-								"text/html", "text/plain", "text/css", "application/javascript", "application/x-javascript",
-								"text/javascript", "text/x-javascript", "text/ecmascript", "text/js", "text/jscript",
-								"text/x-js", "application/ecmascript", "application/x-json", "application/xml",
-								"application/json", "image/svg+xml", "text/xml", "application/xhtml+xml",
-								// Additional content-types for grpc-web https://github.com/grpc/grpc/blob/master/doc/PROTOCOL-WEB.md#protocol-differences-vs-grpc-over-http2
-								"application/grpc-web", "application/grpc-web+proto", "application/grpc-web+json", "application/grpc-web+thrift",
-								"application/grpc-web-text", "application/grpc-web-text+proto", "application/grpc-web-text+thrift",
-							},
-						},
-					},
-				}),
-			},
-		},
+		compressorFilter(CompressionSettings{}),
 		&http.HttpFilter{
 			Name: "grpcweb",
 			ConfigType: &http.HttpFilter_TypedConfig{
@@ -381,6 +490,27 @@ func (b *httpConnectionManagerBuilder) DefaultFilters() *httpConnectionManagerBu
 	return b
 }
 
+// Settings sets the settings of the HTTP connection manager.
+func (b *httpConnectionManagerBuilder) Settings(settings HTTPConnectionManagerSettings) *httpConnectionManagerBuilder {
+	b.settings = settings
+	return b
+}
+
+// Compression sets the settings of the compressor filter of
+// the default filters.
+func (b *httpConnectionManagerBuilder) Compression(settings CompressionSettings) *httpConnectionManagerBuilder {
+	b.compression = settings
+	return b
+}
+
+// AdditionalFilters sets the additional HTTP filters, which are added
+// to the filter chain at their positions when the HTTPConnectionManager
+// is built, i.e. after all other filters are added.
+func (b *httpConnectionManagerBuilder) AdditionalFilters(filters []AdditionalHTTPFilter) *httpConnectionManagerBuilder {
+	b.additionalFilters = filters
+	return b
+}
+
 // AddFilter appends f to the list of filters for this HTTPConnectionManager. f
 // may be nil, in which case it is ignored. Note that Router filters
 // (filters with TypeUrl `type.googleapis.com/envoy.extensions.filters.http.router.v3.Router`)
@@ -455,6 +585,63 @@ func (b *httpConnectionManagerBuilder) Validate() error {
 	return nil
 }
 
+// httpFilters returns the filter chain with the compressor filter
+// for the compression settings, and the additional filters added at
+// their positions. Filters that go after the CORS filter go before
+// the router if there is no CORS filter.
+func (b *httpConnectionManagerBuilder) httpFilters() []*http.HttpFilter {
+	filters := b.filters
+	if !isDefaultCompression(b.compression) {
+		// Replace the default compressor filter, or
+		// remove it if compression is disabled.
+		filters = make([]*http.HttpFilter, 0, len(b.filters))
+		for _, f := range b.filters {
+			if f.GetTypedConfig().MessageIs(&envoy_compressor_v3.Compressor{}) {
+				f = compressorFilter(b.compression)
+				if f == nil {
+					continue
+				}
+			}
+			filters = append(filters, f)
+		}
+	}
+
+	if len(b.additionalFilters) == 0 {
+		return filters
+	}
+
+	var afterCORS, beforeRouter []*http.HttpFilter
+	for _, f := range b.additionalFilters {
+		if f.Position == contour_api_v1alpha1.AfterCORSHTTPFilterPosition {
+			afterCORS = append(afterCORS, f.Filter)
+		} else {
+			beforeRouter = append(beforeRouter, f.Filter)
+		}
+	}
+
+	// The router filter is always the last one.
+	lastIndex := len(filters) - 1
+	chain := make([]*http.HttpFilter, 0, len(filters)+len(b.additionalFilters))
+	for _, f := range filters[:lastIndex] {
+		chain = append(chain, f)
+		if afterCORS != nil && f.GetTypedConfig().MessageIs(&envoy_cors_v3.Cors{}) {
+			chain = append(chain, afterCORS...)
+			afterCORS = nil
+		}
+	}
+	chain = append(chain, afterCORS...)
+	chain = append(chain, beforeRouter...)
+	return append(chain, filters[lastIndex])
+}
+
+// isDefaultCompression returns whether the settings are
+// those of the compressor filter of the default filters.
+func isDefaultCompression(settings CompressionSettings) bool {
+	return (settings.Algorithm == "" || settings.Algorithm == contour_api_v1alpha1.GzipCompression) &&
+		settings.MinContentLength == nil &&
+		len(settings.ContentTypes) == 0
+}
+
 // Get returns a new http.HttpConnectionManager filter, constructed
 // from the builder settings.
 //
@@ -476,7 +663,7 @@ func (b *httpConnectionManagerBuilder) Get() *envoy_listener_v3.Filter {
 			},
 		},
 		Tracing:     b.tracingConfig,
-		HttpFilters: b.filters,
+		HttpFilters: b.httpFilters(),
 		CommonHttpProtocolOptions: &envoy_core_v3.HttpProtocolOptions{
 			IdleTimeout: envoy.Timeout(b.connectionIdleTimeout),
 		},
@@ -490,12 +677,14 @@ func (b *httpConnectionManagerBuilder) Get() *envoy_listener_v3.Filter {
 		UseRemoteAddress:  wrapperspb.Bool(true),
 		XffNumTrustedHops: b.numTrustedHops,
 
-		NormalizePath: wrapperspb.Bool(true),
+		NormalizePath: wrapperspb.Bool(ref.Val(b.settings.NormalizePath, true)),
 
 		// issue #1487 pass through X-Request-Id if provided.
-		PreserveExternalRequestId:  true,
+		PreserveExternalRequestId:  ref.Val(b.settings.PreserveExternalRequestID, true),
 		MergeSlashes:               b.mergeSlashes,
+		ServerName:                 b.settings.ServerName,
 		ServerHeaderTransformation: b.serverHeaderTransformation,
+		Via:                        b.settings.Via,
 
 		RequestTimeout:      envoy.Timeout(b.requestTimeout),
 		StreamIdleTimeout:   envoy.Timeout(b.streamIdleTimeout),
@@ -515,6 +704,21 @@ func (b *httpConnectionManagerBuilder) Get() *envoy_listener_v3.Filter {
 		cm.AccessLog = b.accessLoggers
 	}
 
+	if len(b.internalAddresses) > 0 {
+		cm.InternalAddressConfig = &http.HttpConnectionManager_InternalAddressConfig{}
+		for _, cidr := range b.internalAddresses {
+			_, ipNet, err := net.ParseCIDR(cidr)
+			if err != nil {
+				continue
+			}
+			prefixLen, _ := ipNet.Mask.Size()
+			cm.InternalAddressConfig.CidrRanges = append(cm.InternalAddressConfig.CidrRanges, &envoy_core_v3.CidrRange{
+				AddressPrefix: ipNet.IP.String(),
+				PrefixLen:     wrapperspb.UInt32(uint32(prefixLen)),
+			})
+		}
+	}
+
 	// If there's no explicit metrics prefix, default it to the
 	// route config name.
 	if b.metricsPrefix != "" {
@@ -538,6 +742,23 @@ func (b *httpConnectionManagerBuilder) Get() *envoy_listener_v3.Filter {
 		cm.CommonHttpProtocolOptions.MaxRequestsPerConnection = wrapperspb.UInt32(*b.maxRequestsPerConnection)
 	}
 
+	if b.http2Settings != (HTTP2Settings{}) {
+		cm.Http2ProtocolOptions = &envoy_core_v3.Http2ProtocolOptions{
+			MaxConcurrentStreams:                         protobuf.UInt32PtrOrNil(b.http2Settings.MaxConcurrentStreams),
+			InitialStreamWindowSize:                      protobuf.UInt32PtrOrNil(b.http2Settings.InitialStreamWindowSize),
+			InitialConnectionWindowSize:                  protobuf.UInt32PtrOrNil(b.http2Settings.InitialConnectionWindowSize),
+			MaxOutboundFrames:                            protobuf.UInt32PtrOrNil(b.http2Settings.MaxOutboundFrames),
+			MaxOutboundControlFrames:                     protobuf.UInt32PtrOrNil(b.http2Settings.MaxOutboundControlFrames),
+			MaxConsecutiveInboundFramesWithEmptyPayload:  protobuf.UInt32PtrOrNil(b.http2Settings.MaxConsecutiveInboundFramesWithEmptyPayload),
+			MaxInboundPriorityFramesPerStream:            protobuf.UInt32PtrOrNil(b.http2Settings.MaxInboundPriorityFramesPerStream),
+			MaxInboundWindowUpdateFramesPerDataFrameSent: protobuf.UInt32PtrOrNil(b.http2Settings.MaxInboundWindowUpdateFramesPerDataFrameSent),
+		}
+	}
+
+	if b.localReplyConfig != nil {
+		cm.LocalReplyConfig = b.localReplyConfig
+	}
+
 	if b.enableWebsockets {
 		cm.UpgradeConfigs = append(cm.UpgradeConfigs,
 			&http.HttpConnectionManager_UpgradeConfig{
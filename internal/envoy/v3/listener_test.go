@@ -20,7 +20,9 @@ import (
 	envoy_accesslog_v3 "github.com/envoyproxy/go-control-plane/envoy/config/accesslog/v3"
 	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoy_listener_v3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	envoy_brotli_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/compression/brotli/compressor/v3"
 	envoy_gzip_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/compression/gzip/compressor/v3"
+	envoy_zstd_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/compression/zstd/compressor/v3"
 	envoy_compressor_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/compressor/v3"
 	envoy_cors_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/cors/v3"
 	envoy_config_filter_http_ext_authz_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_authz/v3"
@@ -292,14 +294,14 @@ func TestDownstreamTLSContext(t *testing.T) {
 	}}
 
 	alpnProtocols := []string{"h2", "http/1.1"}
-	validationContext := &envoy_tls_v3.CommonTlsContext_ValidationContext{
-		ValidationContext: &envoy_tls_v3.CertificateValidationContext{
-			TrustedCa: &envoy_core_v3.DataSource{
-				Specifier: &envoy_core_v3.DataSource_InlineBytes{
-					InlineBytes: ca,
-				},
+
+	validationContextSdsSecretConfig := func(pvc *dag.PeerValidationContext) *envoy_tls_v3.CommonTlsContext_ValidationContextSdsSecretConfig {
+		return &envoy_tls_v3.CommonTlsContext_ValidationContextSdsSecretConfig{
+			ValidationContextSdsSecretConfig: &envoy_tls_v3.SdsSecretConfig{
+				Name:      envoy.ValidationContextSecretname(pvc),
+				SdsConfig: tlsCertificateSdsSecretConfigs[0].SdsConfig,
 			},
-		},
+		}
 	}
 
 	peerValidationContext := &dag.PeerValidationContext{
@@ -335,11 +337,6 @@ func TestDownstreamTLSContext(t *testing.T) {
 	peerValidationContextSkipClientCertValidation := &dag.PeerValidationContext{
 		SkipClientCertValidation: true,
 	}
-	validationContextSkipVerify := &envoy_tls_v3.CommonTlsContext_ValidationContext{
-		ValidationContext: &envoy_tls_v3.CertificateValidationContext{
-			TrustChainVerification: envoy_tls_v3.CertificateValidationContext_ACCEPT_UNTRUSTED,
-		},
-	}
 	peerValidationContextSkipClientCertValidationWithCA := &dag.PeerValidationContext{
 		CACertificate: &dag.Secret{
 			Object: &v1.Secret{
@@ -354,16 +351,6 @@ func TestDownstreamTLSContext(t *testing.T) {
 		},
 		SkipClientCertValidation: true,
 	}
-	validationContextSkipVerifyWithCA := &envoy_tls_v3.CommonTlsContext_ValidationContext{
-		ValidationContext: &envoy_tls_v3.CertificateValidationContext{
-			TrustChainVerification: envoy_tls_v3.CertificateValidationContext_ACCEPT_UNTRUSTED,
-			TrustedCa: &envoy_core_v3.DataSource{
-				Specifier: &envoy_core_v3.DataSource_InlineBytes{
-					InlineBytes: ca,
-				},
-			},
-		},
-	}
 	peerValidationContextOptionalClientCertValidationWithCA := &dag.PeerValidationContext{
 		CACertificate: &dag.Secret{
 			Object: &v1.Secret{
@@ -402,20 +389,6 @@ func TestDownstreamTLSContext(t *testing.T) {
 			},
 		},
 	}
-	validationContextWithCRLCheck := &envoy_tls_v3.CommonTlsContext_ValidationContext{
-		ValidationContext: &envoy_tls_v3.CertificateValidationContext{
-			TrustedCa: &envoy_core_v3.DataSource{
-				Specifier: &envoy_core_v3.DataSource_InlineBytes{
-					InlineBytes: ca,
-				},
-			},
-			Crl: &envoy_core_v3.DataSource{
-				Specifier: &envoy_core_v3.DataSource_InlineBytes{
-					InlineBytes: crl,
-				},
-			},
-		},
-	}
 
 	peerValidationContextWithCRLCheckOnlyLeaf := &dag.PeerValidationContext{
 		CACertificate: &dag.Secret{
@@ -442,28 +415,13 @@ func TestDownstreamTLSContext(t *testing.T) {
 		},
 		OnlyVerifyLeafCertCrl: true,
 	}
-	validationContextWithCRLCheckOnlyLeaf := &envoy_tls_v3.CommonTlsContext_ValidationContext{
-		ValidationContext: &envoy_tls_v3.CertificateValidationContext{
-			TrustedCa: &envoy_core_v3.DataSource{
-				Specifier: &envoy_core_v3.DataSource_InlineBytes{
-					InlineBytes: ca,
-				},
-			},
-			Crl: &envoy_core_v3.DataSource{
-				Specifier: &envoy_core_v3.DataSource_InlineBytes{
-					InlineBytes: crl,
-				},
-			},
-			OnlyVerifyLeafCertCrl: true,
-		},
-	}
 
 	tests := map[string]struct {
 		got  *envoy_tls_v3.DownstreamTlsContext
 		want *envoy_tls_v3.DownstreamTlsContext
 	}{
 		"TLS context without client authentication": {
-			DownstreamTLSContext(serverSecret, envoy_tls_v3.TlsParameters_TLSv1_2, cipherSuites, nil, "h2", "http/1.1"),
+			DownstreamTLSContext(SecretConfig(serverSecret), envoy_tls_v3.TlsParameters_TLSv1_2, cipherSuites, nil, "h2", "http/1.1"),
 			&envoy_tls_v3.DownstreamTlsContext{
 				CommonTlsContext: &envoy_tls_v3.CommonTlsContext{
 					TlsParams:                      tlsParams,
@@ -473,85 +431,85 @@ func TestDownstreamTLSContext(t *testing.T) {
 			},
 		},
 		"TLS context with client authentication": {
-			DownstreamTLSContext(serverSecret, envoy_tls_v3.TlsParameters_TLSv1_2, cipherSuites, peerValidationContext, "h2", "http/1.1"),
+			DownstreamTLSContext(SecretConfig(serverSecret), envoy_tls_v3.TlsParameters_TLSv1_2, cipherSuites, peerValidationContext, "h2", "http/1.1"),
 			&envoy_tls_v3.DownstreamTlsContext{
 				CommonTlsContext: &envoy_tls_v3.CommonTlsContext{
 					TlsParams:                      tlsParams,
 					TlsCertificateSdsSecretConfigs: tlsCertificateSdsSecretConfigs,
 					AlpnProtocols:                  alpnProtocols,
-					ValidationContextType:          validationContext,
+					ValidationContextType:          validationContextSdsSecretConfig(peerValidationContext),
 				},
 				RequireClientCertificate: wrapperspb.Bool(true),
 			},
 		},
 		"Downstream validation shall not support subjectName validation": {
-			DownstreamTLSContext(serverSecret, envoy_tls_v3.TlsParameters_TLSv1_2, cipherSuites, peerValidationContextWithSubjectName, "h2", "http/1.1"),
+			DownstreamTLSContext(SecretConfig(serverSecret), envoy_tls_v3.TlsParameters_TLSv1_2, cipherSuites, peerValidationContextWithSubjectName, "h2", "http/1.1"),
 			&envoy_tls_v3.DownstreamTlsContext{
 				CommonTlsContext: &envoy_tls_v3.CommonTlsContext{
 					TlsParams:                      tlsParams,
 					TlsCertificateSdsSecretConfigs: tlsCertificateSdsSecretConfigs,
 					AlpnProtocols:                  alpnProtocols,
-					ValidationContextType:          validationContext,
+					ValidationContextType:          validationContextSdsSecretConfig(peerValidationContextWithSubjectName),
 				},
 				RequireClientCertificate: wrapperspb.Bool(true),
 			},
 		},
 		"skip client cert validation": {
-			DownstreamTLSContext(serverSecret, envoy_tls_v3.TlsParameters_TLSv1_2, cipherSuites, peerValidationContextSkipClientCertValidation, "h2", "http/1.1"),
+			DownstreamTLSContext(SecretConfig(serverSecret), envoy_tls_v3.TlsParameters_TLSv1_2, cipherSuites, peerValidationContextSkipClientCertValidation, "h2", "http/1.1"),
 			&envoy_tls_v3.DownstreamTlsContext{
 				CommonTlsContext: &envoy_tls_v3.CommonTlsContext{
 					TlsParams:                      tlsParams,
 					TlsCertificateSdsSecretConfigs: tlsCertificateSdsSecretConfigs,
 					AlpnProtocols:                  alpnProtocols,
-					ValidationContextType:          validationContextSkipVerify,
+					ValidationContextType:          validationContextSdsSecretConfig(peerValidationContextSkipClientCertValidation),
 				},
 				RequireClientCertificate: wrapperspb.Bool(true),
 			},
 		},
 		"skip client cert validation with ca": {
-			DownstreamTLSContext(serverSecret, envoy_tls_v3.TlsParameters_TLSv1_2, cipherSuites, peerValidationContextSkipClientCertValidationWithCA, "h2", "http/1.1"),
+			DownstreamTLSContext(SecretConfig(serverSecret), envoy_tls_v3.TlsParameters_TLSv1_2, cipherSuites, peerValidationContextSkipClientCertValidationWithCA, "h2", "http/1.1"),
 			&envoy_tls_v3.DownstreamTlsContext{
 				CommonTlsContext: &envoy_tls_v3.CommonTlsContext{
 					TlsParams:                      tlsParams,
 					TlsCertificateSdsSecretConfigs: tlsCertificateSdsSecretConfigs,
 					AlpnProtocols:                  alpnProtocols,
-					ValidationContextType:          validationContextSkipVerifyWithCA,
+					ValidationContextType:          validationContextSdsSecretConfig(peerValidationContextSkipClientCertValidationWithCA),
 				},
 				RequireClientCertificate: wrapperspb.Bool(true),
 			},
 		},
 		"optional client cert validation with ca": {
-			DownstreamTLSContext(serverSecret, envoy_tls_v3.TlsParameters_TLSv1_2, cipherSuites, peerValidationContextOptionalClientCertValidationWithCA, "h2", "http/1.1"),
+			DownstreamTLSContext(SecretConfig(serverSecret), envoy_tls_v3.TlsParameters_TLSv1_2, cipherSuites, peerValidationContextOptionalClientCertValidationWithCA, "h2", "http/1.1"),
 			&envoy_tls_v3.DownstreamTlsContext{
 				CommonTlsContext: &envoy_tls_v3.CommonTlsContext{
 					TlsParams:                      tlsParams,
 					TlsCertificateSdsSecretConfigs: tlsCertificateSdsSecretConfigs,
 					AlpnProtocols:                  alpnProtocols,
-					ValidationContextType:          validationContext,
+					ValidationContextType:          validationContextSdsSecretConfig(peerValidationContextOptionalClientCertValidationWithCA),
 				},
 				RequireClientCertificate: wrapperspb.Bool(false),
 			},
 		},
 		"Downstream validation with CRL check": {
-			DownstreamTLSContext(serverSecret, envoy_tls_v3.TlsParameters_TLSv1_2, cipherSuites, peerValidationContextWithCRLCheck, "h2", "http/1.1"),
+			DownstreamTLSContext(SecretConfig(serverSecret), envoy_tls_v3.TlsParameters_TLSv1_2, cipherSuites, peerValidationContextWithCRLCheck, "h2", "http/1.1"),
 			&envoy_tls_v3.DownstreamTlsContext{
 				CommonTlsContext: &envoy_tls_v3.CommonTlsContext{
 					TlsParams:                      tlsParams,
 					TlsCertificateSdsSecretConfigs: tlsCertificateSdsSecretConfigs,
 					AlpnProtocols:                  alpnProtocols,
-					ValidationContextType:          validationContextWithCRLCheck,
+					ValidationContextType:          validationContextSdsSecretConfig(peerValidationContextWithCRLCheck),
 				},
 				RequireClientCertificate: wrapperspb.Bool(true),
 			},
 		},
 		"Downstream validation with CRL check but only for leaf-certificate": {
-			DownstreamTLSContext(serverSecret, envoy_tls_v3.TlsParameters_TLSv1_2, cipherSuites, peerValidationContextWithCRLCheckOnlyLeaf, "h2", "http/1.1"),
+			DownstreamTLSContext(SecretConfig(serverSecret), envoy_tls_v3.TlsParameters_TLSv1_2, cipherSuites, peerValidationContextWithCRLCheckOnlyLeaf, "h2", "http/1.1"),
 			&envoy_tls_v3.DownstreamTlsContext{
 				CommonTlsContext: &envoy_tls_v3.CommonTlsContext{
 					TlsParams:                      tlsParams,
 					TlsCertificateSdsSecretConfigs: tlsCertificateSdsSecretConfigs,
 					AlpnProtocols:                  alpnProtocols,
-					ValidationContextType:          validationContextWithCRLCheckOnlyLeaf,
+					ValidationContextType:          validationContextSdsSecretConfig(peerValidationContextWithCRLCheckOnlyLeaf),
 				},
 				RequireClientCertificate: wrapperspb.Bool(true),
 			},
@@ -651,7 +609,10 @@ func TestHTTPConnectionManager(t *testing.T) {
 		serverHeaderTranformation     v1alpha1.ServerHeaderTransformationType
 		forwardClientCertificate      *dag.ClientCertificateDetails
 		xffNumTrustedHops             uint32
+		internalAddresses             []string
 		maxRequestsPerConnection      *uint32
+		http2Settings                 HTTP2Settings
+		settings                      HTTPConnectionManagerSettings
 		want                          *envoy_listener_v3.Filter
 	}{
 		"default": {
@@ -1181,6 +1142,53 @@ func TestHTTPConnectionManager(t *testing.T) {
 				},
 			},
 		},
+		"server header transform set to remove": {
+			routename:                 "default/kuard",
+			accesslogger:              FileAccessLogEnvoy("/dev/stdout", "", nil, v1alpha1.LogLevelInfo),
+			serverHeaderTranformation: v1alpha1.RemoveServerHeader,
+			want: &envoy_listener_v3.Filter{
+				Name: wellknown.HTTPConnectionManager,
+				ConfigType: &envoy_listener_v3.Filter_TypedConfig{
+					TypedConfig: protobuf.MustMarshalAny(&http.HttpConnectionManager{
+						StatPrefix: "default/kuard",
+						RouteSpecifier: &http.HttpConnectionManager_Rds{
+							Rds: &http.Rds{
+								RouteConfigName: "default/kuard",
+								ConfigSource: &envoy_core_v3.ConfigSource{
+									ResourceApiVersion: envoy_core_v3.ApiVersion_V3,
+									ConfigSourceSpecifier: &envoy_core_v3.ConfigSource_ApiConfigSource{
+										ApiConfigSource: &envoy_core_v3.ApiConfigSource{
+											ApiType:             envoy_core_v3.ApiConfigSource_GRPC,
+											TransportApiVersion: envoy_core_v3.ApiVersion_V3,
+											GrpcServices: []*envoy_core_v3.GrpcService{{
+												TargetSpecifier: &envoy_core_v3.GrpcService_EnvoyGrpc_{
+													EnvoyGrpc: &envoy_core_v3.GrpcService_EnvoyGrpc{
+														ClusterName: "contour",
+														Authority:   "contour",
+													},
+												},
+											}},
+										},
+									},
+								},
+							},
+						},
+						HttpFilters: defaultHTTPFilters,
+						HttpProtocolOptions: &envoy_core_v3.Http1ProtocolOptions{
+							// Enable support for HTTP/1.0 requests that carry
+							// a Host: header. See #537.
+							AcceptHttp_10: true,
+						},
+						CommonHttpProtocolOptions:  &envoy_core_v3.HttpProtocolOptions{},
+						AccessLog:                  FileAccessLogEnvoy("/dev/stdout", "", nil, v1alpha1.LogLevelInfo),
+						UseRemoteAddress:           wrapperspb.Bool(true),
+						NormalizePath:              wrapperspb.Bool(true),
+						PreserveExternalRequestId:  true,
+						ServerHeaderTransformation: http.HttpConnectionManager_PASS_THROUGH,
+					}),
+				},
+			},
+		},
 		"enable xfcc": {
 			routename:    "default/kuard",
 			accesslogger: FileAccessLogEnvoy("/dev/stdout", "", nil, v1alpha1.LogLevelInfo),
@@ -1347,6 +1355,62 @@ func TestHTTPConnectionManager(t *testing.T) {
 				},
 			},
 		},
+		"internal addresses set": {
+			routename:         "default/kuard",
+			accesslogger:      FileAccessLogEnvoy("/dev/stdout", "", nil, v1alpha1.LogLevelInfo),
+			internalAddresses: []string{"10.0.0.0/8", "fd00::/8"},
+			want: &envoy_listener_v3.Filter{
+				Name: wellknown.HTTPConnectionManager,
+				ConfigType: &envoy_listener_v3.Filter_TypedConfig{
+					TypedConfig: protobuf.MustMarshalAny(&http.HttpConnectionManager{
+						StatPrefix: "default/kuard",
+						RouteSpecifier: &http.HttpConnectionManager_Rds{
+							Rds: &http.Rds{
+								RouteConfigName: "default/kuard",
+								ConfigSource: &envoy_core_v3.ConfigSource{
+									ResourceApiVersion: envoy_core_v3.ApiVersion_V3,
+									ConfigSourceSpecifier: &envoy_core_v3.ConfigSource_ApiConfigSource{
+										ApiConfigSource: &envoy_core_v3.ApiConfigSource{
+											ApiType:             envoy_core_v3.ApiConfigSource_GRPC,
+											TransportApiVersion: envoy_core_v3.ApiVersion_V3,
+											GrpcServices: []*envoy_core_v3.GrpcService{{
+												TargetSpecifier: &envoy_core_v3.GrpcService_EnvoyGrpc_{
+													EnvoyGrpc: &envoy_core_v3.GrpcService_EnvoyGrpc{
+														ClusterName: "contour",
+														Authority:   "contour",
+													},
+												},
+											}},
+										},
+									},
+								},
+							},
+						},
+						HttpFilters: defaultHTTPFilters,
+						HttpProtocolOptions: &envoy_core_v3.Http1ProtocolOptions{
+							// Enable support for HTTP/1.0 requests that carry
+							// a Host: header. See #537.
+							AcceptHttp_10: true,
+						},
+						CommonHttpProtocolOptions: &envoy_core_v3.HttpProtocolOptions{},
+						AccessLog:                 FileAccessLogEnvoy("/dev/stdout", "", nil, v1alpha1.LogLevelInfo),
+						UseRemoteAddress:          wrapperspb.Bool(true),
+						InternalAddressConfig: &http.HttpConnectionManager_InternalAddressConfig{
+							CidrRanges: []*envoy_core_v3.CidrRange{{
+								AddressPrefix: "10.0.0.0",
+								PrefixLen:     wrapperspb.UInt32(8),
+							}, {
+								AddressPrefix: "fd00::",
+								PrefixLen:     wrapperspb.UInt32(8),
+							}},
+						},
+						NormalizePath:             wrapperspb.Bool(true),
+						PreserveExternalRequestId: true,
+						MergeSlashes:              false,
+					}),
+				},
+			},
+		},
 		"maxRequestsPerConnection set to 1": {
 			routename:                "default/kuard",
 			accesslogger:             FileAccessLogEnvoy("/dev/stdout", "", nil, v1alpha1.LogLevelInfo),
@@ -1396,6 +1460,115 @@ func TestHTTPConnectionManager(t *testing.T) {
 				},
 			},
 		},
+		"http2 settings set": {
+			routename:    "default/kuard",
+			accesslogger: FileAccessLogEnvoy("/dev/stdout", "", nil, v1alpha1.LogLevelInfo),
+			http2Settings: HTTP2Settings{
+				MaxConcurrentStreams:              ref.To(uint32(100)),
+				InitialStreamWindowSize:           ref.To(uint32(65535)),
+				MaxInboundPriorityFramesPerStream: ref.To(uint32(0)),
+			},
+			want: &envoy_listener_v3.Filter{
+				Name: wellknown.HTTPConnectionManager,
+				ConfigType: &envoy_listener_v3.Filter_TypedConfig{
+					TypedConfig: protobuf.MustMarshalAny(&http.HttpConnectionManager{
+						StatPrefix: "default/kuard",
+						RouteSpecifier: &http.HttpConnectionManager_Rds{
+							Rds: &http.Rds{
+								RouteConfigName: "default/kuard",
+								ConfigSource: &envoy_core_v3.ConfigSource{
+									ResourceApiVersion: envoy_core_v3.ApiVersion_V3,
+									ConfigSourceSpecifier: &envoy_core_v3.ConfigSource_ApiConfigSource{
+										ApiConfigSource: &envoy_core_v3.ApiConfigSource{
+											ApiType:             envoy_core_v3.ApiConfigSource_GRPC,
+											TransportApiVersion: envoy_core_v3.ApiVersion_V3,
+											GrpcServices: []*envoy_core_v3.GrpcService{{
+												TargetSpecifier: &envoy_core_v3.GrpcService_EnvoyGrpc_{
+													EnvoyGrpc: &envoy_core_v3.GrpcService_EnvoyGrpc{
+														ClusterName: "contour",
+														Authority:   "contour",
+													},
+												},
+											}},
+										},
+									},
+								},
+							},
+						},
+						HttpFilters: defaultHTTPFilters,
+						HttpProtocolOptions: &envoy_core_v3.Http1ProtocolOptions{
+							// Enable support for HTTP/1.0 requests that carry
+							// a Host: header. See #537.
+							AcceptHttp_10: true,
+						},
+						CommonHttpProtocolOptions: &envoy_core_v3.HttpProtocolOptions{},
+						Http2ProtocolOptions: &envoy_core_v3.Http2ProtocolOptions{
+							MaxConcurrentStreams:              wrapperspb.UInt32(100),
+							InitialStreamWindowSize:           wrapperspb.UInt32(65535),
+							MaxInboundPriorityFramesPerStream: wrapperspb.UInt32(0),
+						},
+						AccessLog:                 FileAccessLogEnvoy("/dev/stdout", "", nil, v1alpha1.LogLevelInfo),
+						UseRemoteAddress:          wrapperspb.Bool(true),
+						NormalizePath:             wrapperspb.Bool(true),
+						PreserveExternalRequestId: true,
+						MergeSlashes:              false,
+					}),
+				},
+			},
+		},
+		"http connection manager settings": {
+			routename:    "default/kuard",
+			accesslogger: FileAccessLogEnvoy("/dev/stdout", "", nil, v1alpha1.LogLevelInfo),
+			settings: HTTPConnectionManagerSettings{
+				ServerName:                "example",
+				PreserveExternalRequestID: ref.To(false),
+				NormalizePath:             ref.To(false),
+				Via:                       "1.1 example",
+			},
+			want: &envoy_listener_v3.Filter{
+				Name: wellknown.HTTPConnectionManager,
+				ConfigType: &envoy_listener_v3.Filter_TypedConfig{
+					TypedConfig: protobuf.MustMarshalAny(&http.HttpConnectionManager{
+						StatPrefix: "default/kuard",
+						RouteSpecifier: &http.HttpConnectionManager_Rds{
+							Rds: &http.Rds{
+								RouteConfigName: "default/kuard",
+								ConfigSource: &envoy_core_v3.ConfigSource{
+									ResourceApiVersion: envoy_core_v3.ApiVersion_V3,
+									ConfigSourceSpecifier: &envoy_core_v3.ConfigSource_ApiConfigSource{
+										ApiConfigSource: &envoy_core_v3.ApiConfigSource{
+											ApiType:             envoy_core_v3.ApiConfigSource_GRPC,
+											TransportApiVersion: envoy_core_v3.ApiVersion_V3,
+											GrpcServices: []*envoy_core_v3.GrpcService{{
+												TargetSpecifier: &envoy_core_v3.GrpcService_EnvoyGrpc_{
+													EnvoyGrpc: &envoy_core_v3.GrpcService_EnvoyGrpc{
+														ClusterName: "contour",
+														Authority:   "contour",
+													},
+												},
+											}},
+										},
+									},
+								},
+							},
+						},
+						HttpFilters: defaultHTTPFilters,
+						HttpProtocolOptions: &envoy_core_v3.Http1ProtocolOptions{
+							// Enable support for HTTP/1.0 requests that carry
+							// a Host: header. See #537.
+							AcceptHttp_10: true,
+						},
+						CommonHttpProtocolOptions: &envoy_core_v3.HttpProtocolOptions{},
+						AccessLog:                 FileAccessLogEnvoy("/dev/stdout", "", nil, v1alpha1.LogLevelInfo),
+						UseRemoteAddress:          wrapperspb.Bool(true),
+						NormalizePath:             wrapperspb.Bool(false),
+						ServerName:                "example",
+						Via:                       "1.1 example",
+						MergeSlashes:              false,
+					}),
+				},
+			},
+		},
 	}
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -1413,8 +1586,11 @@ func TestHTTPConnectionManager(t *testing.T) {
 				MergeSlashes(tc.mergeSlashes).
 				ServerHeaderTransformation(tc.serverHeaderTranformation).
 				NumTrustedHops(tc.xffNumTrustedHops).
+				InternalAddresses(tc.internalAddresses).
 				ForwardClientCertificate(tc.forwardClientCertificate).
 				MaxRequestsPerConnection(tc.maxRequestsPerConnection).
+				HTTP2Settings(tc.http2Settings).
+				Settings(tc.settings).
 				DefaultFilters().
 				Get()
 
@@ -2004,3 +2180,145 @@ func TestAddFilter(t *testing.T) {
 		})
 	})
 }
+
+func TestAdditionalFilters(t *testing.T) {
+	buffer, err := ParseHTTPFilter("buffer", `
+"@type": type.googleapis.com/envoy.extensions.filters.http.buffer.v3.Buffer
+max_request_bytes: 1048576
+`)
+	require.NoError(t, err)
+	csrf, err := ParseHTTPFilter("csrf", `{
+  "@type": "type.googleapis.com/envoy.extensions.filters.http.csrf.v3.CsrfPolicy",
+  "filter_enabled": {"default_value": {"numerator": 100}}
+}`)
+	require.NoError(t, err)
+
+	filter := HTTPConnectionManagerBuilder().
+		RouteConfigName("default/kuard").
+		MetricsPrefix("default/kuard").
+		DefaultFilters().
+		AdditionalFilters([]AdditionalHTTPFilter{
+			{Position: v1alpha1.BeforeRouterHTTPFilterPosition, Filter: buffer},
+			{Position: v1alpha1.AfterCORSHTTPFilterPosition, Filter: csrf},
+		}).
+		AddFilter(FilterExternalAuthz(&dag.ExternalAuthorization{
+			AuthorizationService: &dag.ExtensionCluster{Name: "test"},
+		})).
+		Get()
+
+	hcm := &http.HttpConnectionManager{}
+	require.NoError(t, filter.GetTypedConfig().UnmarshalTo(hcm))
+
+	var names []string
+	for _, f := range hcm.HttpFilters {
+		names = append(names, f.Name)
+	}
+	assert.Equal(t, []string{
+		"compressor",
+		"grpcweb",
+		"grpc_stats",
+		"cors",
+		"csrf",
+		"local_ratelimit",
+		"envoy.filters.http.lua",
+		"envoy.filters.http.rbac",
+		"envoy.filters.http.ext_authz",
+		"buffer",
+		"router",
+	}, names)
+}
+
+func TestCompression(t *testing.T) {
+	tests := map[string]struct {
+		settings CompressionSettings
+		want     *http.HttpFilter
+	}{
+		"gzip": {
+			settings: CompressionSettings{Algorithm: v1alpha1.GzipCompression},
+			want: &http.HttpFilter{
+				Name: "compressor",
+				ConfigType: &http.HttpFilter_TypedConfig{
+					TypedConfig: protobuf.MustMarshalAny(&envoy_compressor_v3.Compressor{
+						CompressorLibrary: &envoy_core_v3.TypedExtensionConfig{
+							Name:        "gzip",
+							TypedConfig: protobuf.MustMarshalAny(&envoy_gzip_v3.Gzip{}),
+						},
+						ResponseDirectionConfig: &envoy_compressor_v3.Compressor_ResponseDirectionConfig{
+							CommonConfig: &envoy_compressor_v3.Compressor_CommonDirectionConfig{
+								ContentType: compressorContentTypes,
+							},
+						},
+					}),
+				},
+			},
+		},
+		"brotli": {
+			settings: CompressionSettings{
+				Algorithm:        v1alpha1.BrotliCompression,
+				MinContentLength: ref.To(uint32(1024)),
+			},
+			want: &http.HttpFilter{
+				Name: "compressor",
+				ConfigType: &http.HttpFilter_TypedConfig{
+					TypedConfig: protobuf.MustMarshalAny(&envoy_compressor_v3.Compressor{
+						CompressorLibrary: &envoy_core_v3.TypedExtensionConfig{
+							Name:        "brotli",
+							TypedConfig: protobuf.MustMarshalAny(&envoy_brotli_v3.Brotli{}),
+						},
+						ResponseDirectionConfig: &envoy_compressor_v3.Compressor_ResponseDirectionConfig{
+							CommonConfig: &envoy_compressor_v3.Compressor_CommonDirectionConfig{
+								MinContentLength: wrapperspb.UInt32(1024),
+								ContentType:      compressorContentTypes,
+							},
+						},
+					}),
+				},
+			},
+		},
+		"zstd": {
+			settings: CompressionSettings{
+				Algorithm:    v1alpha1.ZstdCompression,
+				ContentTypes: []string{"application/json"},
+			},
+			want: &http.HttpFilter{
+				Name: "compressor",
+				ConfigType: &http.HttpFilter_TypedConfig{
+					TypedConfig: protobuf.MustMarshalAny(&envoy_compressor_v3.Compressor{
+						CompressorLibrary: &envoy_core_v3.TypedExtensionConfig{
+							Name:        "zstd",
+							TypedConfig: protobuf.MustMarshalAny(&envoy_zstd_v3.Zstd{}),
+						},
+						ResponseDirectionConfig: &envoy_compressor_v3.Compressor_ResponseDirectionConfig{
+							CommonConfig: &envoy_compressor_v3.Compressor_CommonDirectionConfig{
+								ContentType: []string{"application/json"},
+							},
+						},
+					}),
+				},
+			},
+		},
+		"disabled": {
+			settings: CompressionSettings{Algorithm: v1alpha1.DisabledCompression},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			filter := HTTPConnectionManagerBuilder().
+				RouteConfigName("default/kuard").
+				MetricsPrefix("default/kuard").
+				DefaultFilters().
+				Compression(tc.settings).
+				Get()
+
+			hcm := &http.HttpConnectionManager{}
+			require.NoError(t, filter.GetTypedConfig().UnmarshalTo(hcm))
+
+			if tc.want == nil {
+				assert.Equal(t, "grpcweb", hcm.HttpFilters[0].Name)
+				return
+			}
+			protobuf.ExpectEqual(t, tc.want, hcm.HttpFilters[0])
+		})
+	}
+}
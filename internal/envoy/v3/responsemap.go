@@ -0,0 +1,127 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"fmt"
+
+	envoy_accesslog_v3 "github.com/envoyproxy/go-control-plane/envoy/config/accesslog/v3"
+	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// ResponseMapping customizes the local replies with a given status code.
+type ResponseMapping struct {
+	// StatusCode is the status code of the replies.
+	StatusCode uint32
+
+	// RewriteStatusCode replaces the status code
+	// of the replies, if it isn't zero.
+	RewriteStatusCode uint32
+
+	// Body replaces the body of the replies, if it isn't empty.
+	Body string
+
+	// ContentType is the content type of the replies. It
+	// defaults to text/plain, or application/json for JSONFormat.
+	ContentType string
+
+	// JSONFormat renders the replies as JSON objects.
+	JSONFormat map[string]string
+}
+
+// ResponseMapConfig returns the given local reply config with mappers
+// for the given response mappings appended, so that more specific
+// mappers, e.g. those of timeout replies, take precedence.
+func ResponseMapConfig(config *http.LocalReplyConfig, mappings []ResponseMapping) *http.LocalReplyConfig {
+	if len(mappings) == 0 {
+		return config
+	}
+
+	if config == nil {
+		config = &http.LocalReplyConfig{}
+	}
+
+	for _, m := range mappings {
+		config.Mappers = append(config.Mappers, responseMapper(m))
+	}
+
+	return config
+}
+
+// responseMapper returns the local reply mapper of the given mapping.
+func responseMapper(m ResponseMapping) *http.ResponseMapper {
+	mapper := &http.ResponseMapper{
+		Filter: &envoy_accesslog_v3.AccessLogFilter{
+			FilterSpecifier: &envoy_accesslog_v3.AccessLogFilter_StatusCodeFilter{
+				StatusCodeFilter: &envoy_accesslog_v3.StatusCodeFilter{
+					Comparison: &envoy_accesslog_v3.ComparisonFilter{
+						Op: envoy_accesslog_v3.ComparisonFilter_EQ,
+						Value: &envoy_core_v3.RuntimeUInt32{
+							DefaultValue: m.StatusCode,
+							RuntimeKey:   fmt.Sprintf("contour.response_map.status_code_%d", m.StatusCode),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if m.RewriteStatusCode > 0 {
+		mapper.StatusCode = wrapperspb.UInt32(m.RewriteStatusCode)
+	}
+
+	if m.Body != "" {
+		mapper.Body = &envoy_core_v3.DataSource{
+			Specifier: &envoy_core_v3.DataSource_InlineString{
+				InlineString: m.Body,
+			},
+		}
+	}
+
+	switch {
+	case len(m.JSONFormat) > 0:
+		fields := map[string]*structpb.Value{}
+		for key, value := range m.JSONFormat {
+			fields[key] = structpb.NewStringValue(value)
+		}
+
+		mapper.BodyFormatOverride = &envoy_core_v3.SubstitutionFormatString{
+			Format: &envoy_core_v3.SubstitutionFormatString_JsonFormat{
+				JsonFormat: &structpb.Struct{Fields: fields},
+			},
+			ContentType: m.ContentType,
+		}
+	case m.Body != "":
+		contentType := m.ContentType
+		if contentType == "" {
+			contentType = "text/plain"
+		}
+
+		mapper.BodyFormatOverride = &envoy_core_v3.SubstitutionFormatString{
+			Format: &envoy_core_v3.SubstitutionFormatString_TextFormatSource{
+				TextFormatSource: &envoy_core_v3.DataSource{
+					Specifier: &envoy_core_v3.DataSource_InlineString{
+						InlineString: "%LOCAL_REPLY_BODY%",
+					},
+				},
+			},
+			ContentType: contentType,
+		}
+	}
+
+	return mapper
+}
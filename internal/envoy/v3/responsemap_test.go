@@ -0,0 +1,135 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"testing"
+
+	envoy_accesslog_v3 "github.com/envoyproxy/go-control-plane/envoy/config/accesslog/v3"
+	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	"github.com/projectcontour/contour/internal/protobuf"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestResponseMapConfig(t *testing.T) {
+	statusFilter := func(code uint32, key string) *envoy_accesslog_v3.AccessLogFilter {
+		return &envoy_accesslog_v3.AccessLogFilter{
+			FilterSpecifier: &envoy_accesslog_v3.AccessLogFilter_StatusCodeFilter{
+				StatusCodeFilter: &envoy_accesslog_v3.StatusCodeFilter{
+					Comparison: &envoy_accesslog_v3.ComparisonFilter{
+						Op: envoy_accesslog_v3.ComparisonFilter_EQ,
+						Value: &envoy_core_v3.RuntimeUInt32{
+							DefaultValue: code,
+							RuntimeKey:   key,
+						},
+					},
+				},
+			},
+		}
+	}
+
+	timeoutMapper := &http.ResponseMapper{
+		Filter: &envoy_accesslog_v3.AccessLogFilter{
+			FilterSpecifier: &envoy_accesslog_v3.AccessLogFilter_ResponseFlagFilter{
+				ResponseFlagFilter: &envoy_accesslog_v3.ResponseFlagFilter{
+					Flags: []string{"UT"},
+				},
+			},
+		},
+		StatusCode: wrapperspb.UInt32(503),
+	}
+
+	mappings := []ResponseMapping{{
+		StatusCode:        404,
+		RewriteStatusCode: 410,
+	}, {
+		StatusCode: 503,
+		Body:       "unavailable",
+	}, {
+		StatusCode:  502,
+		Body:        "bad gateway",
+		ContentType: "application/problem+json",
+		JSONFormat: map[string]string{
+			"code":  "%RESPONSE_CODE%",
+			"error": "%LOCAL_REPLY_BODY%",
+		},
+	}}
+
+	mappers := []*http.ResponseMapper{{
+		Filter:     statusFilter(404, "contour.response_map.status_code_404"),
+		StatusCode: wrapperspb.UInt32(410),
+	}, {
+		Filter: statusFilter(503, "contour.response_map.status_code_503"),
+		Body: &envoy_core_v3.DataSource{
+			Specifier: &envoy_core_v3.DataSource_InlineString{InlineString: "unavailable"},
+		},
+		BodyFormatOverride: &envoy_core_v3.SubstitutionFormatString{
+			Format: &envoy_core_v3.SubstitutionFormatString_TextFormatSource{
+				TextFormatSource: &envoy_core_v3.DataSource{
+					Specifier: &envoy_core_v3.DataSource_InlineString{InlineString: "%LOCAL_REPLY_BODY%"},
+				},
+			},
+			ContentType: "text/plain",
+		},
+	}, {
+		Filter: statusFilter(502, "contour.response_map.status_code_502"),
+		Body: &envoy_core_v3.DataSource{
+			Specifier: &envoy_core_v3.DataSource_InlineString{InlineString: "bad gateway"},
+		},
+		BodyFormatOverride: &envoy_core_v3.SubstitutionFormatString{
+			Format: &envoy_core_v3.SubstitutionFormatString_JsonFormat{
+				JsonFormat: &structpb.Struct{
+					Fields: map[string]*structpb.Value{
+						"code":  structpb.NewStringValue("%RESPONSE_CODE%"),
+						"error": structpb.NewStringValue("%LOCAL_REPLY_BODY%"),
+					},
+				},
+			},
+			ContentType: "application/problem+json",
+		},
+	}}
+
+	tests := map[string]struct {
+		config   *http.LocalReplyConfig
+		mappings []ResponseMapping
+		want     *http.LocalReplyConfig
+	}{
+		"no mappings": {
+			want: nil,
+		},
+		"mappings": {
+			mappings: mappings,
+			want: &http.LocalReplyConfig{
+				Mappers: mappers,
+			},
+		},
+		"mappings come after timeout replies": {
+			config: &http.LocalReplyConfig{
+				Mappers: []*http.ResponseMapper{timeoutMapper},
+			},
+			mappings: mappings,
+			want: &http.LocalReplyConfig{
+				Mappers: append([]*http.ResponseMapper{timeoutMapper}, mappers...),
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			protobuf.ExpectEqual(t, tc.want, ResponseMapConfig(tc.config, tc.mappings))
+		})
+	}
+}
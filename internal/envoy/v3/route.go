@@ -34,6 +34,7 @@ import (
 	envoy_rbac_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/rbac/v3"
 	envoy_internal_redirect_previous_routes_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/internal_redirect/previous_routes/v3"
 	envoy_internal_redirect_safe_cross_scheme_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/internal_redirect/safe_cross_scheme/v3"
+	envoy_retry_previous_hosts_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/retry/host/previous_hosts/v3"
 	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
 	"github.com/projectcontour/contour/internal/dag"
 	"github.com/projectcontour/contour/internal/envoy"
@@ -41,6 +42,7 @@ import (
 	"github.com/projectcontour/contour/internal/sorter"
 	"google.golang.org/protobuf/types/known/anypb"
 	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
 	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
@@ -85,7 +87,9 @@ func VirtualHostAndRoutes(vh *dag.VirtualHost, dagRoutes []*dag.Route, secure bo
 // buildRoute converts a DAG route to an Envoy route.
 func buildRoute(dagRoute *dag.Route, vhostName string, secure bool) *envoy_route_v3.Route {
 	route := &envoy_route_v3.Route{
-		Match: RouteMatch(dagRoute),
+		Match:      RouteMatch(dagRoute),
+		Metadata:   routeMetadata(dagRoute.Metadata),
+		StatPrefix: dagRoute.StatPrefix,
 	}
 
 	switch {
@@ -94,7 +98,7 @@ func buildRoute(dagRoute *dag.Route, vhostName string, secure bool) *envoy_route
 		// to a SecureVirtualHost that requires upgrade, this logic can move to
 		// envoy.RouteRoute. Currently the DAG processor adds any HTTP->HTTPS
 		// redirect routes to *both* the insecure and secure vhosts.
-		route.Action = UpgradeHTTPS()
+		route.Action = HTTPSRedirect(dagRoute.HTTPSRedirectPolicy)
 	case dagRoute.DirectResponse != nil:
 		route.Action = routeDirectResponse(dagRoute.DirectResponse)
 	case dagRoute.Redirect != nil:
@@ -107,6 +111,11 @@ func buildRoute(dagRoute *dag.Route, vhostName string, secure bool) *envoy_route
 			route.RequestHeadersToAdd = append(headerValueList(dagRoute.RequestHeadersPolicy.Set, false), headerValueList(dagRoute.RequestHeadersPolicy.Add, true)...)
 			route.RequestHeadersToRemove = dagRoute.RequestHeadersPolicy.Remove
 		}
+		// Tag requests on routes with their own timeout replies, so
+		// that the local reply config of the listener can match them.
+		if key := timeoutReplyKey(dagRoute.TimeoutPolicy); key != "" {
+			route.RequestHeadersToAdd = append(route.RequestHeadersToAdd, headerValueList(map[string]string{TimeoutReplyHeader: key}, false)...)
+		}
 		if dagRoute.ResponseHeadersPolicy != nil {
 			route.ResponseHeadersToAdd = append(headerValueList(dagRoute.ResponseHeadersPolicy.Set, false), headerValueList(dagRoute.ResponseHeadersPolicy.Add, true)...)
 			route.ResponseHeadersToRemove = dagRoute.ResponseHeadersPolicy.Remove
@@ -151,6 +160,35 @@ func buildRoute(dagRoute *dag.Route, vhostName string, secure bool) *envoy_route
 	return route
 }
 
+// RouteMetadataNamespace is the Envoy route metadata namespace
+// that holds the metadata of HTTPProxy routes.
+const RouteMetadataNamespace = "projectcontour.io"
+
+// routeMetadata returns the Envoy route metadata for the given
+// key/value pairs, or nil if there are none.
+func routeMetadata(metadata map[string]string) *envoy_core_v3.Metadata {
+	return stringMetadata(RouteMetadataNamespace, metadata)
+}
+
+// stringMetadata returns Envoy metadata that holds the given key/value
+// pairs in the given namespace, or nil if there are none.
+func stringMetadata(namespace string, values map[string]string) *envoy_core_v3.Metadata {
+	if len(values) == 0 {
+		return nil
+	}
+
+	fields := make(map[string]*structpb.Value, len(values))
+	for k, v := range values {
+		fields[k] = structpb.NewStringValue(v)
+	}
+
+	return &envoy_core_v3.Metadata{
+		FilterMetadata: map[string]*structpb.Struct{
+			namespace: {Fields: fields},
+		},
+	}
+}
+
 // routeAuthzDisabled returns a per-route config to disable authorization.
 func routeAuthzDisabled() *anypb.Any {
 	return protobuf.MustMarshalAny(
@@ -237,6 +275,16 @@ func RouteMatch(route *dag.Route) *envoy_route_v3.RouteMatch {
 	routeMatch.Headers = headerMatcher(route.HeaderMatchConditions)
 	routeMatch.QueryParameters = queryParamMatcher(route.QueryParamMatchConditions)
 
+	if route.RuntimeFraction != nil {
+		routeMatch.RuntimeFraction = &envoy_core_v3.RuntimeFractionalPercent{
+			DefaultValue: &envoy_type_v3.FractionalPercent{
+				Numerator:   route.RuntimeFraction.DefaultPercent,
+				Denominator: envoy_type_v3.FractionalPercent_HUNDRED,
+			},
+			RuntimeKey: RuntimeFlagKey(route.RuntimeFraction.Flag),
+		}
+	}
+
 	return routeMatch
 }
 
@@ -360,6 +408,7 @@ func routeRedirect(redirect *dag.Redirect) *envoy_route_v3.Route_Redirect {
 func routeRoute(r *dag.Route) *envoy_route_v3.Route_Route {
 	ra := envoy_route_v3.RouteAction{
 		RetryPolicy:            retryPolicy(r),
+		HedgePolicy:            hedgePolicy(r.HedgePolicy),
 		Timeout:                envoy.Timeout(r.TimeoutPolicy.ResponseTimeout),
 		IdleTimeout:            envoy.Timeout(r.TimeoutPolicy.IdleStreamTimeout),
 		HashPolicy:             hashPolicy(r.RequestHashPolicies),
@@ -403,11 +452,21 @@ func routeRoute(r *dag.Route) *envoy_route_v3.Route_Route {
 		)
 	}
 
-	if envoy.SingleSimpleCluster(r) {
+	switch {
+	case r.AggregateCluster != nil && r.AggregateCluster.RequestHeadersPolicy == nil && r.AggregateCluster.ResponseHeadersPolicy == nil:
+		ra.ClusterSpecifier = &envoy_route_v3.RouteAction_Cluster{
+			Cluster: envoy.AggregateClusterName(r.AggregateCluster),
+		}
+	case r.AggregateCluster != nil:
+		// Headers policies can only be applied to weighted clusters.
+		ra.ClusterSpecifier = &envoy_route_v3.RouteAction_WeightedClusters{
+			WeightedClusters: aggregateWeightedCluster(r.AggregateCluster),
+		}
+	case envoy.SingleSimpleCluster(r):
 		ra.ClusterSpecifier = &envoy_route_v3.RouteAction_Cluster{
 			Cluster: envoy.Clustername(r.Clusters[0]),
 		}
-	} else {
+	default:
 		ra.ClusterSpecifier = &envoy_route_v3.RouteAction_WeightedClusters{
 			WeightedClusters: weightedClusters(r),
 		}
@@ -489,17 +548,47 @@ func retryPolicy(r *dag.Route) *envoy_route_v3.RetryPolicy {
 	}
 
 	rp := &envoy_route_v3.RetryPolicy{
-		RetryOn:              r.RetryPolicy.RetryOn,
-		RetriableStatusCodes: r.RetryPolicy.RetriableStatusCodes,
+		RetryOn:                 r.RetryPolicy.RetryOn,
+		RetriableStatusCodes:    r.RetryPolicy.RetriableStatusCodes,
+		RetriableHeaders:        headerMatcher(r.RetryPolicy.RetriableHeaders),
+		RetriableRequestHeaders: headerMatcher(r.RetryPolicy.RetriableRequestHeaders),
 	}
 	if r.RetryPolicy.NumRetries > 0 {
 		rp.NumRetries = wrapperspb.UInt32(r.RetryPolicy.NumRetries)
 	}
 	rp.PerTryTimeout = envoy.Timeout(r.RetryPolicy.PerTryTimeout)
+	rp.PerTryIdleTimeout = envoy.Timeout(r.RetryPolicy.PerTryIdleTimeout)
+	if r.RetryPolicy.AvoidPreviousHosts {
+		rp.RetryHostPredicate = []*envoy_route_v3.RetryPolicy_RetryHostPredicate{{
+			Name: "envoy.retry_host_predicates.previous_hosts",
+			ConfigType: &envoy_route_v3.RetryPolicy_RetryHostPredicate_TypedConfig{
+				TypedConfig: protobuf.MustMarshalAny(&envoy_retry_previous_hosts_v3.PreviousHostsPredicate{}),
+			},
+		}}
+	}
+	rp.HostSelectionRetryMaxAttempts = r.RetryPolicy.HostSelectionMaxAttempts
+	if r.RetryPolicy.BackOffBaseInterval > 0 {
+		rp.RetryBackOff = &envoy_route_v3.RetryPolicy_RetryBackOff{
+			BaseInterval: durationpb.New(r.RetryPolicy.BackOffBaseInterval),
+		}
+		if r.RetryPolicy.BackOffMaxInterval > 0 {
+			rp.RetryBackOff.MaxInterval = durationpb.New(r.RetryPolicy.BackOffMaxInterval)
+		}
+	}
 
 	return rp
 }
 
+func hedgePolicy(p *dag.HedgePolicy) *envoy_route_v3.HedgePolicy {
+	if p == nil {
+		return nil
+	}
+
+	return &envoy_route_v3.HedgePolicy{
+		HedgeOnPerTryTimeout: p.HedgeOnPerTryTimeout,
+	}
+}
+
 func internalRedirectPolicy(p *dag.InternalRedirectPolicy) *envoy_route_v3.InternalRedirectPolicy {
 	if p == nil {
 		return nil
@@ -545,6 +634,23 @@ func UpgradeHTTPS() *envoy_route_v3.Route_Redirect {
 	}
 }
 
+// HTTPSRedirect returns a route Action that redirects the request
+// to HTTPS as customized by policy, which may be nil.
+func HTTPSRedirect(policy *dag.HTTPSRedirectPolicy) *envoy_route_v3.Route_Redirect {
+	r := UpgradeHTTPS()
+	if policy == nil {
+		return r
+	}
+
+	// Envoy's default is a 301 if not otherwise specified.
+	if policy.StatusCode == http.StatusPermanentRedirect {
+		r.Redirect.ResponseCode = envoy_route_v3.RedirectAction_PERMANENT_REDIRECT
+	}
+	r.Redirect.StripQuery = policy.StripQuery
+
+	return r
+}
+
 // headerValueList creates a list of Envoy HeaderValueOptions from the provided map.
 func headerValueList(hvm map[string]string, app bool) []*envoy_core_v3.HeaderValueOption {
 	var hvs []*envoy_core_v3.HeaderValueOption
@@ -582,25 +688,23 @@ func weightedClusters(route *dag.Route) *envoy_route_v3.WeightedCluster {
 			Name:   envoy.Clustername(cluster),
 			Weight: wrapperspb.UInt32(cluster.Weight),
 		}
-		if cluster.RequestHeadersPolicy != nil {
-			c.RequestHeadersToAdd = append(headerValueList(cluster.RequestHeadersPolicy.Set, false), headerValueList(cluster.RequestHeadersPolicy.Add, true)...)
-			c.RequestHeadersToRemove = cluster.RequestHeadersPolicy.Remove
-			// Check for host header policy and set if found
-			if val := envoy.HostReplaceHeader(cluster.RequestHeadersPolicy); val != "" {
-				c.HostRewriteSpecifier = &envoy_route_v3.WeightedCluster_ClusterWeight_HostRewriteLiteral{
-					HostRewriteLiteral: val,
-				}
-			}
-		}
-		if cluster.ResponseHeadersPolicy != nil {
-			c.ResponseHeadersToAdd = append(headerValueList(cluster.ResponseHeadersPolicy.Set, false), headerValueList(cluster.ResponseHeadersPolicy.Add, true)...)
-			c.ResponseHeadersToRemove = cluster.ResponseHeadersPolicy.Remove
+		clusterWeightHeaders(c, cluster.RequestHeadersPolicy, cluster.ResponseHeadersPolicy)
+		c.MetadataMatch = SubsetMetadata(cluster.Subset)
+		// The Lua code of the most specific route entry runs, which is
+		// the weighted cluster, so the A/B test code has to run on each
+		// weighted cluster, together with its cookie rewrite code.
+		var code string
+		if route.ABTestPolicy != nil {
+			code += abTestCode(route.ABTestPolicy)
 		}
 		if len(route.CookieRewritePolicies) > 0 || len(cluster.CookieRewritePolicies) > 0 {
+			code += cookieRewriteCode(route.CookieRewritePolicies, cluster.CookieRewritePolicies)
+		}
+		if code != "" {
 			if c.TypedPerFilterConfig == nil {
 				c.TypedPerFilterConfig = map[string]*anypb.Any{}
 			}
-			c.TypedPerFilterConfig["envoy.filters.http.lua"] = cookieRewriteConfig(route.CookieRewritePolicies, cluster.CookieRewritePolicies)
+			c.TypedPerFilterConfig["envoy.filters.http.lua"] = luaPerRouteConfig(code)
 		}
 		wc.Clusters = append(wc.Clusters, c)
 	}
@@ -612,9 +716,50 @@ func weightedClusters(route *dag.Route) *envoy_route_v3.WeightedCluster {
 	}
 
 	sort.Stable(sorter.For(wc.Clusters))
+
+	// Pick the cluster with the hash of the A/B
+	// test code rather than a random value.
+	if route.ABTestPolicy != nil {
+		wc.RandomValueSpecifier = &envoy_route_v3.WeightedCluster_HeaderName{
+			HeaderName: ABTestHashHeader,
+		}
+	}
+
 	return &wc
 }
 
+// aggregateWeightedCluster returns a weighted cluster that sends
+// all traffic to the given aggregate cluster.
+func aggregateWeightedCluster(aggregate *dag.AggregateCluster) *envoy_route_v3.WeightedCluster {
+	c := &envoy_route_v3.WeightedCluster_ClusterWeight{
+		Name:   envoy.AggregateClusterName(aggregate),
+		Weight: wrapperspb.UInt32(1),
+	}
+	clusterWeightHeaders(c, aggregate.RequestHeadersPolicy, aggregate.ResponseHeadersPolicy)
+
+	return &envoy_route_v3.WeightedCluster{
+		Clusters: []*envoy_route_v3.WeightedCluster_ClusterWeight{c},
+	}
+}
+
+// clusterWeightHeaders applies the given headers policies to a weighted cluster.
+func clusterWeightHeaders(c *envoy_route_v3.WeightedCluster_ClusterWeight, request, response *dag.HeadersPolicy) {
+	if request != nil {
+		c.RequestHeadersToAdd = append(headerValueList(request.Set, false), headerValueList(request.Add, true)...)
+		c.RequestHeadersToRemove = request.Remove
+		// Check for host header policy and set if found
+		if val := envoy.HostReplaceHeader(request); val != "" {
+			c.HostRewriteSpecifier = &envoy_route_v3.WeightedCluster_ClusterWeight_HostRewriteLiteral{
+				HostRewriteLiteral: val,
+			}
+		}
+	}
+	if response != nil {
+		c.ResponseHeadersToAdd = append(headerValueList(response.Set, false), headerValueList(response.Add, true)...)
+		c.ResponseHeadersToRemove = response.Remove
+	}
+}
+
 // VirtualHost creates a new route.VirtualHost.
 func VirtualHost(hostname string, routes ...*envoy_route_v3.Route) *envoy_route_v3.VirtualHost {
 	return &envoy_route_v3.VirtualHost{
@@ -811,7 +956,7 @@ func containsMatch(s string) *envoy_route_v3.HeaderMatcher_StringMatch {
 	}
 }
 
-func cookieRewriteConfig(routePolicies, clusterPolicies []dag.CookieRewritePolicy) *anypb.Any {
+func cookieRewriteCode(routePolicies, clusterPolicies []dag.CookieRewritePolicy) string {
 	// Merge route and cluster policies
 	mergedPolicies := map[string]dag.CookieRewritePolicy{}
 	for _, p := range append(routePolicies, clusterPolicies...) {
@@ -945,15 +1090,21 @@ end
 
 	t := new(bytes.Buffer)
 	if err := template.Must(template.New("code").Parse(codeTemplate)).Execute(t, policies); err != nil {
-		// If template execution fails, return empty filter.
-		return nil
+		// If template execution fails, return no code.
+		return ""
 	}
 
+	return t.String()
+}
+
+// luaPerRouteConfig returns a per-route config that
+// overrides the code of the Lua filter with code.
+func luaPerRouteConfig(code string) *anypb.Any {
 	c := &lua.LuaPerRoute{
 		Override: &lua.LuaPerRoute_SourceCode{
 			SourceCode: &envoy_core_v3.DataSource{
 				Specifier: &envoy_core_v3.DataSource_InlineString{
-					InlineString: t.String(),
+					InlineString: code,
 				},
 			},
 		},
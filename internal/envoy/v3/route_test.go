@@ -27,6 +27,7 @@ import (
 	envoy_rbac_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/rbac/v3"
 	envoy_internal_redirect_previous_routes_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/internal_redirect/previous_routes/v3"
 	envoy_internal_redirect_safe_cross_scheme_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/internal_redirect/safe_cross_scheme/v3"
+	envoy_retry_previous_hosts_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/retry/host/previous_hosts/v3"
 	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
 	"github.com/projectcontour/contour/internal/dag"
 	"github.com/projectcontour/contour/internal/fixture"
@@ -35,6 +36,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/protobuf/types/known/anypb"
 	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
 	"google.golang.org/protobuf/types/known/wrapperspb"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -93,6 +95,78 @@ func TestRouteRoute(t *testing.T) {
 				},
 			},
 		},
+		"aggregate cluster": {
+			route: &dag.Route{
+				AggregateCluster: &dag.AggregateCluster{
+					Clusters: []*dag.Cluster{c1, c2},
+				},
+			},
+			want: &envoy_route_v3.Route_Route{
+				Route: &envoy_route_v3.RouteAction{
+					ClusterSpecifier: &envoy_route_v3.RouteAction_Cluster{
+						Cluster: "aggregate/default/kuard/3b5f30be49",
+					},
+				},
+			},
+		},
+		"aggregate cluster with headers policy": {
+			route: &dag.Route{
+				AggregateCluster: &dag.AggregateCluster{
+					Clusters: []*dag.Cluster{c1, c2},
+					RequestHeadersPolicy: &dag.HeadersPolicy{
+						Set: map[string]string{"X-Proxy": "contour"},
+					},
+				},
+			},
+			want: &envoy_route_v3.Route_Route{
+				Route: &envoy_route_v3.RouteAction{
+					ClusterSpecifier: &envoy_route_v3.RouteAction_WeightedClusters{
+						WeightedClusters: &envoy_route_v3.WeightedCluster{
+							Clusters: []*envoy_route_v3.WeightedCluster_ClusterWeight{{
+								Name:   "aggregate/default/kuard/3b5f30be49",
+								Weight: wrapperspb.UInt32(1),
+								RequestHeadersToAdd: []*envoy_core_v3.HeaderValueOption{{
+									Header: &envoy_core_v3.HeaderValue{
+										Key:   "X-Proxy",
+										Value: "contour",
+									},
+									AppendAction: envoy_core_v3.HeaderValueOption_OVERWRITE_IF_EXISTS_OR_ADD,
+								}},
+							}},
+						},
+					},
+				},
+			},
+		},
+		"single service with subset": {
+			route: &dag.Route{
+				Clusters: []*dag.Cluster{{
+					Upstream: c1.Upstream,
+					Subset:   map[string]string{"version": "v2"},
+				}},
+			},
+			want: &envoy_route_v3.Route_Route{
+				Route: &envoy_route_v3.RouteAction{
+					ClusterSpecifier: &envoy_route_v3.RouteAction_WeightedClusters{
+						WeightedClusters: &envoy_route_v3.WeightedCluster{
+							Clusters: []*envoy_route_v3.WeightedCluster_ClusterWeight{{
+								Name:   "default/kuard/8080/13cfc7ea70",
+								Weight: wrapperspb.UInt32(1),
+								MetadataMatch: &envoy_core_v3.Metadata{
+									FilterMetadata: map[string]*structpb.Struct{
+										"envoy.lb": {
+											Fields: map[string]*structpb.Value{
+												"version": structpb.NewStringValue("v2"),
+											},
+										},
+									},
+								},
+							}},
+						},
+					},
+				},
+			},
+		},
 		"websocket": {
 			route: &dag.Route{
 				Websocket: true,
@@ -321,6 +395,132 @@ func TestRouteRoute(t *testing.T) {
 				},
 			},
 		},
+		"retriable headers and per try idle timeout": {
+			route: &dag.Route{
+				RetryPolicy: &dag.RetryPolicy{
+					RetryOn:    "retriable-headers",
+					NumRetries: 2,
+					RetriableHeaders: []dag.HeaderMatchCondition{{
+						Name:      "x-upstream-retry",
+						Value:     "true",
+						MatchType: dag.HeaderMatchTypeExact,
+					}},
+					RetriableRequestHeaders: []dag.HeaderMatchCondition{{
+						Name:      "x-idempotent",
+						MatchType: dag.HeaderMatchTypePresent,
+					}},
+					PerTryIdleTimeout: timeout.DurationSetting(5 * time.Second),
+				},
+				Clusters: []*dag.Cluster{c1},
+			},
+			want: &envoy_route_v3.Route_Route{
+				Route: &envoy_route_v3.RouteAction{
+					ClusterSpecifier: &envoy_route_v3.RouteAction_Cluster{
+						Cluster: "default/kuard/8080/da39a3ee5e",
+					},
+					RetryPolicy: &envoy_route_v3.RetryPolicy{
+						RetryOn:    "retriable-headers",
+						NumRetries: wrapperspb.UInt32(2),
+						RetriableHeaders: []*envoy_route_v3.HeaderMatcher{{
+							Name: "x-upstream-retry",
+							HeaderMatchSpecifier: &envoy_route_v3.HeaderMatcher_StringMatch{
+								StringMatch: &matcher.StringMatcher{
+									MatchPattern: &matcher.StringMatcher_Exact{Exact: "true"},
+								},
+							},
+						}},
+						RetriableRequestHeaders: []*envoy_route_v3.HeaderMatcher{{
+							Name:                 "x-idempotent",
+							HeaderMatchSpecifier: &envoy_route_v3.HeaderMatcher_PresentMatch{PresentMatch: true},
+						}},
+						PerTryIdleTimeout: durationpb.New(5 * time.Second),
+					},
+				},
+			},
+		},
+		"retry avoiding previous hosts": {
+			route: &dag.Route{
+				RetryPolicy: &dag.RetryPolicy{
+					RetryOn:                  "5xx",
+					NumRetries:               2,
+					AvoidPreviousHosts:       true,
+					HostSelectionMaxAttempts: 3,
+				},
+				Clusters: []*dag.Cluster{c1},
+			},
+			want: &envoy_route_v3.Route_Route{
+				Route: &envoy_route_v3.RouteAction{
+					ClusterSpecifier: &envoy_route_v3.RouteAction_Cluster{
+						Cluster: "default/kuard/8080/da39a3ee5e",
+					},
+					RetryPolicy: &envoy_route_v3.RetryPolicy{
+						RetryOn:    "5xx",
+						NumRetries: wrapperspb.UInt32(2),
+						RetryHostPredicate: []*envoy_route_v3.RetryPolicy_RetryHostPredicate{{
+							Name: "envoy.retry_host_predicates.previous_hosts",
+							ConfigType: &envoy_route_v3.RetryPolicy_RetryHostPredicate_TypedConfig{
+								TypedConfig: protobuf.MustMarshalAny(&envoy_retry_previous_hosts_v3.PreviousHostsPredicate{}),
+							},
+						}},
+						HostSelectionRetryMaxAttempts: 3,
+					},
+				},
+			},
+		},
+		"retry with back off": {
+			route: &dag.Route{
+				RetryPolicy: &dag.RetryPolicy{
+					RetryOn:             "5xx",
+					NumRetries:          3,
+					BackOffBaseInterval: 100 * time.Millisecond,
+					BackOffMaxInterval:  2 * time.Second,
+				},
+				Clusters: []*dag.Cluster{c1},
+			},
+			want: &envoy_route_v3.Route_Route{
+				Route: &envoy_route_v3.RouteAction{
+					ClusterSpecifier: &envoy_route_v3.RouteAction_Cluster{
+						Cluster: "default/kuard/8080/da39a3ee5e",
+					},
+					RetryPolicy: &envoy_route_v3.RetryPolicy{
+						RetryOn:    "5xx",
+						NumRetries: wrapperspb.UInt32(3),
+						RetryBackOff: &envoy_route_v3.RetryPolicy_RetryBackOff{
+							BaseInterval: durationpb.New(100 * time.Millisecond),
+							MaxInterval:  durationpb.New(2 * time.Second),
+						},
+					},
+				},
+			},
+		},
+		"hedge on per try timeout": {
+			route: &dag.Route{
+				RetryPolicy: &dag.RetryPolicy{
+					RetryOn:       "5xx",
+					NumRetries:    2,
+					PerTryTimeout: timeout.DurationSetting(100 * time.Millisecond),
+				},
+				HedgePolicy: &dag.HedgePolicy{
+					HedgeOnPerTryTimeout: true,
+				},
+				Clusters: []*dag.Cluster{c1},
+			},
+			want: &envoy_route_v3.Route_Route{
+				Route: &envoy_route_v3.RouteAction{
+					ClusterSpecifier: &envoy_route_v3.RouteAction_Cluster{
+						Cluster: "default/kuard/8080/da39a3ee5e",
+					},
+					RetryPolicy: &envoy_route_v3.RetryPolicy{
+						RetryOn:       "5xx",
+						NumRetries:    wrapperspb.UInt32(2),
+						PerTryTimeout: durationpb.New(100 * time.Millisecond),
+					},
+					HedgePolicy: &envoy_route_v3.HedgePolicy{
+						HedgeOnPerTryTimeout: true,
+					},
+				},
+			},
+		},
 		"timeout 90s": {
 			route: &dag.Route{
 				TimeoutPolicy: dag.RouteTimeoutPolicy{
@@ -1050,6 +1250,57 @@ func TestWeightedClusters(t *testing.T) {
 				}},
 			},
 		},
+		"multiple weighted services with an a/b test policy": {
+			route: &dag.Route{
+				Clusters: []*dag.Cluster{{
+					Upstream: &dag.Service{
+						Weighted: dag.WeightedService{
+							Weight:           1,
+							ServiceName:      "kuard",
+							ServiceNamespace: "default",
+							ServicePort: v1.ServicePort{
+								Port: 8080,
+							},
+						},
+					},
+					Weight: 80,
+				}, {
+					Upstream: &dag.Service{
+						Weighted: dag.WeightedService{
+							Weight:           1,
+							ServiceName:      "nginx",
+							ServiceNamespace: "default",
+							ServicePort: v1.ServicePort{
+								Port: 8080,
+							},
+						},
+					},
+					Weight: 20,
+				}},
+				ABTestPolicy: &dag.ABTestPolicy{
+					CookieName: "session",
+					Salt:       "checkout",
+				},
+			},
+			want: &envoy_route_v3.WeightedCluster{
+				Clusters: []*envoy_route_v3.WeightedCluster_ClusterWeight{{
+					Name:   "default/kuard/8080/da39a3ee5e",
+					Weight: wrapperspb.UInt32(80),
+					TypedPerFilterConfig: map[string]*anypb.Any{
+						"envoy.filters.http.lua": luaPerRouteConfig(abTestCode(&dag.ABTestPolicy{CookieName: "session", Salt: "checkout"})),
+					},
+				}, {
+					Name:   "default/nginx/8080/da39a3ee5e",
+					Weight: wrapperspb.UInt32(20),
+					TypedPerFilterConfig: map[string]*anypb.Any{
+						"envoy.filters.http.lua": luaPerRouteConfig(abTestCode(&dag.ABTestPolicy{CookieName: "session", Salt: "checkout"})),
+					},
+				}},
+				RandomValueSpecifier: &envoy_route_v3.WeightedCluster_HeaderName{
+					HeaderName: "x-contour-ab-hash",
+				},
+			},
+		},
 	}
 
 	for name, tc := range tests {
@@ -1852,6 +2103,51 @@ func TestUpgradeHTTPS(t *testing.T) {
 	assert.Equal(t, want, got)
 }
 
+func TestHTTPSRedirect(t *testing.T) {
+	tests := map[string]struct {
+		policy *dag.HTTPSRedirectPolicy
+		want   *envoy_route_v3.RedirectAction
+	}{
+		"no policy": {
+			policy: nil,
+			want: &envoy_route_v3.RedirectAction{
+				SchemeRewriteSpecifier: &envoy_route_v3.RedirectAction_HttpsRedirect{
+					HttpsRedirect: true,
+				},
+			},
+		},
+		"301 keeping query": {
+			policy: &dag.HTTPSRedirectPolicy{
+				StatusCode: 301,
+			},
+			want: &envoy_route_v3.RedirectAction{
+				SchemeRewriteSpecifier: &envoy_route_v3.RedirectAction_HttpsRedirect{
+					HttpsRedirect: true,
+				},
+			},
+		},
+		"308 stripping query": {
+			policy: &dag.HTTPSRedirectPolicy{
+				StatusCode: 308,
+				StripQuery: true,
+			},
+			want: &envoy_route_v3.RedirectAction{
+				SchemeRewriteSpecifier: &envoy_route_v3.RedirectAction_HttpsRedirect{
+					HttpsRedirect: true,
+				},
+				ResponseCode: envoy_route_v3.RedirectAction_PERMANENT_REDIRECT,
+				StripQuery:   true,
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			protobuf.ExpectEqual(t, &envoy_route_v3.Route_Redirect{Redirect: tc.want}, HTTPSRedirect(tc.policy))
+		})
+	}
+}
+
 func TestRouteMatch(t *testing.T) {
 	tests := map[string]struct {
 		route *dag.Route
@@ -2285,6 +2581,29 @@ func TestRouteMatch(t *testing.T) {
 				},
 			},
 		},
+		"runtime fraction": {
+			route: &dag.Route{
+				PathMatchCondition: &dag.PrefixMatchCondition{
+					Prefix: "/checkout",
+				},
+				RuntimeFraction: &dag.RuntimeFraction{
+					Flag:           "new-checkout",
+					DefaultPercent: 10,
+				},
+			},
+			want: &envoy_route_v3.RouteMatch{
+				PathSpecifier: &envoy_route_v3.RouteMatch_Prefix{
+					Prefix: "/checkout",
+				},
+				RuntimeFraction: &envoy_core_v3.RuntimeFractionalPercent{
+					DefaultValue: &envoy_type_v3.FractionalPercent{
+						Numerator:   10,
+						Denominator: envoy_type_v3.FractionalPercent_HUNDRED,
+					},
+					RuntimeKey: "contour.flags.new-checkout",
+				},
+			},
+		},
 	}
 
 	for name, tc := range tests {
@@ -22,13 +22,29 @@ const (
 	DynamicRuntimeLayerName  = "dynamic"
 	maxRegexProgramSizeError = 1 << 20
 	maxRegexProgramSizeWarn  = 1000
+
+	// runtimeFlagPrefix is the prefix of the runtime
+	// keys of the runtime flags of routes.
+	runtimeFlagPrefix = "contour.flags."
 )
 
-func RuntimeLayers() []*envoy_service_runtime_v3.Runtime {
+// RuntimeFlagKey returns the runtime key of the given runtime flag.
+func RuntimeFlagKey(flag string) string {
+	return runtimeFlagPrefix + flag
+}
+
+// RuntimeLayers returns the runtime layers served over RTDS, with
+// the given percentages of runtime flags, keyed by flag name.
+func RuntimeLayers(flags map[string]uint32) []*envoy_service_runtime_v3.Runtime {
+	layer := baseRuntimeLayer()
+	for flag, percent := range flags {
+		layer.Fields[RuntimeFlagKey(flag)] = &structpb.Value{Kind: &structpb.Value_NumberValue{NumberValue: float64(percent)}}
+	}
+
 	return []*envoy_service_runtime_v3.Runtime{
 		{
 			Name:  DynamicRuntimeLayerName,
-			Layer: baseRuntimeLayer(),
+			Layer: layer,
 		},
 	}
 }
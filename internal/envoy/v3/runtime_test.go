@@ -32,5 +32,24 @@ func TestRuntimeLayers(t *testing.T) {
 				},
 			},
 		},
-	}, RuntimeLayers())
+	}, RuntimeLayers(nil))
+}
+
+func TestRuntimeLayersWithFlags(t *testing.T) {
+	require.Equal(t, []*envoy_service_runtime_v3.Runtime{
+		{
+			Name: "dynamic",
+			Layer: &structpb.Struct{
+				Fields: map[string]*structpb.Value{
+					"re2.max_program_size.error_level": {Kind: &structpb.Value_NumberValue{NumberValue: 1 << 20}},
+					"re2.max_program_size.warn_level":  {Kind: &structpb.Value_NumberValue{NumberValue: 1000}},
+					"contour.flags.new-checkout":       {Kind: &structpb.Value_NumberValue{NumberValue: 25}},
+					"contour.flags.search.v2":          {Kind: &structpb.Value_NumberValue{NumberValue: 0}},
+				},
+			},
+		},
+	}, RuntimeLayers(map[string]uint32{
+		"new-checkout": 25,
+		"search.v2":    0,
+	}))
 }
@@ -14,6 +14,8 @@
 package v3
 
 import (
+	"path"
+
 	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoy_tls_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
 	"github.com/projectcontour/contour/internal/dag"
@@ -40,3 +42,87 @@ func Secret(s *dag.Secret) *envoy_tls_v3.Secret {
 		},
 	}
 }
+
+// SecretConfig returns the SDS config that Envoy uses to fetch secret from Contour.
+func SecretConfig(s *dag.Secret) *envoy_tls_v3.SdsSecretConfig {
+	return &envoy_tls_v3.SdsSecretConfig{
+		Name:      envoy.Secretname(s),
+		SdsConfig: ConfigSource("contour"),
+	}
+}
+
+// SecretBackend provides the certificates and keys that Envoy serves.
+type SecretBackend interface {
+	// Secret returns the SDS resource that Contour serves for s,
+	// or nil if Envoy fetches s from elsewhere.
+	Secret(s *dag.Secret) *envoy_tls_v3.Secret
+
+	// SecretConfig returns the SDS config that Envoy uses to fetch s.
+	SecretConfig(s *dag.Secret) *envoy_tls_v3.SdsSecretConfig
+}
+
+// KubernetesSecretBackend serves the certificates and keys
+// of Kubernetes Secrets.
+type KubernetesSecretBackend struct{}
+
+func (KubernetesSecretBackend) Secret(s *dag.Secret) *envoy_tls_v3.Secret {
+	return Secret(s)
+}
+
+func (KubernetesSecretBackend) SecretConfig(s *dag.Secret) *envoy_tls_v3.SdsSecretConfig {
+	return SecretConfig(s)
+}
+
+// FileSecretBackend serves the paths of certificate and key files on
+// Envoy's filesystem, in the <Directory>/<namespace>/<name> directory
+// of each secret. Envoy reloads the files when they change.
+type FileSecretBackend struct {
+	Directory string
+}
+
+func (b FileSecretBackend) Secret(s *dag.Secret) *envoy_tls_v3.Secret {
+	dir := path.Join(b.Directory, s.Namespace(), s.Name())
+
+	return &envoy_tls_v3.Secret{
+		Name: envoy.Secretname(s),
+		Type: &envoy_tls_v3.Secret_TlsCertificate{
+			TlsCertificate: &envoy_tls_v3.TlsCertificate{
+				PrivateKey: &envoy_core_v3.DataSource{
+					Specifier: &envoy_core_v3.DataSource_Filename{
+						Filename: path.Join(dir, "tls.key"),
+					},
+				},
+				CertificateChain: &envoy_core_v3.DataSource{
+					Specifier: &envoy_core_v3.DataSource_Filename{
+						Filename: path.Join(dir, "tls.crt"),
+					},
+				},
+				WatchedDirectory: &envoy_core_v3.WatchedDirectory{
+					Path: dir,
+				},
+			},
+		},
+	}
+}
+
+func (FileSecretBackend) SecretConfig(s *dag.Secret) *envoy_tls_v3.SdsSecretConfig {
+	return SecretConfig(s)
+}
+
+// SDSSecretBackend has Envoy fetch certificates and keys from the
+// external SDS server behind ClusterName, using <namespace>/<name>
+// as the name of each secret.
+type SDSSecretBackend struct {
+	ClusterName string
+}
+
+func (SDSSecretBackend) Secret(*dag.Secret) *envoy_tls_v3.Secret {
+	return nil
+}
+
+func (b SDSSecretBackend) SecretConfig(s *dag.Secret) *envoy_tls_v3.SdsSecretConfig {
+	return &envoy_tls_v3.SdsSecretConfig{
+		Name:      s.Namespace() + "/" + s.Name(),
+		SdsConfig: ConfigSource(b.ClusterName),
+	}
+}
@@ -116,3 +116,97 @@ func TestSecretname(t *testing.T) {
 		})
 	}
 }
+
+func TestValidationContextSecretname(t *testing.T) {
+	ca := func(data string) *dag.Secret {
+		return &dag.Secret{
+			Object: &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "ca",
+					Namespace: "default",
+				},
+				Data: map[string][]byte{
+					dag.CACertificateKey: []byte(data),
+				},
+			},
+		}
+	}
+
+	name := envoy.ValidationContextSecretname(&dag.PeerValidationContext{CACertificate: ca("ca")})
+	assert.Equal(t, "default/ca/validation/af57c31b01", name)
+
+	// A rotated CA keeps the name, so that only the secret is updated.
+	assert.Equal(t, name, envoy.ValidationContextSecretname(&dag.PeerValidationContext{CACertificate: ca("rotated")}))
+
+	// Different validation settings have different names.
+	assert.NotEqual(t, name, envoy.ValidationContextSecretname(&dag.PeerValidationContext{CACertificate: ca("ca"), SkipClientCertValidation: true}))
+	assert.Equal(t, "validation/e7d230a2fa", envoy.ValidationContextSecretname(&dag.PeerValidationContext{SkipClientCertValidation: true}))
+}
+
+func TestSecretBackend(t *testing.T) {
+	secret := &dag.Secret{
+		Object: &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "simple",
+				Namespace: "default",
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		backend    SecretBackend
+		wantSecret *envoy_tls_v3.Secret
+		wantConfig *envoy_tls_v3.SdsSecretConfig
+	}{
+		"kubernetes": {
+			backend:    KubernetesSecretBackend{},
+			wantSecret: Secret(secret),
+			wantConfig: &envoy_tls_v3.SdsSecretConfig{
+				Name:      envoy.Secretname(secret),
+				SdsConfig: ConfigSource("contour"),
+			},
+		},
+		"file": {
+			backend: FileSecretBackend{Directory: "/etc/envoy/certs"},
+			wantSecret: &envoy_tls_v3.Secret{
+				Name: envoy.Secretname(secret),
+				Type: &envoy_tls_v3.Secret_TlsCertificate{
+					TlsCertificate: &envoy_tls_v3.TlsCertificate{
+						PrivateKey: &envoy_core_v3.DataSource{
+							Specifier: &envoy_core_v3.DataSource_Filename{
+								Filename: "/etc/envoy/certs/default/simple/tls.key",
+							},
+						},
+						CertificateChain: &envoy_core_v3.DataSource{
+							Specifier: &envoy_core_v3.DataSource_Filename{
+								Filename: "/etc/envoy/certs/default/simple/tls.crt",
+							},
+						},
+						WatchedDirectory: &envoy_core_v3.WatchedDirectory{
+							Path: "/etc/envoy/certs/default/simple",
+						},
+					},
+				},
+			},
+			wantConfig: &envoy_tls_v3.SdsSecretConfig{
+				Name:      envoy.Secretname(secret),
+				SdsConfig: ConfigSource("contour"),
+			},
+		},
+		"sds": {
+			backend:    SDSSecretBackend{ClusterName: "extension/projectcontour/sds"},
+			wantSecret: nil,
+			wantConfig: &envoy_tls_v3.SdsSecretConfig{
+				Name:      "default/simple",
+				SdsConfig: ConfigSource("extension/projectcontour/sds"),
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			protobuf.ExpectEqual(t, tc.wantSecret, tc.backend.Secret(secret))
+			protobuf.ExpectEqual(t, tc.wantConfig, tc.backend.SecretConfig(secret))
+		})
+	}
+}
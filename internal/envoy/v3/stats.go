@@ -16,10 +16,15 @@ package v3
 import (
 	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoy_listener_v3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	envoy_config_rbac_v3 "github.com/envoyproxy/go-control-plane/envoy/config/rbac/v3"
 	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	envoy_health_check_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/health_check/v3"
+	envoy_rbac_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/rbac/v3"
 	envoy_router_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/router/v3"
 	http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
 	envoy_tls_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
+	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+	envoy_type_v3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
 	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
 	contour_api_v1alpha1 "github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
 	"github.com/projectcontour/contour/internal/protobuf"
@@ -29,13 +34,34 @@ import (
 const metricsServerCertSDSName = "metrics-tls-certificate"
 const metricsCaBundleSDSName = "metrics-ca-certificate"
 
+// HealthSettings customizes the endpoints of the health listener.
+type HealthSettings struct {
+	// ReadinessPath is the path of the readiness probe.
+	// If empty, the readiness probe is served on /ready.
+	ReadinessPath string
+
+	// LivenessPath is the path of the liveness probe.
+	// If empty, there is no liveness probe.
+	LivenessPath string
+
+	// LivenessClusterMinHealthyPercentages are the minimum percentages
+	// of healthy endpoints of clusters, keyed by cluster name, below
+	// which the liveness probe fails.
+	LivenessClusterMinHealthyPercentages map[string]uint32
+}
+
 // StatsListeners returns an array of *envoy_listener_v3.Listeners,
 // either single HTTP listener or HTTP and HTTPS listeners depending on config.
 // The listeners are configured to serve:
 //   - prometheus metrics on /stats (either over HTTP or HTTPS)
-//   - readiness probe on /ready (always over HTTP)
-func StatsListeners(metrics contour_api_v1alpha1.MetricsConfig, health contour_api_v1alpha1.HealthConfig) []*envoy_listener_v3.Listener {
+//   - readiness probe on /ready, or the configured path (always over HTTP)
+//   - liveness probe on the configured path, if any (always over HTTP)
+//
+// If metricsBearerToken is set, it must be presented to get the metrics.
+func StatsListeners(metrics contour_api_v1alpha1.MetricsConfig, health contour_api_v1alpha1.HealthConfig, settings HealthSettings, metricsBearerToken string) []*envoy_listener_v3.Listener {
 	var listeners []*envoy_listener_v3.Listener
+	statsFilters := bearerTokenFilters(metricsBearerToken)
+	healthFilters := livenessFilters(settings)
 
 	switch {
 	// Create HTTPS listener for metrics and HTTP listener for health.
@@ -46,12 +72,12 @@ func StatsListeners(metrics contour_api_v1alpha1.MetricsConfig, health contour_a
 			SocketOptions: TCPKeepaliveSocketOptions(),
 			FilterChains: filterChain("stats",
 				DownstreamTLSTransportSocket(
-					downstreamTLSContext(metrics.TLS.CAFile != "")), routeForAdminInterface("/stats")),
+					downstreamTLSContext(metrics.TLS.CAFile != "", metrics.TLS.AllowedSubjectAltNames)), routeForAdminInterface("/stats"), statsFilters...),
 		}, {
 			Name:          "health",
 			Address:       SocketAddress(health.Address, health.Port),
 			SocketOptions: TCPKeepaliveSocketOptions(),
-			FilterChains:  filterChain("stats", nil, routeForAdminInterface("/ready")),
+			FilterChains:  filterChain("stats", nil, routeForHealth(settings), healthFilters...),
 		}}
 
 	// Create combined HTTP listener for metrics and health.
@@ -61,7 +87,7 @@ func StatsListeners(metrics contour_api_v1alpha1.MetricsConfig, health contour_a
 			Name:          "stats-health",
 			Address:       SocketAddress(metrics.Address, metrics.Port),
 			SocketOptions: TCPKeepaliveSocketOptions(),
-			FilterChains:  filterChain("stats", nil, routeForAdminInterface("/ready", "/stats")),
+			FilterChains:  filterChain("stats", nil, routeForHealth(settings, "/stats"), append(statsFilters, healthFilters...)...),
 		}}
 
 	// Create separate HTTP listeners for metrics and health.
@@ -70,12 +96,12 @@ func StatsListeners(metrics contour_api_v1alpha1.MetricsConfig, health contour_a
 			Name:          "stats",
 			Address:       SocketAddress(metrics.Address, metrics.Port),
 			SocketOptions: TCPKeepaliveSocketOptions(),
-			FilterChains:  filterChain("stats", nil, routeForAdminInterface("/stats")),
+			FilterChains:  filterChain("stats", nil, routeForAdminInterface("/stats"), statsFilters...),
 		}, {
 			Name:          "health",
 			Address:       SocketAddress(health.Address, health.Port),
 			SocketOptions: TCPKeepaliveSocketOptions(),
-			FilterChains:  filterChain("stats", nil, routeForAdminInterface("/ready")),
+			FilterChains:  filterChain("stats", nil, routeForHealth(settings), healthFilters...),
 		}}
 	}
 
@@ -108,7 +134,16 @@ func AdminListener(port int) *envoy_listener_v3.Listener {
 }
 
 // filterChain returns a filter chain used by static listeners.
-func filterChain(statsPrefix string, transportSocket *envoy_core_v3.TransportSocket, routes *http.HttpConnectionManager_RouteConfig) []*envoy_listener_v3.FilterChain {
+// The given HTTP filters run before the router.
+func filterChain(statsPrefix string, transportSocket *envoy_core_v3.TransportSocket, routes *http.HttpConnectionManager_RouteConfig, filters ...*http.HttpFilter) []*envoy_listener_v3.FilterChain {
+	httpFilters := append([]*http.HttpFilter{}, filters...)
+	httpFilters = append(httpFilters, &http.HttpFilter{
+		Name: wellknown.Router,
+		ConfigType: &http.HttpFilter_TypedConfig{
+			TypedConfig: protobuf.MustMarshalAny(&envoy_router_v3.Router{}),
+		},
+	})
+
 	return []*envoy_listener_v3.FilterChain{{
 		Filters: []*envoy_listener_v3.Filter{{
 			Name: wellknown.HTTPConnectionManager,
@@ -116,13 +151,8 @@ func filterChain(statsPrefix string, transportSocket *envoy_core_v3.TransportSoc
 				TypedConfig: protobuf.MustMarshalAny(&http.HttpConnectionManager{
 					StatPrefix:     statsPrefix,
 					RouteSpecifier: routes,
-					HttpFilters: []*http.HttpFilter{{
-						Name: wellknown.Router,
-						ConfigType: &http.HttpFilter_TypedConfig{
-							TypedConfig: protobuf.MustMarshalAny(&envoy_router_v3.Router{}),
-						},
-					}},
-					NormalizePath: wrapperspb.Bool(true),
+					HttpFilters:    httpFilters,
+					NormalizePath:  wrapperspb.Bool(true),
 				}),
 			},
 		}},
@@ -162,9 +192,133 @@ func routeForAdminInterface(prefixes ...string) *http.HttpConnectionManager_Rout
 	return config
 }
 
+// routeForHealth creates static RouteConfig that forwards the readiness probe
+// and the requested prefixes to Envoy admin interface. A readiness probe on a
+// custom path is rewritten to /ready.
+func routeForHealth(settings HealthSettings, prefixes ...string) *http.HttpConnectionManager_RouteConfig {
+	if settings.ReadinessPath == "" || settings.ReadinessPath == "/ready" {
+		return routeForAdminInterface(append([]string{"/ready"}, prefixes...)...)
+	}
+
+	config := routeForAdminInterface(prefixes...)
+	config.RouteConfig.VirtualHosts[0].Routes = append([]*envoy_route_v3.Route{{
+		Match: &envoy_route_v3.RouteMatch{
+			PathSpecifier: &envoy_route_v3.RouteMatch_Path{
+				Path: settings.ReadinessPath,
+			},
+		},
+		Action: &envoy_route_v3.Route_Route{
+			Route: &envoy_route_v3.RouteAction{
+				ClusterSpecifier: &envoy_route_v3.RouteAction_Cluster{
+					Cluster: "envoy-admin",
+				},
+				PrefixRewrite: "/ready",
+			},
+		},
+	}}, config.RouteConfig.VirtualHosts[0].Routes...)
+
+	return config
+}
+
+// livenessFilters returns the HTTP filters that serve the liveness probe,
+// or nil if there is no liveness probe. The probe fails while Envoy is
+// draining, or while one of the given clusters has too few healthy
+// endpoints.
+func livenessFilters(settings HealthSettings) []*http.HttpFilter {
+	if settings.LivenessPath == "" {
+		return nil
+	}
+
+	healthCheck := &envoy_health_check_v3.HealthCheck{
+		PassThroughMode: wrapperspb.Bool(false),
+		Headers: []*envoy_route_v3.HeaderMatcher{{
+			Name: ":path",
+			HeaderMatchSpecifier: &envoy_route_v3.HeaderMatcher_StringMatch{
+				StringMatch: &matcher.StringMatcher{
+					MatchPattern: &matcher.StringMatcher_Exact{Exact: settings.LivenessPath},
+				},
+			},
+		}},
+	}
+
+	if len(settings.LivenessClusterMinHealthyPercentages) > 0 {
+		healthCheck.ClusterMinHealthyPercentages = map[string]*envoy_type_v3.Percent{}
+		for cluster, percentage := range settings.LivenessClusterMinHealthyPercentages {
+			healthCheck.ClusterMinHealthyPercentages[cluster] = &envoy_type_v3.Percent{
+				Value: float64(percentage),
+			}
+		}
+	}
+
+	return []*http.HttpFilter{{
+		Name: wellknown.HealthCheck,
+		ConfigType: &http.HttpFilter_TypedConfig{
+			TypedConfig: protobuf.MustMarshalAny(healthCheck),
+		},
+	}}
+}
+
+// bearerTokenFilters returns the HTTP filters that require the bearer token
+// for the metrics under /stats, or nil if token is empty. Other paths, such
+// as the readiness probe, stay open.
+func bearerTokenFilters(token string) []*http.HttpFilter {
+	if token == "" {
+		return nil
+	}
+
+	stats := &envoy_config_rbac_v3.Permission{
+		Rule: &envoy_config_rbac_v3.Permission_UrlPath{
+			UrlPath: &matcher.PathMatcher{
+				Rule: &matcher.PathMatcher_Path{
+					Path: &matcher.StringMatcher{
+						MatchPattern: &matcher.StringMatcher_Prefix{Prefix: "/stats"},
+					},
+				},
+			},
+		},
+	}
+
+	return []*http.HttpFilter{{
+		Name: "envoy.filters.http.rbac",
+		ConfigType: &http.HttpFilter_TypedConfig{
+			TypedConfig: protobuf.MustMarshalAny(&envoy_rbac_v3.RBAC{
+				Rules: &envoy_config_rbac_v3.RBAC{
+					Action: envoy_config_rbac_v3.RBAC_ALLOW,
+					Policies: map[string]*envoy_config_rbac_v3.Policy{
+						"metrics": {
+							Permissions: []*envoy_config_rbac_v3.Permission{stats},
+							Principals: []*envoy_config_rbac_v3.Principal{{
+								Identifier: &envoy_config_rbac_v3.Principal_Header{
+									Header: &envoy_route_v3.HeaderMatcher{
+										Name: "authorization",
+										HeaderMatchSpecifier: &envoy_route_v3.HeaderMatcher_StringMatch{
+											StringMatch: &matcher.StringMatcher{
+												MatchPattern: &matcher.StringMatcher_Exact{Exact: "Bearer " + token},
+											},
+										},
+									},
+								},
+							}},
+						},
+						"other": {
+							Permissions: []*envoy_config_rbac_v3.Permission{{
+								Rule: &envoy_config_rbac_v3.Permission_NotRule{NotRule: stats},
+							}},
+							Principals: []*envoy_config_rbac_v3.Principal{{
+								Identifier: &envoy_config_rbac_v3.Principal_Any{Any: true},
+							}},
+						},
+					},
+				},
+			}),
+		},
+	}}
+}
+
 // downstreamTLSContext creates TLS context when HTTPS is used to protect Envoy stats endpoint.
 // Certificates and key are hardcoded to the SDS secrets which are returned by StatsSecrets.
-func downstreamTLSContext(clientValidation bool) *envoy_tls_v3.DownstreamTlsContext {
+// If allowedSANs are set, the client certificate must present one of them.
+func downstreamTLSContext(clientValidation bool, allowedSANs []string) *envoy_tls_v3.DownstreamTlsContext {
 	context := &envoy_tls_v3.DownstreamTlsContext{
 		CommonTlsContext: &envoy_tls_v3.CommonTlsContext{
 			TlsParams: &envoy_tls_v3.TlsParameters{
@@ -179,11 +333,24 @@ func downstreamTLSContext(clientValidation bool) *envoy_tls_v3.DownstreamTlsCont
 	}
 
 	if clientValidation {
-		context.CommonTlsContext.ValidationContextType = &envoy_tls_v3.CommonTlsContext_ValidationContextSdsSecretConfig{
-			ValidationContextSdsSecretConfig: &envoy_tls_v3.SdsSecretConfig{
-				Name:      metricsCaBundleSDSName,
-				SdsConfig: ConfigSource("contour"),
-			},
+		caBundle := &envoy_tls_v3.SdsSecretConfig{
+			Name:      metricsCaBundleSDSName,
+			SdsConfig: ConfigSource("contour"),
+		}
+
+		if len(allowedSANs) > 0 {
+			context.CommonTlsContext.ValidationContextType = &envoy_tls_v3.CommonTlsContext_CombinedValidationContext{
+				CombinedValidationContext: &envoy_tls_v3.CommonTlsContext_CombinedCertificateValidationContext{
+					DefaultValidationContext: &envoy_tls_v3.CertificateValidationContext{
+						MatchTypedSubjectAltNames: subjectAltNameMatchers(allowedSANs),
+					},
+					ValidationContextSdsSecretConfig: caBundle,
+				},
+			}
+		} else {
+			context.CommonTlsContext.ValidationContextType = &envoy_tls_v3.CommonTlsContext_ValidationContextSdsSecretConfig{
+				ValidationContextSdsSecretConfig: caBundle,
+			}
 		}
 		context.RequireClientCertificate = wrapperspb.Bool(true)
 	}
@@ -191,6 +358,28 @@ func downstreamTLSContext(clientValidation bool) *envoy_tls_v3.DownstreamTlsCont
 	return context
 }
 
+// subjectAltNameMatchers returns matchers for the given SANs,
+// which may be DNS names, URIs, IP addresses or email addresses.
+func subjectAltNameMatchers(sans []string) []*envoy_tls_v3.SubjectAltNameMatcher {
+	var matchers []*envoy_tls_v3.SubjectAltNameMatcher
+	for _, san := range sans {
+		for _, sanType := range []envoy_tls_v3.SubjectAltNameMatcher_SanType{
+			envoy_tls_v3.SubjectAltNameMatcher_DNS,
+			envoy_tls_v3.SubjectAltNameMatcher_URI,
+			envoy_tls_v3.SubjectAltNameMatcher_IP_ADDRESS,
+			envoy_tls_v3.SubjectAltNameMatcher_EMAIL,
+		} {
+			matchers = append(matchers, &envoy_tls_v3.SubjectAltNameMatcher{
+				SanType: sanType,
+				Matcher: &matcher.StringMatcher{
+					MatchPattern: &matcher.StringMatcher_Exact{Exact: san},
+				},
+			})
+		}
+	}
+	return matchers
+}
+
 // StatsSecrets returns SDS secrets that refer to local file paths in Envoy container.
 func StatsSecrets(metricsTLS *contour_api_v1alpha1.MetricsTLS) []*envoy_tls_v3.Secret {
 	secrets := []*envoy_tls_v3.Secret{}
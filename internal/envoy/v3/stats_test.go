@@ -18,10 +18,15 @@ import (
 
 	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoy_listener_v3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	envoy_config_rbac_v3 "github.com/envoyproxy/go-control-plane/envoy/config/rbac/v3"
 	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	envoy_health_check_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/health_check/v3"
+	envoy_rbac_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/rbac/v3"
 	envoy_router_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/router/v3"
 	http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
 	envoy_tls_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
+	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+	envoy_type_v3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
 	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
 	contour_api_v1alpha1 "github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
 	"github.com/projectcontour/contour/internal/protobuf"
@@ -60,16 +65,18 @@ func TestStatsListeners(t *testing.T) {
 	}
 
 	type testcase struct {
-		metrics contour_api_v1alpha1.MetricsConfig
-		health  contour_api_v1alpha1.HealthConfig
-		want    []*envoy_listener_v3.Listener
+		metrics  contour_api_v1alpha1.MetricsConfig
+		health   contour_api_v1alpha1.HealthConfig
+		settings HealthSettings
+		token    string
+		want     []*envoy_listener_v3.Listener
 	}
 
 	run := func(t *testing.T, name string, tc testcase) {
 		t.Helper()
 		t.Run(name, func(t *testing.T) {
 			t.Helper()
-			got := StatsListeners(tc.metrics, tc.health)
+			got := StatsListeners(tc.metrics, tc.health, tc.settings, tc.token)
 			protobuf.ExpectEqual(t, tc.want, got)
 		})
 	}
@@ -363,6 +370,200 @@ func TestStatsListeners(t *testing.T) {
 			SocketOptions: TCPKeepaliveSocketOptions(),
 		}}})
 
+	run(t, "custom-readiness-path-and-liveness-probe", testcase{
+		metrics: contour_api_v1alpha1.MetricsConfig{Address: "127.0.0.127", Port: 8123},
+		health:  contour_api_v1alpha1.HealthConfig{Address: "127.0.0.127", Port: 8123},
+		settings: HealthSettings{
+			ReadinessPath:                        "/healthz/ready",
+			LivenessPath:                         "/healthz/live",
+			LivenessClusterMinHealthyPercentages: map[string]uint32{"default/backend/80/da39a3ee5e": 50},
+		},
+		want: []*envoy_listener_v3.Listener{{
+			Name:    "stats-health",
+			Address: SocketAddress("127.0.0.127", 8123),
+			FilterChains: FilterChains(
+				&envoy_listener_v3.Filter{
+					Name: wellknown.HTTPConnectionManager,
+					ConfigType: &envoy_listener_v3.Filter_TypedConfig{
+						TypedConfig: protobuf.MustMarshalAny(&http.HttpConnectionManager{
+							StatPrefix: "stats",
+							RouteSpecifier: &http.HttpConnectionManager_RouteConfig{
+								RouteConfig: &envoy_route_v3.RouteConfiguration{
+									VirtualHosts: []*envoy_route_v3.VirtualHost{{
+										Name:    "backend",
+										Domains: []string{"*"},
+										Routes: []*envoy_route_v3.Route{{
+											Match: &envoy_route_v3.RouteMatch{
+												PathSpecifier: &envoy_route_v3.RouteMatch_Path{
+													Path: "/healthz/ready",
+												},
+											},
+											Action: &envoy_route_v3.Route_Route{
+												Route: &envoy_route_v3.RouteAction{
+													ClusterSpecifier: &envoy_route_v3.RouteAction_Cluster{
+														Cluster: "envoy-admin",
+													},
+													PrefixRewrite: "/ready",
+												},
+											},
+										}, statsRoute},
+									}},
+								},
+							},
+							HttpFilters: []*http.HttpFilter{{
+								Name: wellknown.HealthCheck,
+								ConfigType: &http.HttpFilter_TypedConfig{
+									TypedConfig: protobuf.MustMarshalAny(&envoy_health_check_v3.HealthCheck{
+										PassThroughMode: wrapperspb.Bool(false),
+										Headers: []*envoy_route_v3.HeaderMatcher{{
+											Name: ":path",
+											HeaderMatchSpecifier: &envoy_route_v3.HeaderMatcher_StringMatch{
+												StringMatch: &matcher.StringMatcher{
+													MatchPattern: &matcher.StringMatcher_Exact{Exact: "/healthz/live"},
+												},
+											},
+										}},
+										ClusterMinHealthyPercentages: map[string]*envoy_type_v3.Percent{
+											"default/backend/80/da39a3ee5e": {Value: 50},
+										},
+									}),
+								},
+							}, {
+								Name: wellknown.Router,
+								ConfigType: &http.HttpFilter_TypedConfig{
+									TypedConfig: protobuf.MustMarshalAny(&envoy_router_v3.Router{}),
+								},
+							}},
+							NormalizePath: wrapperspb.Bool(true),
+						}),
+					},
+				},
+			),
+			SocketOptions: TCPKeepaliveSocketOptions(),
+		}}})
+
+	statsPrefix := &envoy_config_rbac_v3.Permission{
+		Rule: &envoy_config_rbac_v3.Permission_UrlPath{
+			UrlPath: &matcher.PathMatcher{
+				Rule: &matcher.PathMatcher_Path{
+					Path: &matcher.StringMatcher{
+						MatchPattern: &matcher.StringMatcher_Prefix{Prefix: "/stats"},
+					},
+				},
+			},
+		},
+	}
+
+	run(t, "stats-with-bearer-token-and-health-over-http-single-listener", testcase{
+		metrics: contour_api_v1alpha1.MetricsConfig{Address: "127.0.0.127", Port: 8123},
+		health:  contour_api_v1alpha1.HealthConfig{Address: "127.0.0.127", Port: 8123},
+		token:   "secret",
+		want: []*envoy_listener_v3.Listener{{
+			Name:    "stats-health",
+			Address: SocketAddress("127.0.0.127", 8123),
+			FilterChains: FilterChains(
+				&envoy_listener_v3.Filter{
+					Name: wellknown.HTTPConnectionManager,
+					ConfigType: &envoy_listener_v3.Filter_TypedConfig{
+						TypedConfig: protobuf.MustMarshalAny(&http.HttpConnectionManager{
+							StatPrefix: "stats",
+							RouteSpecifier: &http.HttpConnectionManager_RouteConfig{
+								RouteConfig: &envoy_route_v3.RouteConfiguration{
+									VirtualHosts: []*envoy_route_v3.VirtualHost{{
+										Name:    "backend",
+										Domains: []string{"*"},
+										Routes:  []*envoy_route_v3.Route{readyRoute, statsRoute},
+									}},
+								},
+							},
+							HttpFilters: []*http.HttpFilter{{
+								Name: "envoy.filters.http.rbac",
+								ConfigType: &http.HttpFilter_TypedConfig{
+									TypedConfig: protobuf.MustMarshalAny(&envoy_rbac_v3.RBAC{
+										Rules: &envoy_config_rbac_v3.RBAC{
+											Action: envoy_config_rbac_v3.RBAC_ALLOW,
+											Policies: map[string]*envoy_config_rbac_v3.Policy{
+												"metrics": {
+													Permissions: []*envoy_config_rbac_v3.Permission{statsPrefix},
+													Principals: []*envoy_config_rbac_v3.Principal{{
+														Identifier: &envoy_config_rbac_v3.Principal_Header{
+															Header: &envoy_route_v3.HeaderMatcher{
+																Name: "authorization",
+																HeaderMatchSpecifier: &envoy_route_v3.HeaderMatcher_StringMatch{
+																	StringMatch: &matcher.StringMatcher{
+																		MatchPattern: &matcher.StringMatcher_Exact{Exact: "Bearer secret"},
+																	},
+																},
+															},
+														},
+													}},
+												},
+												"other": {
+													Permissions: []*envoy_config_rbac_v3.Permission{{
+														Rule: &envoy_config_rbac_v3.Permission_NotRule{NotRule: statsPrefix},
+													}},
+													Principals: []*envoy_config_rbac_v3.Principal{{
+														Identifier: &envoy_config_rbac_v3.Principal_Any{Any: true},
+													}},
+												},
+											},
+										},
+									}),
+								},
+							}, {
+								Name: wellknown.Router,
+								ConfigType: &http.HttpFilter_TypedConfig{
+									TypedConfig: protobuf.MustMarshalAny(&envoy_router_v3.Router{}),
+								},
+							}},
+							NormalizePath: wrapperspb.Bool(true),
+						}),
+					},
+				},
+			),
+			SocketOptions: TCPKeepaliveSocketOptions(),
+		}}})
+}
+
+func TestDownstreamTLSContextAllowedSANs(t *testing.T) {
+	want := &envoy_tls_v3.DownstreamTlsContext{
+		CommonTlsContext: &envoy_tls_v3.CommonTlsContext{
+			TlsParams: &envoy_tls_v3.TlsParameters{
+				TlsMinimumProtocolVersion: envoy_tls_v3.TlsParameters_TLSv1_3,
+				TlsMaximumProtocolVersion: envoy_tls_v3.TlsParameters_TLSv1_3,
+			},
+			TlsCertificateSdsSecretConfigs: []*envoy_tls_v3.SdsSecretConfig{{
+				Name:      "metrics-tls-certificate",
+				SdsConfig: ConfigSource("contour"),
+			}},
+			ValidationContextType: &envoy_tls_v3.CommonTlsContext_CombinedValidationContext{
+				CombinedValidationContext: &envoy_tls_v3.CommonTlsContext_CombinedCertificateValidationContext{
+					DefaultValidationContext: &envoy_tls_v3.CertificateValidationContext{
+						MatchTypedSubjectAltNames: []*envoy_tls_v3.SubjectAltNameMatcher{{
+							SanType: envoy_tls_v3.SubjectAltNameMatcher_DNS,
+							Matcher: &matcher.StringMatcher{MatchPattern: &matcher.StringMatcher_Exact{Exact: "prometheus"}},
+						}, {
+							SanType: envoy_tls_v3.SubjectAltNameMatcher_URI,
+							Matcher: &matcher.StringMatcher{MatchPattern: &matcher.StringMatcher_Exact{Exact: "prometheus"}},
+						}, {
+							SanType: envoy_tls_v3.SubjectAltNameMatcher_IP_ADDRESS,
+							Matcher: &matcher.StringMatcher{MatchPattern: &matcher.StringMatcher_Exact{Exact: "prometheus"}},
+						}, {
+							SanType: envoy_tls_v3.SubjectAltNameMatcher_EMAIL,
+							Matcher: &matcher.StringMatcher{MatchPattern: &matcher.StringMatcher_Exact{Exact: "prometheus"}},
+						}},
+					},
+					ValidationContextSdsSecretConfig: &envoy_tls_v3.SdsSecretConfig{
+						Name:      "metrics-ca-certificate",
+						SdsConfig: ConfigSource("contour"),
+					},
+				},
+			},
+		},
+		RequireClientCertificate: wrapperspb.Bool(true),
+	}
+
+	protobuf.ExpectEqual(t, want, downstreamTLSContext(true, []string{"prometheus"}))
 }
 
 func TestStatsTLSSecrets(t *testing.T) {
@@ -14,15 +14,88 @@
 package v3
 
 import (
+	"net"
+	"time"
+
 	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	"github.com/projectcontour/contour/internal/envoy"
 )
 
+// Default TCP keep-alive settings.
+//
+// Note: TCP_KEEPIDLE + (TCP_KEEPINTVL * TCP_KEEPCNT) must be greater than
+// the grpc.KeepaliveParams time + timeout (currently 60 + 20 = 80 seconds)
+// otherwise TestGRPC/StreamClusters fails.
+const (
+	defaultTCPKeepaliveIdle     = 45 * time.Second
+	defaultTCPKeepaliveInterval = 5 * time.Second
+	defaultTCPKeepaliveProbes   = 9
+)
+
+// SocketOptions holds the socket options of the HTTP and HTTPS listeners.
+// TCP keep-alive settings that are not set use Contour's defaults.
+type SocketOptions struct {
+	TCPKeepaliveIdle     time.Duration
+	TCPKeepaliveInterval time.Duration
+	TCPKeepaliveProbes   *uint32
+
+	// TOS is set on all listeners, since IPv6 listeners
+	// may accept IPv4 connections.
+	TOS *int32
+
+	// TrafficClass is only set on IPv6 listeners.
+	TrafficClass *int32
+
+	// Freebind is set on the listeners rather than as a socket option.
+	Freebind bool
+}
+
 func TCPKeepaliveSocketOptions() []*envoy_core_v3.SocketOption {
+	return tcpKeepaliveSocketOptions(defaultTCPKeepaliveIdle, defaultTCPKeepaliveInterval, defaultTCPKeepaliveProbes)
+}
+
+// ListenerSocketOptions returns the socket options of a listener bound
+// to the supplied address.
+func ListenerSocketOptions(opts SocketOptions, address string) []*envoy_core_v3.SocketOption {
+	idle := defaultTCPKeepaliveIdle
+	if opts.TCPKeepaliveIdle > 0 {
+		idle = opts.TCPKeepaliveIdle
+	}
+	interval := defaultTCPKeepaliveInterval
+	if opts.TCPKeepaliveInterval > 0 {
+		interval = opts.TCPKeepaliveInterval
+	}
+	probes := uint32(defaultTCPKeepaliveProbes)
+	if opts.TCPKeepaliveProbes != nil {
+		probes = *opts.TCPKeepaliveProbes
+	}
+
+	options := tcpKeepaliveSocketOptions(idle, interval, probes)
+
+	if opts.TOS != nil {
+		options = append(options, &envoy_core_v3.SocketOption{
+			Description: "IPv4 type of service",
+			Level:       envoy.IPPROTO_IP,
+			Name:        envoy.IP_TOS,
+			Value:       &envoy_core_v3.SocketOption_IntValue{IntValue: int64(*opts.TOS)},
+			State:       envoy_core_v3.SocketOption_STATE_LISTENING,
+		})
+	}
+
+	if ip := net.ParseIP(address); opts.TrafficClass != nil && ip != nil && ip.To4() == nil {
+		options = append(options, &envoy_core_v3.SocketOption{
+			Description: "IPv6 traffic class",
+			Level:       envoy.IPPROTO_IPV6,
+			Name:        envoy.IPV6_TCLASS,
+			Value:       &envoy_core_v3.SocketOption_IntValue{IntValue: int64(*opts.TrafficClass)},
+			State:       envoy_core_v3.SocketOption_STATE_LISTENING,
+		})
+	}
+
+	return options
+}
 
-	// Note: TCP_KEEPIDLE + (TCP_KEEPINTVL * TCP_KEEPCNT) must be greater than
-	// the grpc.KeepaliveParams time + timeout (currently 60 + 20 = 80 seconds)
-	// otherwise TestGRPC/StreamClusters fails.
+func tcpKeepaliveSocketOptions(idle, interval time.Duration, probes uint32) []*envoy_core_v3.SocketOption {
 	return []*envoy_core_v3.SocketOption{
 		// Enable TCP keep-alive.
 		{
@@ -38,7 +111,7 @@ func TCPKeepaliveSocketOptions() []*envoy_core_v3.SocketOption {
 			Description: "TCP keep-alive initial idle time",
 			Level:       envoy.IPPROTO_TCP,
 			Name:        envoy.TCP_KEEPIDLE,
-			Value:       &envoy_core_v3.SocketOption_IntValue{IntValue: 45},
+			Value:       &envoy_core_v3.SocketOption_IntValue{IntValue: int64(idle / time.Second)},
 			State:       envoy_core_v3.SocketOption_STATE_LISTENING,
 		},
 		// The time (in seconds) between individual keepalive probes.
@@ -46,7 +119,7 @@ func TCPKeepaliveSocketOptions() []*envoy_core_v3.SocketOption {
 			Description: "TCP keep-alive time between probes",
 			Level:       envoy.IPPROTO_TCP,
 			Name:        envoy.TCP_KEEPINTVL,
-			Value:       &envoy_core_v3.SocketOption_IntValue{IntValue: 5},
+			Value:       &envoy_core_v3.SocketOption_IntValue{IntValue: int64(interval / time.Second)},
 			State:       envoy_core_v3.SocketOption_STATE_LISTENING,
 		},
 		// The maximum number of TCP keep-alive probes to send before
@@ -56,7 +129,7 @@ func TCPKeepaliveSocketOptions() []*envoy_core_v3.SocketOption {
 			Description: "TCP keep-alive probe count",
 			Level:       envoy.IPPROTO_TCP,
 			Name:        envoy.TCP_KEEPCNT,
-			Value:       &envoy_core_v3.SocketOption_IntValue{IntValue: 9},
+			Value:       &envoy_core_v3.SocketOption_IntValue{IntValue: int64(probes)},
 			State:       envoy_core_v3.SocketOption_STATE_LISTENING,
 		},
 	}
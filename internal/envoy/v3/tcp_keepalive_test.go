@@ -0,0 +1,71 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"testing"
+	"time"
+
+	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	"github.com/projectcontour/contour/internal/envoy"
+	"github.com/projectcontour/contour/internal/protobuf"
+	"github.com/projectcontour/contour/internal/ref"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListenerSocketOptions(t *testing.T) {
+	intValue := func(options []*envoy_core_v3.SocketOption, level, name int64) int64 {
+		t.Helper()
+
+		for _, o := range options {
+			if o.Level == level && o.Name == name {
+				return o.GetIntValue()
+			}
+		}
+		t.Fatalf("missing socket option %d/%d", level, name)
+		return 0
+	}
+
+	hasOption := func(options []*envoy_core_v3.SocketOption, level, name int64) bool {
+		for _, o := range options {
+			if o.Level == level && o.Name == name {
+				return true
+			}
+		}
+		return false
+	}
+
+	// The defaults match the keep-alive options of the other listeners.
+	protobuf.ExpectEqual(t, TCPKeepaliveSocketOptions(), ListenerSocketOptions(SocketOptions{}, "0.0.0.0"))
+
+	opts := SocketOptions{
+		TCPKeepaliveIdle:     2 * time.Minute,
+		TCPKeepaliveInterval: 10 * time.Second,
+		TCPKeepaliveProbes:   ref.To(uint32(3)),
+		TOS:                  ref.To(int32(0x10)),
+		TrafficClass:         ref.To(int32(0x20)),
+	}
+
+	ipv4 := ListenerSocketOptions(opts, "0.0.0.0")
+	assert.Equal(t, int64(120), intValue(ipv4, envoy.IPPROTO_TCP, envoy.TCP_KEEPIDLE))
+	assert.Equal(t, int64(10), intValue(ipv4, envoy.IPPROTO_TCP, envoy.TCP_KEEPINTVL))
+	assert.Equal(t, int64(3), intValue(ipv4, envoy.IPPROTO_TCP, envoy.TCP_KEEPCNT))
+	assert.Equal(t, int64(0x10), intValue(ipv4, envoy.IPPROTO_IP, envoy.IP_TOS))
+	assert.False(t, hasOption(ipv4, envoy.IPPROTO_IPV6, envoy.IPV6_TCLASS))
+
+	// IPv6 listeners may accept IPv4 connections too.
+	ipv6 := ListenerSocketOptions(opts, "::")
+	assert.Equal(t, int64(0x10), intValue(ipv6, envoy.IPPROTO_IP, envoy.IP_TOS))
+	assert.Equal(t, int64(0x20), intValue(ipv6, envoy.IPPROTO_IPV6, envoy.IPV6_TCLASS))
+}
@@ -0,0 +1,192 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"crypto/sha1" // nolint:gosec
+	"fmt"
+	"sort"
+
+	envoy_accesslog_v3 "github.com/envoyproxy/go-control-plane/envoy/config/accesslog/v3"
+	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+	"github.com/projectcontour/contour/internal/dag"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// TimeoutReplyHeader is the request header that is added on routes with
+// their own timeout replies, so that the local reply mappers of the HTTP
+// connection manager can tell which replies to send. Local reply mappers
+// can't match on the route itself.
+const TimeoutReplyHeader = "x-contour-timeout-reply"
+
+const (
+	// responseFlagUpstreamTimeout is the response flag
+	// of replies sent when the response timeout expires.
+	responseFlagUpstreamTimeout = "UT"
+
+	// responseFlagStreamIdleTimeout is the response flag
+	// of replies sent when a stream idle timeout expires.
+	responseFlagStreamIdleTimeout = "SI"
+)
+
+// TimeoutReplies holds the replies that are sent when
+// a timeout expires. Replies that are nil use Envoy's defaults.
+type TimeoutReplies struct {
+	Response *dag.TimeoutReply
+	Idle     *dag.TimeoutReply
+}
+
+// TimeoutReplyConfig returns the local reply config that sends the
+// timeout replies of the routes of the given virtual hosts, falling back
+// to the given default replies, or nil if there are no replies to send.
+func TimeoutReplyConfig(defaults TimeoutReplies, vhosts []*dag.VirtualHost) *http.LocalReplyConfig {
+	routeReplies := map[string]TimeoutReplies{}
+	for _, vh := range vhosts {
+		for _, route := range vh.Routes {
+			if key := timeoutReplyKey(route.TimeoutPolicy); key != "" {
+				routeReplies[key] = TimeoutReplies{
+					Response: route.TimeoutPolicy.ResponseTimeoutReply,
+					Idle:     route.TimeoutPolicy.IdleStreamTimeoutReply,
+				}
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(routeReplies))
+	for key := range routeReplies {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// The first mapper that matches is used, so the mappers
+	// of the routes have to come before the default ones.
+	var mappers []*http.ResponseMapper
+	for _, key := range keys {
+		replies := routeReplies[key]
+		mappers = appendTimeoutReplyMapper(mappers, responseFlagUpstreamTimeout, key, replies.Response)
+		mappers = appendTimeoutReplyMapper(mappers, responseFlagStreamIdleTimeout, key, replies.Idle)
+	}
+	mappers = appendTimeoutReplyMapper(mappers, responseFlagUpstreamTimeout, "", defaults.Response)
+	mappers = appendTimeoutReplyMapper(mappers, responseFlagStreamIdleTimeout, "", defaults.Idle)
+
+	if len(mappers) == 0 {
+		return nil
+	}
+
+	return &http.LocalReplyConfig{
+		Mappers: mappers,
+	}
+}
+
+// appendTimeoutReplyMapper appends a mapper that sends the given reply
+// for local replies with the given response flag to mappers. If key is
+// not empty, the mapper only matches requests on routes with that key.
+func appendTimeoutReplyMapper(mappers []*http.ResponseMapper, flag string, key string, reply *dag.TimeoutReply) []*http.ResponseMapper {
+	if reply == nil {
+		return mappers
+	}
+
+	filter := &envoy_accesslog_v3.AccessLogFilter{
+		FilterSpecifier: &envoy_accesslog_v3.AccessLogFilter_ResponseFlagFilter{
+			ResponseFlagFilter: &envoy_accesslog_v3.ResponseFlagFilter{
+				Flags: []string{flag},
+			},
+		},
+	}
+
+	if key != "" {
+		filter = &envoy_accesslog_v3.AccessLogFilter{
+			FilterSpecifier: &envoy_accesslog_v3.AccessLogFilter_AndFilter{
+				AndFilter: &envoy_accesslog_v3.AndFilter{
+					Filters: []*envoy_accesslog_v3.AccessLogFilter{
+						filter,
+						{
+							FilterSpecifier: &envoy_accesslog_v3.AccessLogFilter_HeaderFilter{
+								HeaderFilter: &envoy_accesslog_v3.HeaderFilter{
+									Header: &envoy_route_v3.HeaderMatcher{
+										Name: TimeoutReplyHeader,
+										HeaderMatchSpecifier: &envoy_route_v3.HeaderMatcher_StringMatch{
+											StringMatch: &matcher.StringMatcher{
+												MatchPattern: &matcher.StringMatcher_Exact{
+													Exact: key,
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	mapper := &http.ResponseMapper{
+		Filter: filter,
+	}
+
+	if reply.StatusCode > 0 {
+		mapper.StatusCode = wrapperspb.UInt32(reply.StatusCode)
+	}
+
+	if reply.Body != "" {
+		contentType := reply.ContentType
+		if contentType == "" {
+			contentType = "text/plain"
+		}
+
+		mapper.Body = &envoy_core_v3.DataSource{
+			Specifier: &envoy_core_v3.DataSource_InlineString{
+				InlineString: reply.Body,
+			},
+		}
+		mapper.BodyFormatOverride = &envoy_core_v3.SubstitutionFormatString{
+			Format: &envoy_core_v3.SubstitutionFormatString_TextFormatSource{
+				TextFormatSource: &envoy_core_v3.DataSource{
+					Specifier: &envoy_core_v3.DataSource_InlineString{
+						InlineString: "%LOCAL_REPLY_BODY%",
+					},
+				},
+			},
+			ContentType: contentType,
+		}
+	}
+
+	return append(mappers, mapper)
+}
+
+// timeoutReplyKey returns the key of the timeout replies of
+// the given timeout policy, or an empty string if it has none.
+func timeoutReplyKey(policy dag.RouteTimeoutPolicy) string {
+	if policy.ResponseTimeoutReply == nil && policy.IdleStreamTimeoutReply == nil {
+		return ""
+	}
+
+	var buf string
+	for _, reply := range []*dag.TimeoutReply{policy.ResponseTimeoutReply, policy.IdleStreamTimeoutReply} {
+		if reply == nil {
+			buf += "-;"
+			continue
+		}
+		buf += fmt.Sprintf("%d|%q|%q;", reply.StatusCode, reply.ContentType, reply.Body)
+	}
+
+	// This isn't a crypto hash, we just want a unique key.
+	hash := sha1.Sum([]byte(buf)) // nolint:gosec
+	return fmt.Sprintf("%x", hash[:5])
+}
@@ -0,0 +1,180 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"testing"
+
+	envoy_accesslog_v3 "github.com/envoyproxy/go-control-plane/envoy/config/accesslog/v3"
+	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+	"github.com/projectcontour/contour/internal/dag"
+	"github.com/projectcontour/contour/internal/protobuf"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestTimeoutReplyConfig(t *testing.T) {
+	flagFilter := func(flag string) *envoy_accesslog_v3.AccessLogFilter {
+		return &envoy_accesslog_v3.AccessLogFilter{
+			FilterSpecifier: &envoy_accesslog_v3.AccessLogFilter_ResponseFlagFilter{
+				ResponseFlagFilter: &envoy_accesslog_v3.ResponseFlagFilter{
+					Flags: []string{flag},
+				},
+			},
+		}
+	}
+
+	routeFilter := func(flag, key string) *envoy_accesslog_v3.AccessLogFilter {
+		return &envoy_accesslog_v3.AccessLogFilter{
+			FilterSpecifier: &envoy_accesslog_v3.AccessLogFilter_AndFilter{
+				AndFilter: &envoy_accesslog_v3.AndFilter{
+					Filters: []*envoy_accesslog_v3.AccessLogFilter{
+						flagFilter(flag),
+						{
+							FilterSpecifier: &envoy_accesslog_v3.AccessLogFilter_HeaderFilter{
+								HeaderFilter: &envoy_accesslog_v3.HeaderFilter{
+									Header: &envoy_route_v3.HeaderMatcher{
+										Name: "x-contour-timeout-reply",
+										HeaderMatchSpecifier: &envoy_route_v3.HeaderMatcher_StringMatch{
+											StringMatch: &matcher.StringMatcher{
+												MatchPattern: &matcher.StringMatcher_Exact{Exact: key},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	jsonReply := &dag.TimeoutReply{
+		StatusCode:  503,
+		Body:        `{"error":"timeout"}`,
+		ContentType: "application/json",
+	}
+	jsonMapper := func(filter *envoy_accesslog_v3.AccessLogFilter) *http.ResponseMapper {
+		return &http.ResponseMapper{
+			Filter:     filter,
+			StatusCode: wrapperspb.UInt32(503),
+			Body: &envoy_core_v3.DataSource{
+				Specifier: &envoy_core_v3.DataSource_InlineString{InlineString: `{"error":"timeout"}`},
+			},
+			BodyFormatOverride: &envoy_core_v3.SubstitutionFormatString{
+				Format: &envoy_core_v3.SubstitutionFormatString_TextFormatSource{
+					TextFormatSource: &envoy_core_v3.DataSource{
+						Specifier: &envoy_core_v3.DataSource_InlineString{InlineString: "%LOCAL_REPLY_BODY%"},
+					},
+				},
+				ContentType: "application/json",
+			},
+		}
+	}
+
+	routeWithReplies := func(response, idle *dag.TimeoutReply) *dag.Route {
+		return &dag.Route{
+			TimeoutPolicy: dag.RouteTimeoutPolicy{
+				ResponseTimeoutReply:   response,
+				IdleStreamTimeoutReply: idle,
+			},
+		}
+	}
+
+	routeKey := timeoutReplyKey(dag.RouteTimeoutPolicy{ResponseTimeoutReply: jsonReply})
+
+	tests := map[string]struct {
+		defaults TimeoutReplies
+		routes   []*dag.Route
+		want     *http.LocalReplyConfig
+	}{
+		"no replies": {
+			routes: []*dag.Route{{}},
+			want:   nil,
+		},
+		"default replies": {
+			defaults: TimeoutReplies{
+				Response: jsonReply,
+				Idle:     &dag.TimeoutReply{StatusCode: 504},
+			},
+			want: &http.LocalReplyConfig{
+				Mappers: []*http.ResponseMapper{
+					jsonMapper(flagFilter("UT")),
+					{
+						Filter:     flagFilter("SI"),
+						StatusCode: wrapperspb.UInt32(504),
+					},
+				},
+			},
+		},
+		"route replies come before default replies": {
+			defaults: TimeoutReplies{
+				Idle: &dag.TimeoutReply{Body: "idle"},
+			},
+			routes: []*dag.Route{
+				routeWithReplies(jsonReply, nil),
+				routeWithReplies(jsonReply, nil),
+				{},
+			},
+			want: &http.LocalReplyConfig{
+				Mappers: []*http.ResponseMapper{
+					jsonMapper(routeFilter("UT", routeKey)),
+					{
+						Filter: flagFilter("SI"),
+						Body: &envoy_core_v3.DataSource{
+							Specifier: &envoy_core_v3.DataSource_InlineString{InlineString: "idle"},
+						},
+						BodyFormatOverride: &envoy_core_v3.SubstitutionFormatString{
+							Format: &envoy_core_v3.SubstitutionFormatString_TextFormatSource{
+								TextFormatSource: &envoy_core_v3.DataSource{
+									Specifier: &envoy_core_v3.DataSource_InlineString{InlineString: "%LOCAL_REPLY_BODY%"},
+								},
+							},
+							ContentType: "text/plain",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			vh := &dag.VirtualHost{Name: "www.example.com"}
+			for i, route := range tc.routes {
+				route.PathMatchCondition = &dag.PrefixMatchCondition{Prefix: "/" + string(rune('a'+i))}
+				vh.AddRoute(route)
+			}
+
+			protobuf.ExpectEqual(t, tc.want, TimeoutReplyConfig(tc.defaults, []*dag.VirtualHost{vh}))
+		})
+	}
+}
+
+func TestTimeoutReplyKey(t *testing.T) {
+	reply := &dag.TimeoutReply{StatusCode: 503}
+
+	if key := timeoutReplyKey(dag.RouteTimeoutPolicy{}); key != "" {
+		t.Errorf("expected no key without replies, got %q", key)
+	}
+
+	response := timeoutReplyKey(dag.RouteTimeoutPolicy{ResponseTimeoutReply: reply})
+	idle := timeoutReplyKey(dag.RouteTimeoutPolicy{IdleStreamTimeoutReply: reply})
+	if response == "" || response == idle {
+		t.Errorf("expected distinct keys for response and idle replies, got %q and %q", response, idle)
+	}
+}
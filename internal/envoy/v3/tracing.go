@@ -14,6 +14,8 @@
 package v3
 
 import (
+	"strings"
+
 	envoy_config_trace_v3 "github.com/envoyproxy/go-control-plane/envoy/config/trace/v3"
 	http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
 	envoy_trace_v3 "github.com/envoyproxy/go-control-plane/envoy/type/tracing/v3"
@@ -45,14 +47,45 @@ func TracingConfig(tracing *EnvoyTracingConfig) *http.HttpConnectionManager_Trac
 		},
 		MaxPathTagLength: wrapperspb.UInt32(tracing.MaxPathTagLength),
 		CustomTags:       customTags,
-		Provider: &envoy_config_trace_v3.Tracing_Http{
-			Name: "envoy.tracers.opentelemetry",
+		Provider:         tracingProvider(tracing),
+	}
+}
+
+func tracingProvider(tracing *EnvoyTracingConfig) *envoy_config_trace_v3.Tracing_Http {
+	if tracing.Zipkin != nil {
+		clusterName := dag.ExtensionClusterName(tracing.ExtensionService)
+
+		// Like the authority of gRPC services.
+		hostname := strings.ReplaceAll(clusterName, "/", ".")
+		if tracing.SNI != "" {
+			hostname = tracing.SNI
+		}
+
+		endpointVersion := envoy_config_trace_v3.ZipkinConfig_HTTP_JSON
+		if tracing.Zipkin.ProtoEncoding {
+			endpointVersion = envoy_config_trace_v3.ZipkinConfig_HTTP_PROTO
+		}
+
+		return &envoy_config_trace_v3.Tracing_Http{
+			Name: "envoy.tracers.zipkin",
 			ConfigType: &envoy_config_trace_v3.Tracing_Http_TypedConfig{
-				TypedConfig: protobuf.MustMarshalAny(&envoy_config_trace_v3.OpenTelemetryConfig{
-					GrpcService: GrpcService(dag.ExtensionClusterName(tracing.ExtensionService), tracing.SNI, tracing.Timeout),
-					ServiceName: tracing.ServiceName,
+				TypedConfig: protobuf.MustMarshalAny(&envoy_config_trace_v3.ZipkinConfig{
+					CollectorCluster:         clusterName,
+					CollectorEndpoint:        tracing.Zipkin.CollectorEndpoint,
+					CollectorEndpointVersion: endpointVersion,
+					CollectorHostname:        hostname,
 				}),
 			},
+		}
+	}
+
+	return &envoy_config_trace_v3.Tracing_Http{
+		Name: "envoy.tracers.opentelemetry",
+		ConfigType: &envoy_config_trace_v3.Tracing_Http_TypedConfig{
+			TypedConfig: protobuf.MustMarshalAny(&envoy_config_trace_v3.OpenTelemetryConfig{
+				GrpcService: GrpcService(dag.ExtensionClusterName(tracing.ExtensionService), tracing.SNI, tracing.Timeout),
+				ServiceName: tracing.ServiceName,
+			}),
 		},
 	}
 }
@@ -102,6 +135,20 @@ type EnvoyTracingConfig struct {
 	OverallSampling  float64
 	MaxPathTagLength uint32
 	CustomTags       []*CustomTag
+
+	// Zipkin configures the Zipkin tracer, instead of the
+	// OpenTelemetry one, if not nil.
+	Zipkin *ZipkinTracingConfig
+}
+
+// ZipkinTracingConfig configures the Zipkin tracer.
+type ZipkinTracingConfig struct {
+	// CollectorEndpoint is the path of the API of the collector.
+	CollectorEndpoint string
+
+	// ProtoEncoding sends the spans encoded as protobuf
+	// rather than JSON.
+	ProtoEncoding bool
 }
 
 type CustomTag struct {
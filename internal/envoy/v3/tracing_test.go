@@ -182,6 +182,64 @@ func TestTracingConfig(t *testing.T) {
 				},
 			},
 		},
+		"zipkin": {
+			tracing: &EnvoyTracingConfig{
+				ExtensionService: k8s.NamespacedNameFrom("projectcontour/zipkin-collector"),
+				ServiceName:      "contour",
+				Timeout:          timeout.DurationSetting(5 * time.Second),
+				OverallSampling:  10,
+				MaxPathTagLength: 256,
+				Zipkin: &ZipkinTracingConfig{
+					CollectorEndpoint: "/api/v2/spans",
+				},
+			},
+			want: &http.HttpConnectionManager_Tracing{
+				OverallSampling: &envoy_type_v3.Percent{
+					Value: 10.0,
+				},
+				MaxPathTagLength: wrapperspb.UInt32(256),
+				Provider: &envoy_config_trace_v3.Tracing_Http{
+					Name: "envoy.tracers.zipkin",
+					ConfigType: &envoy_config_trace_v3.Tracing_Http_TypedConfig{
+						TypedConfig: protobuf.MustMarshalAny(&envoy_config_trace_v3.ZipkinConfig{
+							CollectorCluster:         "extension/projectcontour/zipkin-collector",
+							CollectorEndpoint:        "/api/v2/spans",
+							CollectorEndpointVersion: envoy_config_trace_v3.ZipkinConfig_HTTP_JSON,
+							CollectorHostname:        "extension.projectcontour.zipkin-collector",
+						}),
+					},
+				},
+			},
+		},
+		"zipkin with SNI and proto encoding": {
+			tracing: &EnvoyTracingConfig{
+				ExtensionService: k8s.NamespacedNameFrom("projectcontour/zipkin-collector"),
+				SNI:              "zipkin.example.com",
+				OverallSampling:  100,
+				MaxPathTagLength: 256,
+				Zipkin: &ZipkinTracingConfig{
+					CollectorEndpoint: "/zipkin/api/v2/spans",
+					ProtoEncoding:     true,
+				},
+			},
+			want: &http.HttpConnectionManager_Tracing{
+				OverallSampling: &envoy_type_v3.Percent{
+					Value: 100.0,
+				},
+				MaxPathTagLength: wrapperspb.UInt32(256),
+				Provider: &envoy_config_trace_v3.Tracing_Http{
+					Name: "envoy.tracers.zipkin",
+					ConfigType: &envoy_config_trace_v3.Tracing_Http_TypedConfig{
+						TypedConfig: protobuf.MustMarshalAny(&envoy_config_trace_v3.ZipkinConfig{
+							CollectorCluster:         "extension/projectcontour/zipkin-collector",
+							CollectorEndpoint:        "/zipkin/api/v2/spans",
+							CollectorEndpointVersion: envoy_config_trace_v3.ZipkinConfig_HTTP_PROTO,
+							CollectorHostname:        "zipkin.example.com",
+						}),
+					},
+				},
+			},
+		},
 	}
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
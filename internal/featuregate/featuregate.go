@@ -0,0 +1,136 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package featuregate holds the feature gates that govern the
+// experimental subsystems of Contour, so that they can ship
+// disabled and be enabled per cluster.
+package featuregate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Feature is the name of a feature gate.
+type Feature string
+
+const (
+	// DeltaXDS enables the incremental xDS protocol variant
+	// of the envoy xDS server.
+	DeltaXDS Feature = "DeltaXDS"
+
+	// GatewayAPIExperimentalRoutes enables the processing of
+	// the TLSRoute, GRPCRoute and TCPRoute resources of the
+	// Gateway API experimental channel.
+	GatewayAPIExperimentalRoutes Feature = "GatewayAPIExperimentalRoutes"
+)
+
+// Stage is the maturity of a feature.
+type Stage string
+
+const (
+	// Alpha features are disabled by default,
+	// and may change or be removed.
+	Alpha Stage = "Alpha"
+
+	// Beta features are enabled by default.
+	Beta Stage = "Beta"
+)
+
+// Spec describes a feature gate.
+type Spec struct {
+	Stage       Stage
+	Default     bool
+	Description string
+}
+
+// Known are the feature gates of Contour.
+var Known = map[Feature]Spec{
+	DeltaXDS: {
+		Stage:       Beta,
+		Default:     true,
+		Description: "Serve the incremental xDS protocol variant with the envoy xDS server.",
+	},
+	GatewayAPIExperimentalRoutes: {
+		Stage:       Beta,
+		Default:     true,
+		Description: "Process TLSRoutes, GRPCRoutes and TCPRoutes of the Gateway API experimental channel.",
+	},
+}
+
+// Gates holds the state of the feature gates.
+type Gates struct {
+	enabled map[Feature]bool
+}
+
+// New returns the Gates with the defaults of the Known gates,
+// overridden by overrides. Unknown gates are an error.
+func New(overrides map[string]bool) (*Gates, error) {
+	g := &Gates{
+		enabled: map[Feature]bool{},
+	}
+	for feature, spec := range Known {
+		g.enabled[feature] = spec.Default
+	}
+
+	var unknown []string
+	for name, enabled := range overrides {
+		if _, ok := Known[Feature(name)]; !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		g.enabled[Feature(name)] = enabled
+	}
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown feature gates: %s", strings.Join(unknown, ", "))
+	}
+
+	return g, nil
+}
+
+// Enabled returns true if feature is enabled.
+func (g *Gates) Enabled(feature Feature) bool {
+	return g.enabled[feature]
+}
+
+// State is the state of a feature gate.
+type State struct {
+	Name        Feature `json:"name"`
+	Stage       Stage   `json:"stage"`
+	Default     bool    `json:"default"`
+	Enabled     bool    `json:"enabled"`
+	Description string  `json:"description"`
+}
+
+// States returns the state of the feature gates, sorted by name.
+func (g *Gates) States() []State {
+	states := make([]State, 0, len(Known))
+	for feature, spec := range Known {
+		states = append(states, State{
+			Name:        feature,
+			Stage:       spec.Stage,
+			Default:     spec.Default,
+			Enabled:     g.enabled[feature],
+			Description: spec.Description,
+		})
+	}
+
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].Name < states[j].Name
+	})
+
+	return states
+}
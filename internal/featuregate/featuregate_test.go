@@ -0,0 +1,55 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package featuregate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGates(t *testing.T) {
+	g, err := New(nil)
+	require.NoError(t, err)
+	assert.True(t, g.Enabled(DeltaXDS))
+	assert.True(t, g.Enabled(GatewayAPIExperimentalRoutes))
+
+	g, err = New(map[string]bool{
+		"DeltaXDS":                     false,
+		"GatewayAPIExperimentalRoutes": false,
+	})
+	require.NoError(t, err)
+	assert.False(t, g.Enabled(DeltaXDS))
+	assert.False(t, g.Enabled(GatewayAPIExperimentalRoutes))
+
+	_, err = New(map[string]bool{"HTTP4": true, "DeltaXDS": true, "Bogus": false})
+	assert.EqualError(t, err, "unknown feature gates: Bogus, HTTP4")
+}
+
+func TestStates(t *testing.T) {
+	g, err := New(map[string]bool{"DeltaXDS": false})
+	require.NoError(t, err)
+
+	states := g.States()
+	require.Len(t, states, len(Known))
+	assert.Equal(t, State{
+		Name:        DeltaXDS,
+		Stage:       Beta,
+		Default:     true,
+		Enabled:     false,
+		Description: Known[DeltaXDS].Description,
+	}, states[0])
+	assert.Equal(t, GatewayAPIExperimentalRoutes, states[1].Name)
+}
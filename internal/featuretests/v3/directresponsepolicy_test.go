@@ -125,3 +125,156 @@ func TestDirectResponsePolicy_HTTProxy(t *testing.T) {
 		TypeUrl: routeType,
 	})
 }
+
+func TestDirectResponsePolicyBodyFromSecret_HTTProxy(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	secret := &v1.Secret{
+		ObjectMeta: fixture.ObjectMeta("robots"),
+		Type:       v1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			"robots.txt": []byte("User-agent: *\nDisallow: /\n"),
+		},
+	}
+	rh.OnAdd(secret)
+
+	proxy := fixture.NewProxy("robots").WithSpec(
+		contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{Fqdn: "directresponse.projectcontour.io"},
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/robots.txt",
+				}},
+				DirectResponsePolicy: &contour_api_v1.HTTPDirectResponsePolicy{
+					StatusCode: 200,
+					BodyFrom: &contour_api_v1.DirectResponseBodySource{
+						SecretName: "robots",
+						Key:        "robots.txt",
+					},
+				},
+			}},
+		})
+	rh.OnAdd(proxy)
+
+	directResponseRoute := func(body string) *envoy_discovery_v3.DiscoveryResponse {
+		return &envoy_discovery_v3.DiscoveryResponse{
+			Resources: resources(t,
+				envoy_v3.RouteConfiguration("ingress_http",
+					envoy_v3.VirtualHost("directresponse.projectcontour.io",
+						&envoy_route_v3.Route{
+							Match: routePrefix("/robots.txt"),
+							Action: &envoy_route_v3.Route_DirectResponse{
+								DirectResponse: &envoy_route_v3.DirectResponseAction{
+									Status: 200,
+									Body: &envoy_core_v3.DataSource{
+										Specifier: &envoy_core_v3.DataSource_InlineString{
+											InlineString: body,
+										},
+									},
+								},
+							},
+						},
+					),
+				),
+			),
+			TypeUrl: routeType,
+		}
+	}
+
+	c.Request(routeType).Equals(directResponseRoute("User-agent: *\nDisallow: /\n"))
+
+	// Updating the Secret updates the body.
+	updated := secret.DeepCopy()
+	updated.ResourceVersion = "2"
+	updated.Data["robots.txt"] = []byte("User-agent: *\nAllow: /\n")
+	rh.OnUpdate(secret, updated)
+
+	c.Request(routeType).Equals(directResponseRoute("User-agent: *\nAllow: /\n"))
+
+	// The route is dropped when the Secret is deleted.
+	rh.OnDelete(updated)
+
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http"),
+		),
+		TypeUrl: routeType,
+	})
+}
+
+func TestDirectResponsePolicyBodyFromConfigMap_HTTProxy(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	configMap := &v1.ConfigMap{
+		ObjectMeta: fixture.ObjectMeta("robots"),
+		Data: map[string]string{
+			"robots.txt": "User-agent: *\nDisallow: /\n",
+		},
+	}
+	rh.OnAdd(configMap)
+
+	proxy := fixture.NewProxy("robots").WithSpec(
+		contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{Fqdn: "directresponse.projectcontour.io"},
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/robots.txt",
+				}},
+				DirectResponsePolicy: &contour_api_v1.HTTPDirectResponsePolicy{
+					StatusCode: 200,
+					BodyFrom: &contour_api_v1.DirectResponseBodySource{
+						ConfigMapName: "robots",
+						Key:           "robots.txt",
+					},
+				},
+			}},
+		})
+	rh.OnAdd(proxy)
+
+	directResponseRoute := func(body string) *envoy_discovery_v3.DiscoveryResponse {
+		return &envoy_discovery_v3.DiscoveryResponse{
+			Resources: resources(t,
+				envoy_v3.RouteConfiguration("ingress_http",
+					envoy_v3.VirtualHost("directresponse.projectcontour.io",
+						&envoy_route_v3.Route{
+							Match: routePrefix("/robots.txt"),
+							Action: &envoy_route_v3.Route_DirectResponse{
+								DirectResponse: &envoy_route_v3.DirectResponseAction{
+									Status: 200,
+									Body: &envoy_core_v3.DataSource{
+										Specifier: &envoy_core_v3.DataSource_InlineString{
+											InlineString: body,
+										},
+									},
+								},
+							},
+						},
+					),
+				),
+			),
+			TypeUrl: routeType,
+		}
+	}
+
+	c.Request(routeType).Equals(directResponseRoute("User-agent: *\nDisallow: /\n"))
+
+	// Updating the ConfigMap updates the body.
+	updated := configMap.DeepCopy()
+	updated.ResourceVersion = "2"
+	updated.Data["robots.txt"] = "User-agent: *\nAllow: /\n"
+	rh.OnUpdate(configMap, updated)
+
+	c.Request(routeType).Equals(directResponseRoute("User-agent: *\nAllow: /\n"))
+
+	// The route is dropped when the ConfigMap is deleted.
+	rh.OnDelete(updated)
+
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http"),
+		),
+		TypeUrl: routeType,
+	})
+}
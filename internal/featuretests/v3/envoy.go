@@ -418,7 +418,7 @@ func filterchaintls(domain string, secret *v1.Secret, filter *envoy_listener_v3.
 	return envoy_v3.FilterChainTLS(
 		domain,
 		envoy_v3.DownstreamTLSContext(
-			&dag.Secret{Object: secret},
+			envoy_v3.SecretConfig(&dag.Secret{Object: secret}),
 			envoy_tls_v3.TlsParameters_TLSv1_2,
 			nil,
 			peerValidationContext,
@@ -431,7 +431,7 @@ func filterchaintls(domain string, secret *v1.Secret, filter *envoy_listener_v3.
 func filterchaintlsfallback(fallbackSecret *v1.Secret, peerValidationContext *dag.PeerValidationContext, alpn ...string) *envoy_listener_v3.FilterChain {
 	return envoy_v3.FilterChainTLSFallback(
 		envoy_v3.DownstreamTLSContext(
-			&dag.Secret{Object: fallbackSecret},
+			envoy_v3.SecretConfig(&dag.Secret{Object: fallbackSecret}),
 			envoy_tls_v3.TlsParameters_TLSv1_2,
 			nil,
 			peerValidationContext,
@@ -580,7 +580,8 @@ func statsListener() *envoy_listener_v3.Listener {
 	// Single listener with metrics and health endpoints.
 	listeners := envoy_v3.StatsListeners(
 		contour_api_v1alpha1.MetricsConfig{Address: "0.0.0.0", Port: 8002},
-		contour_api_v1alpha1.HealthConfig{Address: "0.0.0.0", Port: 8002})
+		contour_api_v1alpha1.HealthConfig{Address: "0.0.0.0", Port: 8002},
+		envoy_v3.HealthSettings{}, "")
 	return listeners[0]
 }
 
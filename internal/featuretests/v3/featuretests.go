@@ -149,7 +149,7 @@ func setup(t *testing.T, opts ...any) (ResourceEventHandlerWrapper, *Contour, fu
 		HoldoffMaxDelay: time.Duration(rand.Intn(500)) * time.Millisecond,
 		Observer: contour.NewRebuildMetricsObserver(
 			metrics.NewMetrics(registry),
-			dag.ComposeObservers(xdscache.ObserversOf(resources)...),
+			dag.ComposeObserversParallel(0, xdscache.ObserversOf(resources)...),
 		),
 		Builder: builder,
 	})
@@ -158,7 +158,7 @@ func setup(t *testing.T, opts ...any) (ResourceEventHandlerWrapper, *Contour, fu
 	require.NoError(t, err)
 
 	srv := xds.NewServer(registry)
-	contour_xds_v3.RegisterServer(contour_xds_v3.NewContourServer(log, xdscache.ResourcesOf(resources)...), srv)
+	contour_xds_v3.RegisterServer(contour_xds_v3.NewContourServer(log, nil, xdscache.ResourcesOf(resources)...), srv)
 
 	var wg sync.WaitGroup
 	ctx, cancel := context.WithCancel(context.Background())
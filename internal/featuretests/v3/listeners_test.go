@@ -406,7 +406,7 @@ func TestHTTPProxyTLSListener(t *testing.T) {
 			envoy_v3.FilterChainTLS(
 				"kuard.example.com",
 				envoy_v3.DownstreamTLSContext(
-					&dag.Secret{Object: secret1},
+					envoy_v3.SecretConfig(&dag.Secret{Object: secret1}),
 					envoy_tls_v3.TlsParameters_TLSv1_3,
 					nil,
 					nil,
@@ -487,7 +487,7 @@ func TestTLSListenerCipherSuites(t *testing.T) {
 			envoy_v3.FilterChainTLS(
 				"kuard.example.com",
 				envoy_v3.DownstreamTLSContext(
-					&dag.Secret{Object: secret1},
+					envoy_v3.SecretConfig(&dag.Secret{Object: secret1}),
 					envoy_tls_v3.TlsParameters_TLSv1_2,
 					[]string{"ECDHE-ECDSA-AES256-GCM-SHA384"},
 					nil,
@@ -1077,7 +1077,7 @@ func TestHTTPProxyMinimumTLSVersion(t *testing.T) {
 			envoy_v3.FilterChainTLS(
 				"kuard.example.com",
 				envoy_v3.DownstreamTLSContext(
-					&dag.Secret{Object: secret1},
+					envoy_v3.SecretConfig(&dag.Secret{Object: secret1}),
 					envoy_tls_v3.TlsParameters_TLSv1_2,
 					nil,
 					nil,
@@ -1133,7 +1133,7 @@ func TestHTTPProxyMinimumTLSVersion(t *testing.T) {
 			envoy_v3.FilterChainTLS(
 				"kuard.example.com",
 				envoy_v3.DownstreamTLSContext(
-					&dag.Secret{Object: secret1},
+					envoy_v3.SecretConfig(&dag.Secret{Object: secret1}),
 					envoy_tls_v3.TlsParameters_TLSv1_3,
 					nil,
 					nil,
@@ -0,0 +1,112 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"testing"
+
+	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	envoy_discovery_v3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
+	"github.com/projectcontour/contour/internal/dag"
+	envoy_v3 "github.com/projectcontour/contour/internal/envoy/v3"
+	"github.com/projectcontour/contour/internal/fixture"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestConditions_Method_HTTPProxy(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	for _, name := range []string{"svc1", "svc2", "svc3"} {
+		rh.OnAdd(fixture.NewService(name).
+			WithPorts(v1.ServicePort{Port: 80, TargetPort: intstr.FromInt(8080)}),
+		)
+	}
+
+	rh.OnAdd(fixture.NewProxy("simple").WithSpec(
+		contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{Fqdn: "hello.world"},
+			Includes: []contour_api_v1.Include{{
+				Name: "api",
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/api",
+				}, {
+					Methods: []contour_api_v1.HTTPMethod{"GET", "POST"},
+				}},
+			}},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: "svc1",
+					Port: 80,
+				}},
+			}, {
+				Conditions: []contour_api_v1.MatchCondition{{
+					Methods: []contour_api_v1.HTTPMethod{"GET", "HEAD"},
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: "svc2",
+					Port: 80,
+				}},
+			}},
+		}),
+	)
+
+	// The methods of the route are merged with
+	// those of the include that it belongs to.
+	rh.OnAdd(fixture.NewProxy("api").WithSpec(
+		contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Methods: []contour_api_v1.HTTPMethod{"POST", "PUT"},
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: "svc3",
+					Port: 80,
+				}},
+			}},
+		}),
+	)
+
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http",
+				envoy_v3.VirtualHost("hello.world",
+					&envoy_route_v3.Route{
+						Match: routePrefixWithHeaderConditions("/api", dag.HeaderMatchCondition{
+							Name:      ":method",
+							Value:     "POST",
+							MatchType: "exact",
+						}),
+						Action: routeCluster("default/svc3/80/da39a3ee5e"),
+					},
+					&envoy_route_v3.Route{
+						Match: routePrefixWithHeaderConditions("/", dag.HeaderMatchCondition{
+							Name:      ":method",
+							Value:     "GET|HEAD",
+							MatchType: "regex",
+						}),
+						Action: routeCluster("default/svc2/80/da39a3ee5e"),
+					},
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/"),
+						Action: routeCluster("default/svc1/80/da39a3ee5e"),
+					},
+				),
+			),
+		),
+		TypeUrl: routeType,
+	})
+}
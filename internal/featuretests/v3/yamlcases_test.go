@@ -0,0 +1,152 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	envoy_cluster_v3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	envoy_endpoint_v3 "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
+	envoy_listener_v3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	envoy_tls_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
+	"github.com/projectcontour/contour/internal/k8s"
+	"github.com/projectcontour/contour/internal/protobuf"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"sigs.k8s.io/yaml"
+)
+
+var updateYAMLCases = flag.Bool("update", false, "Rewrite the expected xDS resources of the YAML test cases with the current output.")
+
+// yamlCase is a feature test declared in a YAML file in testdata/cases.
+// The objects are added to Contour in order, then the xDS resources of
+// each type listed in expected must match exactly. Resources are written
+// in the protobuf JSON mapping, with Any fields identified by "@type".
+//
+// To add a case, write a file with a description and objects, and an
+// empty list for each type of resource to check, then run:
+//
+//	go test ./internal/featuretests/v3 -run TestYAMLCases -update
+//
+// and check that the generated resources are correct.
+type yamlCase struct {
+	Description string                       `json:"description"`
+	Objects     []json.RawMessage            `json:"objects"`
+	Expected    map[string][]json.RawMessage `json:"expected"`
+}
+
+// yamlCaseTypes maps the keys of a yamlCase's expected
+// resources to their xDS type URL and message type.
+var yamlCaseTypes = map[string]struct {
+	typeURL string
+	new     func() proto.Message
+}{
+	"clusters":  {clusterType, func() proto.Message { return &envoy_cluster_v3.Cluster{} }},
+	"endpoints": {endpointType, func() proto.Message { return &envoy_endpoint_v3.ClusterLoadAssignment{} }},
+	"listeners": {listenerType, func() proto.Message { return &envoy_listener_v3.Listener{} }},
+	"routes":    {routeType, func() proto.Message { return &envoy_route_v3.RouteConfiguration{} }},
+	"secrets":   {secretType, func() proto.Message { return &envoy_tls_v3.Secret{} }},
+}
+
+func TestYAMLCases(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "cases", "*.yaml"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	for _, file := range files {
+		file := file
+		t.Run(strings.TrimSuffix(filepath.Base(file), ".yaml"), func(t *testing.T) {
+			runYAMLCase(t, file)
+		})
+	}
+}
+
+func runYAMLCase(t *testing.T, file string) {
+	buf, err := os.ReadFile(file)
+	require.NoError(t, err)
+
+	var tc yamlCase
+	require.NoError(t, yaml.UnmarshalStrict(buf, &tc))
+	require.NotEmpty(t, tc.Expected, "%s has no expected resources", file)
+
+	scheme, err := k8s.NewContourScheme()
+	require.NoError(t, err)
+	deserializer := serializer.NewCodecFactory(scheme).UniversalDeserializer()
+
+	rh, c, done := setup(t)
+	defer done()
+
+	for i, raw := range tc.Objects {
+		obj, _, err := deserializer.Decode(raw, nil, nil)
+		require.NoErrorf(t, err, "decoding object %d", i)
+		rh.OnAdd(obj)
+	}
+
+	kinds := make([]string, 0, len(tc.Expected))
+	for kind := range tc.Expected {
+		_, ok := yamlCaseTypes[kind]
+		require.Truef(t, ok, "unknown resource type %q", kind)
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	for _, kind := range kinds {
+		got := c.Request(yamlCaseTypes[kind].typeURL).Resources
+
+		if *updateYAMLCases {
+			tc.Expected[kind] = yamlCaseResources(t, got)
+			continue
+		}
+
+		want := make([]*anypb.Any, 0, len(tc.Expected[kind]))
+		for i, raw := range tc.Expected[kind] {
+			msg := yamlCaseTypes[kind].new()
+			require.NoErrorf(t, protojson.Unmarshal(raw, msg), "unmarshaling %s %d", kind, i)
+			want = append(want, protobuf.MustMarshalAny(msg))
+		}
+
+		protobuf.RequireEqual(t, want, got)
+	}
+
+	if *updateYAMLCases {
+		out, err := yaml.Marshal(tc)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(file, out, 0600))
+	}
+}
+
+// yamlCaseResources converts xDS resources to their JSON mapping.
+func yamlCaseResources(t *testing.T, resources []*anypb.Any) []json.RawMessage {
+	out := make([]json.RawMessage, 0, len(resources))
+	for _, r := range resources {
+		msg, err := r.UnmarshalNew()
+		require.NoError(t, err)
+
+		buf, err := protojson.Marshal(msg)
+		require.NoError(t, err)
+
+		out = append(out, buf)
+	}
+	return out
+}
@@ -17,6 +17,7 @@ package httpsvc
 
 import (
 	"context"
+	"crypto/subtle"
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
@@ -24,8 +25,10 @@ import (
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/projectcontour/contour/internal/certsan"
 	"github.com/sirupsen/logrus"
 )
 
@@ -39,6 +42,10 @@ type Service struct {
 	Cert     string
 	Key      string
 
+	// AllowedSANs is the list of Subject Alternative Names of which
+	// the client certificate must present one. Requires CABundle.
+	AllowedSANs []string
+
 	logrus.FieldLogger
 	http.ServeMux
 }
@@ -128,11 +135,19 @@ func (svc *Service) tlsConfig() (*tls.Config, error) {
 			}
 		}
 
+		var verifyConnection func(tls.ConnectionState) error
+		if len(svc.AllowedSANs) > 0 {
+			verifyConnection = func(cs tls.ConnectionState) error {
+				return certsan.Verify(cs, svc.AllowedSANs)
+			}
+		}
+
 		return &tls.Config{
-			Certificates: []tls.Certificate{cert},
-			ClientAuth:   clientAuth,
-			ClientCAs:    certPool,
-			MinVersion:   tls.VersionTLS13,
+			Certificates:     []tls.Certificate{cert},
+			ClientAuth:       clientAuth,
+			ClientCAs:        certPool,
+			MinVersion:       tls.VersionTLS13,
+			VerifyConnection: verifyConnection,
 		}, nil
 	}
 
@@ -148,3 +163,26 @@ func (svc *Service) tlsConfig() (*tls.Config, error) {
 		},
 	}, nil
 }
+
+// RequireBearerToken returns a http.Handler that serves only the requests
+// that present the bearer token held by tokenFile in their Authorization
+// header. The file is read on every request, so that the token can be
+// rotated.
+func RequireBearerToken(tokenFile string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, err := os.ReadFile(tokenFile)
+		if err != nil || len(strings.TrimSpace(string(token))) == 0 {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		want := "Bearer " + strings.TrimSpace(string(token))
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(want)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -18,6 +18,7 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"sync"
@@ -171,6 +172,110 @@ func TestHTTPSService(t *testing.T) {
 	wg.Wait()
 }
 
+func TestHTTPSServiceAllowedSANs(t *testing.T) {
+	caCert := certyaml.Certificate{
+		Subject: "cn=ca",
+	}
+	serverCert := certyaml.Certificate{
+		Subject:         "cn=contour",
+		SubjectAltNames: []string{"DNS:localhost"},
+		Issuer:          &caCert,
+	}
+	allowedClientCert := certyaml.Certificate{
+		Subject:         "cn=prometheus",
+		SubjectAltNames: []string{"DNS:prometheus"},
+		Issuer:          &caCert,
+	}
+	otherClientCert := certyaml.Certificate{
+		Subject:         "cn=other",
+		SubjectAltNames: []string{"DNS:other"},
+		Issuer:          &caCert,
+	}
+
+	configDir := t.TempDir()
+
+	svc := httpsvc.Service{
+		Addr:        "localhost",
+		Port:        8001,
+		CABundle:    filepath.Join(configDir, "ca.pem"),
+		Cert:        filepath.Join(configDir, "server.pem"),
+		Key:         filepath.Join(configDir, "server-key.pem"),
+		AllowedSANs: []string{"prometheus"},
+		FieldLogger: fixture.NewTestLogger(t),
+	}
+
+	checkFatalErr(t, caCert.WritePEM(svc.CABundle, filepath.Join(configDir, "ca-key.pem")))
+	checkFatalErr(t, serverCert.WritePEM(svc.Cert, svc.Key))
+
+	svc.ServeMux.HandleFunc("/test", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		// nolint:errcheck
+		svc.Start(ctx)
+		wg.Done()
+	}()
+
+	caCertPool := x509.NewCertPool()
+	ca, err := caCert.X509Certificate()
+	checkFatalErr(t, err)
+	caCertPool.AddCert(&ca)
+
+	allowedTLSClientCert, _ := allowedClientCert.TLSCertificate()
+	assert.Eventually(t, func() bool {
+		resp, err := tryGet("https://localhost:8001/test", allowedTLSClientCert, caCertPool)
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	}, 1*time.Second, 100*time.Millisecond)
+
+	// Connection should fail when the client cert has none of the allowed SANs.
+	otherTLSClientCert, _ := otherClientCert.TLSCertificate()
+	_, err = tryGet("https://localhost:8001/test", otherTLSClientCert, caCertPool) // nolint // false positive: response body must be closed
+	assert.NotNil(t, err)
+
+	// Gracefully shut down.
+	cancel()
+	wg.Wait()
+}
+
+func TestRequireBearerToken(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	handler := httpsvc.RequireBearerToken(tokenFile, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	get := func(authorization string) int {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// A missing token file denies all requests.
+	assert.Equal(t, http.StatusInternalServerError, get("Bearer secret"))
+
+	checkFatalErr(t, os.WriteFile(tokenFile, []byte("secret\n"), 0600))
+	assert.Equal(t, http.StatusOK, get("Bearer secret"))
+	assert.Equal(t, http.StatusUnauthorized, get("Bearer other"))
+	assert.Equal(t, http.StatusUnauthorized, get(""))
+
+	// The token is read on every request.
+	checkFatalErr(t, os.WriteFile(tokenFile, []byte("rotated"), 0600))
+	assert.Equal(t, http.StatusUnauthorized, get("Bearer secret"))
+	assert.Equal(t, http.StatusOK, get("Bearer rotated"))
+}
+
 func checkFatalErr(t *testing.T, err error) {
 	t.Helper()
 	if err != nil {
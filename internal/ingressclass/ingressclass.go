@@ -38,13 +38,19 @@ func MatchesIngress(obj *networking_v1.Ingress, ingressClassNames []string) bool
 
 // MatchesHTTPProxy returns true if the passed in HTTPProxy annotations
 // or Spec.IngressClassName match the passed in ingress class name.
-// Annotations take precedence over spec field if both are set.
-func MatchesHTTPProxy(obj *contour_v1.HTTPProxy, ingressClassNames []string) bool {
+// Annotations take precedence over spec field if both are set. If
+// neither is set, namespaceDefaultClass, the default ingress class of
+// the namespace of the HTTPProxy, if any, is matched instead.
+func MatchesHTTPProxy(obj *contour_v1.HTTPProxy, ingressClassNames []string, namespaceDefaultClass string) bool {
 	if annotationClass := annotation.IngressClass(obj); annotationClass != "" {
 		return matches(annotationClass, ingressClassNames)
 	}
 
-	return matches(obj.Spec.IngressClassName, ingressClassNames)
+	if obj.Spec.IngressClassName != "" {
+		return matches(obj.Spec.IngressClassName, ingressClassNames)
+	}
+
+	return matches(namespaceDefaultClass, ingressClassNames)
 }
 
 func matches(objIngressClass string, contourIngressClasses []string) bool {
@@ -146,7 +146,7 @@ func TestMatchesIngress(t *testing.T) {
 
 func TestMatchesHTTPProxy(t *testing.T) {
 	// No annotation, no spec field set, class not configured
-	assert.True(t, MatchesHTTPProxy(&contour_v1.HTTPProxy{}, nil))
+	assert.True(t, MatchesHTTPProxy(&contour_v1.HTTPProxy{}, nil, ""))
 	// Annotation set to default, no spec field set, class not configured
 	assert.True(t, MatchesHTTPProxy(&contour_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
@@ -154,13 +154,13 @@ func TestMatchesHTTPProxy(t *testing.T) {
 				"kubernetes.io/ingress.class": "contour",
 			},
 		},
-	}, nil))
+	}, nil, ""))
 	// No annotation set, spec field set to default, class not configured
 	assert.True(t, MatchesHTTPProxy(&contour_v1.HTTPProxy{
 		Spec: contour_v1.HTTPProxySpec{
 			IngressClassName: "contour",
 		},
-	}, nil))
+	}, nil, ""))
 	// Annotation set, no spec field set, class not configured
 	assert.False(t, MatchesHTTPProxy(&contour_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
@@ -168,15 +168,15 @@ func TestMatchesHTTPProxy(t *testing.T) {
 				"kubernetes.io/ingress.class": "foo",
 			},
 		},
-	}, nil))
+	}, nil, ""))
 	// No annotation set, spec field set, class not configured
 	assert.False(t, MatchesHTTPProxy(&contour_v1.HTTPProxy{
 		Spec: contour_v1.HTTPProxySpec{
 			IngressClassName: "aclass",
 		},
-	}, nil))
+	}, nil, ""))
 	// No annotation, no spec field set, class configured
-	assert.False(t, MatchesHTTPProxy(&contour_v1.HTTPProxy{}, []string{"something"}))
+	assert.False(t, MatchesHTTPProxy(&contour_v1.HTTPProxy{}, []string{"something"}, ""))
 	// Annotation set, no spec field set, class configured
 	assert.True(t, MatchesHTTPProxy(&contour_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
@@ -184,13 +184,13 @@ func TestMatchesHTTPProxy(t *testing.T) {
 				"kubernetes.io/ingress.class": "something",
 			},
 		},
-	}, []string{"something"}))
+	}, []string{"something"}, ""))
 	// No annotation set, spec field set, class configured
 	assert.True(t, MatchesHTTPProxy(&contour_v1.HTTPProxy{
 		Spec: contour_v1.HTTPProxySpec{
 			IngressClassName: "something",
 		},
-	}, []string{"something"}))
+	}, []string{"something"}, ""))
 	// Annotation set, no spec field set, class configured
 	assert.False(t, MatchesHTTPProxy(&contour_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
@@ -198,13 +198,13 @@ func TestMatchesHTTPProxy(t *testing.T) {
 				"kubernetes.io/ingress.class": "foo",
 			},
 		},
-	}, []string{"something"}))
+	}, []string{"something"}, ""))
 	// No annotation set, spec field set, class configured
 	assert.False(t, MatchesHTTPProxy(&contour_v1.HTTPProxy{
 		Spec: contour_v1.HTTPProxySpec{
 			IngressClassName: "aclass",
 		},
-	}, []string{"something"}))
+	}, []string{"something"}, ""))
 	// Annotation set, spec field set, class configured
 	assert.True(t, MatchesHTTPProxy(&contour_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
@@ -215,7 +215,7 @@ func TestMatchesHTTPProxy(t *testing.T) {
 		Spec: contour_v1.HTTPProxySpec{
 			IngressClassName: "aclass",
 		},
-	}, []string{"something"}))
+	}, []string{"something"}, ""))
 	// Annotation set, spec field set, class configured
 	assert.False(t, MatchesHTTPProxy(&contour_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
@@ -226,7 +226,7 @@ func TestMatchesHTTPProxy(t *testing.T) {
 		Spec: contour_v1.HTTPProxySpec{
 			IngressClassName: "something",
 		},
-	}, []string{"something"}))
+	}, []string{"something"}, ""))
 	// Multiple classes: Annotation set, no spec field set, class configured
 	assert.True(t, MatchesHTTPProxy(&contour_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
@@ -234,13 +234,13 @@ func TestMatchesHTTPProxy(t *testing.T) {
 				"kubernetes.io/ingress.class": "something",
 			},
 		},
-	}, []string{"something", "somethingelse"}))
+	}, []string{"something", "somethingelse"}, ""))
 	// Multiple classes: No annotation set, spec field set, class configured
 	assert.True(t, MatchesHTTPProxy(&contour_v1.HTTPProxy{
 		Spec: contour_v1.HTTPProxySpec{
 			IngressClassName: "something",
 		},
-	}, []string{"athing", "something", "somethingelse"}))
+	}, []string{"athing", "something", "somethingelse"}, ""))
 	// Multiple classes: Annotation set, no spec field set, class configured
 	assert.False(t, MatchesHTTPProxy(&contour_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
@@ -248,13 +248,13 @@ func TestMatchesHTTPProxy(t *testing.T) {
 				"kubernetes.io/ingress.class": "foo",
 			},
 		},
-	}, []string{"something", "somethingelse"}))
+	}, []string{"something", "somethingelse"}, ""))
 	// Multiple classes: No annotation set, spec field set, class configured
 	assert.False(t, MatchesHTTPProxy(&contour_v1.HTTPProxy{
 		Spec: contour_v1.HTTPProxySpec{
 			IngressClassName: "aclass",
 		},
-	}, []string{"somethingelse", "something"}))
+	}, []string{"somethingelse", "something"}, ""))
 	// Multiple classes: Annotation set, spec field set, class configured
 	assert.True(t, MatchesHTTPProxy(&contour_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
@@ -265,7 +265,7 @@ func TestMatchesHTTPProxy(t *testing.T) {
 		Spec: contour_v1.HTTPProxySpec{
 			IngressClassName: "aclass",
 		},
-	}, []string{"somethingelse", "something"}))
+	}, []string{"somethingelse", "something"}, ""))
 	// Multiple classes: Annotation set, spec field set, class configured
 	assert.False(t, MatchesHTTPProxy(&contour_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
@@ -276,5 +276,36 @@ func TestMatchesHTTPProxy(t *testing.T) {
 		Spec: contour_v1.HTTPProxySpec{
 			IngressClassName: "something",
 		},
-	}, []string{"something", "somethingelse"}))
+	}, []string{"something", "somethingelse"}, ""))
+}
+
+func TestMatchesHTTPProxyNamespaceDefault(t *testing.T) {
+	// No annotation, no spec field set, namespace default configured
+	assert.True(t, MatchesHTTPProxy(&contour_v1.HTTPProxy{}, []string{"something"}, "something"))
+	// No annotation, no spec field set, namespace default not configured
+	assert.False(t, MatchesHTTPProxy(&contour_v1.HTTPProxy{}, []string{"something"}, "somethingelse"))
+	// No annotation, no spec field set, namespace default, class not configured
+	assert.False(t, MatchesHTTPProxy(&contour_v1.HTTPProxy{}, nil, "something"))
+	// No annotation, no spec field set, namespace default set to default, class not configured
+	assert.True(t, MatchesHTTPProxy(&contour_v1.HTTPProxy{}, nil, "contour"))
+	// Annotation set, no spec field set, namespace default configured
+	assert.False(t, MatchesHTTPProxy(&contour_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				"projectcontour.io/ingress.class": "foo",
+			},
+		},
+	}, []string{"something"}, "something"))
+	// No annotation set, spec field set, namespace default configured
+	assert.False(t, MatchesHTTPProxy(&contour_v1.HTTPProxy{
+		Spec: contour_v1.HTTPProxySpec{
+			IngressClassName: "aclass",
+		},
+	}, []string{"something"}, "something"))
+	// No annotation set, spec field set, namespace default not configured
+	assert.True(t, MatchesHTTPProxy(&contour_v1.HTTPProxy{
+		Spec: contour_v1.HTTPProxySpec{
+			IngressClassName: "something",
+		},
+	}, []string{"something"}, "aclass"))
 }
@@ -0,0 +1,112 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	flowcontrol_v1beta2 "k8s.io/api/flowcontrol/v1beta2"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// FlowSchemaMatchingPrecedence is the matching precedence of the
+// FlowSchema that Contour manages. It takes precedence over the
+// suggested FlowSchemas of service accounts, but not over the ones
+// of the system components.
+const FlowSchemaMatchingPrecedence = 1000
+
+// FlowSchemaName returns the name of the FlowSchema that Contour
+// manages for the service accounts of the supplied namespace.
+func FlowSchemaName(namespace string) string {
+	return "contour-" + namespace
+}
+
+// EnsureFlowSchema creates or updates the FlowSchema that assigns the
+// requests of the service accounts of the supplied namespace, which
+// Contour runs in, to the supplied API Priority and Fairness priority
+// level. It returns an error if the priority level doesn't exist.
+func EnsureFlowSchema(ctx context.Context, client kubernetes.Interface, namespace, priorityLevel string) error {
+	if _, err := client.FlowcontrolV1beta2().PriorityLevelConfigurations().Get(ctx, priorityLevel, metav1.GetOptions{}); err != nil {
+		return fmt.Errorf("failed to get priority level %q: %w", priorityLevel, err)
+	}
+
+	desired := flowSchemaFor(namespace, priorityLevel)
+
+	flowSchemas := client.FlowcontrolV1beta2().FlowSchemas()
+	current, err := flowSchemas.Get(ctx, desired.Name, metav1.GetOptions{})
+	switch {
+	case errors.IsNotFound(err):
+		if _, err := flowSchemas.Create(ctx, desired, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create flow schema %q: %w", desired.Name, err)
+		}
+		return nil
+	case err != nil:
+		return fmt.Errorf("failed to get flow schema %q: %w", desired.Name, err)
+	}
+
+	if equality.Semantic.DeepEqual(current.Spec, desired.Spec) && equality.Semantic.DeepEqual(current.Labels, desired.Labels) {
+		return nil
+	}
+
+	current.Spec = desired.Spec
+	current.Labels = desired.Labels
+	if _, err := flowSchemas.Update(ctx, current, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update flow schema %q: %w", desired.Name, err)
+	}
+
+	return nil
+}
+
+func flowSchemaFor(namespace, priorityLevel string) *flowcontrol_v1beta2.FlowSchema {
+	return &flowcontrol_v1beta2.FlowSchema{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: FlowSchemaName(namespace),
+			Labels: map[string]string{
+				"app.kubernetes.io/managed-by": "contour",
+			},
+		},
+		Spec: flowcontrol_v1beta2.FlowSchemaSpec{
+			PriorityLevelConfiguration: flowcontrol_v1beta2.PriorityLevelConfigurationReference{
+				Name: priorityLevel,
+			},
+			MatchingPrecedence: FlowSchemaMatchingPrecedence,
+			DistinguisherMethod: &flowcontrol_v1beta2.FlowDistinguisherMethod{
+				Type: flowcontrol_v1beta2.FlowDistinguisherMethodByUserType,
+			},
+			Rules: []flowcontrol_v1beta2.PolicyRulesWithSubjects{{
+				Subjects: []flowcontrol_v1beta2.Subject{{
+					Kind: flowcontrol_v1beta2.SubjectKindGroup,
+					Group: &flowcontrol_v1beta2.GroupSubject{
+						Name: "system:serviceaccounts:" + namespace,
+					},
+				}},
+				ResourceRules: []flowcontrol_v1beta2.ResourcePolicyRule{{
+					Verbs:        []string{flowcontrol_v1beta2.VerbAll},
+					APIGroups:    []string{flowcontrol_v1beta2.APIGroupAll},
+					Resources:    []string{flowcontrol_v1beta2.ResourceAll},
+					ClusterScope: true,
+					Namespaces:   []string{flowcontrol_v1beta2.NamespaceEvery},
+				}},
+				NonResourceRules: []flowcontrol_v1beta2.NonResourcePolicyRule{{
+					Verbs:           []string{flowcontrol_v1beta2.VerbAll},
+					NonResourceURLs: []string{flowcontrol_v1beta2.NonResourceAll},
+				}},
+			}},
+		},
+	}
+}
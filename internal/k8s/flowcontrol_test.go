@@ -0,0 +1,80 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	flowcontrol_v1beta2 "k8s.io/api/flowcontrol/v1beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestEnsureFlowSchema(t *testing.T) {
+	ctx := context.Background()
+	priorityLevel := &flowcontrol_v1beta2.PriorityLevelConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "contour"},
+	}
+
+	getFlowSchema := func(t *testing.T, client *fake.Clientset) *flowcontrol_v1beta2.FlowSchema {
+		fs, err := client.FlowcontrolV1beta2().FlowSchemas().Get(ctx, "contour-projectcontour", metav1.GetOptions{})
+		require.NoError(t, err)
+		return fs
+	}
+
+	t.Run("missing priority level", func(t *testing.T) {
+		client := fake.NewSimpleClientset()
+
+		err := EnsureFlowSchema(ctx, client, "projectcontour", "contour")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `failed to get priority level "contour"`)
+	})
+
+	t.Run("create", func(t *testing.T) {
+		client := fake.NewSimpleClientset(priorityLevel)
+
+		require.NoError(t, EnsureFlowSchema(ctx, client, "projectcontour", "contour"))
+
+		fs := getFlowSchema(t, client)
+		assert.Equal(t, "contour", fs.Spec.PriorityLevelConfiguration.Name)
+		assert.Equal(t, int32(FlowSchemaMatchingPrecedence), fs.Spec.MatchingPrecedence)
+		require.Len(t, fs.Spec.Rules, 1)
+		assert.Equal(t, []flowcontrol_v1beta2.Subject{{
+			Kind:  flowcontrol_v1beta2.SubjectKindGroup,
+			Group: &flowcontrol_v1beta2.GroupSubject{Name: "system:serviceaccounts:projectcontour"},
+		}}, fs.Spec.Rules[0].Subjects)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		existing := flowSchemaFor("projectcontour", "workload-low")
+		client := fake.NewSimpleClientset(priorityLevel, existing)
+
+		require.NoError(t, EnsureFlowSchema(ctx, client, "projectcontour", "contour"))
+
+		assert.Equal(t, "contour", getFlowSchema(t, client).Spec.PriorityLevelConfiguration.Name)
+	})
+
+	t.Run("up to date", func(t *testing.T) {
+		client := fake.NewSimpleClientset(priorityLevel, flowSchemaFor("projectcontour", "contour"))
+
+		require.NoError(t, EnsureFlowSchema(ctx, client, "projectcontour", "contour"))
+
+		for _, action := range client.Actions() {
+			assert.NotEqual(t, "update", action.GetVerb())
+		}
+	})
+}
@@ -129,6 +129,11 @@ func IsObjectEqual(old, new client.Object) (bool, error) {
 		if new, ok := new.(*v1.Secret); ok {
 			return reflect.DeepEqual(old.Data, new.Data), nil
 		}
+	case *v1.ConfigMap:
+		if new, ok := new.(*v1.ConfigMap); ok {
+			return reflect.DeepEqual(old.Data, new.Data) &&
+				reflect.DeepEqual(old.BinaryData, new.BinaryData), nil
+		}
 	case *v1.Service:
 		if new, ok := new.(*v1.Service); ok {
 			return apiequality.Semantic.DeepEqual(old.Spec, new.Spec) &&
@@ -47,6 +47,11 @@ func TestIsObjectEqual(t *testing.T) {
 			filename: "testdata/secret-metadata-change.yaml",
 			equals:   true,
 		},
+		{
+			name:     "ConfigMap with content change",
+			filename: "testdata/configmap-content-change.yaml",
+			equals:   false,
+		},
 		{
 			name:     "Service with status change",
 			filename: "testdata/service-status-change.yaml",
@@ -134,17 +139,14 @@ func TestIsEqualForResourceVersion(t *testing.T) {
 	assert.True(t, got)
 }
 
-// TestIsEqualFallback compares with ConfigMap objects, which are not supported.
+// TestIsEqualFallback compares with ServiceAccount objects, which are not supported.
 func TestIsEqualFallback(t *testing.T) {
-	old := &v1.ConfigMap{
+	old := &v1.ServiceAccount{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:            "test",
 			Namespace:       "default",
 			ResourceVersion: "123",
 		},
-		Data: map[string]string{
-			"foo": "bar",
-		},
 	}
 
 	new := old.DeepCopy()
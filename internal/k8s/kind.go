@@ -14,6 +14,7 @@
 package k8s
 
 import (
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
 	"github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
 	v1 "k8s.io/api/core/v1"
@@ -40,6 +41,8 @@ func KindOf(obj any) string {
 		switch obj := obj.(type) {
 		case *v1.Secret:
 			return "Secret"
+		case *v1.ConfigMap:
+			return "ConfigMap"
 		case *v1.Service:
 			return "Service"
 		case *v1.Endpoints:
@@ -72,6 +75,8 @@ func KindOf(obj any) string {
 			return "ContourDeployment"
 		case *v1.Namespace:
 			return "Namespace"
+		case *certmanagerv1.Certificate:
+			return "Certificate"
 		case *unstructured.Unstructured:
 			return obj.GetKind()
 		default:
@@ -90,7 +95,7 @@ func VersionOf(obj any) string {
 	gvk, _, err := scheme.Scheme.ObjectKinds(obj.(runtime.Object))
 	if err != nil {
 		switch obj := obj.(type) {
-		case *v1.Secret, *v1.Service, *v1.Endpoints:
+		case *v1.Secret, *v1.ConfigMap, *v1.Service, *v1.Endpoints:
 			return v1.SchemeGroupVersion.String()
 		case *networking_v1.Ingress:
 			return networking_v1.SchemeGroupVersion.String()
@@ -98,6 +103,8 @@ func VersionOf(obj any) string {
 			return contour_api_v1.GroupVersion.String()
 		case *v1alpha1.ExtensionService:
 			return v1alpha1.GroupVersion.String()
+		case *certmanagerv1.Certificate:
+			return certmanagerv1.SchemeGroupVersion.String()
 		case *unstructured.Unstructured:
 			return obj.GetAPIVersion()
 		default:
@@ -16,6 +16,7 @@ package k8s
 import (
 	"testing"
 
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
 	"github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
 	"github.com/stretchr/testify/assert"
@@ -32,6 +33,7 @@ func TestKindOf(t *testing.T) {
 		Obj  any
 	}{
 		{"Secret", &v1.Secret{}},
+		{"ConfigMap", &v1.ConfigMap{}},
 		{"Service", &v1.Service{}},
 		{"Namespace", &v1.Namespace{}},
 		{"Endpoints", &v1.Endpoints{}},
@@ -40,6 +42,7 @@ func TestKindOf(t *testing.T) {
 		{"HTTPProxy", &contour_api_v1.HTTPProxy{}},
 		{"TLSCertificateDelegation", &contour_api_v1.TLSCertificateDelegation{}},
 		{"ExtensionService", &v1alpha1.ExtensionService{}},
+		{"Certificate", &certmanagerv1.Certificate{}},
 		{"ContourConfiguration", &v1alpha1.ContourConfiguration{}},
 		{"ContourDeployment", &v1alpha1.ContourDeployment{}},
 		{"GRPCRoute", &gatewayapi_v1alpha2.GRPCRoute{}},
@@ -18,11 +18,22 @@ package k8s
 
 // +kubebuilder:rbac:groups="projectcontour.io",resources=httpproxies;tlscertificatedelegations;extensionservices;contourconfigurations,verbs=get;list;watch
 // +kubebuilder:rbac:groups="projectcontour.io",resources=httpproxies/status;extensionservices/status;contourconfigurations/status,verbs=create;get;update
+// +kubebuilder:rbac:groups="projectcontour.io",resources=namespacereports,verbs=get;list;watch;create
+// +kubebuilder:rbac:groups="projectcontour.io",resources=namespacereports/status,verbs=create;get;update
 
 // +kubebuilder:rbac:groups="gateway.networking.k8s.io",resources=gatewayclasses;gateways;httproutes;tlsroutes;grpcroutes;tcproutes;referencegrants,verbs=get;list;watch
 // +kubebuilder:rbac:groups="gateway.networking.k8s.io",resources=gatewayclasses/status;gateways/status;httproutes/status;tlsroutes/status;grpcroutes/status;tcproutes/status,verbs=update
 
-// +kubebuilder:rbac:groups="",resources=secrets;endpoints;services;namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets;configmaps;endpoints;services;namespaces;pods,verbs=get;list;watch
+
+// +kubebuilder:rbac:groups="cert-manager.io",resources=certificates,verbs=get;list;watch
+
+// Add RBAC policy to record events on the objects using deprecated features.
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// Add RBAC policy to assign the requests of Contour to an API Priority and Fairness priority level.
+// +kubebuilder:rbac:groups="flowcontrol.apiserver.k8s.io",resources=prioritylevelconfigurations,verbs=get
+// +kubebuilder:rbac:groups="flowcontrol.apiserver.k8s.io",resources=flowschemas,verbs=get;create;update
 
 // Add RBAC policy to support leader election.
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;get;update,namespace=projectcontour
@@ -14,6 +14,7 @@
 package k8s
 
 import (
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
 	contour_api_v1alpha1 "github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -33,6 +34,7 @@ func NewContourScheme() (*runtime.Scheme, error) {
 		scheme.AddToScheme,
 		gatewayapi_v1alpha2.AddToScheme,
 		gatewayapi_v1beta1.AddToScheme,
+		certmanagerv1.AddToScheme,
 	}
 
 	if err := b.AddToScheme(s); err != nil {
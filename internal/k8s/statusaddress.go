@@ -108,7 +108,7 @@ func (s *StatusAddressUpdater) OnAdd(obj any, isInInitialList bool) {
 		))
 
 	case *contour_api_v1.HTTPProxy:
-		if !ingressclass.MatchesHTTPProxy(o, s.IngressClassNames) {
+		if !ingressclass.MatchesHTTPProxy(o, s.IngressClassNames, s.defaultIngressClass(o.Namespace)) {
 			logNoMatch(s.Logger, o)
 			return
 		}
@@ -211,6 +211,25 @@ func (s *StatusAddressUpdater) OnAdd(obj any, isInInitialList bool) {
 	}
 }
 
+// defaultIngressClass returns the default ingress class of the
+// HTTPProxies of the namespace, if any.
+func (s *StatusAddressUpdater) defaultIngressClass(namespace string) string {
+	if s.Cache == nil {
+		return ""
+	}
+
+	ns := &v1.Namespace{}
+	if err := s.Cache.Get(context.Background(), client.ObjectKey{Name: namespace}, ns); err != nil {
+		s.Logger.
+			WithField("namespace", namespace).
+			WithError(err).
+			Debug("error getting namespace, ignoring its default ingress class")
+		return ""
+	}
+
+	return annotation.DefaultIngressClass(ns)
+}
+
 func (s *StatusAddressUpdater) OnUpdate(oldObj, newObj any) {
 
 	// We only care about the new object, because we're only updating its status.
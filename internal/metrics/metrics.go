@@ -33,6 +33,12 @@ type Metrics struct {
 	proxyValidGauge     *prometheus.GaugeVec
 	proxyOrphanedGauge  *prometheus.GaugeVec
 
+	proxyOrphanedChildGauge *prometheus.GaugeVec
+
+	delegatedSecretUsageGauge *prometheus.GaugeVec
+
+	deprecatedFeatureUsageGauge *prometheus.GaugeVec
+
 	dagRebuildGauge             prometheus.Gauge
 	dagCacheObjectGauge         *prometheus.GaugeVec
 	dagRebuildTotal             prometheus.Counter
@@ -47,8 +53,15 @@ type Metrics struct {
 	statusUpdateNoop            *prometheus.CounterVec
 	statusUpdateDurationSeconds *prometheus.SummaryVec
 
+	leaderElectionIsLeaderGauge prometheus.Gauge
+	leaderElectionTransitions   prometheus.Counter
+
+	secretOpenFailed *prometheus.CounterVec
+
 	// Keep a local cache of metrics for comparison on updates
-	proxyMetricCache *RouteMetric
+	proxyMetricCache           *RouteMetric
+	delegatedSecretMetricCache map[SecretUsageMeta]int
+	deprecatedFeatureCache     map[DeprecatedFeatureMeta]int
 }
 
 // RouteMetric stores various metrics for HTTPProxy objects
@@ -58,11 +71,27 @@ type RouteMetric struct {
 	Invalid  map[Meta]int
 	Orphaned map[Meta]int
 	Root     map[Meta]int
+
+	// OrphanedChildren holds the namespace and name
+	// of each orphaned HTTPProxy.
+	OrphanedChildren map[Meta]int
 }
 
-// Meta holds the vhost and namespace of a metric object
+// Meta holds the vhost, namespace and name of a metric object
 type Meta struct {
-	VHost, Namespace string
+	VHost, Namespace, Name string
+}
+
+// DeprecatedFeatureMeta holds the name of a deprecated feature
+// and the namespace of the objects using it.
+type DeprecatedFeatureMeta struct {
+	Feature, Namespace string
+}
+
+// SecretUsageMeta holds the namespace and name of a delegated
+// Secret, and the namespace of the HTTPProxies using it.
+type SecretUsageMeta struct {
+	SecretNamespace, SecretName, Namespace string
 }
 
 const (
@@ -74,6 +103,12 @@ const (
 	HTTPProxyValidGauge     = "contour_httpproxy_valid"
 	HTTPProxyOrphanedGauge  = "contour_httpproxy_orphaned"
 
+	HTTPProxyOrphanedChildGauge = "contour_httpproxy_orphaned_child"
+
+	DelegatedSecretUsageGauge = "contour_delegated_secret_usage"
+
+	DeprecatedFeatureUsageGauge = "contour_deprecated_feature_usage"
+
 	DAGCacheObjectGauge         = "contour_dag_cache_object"
 	DAGRebuildGauge             = "contour_dagrebuild_timestamp"
 	DAGRebuildTotal             = "contour_dagrebuild_total"
@@ -87,6 +122,11 @@ const (
 	statusUpdateConflict        = "contour_status_update_conflict_total"
 	statusUpdateNoop            = "contour_status_update_noop_total"
 	statusUpdateDurationSeconds = "contour_status_update_duration_seconds"
+
+	LeaderElectionIsLeaderGauge = "contour_leader_election_is_leader"
+	LeaderElectionTransitions   = "contour_leader_election_transitions_total"
+
+	SecretOpenFailed = "contour_secret_open_failed_total"
 )
 
 // NewMetrics creates a new set of metrics and registers them with
@@ -140,6 +180,27 @@ func NewMetrics(registry *prometheus.Registry) *Metrics {
 			},
 			[]string{"namespace"},
 		),
+		proxyOrphanedChildGauge: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: HTTPProxyOrphanedChildGauge,
+				Help: "Orphaned HTTPProxies which have no root delegating to them, by name. The value is always 1.",
+			},
+			[]string{"namespace", "name"},
+		),
+		delegatedSecretUsageGauge: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: DelegatedSecretUsageGauge,
+				Help: "Total number of HTTPProxies using a TLS Secret that is delegated to them from another namespace, by Secret and HTTPProxy namespace.",
+			},
+			[]string{"secret_namespace", "secret_name", "namespace"},
+		),
+		deprecatedFeatureUsageGauge: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: DeprecatedFeatureUsageGauge,
+				Help: "Total number of objects using a deprecated feature, by feature and namespace. Deprecated configuration file parameters have no namespace.",
+			},
+			[]string{"feature", "namespace"},
+		),
 		dagRebuildGauge: prometheus.NewGauge(
 			prometheus.GaugeOpts{
 				Name: DAGRebuildGauge,
@@ -239,6 +300,25 @@ func NewMetrics(registry *prometheus.Registry) *Metrics {
 			},
 			[]string{"kind", "error"},
 		),
+		leaderElectionIsLeaderGauge: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: LeaderElectionIsLeaderGauge,
+				Help: "Whether this Contour instance is the leader, 1 if it is and 0 otherwise.",
+			},
+		),
+		leaderElectionTransitions: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: LeaderElectionTransitions,
+				Help: "Total number of times this Contour instance has become the leader since startup.",
+			},
+		),
+		secretOpenFailed: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: SecretOpenFailed,
+				Help: "Total number of times the sealed private key of a Secret couldn't be opened, so the Secret wasn't sent to Envoy.",
+			},
+			[]string{"namespace", "name"},
+		),
 	}
 	m.buildInfoGauge.WithLabelValues(build.Branch, build.Sha, build.Version).Set(1)
 	m.register(registry)
@@ -254,6 +334,9 @@ func (m *Metrics) register(registry *prometheus.Registry) {
 		m.proxyInvalidGauge,
 		m.proxyValidGauge,
 		m.proxyOrphanedGauge,
+		m.proxyOrphanedChildGauge,
+		m.delegatedSecretUsageGauge,
+		m.deprecatedFeatureUsageGauge,
 		m.dagRebuildGauge,
 		m.dagRebuildTotal,
 		m.dagCacheObjectGauge,
@@ -266,6 +349,9 @@ func (m *Metrics) register(registry *prometheus.Registry) {
 		m.statusUpdateConflict,
 		m.statusUpdateNoop,
 		m.statusUpdateDurationSeconds,
+		m.leaderElectionIsLeaderGauge,
+		m.leaderElectionTransitions,
+		m.secretOpenFailed,
 	)
 }
 
@@ -284,10 +370,14 @@ func (m *Metrics) Zero() {
 		Invalid:  map[Meta]int{meta: 0},
 		Orphaned: map[Meta]int{meta: 0},
 		Root:     map[Meta]int{meta: 0},
+
+		OrphanedChildren: map[Meta]int{meta: 0},
 	}
 
 	m.SetDAGLastRebuilt(time.Now())
 	m.SetHTTPProxyMetric(zeroes)
+	m.SetDelegatedSecretMetric(map[SecretUsageMeta]int{{}: 0})
+	m.SetDeprecatedFeatureMetric(map[DeprecatedFeatureMeta]int{{}: 0})
 	m.EventHandlerOperations.WithLabelValues("add", "Secret").Inc()
 	m.SetDAGCacheObjectMetric("kind", 1)
 	m.SetStatusUpdateTotal("kind")
@@ -296,6 +386,8 @@ func (m *Metrics) Zero() {
 	m.SetStatusUpdateFailed("kind")
 	m.SetStatusUpdateConflict("kind")
 	m.SetStatusUpdateDuration(time.Nanosecond, "kind", false)
+	m.OnElectedLeader()
+	m.SetSecretOpenFailed("", "")
 
 	m.CacheHandlerOnUpdateSummary.Observe(0)
 	m.DAGRebuildSeconds.Observe(0)
@@ -319,6 +411,22 @@ func (m *Metrics) SetDAGCacheObjectMetric(kind string, count int) {
 	m.dagCacheObjectGauge.WithLabelValues(kind).Set(float64(count))
 }
 
+// OnElectedLeader records that this Contour instance has become the leader.
+// If leader election is disabled, it is called once the manager starts.
+func (m *Metrics) OnElectedLeader() {
+	m.leaderElectionIsLeaderGauge.Set(1)
+	m.leaderElectionTransitions.Inc()
+}
+
+// SetSecretOpenFailed records that the sealed private key of
+// the Secret with the given namespace and name couldn't be opened.
+func (m *Metrics) SetSecretOpenFailed(namespace, name string) {
+	if m == nil {
+		return
+	}
+	m.secretOpenFailed.WithLabelValues(namespace, name).Inc()
+}
+
 // SetHTTPProxyMetric sets metric values for a set of HTTPProxies
 func (m *Metrics) SetHTTPProxyMetric(metrics RouteMetric) {
 	// Process metrics
@@ -342,6 +450,10 @@ func (m *Metrics) SetHTTPProxyMetric(metrics RouteMetric) {
 		m.proxyRootTotalGauge.WithLabelValues(meta.Namespace).Set(float64(value))
 		delete(m.proxyMetricCache.Root, meta)
 	}
+	for meta, value := range metrics.OrphanedChildren {
+		m.proxyOrphanedChildGauge.WithLabelValues(meta.Namespace, meta.Name).Set(float64(value))
+		delete(m.proxyMetricCache.OrphanedChildren, meta)
+	}
 
 	// All metrics processed, now remove what's left as they are not needed
 	for meta := range m.proxyMetricCache.Total {
@@ -359,13 +471,58 @@ func (m *Metrics) SetHTTPProxyMetric(metrics RouteMetric) {
 	for meta := range m.proxyMetricCache.Root {
 		m.proxyRootTotalGauge.DeleteLabelValues(meta.Namespace)
 	}
+	for meta := range m.proxyMetricCache.OrphanedChildren {
+		m.proxyOrphanedChildGauge.DeleteLabelValues(meta.Namespace, meta.Name)
+	}
 
 	m.proxyMetricCache = &RouteMetric{
-		Total:    metrics.Total,
-		Invalid:  metrics.Invalid,
-		Valid:    metrics.Valid,
-		Orphaned: metrics.Orphaned,
-		Root:     metrics.Root,
+		Total:            metrics.Total,
+		Invalid:          metrics.Invalid,
+		Valid:            metrics.Valid,
+		Orphaned:         metrics.Orphaned,
+		Root:             metrics.Root,
+		OrphanedChildren: metrics.OrphanedChildren,
+	}
+}
+
+// SetDelegatedSecretMetric sets the number of HTTPProxies, by namespace,
+// using each TLS Secret that is delegated to them from another namespace.
+func (m *Metrics) SetDelegatedSecretMetric(usage map[SecretUsageMeta]int) {
+	for meta, value := range usage {
+		m.delegatedSecretUsageGauge.WithLabelValues(meta.SecretNamespace, meta.SecretName, meta.Namespace).Set(float64(value))
+		delete(m.delegatedSecretMetricCache, meta)
+	}
+
+	// Remove the Secrets that are no longer used.
+	for meta := range m.delegatedSecretMetricCache {
+		m.delegatedSecretUsageGauge.DeleteLabelValues(meta.SecretNamespace, meta.SecretName, meta.Namespace)
+	}
+
+	m.delegatedSecretMetricCache = usage
+}
+
+// SetDeprecatedFeatureMetric sets the number of objects, by namespace,
+// using each deprecated feature of the resources processed by Contour.
+func (m *Metrics) SetDeprecatedFeatureMetric(usage map[DeprecatedFeatureMeta]int) {
+	for meta, value := range usage {
+		m.deprecatedFeatureUsageGauge.WithLabelValues(meta.Feature, meta.Namespace).Set(float64(value))
+		delete(m.deprecatedFeatureCache, meta)
+	}
+
+	// Remove the features that are no longer used.
+	for meta := range m.deprecatedFeatureCache {
+		m.deprecatedFeatureUsageGauge.DeleteLabelValues(meta.Feature, meta.Namespace)
+	}
+
+	m.deprecatedFeatureCache = usage
+}
+
+// SetDeprecatedParameterMetric records the use of each deprecated
+// configuration file parameter. Configuration file parameters are
+// set once, at startup.
+func (m *Metrics) SetDeprecatedParameterMetric(parameters []string) {
+	for _, parameter := range parameters {
+		m.deprecatedFeatureUsageGauge.WithLabelValues("config-file:"+parameter, "").Set(1)
 	}
 }
 
@@ -748,3 +748,185 @@ func TestSetDAGCacheObjectMetric(t *testing.T) {
 		})
 	}
 }
+
+func TestOrphanedChildMetric(t *testing.T) {
+	r := prometheus.NewRegistry()
+	m := NewMetrics(r)
+
+	gatherOrphanedChildren := func() []*io_prometheus_client.Metric {
+		t.Helper()
+
+		gathering, err := r.Gather()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := []*io_prometheus_client.Metric{}
+		for _, mf := range gathering {
+			if mf.GetName() == HTTPProxyOrphanedChildGauge {
+				got = mf.Metric
+			}
+		}
+		return got
+	}
+
+	m.SetHTTPProxyMetric(RouteMetric{
+		OrphanedChildren: map[Meta]int{
+			{Namespace: "foons", Name: "child"}: 1,
+		},
+	})
+
+	assert.Equal(t, []*io_prometheus_client.Metric{{
+		Label: []*io_prometheus_client.LabelPair{{
+			Name:  ref.To("name"),
+			Value: ref.To("child"),
+		}, {
+			Name:  ref.To("namespace"),
+			Value: ref.To("foons"),
+		}},
+		Gauge: &io_prometheus_client.Gauge{
+			Value: ref.To(float64(1)),
+		},
+	}}, gatherOrphanedChildren())
+
+	// Once the HTTPProxy is included again, the metric is removed.
+	m.SetHTTPProxyMetric(RouteMetric{})
+
+	assert.Equal(t, []*io_prometheus_client.Metric{}, gatherOrphanedChildren())
+}
+
+func TestDelegatedSecretMetric(t *testing.T) {
+	r := prometheus.NewRegistry()
+	m := NewMetrics(r)
+
+	gatherDelegatedSecrets := func() []*io_prometheus_client.Metric {
+		t.Helper()
+
+		gathering, err := r.Gather()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := []*io_prometheus_client.Metric{}
+		for _, mf := range gathering {
+			if mf.GetName() == DelegatedSecretUsageGauge {
+				got = mf.Metric
+			}
+		}
+		return got
+	}
+
+	m.SetDelegatedSecretMetric(map[SecretUsageMeta]int{
+		{SecretNamespace: "certs", SecretName: "wildcard", Namespace: "foons"}: 2,
+	})
+
+	assert.Equal(t, []*io_prometheus_client.Metric{{
+		Label: []*io_prometheus_client.LabelPair{{
+			Name:  ref.To("namespace"),
+			Value: ref.To("foons"),
+		}, {
+			Name:  ref.To("secret_name"),
+			Value: ref.To("wildcard"),
+		}, {
+			Name:  ref.To("secret_namespace"),
+			Value: ref.To("certs"),
+		}},
+		Gauge: &io_prometheus_client.Gauge{
+			Value: ref.To(float64(2)),
+		},
+	}}, gatherDelegatedSecrets())
+
+	// Once no HTTPProxy uses the Secret, the metric is removed.
+	m.SetDelegatedSecretMetric(nil)
+
+	assert.Equal(t, []*io_prometheus_client.Metric{}, gatherDelegatedSecrets())
+}
+
+func TestDeprecatedFeatureMetric(t *testing.T) {
+	r := prometheus.NewRegistry()
+	m := NewMetrics(r)
+
+	gatherDeprecatedFeatures := func() []*io_prometheus_client.Metric {
+		t.Helper()
+
+		gathering, err := r.Gather()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := []*io_prometheus_client.Metric{}
+		for _, mf := range gathering {
+			if mf.GetName() == DeprecatedFeatureUsageGauge {
+				got = mf.Metric
+			}
+		}
+		return got
+	}
+
+	metric := func(feature, namespace string, value float64) *io_prometheus_client.Metric {
+		return &io_prometheus_client.Metric{
+			Label: []*io_prometheus_client.LabelPair{{
+				Name:  ref.To("feature"),
+				Value: ref.To(feature),
+			}, {
+				Name:  ref.To("namespace"),
+				Value: ref.To(namespace),
+			}},
+			Gauge: &io_prometheus_client.Gauge{
+				Value: ref.To(value),
+			},
+		}
+	}
+
+	m.SetDeprecatedParameterMetric([]string{"request-timeout"})
+	m.SetDeprecatedFeatureMetric(map[DeprecatedFeatureMeta]int{
+		{Feature: "httpproxy:spec.tcpproxy.includes", Namespace: "foons"}: 2,
+	})
+
+	assert.Equal(t, []*io_prometheus_client.Metric{
+		metric("config-file:request-timeout", "", 1),
+		metric("httpproxy:spec.tcpproxy.includes", "foons", 2),
+	}, gatherDeprecatedFeatures())
+
+	// Once no object uses the feature, the metric is removed,
+	// while the configuration file parameters are kept.
+	m.SetDeprecatedFeatureMetric(nil)
+
+	assert.Equal(t, []*io_prometheus_client.Metric{
+		metric("config-file:request-timeout", "", 1),
+	}, gatherDeprecatedFeatures())
+}
+
+func TestLeaderElectionMetrics(t *testing.T) {
+	r := prometheus.NewRegistry()
+	m := NewMetrics(r)
+
+	gather := func(name string) []*io_prometheus_client.Metric {
+		t.Helper()
+
+		gathering, err := r.Gather()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, mf := range gathering {
+			if mf.GetName() == name {
+				return mf.Metric
+			}
+		}
+		return nil
+	}
+
+	assert.Equal(t, []*io_prometheus_client.Metric{{
+		Label: []*io_prometheus_client.LabelPair{},
+		Gauge: &io_prometheus_client.Gauge{Value: ref.To(float64(0))},
+	}}, gather(LeaderElectionIsLeaderGauge))
+
+	m.OnElectedLeader()
+
+	assert.Equal(t, []*io_prometheus_client.Metric{{
+		Label: []*io_prometheus_client.LabelPair{},
+		Gauge: &io_prometheus_client.Gauge{Value: ref.To(float64(1))},
+	}}, gather(LeaderElectionIsLeaderGauge))
+	assert.Equal(t, float64(1), gather(LeaderElectionTransitions)[0].GetCounter().GetValue())
+}
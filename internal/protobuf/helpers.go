@@ -42,6 +42,14 @@ func UInt32OrNil(val uint32) *wrapperspb.UInt32Value {
 	}
 }
 
+// UInt32PtrOrNil returns a wrapped UInt32Value. If val is nil, nil is returned
+func UInt32PtrOrNil(val *uint32) *wrapperspb.UInt32Value {
+	if val == nil {
+		return nil
+	}
+	return wrapperspb.UInt32(*val)
+}
+
 // AsMessages casts the given slice of values (that implement the proto.Message
 // interface) to a slice of proto.Message. If the length of the slice is 0, it
 // returns nil.
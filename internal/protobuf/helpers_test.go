@@ -25,6 +25,12 @@ func TestU32Nil(t *testing.T) {
 	assert.Equal(t, wrapperspb.UInt32(1), UInt32OrNil(1))
 }
 
+func TestU32PtrNil(t *testing.T) {
+	zero := uint32(0)
+	assert.Equal(t, (*wrapperspb.UInt32Value)(nil), UInt32PtrOrNil(nil))
+	assert.Equal(t, wrapperspb.UInt32(0), UInt32PtrOrNil(&zero))
+}
+
 func TestU32Default(t *testing.T) {
 	assert.Equal(t, wrapperspb.UInt32(99), UInt32OrDefault(0, 99))
 	assert.Equal(t, wrapperspb.UInt32(1), UInt32OrDefault(1, 99))
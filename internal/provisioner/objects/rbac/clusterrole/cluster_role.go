@@ -17,6 +17,7 @@ import (
 	"context"
 	"fmt"
 
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	"github.com/projectcontour/contour/internal/provisioner/equality"
 	"github.com/projectcontour/contour/internal/provisioner/labels"
 	"github.com/projectcontour/contour/internal/provisioner/model"
@@ -50,9 +51,11 @@ func EnsureClusterRole(ctx context.Context, cli client.Client, name string, cont
 // the provided name and contour namespace/name for the owning contour labels.
 func desiredClusterRole(name string, contour *model.Contour) *rbacv1.ClusterRole {
 	var (
-		createGetUpdate = []string{"create", "get", "update"}
-		getListWatch    = []string{"get", "list", "watch"}
-		update          = []string{"update"}
+		createGetUpdate    = []string{"create", "get", "update"}
+		createPatch        = []string{"create", "patch"}
+		getListWatch       = []string{"get", "list", "watch"}
+		getListWatchCreate = []string{"get", "list", "watch", "create"}
+		update             = []string{"update"}
 	)
 
 	policyRuleFor := func(apiGroup string, verbs []string, resources ...string) rbacv1.PolicyRule {
@@ -73,7 +76,10 @@ func desiredClusterRole(name string, contour *model.Contour) *rbacv1.ClusterRole
 		},
 		Rules: []rbacv1.PolicyRule{
 			// Core Contour-watched resources.
-			policyRuleFor(corev1.GroupName, getListWatch, "secrets", "endpoints", "services", "namespaces"),
+			policyRuleFor(corev1.GroupName, getListWatch, "secrets", "configmaps", "endpoints", "services", "namespaces", "pods"),
+
+			// Events recorded on the objects using deprecated features.
+			policyRuleFor(corev1.GroupName, createPatch, "events"),
 
 			// Gateway API resources.
 			// Note, ReferenceGrant does not currently have a .status field so it's omitted from the status rule.
@@ -87,6 +93,11 @@ func desiredClusterRole(name string, contour *model.Contour) *rbacv1.ClusterRole
 			// Contour CRDs.
 			policyRuleFor(contourV1GroupName, getListWatch, "httpproxies", "tlscertificatedelegations", "extensionservices", "contourconfigurations"),
 			policyRuleFor(contourV1GroupName, createGetUpdate, "httpproxies/status", "extensionservices/status", "contourconfigurations/status"),
+			policyRuleFor(contourV1GroupName, getListWatchCreate, "namespacereports"),
+			policyRuleFor(contourV1GroupName, createGetUpdate, "namespacereports/status"),
+
+			// cert-manager resources.
+			policyRuleFor(certmanagerv1.SchemeGroupVersion.Group, getListWatch, "certificates"),
 		},
 	}
 }
@@ -0,0 +1,464 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package proxymetrics publishes the request rate, error rate and
+// latency of the upstream services of each HTTPProxy, translated
+// from the cluster stats of the Envoys.
+package proxymetrics
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/projectcontour/contour/internal/dag"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	requestsStat    = "envoy_cluster_upstream_rq_total"
+	responsesStat   = "envoy_cluster_upstream_rq_xx"
+	requestTimeStat = "envoy_cluster_upstream_rq_time"
+
+	clusterNameLabel       = "envoy_cluster_name"
+	responseCodeClassLabel = "envoy_response_code_class"
+)
+
+// Config configures an Aggregator.
+type Config struct {
+	// Targets returns the URLs of the Prometheus stats
+	// of the Envoys to scrape.
+	Targets func(ctx context.Context) ([]string, error)
+
+	// StatName returns the name that Envoy uses
+	// for the stats of a cluster.
+	StatName func(*dag.Cluster) string
+
+	// Interval is the interval between scrapes.
+	Interval time.Duration
+
+	// BearerTokenFile, if not empty, is the file holding the
+	// bearer token sent with each scrape. It is read on every
+	// scrape, so that the token can be rotated.
+	BearerTokenFile string
+}
+
+// Aggregator is a dag.Observer that periodically scrapes the
+// cluster stats of the Envoys, and publishes the requests, errors
+// and request durations of the upstream clusters of each HTTPProxy
+// as Prometheus metrics labeled by the namespace and name of the
+// HTTPProxy.
+//
+// The counters of each Envoy are published as the increase since
+// the Envoy was first scraped, so that the published counters
+// only decrease when Contour restarts.
+type Aggregator struct {
+	log    logrus.FieldLogger
+	config Config
+	client *http.Client
+
+	requests *prometheus.Desc
+	errors   *prometheus.Desc
+	duration *prometheus.Desc
+
+	mu sync.Mutex
+
+	// proxies holds the HTTPProxies whose routes send requests
+	// to each cluster, keyed by the stat name of the cluster.
+	proxies map[string][]types.NamespacedName
+
+	// envoys holds the last scraped stats of each
+	// Envoy, keyed by target and cluster stat name.
+	envoys map[string]map[string]*clusterStats
+
+	// stats holds the published stats of each HTTPProxy.
+	stats map[types.NamespacedName]*clusterStats
+}
+
+// NewAggregator returns an Aggregator configured by config.
+func NewAggregator(log logrus.FieldLogger, config Config) *Aggregator {
+	labels := []string{"namespace", "name"}
+	return &Aggregator{
+		log:    log,
+		config: config,
+		client: &http.Client{Timeout: config.Interval},
+		requests: prometheus.NewDesc(
+			"contour_httpproxy_upstream_requests_total",
+			"Total number of requests that the routes of the HTTPProxy sent to its upstream services.",
+			labels, nil,
+		),
+		errors: prometheus.NewDesc(
+			"contour_httpproxy_upstream_request_errors_total",
+			"Total number of requests that the routes of the HTTPProxy sent to its upstream services that received a 5xx response.",
+			labels, nil,
+		),
+		duration: prometheus.NewDesc(
+			"contour_httpproxy_upstream_request_duration_seconds",
+			"Histogram of the durations of the requests that the routes of the HTTPProxy sent to its upstream services.",
+			labels, nil,
+		),
+		proxies: map[string][]types.NamespacedName{},
+		envoys:  map[string]map[string]*clusterStats{},
+		stats:   map[types.NamespacedName]*clusterStats{},
+	}
+}
+
+// OnChange records the clusters that the routes
+// of each HTTPProxy of the DAG send requests to.
+func (a *Aggregator) OnChange(d *dag.DAG) {
+	proxies := map[string][]types.NamespacedName{}
+	for proxy, clusters := range d.HTTPProxyClusters {
+		for _, c := range clusters {
+			name := a.config.StatName(c)
+			if !containsProxy(proxies[name], proxy) {
+				proxies[name] = append(proxies[name], proxy)
+			}
+		}
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.proxies = proxies
+
+	// Stop publishing the stats of the HTTPProxies that are gone.
+	for proxy := range a.stats {
+		if _, ok := d.HTTPProxyClusters[proxy]; !ok {
+			delete(a.stats, proxy)
+		}
+	}
+}
+
+func containsProxy(proxies []types.NamespacedName, proxy types.NamespacedName) bool {
+	for _, p := range proxies {
+		if p == proxy {
+			return true
+		}
+	}
+	return false
+}
+
+// NeedLeaderElection is true so that only one Contour
+// publishes the stats of the Envoys.
+func (a *Aggregator) NeedLeaderElection() bool {
+	return true
+}
+
+// Start scrapes the Envoys every interval until the context is done.
+func (a *Aggregator) Start(ctx context.Context) error {
+	a.log.Info("started HTTPProxy metrics aggregator")
+	defer a.log.Info("stopped HTTPProxy metrics aggregator")
+
+	ticker := time.NewTicker(a.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			a.scrape(ctx)
+		}
+	}
+}
+
+// scrape scrapes the stats of each Envoy and adds their
+// increase since the last scrape to the stats of the
+// HTTPProxies that use each cluster.
+func (a *Aggregator) scrape(ctx context.Context) {
+	targets, err := a.config.Targets(ctx)
+	if err != nil {
+		a.log.WithError(err).Error("failed to find the Envoys to scrape")
+		return
+	}
+
+	scraped := map[string]map[string]*clusterStats{}
+	for _, target := range targets {
+		stats, err := a.scrapeTarget(ctx, target)
+		if err != nil {
+			a.log.WithError(err).WithField("target", target).Warn("failed to scrape Envoy stats")
+			continue
+		}
+		scraped[target] = stats
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.update(targets, scraped)
+}
+
+func (a *Aggregator) scrapeTarget(ctx context.Context, target string) (map[string]*clusterStats, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	if a.config.BearerTokenFile != "" {
+		token, err := os.ReadFile(a.config.BearerTokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bearer token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET for %q returned HTTP status %s", target, resp.Status)
+	}
+
+	return parseClusterStats(resp.Body)
+}
+
+// update adds the increase of the scraped stats of each Envoy
+// since its last scrape to the stats of the HTTPProxies. The
+// stats of Envoys that are no longer targets are forgotten,
+// while those of Envoys that failed to be scraped are kept.
+func (a *Aggregator) update(targets []string, scraped map[string]map[string]*clusterStats) {
+	current := map[string]bool{}
+	for _, target := range targets {
+		current[target] = true
+	}
+	for target := range a.envoys {
+		if !current[target] {
+			delete(a.envoys, target)
+		}
+	}
+
+	for target, stats := range scraped {
+		last, seen := a.envoys[target]
+		a.envoys[target] = stats
+
+		// The first scrape of an Envoy is the baseline
+		// that its later scrapes are compared to.
+		if !seen {
+			continue
+		}
+
+		for name, s := range stats {
+			increase := s.since(last[name])
+			for _, proxy := range a.proxies[name] {
+				if a.stats[proxy] == nil {
+					a.stats[proxy] = &clusterStats{}
+				}
+				if !a.stats[proxy].add(increase) {
+					a.log.WithField("cluster", name).WithField("httpproxy", proxy).
+						Debug("skipping request durations with different histogram buckets")
+				}
+			}
+		}
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (a *Aggregator) Describe(ch chan<- *prometheus.Desc) {
+	ch <- a.requests
+	ch <- a.errors
+	ch <- a.duration
+}
+
+// Collect implements prometheus.Collector.
+func (a *Aggregator) Collect(ch chan<- prometheus.Metric) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for proxy, s := range a.stats {
+		ch <- prometheus.MustNewConstMetric(a.requests, prometheus.CounterValue, float64(s.requests), proxy.Namespace, proxy.Name)
+		ch <- prometheus.MustNewConstMetric(a.errors, prometheus.CounterValue, float64(s.errors), proxy.Namespace, proxy.Name)
+		ch <- prometheus.MustNewConstHistogram(a.duration, s.count, s.sum, s.buckets, proxy.Namespace, proxy.Name)
+	}
+}
+
+// clusterStats holds the request stats of a cluster. The request
+// durations are a cumulative histogram in seconds, keyed by the
+// upper bound of each bucket.
+type clusterStats struct {
+	requests uint64
+	errors   uint64
+
+	buckets map[float64]uint64
+	sum     float64
+	count   uint64
+}
+
+// since returns the increase of s since last, or s if the
+// stats were reset, e.g. because Envoy restarted.
+func (s *clusterStats) since(last *clusterStats) *clusterStats {
+	if last == nil || s.requests < last.requests || s.errors < last.errors || s.count < last.count || !sameBuckets(s.buckets, last.buckets) {
+		return s
+	}
+
+	increase := &clusterStats{
+		requests: s.requests - last.requests,
+		errors:   s.errors - last.errors,
+		buckets:  make(map[float64]uint64, len(s.buckets)),
+		sum:      s.sum - last.sum,
+		count:    s.count - last.count,
+	}
+	for bound, count := range s.buckets {
+		increase.buckets[bound] = count - last.buckets[bound]
+	}
+	return increase
+}
+
+// add adds increase to s. The request durations are only
+// added if s has no histogram buckets yet or the same ones
+// as increase, otherwise add returns false.
+func (s *clusterStats) add(increase *clusterStats) bool {
+	s.requests += increase.requests
+	s.errors += increase.errors
+
+	if len(s.buckets) == 0 && s.count == 0 {
+		s.buckets = make(map[float64]uint64, len(increase.buckets))
+		for bound := range increase.buckets {
+			s.buckets[bound] = 0
+		}
+	}
+	if !sameBuckets(s.buckets, increase.buckets) {
+		return false
+	}
+
+	for bound, count := range increase.buckets {
+		s.buckets[bound] += count
+	}
+	s.sum += increase.sum
+	s.count += increase.count
+	return true
+}
+
+func sameBuckets(a, b map[float64]uint64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for bound := range a {
+		if _, ok := b[bound]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// parseClusterStats parses the cluster stats of Envoy in the
+// Prometheus text format, keyed by the stat name of the cluster.
+func parseClusterStats(r io.Reader) (map[string]*clusterStats, error) {
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(r)
+	if err != nil {
+		return nil, fmt.Errorf("parsing Prometheus text format failed: %v", err)
+	}
+
+	stats := map[string]*clusterStats{}
+	clusterStatsOf := func(m *dto.Metric) *clusterStats {
+		name := labelValue(m, clusterNameLabel)
+		if stats[name] == nil {
+			stats[name] = &clusterStats{buckets: map[float64]uint64{}}
+		}
+		return stats[name]
+	}
+
+	if family, ok := families[requestsStat]; ok {
+		for _, m := range family.Metric {
+			clusterStatsOf(m).requests = uint64(m.GetCounter().GetValue())
+		}
+	}
+
+	if family, ok := families[responsesStat]; ok {
+		for _, m := range family.Metric {
+			if labelValue(m, responseCodeClassLabel) == "5" {
+				clusterStatsOf(m).errors = uint64(m.GetCounter().GetValue())
+			}
+		}
+	}
+
+	// Envoy records the request durations in milliseconds.
+	if family, ok := families[requestTimeStat]; ok {
+		for _, m := range family.Metric {
+			s := clusterStatsOf(m)
+			h := m.GetHistogram()
+			for _, b := range h.GetBucket() {
+				if math.IsInf(b.GetUpperBound(), 1) {
+					continue
+				}
+				s.buckets[b.GetUpperBound()/1000] = b.GetCumulativeCount()
+			}
+			s.sum = h.GetSampleSum() / 1000
+			s.count = h.GetSampleCount()
+		}
+	}
+
+	return stats, nil
+}
+
+func labelValue(m *dto.Metric, name string) string {
+	for _, l := range m.GetLabel() {
+		if l.GetName() == name {
+			return l.GetValue()
+		}
+	}
+	return ""
+}
+
+// EndpointsTargets returns Targets that scrape the Envoy pods that
+// are the endpoints of the Envoy service, on the port that serves
+// the Envoy stats.
+func EndpointsTargets(reader client.Reader, service types.NamespacedName, port int) func(context.Context) ([]string, error) {
+	return func(ctx context.Context) ([]string, error) {
+		endpoints := &corev1.Endpoints{}
+		if err := reader.Get(ctx, service, endpoints); err != nil {
+			return nil, fmt.Errorf("error getting endpoints of Envoy service %s: %v", service, err)
+		}
+
+		query := url.Values{"filter": []string{`^cluster\.`}}.Encode()
+
+		seen := map[string]bool{}
+		var targets []string
+		for _, subset := range endpoints.Subsets {
+			// Draining Envoys are not ready but still
+			// serve requests, so they are scraped too.
+			for _, addresses := range [][]corev1.EndpointAddress{subset.Addresses, subset.NotReadyAddresses} {
+				for _, address := range addresses {
+					if seen[address.IP] {
+						continue
+					}
+					seen[address.IP] = true
+					targets = append(targets, (&url.URL{
+						Scheme:   "http",
+						Host:     net.JoinHostPort(address.IP, strconv.Itoa(port)),
+						Path:     "/stats/prometheus",
+						RawQuery: query,
+					}).String())
+				}
+			}
+		}
+		return targets, nil
+	}
+}
@@ -0,0 +1,244 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxymetrics
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/projectcontour/contour/internal/dag"
+	"github.com/projectcontour/contour/internal/envoy"
+	"github.com/projectcontour/contour/internal/fixture"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// envoyStats returns the stats of the default/kuard/80 cluster
+// in the Prometheus text format that Envoy serves.
+func envoyStats(requests, errors, fast, slow int, sumMillis float64) string {
+	return fmt.Sprintf(`# TYPE envoy_cluster_upstream_rq_total counter
+envoy_cluster_upstream_rq_total{envoy_cluster_name="default_kuard_80"} %[1]d
+# TYPE envoy_cluster_upstream_rq_xx counter
+envoy_cluster_upstream_rq_xx{envoy_response_code_class="2",envoy_cluster_name="default_kuard_80"} %[6]d
+envoy_cluster_upstream_rq_xx{envoy_response_code_class="5",envoy_cluster_name="default_kuard_80"} %[2]d
+# TYPE envoy_cluster_upstream_rq_time histogram
+envoy_cluster_upstream_rq_time_bucket{envoy_cluster_name="default_kuard_80",le="10"} %[3]d
+envoy_cluster_upstream_rq_time_bucket{envoy_cluster_name="default_kuard_80",le="100"} %[4]d
+envoy_cluster_upstream_rq_time_bucket{envoy_cluster_name="default_kuard_80",le="+Inf"} %[1]d
+envoy_cluster_upstream_rq_time_sum{envoy_cluster_name="default_kuard_80"} %[5]g
+envoy_cluster_upstream_rq_time_count{envoy_cluster_name="default_kuard_80"} %[1]d
+`, requests, errors, fast, slow, sumMillis, requests-errors)
+}
+
+func TestParseClusterStats(t *testing.T) {
+	got, err := parseClusterStats(strings.NewReader(envoyStats(10, 2, 5, 9, 350)))
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]*clusterStats{
+		"default_kuard_80": {
+			requests: 10,
+			errors:   2,
+			buckets:  map[float64]uint64{0.01: 5, 0.1: 9},
+			sum:      0.35,
+			count:    10,
+		},
+	}, got)
+
+	_, err = parseClusterStats(strings.NewReader("not { prometheus"))
+	require.Error(t, err)
+}
+
+func TestAggregator(t *testing.T) {
+	var stats string
+	token := "secret"
+	envoy1 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer "+token, r.Header.Get("Authorization"))
+		fmt.Fprint(w, stats)
+	}))
+	defer envoy1.Close()
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte(token+"\n"), 0o600))
+
+	a := NewAggregator(fixture.NewTestLogger(t), Config{
+		Targets: func(context.Context) ([]string, error) {
+			return []string{envoy1.URL}, nil
+		},
+		StatName: func(c *dag.Cluster) string {
+			return envoy.AltStatName(c.Upstream)
+		},
+		Interval:        time.Second,
+		BearerTokenFile: tokenFile,
+	})
+
+	kuard := &dag.Cluster{
+		Upstream: &dag.Service{
+			Weighted: dag.WeightedService{
+				ServiceName:      "kuard",
+				ServiceNamespace: "default",
+				ServicePort:      v1.ServicePort{Port: 80},
+			},
+		},
+	}
+	root := types.NamespacedName{Namespace: "default", Name: "root"}
+	child := types.NamespacedName{Namespace: "teama", Name: "child"}
+	a.OnChange(&dag.DAG{
+		HTTPProxyClusters: map[types.NamespacedName][]*dag.Cluster{
+			root:  {kuard},
+			child: {kuard, kuard},
+		},
+	})
+
+	registry := prometheus.NewRegistry()
+	require.NoError(t, registry.Register(a))
+
+	// The first scrape is the baseline.
+	stats = envoyStats(10, 2, 5, 9, 350)
+	a.scrape(context.Background())
+	assert.Empty(t, gather(t, registry))
+
+	stats = envoyStats(25, 3, 10, 22, 900)
+	a.scrape(context.Background())
+
+	want := &proxyMetrics{
+		requests: 15,
+		errors:   1,
+		buckets:  map[float64]uint64{0.01: 5, 0.1: 13},
+		sum:      0.55,
+		count:    15,
+	}
+	assert.Equal(t, map[types.NamespacedName]*proxyMetrics{root: want, child: want}, gather(t, registry))
+
+	// The token was rotated, and Envoy restarted,
+	// so its stats are reset.
+	token = "rotated"
+	require.NoError(t, os.WriteFile(tokenFile, []byte(token), 0o600))
+	stats = envoyStats(4, 0, 4, 4, 20)
+	a.scrape(context.Background())
+
+	want = &proxyMetrics{
+		requests: 19,
+		errors:   1,
+		buckets:  map[float64]uint64{0.01: 9, 0.1: 17},
+		sum:      0.57,
+		count:    19,
+	}
+	assert.Equal(t, map[types.NamespacedName]*proxyMetrics{root: want, child: want}, gather(t, registry))
+
+	// The child HTTPProxy was deleted.
+	a.OnChange(&dag.DAG{
+		HTTPProxyClusters: map[types.NamespacedName][]*dag.Cluster{
+			root: {kuard},
+		},
+	})
+
+	assert.Equal(t, map[types.NamespacedName]*proxyMetrics{root: want}, gather(t, registry))
+}
+
+func TestEndpointsTargets(t *testing.T) {
+	client := fake.NewClientBuilder().WithObjects(&v1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "projectcontour",
+			Name:      "envoy",
+		},
+		Subsets: []v1.EndpointSubset{{
+			Addresses: []v1.EndpointAddress{
+				{IP: "10.0.0.1"},
+				{IP: "fd00::1"},
+			},
+			NotReadyAddresses: []v1.EndpointAddress{
+				{IP: "10.0.0.2"},
+			},
+		}, {
+			Addresses: []v1.EndpointAddress{
+				{IP: "10.0.0.1"},
+			},
+		}},
+	}).Build()
+
+	targets := EndpointsTargets(client, types.NamespacedName{Namespace: "projectcontour", Name: "envoy"}, 8002)
+	got, err := targets(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"http://10.0.0.1:8002/stats/prometheus?filter=%5Ecluster%5C.",
+		"http://[fd00::1]:8002/stats/prometheus?filter=%5Ecluster%5C.",
+		"http://10.0.0.2:8002/stats/prometheus?filter=%5Ecluster%5C.",
+	}, got)
+
+	targets = EndpointsTargets(client, types.NamespacedName{Namespace: "projectcontour", Name: "missing"}, 8002)
+	_, err = targets(context.Background())
+	require.Error(t, err)
+}
+
+// proxyMetrics are the metrics published for an HTTPProxy.
+type proxyMetrics struct {
+	requests float64
+	errors   float64
+	buckets  map[float64]uint64
+	sum      float64
+	count    uint64
+}
+
+// gather returns the metrics that the registry gathers, keyed by
+// HTTPProxy. The request duration sum is rounded to milliseconds.
+func gather(t *testing.T, registry *prometheus.Registry) map[types.NamespacedName]*proxyMetrics {
+	t.Helper()
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	got := map[types.NamespacedName]*proxyMetrics{}
+	for _, family := range families {
+		for _, m := range family.Metric {
+			proxy := types.NamespacedName{
+				Namespace: labelValue(m, "namespace"),
+				Name:      labelValue(m, "name"),
+			}
+			if got[proxy] == nil {
+				got[proxy] = &proxyMetrics{}
+			}
+
+			switch family.GetName() {
+			case "contour_httpproxy_upstream_requests_total":
+				got[proxy].requests = m.GetCounter().GetValue()
+			case "contour_httpproxy_upstream_request_errors_total":
+				got[proxy].errors = m.GetCounter().GetValue()
+			case "contour_httpproxy_upstream_request_duration_seconds":
+				h := m.GetHistogram()
+				got[proxy].buckets = map[float64]uint64{}
+				for _, b := range h.GetBucket() {
+					got[proxy].buckets[b.GetUpperBound()] = b.GetCumulativeCount()
+				}
+				got[proxy].sum = math.Round(h.GetSampleSum()*1000) / 1000
+				got[proxy].count = h.GetSampleCount()
+			default:
+				t.Errorf("unexpected metric %q", family.GetName())
+			}
+		}
+	}
+	return got
+}
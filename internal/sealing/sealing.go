@@ -0,0 +1,155 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sealing encrypts the private keys that Contour holds in
+// memory, so that they aren't exposed in heap dumps of the process.
+package sealing
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// DataKeySize is the size in bytes of the AES-256 data keys.
+const DataKeySize = 32
+
+// prefix marks sealed data, so that it can't be mistaken for
+// the PEM encoded data it replaces.
+var prefix = []byte("contour-sealed:v1:")
+
+// IsSealed returns true if data was sealed by a Sealer.
+func IsSealed(data []byte) bool {
+	return bytes.HasPrefix(data, prefix)
+}
+
+// Sealer seals and opens data with AES-256-GCM.
+type Sealer struct {
+	aead cipher.AEAD
+}
+
+// NewSealer returns a Sealer that uses dataKey, which must be
+// DataKeySize bytes long. The dataKey slice is zeroed.
+func NewSealer(dataKey []byte) (*Sealer, error) {
+	defer Zero(dataKey)
+
+	if len(dataKey) != DataKeySize {
+		return nil, fmt.Errorf("data key must be %d bytes, got %d", DataKeySize, len(dataKey))
+	}
+
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Sealer{aead: aead}, nil
+}
+
+// Seal returns plaintext encrypted under a random nonce.
+func (s *Sealer) Seal(plaintext []byte) []byte {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		// The system random source never fails on supported platforms.
+		panic(fmt.Sprintf("failed to generate nonce: %v", err))
+	}
+
+	sealed := make([]byte, 0, len(prefix)+len(nonce)+len(plaintext)+s.aead.Overhead())
+	sealed = append(sealed, prefix...)
+	sealed = append(sealed, nonce...)
+	return s.aead.Seal(sealed, nonce, plaintext, nil)
+}
+
+// Open returns the plaintext of data sealed by Seal. The caller
+// should Zero the plaintext once it is no longer needed.
+func (s *Sealer) Open(sealed []byte) ([]byte, error) {
+	if !IsSealed(sealed) {
+		return nil, errors.New("data is not sealed")
+	}
+
+	sealed = sealed[len(prefix):]
+	if len(sealed) < s.aead.NonceSize() {
+		return nil, errors.New("sealed data is truncated")
+	}
+
+	nonce, ciphertext := sealed[:s.aead.NonceSize()], sealed[s.aead.NonceSize():]
+	return s.aead.Open(nil, nonce, ciphertext, nil)
+}
+
+// Zero overwrites b with zeros.
+func Zero(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// RandomDataKey returns a new random data key.
+func RandomDataKey() ([]byte, error) {
+	key := make([]byte, DataKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// LoadDataKey returns the data key held by filename. If command is
+// not empty, the file holds a data key sealed by a KMS, and command
+// is run with the content of the file on its standard input to
+// unseal it. The data key is read either as DataKeySize raw bytes
+// or as their base64 encoding.
+func LoadDataKey(filename string, command []string) ([]byte, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(command) > 0 {
+		var stdout, stderr bytes.Buffer
+		/* #nosec */
+		cmd := exec.Command(command[0], command[1:]...)
+		cmd.Stdin = bytes.NewReader(data)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+
+		if err := cmd.Run(); err != nil {
+			return nil, fmt.Errorf("failed to unseal data key with %q: %w: %s", command[0], err, bytes.TrimSpace(stderr.Bytes()))
+		}
+
+		data = stdout.Bytes()
+	}
+	defer Zero(data)
+
+	if len(data) == DataKeySize {
+		return append([]byte(nil), data...), nil
+	}
+
+	encoded := bytes.TrimSpace(data)
+	key := make([]byte, base64.StdEncoding.DecodedLen(len(encoded)))
+	n, err := base64.StdEncoding.Decode(key, encoded)
+	if err != nil || n != DataKeySize {
+		Zero(key)
+		return nil, fmt.Errorf("data key must be %d raw or base64 encoded bytes", DataKeySize)
+	}
+
+	return key[:n], nil
+}
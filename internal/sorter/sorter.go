@@ -258,6 +258,13 @@ func compareRoutesByMethodHeaderQueryParams(lhs, rhs *dag.Route) bool {
 		return lhs.Priority < rhs.Priority
 	}
 
+	// A route with a runtime fraction only matches some of the
+	// requests that match its conditions, so it has to come before
+	// the same route without one, for the rest to fall through.
+	if (lhs.RuntimeFraction == nil) != (rhs.RuntimeFraction == nil) {
+		return lhs.RuntimeFraction != nil
+	}
+
 	// HeaderMatchConditions are equal length: compare item by item.
 	pair := make([]dag.HeaderMatchCondition, 2)
 	for i := 0; i < len(lhsHeaderMatchConditions); i++ {
@@ -284,6 +291,7 @@ func compareRoutesByMethodHeaderQueryParams(lhs, rhs *dag.Route) bool {
 }
 
 // Sorts the given Route slice in place. Routes are ordered first by
+// precedence, highest first, then by
 // type (exact sorts before regex, sorts before prefix) and then
 // longest path match value, then by the length of the HeaderMatch
 // slice (if any). The HeaderMatch slice is also ordered by the matching
@@ -293,6 +301,12 @@ type routeSorter []*dag.Route
 func (s routeSorter) Len() int      { return len(s) }
 func (s routeSorter) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
 func (s routeSorter) Less(i, j int) bool {
+	// Routes with a higher precedence come first,
+	// regardless of their match conditions.
+	if s[i].Precedence != s[j].Precedence {
+		return s[i].Precedence > s[j].Precedence
+	}
+
 	switch a := s[i].PathMatchCondition.(type) {
 	case *dag.PrefixMatchCondition:
 		if b, ok := s[j].PathMatchCondition.(*dag.PrefixMatchCondition); ok {
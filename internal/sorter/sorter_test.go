@@ -313,6 +313,58 @@ func TestSortRoutesPathMatch(t *testing.T) {
 	shuffleAndCheckSort(t, want)
 }
 
+func TestSortRoutesRuntimeFraction(t *testing.T) {
+	want := []*dag.Route{
+		{
+			PathMatchCondition: matchPrefixString("/path/prefix/a"),
+		},
+		{
+			// Comes before the same route without a
+			// runtime fraction, so that the rest of
+			// the requests falls through to it.
+			PathMatchCondition: matchPrefixString("/path/prefix"),
+			RuntimeFraction: &dag.RuntimeFraction{
+				Flag:           "new-prefix",
+				DefaultPercent: 10,
+			},
+		},
+		{
+			PathMatchCondition: matchPrefixString("/path/prefix"),
+		},
+	}
+	shuffleAndCheckSort(t, want)
+}
+
+func TestSortRoutesPrecedence(t *testing.T) {
+	want := []*dag.Route{
+		{
+			// Comes first despite being the least specific
+			// route, since it has the highest precedence.
+			PathMatchCondition: matchPrefixString("/"),
+			Precedence:         10,
+		},
+		{
+			PathMatchCondition: matchRegex("/api/.*"),
+			Precedence:         5,
+		},
+		{
+			PathMatchCondition: matchPrefixString("/api/v1"),
+			Precedence:         5,
+		},
+		{
+			PathMatchCondition: matchExact("/api/v1/users"),
+		},
+		{
+			PathMatchCondition: matchPrefixString("/api"),
+		},
+		{
+			PathMatchCondition: matchExact("/api/v2/users"),
+			Precedence:         -1,
+		},
+	}
+	shuffleAndCheckSort(t, want)
+}
+
 func TestSortRoutesMethod(t *testing.T) {
 	want := []*dag.Route{
 		{
@@ -34,6 +34,9 @@ type ConditionType string
 // ValidCondition is the ConditionType for Valid.
 const ValidCondition ConditionType = "Valid"
 
+// OrphanedCondition is the ConditionType for Orphaned.
+const OrphanedCondition ConditionType = "Orphaned"
+
 // NewCache creates a new Cache for holding status updates.
 func NewCache(gateway types.NamespacedName, gatewayController gatewayapi_v1beta1.GatewayController) Cache {
 	return Cache{
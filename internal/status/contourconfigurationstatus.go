@@ -0,0 +1,78 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package status
+
+import (
+	"fmt"
+	"time"
+
+	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
+	contour_api_v1alpha1 "github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
+	"github.com/projectcontour/contour/internal/k8s"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ContourConfigurationUpdate returns the update of the Valid condition
+// of a ContourConfiguration, given the result of validating the
+// generation of its spec.
+func ContourConfigurationUpdate(name types.NamespacedName, generation int64, validationErr error) k8s.StatusUpdate {
+	cond := contour_api_v1.DetailedCondition{
+		Condition: contour_api_v1.Condition{
+			Type:               string(ValidCondition),
+			Status:             contour_api_v1.ConditionTrue,
+			ObservedGeneration: generation,
+			LastTransitionTime: v1.NewTime(time.Now()),
+			Reason:             "Valid",
+			Message:            "Valid ContourConfiguration",
+		},
+	}
+	if validationErr != nil {
+		cond.AddError("SpecError", "InvalidConfiguration", validationErr.Error())
+	}
+
+	m := k8s.StatusMutatorFunc(func(obj client.Object) client.Object {
+		o, ok := obj.(*contour_api_v1alpha1.ContourConfiguration)
+		if !ok {
+			panic(fmt.Sprintf("unsupported %T object %q in status mutator", obj, name))
+		}
+
+		cfg := o.DeepCopy()
+
+		currCond := cfg.Status.GetConditionFor(cond.Type)
+		if currCond == nil {
+			cfg.Status.Conditions = append(cfg.Status.Conditions, cond)
+			return cfg
+		}
+
+		// Don't update the condition if our observation is stale.
+		if currCond.ObservedGeneration > cond.ObservedGeneration {
+			return cfg
+		}
+
+		if currCond.Status == cond.Status {
+			cond.LastTransitionTime = currCond.LastTransitionTime
+		}
+		cond.DeepCopyInto(currCond)
+
+		return cfg
+	})
+
+	return k8s.StatusUpdate{
+		NamespacedName: name,
+		Resource:       &contour_api_v1alpha1.ContourConfiguration{},
+		Mutator:        m,
+	}
+}
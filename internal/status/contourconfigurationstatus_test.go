@@ -0,0 +1,96 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package status
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
+	contour_api_v1alpha1 "github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestContourConfigurationUpdate(t *testing.T) {
+	name := types.NamespacedName{Namespace: "projectcontour", Name: "contour"}
+	past := metav1.NewTime(time.Now().Add(-time.Hour))
+
+	withCondition := func(cond contour_api_v1.DetailedCondition) *contour_api_v1alpha1.ContourConfiguration {
+		return &contour_api_v1alpha1.ContourConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Namespace: name.Namespace, Name: name.Name},
+			Status: contour_api_v1alpha1.ContourConfigurationStatus{
+				Conditions: []contour_api_v1.DetailedCondition{cond},
+			},
+		}
+	}
+
+	valid := contour_api_v1.DetailedCondition{
+		Condition: contour_api_v1.Condition{
+			Type:               contour_api_v1.ValidConditionType,
+			Status:             contour_api_v1.ConditionTrue,
+			ObservedGeneration: 1,
+			LastTransitionTime: past,
+			Reason:             "Valid",
+			Message:            "Valid ContourConfiguration",
+		},
+	}
+
+	t.Run("valid condition is added", func(t *testing.T) {
+		cfg := &contour_api_v1alpha1.ContourConfiguration{}
+		got := ContourConfigurationUpdate(name, 1, nil).Mutator.Mutate(cfg).(*contour_api_v1alpha1.ContourConfiguration)
+
+		cond := got.Status.GetConditionFor(contour_api_v1.ValidConditionType)
+		require.NotNil(t, cond)
+		assert.Equal(t, contour_api_v1.ConditionTrue, cond.Status)
+		assert.Equal(t, int64(1), cond.ObservedGeneration)
+		assert.Empty(t, cfg.Status.Conditions, "the mutated object must be a copy")
+	})
+
+	t.Run("invalid spec sets the condition false", func(t *testing.T) {
+		got := ContourConfigurationUpdate(name, 2, errors.New("invalid accesslog format")).
+			Mutator.Mutate(withCondition(valid)).(*contour_api_v1alpha1.ContourConfiguration)
+
+		cond := got.Status.GetConditionFor(contour_api_v1.ValidConditionType)
+		require.NotNil(t, cond)
+		assert.Equal(t, contour_api_v1.ConditionFalse, cond.Status)
+		assert.Equal(t, int64(2), cond.ObservedGeneration)
+		assert.NotEqual(t, past, cond.LastTransitionTime)
+
+		subCond, ok := cond.GetError("SpecError")
+		require.True(t, ok)
+		assert.Equal(t, "InvalidConfiguration", subCond.Reason)
+		assert.Equal(t, "invalid accesslog format", subCond.Message)
+	})
+
+	t.Run("transition time is kept if the status doesn't change", func(t *testing.T) {
+		got := ContourConfigurationUpdate(name, 2, nil).
+			Mutator.Mutate(withCondition(valid)).(*contour_api_v1alpha1.ContourConfiguration)
+
+		cond := got.Status.GetConditionFor(contour_api_v1.ValidConditionType)
+		require.NotNil(t, cond)
+		assert.Equal(t, int64(2), cond.ObservedGeneration)
+		assert.Equal(t, past, cond.LastTransitionTime)
+	})
+
+	t.Run("stale observation is ignored", func(t *testing.T) {
+		got := ContourConfigurationUpdate(name, 0, errors.New("stale")).
+			Mutator.Mutate(withCondition(valid)).(*contour_api_v1alpha1.ContourConfiguration)
+
+		assert.Equal(t, []contour_api_v1.DetailedCondition{valid}, got.Status.Conditions)
+	})
+}
@@ -15,8 +15,10 @@ package status
 
 import (
 	"fmt"
+	"sort"
 
 	projectcontour "github.com/projectcontour/contour/apis/projectcontour/v1"
+	"github.com/projectcontour/contour/internal/k8s"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -30,6 +32,10 @@ const (
 	ProxyStatusOrphaned ProxyStatus = "orphaned"
 )
 
+// ConditionHistoryLimit is the maximum number of condition transitions
+// retained in an HTTPProxy's status.
+const ConditionHistoryLimit = 10
+
 // ProxyUpdate holds status updates for a particular HTTPProxy object
 type ProxyUpdate struct {
 	Fullname       types.NamespacedName
@@ -75,13 +81,23 @@ func (pu *ProxyUpdate) Mutate(obj client.Object) client.Object {
 
 	proxy := o.DeepCopy()
 
-	for condType, cond := range pu.Conditions {
+	// Apply the conditions in a stable order, so that the transitions
+	// of several conditions are recorded in the same order each time.
+	condTypes := make([]ConditionType, 0, len(pu.Conditions))
+	for condType := range pu.Conditions {
+		condTypes = append(condTypes, condType)
+	}
+	sort.Slice(condTypes, func(i, j int) bool { return condTypes[i] < condTypes[j] })
+
+	for _, condType := range condTypes {
+		cond := pu.Conditions[condType]
 		cond.ObservedGeneration = pu.Generation
 		cond.LastTransitionTime = pu.TransitionTime
 
 		currCond := proxy.Status.GetConditionFor(string(condType))
 		if currCond == nil {
 			proxy.Status.Conditions = append(proxy.Status.Conditions, *cond)
+			recordTransition(&proxy.Status, cond)
 			continue
 		}
 
@@ -90,10 +106,32 @@ func (pu *ProxyUpdate) Mutate(obj client.Object) client.Object {
 			continue
 		}
 
+		if currCond.Status != cond.Status || currCond.Reason != cond.Reason {
+			recordTransition(&proxy.Status, cond)
+		}
+
 		cond.DeepCopyInto(currCond)
 
 	}
 
+	// The Orphaned condition is only present while the HTTPProxy is orphaned,
+	// so its removal is recorded as a transition to False.
+	if _, ok := pu.Conditions[OrphanedCondition]; !ok {
+		if currCond := proxy.Status.GetConditionFor(string(OrphanedCondition)); currCond != nil && currCond.ObservedGeneration <= pu.Generation {
+			recordTransition(&proxy.Status, &projectcontour.DetailedCondition{
+				Condition: projectcontour.Condition{
+					Type:               string(OrphanedCondition),
+					Status:             projectcontour.ConditionFalse,
+					ObservedGeneration: pu.Generation,
+					LastTransitionTime: pu.TransitionTime,
+					Reason:             "NotOrphaned",
+					Message:            "HTTPProxy is no longer orphaned",
+				},
+			})
+			removeCondition(&proxy.Status, string(OrphanedCondition))
+		}
+	}
+
 	// Set the old status fields using the Valid DetailedCondition's details.
 	// Other conditions are not relevant for these two fields.
 	validCond := proxy.Status.GetConditionFor(projectcontour.ValidConditionType)
@@ -118,3 +156,55 @@ func (pu *ProxyUpdate) Mutate(obj client.Object) client.Object {
 	return proxy
 
 }
+
+// removeCondition removes the condition of the given type from the status.
+func removeCondition(status *projectcontour.HTTPProxyStatus, condType string) {
+	conditions := status.Conditions[:0]
+	for _, cond := range status.Conditions {
+		if cond.Type != condType {
+			conditions = append(conditions, cond)
+		}
+	}
+	status.Conditions = conditions
+}
+
+// recordTransition prepends a ConditionTransition for cond to the
+// status' condition history, discarding the oldest entries once
+// ConditionHistoryLimit is reached.
+func recordTransition(status *projectcontour.HTTPProxyStatus, cond *projectcontour.DetailedCondition) {
+	transition := projectcontour.ConditionTransition{
+		Type:               cond.Type,
+		Status:             cond.Status,
+		Reason:             cond.Reason,
+		Message:            cond.Message,
+		ObservedGeneration: cond.ObservedGeneration,
+		TransitionTime:     cond.LastTransitionTime,
+	}
+
+	history := append([]projectcontour.ConditionTransition{transition}, status.ConditionHistory...)
+	if len(history) > ConditionHistoryLimit {
+		history = history[:ConditionHistoryLimit]
+	}
+	status.ConditionHistory = history
+}
+
+// ProgrammedGenerationMutator returns a StatusMutator that advances
+// the programmedGeneration of an HTTPProxy to the supplied generation.
+func ProgrammedGenerationMutator(generation int64) k8s.StatusMutator {
+	return k8s.StatusMutatorFunc(func(obj client.Object) client.Object {
+		o, ok := obj.(*projectcontour.HTTPProxy)
+		if !ok {
+			panic(fmt.Sprintf("Unsupported %T object %s/%s in status mutator",
+				obj, obj.GetNamespace(), obj.GetName(),
+			))
+		}
+
+		if o.Status.ProgrammedGeneration >= generation {
+			return o
+		}
+
+		proxy := o.DeepCopy()
+		proxy.Status.ProgrammedGeneration = generation
+		return proxy
+	})
+}
@@ -312,3 +312,162 @@ func TestStatusMutator(t *testing.T) {
 
 	run("Test updating existing Valid Condition", updateExistingValidCond)
 }
+
+func TestStatusMutatorConditionHistory(t *testing.T) {
+	validUpdate := func(generation int64, transitionTime v1.Time) ProxyUpdate {
+		return ProxyUpdate{
+			Fullname:       k8s.NamespacedNameFrom("test/test"),
+			Generation:     generation,
+			TransitionTime: transitionTime,
+			Conditions: map[ConditionType]*contour_api_v1.DetailedCondition{
+				ValidCondition: {
+					Condition: contour_api_v1.Condition{
+						Type:    string(ValidCondition),
+						Status:  contour_api_v1.ConditionTrue,
+						Reason:  "Valid",
+						Message: "Valid HTTPProxy",
+					},
+				},
+			},
+		}
+	}
+
+	invalidUpdate := func(generation int64, transitionTime v1.Time) ProxyUpdate {
+		return ProxyUpdate{
+			Fullname:       k8s.NamespacedNameFrom("test/test"),
+			Generation:     generation,
+			TransitionTime: transitionTime,
+			Conditions: map[ConditionType]*contour_api_v1.DetailedCondition{
+				ValidCondition: {
+					Condition: contour_api_v1.Condition{
+						Type:    string(ValidCondition),
+						Status:  contour_api_v1.ConditionFalse,
+						Reason:  "ErrorPresent",
+						Message: "At least one error present, see Errors for details",
+					},
+				},
+			},
+		}
+	}
+
+	mutate := func(proxy *contour_api_v1.HTTPProxy, pu ProxyUpdate) *contour_api_v1.HTTPProxy {
+		return pu.Mutate(proxy).(*contour_api_v1.HTTPProxy)
+	}
+
+	t0 := v1.NewTime(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))
+	t1 := v1.NewTime(t0.Add(time.Minute))
+	t2 := v1.NewTime(t0.Add(2 * time.Minute))
+
+	proxy := &contour_api_v1.HTTPProxy{
+		ObjectMeta: v1.ObjectMeta{
+			Name:       "test",
+			Namespace:  "test",
+			Generation: 1,
+		},
+	}
+
+	// The first observation of a condition is recorded.
+	proxy = mutate(proxy, validUpdate(1, t0))
+	assert.Equal(t, []contour_api_v1.ConditionTransition{{
+		Type:               string(ValidCondition),
+		Status:             contour_api_v1.ConditionTrue,
+		Reason:             "Valid",
+		Message:            "Valid HTTPProxy",
+		ObservedGeneration: 1,
+		TransitionTime:     t0,
+	}}, proxy.Status.ConditionHistory)
+
+	// Observing the same status again is not a transition.
+	proxy = mutate(proxy, validUpdate(1, t1))
+	assert.Len(t, proxy.Status.ConditionHistory, 1)
+
+	// Flapping to invalid is recorded, newest first.
+	proxy = mutate(proxy, invalidUpdate(2, t2))
+	assert.Equal(t, []contour_api_v1.ConditionTransition{{
+		Type:               string(ValidCondition),
+		Status:             contour_api_v1.ConditionFalse,
+		Reason:             "ErrorPresent",
+		Message:            "At least one error present, see Errors for details",
+		ObservedGeneration: 2,
+		TransitionTime:     t2,
+	}, {
+		Type:               string(ValidCondition),
+		Status:             contour_api_v1.ConditionTrue,
+		Reason:             "Valid",
+		Message:            "Valid HTTPProxy",
+		ObservedGeneration: 1,
+		TransitionTime:     t0,
+	}}, proxy.Status.ConditionHistory)
+
+	// Stale observations are not recorded.
+	proxy = mutate(proxy, validUpdate(1, t2))
+	assert.Len(t, proxy.Status.ConditionHistory, 2)
+
+	// The history is bounded.
+	for gen := int64(3); gen < 3+2*ConditionHistoryLimit; gen += 2 {
+		proxy = mutate(proxy, validUpdate(gen, t2))
+		proxy = mutate(proxy, invalidUpdate(gen+1, t2))
+	}
+	assert.Len(t, proxy.Status.ConditionHistory, ConditionHistoryLimit)
+	assert.Equal(t, int64(2+2*ConditionHistoryLimit), proxy.Status.ConditionHistory[0].ObservedGeneration)
+}
+
+func TestStatusMutatorOrphanedCondition(t *testing.T) {
+	proxy := &contour_api_v1.HTTPProxy{
+		ObjectMeta: v1.ObjectMeta{
+			Name:       "test",
+			Namespace:  "test",
+			Generation: 1,
+		},
+	}
+
+	orphaned := ProxyUpdate{
+		Fullname:   k8s.NamespacedNameFrom("test/test"),
+		Generation: 1,
+		Conditions: map[ConditionType]*contour_api_v1.DetailedCondition{},
+	}
+	orphaned.ConditionFor(ValidCondition).AddError(contour_api_v1.ConditionTypeOrphanedError, "Orphaned", "orphaned")
+	orphaned.ConditionFor(OrphanedCondition).AddError(contour_api_v1.ConditionTypeOrphanedError, "NotIncluded", "not included")
+
+	proxy = orphaned.Mutate(proxy).(*contour_api_v1.HTTPProxy)
+	orphanedCond := proxy.Status.GetConditionFor(contour_api_v1.OrphanedConditionType)
+	if assert.NotNil(t, orphanedCond) {
+		assert.Equal(t, contour_api_v1.ConditionTrue, orphanedCond.Status)
+	}
+
+	// Once the HTTPProxy is included, the Orphaned condition is removed.
+	included := ProxyUpdate{
+		Fullname:   k8s.NamespacedNameFrom("test/test"),
+		Generation: 1,
+		Conditions: map[ConditionType]*contour_api_v1.DetailedCondition{},
+	}
+	included.ConditionFor(ValidCondition)
+
+	proxy = included.Mutate(proxy).(*contour_api_v1.HTTPProxy)
+	assert.Nil(t, proxy.Status.GetConditionFor(contour_api_v1.OrphanedConditionType))
+	assert.Len(t, proxy.Status.Conditions, 1)
+
+	// The history records both conditions of the orphaned update in
+	// order of their type, and the removal of the Orphaned condition.
+	var history []string
+	for _, transition := range proxy.Status.ConditionHistory {
+		history = append(history, transition.Type+"="+string(transition.Status)+"/"+transition.Reason)
+	}
+	assert.Equal(t, []string{
+		"Orphaned=False/NotOrphaned",
+		"Valid=True/Valid",
+		"Valid=False/ErrorPresent",
+		"Orphaned=True/ErrorPresent",
+	}, history)
+}
+
+func TestProgrammedGenerationMutator(t *testing.T) {
+	proxy := &contour_api_v1.HTTPProxy{
+		Status: contour_api_v1.HTTPProxyStatus{ProgrammedGeneration: 3},
+	}
+
+	// The programmed generation only advances.
+	assert.Equal(t, int64(3), ProgrammedGenerationMutator(2).Mutate(proxy).(*contour_api_v1.HTTPProxy).Status.ProgrammedGeneration)
+	assert.Equal(t, int64(4), ProgrammedGenerationMutator(4).Mutate(proxy).(*contour_api_v1.HTTPProxy).Status.ProgrammedGeneration)
+	assert.Equal(t, int64(3), proxy.Status.ProgrammedGeneration)
+}
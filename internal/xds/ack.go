@@ -0,0 +1,386 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xds
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+
+	envoy_resource_v3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+)
+
+// requiredTypes are the type URLs of the xDS streams that
+// every Envoy must have ACKed for a revision to be programmed.
+var requiredTypes = []string{
+	envoy_resource_v3.ClusterType,
+	envoy_resource_v3.ListenerType,
+}
+
+func isRequiredType(typeURL string) bool {
+	for _, t := range requiredTypes {
+		if t == typeURL {
+			return true
+		}
+	}
+	return false
+}
+
+// maxSnapshotVersions is the number of snapshot versions whose
+// revision an ACKTracker remembers.
+const maxSnapshotVersions = 16
+
+// ACKTracker tracks the revisions of the xDS resources, which advance
+// every time the resources are updated, and the revisions that the
+// Envoys connected to this Contour have ACKed.
+//
+// A revision is programmed once every xDS stream of at least one Envoy,
+// which must include the cluster and listener streams, has ACKed a
+// response holding that revision, or a later one.
+//
+// A nil ACKTracker tracks nothing.
+type ACKTracker struct {
+	// mu is held for writing while the xDS resources are updated,
+	// so that the revision read while holding it for reading
+	// matches the contents of the resources.
+	mu       sync.RWMutex
+	revision int64
+
+	versionsMu sync.Mutex
+	versions   []snapshotVersion
+
+	streamsMu  sync.Mutex
+	streams    map[int64]*ackStream
+	nodes      map[string]map[int64]*ackStream
+	programmed int64
+
+	notify chan struct{}
+
+	// changed is closed, and replaced, whenever an ACK
+	// is recorded or a stream is closed.
+	changed chan struct{}
+}
+
+// snapshotVersion is a snapshot version and the revision it holds.
+type snapshotVersion struct {
+	version  int64
+	revision int64
+}
+
+// ackStream holds the ACKs of an xDS stream.
+type ackStream struct {
+	node string
+
+	// types holds the responses sent on the stream by type URL.
+	// Aggregated streams carry several types.
+	types map[string]*ackResponse
+}
+
+// ackResponse holds the last response sent for a type URL and
+// the revision that Envoy last ACKed for it.
+type ackResponse struct {
+	nonce    string
+	revision int64
+	acked    int64
+}
+
+// NewACKTracker returns a new ACKTracker.
+func NewACKTracker() *ACKTracker {
+	return &ACKTracker{
+		streams: map[int64]*ackStream{},
+		nodes:   map[string]map[int64]*ackStream{},
+		notify:  make(chan struct{}, 1),
+		changed: make(chan struct{}),
+	}
+}
+
+// Update calls update, which must update the xDS resources, and
+// returns the revision of the updated resources.
+func (t *ACKTracker) Update(update func()) int64 {
+	if t == nil {
+		update()
+		return 0
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	update()
+	t.revision++
+	return t.revision
+}
+
+// Read calls read with the revision of the xDS resources, which
+// are not updated until read returns.
+func (t *ACKTracker) Read(read func(revision int64)) {
+	if t == nil {
+		read(0)
+		return
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	read(t.revision)
+}
+
+// SnapshotUpdated records that the snapshot of the supplied version
+// holds the revision that Update is updating the xDS resources to.
+// It must only be called by the update function passed to Update.
+func (t *ACKTracker) SnapshotUpdated(version string) {
+	if t == nil {
+		return
+	}
+
+	v, err := strconv.ParseInt(version, 10, 64)
+	if err != nil {
+		return
+	}
+
+	t.versionsMu.Lock()
+	defer t.versionsMu.Unlock()
+
+	t.versions = append(t.versions, snapshotVersion{version: v, revision: t.revision + 1})
+	if len(t.versions) > maxSnapshotVersions {
+		t.versions = t.versions[len(t.versions)-maxSnapshotVersions:]
+	}
+}
+
+// SnapshotRevision returns the revision that the snapshot of the
+// supplied version holds. Snapshots generated in between updates hold
+// the revision of the latest snapshot recorded by SnapshotUpdated.
+func (t *ACKTracker) SnapshotRevision(version string) int64 {
+	if t == nil {
+		return 0
+	}
+
+	v, err := strconv.ParseInt(version, 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	t.versionsMu.Lock()
+	defer t.versionsMu.Unlock()
+
+	i := sort.Search(len(t.versions), func(i int) bool {
+		return t.versions[i].version > v
+	})
+	if i == 0 {
+		return 0
+	}
+	return t.versions[i-1].revision
+}
+
+// Response records that the response of the supplied nonce, which
+// holds the supplied revision of the xDS resources of the type URL,
+// is sent on the stream.
+func (t *ACKTracker) Response(streamID int64, typeURL, nonce string, revision int64) {
+	if t == nil {
+		return
+	}
+
+	t.streamsMu.Lock()
+	defer t.streamsMu.Unlock()
+
+	resp := t.stream(streamID).response(typeURL)
+	resp.nonce = nonce
+	resp.revision = revision
+}
+
+// Request records a request received on the stream. It ACKs the last
+// response sent for the type URL if it carries the response's nonce
+// and Envoy did not reject it.
+func (t *ACKTracker) Request(streamID int64, node, typeURL, responseNonce string, rejected bool) {
+	if t == nil {
+		return
+	}
+
+	t.streamsMu.Lock()
+	defer t.streamsMu.Unlock()
+
+	s := t.stream(streamID)
+	if node != "" && s.node == "" {
+		s.node = node
+		if t.nodes[node] == nil {
+			t.nodes[node] = map[int64]*ackStream{}
+		}
+		t.nodes[node][streamID] = s
+	}
+
+	resp := s.response(typeURL)
+	if responseNonce == "" || responseNonce != resp.nonce || rejected {
+		return
+	}
+	resp.acked = resp.revision
+	t.broadcast()
+
+	if resp.acked > t.programmed {
+		t.updateProgrammed(s.node)
+	}
+}
+
+// StreamClosed forgets the stream.
+func (t *ACKTracker) StreamClosed(streamID int64) {
+	if t == nil {
+		return
+	}
+
+	t.streamsMu.Lock()
+	defer t.streamsMu.Unlock()
+
+	s, ok := t.streams[streamID]
+	if !ok {
+		return
+	}
+	delete(t.streams, streamID)
+	t.broadcast()
+
+	if s.node == "" {
+		return
+	}
+	delete(t.nodes[s.node], streamID)
+	if len(t.nodes[s.node]) == 0 {
+		delete(t.nodes, s.node)
+		return
+	}
+
+	// The remaining streams of the Envoy may be
+	// ahead of the closed one.
+	t.updateProgrammed(s.node)
+}
+
+// Programmed returns the latest programmed revision.
+func (t *ACKTracker) Programmed() int64 {
+	if t == nil {
+		return 0
+	}
+
+	t.streamsMu.Lock()
+	defer t.streamsMu.Unlock()
+
+	return t.programmed
+}
+
+// Notify returns a channel that is ready to read once the programmed
+// revision advances.
+func (t *ACKTracker) Notify() <-chan struct{} {
+	if t == nil {
+		return nil
+	}
+
+	return t.notify
+}
+
+// Changed returns a channel that is closed once an Envoy ACKs a
+// response or closes a stream. Unlike Notify, any number of
+// goroutines can wait on it.
+func (t *ACKTracker) Changed() <-chan struct{} {
+	if t == nil {
+		return nil
+	}
+
+	t.streamsMu.Lock()
+	defer t.streamsMu.Unlock()
+
+	return t.changed
+}
+
+// Nodes returns the revision that each connected Envoy has ACKed, by
+// node ID. Envoys are left out until they have ACKed their cluster and
+// listener streams, since they are not serving traffic until then.
+func (t *ACKTracker) Nodes() map[string]int64 {
+	if t == nil {
+		return nil
+	}
+
+	t.streamsMu.Lock()
+	defer t.streamsMu.Unlock()
+
+	nodes := map[string]int64{}
+	for node := range t.nodes {
+		if revision := t.nodeRevision(node); revision > 0 {
+			nodes[node] = revision
+		}
+	}
+	return nodes
+}
+
+func (t *ACKTracker) broadcast() {
+	close(t.changed)
+	t.changed = make(chan struct{})
+}
+
+func (t *ACKTracker) stream(streamID int64) *ackStream {
+	s, ok := t.streams[streamID]
+	if !ok {
+		s = &ackStream{types: map[string]*ackResponse{}}
+		t.streams[streamID] = s
+	}
+	return s
+}
+
+func (s *ackStream) response(typeURL string) *ackResponse {
+	resp, ok := s.types[typeURL]
+	if !ok {
+		resp = &ackResponse{}
+		s.types[typeURL] = resp
+	}
+	return resp
+}
+
+// updateProgrammed advances the programmed revision to the revision
+// that every stream of the node has ACKed, if it is later. It must be
+// called with streamsMu held.
+func (t *ACKTracker) updateProgrammed(node string) {
+	if node == "" {
+		return
+	}
+
+	acked := t.nodeRevision(node)
+	if acked <= t.programmed {
+		// Nothing to advance.
+		return
+	}
+	t.programmed = acked
+
+	select {
+	case t.notify <- struct{}{}:
+	default:
+	}
+}
+
+// nodeRevision returns the revision that every stream of the node has
+// ACKed, or 0 if the node has not ACKed the required types yet. It
+// must be called with streamsMu held.
+func (t *ACKTracker) nodeRevision(node string) int64 {
+	var acked int64 = -1
+	required := map[string]bool{}
+	for _, s := range t.nodes[node] {
+		for typeURL, resp := range s.types {
+			if acked < 0 || resp.acked < acked {
+				acked = resp.acked
+			}
+			if isRequiredType(typeURL) {
+				required[typeURL] = true
+			}
+		}
+	}
+
+	// Envoy opens the listener stream once the clusters are
+	// ACKed, so the resources are not programmed until then.
+	if len(required) < len(requiredTypes) {
+		return 0
+	}
+	return acked
+}
@@ -0,0 +1,189 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xds
+
+import (
+	"testing"
+
+	envoy_resource_v3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	clusterType  = envoy_resource_v3.ClusterType
+	listenerType = envoy_resource_v3.ListenerType
+	routeType    = envoy_resource_v3.RouteType
+)
+
+func TestACKTrackerRevisions(t *testing.T) {
+	acks := NewACKTracker()
+
+	assert.Equal(t, int64(1), acks.Update(func() {
+		acks.SnapshotUpdated("3")
+	}))
+	assert.Equal(t, int64(2), acks.Update(func() {
+		acks.SnapshotUpdated("5")
+	}))
+
+	acks.Read(func(revision int64) {
+		assert.Equal(t, int64(2), revision)
+	})
+
+	assert.Equal(t, int64(0), acks.SnapshotRevision("2"))
+	assert.Equal(t, int64(1), acks.SnapshotRevision("3"))
+	// Snapshots generated in between updates.
+	assert.Equal(t, int64(1), acks.SnapshotRevision("4"))
+	assert.Equal(t, int64(2), acks.SnapshotRevision("6"))
+	assert.Equal(t, int64(0), acks.SnapshotRevision("invalid"))
+}
+
+func TestACKTrackerProgrammed(t *testing.T) {
+	acks := NewACKTracker()
+
+	// The first requests of the streams of two Envoys.
+	acks.Request(1, "envoy-1", clusterType, "", false)
+	acks.Request(2, "envoy-1", listenerType, "", false)
+	acks.Request(3, "envoy-1", routeType, "", false)
+	acks.Request(4, "envoy-2", clusterType, "", false)
+
+	acks.Response(1, clusterType, "a", 1)
+	acks.Response(2, listenerType, "b", 1)
+	acks.Response(3, routeType, "c", 1)
+	acks.Response(4, clusterType, "d", 1)
+
+	// A NACK.
+	acks.Request(1, "", clusterType, "a", true)
+	// A stale nonce.
+	acks.Request(2, "", listenerType, "z", false)
+	acks.Request(3, "", routeType, "c", false)
+	// envoy-2 has ACKed the clusters, but it has not
+	// opened its listener stream yet.
+	acks.Request(4, "", clusterType, "d", false)
+	assertNotNotified(t, acks)
+	assert.Empty(t, acks.Nodes())
+
+	acks.Request(5, "envoy-2", listenerType, "", false)
+	acks.Response(5, listenerType, "e", 1)
+	acks.Request(5, "", listenerType, "e", false)
+	assertNotified(t, acks)
+	assert.Equal(t, int64(1), acks.Programmed())
+	assert.Equal(t, map[string]int64{"envoy-2": 1}, acks.Nodes())
+
+	// envoy-1 ACKs a later revision on all but its route stream.
+	acks.Response(1, clusterType, "f", 2)
+	acks.Request(1, "", clusterType, "f", false)
+	acks.Response(2, listenerType, "g", 2)
+	acks.Request(2, "", listenerType, "g", false)
+	assertNotNotified(t, acks)
+
+	// Its lagging route stream closes.
+	acks.StreamClosed(3)
+	assertNotified(t, acks)
+	assert.Equal(t, int64(2), acks.Programmed())
+	assert.Equal(t, map[string]int64{"envoy-1": 2, "envoy-2": 1}, acks.Nodes())
+
+	// The programmed revision doesn't go backwards.
+	acks.StreamClosed(1)
+	acks.StreamClosed(2)
+	acks.StreamClosed(4)
+	acks.StreamClosed(5)
+	assert.Equal(t, int64(2), acks.Programmed())
+	assert.Empty(t, acks.Nodes())
+}
+
+func TestACKTrackerChanged(t *testing.T) {
+	acks := NewACKTracker()
+
+	changed := acks.Changed()
+	acks.Request(1, "envoy", clusterType, "", false)
+	acks.Response(1, clusterType, "a", 1)
+	assertOpen(t, changed)
+
+	// A NACK.
+	acks.Request(1, "", clusterType, "a", true)
+	assertOpen(t, changed)
+
+	acks.Request(1, "", clusterType, "a", false)
+	assertClosed(t, changed)
+
+	changed = acks.Changed()
+	assertOpen(t, changed)
+	acks.StreamClosed(1)
+	assertClosed(t, changed)
+}
+
+func TestACKTrackerNil(t *testing.T) {
+	var acks *ACKTracker
+
+	updated := false
+	assert.Equal(t, int64(0), acks.Update(func() {
+		updated = true
+		acks.SnapshotUpdated("1")
+	}))
+	assert.True(t, updated)
+
+	acks.Read(func(revision int64) {
+		assert.Equal(t, int64(0), revision)
+	})
+
+	acks.Response(1, clusterType, "a", 1)
+	acks.Request(1, "envoy", clusterType, "a", false)
+	acks.StreamClosed(1)
+
+	assert.Equal(t, int64(0), acks.SnapshotRevision("1"))
+	assert.Equal(t, int64(0), acks.Programmed())
+	assert.Nil(t, acks.Notify())
+	assert.Nil(t, acks.Changed())
+	assert.Nil(t, acks.Nodes())
+}
+
+func assertNotified(t *testing.T, acks *ACKTracker) {
+	t.Helper()
+
+	select {
+	case <-acks.Notify():
+	default:
+		t.Error("expected the programmed revision to advance")
+	}
+}
+
+func assertNotNotified(t *testing.T, acks *ACKTracker) {
+	t.Helper()
+
+	select {
+	case <-acks.Notify():
+		t.Errorf("unexpected programmed revision %d", acks.Programmed())
+	default:
+	}
+}
+
+func assertClosed(t *testing.T, ch <-chan struct{}) {
+	t.Helper()
+
+	select {
+	case <-ch:
+	default:
+		t.Error("expected the channel to be closed")
+	}
+}
+
+func assertOpen(t *testing.T, ch <-chan struct{}) {
+	t.Helper()
+
+	select {
+	case <-ch:
+		t.Error("unexpected closed channel")
+	default:
+	}
+}
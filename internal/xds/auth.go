@@ -0,0 +1,407 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xds
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	"github.com/projectcontour/contour/internal/certsan"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// NodeTokenMetadataKey is the key of the Envoy node metadata field
+// that carries the JWT presented by an Envoy to the xDS server.
+const NodeTokenMetadataKey = "contour-xds-token"
+
+// NodeAuthorizer authorizes the Envoy nodes connecting to the xDS server.
+// A node is authorized if its verified client certificate carries one of
+// AllowedSANs, or if it presents a JWT in its node metadata that is signed
+// by one of the keys in JWTKeyFile. Each stream is authorized on the first
+// request it receives; requests on streams from unauthorized nodes are
+// rejected with a PermissionDenied status.
+type NodeAuthorizer struct {
+	// AllowedSANs is the list of Subject Alternative Names (DNS
+	// names, URIs, IP addresses or email addresses) that authorize
+	// a node when present in its verified client certificate.
+	AllowedSANs []string
+
+	// JWTKeyFile is the name of a PEM file holding the public keys
+	// used to verify node JWTs. If empty, JWTs are not accepted.
+	// The file is read on each authorization so that keys can be
+	// rotated without restarting Contour.
+	JWTKeyFile string
+
+	// JWTIssuer is the required "iss" claim of node JWTs, if set.
+	JWTIssuer string
+
+	// JWTAudiences is the list of accepted "aud" claims of node
+	// JWTs, if set.
+	JWTAudiences []string
+
+	// Log records the nodes that were refused.
+	Log logrus.FieldLogger
+
+	// now returns the current time, for testing.
+	now func() time.Time
+}
+
+// StreamServerInterceptor returns a gRPC stream interceptor that
+// authorizes each stream on its first request.
+func (a *NodeAuthorizer) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &authorizedStream{ServerStream: ss, authorizer: a})
+	}
+}
+
+// UnaryServerInterceptor returns a gRPC unary interceptor that
+// authorizes each request.
+func (a *NodeAuthorizer) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := a.Authorize(ctx, req); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// authorizedStream is a grpc.ServerStream that authorizes the
+// request it receives first.
+type authorizedStream struct {
+	grpc.ServerStream
+	authorizer *NodeAuthorizer
+	authorized bool
+}
+
+func (s *authorizedStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	if !s.authorized {
+		if err := s.authorizer.Authorize(s.Context(), m); err != nil {
+			return err
+		}
+		s.authorized = true
+	}
+
+	return nil
+}
+
+// Authorize returns a PermissionDenied status error if the node that
+// sent the xDS request req over the connection in ctx is not authorized.
+func (a *NodeAuthorizer) Authorize(ctx context.Context, req any) error {
+	var node *envoy_core_v3.Node
+	if r, ok := req.(interface{ GetNode() *envoy_core_v3.Node }); ok {
+		node = r.GetNode()
+	}
+
+	err := a.authorize(ctx, node)
+	if err == nil {
+		return nil
+	}
+
+	if a.Log != nil {
+		a.Log.WithError(err).WithField("node_id", node.GetId()).Warn("refusing unauthorized xDS node")
+	}
+
+	return status.Errorf(codes.PermissionDenied, "xDS node %q is not authorized: %v", node.GetId(), err)
+}
+
+func (a *NodeAuthorizer) authorize(ctx context.Context, node *envoy_core_v3.Node) error {
+	var reasons []string
+
+	if len(a.AllowedSANs) > 0 {
+		err := a.authorizeSANs(ctx)
+		if err == nil {
+			return nil
+		}
+		reasons = append(reasons, err.Error())
+	}
+
+	if a.JWTKeyFile != "" {
+		err := a.authorizeToken(node)
+		if err == nil {
+			return nil
+		}
+		reasons = append(reasons, err.Error())
+	}
+
+	if len(reasons) == 0 {
+		return errors.New("no authorization method is configured")
+	}
+
+	return errors.New(strings.Join(reasons, "; "))
+}
+
+// authorizeSANs checks that the verified client certificate of the peer
+// in ctx has one of the allowed SANs.
+func (a *NodeAuthorizer) authorizeSANs(ctx context.Context) error {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return errors.New("no peer information")
+	}
+
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return errors.New("no verified client certificate")
+	}
+
+	return certsan.Verify(tlsInfo.State, a.AllowedSANs)
+}
+
+// authorizeToken checks the JWT in the metadata of node.
+func (a *NodeAuthorizer) authorizeToken(node *envoy_core_v3.Node) error {
+	token := node.GetMetadata().GetFields()[NodeTokenMetadataKey].GetStringValue()
+	if token == "" {
+		return fmt.Errorf("no %q node metadata", NodeTokenMetadataKey)
+	}
+
+	keys, err := loadPublicKeys(a.JWTKeyFile)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now
+	if a.now != nil {
+		now = a.now
+	}
+
+	return verifyJWT(token, keys, a.JWTIssuer, a.JWTAudiences, now())
+}
+
+// loadPublicKeys returns the public keys held by the PEM file filename.
+// Both PUBLIC KEY and CERTIFICATE blocks are accepted.
+func loadPublicKeys(filename string) ([]crypto.PublicKey, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []crypto.PublicKey
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+
+		switch block.Type {
+		case "PUBLIC KEY":
+			key, err := x509.ParsePKIXPublicKey(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("invalid public key in %s: %w", filename, err)
+			}
+			keys = append(keys, key)
+		case "CERTIFICATE":
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("invalid certificate in %s: %w", filename, err)
+			}
+			keys = append(keys, cert.PublicKey)
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no public keys found in %s", filename)
+	}
+
+	return keys, nil
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+}
+
+type jwtClaims struct {
+	Issuer    string      `json:"iss"`
+	Audience  jwtAudience `json:"aud"`
+	Expiry    *int64      `json:"exp"`
+	NotBefore *int64      `json:"nbf"`
+}
+
+// jwtAudience is the "aud" claim, which may be either
+// a single string or an array of strings.
+type jwtAudience []string
+
+func (a *jwtAudience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = jwtAudience{single}
+		return nil
+	}
+
+	var multi []string
+	if err := json.Unmarshal(data, &multi); err != nil {
+		return err
+	}
+	*a = multi
+	return nil
+}
+
+// verifyJWT checks that token is a compact JWS signed by one of keys,
+// that it is valid at now, and that its issuer and audience match the
+// ones required, if any. Envoy presents the token of its bootstrap on
+// every reconnection, so a token without an expiry is accepted.
+func verifyJWT(token string, keys []crypto.PublicKey, issuer string, audiences []string, now time.Time) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return errors.New("malformed JWT")
+	}
+
+	var header jwtHeader
+	if err := decodeJWTSegment(parts[0], &header); err != nil {
+		return fmt.Errorf("malformed JWT header: %w", err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return fmt.Errorf("malformed JWT signature: %w", err)
+	}
+
+	signed := []byte(parts[0] + "." + parts[1])
+
+	verified := false
+	for _, key := range keys {
+		if err := verifyJWTSignature(header.Alg, key, signed, signature); err == nil {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return fmt.Errorf("JWT signature with algorithm %q could not be verified", header.Alg)
+	}
+
+	var claims jwtClaims
+	if err := decodeJWTSegment(parts[1], &claims); err != nil {
+		return fmt.Errorf("malformed JWT claims: %w", err)
+	}
+
+	switch {
+	case claims.Expiry != nil && now.After(time.Unix(*claims.Expiry, 0)):
+		return errors.New("JWT has expired")
+	case claims.NotBefore != nil && now.Before(time.Unix(*claims.NotBefore, 0)):
+		return errors.New("JWT is not yet valid")
+	case issuer != "" && claims.Issuer != issuer:
+		return fmt.Errorf("JWT issuer %q is not allowed", claims.Issuer)
+	}
+
+	if len(audiences) > 0 && !containsAny(claims.Audience, audiences) {
+		return fmt.Errorf("JWT audience %q is not allowed", claims.Audience)
+	}
+
+	return nil
+}
+
+func decodeJWTSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// ecdsaCurves holds the curve of each ECDSA JWS algorithm.
+var ecdsaCurves = map[string]elliptic.Curve{
+	"ES256": elliptic.P256(),
+	"ES384": elliptic.P384(),
+	"ES512": elliptic.P521(),
+}
+
+// verifyJWTSignature verifies the signature of signed with key
+// using the JWS algorithm alg.
+func verifyJWTSignature(alg string, key crypto.PublicKey, signed, signature []byte) error {
+	var hash crypto.Hash
+	switch alg {
+	case "RS256", "ES256", "PS256":
+		hash = crypto.SHA256
+	case "RS384", "ES384", "PS384":
+		hash = crypto.SHA384
+	case "RS512", "ES512", "PS512":
+		hash = crypto.SHA512
+	case "EdDSA":
+		if k, ok := key.(ed25519.PublicKey); ok && ed25519.Verify(k, signed, signature) {
+			return nil
+		}
+		return errors.New("invalid signature")
+	default:
+		return fmt.Errorf("unsupported JWT algorithm %q", alg)
+	}
+
+	h := hash.New()
+	h.Write(signed)
+	digest := h.Sum(nil)
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		switch alg[0] {
+		case 'R':
+			return rsa.VerifyPKCS1v15(k, hash, digest, signature)
+		case 'P':
+			return rsa.VerifyPSS(k, hash, digest, signature, nil)
+		}
+	case *ecdsa.PublicKey:
+		if alg[0] != 'E' {
+			break
+		}
+		// Each ECDSA algorithm is only defined for one curve.
+		if want := ecdsaCurves[alg]; k.Curve != want {
+			return fmt.Errorf("key of curve %s cannot verify algorithm %q, which needs curve %s",
+				k.Curve.Params().Name, alg, want.Params().Name)
+		}
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return errors.New("invalid signature length")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if ecdsa.Verify(k, digest, r, s) {
+			return nil
+		}
+		return errors.New("invalid signature")
+	}
+
+	return fmt.Errorf("key of type %T cannot verify algorithm %q", key, alg)
+}
+
+func containsAny(have, want []string) bool {
+	for _, h := range have {
+		for _, w := range want {
+			if h == w {
+				return true
+			}
+		}
+	}
+	return false
+}
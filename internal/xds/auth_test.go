@@ -0,0 +1,334 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xds
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	envoy_service_discovery_v3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestNodeAuthorizerSANs(t *testing.T) {
+	spiffe, err := url.Parse("spiffe://cluster.local/ns/projectcontour/sa/envoy")
+	require.NoError(t, err)
+
+	peerWith := func(cert *x509.Certificate) context.Context {
+		state := tls.ConnectionState{}
+		if cert != nil {
+			state.VerifiedChains = [][]*x509.Certificate{{cert}}
+		}
+		return peer.NewContext(context.Background(), &peer.Peer{
+			AuthInfo: credentials.TLSInfo{State: state},
+		})
+	}
+
+	tests := map[string]struct {
+		ctx        context.Context
+		authorized bool
+	}{
+		"allowed DNS SAN": {
+			ctx:        peerWith(&x509.Certificate{DNSNames: []string{"envoy"}}),
+			authorized: true,
+		},
+		"allowed URI SAN": {
+			ctx:        peerWith(&x509.Certificate{URIs: []*url.URL{spiffe}}),
+			authorized: true,
+		},
+		"SAN not allowed": {
+			ctx:        peerWith(&x509.Certificate{DNSNames: []string{"attacker"}}),
+			authorized: false,
+		},
+		"no verified client certificate": {
+			ctx:        peerWith(nil),
+			authorized: false,
+		},
+		"no peer": {
+			ctx:        context.Background(),
+			authorized: false,
+		},
+	}
+
+	a := &NodeAuthorizer{
+		AllowedSANs: []string{"envoy", spiffe.String()},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := a.Authorize(tc.ctx, &envoy_service_discovery_v3.DiscoveryRequest{})
+			assertAuthorized(t, tc.authorized, err)
+		})
+	}
+}
+
+func TestNodeAuthorizerJWT(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	keyFile := writePublicKey(t, &key.PublicKey)
+	now := time.Unix(1700000000, 0)
+
+	claims := func(extra map[string]any) map[string]any {
+		c := map[string]any{
+			"iss": "https://kubernetes.default.svc",
+			"aud": []string{"contour"},
+			"exp": now.Add(time.Hour).Unix(),
+		}
+		for k, v := range extra {
+			c[k] = v
+		}
+		return c
+	}
+
+	tests := map[string]struct {
+		token      string
+		authorized bool
+	}{
+		"valid token": {
+			token:      signJWT(t, key, claims(nil)),
+			authorized: true,
+		},
+		"single string audience": {
+			token:      signJWT(t, key, claims(map[string]any{"aud": "contour"})),
+			authorized: true,
+		},
+		"no token": {
+			token:      "",
+			authorized: false,
+		},
+		"malformed token": {
+			token:      "not-a-jwt",
+			authorized: false,
+		},
+		"signed by another key": {
+			token:      signJWT(t, otherKey, claims(nil)),
+			authorized: false,
+		},
+		"expired": {
+			token:      signJWT(t, key, claims(map[string]any{"exp": now.Add(-time.Minute).Unix()})),
+			authorized: false,
+		},
+		"no expiry": {
+			token:      signJWT(t, key, claims(map[string]any{"exp": nil})),
+			authorized: true,
+		},
+		"not yet valid": {
+			token:      signJWT(t, key, claims(map[string]any{"nbf": now.Add(time.Minute).Unix()})),
+			authorized: false,
+		},
+		"wrong issuer": {
+			token:      signJWT(t, key, claims(map[string]any{"iss": "someone-else"})),
+			authorized: false,
+		},
+		"wrong audience": {
+			token:      signJWT(t, key, claims(map[string]any{"aud": []string{"vault"}})),
+			authorized: false,
+		},
+	}
+
+	a := &NodeAuthorizer{
+		JWTKeyFile:   keyFile,
+		JWTIssuer:    "https://kubernetes.default.svc",
+		JWTAudiences: []string{"contour"},
+		now:          func() time.Time { return now },
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := a.Authorize(context.Background(), discoveryRequestWithToken(tc.token))
+			assertAuthorized(t, tc.authorized, err)
+		})
+	}
+}
+
+func TestVerifyJWTSignatureECDSACurve(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	signed := []byte("header.payload")
+	sign := func(digest []byte) []byte {
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest)
+		require.NoError(t, err)
+
+		signature := make([]byte, 96)
+		r.FillBytes(signature[:48])
+		s.FillBytes(signature[48:])
+		return signature
+	}
+
+	sha384Digest := sha512.Sum384(signed)
+	require.NoError(t, verifyJWTSignature("ES384", &key.PublicKey, signed, sign(sha384Digest[:])))
+
+	// A P-384 key produces valid signatures of SHA-256
+	// digests, but ES256 is only defined for P-256.
+	sha256Digest := sha256.Sum256(signed)
+	err = verifyJWTSignature("ES256", &key.PublicKey, signed, sign(sha256Digest[:]))
+	require.EqualError(t, err, `key of curve P-384 cannot verify algorithm "ES256", which needs curve P-256`)
+}
+
+func TestNodeAuthorizerStreamInterceptor(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	a := &NodeAuthorizer{
+		JWTKeyFile: writePublicKey(t, &key.PublicKey),
+	}
+
+	valid := signJWT(t, key, map[string]any{"exp": time.Now().Add(time.Hour).Unix()})
+
+	tests := map[string]struct {
+		requests   []*envoy_service_discovery_v3.DiscoveryRequest
+		authorized bool
+	}{
+		"authorized stream": {
+			requests: []*envoy_service_discovery_v3.DiscoveryRequest{
+				discoveryRequestWithToken(valid),
+				// Only the first request of a stream is authorized.
+				{},
+			},
+			authorized: true,
+		},
+		"unauthorized stream": {
+			requests: []*envoy_service_discovery_v3.DiscoveryRequest{
+				discoveryRequestWithToken(""),
+			},
+			authorized: false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			stream := &fakeServerStream{requests: tc.requests}
+
+			err := a.StreamServerInterceptor()(nil, stream, &grpc.StreamServerInfo{}, func(_ any, ss grpc.ServerStream) error {
+				for range tc.requests {
+					if err := ss.RecvMsg(&envoy_service_discovery_v3.DiscoveryRequest{}); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+
+			assertAuthorized(t, tc.authorized, err)
+		})
+	}
+}
+
+func assertAuthorized(t *testing.T, authorized bool, err error) {
+	t.Helper()
+
+	if authorized {
+		assert.NoError(t, err)
+		return
+	}
+
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func discoveryRequestWithToken(token string) *envoy_service_discovery_v3.DiscoveryRequest {
+	node := &envoy_core_v3.Node{Id: "envoy"}
+	if token != "" {
+		node.Metadata = &structpb.Struct{
+			Fields: map[string]*structpb.Value{
+				NodeTokenMetadataKey: structpb.NewStringValue(token),
+			},
+		}
+	}
+
+	return &envoy_service_discovery_v3.DiscoveryRequest{Node: node}
+}
+
+func writePublicKey(t *testing.T, key crypto.PublicKey) string {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(key)
+	require.NoError(t, err)
+
+	filename := filepath.Join(t.TempDir(), "key.pem")
+	require.NoError(t, os.WriteFile(filename, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600))
+
+	return filename
+}
+
+// signJWT returns an ES256 JWT with the given claims. Claims
+// with a nil value are omitted.
+func signJWT(t *testing.T, key *ecdsa.PrivateKey, claims map[string]any) string {
+	t.Helper()
+
+	for k, v := range claims {
+		if v == nil {
+			delete(claims, k)
+		}
+	}
+
+	header, err := json.Marshal(map[string]string{"alg": "ES256", "typ": "JWT"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	require.NoError(t, err)
+
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+type fakeServerStream struct {
+	grpc.ServerStream
+	requests []*envoy_service_discovery_v3.DiscoveryRequest
+}
+
+func (s *fakeServerStream) Context() context.Context {
+	return context.Background()
+}
+
+func (s *fakeServerStream) RecvMsg(m any) error {
+	req := s.requests[0]
+	s.requests = s.requests[1:]
+
+	m.(*envoy_service_discovery_v3.DiscoveryRequest).Node = req.Node
+	return nil
+}
@@ -20,6 +20,7 @@ import (
 	envoy_config_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoy_service_discovery_v3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
 	envoy_server_v3 "github.com/envoyproxy/go-control-plane/pkg/server/v3"
+	"github.com/projectcontour/contour/internal/xds"
 	"github.com/sirupsen/logrus"
 )
 
@@ -28,6 +29,14 @@ import (
 // request detail logging. Currently only the xDS State of the World callback
 // OnStreamRequest is implemented.
 func NewRequestLoggingCallbacks(log logrus.FieldLogger) envoy_server_v3.Callbacks {
+	return NewACKTrackingCallbacks(log, nil)
+}
+
+// NewACKTrackingCallbacks returns the callbacks of NewRequestLoggingCallbacks
+// that additionally record the ACKs of the xDS State of the World streams
+// in acks. The versions of the responses are the snapshot versions recorded
+// in acks.
+func NewACKTrackingCallbacks(log logrus.FieldLogger, acks *xds.ACKTracker) envoy_server_v3.Callbacks {
 	return &envoy_server_v3.CallbackFuncs{
 		StreamOpenFunc: func(ctx context.Context, streamID int64, typeURL string) error {
 			logStreamOpenDetails(log, streamID, typeURL)
@@ -35,11 +44,16 @@ func NewRequestLoggingCallbacks(log logrus.FieldLogger) envoy_server_v3.Callback
 		},
 		StreamClosedFunc: func(streamID int64, node *envoy_config_core_v3.Node) {
 			logStreamClosedDetails(log, streamID, node)
+			acks.StreamClosed(streamID)
 		},
 		StreamRequestFunc: func(streamID int64, req *envoy_service_discovery_v3.DiscoveryRequest) error {
 			logDiscoveryRequestDetails(log, req)
+			acks.Request(streamID, req.GetNode().GetId(), req.GetTypeUrl(), req.ResponseNonce, req.ErrorDetail != nil)
 			return nil
 		},
+		StreamResponseFunc: func(ctx context.Context, streamID int64, req *envoy_service_discovery_v3.DiscoveryRequest, resp *envoy_service_discovery_v3.DiscoveryResponse) {
+			acks.Response(streamID, resp.GetTypeUrl(), resp.Nonce, acks.SnapshotRevision(resp.VersionInfo))
+		},
 	}
 }
 
@@ -41,11 +41,13 @@ type grpcStream interface {
 
 // NewContourServer creates an internally implemented Server that streams the
 // provided set of Resource objects. The returned Server implements the xDS
-// State of the World (SotW) variant.
-func NewContourServer(log logrus.FieldLogger, resources ...xds.Resource) Server {
+// State of the World (SotW) variant. The ACKs of the responses are recorded
+// in acks, which can be nil.
+func NewContourServer(log logrus.FieldLogger, acks *xds.ACKTracker, resources ...xds.Resource) Server {
 	c := contourServer{
 		FieldLogger: log,
 		resources:   map[string]xds.Resource{},
+		acks:        acks,
 	}
 
 	for i, r := range resources {
@@ -70,12 +72,17 @@ type contourServer struct {
 	logrus.FieldLogger
 	resources   map[string]xds.Resource
 	connections xds.Counter
+	acks        *xds.ACKTracker
 }
 
 // stream processes a stream of DiscoveryRequests.
 func (s *contourServer) stream(st grpcStream) error {
 	// Bump connection counter and set it as a field on the logger.
-	log := s.WithField("connection", s.connections.Next())
+	connection := s.connections.Next()
+	log := s.WithField("connection", connection)
+
+	streamID := int64(connection)
+	defer s.acks.StreamClosed(streamID)
 
 	// Notify whether the stream terminated on error.
 	done := func(log logrus.FieldLogger, err error) error {
@@ -109,6 +116,8 @@ func (s *contourServer) stream(st grpcStream) error {
 		// Note: redeclare log in this scope so the next time around the loop all is forgotten.
 		log := logDiscoveryRequestDetails(log, req)
 
+		s.acks.Request(streamID, req.GetNode().GetId(), req.GetTypeUrl(), req.ResponseNonce, req.ErrorDetail != nil)
+
 		// From the request we derive the resource to stream which have
 		// been registered according to the typeURL.
 		r, ok := s.resources[req.GetTypeUrl()]
@@ -126,15 +135,19 @@ func (s *contourServer) stream(st grpcStream) error {
 			// so we're going to be sending an update that is a no-op. See #426
 
 			var resources []proto.Message
-			switch len(req.ResourceNames) {
-			case 0:
-				// no resource hints supplied, return the full
-				// contents of the resource
-				resources = r.Contents()
-			default:
-				// resource hints supplied, return exactly those
-				resources = r.Query(req.ResourceNames)
-			}
+			var revision int64
+			s.acks.Read(func(rev int64) {
+				revision = rev
+				switch len(req.ResourceNames) {
+				case 0:
+					// no resource hints supplied, return the full
+					// contents of the resource
+					resources = r.Contents()
+				default:
+					// resource hints supplied, return exactly those
+					resources = r.Query(req.ResourceNames)
+				}
+			})
 
 			anyResources := make([]*anypb.Any, 0, len(resources))
 			for _, r := range resources {
@@ -152,6 +165,7 @@ func (s *contourServer) stream(st grpcStream) error {
 				Nonce:       strconv.Itoa(last),
 			}
 
+			s.acks.Response(streamID, resp.TypeUrl, resp.Nonce, revision)
 			if err := st.Send(resp); err != nil {
 				return done(log, err)
 			}
@@ -22,6 +22,8 @@ import (
 	envoy_service_runtime_v3 "github.com/envoyproxy/go-control-plane/envoy/service/runtime/v3"
 	envoy_service_secret_v3 "github.com/envoyproxy/go-control-plane/envoy/service/secret/v3"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // Server is a collection of handlers for streaming discovery requests.
@@ -47,3 +49,44 @@ func RegisterServer(srv Server, g *grpc.Server) {
 	envoy_service_route_v3.RegisterRouteDiscoveryServiceServer(g, srv)
 	envoy_service_runtime_v3.RegisterRuntimeDiscoveryServiceServer(g, srv)
 }
+
+// WithoutDelta returns srv with the incremental xDS
+// protocol variant disabled. Incremental streams are
+// refused with an Unimplemented status.
+func WithoutDelta(srv Server) Server {
+	return &sotwServer{Server: srv}
+}
+
+type sotwServer struct {
+	Server
+}
+
+var errDeltaDisabled = status.Error(codes.Unimplemented, "incremental xDS is disabled")
+
+func (*sotwServer) DeltaAggregatedResources(envoy_service_discovery_v3.AggregatedDiscoveryService_DeltaAggregatedResourcesServer) error {
+	return errDeltaDisabled
+}
+
+func (*sotwServer) DeltaSecrets(envoy_service_secret_v3.SecretDiscoveryService_DeltaSecretsServer) error {
+	return errDeltaDisabled
+}
+
+func (*sotwServer) DeltaClusters(envoy_service_cluster_v3.ClusterDiscoveryService_DeltaClustersServer) error {
+	return errDeltaDisabled
+}
+
+func (*sotwServer) DeltaEndpoints(envoy_service_endpoint_v3.EndpointDiscoveryService_DeltaEndpointsServer) error {
+	return errDeltaDisabled
+}
+
+func (*sotwServer) DeltaListeners(envoy_service_listener_v3.ListenerDiscoveryService_DeltaListenersServer) error {
+	return errDeltaDisabled
+}
+
+func (*sotwServer) DeltaRoutes(envoy_service_route_v3.RouteDiscoveryService_DeltaRoutesServer) error {
+	return errDeltaDisabled
+}
+
+func (*sotwServer) DeltaRuntime(envoy_service_runtime_v3.RuntimeDiscoveryService_DeltaRuntimeServer) error {
+	return errDeltaDisabled
+}
@@ -0,0 +1,40 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"testing"
+
+	"github.com/projectcontour/contour/internal/fixture"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestWithoutDelta(t *testing.T) {
+	srv := WithoutDelta(NewContourServer(fixture.NewTestLogger(t), nil))
+
+	for name, err := range map[string]error{
+		"ads":       srv.DeltaAggregatedResources(nil),
+		"secrets":   srv.DeltaSecrets(nil),
+		"clusters":  srv.DeltaClusters(nil),
+		"endpoints": srv.DeltaEndpoints(nil),
+		"listeners": srv.DeltaListeners(nil),
+		"routes":    srv.DeltaRoutes(nil),
+		"runtime":   srv.DeltaRuntime(nil),
+	} {
+		assert.Equal(t, codes.Unimplemented, status.Code(err), name)
+		assert.Equal(t, "incremental xDS is disabled", status.Convert(err).Message(), name)
+	}
+}
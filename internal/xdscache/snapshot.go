@@ -22,6 +22,7 @@ import (
 	envoy_types "github.com/envoyproxy/go-control-plane/pkg/cache/types"
 	envoy_resource_v3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
 	"github.com/projectcontour/contour/internal/dag"
+	"github.com/projectcontour/contour/internal/xds"
 	"github.com/sirupsen/logrus"
 )
 
@@ -42,13 +43,19 @@ type SnapshotHandler struct {
 	snapshotters []Snapshotter
 	snapLock     sync.Mutex
 
+	// acks records the revisions of the xDS resources
+	// that the snapshot versions hold.
+	acks *xds.ACKTracker
+
 	logrus.FieldLogger
 }
 
-// NewSnapshotHandler returns an instance of SnapshotHandler.
-func NewSnapshotHandler(resources []ResourceCache, logger logrus.FieldLogger) *SnapshotHandler {
+// NewSnapshotHandler returns an instance of SnapshotHandler. The snapshots
+// generated when the DAG is rebuilt are recorded in acks, which can be nil.
+func NewSnapshotHandler(resources []ResourceCache, acks *xds.ACKTracker, logger logrus.FieldLogger) *SnapshotHandler {
 	return &SnapshotHandler{
 		resources:   parseResources(resources),
+		acks:        acks,
 		FieldLogger: logger,
 	}
 }
@@ -61,21 +68,26 @@ func (s *SnapshotHandler) AddSnapshotter(snap Snapshotter) {
 }
 
 // Refresh is called when the EndpointsTranslator updates values
-// in its cache.
+// in its cache, or the ListenerCache removes listeners.
 func (s *SnapshotHandler) Refresh() {
-	s.generateNewSnapshot()
+	s.generateNewSnapshot(false)
 }
 
 // OnChange is called when the DAG is rebuilt and a new snapshot is needed.
+// It is called after the xDS caches have been rebuilt, so the snapshot holds
+// the revision of the xDS resources that the caches are being updated to.
 func (s *SnapshotHandler) OnChange(root *dag.DAG) {
-	s.generateNewSnapshot()
+	s.generateNewSnapshot(true)
 }
 
 // generateNewSnapshot creates a new snapshot against
 // the Contour XDS caches.
-func (s *SnapshotHandler) generateNewSnapshot() {
+func (s *SnapshotHandler) generateNewSnapshot(dagChanged bool) {
 	// Generate new snapshot version.
 	version := s.newSnapshotVersion()
+	if dagChanged {
+		s.acks.SnapshotUpdated(version)
+	}
 
 	// Convert caches to envoy xDS Resources.
 	resources := map[envoy_resource_v3.Type][]envoy_types.Resource{
@@ -19,6 +19,7 @@ import (
 
 	envoy_cluster_v3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
 	resource "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	contour_api_v1alpha1 "github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
 	"github.com/projectcontour/contour/internal/contour"
 	"github.com/projectcontour/contour/internal/dag"
 	"github.com/projectcontour/contour/internal/envoy"
@@ -33,6 +34,22 @@ type ClusterCache struct {
 	mu     sync.Mutex
 	values map[string]*envoy_cluster_v3.Cluster
 	contour.Cond
+
+	// StatNameFormat sets the format of the names
+	// that Envoy uses for the stats of clusters.
+	StatNameFormat contour_api_v1alpha1.ClusterStatNameFormat
+
+	// MaxStatNameLength caps the length of the names that Envoy
+	// uses for the stats of clusters. Zero means no limit.
+	MaxStatNameLength int
+
+	// DNSResolverSettings holds the DNS settings of the
+	// clusters that resolve their endpoints with DNS.
+	DNSResolverSettings envoy_v3.DNSResolverSettings
+
+	// CircuitBreakerDefaults holds the circuit breaker thresholds
+	// of the clusters whose services don't set them.
+	CircuitBreakerDefaults envoy_v3.CircuitBreakerSettings
 }
 
 // Update replaces the contents of the cache with the supplied map.
@@ -76,6 +93,32 @@ func (c *ClusterCache) Query(names []string) []proto.Message {
 
 func (*ClusterCache) TypeURL() string { return resource.ClusterType }
 
+// statName returns the alt_stat_name of the cluster, or an
+// empty string if Envoy should use the cluster name for stats.
+func (c *ClusterCache) statName(cluster *envoy_cluster_v3.Cluster) string {
+	name := cluster.AltStatName
+	if name == "" || c.StatNameFormat == contour_api_v1alpha1.ClusterNameClusterStatNameFormat {
+		name = cluster.Name
+	}
+	if c.MaxStatNameLength > 0 {
+		name = envoy.TruncateStatName(c.MaxStatNameLength, name)
+	}
+	if name == cluster.Name {
+		return ""
+	}
+	return name
+}
+
+// StatName returns the name that Envoy uses
+// for the stats of the cluster.
+func (c *ClusterCache) StatName(cluster *dag.Cluster) string {
+	envoyCluster := envoy_v3.Cluster(cluster)
+	if name := c.statName(envoyCluster); name != "" {
+		return name
+	}
+	return envoyCluster.Name
+}
+
 func (c *ClusterCache) OnChange(root *dag.DAG) {
 	clusters := map[string]*envoy_cluster_v3.Cluster{}
 
@@ -86,6 +129,13 @@ func (c *ClusterCache) OnChange(root *dag.DAG) {
 		}
 	}
 
+	for _, cluster := range root.GetAggregateClusters() {
+		name := envoy.AggregateClusterName(cluster)
+		if _, ok := clusters[name]; !ok {
+			clusters[name] = envoy_v3.AggregateCluster(cluster)
+		}
+	}
+
 	for name, ec := range root.GetExtensionClusters() {
 		if _, ok := clusters[name]; !ok {
 			clusters[name] = envoy_v3.ExtensionCluster(ec)
@@ -99,5 +149,11 @@ func (c *ClusterCache) OnChange(root *dag.DAG) {
 		}
 	}
 
+	for _, cluster := range clusters {
+		cluster.AltStatName = c.statName(cluster)
+		envoy_v3.ApplyDNSResolverSettings(cluster, c.DNSResolverSettings)
+		envoy_v3.ApplyCircuitBreakerDefaults(cluster, c.CircuitBreakerDefaults)
+	}
+
 	c.Update(clusters)
 }
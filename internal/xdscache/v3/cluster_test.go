@@ -21,8 +21,12 @@ import (
 	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoy_extensions_upstream_http_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/upstreams/http/v3"
 	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
+	contour_api_v1alpha1 "github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
+	"github.com/projectcontour/contour/internal/envoy"
 	envoy_v3 "github.com/projectcontour/contour/internal/envoy/v3"
 	"github.com/projectcontour/contour/internal/protobuf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
 	"google.golang.org/protobuf/types/known/durationpb"
@@ -826,6 +830,131 @@ func TestClusterVisit(t *testing.T) {
 	}
 }
 
+func TestClusterCacheStatNames(t *testing.T) {
+	objs := []any{
+		&networking_v1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "kuard",
+				Namespace: "default",
+			},
+			Spec: networking_v1.IngressSpec{
+				DefaultBackend: backend("kuard-with-a-long-name", 443),
+			},
+		},
+		service("default", "kuard-with-a-long-name",
+			v1.ServicePort{
+				Protocol:   "TCP",
+				Port:       443,
+				TargetPort: intstr.FromInt(8443),
+			},
+		),
+	}
+
+	tests := map[string]struct {
+		format    contour_api_v1alpha1.ClusterStatNameFormat
+		maxLength int
+		want      string
+	}{
+		"default": {
+			want: "default_kuard-with-a-long-name_443",
+		},
+		"service": {
+			format: contour_api_v1alpha1.ServiceClusterStatNameFormat,
+			want:   "default_kuard-with-a-long-name_443",
+		},
+		"cluster": {
+			format: contour_api_v1alpha1.ClusterNameClusterStatNameFormat,
+			want:   "",
+		},
+		"service, max length": {
+			format:    contour_api_v1alpha1.ServiceClusterStatNameFormat,
+			maxLength: 24,
+			want:      envoy.TruncateStatName(24, "default_kuard-with-a-long-name_443"),
+		},
+		"cluster, max length": {
+			format:    contour_api_v1alpha1.ClusterNameClusterStatNameFormat,
+			maxLength: 24,
+			want:      envoy.TruncateStatName(24, "default/kuard-with-a-long-name/443/da39a3ee5e"),
+		},
+		"max length not exceeded": {
+			maxLength: 60,
+			want:      "default_kuard-with-a-long-name_443",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			cc := ClusterCache{
+				StatNameFormat:    tc.format,
+				MaxStatNameLength: tc.maxLength,
+			}
+			dag := buildDAG(t, objs...)
+			cc.OnChange(dag)
+
+			require.Len(t, cc.values, 1)
+			for _, c := range cc.values {
+				assert.Equal(t, tc.want, c.AltStatName)
+				if tc.maxLength > 0 {
+					assert.LessOrEqual(t, len(c.AltStatName), tc.maxLength)
+				}
+
+				// StatName returns the name that Envoy uses for
+				// the stats of the cluster, which is its name if
+				// it has no alt_stat_name.
+				wantStatName := c.AltStatName
+				if wantStatName == "" {
+					wantStatName = c.Name
+				}
+				for _, cluster := range dag.GetClusters() {
+					assert.Equal(t, wantStatName, cc.StatName(cluster))
+				}
+			}
+		})
+	}
+}
+
+func TestClusterCacheCircuitBreakerDefaults(t *testing.T) {
+	objs := []any{
+		&networking_v1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "kuard",
+				Namespace: "default",
+			},
+			Spec: networking_v1.IngressSpec{
+				DefaultBackend: backend("kuard", 80),
+			},
+		},
+		serviceWithAnnotations("default", "kuard",
+			map[string]string{
+				"projectcontour.io/max-connections": "100",
+			},
+			v1.ServicePort{
+				Protocol:   "TCP",
+				Port:       80,
+				TargetPort: intstr.FromInt(8080),
+			},
+		),
+	}
+
+	cc := ClusterCache{
+		CircuitBreakerDefaults: envoy_v3.CircuitBreakerSettings{
+			MaxConnections: 10000,
+			MaxRequests:    20000,
+		},
+	}
+	cc.OnChange(buildDAG(t, objs...))
+
+	require.Len(t, cc.values, 1)
+	for _, c := range cc.values {
+		protobuf.ExpectEqual(t, &envoy_cluster_v3.CircuitBreakers{
+			Thresholds: []*envoy_cluster_v3.CircuitBreakers_Thresholds{{
+				MaxConnections: wrapperspb.UInt32(100),
+				MaxRequests:    wrapperspb.UInt32(20000),
+			}},
+		}, c.CircuitBreakers)
+	}
+}
+
 func service(ns, name string, ports ...v1.ServicePort) *v1.Service {
 	return serviceWithAnnotations(ns, name, nil, ports...)
 }
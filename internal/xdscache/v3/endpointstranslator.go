@@ -17,7 +17,9 @@ import (
 	"fmt"
 	"sort"
 	"sync"
+	"time"
 
+	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoy_endpoint_v3 "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
 	resource "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
 	"github.com/projectcontour/contour/internal/contour"
@@ -29,6 +31,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"google.golang.org/protobuf/proto"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/cache"
 )
@@ -40,6 +43,13 @@ type LoadBalancingEndpoint = envoy_endpoint_v3.LbEndpoint
 // resources by matching the given service port to the given v1.Endpoints.
 // ep may be nil, in which case, the result is also nil.
 func RecalculateEndpoints(port, healthPort v1.ServicePort, ep *v1.Endpoints) []*LoadBalancingEndpoint {
+	return recalculateEndpoints(port, healthPort, ep, nil)
+}
+
+// recalculateEndpoints is like RecalculateEndpoints, but also sets the
+// metadata of each LoadBalancingEndpoint from its address, if metadata
+// is not nil.
+func recalculateEndpoints(port, healthPort v1.ServicePort, ep *v1.Endpoints, metadata func(v1.EndpointAddress) *envoy_core_v3.Metadata) []*LoadBalancingEndpoint {
 	if ep == nil {
 		return nil
 	}
@@ -78,7 +88,11 @@ func RecalculateEndpoints(port, healthPort v1.ServicePort, ep *v1.Endpoints) []*
 
 			for _, a := range addresses {
 				addr := envoy_v3.SocketAddress(a.IP, int(p.Port))
-				lb = append(lb, envoy_v3.LBEndpoint(addr))
+				lbEndpoint := envoy_v3.LBEndpoint(addr)
+				if metadata != nil {
+					lbEndpoint.Metadata = metadata(a)
+				}
+				lb = append(lb, lbEndpoint)
 			}
 		}
 	}
@@ -108,6 +122,163 @@ type EndpointsCache struct {
 
 	// Cache of endpoints, indexed by name.
 	endpoints map[types.NamespacedName]*v1.Endpoints
+
+	// Endpoints that were removed from their Endpoints but are
+	// kept until their deregistration delay expires, indexed by
+	// the name of their Endpoints.
+	deregistering map[types.NamespacedName]map[endpointKey]*deregisteringEndpoint
+
+	// Cache of pod labels, indexed by pod name. The labels are
+	// added to the endpoint metadata of ServiceClusters with subsets.
+	pods map[types.NamespacedName]map[string]string
+}
+
+// endpointKey identifies an endpoint by its address and port.
+type endpointKey struct {
+	ip       string
+	portName string
+	port     int32
+	protocol v1.Protocol
+}
+
+// deregisteringEndpoint is an endpoint that was removed from its
+// Endpoints, and is kept until its deregistration delay expires.
+type deregisteringEndpoint struct {
+	address v1.EndpointAddress
+	port    v1.EndpointPort
+	expires time.Time
+}
+
+// readyEndpoints returns the ready endpoints of ep, by address and port.
+func readyEndpoints(ep *v1.Endpoints) map[endpointKey]*deregisteringEndpoint {
+	ready := map[endpointKey]*deregisteringEndpoint{}
+	if ep == nil {
+		return ready
+	}
+
+	for _, s := range ep.Subsets {
+		for _, a := range s.Addresses {
+			for _, p := range s.Ports {
+				ready[endpointKey{ip: a.IP, portName: p.Name, port: p.Port, protocol: p.Protocol}] = &deregisteringEndpoint{
+					address: a,
+					port:    p,
+				}
+			}
+		}
+	}
+
+	return ready
+}
+
+// deregister records the ready endpoints of old that aren't ready in
+// current, so that they're kept until the delay expires. Endpoints
+// that are ready again stop being deregistered.
+func (c *EndpointsCache) deregister(name types.NamespacedName, old, current *v1.Endpoints, delay time.Duration) {
+	if delay <= 0 {
+		return
+	}
+
+	ready := readyEndpoints(current)
+	pending := c.deregistering[name]
+	if pending == nil {
+		pending = map[endpointKey]*deregisteringEndpoint{}
+	}
+
+	for key := range pending {
+		if _, ok := ready[key]; ok {
+			delete(pending, key)
+		}
+	}
+
+	expires := time.Now().Add(delay)
+	for key, ep := range readyEndpoints(old) {
+		if _, ok := ready[key]; ok {
+			continue
+		}
+		if _, ok := pending[key]; ok {
+			continue
+		}
+		ep.expires = expires
+		pending[key] = ep
+	}
+
+	if len(pending) == 0 {
+		delete(c.deregistering, name)
+		return
+	}
+	c.deregistering[name] = pending
+}
+
+// withDeregistering returns a copy of ep that also includes the
+// endpoints that are being deregistered. ep may be nil.
+func withDeregistering(ep *v1.Endpoints, pending map[endpointKey]*deregisteringEndpoint) *v1.Endpoints {
+	if len(pending) == 0 {
+		return ep
+	}
+
+	if ep == nil {
+		ep = &v1.Endpoints{}
+	} else {
+		ep = ep.DeepCopy()
+	}
+
+	keys := make([]endpointKey, 0, len(pending))
+	for key := range pending {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].ip != keys[j].ip {
+			return keys[i].ip < keys[j].ip
+		}
+		return keys[i].port < keys[j].port
+	})
+
+	for _, key := range keys {
+		ep.Subsets = append(ep.Subsets, v1.EndpointSubset{
+			Addresses: []v1.EndpointAddress{pending[key].address},
+			Ports:     []v1.EndpointPort{pending[key].port},
+		})
+	}
+
+	return ep
+}
+
+// ExpireDeregistering removes the endpoints whose deregistration delay
+// expired at or before now. Any ServiceClusters that are backed by their
+// Services become stale. Returns whether any ServiceClusters use the
+// removed endpoints, and when the next deregistration delay expires,
+// which is zero if no endpoints are being deregistered.
+func (c *EndpointsCache) ExpireDeregistering(now time.Time) (bool, time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var stale bool
+	var next time.Time
+
+	for name, pending := range c.deregistering {
+		var expired bool
+		for key, ep := range pending {
+			if !ep.expires.After(now) {
+				delete(pending, key)
+				expired = true
+				continue
+			}
+			if next.IsZero() || ep.expires.Before(next) {
+				next = ep.expires
+			}
+		}
+
+		if len(pending) == 0 {
+			delete(c.deregistering, name)
+		}
+
+		if affected := c.services[name]; expired && len(affected) > 0 {
+			c.stale = append(c.stale, affected...)
+			stale = true
+		}
+	}
+
+	return stale, next
 }
 
 // Recalculate regenerates all the ClusterLoadAssignments from the
@@ -133,11 +304,23 @@ func (c *EndpointsCache) Recalculate() map[string]*envoy_endpoint_v3.ClusterLoad
 			Policy:      nil,
 		}
 
+		// Envoy requires priority levels to be contiguous, so
+		// when the cluster has failover services, each service
+		// keeps its priority level even if it has no endpoints.
+		var prioritized bool
+		for _, w := range cluster.Services {
+			prioritized = prioritized || w.Priority > 0
+		}
+
 		// Look up each service, and if we have endpoints for that service,
 		// attach them as a new LocalityEndpoints resource2.
 		for _, w := range cluster.Services {
 			n := types.NamespacedName{Namespace: w.ServiceNamespace, Name: w.ServiceName}
-			if lb := RecalculateEndpoints(w.ServicePort, w.HealthPort, c.endpoints[n]); lb != nil {
+			ep := withDeregistering(c.endpoints[n], c.deregistering[n])
+			metadata := func(a v1.EndpointAddress) *envoy_core_v3.Metadata {
+				return c.endpointMetadata(a, w.ServiceName, cluster.SubsetKeys)
+			}
+			if lb := recalculateEndpoints(w.ServicePort, w.HealthPort, ep, metadata); lb != nil || prioritized {
 				// Append the new set of endpoints. Users are allowed to set the load
 				// balancing weight to 0, which we reflect to Envoy as nil in order to
 				// assign no load to that locality.
@@ -146,6 +329,7 @@ func (c *EndpointsCache) Recalculate() map[string]*envoy_endpoint_v3.ClusterLoad
 					&LocalityEndpoints{
 						LbEndpoints:         lb,
 						LoadBalancingWeight: protobuf.UInt32OrNil(w.Weight),
+						Priority:            w.Priority,
 					},
 				)
 			}
@@ -158,6 +342,43 @@ func (c *EndpointsCache) Recalculate() map[string]*envoy_endpoint_v3.ClusterLoad
 	return assignments
 }
 
+// endpointMetadata returns the metadata of the endpoint for address a
+// of the given service. Endpoints backed by a pod carry the Kubernetes
+// metadata that access logs can refer to and, for clusters with subsets,
+// the subset load balancing metadata for the given label keys.
+func (c *EndpointsCache) endpointMetadata(a v1.EndpointAddress, service string, keys []string) *envoy_core_v3.Metadata {
+	if a.TargetRef == nil || a.TargetRef.Kind != "Pod" {
+		return nil
+	}
+
+	metadata := envoy_v3.EndpointMetadata(a.TargetRef.Namespace, a.TargetRef.Name, service)
+	if subset := c.subsetMetadata(a, keys); subset != nil {
+		for ns, fields := range subset.FilterMetadata {
+			metadata.FilterMetadata[ns] = fields
+		}
+	}
+
+	return metadata
+}
+
+// subsetMetadata returns the subset load balancing metadata for the
+// given label keys, from the labels of the pod that backs address a.
+func (c *EndpointsCache) subsetMetadata(a v1.EndpointAddress, keys []string) *envoy_core_v3.Metadata {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	podLabels := c.pods[types.NamespacedName{Namespace: a.TargetRef.Namespace, Name: a.TargetRef.Name}]
+	subset := map[string]string{}
+	for _, k := range keys {
+		if v, ok := podLabels[k]; ok {
+			subset[k] = v
+		}
+	}
+
+	return envoy_v3.SubsetMetadata(subset)
+}
+
 // SetClusters replaces the cache of ServiceCluster resources. All
 // the added clusters will be marked stale.
 func (c *EndpointsCache) SetClusters(clusters []*dag.ServiceCluster) error {
@@ -200,14 +421,16 @@ func (c *EndpointsCache) SetClusters(clusters []*dag.ServiceCluster) error {
 }
 
 // UpdateEndpoint adds ep to the cache, or replaces it if it is
-// already cached. Any ServiceClusters that are backed by a Service
-// that ep belongs become stale. Returns a boolean indicating whether
-// any ServiceClusters use ep or not.
-func (c *EndpointsCache) UpdateEndpoint(ep *v1.Endpoints) bool {
+// already cached. Ready endpoints that were removed are kept until
+// the deregistration delay expires. Any ServiceClusters that are
+// backed by a Service that ep belongs become stale. Returns a
+// boolean indicating whether any ServiceClusters use ep or not.
+func (c *EndpointsCache) UpdateEndpoint(ep *v1.Endpoints, deregistrationDelay time.Duration) bool {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	name := k8s.NamespacedNameOf(ep)
+	c.deregister(name, c.endpoints[name], ep, deregistrationDelay)
 	c.endpoints[name] = ep.DeepCopy()
 
 	// If any service clusters include this endpoint, mark them
@@ -220,14 +443,16 @@ func (c *EndpointsCache) UpdateEndpoint(ep *v1.Endpoints) bool {
 	return false
 }
 
-// DeleteEndpoint deletes ep from the cache. Any ServiceClusters
+// DeleteEndpoint deletes ep from the cache. Its ready endpoints are
+// kept until the deregistration delay expires. Any ServiceClusters
 // that are backed by a Service that ep belongs become stale. Returns
 // a boolean indicating whether any ServiceClusters use ep or not.
-func (c *EndpointsCache) DeleteEndpoint(ep *v1.Endpoints) bool {
+func (c *EndpointsCache) DeleteEndpoint(ep *v1.Endpoints, deregistrationDelay time.Duration) bool {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	name := k8s.NamespacedNameOf(ep)
+	c.deregister(name, c.endpoints[name], nil, deregistrationDelay)
 	delete(c.endpoints, name)
 
 	// If any service clusters include this endpoint, mark them
@@ -240,6 +465,84 @@ func (c *EndpointsCache) DeleteEndpoint(ep *v1.Endpoints) bool {
 	return false
 }
 
+// UpdatePod caches the labels of pod, or replaces them if they
+// are already cached. Any ServiceClusters with subsets that have
+// endpoints backed by pod become stale. Returns a boolean indicating
+// whether any ServiceClusters with subsets use pod or not.
+func (c *EndpointsCache) UpdatePod(pod *v1.Pod) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	name := k8s.NamespacedNameOf(pod)
+	if old, ok := c.pods[name]; ok && labels.Equals(old, pod.Labels) {
+		return false
+	}
+	c.pods[name] = labels.Merge(nil, pod.Labels)
+
+	return c.podStale(name)
+}
+
+// DeletePod deletes the labels of pod from the cache. Any
+// ServiceClusters with subsets that have endpoints backed by pod
+// become stale. Returns a boolean indicating whether any
+// ServiceClusters with subsets use pod or not.
+func (c *EndpointsCache) DeletePod(pod *v1.Pod) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	name := k8s.NamespacedNameOf(pod)
+	if _, ok := c.pods[name]; !ok {
+		return false
+	}
+	delete(c.pods, name)
+
+	return c.podStale(name)
+}
+
+// podStale marks the ServiceClusters with subsets that have
+// endpoints backed by the named pod stale. Returns whether
+// there are any.
+func (c *EndpointsCache) podStale(pod types.NamespacedName) bool {
+	var stale bool
+	for name, clusters := range c.services {
+		var subsets []*dag.ServiceCluster
+		for _, cluster := range clusters {
+			if len(cluster.SubsetKeys) > 0 {
+				subsets = append(subsets, cluster)
+			}
+		}
+		if len(subsets) == 0 {
+			continue
+		}
+
+		if backedByPod(withDeregistering(c.endpoints[name], c.deregistering[name]), pod) {
+			c.stale = append(c.stale, subsets...)
+			stale = true
+		}
+	}
+
+	return stale
+}
+
+// backedByPod returns whether any of the ready addresses
+// of ep are backed by the named pod. ep may be nil.
+func backedByPod(ep *v1.Endpoints, pod types.NamespacedName) bool {
+	if ep == nil {
+		return false
+	}
+
+	for _, s := range ep.Subsets {
+		for _, a := range s.Addresses {
+			if a.TargetRef != nil && a.TargetRef.Kind == "Pod" &&
+				a.TargetRef.Namespace == pod.Namespace && a.TargetRef.Name == pod.Name {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
 // NewEndpointsTranslator allocates a new endpoints translator.
 func NewEndpointsTranslator(log logrus.FieldLogger) *EndpointsTranslator {
 	return &EndpointsTranslator{
@@ -247,19 +550,27 @@ func NewEndpointsTranslator(log logrus.FieldLogger) *EndpointsTranslator {
 		FieldLogger: log,
 		entries:     map[string]*envoy_endpoint_v3.ClusterLoadAssignment{},
 		cache: EndpointsCache{
-			stale:     nil,
-			services:  map[types.NamespacedName][]*dag.ServiceCluster{},
-			endpoints: map[types.NamespacedName]*v1.Endpoints{},
+			stale:         nil,
+			services:      map[types.NamespacedName][]*dag.ServiceCluster{},
+			endpoints:     map[types.NamespacedName]*v1.Endpoints{},
+			deregistering: map[types.NamespacedName]map[endpointKey]*deregisteringEndpoint{},
+			pods:          map[types.NamespacedName]map[string]string{},
 		},
 	}
 }
 
 // A EndpointsTranslator translates Kubernetes Endpoints objects into Envoy
-// ClusterLoadAssignment resources.
+// ClusterLoadAssignment resources. The labels of Pods are added to the
+// endpoint metadata of clusters with subsets.
 type EndpointsTranslator struct {
 	// Observer notifies when the endpoints cache has been updated.
 	Observer contour.Observer
 
+	// DeregistrationDelay is how long endpoints that are removed
+	// from their Endpoints are kept in the ClusterLoadAssignments.
+	// If zero, endpoints are removed immediately.
+	DeregistrationDelay time.Duration
+
 	contour.Cond
 	logrus.FieldLogger
 
@@ -267,6 +578,11 @@ type EndpointsTranslator struct {
 
 	mu      sync.Mutex // Protects entries.
 	entries map[string]*envoy_endpoint_v3.ClusterLoadAssignment
+
+	timerMu sync.Mutex // Protects deregistrationTimer.
+	// deregistrationTimer fires when the next deregistration
+	// delay expires, or is nil if none are pending.
+	deregistrationTimer *time.Timer
 }
 
 // Merge combines the given entries with the existing entries in the
@@ -281,6 +597,47 @@ func (e *EndpointsTranslator) Merge(entries map[string]*envoy_endpoint_v3.Cluste
 	}
 }
 
+// scheduleDeregistration starts a timer to remove endpoints when their
+// deregistration delay expires, unless one is already running. Since
+// every delay is the same, a running timer fires before any delay that
+// starts later expires.
+func (e *EndpointsTranslator) scheduleDeregistration() {
+	if e.DeregistrationDelay <= 0 {
+		return
+	}
+
+	e.timerMu.Lock()
+	defer e.timerMu.Unlock()
+
+	if e.deregistrationTimer == nil {
+		e.deregistrationTimer = time.AfterFunc(e.DeregistrationDelay, e.expireDeregistering)
+	}
+}
+
+// expireDeregistering removes the endpoints whose deregistration delay
+// has expired, and restarts the timer if any are still pending.
+func (e *EndpointsTranslator) expireDeregistering() {
+	stale, next := e.cache.ExpireDeregistering(time.Now())
+
+	e.timerMu.Lock()
+	e.deregistrationTimer = nil
+	if !next.IsZero() {
+		e.deregistrationTimer = time.AfterFunc(time.Until(next), e.expireDeregistering)
+	}
+	e.timerMu.Unlock()
+
+	if !stale {
+		return
+	}
+
+	e.Debug("endpoint deregistration delay expired, recalculating ClusterLoadAssignments")
+	e.Merge(e.cache.Recalculate())
+	e.Notify()
+	if e.Observer != nil {
+		e.Observer.Refresh()
+	}
+}
+
 // OnChange observes DAG rebuild events.
 func (e *EndpointsTranslator) OnChange(root *dag.DAG) {
 	clusters := []*dag.ServiceCluster{}
@@ -351,7 +708,11 @@ func equal(a, b map[string]*envoy_endpoint_v3.ClusterLoadAssignment) bool {
 func (e *EndpointsTranslator) OnAdd(obj any, isInInitialList bool) {
 	switch obj := obj.(type) {
 	case *v1.Endpoints:
-		if !e.cache.UpdateEndpoint(obj) {
+		// Adding Endpoints that are already cached, when the
+		// informer relists them, can remove ready endpoints too.
+		used := e.cache.UpdateEndpoint(obj, e.DeregistrationDelay)
+		e.scheduleDeregistration()
+		if !used {
 			return
 		}
 
@@ -361,6 +722,17 @@ func (e *EndpointsTranslator) OnAdd(obj any, isInInitialList bool) {
 		if e.Observer != nil {
 			e.Observer.Refresh()
 		}
+	case *v1.Pod:
+		if !e.cache.UpdatePod(obj) {
+			return
+		}
+
+		e.WithField("pod", k8s.NamespacedNameOf(obj)).Debug("Pod is in use by a ServiceCluster subset, recalculating ClusterLoadAssignments")
+		e.Merge(e.cache.Recalculate())
+		e.Notify()
+		if e.Observer != nil {
+			e.Observer.Refresh()
+		}
 	default:
 		e.Errorf("OnAdd unexpected type %T: %#v", obj, obj)
 	}
@@ -388,7 +760,9 @@ func (e *EndpointsTranslator) OnUpdate(oldObj, newObj any) {
 			return
 		}
 
-		if !e.cache.UpdateEndpoint(newObj) {
+		used := e.cache.UpdateEndpoint(newObj, e.DeregistrationDelay)
+		e.scheduleDeregistration()
+		if !used {
 			return
 		}
 
@@ -398,6 +772,19 @@ func (e *EndpointsTranslator) OnUpdate(oldObj, newObj any) {
 		if e.Observer != nil {
 			e.Observer.Refresh()
 		}
+	case *v1.Pod:
+		// Only the labels of pods are used, and the
+		// cache ignores updates that don't change them.
+		if !e.cache.UpdatePod(newObj) {
+			return
+		}
+
+		e.WithField("pod", k8s.NamespacedNameOf(newObj)).Debug("Pod is in use by a ServiceCluster subset, recalculating ClusterLoadAssignments")
+		e.Merge(e.cache.Recalculate())
+		e.Notify()
+		if e.Observer != nil {
+			e.Observer.Refresh()
+		}
 	default:
 		e.Errorf("OnUpdate unexpected type %T: %#v", newObj, newObj)
 	}
@@ -406,7 +793,9 @@ func (e *EndpointsTranslator) OnUpdate(oldObj, newObj any) {
 func (e *EndpointsTranslator) OnDelete(obj any) {
 	switch obj := obj.(type) {
 	case *v1.Endpoints:
-		if !e.cache.DeleteEndpoint(obj) {
+		used := e.cache.DeleteEndpoint(obj, e.DeregistrationDelay)
+		e.scheduleDeregistration()
+		if !used {
 			return
 		}
 
@@ -416,6 +805,17 @@ func (e *EndpointsTranslator) OnDelete(obj any) {
 		if e.Observer != nil {
 			e.Observer.Refresh()
 		}
+	case *v1.Pod:
+		if !e.cache.DeletePod(obj) {
+			return
+		}
+
+		e.WithField("pod", k8s.NamespacedNameOf(obj)).Debug("Pod was in use by a ServiceCluster subset, recalculating ClusterLoadAssignments")
+		e.Merge(e.cache.Recalculate())
+		e.Notify()
+		if e.Observer != nil {
+			e.Observer.Refresh()
+		}
 	case cache.DeletedFinalStateUnknown:
 		e.OnDelete(obj.Obj) // recurse into ourselves with the tombstoned value
 	default:
@@ -15,6 +15,7 @@ package v3
 
 import (
 	"testing"
+	"time"
 
 	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoy_endpoint_v3 "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
@@ -27,6 +28,7 @@ import (
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/wrapperspb"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestEndpointsTranslatorContents(t *testing.T) {
@@ -641,6 +643,170 @@ func TestEndpointsTranslatorUpdateEndpoints(t *testing.T) {
 	}
 }
 
+func TestEndpointsTranslatorDeregistrationDelay(t *testing.T) {
+	clusters := []*dag.ServiceCluster{
+		{
+			ClusterName: "default/simple",
+			Services: []dag.WeightedService{
+				{
+					Weight:           1,
+					ServiceName:      "simple",
+					ServiceNamespace: "default",
+					ServicePort:      v1.ServicePort{},
+				},
+			},
+		},
+	}
+
+	both := endpoints("default", "simple", v1.EndpointSubset{
+		Addresses: addresses("192.168.183.24", "192.168.183.25"),
+		Ports: ports(
+			port("", 8080),
+		),
+	})
+	one := endpoints("default", "simple", v1.EndpointSubset{
+		Addresses: addresses("192.168.183.25"),
+		Ports: ports(
+			port("", 8080),
+		),
+	})
+
+	et := NewEndpointsTranslator(fixture.NewTestLogger(t))
+	et.DeregistrationDelay = time.Hour
+	require.NoError(t, et.cache.SetClusters(clusters))
+	et.OnAdd(both, false)
+
+	// The removed endpoint is kept until its delay expires.
+	et.OnUpdate(both, one)
+	protobuf.ExpectEqual(t, []proto.Message{
+		&envoy_endpoint_v3.ClusterLoadAssignment{
+			ClusterName: "default/simple",
+			Endpoints: envoy_v3.WeightedEndpoints(1,
+				envoy_v3.SocketAddress("192.168.183.25", 8080),
+				envoy_v3.SocketAddress("192.168.183.24", 8080),
+			),
+		},
+	}, et.Contents())
+
+	stale, next := et.cache.ExpireDeregistering(time.Now())
+	assert.False(t, stale)
+	assert.False(t, next.IsZero())
+
+	stale, next = et.cache.ExpireDeregistering(time.Now().Add(2 * time.Hour))
+	assert.True(t, stale)
+	assert.True(t, next.IsZero())
+
+	et.Merge(et.cache.Recalculate())
+	protobuf.ExpectEqual(t, []proto.Message{
+		&envoy_endpoint_v3.ClusterLoadAssignment{
+			ClusterName: "default/simple",
+			Endpoints:   envoy_v3.WeightedEndpoints(1, envoy_v3.SocketAddress("192.168.183.25", 8080)),
+		},
+	}, et.Contents())
+
+	// An endpoint that is ready again is no longer deregistered.
+	et.OnUpdate(one, both)
+	et.OnUpdate(both, one)
+	et.OnUpdate(one, both)
+	stale, next = et.cache.ExpireDeregistering(time.Now().Add(2 * time.Hour))
+	assert.False(t, stale)
+	assert.True(t, next.IsZero())
+}
+
+func TestEndpointsTranslatorDeregistrationDelayExpires(t *testing.T) {
+	clusters := []*dag.ServiceCluster{
+		{
+			ClusterName: "default/simple",
+			Services: []dag.WeightedService{
+				{
+					Weight:           1,
+					ServiceName:      "simple",
+					ServiceNamespace: "default",
+					ServicePort:      v1.ServicePort{},
+				},
+			},
+		},
+	}
+
+	ep := endpoints("default", "simple", v1.EndpointSubset{
+		Addresses: addresses("192.168.183.24"),
+		Ports: ports(
+			port("", 8080),
+		),
+	})
+
+	et := NewEndpointsTranslator(fixture.NewTestLogger(t))
+	et.DeregistrationDelay = 200 * time.Millisecond
+	require.NoError(t, et.cache.SetClusters(clusters))
+	et.OnAdd(ep, false)
+	et.OnDelete(ep)
+
+	protobuf.ExpectEqual(t, []proto.Message{
+		&envoy_endpoint_v3.ClusterLoadAssignment{
+			ClusterName: "default/simple",
+			Endpoints:   envoy_v3.WeightedEndpoints(1, envoy_v3.SocketAddress("192.168.183.24", 8080)),
+		},
+	}, et.Contents())
+
+	require.Eventually(t, func() bool {
+		return proto.Equal(&envoy_endpoint_v3.ClusterLoadAssignment{ClusterName: "default/simple"}, et.Contents()[0])
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestEndpointsTranslatorDeregistrationDelayExpiresOnAdd(t *testing.T) {
+	clusters := []*dag.ServiceCluster{
+		{
+			ClusterName: "default/simple",
+			Services: []dag.WeightedService{
+				{
+					Weight:           1,
+					ServiceName:      "simple",
+					ServiceNamespace: "default",
+					ServicePort:      v1.ServicePort{},
+				},
+			},
+		},
+	}
+
+	both := endpoints("default", "simple", v1.EndpointSubset{
+		Addresses: addresses("192.168.183.24", "192.168.183.25"),
+		Ports: ports(
+			port("", 8080),
+		),
+	})
+	one := endpoints("default", "simple", v1.EndpointSubset{
+		Addresses: addresses("192.168.183.25"),
+		Ports: ports(
+			port("", 8080),
+		),
+	})
+
+	et := NewEndpointsTranslator(fixture.NewTestLogger(t))
+	et.DeregistrationDelay = 200 * time.Millisecond
+	require.NoError(t, et.cache.SetClusters(clusters))
+	et.OnAdd(both, false)
+
+	// The informer relists the Endpoints after an endpoint was removed.
+	et.OnAdd(one, false)
+
+	protobuf.ExpectEqual(t, []proto.Message{
+		&envoy_endpoint_v3.ClusterLoadAssignment{
+			ClusterName: "default/simple",
+			Endpoints: envoy_v3.WeightedEndpoints(1,
+				envoy_v3.SocketAddress("192.168.183.25", 8080),
+				envoy_v3.SocketAddress("192.168.183.24", 8080),
+			),
+		},
+	}, et.Contents())
+
+	require.Eventually(t, func() bool {
+		return proto.Equal(&envoy_endpoint_v3.ClusterLoadAssignment{
+			ClusterName: "default/simple",
+			Endpoints:   envoy_v3.WeightedEndpoints(1, envoy_v3.SocketAddress("192.168.183.25", 8080)),
+		}, et.Contents()[0])
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
 func TestEndpointsTranslatorRecomputeClusterLoadAssignment(t *testing.T) {
 	tests := map[string]struct {
 		cluster dag.ServiceCluster
@@ -972,6 +1138,229 @@ func TestEndpointsTranslatorDefaultWeightedService(t *testing.T) {
 	protobuf.ExpectEqual(t, want, et.Contents())
 }
 
+// Test that failover services are added at their priority level,
+// and that a priority level without endpoints is kept so that the
+// levels stay contiguous.
+func TestEndpointsTranslatorFailoverService(t *testing.T) {
+	et := NewEndpointsTranslator(fixture.NewTestLogger(t))
+	clusters := []*dag.ServiceCluster{
+		{
+			ClusterName: "default/primary;default/secondary;default/tertiary",
+			Services: []dag.WeightedService{
+				{
+					Weight:           1,
+					ServiceName:      "primary",
+					ServiceNamespace: "default",
+					ServicePort:      v1.ServicePort{},
+				},
+				{
+					Weight:           1,
+					ServiceName:      "secondary",
+					ServiceNamespace: "default",
+					ServicePort:      v1.ServicePort{},
+					Priority:         1,
+				},
+				{
+					Weight:           1,
+					ServiceName:      "tertiary",
+					ServiceNamespace: "default",
+					ServicePort:      v1.ServicePort{},
+					Priority:         2,
+				},
+			},
+		},
+	}
+
+	require.NoError(t, et.cache.SetClusters(clusters))
+
+	epSubset := v1.EndpointSubset{
+		Addresses: addresses("192.168.183.24"),
+		Ports:     ports(port("", 8080)),
+	}
+
+	et.OnAdd(endpoints("default", "secondary", epSubset), false)
+	et.OnAdd(endpoints("default", "tertiary", epSubset), false)
+
+	want := []proto.Message{
+		&envoy_endpoint_v3.ClusterLoadAssignment{
+			ClusterName: "default/primary;default/secondary;default/tertiary",
+			Endpoints: []*envoy_endpoint_v3.LocalityLbEndpoints{
+				{
+					LoadBalancingWeight: wrapperspb.UInt32(1),
+				},
+				{
+					LbEndpoints:         []*envoy_endpoint_v3.LbEndpoint{envoy_v3.LBEndpoint(envoy_v3.SocketAddress("192.168.183.24", 8080))},
+					LoadBalancingWeight: wrapperspb.UInt32(1),
+					Priority:            1,
+				},
+				{
+					LbEndpoints:         []*envoy_endpoint_v3.LbEndpoint{envoy_v3.LBEndpoint(envoy_v3.SocketAddress("192.168.183.24", 8080))},
+					LoadBalancingWeight: wrapperspb.UInt32(1),
+					Priority:            2,
+				},
+			},
+		},
+	}
+
+	protobuf.ExpectEqual(t, want, et.Contents())
+}
+
+func TestEndpointsTranslatorSubsetService(t *testing.T) {
+	et := NewEndpointsTranslator(fixture.NewTestLogger(t))
+	clusters := []*dag.ServiceCluster{
+		{
+			ClusterName: "default/kuard|version",
+			Services: []dag.WeightedService{
+				{
+					Weight:           1,
+					ServiceName:      "kuard",
+					ServiceNamespace: "default",
+					ServicePort:      v1.ServicePort{},
+				},
+			},
+			SubsetKeys: []string{"version"},
+		},
+	}
+
+	require.NoError(t, et.cache.SetClusters(clusters))
+
+	pod := func(name string, labels map[string]string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Labels:    labels,
+			},
+		}
+	}
+
+	address := func(ip, pod string) v1.EndpointAddress {
+		return v1.EndpointAddress{
+			IP: ip,
+			TargetRef: &v1.ObjectReference{
+				Kind:      "Pod",
+				Namespace: "default",
+				Name:      pod,
+			},
+		}
+	}
+
+	subsetEndpoint := func(ip, pod string, labels map[string]string) *envoy_endpoint_v3.LbEndpoint {
+		lb := envoy_v3.LBEndpoint(envoy_v3.SocketAddress(ip, 8080))
+		lb.Metadata = envoy_v3.EndpointMetadata("default", pod, "kuard")
+		lb.Metadata.FilterMetadata[envoy_v3.SubsetMetadataNamespace] = envoy_v3.SubsetMetadata(labels).FilterMetadata[envoy_v3.SubsetMetadataNamespace]
+		return lb
+	}
+
+	et.OnAdd(pod("kuard-a", map[string]string{"app": "kuard", "version": "v1"}), false)
+	et.OnAdd(pod("kuard-b", map[string]string{"app": "kuard", "version": "v2"}), false)
+	et.OnAdd(endpoints("default", "kuard", v1.EndpointSubset{
+		Addresses: []v1.EndpointAddress{
+			address("192.168.183.24", "kuard-a"),
+			address("192.168.183.25", "kuard-b"),
+			{IP: "192.168.183.26"},
+		},
+		Ports: ports(port("", 8080)),
+	}), false)
+
+	want := []proto.Message{
+		&envoy_endpoint_v3.ClusterLoadAssignment{
+			ClusterName: "default/kuard|version",
+			Endpoints: []*envoy_endpoint_v3.LocalityLbEndpoints{{
+				LbEndpoints: []*envoy_endpoint_v3.LbEndpoint{
+					subsetEndpoint("192.168.183.24", "kuard-a", map[string]string{"version": "v1"}),
+					subsetEndpoint("192.168.183.25", "kuard-b", map[string]string{"version": "v2"}),
+					envoy_v3.LBEndpoint(envoy_v3.SocketAddress("192.168.183.26", 8080)),
+				},
+				LoadBalancingWeight: wrapperspb.UInt32(1),
+			}},
+		},
+	}
+
+	protobuf.ExpectEqual(t, want, et.Contents())
+
+	// Changing the labels of a pod updates the metadata of its endpoint.
+	et.OnUpdate(
+		pod("kuard-b", map[string]string{"app": "kuard", "version": "v2"}),
+		pod("kuard-b", map[string]string{"app": "kuard", "version": "v3"}),
+	)
+
+	want = []proto.Message{
+		&envoy_endpoint_v3.ClusterLoadAssignment{
+			ClusterName: "default/kuard|version",
+			Endpoints: []*envoy_endpoint_v3.LocalityLbEndpoints{{
+				LbEndpoints: []*envoy_endpoint_v3.LbEndpoint{
+					subsetEndpoint("192.168.183.24", "kuard-a", map[string]string{"version": "v1"}),
+					subsetEndpoint("192.168.183.25", "kuard-b", map[string]string{"version": "v3"}),
+					envoy_v3.LBEndpoint(envoy_v3.SocketAddress("192.168.183.26", 8080)),
+				},
+				LoadBalancingWeight: wrapperspb.UInt32(1),
+			}},
+		},
+	}
+
+	protobuf.ExpectEqual(t, want, et.Contents())
+
+	// Pods that don't back any endpoints of clusters
+	// with subsets don't cause a recalculation.
+	assert.False(t, et.cache.UpdatePod(pod("other", map[string]string{"version": "v1"})))
+	assert.False(t, et.cache.UpdatePod(pod("kuard-a", map[string]string{"app": "kuard", "version": "v1"})))
+	assert.True(t, et.cache.DeletePod(pod("kuard-a", nil)))
+}
+
+func TestEndpointsTranslatorEndpointMetadata(t *testing.T) {
+	et := NewEndpointsTranslator(fixture.NewTestLogger(t))
+	clusters := []*dag.ServiceCluster{
+		{
+			ClusterName: "default/kuard",
+			Services: []dag.WeightedService{
+				{
+					Weight:           1,
+					ServiceName:      "kuard",
+					ServiceNamespace: "default",
+					ServicePort:      v1.ServicePort{},
+				},
+			},
+		},
+	}
+
+	require.NoError(t, et.cache.SetClusters(clusters))
+
+	et.OnAdd(endpoints("default", "kuard", v1.EndpointSubset{
+		Addresses: []v1.EndpointAddress{
+			{
+				IP: "192.168.183.24",
+				TargetRef: &v1.ObjectReference{
+					Kind:      "Pod",
+					Namespace: "default",
+					Name:      "kuard-a",
+				},
+			},
+			{IP: "192.168.183.25"},
+		},
+		Ports: ports(port("", 8080)),
+	}), false)
+
+	// Only endpoints backed by a pod carry the Kubernetes metadata.
+	podEndpoint := envoy_v3.LBEndpoint(envoy_v3.SocketAddress("192.168.183.24", 8080))
+	podEndpoint.Metadata = envoy_v3.EndpointMetadata("default", "kuard-a", "kuard")
+
+	want := []proto.Message{
+		&envoy_endpoint_v3.ClusterLoadAssignment{
+			ClusterName: "default/kuard",
+			Endpoints: []*envoy_endpoint_v3.LocalityLbEndpoints{{
+				LbEndpoints: []*envoy_endpoint_v3.LbEndpoint{
+					podEndpoint,
+					envoy_v3.LBEndpoint(envoy_v3.SocketAddress("192.168.183.25", 8080)),
+				},
+				LoadBalancingWeight: wrapperspb.UInt32(1),
+			}},
+		},
+	}
+
+	protobuf.ExpectEqual(t, want, et.Contents())
+}
+
 func TestEqual(t *testing.T) {
 	tests := map[string]struct {
 		a, b map[string]*envoy_endpoint_v3.ClusterLoadAssignment
@@ -14,8 +14,11 @@
 package v3
 
 import (
+	"fmt"
 	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	envoy_accesslog_v3 "github.com/envoyproxy/go-control-plane/envoy/config/accesslog/v3"
 	envoy_listener_v3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
@@ -32,6 +35,7 @@ import (
 	"github.com/projectcontour/contour/internal/timeout"
 	"github.com/projectcontour/contour/pkg/config"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 	"k8s.io/apimachinery/pkg/types"
 )
 
@@ -99,6 +103,10 @@ type ListenerConfig struct {
 	// AccessLogLevel defines the logging level for access log.
 	AccessLogLevel contour_api_v1alpha1.AccessLogLevel
 
+	// AccessLogFilter optionally selects the requests of the access
+	// log level that are access logged.
+	AccessLogFilter *envoy_v3.AccessLogFilterConfig
+
 	// Timeouts holds Listener timeout settings.
 	Timeouts contourconfig.Timeouts
 
@@ -122,10 +130,56 @@ type ListenerConfig struct {
 	// If specified, the listener will use the exact connection balancer.
 	ConnectionBalancer string
 
+	// DrainType defines when Envoy drains the connections of the listeners.
+	// If not specified, Envoy's default drain type is used.
+	DrainType contour_api_v1alpha1.ListenerDrainType
+
+	// RemovalDelay defines how long listeners that are no longer in
+	// the DAG are kept, so that Envoy can warm their replacements
+	// before draining them. If zero, listeners are removed immediately.
+	RemovalDelay time.Duration
+
 	// MaxRequestsPerConnection defines the max number of requests per connection before which the connection is closed.
 	// if not specified there is no limit set.
 	MaxRequestsPerConnection *uint32
 
+	// HTTP2Settings holds the settings of downstream HTTP/2 connections.
+	// Secure virtual hosts can override the maximum concurrent streams.
+	HTTP2Settings envoy_v3.HTTP2Settings
+
+	// SocketOptions holds the socket options of the HTTP and
+	// HTTPS listeners.
+	SocketOptions envoy_v3.SocketOptions
+
+	// HTTPFilters are additional HTTP filters that are added to
+	// the filter chains of the HTTP connection managers.
+	HTTPFilters []envoy_v3.AdditionalHTTPFilter
+
+	// Compression holds the settings of the compressor filter
+	// of the HTTP connection managers.
+	Compression envoy_v3.CompressionSettings
+
+	// HTTPConnectionManagerSettings holds the additional settings
+	// of the HTTP connection managers.
+	HTTPConnectionManagerSettings envoy_v3.HTTPConnectionManagerSettings
+
+	// TimeoutReplies holds the replies that are sent when a timeout
+	// expires. HTTPProxy routes can override them.
+	TimeoutReplies envoy_v3.TimeoutReplies
+
+	// ResponseMap customizes the local replies of the
+	// HTTP connection managers by their status code.
+	ResponseMap []envoy_v3.ResponseMapping
+
+	// HealthSettings customizes the readiness and liveness
+	// probes that the static health listener serves.
+	HealthSettings envoy_v3.HealthSettings
+
+	// MetricsBearerToken is the bearer token that scrapers must
+	// present to the static stats listeners. If empty, no token
+	// is required.
+	MetricsBearerToken string
+
 	// PerConnectionBufferLimitBytes defines the soft limit on size of the listener’s new connection read and write buffers
 	// If unspecified, an implementation defined default is applied (1MiB).
 	PerConnectionBufferLimitBytes *uint32
@@ -141,6 +195,19 @@ type ListenerConfig struct {
 	// TracingConfig optionally configures the tracing collector Service to be
 	// used.
 	TracingConfig *TracingConfig
+
+	// GRPCAccessLogConfig optionally configures the gRPC access log
+	// service that access logs are streamed to, in addition to the
+	// file based access logs.
+	GRPCAccessLogConfig *GRPCAccessLogConfig
+
+	// CaptureConfig optionally configures the HTTP tap filter to
+	// capture requests and responses on routes with capture enabled.
+	CaptureConfig *envoy_v3.CaptureConfig
+
+	// SecretBackend provides the certificates and keys served by
+	// TLS listeners. If not set, Kubernetes Secrets are served.
+	SecretBackend envoy_v3.SecretBackend
 }
 
 type ExtensionServiceConfig struct {
@@ -159,6 +226,21 @@ type TracingConfig struct {
 	MaxPathTagLength uint32
 
 	CustomTags []*CustomTag
+
+	// Zipkin configures the Zipkin tracer, instead of the
+	// OpenTelemetry one, if not nil.
+	Zipkin *envoy_v3.ZipkinTracingConfig
+
+	// ForceTraceClientCIDRs are the address ranges of the clients
+	// that can force the tracing of their requests, if not empty.
+	ForceTraceClientCIDRs []string
+}
+
+type GRPCAccessLogConfig struct {
+	ExtensionServiceConfig
+	LogName             string
+	BufferSizeBytes     uint32
+	BufferFlushInterval time.Duration
 }
 
 type CustomTag struct {
@@ -228,26 +310,172 @@ func (lvc *ListenerConfig) accesslogFields() contour_api_v1alpha1.AccessLogJSONF
 	return contour_api_v1alpha1.DefaultAccessLogJSONFields
 }
 
-func (lvc *ListenerConfig) newInsecureAccessLog() []*envoy_accesslog_v3.AccessLog {
-	switch lvc.accesslogType() {
-	case string(config.JSONAccessLog):
-		return envoy_v3.FileAccessLogJSON(lvc.httpAccessLog(), lvc.accesslogFields(), lvc.AccessLogFormatterExtensions, lvc.AccessLogLevel)
-	default:
-		return envoy_v3.FileAccessLogEnvoy(lvc.httpAccessLog(), lvc.AccessLogFormatString, lvc.AccessLogFormatterExtensions, lvc.AccessLogLevel)
+// newInsecureAccessLog returns the access log of the HTTP listener
+// that serves vhosts. Since the virtual hosts share the listener,
+// the requests to the virtual hosts that have an access log policy
+// are told apart by their authority.
+func (lvc *ListenerConfig) newInsecureAccessLog(vhosts []*dag.VirtualHost) []*envoy_accesslog_v3.AccessLog {
+	var overridden, disabled []envoy_v3.AuthorityMatch
+	for _, vh := range vhosts {
+		if vh.AccessLogPolicy == nil {
+			continue
+		}
+		match := accessLogAuthorityMatch(vh, vhosts)
+		overridden = append(overridden, match)
+		if vh.AccessLogPolicy.Disabled {
+			disabled = append(disabled, match)
+		}
+	}
+
+	logs := envoy_v3.ExcludeAccessLogsByAuthority(lvc.filterHTTPAccessLogs(lvc.newFileAccessLog(lvc.httpAccessLog(), nil)), overridden)
+	for _, vh := range vhosts {
+		if vh.AccessLogPolicy == nil || vh.AccessLogPolicy.Disabled {
+			continue
+		}
+		logs = append(logs, envoy_v3.FilterAccessLogsByAuthority(
+			lvc.filterHTTPAccessLogs(lvc.newFileAccessLog(lvc.httpAccessLog(), vh.AccessLogPolicy)),
+			accessLogAuthorityMatch(vh, vhosts),
+		)...)
 	}
+	logs = append(logs, envoy_v3.ExcludeAccessLogsByAuthority(
+		lvc.filterHTTPAccessLogs(envoy_v3.HTTPGRPCAccessLog(lvc.grpcAccessLogConfig(), lvc.AccessLogLevel)),
+		disabled,
+	)...)
+
+	return logs
 }
 
-func (lvc *ListenerConfig) newSecureAccessLog() []*envoy_accesslog_v3.AccessLog {
-	switch lvc.accesslogType() {
-	case "json":
-		return envoy_v3.FileAccessLogJSON(lvc.httpsAccessLog(), lvc.accesslogFields(), lvc.AccessLogFormatterExtensions, lvc.AccessLogLevel)
+// newSecureAccessLog returns the access log of a filter chain of the
+// HTTPS listener, which is overridden by policy if it is not nil.
+func (lvc *ListenerConfig) newSecureAccessLog(policy *dag.AccessLogPolicy) []*envoy_accesslog_v3.AccessLog {
+	if policy != nil && policy.Disabled {
+		return nil
+	}
+
+	return lvc.filterHTTPAccessLogs(append(
+		lvc.newFileAccessLog(lvc.httpsAccessLog(), policy),
+		envoy_v3.HTTPGRPCAccessLog(lvc.grpcAccessLogConfig(), lvc.AccessLogLevel)...,
+	))
+}
+
+// filterHTTPAccessLogs adds the configured access log filter to the
+// HTTP access logs. The requests whose tracing the client forced are
+// logged regardless of the access log level and filter.
+func (lvc *ListenerConfig) filterHTTPAccessLogs(logs []*envoy_accesslog_v3.AccessLog) []*envoy_accesslog_v3.AccessLog {
+	logs = envoy_v3.FilterAccessLogs(logs, lvc.AccessLogFilter)
+	if len(lvc.forceTraceClientCIDRs()) > 0 {
+		logs = envoy_v3.LogForcedTraces(logs)
+	}
+	return logs
+}
+
+// forceTraceClientCIDRs returns the address ranges of the clients
+// that can force tracing, or nil if tracing is not configured.
+func (lvc *ListenerConfig) forceTraceClientCIDRs() []string {
+	if lvc.TracingConfig == nil {
+		return nil
+	}
+	return lvc.TracingConfig.ForceTraceClientCIDRs
+}
+
+// accessLogAuthorityMatch returns the authority match of the requests
+// to vhost. The requests that a wildcard virtual host covers exclude
+// the ones to the other virtual hosts in vhosts, since Envoy routes
+// them to the more specific virtual host.
+func accessLogAuthorityMatch(vhost *dag.VirtualHost, vhosts []*dag.VirtualHost) envoy_v3.AuthorityMatch {
+	match := envoy_v3.AuthorityMatch{Host: vhost.Name}
+	if !strings.HasPrefix(vhost.Name, "*.") {
+		return match
+	}
+
+	for _, vh := range vhosts {
+		if label, domain, ok := strings.Cut(vh.Name, "."); ok && label != "*" && "*."+domain == vhost.Name {
+			match.Excluded = append(match.Excluded, vh.Name)
+		}
+	}
+	return match
+}
+
+// newInsecureTCPAccessLog returns the access log of TCP proxies
+// on the HTTP (non TLS) listener.
+func (lvc *ListenerConfig) newInsecureTCPAccessLog() []*envoy_accesslog_v3.AccessLog {
+	return envoy_v3.FilterAccessLogs(append(
+		lvc.newFileAccessLog(lvc.httpAccessLog(), nil),
+		envoy_v3.TCPGRPCAccessLog(lvc.grpcAccessLogConfig(), lvc.AccessLogLevel)...,
+	), lvc.AccessLogFilter)
+}
+
+// newSecureTCPAccessLog returns the access log of TCP proxies
+// on the HTTPS listener.
+func (lvc *ListenerConfig) newSecureTCPAccessLog() []*envoy_accesslog_v3.AccessLog {
+	return envoy_v3.FilterAccessLogs(append(
+		lvc.newFileAccessLog(lvc.httpsAccessLog(), nil),
+		envoy_v3.TCPGRPCAccessLog(lvc.grpcAccessLogConfig(), lvc.AccessLogLevel)...,
+	), lvc.AccessLogFilter)
+}
+
+// newFileAccessLog returns the file access log written to path. The
+// settings of policy, if not nil, replace the configured ones.
+func (lvc *ListenerConfig) newFileAccessLog(path string, policy *dag.AccessLogPolicy) []*envoy_accesslog_v3.AccessLog {
+	logging := &contour_api_v1alpha1.EnvoyLogging{
+		AccessLogFormat:       contour_api_v1alpha1.AccessLogType(lvc.accesslogType()),
+		AccessLogFormatString: lvc.AccessLogFormatString,
+		AccessLogJSONFields:   lvc.accesslogFields(),
+	}
+	extensions := lvc.AccessLogFormatterExtensions
+
+	if policy != nil {
+		if policy.Disabled {
+			return nil
+		}
+		if policy.Format != "" {
+			logging.AccessLogFormat = policy.Format
+		}
+		if policy.FormatString != "" {
+			logging.AccessLogFormatString = policy.FormatString
+		}
+		if len(policy.JSONFields) > 0 {
+			logging.AccessLogJSONFields = policy.JSONFields
+		}
+		extensions = logging.AccessLogFormatterExtensions()
+	}
+
+	switch logging.AccessLogFormat {
+	case contour_api_v1alpha1.JSONAccessLog:
+		return envoy_v3.FileAccessLogJSON(path, logging.AccessLogJSONFields, extensions, lvc.AccessLogLevel)
 	default:
-		return envoy_v3.FileAccessLogEnvoy(lvc.httpsAccessLog(), lvc.AccessLogFormatString, lvc.AccessLogFormatterExtensions, lvc.AccessLogLevel)
+		return envoy_v3.FileAccessLogEnvoy(path, logging.AccessLogFormatString, extensions, lvc.AccessLogLevel)
+	}
+}
+
+// grpcAccessLogConfig returns the configuration of the gRPC
+// access log service, or nil if none is configured.
+func (lvc *ListenerConfig) grpcAccessLogConfig() *envoy_v3.GRPCAccessLogConfig {
+	if lvc.GRPCAccessLogConfig == nil {
+		return nil
+	}
+
+	return &envoy_v3.GRPCAccessLogConfig{
+		ExtensionService:    lvc.GRPCAccessLogConfig.ExtensionService,
+		SNI:                 lvc.GRPCAccessLogConfig.SNI,
+		Timeout:             lvc.GRPCAccessLogConfig.Timeout,
+		LogName:             lvc.GRPCAccessLogConfig.LogName,
+		BufferSizeBytes:     lvc.GRPCAccessLogConfig.BufferSizeBytes,
+		BufferFlushInterval: lvc.GRPCAccessLogConfig.BufferFlushInterval,
 	}
 }
 
 // minTLSVersion returns the requested minimum TLS protocol
 // version or envoy_tls_v3.TlsParameters_TLSv1_2 if not configured.
+// secretBackend returns the configured secret backend or the
+// Kubernetes secret backend if not configured.
+func (lvc *ListenerConfig) secretBackend() envoy_v3.SecretBackend {
+	if lvc.SecretBackend == nil {
+		return envoy_v3.KubernetesSecretBackend{}
+	}
+	return lvc.SecretBackend
+}
+
 func (lvc *ListenerConfig) minTLSVersion() envoy_tls_v3.TlsParameters_TlsProtocol {
 	minTLSVersion := envoy_v3.ParseTLSVersion(lvc.MinimumTLSVersion)
 	if minTLSVersion > envoy_tls_v3.TlsParameters_TLSv1_2 {
@@ -262,10 +490,33 @@ type ListenerCache struct {
 	values       map[string]*envoy_listener_v3.Listener
 	staticValues map[string]*envoy_listener_v3.Listener
 
+	// Listeners that are no longer in the DAG, and are kept
+	// until their removal delay expires, indexed by name.
+	retiring map[string]*retiringListener
+	// removalTimer fires when the next removal delay
+	// expires, or is nil if no listeners are retiring.
+	removalTimer *time.Timer
+
+	// Observer notifies when listeners are removed
+	// after their removal delay expires.
+	Observer contour.Observer
+
+	// metricsConfig and healthConfig configure
+	// the static stats listeners.
+	metricsConfig contour_api_v1alpha1.MetricsConfig
+	healthConfig  contour_api_v1alpha1.HealthConfig
+
 	Config ListenerConfig
 	contour.Cond
 }
 
+// retiringListener is a listener that is no longer in the DAG,
+// and is kept until its removal delay expires.
+type retiringListener struct {
+	listener *envoy_listener_v3.Listener
+	expires  time.Time
+}
+
 // NewListenerCache returns an instance of a ListenerCache
 func NewListenerCache(
 	listenerConfig ListenerConfig,
@@ -274,13 +525,13 @@ func NewListenerCache(
 	adminPort int,
 ) *ListenerCache {
 	listenerCache := &ListenerCache{
-		Config:       listenerConfig,
-		staticValues: map[string]*envoy_listener_v3.Listener{},
+		Config:        listenerConfig,
+		staticValues:  map[string]*envoy_listener_v3.Listener{},
+		metricsConfig: metricsConfig,
+		healthConfig:  healthConfig,
 	}
 
-	for _, l := range envoy_v3.StatsListeners(metricsConfig, healthConfig) {
-		listenerCache.staticValues[l.Name] = l
-	}
+	listenerCache.setStatsListeners()
 
 	// If the port is not zero, allow the read-only options from the
 	// Envoy admin webpage to be served.
@@ -292,6 +543,33 @@ func NewListenerCache(
 	return listenerCache
 }
 
+// SetConfig replaces the configuration of the listeners built
+// from the DAG. It must not be called concurrently with OnChange.
+func (c *ListenerCache) SetConfig(config ListenerConfig) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.Config = config
+}
+
+// SetMetricsBearerToken replaces the bearer token required by the
+// static stats listeners, e.g. when the token file is rotated.
+func (c *ListenerCache) SetMetricsBearerToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.Config.MetricsBearerToken = token
+	c.setStatsListeners()
+	c.Cond.Notify()
+}
+
+// setStatsListeners builds the static stats listeners.
+func (c *ListenerCache) setStatsListeners() {
+	for _, l := range envoy_v3.StatsListeners(c.metricsConfig, c.healthConfig, c.Config.HealthSettings, c.Config.MetricsBearerToken) {
+		c.staticValues[l.Name] = l
+	}
+}
+
 // Update replaces the contents of the cache with the supplied map.
 func (c *ListenerCache) Update(v map[string]*envoy_listener_v3.Listener) {
 	c.mu.Lock()
@@ -301,6 +579,97 @@ func (c *ListenerCache) Update(v map[string]*envoy_listener_v3.Listener) {
 	c.Cond.Notify()
 }
 
+// retire adds the listeners that were removed from the DAG to listeners,
+// until the removal delay expires. Envoy warms and starts serving their
+// replacements, e.g. when the port of a Gateway listener changes, before
+// it drains the removed listeners. Removed listeners can't be kept if
+// their address is used by a listener in the DAG.
+func (c *ListenerCache) retire(listeners map[string]*envoy_listener_v3.Listener) {
+	if c.Config.RemovalDelay <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.retiring == nil {
+		c.retiring = map[string]*retiringListener{}
+	}
+
+	expires := time.Now().Add(c.Config.RemovalDelay)
+	for name, l := range c.values {
+		if _, ok := listeners[name]; ok {
+			continue
+		}
+		if _, ok := c.retiring[name]; ok {
+			continue
+		}
+		c.retiring[name] = &retiringListener{
+			listener: l,
+			expires:  expires,
+		}
+	}
+
+	addresses := map[string]bool{}
+	for _, l := range listeners {
+		addresses[listenerAddress(l)] = true
+	}
+
+	for name, r := range c.retiring {
+		if _, ok := listeners[name]; ok || addresses[listenerAddress(r.listener)] {
+			delete(c.retiring, name)
+			continue
+		}
+		listeners[name] = r.listener
+	}
+
+	if len(c.retiring) > 0 && c.removalTimer == nil {
+		c.removalTimer = time.AfterFunc(c.Config.RemovalDelay, c.removeRetired)
+	}
+}
+
+// removeRetired removes the retiring listeners whose removal delay
+// has expired, and restarts the timer if any are still retiring.
+func (c *ListenerCache) removeRetired() {
+	c.mu.Lock()
+
+	c.removalTimer = nil
+	now := time.Now()
+	var next time.Time
+	var removed bool
+	for name, r := range c.retiring {
+		if !r.expires.After(now) {
+			delete(c.retiring, name)
+			delete(c.values, name)
+			removed = true
+			continue
+		}
+		if next.IsZero() || r.expires.Before(next) {
+			next = r.expires
+		}
+	}
+
+	if !next.IsZero() {
+		c.removalTimer = time.AfterFunc(time.Until(next), c.removeRetired)
+	}
+
+	if removed {
+		c.Cond.Notify()
+	}
+
+	c.mu.Unlock()
+
+	if removed && c.Observer != nil {
+		c.Observer.Refresh()
+	}
+}
+
+// listenerAddress returns the socket address of l as a string.
+func listenerAddress(l *envoy_listener_v3.Listener) string {
+	sa := l.GetAddress().GetSocketAddress()
+	return fmt.Sprintf("%s:%d", sa.GetAddress(), sa.GetPortValue())
+}
+
 // Contents returns a copy of the cache's contents.
 func (c *ListenerCache) Contents() []proto.Message {
 	c.mu.Lock()
@@ -362,7 +731,7 @@ func (c *ListenerCache) OnChange(root *dag.DAG) {
 				listener.Port,
 				cfg.PerConnectionBufferLimitBytes,
 				nil,
-				envoy_v3.TCPProxy(listener.Name, listener.TCPProxy, cfg.newInsecureAccessLog()),
+				envoy_v3.TCPProxy(listener.Name, listener.TCPProxy, cfg.newInsecureTCPAccessLog()),
 			)
 
 			continue
@@ -376,7 +745,7 @@ func (c *ListenerCache) OnChange(root *dag.DAG) {
 				DefaultFilters().
 				RouteConfigName(httpRouteConfigName(listener)).
 				MetricsPrefix(listener.Name).
-				AccessLoggers(cfg.newInsecureAccessLog()).
+				AccessLoggers(cfg.newInsecureAccessLog(listener.VirtualHosts)).
 				RequestTimeout(cfg.Timeouts.Request).
 				ConnectionIdleTimeout(cfg.Timeouts.ConnectionIdle).
 				StreamIdleTimeout(cfg.Timeouts.StreamIdle).
@@ -388,8 +757,15 @@ func (c *ListenerCache) OnChange(root *dag.DAG) {
 				ServerHeaderTransformation(cfg.ServerHeaderTransformation).
 				NumTrustedHops(cfg.XffNumTrustedHops).
 				MaxRequestsPerConnection(cfg.MaxRequestsPerConnection).
+				HTTP2Settings(cfg.HTTP2Settings).
+				LocalReplyConfig(envoy_v3.ResponseMapConfig(envoy_v3.TimeoutReplyConfig(cfg.TimeoutReplies, listener.VirtualHosts), cfg.ResponseMap)).
+				AdditionalFilters(cfg.HTTPFilters).
+				Compression(cfg.Compression).
+				Settings(cfg.HTTPConnectionManagerSettings).
 				Tracing(envoy_v3.TracingConfig(envoyTracingConfig(cfg.TracingConfig))).
+				InternalAddresses(cfg.forceTraceClientCIDRs()).
 				AddFilter(envoy_v3.GlobalRateLimitFilter(envoyGlobalRateLimitConfig(cfg.RateLimitConfig))).
+				AddFilter(envoy_v3.CaptureFilter(cfg.CaptureConfig)).
 				AddFilter(httpGlobalExternalAuthConfig(cfg.GlobalExternalAuthConfig)).
 				EnableWebsockets(listener.EnableWebsockets).
 				Get()
@@ -433,6 +809,11 @@ func (c *ListenerCache) OnChange(root *dag.DAG) {
 					authFilter = envoy_v3.FilterExternalAuthz(vh.ExternalAuthorization)
 				}
 
+				http2Settings := cfg.HTTP2Settings
+				if vh.HTTP2MaxConcurrentStreams != nil {
+					http2Settings.MaxConcurrentStreams = vh.HTTP2MaxConcurrentStreams
+				}
+
 				// Create a uniquely named HTTP connection manager for
 				// this vhost, so that the SNI name the client requests
 				// only grants access to that host. See RFC 6066 for
@@ -448,7 +829,7 @@ func (c *ListenerCache) OnChange(root *dag.DAG) {
 					AddFilter(envoy_v3.FilterJWTAuth(vh.JWTProviders)).
 					RouteConfigName(httpsRouteConfigName(listener, vh.VirtualHost.Name)).
 					MetricsPrefix(listener.Name).
-					AccessLoggers(cfg.newSecureAccessLog()).
+					AccessLoggers(cfg.newSecureAccessLog(vh.AccessLogPolicy)).
 					RequestTimeout(cfg.Timeouts.Request).
 					ConnectionIdleTimeout(cfg.Timeouts.ConnectionIdle).
 					StreamIdleTimeout(cfg.Timeouts.StreamIdle).
@@ -460,9 +841,16 @@ func (c *ListenerCache) OnChange(root *dag.DAG) {
 					ServerHeaderTransformation(cfg.ServerHeaderTransformation).
 					NumTrustedHops(cfg.XffNumTrustedHops).
 					Tracing(envoy_v3.TracingConfig(envoyTracingConfig(cfg.TracingConfig))).
+					InternalAddresses(cfg.forceTraceClientCIDRs()).
 					AddFilter(envoy_v3.GlobalRateLimitFilter(envoyGlobalRateLimitConfig(cfg.RateLimitConfig))).
+					AddFilter(envoy_v3.CaptureFilter(cfg.CaptureConfig)).
 					ForwardClientCertificate(forwardClientCertificate).
 					MaxRequestsPerConnection(cfg.MaxRequestsPerConnection).
+					HTTP2Settings(http2Settings).
+					LocalReplyConfig(envoy_v3.ResponseMapConfig(envoy_v3.TimeoutReplyConfig(cfg.TimeoutReplies, []*dag.VirtualHost{&vh.VirtualHost}), cfg.ResponseMap)).
+					AdditionalFilters(cfg.HTTPFilters).
+					Compression(cfg.Compression).
+					Settings(cfg.HTTPConnectionManagerSettings).
 					EnableWebsockets(listener.EnableWebsockets).
 					Get()
 
@@ -470,7 +858,7 @@ func (c *ListenerCache) OnChange(root *dag.DAG) {
 
 				alpnProtos = envoy_v3.ProtoNamesForVersions(cfg.DefaultHTTPVersions...)
 			} else {
-				filters = envoy_v3.Filters(envoy_v3.TCPProxy(listener.Name, vh.TCPProxy, cfg.newSecureAccessLog()))
+				filters = envoy_v3.Filters(envoy_v3.TCPProxy(listener.Name, vh.TCPProxy, cfg.newSecureTCPAccessLog()))
 
 				// Do not offer ALPN for TCP proxying, since
 				// the protocols will be provided by the TCP
@@ -485,7 +873,7 @@ func (c *ListenerCache) OnChange(root *dag.DAG) {
 				vers := max(cfg.minTLSVersion(), envoy_v3.ParseTLSVersion(vh.MinTLSVersion))
 
 				downstreamTLS = envoy_v3.DownstreamTLSContext(
-					vh.Secret,
+					cfg.secretBackend().SecretConfig(vh.Secret),
 					vers,
 					cfg.CipherSuites,
 					vh.DownstreamValidation,
@@ -503,7 +891,7 @@ func (c *ListenerCache) OnChange(root *dag.DAG) {
 				// Construct the downstreamTLSContext passing the configured fallbackCertificate. The TLS minProtocolVersion will use
 				// the value defined in the Contour Configuration file if defined.
 				downstreamTLS = envoy_v3.DownstreamTLSContext(
-					vh.FallbackCertificate,
+					cfg.secretBackend().SecretConfig(vh.FallbackCertificate),
 					cfg.minTLSVersion(),
 					cfg.CipherSuites,
 					vh.DownstreamValidation,
@@ -514,7 +902,7 @@ func (c *ListenerCache) OnChange(root *dag.DAG) {
 					DefaultFilters().
 					RouteConfigName(fallbackCertRouteConfigName(listener)).
 					MetricsPrefix(listener.Name).
-					AccessLoggers(cfg.newSecureAccessLog()).
+					AccessLoggers(cfg.newSecureAccessLog(nil)).
 					RequestTimeout(cfg.Timeouts.Request).
 					ConnectionIdleTimeout(cfg.Timeouts.ConnectionIdle).
 					StreamIdleTimeout(cfg.Timeouts.StreamIdle).
@@ -526,9 +914,16 @@ func (c *ListenerCache) OnChange(root *dag.DAG) {
 					ServerHeaderTransformation(cfg.ServerHeaderTransformation).
 					NumTrustedHops(cfg.XffNumTrustedHops).
 					Tracing(envoy_v3.TracingConfig(envoyTracingConfig(cfg.TracingConfig))).
+					InternalAddresses(cfg.forceTraceClientCIDRs()).
 					AddFilter(envoy_v3.GlobalRateLimitFilter(envoyGlobalRateLimitConfig(cfg.RateLimitConfig))).
+					AddFilter(envoy_v3.CaptureFilter(cfg.CaptureConfig)).
 					ForwardClientCertificate(forwardClientCertificate).
 					MaxRequestsPerConnection(cfg.MaxRequestsPerConnection).
+					HTTP2Settings(cfg.HTTP2Settings).
+					LocalReplyConfig(envoy_v3.ResponseMapConfig(envoy_v3.TimeoutReplyConfig(cfg.TimeoutReplies, fallbackCertVirtualHosts(listener)), cfg.ResponseMap)).
+					AdditionalFilters(cfg.HTTPFilters).
+					Compression(cfg.Compression).
+					Settings(cfg.HTTPConnectionManagerSettings).
 					EnableWebsockets(listener.EnableWebsockets).
 					Get()
 
@@ -562,6 +957,22 @@ func (c *ListenerCache) OnChange(root *dag.DAG) {
 		}
 	}
 
+	// 2. drain type
+	if cfg.DrainType == contour_api_v1alpha1.ModifyOnlyListenerDrainType {
+		for _, listener := range listeners {
+			listener.DrainType = envoy_listener_v3.Listener_MODIFY_ONLY
+		}
+	}
+
+	// 3. socket options
+	for _, listener := range listeners {
+		listener.SocketOptions = envoy_v3.ListenerSocketOptions(cfg.SocketOptions, listener.Address.GetSocketAddress().GetAddress())
+		if cfg.SocketOptions.Freebind {
+			listener.Freebind = wrapperspb.Bool(true)
+		}
+	}
+
+	c.retire(listeners)
 	c.Update(listeners)
 }
 
@@ -611,6 +1022,7 @@ func envoyTracingConfig(config *TracingConfig) *envoy_v3.EnvoyTracingConfig {
 		OverallSampling:  config.OverallSampling,
 		MaxPathTagLength: config.MaxPathTagLength,
 		CustomTags:       envoyTracingConfigCustomTag(config.CustomTags),
+		Zipkin:           config.Zipkin,
 	}
 }
 
@@ -630,6 +1042,19 @@ func envoyTracingConfigCustomTag(tags []*CustomTag) []*envoy_v3.CustomTag {
 	return customTags
 }
 
+// fallbackCertVirtualHosts returns the virtual hosts of the secure
+// virtual hosts of the listener that have a fallback certificate,
+// whose routes are served by the fallback certificate filter chain.
+func fallbackCertVirtualHosts(listener *dag.Listener) []*dag.VirtualHost {
+	var vhosts []*dag.VirtualHost
+	for _, vh := range listener.SecureVirtualHosts {
+		if vh.FallbackCertificate != nil {
+			vhosts = append(vhosts, &vh.VirtualHost)
+		}
+	}
+	return vhosts
+}
+
 func proxyProtocol(useProxy bool) []*envoy_listener_v3.ListenerFilter {
 	if useProxy {
 		return envoy_v3.ListenerFilters(
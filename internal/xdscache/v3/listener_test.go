@@ -23,12 +23,14 @@ import (
 	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoy_listener_v3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
 	ratelimit_config_v3 "github.com/envoyproxy/go-control-plane/envoy/config/ratelimit/v3"
+	envoy_buffer_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/buffer/v3"
 	ratelimit_filter_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ratelimit/v3"
 	http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
 	envoy_tls_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
 	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
 	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
 	"github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
+	"github.com/projectcontour/contour/internal/contour"
 	"github.com/projectcontour/contour/internal/contourconfig"
 	"github.com/projectcontour/contour/internal/dag"
 	envoy_v3 "github.com/projectcontour/contour/internal/envoy/v3"
@@ -36,6 +38,8 @@ import (
 	"github.com/projectcontour/contour/internal/protobuf"
 	"github.com/projectcontour/contour/internal/ref"
 	"github.com/projectcontour/contour/internal/timeout"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/durationpb"
 	v1 "k8s.io/api/core/v1"
@@ -3540,9 +3544,590 @@ func TestListenerVisit(t *testing.T) {
 				SocketOptions: envoy_v3.TCPKeepaliveSocketOptions(),
 			}),
 		},
+		"httpsproxy with HTTP2 max concurrent streams overridden in virtual host": {
+			ListenerConfig: ListenerConfig{
+				HTTP2Settings: envoy_v3.HTTP2Settings{
+					MaxConcurrentStreams:     ref.To(uint32(100)),
+					MaxOutboundControlFrames: ref.To(uint32(1000)),
+				},
+			},
+			objs: []any{
+				&contour_api_v1.HTTPProxy{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "simple",
+						Namespace: "default",
+					},
+					Spec: contour_api_v1.HTTPProxySpec{
+						VirtualHost: &contour_api_v1.VirtualHost{
+							Fqdn: "www.example.com",
+							TLS: &contour_api_v1.TLS{
+								SecretName: "secret",
+							},
+							HTTP2MaxConcurrentStreams: ref.To(uint32(10)),
+						},
+						Routes: []contour_api_v1.Route{{
+							Services: []contour_api_v1.Service{{
+								Name: "backend",
+								Port: 80,
+							}},
+						}},
+					},
+				},
+				&v1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "secret",
+						Namespace: "default",
+					},
+					Type: "kubernetes.io/tls",
+					Data: secretdata(CERTIFICATE, RSA_PRIVATE_KEY),
+				},
+				&v1.Service{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "backend",
+						Namespace: "default",
+					},
+					Spec: v1.ServiceSpec{
+						Ports: []v1.ServicePort{{
+							Name:     "http",
+							Protocol: "TCP",
+							Port:     80,
+						}},
+					},
+				},
+			},
+			want: listenermap(&envoy_listener_v3.Listener{
+				Name:    ENVOY_HTTP_LISTENER,
+				Address: envoy_v3.SocketAddress("0.0.0.0", 8080),
+				FilterChains: envoy_v3.FilterChains(envoy_v3.HTTPConnectionManagerBuilder().
+					RouteConfigName(ENVOY_HTTP_LISTENER).
+					MetricsPrefix(ENVOY_HTTP_LISTENER).
+					AccessLoggers(envoy_v3.FileAccessLogEnvoy(DEFAULT_HTTP_ACCESS_LOG, "", nil, v1alpha1.LogLevelInfo)).
+					DefaultFilters().
+					HTTP2Settings(envoy_v3.HTTP2Settings{
+						MaxConcurrentStreams:     ref.To(uint32(100)),
+						MaxOutboundControlFrames: ref.To(uint32(1000)),
+					}).
+					Get(),
+				),
+				SocketOptions: envoy_v3.TCPKeepaliveSocketOptions(),
+			}, &envoy_listener_v3.Listener{
+				Name:    ENVOY_HTTPS_LISTENER,
+				Address: envoy_v3.SocketAddress("0.0.0.0", 8443),
+				FilterChains: []*envoy_listener_v3.FilterChain{{
+					FilterChainMatch: &envoy_listener_v3.FilterChainMatch{
+						ServerNames: []string{"www.example.com"},
+					},
+					TransportSocket: transportSocket("secret", envoy_tls_v3.TlsParameters_TLSv1_2, nil, "h2", "http/1.1"),
+					Filters: envoy_v3.Filters(envoy_v3.HTTPConnectionManagerBuilder().
+						AddFilter(envoy_v3.FilterMisdirectedRequests("www.example.com")).
+						DefaultFilters().
+						MetricsPrefix(ENVOY_HTTPS_LISTENER).
+						RouteConfigName(path.Join("https", "www.example.com")).
+						AccessLoggers(envoy_v3.FileAccessLogEnvoy(DEFAULT_HTTP_ACCESS_LOG, "", nil, v1alpha1.LogLevelInfo)).
+						HTTP2Settings(envoy_v3.HTTP2Settings{
+							MaxConcurrentStreams:     ref.To(uint32(10)),
+							MaxOutboundControlFrames: ref.To(uint32(1000)),
+						}).
+						Get()),
+				}},
+				ListenerFilters: envoy_v3.ListenerFilters(
+					envoy_v3.TLSInspector(),
+				),
+				SocketOptions: envoy_v3.TCPKeepaliveSocketOptions(),
+			}),
+		},
+		"httpproxy with timeout replies set in listener config": {
+			ListenerConfig: ListenerConfig{
+				TimeoutReplies: envoy_v3.TimeoutReplies{
+					Idle: &dag.TimeoutReply{
+						StatusCode:  408,
+						Body:        `{"error":"idle timeout"}`,
+						ContentType: "application/json",
+					},
+				},
+			},
+			objs: []any{
+				&contour_api_v1.HTTPProxy{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "simple",
+						Namespace: "default",
+					},
+					Spec: contour_api_v1.HTTPProxySpec{
+						VirtualHost: &contour_api_v1.VirtualHost{
+							Fqdn: "www.example.com",
+						},
+						Routes: []contour_api_v1.Route{{
+							Services: []contour_api_v1.Service{{
+								Name: "backend",
+								Port: 80,
+							}},
+						}},
+					},
+				},
+				&v1.Service{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "backend",
+						Namespace: "default",
+					},
+					Spec: v1.ServiceSpec{
+						Ports: []v1.ServicePort{{
+							Name:     "http",
+							Protocol: "TCP",
+							Port:     80,
+						}},
+					},
+				},
+			},
+			want: listenermap(&envoy_listener_v3.Listener{
+				Name:    ENVOY_HTTP_LISTENER,
+				Address: envoy_v3.SocketAddress("0.0.0.0", 8080),
+				FilterChains: envoy_v3.FilterChains(envoy_v3.HTTPConnectionManagerBuilder().
+					RouteConfigName(ENVOY_HTTP_LISTENER).
+					MetricsPrefix(ENVOY_HTTP_LISTENER).
+					AccessLoggers(envoy_v3.FileAccessLogEnvoy(DEFAULT_HTTP_ACCESS_LOG, "", nil, v1alpha1.LogLevelInfo)).
+					DefaultFilters().
+					LocalReplyConfig(envoy_v3.TimeoutReplyConfig(envoy_v3.TimeoutReplies{
+						Idle: &dag.TimeoutReply{
+							StatusCode:  408,
+							Body:        `{"error":"idle timeout"}`,
+							ContentType: "application/json",
+						},
+					}, nil)).
+					Get(),
+				),
+				SocketOptions: envoy_v3.TCPKeepaliveSocketOptions(),
+			}),
+		},
 		"httpproxy with PerConnectionBufferLimitBytes set in listener config": {
 			ListenerConfig: ListenerConfig{
-				PerConnectionBufferLimitBytes: ref.To(uint32(32768)),
+				PerConnectionBufferLimitBytes: ref.To(uint32(32768)),
+			},
+			objs: []any{
+				&contour_api_v1.HTTPProxy{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "simple",
+						Namespace: "default",
+					},
+					Spec: contour_api_v1.HTTPProxySpec{
+						VirtualHost: &contour_api_v1.VirtualHost{
+							Fqdn: "www.example.com",
+						},
+						Routes: []contour_api_v1.Route{{
+							Conditions: []contour_api_v1.MatchCondition{{
+								Prefix: "/",
+							}},
+							Services: []contour_api_v1.Service{{
+								Name: "backend",
+								Port: 80,
+							}},
+						}},
+					},
+				},
+				&v1.Service{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "backend",
+						Namespace: "default",
+					},
+					Spec: v1.ServiceSpec{
+						Ports: []v1.ServicePort{{
+							Name:     "http",
+							Protocol: "TCP",
+							Port:     80,
+						}},
+					},
+				},
+			},
+			want: listenermap(&envoy_listener_v3.Listener{
+				Name:                          ENVOY_HTTP_LISTENER,
+				Address:                       envoy_v3.SocketAddress("0.0.0.0", 8080),
+				PerConnectionBufferLimitBytes: wrapperspb.UInt32(32768),
+				FilterChains: envoy_v3.FilterChains(
+					envoy_v3.HTTPConnectionManagerBuilder().
+						RouteConfigName(ENVOY_HTTP_LISTENER).
+						MetricsPrefix(ENVOY_HTTP_LISTENER).
+						AccessLoggers(envoy_v3.FileAccessLogEnvoy(DEFAULT_HTTP_ACCESS_LOG, "", nil, v1alpha1.LogLevelInfo)).
+						DefaultFilters().
+						Get(),
+				),
+				SocketOptions: envoy_v3.TCPKeepaliveSocketOptions(),
+			}),
+		},
+		"httpproxy with modify-only DrainType set in listener config": {
+			ListenerConfig: ListenerConfig{
+				DrainType: v1alpha1.ModifyOnlyListenerDrainType,
+			},
+			objs: []any{
+				&contour_api_v1.HTTPProxy{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "simple",
+						Namespace: "default",
+					},
+					Spec: contour_api_v1.HTTPProxySpec{
+						VirtualHost: &contour_api_v1.VirtualHost{
+							Fqdn: "www.example.com",
+						},
+						Routes: []contour_api_v1.Route{{
+							Conditions: []contour_api_v1.MatchCondition{{
+								Prefix: "/",
+							}},
+							Services: []contour_api_v1.Service{{
+								Name: "backend",
+								Port: 80,
+							}},
+						}},
+					},
+				},
+				&v1.Service{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "backend",
+						Namespace: "default",
+					},
+					Spec: v1.ServiceSpec{
+						Ports: []v1.ServicePort{{
+							Name:     "http",
+							Protocol: "TCP",
+							Port:     80,
+						}},
+					},
+				},
+			},
+			want: listenermap(&envoy_listener_v3.Listener{
+				Name:      ENVOY_HTTP_LISTENER,
+				Address:   envoy_v3.SocketAddress("0.0.0.0", 8080),
+				DrainType: envoy_listener_v3.Listener_MODIFY_ONLY,
+				FilterChains: envoy_v3.FilterChains(
+					envoy_v3.HTTPConnectionManagerBuilder().
+						RouteConfigName(ENVOY_HTTP_LISTENER).
+						MetricsPrefix(ENVOY_HTTP_LISTENER).
+						AccessLoggers(envoy_v3.FileAccessLogEnvoy(DEFAULT_HTTP_ACCESS_LOG, "", nil, v1alpha1.LogLevelInfo)).
+						DefaultFilters().
+						Get(),
+				),
+				SocketOptions: envoy_v3.TCPKeepaliveSocketOptions(),
+			}),
+		},
+		"httpproxy with socket options set in listener config": {
+			ListenerConfig: ListenerConfig{
+				SocketOptions: envoy_v3.SocketOptions{
+					TCPKeepaliveIdle: 60 * time.Second,
+					TOS:              ref.To(int32(64)),
+					TrafficClass:     ref.To(int32(32)),
+					Freebind:         true,
+				},
+			},
+			objs: []any{
+				&contour_api_v1.HTTPProxy{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "simple",
+						Namespace: "default",
+					},
+					Spec: contour_api_v1.HTTPProxySpec{
+						VirtualHost: &contour_api_v1.VirtualHost{
+							Fqdn: "www.example.com",
+						},
+						Routes: []contour_api_v1.Route{{
+							Conditions: []contour_api_v1.MatchCondition{{
+								Prefix: "/",
+							}},
+							Services: []contour_api_v1.Service{{
+								Name: "backend",
+								Port: 80,
+							}},
+						}},
+					},
+				},
+				&v1.Service{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "backend",
+						Namespace: "default",
+					},
+					Spec: v1.ServiceSpec{
+						Ports: []v1.ServicePort{{
+							Name:     "http",
+							Protocol: "TCP",
+							Port:     80,
+						}},
+					},
+				},
+			},
+			want: listenermap(&envoy_listener_v3.Listener{
+				Name:     ENVOY_HTTP_LISTENER,
+				Address:  envoy_v3.SocketAddress("0.0.0.0", 8080),
+				Freebind: wrapperspb.Bool(true),
+				FilterChains: envoy_v3.FilterChains(
+					envoy_v3.HTTPConnectionManagerBuilder().
+						RouteConfigName(ENVOY_HTTP_LISTENER).
+						MetricsPrefix(ENVOY_HTTP_LISTENER).
+						AccessLoggers(envoy_v3.FileAccessLogEnvoy(DEFAULT_HTTP_ACCESS_LOG, "", nil, v1alpha1.LogLevelInfo)).
+						DefaultFilters().
+						Get(),
+				),
+				SocketOptions: envoy_v3.ListenerSocketOptions(envoy_v3.SocketOptions{
+					TCPKeepaliveIdle: 60 * time.Second,
+					TOS:              ref.To(int32(64)),
+				}, "0.0.0.0"),
+			}),
+		},
+		"httpproxy with additional HTTP filters set in listener config": {
+			ListenerConfig: ListenerConfig{
+				HTTPFilters: []envoy_v3.AdditionalHTTPFilter{{
+					Position: v1alpha1.BeforeRouterHTTPFilterPosition,
+					Filter: &http.HttpFilter{
+						Name: "buffer",
+						ConfigType: &http.HttpFilter_TypedConfig{
+							TypedConfig: protobuf.MustMarshalAny(&envoy_buffer_v3.Buffer{
+								MaxRequestBytes: wrapperspb.UInt32(1024),
+							}),
+						},
+					},
+				}},
+			},
+			objs: []any{
+				&contour_api_v1.HTTPProxy{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "simple",
+						Namespace: "default",
+					},
+					Spec: contour_api_v1.HTTPProxySpec{
+						VirtualHost: &contour_api_v1.VirtualHost{
+							Fqdn: "www.example.com",
+						},
+						Routes: []contour_api_v1.Route{{
+							Conditions: []contour_api_v1.MatchCondition{{
+								Prefix: "/",
+							}},
+							Services: []contour_api_v1.Service{{
+								Name: "backend",
+								Port: 80,
+							}},
+						}},
+					},
+				},
+				&v1.Service{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "backend",
+						Namespace: "default",
+					},
+					Spec: v1.ServiceSpec{
+						Ports: []v1.ServicePort{{
+							Name:     "http",
+							Protocol: "TCP",
+							Port:     80,
+						}},
+					},
+				},
+			},
+			want: listenermap(&envoy_listener_v3.Listener{
+				Name:    ENVOY_HTTP_LISTENER,
+				Address: envoy_v3.SocketAddress("0.0.0.0", 8080),
+				FilterChains: envoy_v3.FilterChains(
+					envoy_v3.HTTPConnectionManagerBuilder().
+						RouteConfigName(ENVOY_HTTP_LISTENER).
+						MetricsPrefix(ENVOY_HTTP_LISTENER).
+						AccessLoggers(envoy_v3.FileAccessLogEnvoy(DEFAULT_HTTP_ACCESS_LOG, "", nil, v1alpha1.LogLevelInfo)).
+						DefaultFilters().
+						AddFilter(&http.HttpFilter{
+							Name: "buffer",
+							ConfigType: &http.HttpFilter_TypedConfig{
+								TypedConfig: protobuf.MustMarshalAny(&envoy_buffer_v3.Buffer{
+									MaxRequestBytes: wrapperspb.UInt32(1024),
+								}),
+							},
+						}).
+						Get(),
+				),
+				SocketOptions: envoy_v3.TCPKeepaliveSocketOptions(),
+			}),
+		},
+		"httpproxy with compression set in listener config": {
+			ListenerConfig: ListenerConfig{
+				Compression: envoy_v3.CompressionSettings{
+					Algorithm: v1alpha1.BrotliCompression,
+				},
+			},
+			objs: []any{
+				&contour_api_v1.HTTPProxy{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "simple",
+						Namespace: "default",
+					},
+					Spec: contour_api_v1.HTTPProxySpec{
+						VirtualHost: &contour_api_v1.VirtualHost{
+							Fqdn: "www.example.com",
+						},
+						Routes: []contour_api_v1.Route{{
+							Conditions: []contour_api_v1.MatchCondition{{
+								Prefix: "/",
+							}},
+							Services: []contour_api_v1.Service{{
+								Name: "backend",
+								Port: 80,
+							}},
+						}},
+					},
+				},
+				&v1.Service{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "backend",
+						Namespace: "default",
+					},
+					Spec: v1.ServiceSpec{
+						Ports: []v1.ServicePort{{
+							Name:     "http",
+							Protocol: "TCP",
+							Port:     80,
+						}},
+					},
+				},
+			},
+			want: listenermap(&envoy_listener_v3.Listener{
+				Name:    ENVOY_HTTP_LISTENER,
+				Address: envoy_v3.SocketAddress("0.0.0.0", 8080),
+				FilterChains: envoy_v3.FilterChains(
+					envoy_v3.HTTPConnectionManagerBuilder().
+						RouteConfigName(ENVOY_HTTP_LISTENER).
+						MetricsPrefix(ENVOY_HTTP_LISTENER).
+						AccessLoggers(envoy_v3.FileAccessLogEnvoy(DEFAULT_HTTP_ACCESS_LOG, "", nil, v1alpha1.LogLevelInfo)).
+						DefaultFilters().
+						Compression(envoy_v3.CompressionSettings{
+							Algorithm: v1alpha1.BrotliCompression,
+						}).
+						Get(),
+				),
+				SocketOptions: envoy_v3.TCPKeepaliveSocketOptions(),
+			}),
+		},
+		"httpproxy with response map set in listener config": {
+			ListenerConfig: ListenerConfig{
+				ResponseMap: []envoy_v3.ResponseMapping{{
+					StatusCode: 503,
+					Body:       "unavailable",
+				}},
+			},
+			objs: []any{
+				&contour_api_v1.HTTPProxy{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "simple",
+						Namespace: "default",
+					},
+					Spec: contour_api_v1.HTTPProxySpec{
+						VirtualHost: &contour_api_v1.VirtualHost{
+							Fqdn: "www.example.com",
+						},
+						Routes: []contour_api_v1.Route{{
+							Conditions: []contour_api_v1.MatchCondition{{
+								Prefix: "/",
+							}},
+							Services: []contour_api_v1.Service{{
+								Name: "backend",
+								Port: 80,
+							}},
+						}},
+					},
+				},
+				&v1.Service{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "backend",
+						Namespace: "default",
+					},
+					Spec: v1.ServiceSpec{
+						Ports: []v1.ServicePort{{
+							Name:     "http",
+							Protocol: "TCP",
+							Port:     80,
+						}},
+					},
+				},
+			},
+			want: listenermap(&envoy_listener_v3.Listener{
+				Name:    ENVOY_HTTP_LISTENER,
+				Address: envoy_v3.SocketAddress("0.0.0.0", 8080),
+				FilterChains: envoy_v3.FilterChains(
+					envoy_v3.HTTPConnectionManagerBuilder().
+						RouteConfigName(ENVOY_HTTP_LISTENER).
+						MetricsPrefix(ENVOY_HTTP_LISTENER).
+						AccessLoggers(envoy_v3.FileAccessLogEnvoy(DEFAULT_HTTP_ACCESS_LOG, "", nil, v1alpha1.LogLevelInfo)).
+						DefaultFilters().
+						LocalReplyConfig(envoy_v3.ResponseMapConfig(nil, []envoy_v3.ResponseMapping{{
+							StatusCode: 503,
+							Body:       "unavailable",
+						}})).
+						Get(),
+				),
+				SocketOptions: envoy_v3.TCPKeepaliveSocketOptions(),
+			}),
+		},
+		"httpproxy with gRPC access log service set in listener config": {
+			ListenerConfig: ListenerConfig{
+				GRPCAccessLogConfig: &GRPCAccessLogConfig{
+					ExtensionServiceConfig: ExtensionServiceConfig{
+						ExtensionService: k8s.NamespacedNameFrom("projectcontour/als"),
+					},
+					LogName:         "contour",
+					BufferSizeBytes: 32768,
+				},
+			},
+			objs: []any{
+				&contour_api_v1.HTTPProxy{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "simple",
+						Namespace: "default",
+					},
+					Spec: contour_api_v1.HTTPProxySpec{
+						VirtualHost: &contour_api_v1.VirtualHost{
+							Fqdn: "www.example.com",
+						},
+						Routes: []contour_api_v1.Route{{
+							Conditions: []contour_api_v1.MatchCondition{{
+								Prefix: "/",
+							}},
+							Services: []contour_api_v1.Service{{
+								Name: "backend",
+								Port: 80,
+							}},
+						}},
+					},
+				},
+				&v1.Service{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "backend",
+						Namespace: "default",
+					},
+					Spec: v1.ServiceSpec{
+						Ports: []v1.ServicePort{{
+							Name:     "http",
+							Protocol: "TCP",
+							Port:     80,
+						}},
+					},
+				},
+			},
+			want: listenermap(&envoy_listener_v3.Listener{
+				Name:    ENVOY_HTTP_LISTENER,
+				Address: envoy_v3.SocketAddress("0.0.0.0", 8080),
+				FilterChains: envoy_v3.FilterChains(
+					envoy_v3.HTTPConnectionManagerBuilder().
+						RouteConfigName(ENVOY_HTTP_LISTENER).
+						MetricsPrefix(ENVOY_HTTP_LISTENER).
+						AccessLoggers(append(
+							envoy_v3.FileAccessLogEnvoy(DEFAULT_HTTP_ACCESS_LOG, "", nil, v1alpha1.LogLevelInfo),
+							envoy_v3.HTTPGRPCAccessLog(&envoy_v3.GRPCAccessLogConfig{
+								ExtensionService: k8s.NamespacedNameFrom("projectcontour/als"),
+								LogName:          "contour",
+								BufferSizeBytes:  32768,
+							}, v1alpha1.LogLevelInfo)...,
+						)).
+						DefaultFilters().
+						Get(),
+				),
+				SocketOptions: envoy_v3.TCPKeepaliveSocketOptions(),
+			}),
+		},
+		"httpproxy with access log filter set in listener config": {
+			ListenerConfig: ListenerConfig{
+				AccessLogFilter: &envoy_v3.AccessLogFilterConfig{
+					MinStatusCode: 400,
+				},
 			},
 			objs: []any{
 				&contour_api_v1.HTTPProxy{
@@ -3580,15 +4165,257 @@ func TestListenerVisit(t *testing.T) {
 				},
 			},
 			want: listenermap(&envoy_listener_v3.Listener{
-				Name:                          ENVOY_HTTP_LISTENER,
-				Address:                       envoy_v3.SocketAddress("0.0.0.0", 8080),
-				PerConnectionBufferLimitBytes: wrapperspb.UInt32(32768),
+				Name:    ENVOY_HTTP_LISTENER,
+				Address: envoy_v3.SocketAddress("0.0.0.0", 8080),
+				FilterChains: envoy_v3.FilterChains(
+					envoy_v3.HTTPConnectionManagerBuilder().
+						RouteConfigName(ENVOY_HTTP_LISTENER).
+						MetricsPrefix(ENVOY_HTTP_LISTENER).
+						AccessLoggers(envoy_v3.FilterAccessLogs(
+							envoy_v3.FileAccessLogEnvoy(DEFAULT_HTTP_ACCESS_LOG, "", nil, v1alpha1.LogLevelInfo),
+							&envoy_v3.AccessLogFilterConfig{MinStatusCode: 400},
+						)).
+						DefaultFilters().
+						Get(),
+				),
+				SocketOptions: envoy_v3.TCPKeepaliveSocketOptions(),
+			}),
+		},
+		"httpproxy with access log policies set in virtual hosts": {
+			objs: []any{
+				&contour_api_v1.HTTPProxy{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "www",
+						Namespace: "default",
+					},
+					Spec: contour_api_v1.HTTPProxySpec{
+						VirtualHost: &contour_api_v1.VirtualHost{
+							Fqdn: "www.example.com",
+							AccessLogPolicy: &contour_api_v1.AccessLogPolicy{
+								Format:     "json",
+								JSONFields: []string{"@timestamp", "method"},
+							},
+						},
+						Routes: []contour_api_v1.Route{{
+							Services: []contour_api_v1.Service{{
+								Name: "backend",
+								Port: 80,
+							}},
+						}},
+					},
+				},
+				&contour_api_v1.HTTPProxy{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "secure",
+						Namespace: "default",
+					},
+					Spec: contour_api_v1.HTTPProxySpec{
+						VirtualHost: &contour_api_v1.VirtualHost{
+							Fqdn: "secure.example.com",
+							TLS: &contour_api_v1.TLS{
+								SecretName: "secret",
+							},
+							AccessLogPolicy: &contour_api_v1.AccessLogPolicy{
+								Disabled: true,
+							},
+						},
+						Routes: []contour_api_v1.Route{{
+							Services: []contour_api_v1.Service{{
+								Name: "backend",
+								Port: 80,
+							}},
+						}},
+					},
+				},
+				&v1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "secret",
+						Namespace: "default",
+					},
+					Type: "kubernetes.io/tls",
+					Data: secretdata(CERTIFICATE, RSA_PRIVATE_KEY),
+				},
+				&v1.Service{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "backend",
+						Namespace: "default",
+					},
+					Spec: v1.ServiceSpec{
+						Ports: []v1.ServicePort{{
+							Name:     "http",
+							Protocol: "TCP",
+							Port:     80,
+						}},
+					},
+				},
+			},
+			want: listenermap(&envoy_listener_v3.Listener{
+				Name:    ENVOY_HTTP_LISTENER,
+				Address: envoy_v3.SocketAddress("0.0.0.0", 8080),
+				FilterChains: envoy_v3.FilterChains(
+					envoy_v3.HTTPConnectionManagerBuilder().
+						RouteConfigName(ENVOY_HTTP_LISTENER).
+						MetricsPrefix(ENVOY_HTTP_LISTENER).
+						AccessLoggers(append(
+							envoy_v3.ExcludeAccessLogsByAuthority(
+								envoy_v3.FileAccessLogEnvoy(DEFAULT_HTTP_ACCESS_LOG, "", nil, v1alpha1.LogLevelInfo),
+								[]envoy_v3.AuthorityMatch{{Host: "secure.example.com"}, {Host: "www.example.com"}},
+							),
+							envoy_v3.FilterAccessLogsByAuthority(
+								envoy_v3.FileAccessLogJSON(DEFAULT_HTTP_ACCESS_LOG, v1alpha1.AccessLogJSONFields{"@timestamp", "method"}, nil, v1alpha1.LogLevelInfo),
+								envoy_v3.AuthorityMatch{Host: "www.example.com"},
+							)...,
+						)).
+						DefaultFilters().
+						Get(),
+				),
+				SocketOptions: envoy_v3.TCPKeepaliveSocketOptions(),
+			}, &envoy_listener_v3.Listener{
+				Name:    ENVOY_HTTPS_LISTENER,
+				Address: envoy_v3.SocketAddress("0.0.0.0", 8443),
+				FilterChains: []*envoy_listener_v3.FilterChain{{
+					FilterChainMatch: &envoy_listener_v3.FilterChainMatch{
+						ServerNames: []string{"secure.example.com"},
+					},
+					TransportSocket: transportSocket("secret", envoy_tls_v3.TlsParameters_TLSv1_2, nil, "h2", "http/1.1"),
+					Filters: envoy_v3.Filters(envoy_v3.HTTPConnectionManagerBuilder().
+						AddFilter(envoy_v3.FilterMisdirectedRequests("secure.example.com")).
+						DefaultFilters().
+						MetricsPrefix(ENVOY_HTTPS_LISTENER).
+						RouteConfigName(path.Join("https", "secure.example.com")).
+						Get()),
+				}},
+				ListenerFilters: envoy_v3.ListenerFilters(
+					envoy_v3.TLSInspector(),
+				),
+				SocketOptions: envoy_v3.TCPKeepaliveSocketOptions(),
+			}),
+		},
+		"httpproxy with force trace set in tracing config": {
+			ListenerConfig: ListenerConfig{
+				AccessLogLevel: v1alpha1.LogLevelError,
+				TracingConfig: &TracingConfig{
+					ExtensionServiceConfig: ExtensionServiceConfig{
+						ExtensionService: k8s.NamespacedNameFrom("projectcontour/otel-collector"),
+						Timeout:          timeout.DefaultSetting(),
+					},
+					ServiceName:           "contour",
+					OverallSampling:       100,
+					MaxPathTagLength:      256,
+					ForceTraceClientCIDRs: []string{"10.0.0.0/8", "fd00::/8"},
+				},
+			},
+			objs: []any{
+				&contour_api_v1.HTTPProxy{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "simple",
+						Namespace: "default",
+					},
+					Spec: contour_api_v1.HTTPProxySpec{
+						VirtualHost: &contour_api_v1.VirtualHost{
+							Fqdn: "www.example.com",
+						},
+						Routes: []contour_api_v1.Route{{
+							Services: []contour_api_v1.Service{{
+								Name: "backend",
+								Port: 80,
+							}},
+						}},
+					},
+				},
+				&v1.Service{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "backend",
+						Namespace: "default",
+					},
+					Spec: v1.ServiceSpec{
+						Ports: []v1.ServicePort{{
+							Name:     "http",
+							Protocol: "TCP",
+							Port:     80,
+						}},
+					},
+				},
+			},
+			want: listenermap(&envoy_listener_v3.Listener{
+				Name:    ENVOY_HTTP_LISTENER,
+				Address: envoy_v3.SocketAddress("0.0.0.0", 8080),
+				FilterChains: envoy_v3.FilterChains(
+					envoy_v3.HTTPConnectionManagerBuilder().
+						RouteConfigName(ENVOY_HTTP_LISTENER).
+						MetricsPrefix(ENVOY_HTTP_LISTENER).
+						AccessLoggers(envoy_v3.LogForcedTraces(
+							envoy_v3.FileAccessLogEnvoy(DEFAULT_HTTP_ACCESS_LOG, "", nil, v1alpha1.LogLevelError),
+						)).
+						DefaultFilters().
+						Tracing(envoy_v3.TracingConfig(&envoy_v3.EnvoyTracingConfig{
+							ExtensionService: k8s.NamespacedNameFrom("projectcontour/otel-collector"),
+							ServiceName:      "contour",
+							Timeout:          timeout.DefaultSetting(),
+							OverallSampling:  100,
+							MaxPathTagLength: 256,
+						})).
+						InternalAddresses([]string{"10.0.0.0/8", "fd00::/8"}).
+						Get(),
+				),
+				SocketOptions: envoy_v3.TCPKeepaliveSocketOptions(),
+			}),
+		},
+		"httpproxy with http connection manager settings set in listener config": {
+			ListenerConfig: ListenerConfig{
+				HTTPConnectionManagerSettings: envoy_v3.HTTPConnectionManagerSettings{
+					ServerName:    "example",
+					NormalizePath: ref.To(false),
+				},
+			},
+			objs: []any{
+				&contour_api_v1.HTTPProxy{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "simple",
+						Namespace: "default",
+					},
+					Spec: contour_api_v1.HTTPProxySpec{
+						VirtualHost: &contour_api_v1.VirtualHost{
+							Fqdn: "www.example.com",
+						},
+						Routes: []contour_api_v1.Route{{
+							Conditions: []contour_api_v1.MatchCondition{{
+								Prefix: "/",
+							}},
+							Services: []contour_api_v1.Service{{
+								Name: "backend",
+								Port: 80,
+							}},
+						}},
+					},
+				},
+				&v1.Service{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "backend",
+						Namespace: "default",
+					},
+					Spec: v1.ServiceSpec{
+						Ports: []v1.ServicePort{{
+							Name:     "http",
+							Protocol: "TCP",
+							Port:     80,
+						}},
+					},
+				},
+			},
+			want: listenermap(&envoy_listener_v3.Listener{
+				Name:    ENVOY_HTTP_LISTENER,
+				Address: envoy_v3.SocketAddress("0.0.0.0", 8080),
 				FilterChains: envoy_v3.FilterChains(
 					envoy_v3.HTTPConnectionManagerBuilder().
 						RouteConfigName(ENVOY_HTTP_LISTENER).
 						MetricsPrefix(ENVOY_HTTP_LISTENER).
 						AccessLoggers(envoy_v3.FileAccessLogEnvoy(DEFAULT_HTTP_ACCESS_LOG, "", nil, v1alpha1.LogLevelInfo)).
 						DefaultFilters().
+						Settings(envoy_v3.HTTPConnectionManagerSettings{
+							ServerName:    "example",
+							NormalizePath: ref.To(false),
+						}).
 						Get(),
 				),
 				SocketOptions: envoy_v3.TCPKeepaliveSocketOptions(),
@@ -3701,7 +4528,7 @@ func transportSocket(secretname string, tlsMinProtoVersion envoy_tls_v3.TlsParam
 		},
 	}
 	return envoy_v3.DownstreamTLSTransportSocket(
-		envoy_v3.DownstreamTLSContext(secret, tlsMinProtoVersion, cipherSuites, nil, alpnprotos...),
+		envoy_v3.DownstreamTLSContext(envoy_v3.SecretConfig(secret), tlsMinProtoVersion, cipherSuites, nil, alpnprotos...),
 	)
 }
 
@@ -3712,3 +4539,99 @@ func listenermap(listeners ...*envoy_listener_v3.Listener) map[string]*envoy_lis
 	}
 	return m
 }
+
+func TestListenerCacheRemovalDelay(t *testing.T) {
+	http80 := envoy_v3.Listener("http-80", "0.0.0.0", 8080, nil, nil)
+	http81 := envoy_v3.Listener("http-81", "0.0.0.0", 8081, nil, nil)
+	https80 := envoy_v3.Listener("https-80", "0.0.0.0", 8080, nil, nil)
+
+	lc := ListenerCache{
+		Config: ListenerConfig{
+			RemovalDelay: time.Hour,
+		},
+	}
+	lc.Update(map[string]*envoy_listener_v3.Listener{"http-80": http80})
+
+	// The removed listener is kept while its replacement warms.
+	listeners := map[string]*envoy_listener_v3.Listener{"http-81": http81}
+	lc.retire(listeners)
+	lc.Update(listeners)
+	protobuf.ExpectEqual(t, map[string]*envoy_listener_v3.Listener{
+		"http-80": http80,
+		"http-81": http81,
+	}, lc.values)
+	require.NotNil(t, lc.removalTimer)
+	lc.removalTimer.Stop()
+
+	// It's removed when its removal delay expires.
+	lc.retiring["http-80"].expires = time.Now()
+	lc.removeRetired()
+	protobuf.ExpectEqual(t, map[string]*envoy_listener_v3.Listener{
+		"http-81": http81,
+	}, lc.values)
+	assert.Nil(t, lc.removalTimer)
+
+	// A removed listener whose address is used by
+	// a listener in the DAG is removed immediately.
+	lc.Update(map[string]*envoy_listener_v3.Listener{"http-80": http80})
+	listeners = map[string]*envoy_listener_v3.Listener{"https-80": https80}
+	lc.retire(listeners)
+	protobuf.ExpectEqual(t, map[string]*envoy_listener_v3.Listener{
+		"https-80": https80,
+	}, listeners)
+	assert.Empty(t, lc.retiring)
+}
+
+func TestListenerCacheRemovalDelayExpires(t *testing.T) {
+	refreshed := make(chan struct{}, 1)
+	lc := ListenerCache{
+		Config: ListenerConfig{
+			RemovalDelay: 10 * time.Millisecond,
+		},
+		Observer: contour.ObserverFunc(func() {
+			refreshed <- struct{}{}
+		}),
+	}
+	lc.Update(map[string]*envoy_listener_v3.Listener{
+		"http-80": envoy_v3.Listener("http-80", "0.0.0.0", 8080, nil, nil),
+	})
+
+	listeners := map[string]*envoy_listener_v3.Listener{}
+	lc.retire(listeners)
+	lc.Update(listeners)
+	assert.Len(t, lc.Contents(), 1)
+
+	select {
+	case <-refreshed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("listener was not removed")
+	}
+	assert.Empty(t, lc.Contents())
+}
+
+func TestListenerCacheSetMetricsBearerToken(t *testing.T) {
+	metrics := v1alpha1.MetricsConfig{Address: "0.0.0.0", Port: 8002}
+	health := v1alpha1.HealthConfig{Address: "0.0.0.0", Port: 8002}
+
+	lc := NewListenerCache(ListenerConfig{MetricsBearerToken: "secret"}, metrics, health, 0)
+	protobuf.ExpectEqual(t, []proto.Message{envoy_v3.StatsListeners(metrics, health, envoy_v3.HealthSettings{}, "secret")[0]}, lc.Contents())
+
+	lc.SetMetricsBearerToken("rotated")
+	protobuf.ExpectEqual(t, []proto.Message{envoy_v3.StatsListeners(metrics, health, envoy_v3.HealthSettings{}, "rotated")[0]}, lc.Contents())
+}
+
+func TestAccessLogAuthorityMatch(t *testing.T) {
+	vhosts := []*dag.VirtualHost{
+		{Name: "*.example.com"},
+		{Name: "a.b.example.com"},
+		{Name: "example.com"},
+		{Name: "www.example.com"},
+		{Name: "www.example.org"},
+	}
+
+	assert.Equal(t, envoy_v3.AuthorityMatch{Host: "www.example.com"}, accessLogAuthorityMatch(vhosts[3], vhosts))
+	assert.Equal(t, envoy_v3.AuthorityMatch{
+		Host:     "*.example.com",
+		Excluded: []string{"www.example.com"},
+	}, accessLogAuthorityMatch(vhosts[0], vhosts))
+}
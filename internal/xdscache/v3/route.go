@@ -30,6 +30,16 @@ import (
 
 // RouteCache manages the contents of the gRPC RDS cache.
 type RouteCache struct {
+	// CaptureEnabled is set if the HTTP tap filter is added to the
+	// listeners, so it must be disabled on routes that don't have
+	// capture enabled.
+	CaptureEnabled bool
+
+	// RemoveServerHeader is set if the Server header is removed
+	// from responses. The HTTP connection managers pass it through,
+	// so the route configurations remove it.
+	RemoveServerHeader bool
+
 	mu     sync.Mutex
 	values map[string]*envoy_route_v3.RouteConfiguration
 	contour.Cond
@@ -117,7 +127,7 @@ func (c *RouteCache) OnChange(root *dag.DAG) {
 				sortRoutes(routes)
 
 				routeConfigs[routeConfigName].VirtualHosts = append(routeConfigs[routeConfigName].VirtualHosts,
-					envoy_v3.VirtualHostAndRoutes(vhost, routes, false),
+					c.virtualHostAndRoutes(vhost, routes, false),
 				)
 			}
 		}
@@ -142,7 +152,7 @@ func (c *RouteCache) OnChange(root *dag.DAG) {
 				sortRoutes(routes)
 
 				routeConfigs[routeConfigName].VirtualHosts = append(routeConfigs[routeConfigName].VirtualHosts,
-					envoy_v3.VirtualHostAndRoutes(&vhost.VirtualHost, routes, true))
+					c.virtualHostAndRoutes(&vhost.VirtualHost, routes, true))
 
 				// A fallback route configuration contains routes for all the vhosts that have the fallback certificate enabled.
 				// When a request is received, the default TLS filterchain will accept the connection,
@@ -155,7 +165,7 @@ func (c *RouteCache) OnChange(root *dag.DAG) {
 					}
 
 					routeConfigs[routeConfigName].VirtualHosts = append(routeConfigs[routeConfigName].VirtualHosts,
-						envoy_v3.VirtualHostAndRoutes(&vhost.VirtualHost, routes, true))
+						c.virtualHostAndRoutes(&vhost.VirtualHost, routes, true))
 				}
 			}
 		}
@@ -163,11 +173,24 @@ func (c *RouteCache) OnChange(root *dag.DAG) {
 
 	for _, routeConfig := range routeConfigs {
 		sort.Stable(sorter.For(routeConfig.VirtualHosts))
+		if c.RemoveServerHeader {
+			routeConfig.ResponseHeadersToRemove = []string{"server"}
+		}
 	}
 
 	c.Update(routeConfigs)
 }
 
+// virtualHostAndRoutes converts a DAG virtual host and its sorted
+// routes to an Envoy virtual host.
+func (c *RouteCache) virtualHostAndRoutes(vh *dag.VirtualHost, routes []*dag.Route, secure bool) *envoy_route_v3.VirtualHost {
+	evh := envoy_v3.VirtualHostAndRoutes(vh, routes, secure)
+	if c.CaptureEnabled {
+		envoy_v3.DisableCapture(evh, routes)
+	}
+	return evh
+}
+
 // sortRoutes sorts the given Route slice in place. Routes are ordered
 // first by path match type, path match value via string comparison and
 // then by the header and query param match conditions.
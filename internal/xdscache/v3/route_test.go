@@ -2707,6 +2707,67 @@ func TestRouteVisit(t *testing.T) {
 				),
 			),
 		},
+		"httpproxy with route-level timeout reply": {
+			objs: []any{
+				&contour_api_v1.HTTPProxy{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "simple",
+						Namespace: "default",
+					},
+					Spec: contour_api_v1.HTTPProxySpec{
+						VirtualHost: &contour_api_v1.VirtualHost{
+							Fqdn: "www.example.com",
+						},
+						Routes: []contour_api_v1.Route{{
+							Conditions: []contour_api_v1.MatchCondition{{
+								Prefix: "/",
+							}},
+							Services: []contour_api_v1.Service{{
+								Name: "backend",
+								Port: 80,
+							}},
+							TimeoutPolicy: &contour_api_v1.TimeoutPolicy{
+								ResponseReply: &contour_api_v1.TimeoutReply{
+									StatusCode:  503,
+									Body:        `{"error":"timeout"}`,
+									ContentType: "application/json",
+								},
+							},
+						}},
+					},
+				},
+				&v1.Service{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "backend",
+						Namespace: "default",
+					},
+					Spec: v1.ServiceSpec{
+						Ports: []v1.ServicePort{{
+							Protocol:   "TCP",
+							Port:       80,
+							TargetPort: intstr.FromInt(8080),
+						}},
+					},
+				},
+			},
+			want: routeConfigurations(
+				envoy_v3.RouteConfiguration("ingress_http",
+					envoy_v3.VirtualHost("www.example.com",
+						&envoy_route_v3.Route{
+							Match:  routePrefix("/"),
+							Action: routecluster("default/backend/80/da39a3ee5e"),
+							RequestHeadersToAdd: []*envoy_core_v3.HeaderValueOption{{
+								Header: &envoy_core_v3.HeaderValue{
+									Key:   "x-contour-timeout-reply",
+									Value: "ea46c23a68",
+								},
+								AppendAction: envoy_core_v3.HeaderValueOption_OVERWRITE_IF_EXISTS_OR_ADD,
+							}},
+						},
+					),
+				),
+			),
+		},
 		"httpproxy with fallback certificate": {
 			fallbackCertificate: &types.NamespacedName{
 				Name:      "fallbacksecret",
@@ -3459,6 +3520,176 @@ func TestRouteVisit(t *testing.T) {
 	}
 }
 
+func TestRouteVisit_Capture(t *testing.T) {
+	service := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "backend",
+			Namespace: "default",
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{{
+				Protocol:   "TCP",
+				Port:       80,
+				TargetPort: intstr.FromInt(8080),
+			}},
+		},
+	}
+	proxy := func(name, fqdn string, captureRoute bool) *contour_api_v1.HTTPProxy {
+		return &contour_api_v1.HTTPProxy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Spec: contour_api_v1.HTTPProxySpec{
+				VirtualHost: &contour_api_v1.VirtualHost{
+					Fqdn: fqdn,
+				},
+				Routes: []contour_api_v1.Route{{
+					Services: []contour_api_v1.Service{{
+						Name: "backend",
+						Port: 80,
+					}},
+				}, {
+					Conditions: []contour_api_v1.MatchCondition{{
+						Prefix: "/capture",
+					}},
+					Services: []contour_api_v1.Service{{
+						Name: "backend",
+						Port: 80,
+					}},
+					EnableCapture: captureRoute,
+				}},
+			},
+		}
+	}
+	captureDisabled := map[string]*anypb.Any{
+		"envoy.filters.http.tap": protobuf.MustMarshalAny(&envoy_route_v3.FilterConfig{Disabled: true}),
+	}
+
+	tests := map[string]struct {
+		captureEnabled bool
+		want           map[string]*envoy_route_v3.RouteConfiguration
+	}{
+		"capture not configured": {
+			captureEnabled: false,
+			want: routeConfigurations(
+				envoy_v3.RouteConfiguration("ingress_http",
+					envoy_v3.VirtualHost("capture.example.com",
+						&envoy_route_v3.Route{
+							Match:  routePrefix("/capture"),
+							Action: routecluster("default/backend/80/da39a3ee5e"),
+						},
+						&envoy_route_v3.Route{
+							Match:  routePrefix("/"),
+							Action: routecluster("default/backend/80/da39a3ee5e"),
+						},
+					),
+					envoy_v3.VirtualHost("www.example.com",
+						&envoy_route_v3.Route{
+							Match:  routePrefix("/capture"),
+							Action: routecluster("default/backend/80/da39a3ee5e"),
+						},
+						&envoy_route_v3.Route{
+							Match:  routePrefix("/"),
+							Action: routecluster("default/backend/80/da39a3ee5e"),
+						},
+					),
+				),
+			),
+		},
+		"capture configured": {
+			captureEnabled: true,
+			want: routeConfigurations(
+				envoy_v3.RouteConfiguration("ingress_http",
+					envoy_v3.VirtualHost("capture.example.com",
+						&envoy_route_v3.Route{
+							Match:  routePrefix("/capture"),
+							Action: routecluster("default/backend/80/da39a3ee5e"),
+						},
+						&envoy_route_v3.Route{
+							Match:                routePrefix("/"),
+							Action:               routecluster("default/backend/80/da39a3ee5e"),
+							TypedPerFilterConfig: captureDisabled,
+						},
+					),
+					&envoy_route_v3.VirtualHost{
+						Name:    "www.example.com",
+						Domains: []string{"www.example.com"},
+						Routes: []*envoy_route_v3.Route{{
+							Match:  routePrefix("/capture"),
+							Action: routecluster("default/backend/80/da39a3ee5e"),
+						}, {
+							Match:  routePrefix("/"),
+							Action: routecluster("default/backend/80/da39a3ee5e"),
+						}},
+						TypedPerFilterConfig: captureDisabled,
+					},
+				),
+			),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			rc := RouteCache{CaptureEnabled: tc.captureEnabled}
+			rc.OnChange(buildDAGFallback(t, nil,
+				proxy("capture", "capture.example.com", true),
+				proxy("simple", "www.example.com", false),
+				service,
+			))
+			protobuf.ExpectEqual(t, tc.want, rc.values)
+		})
+	}
+}
+
+func TestRouteVisit_RemoveServerHeader(t *testing.T) {
+	rc := RouteCache{RemoveServerHeader: true}
+	rc.OnChange(buildDAGFallback(t, nil,
+		&contour_api_v1.HTTPProxy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "simple",
+				Namespace: "default",
+			},
+			Spec: contour_api_v1.HTTPProxySpec{
+				VirtualHost: &contour_api_v1.VirtualHost{
+					Fqdn: "www.example.com",
+				},
+				Routes: []contour_api_v1.Route{{
+					Services: []contour_api_v1.Service{{
+						Name: "backend",
+						Port: 80,
+					}},
+				}},
+			},
+		},
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "backend",
+				Namespace: "default",
+			},
+			Spec: v1.ServiceSpec{
+				Ports: []v1.ServicePort{{
+					Protocol:   "TCP",
+					Port:       80,
+					TargetPort: intstr.FromInt(8080),
+				}},
+			},
+		},
+	))
+
+	want := envoy_v3.RouteConfiguration("ingress_http",
+		envoy_v3.VirtualHost("www.example.com",
+			&envoy_route_v3.Route{
+				Match:  routePrefix("/"),
+				Action: routecluster("default/backend/80/da39a3ee5e"),
+			},
+		),
+	)
+	want.ResponseHeadersToRemove = []string{"server"}
+
+	protobuf.ExpectEqual(t, routeConfigurations(want), rc.values)
+}
+
 func TestRouteVisit_GlobalExternalAuthorization(t *testing.T) {
 	tests := map[string]struct {
 		objs                []any
@@ -14,6 +14,8 @@
 package v3
 
 import (
+	"sync"
+
 	resource "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
 	"github.com/projectcontour/contour/internal/contour"
 	"github.com/projectcontour/contour/internal/dag"
@@ -24,19 +26,47 @@ import (
 
 // RuntimeCache manages the contents of the gRPC RTDS cache.
 type RuntimeCache struct {
+	mu sync.Mutex
 	contour.Cond
+
+	// Flags are the percentages of the runtime
+	// flags of routes, keyed by flag name.
+	// Use SetFlags to change them once the
+	// cache is being served.
+	Flags map[string]uint32
+
+	// Observer notifies when the flags are changed.
+	Observer contour.Observer
+}
+
+// SetFlags replaces the percentages of the runtime flags.
+func (c *RuntimeCache) SetFlags(flags map[string]uint32) {
+	c.mu.Lock()
+	c.Flags = flags
+	c.Cond.Notify()
+	c.mu.Unlock()
+
+	if c.Observer != nil {
+		c.Observer.Refresh()
+	}
 }
 
 // Contents returns all Runtime layers.
 func (c *RuntimeCache) Contents() []proto.Message {
-	return protobuf.AsMessages(envoy_v3.RuntimeLayers())
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return protobuf.AsMessages(envoy_v3.RuntimeLayers(c.Flags))
 }
 
 // Query returns only the "dynamic" layer if requested, otherwise empty.
 func (c *RuntimeCache) Query(names []string) []proto.Message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	for _, name := range names {
 		if name == envoy_v3.DynamicRuntimeLayerName {
-			return protobuf.AsMessages(envoy_v3.RuntimeLayers())
+			return protobuf.AsMessages(envoy_v3.RuntimeLayers(c.Flags))
 		}
 	}
 	return []proto.Message{}
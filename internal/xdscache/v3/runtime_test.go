@@ -17,7 +17,10 @@ import (
 	"testing"
 
 	envoy_service_runtime_v3 "github.com/envoyproxy/go-control-plane/envoy/service/runtime/v3"
+	"github.com/projectcontour/contour/internal/contour"
+	envoy_v3 "github.com/projectcontour/contour/internal/envoy/v3"
 	"github.com/projectcontour/contour/internal/protobuf"
+	"github.com/stretchr/testify/assert"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/structpb"
 )
@@ -53,6 +56,20 @@ func TestRuntimeCacheQuery(t *testing.T) {
 	}
 }
 
+func TestRuntimeCacheSetFlags(t *testing.T) {
+	var refreshed bool
+	rc := &RuntimeCache{
+		Observer: contour.ObserverFunc(func() { refreshed = true }),
+	}
+
+	rc.SetFlags(map[string]uint32{"new-checkout": 25})
+
+	assert.True(t, refreshed)
+	protobuf.ExpectEqual(t, []proto.Message{
+		envoy_v3.RuntimeLayers(map[string]uint32{"new-checkout": 25})[0],
+	}, rc.Contents())
+}
+
 func runtimeLayers() []proto.Message {
 	return []proto.Message{
 		&envoy_service_runtime_v3.Runtime{
@@ -14,18 +14,24 @@
 package v3
 
 import (
+	"errors"
 	"sort"
 	"sync"
 
+	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoy_tls_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
 	resource "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
 	"github.com/projectcontour/contour/internal/contour"
 	"github.com/projectcontour/contour/internal/dag"
 	"github.com/projectcontour/contour/internal/envoy"
 	envoy_v3 "github.com/projectcontour/contour/internal/envoy/v3"
+	"github.com/projectcontour/contour/internal/metrics"
 	"github.com/projectcontour/contour/internal/protobuf"
+	"github.com/projectcontour/contour/internal/sealing"
 	"github.com/projectcontour/contour/internal/sorter"
+	"github.com/sirupsen/logrus"
 	"google.golang.org/protobuf/proto"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 // SecretCache manages the contents of the gRPC SDS cache.
@@ -33,6 +39,21 @@ type SecretCache struct {
 	mu           sync.Mutex
 	values       map[string]*envoy_tls_v3.Secret
 	staticValues map[string]*envoy_tls_v3.Secret
+
+	// Backend provides the certificates and keys of the secrets
+	// in the DAG. If not set, Kubernetes Secrets are served.
+	Backend envoy_v3.SecretBackend
+
+	// Sealer opens the private keys that were sealed when their
+	// Secrets were cached. The cache holds them sealed, and only
+	// opens them in the responses it returns.
+	Sealer *sealing.Sealer
+
+	// Log and Metrics, if set, record the secrets that are not
+	// served because their private key can't be opened.
+	Log     logrus.FieldLogger
+	Metrics *metrics.Metrics
+
 	contour.Cond
 }
 
@@ -49,10 +70,34 @@ func NewSecretsCache(secrets []*envoy_tls_v3.Secret) *SecretCache {
 
 // Update replaces the contents of the cache with the supplied map.
 func (c *SecretCache) Update(v map[string]*envoy_tls_v3.Secret) {
+	c.update(v, nil)
+}
+
+// update replaces the contents of the cache with the supplied map,
+// dropping the secrets whose private key can't be opened. sources
+// holds the Kubernetes Secret of each secret, if known.
+func (c *SecretCache) update(v map[string]*envoy_tls_v3.Secret, sources map[string]types.NamespacedName) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.values = v
+	values := make(map[string]*envoy_tls_v3.Secret, len(v))
+	for name, secret := range v {
+		if _, err := c.open(secret); err != nil {
+			source := sources[name]
+			if c.Log != nil {
+				c.Log.WithError(err).
+					WithField("namespace", source.Namespace).
+					WithField("name", source.Name).
+					WithField("secret", name).
+					Error("dropping secret, unable to open its private key")
+			}
+			c.Metrics.SetSecretOpenFailed(source.Namespace, source.Name)
+			continue
+		}
+		values[name] = secret
+	}
+
+	c.values = values
 	c.Cond.Notify()
 }
 
@@ -62,7 +107,9 @@ func (c *SecretCache) Contents() []proto.Message {
 	defer c.mu.Unlock()
 	var values []*envoy_tls_v3.Secret
 	for _, v := range c.values {
-		values = append(values, v)
+		if v, err := c.open(v); err == nil {
+			values = append(values, v)
+		}
 	}
 	for _, v := range c.staticValues {
 		values = append(values, v)
@@ -86,23 +133,87 @@ func (c *SecretCache) Query(names []string) []proto.Message {
 				continue
 			}
 		}
-		values = append(values, v)
+		if v, err := c.open(v); err == nil {
+			values = append(values, v)
+		}
 	}
 	sort.Stable(sorter.For(values))
 	return protobuf.AsMessages(values)
 }
 
+// open returns a copy of the secret with its private key opened if it
+// is sealed, or an error if it can't be opened.
+func (c *SecretCache) open(secret *envoy_tls_v3.Secret) (*envoy_tls_v3.Secret, error) {
+	key := secret.GetTlsCertificate().GetPrivateKey().GetInlineBytes()
+	if !sealing.IsSealed(key) {
+		return secret, nil
+	}
+
+	if c.Sealer == nil {
+		return nil, errors.New("private key is sealed but no sealer is configured")
+	}
+
+	plaintext, err := c.Sealer.Open(key)
+	if err != nil {
+		return nil, err
+	}
+
+	opened := proto.Clone(secret).(*envoy_tls_v3.Secret)
+	opened.GetTlsCertificate().PrivateKey.Specifier = &envoy_core_v3.DataSource_InlineBytes{
+		InlineBytes: plaintext,
+	}
+	return opened, nil
+}
+
 func (*SecretCache) TypeURL() string { return resource.SecretType }
 
 func (c *SecretCache) OnChange(root *dag.DAG) {
 	secrets := map[string]*envoy_tls_v3.Secret{}
+	sources := map[string]types.NamespacedName{}
 
-	for _, secret := range root.GetSecrets() {
+	var backend envoy_v3.SecretBackend = envoy_v3.KubernetesSecretBackend{}
+	if c.Backend != nil {
+		backend = c.Backend
+	}
+
+	// Only the secrets served by TLS virtual hosts come from
+	// the backend. Client certificates are always Kubernetes
+	// Secrets, since they're configured by the administrator.
+	for _, secret := range root.GetServerSecrets() {
 		name := envoy.Secretname(secret)
+		if _, ok := secrets[name]; ok {
+			continue
+		}
+		if s := backend.Secret(secret); s != nil {
+			secrets[name] = s
+			sources[name] = types.NamespacedName{Namespace: secret.Namespace(), Name: secret.Name()}
+		}
+	}
+
+	// Downstream validation contexts are served as secrets, so
+	// that rotating a client CA or CRL doesn't update listeners.
+	for _, listener := range root.Listeners {
+		for _, vh := range listener.SecureVirtualHosts {
+			if vh.DownstreamValidation == nil {
+				continue
+			}
+			name := envoy.ValidationContextSecretname(vh.DownstreamValidation)
+			if _, ok := secrets[name]; !ok {
+				secrets[name] = envoy_v3.ValidationContextSecret(vh.DownstreamValidation)
+			}
+		}
+	}
+
+	for _, c := range root.GetClusters() {
+		if c.ClientCertificate == nil {
+			continue
+		}
+		name := envoy.Secretname(c.ClientCertificate)
 		if _, ok := secrets[name]; !ok {
-			secrets[name] = envoy_v3.Secret(secret)
+			secrets[name] = envoy_v3.Secret(c.ClientCertificate)
+			sources[name] = types.NamespacedName{Namespace: c.ClientCertificate.Namespace(), Name: c.ClientCertificate.Name()}
 		}
 	}
 
-	c.Update(secrets)
+	c.update(secrets, sources)
 }
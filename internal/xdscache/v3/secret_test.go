@@ -20,8 +20,15 @@ import (
 	envoy_tls_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
 	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
 	"github.com/projectcontour/contour/internal/dag"
+	envoy_v3 "github.com/projectcontour/contour/internal/envoy/v3"
 	"github.com/projectcontour/contour/internal/fixture"
+	"github.com/projectcontour/contour/internal/metrics"
 	"github.com/projectcontour/contour/internal/protobuf"
+	"github.com/projectcontour/contour/internal/sealing"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"google.golang.org/protobuf/proto"
 	v1 "k8s.io/api/core/v1"
 	networking_v1 "k8s.io/api/networking/v1"
@@ -103,6 +110,115 @@ func TestSecretCacheQuery(t *testing.T) {
 	}
 }
 
+func TestSecretCacheSealedPrivateKeys(t *testing.T) {
+	key, err := sealing.RandomDataKey()
+	require.NoError(t, err)
+	sealer, err := sealing.NewSealer(key)
+	require.NoError(t, err)
+
+	sealed := secret("default/secret/0567f551af", map[string][]byte{
+		v1.TLSCertKey:       []byte(CERTIFICATE),
+		v1.TLSPrivateKeyKey: sealer.Seal([]byte(RSA_PRIVATE_KEY)),
+	})
+	want := []proto.Message{
+		secret("default/secret/0567f551af", secretdata(CERTIFICATE, RSA_PRIVATE_KEY)),
+	}
+
+	sc := SecretCache{Sealer: sealer}
+	sc.Update(secretmap(sealed))
+
+	protobuf.ExpectEqual(t, want, sc.Contents())
+	protobuf.ExpectEqual(t, want, sc.Query([]string{"default/secret/0567f551af"}))
+
+	// The cache keeps holding the sealed private key.
+	assert.True(t, sealing.IsSealed(sc.values["default/secret/0567f551af"].GetTlsCertificate().GetPrivateKey().GetInlineBytes()))
+
+	// Sealed private keys can't be served without the sealer.
+	var unsealed SecretCache
+	unsealed.Update(secretmap(sealed))
+	assert.Empty(t, unsealed.Contents())
+
+	// Secrets whose private key can't be opened are logged and counted.
+	log, hook := test.NewNullLogger()
+	registry := prometheus.NewRegistry()
+	otherKey, err := sealing.RandomDataKey()
+	require.NoError(t, err)
+	otherSealer, err := sealing.NewSealer(otherKey)
+	require.NoError(t, err)
+
+	wrongKey := SecretCache{
+		Sealer:  otherSealer,
+		Log:     log,
+		Metrics: metrics.NewMetrics(registry),
+	}
+	wrongKey.OnChange(buildDAG(t,
+		&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "secret",
+				Namespace: "default",
+			},
+			Type: v1.SecretTypeTLS,
+			Data: map[string][]byte{
+				v1.TLSCertKey:       []byte(CERTIFICATE),
+				v1.TLSPrivateKeyKey: sealer.Seal([]byte(RSA_PRIVATE_KEY)),
+			},
+		},
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "kuard",
+				Namespace: "default",
+			},
+			Spec: v1.ServiceSpec{
+				Ports: []v1.ServicePort{{
+					Name:       "http",
+					Protocol:   "TCP",
+					Port:       8080,
+					TargetPort: intstr.FromInt(8080),
+				}},
+			},
+		},
+		&networking_v1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "simple",
+				Namespace: "default",
+			},
+			Spec: networking_v1.IngressSpec{
+				TLS: []networking_v1.IngressTLS{{
+					Hosts:      []string{"whatever.example.com"},
+					SecretName: "secret",
+				}},
+				Rules: []networking_v1.IngressRule{{
+					Host: "whatever.example.com",
+					IngressRuleValue: networking_v1.IngressRuleValue{
+						HTTP: &networking_v1.HTTPIngressRuleValue{
+							Paths: []networking_v1.HTTPIngressPath{{
+								Backend: *backend("kuard", 8080),
+							}},
+						},
+					},
+				}},
+			},
+		},
+	))
+	assert.Empty(t, wrongKey.Contents())
+
+	require.Len(t, hook.AllEntries(), 1)
+	assert.Equal(t, "default", hook.LastEntry().Data["namespace"])
+	assert.Equal(t, "secret", hook.LastEntry().Data["name"])
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	var failed float64
+	for _, family := range families {
+		if family.GetName() == metrics.SecretOpenFailed {
+			for _, m := range family.GetMetric() {
+				failed += m.GetCounter().GetValue()
+			}
+		}
+	}
+	assert.Equal(t, float64(1), failed)
+}
+
 func TestSecretVisit(t *testing.T) {
 	tests := map[string]struct {
 		objs []any
@@ -465,6 +581,73 @@ func TestSecretVisit(t *testing.T) {
 				secret("default/secret-b/5397c67313", secretdata(CERTIFICATE_2, RSA_PRIVATE_KEY_2)),
 			),
 		},
+		"httpproxy with client validation": {
+			objs: []any{
+				&v1.Service{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "backend",
+						Namespace: "default",
+					},
+					Spec: v1.ServiceSpec{
+						Ports: []v1.ServicePort{{
+							Name:       "http",
+							Protocol:   "TCP",
+							Port:       80,
+							TargetPort: intstr.FromInt(8080),
+						}},
+					},
+				},
+				&contour_api_v1.HTTPProxy{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "simple",
+						Namespace: "default",
+					},
+					Spec: contour_api_v1.HTTPProxySpec{
+						VirtualHost: &contour_api_v1.VirtualHost{
+							Fqdn: "www.example.com",
+							TLS: &contour_api_v1.TLS{
+								SecretName: "secret",
+								ClientValidation: &contour_api_v1.DownstreamValidation{
+									CACertificate: "ca",
+								},
+							},
+						},
+						Routes: []contour_api_v1.Route{{
+							Services: []contour_api_v1.Service{{
+								Name: "backend",
+								Port: 80,
+							}},
+						}},
+					},
+				},
+				tlssecret("default", "secret", secretdata(CERTIFICATE, RSA_PRIVATE_KEY)),
+				&v1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "ca",
+						Namespace: "default",
+					},
+					Type: v1.SecretTypeOpaque,
+					Data: map[string][]byte{
+						dag.CACertificateKey: []byte(CERTIFICATE_2),
+					},
+				},
+			},
+			want: secretmap(
+				secret("default/secret/0567f551af", secretdata(CERTIFICATE, RSA_PRIVATE_KEY)),
+				&envoy_tls_v3.Secret{
+					Name: "default/ca/validation/af57c31b01",
+					Type: &envoy_tls_v3.Secret_ValidationContext{
+						ValidationContext: &envoy_tls_v3.CertificateValidationContext{
+							TrustedCa: &envoy_core_v3.DataSource{
+								Specifier: &envoy_core_v3.DataSource_InlineBytes{
+									InlineBytes: []byte(CERTIFICATE_2),
+								},
+							},
+						},
+					},
+				},
+			),
+		},
 	}
 
 	for name, tc := range tests {
@@ -476,6 +659,89 @@ func TestSecretVisit(t *testing.T) {
 	}
 }
 
+func TestSecretVisitBackend(t *testing.T) {
+	objs := []any{
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "kuard",
+				Namespace: "default",
+			},
+			Spec: v1.ServiceSpec{
+				Ports: []v1.ServicePort{{
+					Name:       "http",
+					Protocol:   "TCP",
+					Port:       8080,
+					TargetPort: intstr.FromInt(8080),
+				}},
+			},
+		},
+		&networking_v1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "simple",
+				Namespace: "default",
+			},
+			Spec: networking_v1.IngressSpec{
+				TLS: []networking_v1.IngressTLS{{
+					Hosts:      []string{"whatever.example.com"},
+					SecretName: "secret",
+				}},
+				Rules: []networking_v1.IngressRule{{
+					Host: "whatever.example.com",
+					IngressRuleValue: networking_v1.IngressRuleValue{
+						HTTP: &networking_v1.HTTPIngressRuleValue{
+							Paths: []networking_v1.HTTPIngressPath{{
+								Backend: *backend("kuard", 8080),
+							}},
+						},
+					},
+				}},
+			},
+		},
+		tlssecret("default", "secret", secretdata(CERTIFICATE, RSA_PRIVATE_KEY)),
+	}
+
+	tests := map[string]struct {
+		backend envoy_v3.SecretBackend
+		want    map[string]*envoy_tls_v3.Secret
+	}{
+		"file backend": {
+			backend: envoy_v3.FileSecretBackend{Directory: "/certs"},
+			want: secretmap(&envoy_tls_v3.Secret{
+				Name: "default/secret/0567f551af",
+				Type: &envoy_tls_v3.Secret_TlsCertificate{
+					TlsCertificate: &envoy_tls_v3.TlsCertificate{
+						PrivateKey: &envoy_core_v3.DataSource{
+							Specifier: &envoy_core_v3.DataSource_Filename{
+								Filename: "/certs/default/secret/tls.key",
+							},
+						},
+						CertificateChain: &envoy_core_v3.DataSource{
+							Specifier: &envoy_core_v3.DataSource_Filename{
+								Filename: "/certs/default/secret/tls.crt",
+							},
+						},
+						WatchedDirectory: &envoy_core_v3.WatchedDirectory{
+							Path: "/certs/default/secret",
+						},
+					},
+				},
+			}),
+		},
+		"sds backend": {
+			backend: envoy_v3.SDSSecretBackend{ClusterName: "extension/projectcontour/sds"},
+			want:    map[string]*envoy_tls_v3.Secret{},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			sc := SecretCache{Backend: tc.backend}
+			sc.OnChange(buildDAG(t, objs...))
+			protobuf.ExpectEqual(t, tc.want, sc.values)
+		})
+	}
+}
+
 // buildDAG produces a dag.DAG from the supplied objects.
 func buildDAG(t *testing.T, objs ...any) *dag.DAG {
 	builder := dag.Builder{
@@ -0,0 +1,219 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// APIVersion is the schema version of a configuration file.
+type APIVersion string
+
+// APIVersionV1Alpha1 is the schema of the configuration files written
+// before the apiVersion parameter was introduced. Files that don't set
+// apiVersion are of this version.
+const APIVersionV1Alpha1 APIVersion = "v1alpha1"
+
+// APIVersionV1 is the current schema of the configuration file.
+const APIVersionV1 APIVersion = "v1"
+
+// Conversion describes a deprecated parameter of a configuration
+// file that was converted to the current schema.
+type Conversion struct {
+	// From is the dotted YAML path of the deprecated parameter.
+	From string
+
+	// To is the dotted YAML path of the parameter that replaces
+	// From, or empty if From was removed without replacement.
+	To string
+
+	// Message explains the conversion.
+	Message string
+}
+
+func (c Conversion) String() string {
+	if c.To == "" {
+		return fmt.Sprintf("%s is deprecated and was ignored: %s", c.From, c.Message)
+	}
+	return fmt.Sprintf("%s is deprecated and was converted to %s", c.From, c.To)
+}
+
+// conversions are the conversions from each previous
+// schema version to the current schema.
+var conversions = map[APIVersion][]Conversion{
+	APIVersionV1Alpha1: {{
+		From: "request-timeout",
+		To:   "timeouts.request-timeout",
+	}, {
+		From: "gateway.name",
+		To:   "gateway.gatewayRef.name",
+	}, {
+		From: "gateway.namespace",
+		To:   "gateway.gatewayRef.namespace",
+	}, {
+		From: "leaderelection.configmap-name",
+		To:   "leader-election.lease-name",
+	}, {
+		From: "leaderelection.configmap-namespace",
+		To:   "leader-election.lease-namespace",
+	}, {
+		From: "leaderelection.lease-duration",
+		To:   "leader-election.lease-duration",
+	}, {
+		From: "leaderelection.renew-deadline",
+		To:   "leader-election.renew-deadline",
+	}, {
+		From: "leaderelection.retry-period",
+		To:   "leader-election.retry-period",
+	}},
+}
+
+// convert converts doc, the top level mapping of a configuration
+// file, to the current schema, returning the conversions made.
+func convert(doc *yaml.Node) ([]Conversion, error) {
+	version := APIVersionV1Alpha1
+	if node := lookup(doc, "apiVersion"); node != nil {
+		version = APIVersion(node.Value)
+	}
+
+	switch version {
+	case APIVersionV1:
+		return nil, nil
+	case APIVersionV1Alpha1:
+	default:
+		return nil, fmt.Errorf("invalid configuration apiVersion %q, must be one of %s, %s", version, APIVersionV1Alpha1, APIVersionV1)
+	}
+
+	var converted []Conversion
+	for _, c := range conversions[version] {
+		from := strings.Split(c.From, ".")
+
+		value := remove(doc, from)
+		if value == nil {
+			continue
+		}
+
+		if c.To != "" {
+			to := strings.Split(c.To, ".")
+			if lookupPath(doc, to) != nil {
+				return nil, fmt.Errorf("deprecated parameter %s can't be set with its replacement %s", c.From, c.To)
+			}
+			insert(doc, to, value)
+		}
+
+		converted = append(converted, c)
+	}
+
+	return converted, nil
+}
+
+// lookup returns the value of key in the mapping node,
+// or nil if node isn't a mapping or doesn't have key.
+func lookup(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// lookupPath returns the value at path in the mapping
+// node, or nil if there isn't one.
+func lookupPath(node *yaml.Node, path []string) *yaml.Node {
+	for _, key := range path {
+		node = lookup(node, key)
+	}
+	return node
+}
+
+// remove removes the value at path from the mapping node and
+// returns it, or nil if there isn't one. The mappings that the
+// removal leaves empty are removed too.
+func remove(node *yaml.Node, path []string) *yaml.Node {
+	parent := lookupPath(node, path[:len(path)-1])
+	if parent == nil || parent.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	key := path[len(path)-1]
+	for i := 0; i+1 < len(parent.Content); i += 2 {
+		if parent.Content[i].Value == key {
+			value := parent.Content[i+1]
+			parent.Content = append(parent.Content[:i], parent.Content[i+2:]...)
+			if len(parent.Content) == 0 && len(path) > 1 {
+				remove(node, path[:len(path)-1])
+			}
+			return value
+		}
+	}
+	return nil
+}
+
+// insert sets the value at path in the mapping node,
+// creating the intermediate mappings that are missing.
+func insert(node *yaml.Node, path []string, value *yaml.Node) {
+	for _, key := range path[:len(path)-1] {
+		next := lookup(node, key)
+		if next == nil {
+			next = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, next)
+		}
+		if next.Kind != yaml.MappingNode {
+			// An empty parameter, such as "timeouts:".
+			*next = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		}
+		node = next
+	}
+
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: path[len(path)-1]}, value)
+}
+
+// FieldLine returns the line of the parameter at the dotted YAML
+// path field in the YAML or JSON document data, or 0 if data doesn't
+// set the parameter.
+func FieldLine(data []byte, field string) int {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		return 0
+	}
+
+	node := doc.Content[0]
+	line := 0
+	for _, key := range strings.Split(field, ".") {
+		if node == nil || node.Kind != yaml.MappingNode {
+			return 0
+		}
+
+		var value *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				line, value = node.Content[i].Line, node.Content[i+1]
+				break
+			}
+		}
+		if value == nil {
+			return 0
+		}
+		node = value
+	}
+
+	return line
+}
@@ -0,0 +1,91 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// envReference matches the references to environment variables,
+// "${NAME}" and "$(NAME)", as well as their escaped forms, "$${"
+// and "$$(".
+var envReference = regexp.MustCompile(`\$\$[{(]|\$\{([A-Za-z_][A-Za-z0-9_]*)\}|\$\(([A-Za-z_][A-Za-z0-9_]*)\)`)
+
+// interpolate replaces the references to environment variables in
+// the scalar values of node and its children with the values that
+// lookup returns for them, returning true if any value changed.
+// Mapping keys are not interpolated.
+func interpolate(node *yaml.Node, lookup func(string) (string, bool)) (bool, error) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		value, err := interpolateString(node.Value, lookup)
+		if err != nil {
+			return false, fmt.Errorf("line %d: %w", node.Line, err)
+		}
+		if value == node.Value {
+			return false, nil
+		}
+
+		node.Value = value
+		// Unquoted values are resolved again once interpolated, so
+		// that "${PORT}" can set an integer parameter.
+		if node.Style == 0 {
+			node.Tag = ""
+		}
+		return true, nil
+	case yaml.MappingNode:
+		changed := false
+		for i := 1; i < len(node.Content); i += 2 {
+			c, err := interpolate(node.Content[i], lookup)
+			if err != nil {
+				return false, err
+			}
+			changed = changed || c
+		}
+		return changed, nil
+	default:
+		changed := false
+		for _, child := range node.Content {
+			c, err := interpolate(child, lookup)
+			if err != nil {
+				return false, err
+			}
+			changed = changed || c
+		}
+		return changed, nil
+	}
+}
+
+func interpolateString(s string, lookup func(string) (string, bool)) (string, error) {
+	var err error
+
+	value := envReference.ReplaceAllStringFunc(s, func(ref string) string {
+		// An escaped reference.
+		if ref[1] == '$' {
+			return ref[1:]
+		}
+
+		name := ref[2 : len(ref)-1]
+		value, ok := lookup(name)
+		if !ok && err == nil {
+			err = fmt.Errorf("environment variable %q is not set", name)
+		}
+		return value
+	})
+
+	return value, err
+}
@@ -14,14 +14,19 @@
 package config
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
+	toml "github.com/pelletier/go-toml/v2"
 	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
 	contour_api_v1alpha1 "github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
 	"gopkg.in/yaml.v3"
@@ -94,12 +99,57 @@ const IPv4ClusterDNSFamily ClusterDNSFamilyType = "v4"
 const IPv6ClusterDNSFamily ClusterDNSFamilyType = "v6"
 const AllClusterDNSFamily ClusterDNSFamilyType = "all"
 
+// InsecureVirtualHostPolicy sets whether root HTTPProxies can
+// define virtual hosts that are only served over plain HTTP.
+type InsecureVirtualHostPolicy string
+
+func (p InsecureVirtualHostPolicy) Validate() error {
+	return contour_api_v1alpha1.InsecureVirtualHostPolicy(p).Validate()
+}
+
+const AllowedInsecureVirtualHosts InsecureVirtualHostPolicy = "allowed"
+const ExplicitInsecureVirtualHosts InsecureVirtualHostPolicy = "explicit"
+const DisallowedInsecureVirtualHosts InsecureVirtualHostPolicy = "disallowed"
+
+// ClusterStatNameFormat is the format of the names
+// that Envoy uses for the stats of clusters.
+type ClusterStatNameFormat string
+
+func (f ClusterStatNameFormat) Validate() error {
+	return contour_api_v1alpha1.ClusterStatNameFormat(f).Validate()
+}
+
+const ServiceClusterStatNameFormat ClusterStatNameFormat = "service"
+const ClusterNameClusterStatNameFormat ClusterStatNameFormat = "cluster"
+
+// ListenerDrainType defines when Envoy drains the connections of a listener.
+type ListenerDrainType string
+
+func (d ListenerDrainType) Validate() error {
+	return contour_api_v1alpha1.ListenerDrainType(d).Validate()
+}
+
+const DefaultListenerDrainType ListenerDrainType = "default"
+const ModifyOnlyListenerDrainType ListenerDrainType = "modify-only"
+
+// CompressionAlgorithm is the compression algorithm of responses.
+type CompressionAlgorithm string
+
+func (a CompressionAlgorithm) Validate() error {
+	return contour_api_v1alpha1.CompressionAlgorithm(a).Validate()
+}
+
+const GzipCompression CompressionAlgorithm = "gzip"
+const BrotliCompression CompressionAlgorithm = "brotli"
+const ZstdCompression CompressionAlgorithm = "zstd"
+const DisabledCompression CompressionAlgorithm = "disabled"
+
 // ServerHeaderTransformation defines the action to be applied to the Server header on the response path
 type ServerHeaderTransformationType string
 
 func (s ServerHeaderTransformationType) Validate() error {
 	switch s {
-	case OverwriteServerHeader, AppendIfAbsentServerHeader, PassThroughServerHeader:
+	case OverwriteServerHeader, AppendIfAbsentServerHeader, PassThroughServerHeader, RemoveServerHeader:
 		return nil
 	default:
 		return fmt.Errorf("invalid server header transformation %q", s)
@@ -109,6 +159,7 @@ func (s ServerHeaderTransformationType) Validate() error {
 const OverwriteServerHeader ServerHeaderTransformationType = "overwrite"
 const AppendIfAbsentServerHeader ServerHeaderTransformationType = "append_if_absent"
 const PassThroughServerHeader ServerHeaderTransformationType = "pass_through"
+const RemoveServerHeader ServerHeaderTransformationType = "remove"
 
 // AccessLogType is the name of a supported access logging mechanism.
 type AccessLogType string
@@ -120,6 +171,17 @@ func (a AccessLogType) Validate() error {
 const EnvoyAccessLog AccessLogType = "envoy"
 const JSONAccessLog AccessLogType = "json"
 
+// AccessLogFormatPreset is the name of a predefined access log format.
+type AccessLogFormatPreset string
+
+func (a AccessLogFormatPreset) Validate() error {
+	return contour_api_v1alpha1.AccessLogFormatPreset(a).Validate()
+}
+
+const ApacheCombinedAccessLogPreset AccessLogFormatPreset = "apache-combined"
+const W3CAccessLogPreset AccessLogFormatPreset = "w3c"
+const JSONECSAccessLogPreset AccessLogFormatPreset = "json-ecs"
+
 type AccessLogFields []string
 
 func (a AccessLogFields) Validate() error {
@@ -134,11 +196,12 @@ func (a AccessLogFields) AsFieldMap() map[string]string {
 func (p Parameters) AccessLogFormatterExtensions() []string {
 	el := &contour_api_v1alpha1.EnvoyLogging{
 		AccessLogFormat:       contour_api_v1alpha1.AccessLogType(p.AccessLogFormat),
+		AccessLogFormatPreset: contour_api_v1alpha1.AccessLogFormatPreset(p.AccessLogFormatPreset),
 		AccessLogFormatString: p.AccessLogFormatString,
 		AccessLogJSONFields:   contour_api_v1alpha1.AccessLogJSONFields(p.AccessLogFields),
 		AccessLogLevel:        contour_api_v1alpha1.AccessLogLevel(p.AccessLogLevel),
 	}
-	return el.AccessLogFormatterExtensions()
+	return el.WithAccessLogFormatPreset().AccessLogFormatterExtensions()
 }
 
 // HTTPVersionType is the name of a supported HTTP version.
@@ -225,6 +288,62 @@ type ServerParameters struct {
 	// Defines the XDSServer to use for `contour serve`.
 	// Defaults to "contour"
 	XDSServerType ServerType `yaml:"xds-server-type,omitempty"`
+
+	// Authorization configures how Envoy nodes connecting to the
+	// xDS server are authorized. If unset, any node that can connect
+	// is served.
+	Authorization *XDSAuthorizationParameters `yaml:"authorization,omitempty"`
+}
+
+// XDSAuthorizationParameters holds the configuration for authorizing
+// Envoy nodes connecting to the xDS server. A node is authorized if it
+// satisfies any of the configured methods.
+type XDSAuthorizationParameters struct {
+	// AllowedSANs is the list of Subject Alternative Names that
+	// authorize a node when present in its client certificate.
+	AllowedSANs []string `yaml:"allowed-sans,omitempty"`
+
+	// JWT authorizes nodes that present a valid JWT in their
+	// node metadata.
+	JWT *XDSJWTParameters `yaml:"jwt,omitempty"`
+}
+
+// XDSJWTParameters holds the configuration for verifying the
+// JWTs presented by Envoy nodes.
+type XDSJWTParameters struct {
+	// KeyFile is the name of a PEM file holding the public keys
+	// or certificates used to verify the JWT signatures.
+	KeyFile string `yaml:"key-file"`
+
+	// Issuer is the required "iss" claim of the JWTs.
+	Issuer string `yaml:"issuer,omitempty"`
+
+	// Audiences is the list of accepted "aud" claims of the JWTs.
+	Audiences []string `yaml:"audiences,omitempty"`
+}
+
+// Validate ensures that at least one authorization method is
+// configured and that the configured methods are complete.
+func (a *XDSAuthorizationParameters) Validate() error {
+	if a == nil {
+		return nil
+	}
+
+	if len(a.AllowedSANs) == 0 && a.JWT == nil {
+		return fmt.Errorf("invalid xDS authorization parameters specified: at least one of allowed-sans or jwt must be provided")
+	}
+
+	for _, san := range a.AllowedSANs {
+		if len(strings.TrimSpace(san)) == 0 {
+			return fmt.Errorf("invalid xDS authorization parameters specified: allowed-sans must not be blank")
+		}
+	}
+
+	if a.JWT != nil && len(strings.TrimSpace(a.JWT.KeyFile)) == 0 {
+		return fmt.Errorf("invalid xDS authorization parameters specified: jwt key-file must be provided")
+	}
+
+	return nil
 }
 
 // GatewayParameters holds the configuration for Gateway API controllers.
@@ -306,6 +425,43 @@ type TimeoutParameters struct {
 	// for more information.
 	// +optional
 	ConnectTimeout string `yaml:"connect-timeout,omitempty"`
+
+	// ResponseTimeoutReply customizes the reply that the proxy sends when the
+	// response timeout of a route expires. HTTPProxy routes can override it.
+	ResponseTimeoutReply *TimeoutReply `yaml:"response-timeout-reply,omitempty"`
+
+	// IdleTimeoutReply customizes the reply that the proxy sends when the
+	// stream idle timeout or the idle timeout of a route expires. HTTPProxy
+	// routes can override it.
+	IdleTimeoutReply *TimeoutReply `yaml:"idle-timeout-reply,omitempty"`
+}
+
+// TimeoutReply customizes the reply that the proxy sends when a timeout expires.
+type TimeoutReply struct {
+	// StatusCode is the HTTP status code of the reply. If not set,
+	// the default status code of the timeout is kept.
+	StatusCode int `yaml:"status-code,omitempty"`
+
+	// Body is the body of the reply. If not set, the
+	// default body of the timeout is kept.
+	Body string `yaml:"body,omitempty"`
+
+	// ContentType is the content type of the body.
+	// If not set, text/plain is used.
+	ContentType string `yaml:"content-type,omitempty"`
+}
+
+// Validate the timeout reply.
+func (r *TimeoutReply) Validate() error {
+	if r == nil {
+		return nil
+	}
+
+	if r.StatusCode != 0 && (r.StatusCode < 200 || r.StatusCode > 599) {
+		return fmt.Errorf("invalid status code %d: must be between 200 and 599", r.StatusCode)
+	}
+
+	return nil
 }
 
 // Validate the timeout parameters.
@@ -313,46 +469,89 @@ func (t TimeoutParameters) Validate() error {
 	// We can't use `timeout.Parse` for validation here because
 	// that would make an exported package depend on an internal
 	// package.
-	v := func(str string) error {
+	//
+	// v returns the duration of a finite timeout, or zero if the
+	// timeout is disabled or uses the Envoy default.
+	v := func(str string) (time.Duration, error) {
 		switch str {
 		case "", "infinity", "infinite":
-			return nil
+			return 0, nil
 		default:
-			_, err := time.ParseDuration(str)
-			return err
+			d, err := time.ParseDuration(str)
+			if err != nil {
+				return 0, err
+			}
+			// Envoy rejects negative durations.
+			if d < 0 {
+				return 0, errors.New("must not be negative")
+			}
+			return d, nil
 		}
 	}
 
-	if err := v(t.RequestTimeout); err != nil {
+	requestTimeout, err := v(t.RequestTimeout)
+	if err != nil {
 		return fmt.Errorf("invalid request timeout %q: %w", t.RequestTimeout, err)
 	}
 
-	if err := v(t.ConnectionIdleTimeout); err != nil {
+	if _, err := v(t.ConnectionIdleTimeout); err != nil {
 		return fmt.Errorf("connection idle timeout %q: %w", t.ConnectionIdleTimeout, err)
 	}
 
-	if err := v(t.StreamIdleTimeout); err != nil {
+	streamIdleTimeout, err := v(t.StreamIdleTimeout)
+	if err != nil {
 		return fmt.Errorf("stream idle timeout %q: %w", t.StreamIdleTimeout, err)
 	}
 
-	if err := v(t.MaxConnectionDuration); err != nil {
+	if _, err := v(t.MaxConnectionDuration); err != nil {
 		return fmt.Errorf("max connection duration %q: %w", t.MaxConnectionDuration, err)
 	}
 
-	if err := v(t.DelayedCloseTimeout); err != nil {
+	delayedCloseTimeout, err := v(t.DelayedCloseTimeout)
+	if err != nil {
 		return fmt.Errorf("delayed close timeout %q: %w", t.DelayedCloseTimeout, err)
 	}
 
-	if err := v(t.ConnectionShutdownGracePeriod); err != nil {
+	connectionShutdownGracePeriod, err := v(t.ConnectionShutdownGracePeriod)
+	if err != nil {
 		return fmt.Errorf("connection shutdown grace period %q: %w", t.ConnectionShutdownGracePeriod, err)
 	}
 
 	// ConnectTimeout is normally implicitly set to 2s in Defaults().
 	// ConnectTimeout cannot be "infinite" so use time.ParseDuration() directly instead of v().
 	if t.ConnectTimeout != "" {
-		if _, err := time.ParseDuration(t.ConnectTimeout); err != nil {
+		d, err := time.ParseDuration(t.ConnectTimeout)
+		if err != nil {
 			return fmt.Errorf("connect timeout %q: %w", t.ConnectTimeout, err)
 		}
+		// Envoy requires the connect timeout of clusters to be positive.
+		if d <= 0 {
+			return fmt.Errorf("connect timeout %q: must be greater than zero", t.ConnectTimeout)
+		}
+	}
+
+	// A stream can't be idle for longer than the whole request lasts,
+	// so the stream idle timeout would never expire.
+	if requestTimeout > 0 && streamIdleTimeout > requestTimeout {
+		return fmt.Errorf("stream idle timeout %q is longer than request timeout %q and would never expire: "+
+			"lower the stream idle timeout or raise the request timeout", t.StreamIdleTimeout, t.RequestTimeout)
+	}
+
+	// Once connection close processing starts, Envoy waits for the delayed
+	// close timeout before closing the socket, so a delayed close timeout
+	// that isn't shorter than the shutdown grace period of HTTP/2
+	// connections would keep draining connections open past it.
+	if connectionShutdownGracePeriod > 0 && delayedCloseTimeout >= connectionShutdownGracePeriod {
+		return fmt.Errorf("delayed close timeout %q must be shorter than connection shutdown grace period %q: "+
+			"lower the delayed close timeout or raise the connection shutdown grace period", t.DelayedCloseTimeout, t.ConnectionShutdownGracePeriod)
+	}
+
+	if err := t.ResponseTimeoutReply.Validate(); err != nil {
+		return fmt.Errorf("response timeout reply: %w", err)
+	}
+
+	if err := t.IdleTimeoutReply.Validate(); err != nil {
+		return fmt.Errorf("idle timeout reply: %w", err)
 	}
 
 	return nil
@@ -428,6 +627,173 @@ type ClusterParameters struct {
 	//
 	// +optional
 	PerConnectionBufferLimitBytes *uint32 `yaml:"per-connection-buffer-limit-bytes,omitempty"`
+
+	// StatNameFormat sets the format of the names that Envoy uses for
+	// the stats of clusters. Valid options are 'service' (the default,
+	// e.g. default_kuard_8080) or 'cluster' (the Envoy cluster name).
+	//
+	// +optional
+	StatNameFormat ClusterStatNameFormat `yaml:"stat-name-format,omitempty"`
+
+	// MaxStatNameLength caps the length of the names that Envoy uses for
+	// the stats of clusters. Longer names are truncated and end in a hash
+	// of the full name. If not specified, there is no limit.
+	//
+	// +optional
+	MaxStatNameLength *uint32 `yaml:"max-stat-name-length,omitempty"`
+
+	// EndpointDeregistrationDelay defines how long endpoints that are
+	// removed from a Service's Endpoints are kept in Envoy's load
+	// balancing set. Must be a valid Go duration string. If not
+	// specified, endpoints are removed immediately.
+	//
+	// +optional
+	EndpointDeregistrationDelay string `yaml:"endpoint-deregistration-delay,omitempty"`
+
+	// HTTP2Keepalive sends HTTP/2 PING frames on the connections to
+	// upstreams that are reached with HTTP/2, so that half-open
+	// connections are detected and closed. If not specified, no PING
+	// frames are sent.
+	//
+	// +optional
+	HTTP2Keepalive HTTP2KeepaliveParameters `yaml:"http2-keepalive,omitempty"`
+
+	// HTTP2 holds the settings of the connections to upstreams
+	// that are reached with HTTP/2.
+	//
+	// +optional
+	HTTP2 UpstreamHTTP2Parameters `yaml:"http2,omitempty"`
+
+	// DNSResolvers are the addresses of the DNS resolvers that
+	// externalName clusters use, formatted as <ip> or <ip>:<port>.
+	// The port defaults to 53. If not specified, the resolvers of
+	// Envoy's host are used.
+	//
+	// +optional
+	DNSResolvers []string `yaml:"dns-resolvers,omitempty"`
+
+	// DNSRefreshRate defines how often externalName clusters resolve
+	// their names again. Must be a valid Go duration string greater
+	// than 1ms. If not specified, Envoy's default of 5s applies.
+	//
+	// +optional
+	DNSRefreshRate string `yaml:"dns-refresh-rate,omitempty"`
+
+	// CircuitBreakers holds the default circuit breaker thresholds
+	// of the clusters. Services can override each threshold with
+	// their projectcontour.io/max-* annotations. If not specified,
+	// Envoy's defaults apply.
+	//
+	// +optional
+	CircuitBreakers CircuitBreakerParameters `yaml:"circuit-breakers,omitempty"`
+}
+
+// CircuitBreakerParameters hold the circuit breaker thresholds of clusters.
+// Thresholds that are not specified use Envoy's defaults.
+// See https://www.envoyproxy.io/docs/envoy/latest/api-v3/config/cluster/v3/circuit_breaker.proto
+// for more information.
+type CircuitBreakerParameters struct {
+	// MaxConnections is the maximum number of connections that
+	// Envoy opens to the endpoints of each cluster.
+	MaxConnections *uint32 `yaml:"max-connections,omitempty"`
+
+	// MaxPendingRequests is the maximum number of requests that
+	// are queued while waiting for a connection.
+	MaxPendingRequests *uint32 `yaml:"max-pending-requests,omitempty"`
+
+	// MaxRequests is the maximum number of parallel requests
+	// to the endpoints of each cluster.
+	MaxRequests *uint32 `yaml:"max-requests,omitempty"`
+
+	// MaxRetries is the maximum number of parallel retries
+	// to the endpoints of each cluster. Ignored if a retry
+	// budget is set.
+	MaxRetries *uint32 `yaml:"max-retries,omitempty"`
+
+	// RetryBudgetPercent limits the parallel retries to the
+	// endpoints of each cluster to a percentage of the active
+	// requests.
+	RetryBudgetPercent *uint32 `yaml:"retry-budget-percent,omitempty"`
+
+	// RetryBudgetMinRetryConcurrency is the number of parallel
+	// retries that are always allowed by the retry budget.
+	RetryBudgetMinRetryConcurrency *uint32 `yaml:"retry-budget-min-retry-concurrency,omitempty"`
+}
+
+// UpstreamHTTP2Parameters hold the settings of upstream HTTP/2 connections.
+// Settings that are not specified use Envoy's defaults.
+// See https://www.envoyproxy.io/docs/envoy/latest/api-v3/config/core/v3/protocol.proto#envoy-v3-api-msg-config-core-v3-http2protocoloptions
+// for more information.
+type UpstreamHTTP2Parameters struct {
+	// MaxConcurrentStreams is the maximum number of concurrent
+	// streams that Envoy opens on each upstream HTTP/2 connection.
+	MaxConcurrentStreams *uint32 `yaml:"max-concurrent-streams,omitempty"`
+
+	// InitialStreamWindowSize is the initial flow control
+	// window size of each stream, in bytes.
+	InitialStreamWindowSize *uint32 `yaml:"initial-stream-window-size,omitempty"`
+
+	// InitialConnectionWindowSize is the initial flow control
+	// window size of each connection, in bytes.
+	InitialConnectionWindowSize *uint32 `yaml:"initial-connection-window-size,omitempty"`
+}
+
+// Validate ensures that the upstream HTTP/2 settings are within Envoy's limits.
+func (p UpstreamHTTP2Parameters) Validate() error {
+	const maxInt32 = 1<<31 - 1
+
+	if v := p.MaxConcurrentStreams; v != nil && (*v < 1 || *v > maxInt32) {
+		return fmt.Errorf("invalid HTTP/2 max concurrent streams %d set on cluster, must be between 1 and %d", *v, maxInt32)
+	}
+
+	if v := p.InitialStreamWindowSize; v != nil && (*v < 65535 || *v > maxInt32) {
+		return fmt.Errorf("invalid HTTP/2 initial stream window size %d set on cluster, must be between 65535 and %d", *v, maxInt32)
+	}
+
+	if v := p.InitialConnectionWindowSize; v != nil && (*v < 65535 || *v > maxInt32) {
+		return fmt.Errorf("invalid HTTP/2 initial connection window size %d set on cluster, must be between 65535 and %d", *v, maxInt32)
+	}
+
+	return nil
+}
+
+// HTTP2KeepaliveParameters hold the settings of HTTP/2 PING
+// frames on upstream connections.
+type HTTP2KeepaliveParameters struct {
+	// Interval is how often a PING frame is sent on each connection.
+	// Must be a valid Go duration string.
+	Interval string `yaml:"interval,omitempty"`
+
+	// Timeout is how long to wait for the response to a PING frame
+	// before the connection is closed. Must be a valid Go duration
+	// string of at least 1ms.
+	Timeout string `yaml:"timeout,omitempty"`
+}
+
+// Validate ensures that both the interval and the timeout are set
+// to valid durations, or that neither is.
+func (p HTTP2KeepaliveParameters) Validate() error {
+	if p.Interval == "" && p.Timeout == "" {
+		return nil
+	}
+
+	interval, err := time.ParseDuration(p.Interval)
+	if err != nil {
+		return fmt.Errorf("invalid HTTP/2 keepalive interval %q set on cluster: %w", p.Interval, err)
+	}
+	if interval <= 0 {
+		return fmt.Errorf("invalid HTTP/2 keepalive interval %q set on cluster, must be greater than zero", p.Interval)
+	}
+
+	timeout, err := time.ParseDuration(p.Timeout)
+	if err != nil {
+		return fmt.Errorf("invalid HTTP/2 keepalive timeout %q set on cluster: %w", p.Timeout, err)
+	}
+	if timeout < time.Millisecond {
+		return fmt.Errorf("invalid HTTP/2 keepalive timeout %q set on cluster, minimum value is 1ms", p.Timeout)
+	}
+
+	return nil
 }
 
 func (p *ClusterParameters) Validate() error {
@@ -442,6 +808,56 @@ func (p *ClusterParameters) Validate() error {
 	if p.PerConnectionBufferLimitBytes != nil && *p.PerConnectionBufferLimitBytes < 1 {
 		return fmt.Errorf("invalid per connections buffer limit bytes value %q set on cluster, minimum value is 1", *p.PerConnectionBufferLimitBytes)
 	}
+
+	if err := p.StatNameFormat.Validate(); err != nil {
+		return err
+	}
+
+	if p.MaxStatNameLength != nil && *p.MaxStatNameLength < 8 {
+		return fmt.Errorf("invalid max stat name length %d set on cluster, minimum value is 8", *p.MaxStatNameLength)
+	}
+
+	if p.EndpointDeregistrationDelay != "" {
+		if _, err := time.ParseDuration(p.EndpointDeregistrationDelay); err != nil {
+			return fmt.Errorf("invalid endpoint deregistration delay %q set on cluster: %w", p.EndpointDeregistrationDelay, err)
+		}
+	}
+	if err := p.HTTP2Keepalive.Validate(); err != nil {
+		return err
+	}
+	if err := p.HTTP2.Validate(); err != nil {
+		return err
+	}
+
+	for _, resolver := range p.DNSResolvers {
+		if _, _, err := contour_api_v1alpha1.ParseDNSResolver(resolver); err != nil {
+			return fmt.Errorf("%w set on cluster", err)
+		}
+	}
+
+	if p.DNSRefreshRate != "" {
+		d, err := time.ParseDuration(p.DNSRefreshRate)
+		if err != nil {
+			return fmt.Errorf("invalid DNS refresh rate %q set on cluster: %w", p.DNSRefreshRate, err)
+		}
+		if d <= time.Millisecond {
+			return fmt.Errorf("invalid DNS refresh rate %q set on cluster, must be greater than 1ms", p.DNSRefreshRate)
+		}
+	}
+
+	circuitBreakers := contour_api_v1alpha1.CircuitBreakers{
+		MaxConnections:     p.CircuitBreakers.MaxConnections,
+		MaxPendingRequests: p.CircuitBreakers.MaxPendingRequests,
+		MaxRequests:        p.CircuitBreakers.MaxRequests,
+		MaxRetries:         p.CircuitBreakers.MaxRetries,
+
+		RetryBudgetPercent:             p.CircuitBreakers.RetryBudgetPercent,
+		RetryBudgetMinRetryConcurrency: p.CircuitBreakers.RetryBudgetMinRetryConcurrency,
+	}
+	if err := circuitBreakers.Validate(); err != nil {
+		return fmt.Errorf("%w set on cluster", err)
+	}
+
 	return nil
 }
 
@@ -480,6 +896,335 @@ type ListenerParameters struct {
 	//
 	// +optional
 	PerConnectionBufferLimitBytes *uint32 `yaml:"per-connection-buffer-limit-bytes,omitempty"`
+
+	// DrainType defines when Envoy drains the connections of a listener.
+	// Values: `default` (default), `modify-only`.
+	// See https://www.envoyproxy.io/docs/envoy/latest/api-v3/config/listener/v3/listener.proto#envoy-v3-api-enum-config-listener-v3-listener-draintype
+	// for more information.
+	DrainType ListenerDrainType `yaml:"drain-type,omitempty"`
+
+	// RemovalDelay defines how long a listener that is no longer
+	// needed keeps serving after it's replaced, so that Envoy can
+	// warm its replacement first. Must be a valid Go duration
+	// string. If not specified, listeners are removed immediately.
+	RemovalDelay string `yaml:"removal-delay,omitempty"`
+
+	// HTTP2 holds the settings of downstream HTTP/2 connections.
+	HTTP2 HTTP2Parameters `yaml:"http2,omitempty"`
+
+	// SocketOptions holds the socket options of the listeners.
+	SocketOptions SocketOptions `yaml:"socket-options,omitempty"`
+
+	// HTTPFilters are additional HTTP filters that are added to the
+	// filter chains of the HTTP connection managers, to enable Envoy
+	// filters that Contour does not configure.
+	HTTPFilters []HTTPFilter `yaml:"http-filters,omitempty"`
+
+	// HTTPConnectionManager holds the settings of the
+	// HTTP connection managers of the listeners.
+	HTTPConnectionManager HTTPConnectionManagerParameters `yaml:"http-connection-manager,omitempty"`
+}
+
+// HTTPConnectionManagerParameters hold the settings of the HTTP
+// connection managers.
+type HTTPConnectionManagerParameters struct {
+	// ServerName is the value of the Server header that Envoy sets
+	// on responses. Defaults to "envoy".
+	ServerName string `yaml:"server-name,omitempty"`
+
+	// ServerHeaderTransformation defines the action to be applied to
+	// the Server header on the response path. If set, it takes
+	// precedence over the top-level serverHeaderTransformation.
+	// Values: `overwrite`, `append_if_absent`, `pass_through`, `remove`.
+	ServerHeaderTransformation ServerHeaderTransformationType `yaml:"server-header-transformation,omitempty"`
+
+	// PreserveExternalRequestID sets whether the x-request-id header
+	// of requests from external clients is passed through instead of
+	// being replaced with a new request ID. Defaults to true.
+	PreserveExternalRequestID *bool `yaml:"preserve-external-request-id,omitempty"`
+
+	// NormalizePath sets whether the paths of requests are normalized
+	// according to RFC 3986 before they are matched against routes.
+	// Defaults to true.
+	NormalizePath *bool `yaml:"normalize-path,omitempty"`
+
+	// Via is the value that Envoy appends to the Via header of
+	// requests and responses. If not specified, the Via header
+	// is passed through unchanged.
+	Via string `yaml:"via,omitempty"`
+}
+
+func (p *HTTPConnectionManagerParameters) Validate() error {
+	if p.ServerName != "" {
+		if err := contour_api_v1alpha1.ValidateServerName(p.ServerName); err != nil {
+			return err
+		}
+	}
+
+	if p.ServerHeaderTransformation != "" {
+		if err := p.ServerHeaderTransformation.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if p.Via != "" {
+		if err := contour_api_v1alpha1.ValidateVia(p.Via); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// HTTPFilter is an additional HTTP filter of the HTTP connection managers.
+type HTTPFilter struct {
+	// Name is the name of the filter, which must be unique
+	// among the additional filters.
+	Name string `yaml:"name"`
+
+	// Position defines where the filter is added to the filter chain.
+	// Values: `before-router` (default), `after-cors`.
+	Position HTTPFilterPosition `yaml:"position,omitempty"`
+
+	// TypedConfig is the YAML or JSON representation of the typed
+	// configuration of the filter, including its `@type`.
+	TypedConfig string `yaml:"typed-config"`
+}
+
+// HTTPFilterPosition defines where an additional HTTP
+// filter is added to the filter chain.
+type HTTPFilterPosition string
+
+func (p HTTPFilterPosition) Validate() error {
+	return contour_api_v1alpha1.HTTPFilterPosition(p).Validate()
+}
+
+const BeforeRouterHTTPFilterPosition HTTPFilterPosition = "before-router"
+const AfterCORSHTTPFilterPosition HTTPFilterPosition = "after-cors"
+
+// CompressionParameters hold the settings of the response compression.
+type CompressionParameters struct {
+	// Algorithm selects the compression algorithm of responses.
+	// Values: `gzip` (default), `brotli`, `zstd`, `disabled`.
+	Algorithm CompressionAlgorithm `yaml:"algorithm,omitempty"`
+
+	// MinContentLength is the minimum length of the responses that
+	// are compressed, in bytes. If not specified, Envoy's default of
+	// 30 bytes is used.
+	MinContentLength *uint32 `yaml:"min-content-length,omitempty"`
+
+	// ContentTypes are the content types of the responses that are
+	// compressed. If not specified, text, JavaScript, JSON, XML, SVG
+	// and gRPC-Web responses are compressed.
+	ContentTypes []string `yaml:"content-types,omitempty"`
+}
+
+func (p CompressionParameters) Validate() error {
+	return (&contour_api_v1alpha1.EnvoyCompression{
+		Algorithm:        contour_api_v1alpha1.CompressionAlgorithm(p.Algorithm),
+		MinContentLength: p.MinContentLength,
+		ContentTypes:     p.ContentTypes,
+	}).Validate()
+}
+
+// ResponseMap customizes the replies that Envoy generates itself, e.g.
+// when no route matches, by their status code. Responses of upstreams
+// are not changed.
+type ResponseMap []ResponseMapping
+
+// ResponseMapping customizes the replies that Envoy
+// generates with a given status code.
+type ResponseMapping struct {
+	// StatusCode is the status code of the replies that are customized.
+	StatusCode int `yaml:"status-code"`
+
+	// RewriteStatusCode replaces the status code of the replies.
+	// If not set, the status code is kept.
+	RewriteStatusCode int `yaml:"rewrite-status-code,omitempty"`
+
+	// Body replaces the body of the replies. If not set,
+	// the body is kept.
+	Body string `yaml:"body,omitempty"`
+
+	// ContentType is the content type of the replies.
+	// If not set, text/plain is used for bodies, and
+	// application/json for the JSON format.
+	ContentType string `yaml:"content-type,omitempty"`
+
+	// JSONFormat renders the replies as JSON objects,
+	// whose values are Envoy command operators.
+	JSONFormat map[string]string `yaml:"json-format,omitempty"`
+}
+
+// Validate the response mappings.
+func (m ResponseMap) Validate() error {
+	var mappings []contour_api_v1alpha1.ResponseMapping
+	for _, mapping := range m {
+		mappings = append(mappings, contour_api_v1alpha1.ResponseMapping{
+			StatusCode:        mapping.StatusCode,
+			RewriteStatusCode: mapping.RewriteStatusCode,
+			Body:              mapping.Body,
+			JSONFormat:        mapping.JSONFormat,
+		})
+	}
+	return contour_api_v1alpha1.ValidateResponseMap(mappings)
+}
+
+// SocketOptions hold the socket options of the listeners,
+// which apply to their downstream connections.
+type SocketOptions struct {
+	// TCPKeepalive holds the settings of the TCP keepalive probes.
+	TCPKeepalive TCPKeepaliveParameters `yaml:"tcp-keepalive,omitempty"`
+
+	// TOS is the value of the IPv4 Type of Service field, including
+	// the 6 bit DSCP field, of the IPv4 packets sent by the listeners.
+	// Must be between 0 and 255.
+	TOS *int32 `yaml:"tos,omitempty"`
+
+	// TrafficClass is the value of the IPv6 Traffic Class field,
+	// including the 6 bit DSCP field, of the packets sent by IPv6
+	// listeners. Must be between 0 and 255.
+	TrafficClass *int32 `yaml:"traffic-class,omitempty"`
+
+	// Freebind allows the listeners to bind to addresses that are
+	// not configured on Envoy's host yet.
+	Freebind bool `yaml:"freebind,omitempty"`
+}
+
+// TCPKeepaliveParameters hold the settings of the TCP keepalive
+// probes. Settings that are not specified use Contour's defaults:
+// probes are sent after 45s of inactivity, every 5s, and the
+// connection is closed after 9 unanswered probes.
+type TCPKeepaliveParameters struct {
+	// Idle is how long a connection must be idle before the first
+	// probe is sent. Must be a valid Go duration string of whole
+	// seconds.
+	Idle string `yaml:"idle,omitempty"`
+
+	// Interval is the time between probes. Must be a valid Go
+	// duration string of whole seconds.
+	Interval string `yaml:"interval,omitempty"`
+
+	// Probes is the number of unanswered probes after which the
+	// connection is closed.
+	Probes *uint32 `yaml:"probes,omitempty"`
+}
+
+// Validate ensures that the socket options are within their ranges.
+func (p SocketOptions) Validate() error {
+	if p.TOS != nil && (*p.TOS < 0 || *p.TOS > 255) {
+		return fmt.Errorf("invalid listener TOS value %d, must be between 0 and 255", *p.TOS)
+	}
+	if p.TrafficClass != nil && (*p.TrafficClass < 0 || *p.TrafficClass > 255) {
+		return fmt.Errorf("invalid listener traffic class value %d, must be between 0 and 255", *p.TrafficClass)
+	}
+
+	return p.TCPKeepalive.Validate()
+}
+
+// Validate ensures that the TCP keepalive durations are whole
+// seconds and that the settings are greater than zero.
+func (p TCPKeepaliveParameters) Validate() error {
+	durations := []struct {
+		name, value string
+	}{
+		{"idle time", p.Idle},
+		{"interval", p.Interval},
+	}
+	for _, duration := range durations {
+		name, value := duration.name, duration.value
+		if value == "" {
+			continue
+		}
+
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid listener TCP keepalive %s %q: %w", name, value, err)
+		}
+		if d < time.Second || d%time.Second != 0 {
+			return fmt.Errorf("invalid listener TCP keepalive %s %q, must be a whole number of seconds", name, value)
+		}
+	}
+
+	if p.Probes != nil && *p.Probes < 1 {
+		return fmt.Errorf("invalid listener TCP keepalive probes %d, minimum value is 1", *p.Probes)
+	}
+
+	return nil
+}
+
+// HTTP2Parameters hold the settings of downstream HTTP/2 connections.
+// Settings that are not specified use Envoy's defaults.
+// See https://www.envoyproxy.io/docs/envoy/latest/api-v3/config/core/v3/protocol.proto#envoy-v3-api-msg-config-core-v3-http2protocoloptions
+// for more information.
+type HTTP2Parameters struct {
+	// MaxConcurrentStreams is the maximum number of concurrent
+	// streams that each downstream HTTP/2 connection can open.
+	MaxConcurrentStreams *uint32 `yaml:"max-concurrent-streams,omitempty"`
+
+	// InitialStreamWindowSize is the initial flow control
+	// window size of each stream, in bytes.
+	InitialStreamWindowSize *uint32 `yaml:"initial-stream-window-size,omitempty"`
+
+	// InitialConnectionWindowSize is the initial flow control
+	// window size of each connection, in bytes.
+	InitialConnectionWindowSize *uint32 `yaml:"initial-connection-window-size,omitempty"`
+
+	// MaxOutboundFrames is the maximum number of frames that
+	// can be queued for writing on each connection.
+	MaxOutboundFrames *uint32 `yaml:"max-outbound-frames,omitempty"`
+
+	// MaxOutboundControlFrames is the maximum number of PING, SETTINGS
+	// and RST_STREAM frames that can be queued for writing on each
+	// connection.
+	MaxOutboundControlFrames *uint32 `yaml:"max-outbound-control-frames,omitempty"`
+
+	// MaxConsecutiveInboundFramesWithEmptyPayload is the maximum
+	// number of consecutive frames with an empty payload and no end
+	// stream flag that each connection can receive.
+	MaxConsecutiveInboundFramesWithEmptyPayload *uint32 `yaml:"max-consecutive-inbound-frames-with-empty-payload,omitempty"`
+
+	// MaxInboundPriorityFramesPerStream is the maximum number of
+	// PRIORITY frames that each connection can receive, per stream.
+	MaxInboundPriorityFramesPerStream *uint32 `yaml:"max-inbound-priority-frames-per-stream,omitempty"`
+
+	// MaxInboundWindowUpdateFramesPerDataFrameSent is the maximum
+	// number of WINDOW_UPDATE frames that each connection can receive
+	// for each DATA frame sent.
+	MaxInboundWindowUpdateFramesPerDataFrameSent *uint32 `yaml:"max-inbound-window-update-frames-per-data-frame-sent,omitempty"`
+}
+
+// Validate ensures that the HTTP/2 settings are within Envoy's limits.
+func (p HTTP2Parameters) Validate() error {
+	const maxInt32 = 1<<31 - 1
+
+	if v := p.MaxConcurrentStreams; v != nil && (*v < 1 || *v > maxInt32) {
+		return fmt.Errorf("invalid HTTP/2 max concurrent streams %d, must be between 1 and %d", *v, maxInt32)
+	}
+
+	// The initial window sizes can't be lower
+	// than the default of the HTTP/2 protocol.
+	if v := p.InitialStreamWindowSize; v != nil && (*v < 65535 || *v > maxInt32) {
+		return fmt.Errorf("invalid HTTP/2 initial stream window size %d, must be between 65535 and %d", *v, maxInt32)
+	}
+
+	if v := p.InitialConnectionWindowSize; v != nil && (*v < 65535 || *v > maxInt32) {
+		return fmt.Errorf("invalid HTTP/2 initial connection window size %d, must be between 65535 and %d", *v, maxInt32)
+	}
+
+	if v := p.MaxOutboundFrames; v != nil && *v < 1 {
+		return fmt.Errorf("invalid HTTP/2 max outbound frames %d, minimum value is 1", *v)
+	}
+
+	if v := p.MaxOutboundControlFrames; v != nil && *v < 1 {
+		return fmt.Errorf("invalid HTTP/2 max outbound control frames %d, minimum value is 1", *v)
+	}
+
+	if v := p.MaxInboundWindowUpdateFramesPerDataFrameSent; v != nil && *v < 1 {
+		return fmt.Errorf("invalid HTTP/2 max inbound window update frames per data frame sent %d, minimum value is 1", *v)
+	}
+
+	return nil
 }
 
 func (p *ListenerParameters) Validate() error {
@@ -499,12 +1244,47 @@ func (p *ListenerParameters) Validate() error {
 		return fmt.Errorf("invalid per connections buffer limit bytes value %q set on listener, minimum value is 1", *p.PerConnectionBufferLimitBytes)
 	}
 
-	return nil
+	if err := p.DrainType.Validate(); err != nil {
+		return err
+	}
+
+	if p.RemovalDelay != "" {
+		if _, err := time.ParseDuration(p.RemovalDelay); err != nil {
+			return fmt.Errorf("invalid removal delay %q set on listener: %w", p.RemovalDelay, err)
+		}
+	}
+
+	if err := p.HTTP2.Validate(); err != nil {
+		return err
+	}
+
+	if err := p.SocketOptions.Validate(); err != nil {
+		return err
+	}
+
+	if err := p.HTTPConnectionManager.Validate(); err != nil {
+		return err
+	}
+
+	filters := make([]contour_api_v1alpha1.HTTPFilter, 0, len(p.HTTPFilters))
+	for _, f := range p.HTTPFilters {
+		filters = append(filters, contour_api_v1alpha1.HTTPFilter{
+			Name:        f.Name,
+			Position:    contour_api_v1alpha1.HTTPFilterPosition(f.Position),
+			TypedConfig: f.TypedConfig,
+		})
+	}
+	return contour_api_v1alpha1.ValidateHTTPFilters(filters)
 }
 
 // Parameters contains the configuration file parameters for the
 // Contour ingress controller.
 type Parameters struct {
+	// APIVersion is the schema version of the parameters.
+	// Parsed parameters are always of the current version,
+	// APIVersionV1.
+	APIVersion APIVersion `yaml:"apiVersion,omitempty"`
+
 	// Enable debug logging
 	Debug bool
 
@@ -514,6 +1294,11 @@ type Parameters struct {
 	KubeClientQPS   float32 `yaml:"kubernetesClientQPS,omitempty"`
 	KubeClientBurst int     `yaml:"kubernetesClientBurst,omitempty"`
 
+	// KubeClientPriorityLevel is the name of the API Priority and
+	// Fairness priority level that the requests of the Kubernetes
+	// client are assigned to, by a FlowSchema that Contour manages.
+	KubeClientPriorityLevel string `yaml:"kubernetesClientPriorityLevel,omitempty"`
+
 	// Server contains parameters for the xDS server.
 	Server ServerParameters `yaml:"server,omitempty"`
 
@@ -530,6 +1315,12 @@ type Parameters struct {
 	// Valid options are 'envoy' or 'json'
 	AccessLogFormat AccessLogType `yaml:"accesslog-format,omitempty"`
 
+	// AccessLogFormatPreset selects a predefined access log format,
+	// which replaces the AccessLogFormat, AccessLogFormatString and
+	// AccessLogFields settings.
+	// Valid options are 'apache-combined', 'w3c' or 'json-ecs'
+	AccessLogFormatPreset AccessLogFormatPreset `yaml:"accesslog-format-preset,omitempty"`
+
 	// AccessLogFormatString sets the access log format when format is set to `envoy`.
 	// When empty, Envoy's default format is used.
 	AccessLogFormatString string `yaml:"accesslog-format-string,omitempty"`
@@ -541,6 +1332,21 @@ type Parameters struct {
 	// AccessLogLevel sets the verbosity level of the access log.
 	AccessLogLevel AccessLogLevel `yaml:"accesslog-level,omitempty"`
 
+	// AccessLogPath sets the file that Envoy writes the access logs
+	// of both the HTTP and HTTPS listeners to. When empty, the
+	// --envoy-http-access-log and --envoy-https-access-log flags
+	// are used.
+	AccessLogPath string `yaml:"accesslog-path,omitempty"`
+
+	// AccessLogGRPC configures a gRPC access log service that Envoy
+	// streams the HTTP and TCP access logs to, in addition to the
+	// file based access logs.
+	AccessLogGRPC *AccessLogGRPCParameters `yaml:"accesslog-grpc,omitempty"`
+
+	// AccessLogFilter selects the requests of the access log
+	// level that are access logged.
+	AccessLogFilter *AccessLogFilterParameters `yaml:"accesslog-filter,omitempty"`
+
 	// TLS contains TLS policy parameters.
 	TLS TLSParameters `yaml:"tls,omitempty"`
 
@@ -548,6 +1354,15 @@ type Parameters struct {
 	// permitInsecure field in HTTPProxy.
 	DisablePermitInsecure bool `yaml:"disablePermitInsecure,omitempty"`
 
+	// EnableNamespaceReports enables maintaining a NamespaceReport
+	// resource in each namespace that contains HTTPProxies, summarizing
+	// the state of the HTTPProxies in that namespace.
+	EnableNamespaceReports bool `yaml:"enableNamespaceReports,omitempty"`
+
+	// InsecureVirtualHosts sets whether root HTTPProxies can define
+	// virtual hosts that are only served over plain HTTP.
+	InsecureVirtualHosts InsecureVirtualHostPolicy `yaml:"insecureVirtualHosts,omitempty"`
+
 	// DisableAllowChunkedLength disables the RFC-compliant Envoy behavior to
 	// strip the "Content-Length" header if "Transfer-Encoding: chunked" is
 	// also set. This is an emergency off-switch to revert back to Envoy's
@@ -564,6 +1379,7 @@ type Parameters struct {
 	// When configured as overwrite, overwrites any Server header with "envoy".
 	// When configured as append_if_absent, if a Server header is present, pass it through, otherwise set it to "envoy".
 	// When configured as pass_through, pass through the value of the Server header, and do not append a header if none is present.
+	// When configured as remove, remove any Server header and do not append one.
 	//
 	// Contour's default is overwrite.
 	ServerHeaderTransformation ServerHeaderTransformationType `yaml:"serverHeaderTransformation,omitempty"`
@@ -604,6 +1420,13 @@ type Parameters struct {
 	// Listener holds various configurable Envoy Listener values.
 	Listener ListenerParameters `yaml:"listener,omitempty"`
 
+	// Compression holds the settings of the response compression.
+	Compression CompressionParameters `yaml:"compression,omitempty"`
+
+	// ResponseMap customizes the replies that Envoy generates
+	// itself by their status code.
+	ResponseMap ResponseMap `yaml:"response-map,omitempty"`
+
 	// RateLimitService optionally holds properties of the Rate Limit Service
 	// to be used for global rate limiting.
 	RateLimitService RateLimitService `yaml:"rateLimitService,omitempty"`
@@ -614,11 +1437,207 @@ type Parameters struct {
 	// MetricsParameters holds configurable parameters for Contour and Envoy metrics.
 	Metrics MetricsParameters `yaml:"metrics,omitempty"`
 
+	// Health holds configurable parameters for the Envoy health listener.
+	Health HealthParameters `yaml:"health,omitempty"`
+
 	// Tracing holds the relevant configuration for exporting trace data to OpenTelemetry.
 	Tracing *Tracing `yaml:"tracing,omitempty"`
-}
 
-// Tracing defines properties for exporting trace data to OpenTelemetry.
+	// Capture optionally configures where requests and responses are
+	// captured to for HTTPProxy routes that enable capture.
+	Capture *Capture `yaml:"capture,omitempty"`
+
+	// SecretBackend optionally configures where Envoy gets the
+	// certificates and keys that it serves from.
+	SecretBackend *SecretBackend `yaml:"secret-backend,omitempty"`
+
+	// SecretEncryption optionally enables the encryption of the
+	// private keys that Contour holds in memory.
+	SecretEncryption *SecretEncryption `yaml:"secret-encryption,omitempty"`
+
+	// HTTPSRedirect optionally customizes how HTTP requests to
+	// routes that require TLS are redirected to HTTPS.
+	HTTPSRedirect *HTTPSRedirect `yaml:"https-redirect,omitempty"`
+
+	// RuntimeFlags are the percentages of the runtime
+	// flags of HTTPProxy routes, keyed by flag name.
+	RuntimeFlags RuntimeFlags `yaml:"runtime-flags,omitempty"`
+
+	// FeatureGates enables or disables feature gates, keyed
+	// by gate name. Gates that are not specified keep their
+	// default state.
+	FeatureGates map[string]bool `yaml:"feature-gates,omitempty"`
+
+	// LeaderElection configures how the replicas of Contour
+	// elect the leader that writes the status of resources.
+	LeaderElection LeaderElectionParameters `yaml:"leader-election,omitempty"`
+}
+
+// RuntimeFlags are percentages of runtime flags, keyed by flag name.
+type RuntimeFlags map[string]uint32
+
+// runtimeFlagRegex matches valid runtime flag names.
+var runtimeFlagRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]+(\.[a-zA-Z0-9_-]+)*$`)
+
+// SecretBackendType is the source of the TLS certificates served by Envoy.
+type SecretBackendType string
+
+func (t SecretBackendType) Validate() error {
+	switch t {
+	case "", KubernetesSecretBackend, FileSecretBackend, SDSSecretBackend:
+		return nil
+	default:
+		return fmt.Errorf("invalid secret backend type %q", t)
+	}
+}
+
+const KubernetesSecretBackend SecretBackendType = "kubernetes"
+const FileSecretBackend SecretBackendType = "file"
+const SDSSecretBackend SecretBackendType = "sds"
+
+// SecretBackend defines where Envoy gets the certificates
+// and keys that it serves from.
+type SecretBackend struct {
+	// Type is the secret backend to use.
+	// Values: `kubernetes` (default), `file`, `sds`.
+	Type SecretBackendType `yaml:"type,omitempty"`
+
+	// Directory is the directory on Envoy's filesystem that
+	// contains the certificates and keys, formatted as
+	// <directory>/<namespace>/<name>/tls.{crt,key}.
+	// Used by the file backend.
+	Directory string `yaml:"directory,omitempty"`
+
+	// ExtensionService identifies the extension service defining
+	// the SDS server, formatted as <namespace>/<name>.
+	// Used by the sds backend.
+	ExtensionService string `yaml:"extensionService,omitempty"`
+}
+
+// LeaderElectionResourceLock is the type of resource
+// that leader election locks.
+type LeaderElectionResourceLock string
+
+const LeasesResourceLock LeaderElectionResourceLock = "leases"
+
+func (l LeaderElectionResourceLock) Validate() error {
+	switch l {
+	case LeasesResourceLock:
+		return nil
+	default:
+		return fmt.Errorf("invalid leader election resource lock %q", l)
+	}
+}
+
+// LeaderElectionParameters configure how the replicas of Contour
+// elect the leader that writes the status of resources.
+type LeaderElectionParameters struct {
+	// Disable disables leader election, for installations
+	// that run a single replica of Contour.
+	Disable bool `yaml:"disable,omitempty"`
+
+	// LeaseName is the name of the resource that leader election locks.
+	LeaseName string `yaml:"lease-name,omitempty"`
+
+	// LeaseNamespace is the namespace of the resource
+	// that leader election locks.
+	LeaseNamespace string `yaml:"lease-namespace,omitempty"`
+
+	// ResourceLock is the type of resource that leader election locks.
+	// Values: `leases` (default).
+	ResourceLock LeaderElectionResourceLock `yaml:"resource-lock,omitempty"`
+
+	// LeaseDuration is how long the replicas that aren't the
+	// leader wait before trying to acquire the leadership.
+	LeaseDuration string `yaml:"lease-duration,omitempty"`
+
+	// RenewDeadline is how long the leader retries to renew
+	// the leadership before giving it up.
+	RenewDeadline string `yaml:"renew-deadline,omitempty"`
+
+	// RetryPeriod is how long the replicas wait between
+	// attempts to acquire or renew the leadership.
+	RetryPeriod string `yaml:"retry-period,omitempty"`
+}
+
+func (l LeaderElectionParameters) Validate() error {
+	if msgs := validation.IsDNS1123Subdomain(l.LeaseName); len(msgs) != 0 {
+		return fmt.Errorf("invalid leader election lease name %q: %v", l.LeaseName, msgs)
+	}
+	if msgs := validation.IsDNS1123Label(l.LeaseNamespace); len(msgs) != 0 {
+		return fmt.Errorf("invalid leader election lease namespace %q: %v", l.LeaseNamespace, msgs)
+	}
+	if err := l.ResourceLock.Validate(); err != nil {
+		return err
+	}
+
+	v := func(name, str string) (time.Duration, error) {
+		d, err := time.ParseDuration(str)
+		if err != nil {
+			return 0, fmt.Errorf("invalid leader election %s %q: %w", name, str, err)
+		}
+		if d <= 0 {
+			return 0, fmt.Errorf("invalid leader election %s %q: must be greater than zero", name, str)
+		}
+		return d, nil
+	}
+
+	leaseDuration, err := v("lease duration", l.LeaseDuration)
+	if err != nil {
+		return err
+	}
+	renewDeadline, err := v("renew deadline", l.RenewDeadline)
+	if err != nil {
+		return err
+	}
+	retryPeriod, err := v("retry period", l.RetryPeriod)
+	if err != nil {
+		return err
+	}
+
+	if leaseDuration <= renewDeadline {
+		return fmt.Errorf("leader election lease duration %q must be longer than renew deadline %q", l.LeaseDuration, l.RenewDeadline)
+	}
+	// Retries are jittered by up to 20%.
+	if renewDeadline <= retryPeriod*6/5 {
+		return fmt.Errorf("leader election renew deadline %q must be longer than 1.2 times retry period %q", l.RenewDeadline, l.RetryPeriod)
+	}
+
+	return nil
+}
+
+// HTTPSRedirect defines how HTTP requests are redirected to HTTPS.
+type HTTPSRedirect struct {
+	// StatusCode is the HTTP status code of the redirect response.
+	// Values: `301` (default), `308`.
+	StatusCode int `yaml:"statusCode,omitempty"`
+
+	// StripQuery removes the query string from the redirect location.
+	StripQuery bool `yaml:"stripQuery,omitempty"`
+
+	// ExcludedPathPrefixes are path prefixes that are served
+	// over HTTP instead of being redirected.
+	ExcludedPathPrefixes []string `yaml:"excludedPathPrefixes,omitempty"`
+}
+
+// Capture defines where and how much of the requests and
+// responses on routes with capture enabled are captured.
+type Capture struct {
+	// FilePathPrefix is the path prefix of the files Envoy writes
+	// each captured request and response to, as JSON.
+	FilePathPrefix string `yaml:"filePathPrefix"`
+
+	// SamplePercent is the percentage of requests to capture.
+	// the default value is 100.
+	SamplePercent *uint32 `yaml:"samplePercent,omitempty"`
+
+	// MaxBufferedBytes limits how many bytes of each request and
+	// response body are captured.
+	// the default value is Envoy's default of 1KiB.
+	MaxBufferedBytes *uint32 `yaml:"maxBufferedBytes,omitempty"`
+}
+
+// Tracing defines properties for exporting trace data to OpenTelemetry.
 type Tracing struct {
 	// IncludePodDetail defines a flag.
 	// If it is true, contour will add the pod name and namespace to the span of the trace.
@@ -645,6 +1664,184 @@ type Tracing struct {
 	// ExtensionService identifies the extension service defining the otel-collector,
 	// formatted as <namespace>/<name>.
 	ExtensionService string `yaml:"extensionService"`
+
+	// Provider defines the tracer that Envoy exports trace data with.
+	// Values: opentelemetry (default), zipkin.
+	Provider TracingProvider `yaml:"provider,omitempty"`
+
+	// Zipkin configures the zipkin tracer.
+	Zipkin *ZipkinTracing `yaml:"zipkin,omitempty"`
+
+	// ForceTrace allows clients to force the tracing of a request
+	// with the x-envoy-force-trace header.
+	ForceTrace *ForceTrace `yaml:"forceTrace,omitempty"`
+}
+
+// ForceTrace defines the clients that can force the tracing of their requests.
+type ForceTrace struct {
+	// AllowedClientCIDRs are the address ranges of the
+	// clients that can force tracing, e.g. 10.0.0.0/8.
+	AllowedClientCIDRs []string `yaml:"allowedClientCIDRs"`
+}
+
+// Validate ensures that at least one valid address range is allowed.
+func (f *ForceTrace) Validate() error {
+	if f == nil {
+		return nil
+	}
+
+	if len(f.AllowedClientCIDRs) == 0 {
+		return errors.New("tracing.forceTrace.allowedClientCIDRs must be defined")
+	}
+	for _, cidr := range f.AllowedClientCIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("invalid tracing.forceTrace.allowedClientCIDRs: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// AccessLogGRPCParameters holds the configuration of the
+// gRPC access log service.
+type AccessLogGRPCParameters struct {
+	// ExtensionService identifies the extension service defining the
+	// gRPC access log service, formatted as <namespace>/<name>. Its
+	// address and TLS settings are used to connect to the service.
+	ExtensionService string `yaml:"extensionService"`
+
+	// LogName is the name that the access log service uses
+	// to tell the access logs of Envoy apart.
+	// The default value is contour.
+	LogName string `yaml:"log-name,omitempty"`
+
+	// BufferSizeBytes is the size of the buffer of access log entries
+	// that Envoy flushes to the access log service when full.
+	// Envoy's default is used if not set.
+	BufferSizeBytes uint32 `yaml:"buffer-size-bytes,omitempty"`
+
+	// BufferFlushInterval is the interval that Envoy flushes the
+	// buffered access log entries to the access log service at.
+	// Envoy's default is used if not set.
+	BufferFlushInterval string `yaml:"buffer-flush-interval,omitempty"`
+}
+
+// Validate ensures that the gRPC access log service parameters are valid.
+func (a *AccessLogGRPCParameters) Validate() error {
+	if a == nil {
+		return nil
+	}
+
+	if a.ExtensionService == "" {
+		return errors.New("accesslog-grpc.extensionService must be defined")
+	}
+
+	if a.BufferFlushInterval != "" {
+		interval, err := time.ParseDuration(a.BufferFlushInterval)
+		if err != nil {
+			return fmt.Errorf("invalid access log buffer flush interval %q: %v", a.BufferFlushInterval, err)
+		}
+		if interval <= 0 {
+			return fmt.Errorf("invalid access log buffer flush interval %q: must be positive", a.BufferFlushInterval)
+		}
+	}
+
+	return nil
+}
+
+// AccessLogFilterParameters selects the requests that are access
+// logged. A request is logged if it matches any of the conditions
+// that are set, or if it is sampled.
+type AccessLogFilterParameters struct {
+	// MinStatusCode logs the requests whose response
+	// status code is at least the given code.
+	MinStatusCode uint32 `yaml:"min-status-code,omitempty"`
+
+	// MinDuration logs the requests that take at
+	// least the given duration.
+	MinDuration string `yaml:"min-duration,omitempty"`
+
+	// RequestHeaders logs the requests that have any
+	// of the given request headers.
+	RequestHeaders []string `yaml:"request-headers,omitempty"`
+
+	// SamplePercent logs the given percentage of
+	// the requests, chosen at random.
+	SamplePercent uint32 `yaml:"sample-percent,omitempty"`
+}
+
+// Validate ensures that the access log filter parameters are valid.
+func (a *AccessLogFilterParameters) Validate() error {
+	return a.Filter().Validate()
+}
+
+// Filter returns the access log filter that the
+// parameters configure, or nil if they configure none.
+func (a *AccessLogFilterParameters) Filter() *contour_api_v1alpha1.AccessLogFilter {
+	if a == nil {
+		return nil
+	}
+
+	filter := &contour_api_v1alpha1.AccessLogFilter{
+		RequestHeaders: a.RequestHeaders,
+	}
+	if a.MinStatusCode != 0 {
+		filter.MinStatusCode = &a.MinStatusCode
+	}
+	if a.MinDuration != "" {
+		filter.MinDuration = &a.MinDuration
+	}
+	if a.SamplePercent != 0 {
+		filter.SamplePercent = &a.SamplePercent
+	}
+
+	return filter
+}
+
+// TracingProvider is the tracer that Envoy exports trace data with.
+type TracingProvider string
+
+const (
+	OpenTelemetryTracingProvider TracingProvider = "opentelemetry"
+	ZipkinTracingProvider        TracingProvider = "zipkin"
+)
+
+func (t TracingProvider) Validate() error {
+	switch t {
+	case "", OpenTelemetryTracingProvider, ZipkinTracingProvider:
+		return nil
+	default:
+		return fmt.Errorf("invalid tracing provider %q", t)
+	}
+}
+
+// ZipkinTracing defines how trace data is exported to a Zipkin collector.
+type ZipkinTracing struct {
+	// CollectorEndpoint is the path of the API of the collector
+	// that spans are sent to.
+	// the default value is /api/v2/spans.
+	CollectorEndpoint string `yaml:"collectorEndpoint,omitempty"`
+
+	// Encoding defines the encoding of the spans.
+	// Values: json (default), proto.
+	Encoding string `yaml:"encoding,omitempty"`
+}
+
+func (z *ZipkinTracing) Validate() error {
+	if z == nil {
+		return nil
+	}
+
+	if z.CollectorEndpoint != "" && !strings.HasPrefix(z.CollectorEndpoint, "/") {
+		return fmt.Errorf("invalid tracing.zipkin.collectorEndpoint %q: must start with a slash", z.CollectorEndpoint)
+	}
+
+	switch z.Encoding {
+	case "", "json", "proto":
+		return nil
+	default:
+		return fmt.Errorf("invalid tracing.zipkin.encoding %q", z.Encoding)
+	}
 }
 
 // CustomTag defines custom tags with unique tag name
@@ -760,6 +1957,36 @@ type RateLimitService struct {
 type MetricsParameters struct {
 	Contour MetricsServerParameters `yaml:"contour,omitempty"`
 	Envoy   MetricsServerParameters `yaml:"envoy,omitempty"`
+
+	// HTTPProxy optionally enables the metrics of the requests
+	// to the upstreams of each HTTPProxy, which Contour aggregates
+	// from the stats of the Envoys.
+	HTTPProxy *HTTPProxyMetricsParameters `yaml:"httpproxy,omitempty"`
+}
+
+// HTTPProxyMetricsParameters defines how Contour aggregates
+// the metrics of HTTPProxies.
+type HTTPProxyMetricsParameters struct {
+	// ScrapeInterval is how often Contour scrapes the stats of
+	// the Envoys. Defaults to 30s.
+	ScrapeInterval string `yaml:"scrape-interval,omitempty"`
+}
+
+// Validate ensures the HTTPProxy metrics parameters are valid.
+func (p *HTTPProxyMetricsParameters) Validate() error {
+	if p == nil || p.ScrapeInterval == "" {
+		return nil
+	}
+
+	d, err := time.ParseDuration(p.ScrapeInterval)
+	if err != nil {
+		return fmt.Errorf("invalid scrape-interval %q: %w", p.ScrapeInterval, err)
+	}
+	if d <= 0 {
+		return fmt.Errorf("invalid scrape-interval %q: must be greater than zero", p.ScrapeInterval)
+	}
+
+	return nil
 }
 
 // MetricsServerParameters defines configuration for metrics server.
@@ -781,6 +2008,87 @@ type MetricsServerParameters struct {
 	// CABundle is the file path for CA certificate(s) used for validating the client certificate.
 	// Optional: required only if client certificates shall be validated to protect the metrics endpoint.
 	CABundle string `yaml:"ca-certificate-path,omitempty"`
+
+	// AllowedSANs is the list of Subject Alternative Names of which
+	// the client certificate must present one.
+	// Optional: requires ca-certificate-path.
+	AllowedSANs []string `yaml:"allowed-sans,omitempty"`
+
+	// BearerTokenPath is the file path for the bearer token that scrapers
+	// must present in the Authorization header.
+	// Optional: the file is read by Contour, also for the metrics of Envoy.
+	BearerTokenPath string `yaml:"bearer-token-path,omitempty"`
+}
+
+// HealthParameters defines configuration for health endpoints.
+type HealthParameters struct {
+	Envoy EnvoyHealthParameters `yaml:"envoy,omitempty"`
+}
+
+// EnvoyHealthParameters defines configuration for the health listener of Envoy.
+type EnvoyHealthParameters struct {
+	// Address that the health listener will bind to.
+	// Overrides the --stats-address flag.
+	Address string `yaml:"address,omitempty"`
+
+	// Port that the health listener will bind to.
+	// Overrides the --stats-port flag.
+	Port int `yaml:"port,omitempty"`
+
+	// ReadinessPath is the path of the readiness endpoint.
+	// Defaults to "/ready".
+	ReadinessPath string `yaml:"readiness-path,omitempty"`
+
+	// LivenessPath is the path of the optional liveness endpoint.
+	// If empty, there is no liveness endpoint.
+	LivenessPath string `yaml:"liveness-path,omitempty"`
+
+	// LivenessClusterMinHealthyPercentages are the minimum percentages
+	// of healthy endpoints of Envoy clusters, keyed by cluster name,
+	// below which the liveness endpoint fails.
+	LivenessClusterMinHealthyPercentages map[string]uint32 `yaml:"liveness-cluster-min-healthy-percentages,omitempty"`
+}
+
+func (p *HealthParameters) Validate() error {
+	if err := p.Envoy.Validate(); err != nil {
+		return fmt.Errorf("health.envoy: %v", err)
+	}
+
+	return nil
+}
+
+// Validate ensures the Envoy health listener parameters are valid.
+func (p *EnvoyHealthParameters) Validate() error {
+	if p.Port < 0 || p.Port > 65535 {
+		return fmt.Errorf("invalid port %d", p.Port)
+	}
+
+	if len(p.LivenessClusterMinHealthyPercentages) > 0 && p.LivenessPath == "" {
+		return fmt.Errorf("liveness-cluster-min-healthy-percentages requires liveness-path")
+	}
+
+	return p.Listener().Validate()
+}
+
+// Listener returns the health listener endpoints that the
+// parameters configure, or nil if they configure none.
+func (p *EnvoyHealthParameters) Listener() *contour_api_v1alpha1.EnvoyHealthListener {
+	if p.ReadinessPath == "" && p.LivenessPath == "" {
+		return nil
+	}
+
+	listener := &contour_api_v1alpha1.EnvoyHealthListener{}
+	if p.ReadinessPath != "" {
+		listener.ReadinessPath = &p.ReadinessPath
+	}
+	if p.LivenessPath != "" {
+		listener.Liveness = &contour_api_v1alpha1.EnvoyLivenessEndpoint{
+			Path:                         p.LivenessPath,
+			ClusterMinHealthyPercentages: p.LivenessClusterMinHealthyPercentages,
+		}
+	}
+
+	return listener
 }
 
 func (p *MetricsParameters) Validate() error {
@@ -790,6 +2098,12 @@ func (p *MetricsParameters) Validate() error {
 	if err := p.Envoy.Validate(); err != nil {
 		return fmt.Errorf("metrics.envoy: %v", err)
 	}
+	if err := p.HTTPProxy.Validate(); err != nil {
+		return fmt.Errorf("metrics.httpproxy: %v", err)
+	}
+	if p.HTTPProxy != nil && p.Envoy.HasTLS() {
+		return errors.New("metrics.httpproxy cannot be enabled when the Envoy metrics are served over HTTPS")
+	}
 
 	return nil
 }
@@ -829,6 +2143,112 @@ func (t *Tracing) Validate() error {
 		}
 		customTagNames = append(customTagNames, customTag.TagName)
 	}
+
+	if err := t.Provider.Validate(); err != nil {
+		return err
+	}
+	if t.Zipkin != nil && t.Provider != ZipkinTracingProvider {
+		return fmt.Errorf("tracing.zipkin requires the %q tracing provider", ZipkinTracingProvider)
+	}
+	if err := t.ForceTrace.Validate(); err != nil {
+		return err
+	}
+
+	return t.Zipkin.Validate()
+}
+
+func (s *SecretBackend) Validate() error {
+	if s == nil {
+		return nil
+	}
+
+	if err := s.Type.Validate(); err != nil {
+		return err
+	}
+
+	if s.Type == FileSecretBackend && s.Directory == "" {
+		return errors.New("secret-backend.directory must be defined with the file secret backend")
+	}
+
+	if s.Type == SDSSecretBackend && s.ExtensionService == "" {
+		return errors.New("secret-backend.extensionService must be defined with the sds secret backend")
+	}
+
+	return nil
+}
+
+// SecretEncryption defines how the private keys that
+// Contour holds in memory are encrypted.
+type SecretEncryption struct {
+	// DataKeyFile is the name of the file holding the data key,
+	// as 32 raw or base64 encoded bytes. If not set, a random
+	// data key is generated when Contour starts.
+	DataKeyFile string `yaml:"dataKeyFile,omitempty"`
+
+	// UnsealCommand unseals a data key file that was sealed
+	// by a KMS, reading it from standard input and writing
+	// the data key to standard output.
+	UnsealCommand []string `yaml:"unsealCommand,omitempty"`
+}
+
+func (s *SecretEncryption) Validate() error {
+	if s == nil {
+		return nil
+	}
+
+	if len(s.UnsealCommand) > 0 && s.DataKeyFile == "" {
+		return errors.New("secret-encryption.dataKeyFile must be defined with secret-encryption.unsealCommand")
+	}
+
+	return nil
+}
+
+func (h *HTTPSRedirect) Validate() error {
+	if h == nil {
+		return nil
+	}
+
+	switch h.StatusCode {
+	case 0, 301, 308:
+	default:
+		return fmt.Errorf("invalid https-redirect.statusCode %d, must be 301 or 308", h.StatusCode)
+	}
+
+	for _, prefix := range h.ExcludedPathPrefixes {
+		if !strings.HasPrefix(prefix, "/") {
+			return fmt.Errorf("invalid https-redirect.excludedPathPrefixes %q, must start with /", prefix)
+		}
+	}
+
+	return nil
+}
+
+func (f RuntimeFlags) Validate() error {
+	for flag, percent := range f {
+		if !runtimeFlagRegex.MatchString(flag) {
+			return fmt.Errorf("invalid runtime-flags flag %q", flag)
+		}
+		if percent > 100 {
+			return fmt.Errorf("invalid runtime-flags percent %d for flag %q, must be at most 100", percent, flag)
+		}
+	}
+
+	return nil
+}
+
+func (c *Capture) Validate() error {
+	if c == nil {
+		return nil
+	}
+
+	if c.FilePathPrefix == "" {
+		return errors.New("capture.filePathPrefix must be defined")
+	}
+
+	if c.SamplePercent != nil && (*c.SamplePercent < 1 || *c.SamplePercent > 100) {
+		return fmt.Errorf("invalid capture sample percent %d, must be between 1 and 100", *c.SamplePercent)
+	}
+
 	return nil
 }
 
@@ -843,6 +2263,11 @@ func (p *MetricsServerParameters) Validate() error {
 		return fmt.Errorf("you must supply also server-certificate-path and server-key-path if setting ca-certificate-path")
 	}
 
+	// Client certificate SANs can only be checked if client certificates are validated.
+	if len(p.AllowedSANs) > 0 && p.CABundle == "" {
+		return fmt.Errorf("you must supply also ca-certificate-path if setting allowed-sans")
+	}
+
 	return nil
 }
 
@@ -864,65 +2289,118 @@ const LogLevelDisabled AccessLogLevel = "disabled"
 
 // Validate verifies that the parameter values do not have any syntax errors.
 func (p *Parameters) Validate() error {
-	if err := p.Cluster.DNSLookupFamily.Validate(); err != nil {
-		return err
-	}
-
-	if err := p.Server.XDSServerType.Validate(); err != nil {
-		return err
-	}
-
-	if err := p.GatewayConfig.Validate(); err != nil {
-		return err
-	}
-
-	if err := p.AccessLogFormat.Validate(); err != nil {
-		return err
-	}
-
-	if err := p.AccessLogFields.Validate(); err != nil {
-		return err
+	for _, v := range p.validations() {
+		if err := v.validate(); err != nil {
+			return err
+		}
 	}
 
-	if err := p.AccessLogLevel.Validate(); err != nil {
-		return err
-	}
+	return nil
+}
 
-	if err := contour_api_v1alpha1.AccessLogFormatString(p.AccessLogFormatString).Validate(); err != nil {
-		return err
-	}
+// FieldError is an invalid parameter.
+type FieldError struct {
+	// Field is the dotted YAML path of the parameter.
+	Field string
 
-	if err := p.TLS.Validate(); err != nil {
-		return err
-	}
+	// Err is the reason the parameter is invalid.
+	Err error
+}
 
-	if err := p.Timeouts.Validate(); err != nil {
-		return err
-	}
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Err)
+}
 
-	if err := p.Policy.Validate(); err != nil {
-		return err
-	}
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
 
-	for _, v := range p.DefaultHTTPVersions {
-		if err := v.Validate(); err != nil {
-			return err
+// FieldErrors validates the parameters like Validate, but returns
+// every invalid parameter rather than the first one.
+func (p *Parameters) FieldErrors() []*FieldError {
+	var errs []*FieldError
+	for _, v := range p.validations() {
+		if err := v.validate(); err != nil {
+			errs = append(errs, &FieldError{Field: v.field, Err: err})
 		}
 	}
 
-	if err := p.Metrics.Validate(); err != nil {
-		return err
-	}
+	return errs
+}
 
-	if err := p.Tracing.Validate(); err != nil {
-		return err
-	}
+type parameterValidation struct {
+	field    string
+	validate func() error
+}
 
-	if err := p.Cluster.Validate(); err != nil {
-		return err
+// validations returns the validations of the parameters, in order,
+// with the YAML path of the parameters that each validates.
+func (p *Parameters) validations() []parameterValidation {
+	return []parameterValidation{
+		{"kubernetesClientQPS", func() error {
+			if p.KubeClientQPS < 0 {
+				return fmt.Errorf("invalid Kubernetes client QPS %v: must not be negative", p.KubeClientQPS)
+			}
+			return nil
+		}},
+		{"kubernetesClientBurst", func() error {
+			if p.KubeClientBurst < 0 {
+				return fmt.Errorf("invalid Kubernetes client burst %d: must not be negative", p.KubeClientBurst)
+			}
+			return nil
+		}},
+		{"kubernetesClientPriorityLevel", func() error {
+			if p.KubeClientPriorityLevel == "" {
+				return nil
+			}
+			if msgs := validation.IsDNS1123Subdomain(p.KubeClientPriorityLevel); len(msgs) != 0 {
+				return fmt.Errorf("invalid Kubernetes client priority level %q: %v", p.KubeClientPriorityLevel, msgs)
+			}
+			return nil
+		}},
+		{"cluster.dns-lookup-family", p.Cluster.DNSLookupFamily.Validate},
+		{"server.xds-server-type", p.Server.XDSServerType.Validate},
+		{"server.authorization", p.Server.Authorization.Validate},
+		{"gateway", p.GatewayConfig.Validate},
+		{"accesslog-format", p.AccessLogFormat.Validate},
+		{"accesslog-format-preset", p.AccessLogFormatPreset.Validate},
+		{"json-fields", p.AccessLogFields.Validate},
+		{"accesslog-level", p.AccessLogLevel.Validate},
+		{"accesslog-format-string", contour_api_v1alpha1.AccessLogFormatString(p.AccessLogFormatString).Validate},
+		{"accesslog-path", func() error {
+			if p.AccessLogPath != "" && !strings.HasPrefix(p.AccessLogPath, "/") {
+				return fmt.Errorf("invalid access log path %q: must be an absolute path", p.AccessLogPath)
+			}
+			return nil
+		}},
+		{"accesslog-grpc", p.AccessLogGRPC.Validate},
+		{"accesslog-filter", p.AccessLogFilter.Validate},
+		{"tls", p.TLS.Validate},
+		{"insecureVirtualHosts", p.InsecureVirtualHosts.Validate},
+		{"timeouts", p.Timeouts.Validate},
+		{"policy", p.Policy.Validate},
+		{"default-http-versions", func() error {
+			for _, v := range p.DefaultHTTPVersions {
+				if err := v.Validate(); err != nil {
+					return err
+				}
+			}
+			return nil
+		}},
+		{"metrics", p.Metrics.Validate},
+		{"health", p.Health.Validate},
+		{"tracing", p.Tracing.Validate},
+		{"capture", p.Capture.Validate},
+		{"secret-backend", p.SecretBackend.Validate},
+		{"secret-encryption", p.SecretEncryption.Validate},
+		{"https-redirect", p.HTTPSRedirect.Validate},
+		{"runtime-flags", p.RuntimeFlags.Validate},
+		{"cluster", p.Cluster.Validate},
+		{"listener", p.Listener.Validate},
+		{"compression", p.Compression.Validate},
+		{"response-map", p.ResponseMap.Validate},
+		{"leader-election", p.LeaderElection.Validate},
 	}
-
-	return p.Listener.Validate()
 }
 
 // Defaults returns the default set of parameters.
@@ -930,6 +2408,7 @@ func Defaults() Parameters {
 	contourNamespace := GetenvOr("CONTOUR_NAMESPACE", "projectcontour")
 
 	return Parameters{
+		APIVersion: APIVersionV1,
 		Debug:      false,
 		InCluster:  false,
 		Kubeconfig: filepath.Join(os.Getenv("HOME"), ".kube", "config"),
@@ -942,6 +2421,7 @@ func Defaults() Parameters {
 		AccessLogLevel:             LogLevelInfo,
 		TLS:                        TLSParameters{},
 		DisablePermitInsecure:      false,
+		EnableNamespaceReports:     false,
 		DisableAllowChunkedLength:  false,
 		DisableMergeSlashes:        false,
 		ServerHeaderTransformation: OverwriteServerHeader,
@@ -969,33 +2449,339 @@ func Defaults() Parameters {
 		Listener: ListenerParameters{
 			ConnectionBalancer: "",
 		},
+		LeaderElection: LeaderElectionParameters{
+			LeaseName:      "leader-elect",
+			LeaseNamespace: contourNamespace,
+			ResourceLock:   LeasesResourceLock,
+			LeaseDuration:  "15s",
+			RenewDeadline:  "10s",
+			RetryPeriod:    "2s",
+		},
 	}
 }
 
-// Parse reads parameters from a YAML input stream. Any parameters
-// not specified by the input are according to Defaults().
+// Parse reads parameters from a YAML or JSON input stream. Any
+// parameters not specified by the input are according to Defaults().
+//
+// As JSON is a subset of YAML, JSON documents are decoded by the
+// YAML decoder, and use the same keys as YAML documents.
+//
+// References to environment variables in the values of the input,
+// written "${NAME}" or "$(NAME)", are replaced by the values of the
+// variables. "$${" and "$$(" escape them.
+//
+// Inputs of a previous APIVersion are converted to the current one.
 func Parse(in io.Reader) (*Parameters, error) {
+	conf, _, err := ParseWithConversions(in)
+	return conf, err
+}
+
+// ParseWithConversions reads parameters from a YAML or JSON input
+// stream like Parse, and also returns the conversions of the
+// deprecated parameters of the input.
+func ParseWithConversions(in io.Reader) (*Parameters, []Conversion, error) {
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read configuration: %w", err)
+	}
+
 	conf := Defaults()
-	decoder := yaml.NewDecoder(in)
+	converted, err := decode(&conf, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	normalize(&conf)
+
+	return &conf, converted, nil
+}
+
+// decode decodes the YAML or JSON document data over conf, so that
+// the parameters that data doesn't set keep their value, and returns
+// the conversions of its deprecated parameters.
+func decode(conf *Parameters, data []byte) ([]Conversion, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse configuration: %w", err)
+	}
+
+	var converted []Conversion
+	var lines map[int]int
+	if len(doc.Content) > 0 {
+		interpolated, err := interpolate(doc.Content[0], os.LookupEnv)
+		if err != nil {
+			return nil, fmt.Errorf("failed to interpolate configuration: %w", err)
+		}
+		if converted, err = convert(doc.Content[0]); err != nil {
+			return nil, err
+		}
+		if interpolated || len(converted) > 0 {
+			if data, err = yaml.Marshal(&doc); err != nil {
+				return nil, fmt.Errorf("failed to convert configuration: %w", err)
+			}
+			lines = lineMap(&doc, data)
+		}
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
 
 	decoder.KnownFields(true)
 
-	if err := decoder.Decode(&conf); err != nil {
+	if err := decoder.Decode(conf); err != nil {
 		// The YAML decoder will return EOF if there are
 		// no YAML nodes in the results. In this case, we just
-		// want to succeed and return the defaults.
+		// want to succeed and keep the current parameters.
 		if err != io.EOF {
-			return nil, fmt.Errorf("failed to parse configuration: %w", err)
+			return nil, fmt.Errorf("failed to parse configuration: %w", remapLines(err, lines))
 		}
 	}
 
+	return converted, nil
+}
+
+// lineMap maps the lines of data, the re-marshaled doc, to the lines
+// of the original document, so that decoding errors are reported at
+// the position of the input. The nodes added by conversions take the
+// line of their parent.
+func lineMap(doc *yaml.Node, data []byte) map[int]int {
+	var remarshaled yaml.Node
+	if err := yaml.Unmarshal(data, &remarshaled); err != nil {
+		return nil
+	}
+
+	lines := map[int]int{}
+
+	var walk func(orig, node *yaml.Node, line int)
+	walk = func(orig, node *yaml.Node, line int) {
+		if orig.Line > 0 {
+			line = orig.Line
+		}
+		// Collection nodes start on the line of their first entry,
+		// whose own position, walked next, is more accurate.
+		lines[node.Line] = line
+		for i := range node.Content {
+			if i < len(orig.Content) {
+				walk(orig.Content[i], node.Content[i], line)
+			}
+		}
+	}
+	walk(doc, &remarshaled, 0)
+
+	return lines
+}
+
+// yamlErrorLine matches the line numbers of the errors of the YAML decoder.
+var yamlErrorLine = regexp.MustCompile(`^line (\d+): `)
+
+// remapLines rewrites the line numbers of the YAML decoding error
+// err according to lines, as returned by lineMap.
+func remapLines(err error, lines map[int]int) error {
+	var typeErr *yaml.TypeError
+	if len(lines) == 0 || !errors.As(err, &typeErr) {
+		return err
+	}
+
+	remapped := &yaml.TypeError{Errors: make([]string, len(typeErr.Errors))}
+	for i, msg := range typeErr.Errors {
+		remapped.Errors[i] = yamlErrorLine.ReplaceAllStringFunc(msg, func(prefix string) string {
+			line, err := strconv.Atoi(yamlErrorLine.FindStringSubmatch(prefix)[1])
+			if orig, ok := lines[line]; ok && err == nil && orig > 0 {
+				return fmt.Sprintf("line %d: ", orig)
+			}
+			return prefix
+		})
+	}
+
+	return remapped
+}
+
+// normalize completes the parameters once decoded.
+func normalize(conf *Parameters) {
+	// Parameters are always of the current version
+	// once converted.
+	conf.APIVersion = APIVersionV1
+
 	// Force the version string to match the lowercase version
 	// constants (assuming that it will match).
 	for i, v := range conf.DefaultHTTPVersions {
 		conf.DefaultHTTPVersions[i] = HTTPVersionType(strings.ToLower(string(v)))
 	}
+}
+
+// ParseTOML reads parameters from a TOML input stream. Any parameters
+// not specified by the input are according to Defaults().
+//
+// The TOML document uses the same keys as YAML documents.
+func ParseTOML(in io.Reader) (*Parameters, error) {
+	data, err := tomlToYAML(in)
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(bytes.NewReader(data))
+}
+
+// tomlToYAML converts a TOML document to YAML.
+func tomlToYAML(in io.Reader) ([]byte, error) {
+	var doc map[string]any
+	if err := toml.NewDecoder(in).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to parse configuration: %w", err)
+	}
+
+	// Rather than duplicating the YAML tags of the parameters,
+	// convert the TOML document to YAML, so that it's decoded
+	// with the same keys and strictness.
+	data, err := yaml.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse configuration: %w", err)
+	}
+
+	return data, nil
+}
+
+// ParseFile reads parameters from the file at filename. Files with
+// a ".toml" extension are read as TOML, other files as YAML or JSON.
+func ParseFile(filename string) (*Parameters, error) {
+	conf, _, err := ParseFileWithConversions(filename)
+	return conf, err
+}
+
+// ParseFileWithConversions reads parameters from the file at filename
+// like ParseFile, and also returns the conversions of the deprecated
+// parameters of the file.
+func ParseFileWithConversions(filename string) (*Parameters, []Conversion, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if isTOML(filename) {
+		if data, err = tomlToYAML(bytes.NewReader(data)); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return ParseWithConversions(bytes.NewReader(data))
+}
+
+func isTOML(filename string) bool {
+	return strings.EqualFold(filepath.Ext(filename), ".toml")
+}
+
+// ParseFiles reads parameters from the files at paths, layering them
+// in order: the parameters set by a file override the ones set by the
+// previous files, field by field, while the parameters that no file
+// sets are according to Defaults(). Lists are overridden as a whole,
+// while maps are merged.
+//
+// A path can be a directory, in which case the YAML, JSON and TOML
+// files it contains are read in the lexical order of their names.
+// Hidden files are ignored.
+func ParseFiles(paths ...string) (*Parameters, []Conversion, error) {
+	files, err := readFiles(paths)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return parseFiles(files)
+}
+
+// configFile is the content of a configuration file.
+type configFile struct {
+	name    string
+	content []byte
+}
+
+// configFileExtensions are the extensions of the files
+// read from the directories of configuration files.
+var configFileExtensions = map[string]bool{
+	".yaml": true,
+	".yml":  true,
+	".json": true,
+	".toml": true,
+}
+
+// readFiles reads the configuration files at paths, replacing
+// directories by the configuration files they contain.
+func readFiles(paths []string) ([]configFile, error) {
+	var files []configFile
+
+	read := func(name string) error {
+		content, err := os.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		files = append(files, configFile{name: name, content: content})
+		return nil
+	}
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			if err := read(path); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		// Entries are sorted by name.
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			// The kubelet keeps the content of ConfigMap
+			// volumes in hidden directories.
+			if strings.HasPrefix(entry.Name(), ".") || !configFileExtensions[strings.ToLower(filepath.Ext(entry.Name()))] {
+				continue
+			}
+
+			name := filepath.Join(path, entry.Name())
+
+			// The keys of ConfigMap volumes are symbolic
+			// links, so follow them to skip directories.
+			info, err := os.Stat(name)
+			if err != nil {
+				return nil, err
+			}
+			if info.IsDir() {
+				continue
+			}
+
+			if err := read(name); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return files, nil
+}
+
+// parseFiles reads parameters from files, layered in order.
+func parseFiles(files []configFile) (*Parameters, []Conversion, error) {
+	conf := Defaults()
+
+	var conversions []Conversion
+	for _, f := range files {
+		data := f.content
+		if isTOML(f.name) {
+			var err error
+			if data, err = tomlToYAML(bytes.NewReader(data)); err != nil {
+				return nil, nil, fmt.Errorf("%s: %w", f.name, err)
+			}
+		}
+
+		converted, err := decode(&conf, data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", f.name, err)
+		}
+		conversions = append(conversions, converted...)
+	}
+	normalize(&conf)
 
-	return &conf, nil
+	return &conf, conversions, nil
 }
 
 // GetenvOr reads an environment or return a default value
@@ -15,9 +15,11 @@ package config
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	contour_api_v1alpha1 "github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
 	"github.com/projectcontour/contour/internal/ref"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -45,6 +47,7 @@ func TestParseDefaults(t *testing.T) {
 	require.NoError(t, err)
 
 	expected := `
+apiVersion: v1
 debug: false
 kubeconfig: TestParseDefaults/.kube/config
 server:
@@ -86,6 +89,13 @@ cluster:
     dns-lookup-family: auto
 network:
     admin-port: 9001
+leader-election:
+    lease-name: leader-elect
+    lease-namespace: projectcontour
+    resource-lock: leases
+    lease-duration: 15s
+    renew-deadline: 10s
+    retry-period: 2s
 `
 	assert.Equal(t, strings.TrimSpace(expected), strings.TrimSpace(string(data)))
 
@@ -104,6 +114,14 @@ foo: bad
 `
 	_, err := Parse(strings.NewReader(badYAML))
 	require.Error(t, err)
+
+	// Misspelled keys are rejected rather than
+	// falling back to their defaults.
+	_, err = Parse(strings.NewReader("disable-merge-slashes: true\n"))
+	require.EqualError(t, err, "failed to parse configuration: yaml: unmarshal errors:\n  line 1: field disable-merge-slashes not found in type config.Parameters")
+
+	_, err = Parse(strings.NewReader("timeouts:\n  request-timeout: 30s\n  requestTimeout: 30s\n"))
+	require.EqualError(t, err, "failed to parse configuration: yaml: unmarshal errors:\n  line 3: field requestTimeout not found in type config.TimeoutParameters")
 }
 
 func TestParseApplyToIngress(t *testing.T) {
@@ -121,6 +139,410 @@ policy:
 	assert.Equal(t, &wanted, conf)
 }
 
+func TestParseConversions(t *testing.T) {
+	tests := map[string]struct {
+		in        string
+		want      func(*Parameters)
+		converted []string
+		wantErr   bool
+	}{
+		"no apiVersion, no deprecated parameters": {
+			in: `
+policy:
+  applyToIngress: true
+`,
+			want: func(p *Parameters) {
+				p.Policy.ApplyToIngress = true
+			},
+		},
+		"v1alpha1 request-timeout": {
+			in: `
+request-timeout: 30s
+timeouts:
+  connect-timeout: 5s
+`,
+			want: func(p *Parameters) {
+				p.Timeouts.RequestTimeout = "30s"
+				p.Timeouts.ConnectTimeout = "5s"
+			},
+			converted: []string{"request-timeout"},
+		},
+		"v1alpha1 empty timeouts": {
+			in: `
+apiVersion: v1alpha1
+request-timeout: 30s
+timeouts:
+`,
+			want: func(p *Parameters) {
+				p.Timeouts.RequestTimeout = "30s"
+			},
+			converted: []string{"request-timeout"},
+		},
+		"v1alpha1 gateway name and namespace": {
+			in: `
+gateway:
+  name: contour
+  namespace: projectcontour
+`,
+			want: func(p *Parameters) {
+				p.GatewayConfig = &GatewayParameters{
+					GatewayRef: &NamespacedName{Name: "contour", Namespace: "projectcontour"},
+				}
+			},
+			converted: []string{"gateway.name", "gateway.namespace"},
+		},
+		"v1alpha1 leaderelection": {
+			in: `
+leaderelection:
+  configmap-name: contour
+  configmap-namespace: contour-system
+  lease-duration: 30s
+  renew-deadline: 20s
+  retry-period: 5s
+`,
+			want: func(p *Parameters) {
+				p.LeaderElection.LeaseName = "contour"
+				p.LeaderElection.LeaseNamespace = "contour-system"
+				p.LeaderElection.LeaseDuration = "30s"
+				p.LeaderElection.RenewDeadline = "20s"
+				p.LeaderElection.RetryPeriod = "5s"
+			},
+			converted: []string{
+				"leaderelection.configmap-name",
+				"leaderelection.configmap-namespace",
+				"leaderelection.lease-duration",
+				"leaderelection.renew-deadline",
+				"leaderelection.retry-period",
+			},
+		},
+		"v1alpha1 unknown leaderelection parameter": {
+			in: `
+leaderelection:
+  configmap-name: contour
+  election-timeout: 10s
+`,
+			wantErr: true,
+		},
+		"v1alpha1 JSON": {
+			in:        `{"request-timeout": "30s"}`,
+			want:      func(p *Parameters) { p.Timeouts.RequestTimeout = "30s" },
+			converted: []string{"request-timeout"},
+		},
+		"v1alpha1 deprecated parameter set with its replacement": {
+			in: `
+request-timeout: 30s
+timeouts:
+  request-timeout: 10s
+`,
+			wantErr: true,
+		},
+		"v1 deprecated parameter": {
+			in: `
+apiVersion: v1
+request-timeout: 30s
+`,
+			wantErr: true,
+		},
+		"v1": {
+			in: `
+apiVersion: v1
+timeouts:
+  request-timeout: 30s
+`,
+			want: func(p *Parameters) {
+				p.Timeouts.RequestTimeout = "30s"
+			},
+		},
+		"unknown apiVersion": {
+			in: `
+apiVersion: v2
+`,
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			conf, converted, err := ParseWithConversions(strings.NewReader(tc.in))
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			wanted := Defaults()
+			tc.want(&wanted)
+			assert.Equal(t, &wanted, conf)
+
+			var from []string
+			for _, c := range converted {
+				from = append(from, c.From)
+			}
+			assert.Equal(t, tc.converted, from)
+		})
+	}
+}
+
+func TestParseConversionErrorPosition(t *testing.T) {
+	// The errors of a converted document are reported at
+	// the lines of the original document.
+	in := `request-timeout: 30s
+leaderelection:
+  configmap-name: contour
+unknown-field: true
+timeouts:
+  connection-idle-timeout: nope
+  unknown-timeout: 10s
+`
+	_, err := Parse(strings.NewReader(in))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line 4: field unknown-field not found")
+	assert.Contains(t, err.Error(), "line 7: field unknown-timeout not found")
+}
+
+func TestConversionString(t *testing.T) {
+	assert.Equal(t,
+		"request-timeout is deprecated and was converted to timeouts.request-timeout",
+		Conversion{From: "request-timeout", To: "timeouts.request-timeout"}.String())
+	assert.Equal(t,
+		"leaderelection is deprecated and was ignored: use flags",
+		Conversion{From: "leaderelection", Message: "use flags"}.String())
+}
+
+func TestParseInterpolation(t *testing.T) {
+	t.Setenv("CONTOUR_TEST_SERVICE_NAME", "envoy-external")
+	t.Setenv("CONTOUR_TEST_POD_NAMESPACE", "ingress")
+	t.Setenv("CONTOUR_TEST_ADMIN_PORT", "9100")
+
+	tests := map[string]struct {
+		in      string
+		want    func(*Parameters)
+		wantErr bool
+	}{
+		"braces": {
+			in: `envoy-service-name: ${CONTOUR_TEST_SERVICE_NAME}`,
+			want: func(p *Parameters) {
+				p.EnvoyServiceName = "envoy-external"
+			},
+		},
+		"parentheses": {
+			in: `envoy-service-namespace: $(CONTOUR_TEST_POD_NAMESPACE)`,
+			want: func(p *Parameters) {
+				p.EnvoyServiceNamespace = "ingress"
+			},
+		},
+		"part of a value": {
+			in: `
+gateway:
+  gatewayRef:
+    namespace: ${CONTOUR_TEST_POD_NAMESPACE}
+    name: contour-$(CONTOUR_TEST_POD_NAMESPACE)
+`,
+			want: func(p *Parameters) {
+				p.GatewayConfig = &GatewayParameters{
+					GatewayRef: &NamespacedName{Namespace: "ingress", Name: "contour-ingress"},
+				}
+			},
+		},
+		"integer": {
+			in: `
+network:
+  admin-port: ${CONTOUR_TEST_ADMIN_PORT}
+`,
+			want: func(p *Parameters) {
+				p.Network.EnvoyAdminPort = 9100
+			},
+		},
+		"sequence": {
+			in: `
+policy:
+  request-headers:
+    remove:
+    - x-${CONTOUR_TEST_POD_NAMESPACE}
+`,
+			want: func(p *Parameters) {
+				p.Policy.RequestHeadersPolicy.Remove = []string{"x-ingress"}
+			},
+		},
+		"escaped": {
+			in: `envoy-service-name: $${CONTOUR_TEST_SERVICE_NAME}-$$(CONTOUR_TEST_POD_NAMESPACE)`,
+			want: func(p *Parameters) {
+				p.EnvoyServiceName = "${CONTOUR_TEST_SERVICE_NAME}-$(CONTOUR_TEST_POD_NAMESPACE)"
+			},
+		},
+		"JSON": {
+			in: `{"envoy-service-name": "${CONTOUR_TEST_SERVICE_NAME}"}`,
+			want: func(p *Parameters) {
+				p.EnvoyServiceName = "envoy-external"
+			},
+		},
+		"unset variable": {
+			in:      `envoy-service-name: ${CONTOUR_TEST_UNSET}`,
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			conf, err := Parse(strings.NewReader(tc.in))
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			wanted := Defaults()
+			tc.want(&wanted)
+			assert.Equal(t, &wanted, conf)
+		})
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	json := `{
+	"policy": {"applyToIngress": true},
+	"default-http-versions": ["HTTP/2"],
+	"timeouts": {"request-timeout": "30s"}
+}`
+
+	conf, err := Parse(strings.NewReader(json))
+	require.NoError(t, err)
+
+	wanted := Defaults()
+	wanted.Policy.ApplyToIngress = true
+	wanted.DefaultHTTPVersions = []HTTPVersionType{HTTPVersion2}
+	wanted.Timeouts.RequestTimeout = "30s"
+
+	assert.Equal(t, &wanted, conf)
+
+	_, err = Parse(strings.NewReader(`{"foo": "bad"}`))
+	require.Error(t, err)
+}
+
+func TestParseTOML(t *testing.T) {
+	in := `
+default-http-versions = ["HTTP/2"]
+
+[policy]
+applyToIngress = true
+
+[timeouts]
+request-timeout = "30s"
+
+[network]
+admin-port = 9100
+`
+
+	conf, err := ParseTOML(strings.NewReader(in))
+	require.NoError(t, err)
+
+	wanted := Defaults()
+	wanted.Policy.ApplyToIngress = true
+	wanted.DefaultHTTPVersions = []HTTPVersionType{HTTPVersion2}
+	wanted.Timeouts.RequestTimeout = "30s"
+	wanted.Network.EnvoyAdminPort = 9100
+
+	assert.Equal(t, &wanted, conf)
+
+	_, err = ParseTOML(strings.NewReader(`foo = "bad"`))
+	require.Error(t, err)
+
+	_, err = ParseTOML(strings.NewReader(`policy = [`))
+	require.Error(t, err)
+}
+
+func TestParseFile(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"contour.yaml": "policy:\n  applyToIngress: true\n",
+		"contour.json": `{"policy": {"applyToIngress": true}}`,
+		"contour.toml": "[policy]\napplyToIngress = true\n",
+	}
+
+	wanted := Defaults()
+	wanted.Policy.ApplyToIngress = true
+
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			filename := filepath.Join(dir, name)
+			require.NoError(t, os.WriteFile(filename, []byte(content), 0600))
+
+			conf, err := ParseFile(filename)
+			require.NoError(t, err)
+			assert.Equal(t, &wanted, conf)
+		})
+	}
+
+	_, err := ParseFile(filepath.Join(dir, "missing.yaml"))
+	require.Error(t, err)
+}
+
+func TestParseFiles(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		t.Helper()
+
+		filename := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(filename), 0700))
+		require.NoError(t, os.WriteFile(filename, []byte(content), 0600))
+		return filename
+	}
+
+	base := write("base.yaml", `
+request-timeout: 30s
+accesslog-level: error
+feature-gates:
+  DeltaXDS: true
+default-http-versions:
+- http/1.1
+- http/2
+`)
+
+	// Files of a directory are layered in the order
+	// of their names.
+	overrides := filepath.Join(dir, "overrides")
+	write("overrides/20-cluster.toml", "[timeouts]\nconnect-timeout = \"5s\"\n")
+	write("overrides/10-cluster.yaml", `
+apiVersion: v1
+timeouts:
+  request-timeout: 10s
+  connect-timeout: 3s
+feature-gates:
+  GatewayAPIExperimentalRoutes: false
+default-http-versions:
+- HTTP/2
+`)
+	// Hidden files, other files and directories are ignored.
+	write("overrides/.hidden.yaml", "invalid: true\n")
+	write("overrides/README.md", "invalid: true\n")
+	write("overrides/nested.yaml/contour.yaml", "invalid: true\n")
+
+	conf, conversions, err := ParseFiles(base, overrides)
+	require.NoError(t, err)
+
+	wanted := Defaults()
+	wanted.Timeouts.RequestTimeout = "10s"
+	wanted.Timeouts.ConnectTimeout = "5s"
+	wanted.AccessLogLevel = LogLevelError
+	wanted.FeatureGates = map[string]bool{
+		"DeltaXDS":                     true,
+		"GatewayAPIExperimentalRoutes": false,
+	}
+	wanted.DefaultHTTPVersions = []HTTPVersionType{HTTPVersion2}
+	assert.Equal(t, &wanted, conf)
+
+	assert.Equal(t, []Conversion{{From: "request-timeout", To: "timeouts.request-timeout"}}, conversions)
+
+	// Errors name the file that caused them.
+	invalid := write("invalid.yaml", "\nfoo: bar\n")
+	_, _, err = ParseFiles(base, invalid)
+	require.EqualError(t, err, invalid+": failed to parse configuration: yaml: unmarshal errors:\n  line 2: field foo not found in type config.Parameters")
+
+	_, _, err = ParseFiles(base, filepath.Join(dir, "missing.yaml"))
+	require.Error(t, err)
+}
+
 func TestValidateClusterDNSFamilyType(t *testing.T) {
 	assert.Error(t, ClusterDNSFamilyType("").Validate())
 	assert.Error(t, ClusterDNSFamilyType("foo").Validate())
@@ -131,6 +553,14 @@ func TestValidateClusterDNSFamilyType(t *testing.T) {
 	assert.NoError(t, AllClusterDNSFamily.Validate())
 }
 
+func TestValidateInsecureVirtualHostPolicy(t *testing.T) {
+	assert.NoError(t, InsecureVirtualHostPolicy("").Validate())
+	assert.NoError(t, AllowedInsecureVirtualHosts.Validate())
+	assert.NoError(t, ExplicitInsecureVirtualHosts.Validate())
+	assert.NoError(t, DisallowedInsecureVirtualHosts.Validate())
+	assert.Error(t, InsecureVirtualHostPolicy("foo").Validate())
+}
+
 func TestValidateServerHeaderTranformationType(t *testing.T) {
 	assert.Error(t, ServerHeaderTransformationType("").Validate())
 	assert.Error(t, ServerHeaderTransformationType("foo").Validate())
@@ -187,6 +617,21 @@ func TestValidateServerType(t *testing.T) {
 	assert.NoError(t, ContourServerType.Validate())
 }
 
+func TestValidateXDSAuthorizationParameters(t *testing.T) {
+	// Not required if nothing is passed.
+	var authz *XDSAuthorizationParameters
+	assert.NoError(t, authz.Validate())
+
+	// At least one method is required.
+	assert.Error(t, (&XDSAuthorizationParameters{}).Validate())
+
+	assert.NoError(t, (&XDSAuthorizationParameters{AllowedSANs: []string{"envoy"}}).Validate())
+	assert.Error(t, (&XDSAuthorizationParameters{AllowedSANs: []string{""}}).Validate())
+
+	assert.NoError(t, (&XDSAuthorizationParameters{JWT: &XDSJWTParameters{KeyFile: "/keys/jwt.pem"}}).Validate())
+	assert.Error(t, (&XDSAuthorizationParameters{JWT: &XDSJWTParameters{Issuer: "issuer"}}).Validate())
+}
+
 func TestValidateGatewayParameters(t *testing.T) {
 	// Not required if nothing is passed.
 	var gw *GatewayParameters
@@ -236,6 +681,47 @@ func TestValidateTimeoutParams(t *testing.T) {
 	assert.Error(t, TimeoutParameters{ConnectionShutdownGracePeriod: "bong"}.Validate())
 	assert.Error(t, TimeoutParameters{ConnectTimeout: "infinite"}.Validate())
 
+	// Envoy rejects negative durations and a zero connect timeout.
+	assert.EqualError(t, TimeoutParameters{RequestTimeout: "-1s"}.Validate(), `invalid request timeout "-1s": must not be negative`)
+	assert.Error(t, TimeoutParameters{ConnectionIdleTimeout: "-1s"}.Validate())
+	assert.Error(t, TimeoutParameters{ConnectTimeout: "-2s"}.Validate())
+	assert.EqualError(t, TimeoutParameters{ConnectTimeout: "0s"}.Validate(), `connect timeout "0s": must be greater than zero`)
+
+	assert.NoError(t, TimeoutParameters{
+		RequestTimeout:    "30s",
+		StreamIdleTimeout: "30s",
+	}.Validate())
+	assert.NoError(t, TimeoutParameters{
+		RequestTimeout:    "infinity",
+		StreamIdleTimeout: "5m",
+	}.Validate())
+	assert.EqualError(t, TimeoutParameters{
+		RequestTimeout:    "30s",
+		StreamIdleTimeout: "5m",
+	}.Validate(), `stream idle timeout "5m" is longer than request timeout "30s" and would never expire: `+
+		`lower the stream idle timeout or raise the request timeout`)
+
+	assert.NoError(t, TimeoutParameters{
+		DelayedCloseTimeout:           "1s",
+		ConnectionShutdownGracePeriod: "5s",
+	}.Validate())
+	assert.NoError(t, TimeoutParameters{
+		DelayedCloseTimeout:           "10s",
+		ConnectionShutdownGracePeriod: "infinity",
+	}.Validate())
+	assert.EqualError(t, TimeoutParameters{
+		DelayedCloseTimeout:           "5s",
+		ConnectionShutdownGracePeriod: "5s",
+	}.Validate(), `delayed close timeout "5s" must be shorter than connection shutdown grace period "5s": `+
+		`lower the delayed close timeout or raise the connection shutdown grace period`)
+
+	assert.NoError(t, TimeoutParameters{
+		ResponseTimeoutReply: &TimeoutReply{StatusCode: 503, Body: `{"error":"timeout"}`, ContentType: "application/json"},
+		IdleTimeoutReply:     &TimeoutReply{Body: "idle timeout"},
+	}.Validate())
+	assert.Error(t, TimeoutParameters{ResponseTimeoutReply: &TimeoutReply{StatusCode: 199}}.Validate())
+	assert.Error(t, TimeoutParameters{IdleTimeoutReply: &TimeoutReply{StatusCode: 600}}.Validate())
+
 }
 
 func TestTLSParametersValidation(t *testing.T) {
@@ -303,8 +789,19 @@ server:
   xds-server-type: magic
 `)
 
+	check(`
+server:
+  authorization:
+    jwt:
+      issuer: https://kubernetes.default.svc
+`)
+
 	check(`
 accesslog-format: /dev/null
+`)
+
+	check(`
+accesslog-format-preset: nginx
 `)
 
 	check(`
@@ -318,6 +815,44 @@ json-fields:
 
 	check(`
 accesslog-level: invalid
+`)
+
+	check(`
+accesslog-path: var/log/envoy/access.log
+`)
+
+	check(`
+accesslog-grpc:
+  log-name: contour
+`)
+
+	check(`
+accesslog-filter:
+  min-status-code: 99
+`)
+
+	check(`
+metrics:
+  httpproxy:
+    scrape-interval: 0s
+`)
+
+	check(`
+metrics:
+  envoy:
+    server-certificate-path: cert.pem
+    server-key-path: key.pem
+  httpproxy: {}
+`)
+
+	check(`
+compression:
+  algorithm: deflate
+`)
+
+	check(`
+response-map:
+- status-code: 503
 `)
 
 	check(`
@@ -353,6 +888,17 @@ listener:
   connection-balancer: notexact
 `)
 
+	check(`
+kubernetesClientQPS: -1
+`)
+
+	check(`
+kubernetesClientBurst: -1
+`)
+
+	check(`
+kubernetesClientPriorityLevel: Not_A_Name
+`)
 }
 
 func TestConfigFileDefaultOverrideImport(t *testing.T) {
@@ -389,6 +935,27 @@ serverHeaderTransformation: overwrite
 tls:
 `)
 
+	check(func(t *testing.T, conf *Parameters) {
+		assert.Equal(t, &XDSAuthorizationParameters{
+			AllowedSANs: []string{"spiffe://cluster.local/ns/projectcontour/sa/envoy"},
+			JWT: &XDSJWTParameters{
+				KeyFile:   "/keys/jwt.pem",
+				Issuer:    "https://kubernetes.default.svc",
+				Audiences: []string{"contour"},
+			},
+		}, conf.Server.Authorization)
+	}, `
+server:
+  authorization:
+    allowed-sans:
+    - spiffe://cluster.local/ns/projectcontour/sa/envoy
+    jwt:
+      key-file: /keys/jwt.pem
+      issuer: https://kubernetes.default.svc
+      audiences:
+      - contour
+`)
+
 	check(func(t *testing.T, conf *Parameters) {
 		assert.Equal(t, "1.3", conf.TLS.MinimumProtocolVersion)
 		assert.Equal(t, TLSCiphers{"ECDHE-RSA-AES256-GCM-SHA384"}, conf.TLS.CipherSuites)
@@ -439,6 +1006,17 @@ listener:
 	}, `
 cluster:
   max-requests-per-connection: 1
+`)
+
+	check(func(t *testing.T, conf *Parameters) {
+		assert.Equal(t, float32(50), conf.KubeClientQPS)
+		assert.Equal(t, 100, conf.KubeClientBurst)
+		assert.Equal(t, "contour", conf.KubeClientPriorityLevel)
+		assert.NoError(t, conf.Validate())
+	}, `
+kubernetesClientQPS: 50
+kubernetesClientBurst: 100
+kubernetesClientPriorityLevel: contour
 `)
 }
 
@@ -497,6 +1075,74 @@ func TestMetricsParametersValidation(t *testing.T) {
 	}
 	assert.Error(t, tlsCAWithoutServerCert.Validate())
 
+	sansWithoutCA := MetricsParameters{
+		Contour: MetricsServerParameters{
+			Address:     "0.0.0.0",
+			Port:        1234,
+			ServerCert:  "cert.pem",
+			ServerKey:   "key.pem",
+			AllowedSANs: []string{"prometheus"},
+		},
+	}
+	assert.Error(t, sansWithoutCA.Validate())
+
+	sansWithoutCA.Contour.CABundle = "ca.pem"
+	sansWithoutCA.Contour.BearerTokenPath = "token"
+	assert.NoError(t, sansWithoutCA.Validate())
+
+	httpProxy := valid
+	httpProxy.HTTPProxy = &HTTPProxyMetricsParameters{ScrapeInterval: "15s"}
+	assert.NoError(t, httpProxy.Validate())
+
+	httpProxy.HTTPProxy.ScrapeInterval = "15"
+	assert.Error(t, httpProxy.Validate())
+
+	httpProxyTLS := tlsValid
+	httpProxyTLS.Contour, httpProxyTLS.Envoy = tlsValid.Envoy, tlsValid.Contour
+	httpProxyTLS.HTTPProxy = &HTTPProxyMetricsParameters{}
+	assert.Error(t, httpProxyTLS.Validate())
+}
+
+func TestHealthParametersValidation(t *testing.T) {
+	var h HealthParameters
+	assert.NoError(t, h.Validate())
+	assert.Nil(t, h.Envoy.Listener())
+
+	h = HealthParameters{
+		Envoy: EnvoyHealthParameters{
+			Address:       "0.0.0.0",
+			Port:          8090,
+			ReadinessPath: "/healthz/ready",
+			LivenessPath:  "/healthz/live",
+			LivenessClusterMinHealthyPercentages: map[string]uint32{
+				"default/backend/80/da39a3ee5e": 50,
+			},
+		},
+	}
+	assert.NoError(t, h.Validate())
+	assert.Equal(t, &contour_api_v1alpha1.EnvoyHealthListener{
+		ReadinessPath: ref.To("/healthz/ready"),
+		Liveness: &contour_api_v1alpha1.EnvoyLivenessEndpoint{
+			Path: "/healthz/live",
+			ClusterMinHealthyPercentages: map[string]uint32{
+				"default/backend/80/da39a3ee5e": 50,
+			},
+		},
+	}, h.Envoy.Listener())
+
+	h.Envoy.Port = 70000
+	assert.Error(t, h.Validate())
+
+	h.Envoy.Port = 8090
+	h.Envoy.LivenessPath = "/healthz/ready"
+	assert.Error(t, h.Validate())
+
+	h.Envoy.LivenessPath = ""
+	assert.Error(t, h.Validate())
+
+	h.Envoy.LivenessClusterMinHealthyPercentages = nil
+	h.Envoy.ReadinessPath = "ready"
+	assert.Error(t, h.Validate())
 }
 
 func TestListenerValidation(t *testing.T) {
@@ -530,6 +1176,144 @@ func TestListenerValidation(t *testing.T) {
 		PerConnectionBufferLimitBytes: ref.To(uint32(0)),
 	}
 	require.Error(t, l.Validate())
+	l = &ListenerParameters{
+		DrainType: ModifyOnlyListenerDrainType,
+	}
+	require.NoError(t, l.Validate())
+	l = &ListenerParameters{
+		DrainType: "drain-all",
+	}
+	require.Error(t, l.Validate())
+	l = &ListenerParameters{
+		RemovalDelay: "30s",
+	}
+	require.NoError(t, l.Validate())
+	l = &ListenerParameters{
+		RemovalDelay: "foo",
+	}
+	require.Error(t, l.Validate())
+	l = &ListenerParameters{
+		HTTP2: HTTP2Parameters{
+			MaxConcurrentStreams:                         ref.To(uint32(100)),
+			InitialStreamWindowSize:                      ref.To(uint32(65535)),
+			InitialConnectionWindowSize:                  ref.To(uint32(1048576)),
+			MaxOutboundFrames:                            ref.To(uint32(10000)),
+			MaxOutboundControlFrames:                     ref.To(uint32(1000)),
+			MaxConsecutiveInboundFramesWithEmptyPayload:  ref.To(uint32(0)),
+			MaxInboundPriorityFramesPerStream:            ref.To(uint32(0)),
+			MaxInboundWindowUpdateFramesPerDataFrameSent: ref.To(uint32(10)),
+		},
+	}
+	require.NoError(t, l.Validate())
+	l = &ListenerParameters{
+		HTTP2: HTTP2Parameters{
+			MaxConcurrentStreams: ref.To(uint32(0)),
+		},
+	}
+	require.Error(t, l.Validate())
+	l = &ListenerParameters{
+		HTTP2: HTTP2Parameters{
+			InitialStreamWindowSize: ref.To(uint32(1024)),
+		},
+	}
+	require.Error(t, l.Validate())
+	l = &ListenerParameters{
+		HTTP2: HTTP2Parameters{
+			InitialConnectionWindowSize: ref.To(uint32(1 << 31)),
+		},
+	}
+	require.Error(t, l.Validate())
+	l = &ListenerParameters{
+		HTTP2: HTTP2Parameters{
+			MaxOutboundControlFrames: ref.To(uint32(0)),
+		},
+	}
+	require.Error(t, l.Validate())
+	l = &ListenerParameters{
+		SocketOptions: SocketOptions{
+			TCPKeepalive: TCPKeepaliveParameters{
+				Idle:     "2m",
+				Interval: "10s",
+				Probes:   ref.To(uint32(3)),
+			},
+			TOS:          ref.To(int32(184)),
+			TrafficClass: ref.To(int32(0)),
+			Freebind:     true,
+		},
+	}
+	require.NoError(t, l.Validate())
+	l = &ListenerParameters{
+		SocketOptions: SocketOptions{
+			TCPKeepalive: TCPKeepaliveParameters{Idle: "1500ms"},
+		},
+	}
+	require.Error(t, l.Validate())
+	l = &ListenerParameters{
+		SocketOptions: SocketOptions{
+			TCPKeepalive: TCPKeepaliveParameters{Interval: "foo"},
+		},
+	}
+	require.Error(t, l.Validate())
+	l = &ListenerParameters{
+		SocketOptions: SocketOptions{
+			TCPKeepalive: TCPKeepaliveParameters{Probes: ref.To(uint32(0))},
+		},
+	}
+	require.Error(t, l.Validate())
+	l = &ListenerParameters{
+		SocketOptions: SocketOptions{TOS: ref.To(int32(256))},
+	}
+	require.Error(t, l.Validate())
+	l = &ListenerParameters{
+		SocketOptions: SocketOptions{TrafficClass: ref.To(int32(-1))},
+	}
+	require.Error(t, l.Validate())
+	l = &ListenerParameters{
+		HTTPFilters: []HTTPFilter{{
+			Name:        "buffer",
+			Position:    AfterCORSHTTPFilterPosition,
+			TypedConfig: `{"@type": "type.googleapis.com/envoy.extensions.filters.http.buffer.v3.Buffer"}`,
+		}},
+	}
+	require.NoError(t, l.Validate())
+	l = &ListenerParameters{
+		HTTPFilters: []HTTPFilter{{
+			Name:        "buffer",
+			Position:    "after-router",
+			TypedConfig: `{"@type": "type.googleapis.com/envoy.extensions.filters.http.buffer.v3.Buffer"}`,
+		}},
+	}
+	require.Error(t, l.Validate())
+	l = &ListenerParameters{
+		HTTPFilters: []HTTPFilter{{Name: "buffer"}},
+	}
+	require.Error(t, l.Validate())
+	l = &ListenerParameters{
+		HTTPConnectionManager: HTTPConnectionManagerParameters{
+			ServerName:                 "example",
+			ServerHeaderTransformation: PassThroughServerHeader,
+			PreserveExternalRequestID:  ref.To(false),
+			NormalizePath:              ref.To(false),
+			Via:                        "1.1 example",
+		},
+	}
+	require.NoError(t, l.Validate())
+	l = &ListenerParameters{
+		HTTPConnectionManager: HTTPConnectionManagerParameters{ServerHeaderTransformation: RemoveServerHeader},
+	}
+	require.NoError(t, l.Validate())
+	l = &ListenerParameters{
+		HTTPConnectionManager: HTTPConnectionManagerParameters{Via: " 1.1 example"},
+	}
+	require.Error(t, l.Validate())
+	l = &ListenerParameters{
+		HTTPConnectionManager: HTTPConnectionManagerParameters{ServerName: "example\n"},
+	}
+	require.Error(t, l.Validate())
+	l = &ListenerParameters{
+		HTTPConnectionManager: HTTPConnectionManagerParameters{ServerHeaderTransformation: "drop"},
+	}
+	require.Error(t, l.Validate())
 }
 
 func TestClusterParametersValidation(t *testing.T) {
@@ -550,6 +1334,132 @@ func TestClusterParametersValidation(t *testing.T) {
 		PerConnectionBufferLimitBytes: ref.To(uint32(1)),
 	}
 	require.NoError(t, l.Validate())
+	l = &ClusterParameters{
+		StatNameFormat: ClusterNameClusterStatNameFormat,
+	}
+	require.NoError(t, l.Validate())
+	l = &ClusterParameters{
+		StatNameFormat: "foo",
+	}
+	require.Error(t, l.Validate())
+	l = &ClusterParameters{
+		MaxStatNameLength: ref.To(uint32(7)),
+	}
+	require.Error(t, l.Validate())
+	l = &ClusterParameters{
+		MaxStatNameLength: ref.To(uint32(8)),
+	}
+	require.NoError(t, l.Validate())
+	l = &ClusterParameters{
+		EndpointDeregistrationDelay: "10s",
+	}
+	require.NoError(t, l.Validate())
+	l = &ClusterParameters{
+		EndpointDeregistrationDelay: "foo",
+	}
+	require.Error(t, l.Validate())
+	l = &ClusterParameters{
+		HTTP2Keepalive: HTTP2KeepaliveParameters{
+			Interval: "30s",
+			Timeout:  "5s",
+		},
+	}
+	require.NoError(t, l.Validate())
+	l = &ClusterParameters{
+		HTTP2Keepalive: HTTP2KeepaliveParameters{
+			Interval: "30s",
+		},
+	}
+	require.Error(t, l.Validate())
+	l = &ClusterParameters{
+		HTTP2Keepalive: HTTP2KeepaliveParameters{
+			Interval: "0s",
+			Timeout:  "5s",
+		},
+	}
+	require.Error(t, l.Validate())
+	l = &ClusterParameters{
+		HTTP2Keepalive: HTTP2KeepaliveParameters{
+			Interval: "30s",
+			Timeout:  "100us",
+		},
+	}
+	require.Error(t, l.Validate())
+	l = &ClusterParameters{
+		HTTP2: UpstreamHTTP2Parameters{
+			MaxConcurrentStreams:        ref.To(uint32(100)),
+			InitialStreamWindowSize:     ref.To(uint32(65535)),
+			InitialConnectionWindowSize: ref.To(uint32(1048576)),
+		},
+	}
+	require.NoError(t, l.Validate())
+	l = &ClusterParameters{
+		HTTP2: UpstreamHTTP2Parameters{
+			MaxConcurrentStreams: ref.To(uint32(0)),
+		},
+	}
+	require.Error(t, l.Validate())
+	l = &ClusterParameters{
+		HTTP2: UpstreamHTTP2Parameters{
+			InitialStreamWindowSize: ref.To(uint32(1024)),
+		},
+	}
+	require.Error(t, l.Validate())
+	l = &ClusterParameters{
+		HTTP2: UpstreamHTTP2Parameters{
+			InitialConnectionWindowSize: ref.To(uint32(1 << 31)),
+		},
+	}
+	require.Error(t, l.Validate())
+	l = &ClusterParameters{
+		DNSResolvers:   []string{"10.0.0.10", "10.0.0.11:5353", "fd00::10", "[fd00::11]:5353"},
+		DNSRefreshRate: "30s",
+	}
+	require.NoError(t, l.Validate())
+	l = &ClusterParameters{
+		DNSResolvers: []string{"dns.example.com"},
+	}
+	require.Error(t, l.Validate())
+	l = &ClusterParameters{
+		DNSResolvers: []string{"10.0.0.10:0"},
+	}
+	require.Error(t, l.Validate())
+	l = &ClusterParameters{
+		DNSRefreshRate: "foo",
+	}
+	require.Error(t, l.Validate())
+	l = &ClusterParameters{
+		DNSRefreshRate: "1ms",
+	}
+	require.Error(t, l.Validate())
+	l = &ClusterParameters{
+		CircuitBreakers: CircuitBreakerParameters{
+			MaxConnections:     ref.To(uint32(10000)),
+			MaxPendingRequests: ref.To(uint32(10000)),
+			MaxRequests:        ref.To(uint32(10000)),
+			MaxRetries:         ref.To(uint32(10)),
+		},
+	}
+	require.NoError(t, l.Validate())
+	l = &ClusterParameters{
+		CircuitBreakers: CircuitBreakerParameters{
+			MaxPendingRequests: ref.To(uint32(0)),
+		},
+	}
+	require.Error(t, l.Validate())
+	l = &ClusterParameters{
+		CircuitBreakers: CircuitBreakerParameters{
+			RetryBudgetPercent:             ref.To(uint32(20)),
+			RetryBudgetMinRetryConcurrency: ref.To(uint32(3)),
+		},
+	}
+	require.NoError(t, l.Validate())
+	l = &ClusterParameters{
+		CircuitBreakers: CircuitBreakerParameters{
+			RetryBudgetPercent: ref.To(uint32(200)),
+		},
+	}
+	require.Error(t, l.Validate())
 }
 
 func TestTracingConfigValidation(t *testing.T) {
@@ -627,4 +1537,299 @@ func TestTracingConfigValidation(t *testing.T) {
 		ExtensionService: "projectcontour/otel-collector",
 	}
 	require.Error(t, trace.Validate())
+
+	trace = &Tracing{
+		ExtensionService: "projectcontour/zipkin-collector",
+		Provider:         ZipkinTracingProvider,
+		Zipkin: &ZipkinTracing{
+			CollectorEndpoint: "/api/v2/spans",
+			Encoding:          "proto",
+		},
+	}
+	require.NoError(t, trace.Validate())
+
+	trace = &Tracing{
+		ExtensionService: "projectcontour/zipkin-collector",
+		Provider:         "jaeger",
+	}
+	require.Error(t, trace.Validate())
+
+	trace = &Tracing{
+		ExtensionService: "projectcontour/otel-collector",
+		Zipkin:           &ZipkinTracing{},
+	}
+	require.Error(t, trace.Validate())
+
+	trace = &Tracing{
+		ExtensionService: "projectcontour/zipkin-collector",
+		Provider:         ZipkinTracingProvider,
+		Zipkin: &ZipkinTracing{
+			CollectorEndpoint: "api/v2/spans",
+		},
+	}
+	require.Error(t, trace.Validate())
+
+	trace = &Tracing{
+		ExtensionService: "projectcontour/zipkin-collector",
+		Provider:         ZipkinTracingProvider,
+		Zipkin: &ZipkinTracing{
+			Encoding: "thrift",
+		},
+	}
+	require.Error(t, trace.Validate())
+
+	trace = &Tracing{
+		ExtensionService: "projectcontour/otel-collector",
+		ForceTrace: &ForceTrace{
+			AllowedClientCIDRs: []string{"10.0.0.0/8", "fd00::/8"},
+		},
+	}
+	require.NoError(t, trace.Validate())
+
+	trace = &Tracing{
+		ExtensionService: "projectcontour/otel-collector",
+		ForceTrace:       &ForceTrace{},
+	}
+	require.Error(t, trace.Validate())
+
+	trace = &Tracing{
+		ExtensionService: "projectcontour/otel-collector",
+		ForceTrace: &ForceTrace{
+			AllowedClientCIDRs: []string{"10.0.0.1"},
+		},
+	}
+	require.Error(t, trace.Validate())
+}
+
+func TestCaptureValidation(t *testing.T) {
+	var capture *Capture
+	require.NoError(t, capture.Validate())
+
+	capture = &Capture{}
+	require.Error(t, capture.Validate())
+
+	capture = &Capture{
+		FilePathPrefix: "/var/log/envoy/capture",
+	}
+	require.NoError(t, capture.Validate())
+
+	capture = &Capture{
+		FilePathPrefix:   "/var/log/envoy/capture",
+		SamplePercent:    ref.To(uint32(5)),
+		MaxBufferedBytes: ref.To(uint32(4096)),
+	}
+	require.NoError(t, capture.Validate())
+
+	capture = &Capture{
+		FilePathPrefix: "/var/log/envoy/capture",
+		SamplePercent:  ref.To(uint32(0)),
+	}
+	require.Error(t, capture.Validate())
+
+	capture = &Capture{
+		FilePathPrefix: "/var/log/envoy/capture",
+		SamplePercent:  ref.To(uint32(101)),
+	}
+	require.Error(t, capture.Validate())
+}
+
+func TestAccessLogGRPCValidation(t *testing.T) {
+	var als *AccessLogGRPCParameters
+	require.NoError(t, als.Validate())
+
+	als = &AccessLogGRPCParameters{}
+	require.Error(t, als.Validate())
+
+	als = &AccessLogGRPCParameters{
+		ExtensionService:    "projectcontour/als",
+		LogName:             "contour",
+		BufferSizeBytes:     32768,
+		BufferFlushInterval: "2s",
+	}
+	require.NoError(t, als.Validate())
+
+	als.BufferFlushInterval = "0s"
+	require.Error(t, als.Validate())
+
+	als.BufferFlushInterval = "invalid"
+	require.Error(t, als.Validate())
+}
+
+func TestAccessLogFilterValidation(t *testing.T) {
+	var filter *AccessLogFilterParameters
+	require.NoError(t, filter.Validate())
+	assert.Nil(t, filter.Filter())
+
+	filter = &AccessLogFilterParameters{
+		MinStatusCode:  400,
+		MinDuration:    "500ms",
+		RequestHeaders: []string{"x-debug"},
+		SamplePercent:  10,
+	}
+	require.NoError(t, filter.Validate())
+	assert.Equal(t, &contour_api_v1alpha1.AccessLogFilter{
+		MinStatusCode:  ref.To(uint32(400)),
+		MinDuration:    ref.To("500ms"),
+		RequestHeaders: []string{"x-debug"},
+		SamplePercent:  ref.To(uint32(10)),
+	}, filter.Filter())
+
+	filter = &AccessLogFilterParameters{MinDuration: "invalid"}
+	require.Error(t, filter.Validate())
+
+	filter = &AccessLogFilterParameters{RequestHeaders: []string{"x debug"}}
+	require.Error(t, filter.Validate())
+
+	filter = &AccessLogFilterParameters{SamplePercent: 101}
+	require.Error(t, filter.Validate())
+}
+
+func TestSecretBackendValidation(t *testing.T) {
+	var backend *SecretBackend
+	require.NoError(t, backend.Validate())
+
+	backend = &SecretBackend{}
+	require.NoError(t, backend.Validate())
+
+	backend = &SecretBackend{Type: "vault"}
+	require.Error(t, backend.Validate())
+
+	backend = &SecretBackend{Type: FileSecretBackend}
+	require.Error(t, backend.Validate())
+
+	backend = &SecretBackend{
+		Type:      FileSecretBackend,
+		Directory: "/etc/envoy/certs",
+	}
+	require.NoError(t, backend.Validate())
+
+	backend = &SecretBackend{Type: SDSSecretBackend}
+	require.Error(t, backend.Validate())
+
+	backend = &SecretBackend{
+		Type:             SDSSecretBackend,
+		ExtensionService: "projectcontour/sds",
+	}
+	require.NoError(t, backend.Validate())
+}
+
+func TestSecretEncryptionValidation(t *testing.T) {
+	var encryption *SecretEncryption
+	require.NoError(t, encryption.Validate())
+
+	encryption = &SecretEncryption{}
+	require.NoError(t, encryption.Validate())
+
+	encryption = &SecretEncryption{UnsealCommand: []string{"aws", "kms", "decrypt"}}
+	require.Error(t, encryption.Validate())
+
+	encryption = &SecretEncryption{
+		DataKeyFile:   "/keys/data-key.enc",
+		UnsealCommand: []string{"aws", "kms", "decrypt"},
+	}
+	require.NoError(t, encryption.Validate())
+}
+
+func TestHTTPSRedirectValidation(t *testing.T) {
+	var redirect *HTTPSRedirect
+	require.NoError(t, redirect.Validate())
+
+	redirect = &HTTPSRedirect{}
+	require.NoError(t, redirect.Validate())
+
+	redirect = &HTTPSRedirect{StatusCode: 308}
+	require.NoError(t, redirect.Validate())
+
+	redirect = &HTTPSRedirect{StatusCode: 302}
+	require.Error(t, redirect.Validate())
+
+	redirect = &HTTPSRedirect{ExcludedPathPrefixes: []string{"/.well-known/"}}
+	require.NoError(t, redirect.Validate())
+
+	redirect = &HTTPSRedirect{ExcludedPathPrefixes: []string{".well-known"}}
+	require.Error(t, redirect.Validate())
+}
+
+func TestCompressionValidation(t *testing.T) {
+	compression := CompressionParameters{}
+	require.NoError(t, compression.Validate())
+
+	compression = CompressionParameters{
+		Algorithm:        ZstdCompression,
+		MinContentLength: ref.To(uint32(1024)),
+		ContentTypes:     []string{"application/json"},
+	}
+	require.NoError(t, compression.Validate())
+
+	compression = CompressionParameters{Algorithm: "deflate"}
+	require.Error(t, compression.Validate())
+
+	compression = CompressionParameters{ContentTypes: []string{" "}}
+	require.Error(t, compression.Validate())
+}
+
+func TestResponseMapValidation(t *testing.T) {
+	var responseMap ResponseMap
+	require.NoError(t, responseMap.Validate())
+
+	responseMap = ResponseMap{{
+		StatusCode:  503,
+		Body:        "unavailable",
+		ContentType: "text/html",
+	}, {
+		StatusCode: 404,
+		JSONFormat: map[string]string{"code": "%RESPONSE_CODE%"},
+	}}
+	require.NoError(t, responseMap.Validate())
+
+	responseMap = ResponseMap{{StatusCode: 503, Body: "a"}, {StatusCode: 503, Body: "b"}}
+	require.Error(t, responseMap.Validate())
+
+	responseMap = ResponseMap{{StatusCode: 503, RewriteStatusCode: 100}}
+	require.Error(t, responseMap.Validate())
+}
+
+func TestRuntimeFlagsValidation(t *testing.T) {
+	var flags RuntimeFlags
+	require.NoError(t, flags.Validate())
+
+	flags = RuntimeFlags{"new-checkout": 25, "search.v2": 100}
+	require.NoError(t, flags.Validate())
+
+	flags = RuntimeFlags{"new-checkout": 101}
+	require.Error(t, flags.Validate())
+
+	flags = RuntimeFlags{"new checkout": 25}
+	require.Error(t, flags.Validate())
+
+	flags = RuntimeFlags{".new-checkout": 25}
+	require.Error(t, flags.Validate())
+}
+
+func TestLeaderElectionValidation(t *testing.T) {
+	l := Defaults().LeaderElection
+	require.NoError(t, l.Validate())
+
+	l.ResourceLock = "configmapsleases"
+	require.Error(t, l.Validate())
+
+	l = Defaults().LeaderElection
+	l.LeaseName = "Leader_Elect"
+	require.Error(t, l.Validate())
+
+	l = Defaults().LeaderElection
+	l.LeaseNamespace = ""
+	require.Error(t, l.Validate())
+
+	l = Defaults().LeaderElection
+	l.RetryPeriod = "-2s"
+	require.Error(t, l.Validate())
+
+	l = Defaults().LeaderElection
+	l.LeaseDuration = "10s"
+	require.EqualError(t, l.Validate(), `leader election lease duration "10s" must be longer than renew deadline "10s"`)
+
+	l = Defaults().LeaderElection
+	l.RetryPeriod = "9s"
+	require.EqualError(t, l.Validate(), `leader election renew deadline "10s" must be longer than 1.2 times retry period "9s"`)
 }
@@ -0,0 +1,226 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Update is a change of the configuration files seen by a Watcher.
+type Update struct {
+	// Parameters are the parameters of the changed files.
+	Parameters *Parameters
+
+	// Changed are the paths of the parameters that differ
+	// from the previous parameters of the files, as returned
+	// by Diff.
+	Changed []string
+
+	// Err is set if the changed files can't be parsed or are
+	// invalid, in which case the other fields are not set.
+	Err error
+}
+
+// Watcher watches configuration files, or directories of them, and
+// sends an Update each time the parameters they layer change.
+//
+// The directories of the files are watched rather than the files
+// themselves, so that the atomic symlink swaps done by the kubelet
+// when updating a ConfigMap volume are seen.
+type Watcher struct {
+	paths   []string
+	current *Parameters
+	files   []configFile
+	updates chan Update
+}
+
+// NewWatcher returns a Watcher of the configuration files at paths,
+// layered as by ParseFiles.
+func NewWatcher(paths ...string) (*Watcher, error) {
+	files, err := readFiles(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	params, _, err := parseFiles(files)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Watcher{
+		paths:   paths,
+		current: params,
+		files:   files,
+		updates: make(chan Update, 1),
+	}, nil
+}
+
+// Updates returns the channel on which Updates are sent.
+func (w *Watcher) Updates() <-chan Update {
+	return w.updates
+}
+
+// Start watches the configuration files until ctx is canceled.
+func (w *Watcher) Start(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	watched := map[string]bool{}
+	for _, path := range w.paths {
+		dir := path
+		if info, err := os.Stat(path); err != nil || !info.IsDir() {
+			dir = filepath.Dir(path)
+		}
+		if watched[dir] {
+			continue
+		}
+		if err := watcher.Add(dir); err != nil {
+			return fmt.Errorf("failed to watch configuration file %q: %w", path, err)
+		}
+		watched[dir] = true
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-watcher.Errors:
+			w.send(ctx, Update{Err: fmt.Errorf("failed to watch configuration files: %w", err)})
+		case <-watcher.Events:
+			if update, ok := w.reload(); ok {
+				w.send(ctx, update)
+			}
+		}
+	}
+}
+
+// reload reads the configuration files, returning an Update
+// and true if their content changed.
+func (w *Watcher) reload() (Update, bool) {
+	files, err := readFiles(w.paths)
+	if err != nil {
+		// A file is missing while a ConfigMap
+		// volume is updated, so wait for the next
+		// event.
+		if os.IsNotExist(err) {
+			return Update{}, false
+		}
+		return Update{Err: err}, true
+	}
+
+	if reflect.DeepEqual(files, w.files) {
+		return Update{}, false
+	}
+	w.files = files
+
+	params, _, err := parseFiles(files)
+	if err != nil {
+		return Update{Err: err}, true
+	}
+
+	if err := params.Validate(); err != nil {
+		return Update{Err: fmt.Errorf("invalid Contour configuration: %w", err)}, true
+	}
+
+	changed := Diff(w.current, params)
+	w.current = params
+
+	if len(changed) == 0 {
+		return Update{}, false
+	}
+
+	return Update{Parameters: params, Changed: changed}, true
+}
+
+func (w *Watcher) send(ctx context.Context, update Update) {
+	select {
+	case <-ctx.Done():
+	case w.updates <- update:
+	}
+}
+
+// Diff returns the paths of the parameters that differ between
+// a and b. A path is the YAML keys of a parameter joined with ".",
+// e.g. "timeouts.request-timeout". Parameters that hold a struct
+// are compared field by field, while other parameters, including
+// pointers, lists and maps, are compared as a whole.
+func Diff(a, b *Parameters) []string {
+	return diff("", reflect.ValueOf(a).Elem(), reflect.ValueOf(b).Elem())
+}
+
+func diff(prefix string, va, vb reflect.Value) []string {
+	var changed []string
+
+	for i := 0; i < va.NumField(); i++ {
+		fa, fb := va.Field(i), vb.Field(i)
+		if reflect.DeepEqual(fa.Interface(), fb.Interface()) {
+			continue
+		}
+
+		path := prefix + yamlKey(va.Type().Field(i))
+		if fa.Kind() == reflect.Struct {
+			changed = append(changed, diff(path+".", fa, fb)...)
+			continue
+		}
+		changed = append(changed, path)
+	}
+
+	return changed
+}
+
+// CopyParameter sets the parameter of dst at path, as
+// returned by Diff, to the value of the parameter of src.
+func CopyParameter(dst, src *Parameters, path string) error {
+	vd, vs := reflect.ValueOf(dst).Elem(), reflect.ValueOf(src).Elem()
+
+	for _, key := range strings.Split(path, ".") {
+		if vd.Kind() != reflect.Struct {
+			return fmt.Errorf("unknown parameter %q", path)
+		}
+
+		found := false
+		for i := 0; i < vd.NumField(); i++ {
+			if yamlKey(vd.Type().Field(i)) == key {
+				vd, vs = vd.Field(i), vs.Field(i)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("unknown parameter %q", path)
+		}
+	}
+
+	vd.Set(vs)
+	return nil
+}
+
+// yamlKey returns the YAML key of a field of Parameters.
+func yamlKey(field reflect.StructField) string {
+	key, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	if key == "" {
+		key = strings.ToLower(field.Name)
+	}
+	return key
+}
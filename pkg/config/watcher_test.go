@@ -0,0 +1,160 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/projectcontour/contour/internal/ref"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	a := Defaults()
+	b := Defaults()
+	assert.Empty(t, Diff(&a, &b))
+
+	b.RuntimeFlags = RuntimeFlags{"new-checkout": 25}
+	b.AccessLogLevel = LogLevelError
+	b.Debug = true
+	b.Timeouts.RequestTimeout = "30s"
+	b.Cluster.CircuitBreakers.MaxRetries = ref.To(uint32(5))
+	b.TLS.CipherSuites = TLSCiphers{"ECDHE-RSA-AES256-GCM-SHA384"}
+	assert.Equal(t, []string{
+		"debug",
+		"accesslog-level",
+		"tls.cipher-suites",
+		"timeouts.request-timeout",
+		"cluster.circuit-breakers.max-retries",
+		"runtime-flags",
+	}, Diff(&a, &b))
+}
+
+func TestCopyParameter(t *testing.T) {
+	dst := Defaults()
+	dst.Timeouts.ConnectTimeout = "5s"
+	src := Defaults()
+	src.Timeouts.RequestTimeout = "30s"
+	src.Timeouts.ConnectTimeout = "10s"
+
+	require.NoError(t, CopyParameter(&dst, &src, "timeouts.request-timeout"))
+	assert.Equal(t, "30s", dst.Timeouts.RequestTimeout)
+	assert.Equal(t, "5s", dst.Timeouts.ConnectTimeout)
+
+	assert.Error(t, CopyParameter(&dst, &src, "timeouts.unknown"))
+	assert.Error(t, CopyParameter(&dst, &src, "debug.unknown"))
+}
+
+func TestWatcher(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "contour.yaml")
+
+	// Replace the file atomically, as the kubelet does
+	// for ConfigMap volumes.
+	write := func(content string) {
+		tmp := filepath.Join(dir, ".contour.yaml.tmp")
+		require.NoError(t, os.WriteFile(tmp, []byte(content), 0600))
+		require.NoError(t, os.Rename(tmp, filename))
+	}
+
+	write("runtime-flags:\n  new-checkout: 10\n")
+
+	w, err := NewWatcher(filename)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- w.Start(ctx) }()
+	defer func() {
+		cancel()
+		require.NoError(t, <-done)
+	}()
+
+	next := func() Update {
+		t.Helper()
+
+		select {
+		case update := <-w.Updates():
+			return update
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "timed out waiting for a configuration update")
+			return Update{}
+		}
+	}
+
+	// Give the watcher time to start watching.
+	require.Eventually(t, func() bool {
+		write("runtime-flags:\n  new-checkout: 25\n")
+		select {
+		case update := <-w.Updates():
+			require.NoError(t, update.Err)
+			assert.Equal(t, []string{"runtime-flags"}, update.Changed)
+			assert.Equal(t, RuntimeFlags{"new-checkout": 25}, update.Parameters.RuntimeFlags)
+			return true
+		case <-time.After(100 * time.Millisecond):
+			return false
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+
+	write("accesslog-level: invalid\nruntime-flags:\n  new-checkout: 25\n")
+	assert.Error(t, next().Err)
+
+	write("accesslog-level: error\nruntime-flags:\n  new-checkout: 25\n")
+	update := next()
+	require.NoError(t, update.Err)
+	assert.Equal(t, []string{"accesslog-level"}, update.Changed)
+}
+
+func TestWatcherLayers(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.yaml")
+	require.NoError(t, os.WriteFile(base, []byte("runtime-flags:\n  new-checkout: 10\n"), 0600))
+
+	overrides := filepath.Join(dir, "overrides")
+	require.NoError(t, os.Mkdir(overrides, 0700))
+
+	w, err := NewWatcher(base, overrides)
+	require.NoError(t, err)
+	assert.Equal(t, RuntimeFlags{"new-checkout": 10}, w.current.RuntimeFlags)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- w.Start(ctx) }()
+	defer func() {
+		cancel()
+		require.NoError(t, <-done)
+	}()
+
+	// Adding a file to the directory overrides the base file. It's
+	// written atomically, hidden files of the directory being ignored.
+	require.Eventually(t, func() bool {
+		tmp := filepath.Join(overrides, ".cluster.yaml.tmp")
+		require.NoError(t, os.WriteFile(tmp, []byte("runtime-flags:\n  new-checkout: 25\n"), 0600))
+		require.NoError(t, os.Rename(tmp, filepath.Join(overrides, "cluster.yaml")))
+		select {
+		case update := <-w.Updates():
+			require.NoError(t, update.Err)
+			assert.Equal(t, []string{"runtime-flags"}, update.Changed)
+			assert.Equal(t, RuntimeFlags{"new-checkout": 25}, update.Parameters.RuntimeFlags)
+			return true
+		case <-time.After(100 * time.Millisecond):
+			return false
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -18,6 +18,8 @@ package gatewayapi
 import (
 	"testing"
 
+	"github.com/projectcontour/contour/internal/dag"
+
 	"github.com/bombsimon/logrusr/v2"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/require"
@@ -48,15 +50,24 @@ func TestGatewayConformance(t *testing.T) {
 	require.NoError(t, v1alpha2.AddToScheme(client.Scheme()))
 	require.NoError(t, v1beta1.AddToScheme(client.Scheme()))
 
+	// Run exactly the features Contour supports, rather than all
+	// features, so that conformance reports match Contour's capabilities.
+	supportedFeatures := sets.New[suite.SupportedFeature]()
+	for _, feature := range dag.SupportedGatewayAPIFeatures() {
+		f := suite.SupportedFeature(feature)
+		require.Truef(t, suite.AllFeatures.Has(f), "unknown Gateway API feature %q", feature)
+		supportedFeatures.Insert(f)
+	}
+
 	cSuite := suite.New(suite.Options{
 		Client: client,
 		// This clientset is needed in addition to the client only because
 		// controller-runtime client doesn't support non CRUD sub-resources yet (https://github.com/kubernetes-sigs/controller-runtime/issues/452).
-		Clientset:                  clientset,
-		GatewayClassName:           *flags.GatewayClassName,
-		Debug:                      *flags.ShowDebug,
-		CleanupBaseResources:       *flags.CleanupBaseResources,
-		EnableAllSupportedFeatures: true,
+		Clientset:            clientset,
+		GatewayClassName:     *flags.GatewayClassName,
+		Debug:                *flags.ShowDebug,
+		CleanupBaseResources: *flags.CleanupBaseResources,
+		SupportedFeatures:    supportedFeatures,
 		// Keep the list of skipped features in sync with
 		// test/scripts/run-gateway-conformance.sh.
 		SkipTests: []string{
@@ -65,9 +76,6 @@ func TestGatewayConformance(t *testing.T) {
 			// See: https://github.com/envoyproxy/envoy/issues/17318
 			tests.HTTPRouteRedirectPortAndScheme.ShortName,
 		},
-		ExemptFeatures: sets.New(
-			suite.SupportMesh,
-		),
 	})
 	cSuite.Setup(t)
 	cSuite.Run(t, tests.ConformanceTests)
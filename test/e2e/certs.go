@@ -241,3 +241,56 @@ func (c *Certs) CreateCert(ns, name, issuer string, dnsNames ...string) func() {
 		require.NoError(c.t, c.client.Delete(context.TODO(), secret))
 	}
 }
+
+// CreateClientCert creates a client certificate signed by the given CA
+// issuer, with the given email addresses, and waits for it to be ready.
+// The certificate's common name and Secret are both named name. It returns
+// the certificate and key, for use with OptUseClientCert, and a cleanup function.
+func (c *Certs) CreateClientCert(ns, name, issuer string, emailAddresses ...string) (*tls.Certificate, func()) {
+	cert := &certmanagerv1.Certificate{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: ns,
+			Name:      name,
+		},
+		Spec: certmanagerv1.CertificateSpec{
+			Usages: []certmanagerv1.KeyUsage{
+				certmanagerv1.UsageClientAuth,
+			},
+			EmailAddresses: emailAddresses,
+			CommonName:     name,
+			SecretName:     name,
+			IssuerRef: certmanagermetav1.ObjectReference{
+				Name: issuer,
+			},
+		},
+	}
+
+	// Wait for the Certificate to be ready since we need
+	// to download the Secret contents to use it.
+	_, ok := c.CreateCertAndWaitFor(cert, CertIsReady)
+	require.True(c.t, ok, "client certificate %s/%s never became ready", ns, name)
+
+	clientCert, _ := c.GetTLSCertificate(ns, name)
+
+	return &clientCert, func() {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: ns,
+				Name:      name,
+			},
+		}
+		require.NoError(c.t, c.client.Delete(context.TODO(), cert))
+		require.NoError(c.t, c.client.Delete(context.TODO(), secret))
+	}
+}
+
+// CertIsReady returns true if the Certificate has a
+// Ready condition with status True.
+func CertIsReady(cert *certmanagerv1.Certificate) bool {
+	for _, cond := range cert.Status.Conditions {
+		if cond.Type == certmanagerv1.CertificateConditionReady && cond.Status == certmanagermetav1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
@@ -27,6 +27,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/onsi/gomega/gexec"
@@ -77,9 +78,17 @@ type Deployment struct {
 	// Contour image to use in in-cluster deployment.
 	contourImage string
 
+	// If true, Contour and Envoy are already installed in the cluster
+	// and must not be deployed, started or deleted by the tests.
+	skipProvisioning bool
+
 	// EnvoyDeploymentMode determines how Envoy is deployed (daemonset or deployment)
 	EnvoyDeploymentMode
 
+	// Namespaces created for the current spec, which the local
+	// Contour watches when specs are run in parallel.
+	namespaces []string
+
 	Namespace                 *v1.Namespace
 	ContourServiceAccount     *v1.ServiceAccount
 	EnvoyServiceAccount       *v1.ServiceAccount
@@ -456,6 +465,16 @@ func (d *Deployment) EnsureGlobalExternalAuthResources(namespace string) error {
 // - ConfigMap with Envoy bootstrap config
 // - Envoy DaemonSet modified for local Contour xDS server
 func (d *Deployment) EnsureResourcesForLocalContour() error {
+	if d.skipProvisioning {
+		return nil
+	}
+
+	// When specs are run in parallel, each process
+	// runs its own Envoy in a namespace of its own.
+	if parallel() {
+		d.setNamespace(ParallelName(d.Namespace.Name))
+	}
+
 	if err := d.EnsureNamespace(); err != nil {
 		return err
 	}
@@ -478,7 +497,7 @@ func (d *Deployment) EnsureResourcesForLocalContour() error {
 		"bootstrap",
 		bFile.Name(),
 		"--xds-address="+d.localContourHost,
-		"--xds-port="+d.localContourPort,
+		"--xds-port="+strconv.Itoa(d.xdsPort()),
 		"--xds-resource-version=v3",
 		"--admin-address=/admin/admin.sock",
 	)
@@ -520,7 +539,8 @@ func (d *Deployment) EnsureResourcesForLocalContour() error {
 
 	// The envoy deployment uses host ports, so can have at most
 	// one replica per node, and our cluster only has one worker
-	// node, so scale the deployment to 1.
+	// node, so scale the deployment to 1. Port-forwards are also
+	// to a single pod when specs are run in parallel.
 	d.EnvoyDeployment.Spec.Replicas = ref.To(int32(1))
 
 	return d.EnsureEnvoyDeployment()
@@ -568,15 +588,54 @@ func (d *Deployment) mutatePodTemplate(pts v1.PodTemplateSpec) v1.PodTemplateSpe
 			Protocol:      v1.ProtocolTCP,
 		})
 
+	// The Envoys of parallel processes can't share the host
+	// ports, so they are reached through port-forwards.
+	if parallel() {
+		for i := range pts.Spec.Containers[0].Ports {
+			pts.Spec.Containers[0].Ports[i].HostPort = 0
+		}
+	}
+
 	return pts
 }
 
+// setNamespace moves the namespace and the namespaced
+// resources of the Envoy of a local Contour to namespace.
+func (d *Deployment) setNamespace(namespace string) {
+	d.Namespace.Name = namespace
+	for _, obj := range []client.Object{
+		d.EnvoyServiceAccount,
+		d.EnvoyService,
+		d.EnvoyDaemonSet,
+		d.EnvoyDeployment,
+		d.ContourConfigMap,
+	} {
+		obj.SetNamespace(namespace)
+	}
+}
+
+// watchNamespace adds namespace to the namespaces
+// created for the current spec.
+func (d *Deployment) watchNamespace(namespace string) {
+	d.namespaces = append(d.namespaces, namespace)
+}
+
+// xdsPort returns the port of the xDS server of the local Contour.
+func (d *Deployment) xdsPort() int {
+	port, _ := strconv.Atoi(d.localContourPort)
+	return port + parallelPortOffset()
+}
+
 // DeleteResourcesForLocalContour ensures deletion of all resources
 // created in the projectcontour namespace for running a local contour.
 // This is done instead of deleting the entire namespace as a performance
 // optimization, because deleting non-empty namespaces can take up to a
 // couple minutes to complete.
 func (d *Deployment) DeleteResourcesForLocalContour() error {
+	if d.skipProvisioning {
+		return nil
+	}
+
 	for _, r := range []client.Object{
 		d.ContourConfigMap,
 		d.EnvoyService,
@@ -598,13 +657,23 @@ func (d *Deployment) DeleteResourcesForLocalContour() error {
 		}
 	}
 
+	// The namespaces of the Envoys of parallel
+	// processes are only used by one suite.
+	if parallel() {
+		return d.EnsureDeleted(d.Namespace)
+	}
+
 	return nil
 }
 
 // Starts local contour, applying arguments and marshaling config into config
 // file. Returns running Contour command and config file so we can clean them
-// up.
+// up. If Contour is already installed in the cluster, nothing is started and
+// a nil command is returned, so tests run against the installed Contour.
 func (d *Deployment) StartLocalContour(config *config.Parameters, contourConfiguration *contour_api_v1alpha1.ContourConfiguration, additionalArgs ...string) (*gexec.Session, string, error) {
+	if d.skipProvisioning {
+		return nil, "", nil
+	}
 
 	var content []byte
 	var configReferenceName string
@@ -613,13 +682,23 @@ func (d *Deployment) StartLocalContour(config *config.Parameters, contourConfigu
 
 	// Look for the ENV variable to tell if this test run should use
 	// the ContourConfiguration file or the ContourConfiguration CRD.
-	if UsingContourConfigCRD() {
-		port, _ := strconv.Atoi(d.localContourPort)
+	// When specs are run in parallel, Contour only watches the
+	// namespaces of its spec and of its Envoy, so that it doesn't
+	// process the resources of the specs of the other processes,
+	// unless the spec restricts the namespaces itself.
+	if parallel() && !hasFlag(additionalArgs, "--watch-namespaces") {
+		additionalArgs = append(additionalArgs, "--watch-namespaces="+strings.Join(append([]string{d.Namespace.Name}, d.namespaces...), ","))
+	}
+
+	offset := parallelPortOffset()
 
+	if UsingContourConfigCRD() {
 		contourConfiguration.Name = randomString(14)
+		contourConfiguration.Namespace = d.Namespace.Name
+		contourConfiguration.Spec.Envoy.Service.Namespace = d.Namespace.Name
 
 		// Set the xds server to the defined testing port as well as enable insecure communication.
-		contourConfiguration.Spec.XDSServer.Port = port
+		contourConfiguration.Spec.XDSServer.Port = d.xdsPort()
 		contourConfiguration.Spec.XDSServer.Address = listenAllAddress()
 		contourConfiguration.Spec.XDSServer.TLS = &contour_api_v1alpha1.TLS{
 			Insecure: ref.To(true),
@@ -629,6 +708,10 @@ func (d *Deployment) StartLocalContour(config *config.Parameters, contourConfigu
 			return nil, "", fmt.Errorf("could not create ContourConfiguration: %v", err)
 		}
 
+		contourConfiguration.Spec.Metrics.Port += offset
+		contourConfiguration.Spec.Health.Port += offset
+		contourConfiguration.Spec.Debug.Port += offset
+
 		contourServeArgs = append([]string{
 			"serve",
 			"--kubeconfig=" + d.kubeConfig,
@@ -646,6 +729,8 @@ func (d *Deployment) StartLocalContour(config *config.Parameters, contourConfigu
 		}
 		defer configFile.Close()
 
+		config.EnvoyServiceNamespace = d.Namespace.Name
+
 		content, err = yaml.Marshal(config)
 		if err != nil {
 			return nil, "", err
@@ -657,13 +742,16 @@ func (d *Deployment) StartLocalContour(config *config.Parameters, contourConfigu
 		contourServeArgs = append([]string{
 			"serve",
 			"--xds-address=" + listenAllAddress(),
-			"--xds-port=" + d.localContourPort,
+			"--xds-port=" + strconv.Itoa(d.xdsPort()),
 			"--stats-address=" + listenAllAddress(),
 			"--debug-http-address=" + localAddress(),
 			"--http-address=" + listenAllAddress(),
 			"--envoy-service-http-address=" + listenAllAddress(),
 			"--envoy-service-https-address=" + listenAllAddress(),
 			"--health-address=" + listenAllAddress(),
+			"--http-port=" + strconv.Itoa(8000+offset),
+			"--health-port=" + strconv.Itoa(8000+offset),
+			"--debug-http-port=" + strconv.Itoa(6060+offset),
 			"--insecure",
 			"--kubeconfig=" + d.kubeConfig,
 			"--config-path=" + configFile.Name(),
@@ -674,13 +762,26 @@ func (d *Deployment) StartLocalContour(config *config.Parameters, contourConfigu
 		configReferenceName = configFile.Name()
 	}
 
-	session, err := gexec.Start(exec.Command(d.contourBin, contourServeArgs...), d.cmdOutputWriter, d.cmdOutputWriter) // nolint:gosec
+	contourCmd := exec.Command(d.contourBin, contourServeArgs...) // nolint:gosec
+	contourCmd.Env = append(os.Environ(), "CONTOUR_NAMESPACE="+d.Namespace.Name)
+
+	session, err := gexec.Start(contourCmd, d.cmdOutputWriter, d.cmdOutputWriter)
 	if err != nil {
 		return nil, "", err
 	}
 	return session, configReferenceName, nil
 }
 
+// hasFlag returns true if args sets flag.
+func hasFlag(args []string, flag string) bool {
+	for _, arg := range args {
+		if arg == flag || strings.HasPrefix(arg, flag+"=") {
+			return true
+		}
+	}
+	return false
+}
+
 func listenAllAddress() string {
 	if os.Getenv("IPV6_CLUSTER") == "true" {
 		return "::"
@@ -696,6 +797,13 @@ func localAddress() string {
 }
 
 func (d *Deployment) StopLocalContour(contourCmd *gexec.Session, configFile string) error {
+	// The namespaces of the next spec are
+	// added by its own BeforeEach.
+	d.namespaces = nil
+
+	if contourCmd == nil {
+		return nil
+	}
 
 	// Look for the ENV variable to tell if this test run should use
 	// the ContourConfiguration file or the ContourConfiguration CRD.
@@ -703,7 +811,7 @@ func (d *Deployment) StopLocalContour(contourCmd *gexec.Session, configFile stri
 		cc := &contour_api_v1alpha1.ContourConfiguration{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      configFile,
-				Namespace: "projectcontour",
+				Namespace: d.Namespace.Name,
 			},
 		}
 
@@ -738,6 +846,10 @@ func (d *Deployment) StopLocalContour(contourCmd *gexec.Session, configFile stri
 // - Contour deployment (only started if bool passed in is true)
 // - Envoy DaemonSet
 func (d *Deployment) EnsureResourcesForInclusterContour(startContourDeployment bool) error {
+	if d.skipProvisioning {
+		return nil
+	}
+
 	fmt.Fprintf(d.cmdOutputWriter, "Deploying Contour with image: %s\n", d.contourImage)
 
 	if err := d.EnsureNamespace(); err != nil {
@@ -854,6 +966,10 @@ func (d *Deployment) EnsureResourcesForInclusterContour(startContourDeployment b
 // optimization, because deleting non-empty namespaces can take up to a
 // couple minutes to complete.
 func (d *Deployment) DeleteResourcesForInclusterContour() error {
+	if d.skipProvisioning {
+		return nil
+	}
+
 	// Also need to delete leader election resources to ensure
 	// multiple test runs can be run cleanly.
 	leaderElectionLease := &coordinationv1.Lease{
@@ -41,6 +41,10 @@ const (
 
 	// GRPCServerImage is the image to use for tests that require a gRPC server.
 	GRPCServerImage = "ghcr.io/projectcontour/yages:v0.1.0"
+
+	// WebSocketServerImage is the image to use for tests that require
+	// a WebSocket echo server.
+	WebSocketServerImage = "docker.io/jmalloc/echo-server:v0.3.6"
 )
 
 // Fixtures holds references to all of the E2E fixtures helpers.
@@ -56,6 +60,10 @@ type Fixtures struct {
 	// GRPC provides helpers for working with a gRPC echo server test
 	// fixture.
 	GRPC *GRPC
+
+	// WebSocket provides helpers for working with a WebSocket echo
+	// server test fixture.
+	WebSocket *WebSocket
 }
 
 // Echo manages the ingress-conformance-echo fixture.
@@ -491,6 +499,95 @@ func (g *GRPC) Deploy(ns, name string) func() {
 	}
 }
 
+// WebSocket manages the WebSocket echo server fixture.
+type WebSocket struct {
+	client client.Client
+	t      ginkgo.GinkgoTInterface
+}
+
+// Deploy creates the WebSocket echo server fixture, specifically the
+// deployment and service, in the given namespace and with the given name,
+// or fails the test if it encounters an error. Namespace is defaulted to
+// "default" and name is defaulted to "websocket-echo" if not provided.
+// The server replies to each message with the same message, after first
+// sending a "Request served by <pod name>" message on every connection.
+// Returns a cleanup function.
+func (w *WebSocket) Deploy(ns, name string) func() {
+	ns = valOrDefault(ns, "default")
+	name = valOrDefault(name, "websocket-echo")
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: ns,
+			Name:      name,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: ref.To(int32(1)),
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"app.kubernetes.io/name": name},
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"app.kubernetes.io/name": name},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:            "websocket-echo",
+							Image:           WebSocketServerImage,
+							ImagePullPolicy: corev1.PullIfNotPresent,
+							Env: []corev1.EnvVar{
+								{
+									Name:  "PORT",
+									Value: "3000",
+								},
+							},
+							Ports: []corev1.ContainerPort{
+								{
+									Name:          "http",
+									ContainerPort: 3000,
+								},
+							},
+							ReadinessProbe: &corev1.Probe{
+								ProbeHandler: corev1.ProbeHandler{
+									HTTPGet: &corev1.HTTPGetAction{
+										Path: "/",
+										Port: intstr.FromString("http"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	require.NoError(w.t, w.client.Create(context.TODO(), deployment))
+
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: ns,
+			Name:      name,
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "http",
+					Port:       80,
+					TargetPort: intstr.FromString("http"),
+				},
+			},
+			Selector: map[string]string{"app.kubernetes.io/name": name},
+		},
+	}
+	require.NoError(w.t, w.client.Create(context.TODO(), service))
+
+	return func() {
+		require.NoError(w.t, w.client.Delete(context.TODO(), service))
+		require.NoError(w.t, w.client.Delete(context.TODO(), deployment))
+	}
+}
+
 // DefaultContourConfigFileParams returns a default configuration in a config
 // file params object.
 func DefaultContourConfigFileParams() *config.Parameters {
@@ -20,6 +20,7 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -73,6 +74,9 @@ type Framework struct {
 	// HTTP provides helpers for making HTTP/HTTPS requests.
 	HTTP *HTTP
 
+	// GRPC provides helpers for making gRPC requests.
+	GRPC *GRPCClient
+
 	// Certs provides helpers for creating cert-manager certificates
 	// and related resources.
 	Certs *Certs
@@ -88,6 +92,9 @@ type Framework struct {
 	// Kubectl provides helpers for managing kubectl port-forward helpers.
 	Kubectl *Kubectl
 
+	// Provider is the kind of cluster the tests are running against.
+	Provider Provider
+
 	t ginkgo.GinkgoTInterface
 }
 
@@ -136,7 +143,37 @@ func NewFramework(inClusterTestSuite bool) *Framework {
 	crClient, err := client.New(config, client.Options{Scheme: scheme})
 	require.NoError(t, err)
 
+	provider := KindProvider
+	if val := os.Getenv("CONTOUR_E2E_PROVIDER"); val != "" {
+		provider = Provider(val)
+	}
+	require.Contains(t, []Provider{KindProvider, ExternalProvider}, provider, "unsupported CONTOUR_E2E_PROVIDER")
+
 	httpURLBase := os.Getenv("CONTOUR_E2E_HTTP_URL_BASE")
+	httpsURLBase := os.Getenv("CONTOUR_E2E_HTTPS_URL_BASE")
+
+	// An external cluster's Envoy isn't reachable via kind's forwarded
+	// host ports, so discover its address unless one was provided.
+	if provider == ExternalProvider && (httpURLBase == "" || httpsURLBase == "") {
+		envoyService := types.NamespacedName{Namespace: "projectcontour", Name: "envoy"}
+		if val := os.Getenv("CONTOUR_E2E_ENVOY_SERVICE_NAMESPACE"); val != "" {
+			envoyService.Namespace = val
+		}
+		if val := os.Getenv("CONTOUR_E2E_ENVOY_SERVICE_NAME"); val != "" {
+			envoyService.Name = val
+		}
+
+		discoveredHTTP, discoveredHTTPS, err := discoverEnvoyURLBases(crClient, envoyService, time.Second, 5*time.Minute)
+		require.NoError(t, err)
+
+		if httpURLBase == "" {
+			httpURLBase = discoveredHTTP
+		}
+		if httpsURLBase == "" {
+			httpsURLBase = discoveredHTTPS
+		}
+	}
+
 	if httpURLBase == "" {
 		if ipV6Cluster {
 			httpURLBase = "http://[::1]:9080"
@@ -145,7 +182,6 @@ func NewFramework(inClusterTestSuite bool) *Framework {
 		}
 	}
 
-	httpsURLBase := os.Getenv("CONTOUR_E2E_HTTPS_URL_BASE")
 	if httpsURLBase == "" {
 		if ipV6Cluster {
 			httpsURLBase = "https://[::1]:9443"
@@ -185,12 +221,16 @@ func NewFramework(inClusterTestSuite bool) *Framework {
 		kubeConfig = filepath.Join(os.Getenv("HOME"), ".kube", "config")
 	}
 
-	if inClusterTestSuite {
+	switch {
+	case provider == ExternalProvider:
+		// Contour is already installed in the cluster, so there
+		// is nothing to build or deploy.
+	case inClusterTestSuite:
 		var found bool
 		if contourImage, found = os.LookupEnv("CONTOUR_E2E_IMAGE"); !found {
 			contourImage = "ghcr.io/projectcontour/contour:main"
 		}
-	} else {
+	default:
 		contourHost = os.Getenv("CONTOUR_E2E_LOCAL_HOST")
 		require.NotEmpty(t, contourHost, "CONTOUR_E2E_LOCAL_HOST environment variable not supplied")
 
@@ -216,6 +256,7 @@ func NewFramework(inClusterTestSuite bool) *Framework {
 		localContourPort:    contourPort,
 		contourBin:          contourBin,
 		contourImage:        contourImage,
+		skipProvisioning:    provider == ExternalProvider,
 		EnvoyDeploymentMode: envoyDeploymentMode,
 	}
 
@@ -250,6 +291,10 @@ func NewFramework(inClusterTestSuite bool) *Framework {
 				client: crClient,
 				t:      t,
 			},
+			WebSocket: &WebSocket{
+				client: crClient,
+				t:      t,
+			},
 		},
 		HTTP: &HTTP{
 			HTTPURLBase:        httpURLBase,
@@ -260,6 +305,13 @@ func NewFramework(inClusterTestSuite bool) *Framework {
 			RetryTimeout:       60 * time.Second,
 			t:                  t,
 		},
+		GRPC: &GRPCClient{
+			InsecureAddr:  grpcAddr(httpURLBase),
+			SecureAddr:    grpcAddr(httpsURLBase),
+			RetryInterval: time.Second,
+			RetryTimeout:  60 * time.Second,
+			t:             t,
+		},
 		Certs: &Certs{
 			client:        crClient,
 			retryInterval: time.Second,
@@ -269,6 +321,7 @@ func NewFramework(inClusterTestSuite bool) *Framework {
 		Deployment:  deployment,
 		Provisioner: provisioner,
 		Kubectl:     kubectl,
+		Provider:    provider,
 		t:           t,
 	}
 }
@@ -283,7 +336,12 @@ type NamespacedGatewayTestBody func(ns string, gw types.NamespacedName)
 type NamespacedTestBody func(string)
 type TestBody func()
 
+// NamespacedTest runs body in a namespace that is created before and deleted
+// after each spec. When specs are run in parallel, the namespace name is made
+// unique to the Ginkgo process so that specs sharing a body can't interfere.
 func (f *Framework) NamespacedTest(namespace string, body NamespacedTestBody) {
+	namespace = ParallelName(namespace)
+
 	ginkgo.Context("with namespace: "+namespace, func() {
 		ginkgo.BeforeEach(func() {
 			f.CreateNamespace(namespace)
@@ -296,6 +354,57 @@ func (f *Framework) NamespacedTest(namespace string, body NamespacedTestBody) {
 	})
 }
 
+// ParallelName returns name suffixed with the Ginkgo parallel process number
+// when specs are run in parallel, or name unchanged otherwise. It is used to
+// give resources that are created by each process unique names.
+func ParallelName(name string) string {
+	if !parallel() {
+		return name
+	}
+	suiteConfig, _ := ginkgo.GinkgoConfiguration()
+	return fmt.Sprintf("%s-p%d", name, suiteConfig.ParallelProcess)
+}
+
+// parallel returns true if specs are run by several Ginkgo processes.
+func parallel() bool {
+	suiteConfig, _ := ginkgo.GinkgoConfiguration()
+	return suiteConfig.ParallelTotal > 1
+}
+
+// parallelPortOffset returns the offset added to the local ports of
+// the Contour run by this Ginkgo process, so that the Contours of
+// parallel processes don't contend for ports.
+func parallelPortOffset() int {
+	if !parallel() {
+		return 0
+	}
+	suiteConfig, _ := ginkgo.GinkgoConfiguration()
+	return 10 * (suiteConfig.ParallelProcess - 1)
+}
+
+// ForwardEnvoyPorts points the HTTP and gRPC clients at the Envoy of this
+// Ginkgo process when specs are run in parallel. Each process has its own
+// Envoy, which can't bind the host ports forwarded by the kind cluster, so
+// its listeners are reached through port-forwards instead. It must be
+// called once Envoy is updated, as port-forwards are to a single pod.
+func (f *Framework) ForwardEnvoyPorts() {
+	if !parallel() || f.Deployment.skipProvisioning {
+		return
+	}
+
+	forward := func(port int) string {
+		addr, err := f.Kubectl.PortForward(f.Deployment.Namespace.Name, f.Deployment.EnvoyResourceAndName(), port)
+		require.NoError(f.t, err)
+		return addr
+	}
+
+	f.HTTP.HTTPURLBase = "http://" + forward(8080)
+	f.HTTP.HTTPSURLBase = "https://" + forward(8443)
+	f.HTTP.HTTPURLMetricsBase = "http://" + forward(8002)
+	f.GRPC.InsecureAddr = grpcAddr(f.HTTP.HTTPURLBase)
+	f.GRPC.SecureAddr = grpcAddr(f.HTTP.HTTPSURLBase)
+}
+
 func (f *Framework) Test(body TestBody) {
 	body()
 }
@@ -376,6 +485,10 @@ func (f *Framework) CreateNamespace(name string) {
 
 	// Now try creating it.
 	require.NoError(f.t, f.Client.Create(context.TODO(), ns))
+
+	// When specs are run in parallel, the local Contour
+	// only watches the namespaces created for its spec.
+	f.Deployment.watchNamespace(name)
 }
 
 // DeleteNamespace deletes the namespace with the given name in the
@@ -55,24 +55,30 @@ func TestGatewayAPI(t *testing.T) {
 	RunSpecs(t, "Gateway API tests")
 }
 
-var _ = BeforeSuite(func() {
+// Each Ginkgo process runs its own Envoy, so that
+// specs can run in parallel.
+var _ = SynchronizedBeforeSuite(func() {}, func() {
+	require.NoError(f.T(), f.Deployment.EnsureResourcesForLocalContour())
+
 	var found bool
 	reconcileMode, found = os.LookupEnv("CONTOUR_E2E_GATEWAY_RECONCILE_MODE")
 	if !found {
 		reconcileMode = ReconcileModeGateway
 	}
-
-	require.NoError(f.T(), f.Deployment.EnsureResourcesForLocalContour())
 })
 
-var _ = AfterSuite(func() {
+var _ = SynchronizedAfterSuite(func() {
+	f.Kubectl.StopPortForwards()
+	gexec.CleanupBuildArtifacts()
+
 	// Delete resources individually instead of deleting the entire contour
 	// namespace as a performance optimization, because deleting non-empty
 	// namespaces can take up to a couple minutes to complete.
 	require.NoError(f.T(), f.Deployment.DeleteResourcesForLocalContour())
-	gexec.CleanupBuildArtifacts()
-})
+}, func() {})
 
+// Each spec runs its own local Contour, which the
+// Envoy of its Ginkgo process connects to.
 var _ = Describe("Gateway API", func() {
 	var (
 		contourCmd            *gexec.Session
@@ -152,6 +158,7 @@ var _ = Describe("Gateway API", func() {
 
 		// Wait for Envoy to be healthy.
 		require.NoError(f.T(), f.Deployment.WaitForEnvoyUpdated())
+		f.ForwardEnvoyPorts()
 
 		gatewayClassCond := e2e.GatewayClassAccepted
 		// If we're reconciling a specific Gateway,
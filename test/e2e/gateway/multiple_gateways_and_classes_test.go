@@ -76,6 +76,7 @@ var _ = Describe("GatewayClass/Gateway admission tests", func() {
 
 		// Wait for Envoy to be healthy.
 		require.NoError(f.T(), f.Deployment.WaitForEnvoyUpdated())
+		f.ForwardEnvoyPorts()
 	})
 
 	AfterEach(func() {
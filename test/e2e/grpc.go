@@ -0,0 +1,200 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build e2e
+
+package e2e
+
+import (
+	"context"
+	"crypto/tls"
+	"strings"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/projectcontour/yages/yages"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// GRPCClient provides helpers for making gRPC requests through Envoy
+// to the gRPC echo fixture.
+type GRPCClient struct {
+	// InsecureAddr holds the IP address and port for making
+	// plaintext (h2c) gRPC requests, formatted as "<ip>:<port>".
+	InsecureAddr string
+
+	// SecureAddr holds the IP address and port for making
+	// TLS gRPC requests, formatted as "<ip>:<port>".
+	SecureAddr string
+
+	// RetryInterval is how often to retry polling operations.
+	RetryInterval time.Duration
+
+	// RetryTimeout is how long to continue trying polling
+	// operations before giving up.
+	RetryTimeout time.Duration
+
+	t ginkgo.GinkgoTInterface
+}
+
+type GRPCRequestOpts struct {
+	// Host is the authority of the request, i.e. the
+	// FQDN of the virtual host.
+	Host string
+
+	// Secure makes the request over TLS to SecureAddr, rather
+	// than as plaintext (h2c) to InsecureAddr.
+	Secure bool
+
+	// OverrideAddr is used in place of InsecureAddr or SecureAddr
+	// if it is set.
+	OverrideAddr string
+
+	// TLSConfigOpts modify the TLS configuration of secure requests.
+	TLSConfigOpts []func(*tls.Config)
+
+	// DialOpts are appended to the options used to dial the server.
+	DialOpts []grpc.DialOption
+
+	// Condition is checked after every request.
+	Condition func(*GRPCResponse) bool
+}
+
+// GRPCResponse holds the result of a gRPC request.
+type GRPCResponse struct {
+	// Code is the gRPC status code of the response.
+	Code codes.Code
+
+	// Message is the gRPC status message of the response.
+	Message string
+
+	// Text holds the text returned by the echo fixture for
+	// Ping requests, or the serving status for health checks.
+	Text string
+}
+
+// HasGRPCCode returns a function that returns true
+// if the response has the specified gRPC status code,
+// or false otherwise.
+func HasGRPCCode(code codes.Code) func(*GRPCResponse) bool {
+	return func(res *GRPCResponse) bool {
+		return res != nil && res.Code == code
+	}
+}
+
+// PingUntil repeatedly calls the Ping method of the gRPC echo fixture
+// until "condition" returns true or the timeout is reached.
+// It always returns the last gRPC response received.
+func (g *GRPCClient) PingUntil(opts *GRPCRequestOpts) (*GRPCResponse, bool) {
+	return g.requestUntil(opts, func(ctx context.Context, conn *grpc.ClientConn) (string, error) {
+		resp, err := yages.NewEchoClient(conn).Ping(ctx, &yages.Empty{})
+		if err != nil {
+			return "", err
+		}
+		return resp.Text, nil
+	})
+}
+
+// HealthCheckUntil repeatedly calls the standard gRPC health checking
+// service of the gRPC echo fixture until "condition" returns true or
+// the timeout is reached. It always returns the last gRPC response received.
+func (g *GRPCClient) HealthCheckUntil(opts *GRPCRequestOpts) (*GRPCResponse, bool) {
+	return g.requestUntil(opts, func(ctx context.Context, conn *grpc.ClientConn) (string, error) {
+		resp, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+		if err != nil {
+			return "", err
+		}
+		return resp.Status.String(), nil
+	})
+}
+
+func (g *GRPCClient) requestUntil(opts *GRPCRequestOpts, call func(context.Context, *grpc.ClientConn) (string, error)) (*GRPCResponse, bool) {
+	addr := g.InsecureAddr
+	creds := insecure.NewCredentials()
+
+	if opts.Secure {
+		addr = g.SecureAddr
+
+		tlsConfig := &tls.Config{
+			ServerName: opts.Host,
+			//nolint:gosec
+			InsecureSkipVerify: true,
+		}
+		for _, opt := range opts.TLSConfigOpts {
+			opt(tlsConfig)
+		}
+		creds = credentials.NewTLS(tlsConfig)
+	}
+
+	if opts.OverrideAddr != "" {
+		addr = opts.OverrideAddr
+	}
+
+	dialOpts := append([]grpc.DialOption{
+		grpc.WithAuthority(opts.Host),
+		grpc.WithTransportCredentials(creds),
+	}, opts.DialOpts...)
+
+	var res *GRPCResponse
+
+	if err := wait.PollUntilContextTimeout(context.Background(), g.RetryInterval, g.RetryTimeout, true, func(ctx context.Context) (bool, error) {
+		// Dial a new connection for every request so we don't
+		// inadvertently keep making requests to a draining Listener.
+		conn, err := grpc.DialContext(ctx, addr, dialOpts...)
+		if err != nil {
+			g.t.Logf("dial error: %s", err)
+			// if there was an error, we want to keep
+			// retrying, so just return false, not an
+			// error.
+			return false, nil
+		}
+		defer conn.Close()
+
+		callCtx, cancel := context.WithTimeout(ctx, g.RetryInterval*5)
+		defer cancel()
+
+		text, err := call(callCtx, conn)
+		st := status.Convert(err)
+
+		res = &GRPCResponse{
+			Code:    st.Code(),
+			Message: st.Message(),
+			Text:    text,
+		}
+
+		if opts.Condition != nil {
+			return opts.Condition(res), nil
+		}
+		return false, nil
+	}); err != nil {
+		return res, false
+	}
+
+	return res, true
+}
+
+// grpcAddr strips the scheme from an HTTP URL base to
+// give the address to make gRPC requests to.
+func grpcAddr(urlBase string) string {
+	_, addr, found := strings.Cut(urlBase, "://")
+	if !found {
+		return urlBase
+	}
+	return addr
+}
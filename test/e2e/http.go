@@ -207,6 +207,19 @@ func OptSetSNI(name string) func(*tls.Config) {
 	}
 }
 
+// OptUseClientCert returns a TLS config functional option
+// that presents the provided client certificate.
+func OptUseClientCert(cert *tls.Certificate) func(*tls.Config) {
+	return func(c *tls.Config) {
+		// Use c.GetClientCertificate rather than setting c.Certificates so the
+		// client cert specified is always presented, regardless of the request
+		// details from the server.
+		c.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return cert, nil
+		}
+	}
+}
+
 // SecureRequestUntil repeatedly makes HTTPS requests with the provided
 // parameters until "condition" returns true or the timeout is reached.
 // It always returns the last HTTP response received.
@@ -272,52 +272,10 @@ func testClientCertAuth(namespace string) {
 		require.NoError(t, f.Client.Create(context.TODO(), echoWithOptionalAuthNoCA))
 
 		// Get a client certificate.
-		clientCert := &certmanagerv1.Certificate{
-			ObjectMeta: metav1.ObjectMeta{
-				Namespace: namespace,
-				Name:      "echo-client-cert",
-			},
-			Spec: certmanagerv1.CertificateSpec{
-				Usages: []certmanagerv1.KeyUsage{
-					certmanagerv1.UsageClientAuth,
-				},
-				EmailAddresses: []string{
-					"client@projectcontour.io",
-				},
-				CommonName: "client",
-				SecretName: "echo-client",
-				IssuerRef: certmanagermetav1.ObjectReference{
-					Name: "ca-projectcontour-io",
-				},
-			},
-		}
-		// Wait for the Cert to be ready since we'll directly download
-		// the secret contents for use as a client cert later on.
-		f.Certs.CreateCertAndWaitFor(clientCert, certIsReady)
+		validClientCert, _ := f.Certs.CreateClientCert(namespace, "echo-client", "ca-projectcontour-io", "client@projectcontour.io")
 
 		// Get another client certificate.
-		clientCertInvalid := &certmanagerv1.Certificate{
-			ObjectMeta: metav1.ObjectMeta{
-				Namespace: namespace,
-				Name:      "echo-client-cert-invalid",
-			},
-			Spec: certmanagerv1.CertificateSpec{
-				Usages: []certmanagerv1.KeyUsage{
-					certmanagerv1.UsageClientAuth,
-				},
-				EmailAddresses: []string{
-					"badclient@projectcontour.io",
-				},
-				CommonName: "badclient",
-				SecretName: "echo-client-invalid",
-				IssuerRef: certmanagermetav1.ObjectReference{
-					Name: "ca-notprojectcontour-io",
-				},
-			},
-		}
-		// Wait for the Cert to be ready since we'll directly download
-		// the secret contents for use as a client cert later on.
-		f.Certs.CreateCertAndWaitFor(clientCertInvalid, certIsReady)
+		invalidClientCert, _ := f.Certs.CreateClientCert(namespace, "echo-client-invalid", "ca-notprojectcontour-io", "badclient@projectcontour.io")
 
 		// This proxy does not require client certificate auth.
 		noAuthProxy := &contourv1.HTTPProxy{
@@ -499,10 +457,6 @@ func testClientCertAuth(namespace string) {
 		}
 		f.CreateHTTPProxyAndWaitFor(optionalAuthNoCAProxy, e2e.HTTPProxyValid)
 
-		// get the valid & invalid client certs
-		validClientCert, _ := f.Certs.GetTLSCertificate(namespace, clientCert.Spec.SecretName)
-		invalidClientCert, _ := f.Certs.GetTLSCertificate(namespace, clientCertInvalid.Spec.SecretName)
-
 		cases := map[string]struct {
 			host       string
 			clientCert *tls.Certificate
@@ -515,12 +469,12 @@ func testClientCertAuth(namespace string) {
 			},
 			"echo-no-auth with echo-client-cert should succeed": {
 				host:       noAuthProxy.Spec.VirtualHost.Fqdn,
-				clientCert: &validClientCert,
+				clientCert: validClientCert,
 				wantErr:    "",
 			},
 			"echo-no-auth with echo-client-cert-invalid should succeed": {
 				host:       noAuthProxy.Spec.VirtualHost.Fqdn,
-				clientCert: &invalidClientCert,
+				clientCert: invalidClientCert,
 				wantErr:    "",
 			},
 
@@ -531,12 +485,12 @@ func testClientCertAuth(namespace string) {
 			},
 			"echo-with-auth with echo-client-cert should succeed": {
 				host:       authProxy.Spec.VirtualHost.Fqdn,
-				clientCert: &validClientCert,
+				clientCert: validClientCert,
 				wantErr:    "",
 			},
 			"echo-with-auth with echo-client-cert-invalid should error": {
 				host:       authProxy.Spec.VirtualHost.Fqdn,
-				clientCert: &invalidClientCert,
+				clientCert: invalidClientCert,
 				wantErr:    "tls: unknown certificate authority",
 			},
 
@@ -547,12 +501,12 @@ func testClientCertAuth(namespace string) {
 			},
 			"echo-with-auth-skip-verify with echo-client-cert should succeed": {
 				host:       authSkipVerifyProxy.Spec.VirtualHost.Fqdn,
-				clientCert: &validClientCert,
+				clientCert: validClientCert,
 				wantErr:    "",
 			},
 			"echo-with-auth-skip-verify with echo-client-cert-invalid should succeed": {
 				host:       authSkipVerifyProxy.Spec.VirtualHost.Fqdn,
-				clientCert: &invalidClientCert,
+				clientCert: invalidClientCert,
 				wantErr:    "",
 			},
 
@@ -563,12 +517,12 @@ func testClientCertAuth(namespace string) {
 			},
 			"echo-with-auth-skip-verify-with-ca with echo-client-cert should succeed": {
 				host:       authSkipVerifyWithCAProxy.Spec.VirtualHost.Fqdn,
-				clientCert: &validClientCert,
+				clientCert: validClientCert,
 				wantErr:    "",
 			},
 			"echo-with-auth-skip-verify-with-ca with echo-client-cert-invalid should succeed": {
 				host:       authSkipVerifyWithCAProxy.Spec.VirtualHost.Fqdn,
-				clientCert: &invalidClientCert,
+				clientCert: invalidClientCert,
 				wantErr:    "",
 			},
 
@@ -579,12 +533,12 @@ func testClientCertAuth(namespace string) {
 			},
 			"echo-with-optional-auth with echo-client-cert should succeed": {
 				host:       optionalAuthProxy.Spec.VirtualHost.Fqdn,
-				clientCert: &validClientCert,
+				clientCert: validClientCert,
 				wantErr:    "",
 			},
 			"echo-with-optional-auth with echo-client-cert-invalid should error": {
 				host:       optionalAuthProxy.Spec.VirtualHost.Fqdn,
-				clientCert: &invalidClientCert,
+				clientCert: invalidClientCert,
 				wantErr:    "tls: unknown certificate authority",
 			},
 			"echo-with-optional-auth-no-ca without a client cert should succeed": {
@@ -594,12 +548,12 @@ func testClientCertAuth(namespace string) {
 			},
 			"echo-with-optional-auth-no-ca with echo-client-cert should succeed": {
 				host:       optionalAuthNoCAProxy.Spec.VirtualHost.Fqdn,
-				clientCert: &validClientCert,
+				clientCert: validClientCert,
 				wantErr:    "",
 			},
 			"echo-with-optional-auth-no-ca with echo-client-cert-invalid should succeed": {
 				host:       optionalAuthNoCAProxy.Spec.VirtualHost.Fqdn,
-				clientCert: &invalidClientCert,
+				clientCert: invalidClientCert,
 				wantErr:    "",
 			},
 		}
@@ -610,7 +564,7 @@ func testClientCertAuth(namespace string) {
 				Host: tc.host,
 			}
 			if tc.clientCert != nil {
-				opts.TLSConfigOpts = append(opts.TLSConfigOpts, optUseClientCert(tc.clientCert))
+				opts.TLSConfigOpts = append(opts.TLSConfigOpts, e2e.OptUseClientCert(tc.clientCert))
 			}
 
 			switch {
@@ -635,23 +589,3 @@ func testClientCertAuth(namespace string) {
 		}
 	})
 }
-
-func optUseClientCert(cert *tls.Certificate) func(*tls.Config) {
-	return func(c *tls.Config) {
-		// Use c.GetClientCertificate rather than setting c.Certificates so the
-		// client cert specified is always presented, regardless of the request
-		// details from the server.
-		c.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
-			return cert, nil
-		}
-	}
-}
-
-func certIsReady(cert *certmanagerv1.Certificate) bool {
-	for _, cond := range cert.Status.Conditions {
-		if cond.Type == certmanagerv1.CertificateConditionReady && cond.Status == certmanagermetav1.ConditionTrue {
-			return true
-		}
-	}
-	return false
-}
@@ -298,7 +298,7 @@ func testClientCertRevocation(namespace string) {
 				Host: tc.host,
 			}
 			if tc.clientCert != nil {
-				opts.TLSConfigOpts = append(opts.TLSConfigOpts, optUseClientCert(tc.clientCert))
+				opts.TLSConfigOpts = append(opts.TLSConfigOpts, e2e.OptUseClientCert(tc.clientCert))
 			}
 
 			switch {
@@ -423,7 +423,7 @@ func testClientCertRevocation(namespace string) {
 
 		opts := &e2e.HTTPSRequestOpts{
 			Host:          "crl-rotate.projectcontour.io",
-			TLSConfigOpts: []func(*tls.Config){optUseClientCert(tlsCertificate(t, &client))},
+			TLSConfigOpts: []func(*tls.Config){e2e.OptUseClientCert(tlsCertificate(t, &client))},
 		}
 
 		// TLS connection will fail since client certificate is revoked.
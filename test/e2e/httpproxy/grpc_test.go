@@ -125,6 +125,60 @@ func testGRPCServicePlaintext(namespace string) {
 	})
 }
 
+func testGRPCHealthCheck(namespace string) {
+	Specify("requests to the gRPC health checking service work as expected", func() {
+		t := f.T()
+
+		f.Fixtures.GRPC.Deploy(namespace, "grpc-echo")
+
+		p := &contourv1.HTTPProxy{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      "grpc-health",
+			},
+			Spec: contourv1.HTTPProxySpec{
+				VirtualHost: &contourv1.VirtualHost{
+					Fqdn: "grpc-health.projectcontour.io",
+				},
+				Routes: []contourv1.Route{
+					{
+						Services: []contourv1.Service{
+							{
+								Name:     "grpc-echo",
+								Port:     9000,
+								Protocol: ref.To("h2c"),
+							},
+						},
+						Conditions: []contourv1.MatchCondition{
+							{
+								Prefix: "/grpc.health.v1.Health/",
+							},
+						},
+					},
+				},
+			},
+		}
+		_, ok := f.CreateHTTPProxyAndWaitFor(p, e2e.HTTPProxyValid)
+		require.True(t, ok)
+
+		res, ok := f.GRPC.HealthCheckUntil(&e2e.GRPCRequestOpts{
+			Host:      p.Spec.VirtualHost.Fqdn,
+			Condition: e2e.HasGRPCCode(codes.OK),
+		})
+		require.NotNil(t, res, "request never succeeded")
+		require.Truef(t, ok, "expected gRPC status OK, got %d: %s", res.Code, res.Message)
+		require.Equal(t, "SERVING", res.Text)
+
+		// Requests to other services don't match the route.
+		res, ok = f.GRPC.PingUntil(&e2e.GRPCRequestOpts{
+			Host:      p.Spec.VirtualHost.Fqdn,
+			Condition: e2e.HasGRPCCode(codes.Unimplemented),
+		})
+		require.NotNil(t, res, "request never succeeded")
+		require.Truef(t, ok, "expected gRPC status Unimplemented, got %d: %s", res.Code, res.Message)
+	})
+}
+
 func testGRPCWeb(namespace string) {
 	Specify("grpc-Web HTTP requests to a gRPC service work as expected", func() {
 		t := f.T()
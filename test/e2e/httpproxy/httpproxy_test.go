@@ -42,17 +42,21 @@ func TestHTTPProxy(t *testing.T) {
 	RunSpecs(t, "HTTPProxy tests")
 }
 
-var _ = BeforeSuite(func() {
+// Each Ginkgo process runs its own Envoy, so that
+// specs can run in parallel.
+var _ = SynchronizedBeforeSuite(func() {}, func() {
 	require.NoError(f.T(), f.Deployment.EnsureResourcesForLocalContour())
 })
 
-var _ = AfterSuite(func() {
+var _ = SynchronizedAfterSuite(func() {
+	f.Kubectl.StopPortForwards()
+	gexec.CleanupBuildArtifacts()
+
 	// Delete resources individually instead of deleting the entire contour
 	// namespace as a performance optimization, because deleting non-empty
 	// namespaces can take up to a couple minutes to complete.
 	require.NoError(f.T(), f.Deployment.DeleteResourcesForLocalContour())
-	gexec.CleanupBuildArtifacts()
-})
+}, func() {})
 
 // Contains specs that test that kubebuilder API validations
 // work as expected, and do not require a Contour instance to
@@ -65,6 +69,8 @@ var _ = Describe("HTTPProxy API validation", func() {
 	f.NamespacedTest("invalid-cookie-rewrite-fields", testInvalidCookieRewriteFields)
 })
 
+// Each spec runs its own local Contour, which the
+// Envoy of its Ginkgo process connects to.
 var _ = Describe("HTTPProxy", func() {
 	var (
 		contourCmd            *gexec.Session
@@ -99,6 +105,7 @@ var _ = Describe("HTTPProxy", func() {
 
 		// Wait for Envoy to be healthy.
 		require.NoError(f.T(), f.Deployment.WaitForEnvoyUpdated())
+		f.ForwardEnvoyPorts()
 	})
 
 	AfterEach(func() {
@@ -290,6 +297,8 @@ var _ = Describe("HTTPProxy", func() {
 
 	f.NamespacedTest("httpproxy-host-header-rewrite", testHostHeaderRewrite)
 
+	f.NamespacedTest("httpproxy-websockets", testWebSockets)
+
 	f.NamespacedTest("httpproxy-ip-filters", func(namespace string) {
 		// ip filter tests rely on the ability to forge x-forwarded-for
 		Context("with trusted xff hops", func() {
@@ -655,6 +664,8 @@ descriptors:
 	Context("gRPC tests", func() {
 		f.NamespacedTest("grpc-upstream-plaintext", testGRPCServicePlaintext)
 
+		f.NamespacedTest("grpc-health-check", testGRPCHealthCheck)
+
 		f.NamespacedTest("grpc-web", testGRPCWeb)
 	})
 
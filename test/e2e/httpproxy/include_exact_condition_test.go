@@ -28,17 +28,27 @@ import (
 )
 
 func testIncludeExactCondition(namespace string) {
-	Specify("HTTPProxy include exacts can cross namespaces", func() {
-		var (
-			t              = f.T()
-			appNamespace   = "httpproxy-include-exact-condition-app"
-			adminNamespace = "httpproxy-include-exact-condition-admin"
-		)
+	var (
+		appNamespace   = "httpproxy-include-exact-condition-app"
+		adminNamespace = "httpproxy-include-exact-condition-admin"
+	)
 
+	// The namespaces are created before Contour is started,
+	// so that it watches them when specs are run in parallel.
+	BeforeEach(func() {
 		for _, ns := range []string{appNamespace, adminNamespace} {
 			f.CreateNamespace(ns)
-			defer f.DeleteNamespace(ns, false)
 		}
+	})
+
+	AfterEach(func() {
+		for _, ns := range []string{appNamespace, adminNamespace} {
+			f.DeleteNamespace(ns, false)
+		}
+	})
+
+	Specify("HTTPProxy include exacts can cross namespaces", func() {
+		t := f.T()
 
 		f.Fixtures.Echo.Deploy(appNamespace, "echo-app")
 		f.Fixtures.Echo.Deploy(adminNamespace, "echo-admin")
@@ -27,17 +27,27 @@ import (
 )
 
 func testIncludePrefixCondition(namespace string) {
-	Specify("HTTPProxy include prefixes can cross namespaces", func() {
-		var (
-			t              = f.T()
-			appNamespace   = "httpproxy-include-prefix-condition-app"
-			adminNamespace = "httpproxy-include-prefix-condition-admin"
-		)
+	var (
+		appNamespace   = "httpproxy-include-prefix-condition-app"
+		adminNamespace = "httpproxy-include-prefix-condition-admin"
+	)
 
+	// The namespaces are created before Contour is started,
+	// so that it watches them when specs are run in parallel.
+	BeforeEach(func() {
 		for _, ns := range []string{appNamespace, adminNamespace} {
 			f.CreateNamespace(ns)
-			defer f.DeleteNamespace(ns, false)
 		}
+	})
+
+	AfterEach(func() {
+		for _, ns := range []string{appNamespace, adminNamespace} {
+			f.DeleteNamespace(ns, false)
+		}
+	})
+
+	Specify("HTTPProxy include prefixes can cross namespaces", func() {
+		t := f.T()
 
 		f.Fixtures.Echo.Deploy(appNamespace, "echo-app")
 		f.Fixtures.Echo.Deploy(adminNamespace, "echo-admin")
@@ -27,17 +27,27 @@ import (
 )
 
 func testIncludeRegexCondition(namespace string) {
-	Specify("HTTPProxy with included regex and prefix HTTPProxies", func() {
-		var (
-			t              = f.T()
-			echo1Namespace = "echo-1"
-			echo2Namespace = "echo-2"
-		)
-
+	var (
+		echo1Namespace = "echo-1"
+		echo2Namespace = "echo-2"
+	)
+
+	// The namespaces are created before Contour is started,
+	// so that it watches them when specs are run in parallel.
+	BeforeEach(func() {
 		for _, ns := range []string{echo1Namespace, echo2Namespace} {
 			f.CreateNamespace(ns)
-			defer f.DeleteNamespace(ns, false)
 		}
+	})
+
+	AfterEach(func() {
+		for _, ns := range []string{echo1Namespace, echo2Namespace} {
+			f.DeleteNamespace(ns, false)
+		}
+	})
+
+	Specify("HTTPProxy with included regex and prefix HTTPProxies", func() {
+		t := f.T()
 
 		f.Fixtures.Echo.Deploy(echo1Namespace, "echo-1")
 		f.Fixtures.Echo.Deploy(echo2Namespace, "echo-2")
@@ -0,0 +1,95 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build e2e
+
+package httpproxy
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	contourv1 "github.com/projectcontour/contour/apis/projectcontour/v1"
+	"github.com/projectcontour/contour/test/e2e"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testWebSockets(namespace string) {
+	Specify("websocket upgrades are only allowed on routes that enable them", func() {
+		t := f.T()
+
+		f.Fixtures.WebSocket.Deploy(namespace, "websocket-echo")
+		f.Certs.CreateSelfSignedCert(namespace, "websocket", "websocket", "websockets.projectcontour.io")
+
+		p := &contourv1.HTTPProxy{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      "websockets",
+			},
+			Spec: contourv1.HTTPProxySpec{
+				VirtualHost: &contourv1.VirtualHost{
+					Fqdn: "websockets.projectcontour.io",
+					TLS: &contourv1.TLS{
+						SecretName: "websocket",
+					},
+				},
+				Routes: []contourv1.Route{
+					{
+						// So we can make TLS and non-TLS requests.
+						PermitInsecure:   true,
+						EnableWebsockets: true,
+						Services: []contourv1.Service{
+							{
+								Name: "websocket-echo",
+								Port: 80,
+							},
+						},
+						Conditions: []contourv1.MatchCondition{
+							{
+								Prefix: "/ws",
+							},
+						},
+					},
+					{
+						PermitInsecure: true,
+						Services: []contourv1.Service{
+							{
+								Name: "websocket-echo",
+								Port: 80,
+							},
+						},
+					},
+				},
+			},
+		}
+		_, ok := f.CreateHTTPProxyAndWaitFor(p, e2e.HTTPProxyValid)
+		require.True(t, ok)
+
+		for _, secure := range []bool{false, true} {
+			res, ok := f.HTTP.WebSocketRequestUntil(&e2e.WebSocketRequestOpts{
+				Host:      p.Spec.VirtualHost.Fqdn,
+				Path:      "/ws",
+				Secure:    secure,
+				Messages:  []string{"hello"},
+				Condition: e2e.HasWebSocketMessage("hello"),
+			})
+			require.NotNil(t, res, "websocket connection never succeeded")
+			require.Truef(t, ok, "expected echoed message, got %v", res.Messages)
+		}
+
+		_, err := f.HTTP.WebSocketDial(&e2e.WebSocketRequestOpts{
+			Host: p.Spec.VirtualHost.Fqdn,
+			Path: "/",
+		})
+		require.Error(t, err, "expected websocket upgrade to fail on a route without websockets enabled")
+	})
+}
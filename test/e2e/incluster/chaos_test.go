@@ -0,0 +1,233 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build e2e
+
+package incluster
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	contourv1 "github.com/projectcontour/contour/apis/projectcontour/v1"
+	"github.com/projectcontour/contour/internal/ref"
+	"github.com/projectcontour/contour/test/e2e"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// chaosRecoveryTimeout is how long Contour and Envoy may take to
+// serve up to date routes again after a failure has been injected.
+// This allows for terminating Envoy pods to drain connections.
+const chaosRecoveryTimeout = 2 * time.Minute
+
+func testChaos(namespace string) {
+	Specify("routes are updated after the contour leader is killed under load", func() {
+		p := deployChaosApp(namespace)
+
+		poller, err := e2e.StartAppPoller(f.HTTP.HTTPURLBase, p.Spec.VirtualHost.Fqdn, http.StatusOK, GinkgoWriter)
+		require.NoError(f.T(), err)
+
+		var originalLeader string
+		require.Eventually(f.T(), func() bool {
+			originalLeader, err = getLeaderID()
+			return err == nil
+		}, 2*time.Minute, f.RetryInterval)
+
+		By("deleting the contour leader pod")
+		leaderPod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      podNameFromLeaderID(originalLeader),
+				Namespace: f.Deployment.Namespace.Name,
+			},
+		}
+		require.NoError(f.T(), f.Client.Delete(context.TODO(), leaderPod))
+
+		require.Eventually(f.T(), func() bool {
+			newLeader, err := getLeaderID()
+			return err == nil && newLeader != originalLeader
+		}, 2*time.Minute, f.RetryInterval)
+
+		By("ensuring routes are updated by the new leader")
+		switchChaosAppBackend(p, "echo-v2")
+
+		// Envoy keeps running throughout, so no requests should fail.
+		poller.Stop()
+		checkChaosPollerResults(poller, 99)
+	})
+
+	Specify("routes are recovered after envoy pods are restarted under load", func() {
+		p := deployChaosApp(namespace)
+
+		poller, err := e2e.StartAppPoller(f.HTTP.HTTPURLBase, p.Spec.VirtualHost.Fqdn, http.StatusOK, GinkgoWriter)
+		require.NoError(f.T(), err)
+
+		By("deleting all envoy pods")
+		selector := f.Deployment.EnvoyDaemonSet.Spec.Selector
+		if f.Deployment.EnvoyDeploymentMode == e2e.DeploymentMode {
+			selector = f.Deployment.EnvoyDeployment.Spec.Selector
+		}
+		start := time.Now()
+		require.NoError(f.T(), f.Client.DeleteAllOf(context.TODO(), &corev1.Pod{},
+			client.InNamespace(f.Deployment.Namespace.Name),
+			client.MatchingLabelsSelector{Selector: labels.SelectorFromSet(selector.MatchLabels)},
+		))
+
+		require.NoError(f.T(), f.Deployment.WaitForEnvoyUpdated())
+		checkChaosAppBackend(p, "echo-v1")
+		f.T().Logf("Recovery time after envoy restart: %s\n", time.Since(start))
+		require.LessOrEqual(f.T(), time.Since(start), chaosRecoveryTimeout, "envoy took too long to recover")
+
+		By("ensuring routes are updated after envoy restarts")
+		switchChaosAppBackend(p, "echo-v2")
+
+		// Requests fail to connect while envoy restarts, so
+		// don't require a particular success rate.
+		poller.Stop()
+		checkChaosPollerResults(poller, 0)
+	})
+
+	Specify("envoy serves the last known routes while the xds connection is down", func() {
+		p := deployChaosApp(namespace)
+
+		poller, err := e2e.StartAppPoller(f.HTTP.HTTPURLBase, p.Spec.VirtualHost.Fqdn, http.StatusOK, GinkgoWriter)
+		require.NoError(f.T(), err)
+
+		By("scaling contour down to cut envoy off from xds")
+		deployment := &appsv1.Deployment{}
+		require.NoError(f.T(), f.Client.Get(context.TODO(), client.ObjectKeyFromObject(f.Deployment.ContourDeployment), deployment))
+		replicas := ref.Val(deployment.Spec.Replicas, 1)
+		scaleContour(0)
+		require.Eventually(f.T(), func() bool {
+			pods := &corev1.PodList{}
+			if err := f.Client.List(context.TODO(), pods,
+				client.InNamespace(f.Deployment.Namespace.Name),
+				client.MatchingLabels(f.Deployment.ContourDeployment.Spec.Selector.MatchLabels),
+			); err != nil {
+				return false
+			}
+			return len(pods.Items) == 0
+		}, 2*time.Minute, f.RetryInterval)
+
+		By("changing routes while the xds connection is down")
+		updateChaosAppBackend(p, "echo-v2")
+
+		// Envoy can't receive the change so it should keep
+		// serving the last known routes.
+		for i := 0; i < 5; i++ {
+			checkChaosAppBackend(p, "echo-v1")
+			time.Sleep(f.RetryInterval)
+		}
+
+		By("restoring the xds connection")
+		start := time.Now()
+		scaleContour(replicas)
+		checkChaosAppBackend(p, "echo-v2")
+		f.T().Logf("Recovery time after restoring xds: %s\n", time.Since(start))
+		require.LessOrEqual(f.T(), time.Since(start), chaosRecoveryTimeout, "envoy took too long to receive updated routes")
+
+		poller.Stop()
+		checkChaosPollerResults(poller, 99)
+	})
+}
+
+// deployChaosApp deploys two echo services and an HTTPProxy
+// routing to the first of them, and waits for it to be routable.
+func deployChaosApp(namespace string) *contourv1.HTTPProxy {
+	f.Fixtures.Echo.Deploy(namespace, "echo-v1")
+	f.Fixtures.Echo.Deploy(namespace, "echo-v2")
+
+	p := &contourv1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      "chaos",
+		},
+		Spec: contourv1.HTTPProxySpec{
+			VirtualHost: &contourv1.VirtualHost{
+				Fqdn: "chaos.projectcontour.io",
+			},
+			Routes: []contourv1.Route{
+				{
+					Services: []contourv1.Service{
+						{
+							Name: "echo-v1",
+							Port: 80,
+						},
+					},
+				},
+			},
+		},
+	}
+	_, ok := f.CreateHTTPProxyAndWaitFor(p, e2e.HTTPProxyValid)
+	require.True(f.T(), ok)
+
+	checkChaosAppBackend(p, "echo-v1")
+
+	return p
+}
+
+// updateChaosAppBackend points the HTTPProxy's route at service.
+func updateChaosAppBackend(p *contourv1.HTTPProxy, service string) {
+	require.NoError(f.T(), f.Client.Get(context.TODO(), client.ObjectKeyFromObject(p), p))
+	p.Spec.Routes[0].Services[0].Name = service
+	require.NoError(f.T(), f.Client.Update(context.TODO(), p))
+}
+
+// switchChaosAppBackend points the HTTPProxy's route at service and
+// requires that Envoy stops serving the old route within chaosRecoveryTimeout.
+func switchChaosAppBackend(p *contourv1.HTTPProxy, service string) {
+	start := time.Now()
+	updateChaosAppBackend(p, service)
+	checkChaosAppBackend(p, service)
+
+	f.T().Logf("Route propagation delay: %s\n", time.Since(start))
+	require.LessOrEqual(f.T(), time.Since(start), chaosRecoveryTimeout, "stale route served for too long")
+}
+
+// checkChaosAppBackend requires that requests are eventually served by service.
+func checkChaosAppBackend(p *contourv1.HTTPProxy, service string) {
+	res, ok := f.HTTP.RequestUntil(&e2e.HTTPRequestOpts{
+		Host: p.Spec.VirtualHost.Fqdn,
+		Condition: func(res *e2e.HTTPResponse) bool {
+			return e2e.HasStatusCode(http.StatusOK)(res) && f.GetEchoResponseBody(res.Body).Service == service
+		},
+	})
+	require.NotNil(f.T(), res, "request never succeeded")
+	require.Truef(f.T(), ok, "expected 200 response from %s, got %d", service, res.StatusCode)
+}
+
+// checkChaosPollerResults requires that the poller received no 5xx
+// responses and that at least minSuccessPercentage of its requests
+// succeeded.
+func checkChaosPollerResults(poller *e2e.AppPoller, minSuccessPercentage float64) {
+	totalRequests, successfulRequests := poller.Results()
+	f.T().Logf("Total requests: %d, successful requests: %d, 5xx responses: %d\n", totalRequests, successfulRequests, poller.ServerErrors())
+	require.Greater(f.T(), totalRequests, uint(0))
+	require.Zero(f.T(), poller.ServerErrors(), "received 5xx responses")
+
+	successPercentage := 100 * float64(successfulRequests) / float64(totalRequests)
+	require.GreaterOrEqualf(f.T(), successPercentage, minSuccessPercentage, "success rate of %.2f%% less than %.2f%%", successPercentage, minSuccessPercentage)
+}
+
+func scaleContour(replicas int32) {
+	deployment := &appsv1.Deployment{}
+	require.NoError(f.T(), f.Client.Get(context.TODO(), client.ObjectKeyFromObject(f.Deployment.ContourDeployment), deployment))
+	deployment.Spec.Replicas = ref.To(replicas)
+	require.NoError(f.T(), f.Client.Update(context.TODO(), deployment))
+}
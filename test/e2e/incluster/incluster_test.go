@@ -40,7 +40,7 @@ func TestIncluster(t *testing.T) {
 	RunSpecs(t, "In-cluster tests")
 }
 
-var _ = BeforeSuite(func() {
+var _ = SynchronizedBeforeSuite(func() {
 	// Default to using ContourConfiguration CRD and debug logging.
 	originalArgs := f.Deployment.ContourDeployment.Spec.Template.Spec.Containers[0].Args
 	var newArgs []string
@@ -54,16 +54,18 @@ var _ = BeforeSuite(func() {
 	f.Deployment.ContourDeployment.Spec.Template.Spec.Containers[0].Args = newArgs
 
 	require.NoError(f.T(), f.Deployment.EnsureResourcesForInclusterContour(false))
-})
+}, func() {})
 
-var _ = AfterSuite(func() {
+var _ = SynchronizedAfterSuite(func() {}, func() {
 	// Delete resources individually instead of deleting the entire contour
 	// namespace as a performance optimization, because deleting non-empty
 	// namespaces can take up to a couple minutes to complete.
 	require.NoError(f.T(), f.Deployment.DeleteResourcesForInclusterContour())
 })
 
-var _ = Describe("Incluster", func() {
+// Each spec deploys Contour into the shared projectcontour
+// namespace, so these specs can't run in parallel.
+var _ = Describe("Incluster", Serial, func() {
 	var contourConfig *contour_api_v1alpha1.ContourConfiguration
 
 	BeforeEach(func() {
@@ -103,6 +105,8 @@ var _ = Describe("Incluster", func() {
 
 	f.NamespacedTest("leader-election", testLeaderElection)
 
+	f.NamespacedTest("chaos", testChaos)
+
 	f.NamespacedTest("projectcontour-resource-rbac", testProjectcontourResourcesRBAC)
 
 	f.NamespacedTest("ingress-resource-rbac", testIngressResourceRBAC)
@@ -38,29 +38,6 @@ func testLeaderElection(namespace string) {
 	// unit tests as it is difficult to observe e.g. which contour instance
 	// has set status on an object.
 	Specify("leader election resources are created as expected", func() {
-		getLeaderID := func() (string, error) {
-			leaderElectionLease := &coordinationv1.Lease{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "leader-elect",
-					Namespace: f.Deployment.Namespace.Name,
-				},
-			}
-			if err := f.Client.Get(context.TODO(), client.ObjectKeyFromObject(leaderElectionLease), leaderElectionLease); err != nil {
-				return "", err
-			}
-
-			leaseHolder := ref.Val(leaderElectionLease.Spec.HolderIdentity, "")
-			if !strings.HasPrefix(leaseHolder, "contour-") {
-				return "", fmt.Errorf("invalid leader name: %q", leaseHolder)
-			}
-			return leaseHolder, nil
-		}
-
-		podNameFromLeaderID := func(id string) string {
-			require.Greater(f.T(), len(id), 37)
-			return id[:len(id)-37]
-		}
-
 		var originalLeader string
 		require.Eventually(f.T(), func() bool {
 			var err error
@@ -120,3 +97,30 @@ func testLeaderElection(namespace string) {
 		require.NoError(f.T(), f.Client.Get(context.TODO(), client.ObjectKeyFromObject(leaderPod), leaderPod))
 	})
 }
+
+// getLeaderID returns the identity of the Contour instance
+// holding the leader election lease.
+func getLeaderID() (string, error) {
+	leaderElectionLease := &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "leader-elect",
+			Namespace: f.Deployment.Namespace.Name,
+		},
+	}
+	if err := f.Client.Get(context.TODO(), client.ObjectKeyFromObject(leaderElectionLease), leaderElectionLease); err != nil {
+		return "", err
+	}
+
+	leaseHolder := ref.Val(leaderElectionLease.Spec.HolderIdentity, "")
+	if !strings.HasPrefix(leaseHolder, "contour-") {
+		return "", fmt.Errorf("invalid leader name: %q", leaseHolder)
+	}
+	return leaseHolder, nil
+}
+
+// podNameFromLeaderID chops the _UUID suffix off a leader identity
+// to give the name of the leader's pod.
+func podNameFromLeaderID(id string) string {
+	require.Greater(f.T(), len(id), 37)
+	return id[:len(id)-37]
+}
@@ -41,7 +41,9 @@ func TestInfra(t *testing.T) {
 	RunSpecs(t, "Infra tests")
 }
 
-var _ = BeforeSuite(func() {
+// Each Ginkgo process runs its own Envoy, so that
+// specs can run in parallel.
+var _ = SynchronizedBeforeSuite(func() {}, func() {
 	// Add volume mount for the Envoy deployment for certificate and key,
 	// used only for testing metrics over HTTPS.
 	f.Deployment.EnvoyExtraVolumeMounts = []v1.VolumeMount{{
@@ -59,14 +61,18 @@ var _ = BeforeSuite(func() {
 	require.NoError(f.T(), f.Deployment.EnsureResourcesForLocalContour())
 
 	// Create certificate and key for metrics over HTTPS.
+	namespace := f.Deployment.Namespace.Name
 	cleanup = append(cleanup,
-		f.Certs.CreateCA("projectcontour", "metrics-ca"),
-		f.Certs.CreateCert("projectcontour", "metrics-server", "metrics-ca", "localhost"),
-		f.Certs.CreateCert("projectcontour", "metrics-client", "metrics-ca"),
+		f.Certs.CreateCA(namespace, "metrics-ca"),
+		f.Certs.CreateCert(namespace, "metrics-server", "metrics-ca", "localhost"),
+		f.Certs.CreateCert(namespace, "metrics-client", "metrics-ca"),
 	)
 })
 
-var _ = AfterSuite(func() {
+var _ = SynchronizedAfterSuite(func() {
+	f.Kubectl.StopPortForwards()
+	gexec.CleanupBuildArtifacts()
+
 	// Delete resources individually instead of deleting the entire contour
 	// namespace as a performance optimization, because deleting non-empty
 	// namespaces can take up to a couple of minutes to complete.
@@ -74,13 +80,13 @@ var _ = AfterSuite(func() {
 		c()
 	}
 	require.NoError(f.T(), f.Deployment.DeleteResourcesForLocalContour())
-	gexec.CleanupBuildArtifacts()
-})
+}, func() {})
 
+// Each spec runs its own local Contour, which the
+// Envoy of its Ginkgo process connects to.
 var _ = Describe("Infra", func() {
 	var (
 		contourCmd            *gexec.Session
-		kubectlCmd            *gexec.Session
 		contourConfig         *config.Parameters
 		contourConfiguration  *contour_api_v1alpha1.ContourConfiguration
 		contourConfigFile     string
@@ -112,13 +118,15 @@ var _ = Describe("Infra", func() {
 
 		// Wait for Envoy to be healthy.
 		require.NoError(f.T(), f.Deployment.WaitForEnvoyUpdated())
+		f.ForwardEnvoyPorts()
 
-		kubectlCmd, err = f.Kubectl.StartKubectlPortForward(19001, 9001, "projectcontour", f.Deployment.EnvoyResourceAndName(), additionalContourArgs...)
+		adminAddr, err := f.Kubectl.PortForward(f.Deployment.Namespace.Name, f.Deployment.EnvoyResourceAndName(), 9001)
 		require.NoError(f.T(), err)
+		f.HTTP.HTTPURLAdminBase = "http://" + adminAddr
 	})
 
 	AfterEach(func() {
-		f.Kubectl.StopKubectlPortForward(kubectlCmd)
+		f.Kubectl.StopPortForwards()
 		require.NoError(f.T(), f.Deployment.StopLocalContour(contourCmd, contourConfigFile))
 	})
 
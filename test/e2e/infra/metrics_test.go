@@ -77,21 +77,21 @@ func testEnvoyMetricsOverHTTPS() {
 		// c) Restart port-forwarding when connection attempts fail.
 		//
 		// Executing port-forward started in BeforeEach(), JustBeforeEach() or combining metrics
-		// port with the admin port-forward command (127.0.0.1:19001 -> 9001) did not help.
+		// port with the admin port-forward command (9001) did not help.
 		//
 		// The simplest workaround (a) is taken here.
 		time.Sleep(5 * time.Second)
 
 		// Port-forward for metrics over HTTPS
-		kubectlCmd, err := f.Kubectl.StartKubectlPortForward(18003, 8003, "projectcontour", f.Deployment.EnvoyResourceAndName())
+		metricsAddr, err := f.Kubectl.PortForward(f.Deployment.Namespace.Name, f.Deployment.EnvoyResourceAndName(), 8003)
 		require.NoError(t, err)
-		defer f.Kubectl.StopKubectlPortForward(kubectlCmd)
 
-		clientCert, caBundle := f.Certs.GetTLSCertificate("projectcontour", "metrics-client")
+		clientCert, caBundle := f.Certs.GetTLSCertificate(f.Deployment.Namespace.Name, "metrics-client")
 		client := http.Client{
 			Transport: &http.Transport{
 				TLSClientConfig: &tls.Config{
 					MinVersion:   tls.VersionTLS13,
+					ServerName:   "localhost",
 					Certificates: []tls.Certificate{clientCert},
 					RootCAs:      caBundle,
 				},
@@ -99,7 +99,7 @@ func testEnvoyMetricsOverHTTPS() {
 		}
 
 		gomega.Eventually(func() int {
-			resp, err := client.Get("https://localhost:18003/stats")
+			resp, err := client.Get("https://" + metricsAddr + "/stats")
 			if err != nil {
 				GinkgoWriter.Println(err)
 				return 0
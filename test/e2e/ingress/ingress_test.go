@@ -40,18 +40,24 @@ func TestIngress(t *testing.T) {
 	RunSpecs(t, "Ingress tests")
 }
 
-var _ = BeforeSuite(func() {
+// Each Ginkgo process runs its own Envoy, so that
+// specs can run in parallel.
+var _ = SynchronizedBeforeSuite(func() {}, func() {
 	require.NoError(f.T(), f.Deployment.EnsureResourcesForLocalContour())
 })
 
-var _ = AfterSuite(func() {
+var _ = SynchronizedAfterSuite(func() {
+	f.Kubectl.StopPortForwards()
+	gexec.CleanupBuildArtifacts()
+
 	// Delete resources individually instead of deleting the entire contour
 	// namespace as a performance optimization, because deleting non-empty
 	// namespaces can take up to a couple minutes to complete.
 	require.NoError(f.T(), f.Deployment.DeleteResourcesForLocalContour())
-	gexec.CleanupBuildArtifacts()
-})
+}, func() {})
 
+// Each spec runs its own local Contour, which the
+// Envoy of its Ginkgo process connects to.
 var _ = Describe("Ingress", func() {
 	var (
 		contourCmd            *gexec.Session
@@ -84,6 +90,7 @@ var _ = Describe("Ingress", func() {
 
 		// Wait for Envoy to be healthy.
 		require.NoError(f.T(), f.Deployment.WaitForEnvoyUpdated())
+		f.ForwardEnvoyPorts()
 	})
 
 	AfterEach(func() {
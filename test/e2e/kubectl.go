@@ -18,7 +18,10 @@ package e2e
 import (
 	"fmt"
 	"io"
+	"net"
 	"os/exec"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/onsi/gomega"
@@ -29,6 +32,16 @@ import (
 type Kubectl struct {
 	// Command output is written to this writer.
 	cmdOutputWriter io.Writer
+
+	// portForwards holds the running pooled port-forwards,
+	// keyed by the namespace, object and port forwarded to.
+	portForwards map[string]*portForward
+	lock         sync.Mutex
+}
+
+type portForward struct {
+	localPort int
+	session   *gexec.Session
 }
 
 func (k *Kubectl) StartKubectlPortForward(localPort, containerPort int, namespace, object string, additionalArgs ...string) (*gexec.Session, error) {
@@ -54,3 +67,70 @@ func (k *Kubectl) StopKubectlPortForward(cmd *gexec.Session) {
 	// a minute should be more than enough to avoid them.
 	cmd.Terminate().Wait(time.Minute)
 }
+
+// PortForward returns the local address of a kubectl port-forward to
+// containerPort of object in namespace. Port-forwards are pooled, so an
+// existing one for the same target is reused if it is still running;
+// otherwise a new one is started on a free local port so that parallel
+// test processes don't contend for fixed ports. Pooled port-forwards are
+// stopped by StopPortForwards.
+func (k *Kubectl) PortForward(namespace, object string, containerPort int) (string, error) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	key := fmt.Sprintf("%s/%s:%d", namespace, object, containerPort)
+
+	if pf, ok := k.portForwards[key]; ok {
+		// ExitCode is -1 while the process is still running.
+		if pf.session.ExitCode() == -1 {
+			return localPortAddress(pf.localPort), nil
+		}
+		delete(k.portForwards, key)
+	}
+
+	localPort, err := freeLocalPort()
+	if err != nil {
+		return "", err
+	}
+
+	session, err := k.StartKubectlPortForward(localPort, containerPort, namespace, object)
+	if err != nil {
+		return "", err
+	}
+
+	if k.portForwards == nil {
+		k.portForwards = map[string]*portForward{}
+	}
+	k.portForwards[key] = &portForward{
+		localPort: localPort,
+		session:   session,
+	}
+
+	return localPortAddress(localPort), nil
+}
+
+// StopPortForwards stops all pooled port-forwards.
+func (k *Kubectl) StopPortForwards() {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	for key, pf := range k.portForwards {
+		k.StopKubectlPortForward(pf.session)
+		delete(k.portForwards, key)
+	}
+}
+
+// freeLocalPort returns a local TCP port that is not currently in use.
+func freeLocalPort() (int, error) {
+	l, err := net.Listen("tcp", localPortAddress(0))
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+func localPortAddress(port int) string {
+	return net.JoinHostPort(localAddress(), strconv.Itoa(port))
+}
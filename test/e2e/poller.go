@@ -27,14 +27,39 @@ import (
 )
 
 type AppPoller struct {
-	cancel             context.CancelFunc
-	wg                 *sync.WaitGroup
-	logger             *log.Logger
-	totalRequests      uint
-	successfulRequests uint
+	cancel               context.CancelFunc
+	wg                   *sync.WaitGroup
+	logger               *log.Logger
+	totalRequests        uint
+	successfulRequests   uint
+	serverErrorResponses uint
 }
 
+// StartAppPoller starts making requests to the app every 200ms until
+// stopped, using a new connection for every request.
 func StartAppPoller(address string, hostName string, expectedStatus int, errorWriter io.Writer) (*AppPoller, error) {
+	// Disable keep alives so connections don't stay
+	// open to terminating Envoy pods, which would cause
+	// the shutdown-manager to block waiting for the
+	// connections to drain. This lets the upgrade test
+	// be more efficient.
+	transport := makeDisableKeepAlivesTransport()
+
+	return startAppPoller(transport, address, hostName, expectedStatus, errorWriter)
+}
+
+// StartKeepAliveAppPoller starts making requests to the app every 200ms
+// until stopped, reusing connections between requests. This means requests
+// are in flight on long-lived connections while Envoy pods drain, so it can
+// be used to check that Envoy closes connections gracefully.
+func StartKeepAliveAppPoller(address string, hostName string, expectedStatus int, errorWriter io.Writer) (*AppPoller, error) {
+	//nolint:forbidigo
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	return startAppPoller(transport, address, hostName, expectedStatus, errorWriter)
+}
+
+func startAppPoller(transport *http.Transport, address string, hostName string, expectedStatus int, errorWriter io.Writer) (*AppPoller, error) {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	poller := &AppPoller{
@@ -43,13 +68,6 @@ func StartAppPoller(address string, hostName string, expectedStatus int, errorWr
 		logger: log.New(errorWriter, "", log.LstdFlags|log.Lmicroseconds|log.LUTC),
 	}
 
-	// Disable keep alives so connections don't stay
-	// open to terminating Envoy pods, which would cause
-	// the shutdown-manager to block waiting for the
-	// connections to drain. This lets the upgrade test
-	// be more efficient.
-	transport := makeDisableKeepAlivesTransport()
-
 	client := &http.Client{
 		Transport: transport,
 		Timeout:   100 * time.Millisecond,
@@ -63,6 +81,7 @@ func StartAppPoller(address string, hostName string, expectedStatus int, errorWr
 	poller.wg.Add(1)
 	go func() {
 		defer poller.wg.Done()
+		defer transport.CloseIdleConnections()
 		poller.logger.Println("started app poller loop")
 		// Ignore error here since we know we are just polling until
 		// told to stop.
@@ -81,6 +100,13 @@ func StartAppPoller(address string, hostName string, expectedStatus int, errorWr
 				poller.logger.Printf("error making request #%d: %s\n", poller.totalRequests, err)
 				return false, nil
 			}
+			// Drain the body so the connection can be reused.
+			_, _ = io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+
+			if res.StatusCode >= http.StatusInternalServerError {
+				poller.serverErrorResponses++
+			}
 			if res.StatusCode == expectedStatus {
 				poller.successfulRequests++
 			} else {
@@ -102,3 +128,10 @@ func (p *AppPoller) Stop() {
 func (p *AppPoller) Results() (uint, uint) {
 	return p.totalRequests, p.successfulRequests
 }
+
+// ServerErrors returns the number of requests that received
+// a 5xx response. Requests that failed without receiving a
+// response are not counted.
+func (p *AppPoller) ServerErrors() uint {
+	return p.serverErrorResponses
+}
@@ -0,0 +1,87 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build e2e
+
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Provider determines what kind of cluster the tests run against.
+type Provider string
+
+const (
+	// KindProvider runs tests against a local kind cluster created by
+	// test/scripts/make-kind-cluster.sh. The framework provisions
+	// Contour and Envoy itself and reaches Envoy via the host ports
+	// forwarded by the kind node.
+	KindProvider Provider = "kind"
+
+	// ExternalProvider runs tests against a pre-provisioned cluster,
+	// e.g. a managed Kubernetes offering, that already has Contour and
+	// Envoy installed. The framework does not deploy or delete Contour
+	// and reaches Envoy via its LoadBalancer Service address.
+	ExternalProvider Provider = "external"
+)
+
+// discoverEnvoyURLBases waits for the Envoy Service to be assigned a
+// LoadBalancer address and returns the HTTP and HTTPS URL bases for it.
+func discoverEnvoyURLBases(c client.Client, key types.NamespacedName, interval, timeout time.Duration) (string, string, error) {
+	svc := &corev1.Service{}
+
+	var addr string
+	if err := wait.PollUntilContextTimeout(context.Background(), interval, timeout, true, func(ctx context.Context) (bool, error) {
+		if err := c.Get(ctx, key, svc); err != nil {
+			return false, nil
+		}
+		for _, ingress := range svc.Status.LoadBalancer.Ingress {
+			switch {
+			case ingress.IP != "":
+				addr = ingress.IP
+			case ingress.Hostname != "":
+				addr = ingress.Hostname
+			}
+			if addr != "" {
+				return true, nil
+			}
+		}
+		return false, nil
+	}); err != nil {
+		return "", "", fmt.Errorf("timed out waiting for service %s to be assigned a load balancer address: %w", key, err)
+	}
+
+	httpPort, httpsPort := int32(80), int32(443)
+	for _, port := range svc.Spec.Ports {
+		switch port.Name {
+		case "http":
+			httpPort = port.Port
+		case "https":
+			httpsPort = port.Port
+		}
+	}
+
+	return "http://" + net.JoinHostPort(addr, strconv.Itoa(int(httpPort))),
+		"https://" + net.JoinHostPort(addr, strconv.Itoa(int(httpsPort))),
+		nil
+}
@@ -44,7 +44,9 @@ func TestProvisioner(t *testing.T) {
 	RunSpecs(t, "Gateway provisioner tests")
 }
 
-var _ = BeforeSuite(func() {
+// Each Gateway gets its own Contour and Envoy, so specs can run in
+// parallel once the provisioner and shared GatewayClasses are set up.
+var _ = SynchronizedBeforeSuite(func() {
 	require.NoError(f.T(), f.Provisioner.EnsureResourcesForInclusterProvisioner())
 
 	gc := &gatewayapi_v1beta1.GatewayClass{
@@ -115,9 +117,9 @@ var _ = BeforeSuite(func() {
 	}
 	_, ok = f.CreateGatewayClassAndWaitFor(gcWithEnvoyDeployment, e2e.GatewayClassAccepted)
 	require.True(f.T(), ok)
-})
+}, func() {})
 
-var _ = AfterSuite(func() {
+var _ = SynchronizedAfterSuite(func() {}, func() {
 	// Delete resources individually instead of deleting the entire contour
 	// namespace as a performance optimization, because deleting non-empty
 	// namespaces can take up to a couple minutes to complete.
@@ -22,6 +22,7 @@ import (
 	"os"
 	"os/exec"
 	"testing"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -37,6 +38,10 @@ import (
 	gatewayapi_v1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 )
 
+// maxRoutePropagationDelay is how long a new route may take to
+// become routable once Contour has been upgraded.
+const maxRoutePropagationDelay = 30 * time.Second
+
 var (
 	f = e2e.NewFramework(true)
 
@@ -120,18 +125,33 @@ var _ = Describe("When upgrading", func() {
 				poller, err := e2e.StartAppPoller(f.HTTP.HTTPURLBase, appHost, http.StatusOK, GinkgoWriter)
 				require.NoError(f.T(), err)
 
+				// Also make requests over long-lived connections, which
+				// will be open while the old Envoy pods drain.
+				keepAlivePoller, err := e2e.StartKeepAliveAppPoller(f.HTTP.HTTPURLBase, appHost, http.StatusOK, GinkgoWriter)
+				require.NoError(f.T(), err)
+
 				By("deploying updated contour resources")
 				require.NoError(f.T(), f.Deployment.EnsureResourcesForInclusterContour(true))
 
 				By("ensuring app is still routable")
 				checkRoutability(appHost)
 
+				By("ensuring new routes are programmed promptly")
+				checkRoutePropagation(namespace, "echo-after-upgrade", "upgrade-echo-after.test.com")
+
 				poller.Stop()
-				totalRequests, successfulRequests := poller.Results()
-				f.T().Logf("Total requests: %d, successful requests: %d\n", totalRequests, successfulRequests)
-				require.Greater(f.T(), totalRequests, uint(0))
-				successPercentage := 100 * float64(successfulRequests) / float64(totalRequests)
-				require.Greaterf(f.T(), successPercentage, float64(90.0), "success rate of %.2f%% less than 90%", successPercentage)
+				keepAlivePoller.Stop()
+
+				for name, p := range map[string]*e2e.AppPoller{"new connection": poller, "keep-alive": keepAlivePoller} {
+					totalRequests, successfulRequests := p.Results()
+					f.T().Logf("%s poller: total requests: %d, successful requests: %d, 5xx responses: %d\n", name, totalRequests, successfulRequests, p.ServerErrors())
+					require.Greater(f.T(), totalRequests, uint(0))
+					successPercentage := 100 * float64(successfulRequests) / float64(totalRequests)
+					require.Greaterf(f.T(), successPercentage, float64(90.0), "%s poller success rate of %.2f%% less than 90%", name, successPercentage)
+					// Requests may fail to connect while Envoy pods are
+					// replaced, but Envoy should never return an error.
+					require.Zerof(f.T(), p.ServerErrors(), "%s poller received 5xx responses", name)
+				}
 			})
 		})
 	})
@@ -302,3 +322,38 @@ func checkRoutability(host string) {
 	require.NotNil(f.T(), res, "request never succeeded")
 	require.Truef(f.T(), ok, "expected 200 response code, got %d", res.StatusCode)
 }
+
+// checkRoutePropagation creates an HTTPProxy for host that routes to
+// the echo app and requires it to become routable within
+// maxRoutePropagationDelay.
+func checkRoutePropagation(namespace, name, host string) {
+	p := &contourv1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+		},
+		Spec: contourv1.HTTPProxySpec{
+			VirtualHost: &contourv1.VirtualHost{
+				Fqdn: host,
+			},
+			Routes: []contourv1.Route{
+				{
+					Services: []contourv1.Service{
+						{
+							Name: "echo",
+							Port: 80,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	start := time.Now()
+	require.NoError(f.T(), f.Client.Create(context.TODO(), p))
+	checkRoutability(host)
+
+	delay := time.Since(start)
+	f.T().Logf("Route propagation delay for %s: %s\n", host, delay)
+	require.LessOrEqualf(f.T(), delay, maxRoutePropagationDelay, "route for %s took longer than %s to propagate", host, maxRoutePropagationDelay)
+}
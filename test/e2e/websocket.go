@@ -0,0 +1,199 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build e2e
+
+package e2e
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"golang.org/x/net/websocket"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+type WebSocketRequestOpts struct {
+	Path string
+	Host string
+
+	// Secure makes the request over TLS to HTTPSURLBase,
+	// rather than to HTTPURLBase.
+	Secure bool
+
+	OverrideURL   string
+	Headers       map[string]string
+	TLSConfigOpts []func(*tls.Config)
+
+	// Messages are sent once the connection has been upgraded.
+	Messages []string
+
+	// Condition is checked after each message is received.
+	Condition func(*WebSocketResponse) bool
+}
+
+func (o *WebSocketRequestOpts) requestURLBase(h *HTTP) string {
+	switch {
+	case o.OverrideURL != "":
+		return o.OverrideURL
+	case o.Secure:
+		return h.HTTPSURLBase
+	default:
+		return h.HTTPURLBase
+	}
+}
+
+// WebSocketResponse holds the messages received over a WebSocket connection.
+type WebSocketResponse struct {
+	// Messages holds the text of the messages received, in order.
+	Messages []string
+}
+
+// HasWebSocketMessage returns a function that returns true
+// if the specified message has been received, or false otherwise.
+func HasWebSocketMessage(msg string) func(*WebSocketResponse) bool {
+	return func(res *WebSocketResponse) bool {
+		if res == nil {
+			return false
+		}
+		for _, m := range res.Messages {
+			if m == msg {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// WebSocketDial makes a single WebSocket connection with the provided
+// parameters and returns the upgraded connection or an error. Note that
+// opts.Messages and opts.Condition are ignored by this method. The caller
+// is responsible for closing the connection.
+//
+// This is useful for tests that need to hold a connection open, e.g. to
+// test idle timeouts or connection draining. Otherwise, tests should use
+// WebSocketRequestUntil which will retry to account for eventual consistency.
+func (h *HTTP) WebSocketDial(opts *WebSocketRequestOpts) (*websocket.Conn, error) {
+	base, err := url.Parse(opts.requestURLBase(h))
+	if err != nil {
+		return nil, err
+	}
+
+	// The Host header of the upgrade request is always taken from the
+	// location, so connect to Envoy's address ourselves and then use a
+	// location that names the virtual host.
+	location := &url.URL{
+		Scheme: "ws",
+		Host:   opts.Host,
+		Path:   opts.Path,
+	}
+	origin := &url.URL{
+		Scheme: "http",
+		Host:   opts.Host,
+	}
+
+	dialer := &net.Dialer{Timeout: h.RetryInterval * 5}
+
+	var conn net.Conn
+	if opts.Secure {
+		location.Scheme = "wss"
+		origin.Scheme = "https"
+
+		tlsConfig := &tls.Config{
+			ServerName: opts.Host,
+			//nolint:gosec
+			InsecureSkipVerify: true,
+		}
+		for _, opt := range opts.TLSConfigOpts {
+			opt(tlsConfig)
+		}
+		conn, err = tls.DialWithDialer(dialer, "tcp", base.Host, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", base.Host)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := websocket.NewConfig(location.String(), origin.String())
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	for k, v := range opts.Headers {
+		config.Header.Set(k, v)
+	}
+
+	ws, err := websocket.NewClient(config, conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("websocket handshake failed: %w", err)
+	}
+
+	return ws, nil
+}
+
+// WebSocketRequestUntil repeatedly makes WebSocket connections with the
+// provided parameters, sending opts.Messages on each, until "condition"
+// returns true or the timeout is reached. It always returns the messages
+// received over the last connection.
+func (h *HTTP) WebSocketRequestUntil(opts *WebSocketRequestOpts) (*WebSocketResponse, bool) {
+	var res *WebSocketResponse
+
+	if err := wait.PollUntilContextTimeout(context.Background(), h.RetryInterval, h.RetryTimeout, true, func(ctx context.Context) (bool, error) {
+		ws, err := h.WebSocketDial(opts)
+		if err != nil {
+			h.t.Logf("websocket error: %s", err)
+			// if there was an error, we want to keep
+			// retrying, so just return false, not an
+			// error.
+			return false, nil
+		}
+		defer ws.Close()
+
+		res = &WebSocketResponse{}
+
+		for _, msg := range opts.Messages {
+			if err := websocket.Message.Send(ws, msg); err != nil {
+				h.t.Logf("websocket send error: %s", err)
+				return false, nil
+			}
+		}
+
+		// Read messages until the condition is met or
+		// the server stops sending them.
+		for {
+			if err := ws.SetReadDeadline(time.Now().Add(h.RetryInterval * 5)); err != nil {
+				return false, nil
+			}
+
+			var msg string
+			if err := websocket.Message.Receive(ws, &msg); err != nil {
+				return false, nil
+			}
+			res.Messages = append(res.Messages, msg)
+
+			if opts.Condition != nil && opts.Condition(res) {
+				return true, nil
+			}
+		}
+	}); err != nil {
+		return res, false
+	}
+
+	return res, true
+}
@@ -44,13 +44,16 @@ EOF
 
 # If we're running conformance tests for the same Gateway API version
 # that we're using via go.mod, use our own test driver (via `go test`)
-# so we can opt into additional supported features that we know we support.
+# which opts into the features Contour supports and skips known failures.
 # Otherwise, we're likely running the `main` conformance tests for a nightly
 # build, where we have to clone the upstream repo to be able to run that
-# version of the tests, but lose the ability to opt into tests for additional
-# supported features since that's not exposed via flag.
+# version of the tests, and pass the supported features via flag.
 GO_MOD_GATEWAY_API_VERSION=$(grep "sigs.k8s.io/gateway-api" go.mod | awk '{print $2}')
 
+# Generated from Contour's supported features by
+# `make generate-gateway-conformance-features`.
+SUPPORTED_FEATURES=$(paste -sd, test/conformance/gatewayapi/supported-features.txt)
+
 if [ "$GATEWAY_API_VERSION" = "$GO_MOD_GATEWAY_API_VERSION" ]; then
   go test -timeout=40m -tags conformance ./test/conformance/gatewayapi --gateway-class=contour
 else 
@@ -62,5 +65,5 @@ else
   # test/conformance/gatewayapi/gateway_conformance_test.go.
   # Can implement with the -skip flag available with go 1.20
   # or if Gateway API supports skipping tests via custom flag.
-  go test -timeout=40m ./conformance -gateway-class=contour -all-features -exempt-features=Mesh
+  go test -timeout=40m ./conformance -gateway-class=contour -supported-features="${SUPPORTED_FEATURES}"
 fi